@@ -0,0 +1,85 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// ops for the audit log.
+var (
+	OpRecordAuditEvent = "RecordAuditEvent"
+	OpFindAuditEvents  = "FindAuditEvents"
+)
+
+// AuditLogService records the mutating calls made to the API in an
+// append-only log. Recorded events cannot be changed or removed.
+type AuditLogService interface {
+	// RecordAuditEvent appends an event to the log, setting e.ID and e.Time
+	// if it has no time.
+	RecordAuditEvent(ctx context.Context, e *AuditEvent) error
+
+	// FindAuditEvents returns the events of the log that match filter, in the
+	// order they were recorded, and the total count of matching events.
+	FindAuditEvents(ctx context.Context, filter AuditEventFilter, opt ...FindOptions) ([]*AuditEvent, int, error)
+}
+
+// AuditEvent records a mutating call made to the API: who made it, from
+// where, to what resource, and the state of the resource before and after.
+type AuditEvent struct {
+	ID   ID        `json:"id"`
+	Time time.Time `json:"time"`
+
+	// UserID is the user the call was made on behalf of, and AuthorizerID
+	// and AuthorizerKind identify the authorization or session it was made
+	// with. They are empty for unauthenticated calls, such as sign ins.
+	UserID         ID     `json:"userID,omitempty"`
+	AuthorizerID   ID     `json:"authorizerID,omitempty"`
+	AuthorizerKind string `json:"authorizerKind,omitempty"`
+	// SourceIP is the address the call was made from.
+	SourceIP string `json:"sourceIP"`
+
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	// ResourceType and ResourceID are the resource the path refers to, such
+	// as the bucket of /api/v2/buckets/:id/labels. ResourceID is the ID of
+	// the created resource for calls creating one.
+	ResourceType ResourceType `json:"resourceType,omitempty"`
+	ResourceID   ID           `json:"resourceID,omitempty"`
+	StatusCode   int          `json:"statusCode"`
+
+	// Before and After are the JSON representations of the resource before
+	// and after the call, when they are known. Secrets, such as tokens and
+	// passwords, are redacted from them.
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditEventFilter represents a set of filters that restrict the events
+// returned from the audit log.
+type AuditEventFilter struct {
+	UserID       *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+	// Since and Until restrict events to those recorded at or after Since
+	// and before Until.
+	Since *time.Time
+	Until *time.Time
+}
+
+// Matches returns true if the event matches the filter.
+func (f AuditEventFilter) Matches(e *AuditEvent) bool {
+	switch {
+	case f.UserID != nil && e.UserID != *f.UserID:
+		return false
+	case f.ResourceType != nil && e.ResourceType != *f.ResourceType:
+		return false
+	case f.ResourceID != nil && e.ResourceID != *f.ResourceID:
+		return false
+	case f.Since != nil && e.Time.Before(*f.Since):
+		return false
+	case f.Until != nil && !e.Time.Before(*f.Until):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+// Package audit exports the events of the audit log as they are recorded, to
+// files or syslog, so that they can be kept outside of the instance.
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// An Exporter exports audit events.
+type Exporter interface {
+	Export(ctx context.Context, e *influxdb.AuditEvent) error
+	Close() error
+}
+
+var _ influxdb.AuditLogService = (*Service)(nil)
+
+// Service records audit events in the wrapped service and exports them to
+// its exporters. Events that cannot be exported remain recorded.
+type Service struct {
+	influxdb.AuditLogService
+
+	Exporters []Exporter
+
+	log *zap.Logger
+}
+
+// NewService returns a Service recording events in s and exporting them to
+// exporters.
+func NewService(log *zap.Logger, s influxdb.AuditLogService, exporters ...Exporter) *Service {
+	return &Service{
+		AuditLogService: s,
+		Exporters:       exporters,
+		log:             log,
+	}
+}
+
+// RecordAuditEvent records an event, then exports it.
+func (s *Service) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	if err := s.AuditLogService.RecordAuditEvent(ctx, e); err != nil {
+		return err
+	}
+
+	for _, x := range s.Exporters {
+		if err := x.Export(ctx, e); err != nil {
+			s.log.Warn("Unable to export audit event", zap.String("id", e.ID.String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// Close closes the exporters.
+func (s *Service) Close() error {
+	var firstErr error
+	for _, x := range s.Exporters {
+		if err := x.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,75 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+type failingExporter struct{}
+
+func (failingExporter) Export(ctx context.Context, e *influxdb.AuditEvent) error {
+	return errors.New("export failed")
+}
+
+func (failingExporter) Close() error { return nil }
+
+func TestService_RecordAuditEvent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "audit.log")
+	fx, err := audit.NewFileExporter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id influxdb.ID = 1
+	m := mock.NewAuditLogService()
+	m.RecordAuditEventFn = func(ctx context.Context, e *influxdb.AuditEvent) error {
+		e.ID = id
+		id++
+		return nil
+	}
+	s := audit.NewService(zaptest.NewLogger(t), m, failingExporter{}, fx)
+
+	for _, p := range []string{"/api/v2/buckets", "/api/v2/orgs"} {
+		if err := s.RecordAuditEvent(context.Background(), &influxdb.AuditEvent{Method: "POST", Path: p}); err != nil {
+			t.Fatalf("expected event to be recorded despite failing exporter, got %v", err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("unexpected number of exported events: %d\n%s", len(lines), data)
+	}
+	for i, line := range lines {
+		var e influxdb.AuditEvent
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID != influxdb.ID(i+1) {
+			t.Errorf("unexpected event in line %d: %s", i, line)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+)
+
+// FileExporter appends audit events to a file, as one JSON object per line.
+type FileExporter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileExporter returns a FileExporter appending to the file at path,
+// which is created if it does not exist.
+func NewFileExporter(path string) (*FileExporter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{f: f}, nil
+}
+
+// Export appends e to the file.
+func (x *FileExporter) Export(ctx context.Context, e *influxdb.AuditEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	_, err = x.f.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (x *FileExporter) Close() error {
+	return x.f.Close()
+}
@@ -0,0 +1,55 @@
+// +build !windows
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"net/url"
+
+	"github.com/influxdata/influxdb"
+)
+
+// syslogTag is the tag of the messages audit events are sent as.
+const syslogTag = "influxd-audit"
+
+// SyslogExporter sends audit events to syslog, as JSON objects.
+type SyslogExporter struct {
+	w *syslog.Writer
+}
+
+// NewSyslogExporter returns a SyslogExporter sending events to the syslog
+// server at addr, a udp:// or tcp:// URL, or to the local syslog daemon if
+// addr is "local".
+func NewSyslogExporter(addr string) (*SyslogExporter, error) {
+	var network, raddr string
+	if addr != "local" {
+		u, err := url.Parse(addr)
+		if err != nil || (u.Scheme != "udp" && u.Scheme != "tcp") || u.Host == "" {
+			return nil, fmt.Errorf("syslog address must be local, or a udp:// or tcp:// URL, got %q", addr)
+		}
+		network, raddr = u.Scheme, u.Host
+	}
+
+	w, err := syslog.Dial(network, raddr, syslog.LOG_NOTICE|syslog.LOG_AUTH, syslogTag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogExporter{w: w}, nil
+}
+
+// Export sends e to syslog.
+func (x *SyslogExporter) Export(ctx context.Context, e *influxdb.AuditEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return x.w.Notice(string(data))
+}
+
+// Close closes the connection to syslog.
+func (x *SyslogExporter) Close() error {
+	return x.w.Close()
+}
@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/influxdata/influxdb"
+)
+
+// SyslogExporter sends audit events to syslog, which is not supported on
+// Windows.
+type SyslogExporter struct{}
+
+// NewSyslogExporter returns an error, as syslog is not supported on Windows.
+func NewSyslogExporter(addr string) (*SyslogExporter, error) {
+	return nil, errors.New("syslog is not supported on windows")
+}
+
+// Export does nothing.
+func (x *SyslogExporter) Export(ctx context.Context, e *influxdb.AuditEvent) error {
+	return nil
+}
+
+// Close does nothing.
+func (x *SyslogExporter) Close() error {
+	return nil
+}
@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
@@ -23,17 +24,68 @@ type Authorization struct {
 	OrgID       ID           `json:"orgID"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
+
+	// ExpiresAt is when the token of the authorization expires. It never
+	// expires if it is nil.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// LastUsedAt is when the authorization was last used to authenticate a
+	// request, to within AuthorizationLastUsedPrecision.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	// Stale is set on authorizations that have not been used for a while.
+	Stale bool `json:"stale,omitempty"`
+
+	// PreviousToken is the token of the authorization before it was last
+	// rotated, which remains valid until PreviousTokenExpiresAt.
+	PreviousToken          string     `json:"previousToken,omitempty"`
+	PreviousTokenExpiresAt *time.Time `json:"previousTokenExpiresAt,omitempty"`
+
+	// RateLimits limits the rate of the requests made with the
+	// authorization. It is unlimited if it is nil.
+	RateLimits *AuthorizationRateLimits `json:"rateLimits,omitempty"`
+
 	CRUDLog
 }
 
 // AuthorizationUpdate is the authorization update request.
 type AuthorizationUpdate struct {
-	Status      *Status `json:"status,omitempty"`
-	Description *string `json:"description,omitempty"`
+	Status      *Status                  `json:"status,omitempty"`
+	Description *string                  `json:"description,omitempty"`
+	ExpiresAt   *time.Time               `json:"expiresAt,omitempty"`
+	RateLimits  *AuthorizationRateLimits `json:"rateLimits,omitempty"`
+}
+
+// AuthorizationRateLimits limits the rate of the requests made with an
+// authorization. A limit of zero leaves the rate unlimited.
+type AuthorizationRateLimits struct {
+	// MaxRequestsPerSecond is the maximum number of requests made with the
+	// authorization each second, on average. Requests may burst up to a
+	// second's worth of requests.
+	MaxRequestsPerSecond float64 `json:"maxRequestsPerSecond,omitempty"`
+
+	// MaxWriteBytesPerSecond is the maximum number of bytes of points
+	// written with the authorization each second, on average, as sent in
+	// the bodies of write requests.
+	MaxWriteBytesPerSecond int64 `json:"maxWriteBytesPerSecond,omitempty"`
+}
+
+// Valid returns an error if a limit is negative.
+func (l *AuthorizationRateLimits) Valid() error {
+	if l.MaxRequestsPerSecond < 0 || l.MaxWriteBytesPerSecond < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "rate limits must not be negative",
+		}
+	}
+	return nil
 }
 
 // Valid ensures that the authorization is valid.
 func (a *Authorization) Valid() error {
+	if a.RateLimits != nil {
+		if err := a.RateLimits.Valid(); err != nil {
+			return err
+		}
+	}
 	for _, p := range a.Permissions {
 		if p.Resource.OrgID != nil && *p.Resource.OrgID != a.OrgID {
 			return &Error{
@@ -56,6 +108,17 @@ func (a *Authorization) Allowed(p Permission) bool {
 	return PermissionAllowed(p, a.Permissions)
 }
 
+// SeriesScope returns the series of the bucket of p the authorization allows
+// p on.
+func (a *Authorization) SeriesScope(p Permission) SeriesScope {
+	return PermissionSeriesScope(p, a.Permissions)
+}
+
+// Expired returns true if the token of the authorization has expired at now.
+func (a *Authorization) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
 // IsActive is a stub for idpe.
 func IsActive(a *Authorization) bool {
 	return a.IsActive()
@@ -79,12 +142,17 @@ func (a *Authorization) Identifier() ID { return a.ID }
 
 // auth service op
 const (
-	OpFindAuthorizationByID    = "FindAuthorizationByID"
-	OpFindAuthorizationByToken = "FindAuthorizationByToken"
-	OpFindAuthorizations       = "FindAuthorizations"
-	OpCreateAuthorization      = "CreateAuthorization"
-	OpUpdateAuthorization      = "UpdateAuthorization"
-	OpDeleteAuthorization      = "DeleteAuthorization"
+	OpFindAuthorizationByID       = "FindAuthorizationByID"
+	OpFindAuthorizationByToken    = "FindAuthorizationByToken"
+	OpFindAuthorizations          = "FindAuthorizations"
+	OpCreateAuthorization         = "CreateAuthorization"
+	OpUpdateAuthorization         = "UpdateAuthorization"
+	OpDeleteAuthorization         = "DeleteAuthorization"
+	OpCreateAuthorizationBatch    = "CreateAuthorizationBatch"
+	OpDeleteLabeledAuthorizations = "DeleteLabeledAuthorizations"
+	OpRotateAuthorization         = "RotateAuthorization"
+	OpTouchAuthorization          = "TouchAuthorization"
+	OpFlagStaleAuthorizations     = "FlagStaleAuthorizations"
 )
 
 // AuthorizationService represents a service for managing authorization data.
@@ -120,3 +188,97 @@ type AuthorizationFilter struct {
 	OrgID *ID
 	Org   *string
 }
+
+// MaxAuthorizationBatchSize is the largest number of authorizations that can
+// be created by a single AuthorizationBatch.
+const MaxAuthorizationBatchSize = 10000
+
+// AuthorizationBatch describes a set of write-only authorizations for a
+// bucket, created together and mapped to a common label so that they can be
+// revoked together. It is used to provision tokens for fleets of devices.
+type AuthorizationBatch struct {
+	OrgID       ID     `json:"orgID"`
+	UserID      ID     `json:"userID,omitempty"`
+	BucketID    ID     `json:"bucketID"`
+	LabelID     ID     `json:"labelID"`
+	Count       int    `json:"count"`
+	Description string `json:"description"`
+}
+
+// Valid ensures that the batch is valid.
+func (b *AuthorizationBatch) Valid() error {
+	if !b.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "org id required",
+		}
+	}
+	if !b.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucket id required",
+		}
+	}
+	if !b.LabelID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "label id required",
+		}
+	}
+	if b.Count <= 0 || b.Count > MaxAuthorizationBatchSize {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("count must be between 1 and %d", MaxAuthorizationBatchSize),
+		}
+	}
+	return nil
+}
+
+// Authorization returns an authorization that grants write access to the
+// bucket of the batch only.
+func (b *AuthorizationBatch) Authorization() (*Authorization, error) {
+	p, err := NewPermissionAtID(b.BucketID, WriteAction, BucketsResourceType, b.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	return &Authorization{
+		Status:      Active,
+		Description: b.Description,
+		OrgID:       b.OrgID,
+		UserID:      b.UserID,
+		Permissions: []Permission{*p},
+	}, nil
+}
+
+// AuthorizationBatchService creates and revokes authorizations in bulk.
+type AuthorizationBatchService interface {
+	// CreateAuthorizationBatch creates the authorizations described by b and
+	// maps each of them to the label of b.
+	CreateAuthorizationBatch(ctx context.Context, b *AuthorizationBatch) ([]*Authorization, error)
+
+	// DeleteLabeledAuthorizations deletes every authorization in an
+	// organization mapped to a label, returning the number deleted.
+	DeleteLabeledAuthorizations(ctx context.Context, orgID, labelID ID) (int, error)
+}
+
+// AuthorizationLastUsedPrecision is the precision to which the last use of
+// authorizations is tracked, so that it is not recorded on every request.
+const AuthorizationLastUsedPrecision = time.Minute
+
+// AuthorizationLifecycleService manages the lifecycle of the tokens of
+// authorizations.
+type AuthorizationLifecycleService interface {
+	// RotateAuthorization issues a new token for an authorization. Its
+	// current token remains valid for grace.
+	RotateAuthorization(ctx context.Context, id ID, grace time.Duration) (*Authorization, error)
+
+	// TouchAuthorization records that an authorization was used at t, and
+	// that it is no longer stale.
+	TouchAuthorization(ctx context.Context, id ID, t time.Time) error
+
+	// FlagStaleAuthorizations flags the authorizations that have not been
+	// used since before as stale, and clears the flag of the others. Those
+	// that have never been used are stale if they were created before. It
+	// returns the number of stale authorizations.
+	FlagStaleAuthorizations(ctx context.Context, before time.Time) (int, error)
+}
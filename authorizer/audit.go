@@ -0,0 +1,50 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.AuditLogService = (*AuditLogService)(nil)
+
+// AuditLogService wraps a influxdb.AuditLogService and authorizes actions
+// against it appropriately.
+type AuditLogService struct {
+	s influxdb.AuditLogService
+}
+
+// NewAuditLogService constructs an instance of an authorizing audit log
+// service.
+func NewAuditLogService(s influxdb.AuditLogService) *AuditLogService {
+	return &AuditLogService{
+		s: s,
+	}
+}
+
+// RecordAuditEvent checks to see if the authorizer on context has write
+// access to every resource, as events are recorded on behalf of any user.
+func (s *AuditLogService) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+
+	return s.s.RecordAuditEvent(ctx, e)
+}
+
+// FindAuditEvents checks to see if the authorizer on context has read access
+// to every resource, as events record calls made to any resource.
+func (s *AuditLogService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, 0, err
+	}
+
+	return s.s.FindAuditEvents(ctx, filter, opt...)
+}
@@ -0,0 +1,47 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestAuditLogService_FindAuditEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantErr     bool
+	}{
+		{
+			name:        "authorized to read every resource",
+			permissions: influxdb.ReadAllPermissions(),
+		},
+		{
+			name: "unauthorized to read every resource",
+			permissions: []influxdb.Permission{
+				{Action: influxdb.ReadAction, Resource: influxdb.Resource{Type: influxdb.BucketsResourceType}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewAuditLogService(&mock.AuditLogService{
+				FindAuditEventsFn: func(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+					return []*influxdb.AuditEvent{}, 0, nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+			_, _, err := s.FindAuditEvents(ctx, influxdb.AuditEventFilter{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindAuditEvents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,8 +3,10 @@ package authorizer
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/influxdata/influxdb"
+	influxdbcontext "github.com/influxdata/influxdb/context"
 )
 
 var _ influxdb.AuthorizationService = (*AuthorizationService)(nil)
@@ -129,6 +131,8 @@ func (s *AuthorizationService) CreateAuthorization(ctx context.Context, a *influ
 }
 
 // VerifyPermission ensures that an authorization is allowed all of the appropriate permissions.
+// A permission is only allowed on the series of buckets the authorization is
+// restricted to.
 func VerifyPermissions(ctx context.Context, ps []influxdb.Permission) error {
 	for _, p := range ps {
 		if err := IsAllowed(ctx, p); err != nil {
@@ -138,6 +142,13 @@ func VerifyPermissions(ctx context.Context, ps []influxdb.Permission) error {
 				Code: influxdb.EForbidden,
 			}
 		}
+		a, _ := influxdbcontext.GetAuthorizer(ctx)
+		if !influxdb.AuthorizerSeriesScope(a, p).Contains(p.Resource) {
+			return &influxdb.Error{
+				Msg:  fmt.Sprintf("permission %s is not allowed on series outside of the restrictions of the authorization", p),
+				Code: influxdb.EForbidden,
+			}
+		}
 	}
 
 	return nil
@@ -170,3 +181,120 @@ func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id influ
 
 	return s.s.DeleteAuthorization(ctx, id)
 }
+
+var _ influxdb.AuthorizationBatchService = (*AuthorizationBatchService)(nil)
+
+// AuthorizationBatchService wraps a influxdb.AuthorizationBatchService and
+// authorizes actions against it appropriately.
+type AuthorizationBatchService struct {
+	s influxdb.AuthorizationBatchService
+}
+
+// NewAuthorizationBatchService constructs an instance of an authorizing
+// authorization batch service.
+func NewAuthorizationBatchService(s influxdb.AuthorizationBatchService) *AuthorizationBatchService {
+	return &AuthorizationBatchService{
+		s: s,
+	}
+}
+
+func authorizeWriteOrgAuthorizations(ctx context.Context, orgID influxdb.ID) error {
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.AuthorizationsResourceType, orgID)
+	if err != nil {
+		return err
+	}
+	return IsAllowed(ctx, *p)
+}
+
+// CreateAuthorizationBatch checks to see if the authorizer on context has
+// write access to the authorizations of the organization, and is itself
+// allowed to write to the bucket of the batch.
+func (s *AuthorizationBatchService) CreateAuthorizationBatch(ctx context.Context, b *influxdb.AuthorizationBatch) ([]*influxdb.Authorization, error) {
+	if err := authorizeWriteOrgAuthorizations(ctx, b.OrgID); err != nil {
+		return nil, err
+	}
+	if err := authorizeWriteAuthorization(ctx, b.UserID); err != nil {
+		return nil, err
+	}
+
+	a, err := b.Authorization()
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyPermissions(ctx, a.Permissions); err != nil {
+		return nil, err
+	}
+
+	return s.s.CreateAuthorizationBatch(ctx, b)
+}
+
+// DeleteLabeledAuthorizations checks to see if the authorizer on context has
+// write access to the authorizations of the organization.
+func (s *AuthorizationBatchService) DeleteLabeledAuthorizations(ctx context.Context, orgID, labelID influxdb.ID) (int, error) {
+	if err := authorizeWriteOrgAuthorizations(ctx, orgID); err != nil {
+		return 0, err
+	}
+	return s.s.DeleteLabeledAuthorizations(ctx, orgID, labelID)
+}
+
+var _ influxdb.AuthorizationLifecycleService = (*AuthorizationLifecycleService)(nil)
+
+// AuthorizationLifecycleService wraps a influxdb.AuthorizationLifecycleService
+// and authorizes actions against it appropriately.
+type AuthorizationLifecycleService struct {
+	s  influxdb.AuthorizationLifecycleService
+	as influxdb.AuthorizationService
+}
+
+// NewAuthorizationLifecycleService constructs an instance of an authorizing
+// authorization lifecycle service. The authorizations are looked up in as.
+func NewAuthorizationLifecycleService(s influxdb.AuthorizationLifecycleService, as influxdb.AuthorizationService) *AuthorizationLifecycleService {
+	return &AuthorizationLifecycleService{
+		s:  s,
+		as: as,
+	}
+}
+
+// RotateAuthorization checks to see if the authorizer on context has write
+// access to the authorization provided.
+func (s *AuthorizationLifecycleService) RotateAuthorization(ctx context.Context, id influxdb.ID, grace time.Duration) (*influxdb.Authorization, error) {
+	a, err := s.as.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteAuthorization(ctx, a.UserID); err != nil {
+		return nil, err
+	}
+
+	return s.s.RotateAuthorization(ctx, id, grace)
+}
+
+// TouchAuthorization checks to see if the authorizer on context has write
+// access to the authorization provided.
+func (s *AuthorizationLifecycleService) TouchAuthorization(ctx context.Context, id influxdb.ID, t time.Time) error {
+	a, err := s.as.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteAuthorization(ctx, a.UserID); err != nil {
+		return err
+	}
+
+	return s.s.TouchAuthorization(ctx, id, t)
+}
+
+// FlagStaleAuthorizations checks to see if the authorizer on context has
+// write access to all authorizations.
+func (s *AuthorizationLifecycleService) FlagStaleAuthorizations(ctx context.Context, before time.Time) (int, error) {
+	p, err := influxdb.NewGlobalPermission(influxdb.WriteAction, influxdb.AuthorizationsResourceType)
+	if err != nil {
+		return 0, err
+	}
+	if err := IsAllowed(ctx, *p); err != nil {
+		return 0, err
+	}
+
+	return s.s.FlagStaleAuthorizations(ctx, before)
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb"
@@ -270,3 +271,124 @@ func TestAuthorizationService_WriteAuthorization(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthorizationService_CreateAuthorization_seriesScope(t *testing.T) {
+	scoped := func(measurements ...string) influxdb.Permission {
+		return influxdb.Permission{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type:         influxdb.BucketsResourceType,
+				OrgID:        influxdbtesting.IDPtr(1),
+				ID:           influxdbtesting.IDPtr(2),
+				Measurements: measurements,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		permission influxdb.Permission
+		wantErr    bool
+	}{
+		{
+			name:       "series in the scope of the authorizer",
+			permission: scoped("cpu"),
+		},
+		{
+			name:       "series outside of the scope of the authorizer",
+			permission: scoped("cpu", "mem"),
+			wantErr:    true,
+		},
+		{
+			name: "every series of the bucket",
+			permission: influxdb.Permission{
+				Action: influxdb.ReadAction,
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					ID:    influxdbtesting.IDPtr(2),
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mock.AuthorizationService{}
+			m.CreateAuthorizationFn = func(ctx context.Context, a *influxdb.Authorization) error {
+				return nil
+			}
+			s := authorizer.NewAuthorizationService(m)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				Status: influxdb.Active,
+				UserID: 1,
+				Permissions: []influxdb.Permission{
+					scoped("cpu"),
+					{
+						Action:   influxdb.WriteAction,
+						Resource: influxdb.Resource{Type: influxdb.UsersResourceType, ID: influxdbtesting.IDPtr(1)},
+					},
+				},
+			})
+
+			err := s.CreateAuthorization(ctx, &influxdb.Authorization{
+				UserID:      1,
+				Permissions: []influxdb.Permission{tt.permission},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CreateAuthorization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && influxdb.ErrorCode(err) != influxdb.EForbidden {
+				t.Fatalf("got error code %q, expected forbidden", influxdb.ErrorCode(err))
+			}
+		})
+	}
+}
+
+func TestAuthorizationLifecycleService_RotateAuthorization(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  influxdb.ID
+		wantErr bool
+	}{
+		{
+			name:   "authorization of a user the authorizer can write",
+			userID: 1,
+		},
+		{
+			name:    "authorization of another user",
+			userID:  2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := &mock.AuthorizationService{}
+			as.FindAuthorizationByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+				return &influxdb.Authorization{ID: id, UserID: tt.userID}, nil
+			}
+			m := mock.NewAuthorizationLifecycleService()
+			m.RotateAuthorizationFn = func(ctx context.Context, id influxdb.ID, grace time.Duration) (*influxdb.Authorization, error) {
+				return &influxdb.Authorization{ID: id, UserID: tt.userID}, nil
+			}
+			s := authorizer.NewAuthorizationLifecycleService(m, as)
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				Status: influxdb.Active,
+				UserID: 1,
+				Permissions: []influxdb.Permission{{
+					Action:   influxdb.WriteAction,
+					Resource: influxdb.Resource{Type: influxdb.UsersResourceType, ID: influxdbtesting.IDPtr(1)},
+				}},
+			})
+
+			_, err := s.RotateAuthorization(ctx, 10, time.Hour)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RotateAuthorization() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -33,3 +33,28 @@ func IsAllowedAll(ctx context.Context, permissions []influxdb.Permission) error
 
 	return nil
 }
+
+// IsAllowedUnrestricted checks to see if an action is authorized by a
+// permission that is not restricted to some series of a bucket, as
+// operations on a bucket as a whole must be.
+func IsAllowedUnrestricted(ctx context.Context, p influxdb.Permission) error {
+	a, err := influxdbcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !a.Allowed(p) {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("%s is unauthorized", p),
+		}
+	}
+	if influxdb.AuthorizerSeriesScope(a, p) != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("%s is restricted to some series of the bucket", p),
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package authorizer
 
 import (
 	"context"
+	"io"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
@@ -42,13 +43,16 @@ func authorizeReadBucket(ctx context.Context, orgID, id influxdb.ID) error {
 	return nil
 }
 
+// authorizeWriteBucket checks that the authorizer on context may write to
+// the bucket as a whole, which permissions restricted to some of its series
+// do not allow.
 func authorizeWriteBucket(ctx context.Context, orgID, id influxdb.ID) error {
 	p, err := newBucketPermission(influxdb.WriteAction, orgID, id)
 	if err != nil {
 		return err
 	}
 
-	if err := IsAllowed(ctx, *p); err != nil {
+	if err := IsAllowedUnrestricted(ctx, *p); err != nil {
 		return err
 	}
 
@@ -187,3 +191,191 @@ func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error
 
 	return s.s.DeleteBucket(ctx, id)
 }
+
+var _ influxdb.BucketRestoreService = (*BucketRestoreService)(nil)
+
+// BucketRestoreService wraps a influxdb.BucketRestoreService and authorizes
+// actions against it appropriately.
+type BucketRestoreService struct {
+	s influxdb.BucketRestoreService
+}
+
+// NewBucketRestoreService constructs an instance of an authorizing bucket
+// restore service.
+func NewBucketRestoreService(s influxdb.BucketRestoreService) *BucketRestoreService {
+	return &BucketRestoreService{
+		s: s,
+	}
+}
+
+// FindDeletedBucketByID checks to see if the authorizer on context has read
+// access to the deleted bucket provided.
+func (s *BucketRestoreService) FindDeletedBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.s.FindDeletedBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, b.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// RestoreBucket checks to see if the authorizer on context has write access
+// to the deleted bucket provided.
+func (s *BucketRestoreService) RestoreBucket(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.s.FindDeletedBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, b.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	return s.s.RestoreBucket(ctx, id)
+}
+
+var _ influxdb.BucketTransferService = (*BucketTransferService)(nil)
+
+// BucketTransferService wraps a influxdb.BucketTransferService and authorizes
+// actions against it appropriately.
+type BucketTransferService struct {
+	s influxdb.BucketTransferService
+	b influxdb.BucketService
+}
+
+// NewBucketTransferService constructs an instance of an authorizing bucket
+// transfer service. The buckets transferred are found by b.
+func NewBucketTransferService(s influxdb.BucketTransferService, b influxdb.BucketService) *BucketTransferService {
+	return &BucketTransferService{
+		s: s,
+		b: b,
+	}
+}
+
+// RenameBucket checks to see if the authorizer on context has write access to
+// the bucket provided.
+func (s *BucketTransferService) RenameBucket(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.b.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, b.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	return s.s.RenameBucket(ctx, id, name)
+}
+
+// TransferBucket checks to see if the authorizer on context has write access
+// to the bucket provided, and to the buckets of the organization it is
+// transferred to.
+func (s *BucketTransferService) TransferBucket(ctx context.Context, id, orgID influxdb.ID) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.b.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, b.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	p, err := influxdb.NewPermission(influxdb.WriteAction, influxdb.BucketsResourceType, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := IsAllowed(ctx, *p); err != nil {
+		return nil, err
+	}
+
+	return s.s.TransferBucket(ctx, id, orgID)
+}
+
+var _ influxdb.BucketExportService = (*BucketExportService)(nil)
+
+// BucketExportService wraps a influxdb.BucketExportService and authorizes
+// actions against it appropriately.
+type BucketExportService struct {
+	s influxdb.BucketExportService
+	b influxdb.BucketService
+}
+
+// NewBucketExportService constructs an instance of an authorizing bucket
+// export service. The buckets that are exported are found by b.
+func NewBucketExportService(s influxdb.BucketExportService, b influxdb.BucketService) *BucketExportService {
+	return &BucketExportService{
+		s: s,
+		b: b,
+	}
+}
+
+// ExportBucket checks to see if the authorizer on context has read access to
+// the bucket provided.
+func (s *BucketExportService) ExportBucket(ctx context.Context, id influxdb.ID, start, stop int64, cursor string, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.b.FindBucketByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeReadBucket(ctx, b.OrgID, id); err != nil {
+		return err
+	}
+
+	return s.s.ExportBucket(ctx, id, start, stop, cursor, w)
+}
+
+var _ influxdb.BucketUsageService = (*BucketUsageService)(nil)
+
+// BucketUsageService wraps a influxdb.BucketUsageService and authorizes
+// actions against it appropriately.
+type BucketUsageService struct {
+	s influxdb.BucketUsageService
+	b influxdb.BucketService
+}
+
+// NewBucketUsageService constructs an instance of an authorizing bucket usage
+// service. The buckets whose usage is found are found by b.
+func NewBucketUsageService(s influxdb.BucketUsageService, b influxdb.BucketService) *BucketUsageService {
+	return &BucketUsageService{
+		s: s,
+		b: b,
+	}
+}
+
+// FindBucketUsage checks to see if the authorizer on context has read access
+// to the bucket provided.
+func (s *BucketUsageService) FindBucketUsage(ctx context.Context, id influxdb.ID) (*influxdb.BucketUsage, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b, err := s.b.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, b.OrgID, id); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindBucketUsage(ctx, id)
+}
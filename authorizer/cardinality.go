@@ -0,0 +1,62 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.CardinalityService = (*CardinalityService)(nil)
+
+// CardinalityService wraps a influxdb.CardinalityService and authorizes actions
+// against it appropriately.
+type CardinalityService struct {
+	s influxdb.CardinalityService
+}
+
+// NewCardinalityService constructs an instance of an authorizing cardinality service.
+func NewCardinalityService(s influxdb.CardinalityService) *CardinalityService {
+	return &CardinalityService{
+		s: s,
+	}
+}
+
+// FindMeasurementCardinality checks to see if the authorizer on context has
+// read access to the buckets the estimates belong to, and filters out any it
+// does not.
+func (s *CardinalityService) FindMeasurementCardinality(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// The limit is applied after filtering so that unauthorized estimates do
+	// not count towards it.
+	limit := filter.Limit
+	filter.Limit = 0
+
+	cs, err := s.s.FindMeasurementCardinality(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	cards := cs[:0]
+	for _, c := range cs {
+		err := authorizeReadBucket(ctx, c.OrgID, c.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		cards = append(cards, c)
+		if limit > 0 && len(cards) == limit {
+			break
+		}
+	}
+
+	return cards, nil
+}
@@ -0,0 +1,72 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.CompactionService = (*CompactionService)(nil)
+
+// CompactionService wraps a influxdb.CompactionService and authorizes actions
+// against it appropriately.
+type CompactionService struct {
+	s influxdb.CompactionService
+}
+
+// NewCompactionService constructs an instance of an authorizing compaction
+// service.
+func NewCompactionService(s influxdb.CompactionService) *CompactionService {
+	return &CompactionService{
+		s: s,
+	}
+}
+
+// CompactionSettings checks to see if the authorizer on context has read
+// access to all resources before returning the compaction settings.
+func (s *CompactionService) CompactionSettings(ctx context.Context) (*influxdb.CompactionSettings, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.CompactionSettings(ctx)
+}
+
+// UpdateCompactionSettings checks to see if the authorizer on context has
+// operator permissions before changing the compaction settings.
+func (s *CompactionService) UpdateCompactionSettings(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateCompactionSettings(ctx, upd)
+}
+
+// CompactBucket checks to see if the authorizer on context has operator
+// permissions before compacting the bucket.
+func (s *CompactionService) CompactBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.CompactBucket(ctx, orgID, bucketID)
+}
+
+// TombstoneStats checks to see if the authorizer on context has read access
+// to all resources before returning the tombstone stats.
+func (s *CompactionService) TombstoneStats(ctx context.Context) (*influxdb.TombstoneStats, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.TombstoneStats(ctx)
+}
@@ -0,0 +1,60 @@
+package authorizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.DrainService = (*DrainService)(nil)
+
+// DrainService wraps a influxdb.DrainService and authorizes actions
+// against it appropriately.
+type DrainService struct {
+	s influxdb.DrainService
+}
+
+// NewDrainService constructs an instance of an authorizing drain service.
+func NewDrainService(s influxdb.DrainService) *DrainService {
+	return &DrainService{
+		s: s,
+	}
+}
+
+// Drain checks to see if the authorizer on context has operator permissions
+// before draining the server.
+func (s *DrainService) Drain(ctx context.Context, timeout time.Duration) (*influxdb.DrainStatus, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.Drain(ctx, timeout)
+}
+
+// Resume checks to see if the authorizer on context has operator permissions
+// before resuming the server.
+func (s *DrainService) Resume(ctx context.Context) (*influxdb.DrainStatus, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.Resume(ctx)
+}
+
+// DrainStatus checks to see if the authorizer on context has read access to
+// all resources before returning the drain status.
+func (s *DrainService) DrainStatus(ctx context.Context) (*influxdb.DrainStatus, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.DrainStatus(ctx)
+}
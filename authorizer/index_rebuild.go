@@ -0,0 +1,61 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.IndexRebuildService = (*IndexRebuildService)(nil)
+
+// IndexRebuildService wraps a influxdb.IndexRebuildService and authorizes
+// actions against it appropriately. Rebuilding replaces the whole index, so it
+// requires operator permissions.
+type IndexRebuildService struct {
+	s influxdb.IndexRebuildService
+}
+
+// NewIndexRebuildService constructs an instance of an authorizing index
+// rebuild service.
+func NewIndexRebuildService(s influxdb.IndexRebuildService) *IndexRebuildService {
+	return &IndexRebuildService{
+		s: s,
+	}
+}
+
+// RebuildIndex checks to see if the authorizer on context has operator
+// permissions before starting the rebuild.
+func (s *IndexRebuildService) RebuildIndex(ctx context.Context, r *influxdb.IndexRebuild) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.RebuildIndex(ctx, r)
+}
+
+// FindIndexRebuildByID checks to see if the authorizer on context has read
+// access to all resources before returning the index rebuild.
+func (s *IndexRebuildService) FindIndexRebuildByID(ctx context.Context, id influxdb.ID) (*influxdb.IndexRebuild, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindIndexRebuildByID(ctx, id)
+}
+
+// FindIndexRebuilds checks to see if the authorizer on context has read
+// access to all resources before returning the index rebuilds.
+func (s *IndexRebuildService) FindIndexRebuilds(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindIndexRebuilds(ctx, filter)
+}
@@ -0,0 +1,134 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var (
+	_ influxdb.LDAPService     = (*LDAPService)(nil)
+	_ influxdb.LDAPSyncService = (*LDAPSyncService)(nil)
+)
+
+// authorizeLDAP checks that the authorizer on context has access with a to
+// every user and organization, as syncs of the directory manage the users and
+// the members of organizations.
+func authorizeLDAP(ctx context.Context, a influxdb.Action) error {
+	for _, rt := range []influxdb.ResourceType{influxdb.UsersResourceType, influxdb.OrgsResourceType} {
+		p, err := influxdb.NewGlobalPermission(a, rt)
+		if err != nil {
+			return err
+		}
+		if err := IsAllowed(ctx, *p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LDAPService wraps a influxdb.LDAPService and authorizes actions against it
+// appropriately.
+type LDAPService struct {
+	s influxdb.LDAPService
+}
+
+// NewLDAPService constructs an instance of an authorizing LDAP service.
+func NewLDAPService(s influxdb.LDAPService) *LDAPService {
+	return &LDAPService{
+		s: s,
+	}
+}
+
+// FindLDAPConfig checks to see if the authorizer on context has read access
+// to every user and organization.
+func (s *LDAPService) FindLDAPConfig(ctx context.Context) (*influxdb.LDAPConfig, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeLDAP(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindLDAPConfig(ctx)
+}
+
+// PutLDAPConfig checks to see if the authorizer on context has write access
+// to every user and organization.
+func (s *LDAPService) PutLDAPConfig(ctx context.Context, c *influxdb.LDAPConfig) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeLDAP(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.PutLDAPConfig(ctx, c)
+}
+
+// FindLDAPMemberships checks to see if the authorizer on context has read
+// access to every user and organization.
+func (s *LDAPService) FindLDAPMemberships(ctx context.Context) ([]*influxdb.LDAPMembership, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeLDAP(ctx, influxdb.ReadAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.FindLDAPMemberships(ctx)
+}
+
+// PutLDAPMembership checks to see if the authorizer on context has write
+// access to every user and organization.
+func (s *LDAPService) PutLDAPMembership(ctx context.Context, m *influxdb.LDAPMembership) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeLDAP(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.PutLDAPMembership(ctx, m)
+}
+
+// DeleteLDAPMembership checks to see if the authorizer on context has write
+// access to every user and organization.
+func (s *LDAPService) DeleteLDAPMembership(ctx context.Context, orgID, userID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeLDAP(ctx, influxdb.WriteAction); err != nil {
+		return err
+	}
+
+	return s.s.DeleteLDAPMembership(ctx, orgID, userID)
+}
+
+// LDAPSyncService wraps a influxdb.LDAPSyncService and authorizes syncs.
+type LDAPSyncService struct {
+	s influxdb.LDAPSyncService
+}
+
+// NewLDAPSyncService constructs an instance of an authorizing LDAP sync
+// service.
+func NewLDAPSyncService(s influxdb.LDAPSyncService) *LDAPSyncService {
+	return &LDAPSyncService{
+		s: s,
+	}
+}
+
+// SyncLDAP checks to see if the authorizer on context has write access to
+// every user and organization, even for dry runs, which report the members of
+// the groups of the directory.
+func (s *LDAPSyncService) SyncLDAP(ctx context.Context, dryRun bool) (*influxdb.LDAPSyncReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeLDAP(ctx, influxdb.WriteAction); err != nil {
+		return nil, err
+	}
+
+	return s.s.SyncLDAP(ctx, dryRun)
+}
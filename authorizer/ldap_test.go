@@ -0,0 +1,49 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestLDAPSyncService_SyncLDAP(t *testing.T) {
+	permission := func(rt influxdb.ResourceType) influxdb.Permission {
+		return influxdb.Permission{Action: influxdb.WriteAction, Resource: influxdb.Resource{Type: rt}}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantErr     bool
+	}{
+		{
+			name:        "authorized to write every user and organization",
+			permissions: []influxdb.Permission{permission(influxdb.UsersResourceType), permission(influxdb.OrgsResourceType)},
+		},
+		{
+			name:        "unauthorized to write every organization",
+			permissions: []influxdb.Permission{permission(influxdb.UsersResourceType)},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewLDAPSyncService(&mock.LDAPSyncService{
+				SyncLDAPFn: func(ctx context.Context, dryRun bool) (*influxdb.LDAPSyncReport, error) {
+					return &influxdb.LDAPSyncReport{DryRun: dryRun}, nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+			_, err := s.SyncLDAP(ctx, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SyncLDAP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.MaterializedViewService = (*MaterializedViewService)(nil)
+
+// MaterializedViewService wraps a influxdb.MaterializedViewService and
+// authorizes actions against it with the permissions of the bucket each view
+// is materialized in.
+type MaterializedViewService struct {
+	s influxdb.MaterializedViewService
+}
+
+// NewMaterializedViewService constructs an instance of an authorizing
+// materialized view service.
+func NewMaterializedViewService(s influxdb.MaterializedViewService) *MaterializedViewService {
+	return &MaterializedViewService{
+		s: s,
+	}
+}
+
+// FindMaterializedViewByID checks to see if the authorizer on context has
+// read access to the bucket of the view.
+func (s *MaterializedViewService) FindMaterializedViewByID(ctx context.Context, id influxdb.ID) (*influxdb.MaterializedView, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	v, err := s.s.FindMaterializedViewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, v.OrgID, v.BucketID); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// FindMaterializedViews retrieves the views that match filter and filters the
+// list down to those of the buckets the authorizer on context has read access
+// to.
+func (s *MaterializedViewService) FindMaterializedViews(ctx context.Context, filter influxdb.MaterializedViewFilter, opt ...influxdb.FindOptions) ([]*influxdb.MaterializedView, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	vs, _, err := s.s.FindMaterializedViews(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	views := vs[:0]
+	for _, v := range vs {
+		err := authorizeReadBucket(ctx, v.OrgID, v.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		views = append(views, v)
+	}
+
+	return views, len(views), nil
+}
+
+// CreateMaterializedView checks to see if the authorizer on context has write
+// access to the bucket of the view.
+func (s *MaterializedViewService) CreateMaterializedView(ctx context.Context, v *influxdb.MaterializedView) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteBucket(ctx, v.OrgID, v.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.CreateMaterializedView(ctx, v)
+}
+
+// UpdateMaterializedView checks to see if the authorizer on context has write
+// access to the bucket of the view.
+func (s *MaterializedViewService) UpdateMaterializedView(ctx context.Context, id influxdb.ID, upd influxdb.MaterializedViewUpdate) (*influxdb.MaterializedView, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	v, err := s.s.FindMaterializedViewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, v.OrgID, v.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateMaterializedView(ctx, id, upd)
+}
+
+// DeleteMaterializedView checks to see if the authorizer on context has write
+// access to the bucket of the view.
+func (s *MaterializedViewService) DeleteMaterializedView(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	v, err := s.s.FindMaterializedViewByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, v.OrgID, v.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.DeleteMaterializedView(ctx, id)
+}
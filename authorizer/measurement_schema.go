@@ -0,0 +1,98 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.MeasurementSchemaService = (*MeasurementSchemaService)(nil)
+
+// MeasurementSchemaService wraps a influxdb.MeasurementSchemaService and
+// authorizes actions against it with the permissions of the bucket of each
+// schema.
+type MeasurementSchemaService struct {
+	s influxdb.MeasurementSchemaService
+}
+
+// NewMeasurementSchemaService constructs an instance of an authorizing
+// measurement schema service.
+func NewMeasurementSchemaService(s influxdb.MeasurementSchemaService) *MeasurementSchemaService {
+	return &MeasurementSchemaService{
+		s: s,
+	}
+}
+
+// FindMeasurementSchemaByID checks to see if the authorizer on context has read access to the bucket of the schema.
+func (s *MeasurementSchemaService) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// FindMeasurementSchemas retrieves the schemas that match filter and filters the list down to those of the buckets the authorizer on context has read access to.
+func (s *MeasurementSchemaService) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ss, err := s.s.FindMeasurementSchemas(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := ss[:0]
+	for _, ms := range ss {
+		err := authorizeReadBucket(ctx, ms.OrgID, ms.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		schemas = append(schemas, ms)
+	}
+
+	return schemas, nil
+}
+
+// CreateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the schema.
+func (s *MeasurementSchemaService) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return err
+	}
+
+	return s.s.CreateMeasurementSchema(ctx, ms)
+}
+
+// UpdateMeasurementSchema checks to see if the authorizer on context has write access to the bucket of the schema.
+func (s *MeasurementSchemaService) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, err := s.s.FindMeasurementSchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeWriteBucket(ctx, ms.OrgID, ms.BucketID); err != nil {
+		return nil, err
+	}
+
+	return s.s.UpdateMeasurementSchema(ctx, id, upd)
+}
@@ -0,0 +1,89 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.MetadataStoreService = (*MetadataStoreService)(nil)
+
+// MetadataStoreService wraps a influxdb.MetadataStoreService and authorizes
+// actions against it appropriately.
+type MetadataStoreService struct {
+	s influxdb.MetadataStoreService
+}
+
+// NewMetadataStoreService constructs an instance of an authorizing metadata
+// store service.
+func NewMetadataStoreService(s influxdb.MetadataStoreService) *MetadataStoreService {
+	return &MetadataStoreService{
+		s: s,
+	}
+}
+
+// MetadataStoreStats checks to see if the authorizer on context has read
+// access to all resources before returning the stats of the metadata store.
+func (s *MetadataStoreService) MetadataStoreStats(ctx context.Context) (*influxdb.MetadataStoreStats, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.MetadataStoreStats(ctx)
+}
+
+// CompactMetadataStore checks to see if the authorizer on context has
+// operator permissions before compacting the metadata store.
+func (s *MetadataStoreService) CompactMetadataStore(ctx context.Context) (*influxdb.MetadataCompaction, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.CompactMetadataStore(ctx)
+}
+
+var _ influxdb.MetadataBundleService = (*MetadataBundleService)(nil)
+
+// MetadataBundleService wraps a influxdb.MetadataBundleService and authorizes
+// actions against it appropriately.
+type MetadataBundleService struct {
+	s influxdb.MetadataBundleService
+}
+
+// NewMetadataBundleService constructs an instance of an authorizing metadata
+// bundle service.
+func NewMetadataBundleService(s influxdb.MetadataBundleService) *MetadataBundleService {
+	return &MetadataBundleService{
+		s: s,
+	}
+}
+
+// ExportMetadata checks to see if the authorizer on context has operator
+// permissions before exporting the metadata, as it includes the tokens of
+// all authorizations.
+func (s *MetadataBundleService) ExportMetadata(ctx context.Context) (*influxdb.MetadataBundle, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.ExportMetadata(ctx)
+}
+
+// ImportMetadata checks to see if the authorizer on context has operator
+// permissions before importing the metadata.
+func (s *MetadataBundleService) ImportMetadata(ctx context.Context, b *influxdb.MetadataBundle) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.ImportMetadata(ctx, b)
+}
@@ -0,0 +1,60 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.NetworkPolicyService = (*NetworkPolicyService)(nil)
+
+// NetworkPolicyService wraps a influxdb.NetworkPolicyService and authorizes
+// actions against it appropriately.
+type NetworkPolicyService struct {
+	s influxdb.NetworkPolicyService
+}
+
+// NewNetworkPolicyService constructs an instance of an authorizing network
+// policy service.
+func NewNetworkPolicyService(s influxdb.NetworkPolicyService) *NetworkPolicyService {
+	return &NetworkPolicyService{
+		s: s,
+	}
+}
+
+// FindNetworkPolicy checks to see if the authorizer on context has read
+// access to the organization provided.
+func (s *NetworkPolicyService) FindNetworkPolicy(ctx context.Context, orgID influxdb.ID) (*influxdb.NetworkPolicy, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindNetworkPolicy(ctx, orgID)
+}
+
+// SetNetworkPolicy checks to see if the authorizer on context has write
+// access to the organization of the policy.
+func (s *NetworkPolicyService) SetNetworkPolicy(ctx context.Context, p *influxdb.NetworkPolicy) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteOrg(ctx, p.OrgID); err != nil {
+		return err
+	}
+	return s.s.SetNetworkPolicy(ctx, p)
+}
+
+// DeleteNetworkPolicy checks to see if the authorizer on context has write
+// access to the organization provided.
+func (s *NetworkPolicyService) DeleteNetworkPolicy(ctx context.Context, orgID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteOrg(ctx, orgID); err != nil {
+		return err
+	}
+	return s.s.DeleteNetworkPolicy(ctx, orgID)
+}
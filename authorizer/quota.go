@@ -0,0 +1,73 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.OrgQuotaService = (*OrgQuotaService)(nil)
+
+// OrgQuotaService wraps a influxdb.OrgQuotaService and authorizes actions
+// against it appropriately.
+type OrgQuotaService struct {
+	s influxdb.OrgQuotaService
+}
+
+// NewOrgQuotaService constructs an instance of an authorizing org quota
+// service.
+func NewOrgQuotaService(s influxdb.OrgQuotaService) *OrgQuotaService {
+	return &OrgQuotaService{
+		s: s,
+	}
+}
+
+// FindOrgQuota checks to see if the authorizer on context has read access to
+// the organization provided.
+func (s *OrgQuotaService) FindOrgQuota(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgQuota, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindOrgQuota(ctx, orgID)
+}
+
+// FindOrgQuotas checks to see if the authorizer on context has read access to
+// all resources before returning the quotas of every organization.
+func (s *OrgQuotaService) FindOrgQuotas(ctx context.Context) ([]*influxdb.OrgQuota, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindOrgQuotas(ctx)
+}
+
+// SetOrgQuota checks to see if the authorizer on context has operator
+// permissions before replacing the quota, as organizations may not raise their
+// own limits.
+func (s *OrgQuotaService) SetOrgQuota(ctx context.Context, q *influxdb.OrgQuota) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.SetOrgQuota(ctx, q)
+}
+
+// DeleteOrgQuota checks to see if the authorizer on context has operator
+// permissions before removing the limits of the organization.
+func (s *OrgQuotaService) DeleteOrgQuota(ctx context.Context, orgID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.DeleteOrgQuota(ctx, orgID)
+}
@@ -0,0 +1,86 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// RunningQueryService wraps a influxdb.RunningQueryService and authorizes
+// actions against it appropriately.
+type RunningQueryService struct {
+	s influxdb.RunningQueryService
+}
+
+// NewRunningQueryService constructs an instance of an authorizing running
+// query service.
+func NewRunningQueryService(s influxdb.RunningQueryService) *RunningQueryService {
+	return &RunningQueryService{
+		s: s,
+	}
+}
+
+// FindRunningQueryByID checks to see if the authorizer on context has read
+// access to the organization of the query.
+func (s *RunningQueryService) FindRunningQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	q, err := s.s.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeReadOrg(ctx, q.OrgID); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// FindRunningQueries retrieves all queries that match the provided filter and
+// then filters the list down to the queries of organizations the authorizer
+// on context has read access to.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	qs, err := s.s.FindRunningQueries(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	queries := qs[:0]
+	for _, q := range qs {
+		err := authorizeReadOrg(ctx, q.OrgID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// KillRunningQuery checks to see if the authorizer on context has write
+// access to the organization of the query before killing it.
+func (s *RunningQueryService) KillRunningQuery(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	q, err := s.s.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorizeWriteOrg(ctx, q.OrgID); err != nil {
+		return err
+	}
+	return s.s.KillRunningQuery(ctx, id)
+}
@@ -0,0 +1,85 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.SeriesMoveService = (*SeriesMoveService)(nil)
+
+// SeriesMoveService wraps a influxdb.SeriesMoveService and authorizes actions
+// against it appropriately.
+type SeriesMoveService struct {
+	s influxdb.SeriesMoveService
+}
+
+// NewSeriesMoveService constructs an instance of an authorizing series move service.
+func NewSeriesMoveService(s influxdb.SeriesMoveService) *SeriesMoveService {
+	return &SeriesMoveService{
+		s: s,
+	}
+}
+
+// MoveSeries checks to see if the authorizer on context has write access to
+// both the source and destination buckets.
+func (s *SeriesMoveService) MoveSeries(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteBucket(ctx, m.OrgID, m.SourceBucketID); err != nil {
+		return err
+	}
+	if err := authorizeWriteBucket(ctx, m.OrgID, m.DestinationBucketID); err != nil {
+		return err
+	}
+	return s.s.MoveSeries(ctx, m, pred)
+}
+
+// FindSeriesMoveByID checks to see if the authorizer on context has read
+// access to the source bucket of the series move.
+func (s *SeriesMoveService) FindSeriesMoveByID(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	m, err := s.s.FindSeriesMoveByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, m.OrgID, m.SourceBucketID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FindSeriesMoves checks to see if the authorizer on context has read access
+// to the source bucket of each series move, and filters out any it does not.
+func (s *SeriesMoveService) FindSeriesMoves(ctx context.Context, filter influxdb.SeriesMoveFilter) ([]*influxdb.SeriesMove, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ms, err := s.s.FindSeriesMoves(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	moves := ms[:0]
+	for _, m := range ms {
+		err := authorizeReadBucket(ctx, m.OrgID, m.SourceBucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		moves = append(moves, m)
+	}
+
+	return moves, nil
+}
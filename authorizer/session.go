@@ -0,0 +1,122 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.SessionManagementService = (*SessionManagementService)(nil)
+
+// SessionManagementService wraps a influxdb.SessionManagementService and
+// authorizes actions against it appropriately. The sessions of a user are
+// authorized as the user is.
+type SessionManagementService struct {
+	s influxdb.SessionManagementService
+}
+
+// NewSessionManagementService constructs an instance of an authorizing
+// session management service.
+func NewSessionManagementService(s influxdb.SessionManagementService) *SessionManagementService {
+	return &SessionManagementService{
+		s: s,
+	}
+}
+
+// FindSessions retrieves all sessions that match the provided filter and then
+// filters the list down to the sessions of the users the authorizer on
+// context has read access to.
+func (s *SessionManagementService) FindSessions(ctx context.Context, filter influxdb.SessionFilter, opt ...influxdb.FindOptions) ([]*influxdb.Session, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.UserID != nil {
+		if err := authorizeReadUser(ctx, *filter.UserID); err != nil {
+			return nil, 0, err
+		}
+		return s.s.FindSessions(ctx, filter, opt...)
+	}
+
+	ss, _, err := s.s.FindSessions(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	sessions := ss[:0]
+	for _, sn := range ss {
+		err := authorizeReadUser(ctx, sn.UserID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		sessions = append(sessions, sn)
+	}
+
+	n := len(sessions)
+	if len(opt) > 0 {
+		sessions = pageSessions(sessions, opt[0])
+	}
+	return sessions, n, nil
+}
+
+// pageSessions returns the page of ss described by opt.
+func pageSessions(ss []*influxdb.Session, opt influxdb.FindOptions) []*influxdb.Session {
+	if opt.Descending {
+		for i, j := 0, len(ss)-1; i < j; i, j = i+1, j-1 {
+			ss[i], ss[j] = ss[j], ss[i]
+		}
+	}
+	if opt.Offset >= len(ss) {
+		return ss[:0]
+	}
+	ss = ss[opt.Offset:]
+	if opt.Limit > 0 && opt.Limit < len(ss) {
+		ss = ss[:opt.Limit]
+	}
+	return ss
+}
+
+// RevokeSession checks to see if the authorizer on context has write access
+// to the user of the session.
+func (s *SessionManagementService) RevokeSession(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ss, _, err := s.s.FindSessions(ctx, influxdb.SessionFilter{ID: &id})
+	if err != nil {
+		return err
+	}
+	if len(ss) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Op:   influxdb.OpRevokeSession,
+			Msg:  influxdb.ErrSessionNotFound,
+		}
+	}
+
+	if err := authorizeWriteUser(ctx, ss[0].UserID); err != nil {
+		return err
+	}
+
+	return s.s.RevokeSession(ctx, id)
+}
+
+// RevokeSessions checks to see if the authorizer on context has write access
+// to the user.
+func (s *SessionManagementService) RevokeSessions(ctx context.Context, userID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteUser(ctx, userID); err != nil {
+		return err
+	}
+
+	return s.s.RevokeSessions(ctx, userID)
+}
@@ -0,0 +1,53 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestSessionManagementService(t *testing.T) {
+	svc := mock.NewSessionManagementService()
+	svc.FindSessionsFn = func(ctx context.Context, filter influxdb.SessionFilter, opt ...influxdb.FindOptions) ([]*influxdb.Session, int, error) {
+		ss := []*influxdb.Session{{ID: 10, UserID: 1}, {ID: 20, UserID: 2}}
+		if filter.ID != nil {
+			for _, s := range ss {
+				if s.ID == *filter.ID {
+					return []*influxdb.Session{s}, 1, nil
+				}
+			}
+			return nil, 0, nil
+		}
+		return ss, len(ss), nil
+	}
+	s := authorizer.NewSessionManagementService(svc)
+
+	// The user of the first session may only manage its own sessions.
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{influxdb.MePermissions(1)})
+
+	ss, n, err := s.FindSessions(ctx, influxdb.SessionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || ss[0].ID != 10 {
+		t.Errorf("got sessions %+v, want only the session of the user", ss)
+	}
+	other := influxdb.ID(2)
+	if _, _, err := s.FindSessions(ctx, influxdb.SessionFilter{UserID: &other}); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Errorf("expected listing the sessions of another user to be unauthorized, got %v", err)
+	}
+
+	if err := s.RevokeSession(ctx, 10); err != nil {
+		t.Errorf("expected revoking the session of the user to succeed, got %v", err)
+	}
+	if err := s.RevokeSession(ctx, 20); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Errorf("expected revoking the session of another user to be unauthorized, got %v", err)
+	}
+	if err := s.RevokeSessions(ctx, 2); influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+		t.Errorf("expected revoking the sessions of another user to be unauthorized, got %v", err)
+	}
+}
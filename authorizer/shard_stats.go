@@ -0,0 +1,61 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.ShardStatsService = (*ShardStatsService)(nil)
+
+// ShardStatsService wraps a influxdb.ShardStatsService and authorizes actions
+// against it appropriately.
+type ShardStatsService struct {
+	s influxdb.ShardStatsService
+}
+
+// NewShardStatsService constructs an instance of an authorizing shard stats service.
+func NewShardStatsService(s influxdb.ShardStatsService) *ShardStatsService {
+	return &ShardStatsService{
+		s: s,
+	}
+}
+
+// FindShardStats checks to see if the authorizer on context has read
+// access to the buckets the stats belong to, and filters out any it does not.
+func (s *ShardStatsService) FindShardStats(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// The limit is applied after filtering so that unauthorized stats do not
+	// count towards it.
+	limit := filter.Limit
+	filter.Limit = 0
+
+	ss, err := s.s.FindShardStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	stats := ss[:0]
+	for _, st := range ss {
+		err := authorizeReadBucket(ctx, st.OrgID, st.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		stats = append(stats, st)
+		if limit > 0 && len(stats) == limit {
+			break
+		}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,35 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.SnapshotService = (*SnapshotService)(nil)
+
+// SnapshotService wraps a influxdb.SnapshotService and authorizes actions
+// against it appropriately.
+type SnapshotService struct {
+	s influxdb.SnapshotService
+}
+
+// NewSnapshotService constructs an instance of an authorizing snapshot service.
+func NewSnapshotService(s influxdb.SnapshotService) *SnapshotService {
+	return &SnapshotService{
+		s: s,
+	}
+}
+
+// CreateSnapshot checks that the authorizer can read all resources, as with a
+// backup, before creating the snapshot.
+func (s *SnapshotService) CreateSnapshot(ctx context.Context) (*influxdb.SnapshotManifest, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.CreateSnapshot(ctx)
+}
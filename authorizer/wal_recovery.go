@@ -0,0 +1,36 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.WALRecoveryService = (*WALRecoveryService)(nil)
+
+// WALRecoveryService wraps a influxdb.WALRecoveryService and authorizes
+// actions against it appropriately.
+type WALRecoveryService struct {
+	s influxdb.WALRecoveryService
+}
+
+// NewWALRecoveryService constructs an instance of an authorizing WAL recovery
+// service.
+func NewWALRecoveryService(s influxdb.WALRecoveryService) *WALRecoveryService {
+	return &WALRecoveryService{
+		s: s,
+	}
+}
+
+// WALRecoveryReport checks that the authorizer can read all resources, since
+// the report covers the buckets of every organization.
+func (s *WALRecoveryService) WALRecoveryReport(ctx context.Context) (*influxdb.WALRecoveryReport, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.WALRecoveryReport(ctx)
+}
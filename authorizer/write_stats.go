@@ -0,0 +1,61 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.WriteStatsService = (*WriteStatsService)(nil)
+
+// WriteStatsService wraps a influxdb.WriteStatsService and authorizes actions
+// against it appropriately.
+type WriteStatsService struct {
+	s influxdb.WriteStatsService
+}
+
+// NewWriteStatsService constructs an instance of an authorizing write stats service.
+func NewWriteStatsService(s influxdb.WriteStatsService) *WriteStatsService {
+	return &WriteStatsService{
+		s: s,
+	}
+}
+
+// FindMeasurementWriteStats checks to see if the authorizer on context has read
+// access to the buckets the stats belong to, and filters out any it does not.
+func (s *WriteStatsService) FindMeasurementWriteStats(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// The limit is applied after filtering so that unauthorized stats do not
+	// count towards it.
+	limit := filter.Limit
+	filter.Limit = 0
+
+	ss, err := s.s.FindMeasurementWriteStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// This filters without allocating
+	// https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating
+	stats := ss[:0]
+	for _, st := range ss {
+		err := authorizeReadBucket(ctx, st.OrgID, st.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		stats = append(stats, st)
+		if limit > 0 && len(stats) == limit {
+			break
+		}
+	}
+
+	return stats, nil
+}
@@ -75,6 +75,12 @@ type Resource struct {
 	Type  ResourceType `json:"type"`
 	ID    *ID          `json:"id,omitempty"`
 	OrgID *ID          `json:"orgID,omitempty"`
+
+	// Measurements and Tags restrict a permission on buckets to some of
+	// their series: those of one of the measurements that match all of the
+	// tag rules. See SeriesScope.
+	Measurements []string  `json:"measurements,omitempty"`
+	Tags         []TagRule `json:"tags,omitempty"`
 }
 
 // String stringifies a resource
@@ -282,7 +288,7 @@ func (p *Permission) Valid() error {
 		}
 	}
 
-	return nil
+	return p.Resource.validScope()
 }
 
 // NewPermission returns a permission with provided arguments.
@@ -285,6 +285,47 @@ func TestPermission_Valid(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid bucket permission restricted to measurements and tags",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:         platform.BucketsResourceType,
+					ID:           validID(),
+					OrgID:        influxdbtesting.IDPtr(1),
+					Measurements: []string{"cpu"},
+					Tags: []platform.TagRule{
+						{Tag: platform.Tag{Key: "team", Value: "a|b"}, Operator: platform.RegexEqual},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid dashboard permission restricted to measurements",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:         platform.DashboardsResourceType,
+					OrgID:        influxdbtesting.IDPtr(1),
+					Measurements: []string{"cpu"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid bucket permission restricted to an invalid regular expression",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:  platform.BucketsResourceType,
+					OrgID: influxdbtesting.IDPtr(1),
+					Tags: []platform.TagRule{
+						{Tag: platform.Tag{Key: "team", Value: "a("}, Operator: platform.RegexEqual},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid permission without an action",
 			fields: fields{
@@ -0,0 +1,238 @@
+// Package awssecrets implements influxdb.SecretService using AWS Secrets
+// Manager.
+package awssecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SecretService = (*SecretService)(nil)
+
+// DefaultPrefix is the prefix of the names of the secrets of Secrets Manager
+// holding the secrets of organizations, if none is configured.
+const DefaultPrefix = "influxdb/"
+
+// Config configures the Secrets Manager client. If any field is a zero
+// value, it will be ignored and the default used.
+type Config struct {
+	// Region and Endpoint default to those of the shared AWS configuration.
+	Region   string
+	Endpoint string
+	// Prefix is the prefix of the names of the secrets holding the secrets
+	// of organizations.
+	Prefix string
+	// KMSKeyID is the KMS key the secrets are encrypted with. It defaults to
+	// the default key of the account.
+	KMSKeyID string
+}
+
+// SecretService stores the secrets of each organization as a JSON object in
+// a secret of Secrets Manager named after the organization, such as
+// influxdb/031c8cbefe101000. Secrets Manager does not support conditional
+// writes, so the last of concurrent writes of the secrets of an organization
+// wins.
+type SecretService struct {
+	Client secretsmanageriface.SecretsManagerAPI
+
+	prefix   string
+	kmsKeyID string
+}
+
+// NewSecretService creates an instance of a SecretService. Credentials are
+// read from the standard AWS environment variables and shared configuration.
+func NewSecretService(c Config) (*SecretService, error) {
+	config := aws.NewConfig()
+	if c.Region != "" {
+		config = config.WithRegion(c.Region)
+	}
+	if c.Endpoint != "" {
+		config = config.WithEndpoint(c.Endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SecretService{
+		Client:   secretsmanager.New(sess),
+		prefix:   c.Prefix,
+		kmsKeyID: c.KMSKeyID,
+	}
+	if s.prefix == "" {
+		s.prefix = DefaultPrefix
+	}
+	return s, nil
+}
+
+func (s *SecretService) name(orgID influxdb.ID) string {
+	return s.prefix + orgID.String()
+}
+
+// LoadSecret retrieves the secret value v found at key k for organization orgID.
+func (s *SecretService) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+	data, _, err := s.loadSecrets(ctx, orgID)
+	if err != nil {
+		return "", err
+	}
+
+	if v, ok := data[k]; ok {
+		return v, nil
+	}
+
+	return "", &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrSecretNotFound,
+	}
+}
+
+// loadSecrets retrieves a map of secrets for an organization and whether the
+// secret holding them exists.
+func (s *SecretService) loadSecrets(ctx context.Context, orgID influxdb.ID) (map[string]string, bool, error) {
+	out, err := s.Client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(s.name(orgID)),
+	})
+	if isNotFound(err) {
+		return map[string]string{}, false, nil
+	} else if err != nil {
+		return nil, false, unavailable(err)
+	}
+
+	m := map[string]string{}
+	if out.SecretString == nil {
+		return m, true, nil
+	}
+	if err := json.Unmarshal([]byte(*out.SecretString), &m); err != nil {
+		return nil, false, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  fmt.Sprintf("secret %s is not a JSON object of strings", s.name(orgID)),
+			Err:  err,
+		}
+	}
+	return m, true, nil
+}
+
+// putSecrets replaces the secrets of the organization orgID with data,
+// creating the secret holding them unless it exists.
+func (s *SecretService) putSecrets(ctx context.Context, orgID influxdb.ID, data map[string]string, exists bool) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		in := &secretsmanager.CreateSecretInput{
+			Name:         aws.String(s.name(orgID)),
+			SecretString: aws.String(string(b)),
+		}
+		if s.kmsKeyID != "" {
+			in.KmsKeyId = aws.String(s.kmsKeyID)
+		}
+		_, err := s.Client.CreateSecretWithContext(ctx, in)
+		if !isExists(err) {
+			return unavailable(err)
+		}
+		// The secret was created since it was read.
+	}
+
+	_, err = s.Client.PutSecretValueWithContext(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(s.name(orgID)),
+		SecretString: aws.String(string(b)),
+	})
+	return unavailable(err)
+}
+
+// GetSecretKeys retrieves all secret keys that are stored for the organization orgID.
+func (s *SecretService) GetSecretKeys(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+	data, _, err := s.loadSecrets(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// PutSecret stores the secret pair (k,v) for the organization orgID.
+func (s *SecretService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	return s.PatchSecrets(ctx, orgID, map[string]string{k: v})
+}
+
+// PutSecrets puts all provided secrets and overwrites any previous values.
+func (s *SecretService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	_, exists, err := s.loadSecrets(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	return s.putSecrets(ctx, orgID, m, exists)
+}
+
+// PatchSecrets patches all provided secrets and updates any previous values.
+func (s *SecretService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	data, exists, err := s.loadSecrets(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	for k, v := range m {
+		data[k] = v
+	}
+
+	return s.putSecrets(ctx, orgID, data, exists)
+}
+
+// DeleteSecret removes a single secret from the secret store.
+func (s *SecretService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	data, exists, err := s.loadSecrets(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	for _, k := range ks {
+		delete(data, k)
+	}
+
+	return s.putSecrets(ctx, orgID, data, exists)
+}
+
+func isNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == secretsmanager.ErrCodeResourceNotFoundException
+}
+
+func isExists(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == secretsmanager.ErrCodeResourceExistsException
+}
+
+// unavailable returns the errors of Secrets Manager as unavailable errors.
+func unavailable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  "unable to access aws secrets manager",
+		Err:  err,
+	}
+}
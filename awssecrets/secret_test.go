@@ -0,0 +1,86 @@
+package awssecrets_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/awssecrets"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+// fakeSecretsManager holds secret strings by name.
+type fakeSecretsManager struct {
+	secretsmanageriface.SecretsManagerAPI
+
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+func (f *fakeSecretsManager) GetSecretValueWithContext(ctx aws.Context, in *secretsmanager.GetSecretValueInput, opts ...request.Option) (*secretsmanager.GetSecretValueOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.secrets[*in.SecretId]
+	if !ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	return &secretsmanager.GetSecretValueOutput{Name: in.SecretId, SecretString: aws.String(v)}, nil
+}
+
+func (f *fakeSecretsManager) CreateSecretWithContext(ctx aws.Context, in *secretsmanager.CreateSecretInput, opts ...request.Option) (*secretsmanager.CreateSecretOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.secrets[*in.Name]; ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceExistsException, "exists", nil)
+	}
+	f.secrets[*in.Name] = *in.SecretString
+	return &secretsmanager.CreateSecretOutput{Name: in.Name}, nil
+}
+
+func (f *fakeSecretsManager) PutSecretValueWithContext(ctx aws.Context, in *secretsmanager.PutSecretValueInput, opts ...request.Option) (*secretsmanager.PutSecretValueOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.secrets[*in.SecretId]; !ok {
+		return nil, awserr.New(secretsmanager.ErrCodeResourceNotFoundException, "not found", nil)
+	}
+	f.secrets[*in.SecretId] = *in.SecretString
+	return &secretsmanager.PutSecretValueOutput{Name: in.SecretId}, nil
+}
+
+func initSecretService(f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
+	s, err := awssecrets.NewSecretService(awssecrets.Config{Region: "us-east-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Client = &fakeSecretsManager{secrets: make(map[string]string)}
+
+	ctx := context.Background()
+	for _, sec := range f.Secrets {
+		for k, v := range sec.Env {
+			if err := s.PutSecret(ctx, sec.OrganizationID, k, v); err != nil {
+				t.Fatalf("failed to populate secrets: %v", err)
+			}
+		}
+	}
+	return s, func() {}
+}
+
+func TestSecretService(t *testing.T) {
+	influxdbtesting.SecretService(initSecretService, t)
+}
+
+func TestSecretService_LoadSecret_NotFound(t *testing.T) {
+	s, done := initSecretService(influxdbtesting.SecretServiceFields{}, t)
+	defer done()
+
+	_, err := s.LoadSecret(context.Background(), influxdb.ID(1), "api_key")
+	if got, want := influxdb.ErrorCode(err), influxdb.ENotFound; got != want {
+		t.Fatalf("got error code %q, want %q", got, want)
+	}
+}
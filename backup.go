@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"io"
+	"time"
 )
 
 // BackupService represents the data backup functions of InfluxDB.
@@ -16,6 +17,38 @@ type BackupService interface {
 	InternalBackupPath(backupID int) string
 }
 
+// SnapshotManifest describes a snapshot of the TSM data of the storage engine.
+// The files of a snapshot form a consistent set and can be downloaded with
+// FetchBackupFile, using the snapshot ID as the backup ID.
+type SnapshotManifest struct {
+	ID int `json:"id"`
+	// CheckpointID is the checkpoint taken by the snapshot. The snapshot
+	// includes every write covered by it.
+	CheckpointID CheckpointID   `json:"checkpointID"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	Files        []SnapshotFile `json:"files"`
+}
+
+// SnapshotFile is a file of a snapshot.
+type SnapshotFile struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// SnapshotService creates snapshots of the storage engine while it is running.
+type SnapshotService interface {
+	// CreateSnapshot writes the cache to TSM files, then creates hard links to
+	// all TSM and tombstone files, and returns the manifest of the snapshot.
+	CreateSnapshot(ctx context.Context) (*SnapshotManifest, error)
+}
+
+// CheckpointID identifies a storage checkpoint. Every write accepted by the
+// storage engine before a checkpoint was requested is durable once the
+// checkpoint completes. IDs never decrease, including across restarts, so a
+// checkpoint includes all writes covered by any checkpoint with a smaller ID
+// and can be used as a watermark by backups and replication.
+type CheckpointID uint64
+
 // KVBackupService represents the meta data backup functions of InfluxDB.
 type KVBackupService interface {
 	// Backup creates a live backup copy of the metadata database.
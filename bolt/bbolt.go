@@ -3,9 +3,6 @@ package bolt
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"time"
 
 	bolt "github.com/coreos/bbolt"
 	platform "github.com/influxdata/influxdb"
@@ -19,7 +16,7 @@ const DefaultFilename = "influxd.bolt"
 // Client is a client for the boltDB data store.
 type Client struct {
 	Path string
-	db   *bolt.DB
+	db   *DB
 	log  *zap.Logger
 
 	IDGenerator    platform.IDGenerator
@@ -38,23 +35,14 @@ func NewClient(log *zap.Logger) *Client {
 }
 
 // DB returns the clients DB.
-func (c *Client) DB() *bolt.DB {
+func (c *Client) DB() *DB {
 	return c.db
 }
 
 // Open / create boltDB file.
 func (c *Client) Open(ctx context.Context) error {
-	// Ensure the required directory structure exists.
-	if err := os.MkdirAll(filepath.Dir(c.Path), 0700); err != nil {
-		return fmt.Errorf("unable to create directory %s: %v", c.Path, err)
-	}
-
-	if _, err := os.Stat(c.Path); err != nil && !os.IsNotExist(err) {
-		return err
-	}
-
 	// Open database file.
-	db, err := bolt.Open(c.Path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	db, err := OpenDB(c.log, c.Path)
 	if err != nil {
 		return fmt.Errorf("unable to open boltdb; is there a chronograf already running?  %v", err)
 	}
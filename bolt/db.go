@@ -0,0 +1,303 @@
+package bolt
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.MetadataStoreService = (*DB)(nil)
+
+// compactTxSize is the number of bytes copied by each transaction of a
+// compaction, bounding the memory of the copy.
+const compactTxSize = 64 * 1024 * 1024
+
+// DB is a bolt database which may be compacted while it is in use. Its
+// transactions are held off while its file is swapped for a compacted copy,
+// so the services sharing a database must all use the DB rather than the
+// underlying bolt database.
+type DB struct {
+	path string
+	log  *zap.Logger
+
+	mu sync.RWMutex
+	db *bolt.DB
+}
+
+// OpenDB opens the bolt database at path, creating it if it does not exist.
+func OpenDB(log *zap.Logger, path string) (*DB, error) {
+	// Ensure the required directory structure exists.
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("unable to create directory %s: %v", path, err)
+	}
+
+	if _, err := os.Stat(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	db, err := openBolt(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{path: path, log: log, db: db}, nil
+}
+
+func openBolt(path string) (*bolt.DB, error) {
+	return bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+}
+
+// Path returns the path of the file of the database.
+func (db *DB) Path() string {
+	return db.path
+}
+
+// View executes fn in a read-only transaction.
+func (db *DB) View(fn func(*bolt.Tx) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.db.View(fn)
+}
+
+// Update executes fn in a read-write transaction.
+func (db *DB) Update(fn func(*bolt.Tx) error) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.db.Update(fn)
+}
+
+// Stats returns the statistics of the database.
+func (db *DB) Stats() bolt.Stats {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.db.Stats()
+}
+
+// Close closes the database.
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.db.Close()
+}
+
+// MetadataStoreStats returns the size of the file of the database and the
+// ratio of its pages which are free.
+func (db *DB) MetadataStoreStats(ctx context.Context) (*influxdb.MetadataStoreStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.stats()
+}
+
+// stats must be called with mu held.
+func (db *DB) stats() (*influxdb.MetadataStoreStats, error) {
+	st := &influxdb.MetadataStoreStats{Path: db.path}
+	err := db.db.View(func(tx *bolt.Tx) error {
+		st.Size = tx.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The free pages are those of the freelist and those freed by
+	// transactions still open, which are reused once they complete.
+	s := db.db.Stats()
+	if pages := st.Size / int64(db.db.Info().PageSize); pages > 0 {
+		st.FreePageRatio = float64(s.FreePageN+s.PendingPageN) / float64(pages)
+	}
+	return st, nil
+}
+
+// CompactMetadataStore copies the database into a new file without its free
+// pages, and swaps it for the file of the database. Transactions wait while
+// the database is compacted.
+func (db *DB) CompactMetadataStore(ctx context.Context) (*influxdb.MetadataCompaction, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	start := time.Now()
+	before, err := db.stats()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath := db.path + ".compact"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := db.compactTo(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "failed to compact metadata store",
+			Err:  err,
+		}
+	}
+
+	if err := db.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, db.path); err != nil {
+		os.Remove(tmpPath)
+		return nil, db.reopen(err)
+	}
+	if err := syncDir(filepath.Dir(db.path)); err != nil {
+		db.log.Warn("Failed to sync directory of compacted metadata store", zap.Error(err))
+	}
+	if err := db.reopen(nil); err != nil {
+		return nil, err
+	}
+
+	after, err := db.stats()
+	if err != nil {
+		return nil, err
+	}
+	c := &influxdb.MetadataCompaction{
+		Before:   *before,
+		After:    *after,
+		Duration: time.Since(start),
+	}
+	db.log.Info("Compacted metadata store",
+		zap.String("path", db.path),
+		zap.Int64("size_before", before.Size),
+		zap.Int64("size_after", after.Size),
+		zap.Duration("duration", c.Duration))
+	return c, nil
+}
+
+// reopen opens the file of the database after it was closed to be swapped,
+// returning cause if it is not nil.
+func (db *DB) reopen(cause error) error {
+	bdb, err := openBolt(db.path)
+	if err != nil {
+		// Transactions of the closed database fail until it is reopened.
+		db.log.Error("Failed to reopen metadata store", zap.String("path", db.path), zap.Error(err))
+		return fmt.Errorf("unable to reopen boltdb file %v", err)
+	}
+	db.db = bdb
+	return cause
+}
+
+// compactTo copies the buckets of the database to a new database at path.
+func (db *DB) compactTo(path string) error {
+	dst, err := openBolt(path)
+	if err != nil {
+		return err
+	}
+
+	err = db.db.View(func(src *bolt.Tx) error {
+		w := &compactWriter{db: dst}
+		if err := w.begin(); err != nil {
+			return err
+		}
+		err := src.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return w.copyBucket([][]byte{name}, b)
+		})
+		if err != nil {
+			w.tx.Rollback()
+			return err
+		}
+		return w.tx.Commit()
+	})
+	if err != nil {
+		dst.Close()
+		return err
+	}
+
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// compactWriter writes the copy of a database in transactions of at most
+// compactTxSize bytes.
+type compactWriter struct {
+	db   *bolt.DB
+	tx   *bolt.Tx
+	size int
+}
+
+func (w *compactWriter) begin() (err error) {
+	w.tx, err = w.db.Begin(true)
+	w.size = 0
+	return err
+}
+
+// bucket returns the bucket at path in the current transaction, creating it
+// if needed.
+func (w *compactWriter) bucket(path [][]byte) (*bolt.Bucket, error) {
+	b, err := w.tx.CreateBucketIfNotExists(path[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range path[1:] {
+		if b, err = b.CreateBucketIfNotExists(name); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+func (w *compactWriter) copyBucket(path [][]byte, src *bolt.Bucket) error {
+	dst, err := w.bucket(path)
+	if err != nil {
+		return err
+	}
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	tx := w.tx
+	return src.ForEach(func(k, v []byte) error {
+		if v == nil {
+			nested := append(append([][]byte(nil), path...), k)
+			return w.copyBucket(nested, src.Bucket(k))
+		}
+
+		if w.size+len(k)+len(v) > compactTxSize {
+			if err := w.tx.Commit(); err != nil {
+				return err
+			}
+			if err := w.begin(); err != nil {
+				return err
+			}
+		}
+		// The bucket is looked up again once the copy moves on to another
+		// transaction, here or while copying a nested bucket.
+		if w.tx != tx {
+			if dst, err = w.bucket(path); err != nil {
+				return err
+			}
+			tx = w.tx
+		}
+		w.size += len(k) + len(v)
+		return dst.Put(k, v)
+	})
+}
+
+// syncDir flushes the entries of the directory at path, so a file renamed
+// into it survives a crash.
+func syncDir(path string) error {
+	d, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
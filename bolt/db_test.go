@@ -0,0 +1,153 @@
+package bolt_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	bbolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDB_CompactMetadataStore(t *testing.T) {
+	c, closeFn, err := NewTestClient(t)
+	if err != nil {
+		t.Fatalf("unable to create bolt client: %v", err)
+	}
+	defer closeFn()
+
+	ctx := context.Background()
+	store := bolt.NewKVStore(zaptest.NewLogger(t), c.Path)
+	store.WithDB(c.DB())
+
+	value := make([]byte, 1024)
+	put := func(from, to int) {
+		t.Helper()
+		err := store.Update(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("churn"))
+			if err != nil {
+				return err
+			}
+			for i := from; i < to; i++ {
+				if err := b.Put([]byte(fmt.Sprintf("%08d", i)), value); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	put(0, 4096)
+	err = store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("churn"))
+		if err != nil {
+			return err
+		}
+		for i := 10; i < 4096; i++ {
+			if err := b.Delete([]byte(fmt.Sprintf("%08d", i))); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nested buckets and bucket sequences are kept by the copy.
+	err = c.DB().Update(func(tx *bbolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte("parent"))
+		if err != nil {
+			return err
+		}
+		if err := b.SetSequence(42); err != nil {
+			return err
+		}
+		nested, err := b.CreateBucketIfNotExists([]byte("nested"))
+		if err != nil {
+			return err
+		}
+		return nested.Put([]byte("k"), []byte("v"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := c.DB().MetadataStoreStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.FreePageRatio < 0.5 {
+		t.Fatalf("expected most pages to be free before compaction, got ratio %v", before.FreePageRatio)
+	}
+
+	compaction, err := c.DB().CompactMetadataStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compaction.Before != *before {
+		t.Errorf("unexpected stats before compaction: got %+v, want %+v", compaction.Before, *before)
+	}
+	if compaction.After.Size >= before.Size/4 {
+		t.Errorf("expected compaction to shrink the file from %d bytes, got %d", before.Size, compaction.After.Size)
+	}
+
+	after, err := c.DB().MetadataStoreStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Size != compaction.After.Size {
+		t.Errorf("unexpected size after compaction: got %d, want %d", after.Size, compaction.After.Size)
+	}
+
+	// The store keeps working on the compacted file.
+	put(4096, 4097)
+	var n int
+	err = store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("churn"))
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+			n++
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 11 {
+		t.Errorf("expected 11 keys after compaction, got %d", n)
+	}
+
+	err = c.DB().View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket([]byte("parent"))
+		if b == nil {
+			return fmt.Errorf("parent bucket not copied")
+		}
+		if seq := b.Sequence(); seq != 42 {
+			return fmt.Errorf("expected sequence 42, got %d", seq)
+		}
+		nested := b.Bucket([]byte("nested"))
+		if nested == nil {
+			return fmt.Errorf("nested bucket not copied")
+		}
+		if v := nested.Get([]byte("k")); string(v) != "v" {
+			return fmt.Errorf("expected nested value v, got %q", v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -5,9 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
-	"time"
+	"sync"
 
 	bolt "github.com/coreos/bbolt"
 	"github.com/influxdata/influxdb/kit/tracing"
@@ -21,8 +19,13 @@ var _ kv.Store = (*KVStore)(nil)
 // KVStore is a kv.Store backed by boltdb.
 type KVStore struct {
 	path string
-	db   *bolt.DB
+	db   *DB
 	log  *zap.Logger
+
+	// updateMu is held while an update is committed and its changes are
+	// published, so watchers see the changes in the order they are committed.
+	updateMu sync.Mutex
+	watchers kv.Watchers
 }
 
 // NewKVStore returns an instance of KVStore with the file at
@@ -39,17 +42,8 @@ func (s *KVStore) Open(ctx context.Context) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	// Ensure the required directory structure exists.
-	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
-		return fmt.Errorf("unable to create directory %s: %v", s.path, err)
-	}
-
-	if _, err := os.Stat(s.path); err != nil && !os.IsNotExist(err) {
-		return err
-	}
-
 	// Open database file.
-	db, err := bolt.Open(s.path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	db, err := OpenDB(s.log, s.path)
 	if err != nil {
 		return fmt.Errorf("unable to open boltdb file %v", err)
 	}
@@ -95,7 +89,7 @@ func (s *KVStore) cleanBucket(tx *bolt.Tx, b *bolt.Bucket) {
 }
 
 // WithDB sets the boltdb on the store.
-func (s *KVStore) WithDB(db *bolt.DB) {
+func (s *KVStore) WithDB(db *DB) {
 	s.db = db
 }
 
@@ -117,12 +111,29 @@ func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	return s.db.Update(func(tx *bolt.Tx) error {
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	events := &kv.EventRecorder{}
+	err := s.db.Update(func(tx *bolt.Tx) error {
 		return fn(&Tx{
-			tx:  tx,
-			ctx: ctx,
+			tx:     tx,
+			ctx:    ctx,
+			events: events,
 		})
 	})
+	if err != nil {
+		return err
+	}
+
+	s.watchers.Publish(events.Events...)
+	return nil
+}
+
+// Watch returns a channel of the changes committed to the keys of bucket with
+// prefix.
+func (s *KVStore) Watch(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error) {
+	return s.watchers.Watch(ctx, bucket, prefix), nil
 }
 
 // Backup copies all K:Vs to a writer, in BoltDB format.
@@ -140,6 +151,8 @@ func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
 type Tx struct {
 	tx  *bolt.Tx
 	ctx context.Context
+	// events records the changes of an update transaction.
+	events *kv.EventRecorder
 }
 
 // Context returns the context for the transaction.
@@ -153,14 +166,12 @@ func (tx *Tx) WithContext(ctx context.Context) {
 }
 
 // createBucketIfNotExists creates a bucket with the provided byte slice.
-func (tx *Tx) createBucketIfNotExists(b []byte) (*Bucket, error) {
+func (tx *Tx) createBucketIfNotExists(b []byte) (kv.Bucket, error) {
 	bkt, err := tx.tx.CreateBucketIfNotExists(b)
 	if err != nil {
 		return nil, err
 	}
-	return &Bucket{
-		bucket: bkt,
-	}, nil
+	return tx.bucket(b, bkt), nil
 }
 
 // Bucket retrieves the bucket named b.
@@ -169,9 +180,14 @@ func (tx *Tx) Bucket(b []byte) (kv.Bucket, error) {
 	if bkt == nil {
 		return tx.createBucketIfNotExists(b)
 	}
-	return &Bucket{
-		bucket: bkt,
-	}, nil
+	return tx.bucket(b, bkt), nil
+}
+
+func (tx *Tx) bucket(name []byte, bkt *bolt.Bucket) kv.Bucket {
+	if tx.events == nil {
+		return &Bucket{bucket: bkt}
+	}
+	return tx.events.Bucket(name, &Bucket{bucket: bkt})
 }
 
 // Bucket implements kv.Bucket.
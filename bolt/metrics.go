@@ -1,6 +1,7 @@
 package bolt
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -73,6 +74,16 @@ var (
 		"boltdb_reads_total",
 		"Total number of boltdb reads",
 		nil, nil)
+
+	boltSizeDesc = prometheus.NewDesc(
+		"boltdb_size_bytes",
+		"Size of the boltdb file",
+		nil, nil)
+
+	boltFreePageRatioDesc = prometheus.NewDesc(
+		"boltdb_free_page_ratio",
+		"Ratio of the pages of the boltdb file which are free, and reclaimed by compacting it",
+		nil, nil)
 )
 
 // Describe returns all descriptions of the collector.
@@ -87,6 +98,8 @@ func (c *Client) Describe(ch chan<- *prometheus.Desc) {
 	ch <- telegrafPluginsDesc
 	ch <- boltWritesDesc
 	ch <- boltReadsDesc
+	ch <- boltSizeDesc
+	ch <- boltFreePageRatioDesc
 }
 
 type instaTicker struct {
@@ -136,6 +149,20 @@ func (c *Client) Collect(ch chan<- prometheus.Metric) {
 		float64(writes),
 	)
 
+	if st, err := c.db.MetadataStoreStats(context.Background()); err == nil {
+		ch <- prometheus.MustNewConstMetric(
+			boltSizeDesc,
+			prometheus.GaugeValue,
+			float64(st.Size),
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			boltFreePageRatioDesc,
+			prometheus.GaugeValue,
+			st.FreePageRatio,
+		)
+	}
+
 	orgs, buckets, users, tokens := 0, 0, 0, 0
 	dashboards, scrapers, telegrafs := 0, 0, 0
 	_ = c.db.View(func(tx *bolt.Tx) error {
@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"time"
 )
@@ -41,9 +42,110 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	ShardGroupDuration  time.Duration `json:"shardGroupDuration,omitempty"`
+	Precision           string        `json:"precision,omitempty"`
+	CompactionStrategy  string        `json:"compactionStrategy,omitempty"`
+	CacheBudget         int64         `json:"cacheBudgetBytes,omitempty"`
+	MaxSeries           int           `json:"maxSeries,omitempty"`
+	// CacheWriteColdDuration is how long the bucket's data stays in the cache
+	// without being written to before it is snapshotted on its own. Zero
+	// leaves it to the cache snapshot settings of the engine.
+	CacheWriteColdDuration time.Duration `json:"cacheWriteColdDuration,omitempty"`
+	// SchemaType is SchemaTypeExplicit if the points written to the bucket
+	// must conform to its measurement schemas. It is set when the bucket is
+	// created.
+	SchemaType string `json:"schemaType,omitempty"`
+	// MeasurementRetention overrides RetentionPeriod for the data of some
+	// measurements of the bucket.
+	MeasurementRetention []MeasurementRetention `json:"measurementRetention,omitempty"`
+	// Rollups downsample the data written to the bucket into other buckets
+	// as it is written.
+	Rollups []Rollup `json:"rollups,omitempty"`
+	// DeletedAt is the time the bucket was deleted, if it is kept to be
+	// restored until its data is dropped.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// StorageOrgID is the ID of the organization the data of the bucket is
+	// stored under, if the bucket was transferred from it to another
+	// organization. It is not set while the data is stored under OrgID.
+	StorageOrgID ID `json:"storageOrgID,omitempty"`
+	// Metadata holds arbitrary labels of the bucket, as the team or cost
+	// center it belongs to. The values of the keys configured on the storage
+	// engine label the metrics of the bucket.
+	Metadata map[string]string `json:"metadata,omitempty"`
 	CRUDLog
 }
 
+// MeasurementRetention is the retention period of the data of a measurement
+// of a bucket.
+type MeasurementRetention struct {
+	Measurement     string        `json:"measurement"`
+	RetentionPeriod time.Duration `json:"retentionPeriod"`
+}
+
+// Functions aggregating the values of the windows of rollups.
+const (
+	RollupFunctionCount = "count"
+	RollupFunctionSum   = "sum"
+	RollupFunctionMean  = "mean"
+	RollupFunctionMin   = "min"
+	RollupFunctionMax   = "max"
+	RollupFunctionLast  = "last"
+)
+
+// RollupFunctions are the functions aggregating the values of the windows of
+// rollups.
+var RollupFunctions = []string{
+	RollupFunctionCount,
+	RollupFunctionSum,
+	RollupFunctionMean,
+	RollupFunctionMin,
+	RollupFunctionMax,
+	RollupFunctionLast,
+}
+
+// Rollup is a rule downsampling the data written to a bucket into another
+// bucket of its organization. The numeric values of each series field are
+// aggregated over windows of Every, and the aggregate of a window is written
+// to the target bucket, at the start of the window, once it has closed.
+type Rollup struct {
+	// Measurement restricts the rule to the data of a measurement, if it is
+	// not empty.
+	Measurement    string        `json:"measurement,omitempty"`
+	Every          time.Duration `json:"every"`
+	Function       string        `json:"function"`
+	TargetBucketID ID            `json:"targetBucketID"`
+}
+
+// MaxRetentionPeriod returns the longest retention period of the data of the
+// bucket, or InfiniteRetention if some of its data is never expired.
+func (b *Bucket) MaxRetentionPeriod() time.Duration {
+	if b.RetentionPeriod == InfiniteRetention {
+		return InfiniteRetention
+	}
+	max := b.RetentionPeriod
+	for _, mr := range b.MeasurementRetention {
+		if mr.RetentionPeriod > max {
+			max = mr.RetentionPeriod
+		}
+	}
+	return max
+}
+
+// ExplicitSchema returns true if the points written to the bucket must conform
+// to its measurement schemas.
+func (b *Bucket) ExplicitSchema() bool {
+	return b.SchemaType == SchemaTypeExplicit
+}
+
+// DataOrgID returns the ID of the organization the data of the bucket is
+// stored under.
+func (b *Bucket) DataOrgID() ID {
+	if b.StorageOrgID.Valid() {
+		return b.StorageOrgID
+	}
+	return b.OrgID
+}
+
 // BucketType differentiates system buckets from user buckets.
 type BucketType int
 
@@ -72,6 +174,7 @@ var (
 	OpPutBucket      = "PutBucket"
 	OpUpdateBucket   = "UpdateBucket"
 	OpDeleteBucket   = "DeleteBucket"
+	OpTransferBucket = "TransferBucket"
 )
 
 // BucketService represents a service for managing bucket data.
@@ -98,12 +201,87 @@ type BucketService interface {
 	FindBucketByName(ctx context.Context, orgID ID, name string) (*Bucket, error)
 }
 
+// BucketRestoreService restores buckets which were deleted, until their data
+// is dropped at the end of the grace period of deleted buckets. Deleted buckets
+// are listed by FindBuckets with the Deleted filter.
+type BucketRestoreService interface {
+	// FindDeletedBucketByID returns a deleted bucket by ID.
+	FindDeletedBucketByID(ctx context.Context, id ID) (*Bucket, error)
+
+	// RestoreBucket undeletes a deleted bucket. It fails if the organization
+	// of the bucket has another bucket of its name.
+	RestoreBucket(ctx context.Context, id ID) (*Bucket, error)
+}
+
+// BucketTransferService renames buckets and moves them between
+// organizations. The data of a bucket is kept where it is stored, so neither
+// operation copies it.
+type BucketTransferService interface {
+	// RenameBucket gives a bucket another name. It fails if the organization
+	// of the bucket has another bucket of that name.
+	RenameBucket(ctx context.Context, id ID, name string) (*Bucket, error)
+
+	// TransferBucket moves a bucket to the organization of orgID. It fails if
+	// that organization has another bucket of its name.
+	TransferBucket(ctx context.Context, id, orgID ID) (*Bucket, error)
+}
+
+// BucketUsage is the storage used by the data of a bucket.
+type BucketUsage struct {
+	BucketID ID `json:"bucketID"`
+
+	// DiskBytes is the size of the bucket's data in TSM files, and
+	// CacheBytes the size of its data in the cache waiting to be written to
+	// them.
+	DiskBytes  int64 `json:"diskBytes"`
+	CacheBytes int64 `json:"cacheBytes"`
+
+	// Series is the number of series of the bucket.
+	Series int64 `json:"series"`
+
+	// Points is the number of values of the bucket. Values overwritten or
+	// deleted are counted until the files holding them are compacted.
+	Points int64 `json:"points"`
+}
+
+// BucketUsageService finds the storage used by buckets.
+type BucketUsageService interface {
+	FindBucketUsage(ctx context.Context, id ID) (*BucketUsage, error)
+}
+
+// BucketExportCursorPrefix begins the comment lines of a bucket export that
+// hold the cursor to resume the export after the series preceding them.
+const BucketExportCursorPrefix = "# cursor="
+
+// BucketExportService exports the data of buckets.
+type BucketExportService interface {
+	// ExportBucket writes the points of a bucket with times in [start, stop)
+	// to w as line protocol. An export given a cursor resumes after the series
+	// it was read after.
+	ExportBucket(ctx context.Context, id ID, start, stop int64, cursor string, w io.Writer) error
+}
+
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
-	Name            *string        `json:"name,omitempty"`
-	Description     *string        `json:"description,omitempty"`
-	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	Name               *string        `json:"name,omitempty"`
+	Description        *string        `json:"description,omitempty"`
+	RetentionPeriod    *time.Duration `json:"retentionPeriod,omitempty"`
+	ShardGroupDuration *time.Duration `json:"shardGroupDuration,omitempty"`
+	Precision          *string        `json:"precision,omitempty"`
+	CompactionStrategy *string        `json:"compactionStrategy,omitempty"`
+	CacheBudget        *int64         `json:"cacheBudgetBytes,omitempty"`
+	MaxSeries          *int           `json:"maxSeries,omitempty"`
+	// CacheWriteColdDuration replaces the cache write cold duration of the
+	// bucket when it is not nil.
+	CacheWriteColdDuration *time.Duration `json:"cacheWriteColdDuration,omitempty"`
+	// MeasurementRetention replaces the retention periods of measurements
+	// when it is not nil.
+	MeasurementRetention []MeasurementRetention `json:"measurementRetention,omitempty"`
+	// Rollups replaces the rollups of the bucket when it is not nil.
+	Rollups []Rollup `json:"rollups,omitempty"`
+	// Metadata replaces the metadata of the bucket when it is not nil.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -112,6 +290,9 @@ type BucketFilter struct {
 	Name           *string
 	OrganizationID *ID
 	Org            *string
+	// Deleted selects the deleted buckets which are yet to be dropped
+	// rather than the buckets in use.
+	Deleted bool
 }
 
 // QueryParams Converts BucketFilter fields to url query params.
@@ -133,6 +314,10 @@ func (f BucketFilter) QueryParams() map[string][]string {
 		qp["org"] = []string{*f.Org}
 	}
 
+	if f.Deleted {
+		qp["deleted"] = []string{"true"}
+	}
+
 	return qp
 }
 
@@ -0,0 +1,44 @@
+package influxdb
+
+import "context"
+
+// MeasurementCardinality estimates the number of series of a single
+// measurement within a bucket, and the number of values of each of its tag
+// keys, so that the tag responsible for a growth in series can be found.
+//
+// Estimates are made with HyperLogLog sketches as points are written, and
+// include series that have since been deleted from the bucket.
+type MeasurementCardinality struct {
+	OrgID       ID     `json:"orgID"`
+	BucketID    ID     `json:"bucketID"`
+	Measurement string `json:"measurement"`
+
+	// Series is the estimated number of series of the measurement.
+	Series uint64 `json:"series"`
+
+	// Tags holds the estimated number of values of each tag key, ranked in
+	// descending order.
+	Tags []TagKeyCardinality `json:"tags"`
+}
+
+// TagKeyCardinality is the estimated number of values of a tag key.
+type TagKeyCardinality struct {
+	Key    string `json:"key"`
+	Values uint64 `json:"values"`
+}
+
+// CardinalityFilter restricts the results of a cardinality lookup.
+type CardinalityFilter struct {
+	OrgID       *ID
+	BucketID    *ID
+	Measurement string
+
+	// Limit is the maximum number of results returned. Results are ranked by
+	// series in descending order. Zero means no limit.
+	Limit int
+}
+
+// CardinalityService provides estimates of per-measurement series cardinality.
+type CardinalityService interface {
+	FindMeasurementCardinality(ctx context.Context, filter CardinalityFilter) ([]*MeasurementCardinality, error)
+}
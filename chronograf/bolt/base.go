@@ -10,7 +10,7 @@ import (
 var SchemaVersionBucket = []byte("SchemaVersions")
 
 // IsMigrationComplete checks for the presence of a particular migration id
-func IsMigrationComplete(db *bolt.DB, id string) (bool, error) {
+func IsMigrationComplete(db DB, id string) (bool, error) {
 	complete := false
 	if err := db.View(func(tx *bolt.Tx) error {
 		migration := tx.Bucket(SchemaVersionBucket).Get([]byte(id))
@@ -26,7 +26,7 @@ func IsMigrationComplete(db *bolt.DB, id string) (bool, error) {
 }
 
 // MarkMigrationAsComplete adds the migration id to the schema bucket
-func MarkMigrationAsComplete(db *bolt.DB, id string) error {
+func MarkMigrationAsComplete(db DB, id string) error {
 	if err := db.Update(func(tx *bolt.Tx) error {
 		now := time.Now().UTC().Format(time.RFC3339)
 		return tx.Bucket(SchemaVersionBucket).Put([]byte(id), []byte(now))
@@ -51,8 +51,8 @@ func MarkMigrationAsComplete(db *bolt.DB, id string) error {
 // 				development branches that have different schema definitions.
 type Migration struct {
 	ID   string
-	Up   func(db *bolt.DB) error
-	Down func(db *bolt.DB) error
+	Up   func(db DB) error
+	Down func(db DB) error
 }
 
 // Migrate runs one migration's Up() function, if it has not already been run
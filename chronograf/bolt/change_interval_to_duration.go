@@ -26,7 +26,7 @@ func updateDashboard(board *Dashboard) {
 	}
 }
 
-var up = func(db *bolt.DB) error {
+var up = func(db DB) error {
 	// For each dashboard
 	err := db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(dashboardBucket)
@@ -68,7 +68,7 @@ var up = func(db *bolt.DB) error {
 	return nil
 }
 
-var down = func(db *bolt.DB) error {
+var down = func(db DB) error {
 	return nil
 }
 
@@ -13,10 +13,18 @@ import (
 	"github.com/influxdata/influxdb/chronograf/id"
 )
 
+// DB is the database of a client: a bolt database, or a database shared with
+// other services which wraps one.
+type DB interface {
+	View(fn func(*bolt.Tx) error) error
+	Update(fn func(*bolt.Tx) error) error
+	Close() error
+}
+
 // Client is a client for the boltDB data store.
 type Client struct {
 	Path      string
-	db        *bolt.DB
+	db        DB
 	logger    chronograf.Logger
 	isNew     bool
 	Now       func() time.Time
@@ -58,7 +66,7 @@ func NewClient() *Client {
 
 // WithDB sets the boltdb database for a client. It should not be called
 // after a call to Open.
-func (c *Client) WithDB(db *bolt.DB) {
+func (c *Client) WithDB(db DB) {
 	c.db = db
 }
 
@@ -16,7 +16,6 @@ import (
 	"strconv"
 	"time"
 
-	bbolt "github.com/coreos/bbolt"
 	"github.com/influxdata/influxdb/chronograf"
 	"github.com/influxdata/influxdb/chronograf/bolt"
 	idgen "github.com/influxdata/influxdb/chronograf/id"
@@ -426,7 +425,7 @@ func (s *Server) Serve(ctx context.Context) error {
 	return nil
 }
 
-func NewServiceV2(ctx context.Context, d *bbolt.DB) (*Service, error) {
+func NewServiceV2(ctx context.Context, d bolt.DB) (*Service, error) {
 	db := bolt.NewClient()
 	db.WithDB(d)
 
@@ -95,7 +95,7 @@ func TestCmdBucket(t *testing.T) {
 		cmdFn := func(expectedBkt influxdb.Bucket) func(*globalFlags, genericCLIOpts) *cobra.Command {
 			svc := mock.NewBucketService()
 			svc.CreateBucketFn = func(ctx context.Context, bucket *influxdb.Bucket) error {
-				if expectedBkt != *bucket {
+				if !reflect.DeepEqual(expectedBkt, *bucket) {
 					return fmt.Errorf("unexpected bucket;\n\twant= %+v\n\tgot=  %+v", expectedBkt, *bucket)
 				}
 				return nil
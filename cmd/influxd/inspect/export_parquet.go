@@ -0,0 +1,108 @@
+package inspect
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/spf13/cobra"
+)
+
+// exportParquetFlags defines the `export-parquet` Command.
+var exportParquetFlags = struct {
+	orgID, bucketID string
+	start, end      string
+	rowGroupSize    int
+
+	dataDir   string
+	outputDir string
+}{}
+
+func NewExportParquetCommand() *cobra.Command {
+	exportParquetCommand := &cobra.Command{
+		Use:   "export-parquet",
+		Short: "Exports TSM data to Parquet files",
+		Long: `
+This command will export the point data of the TSM files within a storage
+engine directory to Apache Parquet files, for loading into analytics tools.
+
+A file is written for each bucket, measurement and day (UTC) with data, to
+
+	<output-dir>/bucket=<id>/measurement=<name>/day=<yyyy-mm-dd>/data.parquet
+
+Each row holds the values of a series at a single time, with a column for the
+time, each tag key and each field key of the measurement. The data exported
+can be restricted to an organization, a bucket and a time range.`,
+		RunE: inspectExportParquetF,
+	}
+
+	exportParquetCommand.Flags().StringVarP(&exportParquetFlags.orgID, "org-id", "", "", "export only data belonging to organization ID.")
+	exportParquetCommand.Flags().StringVarP(&exportParquetFlags.bucketID, "bucket-id", "", "", "export only data belonging to bucket ID. Requires org flag to be set.")
+	exportParquetCommand.Flags().StringVarP(&exportParquetFlags.start, "start", "", "", "export only data at or after time (RFC3339 format).")
+	exportParquetCommand.Flags().StringVarP(&exportParquetFlags.end, "end", "", "", "export only data at or before time (RFC3339 format).")
+	exportParquetCommand.Flags().IntVarP(&exportParquetFlags.rowGroupSize, "row-group-size", "", 0, "number of rows per Parquet row group.")
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(err)
+	}
+	dir = filepath.Join(dir, "engine/data")
+	exportParquetCommand.Flags().StringVarP(&exportParquetFlags.dataDir, "data-dir", "", dir, fmt.Sprintf("use provided data directory (defaults to %s).", dir))
+	exportParquetCommand.Flags().StringVarP(&exportParquetFlags.outputDir, "output-dir", "", "", "directory to write Parquet files to.")
+
+	return exportParquetCommand
+}
+
+// inspectExportParquetF runs the export-parquet tool.
+func inspectExportParquetF(cmd *cobra.Command, args []string) error {
+	if exportParquetFlags.outputDir == "" {
+		return errors.New("output-dir must be set")
+	}
+	if exportParquetFlags.orgID == "" && exportParquetFlags.bucketID != "" {
+		return errors.New("org-id must be set for non-empty bucket-id")
+	}
+
+	export := tsm1.NewParquetExport(exportParquetFlags.dataDir, exportParquetFlags.outputDir)
+	export.Stdout = os.Stdout
+	export.RowGroupSize = exportParquetFlags.rowGroupSize
+
+	if exportParquetFlags.orgID != "" {
+		orgID, err := influxdb.IDFromString(exportParquetFlags.orgID)
+		if err != nil {
+			return err
+		}
+		export.OrgID = orgID
+	}
+
+	if exportParquetFlags.bucketID != "" {
+		bucketID, err := influxdb.IDFromString(exportParquetFlags.bucketID)
+		if err != nil {
+			return err
+		}
+		export.BucketID = bucketID
+	}
+
+	if exportParquetFlags.start != "" {
+		t, err := time.Parse(time.RFC3339, exportParquetFlags.start)
+		if err != nil {
+			return err
+		}
+		export.Start = t.UnixNano()
+	}
+
+	if exportParquetFlags.end != "" {
+		t, err := time.Parse(time.RFC3339, exportParquetFlags.end)
+		if err != nil {
+			return err
+		}
+		export.End = t.UnixNano()
+	}
+
+	_, err := export.Run()
+	return err
+}
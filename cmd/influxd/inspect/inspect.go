@@ -17,6 +17,7 @@ func NewCommand() *cobra.Command {
 		NewBuildTSICommand(),
 		NewExportBlocksCommand(),
 		NewExportIndexCommand(),
+		NewExportParquetCommand(),
 		NewReportTSMCommand(),
 		NewVerifyTSMCommand(),
 		NewVerifyWALCommand(),
@@ -6,8 +6,10 @@ import (
 	"io/ioutil"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/drain"
 	"github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/kit/prom"
 	"github.com/influxdata/influxdb/models"
@@ -29,10 +31,24 @@ type Engine interface {
 	readservice.Viewer
 	storage.PointsWriter
 	storage.BucketDeleter
+	storage.BucketRangeDeleter
+	storage.BucketSettingsSetter
+	storage.OrgQuotaSetter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	influxdb.WriteStatsService
+	influxdb.ShardStatsService
+	influxdb.CardinalityService
+	drain.Checkpointer
+	influxdb.CompactionService
+	influxdb.SeriesMoveService
+	influxdb.IndexRebuildService
+	influxdb.SnapshotService
+	influxdb.WALRecoveryService
 
 	SeriesCardinality() int64
+	RetentionExpiries() map[influxdb.ID]uint64
+	WriteHints() storage.WriteHints
 
 	WithLogger(log *zap.Logger)
 	Open(context.Context) error
@@ -115,6 +131,18 @@ func (t *TemporaryEngine) SeriesCardinality() int64 {
 	return t.engine.SeriesCardinality()
 }
 
+// RetentionExpiries returns the number of times data has been expired from the
+// buckets of each org.
+func (t *TemporaryEngine) RetentionExpiries() map[influxdb.ID]uint64 {
+	return t.engine.RetentionExpiries()
+}
+
+// DeleteBucketRange will delete the data of a bucket, or of one of its
+// measurements, within the range.
+func (t *TemporaryEngine) DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	return t.engine.DeleteBucketRange(ctx, orgID, bucketID, measurement, min, max)
+}
+
 // DeleteBucketRangePredicate will delete a bucket from the range and predicate.
 func (t *TemporaryEngine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	return t.engine.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
@@ -126,6 +154,79 @@ func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID infl
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
 }
 
+// GroupCardinality estimates the number of groups produced by grouping the
+// series in a bucket by tagKeys.
+func (t *TemporaryEngine) GroupCardinality(ctx context.Context, orgID, bucketID influxdb.ID, tagKeys []string) (int64, int64, error) {
+	return t.engine.GroupCardinality(ctx, orgID, bucketID, tagKeys)
+}
+
+// SetBucketShardGroupDuration sets the shard group duration of a bucket.
+func (t *TemporaryEngine) SetBucketShardGroupDuration(bucketID influxdb.ID, d time.Duration) {
+	t.engine.SetBucketShardGroupDuration(bucketID, d)
+}
+
+// SetBucketRetentionPeriod sets the retention period of a bucket.
+func (t *TemporaryEngine) SetBucketRetentionPeriod(bucketID influxdb.ID, d time.Duration) {
+	t.engine.SetBucketRetentionPeriod(bucketID, d)
+}
+
+// SetBucketPrecision sets the precision of a bucket.
+func (t *TemporaryEngine) SetBucketPrecision(bucketID influxdb.ID, d time.Duration) {
+	t.engine.SetBucketPrecision(bucketID, d)
+}
+
+// SetBucketCompactionStrategy sets the compaction strategy of a bucket.
+func (t *TemporaryEngine) SetBucketCompactionStrategy(bucketID influxdb.ID, name string) {
+	t.engine.SetBucketCompactionStrategy(bucketID, name)
+}
+
+// SetBucketCacheBudget sets the cache budget of a bucket.
+func (t *TemporaryEngine) SetBucketCacheBudget(bucketID influxdb.ID, n uint64) {
+	t.engine.SetBucketCacheBudget(bucketID, n)
+}
+
+// SetBucketCacheWriteColdDuration sets the cache write cold duration of a
+// bucket.
+func (t *TemporaryEngine) SetBucketCacheWriteColdDuration(bucketID influxdb.ID, d time.Duration) {
+	t.engine.SetBucketCacheWriteColdDuration(bucketID, d)
+}
+
+// SetBucketSeriesLimit sets the series limit of a bucket.
+func (t *TemporaryEngine) SetBucketSeriesLimit(bucketID influxdb.ID, n int) {
+	t.engine.SetBucketSeriesLimit(bucketID, n)
+}
+
+// SetBucketRollups sets the rollup rules of a bucket.
+func (t *TemporaryEngine) SetBucketRollups(bucketID influxdb.ID, rules []influxdb.Rollup) {
+	t.engine.SetBucketRollups(bucketID, rules)
+}
+
+// SetBucketStorageOrg sets the organization the data of a bucket is stored
+// under.
+func (t *TemporaryEngine) SetBucketStorageOrg(bucketID, orgID influxdb.ID) {
+	t.engine.SetBucketStorageOrg(bucketID, orgID)
+}
+
+// SetBucketMetadata sets the metadata labelling the metrics of a bucket.
+func (t *TemporaryEngine) SetBucketMetadata(bucketID influxdb.ID, md map[string]string) {
+	t.engine.SetBucketMetadata(bucketID, md)
+}
+
+// SetOrgQuota sets the quota enforced for an organization.
+func (t *TemporaryEngine) SetOrgQuota(q *influxdb.OrgQuota) {
+	t.engine.SetOrgQuota(q)
+}
+
+// CheckBucketQuota calls into the underlying engines CheckBucketQuota.
+func (t *TemporaryEngine) CheckBucketQuota(orgID influxdb.ID, n int) error {
+	return t.engine.CheckBucketQuota(orgID, n)
+}
+
+// AdmitOrgQuery calls into the underlying engines AdmitOrgQuery.
+func (t *TemporaryEngine) AdmitOrgQuery(orgID influxdb.ID) error {
+	return t.engine.AdmitOrgQuery(orgID)
+}
+
 // WithLogger sets the logger on the engine. It must be called before Open.
 func (t *TemporaryEngine) WithLogger(log *zap.Logger) {
 	t.log = log.With(zap.String("service", "temporary_engine"))
@@ -179,3 +280,98 @@ func (t *TemporaryEngine) FetchBackupFile(ctx context.Context, backupID int, bac
 func (t *TemporaryEngine) InternalBackupPath(backupID int) string {
 	return t.engine.InternalBackupPath(backupID)
 }
+
+// FindMeasurementWriteStats calls into the underlying engines FindMeasurementWriteStats.
+func (t *TemporaryEngine) FindMeasurementWriteStats(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+	return t.engine.FindMeasurementWriteStats(ctx, filter)
+}
+
+// BucketUsage calls into the underlying engines BucketUsage.
+func (t *TemporaryEngine) BucketUsage(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.BucketUsage, error) {
+	return t.engine.BucketUsage(ctx, orgID, bucketID)
+}
+
+// ExportBucket calls into the underlying engines ExportBucket.
+func (t *TemporaryEngine) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, start, stop int64, cursor string, w io.Writer) error {
+	return t.engine.ExportBucket(ctx, orgID, bucketID, start, stop, cursor, w)
+}
+
+// FindShardStats calls into the underlying engines FindShardStats.
+func (t *TemporaryEngine) FindShardStats(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error) {
+	return t.engine.FindShardStats(ctx, filter)
+}
+
+// FindMeasurementCardinality calls into the underlying engines FindMeasurementCardinality.
+func (t *TemporaryEngine) FindMeasurementCardinality(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+	return t.engine.FindMeasurementCardinality(ctx, filter)
+}
+
+// Checkpoint calls into the underlying engines Checkpoint.
+func (t *TemporaryEngine) Checkpoint(ctx context.Context) (influxdb.CheckpointID, error) {
+	return t.engine.Checkpoint(ctx)
+}
+
+// CompactionSettings calls into the underlying engines CompactionSettings.
+func (t *TemporaryEngine) CompactionSettings(ctx context.Context) (*influxdb.CompactionSettings, error) {
+	return t.engine.CompactionSettings(ctx)
+}
+
+// UpdateCompactionSettings calls into the underlying engines UpdateCompactionSettings.
+func (t *TemporaryEngine) UpdateCompactionSettings(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+	return t.engine.UpdateCompactionSettings(ctx, upd)
+}
+
+// CompactBucket calls into the underlying engines CompactBucket.
+func (t *TemporaryEngine) CompactBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	return t.engine.CompactBucket(ctx, orgID, bucketID)
+}
+
+// TombstoneStats calls into the underlying engines TombstoneStats.
+func (t *TemporaryEngine) TombstoneStats(ctx context.Context) (*influxdb.TombstoneStats, error) {
+	return t.engine.TombstoneStats(ctx)
+}
+
+// MoveSeries calls into the underlying engines MoveSeries.
+func (t *TemporaryEngine) MoveSeries(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error {
+	return t.engine.MoveSeries(ctx, m, pred)
+}
+
+// FindSeriesMoveByID calls into the underlying engines FindSeriesMoveByID.
+func (t *TemporaryEngine) FindSeriesMoveByID(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error) {
+	return t.engine.FindSeriesMoveByID(ctx, id)
+}
+
+// FindSeriesMoves calls into the underlying engines FindSeriesMoves.
+func (t *TemporaryEngine) FindSeriesMoves(ctx context.Context, filter influxdb.SeriesMoveFilter) ([]*influxdb.SeriesMove, error) {
+	return t.engine.FindSeriesMoves(ctx, filter)
+}
+
+// RebuildIndex calls into the underlying engines RebuildIndex.
+func (t *TemporaryEngine) RebuildIndex(ctx context.Context, r *influxdb.IndexRebuild) error {
+	return t.engine.RebuildIndex(ctx, r)
+}
+
+// FindIndexRebuildByID calls into the underlying engines FindIndexRebuildByID.
+func (t *TemporaryEngine) FindIndexRebuildByID(ctx context.Context, id influxdb.ID) (*influxdb.IndexRebuild, error) {
+	return t.engine.FindIndexRebuildByID(ctx, id)
+}
+
+// FindIndexRebuilds calls into the underlying engines FindIndexRebuilds.
+func (t *TemporaryEngine) FindIndexRebuilds(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error) {
+	return t.engine.FindIndexRebuilds(ctx, filter)
+}
+
+// CreateSnapshot calls into the underlying engines CreateSnapshot.
+func (t *TemporaryEngine) CreateSnapshot(ctx context.Context) (*influxdb.SnapshotManifest, error) {
+	return t.engine.CreateSnapshot(ctx)
+}
+
+// WALRecoveryReport calls into the underlying engines WALRecoveryReport.
+func (t *TemporaryEngine) WALRecoveryReport(ctx context.Context) (*influxdb.WALRecoveryReport, error) {
+	return t.engine.WALRecoveryReport(ctx)
+}
+
+// WriteHints calls into the underlying engines WriteHints.
+func (t *TemporaryEngine) WriteHints() storage.WriteHints {
+	return t.engine.WriteHints()
+}
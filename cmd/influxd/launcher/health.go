@@ -0,0 +1,84 @@
+package launcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/kv"
+)
+
+// compactionBacklogThreshold is the number of TSM files of a shard waiting
+// to be compacted above which the storage engine is degraded.
+const compactionBacklogThreshold = 64
+
+// kvCheck checks that the kv store can be read.
+func kvCheck(store kv.Store) check.Checker {
+	return check.NamedFunc("kv", func(ctx context.Context) check.Response {
+		if err := store.View(ctx, func(kv.Tx) error { return nil }); err != nil {
+			return check.Fail("unavailable", "kv store cannot be read: %v", err)
+		}
+		return check.Pass()
+	})
+}
+
+// engineCheck checks that the storage engine is open, and reports the
+// shards it has open. The engine is degraded while a shard falls behind on
+// its compactions.
+func engineCheck(s influxdb.ShardStatsService) check.Checker {
+	return check.NamedFunc("storage-engine", func(ctx context.Context) check.Response {
+		stats, err := s.FindShardStats(ctx, influxdb.ShardStatsFilter{SortBy: influxdb.ShardStatsSortCompactions})
+		if err != nil {
+			return check.Fail("unavailable", "storage engine is unavailable: %v", err)
+		}
+		if len(stats) > 0 && stats[0].CompactionBacklog > compactionBacklogThreshold {
+			return check.Warn("compaction-backlog", "bucket %s has %d TSM files waiting to be compacted", stats[0].BucketID, stats[0].CompactionBacklog)
+		}
+		return check.Info("%d shards open", len(stats))
+	})
+}
+
+// walCheck checks that the WAL has been replayed. The storage engine is
+// degraded if data was lost replaying it, until it is reopened.
+func walCheck(s influxdb.WALRecoveryService) check.Checker {
+	return check.NamedFunc("wal", func(ctx context.Context) check.Response {
+		report, err := s.WALRecoveryReport(ctx)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return check.Info("WAL is disabled")
+		} else if err != nil {
+			return check.Fail("unavailable", "WAL is unavailable: %v", err)
+		}
+
+		switch {
+		case report.CompletedAt.IsZero():
+			return check.Fail("replaying", "replaying %d WAL segments", len(report.Segments))
+		case report.Error != "":
+			return check.Fail("replay-failed", "WAL replay failed: %s", report.Error)
+		}
+
+		var dropped int
+		for _, b := range report.Buckets {
+			dropped += b.PointsDropped
+		}
+		if dropped > 0 {
+			return check.Warn("points-dropped", "%d points were dropped replaying the WAL", dropped)
+		}
+		return check.Info("replayed %d WAL segments in %s", len(report.Segments), report.CompletedAt.Sub(report.StartedAt).Round(time.Millisecond))
+	})
+}
+
+// drainCheck checks that the server is not draining, as a draining server
+// refuses writes and queries.
+func drainCheck(s influxdb.DrainService) check.Checker {
+	return check.NamedFunc("drain", func(ctx context.Context) check.Response {
+		status, err := s.DrainStatus(ctx)
+		if err != nil {
+			return check.Error(err)
+		}
+		if status.State != influxdb.DrainStateActive {
+			return check.Fail(string(status.State), "server is %s", status.State)
+		}
+		return check.Pass()
+	})
+}
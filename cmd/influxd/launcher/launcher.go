@@ -17,11 +17,15 @@ import (
 
 	"github.com/influxdata/flux"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/audit"
 	"github.com/influxdata/influxdb/authorizer"
+	"github.com/influxdata/influxdb/awssecrets"
 	"github.com/influxdata/influxdb/bolt"
 	"github.com/influxdata/influxdb/chronograf/server"
 	"github.com/influxdata/influxdb/cmd/influxd/inspect"
+	"github.com/influxdata/influxdb/drain"
 	"github.com/influxdata/influxdb/endpoints"
+	"github.com/influxdata/influxdb/etcd"
 	"github.com/influxdata/influxdb/gather"
 	"github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/inmem"
@@ -32,13 +36,20 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/ldap"
+	"github.com/influxdata/influxdb/lifecycle"
 	influxlogger "github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/materialized"
 	"github.com/influxdata/influxdb/nats"
 	"github.com/influxdata/influxdb/pkger"
+	"github.com/influxdata/influxdb/postgres"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/cache"
 	"github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/slowlog"
 	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/secret"
 	"github.com/influxdata/influxdb/snowflake"
 	"github.com/influxdata/influxdb/source"
 	"github.com/influxdata/influxdb/storage"
@@ -50,8 +61,10 @@ import (
 	"github.com/influxdata/influxdb/task/backend/middleware"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
 	"github.com/influxdata/influxdb/telemetry"
+	"github.com/influxdata/influxdb/tokens"
+	"github.com/influxdata/influxdb/toml"
 	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/influxdata/influxdb/vault"
 	pzap "github.com/influxdata/influxdb/zap"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -67,6 +80,11 @@ const (
 	BoltStore = "bolt"
 	// MemoryStore stores all REST resources in memory (useful for testing).
 	MemoryStore = "memory"
+	// PostgresStore stores all REST resources in PostgreSQL.
+	PostgresStore = "postgres"
+	// EtcdStore stores all REST resources in etcd, to be shared by several
+	// instances.
+	EtcdStore = "etcd"
 
 	// LogTracing enables tracing via zap logs
 	LogTracing = "log"
@@ -120,6 +138,8 @@ func NewCommand() *cobra.Command {
 
 var vaultConfig vault.Config
 
+var awsSecretsConfig awssecrets.Config
+
 func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 	dir, err := fs.InfluxDir()
 	if err != nil {
@@ -145,6 +165,21 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: ":9999",
 			Desc:    "bind address for the REST HTTP API",
 		},
+		{
+			DestP: &l.httpWriteMaxBodyBytes,
+			Flag:  "http-write-max-body-bytes",
+			Desc:  "maximum number of bytes of the decompressed body of a write request; 0 means no limit",
+		},
+		{
+			DestP: &l.httpWriteMaxLines,
+			Flag:  "http-write-max-lines",
+			Desc:  "maximum number of lines of line protocol in a write request; 0 means no limit",
+		},
+		{
+			DestP: &l.httpWriteMaxConcurrency,
+			Flag:  "http-write-max-concurrency",
+			Desc:  "maximum number of write requests handled at once, beyond which writes are rejected with 429; 0 means no limit",
+		},
 		{
 			DestP:   &l.boltPath,
 			Flag:    "bolt-path",
@@ -160,7 +195,29 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			DestP:   &l.storeType,
 			Flag:    "store",
 			Default: "bolt",
-			Desc:    "backing store for REST resources (bolt or memory)",
+			Desc:    "backing store for REST resources (bolt, memory, postgres or etcd)",
+		},
+		{
+			DestP: &l.postgresDSN,
+			Flag:  "postgres-dsn",
+			Desc:  "connection string of the PostgreSQL database storing REST resources when the store is postgres",
+		},
+		{
+			DestP: &l.etcdEndpoints,
+			Flag:  "etcd-endpoints",
+			Desc:  "endpoints of the etcd cluster storing REST resources when the store is etcd",
+		},
+		{
+			DestP:   &l.etcdPrefix,
+			Flag:    "etcd-prefix",
+			Default: etcd.DefaultPrefix,
+			Desc:    "prefix of the etcd keys of REST resources; instances sharing their resources use the same prefix",
+		},
+		{
+			DestP:   &l.etcdMaxTxnOps,
+			Flag:    "etcd-max-txn-ops",
+			Default: etcd.DefaultMaxTxnOps,
+			Desc:    "maximum number of operations of an etcd transaction, as configured on the etcd cluster; updates writing more keys fail",
 		},
 		{
 			DestP:   &l.testing,
@@ -178,7 +235,13 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
 			Default: "bolt",
-			Desc:    "data store for secrets (bolt or vault)",
+			Desc:    "data store for secrets (bolt, vault or aws)",
+		},
+		{
+			DestP:   &l.secretCacheTTL,
+			Flag:    "secret-cache-ttl",
+			Default: time.Minute,
+			Desc:    "how long secrets read from a vault or aws secret store are cached. 0 disables caching",
 		},
 		{
 			DestP:   &l.reportingDisabled,
@@ -186,6 +249,114 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disable sending telemetry data to https://telemetry.influxdata.com every 8 hours",
 		},
+		{
+			DestP:   &l.queryRejectOutsideRetention,
+			Flag:    "query-reject-outside-retention",
+			Default: false,
+			Desc:    "fail queries whose range starts before the retention period of their bucket, rather than annotating the effective range",
+		},
+		{
+			DestP: &l.querySpillDir,
+			Flag:  "query-spill-dir",
+			Desc:  "directory that queries spill series to when sorting them for grouping, and tables buffered by joins to; defaults to the system temporary directory",
+		},
+		{
+			DestP:   &l.querySpillMemoryBytes,
+			Flag:    "query-spill-memory-bytes",
+			Default: int(reads.DefaultSpillMemoryBytes),
+			Desc:    "estimated size in bytes of the series a query sorts, or the tables a join buffers, in memory before spilling them to disk; 0 disables spilling",
+		},
+		{
+			DestP:   &l.querySpillMaxTempBytes,
+			Flag:    "query-spill-max-temp-bytes",
+			Default: int(reads.DefaultSpillMaxTempBytes),
+			Desc:    "maximum size in bytes of the temporary files of each query or join, beyond which it fails; 0 disables the limit",
+		},
+		{
+			DestP:   &l.queryCacheTTL,
+			Flag:    "query-cache-ttl",
+			Default: time.Duration(0),
+			Desc:    "how long to cache the results of queries reading buckets, which are run as of the start of each TTL window; 0 disables the cache",
+		},
+		{
+			DestP:   &l.queryCacheMaxBytes,
+			Flag:    "query-cache-max-bytes",
+			Default: 64 * 1024 * 1024,
+			Desc:    "maximum size in bytes of the cached results of queries",
+		},
+		{
+			DestP:   &l.slowQueryLogDuration,
+			Flag:    "slow-query-log-duration",
+			Default: time.Duration(0),
+			Desc:    "record queries taking at least this long in their org's _monitoring bucket; 0 disables the threshold",
+		},
+		{
+			DestP:   &l.slowQueryLogScannedValues,
+			Flag:    "slow-query-log-scanned-values",
+			Default: 0,
+			Desc:    "record queries reading at least this many values from storage in their org's _monitoring bucket; 0 disables the threshold",
+		},
+		{
+			DestP:   &l.slowQueryLogScannedBytes,
+			Flag:    "slow-query-log-scanned-bytes",
+			Default: 0,
+			Desc:    "record queries reading at least this many bytes from storage in their org's _monitoring bucket; 0 disables the threshold",
+		},
+		{
+			DestP:   &l.lifecycleReportInterval,
+			Flag:    "lifecycle-report-interval",
+			Default: time.Duration(0),
+			Desc:    "how often to write a data lifecycle report for each org to its _monitoring bucket; 0 disables reports",
+		},
+		{
+			DestP: &l.lifecycleReportWebhookURL,
+			Flag:  "lifecycle-report-webhook-url",
+			Desc:  "URL to post a JSON digest of every org's lifecycle report to",
+		},
+		{
+			DestP:   &l.materializedViewInterval,
+			Flag:    "materialized-view-interval",
+			Default: 10 * time.Second,
+			Desc:    "how often to materialize the windows of materialized views that have ended; 0 disables materialization",
+		},
+		{
+			DestP:   &l.tokenStaleAfter,
+			Flag:    "token-stale-after",
+			Default: 90 * 24 * time.Hour,
+			Desc:    "flag tokens that have not been used for this long as stale; 0 disables flagging",
+		},
+		{
+			DestP: &l.auditLogFile,
+			Flag:  "audit-log-file",
+			Desc:  "file to append the events of the audit log to, as JSON lines",
+		},
+		{
+			DestP: &l.auditLogSyslog,
+			Flag:  "audit-log-syslog",
+			Desc:  "syslog server to send the events of the audit log to, as udp://host:port or tcp://host:port, or local for the local syslog daemon",
+		},
+		{
+			DestP:   &l.systemBuckets.TasksRetention,
+			Flag:    "tasks-bucket-retention",
+			Default: platform.TasksSystemBucketRetention,
+			Desc:    "retention period of the _tasks system bucket created with each organization",
+		},
+		{
+			DestP:   &l.systemBuckets.MonitoringRetention,
+			Flag:    "monitoring-bucket-retention",
+			Default: platform.MonitoringSystemBucketRetention,
+			Desc:    "retention period of the _monitoring system bucket created with each organization",
+		},
+		{
+			DestP: &l.systemBuckets.ShardGroupDuration,
+			Flag:  "system-bucket-shard-group-duration",
+			Desc:  "shard group duration of the system buckets created with each organization; 0 disables partitioning their data into shard groups",
+		},
+		{
+			DestP: &l.systemBuckets.MaxSeries,
+			Flag:  "system-bucket-max-series",
+			Desc:  "maximum number of series of each system bucket created with each organization; 0 uses the engine-wide limit",
+		},
 		{
 			DestP:   &l.sessionLength,
 			Flag:    "session-length",
@@ -198,6 +369,24 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.sessionIdleTimeout,
+			Flag:    "session-idle-timeout",
+			Default: platform.RenewSessionTime,
+			Desc:    "how long sessions last after they are last used, when they are extended on request",
+		},
+		{
+			DestP:   &l.sessionMaxLength,
+			Flag:    "session-max-length",
+			Default: time.Duration(0),
+			Desc:    "how long sessions may be extended for after they are created. 0 lets sessions be extended indefinitely",
+		},
+		{
+			DestP:   &l.signedWriteMaxSkew,
+			Flag:    "signed-write-max-skew",
+			Default: http.DefaultSignedRequestMaxSkew,
+			Desc:    "how far the timestamp of writes signed with a token may be from the time of the server. 0 disables signed writes",
+		},
 		{
 			DestP: &vaultConfig.Address,
 			Flag:  "vault-addr",
@@ -248,6 +437,260 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Flag:  "vault-token",
 			Desc:  "vault authentication token",
 		},
+		{
+			DestP: &awsSecretsConfig.Region,
+			Flag:  "aws-secrets-region",
+			Desc:  "region of AWS Secrets Manager. Defaults to the region of the shared AWS configuration.",
+		},
+		{
+			DestP: &awsSecretsConfig.Endpoint,
+			Flag:  "aws-secrets-endpoint",
+			Desc:  "endpoint of AWS Secrets Manager, to use instead of the endpoint of its region.",
+		},
+		{
+			DestP:   &awsSecretsConfig.Prefix,
+			Flag:    "aws-secrets-prefix",
+			Default: awssecrets.DefaultPrefix,
+			Desc:    "prefix of the names of the AWS secrets holding the secrets of organizations.",
+		},
+		{
+			DestP: &awsSecretsConfig.KMSKeyID,
+			Flag:  "aws-secrets-kms-key-id",
+			Desc:  "KMS key the AWS secrets are encrypted with. Defaults to the default key of the account.",
+		},
+		{
+			DestP:   &l.drainTimeout,
+			Flag:    "shutdown-drain-timeout",
+			Default: time.Duration(0),
+			Desc:    "time to wait for in-flight writes and queries before checkpointing storage on shutdown; 0 disables draining",
+		},
+		{
+			DestP:   &l.bucketDeleteGracePeriod,
+			Flag:    "bucket-delete-grace-period",
+			Default: time.Duration(0),
+			Desc:    "how long deleted buckets are kept to be restored before their data is dropped; 0 drops the data of a bucket when it is deleted",
+		},
+		{
+			DestP: &l.StorageConfig.Engine.Tiering.URL,
+			Flag:  "storage-tiering-url",
+			Desc:  "object store to offload cold TSM files to, such as s3://bucket/prefix, gs://bucket/prefix or azblob://account/container",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.Engine.Tiering.ColdDuration),
+			Flag:    "storage-tiering-cold-duration",
+			Default: time.Duration(tsm1.DefaultTieringColdDuration),
+			Desc:    "how long after their newest point fully compacted TSM files are offloaded to the object store",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.Engine.Tiering.FetchTimeout),
+			Flag:    "storage-tiering-fetch-timeout",
+			Default: time.Duration(tsm1.DefaultTieringFetchTimeout),
+			Desc:    "maximum duration of fetching a block of an offloaded TSM file from the object store; 0 disables the timeout",
+		},
+		{
+			DestP:   &l.StorageConfig.Engine.Compaction.MaxConcurrentFull,
+			Flag:    "storage-compact-max-concurrent-full",
+			Default: 0,
+			Desc:    "maximum number of full and optimize compactions that can run at once; 0 limits them only by the maximum number of concurrent compactions",
+		},
+		{
+			DestP:   &l.compactThroughput,
+			Flag:    "storage-compact-throughput",
+			Default: int(tsm1.DefaultCompactThroughput),
+			Desc:    "rate in bytes per second that compactions may write to disk; 0 disables rate limiting",
+		},
+		{
+			DestP:   &l.compactThroughputBurst,
+			Flag:    "storage-compact-throughput-burst",
+			Default: int(tsm1.DefaultCompactThroughputBurst),
+			Desc:    "number of bytes compactions may write at once above the throughput limit",
+		},
+		{
+			DestP: &l.StorageConfig.Engine.Compaction.QuietHours,
+			Flag:  "storage-compact-quiet-hours",
+			Desc:  "windows of the day in local time, such as 09:00-17:00, when only level 1 and 2 compactions are started",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.WAL.FsyncDelay),
+			Flag:    "storage-wal-fsync-delay",
+			Default: tsm1.DefaultWALFsyncDelay,
+			Desc:    "how long writes to the WAL wait before they are fsynced, so that the writes of that time share an fsync; 0 fsyncs every write",
+		},
+		{
+			DestP:   &l.walFsyncMaxBatchBytes,
+			Flag:    "storage-wal-fsync-max-batch-bytes",
+			Default: tsm1.DefaultWALFsyncMaxBatchBytes,
+			Desc:    "number of bytes written to the WAL that are fsynced without waiting for the fsync delay; 0 disables the limit",
+		},
+		{
+			DestP: (*time.Duration)(&l.StorageConfig.FutureWriteTolerance),
+			Flag:  "storage-future-write-tolerance",
+			Desc:  "how far into the future points can be written; 0 accepts points at any time",
+		},
+		{
+			DestP: &l.StorageConfig.MaxSeriesPerBucket,
+			Flag:  "storage-max-series-per-bucket",
+			Desc:  "maximum number of series in a bucket; points that would create more are dropped; 0 disables the limit",
+		},
+		{
+			DestP: &l.StorageConfig.MaxValuesPerTag,
+			Flag:  "storage-max-values-per-tag",
+			Desc:  "maximum number of values of a tag key in a bucket; points that would add more are dropped; 0 disables the limit",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.IdempotencyKeyTTL),
+			Flag:    "storage-idempotency-key-ttl",
+			Default: storage.DefaultIdempotencyKeyTTL,
+			Desc:    "how long the Idempotency-Key of a write is remembered; retried batches with the key are dropped; 0 disables idempotency keys",
+		},
+		{
+			DestP:   &l.StorageConfig.MaxIdempotencyKeys,
+			Flag:    "storage-max-idempotency-keys",
+			Default: storage.DefaultMaxIdempotencyKeys,
+			Desc:    "maximum number of write idempotency keys remembered; the oldest are forgotten first; 0 disables the limit",
+		},
+		{
+			DestP: (*time.Duration)(&l.StorageConfig.DedupeWindow),
+			Flag:  "storage-dedupe-window",
+			Desc:  "how long written field values are remembered; points whose field values are those last written to the same series, fields and time within the window are dropped; 0 disables deduplication",
+		},
+		{
+			DestP:   &l.StorageConfig.MaxDedupePoints,
+			Flag:    "storage-max-dedupe-points",
+			Default: storage.DefaultMaxDedupePoints,
+			Desc:    "maximum number of field values remembered for deduplication; the oldest are forgotten first; 0 disables the limit",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.RollupDelay),
+			Flag:    "storage-rollup-delay",
+			Default: storage.DefaultRollupDelay,
+			Desc:    "how long after the end of a window of a bucket rollup its aggregate is written; later points are not rolled up",
+		},
+		{
+			DestP: &l.StorageConfig.BucketMetricLabels,
+			Flag:  "storage-bucket-metric-labels",
+			Desc:  "keys of bucket metadata whose values label the per-bucket storage metrics, e.g. team,cost-center",
+		},
+		{
+			DestP: &l.StorageConfig.EncryptionKeyFile,
+			Flag:  "storage-encryption-key-file",
+			Desc:  "path to a file of encryption keys used to encrypt TSM and WAL data at rest; the key with the highest ID encrypts new data",
+		},
+		{
+			DestP: &l.StorageConfig.FaultInjection,
+			Flag:  "storage-fault-injection",
+			Desc:  "faults to inject into storage file operations for testing, e.g. wal-sync:error@0.01,tsm-read:corrupt; never use with data you want to keep",
+		},
+		{
+			DestP: &l.StorageConfig.ReadOnly,
+			Flag:  "storage-read-only",
+			Desc:  "open storage files read-only and reject writes, deletes and compactions, for serving reads from a snapshot or replicated volume",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.ReadOnlyRefreshInterval),
+			Flag:    "storage-read-only-refresh-interval",
+			Default: time.Duration(storage.DefaultReadOnlyRefreshInterval),
+			Desc:    "how often read-only storage checks its files for external changes and reopens them; 0 disables refreshing",
+		},
+		{
+			DestP: &l.ReadServiceConfig.BindAddress,
+			Flag:  "storage-grpc-bind-address",
+			Desc:  "bind address for the gRPC storage read service; empty disables the service",
+		},
+		{
+			DestP: &l.ReadServiceConfig.TLSCert,
+			Flag:  "storage-grpc-tls-cert",
+			Desc:  "TLS certificate of the gRPC storage read service; required to serve",
+		},
+		{
+			DestP: &l.ReadServiceConfig.TLSKey,
+			Flag:  "storage-grpc-tls-key",
+			Desc:  "TLS key of the gRPC storage read service; required to serve",
+		},
+		{
+			DestP: &l.ReadServiceConfig.MaxConcurrentStreams,
+			Flag:  "storage-grpc-max-concurrent-streams",
+			Desc:  "maximum number of concurrent read streams of each gRPC connection; 0 disables the limit",
+		},
+		{
+			DestP:   &l.ReadServiceConfig.MaxRecvMsgSize,
+			Flag:    "storage-grpc-max-recv-msg-size",
+			Default: readservice.DefaultGRPCMaxRecvMsgSize,
+			Desc:    "maximum size in bytes of gRPC read request messages",
+		},
+		{
+			DestP:   &l.ReadServiceConfig.MaxSendMsgSize,
+			Flag:    "storage-grpc-max-send-msg-size",
+			Default: readservice.DefaultGRPCMaxSendMsgSize,
+			Desc:    "maximum size in bytes of gRPC read response messages",
+		},
+		{
+			DestP: &l.ReadServiceConfig.InitialWindowSize,
+			Flag:  "storage-grpc-initial-window-size",
+			Desc:  "HTTP/2 flow control window in bytes of each gRPC stream; 0 sizes the window dynamically, otherwise at least 65536",
+		},
+		{
+			DestP: &l.ReadServiceConfig.InitialConnWindowSize,
+			Flag:  "storage-grpc-initial-conn-window-size",
+			Desc:  "HTTP/2 flow control window in bytes of each gRPC connection; 0 sizes the window dynamically, otherwise at least 65536",
+		},
+		{
+			DestP:   &l.ReadServiceConfig.WriteBufferSize,
+			Flag:    "storage-grpc-write-buffer-size",
+			Default: readservice.DefaultGRPCWriteBufferSize,
+			Desc:    "size in bytes of the write buffer of each gRPC connection; 0 writes frames as they are sent",
+		},
+		{
+			DestP:   &l.ReadServiceConfig.ReadBufferSize,
+			Flag:    "storage-grpc-read-buffer-size",
+			Default: readservice.DefaultGRPCReadBufferSize,
+			Desc:    "size in bytes of the read buffer of each gRPC connection",
+		},
+		{
+			DestP:   &l.ReadServiceConfig.MaxHeaderListSize,
+			Flag:    "storage-grpc-max-header-list-size",
+			Default: readservice.DefaultGRPCMaxHeaderListSize,
+			Desc:    "maximum size in bytes of the HTTP/2 headers of a gRPC read request",
+		},
+		{
+			DestP:   (*time.Duration)(&l.ReadServiceConfig.KeepaliveTime),
+			Flag:    "storage-grpc-keepalive-time",
+			Default: readservice.DefaultGRPCKeepaliveTime,
+			Desc:    "how long a gRPC connection is idle before the server pings the client; at least 1s",
+		},
+		{
+			DestP:   (*time.Duration)(&l.ReadServiceConfig.KeepaliveTimeout),
+			Flag:    "storage-grpc-keepalive-timeout",
+			Default: readservice.DefaultGRPCKeepaliveTimeout,
+			Desc:    "how long the server waits for a reply to a keepalive ping before closing the gRPC connection",
+		},
+		{
+			DestP:   (*time.Duration)(&l.ReadServiceConfig.KeepaliveMinTime),
+			Flag:    "storage-grpc-keepalive-min-time",
+			Default: readservice.DefaultGRPCKeepaliveMinTime,
+			Desc:    "minimum time between keepalive pings from gRPC clients; clients pinging more often are disconnected",
+		},
+		{
+			DestP:   &l.ReadServiceConfig.KeepalivePermitWithoutStream,
+			Flag:    "storage-grpc-keepalive-permit-without-stream",
+			Default: readservice.DefaultGRPCKeepalivePermitWithoutStream,
+			Desc:    "allow gRPC clients to send keepalive pings when they have no active read streams",
+		},
+		{
+			DestP: (*time.Duration)(&l.ReadServiceConfig.MaxConnectionIdle),
+			Flag:  "storage-grpc-max-connection-idle",
+			Desc:  "how long a gRPC connection may be idle before it is closed; 0 disables the limit",
+		},
+		{
+			DestP: (*time.Duration)(&l.ReadServiceConfig.MaxConnectionAge),
+			Flag:  "storage-grpc-max-connection-age",
+			Desc:  "how long a gRPC connection may be open before it is closed; 0 disables the limit",
+		},
+		{
+			DestP: (*time.Duration)(&l.ReadServiceConfig.MaxConnectionAgeGrace),
+			Flag:  "storage-grpc-max-connection-age-grace",
+			Desc:  "how long the read streams of a gRPC connection closed for its age have to complete; 0 waits for them",
+		},
 		{
 			DestP:   &l.httpTLSCert,
 			Flag:    "tls-cert",
@@ -274,10 +717,18 @@ type Launcher struct {
 	running bool
 
 	storeType            string
+	postgresDSN          string
+	etcdEndpoints        []string
+	etcdPrefix           string
+	etcdMaxTxnOps        int
 	assetsPath           string
 	testing              bool
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
+	sessionIdleTimeout   time.Duration
+	sessionMaxLength     time.Duration
+	signedWriteMaxSkew   time.Duration
+	systemBuckets        kv.SystemBucketsConfig
 
 	logLevel          string
 	tracingType       string
@@ -287,12 +738,57 @@ type Launcher struct {
 	boltPath        string
 	enginePath      string
 	secretStore     string
+	secretCacheTTL  time.Duration
+
+	httpWriteMaxBodyBytes   int
+	httpWriteMaxLines       int
+	httpWriteMaxConcurrency int
+
+	drainTimeout time.Duration
+	drainService *drain.Service
+
+	bucketDeleteGracePeriod time.Duration
+
+	queryRejectOutsideRetention bool
+
+	querySpillDir          string
+	querySpillMemoryBytes  int
+	querySpillMaxTempBytes int
+
+	queryCacheTTL      time.Duration
+	queryCacheMaxBytes int
+
+	slowQueryLogDuration      time.Duration
+	slowQueryLogScannedValues int
+	slowQueryLogScannedBytes  int
+
+	lifecycleReportInterval   time.Duration
+	lifecycleReportWebhookURL string
+
+	materializedViewInterval time.Duration
+
+	tokenStaleAfter time.Duration
+
+	auditLogFile   string
+	auditLogSyslog string
+	auditLog       *audit.Service
+
+	compactThroughput      int
+	compactThroughputBurst int
+
+	walFsyncMaxBatchBytes int
 
 	boltClient    *bolt.Client
+	postgresStore *postgres.KVStore
+	etcdStore     *etcd.KVStore
+	kvStore       kv.Store
 	kvService     *kv.Service
 	engine        Engine
 	StorageConfig storage.Config
 
+	ReadServiceConfig readservice.GRPCConfig
+	readServer        *readservice.Server
+
 	queryController *control.Controller
 
 	httpPort    int
@@ -324,6 +820,8 @@ func NewLauncher() *Launcher {
 		Stdout:        os.Stdout,
 		Stderr:        os.Stderr,
 		StorageConfig: storage.NewConfig(),
+
+		ReadServiceConfig: readservice.NewGRPCConfig(),
 	}
 }
 
@@ -364,7 +862,12 @@ func (m *Launcher) Engine() Engine {
 }
 
 // Shutdown shuts down the HTTP server and waits for all services to clean up.
+// If a drain timeout is configured, the server is drained first.
 func (m *Launcher) Shutdown(ctx context.Context) {
+	if m.drainTimeout > 0 && m.drainService != nil {
+		m.drain()
+	}
+
 	m.httpServer.Shutdown(ctx)
 
 	m.log.Info("Stopping", zap.String("service", "task"))
@@ -374,16 +877,44 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
+	if m.auditLog != nil {
+		m.log.Info("Stopping", zap.String("service", "audit"))
+		if err := m.auditLog.Close(); err != nil {
+			m.log.Info("Failed closing audit log exporters", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "bolt"))
 	if err := m.boltClient.Close(); err != nil {
 		m.log.Info("Failed closing bolt", zap.Error(err))
 	}
 
+	if m.postgresStore != nil {
+		m.log.Info("Stopping", zap.String("service", "postgres"))
+		if err := m.postgresStore.Close(); err != nil {
+			m.log.Info("Failed closing postgres", zap.Error(err))
+		}
+	}
+
+	if m.etcdStore != nil {
+		m.log.Info("Stopping", zap.String("service", "etcd"))
+		if err := m.etcdStore.Close(); err != nil {
+			m.log.Info("Failed closing etcd", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "query"))
 	if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
 		m.log.Info("Failed closing query service", zap.Error(err))
 	}
 
+	if m.readServer != nil {
+		m.log.Info("Stopping", zap.String("service", "storage-grpc"))
+		if err := m.readServer.Close(); err != nil {
+			m.log.Info("Failed closing storage read service", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "storage-engine"))
 	if err := m.engine.Close(); err != nil {
 		m.log.Error("Failed to close engine", zap.Error(err))
@@ -400,6 +931,29 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 	m.log.Sync()
 }
 
+// drain drains in-flight writes and queries and checkpoints the storage engine,
+// so the WAL does not need to be replayed on the next start.
+func (m *Launcher) drain() {
+	m.log.Info("Stopping", zap.String("service", "drain"))
+
+	// The shutdown context has usually been cancelled by the time Shutdown is
+	// called, so the drain is bounded by its own timeout. The checkpoint is
+	// given as long again to complete.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*m.drainTimeout)
+	defer cancel()
+
+	if _, err := m.drainService.Drain(ctx, m.drainTimeout); err != nil {
+		m.log.Error("Failed to drain", zap.Error(err))
+		return
+	}
+	status, err := m.drainService.Wait(ctx)
+	if err != nil {
+		m.log.Error("Failed to wait for drain", zap.Error(err))
+	} else if status.Err != "" {
+		m.log.Error("Failed to checkpoint storage while draining", zap.String("error", status.Err))
+	}
+}
+
 // Cancel executes the context cancel on the program. Used for testing.
 func (m *Launcher) Cancel() { m.cancel() }
 
@@ -419,6 +973,26 @@ func (m *Launcher) Run(ctx context.Context, args ...string) error {
 	return cmd.Execute()
 }
 
+// validateSystemBuckets returns an error if the settings of the system
+// buckets would be rejected for buckets created through the API.
+func validateSystemBuckets(c kv.SystemBucketsConfig) error {
+	if c.TasksRetention < 0 || c.MonitoringRetention < 0 {
+		return errors.New("system bucket retention periods must not be negative")
+	}
+	if c.ShardGroupDuration != 0 && c.ShardGroupDuration < storage.MinShardGroupDuration {
+		return fmt.Errorf("system bucket shard group duration must be at least %s", storage.MinShardGroupDuration)
+	}
+	for _, rp := range []time.Duration{c.TasksRetention, c.MonitoringRetention} {
+		if rp >= storage.MinShardGroupDuration && c.ShardGroupDuration > rp {
+			return errors.New("system bucket shard group duration must not be longer than their retention periods")
+		}
+	}
+	if c.MaxSeries < 0 {
+		return errors.New("system bucket series limit must not be negative")
+	}
+	return nil
+}
+
 func (m *Launcher) run(ctx context.Context) (err error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -478,8 +1052,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	if err := validateSystemBuckets(m.systemBuckets); err != nil {
+		return err
+	}
 	serviceConfig := kv.ServiceConfig{
-		SessionLength: time.Duration(m.sessionLength) * time.Minute,
+		SessionLength:    time.Duration(m.sessionLength) * time.Minute,
+		SessionMaxLength: m.sessionMaxLength,
+		SystemBuckets:    m.systemBuckets,
 	}
 
 	flushers := flushers{}
@@ -487,18 +1066,45 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	case BoltStore:
 		store := bolt.NewKVStore(m.log.With(zap.String("service", "kvstore-bolt")), m.boltPath)
 		store.WithDB(m.boltClient.DB())
+		m.kvStore = store
 		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
 		if m.testing {
 			flushers = append(flushers, store)
 		}
 	case MemoryStore:
 		store := inmem.NewKVStore()
+		m.kvStore = store
+		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
+		if m.testing {
+			flushers = append(flushers, store)
+		}
+	case PostgresStore:
+		store := postgres.NewKVStore(m.log.With(zap.String("service", "kvstore-postgres")), m.postgresDSN)
+		if err := store.Open(ctx); err != nil {
+			m.log.Error("Failed opening postgres", zap.Error(err))
+			return err
+		}
+		m.postgresStore = store
+		m.kvStore = store
+		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
+		if m.testing {
+			flushers = append(flushers, store)
+		}
+	case EtcdStore:
+		store := etcd.NewKVStore(m.log.With(zap.String("service", "kvstore-etcd")), m.etcdEndpoints, m.etcdPrefix)
+		store.WithMaxTxnOps(m.etcdMaxTxnOps)
+		if err := store.Open(ctx); err != nil {
+			m.log.Error("Failed opening etcd", zap.Error(err))
+			return err
+		}
+		m.etcdStore = store
+		m.kvStore = store
 		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
 		if m.testing {
 			flushers = append(flushers, store)
 		}
 	default:
-		err := fmt.Errorf("unknown store type %s; expected bolt or memory", m.storeType)
+		err := fmt.Errorf("unknown store type %s; expected bolt, memory, postgres or etcd", m.storeType)
 		m.log.Error("Failed opening bolt", zap.Error(err))
 		return err
 	}
@@ -531,6 +1137,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		orgLogSvc                 platform.OrganizationOperationLogService = m.kvService
 		onboardingSvc             platform.OnboardingService               = m.kvService
 		scraperTargetSvc          platform.ScraperTargetStoreService       = m.kvService
+		scraperTargetStatusSvc    platform.ScraperTargetStatusService      = m.kvService
 		telegrafSvc               platform.TelegrafConfigStore             = m.kvService
 		userResourceSvc           platform.UserResourceMappingService      = m.kvService
 		labelSvc                  platform.LabelService                    = m.kvService
@@ -551,11 +1158,29 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			return err
 		}
 		secretSvc = svc
+
+		renewer := vault.NewTokenRenewer(m.log, svc.Client)
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			renewer.Run(ctx)
+		}()
+	case "aws":
+		// Credentials are read from the standard AWS environment variables and shared configuration.
+		svc, err := awssecrets.NewSecretService(awsSecretsConfig)
+		if err != nil {
+			m.log.Error("Failed initializing aws secret service", zap.Error(err))
+			return err
+		}
+		secretSvc = svc
 	default:
-		err := fmt.Errorf("unknown secret service %q, expected \"bolt\" or \"vault\"", m.secretStore)
+		err := fmt.Errorf("unknown secret service %q, expected \"bolt\", \"vault\" or \"aws\"", m.secretStore)
 		m.log.Error("Failed setting secret service", zap.Error(err))
 		return err
 	}
+	if m.secretStore != "bolt" && m.secretCacheTTL > 0 {
+		secretSvc = secret.NewCachingService(secretSvc, m.secretCacheTTL)
+	}
 
 	chronografSvc, err := server.NewServiceV2(ctx, m.boltClient.DB())
 	if err != nil {
@@ -563,13 +1188,17 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	m.StorageConfig.Engine.Compaction.Throughput = toml.Size(m.compactThroughput)
+	m.StorageConfig.Engine.Compaction.ThroughputBurst = toml.Size(m.compactThroughputBurst)
+	m.StorageConfig.WAL.FsyncMaxBatchBytes = toml.Size(m.walFsyncMaxBatchBytes)
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc), storage.WithRollupRules(bucketSvc))
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc), storage.WithRollupRules(bucketSvc))
 	}
 	m.engine.WithLogger(m.log)
 	if err := m.engine.Open(ctx); err != nil {
@@ -579,12 +1208,97 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	if err := storage.LoadBucketSettings(ctx, bucketSvc, m.engine); err != nil {
+		m.log.Error("Failed to load bucket settings", zap.Error(err))
+		return err
+	}
+	if err := storage.LoadOrgQuotas(ctx, m.kvService, m.engine); err != nil {
+		m.log.Error("Failed to load organization quotas", zap.Error(err))
+		return err
+	}
+
+	// Buckets may be deleted by other instances sharing the kv store, so the
+	// engine drops the data of every deleted bucket as the store reports it.
+	deletedBuckets, err := m.kvService.WatchDeletedBuckets(ctx)
+	if err != nil {
+		m.log.Error("Failed to watch deleted buckets", zap.Error(err))
+		return err
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		storage.DropDeletedBuckets(ctx, m.log.With(zap.String("service", "bucket-watcher")), deletedBuckets, m.engine)
+	}()
+
+	// The system buckets of new organizations are created by the kv store
+	// itself, so the engine learns of their settings as the store reports
+	// every created bucket.
+	createdBuckets, err := m.kvService.WatchCreatedBuckets(ctx)
+	if err != nil {
+		m.log.Error("Failed to watch created buckets", zap.Error(err))
+		return err
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		storage.LoadCreatedBuckets(ctx, createdBuckets, m.engine)
+	}()
+
+	// Deleted buckets are kept for the grace period, and their data dropped
+	// once it has passed.
+	storageBucketSvc := storage.NewBucketService(bucketSvc, m.engine)
+	storageBucketSvc.WithDeleteGracePeriod(m.bucketDeleteGracePeriod)
+	if m.bucketDeleteGracePeriod > 0 {
+		interval := time.Hour
+		if m.bucketDeleteGracePeriod < interval {
+			interval = m.bucketDeleteGracePeriod
+		}
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			storage.PurgeDeletedBucketsEvery(ctx, m.log.With(zap.String("service", "bucket-purger")), storageBucketSvc, interval)
+		}()
+	}
+
+	spill := readservice.WithSpill(reads.SpillConfig{
+		Dir:          m.querySpillDir,
+		MemoryBytes:  int64(m.querySpillMemoryBytes),
+		MaxTempBytes: int64(m.querySpillMaxTempBytes),
+	})
+	m.reg.MustRegister(reads.PrometheusCollectors()...)
+
+	if m.ReadServiceConfig.BindAddress != "" {
+		m.readServer = readservice.NewServer(readservice.NewStore(m.engine, spill), m.ReadServiceConfig)
+		m.readServer.AuthorizationService = authSvc
+		m.readServer.Logger = m.log.With(zap.String("service", "storage-grpc"))
+		if err := m.readServer.Open(); err != nil {
+			m.log.Error("Failed to open storage read service", zap.Error(err))
+			return err
+		}
+	}
+
+	m.drainService = drain.NewService(m.engine, m.log.With(zap.String("service", "drain")))
+
 	var (
-		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
-		backupService platform.BackupService = m.engine
+		deleteService     platform.DeleteService     = m.engine
+		pointsWriter      storage.PointsWriter       = m.engine
+		backupService     platform.BackupService     = m.engine
+		writeStatsService platform.WriteStatsService = m.engine
 	)
 
+	var queryCache *cache.Cache
+	if m.queryCacheTTL > 0 {
+		queryCache = cache.New(m.log.With(zap.String("service", "query-cache")), bucketSvc, cache.Config{
+			TTL:      m.queryCacheTTL,
+			MaxBytes: int64(m.queryCacheMaxBytes),
+		})
+		m.reg.MustRegister(queryCache.PrometheusCollectors()...)
+		// Writes and deletes, including those of queries, invalidate the
+		// cached results of the buckets they change.
+		pointsWriter = queryCache.PointsWriter(pointsWriter)
+		deleteService = queryCache.DeleteService(deleteService)
+	}
+
 	// TODO(cwolff): Figure out a good default per-query memory limit:
 	//   https://github.com/influxdata/influxdb/issues/13642
 	const (
@@ -594,8 +1308,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	)
 
 	deps, err := influxdb.NewDependencies(
-		reads.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
+		reads.NewReader(readservice.NewStore(m.engine, spill)),
+		pointsWriter,
 		authorizer.NewBucketService(bucketSvc),
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
@@ -605,6 +1319,20 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		m.log.Error("Failed to get query controller dependencies", zap.Error(err))
 		return err
 	}
+	deps.StorageDeps.FromDeps.RejectOutsideRetention = m.queryRejectOutsideRetention
+	deps.StorageDeps.FromDeps.Logger = m.log.With(zap.String("service", "storage-reads"))
+	// Points written by to() are held to the limits and schemas of the
+	// write API.
+	deps.StorageDeps.ToDeps.MaxBatchLines = m.httpWriteMaxLines
+	deps.StorageDeps.ToDeps.MaxBatchBytes = m.httpWriteMaxBodyBytes
+	deps.StorageDeps.ToDeps.WriteHinter = m.engine
+	deps.StorageDeps.ToDeps.BucketService = bucketSvc
+	deps.StorageDeps.ToDeps.MeasurementSchemaService = m.kvService
+	deps.StorageDeps.JoinDeps = influxdb.JoinDependencies{
+		SpillDir:          m.querySpillDir,
+		SpillMemoryBytes:  int64(m.querySpillMemoryBytes),
+		SpillMaxTempBytes: int64(m.querySpillMaxTempBytes),
+	}
 
 	m.queryController, err = control.New(control.Config{
 		ConcurrencyQuota:         concurrencyQuota,
@@ -620,7 +1348,24 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	if err := storage.LoadOrgQuotas(ctx, m.kvService, m.queryController); err != nil {
+		m.log.Error("Failed to load organization quotas", zap.Error(err))
+		return err
+	}
+
+	var storageQueryService query.ProxyQueryService = readservice.NewProxyQueryService(m.queryController)
+	if queryCache != nil {
+		storageQueryService = queryCache.ProxyQueryService(storageQueryService)
+	}
+	slowLogConfig := slowlog.Config{
+		Duration:      m.slowQueryLogDuration,
+		ScannedValues: int64(m.slowQueryLogScannedValues),
+		ScannedBytes:  int64(m.slowQueryLogScannedBytes),
+	}
+	if slowLogConfig.Enabled() {
+		slowLog := slowlog.New(m.log.With(zap.String("service", "slow-query-log")), bucketSvc, pointsWriter, slowLogConfig)
+		storageQueryService = query.NewLoggingProxyQueryService(m.log, slowLog, storageQueryService)
+	}
 	var taskSvc platform.TaskService
 	{
 		// create the task stack
@@ -745,7 +1490,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	}
 
 	subscriber.Subscribe(gather.MetricsSubject, "metrics", gather.NewRecorderHandler(m.log, gather.PointWriter{Writer: pointsWriter}))
-	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second)
+	scraperScheduler, err := gather.NewScheduler(m.log, 10, scraperTargetSvc, publisher, subscriber, 10*time.Second, 30*time.Second,
+		gather.WithSecretService(secretSvc),
+		gather.WithTargetStatusService(scraperTargetStatusSvc),
+	)
 	if err != nil {
 		m.log.Error("Failed to create scraper subscriber", zap.Error(err))
 		return err
@@ -761,30 +1509,139 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		log.Info("Stopping")
 	}(m.log)
 
+	if m.lifecycleReportInterval > 0 {
+		reporter := lifecycle.NewReporter(m.log)
+		reporter.Interval = m.lifecycleReportInterval
+		reporter.OrganizationService = orgSvc
+		reporter.BucketService = bucketSvc
+		reporter.WriteStatsService = writeStatsService
+		reporter.CardinalityService = m.engine
+		reporter.RetentionCounter = m.engine
+		reporter.PointsWriter = pointsWriter
+		reporter.SeriesLimit = m.StorageConfig.MaxSeriesPerBucket
+		if m.lifecycleReportWebhookURL != "" {
+			reporter.Notifier = lifecycle.NewWebhookNotifier(m.lifecycleReportWebhookURL)
+		}
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			reporter.Report(ctx)
+		}()
+	}
+
+	if m.materializedViewInterval > 0 {
+		materializer := materialized.NewMaterializer(m.log)
+		materializer.Interval = m.materializedViewInterval
+		materializer.ViewService = m.kvService
+		materializer.QueryService = query.QueryServiceBridge{AsyncQueryService: m.queryController}
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			materializer.Run(ctx)
+		}()
+	}
+
+	if m.tokenStaleAfter > 0 {
+		staleFlagger := tokens.NewStaleFlagger(m.log)
+		staleFlagger.StaleAfter = m.tokenStaleAfter
+		staleFlagger.AuthorizationLifecycleService = m.kvService
+
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			staleFlagger.Run(ctx)
+		}()
+	}
+
+	var auditExporters []audit.Exporter
+	if m.auditLogFile != "" {
+		x, err := audit.NewFileExporter(m.auditLogFile)
+		if err != nil {
+			m.log.Error("Failed to open audit log file", zap.String("path", m.auditLogFile), zap.Error(err))
+			return err
+		}
+		auditExporters = append(auditExporters, x)
+	}
+	if m.auditLogSyslog != "" {
+		x, err := audit.NewSyslogExporter(m.auditLogSyslog)
+		if err != nil {
+			m.log.Error("Failed to connect to audit log syslog", zap.String("address", m.auditLogSyslog), zap.Error(err))
+			return err
+		}
+		auditExporters = append(auditExporters, x)
+	}
+	m.auditLog = audit.NewService(m.log.With(zap.String("service", "audit")), m.kvService, auditExporters...)
+
+	ldapSyncer := ldap.NewSyncer(m.log)
+	ldapSyncer.LDAPService = m.kvService
+	ldapSyncer.UserService = userSvc
+	ldapSyncer.UserResourceMappingService = userResourceSvc
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ldapSyncer.Run(ctx)
+	}()
+	passwdsSvc = ldap.NewPasswordsService(m.log, passwdsSvc, m.kvService, userSvc)
+
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
 
 	m.apibackend = &http.APIBackend{
-		AssetsPath:           m.assetsPath,
-		HTTPErrorHandler:     kithttp.ErrorHandler(0),
-		Logger:               m.log,
-		SessionRenewDisabled: m.sessionRenewDisabled,
-		NewBucketService:     source.NewBucketService,
-		NewQueryService:      source.NewQueryService,
-		PointsWriter:         pointsWriter,
-		DeleteService:        deleteService,
-		BackupService:        backupService,
-		KVBackupService:      m.kvService,
-		AuthorizationService: authSvc,
+		AssetsPath:                m.assetsPath,
+		HTTPErrorHandler:          kithttp.ErrorHandler(0),
+		Logger:                    m.log,
+		SessionRenewDisabled:      m.sessionRenewDisabled,
+		MaxBatchSizeBytes:         int64(m.httpWriteMaxBodyBytes),
+		WriteParserMaxLines:       m.httpWriteMaxLines,
+		MaxConcurrentWrites:       m.httpWriteMaxConcurrency,
+		NewBucketService:          source.NewBucketService,
+		NewQueryService:           source.NewQueryService,
+		PointsWriter:              pointsWriter,
+		DeleteService:             deleteService,
+		BackupService:             backupService,
+		WriteStatsService:         writeStatsService,
+		ShardStatsService:         m.engine,
+		CardinalityService:        m.engine,
+		DrainService:              m.drainService,
+		CompactionService:         m.engine,
+		SeriesMoveService:         m.engine,
+		IndexRebuildService:       m.engine,
+		SnapshotService:           m.engine,
+		WALRecoveryService:        m.engine,
+		DrainGate:                 m.drainService,
+		WriteHinter:               m.engine,
+		KVBackupService:           m.kvService,
+		MetadataStoreService:      m.boltClient.DB(),
+		MetadataBundleService:     m.kvService,
+		AuthorizationService:      authSvc,
+		AuthorizationBatchService: m.kvService,
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		BucketService:                   storageBucketSvc,
+		BucketRestoreService:            storageBucketSvc,
+		BucketTransferService:           storageBucketSvc,
+		BucketUsageService:              storageBucketSvc,
+		BucketExportService:             storageBucketSvc,
+		MeasurementSchemaService:        m.kvService,
 		SessionService:                  sessionSvc,
+		SessionManagementService:        m.kvService,
+		SessionIdleTimeout:              m.sessionIdleTimeout,
+		AuthorizationLifecycleService:   m.kvService,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
+		OrgQuotaService:                 storage.NewOrgQuotaService(m.kvService, storage.OrgQuotaSetters{m.engine, m.queryController}),
+		NetworkPolicyService:            m.kvService,
 		UserResourceMappingService:      userResourceSvc,
 		LabelService:                    labelSvc,
 		DashboardService:                dashboardSvc,
+		DBRPMappingService:              m.kvService,
+		MaterializedViewService:         m.kvService,
+		LDAPService:                     m.kvService,
+		LDAPSyncService:                 ldapSyncer,
+		AuditLogService:                 m.auditLog,
+		TokenRateLimiter:                http.NewTokenRateLimiter(),
 		DashboardOperationLogService:    dashboardLogSvc,
 		BucketOperationLogService:       bucketLogSvc,
 		UserOperationLogService:         userLogSvc,
@@ -794,6 +1651,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		PasswordsService:                passwdsSvc,
 		OnboardingService:               onboardingSvc,
 		InfluxQLService:                 storageQueryService,
+		RunningQueryService:             m.queryController,
+		QueryExplainer:                  m.queryController,
 		FluxService:                     storageQueryService,
 		TaskService:                     taskSvc,
 		TelegrafService:                 telegrafSvc,
@@ -801,15 +1660,23 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
 		CheckService:                    checkSvc,
 		ScraperTargetStoreService:       scraperTargetSvc,
+		ScraperTargetStatusService:      scraperTargetStatusSvc,
 		ChronografService:               chronografSvc,
 		SecretService:                   secretSvc,
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
 		OrgLookupService:                m.kvService,
+		RevisionService:                 m.kvService,
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
 	}
 
+	if m.signedWriteMaxSkew > 0 {
+		v := http.NewSignedRequestVerifier(m.signedWriteMaxSkew)
+		v.MaxBodyBytes = int64(m.httpWriteMaxBodyBytes)
+		m.apibackend.SignedRequestVerifier = v
+	}
+
 	m.reg.MustRegister(m.apibackend.PrometheusCollectors()...)
 
 	var pkgSVC pkger.SVC
@@ -851,6 +1718,20 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			m.reg,
 			http.WithLog(httpLogger),
 			http.WithAPIHandler(platformHandler),
+			http.WithHealthHandler(http.NewHealthHandler(
+				kvCheck(m.kvStore),
+				engineCheck(m.engine),
+				walCheck(m.engine),
+				m.scheduler,
+				m.queryController,
+			)),
+			http.WithReadyHandler(http.NewReadyHandler(
+				kvCheck(m.kvStore),
+				engineCheck(m.engine),
+				walCheck(m.engine),
+				m.queryController,
+				drainCheck(m.drainService),
+			)),
 		)
 
 		if logconf.Level == zap.DebugLevel {
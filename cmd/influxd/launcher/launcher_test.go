@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	nethttp "net/http"
+	"reflect"
 	"testing"
 
 	platform "github.com/influxdata/influxdb"
@@ -139,3 +140,76 @@ func TestLauncher_SetupWithUsers(t *testing.T) {
 		t.Fatalf("unexpected 2 users: %#+v", exp)
 	}
 }
+
+func TestLauncher_HealthAndReady(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	type status struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			Reason string `json:"reason"`
+		} `json:"checks"`
+	}
+	get := func(path string, wantCode int) status {
+		t.Helper()
+		resp, err := nethttp.Get(l.URL() + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != wantCode {
+			t.Fatalf("unexpected status code: %d, body: %s", resp.StatusCode, body)
+		}
+		var s status
+		if err := json.Unmarshal(body, &s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	do := func(method string, wantCode int) {
+		t.Helper()
+		resp, err := nethttp.DefaultClient.Do(l.NewHTTPRequestOrFail(t, method, "/api/v2/drain", l.Auth.Token, ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != wantCode {
+			t.Fatalf("unexpected status code: %d", resp.StatusCode)
+		}
+	}
+
+	health := get("/health", nethttp.StatusOK)
+	if health.Status != "pass" {
+		t.Fatalf("unexpected health status %q: %+v", health.Status, health.Checks)
+	}
+	var names []string
+	for _, c := range health.Checks {
+		names = append(names, c.Name)
+	}
+	if want := []string{"kv", "query-controller", "storage-engine", "task-scheduler", "wal"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected health checks %v, expected %v", names, want)
+	}
+
+	if ready := get("/ready", nethttp.StatusOK); ready.Status != "ready" {
+		t.Fatalf("unexpected ready status %q: %+v", ready.Status, ready.Checks)
+	}
+
+	// A draining server is healthy, but not ready for writes and queries.
+	do("POST", nethttp.StatusAccepted)
+	ready := get("/ready", nethttp.StatusServiceUnavailable)
+	if ready.Status != "unready" || ready.Checks[0].Name != "drain" || ready.Checks[0].Status != "fail" {
+		t.Fatalf("unexpected ready status %q: %+v", ready.Status, ready.Checks)
+	}
+	get("/health", nethttp.StatusOK)
+
+	do("DELETE", nethttp.StatusOK)
+	get("/ready", nethttp.StatusOK)
+}
@@ -3,9 +3,11 @@ package launcher_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	nethttp "net/http"
 	"strings"
 	"testing"
@@ -405,3 +407,348 @@ from(bucket: "%s")
 		t.Fatal(err)
 	}
 }
+
+func TestPipeline_Query_Params(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a value=1 946684800000000000
+cpu,host=b value=2 946684800000000000`)
+
+	body := fmt.Sprintf(`{
+	"query": "from(bucket: params.bucket) |> range(start: time(v: params.start)) |> filter(fn: (r) => r.host == params.host) |> keep(columns: [\"_value\"])",
+	"params": {"bucket": %q, "start": "2000-01-01T00:00:00Z", "host": "b"}
+}`, l.Bucket.Name)
+	req := l.MustNewHTTPRequest("POST", "/api/v2/query?orgID="+l.Org.ID.String(), body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("unexpected status %d: %s", resp.StatusCode, buf.String())
+	}
+	if got, want := buf.String(), `{"results":[{"name":"_result","tables":[{"groupKey":{},"rows":[{"_value":2}]}]}]}`; got != want {
+		t.Fatalf("unexpected results -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+
+	// Interpolations in the values of params are rejected.
+	body = `{"query": "from(bucket: params.bucket)", "params": {"bucket": "${secret}"}}`
+	req = l.MustNewHTTPRequest("POST", "/api/v2/query?orgID="+l.Org.ID.String(), body)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err = nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != nethttp.StatusBadRequest {
+		t.Fatalf("unexpected status %d for interpolated param", resp.StatusCode)
+	}
+}
+
+func TestPipeline_QueryCache(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--query-cache-ttl", "1h")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	q := fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z) |> count() |> keep(columns: ["_value"])`, l.Bucket.Name)
+	count := func() string {
+		t.Helper()
+		return l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q)
+	}
+
+	l.WritePointsOrFail(t, `cpu value=1 946684800000000000`)
+	first := count()
+	if got := count(); got != first {
+		t.Fatalf("unexpected results of the cached query -want/+got:\n\t- %s\n\t+ %s", first, got)
+	}
+	resp, err := nethttp.Get(l.URL() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(metrics, []byte(`query_cache_requests_total{result="hit"} 1`)) {
+		t.Fatal("expected the second query to be served from the cache")
+	}
+
+	// Writes invalidate the results of the queries reading their bucket.
+	l.WritePointsOrFail(t, `cpu value=2 946684801000000000`)
+	if got := count(); got == first || !strings.Contains(got, ",2\r\n") {
+		t.Fatalf("expected the results to count the new point, got %s", got)
+	}
+}
+
+func TestPipeline_Query_PushDownBareAggregate(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a f=3.5,i=3i,u=3u,s="x" 946684800000000000
+cpu,host=a f=-1.25,i=-1i,u=1u,s="y" 946684801000000000
+cpu,host=a f=4,i=4i,u=4u,s="z" 946684802000000000
+cpu,host=b f=1,i=1i,u=1u,s="x" 946684800000000000
+cpu,host=b f=5,i=5i,u=5u,s="y" 946684803000000000
+cpu,host=b f=1,i=1i,u=1u 946684804000000000`)
+
+	// Filters on the existence of values cannot be pushed down, so the
+	// aggregate following one is computed by the query rather than storage.
+	for _, agg := range []string{"count", "sum", "min", "max", "mean"} {
+		t.Run(agg, func(t *testing.T) {
+			fields := `r._field != "s"`
+			if agg == "count" {
+				fields = `r._field != ""`
+			}
+			q := fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z) |> filter(fn: (r) => %s)`, l.Bucket.Name, fields)
+			want := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q+` |> filter(fn: (r) => exists r._value) |> `+agg+`()`)
+			got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q+` |> `+agg+`()`)
+			if got != want {
+				t.Fatalf("unexpected results of the pushed down %s -want/+got:\n\t- %s\n\t+ %s", agg, want, got)
+			}
+			if strings.Count(got, "\r\n") < 7 {
+				t.Fatalf("expected a row for each series, got %s", got)
+			}
+		})
+	}
+}
+
+func TestPipeline_Query_PushDownGroupAggregate(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a,cpu=0 f=3.5,i=3i,u=3u,s="x" 946684800000000000
+cpu,host=a,cpu=0 f=-1.25,i=-1i,u=1u,s="y" 946684801000000000
+cpu,host=a,cpu=1 f=4,i=4i,u=4u,s="z" 946684802000000000
+cpu,host=a,cpu=1 f=2,i=2i,u=2u 946684803000000000
+cpu,host=b,cpu=0 f=1,i=1i,u=1u,s="x" 946684800000000000
+cpu,host=b,cpu=1 f=5,i=5i,u=5u,s="y" 946684803000000000
+cpu,host=b,cpu=1 f=0.5,i=0i,u=0u 946684804000000000`)
+
+	// Filters on the existence of values cannot be pushed down, so the
+	// aggregate following one is computed by the query rather than storage.
+	for _, agg := range []string{"count", "sum", "min", "max"} {
+		t.Run(agg, func(t *testing.T) {
+			fields := `r._field != "s"`
+			if agg == "count" {
+				fields = `r._field != ""`
+			}
+			q := fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z) |> filter(fn: (r) => %s)`, l.Bucket.Name, fields)
+			q += ` |> group(columns: ["host", "_field"])`
+			want := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q+` |> filter(fn: (r) => exists r._value) |> `+agg+`()`)
+			got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q+` |> `+agg+`()`)
+			if got != want {
+				t.Fatalf("unexpected results of the pushed down %s -want/+got:\n\t- %s\n\t+ %s", agg, want, got)
+			}
+			if strings.Count(got, "\r\n") < 7 {
+				t.Fatalf("expected a row for each group, got %s", got)
+			}
+		})
+	}
+}
+
+func TestPipeline_Query_ExplainAndProfile(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a v=1 946684800000000000
+cpu,host=a v=2 946684801000000000
+cpu,host=b v=3 946684800000000000`)
+
+	q := fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z) |> group(columns: ["host"]) |> sum()`, l.Bucket.Name)
+	body, err := json.Marshal(map[string]string{"query": q})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(path string, v interface{}) {
+		t.Helper()
+		resp, err := nethttp.DefaultClient.Do(l.MustNewHTTPRequest("POST", path+"?orgID="+l.Org.ID.String(), string(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != nethttp.StatusOK {
+			t.Fatalf("unexpected status of %s: %d %s", path, resp.StatusCode, b)
+		}
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The grouping and aggregate are pushed down into the storage read.
+	var e query.Explanation
+	post("/api/v2/query/explain", &e)
+	if len(e.Nodes) != 3 || e.Nodes[0].Kind != "ReadGroupPhysKind" {
+		t.Fatalf("expected the query to be a read of storage, got plan:\n%s", e.Plan)
+	}
+	details := strings.Join(e.Nodes[0].Details, "\n")
+	if !strings.Contains(details, "group: by [host]") || !strings.Contains(details, "aggregate: sum") {
+		t.Errorf("expected the group and sum to be pushed down, got details:\n%s", details)
+	}
+
+	var p query.Profile
+	post("/api/v2/query/profile", &p)
+	if len(p.Nodes) != len(e.Nodes) || p.Nodes[0].ID != e.Nodes[0].ID {
+		t.Fatalf("expected the profiled plan to be the explained plan, got:\n%s", p.Plan)
+	}
+	if r := p.Nodes[0].Read; r == nil || r.Operation != "readGroup" || r.ScannedValues == 0 {
+		t.Errorf("unexpected profile of the storage read: %+v", r)
+	}
+	if p.ExecuteDuration.Duration <= 0 {
+		t.Errorf("expected the execute duration of the query, got %s", p.ExecuteDuration)
+	}
+}
+
+func TestPipeline_Query_SlowQueryLog(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--slow-query-log-scanned-values", "2")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a v=1 946684800000000000
+cpu,host=a v=2 946684801000000000`)
+
+	// Only the query scanning both values is recorded.
+	l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-01T00:00:01Z) |> first()`, l.Bucket.Name))
+	l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z) |> count()`, l.Bucket.Name))
+
+	got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, `from(bucket: "_monitoring") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "slow_queries" and r._field == "query")`)
+	if !strings.Contains(got, "count()") || strings.Contains(got, "first()") {
+		t.Fatalf("expected only the query scanning the threshold to be recorded, got:\n%s", got)
+	}
+	if !strings.Contains(got, l.User.ID.String()) {
+		t.Fatalf("expected the user of the query to be recorded, got:\n%s", got)
+	}
+}
+
+func TestPipeline_Query_OrgMemoryQuota(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a v=1 946684800000000000
+cpu,host=b v=2 946684800000000000`)
+
+	setQuota := func(body string) {
+		t.Helper()
+		resp, err := nethttp.DefaultClient.Do(l.MustNewHTTPRequest("PUT", fmt.Sprintf("/api/v2/orgs/%s/quota", l.Org.ID), body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != nethttp.StatusOK {
+			b, _ := ioutil.ReadAll(resp.Body)
+			t.Fatalf("failed to set the quota of the organization: %d %s", resp.StatusCode, b)
+		}
+	}
+
+	q := fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z) |> sort(columns: ["_value"])`, l.Bucket.Name)
+
+	setQuota(`{"maxQueryMemoryBytesPerQuery": 1}`)
+	_, err := phttp.SimpleQuery(l.URL(), q, l.Org.Name, l.Auth.Token)
+	if err == nil || !strings.Contains(err.Error(), "memory") {
+		t.Fatalf("expected the query to exceed the memory quota of the organization, got %v", err)
+	}
+
+	setQuota(`{}`)
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q); strings.Count(got, "\r\n") < 3 {
+		t.Fatalf("expected a row for each series, got %s", got)
+	}
+}
+
+func TestPipeline_Query_AlignedJoin(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `cpu,host=a,cpu=0 v=1 946684800000000000
+cpu,host=a,cpu=0 v=2 946684801000000000
+cpu,host=a,cpu=1 v=3 946684800000000000
+cpu,host=b,cpu=0 v=4 946684801000000000
+mem,host=a v=10 946684800000000000
+mem,host=a v=20 946684801000000000
+mem,host=b v=30 946684800000000000
+mem,host=b v=40 946684801000000000
+mem,host=c v=50 946684801000000000`)
+
+	// Filters on the existence of values are not pushed down, so the join
+	// following them is not aligned.
+	side := func(m, exists string) string {
+		return fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z) |> filter(fn: (r) => r._measurement == %q)%s`, l.Bucket.Name, m, exists)
+	}
+	join := func(exists string) string {
+		return fmt.Sprintf(`join(tables: {cpu: %s, mem: %s}, on: ["_time", "host"]) |> group() |> sort(columns: ["_time", "host", "cpu"])`,
+			side("cpu", exists), side("mem", exists))
+	}
+	want := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, join(` |> filter(fn: (r) => exists r._value)`))
+	got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, join(""))
+	if got != want {
+		t.Fatalf("unexpected results of the aligned join -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if strings.Count(got, "\r\n") < 6 {
+		t.Fatalf("expected a row for each pair of points, got %s", got)
+	}
+}
+
+func TestPipeline_MaterializedView(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--materialized-view-interval", "100ms")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	out := &influxdb.Bucket{OrgID: l.Org.ID, Name: "cpu_1h"}
+	if err := l.BucketService(t).CreateBucket(ctx, out); err != nil {
+		t.Fatal(err)
+	}
+	l.WritePointsOrFail(t, `cpu,host=a v=1 946684800000000000
+cpu,host=a v=2 946686600000000000
+cpu,host=a v=4 946688400000000000`)
+
+	// The view catches up on the windows since its latest completed one.
+	body, err := json.Marshal(map[string]interface{}{
+		"name":            "cpu 1h",
+		"query":           fmt.Sprintf(`from(bucket: %q) |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> sum() |> map(fn: (r) => ({r with _time: r._start}))`, l.Bucket.Name),
+		"bucketID":        out.ID.String(),
+		"every":           "1h",
+		"latestCompleted": "2000-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := l.MustNewHTTPRequest("POST", "/api/v2/materializedViews", string(body))
+	req.Header.Set("Content-Type", "application/json")
+	phttp.SetToken(l.Auth.Token, req)
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != nethttp.StatusCreated {
+		b, _ := ioutil.ReadAll(resp.Body)
+		t.Fatalf("unexpected status creating the view %d: %s", resp.StatusCode, b)
+	}
+	resp.Body.Close()
+
+	q := fmt.Sprintf(`from(bucket: %q) |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-01T03:00:00Z) |> keep(columns: ["_time", "_value"])`, out.Name)
+	want := ",result,table,_time,_value\r\n" +
+		",_result,0,2000-01-01T00:00:00Z,3\r\n" +
+		",_result,0,2000-01-01T01:00:00Z,4\r\n\r\n"
+	var got string
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+		if got = l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q); got == want {
+			return
+		}
+	}
+	t.Fatalf("unexpected materialized results -want/+got:\n\t- %q\n\t+ %q", want, got)
+}
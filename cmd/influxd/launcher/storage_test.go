@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	nethttp "net/http"
+	"net/url"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -91,6 +93,100 @@ func TestLauncher_WriteAndQuery(t *testing.T) {
 	}
 }
 
+func TestLauncher_LegacyWrite(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	if err := l.KeyValueService().Create(ctx, &influxdb.DBRPMapping{
+		Cluster:         http.DefaultDBRPCluster,
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  l.Org.ID,
+		BucketID:        l.Bucket.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write as a 1.x client authenticating with the token as its password.
+	req, err := nethttp.NewRequest("POST", l.URL()+"/write?db=telegraf&precision=s&u=user&p="+l.Auth.Token, strings.NewReader(`m,k=v f=100i 946684800`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := nethttp.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code: %d, body: %s, headers: %v", resp.StatusCode, body, resp.Header)
+	}
+
+	qs := `from(bucket:"BUCKET") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z)`
+	exp := `,result,table,_start,_stop,_time,_value,_field,_measurement,k` + "\r\n" +
+		`,_result,0,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z,2000-01-01T00:00:00Z,100,f,m,v` + "\r\n\r\n"
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected query results -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+}
+
+func TestLauncher_LegacyQuery(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	if err := l.KeyValueService().Create(ctx, &influxdb.DBRPMapping{
+		Cluster:         http.DefaultDBRPCluster,
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  l.Org.ID,
+		BucketID:        l.Bucket.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	l.WritePointsOrFail(t, `cpu,host=a value=1 946684800000000000
+cpu,host=a value=2 946684810000000000`)
+
+	q := url.Values{}
+	q.Set("db", "telegraf")
+	q.Set("rp", "autogen")
+	q.Set("epoch", "s")
+	q.Set("q", `SELECT value FROM cpu WHERE time >= '2000-01-01T00:00:00Z' AND time < '2000-01-02T00:00:00Z'`)
+	resp, err := nethttp.DefaultClient.Do(l.MustNewHTTPRequest("GET", "/query?"+q.Encode(), ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != nethttp.StatusOK {
+		t.Fatalf("unexpected status code: %d, body: %s, headers: %v", resp.StatusCode, body, resp.Header)
+	}
+
+	exp := `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[[946684800,1],[946684810,2]]}]}]}` + "\n"
+	if got := string(body); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected query results -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+}
+
 func TestLauncher_BucketDelete(t *testing.T) {
 	l := launcher.RunTestLauncherOrFail(t, ctx)
 	l.SetupOrFail(t)
@@ -0,0 +1,104 @@
+package influxdb
+
+import "context"
+
+// CompactionSettings control when the storage engine compacts TSM files and
+// how much disk throughput compactions may use.
+type CompactionSettings struct {
+	// MaxConcurrentFull is the maximum number of full and optimize compactions
+	// that can run at once. A value of 0 limits them only by the maximum
+	// number of concurrent compactions.
+	MaxConcurrentFull int `json:"maxConcurrentFull"`
+
+	// Throughput is the rate, in bytes per second, that compactions may write
+	// to disk. A value of 0 disables rate limiting.
+	Throughput int64 `json:"throughput"`
+
+	// ThroughputBurst is the number of bytes compactions may write at once
+	// above Throughput. A value of 0 allows bursts of Throughput.
+	ThroughputBurst int64 `json:"throughputBurst"`
+
+	// QuietHours are the windows of the day, such as "09:00-17:00" in the
+	// server's local time, during which only the compactions needed to keep
+	// up with writes are run. Level 3, full and optimize compactions wait
+	// until the window ends.
+	QuietHours []string `json:"quietHours"`
+}
+
+// CompactionSettingsUpdate is the set of changes to apply to the compaction
+// settings. Nil fields are left unchanged.
+type CompactionSettingsUpdate struct {
+	MaxConcurrentFull *int      `json:"maxConcurrentFull,omitempty"`
+	Throughput        *int64    `json:"throughput,omitempty"`
+	ThroughputBurst   *int64    `json:"throughputBurst,omitempty"`
+	QuietHours        *[]string `json:"quietHours,omitempty"`
+}
+
+// Apply applies the update to the settings.
+func (u CompactionSettingsUpdate) Apply(s *CompactionSettings) {
+	if u.MaxConcurrentFull != nil {
+		s.MaxConcurrentFull = *u.MaxConcurrentFull
+	}
+	if u.Throughput != nil {
+		s.Throughput = *u.Throughput
+	}
+	if u.ThroughputBurst != nil {
+		s.ThroughputBurst = *u.ThroughputBurst
+	}
+	if u.QuietHours != nil {
+		s.QuietHours = *u.QuietHours
+	}
+}
+
+// CompactionService reads and changes the compaction settings of a running
+// server, and compacts buckets on demand.
+type CompactionService interface {
+	// CompactionSettings returns the current compaction settings.
+	CompactionSettings(ctx context.Context) (*CompactionSettings, error)
+
+	// UpdateCompactionSettings applies upd to the compaction settings and
+	// returns the result. The settings take effect for compactions started
+	// after the update, except throughput which also applies to compactions
+	// in progress.
+	UpdateCompactionSettings(ctx context.Context, upd CompactionSettingsUpdate) (*CompactionSettings, error)
+
+	// CompactBucket writes the cached data of the bucket to disk and fully
+	// compacts its TSM files, returning once the compaction has finished.
+	CompactBucket(ctx context.Context, orgID, bucketID ID) error
+
+	// TombstoneStats returns the deleted data waiting to be removed from TSM
+	// files by compaction, and its cost to reads.
+	TombstoneStats(ctx context.Context) (*TombstoneStats, error)
+}
+
+// TombstoneStats describes the deleted data still held in the TSM files of
+// the storage engine, to tell when files need compacting after large deletes.
+type TombstoneStats struct {
+	// Files holds the stats of the files with tombstones, with the most
+	// deleted data first.
+	Files []*FileTombstoneStats `json:"files"`
+
+	// TombstonedValuesRead is the number of values read since the engine was
+	// opened that were then dropped because they had been deleted, and
+	// TombstonedBlocksRead is the number of blocks read that held only such
+	// values. They measure the read amplification caused by deletes.
+	TombstonedValuesRead uint64 `json:"tombstonedValuesRead"`
+	TombstonedBlocksRead uint64 `json:"tombstonedBlocksRead"`
+}
+
+// FileTombstoneStats describes the deleted data held in a single TSM file.
+type FileTombstoneStats struct {
+	Path      string `json:"path"`
+	SizeBytes uint64 `json:"sizeBytes"`
+
+	// TombstoneBytes is the size of the tombstone files recording the deletes.
+	TombstoneBytes uint64 `json:"tombstoneBytes"`
+
+	// TombstonedBlocks is the number of blocks whose values have all been
+	// deleted, TombstonedKeys is the number of series keys they belong to,
+	// and TombstonedBytes is their size, which is reclaimed once the file is
+	// compacted.
+	TombstonedKeys   int    `json:"tombstonedKeys"`
+	TombstonedBlocks int    `json:"tombstonedBlocks"`
+	TombstonedBytes  uint64 `json:"tombstonedBytes"`
+}
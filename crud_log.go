@@ -1,6 +1,7 @@
 package influxdb
 
 import (
+	"sync"
 	"time"
 )
 
@@ -39,3 +40,28 @@ type RealTimeGenerator struct{}
 func (g RealTimeGenerator) Now() time.Time {
 	return time.Now()
 }
+
+// StepTimeGenerator generates times that advance by a fixed step on every call,
+// so that states built with it are reproducible.
+//
+// Safe for concurrent use by multiple goroutines.
+type StepTimeGenerator struct {
+	mu   sync.Mutex
+	next time.Time
+	step time.Duration
+}
+
+// NewStepTimeGenerator creates a StepTimeGenerator whose first time is start.
+// A zero step always generates start.
+func NewStepTimeGenerator(start time.Time, step time.Duration) *StepTimeGenerator {
+	return &StepTimeGenerator{next: start, step: step}
+}
+
+// Now returns the next time.
+func (g *StepTimeGenerator) Now() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.next
+	g.next = g.next.Add(g.step)
+	return now
+}
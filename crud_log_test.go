@@ -0,0 +1,24 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestStepTimeGenerator(t *testing.T) {
+	start := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	gen := influxdb.NewStepTimeGenerator(start, time.Second)
+	for i := 0; i < 3; i++ {
+		if got, exp := gen.Now(), start.Add(time.Duration(i)*time.Second); !got.Equal(exp) {
+			t.Fatalf("unexpected time %d; got=%v, exp=%v", i, got, exp)
+		}
+	}
+
+	fixed := influxdb.NewStepTimeGenerator(start, 0)
+	fixed.Now()
+	if got := fixed.Now(); !got.Equal(start) {
+		t.Fatalf("unexpected time of zero step; got=%v, exp=%v", got, start)
+	}
+}
@@ -7,6 +7,18 @@ import (
 	"unicode"
 )
 
+// ops for dbrp mappings.
+var (
+	OpFindDBRPMappingBy = "FindDBRPMappingBy"
+	OpFindDBRPMapping   = "FindDBRPMapping"
+	OpFindDBRPMappings  = "FindDBRPMappings"
+	OpCreateDBRPMapping = "CreateDBRPMapping"
+	OpDeleteDBRPMapping = "DeleteDBRPMapping"
+)
+
+// ErrDBRPMappingNotFound is the error msg for a missing dbrp mapping.
+const ErrDBRPMappingNotFound = "dbrp mapping not found"
+
 // DBRPMappingService provides a mapping of cluster, database and retention policy to an organization ID and bucket ID.
 type DBRPMappingService interface {
 	// FindBy returns the dbrp mapping the for cluster, db and rp.
@@ -0,0 +1,64 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// DrainState is the state of a server with respect to draining.
+type DrainState string
+
+const (
+	// DrainStateActive means the server is accepting writes and queries.
+	DrainStateActive DrainState = "active"
+
+	// DrainStateDraining means the server is refusing new writes and queries
+	// while it waits for in-flight requests and persists its in-memory state.
+	DrainStateDraining DrainState = "draining"
+
+	// DrainStateDrained means the drain has completed. New writes and queries
+	// continue to be refused until the server is resumed.
+	DrainStateDrained DrainState = "drained"
+)
+
+// DrainStatus reports the progress of a drain.
+type DrainStatus struct {
+	State DrainState `json:"state"`
+
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// InFlightWrites and InFlightQueries are the number of requests that
+	// were admitted and have not yet completed.
+	InFlightWrites  int64 `json:"inFlightWrites"`
+	InFlightQueries int64 `json:"inFlightQueries"`
+
+	// TimedOut is true if the drain stopped waiting for in-flight requests
+	// before they completed.
+	TimedOut bool `json:"timedOut,omitempty"`
+
+	// Checkpointed is true once the storage cache has been written to disk and
+	// the WAL segments it covered have been removed.
+	Checkpointed bool `json:"checkpointed"`
+
+	// CheckpointID identifies the checkpoint taken by the drain.
+	CheckpointID CheckpointID `json:"checkpointID,omitempty"`
+
+	// Err is set if the drain failed to checkpoint storage.
+	Err string `json:"error,omitempty"`
+}
+
+// DrainService manages draining the server for shutdown and maintenance.
+type DrainService interface {
+	// Drain stops the server from accepting new writes and queries and begins
+	// waiting, for at most timeout, for in-flight requests to complete before
+	// checkpointing storage. It returns without waiting for the drain to
+	// complete; progress is reported by DrainStatus.
+	Drain(ctx context.Context, timeout time.Duration) (*DrainStatus, error)
+
+	// Resume makes the server accept writes and queries again.
+	Resume(ctx context.Context) (*DrainStatus, error)
+
+	// DrainStatus returns the progress of the current drain.
+	DrainStatus(ctx context.Context) (*DrainStatus, error)
+}
@@ -0,0 +1,218 @@
+// Package drain coordinates draining the server of writes and queries ahead of
+// a shutdown or maintenance.
+package drain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// DefaultPollInterval is how often a drain checks whether in-flight requests
+// have completed.
+const DefaultPollInterval = 100 * time.Millisecond
+
+// ErrDraining is returned when a request is refused because the server is
+// draining.
+var ErrDraining = &influxdb.Error{
+	Code: influxdb.EUnavailable,
+	Msg:  "server is draining and not accepting new requests",
+}
+
+// A Checkpointer persists in-memory storage state so that it does not have to
+// be recovered when the server restarts.
+type Checkpointer interface {
+	Checkpoint(ctx context.Context) (influxdb.CheckpointID, error)
+}
+
+var _ influxdb.DrainService = (*Service)(nil)
+
+// Service tracks in-flight writes and queries and drains them on request.
+type Service struct {
+	// PollInterval is how often in-flight requests are checked during a drain.
+	PollInterval time.Duration
+
+	checkpointer Checkpointer
+	log          *zap.Logger
+
+	mu     sync.Mutex
+	status influxdb.DrainStatus
+	writes int64
+	reads  int64
+
+	// gen is incremented on every resume so a drain that is still running
+	// does not overwrite the status of a later one.
+	gen  int
+	done chan struct{}
+}
+
+// NewService returns a new Service that checkpoints storage with c once
+// in-flight requests have completed. If c is nil no checkpoint is taken.
+func NewService(c Checkpointer, log *zap.Logger) *Service {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &Service{
+		PollInterval: DefaultPollInterval,
+		checkpointer: c,
+		log:          log,
+		status:       influxdb.DrainStatus{State: influxdb.DrainStateActive},
+	}
+}
+
+// AdmitWrite records the start of a write, returning a function to call when
+// it completes. ErrDraining is returned if the server is draining.
+func (s *Service) AdmitWrite() (func(), error) {
+	return s.admit(&s.writes)
+}
+
+// AdmitQuery records the start of a query, returning a function to call when
+// it completes. ErrDraining is returned if the server is draining.
+func (s *Service) AdmitQuery() (func(), error) {
+	return s.admit(&s.reads)
+}
+
+func (s *Service) admit(n *int64) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.State != influxdb.DrainStateActive {
+		return nil, ErrDraining
+	}
+	*n++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			*n--
+			s.mu.Unlock()
+		})
+	}, nil
+}
+
+// Drain stops admitting writes and queries and starts draining in the
+// background. Calling Drain while a drain is in progress or complete returns
+// its status.
+func (s *Service) Drain(ctx context.Context, timeout time.Duration) (*influxdb.DrainStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.State == influxdb.DrainStateActive {
+		now := time.Now().UTC()
+		s.status = influxdb.DrainStatus{
+			State:     influxdb.DrainStateDraining,
+			StartedAt: &now,
+		}
+		s.done = make(chan struct{})
+
+		s.log.Info("Draining", zap.Duration("timeout", timeout),
+			zap.Int64("in_flight_writes", s.writes), zap.Int64("in_flight_queries", s.reads))
+		go s.drain(s.gen, timeout, s.done)
+	}
+	return s.statusLocked(), nil
+}
+
+// drain waits for in-flight requests, up to timeout, then checkpoints storage.
+func (s *Service) drain(gen int, timeout time.Duration, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		s.mu.Lock()
+		if s.gen != gen {
+			s.mu.Unlock()
+			return // Resumed
+		}
+		idle, expired := s.writes == 0 && s.reads == 0, !time.Now().Before(deadline)
+		if !idle && expired {
+			s.status.TimedOut = true
+			s.log.Warn("Timed out waiting for in-flight requests",
+				zap.Int64("in_flight_writes", s.writes), zap.Int64("in_flight_queries", s.reads))
+		}
+		s.mu.Unlock()
+
+		if idle || expired {
+			break
+		}
+		<-ticker.C
+	}
+
+	var (
+		id  influxdb.CheckpointID
+		err error
+	)
+	if s.checkpointer != nil {
+		id, err = s.checkpointer.Checkpoint(context.Background())
+		if err != nil {
+			s.log.Error("Failed to checkpoint storage", zap.Error(err))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen != gen {
+		return
+	}
+	now := time.Now().UTC()
+	s.status.State = influxdb.DrainStateDrained
+	s.status.CompletedAt = &now
+	s.status.Checkpointed = s.checkpointer != nil && err == nil
+	if err != nil {
+		s.status.Err = err.Error()
+	} else {
+		s.status.CheckpointID = id
+	}
+	s.log.Info("Drained", zap.Bool("checkpointed", s.status.Checkpointed), zap.Uint64("checkpoint_id", uint64(s.status.CheckpointID)), zap.Bool("timed_out", s.status.TimedOut))
+}
+
+// Wait blocks until the current drain completes or ctx is done. It returns
+// immediately if there is no drain in progress.
+func (s *Service) Wait(ctx context.Context) (*influxdb.DrainStatus, error) {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return s.DrainStatus(ctx)
+}
+
+// Resume admits writes and queries again, abandoning any drain in progress.
+func (s *Service) Resume(ctx context.Context) (*influxdb.DrainStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.status.State != influxdb.DrainStateActive {
+		s.log.Info("Resuming after drain")
+	}
+	s.gen++
+	s.done = nil
+	s.status = influxdb.DrainStatus{State: influxdb.DrainStateActive}
+	return s.statusLocked(), nil
+}
+
+// DrainStatus returns the progress of the current drain.
+func (s *Service) DrainStatus(ctx context.Context) (*influxdb.DrainStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statusLocked(), nil
+}
+
+func (s *Service) statusLocked() *influxdb.DrainStatus {
+	status := s.status
+	status.InFlightWrites = s.writes
+	status.InFlightQueries = s.reads
+	return &status
+}
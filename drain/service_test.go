@@ -0,0 +1,131 @@
+package drain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/drain"
+	"go.uber.org/zap/zaptest"
+)
+
+type checkpointer struct {
+	n   int
+	err error
+}
+
+func (c *checkpointer) Checkpoint(ctx context.Context) (influxdb.CheckpointID, error) {
+	c.n++
+	if c.err != nil {
+		return 0, c.err
+	}
+	return influxdb.CheckpointID(c.n), nil
+}
+
+func newService(t *testing.T, c drain.Checkpointer) *drain.Service {
+	s := drain.NewService(c, zaptest.NewLogger(t))
+	s.PollInterval = time.Millisecond
+	return s
+}
+
+func TestService_Drain(t *testing.T) {
+	ctx := context.Background()
+	c := &checkpointer{}
+	s := newService(t, c)
+
+	doneWrite, err := s.AdmitWrite()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doneQuery, err := s.AdmitQuery()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := s.Drain(ctx, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != influxdb.DrainStateDraining {
+		t.Fatalf("unexpected state: got %q, exp %q", status.State, influxdb.DrainStateDraining)
+	}
+	if status.InFlightWrites != 1 || status.InFlightQueries != 1 {
+		t.Fatalf("unexpected in-flight requests: got %d writes and %d queries", status.InFlightWrites, status.InFlightQueries)
+	}
+
+	if _, err := s.AdmitWrite(); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected write to be refused, got %v", err)
+	}
+	if _, err := s.AdmitQuery(); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected query to be refused, got %v", err)
+	}
+
+	doneWrite()
+	doneWrite() // Calling done more than once has no effect.
+	doneQuery()
+
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	status, err = s.Wait(wctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != influxdb.DrainStateDrained {
+		t.Fatalf("unexpected state: got %q, exp %q", status.State, influxdb.DrainStateDrained)
+	}
+	if !status.Checkpointed || status.CheckpointID != 1 || status.TimedOut || status.Err != "" {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	if status.InFlightWrites != 0 || status.InFlightQueries != 0 {
+		t.Fatalf("unexpected in-flight requests: got %d writes and %d queries", status.InFlightWrites, status.InFlightQueries)
+	}
+	if c.n != 1 {
+		t.Fatalf("unexpected number of checkpoints: got %d, exp 1", c.n)
+	}
+
+	status, err = s.Resume(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != influxdb.DrainStateActive {
+		t.Fatalf("unexpected state: got %q, exp %q", status.State, influxdb.DrainStateActive)
+	}
+	if _, err := s.AdmitWrite(); err != nil {
+		t.Fatalf("expected write to be admitted after resume, got %v", err)
+	}
+}
+
+func TestService_Drain_Timeout(t *testing.T) {
+	ctx := context.Background()
+	c := &checkpointer{err: errors.New("snapshots disabled")}
+	s := newService(t, c)
+
+	if _, err := s.AdmitWrite(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Drain(ctx, 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	status, err := s.Wait(wctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.State != influxdb.DrainStateDrained {
+		t.Fatalf("unexpected state: got %q, exp %q", status.State, influxdb.DrainStateDrained)
+	}
+	if !status.TimedOut {
+		t.Fatal("expected drain to time out")
+	}
+	if status.Checkpointed || status.Err != "snapshots disabled" {
+		t.Fatalf("unexpected checkpoint status: %+v", status)
+	}
+	if status.InFlightWrites != 1 {
+		t.Fatalf("unexpected in-flight writes: got %d, exp 1", status.InFlightWrites)
+	}
+}
@@ -11,6 +11,14 @@ type Duration struct {
 	time.Duration
 }
 
+// Value returns the duration of d, or zero if d is nil.
+func (d *Duration) Value() time.Duration {
+	if d == nil {
+		return 0
+	}
+	return d.Duration
+}
+
 // MarshalJSON implements json.Marshaler interface.
 func (d Duration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.String())
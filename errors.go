@@ -25,6 +25,7 @@ const (
 	EUnauthorized        = "unauthorized"
 	EMethodNotAllowed    = "method not allowed"
 	ETooLarge            = "request too large"
+	EPreconditionFailed  = "precondition failed"
 )
 
 // Error is the error struct of platform.
@@ -0,0 +1,586 @@
+// Package etcd provides a kv.Store backed by etcd, so that several influxd
+// instances can share their metadata.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/coreos/etcd/clientv3"
+	"github.com/coreos/etcd/clientv3/concurrency"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap"
+)
+
+// check that *KVStore implement kv.Store interface.
+var _ kv.Store = (*KVStore)(nil)
+
+const (
+	// DefaultPrefix is the prefix of the keys of the store.
+	DefaultPrefix = "/influxdb/"
+
+	// DefaultMaxTxnOps is the maximum number of operations of an etcd
+	// transaction, as configured by default on etcd servers.
+	DefaultMaxTxnOps = 128
+
+	// rangeBatchSize is the number of pairs read at a time.
+	rangeBatchSize = 1000
+
+	// sessionTTL is the TTL in seconds of the lease of the session holding
+	// the update lock. The lock is released if the instance holding it
+	// stops renewing the lease.
+	sessionTTL = 10
+)
+
+// KVStore is a kv.Store backed by etcd. The pairs of a bucket are the keys
+// of the prefix of the store and the hex encoded name of the bucket.
+//
+// View transactions read a snapshot of the store at the revision it had when
+// they started. Update transactions hold a lock shared by every instance
+// using the store, so they are applied one at a time, and their writes are
+// buffered and committed in a single etcd transaction when they complete. An
+// update fails if it writes more pairs than the number of operations allowed
+// in an etcd transaction.
+type KVStore struct {
+	endpoints []string
+	prefix    string
+	maxTxnOps int
+
+	client  *clientv3.Client
+	session *concurrency.Session
+	log     *zap.Logger
+
+	// updateMu serializes the updates of the instance. The etcd lock is
+	// re-entrant for the session shared by its updates, so it only serializes
+	// them with the updates of other instances.
+	updateMu sync.Mutex
+}
+
+// NewKVStore returns an instance of KVStore connecting to the etcd cluster of
+// the provided endpoints, storing its keys under prefix.
+func NewKVStore(log *zap.Logger, endpoints []string, prefix string) *KVStore {
+	return &KVStore{
+		endpoints: endpoints,
+		prefix:    prefix,
+		maxTxnOps: DefaultMaxTxnOps,
+		log:       log,
+	}
+}
+
+// WithMaxTxnOps sets the maximum number of operations of the etcd
+// transactions committing updates, which must not be greater than that
+// configured on the etcd servers. Updates writing more keys fail.
+func (s *KVStore) WithMaxTxnOps(n int) {
+	s.maxTxnOps = n
+}
+
+// Open connects to the etcd cluster.
+func (s *KVStore) Open(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   s.endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to connect to etcd: %v", err)
+	}
+
+	session, err := concurrency.NewSession(client, concurrency.WithTTL(sessionTTL))
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("unable to create etcd session: %v", err)
+	}
+	s.client, s.session = client, session
+
+	s.log.Info("Resources opened", zap.Strings("endpoints", s.endpoints), zap.String("prefix", s.prefix))
+	return nil
+}
+
+// Close the connection to the etcd cluster, releasing the update lock if it is
+// held.
+func (s *KVStore) Close() error {
+	if s.session != nil {
+		s.session.Close()
+	}
+	if s.client != nil {
+		return s.client.Close()
+	}
+	return nil
+}
+
+// Flush removes all keys from the store.
+func (s *KVStore) Flush(ctx context.Context) {
+	_, _ = s.client.Delete(ctx, s.pairsPrefix(), clientv3.WithPrefix())
+}
+
+// View opens up a read only transaction against the store.
+func (s *KVStore) View(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	resp, err := s.client.Get(ctx, s.pairsPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return err
+	}
+
+	return fn(&Tx{
+		store: s,
+		ctx:   ctx,
+		rev:   resp.Header.Revision,
+	})
+}
+
+// Update opens up an update transaction against the store. Its writes are
+// committed if fn returns no error.
+func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	mu := concurrency.NewMutex(s.session, s.prefix+"lock")
+	if err := mu.Lock(ctx); err != nil {
+		return fmt.Errorf("unable to acquire etcd lock: %v", err)
+	}
+	defer mu.Unlock(context.Background())
+
+	tx := &Tx{
+		store:    s,
+		ctx:      ctx,
+		writable: true,
+		writes:   make(map[string]*write),
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.commit(mu)
+}
+
+// Watch returns a channel of the changes committed to the keys of bucket with
+// prefix by every instance using the store. The channel is also closed if the
+// watch fails, as when the revisions it has not sent yet have been compacted.
+func (s *KVStore) Watch(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error) {
+	wch := s.client.Watch(ctx, s.bucketPrefix(bucket)+string(prefix), clientv3.WithPrefix(), clientv3.WithPrevKV())
+
+	events := make(chan kv.Event)
+	go func() {
+		defer close(events)
+		for resp := range wch {
+			if err := resp.Err(); err != nil {
+				s.log.Error("Failed to watch etcd keys", zap.Binary("bucket", bucket), zap.Error(err))
+				return
+			}
+			for _, ev := range resp.Events {
+				e, err := s.event(ev)
+				if err != nil {
+					s.log.Error("Failed to decode etcd event", zap.Error(err))
+					return
+				}
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// event returns the kv.Event of etcd event ev.
+func (s *KVStore) event(ev *clientv3.Event) (kv.Event, error) {
+	name, key, err := s.decodeKey(ev.Kv.Key)
+	if err != nil {
+		return kv.Event{}, err
+	}
+
+	e := kv.Event{Bucket: name, Key: key}
+	if ev.PrevKv != nil {
+		e.PrevValue = ev.PrevKv.Value
+	}
+	switch {
+	case ev.Type == clientv3.EventTypeDelete:
+		e.Type = kv.EventDelete
+	case ev.IsCreate():
+		e.Type, e.Value = kv.EventCreate, ev.Kv.Value
+	default:
+		e.Type, e.Value = kv.EventUpdate, ev.Kv.Value
+	}
+	return e, nil
+}
+
+// Backup copies all K:Vs to a writer, in BoltDB format, so that it can be
+// restored to a bolt store.
+func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := ioutil.TempFile("", "influxd-etcd-backup")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = s.View(ctx, func(tx kv.Tx) error {
+		t := tx.(*Tx)
+		return db.Update(func(btx *bolt.Tx) error {
+			prefix := s.pairsPrefix()
+			return t.scan(prefix, clientv3.GetPrefixRangeEnd(prefix), kv.CursorAscending, func(k, v []byte) (bool, error) {
+				name, key, err := s.decodeKey(k)
+				if err != nil {
+					return false, err
+				}
+				b, err := btx.CreateBucketIfNotExists(name)
+				if err != nil {
+					return false, err
+				}
+				return true, b.Put(key, v)
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(btx *bolt.Tx) error {
+		_, err := btx.WriteTo(w)
+		return err
+	})
+}
+
+// pairsPrefix returns the prefix of the keys of the pairs of every bucket.
+func (s *KVStore) pairsPrefix() string {
+	return s.prefix + "pairs/"
+}
+
+// bucketPrefix returns the prefix of the keys of the pairs of bucket b.
+func (s *KVStore) bucketPrefix(b []byte) string {
+	return s.pairsPrefix() + hex.EncodeToString(b) + "/"
+}
+
+// decodeKey returns the bucket and the key of the pair of etcd key k.
+func (s *KVStore) decodeKey(k []byte) ([]byte, []byte, error) {
+	k = bytes.TrimPrefix(k, []byte(s.pairsPrefix()))
+	i := bytes.IndexByte(k, '/')
+	if i < 0 {
+		return nil, nil, fmt.Errorf("invalid etcd key %q", k)
+	}
+	name, err := hex.DecodeString(string(k[:i]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid etcd key %q: %v", k, err)
+	}
+	return name, k[i+1:], nil
+}
+
+// write is a write buffered by an update transaction.
+type write struct {
+	value   []byte
+	deleted bool
+}
+
+// Tx is a transaction of the store. It implements kv.Tx.
+type Tx struct {
+	store    *KVStore
+	ctx      context.Context
+	rev      int64 // the revision read by view transactions
+	writable bool
+	writes   map[string]*write
+}
+
+// Context returns the context for the transaction.
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// WithContext sets the context for the transaction.
+func (tx *Tx) WithContext(ctx context.Context) {
+	tx.ctx = ctx
+}
+
+// Bucket retrieves the bucket named b. Buckets have no representation of
+// their own in etcd, so every bucket exists.
+func (tx *Tx) Bucket(b []byte) (kv.Bucket, error) {
+	return &Bucket{tx: tx, prefix: tx.store.bucketPrefix(b)}, nil
+}
+
+// readOpts returns the options of the reads of the transaction.
+func (tx *Tx) readOpts(opts ...clientv3.OpOption) []clientv3.OpOption {
+	if tx.rev > 0 {
+		opts = append(opts, clientv3.WithRev(tx.rev))
+	}
+	return opts
+}
+
+// scan calls fn with the pairs of the keys in [start, end) in direction,
+// including the buffered writes of the transaction, until fn returns false.
+func (tx *Tx) scan(start, end string, direction kv.CursorDirection, fn func(k, v []byte) (bool, error)) error {
+	var pairs []kv.Pair
+	from := start
+	for {
+		order := clientv3.SortAscend
+		if direction == kv.CursorDescending {
+			order = clientv3.SortDescend
+		}
+		resp, err := tx.store.client.Get(tx.ctx, from, tx.readOpts(
+			clientv3.WithRange(end),
+			clientv3.WithSort(clientv3.SortByKey, order),
+			clientv3.WithLimit(rangeBatchSize),
+		)...)
+		if err != nil {
+			return err
+		}
+		for _, p := range resp.Kvs {
+			pairs = append(pairs, kv.Pair{Key: p.Key, Value: p.Value})
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			break
+		}
+
+		// Continue after the last key read.
+		last := string(resp.Kvs[len(resp.Kvs)-1].Key)
+		if direction == kv.CursorDescending {
+			end = last
+		} else {
+			from = last + "\x00"
+		}
+	}
+
+	pairs = tx.applyWrites(pairs, start, end, direction)
+	for _, p := range pairs {
+		if ok, err := fn(p.Key, p.Value); err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyWrites returns pairs, which are the pairs of [start, end) in direction,
+// with the buffered writes of the transaction to the range applied.
+func (tx *Tx) applyWrites(pairs []kv.Pair, start, end string, direction kv.CursorDirection) []kv.Pair {
+	if len(tx.writes) == 0 {
+		return pairs
+	}
+
+	m := make(map[string][]byte, len(pairs))
+	for _, p := range pairs {
+		m[string(p.Key)] = p.Value
+	}
+	for k, w := range tx.writes {
+		if k < start || (end != "" && k >= end) {
+			continue
+		}
+		if w.deleted {
+			delete(m, k)
+		} else {
+			m[k] = w.value
+		}
+	}
+
+	pairs = pairs[:0]
+	for k, v := range m {
+		pairs = append(pairs, kv.Pair{Key: []byte(k), Value: v})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if direction == kv.CursorDescending {
+			return bytes.Compare(pairs[i].Key, pairs[j].Key) > 0
+		}
+		return bytes.Compare(pairs[i].Key, pairs[j].Key) < 0
+	})
+	return pairs
+}
+
+// commit commits the buffered writes of the transaction in a single etcd
+// transaction that succeeds only while mu is held, so that the update is
+// applied entirely or not at all.
+func (tx *Tx) commit(mu *concurrency.Mutex) error {
+	if len(tx.writes) == 0 {
+		return nil
+	}
+	if max := tx.store.maxTxnOps; max > 0 && len(tx.writes) > max {
+		return fmt.Errorf("update writes %d keys, more than the %d operations allowed in an etcd transaction", len(tx.writes), max)
+	}
+
+	keys := make([]string, 0, len(tx.writes))
+	for k := range tx.writes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ops := make([]clientv3.Op, 0, len(keys))
+	for _, k := range keys {
+		if w := tx.writes[k]; w.deleted {
+			ops = append(ops, clientv3.OpDelete(k))
+		} else {
+			ops = append(ops, clientv3.OpPut(k, string(w.value)))
+		}
+	}
+
+	resp, err := tx.store.client.Txn(tx.ctx).If(mu.IsOwner()).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("etcd lock lost before the update was committed")
+	}
+	return nil
+}
+
+// Bucket is a bucket of the store. It implements kv.Bucket.
+type Bucket struct {
+	tx     *Tx
+	prefix string
+}
+
+// Get retrieves the value at the provided key.
+func (b *Bucket) Get(key []byte) ([]byte, error) {
+	k := b.prefix + string(key)
+	if w, ok := b.tx.writes[k]; ok {
+		if w.deleted || len(w.value) == 0 {
+			return nil, kv.ErrKeyNotFound
+		}
+		return w.value, nil
+	}
+
+	resp, err := b.tx.store.client.Get(b.tx.ctx, k, b.tx.readOpts()...)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 || len(resp.Kvs[0].Value) == 0 {
+		return nil, kv.ErrKeyNotFound
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// Put sets the value at the provided key.
+func (b *Bucket) Put(key []byte, value []byte) error {
+	if !b.tx.writable {
+		return kv.ErrTxNotWritable
+	}
+	b.tx.writes[b.prefix+string(key)] = &write{value: append([]byte(nil), value...)}
+	return nil
+}
+
+// Delete removes the provided key.
+func (b *Bucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return kv.ErrTxNotWritable
+	}
+	b.tx.writes[b.prefix+string(key)] = &write{deleted: true}
+	return nil
+}
+
+// pairs returns the pairs of the bucket from seek in direction, matching the
+// prefix and predicate provided.
+func (b *Bucket) pairs(seek, prefix []byte, direction kv.CursorDirection, fn kv.CursorPredicateFunc) ([]kv.Pair, error) {
+	start, end := b.prefix, clientv3.GetPrefixRangeEnd(b.prefix)
+	if prefix != nil {
+		start = b.prefix + string(prefix)
+		end = clientv3.GetPrefixRangeEnd(start)
+	}
+	if len(seek) > 0 {
+		if direction == kv.CursorDescending {
+			end = b.prefix + string(seek) + "\x00"
+		} else {
+			start = b.prefix + string(seek)
+		}
+	}
+
+	var pairs []kv.Pair
+	err := b.tx.scan(start, end, direction, func(k, v []byte) (bool, error) {
+		k = k[len(b.prefix):]
+		if fn == nil || fn(k, v) {
+			pairs = append(pairs, kv.Pair{Key: k, Value: v})
+		}
+		return true, nil
+	})
+	return pairs, err
+}
+
+// Cursor reads the pairs of the bucket matching the provided hints and
+// returns a static cursor over them.
+func (b *Bucket) Cursor(opts ...kv.CursorHint) (kv.Cursor, error) {
+	var o kv.CursorHints
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var prefix []byte
+	if o.KeyPrefix != nil {
+		prefix = []byte(*o.KeyPrefix)
+	}
+
+	pairs, err := b.pairs(nil, prefix, kv.CursorAscending, o.PredicateFn)
+	if err != nil {
+		return nil, err
+	}
+	return kv.NewStaticCursor(pairs), nil
+}
+
+// ForwardCursor retrieves a cursor for iterating through the entries
+// in the key value store in a given direction (ascending / descending).
+// The pairs iterated are read when the cursor is created.
+func (b *Bucket) ForwardCursor(seek []byte, opts ...kv.CursorOption) (kv.ForwardCursor, error) {
+	config := kv.NewCursorConfig(opts...)
+	if config.Prefix != nil && !bytes.HasPrefix(seek, config.Prefix) {
+		return nil, fmt.Errorf("seek bytes %q not prefixed with %q: %w", string(seek), string(config.Prefix), kv.ErrSeekMissingPrefix)
+	}
+
+	pairs, err := b.pairs(seek, config.Prefix, config.Direction, config.Hints.PredicateFn)
+	if err != nil {
+		return nil, err
+	}
+	if config.SkipFirst && len(pairs) > 0 {
+		pairs = pairs[1:]
+	}
+	return &ForwardCursor{pairs: pairs}, nil
+}
+
+// ForwardCursor is a kv.ForwardCursor over the pairs read when it was
+// created.
+type ForwardCursor struct {
+	pairs  []kv.Pair
+	n      int
+	closed bool
+}
+
+// Next returns the next key/value pair in the cursor.
+func (c *ForwardCursor) Next() ([]byte, []byte) {
+	if c.closed || c.n >= len(c.pairs) {
+		return nil, nil
+	}
+	p := c.pairs[c.n]
+	c.n++
+	return p.Key, p.Value
+}
+
+// Err always returns nil, as the pairs are read when the cursor is created.
+func (c *ForwardCursor) Err() error {
+	return nil
+}
+
+// Close closes the cursor.
+func (c *ForwardCursor) Close() error {
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,133 @@
+package etcd
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTx_applyWrites(t *testing.T) {
+	s := NewKVStore(nil, nil, DefaultPrefix)
+	b, o := s.bucketPrefix([]byte("bucket")), s.bucketPrefix([]byte("other"))
+	tx := &Tx{store: s, writable: true, writes: map[string]*write{
+		b + "a": {deleted: true},
+		b + "b": {value: []byte("2")},
+		b + "d": {value: []byte("4")},
+		o + "c": {value: []byte("3")},
+	}}
+
+	pairs := []kv.Pair{
+		{Key: []byte(b + "a"), Value: []byte("1")},
+		{Key: []byte(b + "c"), Value: []byte("3")},
+	}
+	got := tx.applyWrites(pairs, b, b+"z", kv.CursorDescending)
+	exp := []kv.Pair{
+		{Key: []byte(b + "d"), Value: []byte("4")},
+		{Key: []byte(b + "c"), Value: []byte("3")},
+		{Key: []byte(b + "b"), Value: []byte("2")},
+	}
+	if !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected pairs: -got/+exp\n%v", cmp.Diff(got, exp))
+	}
+}
+
+func TestKVStore_decodeKey(t *testing.T) {
+	s := NewKVStore(nil, nil, DefaultPrefix)
+	name, key, err := s.decodeKey([]byte(s.bucketPrefix([]byte("a/b")) + "c/d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(name) != "a/b" || string(key) != "c/d" {
+		t.Fatalf("got bucket %q and key %q, expected a/b and c/d", name, key)
+	}
+}
+
+func TestTx_commitTooLarge(t *testing.T) {
+	s := NewKVStore(nil, nil, DefaultPrefix)
+	s.WithMaxTxnOps(2)
+	b := s.bucketPrefix([]byte("bucket"))
+	tx := &Tx{store: s, writable: true, writes: map[string]*write{
+		b + "a": {value: []byte("1")},
+		b + "b": {value: []byte("2")},
+		b + "c": {deleted: true},
+	}}
+
+	// The update is rejected before reaching etcd, so nothing of it is
+	// applied.
+	if err := tx.commit(nil); err == nil {
+		t.Fatal("expected an error committing more writes than allowed")
+	}
+}
+
+func TestKVStore_ConcurrentUpdate(t *testing.T) {
+	endpoints := os.Getenv("INFLUXDB_ETCD_TEST_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("INFLUXDB_ETCD_TEST_ENDPOINTS is not set")
+	}
+
+	s := NewKVStore(zaptest.NewLogger(t), strings.Split(endpoints, ","), "/influxdb-test/")
+	if err := s.Open(context.Background()); err != nil {
+		t.Fatalf("failed to open etcd store: %v", err)
+	}
+	defer s.Close()
+	s.Flush(context.Background())
+
+	// Every update increments a counter read in the same transaction, so an
+	// increment is lost if two updates of the instance run concurrently.
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.Update(context.Background(), func(tx kv.Tx) error {
+				b, err := tx.Bucket([]byte("bucket"))
+				if err != nil {
+					return err
+				}
+				var count int
+				if v, err := b.Get([]byte("count")); err == nil {
+					count, _ = strconv.Atoi(string(v))
+				} else if !kv.IsNotFound(err) {
+					return err
+				}
+				time.Sleep(10 * time.Millisecond)
+				return b.Put([]byte("count"), []byte(strconv.Itoa(count+1)))
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := s.View(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		v, err := b.Get([]byte("count"))
+		if err != nil {
+			return err
+		}
+		if string(v) != strconv.Itoa(n) {
+			t.Errorf("got count %s, expected %d", v, n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
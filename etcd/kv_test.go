@@ -0,0 +1,57 @@
+package etcd_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/etcd"
+	"github.com/influxdata/influxdb/kv"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewTestKVStore returns a store of the etcd cluster of the comma separated
+// endpoints of INFLUXDB_ETCD_TEST_ENDPOINTS, emptied of all keys, skipping the
+// test if it is not set.
+func NewTestKVStore(t *testing.T) (*etcd.KVStore, func()) {
+	endpoints := os.Getenv("INFLUXDB_ETCD_TEST_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("INFLUXDB_ETCD_TEST_ENDPOINTS is not set")
+	}
+
+	s := etcd.NewKVStore(zaptest.NewLogger(t), strings.Split(endpoints, ","), "/influxdb-test/")
+	if err := s.Open(context.Background()); err != nil {
+		t.Fatalf("failed to open etcd store: %v", err)
+	}
+	s.Flush(context.Background())
+	return s, func() { s.Close() }
+}
+
+func initKVStore(f platformtesting.KVStoreFields, t *testing.T) (kv.Store, func()) {
+	s, closeFn := NewTestKVStore(t)
+
+	err := s.Update(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket(f.Bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range f.Pairs {
+			if err := b.Put(p.Key, p.Value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to put keys: %v", err)
+	}
+	return s, closeFn
+}
+
+func TestKVStore(t *testing.T) {
+	platformtesting.KVStore(initKVStore, t)
+}
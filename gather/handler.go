@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/nats"
@@ -14,7 +15,9 @@ import (
 type handler struct {
 	Scraper   Scraper
 	Publisher nats.Publisher
-	log       *zap.Logger
+	// Status records the outcome of each scrape, if set.
+	Status influxdb.ScraperTargetStatusService
+	log    *zap.Logger
 }
 
 // Process consumes scraper target from scraper target queue,
@@ -29,9 +32,18 @@ func (h *handler) Process(s nats.Subscription, m nats.Message) {
 		return
 	}
 
-	ms, err := h.Scraper.Gather(context.TODO(), *req)
+	ctx := context.Background()
+	if req.Timeout.Value() > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout.Value())
+		defer cancel()
+	}
+
+	start := time.Now()
+	ms, err := h.gather(ctx, req)
+	h.recordStatus(req, start, time.Since(start), err)
 	if err != nil {
-		h.log.Error("Unable to gather", zap.Error(err))
+		h.log.Error("Unable to gather", zap.String("scraper_target_id", req.ID.String()), zap.Error(err))
 		return
 	}
 
@@ -48,3 +60,28 @@ func (h *handler) Process(s nats.Subscription, m nats.Message) {
 	}
 
 }
+
+// gather scrapes the target and applies its relabel rules.
+func (h *handler) gather(ctx context.Context, target *influxdb.ScraperTarget) (MetricsCollection, error) {
+	rl, err := newRelabeler(target.RelabelRules)
+	if err != nil {
+		return MetricsCollection{}, err
+	}
+
+	ms, err := h.Scraper.Gather(ctx, *target)
+	if err != nil {
+		return ms, err
+	}
+	ms.MetricsSlice = rl.Relabel(ms.MetricsSlice)
+	return ms, nil
+}
+
+func (h *handler) recordStatus(target *influxdb.ScraperTarget, start time.Time, dur time.Duration, scrapeErr error) {
+	if h.Status == nil || !target.ID.Valid() {
+		return
+	}
+
+	if _, err := h.Status.RecordTargetScrape(context.Background(), target.ID, start, dur, scrapeErr); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		h.log.Error("Unable to record scraper target status", zap.String("scraper_target_id", target.ID.String()), zap.Error(err))
+	}
+}
@@ -2,6 +2,8 @@ package gather
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"math"
@@ -17,19 +19,116 @@ import (
 
 // prometheusScraper handles parsing prometheus metrics.
 // implements Scraper interfaces.
-type prometheusScraper struct{}
+type prometheusScraper struct {
+	// Secrets resolves the credentials referenced by a target's auth and
+	// TLS configuration. It is only required by targets that use them.
+	Secrets influxdb.SecretService
+}
 
 // Gather parse metrics from a scraper target url.
 func (p *prometheusScraper) Gather(ctx context.Context, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
-	resp, err := http.Get(target.URL)
+	client, err := p.client(ctx, target)
+	if err != nil {
+		return collected, err
+	}
+	if t, ok := client.Transport.(*http.Transport); ok {
+		defer t.CloseIdleConnections()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+	if err != nil {
+		return collected, err
+	}
+	req = req.WithContext(ctx)
+	if err := p.authorize(ctx, req, target); err != nil {
+		return collected, err
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return collected, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return collected, fmt.Errorf("unexpected response status from scraper target: %s", resp.Status)
+	}
+
 	return p.parse(resp.Body, resp.Header, target)
 }
 
+// authorize adds the target's credentials to req.
+func (p *prometheusScraper) authorize(ctx context.Context, req *http.Request, target influxdb.ScraperTarget) error {
+	if target.Auth == nil {
+		return nil
+	}
+
+	switch target.Auth.Type {
+	case influxdb.ScraperAuthBasic:
+		var password string
+		if target.Auth.PasswordSecret != "" {
+			var err error
+			if password, err = p.loadSecret(ctx, target.OrgID, target.Auth.PasswordSecret); err != nil {
+				return err
+			}
+		}
+		req.SetBasicAuth(target.Auth.Username, password)
+	case influxdb.ScraperAuthBearer:
+		token, err := p.loadSecret(ctx, target.OrgID, target.Auth.TokenSecret)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// client returns an HTTP client configured with the target's TLS settings.
+func (p *prometheusScraper) client(ctx context.Context, target influxdb.ScraperTarget) (*http.Client, error) {
+	if target.TLS == nil {
+		return http.DefaultClient, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         target.TLS.ServerName,
+		InsecureSkipVerify: target.TLS.InsecureSkipVerify,
+	}
+
+	if target.TLS.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(target.TLS.CACert)) {
+			return nil, fmt.Errorf("unable to parse CA certificate for scraper target %s", target.ID)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if target.TLS.ClientCert != "" {
+		key, err := p.loadSecret(ctx, target.OrgID, target.TLS.ClientKeySecret)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair([]byte(target.TLS.ClientCert), []byte(key))
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate for scraper target %s: %v", target.ID, err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: cfg,
+		},
+	}, nil
+}
+
+func (p *prometheusScraper) loadSecret(ctx context.Context, orgID influxdb.ID, key string) (string, error) {
+	if p.Secrets == nil {
+		return "", fmt.Errorf("unable to load secret %q: no secret service configured", key)
+	}
+	return p.Secrets.LoadSecret(ctx, orgID, key)
+}
+
 func (p *prometheusScraper) parse(r io.Reader, header http.Header, target influxdb.ScraperTarget) (collected MetricsCollection, err error) {
 	var parser expfmt.TextParser
 	now := time.Now()
@@ -0,0 +1,131 @@
+package gather
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+const (
+	defaultRelabelSeparator   = ";"
+	defaultRelabelRegex       = "(.*)"
+	defaultRelabelReplacement = "$1"
+)
+
+// relabeler applies a target's relabel rules to scraped metrics.
+type relabeler struct {
+	rules []relabelRule
+}
+
+type relabelRule struct {
+	influxdb.ScraperRelabelRule
+	re *regexp.Regexp
+}
+
+// newRelabeler compiles rules. Rules are expected to have been validated when
+// the target was stored.
+func newRelabeler(rules []influxdb.ScraperRelabelRule) (*relabeler, error) {
+	r := &relabeler{rules: make([]relabelRule, 0, len(rules))}
+	for _, rule := range rules {
+		if rule.Separator == "" {
+			rule.Separator = defaultRelabelSeparator
+		}
+		if rule.Regex == "" {
+			rule.Regex = defaultRelabelRegex
+		}
+		if rule.Replacement == "" {
+			rule.Replacement = defaultRelabelReplacement
+		}
+		if rule.Action == "" {
+			rule.Action = influxdb.RelabelReplace
+		}
+
+		// Like Prometheus, the expression must match the whole value.
+		re, err := regexp.Compile("^(?:" + rule.Regex + ")$")
+		if err != nil {
+			return nil, err
+		}
+		r.rules = append(r.rules, relabelRule{ScraperRelabelRule: rule, re: re})
+	}
+	return r, nil
+}
+
+// Relabel applies the rules to each metric, returning the metrics that were
+// not dropped.
+func (r *relabeler) Relabel(ms MetricsSlice) MetricsSlice {
+	if len(r.rules) == 0 {
+		return ms
+	}
+
+	out := ms[:0]
+	for _, m := range ms {
+		if r.relabel(&m) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// relabel applies the rules to m and returns false if m should be dropped.
+func (r *relabeler) relabel(m *Metrics) bool {
+	labels := make(map[string]string, len(m.Tags)+1)
+	for k, v := range m.Tags {
+		labels[k] = v
+	}
+	labels[influxdb.ScraperMetricNameLabel] = m.Name
+
+	for _, rule := range r.rules {
+		switch rule.Action {
+		case influxdb.RelabelReplace:
+			v := sourceValue(labels, rule)
+			idx := rule.re.FindStringSubmatchIndex(v)
+			if idx == nil {
+				continue
+			}
+			res := string(rule.re.ExpandString(nil, rule.Replacement, v, idx))
+			if res == "" {
+				delete(labels, rule.TargetLabel)
+			} else {
+				labels[rule.TargetLabel] = res
+			}
+		case influxdb.RelabelKeep:
+			if !rule.re.MatchString(sourceValue(labels, rule)) {
+				return false
+			}
+		case influxdb.RelabelDrop:
+			if rule.re.MatchString(sourceValue(labels, rule)) {
+				return false
+			}
+		case influxdb.RelabelLabelDrop:
+			for k := range labels {
+				if k != influxdb.ScraperMetricNameLabel && rule.re.MatchString(k) {
+					delete(labels, k)
+				}
+			}
+		case influxdb.RelabelLabelKeep:
+			for k := range labels {
+				if k != influxdb.ScraperMetricNameLabel && !rule.re.MatchString(k) {
+					delete(labels, k)
+				}
+			}
+		}
+	}
+
+	name := labels[influxdb.ScraperMetricNameLabel]
+	if name == "" {
+		// A metric cannot be written without a name.
+		return false
+	}
+	delete(labels, influxdb.ScraperMetricNameLabel)
+	m.Name, m.Tags = name, labels
+	return true
+}
+
+func sourceValue(labels map[string]string, rule relabelRule) string {
+	vs := make([]string, len(rule.SourceLabels))
+	for i, l := range rule.SourceLabels {
+		vs[i] = labels[l]
+	}
+	return strings.Join(vs, rule.Separator)
+}
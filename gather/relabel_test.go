@@ -0,0 +1,129 @@
+package gather
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+)
+
+func TestRelabeler_Relabel(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules []influxdb.ScraperRelabelRule
+		ms    MetricsSlice
+		want  MetricsSlice
+	}{
+		{
+			name: "no rules",
+			ms: MetricsSlice{
+				{Name: "go_goroutines", Tags: map[string]string{"job": "a"}},
+			},
+			want: MetricsSlice{
+				{Name: "go_goroutines", Tags: map[string]string{"job": "a"}},
+			},
+		},
+		{
+			name: "replace tag from name",
+			rules: []influxdb.ScraperRelabelRule{
+				{
+					SourceLabels: []string{influxdb.ScraperMetricNameLabel},
+					Regex:        "([a-z]+)_.*",
+					TargetLabel:  "prefix",
+				},
+			},
+			ms: MetricsSlice{
+				{Name: "go_goroutines", Tags: map[string]string{}},
+			},
+			want: MetricsSlice{
+				{Name: "go_goroutines", Tags: map[string]string{"prefix": "go"}},
+			},
+		},
+		{
+			name: "rename metric",
+			rules: []influxdb.ScraperRelabelRule{
+				{
+					SourceLabels: []string{influxdb.ScraperMetricNameLabel, "job"},
+					Regex:        "(.*);(.*)",
+					Replacement:  "${2}_$1",
+					TargetLabel:  influxdb.ScraperMetricNameLabel,
+				},
+			},
+			ms: MetricsSlice{
+				{Name: "up", Tags: map[string]string{"job": "node"}},
+			},
+			want: MetricsSlice{
+				{Name: "node_up", Tags: map[string]string{"job": "node"}},
+			},
+		},
+		{
+			name: "keep and drop",
+			rules: []influxdb.ScraperRelabelRule{
+				{
+					SourceLabels: []string{influxdb.ScraperMetricNameLabel},
+					Regex:        "go_.*",
+					Action:       influxdb.RelabelKeep,
+				},
+				{
+					SourceLabels: []string{"version"},
+					Regex:        "go1\\.10.*",
+					Action:       influxdb.RelabelDrop,
+				},
+			},
+			ms: MetricsSlice{
+				{Name: "go_goroutines", Tags: map[string]string{}},
+				{Name: "process_cpu_seconds_total", Tags: map[string]string{}},
+				{Name: "go_info", Tags: map[string]string{"version": "go1.10.3"}},
+			},
+			want: MetricsSlice{
+				{Name: "go_goroutines", Tags: map[string]string{}},
+			},
+		},
+		{
+			name: "label drop and keep",
+			rules: []influxdb.ScraperRelabelRule{
+				{Regex: "tmp_.*", Action: influxdb.RelabelLabelDrop},
+				{Regex: "job|tmp_b|instance", Action: influxdb.RelabelLabelKeep},
+			},
+			ms: MetricsSlice{
+				{Name: "up", Tags: map[string]string{"job": "a", "tmp_a": "x", "tmp_b": "y", "env": "prod"}},
+			},
+			want: MetricsSlice{
+				{Name: "up", Tags: map[string]string{"job": "a"}},
+			},
+		},
+		{
+			name: "empty name is dropped",
+			rules: []influxdb.ScraperRelabelRule{
+				{
+					SourceLabels: []string{"missing"},
+					Replacement:  "",
+					Regex:        "",
+					TargetLabel:  influxdb.ScraperMetricNameLabel,
+				},
+			},
+			ms: MetricsSlice{
+				{Name: "up", Tags: map[string]string{}},
+			},
+			want: MetricsSlice{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := newRelabeler(c.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := r.Relabel(c.ms)
+			if len(got) != len(c.want) {
+				t.Fatalf("unexpected number of metrics: got %d, want %d", len(got), len(c.want))
+			}
+			for i := range got {
+				if diff := cmp.Diff(got[i], c.want[i], metricsCmpOption); diff != "" {
+					t.Errorf("unexpected metric %d: got %v, want %v", i, got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
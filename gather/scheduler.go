@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb"
@@ -19,6 +20,9 @@ const (
 	promTargetSubject = "promTarget"
 )
 
+// minGatherWait is the shortest time the scheduler waits between gathers.
+const minGatherWait = time.Second
+
 // Scheduler is struct to run scrape jobs.
 type Scheduler struct {
 	Targets influxdb.ScraperTargetStoreService
@@ -30,9 +34,39 @@ type Scheduler struct {
 	// Publisher will send the gather requests and gathered metrics to the queue.
 	Publisher nats.Publisher
 
+	// Secrets resolves scraper target credentials.
+	Secrets influxdb.SecretService
+	// Status records the outcome of each scrape.
+	Status influxdb.ScraperTargetStatusService
+
 	log *zap.Logger
 
 	gather chan struct{}
+
+	// lastGather holds the time each target was last scheduled. It is only
+	// accessed by the run loop.
+	lastGather map[influxdb.ID]time.Time
+	// wait is the time, in nanoseconds, until the next target is due.
+	wait int64
+}
+
+// SchedulerOption sets an optional dependency of the Scheduler.
+type SchedulerOption func(*Scheduler)
+
+// WithSecretService sets the service used to resolve the secrets referenced
+// by scraper target auth and TLS configuration.
+func WithSecretService(svc influxdb.SecretService) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Secrets = svc
+	}
+}
+
+// WithTargetStatusService sets the service used to record the outcome of
+// each scrape.
+func WithTargetStatusService(svc influxdb.ScraperTargetStatusService) SchedulerOption {
+	return func(s *Scheduler) {
+		s.Status = svc
+	}
 }
 
 // NewScheduler creates a new Scheduler and subscriptions for scraper jobs.
@@ -44,6 +78,7 @@ func NewScheduler(
 	s nats.Subscriber,
 	interval time.Duration,
 	timeout time.Duration,
+	opts ...SchedulerOption,
 ) (*Scheduler, error) {
 	if interval == 0 {
 		interval = 60 * time.Second
@@ -52,18 +87,25 @@ func NewScheduler(
 		timeout = 30 * time.Second
 	}
 	scheduler := &Scheduler{
-		Targets:   targets,
-		Interval:  interval,
-		Timeout:   timeout,
-		Publisher: p,
-		log:       log,
-		gather:    make(chan struct{}, 100),
+		Targets:    targets,
+		Interval:   interval,
+		Timeout:    timeout,
+		Publisher:  p,
+		log:        log,
+		gather:     make(chan struct{}, 100),
+		lastGather: make(map[influxdb.ID]time.Time),
+		wait:       int64(interval),
+	}
+
+	for _, o := range opts {
+		o(scheduler)
 	}
 
 	for i := 0; i < numScrapers; i++ {
 		err := s.Subscribe(promTargetSubject, "metrics", &handler{
-			Scraper:   new(prometheusScraper),
+			Scraper:   &prometheusScraper{Secrets: scheduler.Secrets},
 			Publisher: p,
+			Status:    scheduler.Status,
 			log:       log,
 		})
 		if err != nil {
@@ -82,7 +124,7 @@ func (s *Scheduler) Run(ctx context.Context) error {
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(s.Interval): // TODO: change to ticker because of garbage collection
+			case <-time.After(time.Duration(atomic.LoadInt64(&s.wait))): // TODO: change to ticker because of garbage collection
 				s.gather <- struct{}{}
 			}
 		}
@@ -113,12 +155,51 @@ func (s *Scheduler) doGather(ctx context.Context) {
 		tracing.LogError(span, err)
 		return
 	}
+	now := time.Now()
+	wait := s.Interval
+	seen := make(map[influxdb.ID]bool, len(targets))
 	for _, target := range targets {
+		seen[target.ID] = true
+
+		// Targets without their own interval are scraped on every gather.
+		interval := target.Interval.Value()
+		if interval > 0 {
+			if last, ok := s.lastGather[target.ID]; ok {
+				if due := last.Add(interval).Sub(now); due > 0 {
+					if due < wait {
+						wait = due
+					}
+					continue
+				}
+			}
+			if interval < wait {
+				wait = interval
+			}
+		}
+
+		s.lastGather[target.ID] = now
 		if err := requestScrape(target, s.Publisher); err != nil {
 			s.log.Error("JSON encoding error", zap.Error(err))
 			tracing.LogError(span, err)
 		}
 	}
+
+	// Forget targets that have been removed.
+	for id := range s.lastGather {
+		if !seen[id] {
+			delete(s.lastGather, id)
+		}
+	}
+
+	// Avoid spinning on very short target intervals, but never wait longer
+	// than the scheduler's own interval.
+	if wait < minGatherWait {
+		wait = minGatherWait
+		if s.Interval < wait {
+			wait = s.Interval
+		}
+	}
+	atomic.StoreInt64(&s.wait, int64(wait))
 }
 
 func requestScrape(t influxdb.ScraperTarget, publisher nats.Publisher) error {
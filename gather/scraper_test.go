@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
 )
 
 var (
@@ -155,6 +156,91 @@ func TestPrometheusScraper(t *testing.T) {
 	}
 }
 
+func TestPrometheusScraper_Auth(t *testing.T) {
+	secrets := &mock.SecretService{
+		LoadSecretFn: func(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+			switch k {
+			case "password":
+				return "p@ss", nil
+			case "token":
+				return "t0ken", nil
+			}
+			return "", &influxdb.Error{Code: influxdb.ENotFound, Msg: "secret not found"}
+		},
+	}
+
+	cases := []struct {
+		name   string
+		auth   *influxdb.ScraperAuth
+		header string
+		hasErr bool
+	}{
+		{
+			name: "basic",
+			auth: &influxdb.ScraperAuth{
+				Type:           influxdb.ScraperAuthBasic,
+				Username:       "user",
+				PasswordSecret: "password",
+			},
+			header: "Basic dXNlcjpwQHNz",
+		},
+		{
+			name: "bearer",
+			auth: &influxdb.ScraperAuth{
+				Type:        influxdb.ScraperAuthBearer,
+				TokenSecret: "token",
+			},
+			header: "Bearer t0ken",
+		},
+		{
+			name: "missing secret",
+			auth: &influxdb.ScraperAuth{
+				Type:        influxdb.ScraperAuthBearer,
+				TokenSecret: "missing",
+			},
+			hasErr: true,
+		},
+		{
+			name:   "unauthorized",
+			hasErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if c.header == "" || r.Header.Get("Authorization") != c.header {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+				w.Write([]byte(sampleResp))
+			}))
+			defer ts.Close()
+
+			scraper := &prometheusScraper{Secrets: secrets}
+			results, err := scraper.Gather(context.Background(), influxdb.ScraperTarget{
+				URL:      ts.URL + "/metrics",
+				OrgID:    *orgID,
+				BucketID: *bucketID,
+				Auth:     c.auth,
+			})
+			if c.hasErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results.MetricsSlice) != 8 {
+				t.Fatalf("unexpected number of metrics: got %d, want 8", len(results.MetricsSlice))
+			}
+		})
+	}
+}
+
 const sampleResp = `
 # 	HELP go_gc_duration_seconds A summary of the GC invocation durations.
 # TYPE go_gc_duration_seconds summary
@@ -266,7 +352,7 @@ func (s *mockStorage) UpdateTarget(ctx context.Context, update *influxdb.Scraper
 	defer s.Unlock()
 
 	for k, v := range s.Targets {
-		if v.ID.String() == string(update.ID) {
+		if v.ID == update.ID {
 			s.Targets[k] = *update
 			break
 		}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/influxdata/influxdb"
@@ -28,6 +29,9 @@ type APIBackend struct {
 	Logger     *zap.Logger
 	influxdb.HTTPErrorHandler
 	SessionRenewDisabled bool
+	// SessionIdleTimeout is how long sessions last after they are last used,
+	// or influxdb.RenewSessionTime if it is zero.
+	SessionIdleTimeout time.Duration
 	// MaxBatchSizeBytes is the maximum number of bytes which can be written
 	// in a single points batch
 	MaxBatchSizeBytes int64
@@ -44,24 +48,68 @@ type APIBackend struct {
 	// write request. A value of zero specifies there is no limit.
 	WriteParserMaxValues int
 
+	// MaxConcurrentWrites is the maximum number of write requests that may be
+	// handled at once. A value of zero specifies there is no limit.
+	MaxConcurrentWrites int
+
 	NewBucketService func(*influxdb.Source) (influxdb.BucketService, error)
 	NewQueryService  func(*influxdb.Source) (query.ProxyQueryService, error)
 
 	WriteEventRecorder metric.EventRecorder
 	QueryEventRecorder metric.EventRecorder
 
+	// DrainGate, if set, refuses writes and queries while the server is draining.
+	DrainGate DrainGate
+
+	// WriteHinter, if set, provides hints to clients on how to shape their
+	// writes in the headers of write responses.
+	WriteHinter WriteHinter
+
+	// TokenRateLimiter, if set, enforces the rate limits of authorizations.
+	TokenRateLimiter *TokenRateLimiter
+
+	// SignedRequestVerifier, if set, authenticates writes signed with the
+	// token of an authorization.
+	SignedRequestVerifier *SignedRequestVerifier
+
 	PointsWriter                    storage.PointsWriter
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
+	WriteStatsService               influxdb.WriteStatsService
+	ShardStatsService               influxdb.ShardStatsService
+	CardinalityService              influxdb.CardinalityService
+	DrainService                    influxdb.DrainService
+	CompactionService               influxdb.CompactionService
+	SeriesMoveService               influxdb.SeriesMoveService
+	IndexRebuildService             influxdb.IndexRebuildService
+	SnapshotService                 influxdb.SnapshotService
+	WALRecoveryService              influxdb.WALRecoveryService
 	KVBackupService                 influxdb.KVBackupService
+	MetadataStoreService            influxdb.MetadataStoreService
+	MetadataBundleService           influxdb.MetadataBundleService
 	AuthorizationService            influxdb.AuthorizationService
+	AuthorizationBatchService       influxdb.AuthorizationBatchService
+	AuthorizationLifecycleService   influxdb.AuthorizationLifecycleService
 	BucketService                   influxdb.BucketService
+	BucketRestoreService            influxdb.BucketRestoreService
+	BucketTransferService           influxdb.BucketTransferService
+	BucketUsageService              influxdb.BucketUsageService
+	BucketExportService             influxdb.BucketExportService
+	MeasurementSchemaService        influxdb.MeasurementSchemaService
 	SessionService                  influxdb.SessionService
+	SessionManagementService        influxdb.SessionManagementService
 	UserService                     influxdb.UserService
 	OrganizationService             influxdb.OrganizationService
+	OrgQuotaService                 influxdb.OrgQuotaService
+	NetworkPolicyService            influxdb.NetworkPolicyService
 	UserResourceMappingService      influxdb.UserResourceMappingService
 	LabelService                    influxdb.LabelService
 	DashboardService                influxdb.DashboardService
+	DBRPMappingService              influxdb.DBRPMappingService
+	MaterializedViewService         influxdb.MaterializedViewService
+	LDAPService                     influxdb.LDAPService
+	LDAPSyncService                 influxdb.LDAPSyncService
+	AuditLogService                 influxdb.AuditLogService
 	DashboardOperationLogService    influxdb.DashboardOperationLogService
 	BucketOperationLogService       influxdb.BucketOperationLogService
 	UserOperationLogService         influxdb.UserOperationLogService
@@ -71,11 +119,14 @@ type APIBackend struct {
 	PasswordsService                influxdb.PasswordsService
 	OnboardingService               influxdb.OnboardingService
 	InfluxQLService                 query.ProxyQueryService
+	RunningQueryService             influxdb.RunningQueryService
+	QueryExplainer                  query.Explainer
 	FluxService                     query.ProxyQueryService
 	TaskService                     influxdb.TaskService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
+	ScraperTargetStatusService      influxdb.ScraperTargetStatusService
 	SecretService                   influxdb.SecretService
 	LookupService                   influxdb.LookupService
 	ChronografService               *server.Service
@@ -83,6 +134,7 @@ type APIBackend struct {
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	RevisionService                 influxdb.RevisionService
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -97,6 +149,10 @@ func (b *APIBackend) PrometheusCollectors() []prometheus.Collector {
 		cs = append(cs, pc.PrometheusCollectors()...)
 	}
 
+	if b.TokenRateLimiter != nil {
+		cs = append(cs, b.TokenRateLimiter.PrometheusCollectors()...)
+	}
+
 	return cs
 }
 
@@ -117,16 +173,34 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		Router: newBaseChiRouter(b.HTTPErrorHandler),
 	}
 
+	h.Use(traceDebugMW(b.HTTPErrorHandler))
+	if b.NetworkPolicyService != nil {
+		h.Use(networkPolicyMW(b.Logger.With(zap.String("service", "network_policy")), b.HTTPErrorHandler, b.NetworkPolicyService, b.AuditLogService))
+	}
+	if b.TokenRateLimiter != nil {
+		h.Use(rateLimitMW(b.HTTPErrorHandler, b.TokenRateLimiter))
+	}
+	if b.AuditLogService != nil {
+		h.Use(auditMW(b.Logger.With(zap.String("service", "audit")), b.AuditLogService))
+	}
+
 	b.UserResourceMappingService = authorizer.NewURMService(b.OrgLookupService, b.UserResourceMappingService)
 
 	h.Mount("/api/v2", serveLinksHandler(b.HTTPErrorHandler))
 
 	authorizationBackend := NewAuthorizationBackend(b.Logger.With(zap.String("handler", "authorization")), b)
 	authorizationBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
+	authorizationBackend.AuthorizationBatchService = authorizer.NewAuthorizationBatchService(b.AuthorizationBatchService)
+	authorizationBackend.AuthorizationLifecycleService = authorizer.NewAuthorizationLifecycleService(b.AuthorizationLifecycleService, b.AuthorizationService)
 	h.Mount(prefixAuthorization, NewAuthorizationHandler(b.Logger, authorizationBackend))
 
 	bucketBackend := NewBucketBackend(b.Logger.With(zap.String("handler", "bucket")), b)
 	bucketBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	bucketBackend.MeasurementSchemaService = authorizer.NewMeasurementSchemaService(b.MeasurementSchemaService)
+	bucketBackend.BucketRestoreService = authorizer.NewBucketRestoreService(b.BucketRestoreService)
+	bucketBackend.BucketTransferService = authorizer.NewBucketTransferService(b.BucketTransferService, b.BucketService)
+	bucketBackend.BucketUsageService = authorizer.NewBucketUsageService(b.BucketUsageService, b.BucketService)
+	bucketBackend.BucketExportService = authorizer.NewBucketExportService(b.BucketExportService, b.BucketService)
 	h.Mount(prefixBuckets, NewBucketHandler(b.Logger, bucketBackend))
 
 	checkBackend := NewCheckBackend(b.Logger.With(zap.String("handler", "check")), b)
@@ -140,6 +214,11 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	dashboardBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
 	h.Mount(prefixDashboards, NewDashboardHandler(b.Logger, dashboardBackend))
 
+	dbrpMappingBackend := NewDBRPMappingBackend(b.Logger.With(zap.String("handler", "dbrp")), b)
+	dbrpMappingBackend.DBRPMappingService = authorizer.NewDBRPMappingService(b.DBRPMappingService)
+	dbrpMappingBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.Mount(prefixDBRPs, NewDBRPMappingHandler(b.Logger, dbrpMappingBackend))
+
 	deleteBackend := NewDeleteBackend(b.Logger.With(zap.String("handler", "delete")), b)
 	h.Mount(prefixDelete, NewDeleteHandler(b.Logger, deleteBackend))
 
@@ -147,10 +226,46 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(prefixDocuments, NewDocumentHandler(documentBackend))
 
 	fluxBackend := NewFluxBackend(b.Logger.With(zap.String("handler", "query")), b)
-	h.Mount(prefixQuery, NewFluxHandler(b.Logger, fluxBackend))
+	var fluxHandler http.Handler = NewFluxHandler(b.Logger, fluxBackend)
+	if b.DrainGate != nil {
+		fluxHandler = newDrainQueryGate(b.HTTPErrorHandler, b.DrainGate, fluxHandler)
+	}
+	h.Mount(prefixQuery, fluxHandler)
+
+	legacyQueryBackend := NewLegacyQueryBackend(b.Logger.With(zap.String("handler", "legacy_query")), b)
+	var legacyQueryHandler http.Handler = NewLegacyQueryHandler(b.Logger, legacyQueryBackend)
+	if b.DrainGate != nil {
+		legacyQueryHandler = newDrainQueryGate(b.HTTPErrorHandler, b.DrainGate, legacyQueryHandler)
+	}
+	h.Mount(prefixLegacyQuery, legacyQueryHandler)
+
+	grafanaBackend := NewGrafanaBackend(b.Logger.With(zap.String("handler", "grafana")), b)
+	var grafanaHandler http.Handler = NewGrafanaHandler(b.Logger, grafanaBackend)
+	if b.DrainGate != nil {
+		grafanaHandler = newDrainQueryGate(b.HTTPErrorHandler, b.DrainGate, grafanaHandler)
+	}
+	h.Mount(prefixGrafana, grafanaHandler)
+
+	runningQueryBackend := NewRunningQueryBackend(b.Logger.With(zap.String("handler", "running_query")), b)
+	runningQueryBackend.RunningQueryService = authorizer.NewRunningQueryService(b.RunningQueryService)
+	h.Mount(prefixRunningQueries, NewRunningQueryHandler(b.Logger, runningQueryBackend))
 
 	h.Mount(prefixLabels, NewLabelHandler(b.Logger, authorizer.NewLabelService(b.LabelService), b.HTTPErrorHandler))
 
+	materializedViewBackend := NewMaterializedViewBackend(b.Logger.With(zap.String("handler", "materialized_view")), b)
+	materializedViewBackend.MaterializedViewService = authorizer.NewMaterializedViewService(b.MaterializedViewService)
+	materializedViewBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.Mount(prefixMaterializedViews, NewMaterializedViewHandler(b.Logger, materializedViewBackend))
+
+	ldapBackend := NewLDAPBackend(b.Logger.With(zap.String("handler", "ldap")), b)
+	ldapBackend.LDAPService = authorizer.NewLDAPService(b.LDAPService)
+	ldapBackend.LDAPSyncService = authorizer.NewLDAPSyncService(b.LDAPSyncService)
+	h.Mount(prefixLDAP, NewLDAPHandler(b.Logger, ldapBackend))
+
+	auditBackend := NewAuditBackend(b.Logger.With(zap.String("handler", "audit")), b)
+	auditBackend.AuditLogService = authorizer.NewAuditLogService(b.AuditLogService)
+	h.Mount(prefixAudit, NewAuditHandler(b.Logger, auditBackend))
+
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
 	notificationEndpointBackend.NotificationEndpointService = authorizer.NewNotificationEndpointService(b.NotificationEndpointService,
 		b.UserResourceMappingService, b.OrganizationService)
@@ -163,6 +278,8 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	orgBackend := NewOrgBackend(b.Logger.With(zap.String("handler", "org")), b)
 	orgBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	orgBackend.OrgQuotaService = authorizer.NewOrgQuotaService(b.OrgQuotaService)
+	orgBackend.NetworkPolicyService = authorizer.NewNetworkPolicyService(b.NetworkPolicyService)
 	h.Mount(prefixOrganizations, NewOrgHandler(b.Logger, orgBackend))
 
 	scraperBackend := NewScraperBackend(b.Logger.With(zap.String("handler", "scraper")), b)
@@ -176,6 +293,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(prefixSignIn, sessionHandler)
 	h.Mount(prefixSignOut, sessionHandler)
 
+	sessionManagementBackend := NewSessionManagementBackend(b.Logger.With(zap.String("handler", "session_management")), b)
+	sessionManagementBackend.SessionManagementService = authorizer.NewSessionManagementService(b.SessionManagementService)
+	h.Mount(prefixSessions, NewSessionManagementHandler(b.Logger, sessionManagementBackend))
+
 	setupBackend := NewSetupBackend(b.Logger.With(zap.String("handler", "setup")), b)
 	h.Mount(prefixSetup, NewSetupHandler(b.Logger, setupBackend))
 
@@ -212,13 +333,64 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	backupBackend.BackupService = authorizer.NewBackupService(backupBackend.BackupService)
 	h.Mount(prefixBackup, NewBackupHandler(backupBackend))
 
+	drainBackend := NewDrainBackend(b.Logger.With(zap.String("handler", "drain")), b)
+	drainBackend.DrainService = authorizer.NewDrainService(b.DrainService)
+	h.Mount(prefixDrain, NewDrainHandler(b.Logger, drainBackend))
+
+	metadataStoreBackend := NewMetadataStoreBackend(b.Logger.With(zap.String("handler", "metadata_store")), b)
+	metadataStoreBackend.MetadataStoreService = authorizer.NewMetadataStoreService(b.MetadataStoreService)
+	metadataStoreBackend.MetadataBundleService = authorizer.NewMetadataBundleService(b.MetadataBundleService)
+	h.Mount(prefixMetadataStore, NewMetadataStoreHandler(b.Logger, metadataStoreBackend))
+
+	compactionBackend := NewCompactionBackend(b.Logger.With(zap.String("handler", "compaction")), b)
+	compactionBackend.CompactionService = authorizer.NewCompactionService(b.CompactionService)
+	h.Mount(prefixCompaction, NewCompactionHandler(b.Logger, compactionBackend))
+
+	seriesMoveBackend := NewSeriesMoveBackend(b.Logger.With(zap.String("handler", "series_move")), b)
+	seriesMoveBackend.SeriesMoveService = authorizer.NewSeriesMoveService(b.SeriesMoveService)
+	seriesMoveBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.Mount(prefixSeriesMoves, NewSeriesMoveHandler(b.Logger, seriesMoveBackend))
+
+	indexRebuildBackend := NewIndexRebuildBackend(b.Logger.With(zap.String("handler", "index_rebuild")), b)
+	indexRebuildBackend.IndexRebuildService = authorizer.NewIndexRebuildService(b.IndexRebuildService)
+	indexRebuildBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+	h.Mount(prefixIndexRebuilds, NewIndexRebuildHandler(b.Logger, indexRebuildBackend))
+
+	snapshotBackend := NewSnapshotBackend(b.Logger.With(zap.String("handler", "snapshot")), b)
+	snapshotBackend.SnapshotService = authorizer.NewSnapshotService(b.SnapshotService)
+	h.Mount(prefixSnapshots, NewSnapshotHandler(b.Logger, snapshotBackend))
+
+	walRecoveryBackend := NewWALRecoveryBackend(b.Logger.With(zap.String("handler", "wal_recovery")), b)
+	walRecoveryBackend.WALRecoveryService = authorizer.NewWALRecoveryService(b.WALRecoveryService)
+	h.Mount(prefixWALRecovery, NewWALRecoveryHandler(b.Logger, walRecoveryBackend))
+
+	writeStatsBackend := NewWriteStatsBackend(b.Logger.With(zap.String("handler", "write_stats")), b)
+	writeStatsBackend.WriteStatsService = authorizer.NewWriteStatsService(b.WriteStatsService)
+	h.Mount(prefixWriteStats, NewWriteStatsHandler(b.Logger, writeStatsBackend))
+
+	shardStatsBackend := NewShardStatsBackend(b.Logger.With(zap.String("handler", "shard_stats")), b)
+	shardStatsBackend.ShardStatsService = authorizer.NewShardStatsService(b.ShardStatsService)
+	h.Mount(prefixShardStats, NewShardStatsHandler(b.Logger, shardStatsBackend))
+
+	cardinalityBackend := NewCardinalityBackend(b.Logger.With(zap.String("handler", "cardinality")), b)
+	cardinalityBackend.CardinalityService = authorizer.NewCardinalityService(b.CardinalityService)
+	h.Mount(prefixCardinality, NewCardinalityHandler(b.Logger, cardinalityBackend))
+
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
-	h.Mount(prefixWrite, NewWriteHandler(b.Logger, writeBackend,
+	var writeHandler http.Handler = NewWriteHandler(b.Logger, writeBackend,
 		WithMaxBatchSizeBytes(b.MaxBatchSizeBytes),
 		WithParserMaxBytes(b.WriteParserMaxBytes),
 		WithParserMaxLines(b.WriteParserMaxLines),
 		WithParserMaxValues(b.WriteParserMaxValues),
-	))
+		WithMaxConcurrentWrites(b.MaxConcurrentWrites),
+	)
+	if b.DrainGate != nil {
+		writeHandler = newDrainWriteGate(b.HTTPErrorHandler, b.DrainGate, writeHandler)
+	}
+	h.Mount(prefixWrite, writeHandler)
+
+	legacyWriteBackend := NewLegacyWriteBackend(b.Logger.With(zap.String("handler", "legacy_write")), b)
+	h.Mount(prefixLegacyWrite, NewLegacyWriteHandler(b.Logger, legacyWriteBackend, writeHandler))
 
 	for _, o := range opts {
 		o(h)
@@ -246,6 +418,8 @@ var apiLinks = map[string]interface{}{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
 		"analyze":     "/api/v2/query/analyze",
+		"explain":     "/api/v2/query/explain",
+		"profile":     "/api/v2/query/profile",
 		"suggestions": "/api/v2/query/suggestions",
 	},
 	"setup":    "/api/v2/setup",
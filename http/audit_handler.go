@@ -0,0 +1,390 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime"
+	"net"
+	http "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const prefixAudit = "/api/v2/audit"
+
+// AuditBackend is all services and associated parameters required to
+// construct the AuditHandler.
+type AuditBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	AuditLogService influxdb.AuditLogService
+}
+
+// NewAuditBackend returns a new instance of AuditBackend.
+func NewAuditBackend(log *zap.Logger, b *APIBackend) *AuditBackend {
+	return &AuditBackend{
+		log: log,
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		AuditLogService:  b.AuditLogService,
+	}
+}
+
+// AuditHandler queries the audit log.
+type AuditHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	AuditLogService influxdb.AuditLogService
+}
+
+// NewAuditHandler creates a new handler at /api/v2/audit to query the audit
+// log.
+func NewAuditHandler(log *zap.Logger, b *AuditBackend) *AuditHandler {
+	h := &AuditHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		AuditLogService: b.AuditLogService,
+	}
+
+	h.HandlerFunc("GET", prefixAudit, h.handleGetAuditEvents)
+	return h
+}
+
+type auditEventsResponse struct {
+	Events []*influxdb.AuditEvent `json:"events"`
+}
+
+// handleGetAuditEvents is the HTTP handler for the GET /api/v2/audit route.
+func (h *AuditHandler) handleGetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "AuditHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeAuditEventFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	opts, err := decodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if opts.After != "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "after is not supported; page with offset",
+		}, w)
+		return
+	}
+
+	es, _, err := h.AuditLogService.FindAuditEvents(ctx, filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, auditEventsResponse{Events: es}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodeAuditEventFilter decodes the userID, resourceType, resourceID, since
+// and until query parameters of a request.
+func decodeAuditEventFilter(r *http.Request) (influxdb.AuditEventFilter, error) {
+	var filter influxdb.AuditEventFilter
+	qp := r.URL.Query()
+	for _, p := range []struct {
+		name string
+		dst  **influxdb.ID
+	}{
+		{name: "userID", dst: &filter.UserID},
+		{name: "resourceID", dst: &filter.ResourceID},
+	} {
+		if s := qp.Get(p.name); s != "" {
+			id, err := influxdb.IDFromString(s)
+			if err != nil {
+				return filter, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "invalid " + p.name,
+					Err:  err,
+				}
+			}
+			*p.dst = id
+		}
+	}
+	if s := qp.Get("resourceType"); s != "" {
+		rt := influxdb.ResourceType(s)
+		filter.ResourceType = &rt
+	}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{name: "since", dst: &filter.Since},
+		{name: "until", dst: &filter.Until},
+	} {
+		if s := qp.Get(p.name); s != "" {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return filter, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  p.name + " must be an RFC3339 time",
+					Err:  err,
+				}
+			}
+			*p.dst = &t
+		}
+	}
+	return filter, nil
+}
+
+// maxAuditBodySize is the size of the largest representations of resources
+// recorded in audit events. Larger representations are not recorded.
+const maxAuditBodySize = 64 * 1024
+
+// auditRedacted replaces the secrets redacted from audit events.
+const auditRedacted = "[REDACTED]"
+
+// auditSecretKeys are the keys of the JSON objects whose values are redacted
+// from audit events.
+var auditSecretKeys = map[string]bool{
+	"token":         true,
+	"password":      true,
+	"bindpassword":  true,
+	"previoustoken": true,
+	"secret":        true,
+}
+
+// auditMW records the mutating calls made to next in the audit log, with
+// the state of the resource they are made to before and after. The state
+// before is that returned by a GET request to the same path, if any. It must
+// wrap handlers of authenticated requests.
+func auditMW(log *zap.Logger, s influxdb.AuditLogService) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !auditedRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			e := &influxdb.AuditEvent{
+				SourceIP: sourceIP(r),
+				Method:   r.Method,
+				Path:     r.URL.Path,
+			}
+			if a, err := pctx.GetAuthorizer(ctx); err == nil {
+				e.UserID = a.GetUserID()
+				e.AuthorizerID = a.Identifier()
+				e.AuthorizerKind = a.Kind()
+			}
+			e.ResourceType, e.ResourceID = auditResource(r.URL.Path)
+
+			if r.Method == http.MethodPut || r.Method == http.MethodPatch || r.Method == http.MethodDelete {
+				// The GET request is routed with a route context of its own,
+				// as routing modifies it.
+				rctx := chi.NewRouteContext()
+				if c := chi.RouteContext(ctx); c != nil {
+					rctx.RoutePath = c.RoutePath
+				}
+				get := r.Clone(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+				get.Method = http.MethodGet
+				get.Body = http.NoBody
+				get.ContentLength = 0
+				bw := &auditResponseWriter{header: make(http.Header)}
+				next.ServeHTTP(bw, get)
+				e.Before = bw.resource()
+			}
+
+			aw := &auditResponseWriter{ResponseWriter: w, header: w.Header()}
+			next.ServeHTTP(aw, r)
+
+			e.StatusCode = aw.Code()
+			if r.Method != http.MethodDelete {
+				e.After = aw.resource()
+			}
+			if !e.ResourceID.Valid() && e.StatusCode == http.StatusCreated && e.After != nil {
+				var created struct {
+					ID influxdb.ID `json:"id"`
+				}
+				if err := json.Unmarshal(e.After, &created); err == nil {
+					e.ResourceID = created.ID
+				}
+			}
+
+			if err := s.RecordAuditEvent(ctx, e); err != nil {
+				log.Error("Unable to record audit event", zap.String("method", e.Method), zap.String("path", e.Path), zap.Error(err))
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// auditedRequest returns true if r is a mutating call to be audited. Writes
+// of points and queries are not audited, even though most queries are made
+// with POST requests.
+func auditedRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	p := r.URL.Path
+	for _, prefix := range []string{prefixWrite, prefixQuery, prefixGrafana} {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return false
+		}
+	}
+	switch {
+	case p == prefixLegacyWrite, p == prefixLegacyQuery:
+		return false
+	case strings.HasPrefix(p, prefixSources+"/") && strings.HasSuffix(p, "/query"):
+		return false
+	}
+	return true
+}
+
+// auditResource returns the type of the resource a path of the API refers
+// to, and its ID if the path has one.
+func auditResource(p string) (influxdb.ResourceType, influxdb.ID) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(p, "/api/v2"), "/"), "/")
+	if parts[0] == "" {
+		return "", 0
+	}
+
+	rt := influxdb.ResourceType(parts[0])
+	if len(parts) < 2 {
+		return rt, 0
+	}
+	var id influxdb.ID
+	if err := id.DecodeFromString(parts[1]); err != nil {
+		return rt, 0
+	}
+	return rt, id
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditResponseWriter captures the status code and body of responses, and
+// writes them to the wrapped ResponseWriter if it has one.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	header http.Header
+
+	code      int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *auditResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *auditResponseWriter) WriteHeader(code int) {
+	if w.code != 0 {
+		return
+	}
+	w.code = code
+	if w.ResponseWriter != nil {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.truncated {
+		if w.body.Len()+len(b) > maxAuditBodySize {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	if w.ResponseWriter == nil {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client, if the wrapped ResponseWriter
+// can be flushed.
+func (w *auditResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Code returns the status code of the response.
+func (w *auditResponseWriter) Code() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
+
+// resource returns the JSON body of a successful response, with its secrets
+// redacted, or nil if the response is not one.
+func (w *auditResponseWriter) resource() json.RawMessage {
+	if w.Code()/100 != 2 || w.truncated || w.body.Len() == 0 {
+		return nil
+	}
+	if mt, _, err := mime.ParseMediaType(w.header.Get("Content-Type")); err != nil || mt != "application/json" {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(w.body.Bytes(), &v); err != nil {
+		return nil
+	}
+	data, err := json.Marshal(redactAuditSecrets(v))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// redactAuditSecrets replaces the values of the secret keys of the objects
+// of v.
+func redactAuditSecrets(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, x := range v {
+			if auditSecretKeys[strings.ToLower(k)] {
+				v[k] = auditRedacted
+				continue
+			}
+			v[k] = redactAuditSecrets(x)
+		}
+	case []interface{}:
+		for i, x := range v {
+			v[i] = redactAuditSecrets(x)
+		}
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/inmem"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAuditMW(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "cpu"
+	buckets := NewRouter(kithttp.ErrorHandler(0))
+	buckets.HandlerFunc("GET", "/api/v2/buckets/:id", func(w http.ResponseWriter, r *http.Request) {
+		encodeResponse(r.Context(), w, http.StatusOK, map[string]string{"id": "0000000000000001", "name": name, "token": "secret"})
+	})
+	buckets.HandlerFunc("PATCH", "/api/v2/buckets/:id", func(w http.ResponseWriter, r *http.Request) {
+		name = "mem"
+		encodeResponse(r.Context(), w, http.StatusOK, map[string]string{"id": "0000000000000001", "name": name})
+	})
+	buckets.HandlerFunc("POST", "/api/v2/buckets", func(w http.ResponseWriter, r *http.Request) {
+		encodeResponse(r.Context(), w, http.StatusCreated, map[string]string{"id": "0000000000000002", "name": "disk"})
+	})
+	writes := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	h := newBaseChiRouter(kithttp.ErrorHandler(0))
+	h.Use(auditMW(zaptest.NewLogger(t), svc))
+	h.Mount(prefixBuckets, buckets)
+	h.Mount(prefixWrite, writes)
+
+	auth := &influxdb.Authorization{ID: 3, UserID: 4}
+	for _, req := range []struct{ method, path string }{
+		{method: "PATCH", path: "/api/v2/buckets/0000000000000001"},
+		{method: "POST", path: "/api/v2/buckets"},
+		{method: "POST", path: "/api/v2/write"},
+		{method: "GET", path: "/api/v2/buckets/0000000000000001"},
+	} {
+		r := httptest.NewRequest(req.method, "http://any.tld"+req.path, nil)
+		r.RemoteAddr = "192.0.2.1:5000"
+		r = r.WithContext(pctx.SetAuthorizer(r.Context(), auth))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code/100 != 2 {
+			t.Fatalf("unexpected status code of %s %s: %d: %s", req.method, req.path, w.Code, w.Body.String())
+		}
+	}
+
+	es, _, err := svc.FindAuditEvents(ctx, influxdb.AuditEventFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range es {
+		e.ID = 0
+		e.Time = time.Time{}
+	}
+	want := []*influxdb.AuditEvent{
+		{
+			UserID:         4,
+			AuthorizerID:   3,
+			AuthorizerKind: influxdb.AuthorizationKind,
+			SourceIP:       "192.0.2.1",
+			Method:         "PATCH",
+			Path:           "/api/v2/buckets/0000000000000001",
+			ResourceType:   influxdb.BucketsResourceType,
+			ResourceID:     1,
+			StatusCode:     http.StatusOK,
+			Before:         []byte(`{"id":"0000000000000001","name":"cpu","token":"[REDACTED]"}`),
+			After:          []byte(`{"id":"0000000000000001","name":"mem"}`),
+		},
+		{
+			UserID:         4,
+			AuthorizerID:   3,
+			AuthorizerKind: influxdb.AuthorizationKind,
+			SourceIP:       "192.0.2.1",
+			Method:         "POST",
+			Path:           "/api/v2/buckets",
+			ResourceType:   influxdb.BucketsResourceType,
+			ResourceID:     2,
+			StatusCode:     http.StatusCreated,
+			After:          []byte(`{"id":"0000000000000002","name":"disk"}`),
+		},
+	}
+	if diff := cmp.Diff(want, es); diff != "" {
+		t.Fatalf("unexpected audit events: -want/+got\n%s", diff)
+	}
+}
+
+func TestAuditHandler_handleGetAuditEvents(t *testing.T) {
+	var gotFilter influxdb.AuditEventFilter
+	var gotOpts influxdb.FindOptions
+	svc := &mock.AuditLogService{
+		FindAuditEventsFn: func(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+			gotFilter, gotOpts = filter, opt[0]
+			return []*influxdb.AuditEvent{{
+				ID:           1,
+				Time:         time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				UserID:       2,
+				SourceIP:     "192.0.2.1",
+				Method:       "DELETE",
+				Path:         "/api/v2/buckets/0000000000000003",
+				ResourceType: influxdb.BucketsResourceType,
+				ResourceID:   3,
+				StatusCode:   http.StatusNoContent,
+			}}, 1, nil
+		},
+	}
+
+	h := NewAuditHandler(zaptest.NewLogger(t), &AuditBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		AuditLogService:  svc,
+	})
+
+	r := httptest.NewRequest("GET", "http://any.tld"+prefixAudit+"?userID=0000000000000002&since=2020-01-01T00:00:00Z&descending=true", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d: %s", res.StatusCode, body)
+	}
+	if gotFilter.UserID == nil || *gotFilter.UserID != 2 || gotFilter.Since == nil || !gotOpts.Descending {
+		t.Fatalf("unexpected filter %+v and options %+v", gotFilter, gotOpts)
+	}
+	want := `{"events": [{"id": "0000000000000001", "time": "2020-01-01T00:00:00Z", "userID": "0000000000000002", "sourceIP": "192.0.2.1", "method": "DELETE", "path": "/api/v2/buckets/0000000000000003", "resourceType": "buckets", "resourceID": "0000000000000003", "statusCode": 204}]}`
+	if eq, diff, err := jsonEqual(string(body), want); err != nil {
+		t.Fatal(err)
+	} else if !eq {
+		t.Fatalf("unexpected body: %s", diff)
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,10 +24,12 @@ type AuthorizationBackend struct {
 	platform.HTTPErrorHandler
 	log *zap.Logger
 
-	AuthorizationService platform.AuthorizationService
-	OrganizationService  platform.OrganizationService
-	UserService          platform.UserService
-	LookupService        platform.LookupService
+	AuthorizationService          platform.AuthorizationService
+	AuthorizationBatchService     platform.AuthorizationBatchService
+	AuthorizationLifecycleService platform.AuthorizationLifecycleService
+	OrganizationService           platform.OrganizationService
+	UserService                   platform.UserService
+	LookupService                 platform.LookupService
 }
 
 // NewAuthorizationBackend returns a new instance of AuthorizationBackend.
@@ -35,10 +38,12 @@ func NewAuthorizationBackend(log *zap.Logger, b *APIBackend) *AuthorizationBacke
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		AuthorizationService: b.AuthorizationService,
-		OrganizationService:  b.OrganizationService,
-		UserService:          b.UserService,
-		LookupService:        b.LookupService,
+		AuthorizationService:          b.AuthorizationService,
+		AuthorizationBatchService:     b.AuthorizationBatchService,
+		AuthorizationLifecycleService: b.AuthorizationLifecycleService,
+		OrganizationService:           b.OrganizationService,
+		UserService:                   b.UserService,
+		LookupService:                 b.LookupService,
 	}
 }
 
@@ -48,10 +53,12 @@ type AuthorizationHandler struct {
 	platform.HTTPErrorHandler
 	log *zap.Logger
 
-	OrganizationService  platform.OrganizationService
-	UserService          platform.UserService
-	AuthorizationService platform.AuthorizationService
-	LookupService        platform.LookupService
+	OrganizationService           platform.OrganizationService
+	UserService                   platform.UserService
+	AuthorizationService          platform.AuthorizationService
+	AuthorizationBatchService     platform.AuthorizationBatchService
+	AuthorizationLifecycleService platform.AuthorizationLifecycleService
+	LookupService                 platform.LookupService
 }
 
 // NewAuthorizationHandler returns a new instance of AuthorizationHandler.
@@ -61,10 +68,12 @@ func NewAuthorizationHandler(log *zap.Logger, b *AuthorizationBackend) *Authoriz
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              log,
 
-		AuthorizationService: b.AuthorizationService,
-		OrganizationService:  b.OrganizationService,
-		UserService:          b.UserService,
-		LookupService:        b.LookupService,
+		AuthorizationService:          b.AuthorizationService,
+		AuthorizationBatchService:     b.AuthorizationBatchService,
+		AuthorizationLifecycleService: b.AuthorizationLifecycleService,
+		OrganizationService:           b.OrganizationService,
+		UserService:                   b.UserService,
+		LookupService:                 b.LookupService,
 	}
 
 	h.HandlerFunc("POST", "/api/v2/authorizations", h.handlePostAuthorization)
@@ -72,22 +81,29 @@ func NewAuthorizationHandler(log *zap.Logger, b *AuthorizationBackend) *Authoriz
 	h.HandlerFunc("GET", "/api/v2/authorizations/:id", h.handleGetAuthorization)
 	h.HandlerFunc("PATCH", "/api/v2/authorizations/:id", h.handleUpdateAuthorization)
 	h.HandlerFunc("DELETE", "/api/v2/authorizations/:id", h.handleDeleteAuthorization)
+	h.HandlerFunc("POST", "/api/v2/authorizations/batch", h.handlePostAuthorizationBatch)
+	h.HandlerFunc("POST", "/api/v2/authorizations/rotate", h.handleRotateAuthorization)
+	h.HandlerFunc("DELETE", "/api/v2/authorizations", h.handleDeleteLabeledAuthorizations)
 	return h
 }
 
 type authResponse struct {
-	ID          platform.ID          `json:"id"`
-	Token       string               `json:"token"`
-	Status      platform.Status      `json:"status"`
-	Description string               `json:"description"`
-	OrgID       platform.ID          `json:"orgID"`
-	Org         string               `json:"org"`
-	UserID      platform.ID          `json:"userID"`
-	User        string               `json:"user"`
-	Permissions []permissionResponse `json:"permissions"`
-	Links       map[string]string    `json:"links"`
-	CreatedAt   time.Time            `json:"createdAt"`
-	UpdatedAt   time.Time            `json:"updatedAt"`
+	ID          platform.ID                       `json:"id"`
+	Token       string                            `json:"token"`
+	Status      platform.Status                   `json:"status"`
+	Description string                            `json:"description"`
+	OrgID       platform.ID                       `json:"orgID"`
+	Org         string                            `json:"org"`
+	UserID      platform.ID                       `json:"userID"`
+	User        string                            `json:"user"`
+	Permissions []permissionResponse              `json:"permissions"`
+	Links       map[string]string                 `json:"links"`
+	ExpiresAt   *time.Time                        `json:"expiresAt,omitempty"`
+	LastUsedAt  *time.Time                        `json:"lastUsedAt,omitempty"`
+	Stale       bool                              `json:"stale,omitempty"`
+	RateLimits  *platform.AuthorizationRateLimits `json:"rateLimits,omitempty"`
+	CreatedAt   time.Time                         `json:"createdAt"`
+	UpdatedAt   time.Time                         `json:"updatedAt"`
 }
 
 func newAuthResponse(a *platform.Authorization, org *platform.Organization, user *platform.User, ps []permissionResponse) *authResponse {
@@ -105,8 +121,12 @@ func newAuthResponse(a *platform.Authorization, org *platform.Organization, user
 			"self": fmt.Sprintf("/api/v2/authorizations/%s", a.ID),
 			"user": fmt.Sprintf("/api/v2/users/%s", a.UserID),
 		},
-		CreatedAt: a.CreatedAt,
-		UpdatedAt: a.UpdatedAt,
+		ExpiresAt:  a.ExpiresAt,
+		LastUsedAt: a.LastUsedAt,
+		Stale:      a.Stale,
+		RateLimits: a.RateLimits,
+		CreatedAt:  a.CreatedAt,
+		UpdatedAt:  a.UpdatedAt,
 	}
 	return res
 }
@@ -119,6 +139,10 @@ func (a *authResponse) toPlatform() *platform.Authorization {
 		Description: a.Description,
 		OrgID:       a.OrgID,
 		UserID:      a.UserID,
+		ExpiresAt:   a.ExpiresAt,
+		LastUsedAt:  a.LastUsedAt,
+		Stale:       a.Stale,
+		RateLimits:  a.RateLimits,
 		CRUDLog: platform.CRUDLog{
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
@@ -240,11 +264,13 @@ func (h *AuthorizationHandler) handlePostAuthorization(w http.ResponseWriter, r
 }
 
 type postAuthorizationRequest struct {
-	Status      platform.Status       `json:"status"`
-	OrgID       platform.ID           `json:"orgID"`
-	UserID      *platform.ID          `json:"userID,omitempty"`
-	Description string                `json:"description"`
-	Permissions []platform.Permission `json:"permissions"`
+	Status      platform.Status                   `json:"status"`
+	OrgID       platform.ID                       `json:"orgID"`
+	UserID      *platform.ID                      `json:"userID,omitempty"`
+	Description string                            `json:"description"`
+	Permissions []platform.Permission             `json:"permissions"`
+	ExpiresAt   *time.Time                        `json:"expiresAt,omitempty"`
+	RateLimits  *platform.AuthorizationRateLimits `json:"rateLimits,omitempty"`
 }
 
 func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Authorization {
@@ -254,6 +280,8 @@ func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Auth
 		Description: p.Description,
 		Permissions: p.Permissions,
 		UserID:      userID,
+		ExpiresAt:   p.ExpiresAt,
+		RateLimits:  p.RateLimits,
 	}
 }
 
@@ -263,6 +291,8 @@ func newPostAuthorizationRequest(a *platform.Authorization) (*postAuthorizationR
 		Description: a.Description,
 		Permissions: a.Permissions,
 		Status:      a.Status,
+		ExpiresAt:   a.ExpiresAt,
+		RateLimits:  a.RateLimits,
 	}
 
 	if a.UserID.Valid() {
@@ -304,6 +334,12 @@ func (p *postAuthorizationRequest) Validate() error {
 		}
 	}
 
+	if p.RateLimits != nil {
+		if err := p.RateLimits.Valid(); err != nil {
+			return err
+		}
+	}
+
 	if p.Status == "" {
 		p.Status = platform.Active
 	}
@@ -618,6 +654,233 @@ func decodeDeleteAuthorizationRequest(ctx context.Context, r *http.Request) (*de
 	}, nil
 }
 
+// handleRotateAuthorization is the HTTP handler for the POST /api/v2/authorizations/rotate route.
+func (h *AuthorizationHandler) handleRotateAuthorization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, err := decodeRotateAuthorizationRequest(ctx, r)
+	if err != nil {
+		h.log.Info("Failed to decode request", zap.String("handler", "rotateAuthorization"), zap.Error(err))
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	a, err := h.AuthorizationLifecycleService.RotateAuthorization(ctx, req.ID, req.GracePeriod.Duration)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	o, err := h.OrganizationService.FindOrganizationByID(ctx, a.OrgID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	u, err := h.UserService.FindUserByID(ctx, a.UserID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ps, err := newPermissionsResponse(ctx, a.Permissions, h.LookupService)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Auth rotated", zap.String("authID", a.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newAuthResponse(a, o, u, ps)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type rotateAuthorizationRequest struct {
+	ID platform.ID `json:"id"`
+	// GracePeriod is how long the current token remains valid. It is
+	// revoked at once without one.
+	GracePeriod platform.Duration `json:"gracePeriod"`
+}
+
+func decodeRotateAuthorizationRequest(ctx context.Context, r *http.Request) (*rotateAuthorizationRequest, error) {
+	req := &rotateAuthorizationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+	if !req.ID.Valid() {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "id is required",
+		}
+	}
+	return req, nil
+}
+
+// handlePostAuthorizationBatch is the HTTP handler for the POST /api/v2/authorizations/batch route.
+func (h *AuthorizationHandler) handlePostAuthorizationBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodePostAuthorizationBatchRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if !req.UserID.Valid() {
+		user, err := getAuthorizedUser(r, h.UserService)
+		if err != nil {
+			h.HandleHTTPError(ctx, platform.ErrUnableToCreateToken, w)
+			return
+		}
+		req.UserID = user.ID
+	}
+
+	as, err := h.AuthorizationBatchService.CreateAuthorizationBatch(ctx, &req.AuthorizationBatch)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	h.log.Debug("Auth batch created", zap.String("labelID", req.LabelID.String()), zap.Int("count", len(as)))
+
+	if req.Format == "csv" {
+		if err := encodeAuthorizationBatchCSV(w, as); err != nil {
+			logEncodingError(h.log, r, err)
+		}
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, newAuthBatchResponse(as)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type postAuthorizationBatchRequest struct {
+	platform.AuthorizationBatch
+	Format string
+}
+
+func decodePostAuthorizationBatchRequest(ctx context.Context, r *http.Request) (*postAuthorizationBatchRequest, error) {
+	req := &postAuthorizationBatchRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req.AuthorizationBatch); err != nil {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+
+	switch req.Format = r.URL.Query().Get("format"); req.Format {
+	case "", "json", "csv":
+	default:
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("unsupported format %q; must be json or csv", req.Format),
+		}
+	}
+
+	return req, req.Valid()
+}
+
+type authBatchToken struct {
+	ID    platform.ID `json:"id"`
+	Token string      `json:"token"`
+}
+
+type authBatchResponse struct {
+	Tokens []authBatchToken `json:"tokens"`
+}
+
+func newAuthBatchResponse(as []*platform.Authorization) *authBatchResponse {
+	res := &authBatchResponse{
+		Tokens: make([]authBatchToken, len(as)),
+	}
+	for i, a := range as {
+		res.Tokens[i] = authBatchToken{ID: a.ID, Token: a.Token}
+	}
+	return res
+}
+
+// encodeAuthorizationBatchCSV writes the IDs and tokens of a batch of
+// authorizations as CSV, one authorization per row.
+func encodeAuthorizationBatchCSV(w http.ResponseWriter, as []*platform.Authorization) error {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.WriteHeader(http.StatusCreated)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "token"}); err != nil {
+		return err
+	}
+	for _, a := range as {
+		if err := cw.Write([]string{a.ID.String(), a.Token}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// handleDeleteLabeledAuthorizations is the HTTP handler for the DELETE /api/v2/authorizations route.
+func (h *AuthorizationHandler) handleDeleteLabeledAuthorizations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	req, err := decodeDeleteLabeledAuthorizationsRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	n, err := h.AuthorizationBatchService.DeleteLabeledAuthorizations(ctx, req.OrgID, req.LabelID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	h.log.Debug("Labeled auths deleted", zap.String("labelID", req.LabelID.String()), zap.Int("count", n))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, deleteLabeledAuthorizationsResponse{Deleted: n}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type deleteLabeledAuthorizationsRequest struct {
+	OrgID   platform.ID
+	LabelID platform.ID
+}
+
+type deleteLabeledAuthorizationsResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+func decodeDeleteLabeledAuthorizationsRequest(ctx context.Context, r *http.Request) (*deleteLabeledAuthorizationsRequest, error) {
+	qp := r.URL.Query()
+	orgID, err := decodeIDFromQuery(qp, "orgID")
+	if err != nil {
+		return nil, err
+	}
+	labelID, err := decodeIDFromQuery(qp, "labelID")
+	if err != nil {
+		return nil, err
+	}
+	if !orgID.Valid() || !labelID.Valid() {
+		return nil, &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "orgID and labelID are required",
+		}
+	}
+
+	return &deleteLabeledAuthorizationsRequest{
+		OrgID:   orgID,
+		LabelID: labelID,
+	}, nil
+}
+
 func getAuthorizedUser(r *http.Request, svc platform.UserService) (*platform.User, error) {
 	ctx := r.Context()
 
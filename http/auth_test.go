@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -855,6 +856,151 @@ func TestService_handleDeleteAuthorization(t *testing.T) {
 	}
 }
 
+func TestService_handleAuthorizationBatch(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	user := &platform.User{Name: "fleet"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &platform.Organization{Name: "fleet"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &platform.Bucket{Name: "devices", OrgID: org.ID}
+	if err := svc.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+	label := &platform.Label{Name: "edge", OrgID: org.ID}
+	if err := svc.CreateLabel(ctx, label); err != nil {
+		t.Fatal(err)
+	}
+
+	authorizationBackend := NewMockAuthorizationBackend(t)
+	authorizationBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	authorizationBackend.AuthorizationService = svc
+	authorizationBackend.AuthorizationBatchService = svc
+	authorizationBackend.UserService = svc
+	h := NewAuthorizationHandler(zaptest.NewLogger(t), authorizationBackend)
+
+	body := fmt.Sprintf(`{"orgID": %q, "bucketID": %q, "labelID": %q, "count": 2}`, org.ID, bucket.ID, label.ID)
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/authorizations/batch?format=csv", bytes.NewBufferString(body))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &platform.Session{UserID: user.ID}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	if res.StatusCode != http.StatusCreated {
+		b, _ := ioutil.ReadAll(res.Body)
+		t.Fatalf("unexpected status code: %d: %s", res.StatusCode, b)
+	}
+	if got, want := res.Header.Get("Content-Type"), "text/csv; charset=utf-8"; got != want {
+		t.Fatalf("unexpected content type: got %q, want %q", got, want)
+	}
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "id" || rows[0][1] != "token" {
+		t.Fatalf("unexpected csv: %v", rows)
+	}
+	for _, row := range rows[1:] {
+		a, err := svc.FindAuthorizationByToken(ctx, row[1])
+		if err != nil {
+			t.Fatal(err)
+		} else if a.UserID != user.ID || a.ID.String() != row[0] {
+			t.Fatalf("unexpected authorization: %v", a)
+		}
+	}
+
+	r = httptest.NewRequest("DELETE", fmt.Sprintf("http://any.url/api/v2/authorizations?orgID=%s&labelID=%s", org.ID, label.ID), nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res = w.Result()
+	b, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d: %s", res.StatusCode, b)
+	}
+	if eq, diff, err := jsonEqual(string(b), `{"deleted": 2}`); err != nil {
+		t.Fatal(err)
+	} else if !eq {
+		t.Fatalf("unexpected body: %s", diff)
+	}
+}
+
+func TestService_handleRotateAuthorization(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	user := &platform.User{Name: "ci"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &platform.Organization{Name: "ci"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	auth := &platform.Authorization{
+		OrgID:       org.ID,
+		UserID:      user.ID,
+		Permissions: []platform.Permission{{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.OrgsResourceType, ID: &org.ID}}},
+	}
+	if err := svc.CreateAuthorization(ctx, auth); err != nil {
+		t.Fatal(err)
+	}
+	oldToken := auth.Token
+
+	authorizationBackend := NewMockAuthorizationBackend(t)
+	authorizationBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	authorizationBackend.AuthorizationService = svc
+	authorizationBackend.AuthorizationLifecycleService = svc
+	authorizationBackend.OrganizationService = svc
+	authorizationBackend.UserService = svc
+	h := NewAuthorizationHandler(zaptest.NewLogger(t), authorizationBackend)
+
+	body := fmt.Sprintf(`{"id": %q, "gracePeriod": "1h"}`, auth.ID)
+	r := httptest.NewRequest("POST", "http://any.url/api/v2/authorizations/rotate", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	b, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status code: %d: %s", res.StatusCode, b)
+	}
+	var got authResponse
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != auth.ID || got.Token == "" || got.Token == oldToken {
+		t.Fatalf("unexpected rotated authorization: %s", b)
+	}
+
+	for _, token := range []string{oldToken, got.Token} {
+		if a, err := svc.FindAuthorizationByToken(ctx, token); err != nil {
+			t.Fatalf("token %q is not valid: %v", token, err)
+		} else if a.ID != auth.ID {
+			t.Fatalf("unexpected authorization: %v", a)
+		}
+	}
+
+	body = fmt.Sprintf(`{"id": %q, "gracePeriod": "-1h"}`, auth.ID)
+	r = httptest.NewRequest("POST", "http://any.url/api/v2/authorizations/rotate", bytes.NewBufferString(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if res := w.Result(); res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status code for negative grace period: %d", res.StatusCode)
+	}
+}
+
 func initAuthorizationService(f platformtesting.AuthorizationFields, t *testing.T) (platform.AuthorizationService, string, func()) {
 	t.Helper()
 	if t.Name() == "TestAuthorizationService_FindAuthorizations/find_authorization_by_token" {
@@ -873,6 +1019,9 @@ func initAuthorizationService(f platformtesting.AuthorizationFields, t *testing.
 	svc.IDGenerator = f.IDGenerator
 	svc.TokenGenerator = f.TokenGenerator
 	svc.TimeGenerator = f.TimeGenerator
+	if f.TimeGenerator == nil {
+		svc.TimeGenerator = platform.RealTimeGenerator{}
+	}
 
 	ctx := context.Background()
 
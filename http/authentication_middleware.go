@@ -20,15 +20,32 @@ type AuthenticationHandler struct {
 	log *zap.Logger
 
 	AuthorizationService platform.AuthorizationService
-	SessionService       platform.SessionService
-	UserService          platform.UserService
-	TokenParser          *jsonweb.TokenParser
-	SessionRenewDisabled bool
+	// AuthorizationLifecycleService, if set, records the last use of
+	// authorizations.
+	AuthorizationLifecycleService platform.AuthorizationLifecycleService
+	SessionService                platform.SessionService
+	UserService                   platform.UserService
+	TokenParser                   *jsonweb.TokenParser
+	SessionRenewDisabled          bool
+	// SessionIdleTimeout is how long sessions last after they are last used,
+	// or platform.RenewSessionTime if it is zero.
+	SessionIdleTimeout time.Duration
+	// SignedRequestVerifier, if set, authenticates the requests to the routes
+	// registered with RegisterSignedRoute that are signed with the token of
+	// an authorization.
+	SignedRequestVerifier *SignedRequestVerifier
 
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
 
+	// legacyAuthRouter holds the 1.x compatible routes, of which the token
+	// may also be given as a password.
+	legacyAuthRouter *httprouter.Router
+
+	// signedAuthRouter holds the routes accepting signed requests.
+	signedAuthRouter *httprouter.Router
+
 	Handler http.Handler
 }
 
@@ -40,6 +57,8 @@ func NewAuthenticationHandler(log *zap.Logger, h platform.HTTPErrorHandler) *Aut
 		Handler:          http.DefaultServeMux,
 		TokenParser:      jsonweb.NewTokenParser(jsonweb.EmptyKeyStore),
 		noAuthRouter:     httprouter.New(),
+		legacyAuthRouter: httprouter.New(),
+		signedAuthRouter: httprouter.New(),
 	}
 }
 
@@ -49,9 +68,22 @@ func (h *AuthenticationHandler) RegisterNoAuthRoute(method, path string) {
 	h.noAuthRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 }
 
+// RegisterLegacyAuthRoute accepts the token of requests to routes as the
+// password of 1.x clients, as well as in the Authorization Header.
+func (h *AuthenticationHandler) RegisterLegacyAuthRoute(method, path string) {
+	h.legacyAuthRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
+
+// RegisterSignedRoute accepts requests to routes signed with the token of an
+// authorization, instead of bearing it, if the SignedRequestVerifier is set.
+func (h *AuthenticationHandler) RegisterSignedRoute(method, path string) {
+	h.signedAuthRouter.HandlerFunc(method, path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+}
+
 const (
 	tokenAuthScheme   = "token"
 	sessionAuthScheme = "session"
+	signedAuthScheme  = "signed"
 )
 
 // ProbeAuthScheme probes the http request for the requests for token or cookie session.
@@ -83,7 +115,17 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 	}
 
 	ctx := r.Context()
+	if handler, _, _ := h.legacyAuthRouter.Lookup(r.Method, r.URL.Path); handler != nil {
+		if t, err := GetLegacyToken(r); err == nil {
+			r = r.Clone(ctx)
+			SetToken(t, r)
+		}
+	}
+
 	scheme, err := ProbeAuthScheme(r)
+	if h.isSignedRequest(r) {
+		scheme, err = signedAuthScheme, nil
+	}
 	if err != nil {
 		h.unauthorized(ctx, w, err)
 		return
@@ -95,6 +137,8 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		auth, err = h.extractAuthorization(ctx, r)
 	case sessionAuthScheme:
 		auth, err = h.extractSession(ctx, r)
+	case signedAuthScheme:
+		auth, err = h.extractSignedAuthorization(ctx, r)
 	default:
 		// TODO: this error will be nil if it gets here, this should be remedied with some
 		//  sentinel error I'm thinking
@@ -152,7 +196,80 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 		return nil, err
 	}
 
-	return h.AuthorizationService.FindAuthorizationByToken(ctx, t)
+	a, err := h.AuthorizationService.FindAuthorizationByToken(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if a.Expired(now) {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "token has expired",
+		}
+	}
+	h.touchAuthorization(ctx, a, now)
+	return a, nil
+}
+
+// isSignedRequest returns true if r is signed and made to a route accepting
+// signed requests.
+func (h *AuthenticationHandler) isSignedRequest(r *http.Request) bool {
+	if h.SignedRequestVerifier == nil || r.Header.Get(SignatureKeyIDHeader) == "" {
+		return false
+	}
+	handler, _, _ := h.signedAuthRouter.Lookup(r.Method, r.URL.Path)
+	return handler != nil
+}
+
+// extractSignedAuthorization returns the authorization a request is signed
+// with, if the signature is valid. The previous token of a rotated
+// authorization is accepted until it expires.
+func (h *AuthenticationHandler) extractSignedAuthorization(ctx context.Context, r *http.Request) (platform.Authorizer, error) {
+	var id platform.ID
+	if err := id.DecodeFromString(r.Header.Get(SignatureKeyIDHeader)); err != nil {
+		return nil, err
+	}
+
+	a, err := h.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if a.Expired(now) {
+		return nil, &platform.Error{
+			Code: platform.EUnauthorized,
+			Msg:  "token has expired",
+		}
+	}
+
+	tokens := []string{a.Token}
+	if a.PreviousToken != "" && a.PreviousTokenExpiresAt != nil && now.Before(*a.PreviousTokenExpiresAt) {
+		tokens = append(tokens, a.PreviousToken)
+	}
+	if err := h.SignedRequestVerifier.Verify(r, tokens...); err != nil {
+		return nil, err
+	}
+
+	h.touchAuthorization(ctx, a, now)
+	return a, nil
+}
+
+// touchAuthorization records the use of an authorization, unless its last use
+// was recorded less than platform.AuthorizationLastUsedPrecision ago. Failing
+// to record it does not fail the request.
+func (h *AuthenticationHandler) touchAuthorization(ctx context.Context, a *platform.Authorization, now time.Time) {
+	if h.AuthorizationLifecycleService == nil {
+		return
+	}
+	if a.LastUsedAt != nil && now.Sub(*a.LastUsedAt) < platform.AuthorizationLastUsedPrecision {
+		return
+	}
+
+	if err := h.AuthorizationLifecycleService.TouchAuthorization(ctx, a.ID, now); err != nil {
+		h.log.Warn("Unable to record use of authorization", zap.String("id", a.ID.String()), zap.Error(err))
+	}
 }
 
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (*platform.Session, error) {
@@ -168,7 +285,11 @@ func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Requ
 
 	if !h.SessionRenewDisabled {
 		// if the session is not expired, renew the session
-		err = h.SessionService.RenewSession(ctx, s, time.Now().Add(platform.RenewSessionTime))
+		idle := h.SessionIdleTimeout
+		if idle <= 0 {
+			idle = platform.RenewSessionTime
+		}
+		err = h.SessionService.RenewSession(ctx, s, time.Now().Add(idle))
 		if err != nil {
 			return nil, err
 		}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -397,3 +399,246 @@ func TestAuthenticationHandler_NoAuthRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthenticationHandler_LegacyAuthRoutes(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{
+			name: "password of legacy auth route",
+			path: "/write?db=telegraf&u=user&p=tok",
+			code: http.StatusOK,
+		},
+		{
+			name: "password of auth route",
+			path: "/api/v2/write?org=o&bucket=b&p=tok",
+			code: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+			h.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+					if token != "tok" {
+						return nil, fmt.Errorf("authorization not found")
+					}
+					return &platform.Authorization{}, nil
+				},
+			}
+			h.SessionService = mock.NewSessionService()
+			h.Handler = handler
+			h.RegisterLegacyAuthRoute("POST", "/write")
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", tt.path, nil)
+
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("expected status code to be %d got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestAuthenticationHandler_AuthorizationLifecycle(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	recent := now.Add(-time.Second)
+
+	tests := []struct {
+		name    string
+		auth    *platform.Authorization
+		code    int
+		touched bool
+	}{
+		{
+			name:    "expired token",
+			auth:    &platform.Authorization{ID: one, ExpiresAt: &past},
+			code:    http.StatusUnauthorized,
+			touched: false,
+		},
+		{
+			name:    "unexpired token",
+			auth:    &platform.Authorization{ID: one, ExpiresAt: &future},
+			code:    http.StatusOK,
+			touched: true,
+		},
+		{
+			name:    "token used within the last used precision",
+			auth:    &platform.Authorization{ID: one, LastUsedAt: &recent},
+			code:    http.StatusOK,
+			touched: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			var touched bool
+			h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+			h.AuthorizationService = &mock.AuthorizationService{
+				FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+					return tt.auth, nil
+				},
+			}
+			h.AuthorizationLifecycleService = &mock.AuthorizationLifecycleService{
+				TouchAuthorizationFn: func(ctx context.Context, id platform.ID, t time.Time) error {
+					touched = true
+					return nil
+				},
+			}
+			h.SessionService = mock.NewSessionService()
+			h.Handler = handler
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/v2/write", nil)
+			platformhttp.SetToken("tok", r)
+
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("expected status code to be %d got %d", want, got)
+			}
+			if touched != tt.touched {
+				t.Errorf("expected authorization touched to be %v got %v", tt.touched, touched)
+			}
+		})
+	}
+}
+
+func TestAuthenticationHandler_SignedRoutes(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	auth := &platform.Authorization{
+		ID:                     one,
+		Token:                  "tok",
+		PreviousToken:          "old",
+		PreviousTokenExpiresAt: &future,
+	}
+
+	var body string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+	h.AuthorizationService = &mock.AuthorizationService{
+		FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+			if id != one {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "authorization not found"}
+			}
+			return auth, nil
+		},
+	}
+	h.SessionService = mock.NewSessionService()
+	h.SignedRequestVerifier = platformhttp.NewSignedRequestVerifier(time.Minute)
+	h.Handler = handler
+	h.RegisterSignedRoute("POST", "/api/v2/write")
+
+	signed := func(t *testing.T, path, token string, ts time.Time) *http.Request {
+		r := httptest.NewRequest("POST", path, strings.NewReader("m f=1"))
+		if err := platformhttp.SignRequest(r, one, token, ts); err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	do := func(r *http.Request) int {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Run("signed write", func(t *testing.T) {
+		r := signed(t, "/api/v2/write?org=o&bucket=b", "tok", now)
+		if code := do(r); code != http.StatusNoContent {
+			t.Fatalf("expected status code to be %d got %d", http.StatusNoContent, code)
+		}
+		if body != "m f=1" {
+			t.Errorf("expected body to be passed on, got %q", body)
+		}
+
+		// The same request cannot be replayed.
+		r = signed(t, "/api/v2/write?org=o&bucket=b", "tok", now)
+		if code := do(r); code != http.StatusUnauthorized {
+			t.Errorf("expected replayed request status code to be %d got %d", http.StatusUnauthorized, code)
+		}
+	})
+
+	t.Run("previous token of a rotated authorization", func(t *testing.T) {
+		r := signed(t, "/api/v2/write?org=o&bucket=b", "old", now)
+		if code := do(r); code != http.StatusNoContent {
+			t.Errorf("expected status code to be %d got %d", http.StatusNoContent, code)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		modify func(r *http.Request) *http.Request
+	}{
+		{
+			name: "wrong token",
+			modify: func(r *http.Request) *http.Request {
+				return signed(t, "/api/v2/write?org=o&bucket=b", "wrong", now)
+			},
+		},
+		{
+			name: "modified body",
+			modify: func(r *http.Request) *http.Request {
+				r.Body = ioutil.NopCloser(strings.NewReader("m f=2"))
+				return r
+			},
+		},
+		{
+			name: "modified query",
+			modify: func(r *http.Request) *http.Request {
+				r.URL.RawQuery = "org=o&bucket=other"
+				return r
+			},
+		},
+		{
+			name: "timestamp out of the skew window",
+			modify: func(r *http.Request) *http.Request {
+				return signed(t, "/api/v2/write?org=o&bucket=b", "tok", now.Add(-2*time.Minute))
+			},
+		},
+		{
+			name: "unknown key",
+			modify: func(r *http.Request) *http.Request {
+				r.Header.Set(platformhttp.SignatureKeyIDHeader, "0000000000000002")
+				return r
+			},
+		},
+		{
+			name: "route not accepting signed requests",
+			modify: func(r *http.Request) *http.Request {
+				return signed(t, "/api/v2/buckets", "tok", now)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.modify(signed(t, "/api/v2/write?org=o&bucket=b", "tok", now.Add(-time.Second)))
+			if code := do(r); code != http.StatusUnauthorized {
+				t.Errorf("expected status code to be %d got %d", http.StatusUnauthorized, code)
+			}
+		})
+	}
+}
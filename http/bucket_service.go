@@ -1,13 +1,18 @@
 package http
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/influxdata/flux/iocounter"
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
@@ -28,6 +33,11 @@ type BucketBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	OrganizationService        influxdb.OrganizationService
+	MeasurementSchemaService   influxdb.MeasurementSchemaService
+	BucketRestoreService       influxdb.BucketRestoreService
+	BucketTransferService      influxdb.BucketTransferService
+	BucketUsageService         influxdb.BucketUsageService
+	BucketExportService        influxdb.BucketExportService
 }
 
 // NewBucketBackend returns a new instance of BucketBackend.
@@ -42,6 +52,11 @@ func NewBucketBackend(log *zap.Logger, b *APIBackend) *BucketBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		MeasurementSchemaService:   b.MeasurementSchemaService,
+		BucketRestoreService:       b.BucketRestoreService,
+		BucketTransferService:      b.BucketTransferService,
+		BucketUsageService:         b.BucketUsageService,
+		BucketExportService:        b.BucketExportService,
 	}
 }
 
@@ -57,12 +72,21 @@ type BucketHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	OrganizationService        influxdb.OrganizationService
+	MeasurementSchemaService   influxdb.MeasurementSchemaService
+	BucketRestoreService       influxdb.BucketRestoreService
+	BucketTransferService      influxdb.BucketTransferService
+	BucketUsageService         influxdb.BucketUsageService
+	BucketExportService        influxdb.BucketExportService
 }
 
 const (
 	prefixBuckets          = "/api/v2/buckets"
 	bucketsIDPath          = "/api/v2/buckets/:id"
 	bucketsIDLogPath       = "/api/v2/buckets/:id/logs"
+	bucketsIDRestorePath   = "/api/v2/buckets/:id/restore"
+	bucketsIDTransferPath  = "/api/v2/buckets/:id/transfer"
+	bucketsIDUsagePath     = "/api/v2/buckets/:id/usage"
+	bucketsIDExportPath    = "/api/v2/buckets/:id/export"
 	bucketsIDMembersPath   = "/api/v2/buckets/:id/members"
 	bucketsIDMembersIDPath = "/api/v2/buckets/:id/members/:userID"
 	bucketsIDOwnersPath    = "/api/v2/buckets/:id/owners"
@@ -84,6 +108,11 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		MeasurementSchemaService:   b.MeasurementSchemaService,
+		BucketRestoreService:       b.BucketRestoreService,
+		BucketTransferService:      b.BucketTransferService,
+		BucketUsageService:         b.BucketUsageService,
+		BucketExportService:        b.BucketExportService,
 	}
 
 	h.HandlerFunc("POST", prefixBuckets, h.handlePostBucket)
@@ -92,6 +121,10 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 	h.HandlerFunc("GET", bucketsIDLogPath, h.handleGetBucketLog)
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
+	h.HandlerFunc("POST", bucketsIDRestorePath, h.handlePostBucketRestore)
+	h.HandlerFunc("POST", bucketsIDTransferPath, h.handlePostBucketTransfer)
+	h.HandlerFunc("GET", bucketsIDUsagePath, h.handleGetBucketUsage)
+	h.HandlerFunc("GET", bucketsIDExportPath, h.handleGetBucketExport)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -127,25 +160,85 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 	h.HandlerFunc("POST", bucketsIDLabelsPath, newPostLabelHandler(labelBackend))
 	h.HandlerFunc("DELETE", bucketsIDLabelsIDPath, newDeleteLabelHandler(labelBackend))
 
+	h.HandlerFunc("GET", bucketsIDSchemaMeasurementsPath, h.handleGetMeasurementSchemas)
+	h.HandlerFunc("POST", bucketsIDSchemaMeasurementsPath, h.handlePostMeasurementSchema)
+	h.HandlerFunc("GET", bucketsIDSchemaMeasurementsIDPath, h.handleGetMeasurementSchema)
+	h.HandlerFunc("PATCH", bucketsIDSchemaMeasurementsIDPath, h.handlePatchMeasurementSchema)
+
 	return h
 }
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Type                string          `json:"type"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID       `json:"id,omitempty"`
+	OrgID               influxdb.ID       `json:"orgID,omitempty"`
+	Type                string            `json:"type"`
+	Description         string            `json:"description,omitempty"`
+	Name                string            `json:"name"`
+	RetentionPolicyName string            `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule   `json:"retentionRules"`
+	ShardGroupDuration  int64             `json:"shardGroupDurationSeconds,omitempty"`
+	Precision           string            `json:"precision,omitempty"`
+	CompactionStrategy  string            `json:"compactionStrategy,omitempty"`
+	CacheBudget         int64             `json:"cacheBudgetBytes,omitempty"`
+	CacheWriteCold      int64             `json:"cacheWriteColdDurationSeconds,omitempty"`
+	MaxSeries           int               `json:"maxSeries,omitempty"`
+	SchemaType          string            `json:"schemaType,omitempty"`
+	Rollups             []rollupRule      `json:"rollups,omitempty"`
+	DeletedAt           *time.Time        `json:"deletedAt,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
 	influxdb.CRUDLog
 }
 
-// retentionRule is the retention rule action for a bucket.
+// retentionRule is the retention rule action for a bucket. A rule with a
+// measurement applies to the data of that measurement only.
 type retentionRule struct {
 	Type         string `json:"type"`
 	EverySeconds int64  `json:"everySeconds"`
+	Measurement  string `json:"measurement,omitempty"`
+}
+
+// rollupRule is a rule downsampling the data written to a bucket into another
+// bucket as it is written.
+type rollupRule struct {
+	Measurement    string      `json:"measurement,omitempty"`
+	EverySeconds   int64       `json:"everySeconds"`
+	Function       string      `json:"function"`
+	TargetBucketID influxdb.ID `json:"targetBucketID"`
+}
+
+// rollups returns the rollups of rules, which is nil if rules is nil.
+func rollups(rules []rollupRule) []influxdb.Rollup {
+	if rules == nil {
+		return nil
+	}
+	rs := make([]influxdb.Rollup, 0, len(rules))
+	for _, r := range rules {
+		rs = append(rs, influxdb.Rollup{
+			Measurement:    r.Measurement,
+			Every:          time.Duration(r.EverySeconds) * time.Second,
+			Function:       r.Function,
+			TargetBucketID: r.TargetBucketID,
+		})
+	}
+	return rs
+}
+
+// newRollupRules returns the rules of rollups, which is nil if rs is nil.
+func newRollupRules(rs []influxdb.Rollup) []rollupRule {
+	if rs == nil {
+		return nil
+	}
+	rules := make([]rollupRule, 0, len(rs))
+	for _, r := range rs {
+		rules = append(rules, rollupRule{
+			Measurement:    r.Measurement,
+			EverySeconds:   int64(r.Every.Round(time.Second) / time.Second),
+			Function:       r.Function,
+			TargetBucketID: r.TargetBucketID,
+		})
+	}
+	return rules
 }
 
 func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
@@ -160,33 +253,87 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// retentionPeriods returns the retention period of a bucket, taken from the
+// first of rules without a measurement, and the retention periods of the
+// measurements of the bucket.
+func retentionPeriods(rules []retentionRule) (time.Duration, []influxdb.MeasurementRetention, error) {
+	var d time.Duration // zero value implies infinite retention policy
+	var bucketRule bool
+	mrs := []influxdb.MeasurementRetention{}
+	for _, rr := range rules {
+		t, err := rr.RetentionPeriod()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if rr.Measurement != "" {
+			mrs = append(mrs, influxdb.MeasurementRetention{
+				Measurement:     rr.Measurement,
+				RetentionPeriod: t,
+			})
+		} else if !bucketRule {
+			// Only support a single retention period for the bucket for the
+			// moment.
+			d, bucketRule = t, true
+		}
+	}
+	return d, mrs, nil
+}
+
+// newRetentionRules returns the retention rules of a retention period and the
+// retention periods of measurements.
+func newRetentionRules(d time.Duration, mrs []influxdb.MeasurementRetention) []retentionRule {
+	rules := []retentionRule{}
+	rp := int64(d.Round(time.Second) / time.Second)
+	if rp > 0 {
+		rules = append(rules, retentionRule{
+			Type:         "expire",
+			EverySeconds: rp,
+		})
+	}
+	for _, mr := range mrs {
+		rules = append(rules, retentionRule{
+			Type:         "expire",
+			EverySeconds: int64(mr.RetentionPeriod.Round(time.Second) / time.Second),
+			Measurement:  mr.Measurement,
+		})
+	}
+	return rules
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
 	}
 
-	var d time.Duration // zero value implies infinite retention policy
-
-	// Only support a single retention period for the moment
-	if len(b.RetentionRules) > 0 {
-		d = time.Duration(b.RetentionRules[0].EverySeconds) * time.Second
-		if d < time.Second {
-			return nil, &influxdb.Error{
-				Code: influxdb.EUnprocessableEntity,
-				Msg:  "expiration seconds must be greater than or equal to one second",
-			}
-		}
+	d, mrs, err := retentionPeriods(b.RetentionRules)
+	if err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		mrs = nil
 	}
 
 	return &influxdb.Bucket{
-		ID:                  b.ID,
-		OrgID:               b.OrgID,
-		Type:                influxdb.ParseBucketType(b.Type),
-		Description:         b.Description,
-		Name:                b.Name,
-		RetentionPolicyName: b.RetentionPolicyName,
-		RetentionPeriod:     d,
-		CRUDLog:             b.CRUDLog,
+		ID:                     b.ID,
+		OrgID:                  b.OrgID,
+		Type:                   influxdb.ParseBucketType(b.Type),
+		Description:            b.Description,
+		Name:                   b.Name,
+		RetentionPolicyName:    b.RetentionPolicyName,
+		RetentionPeriod:        d,
+		ShardGroupDuration:     time.Duration(b.ShardGroupDuration) * time.Second,
+		Precision:              b.Precision,
+		CompactionStrategy:     b.CompactionStrategy,
+		CacheBudget:            b.CacheBudget,
+		MaxSeries:              b.MaxSeries,
+		MeasurementRetention:   mrs,
+		CacheWriteColdDuration: time.Duration(b.CacheWriteCold) * time.Second,
+		SchemaType:             b.SchemaType,
+		Rollups:                rollups(b.Rollups),
+		DeletedAt:              b.DeletedAt,
+		Metadata:               b.Metadata,
+		CRUDLog:                b.CRUDLog,
 	}, nil
 }
 
@@ -195,14 +342,7 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		return nil
 	}
 
-	rules := []retentionRule{}
-	rp := int64(pb.RetentionPeriod.Round(time.Second) / time.Second)
-	if rp > 0 {
-		rules = append(rules, retentionRule{
-			Type:         "expire",
-			EverySeconds: rp,
-		})
-	}
+	rules := newRetentionRules(pb.RetentionPeriod, pb.MeasurementRetention)
 
 	return &bucket{
 		ID:                  pb.ID,
@@ -212,25 +352,40 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		ShardGroupDuration:  int64(pb.ShardGroupDuration.Round(time.Second) / time.Second),
+		Precision:           pb.Precision,
+		CompactionStrategy:  pb.CompactionStrategy,
+		CacheBudget:         pb.CacheBudget,
+		CacheWriteCold:      int64(pb.CacheWriteColdDuration.Round(time.Second) / time.Second),
+		MaxSeries:           pb.MaxSeries,
+		SchemaType:          pb.SchemaType,
+		Rollups:             newRollupRules(pb.Rollups),
+		DeletedAt:           pb.DeletedAt,
+		Metadata:            pb.Metadata,
 		CRUDLog:             pb.CRUDLog,
 	}
 }
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string         `json:"name,omitempty"`
-	Description    *string         `json:"description,omitempty"`
-	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	Name               *string         `json:"name,omitempty"`
+	Description        *string         `json:"description,omitempty"`
+	RetentionRules     []retentionRule `json:"retentionRules,omitempty"`
+	ShardGroupDuration *int64          `json:"shardGroupDurationSeconds,omitempty"`
+	Precision          *string         `json:"precision,omitempty"`
+	CompactionStrategy *string         `json:"compactionStrategy,omitempty"`
+	CacheBudget        *int64          `json:"cacheBudgetBytes,omitempty"`
+	CacheWriteCold     *int64          `json:"cacheWriteColdDurationSeconds,omitempty"`
+	MaxSeries          *int            `json:"maxSeries,omitempty"`
+	// Rollups replaces the rollups of the bucket when it is not null.
+	Rollups []rollupRule `json:"rollups"`
+	// Metadata replaces the metadata of the bucket when it is not null.
+	Metadata map[string]string `json:"metadata"`
 }
 
 func (b *bucketUpdate) OK() error {
-	if len(b.RetentionRules) > 0 {
-		_, err := b.RetentionRules[0].RetentionPeriod()
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	_, _, err := retentionPeriods(b.RetentionRules)
+	return err
 }
 
 func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
@@ -238,17 +393,33 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		return nil
 	}
 
-	// For now, only use a single retention rule.
-	var d time.Duration
-	if len(b.RetentionRules) > 0 {
-		d, _ = b.RetentionRules[0].RetentionPeriod()
-	}
+	d, mrs, _ := retentionPeriods(b.RetentionRules)
 
-	return &influxdb.BucketUpdate{
-		Name:            b.Name,
-		Description:     b.Description,
-		RetentionPeriod: &d,
+	upd := &influxdb.BucketUpdate{
+		Name:               b.Name,
+		Description:        b.Description,
+		RetentionPeriod:    &d,
+		Precision:          b.Precision,
+		CompactionStrategy: b.CompactionStrategy,
+		CacheBudget:        b.CacheBudget,
+		MaxSeries:          b.MaxSeries,
+		Rollups:            rollups(b.Rollups),
+		Metadata:           b.Metadata,
+	}
+	// The retention periods of measurements are only replaced when the
+	// update has retention rules, so that updating other settings keeps them.
+	if b.RetentionRules != nil {
+		upd.MeasurementRetention = mrs
+	}
+	if b.ShardGroupDuration != nil {
+		sgd := time.Duration(*b.ShardGroupDuration) * time.Second
+		upd.ShardGroupDuration = &sgd
+	}
+	if b.CacheWriteCold != nil {
+		d := time.Duration(*b.CacheWriteCold) * time.Second
+		upd.CacheWriteColdDuration = &d
 	}
+	return upd
 }
 
 func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
@@ -257,9 +428,15 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 	}
 
 	up := &bucketUpdate{
-		Name:           pb.Name,
-		Description:    pb.Description,
-		RetentionRules: []retentionRule{},
+		Name:               pb.Name,
+		Description:        pb.Description,
+		RetentionRules:     []retentionRule{},
+		Precision:          pb.Precision,
+		CompactionStrategy: pb.CompactionStrategy,
+		CacheBudget:        pb.CacheBudget,
+		MaxSeries:          pb.MaxSeries,
+		Rollups:            newRollupRules(pb.Rollups),
+		Metadata:           pb.Metadata,
 	}
 
 	if pb.RetentionPeriod != nil {
@@ -269,6 +446,22 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 			EverySeconds: d,
 		})
 	}
+	for _, mr := range pb.MeasurementRetention {
+		up.RetentionRules = append(up.RetentionRules, retentionRule{
+			Type:         "expire",
+			EverySeconds: int64(mr.RetentionPeriod.Round(time.Second) / time.Second),
+			Measurement:  mr.Measurement,
+		})
+	}
+
+	if pb.ShardGroupDuration != nil {
+		d := int64((*pb.ShardGroupDuration).Round(time.Second) / time.Second)
+		up.ShardGroupDuration = &d
+	}
+	if pb.CacheWriteColdDuration != nil {
+		d := int64((*pb.CacheWriteColdDuration).Round(time.Second) / time.Second)
+		up.CacheWriteCold = &d
+	}
 	return up
 }
 
@@ -307,12 +500,19 @@ type bucketsResponse struct {
 
 func newBucketsResponse(ctx context.Context, opts influxdb.FindOptions, f influxdb.BucketFilter, bs []*influxdb.Bucket, labelService influxdb.LabelService) *bucketsResponse {
 	rs := make([]*bucketResponse, 0, len(bs))
+	ids := make([]influxdb.ID, 0, len(bs))
 	for _, b := range bs {
 		labels, _ := labelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: b.ID})
 		rs = append(rs, NewBucketResponse(b, labels))
+
+		// The placeholder system buckets listed with the buckets of old
+		// organizations are not where the listing ended.
+		if b.ID != influxdb.TasksSystemBucketID && b.ID != influxdb.MonitoringSystemBucketID {
+			ids = append(ids, b.ID)
+		}
 	}
 	return &bucketsResponse{
-		Links:   newPagingLinks(prefixBuckets, opts, f, len(bs)),
+		Links:   newPagingLinks(prefixBuckets, opts, f, ids),
 		Buckets: rs,
 	}
 }
@@ -336,11 +536,20 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 }
 
 type postBucketRequest struct {
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	OrgID               influxdb.ID       `json:"orgID,omitempty"`
+	Name                string            `json:"name"`
+	Description         string            `json:"description"`
+	RetentionPolicyName string            `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule   `json:"retentionRules"`
+	ShardGroupDuration  int64             `json:"shardGroupDurationSeconds,omitempty"`
+	Precision           string            `json:"precision,omitempty"`
+	CompactionStrategy  string            `json:"compactionStrategy,omitempty"`
+	CacheBudget         int64             `json:"cacheBudgetBytes,omitempty"`
+	CacheWriteCold      int64             `json:"cacheWriteColdDurationSeconds,omitempty"`
+	MaxSeries           int               `json:"maxSeries,omitempty"`
+	SchemaType          string            `json:"schemaType,omitempty"`
+	Rollups             []rollupRule      `json:"rollups,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -351,13 +560,10 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
-	// Only support a single retention period for the moment
-	if len(b.RetentionRules) > 0 {
-		if _, err := b.RetentionRules[0].RetentionPeriod(); err != nil {
-			return &influxdb.Error{
-				Code: influxdb.EUnprocessableEntity,
-				Msg:  err.Error(),
-			}
+	if _, _, err := retentionPeriods(b.RetentionRules); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  err.Error(),
 		}
 	}
 
@@ -373,19 +579,28 @@ func (b *postBucketRequest) OK() error {
 }
 
 func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
-	// Only support a single retention period for the moment
-	var dur time.Duration
-	if len(b.RetentionRules) > 0 {
-		dur, _ = b.RetentionRules[0].RetentionPeriod()
+	dur, mrs, _ := retentionPeriods(b.RetentionRules)
+	if len(mrs) == 0 {
+		mrs = nil
 	}
 
 	return &influxdb.Bucket{
-		OrgID:               b.OrgID,
-		Description:         b.Description,
-		Name:                b.Name,
-		Type:                influxdb.BucketTypeUser,
-		RetentionPolicyName: b.RetentionPolicyName,
-		RetentionPeriod:     dur,
+		OrgID:                  b.OrgID,
+		Description:            b.Description,
+		Name:                   b.Name,
+		Type:                   influxdb.BucketTypeUser,
+		RetentionPolicyName:    b.RetentionPolicyName,
+		RetentionPeriod:        dur,
+		ShardGroupDuration:     time.Duration(b.ShardGroupDuration) * time.Second,
+		Precision:              b.Precision,
+		CompactionStrategy:     b.CompactionStrategy,
+		CacheBudget:            b.CacheBudget,
+		MaxSeries:              b.MaxSeries,
+		MeasurementRetention:   mrs,
+		CacheWriteColdDuration: time.Duration(b.CacheWriteCold) * time.Second,
+		SchemaType:             b.SchemaType,
+		Rollups:                rollups(b.Rollups),
+		Metadata:               b.Metadata,
 	}
 }
 
@@ -476,6 +691,201 @@ func (h *BucketHandler) handleDeleteBucket(w http.ResponseWriter, r *http.Reques
 	h.api.Respond(w, http.StatusNoContent, nil)
 }
 
+// handlePostBucketRestore is the HTTP handler for the POST /api/v2/buckets/:id/restore route.
+func (h *BucketHandler) handlePostBucketRestore(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	b, err := h.BucketRestoreService.RestoreBucket(ctx, id)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: b.ID})
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	h.log.Debug("Bucket restored", zap.String("bucketID", id.String()))
+
+	h.api.Respond(w, http.StatusOK, NewBucketResponse(b, labels))
+}
+
+// handlePostBucketTransfer is the HTTP handler for the POST /api/v2/buckets/:id/transfer route.
+func (h *BucketHandler) handlePostBucketTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	var req postBucketTransferRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	b, err := h.BucketTransferService.TransferBucket(ctx, id, req.OrgID)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: b.ID})
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	h.log.Debug("Bucket transferred", zap.String("bucketID", id.String()), zap.String("orgID", req.OrgID.String()))
+
+	h.api.Respond(w, http.StatusOK, NewBucketResponse(b, labels))
+}
+
+type postBucketTransferRequest struct {
+	OrgID influxdb.ID `json:"orgID"`
+}
+
+func (r *postBucketTransferRequest) OK() error {
+	if !r.OrgID.Valid() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "organization id must be provided",
+		}
+	}
+	return nil
+}
+
+// handleGetBucketUsage is the HTTP handler for the GET /api/v2/buckets/:id/usage route.
+func (h *BucketHandler) handleGetBucketUsage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	u, err := h.BucketUsageService.FindBucketUsage(ctx, id)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	h.api.Respond(w, http.StatusOK, u)
+}
+
+// handleGetBucketExport is the HTTP handler for the GET /api/v2/buckets/:id/export route.
+// The line protocol of the export is streamed gzip compressed.
+func (h *BucketHandler) handleGetBucketExport(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "BucketHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	qp := r.URL.Query()
+	start, stop, err := decodeExportRange(qp.Get("start"), qp.Get("stop"))
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Encoding", "gzip")
+	cw := iocounter.Writer{Writer: w}
+	gw := gzip.NewWriter(&cw)
+	if err := h.BucketExportService.ExportBucket(ctx, id, start, stop, qp.Get("cursor"), gw); err != nil {
+		if cw.Count() == 0 {
+			// Only respond with the error IFF nothing has been written to w.
+			w.Header().Del("Content-Encoding")
+			h.api.Err(w, err)
+			return
+		}
+		// The gzip stream is left unterminated, so that clients see the
+		// export was cut short and can resume it from its last cursor.
+		_ = tracing.LogError(span, err)
+		h.log.Info("Error exporting bucket",
+			zap.String("handler", "bucket"),
+			zap.Error(err),
+		)
+		return
+	}
+	if err := gw.Close(); err != nil {
+		h.log.Info("Error writing response to client",
+			zap.String("handler", "bucket"),
+			zap.Error(err),
+		)
+	}
+}
+
+// decodeExportRange returns the range of times of the start and stop query
+// parameters of an export. The range is unbounded if they are not given.
+func decodeExportRange(qStart, qStop string) (int64, int64, error) {
+	start, stop := int64(math.MinInt64), int64(math.MaxInt64)
+	if qStart != "" {
+		t, err := time.Parse(time.RFC3339Nano, qStart)
+		if err != nil {
+			return 0, 0, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid RFC3339Nano for parameter start",
+				Err:  err,
+			}
+		}
+		start = t.UnixNano()
+	}
+	if qStop != "" {
+		t, err := time.Parse(time.RFC3339Nano, qStop)
+		if err != nil {
+			return 0, 0, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid RFC3339Nano for parameter stop",
+				Err:  err,
+			}
+		}
+		stop = t.UnixNano()
+	}
+	if start > stop {
+		return 0, 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "start must not be after stop",
+		}
+	}
+	return start, stop, nil
+}
+
+// decodeDeletedFromQuery returns whether the deleted buckets are selected by
+// the query.
+func decodeDeletedFromQuery(q map[string][]string) (bool, error) {
+	v := q["deleted"]
+	if len(v) == 0 || v[0] == "" {
+		return false, nil
+	}
+	deleted, err := strconv.ParseBool(v[0])
+	if err != nil {
+		return false, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "deleted must be true or false",
+			Err:  err,
+		}
+	}
+	return deleted, nil
+}
+
 // handleGetBuckets is the HTTP handler for the GET /api/v2/buckets route.
 func (h *BucketHandler) handleGetBuckets(w http.ResponseWriter, r *http.Request) {
 	var filter influxdb.BucketFilter
@@ -505,6 +915,11 @@ func (h *BucketHandler) handleGetBuckets(w http.ResponseWriter, r *http.Request)
 		filter.OrganizationID = &orgID
 	}
 
+	if filter.Deleted, err = decodeDeletedFromQuery(q); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
 	opts, err := decodeFindOptions(r)
 	if err != nil {
 		h.api.Err(w, err)
@@ -561,6 +976,10 @@ func decodeGetBucketsRequest(r *http.Request) (*getBucketsRequest, error) {
 		req.filter.ID = id
 	}
 
+	if req.filter.Deleted, err = decodeDeletedFromQuery(qp); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
@@ -713,6 +1132,9 @@ func (s *BucketService) FindBuckets(ctx context.Context, filter influxdb.BucketF
 	if filter.Name != nil {
 		params = append(params, [2]string{"name", (*filter.Name)})
 	}
+	if filter.Deleted {
+		params = append(params, [2]string{"deleted", "true"})
+	}
 
 	var bs bucketsResponse
 	err := s.Client.
@@ -780,6 +1202,98 @@ func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error
 		Do(ctx)
 }
 
+// FindDeletedBucketByID returns a deleted bucket by ID.
+func (s *BucketService) FindDeletedBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	bs, n, err := s.FindBuckets(ctx, influxdb.BucketFilter{ID: &id, Deleted: true})
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "deleted bucket not found",
+		}
+	}
+	return bs[0], nil
+}
+
+// RestoreBucket undeletes a deleted bucket.
+func (s *BucketService) RestoreBucket(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	var br bucketResponse
+	err := s.Client.
+		Post(nil, path.Join(bucketIDPath(id), "restore")).
+		DecodeJSON(&br).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return br.toInfluxDB()
+}
+
+// RenameBucket gives a bucket another name.
+func (s *BucketService) RenameBucket(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+	return s.UpdateBucket(ctx, id, influxdb.BucketUpdate{Name: &name})
+}
+
+// TransferBucket moves a bucket to another organization.
+func (s *BucketService) TransferBucket(ctx context.Context, id, orgID influxdb.ID) (*influxdb.Bucket, error) {
+	var br bucketResponse
+	err := s.Client.
+		PostJSON(postBucketTransferRequest{OrgID: orgID}, path.Join(bucketIDPath(id), "transfer")).
+		DecodeJSON(&br).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return br.toInfluxDB()
+}
+
+// FindBucketUsage returns the storage used by the data of a bucket.
+func (s *BucketService) FindBucketUsage(ctx context.Context, id influxdb.ID) (*influxdb.BucketUsage, error) {
+	var u influxdb.BucketUsage
+	err := s.Client.
+		Get(bucketIDPath(id), "usage").
+		DecodeJSON(&u).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// ExportBucket writes the points of a bucket with times in [start, stop) to w
+// as line protocol.
+func (s *BucketService) ExportBucket(ctx context.Context, id influxdb.ID, start, stop int64, cursor string, w io.Writer) error {
+	var params [][2]string
+	if start != math.MinInt64 {
+		params = append(params, [2]string{"start", time.Unix(0, start).UTC().Format(time.RFC3339Nano)})
+	}
+	if stop != math.MaxInt64 {
+		params = append(params, [2]string{"stop", time.Unix(0, stop).UTC().Format(time.RFC3339Nano)})
+	}
+	if cursor != "" {
+		params = append(params, [2]string{"cursor", cursor})
+	}
+
+	return s.Client.
+		Get(bucketIDPath(id), "export").
+		QueryParams(params...).
+		Decode(func(resp *http.Response) error {
+			var r io.Reader = resp.Body
+			if resp.Header.Get("Content-Encoding") == "gzip" {
+				gr, err := gzip.NewReader(resp.Body)
+				if err != nil {
+					return err
+				}
+				defer gr.Close()
+				r = gr
+			}
+			_, err := io.Copy(w, r)
+			return err
+		}).
+		Do(ctx)
+}
+
 // validBucketName reports any errors with bucket names
 func validBucketName(bucket *influxdb.Bucket) error {
 	// names starting with an underscore are reserved for system buckets
@@ -2,12 +2,17 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -106,7 +111,7 @@ func TestService_handleGetBuckets(t *testing.T) {
 {
   "links": {
     "self": "/api/v2/buckets?descending=false&limit=1&offset=0",
-    "next": "/api/v2/buckets?descending=false&limit=1&offset=1"
+    "next": "/api/v2/buckets?after=YzAxNzVmMDA3N2E3NzAwNQ&descending=false&limit=1"
   },
   "buckets": [
     {
@@ -431,6 +436,60 @@ func TestService_handlePostBucket(t *testing.T) {
   "retentionRules": [],
   "labels": []
 }
+`,
+			},
+		},
+		{
+			name: "create a new bucket with measurement retention rules",
+			fields: fields{
+				BucketService: &mock.BucketService{
+					CreateBucketFn: func(ctx context.Context, c *platform.Bucket) error {
+						c.ID = platformtesting.MustIDBase16("020f755c3c082000")
+						return nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{ID: platformtesting.MustIDBase16("6f626f7274697320")}, nil
+					},
+				},
+			},
+			args: args{
+				bucket: &platform.Bucket{
+					Name:            "hello",
+					OrgID:           platformtesting.MustIDBase16("6f626f7274697320"),
+					RetentionPeriod: 30 * 24 * time.Hour,
+					MeasurementRetention: []platform.MeasurementRetention{
+						{Measurement: "events", RetentionPeriod: 2 * 365 * 24 * time.Hour},
+					},
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/6f626f7274697320",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "write": "/api/v2/write?org=6f626f7274697320&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "6f626f7274697320",
+  "type": "user",
+  "name": "hello",
+  "retentionRules": [
+    {"type": "expire", "everySeconds": 2592000},
+    {"type": "expire", "everySeconds": 63072000, "measurement": "events"}
+  ],
+  "labels": []
+}
 `,
 			},
 		},
@@ -609,9 +668,11 @@ func TestService_handlePatchBucket(t *testing.T) {
 		BucketService platform.BucketService
 	}
 	type args struct {
-		id        string
-		name      string
-		retention time.Duration
+		id                 string
+		name               string
+		retention          time.Duration
+		shardGroupDuration time.Duration
+		precision          string
 	}
 	type wants struct {
 		statusCode  int
@@ -687,6 +748,106 @@ func TestService_handlePatchBucket(t *testing.T) {
   "retentionRules": [{"type": "expire", "everySeconds": 2}],
   "labels": []
 }
+`,
+			},
+		},
+		{
+			name: "update a bucket shard group duration",
+			fields: fields{
+				&mock.BucketService{
+					UpdateBucketFn: func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+						d := &platform.Bucket{
+							ID:              platformtesting.MustIDBase16("020f755c3c082000"),
+							Name:            "hello",
+							OrgID:           platformtesting.MustIDBase16("020f755c3c082000"),
+							RetentionPeriod: *upd.RetentionPeriod,
+						}
+						if upd.ShardGroupDuration != nil {
+							d.ShardGroupDuration = *upd.ShardGroupDuration
+						}
+						return d, nil
+					},
+				},
+			},
+			args: args{
+				id:                 "020f755c3c082000",
+				retention:          24 * time.Hour,
+				shardGroupDuration: 6 * time.Hour,
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/020f755c3c082000",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "write": "/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "020f755c3c082000",
+  "type": "user",
+  "name": "hello",
+  "retentionRules": [{"type": "expire", "everySeconds": 86400}],
+  "shardGroupDurationSeconds": 21600,
+  "labels": []
+}
+`,
+			},
+		},
+		{
+			name: "update a bucket precision",
+			fields: fields{
+				&mock.BucketService{
+					UpdateBucketFn: func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+						d := &platform.Bucket{
+							ID:              platformtesting.MustIDBase16("020f755c3c082000"),
+							Name:            "hello",
+							OrgID:           platformtesting.MustIDBase16("020f755c3c082000"),
+							RetentionPeriod: *upd.RetentionPeriod,
+						}
+						if upd.Precision != nil {
+							d.Precision = *upd.Precision
+						}
+						return d, nil
+					},
+				},
+			},
+			args: args{
+				id:        "020f755c3c082000",
+				retention: 24 * time.Hour,
+				precision: "s",
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/020f755c3c082000",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "write": "/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "020f755c3c082000",
+  "type": "user",
+  "name": "hello",
+  "retentionRules": [{"type": "expire", "everySeconds": 86400}],
+  "precision": "s",
+  "labels": []
+}
 `,
 			},
 		},
@@ -943,6 +1104,14 @@ func TestService_handlePatchBucket(t *testing.T) {
 				upd.RetentionPeriod = &tt.args.retention
 			}
 
+			if tt.args.shardGroupDuration != 0 {
+				upd.ShardGroupDuration = &tt.args.shardGroupDuration
+			}
+
+			if tt.args.precision != "" {
+				upd.Precision = &tt.args.precision
+			}
+
 			b, err := json.Marshal(newBucketUpdate(&upd))
 			if err != nil {
 				t.Fatalf("failed to unmarshal bucket update: %v", err)
@@ -985,6 +1154,517 @@ func TestService_handlePatchBucket(t *testing.T) {
 	}
 }
 
+func TestService_handlePatchBucket_MeasurementRetention(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []platform.MeasurementRetention
+	}{
+		{
+			name: "update without retention rules keeps measurement retention",
+			body: `{"maxSeries": 10}`,
+		},
+		{
+			name: "update with retention rules replaces measurement retention",
+			body: `{"retentionRules": [{"type": "expire", "everySeconds": 60, "measurement": "cpu"}]}`,
+			want: []platform.MeasurementRetention{
+				{Measurement: "cpu", RetentionPeriod: time.Minute},
+			},
+		},
+		{
+			name: "update with empty retention rules removes measurement retention",
+			body: `{"retentionRules": []}`,
+			want: []platform.MeasurementRetention{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []platform.MeasurementRetention
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketService = &mock.BucketService{
+				UpdateBucketFn: func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+					got = upd.MeasurementRetention
+					return &platform.Bucket{
+						ID:    id,
+						Name:  "hello",
+						OrgID: platformtesting.MustIDBase16("020f755c3c082000"),
+					}, nil
+				},
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("PATCH", "http://any.url", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			h.handlePatchBucket(w, r)
+
+			if res := w.Result(); res.StatusCode != http.StatusOK {
+				t.Fatalf("handlePatchBucket() = %v, want %v", res.StatusCode, http.StatusOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got measurement retention %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_handlePatchBucket_Metadata(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want map[string]string
+	}{
+		{
+			name: "update without metadata keeps metadata",
+			body: `{"maxSeries": 10}`,
+		},
+		{
+			name: "update with metadata replaces metadata",
+			body: `{"metadata": {"team": "platform"}}`,
+			want: map[string]string{"team": "platform"},
+		},
+		{
+			name: "update with empty metadata removes metadata",
+			body: `{"metadata": {}}`,
+			want: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketService = &mock.BucketService{
+				UpdateBucketFn: func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+					got = upd.Metadata
+					return &platform.Bucket{
+						ID:       id,
+						Name:     "hello",
+						OrgID:    platformtesting.MustIDBase16("020f755c3c082000"),
+						Metadata: upd.Metadata,
+					}, nil
+				},
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("PATCH", "http://any.url", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			h.handlePatchBucket(w, r)
+
+			if res := w.Result(); res.StatusCode != http.StatusOK {
+				t.Fatalf("handlePatchBucket() = %v, want %v", res.StatusCode, http.StatusOK)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got metadata %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBuckets_Deleted(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleted     string
+		wantStatus  int
+		wantDeleted bool
+	}{
+		{
+			name:       "live buckets are listed by default",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "deleted buckets are listed when asked for",
+			deleted:     "true",
+			wantStatus:  http.StatusOK,
+			wantDeleted: true,
+		},
+		{
+			name:       "invalid deleted value",
+			deleted:    "maybe",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got bool
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketService = &mock.BucketService{
+				FindBucketsFn: func(ctx context.Context, filter platform.BucketFilter, opts ...platform.FindOptions) ([]*platform.Bucket, int, error) {
+					got = filter.Deleted
+					return nil, 0, nil
+				},
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("GET", "http://any.url?deleted="+tt.deleted, nil)
+			w := httptest.NewRecorder()
+			h.handleGetBuckets(w, r)
+
+			if res := w.Result(); res.StatusCode != tt.wantStatus {
+				t.Fatalf("handleGetBuckets() = %v, want %v", res.StatusCode, tt.wantStatus)
+			}
+			if got != tt.wantDeleted {
+				t.Errorf("got deleted filter %v, want %v", got, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestService_handlePostBucketRestore(t *testing.T) {
+	tests := []struct {
+		name       string
+		restore    func(context.Context, platform.ID) (*platform.Bucket, error)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "restore a deleted bucket",
+			restore: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+				return &platform.Bucket{
+					ID:    id,
+					Name:  "hello",
+					OrgID: platformtesting.MustIDBase16("50f7ba1150f7ba11"),
+				}, nil
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `
+{
+  "links": {
+    "org": "/api/v2/orgs/50f7ba1150f7ba11",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "write": "/api/v2/write?org=50f7ba1150f7ba11&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "50f7ba1150f7ba11",
+  "type": "user",
+  "name": "hello",
+  "retentionRules": [],
+  "labels": []
+}
+`,
+		},
+		{
+			name: "restore a bucket whose name is taken",
+			restore: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+				return nil, &platform.Error{
+					Code: platform.EConflict,
+					Msg:  "bucket name is not unique",
+				}
+			},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketRestoreService = &mock.BucketRestoreService{
+				RestoreBucketFn: tt.restore,
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url", nil)
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			h.handlePostBucketRestore(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("handlePostBucketRestore() = %v, want %v: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wantBody); err != nil || !eq {
+				t.Errorf("handlePostBucketRestore() = ***%v***", diff)
+			}
+		})
+	}
+}
+
+func TestService_handlePostBucketTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantOrgID  platform.ID
+	}{
+		{
+			name:       "transfer a bucket",
+			body:       `{"orgID": "50f7ba1150f7ba11"}`,
+			wantStatus: http.StatusOK,
+			wantOrgID:  platformtesting.MustIDBase16("50f7ba1150f7ba11"),
+		},
+		{
+			name:       "transfer a bucket without an organization",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got platform.ID
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketTransferService = &mock.BucketTransferService{
+				TransferBucketFn: func(ctx context.Context, id, orgID platform.ID) (*platform.Bucket, error) {
+					got = orgID
+					return &platform.Bucket{
+						ID:           id,
+						Name:         "hello",
+						OrgID:        orgID,
+						StorageOrgID: platformtesting.MustIDBase16("020f755c3c082001"),
+					}, nil
+				},
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("POST", "http://any.url", strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			h.handlePostBucketTransfer(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("handlePostBucketTransfer() = %v, want %v: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if got != tt.wantOrgID {
+				t.Errorf("got bucket transferred to %s, want %s", got, tt.wantOrgID)
+			}
+			if tt.wantStatus == http.StatusOK && strings.Contains(string(body), "storageOrgID") {
+				t.Errorf("expected the organization the data is stored under not to be returned: %s", body)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBucketUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "get the usage of a bucket",
+			wantStatus: http.StatusOK,
+			wantBody: `
+{
+  "bucketID": "020f755c3c082000",
+  "diskBytes": 4096,
+  "cacheBytes": 512,
+  "series": 3,
+  "points": 120
+}`,
+		},
+		{
+			name:       "get the usage of a missing bucket",
+			err:        &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketUsageService = &mock.BucketUsageService{
+				FindBucketUsageFn: func(ctx context.Context, id platform.ID) (*platform.BucketUsage, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &platform.BucketUsage{
+						BucketID:   id,
+						DiskBytes:  4096,
+						CacheBytes: 512,
+						Series:     3,
+						Points:     120,
+					}, nil
+				},
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("GET", "http://any.url", nil)
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			h.handleGetBucketUsage(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("handleGetBucketUsage() = %v, want %v: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wantBody); err != nil || !eq {
+				t.Errorf("handleGetBucketUsage() = ***%v***", diff)
+			}
+		})
+	}
+}
+
+func TestService_handleGetBucketExport(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+		wantStart  int64
+		wantStop   int64
+		wantCursor string
+		wantBody   string
+	}{
+		{
+			name:       "export a bucket",
+			wantStatus: http.StatusOK,
+			wantStart:  math.MinInt64,
+			wantStop:   math.MaxInt64,
+			wantBody:   "cpu value=1 10\n",
+		},
+		{
+			name:       "resume an export of a time range",
+			query:      "?start=1970-01-01T00:00:00Z&stop=1970-01-01T00:00:01Z&cursor=abc",
+			wantStatus: http.StatusOK,
+			wantStart:  0,
+			wantStop:   int64(time.Second),
+			wantCursor: "abc",
+			wantBody:   "cpu value=1 10\n",
+		},
+		{
+			name:       "export with start after stop",
+			query:      "?start=1970-01-01T00:00:01Z&stop=1970-01-01T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "export a missing bucket",
+			err:        &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketBackend := NewMockBucketBackend(t)
+			bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			bucketBackend.BucketExportService = &mock.BucketExportService{
+				ExportBucketFn: func(ctx context.Context, id platform.ID, start, stop int64, cursor string, w io.Writer) error {
+					if tt.err != nil {
+						return tt.err
+					}
+					if start != tt.wantStart || stop != tt.wantStop || cursor != tt.wantCursor {
+						t.Errorf("got export of [%d, %d) after %q", start, stop, cursor)
+					}
+					_, err := io.WriteString(w, "cpu value=1 10\n")
+					return err
+				},
+			}
+			h := NewBucketHandler(zaptest.NewLogger(t), bucketBackend)
+
+			r := httptest.NewRequest("GET", "http://any.url"+tt.query, nil)
+			r = r.WithContext(context.WithValue(
+				context.Background(),
+				httprouter.ParamsKey,
+				httprouter.Params{
+					{
+						Key:   "id",
+						Value: "020f755c3c082000",
+					},
+				}))
+
+			w := httptest.NewRecorder()
+			h.handleGetBucketExport(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wantStatus {
+				body, _ := ioutil.ReadAll(res.Body)
+				t.Fatalf("handleGetBucketExport() = %v, want %v: %s", res.StatusCode, tt.wantStatus, body)
+			}
+			if tt.wantBody == "" {
+				if got := res.Header.Get("Content-Encoding"); got != "" {
+					t.Errorf("got Content-Encoding %q of error", got)
+				}
+				return
+			}
+			if got := res.Header.Get("Content-Encoding"); got != "gzip" {
+				t.Fatalf("got Content-Encoding %q, want gzip", got)
+			}
+			gr, err := gzip.NewReader(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := ioutil.ReadAll(gr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("handleGetBucketExport() = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
 func TestService_handlePostBucketMember(t *testing.T) {
 	type fields struct {
 		UserService platform.UserService
@@ -0,0 +1,128 @@
+package http
+
+import (
+	"fmt"
+	http "net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixCardinality = "/api/v2/stats/cardinality"
+)
+
+// CardinalityBackend is all services and associated parameters required to
+// construct the CardinalityHandler.
+type CardinalityBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	CardinalityService influxdb.CardinalityService
+}
+
+// NewCardinalityBackend returns a new instance of CardinalityBackend.
+func NewCardinalityBackend(log *zap.Logger, b *APIBackend) *CardinalityBackend {
+	return &CardinalityBackend{
+		log: log,
+
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		CardinalityService: b.CardinalityService,
+	}
+}
+
+// CardinalityHandler serves estimates of the series cardinality of each
+// measurement and its tag keys.
+type CardinalityHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	CardinalityService influxdb.CardinalityService
+}
+
+// NewCardinalityHandler creates a new handler at /api/v2/stats/cardinality to
+// serve cardinality estimates.
+func NewCardinalityHandler(log *zap.Logger, b *CardinalityBackend) *CardinalityHandler {
+	h := &CardinalityHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		CardinalityService: b.CardinalityService,
+	}
+
+	h.HandlerFunc("GET", prefixCardinality, h.handleGetCardinality)
+	return h
+}
+
+type cardinalityResponse struct {
+	Measurements []*influxdb.MeasurementCardinality `json:"measurements"`
+}
+
+func (h *CardinalityHandler) handleGetCardinality(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CardinalityHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeCardinalityFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	cards, err := h.CardinalityService.FindMeasurementCardinality(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if cards == nil {
+		cards = []*influxdb.MeasurementCardinality{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, cardinalityResponse{Measurements: cards}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeCardinalityFilter(r *http.Request) (*influxdb.CardinalityFilter, error) {
+	filter := &influxdb.CardinalityFilter{}
+	qp := r.URL.Query()
+
+	if orgID := qp.Get(OrgID); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		filter.OrgID = id
+	}
+
+	if bucketID := qp.Get(BucketID); bucketID != "" {
+		id, err := influxdb.IDFromString(bucketID)
+		if err != nil {
+			return nil, err
+		}
+		filter.BucketID = id
+	}
+
+	filter.Measurement = qp.Get("measurement")
+
+	if limit := qp.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("limit must be a positive integer, got %q", limit),
+			}
+		}
+		filter.Limit = l
+	}
+
+	return filter, nil
+}
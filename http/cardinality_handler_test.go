@@ -0,0 +1,153 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCardinalityHandler_handleGetCardinality(t *testing.T) {
+	type wants struct {
+		statusCode int
+		filter     *influxdb.CardinalityFilter
+		body       string
+	}
+
+	orgID, bucketID := influxdb.ID(0x020f755c3c082000), influxdb.ID(0x020f755c3c082001)
+
+	tests := []struct {
+		name        string
+		queryParams map[string][]string
+		wants       wants
+	}{
+		{
+			name:        "all measurements",
+			queryParams: map[string][]string{},
+			wants: wants{
+				statusCode: http.StatusOK,
+				filter:     &influxdb.CardinalityFilter{},
+				body: `{"measurements": [{
+					"orgID": "020f755c3c082000",
+					"bucketID": "020f755c3c082001",
+					"measurement": "cpu",
+					"series": 1000,
+					"tags": [
+						{"key": "host", "values": 1000},
+						{"key": "region", "values": 3}
+					]
+				}]}`,
+			},
+		},
+		{
+			name: "filtered",
+			queryParams: map[string][]string{
+				"orgID":       {"020f755c3c082000"},
+				"bucketID":    {"020f755c3c082001"},
+				"measurement": {"cpu"},
+				"limit":       {"5"},
+			},
+			wants: wants{
+				statusCode: http.StatusOK,
+				filter: &influxdb.CardinalityFilter{
+					OrgID:       &orgID,
+					BucketID:    &bucketID,
+					Measurement: "cpu",
+					Limit:       5,
+				},
+			},
+		},
+		{
+			name: "invalid limit",
+			queryParams: map[string][]string{
+				"limit": {"0"},
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "limit must be a positive integer, got \"0\""}`,
+			},
+		},
+		{
+			name: "invalid bucket id",
+			queryParams: map[string][]string{
+				"bucketID": {"bad"},
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFilter *influxdb.CardinalityFilter
+			svc := mock.NewCardinalityService()
+			svc.FindMeasurementCardinalityF = func(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+				gotFilter = &filter
+				return []*influxdb.MeasurementCardinality{{
+					OrgID:       orgID,
+					BucketID:    bucketID,
+					Measurement: "cpu",
+					Series:      1000,
+					Tags: []influxdb.TagKeyCardinality{
+						{Key: "host", Values: 1000},
+						{Key: "region", Values: 3},
+					},
+				}}, nil
+			}
+
+			h := NewCardinalityHandler(zaptest.NewLogger(t), &CardinalityBackend{
+				HTTPErrorHandler:   kithttp.ErrorHandler(0),
+				CardinalityService: svc,
+			})
+
+			r := httptest.NewRequest("GET", "http://any.tld"+prefixCardinality, nil)
+			qp := r.URL.Query()
+			for k, vs := range tt.queryParams {
+				for _, v := range vs {
+					qp.Add(k, v)
+				}
+			}
+			r.URL.RawQuery = qp.Encode()
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handleGetCardinality() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if tt.wants.filter != nil {
+				if gotFilter == nil {
+					t.Fatal("expected FindMeasurementCardinality to be called")
+				}
+				if !cardinalityFilterEqual(*gotFilter, *tt.wants.filter) {
+					t.Errorf("got filter %+v, want %+v", *gotFilter, *tt.wants.filter)
+				}
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("handleGetCardinality(). error unmarshaling json %v", err)
+				} else if !eq {
+					t.Errorf("handleGetCardinality() = ***%s***", diff)
+				}
+			}
+		})
+	}
+}
+
+func cardinalityFilterEqual(a, b influxdb.CardinalityFilter) bool {
+	idEqual := func(x, y *influxdb.ID) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return idEqual(a.OrgID, b.OrgID) && idEqual(a.BucketID, b.BucketID) &&
+		a.Measurement == b.Measurement && a.Limit == b.Limit
+}
@@ -31,6 +31,7 @@ type CheckBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	OrganizationService        influxdb.OrganizationService
+	RevisionService            influxdb.RevisionService
 }
 
 // NewCheckBackend returns a new instance of CheckBackend.
@@ -45,6 +46,7 @@ func NewCheckBackend(log *zap.Logger, b *APIBackend) *CheckBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		OrganizationService:        b.OrganizationService,
+		RevisionService:            b.RevisionService,
 	}
 }
 
@@ -60,6 +62,7 @@ type CheckHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	OrganizationService        influxdb.OrganizationService
+	RevisionService            influxdb.RevisionService
 }
 
 const (
@@ -87,6 +90,7 @@ func NewCheckHandler(log *zap.Logger, b *CheckBackend) *CheckHandler {
 		UserService:                b.UserService,
 		TaskService:                b.TaskService,
 		OrganizationService:        b.OrganizationService,
+		RevisionService:            b.RevisionService,
 	}
 	h.HandlerFunc("POST", prefixChecks, h.handlePostCheck)
 	h.HandlerFunc("GET", prefixChecks, h.handleGetChecks)
@@ -232,9 +236,13 @@ func (h *CheckHandler) newCheckResponse(ctx context.Context, chk influxdb.Check,
 }
 
 func (h *CheckHandler) newChecksResponse(ctx context.Context, chks []influxdb.Check, labelService influxdb.LabelService, f influxdb.PagingFilter, opts influxdb.FindOptions) *checksResponse {
+	ids := make([]influxdb.ID, len(chks))
+	for i, chk := range chks {
+		ids[i] = chk.GetID()
+	}
 	resp := &checksResponse{
 		Checks: []*checkResponse{},
-		Links:  newPagingLinks(prefixChecks, opts, f, len(chks)),
+		Links:  newPagingLinks(prefixChecks, opts, f, ids),
 	}
 	for _, chk := range chks {
 		labels, _ := labelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: chk.GetID()})
@@ -327,6 +335,11 @@ func (h *CheckHandler) handleGetCheck(w http.ResponseWriter, r *http.Request) {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	rev, hasRev, err := findRevision(ctx, h.RevisionService, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
 	chk, err := h.CheckService.FindCheckByID(ctx, id)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
@@ -346,6 +359,9 @@ func (h *CheckHandler) handleGetCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if hasRev {
+		setETag(w, rev)
+	}
 	if err := encodeResponse(ctx, w, http.StatusOK, cr); err != nil {
 		logEncodingError(h.log, r, err)
 		return
@@ -607,11 +623,13 @@ func (h *CheckHandler) handlePutCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	c, err := h.CheckService.UpdateCheck(ctx, chk.GetID(), chk)
+	m := decodeIfMatch(r)
+	c, err := h.CheckService.UpdateCheck(m.context(ctx, chk.GetID()), chk.GetID(), chk)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	m.setETag(w)
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: c.GetID()})
 	if err != nil {
@@ -642,11 +660,13 @@ func (h *CheckHandler) handlePatchCheck(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	chk, err := h.CheckService.PatchCheck(ctx, req.ID, req.Update)
+	m := decodeIfMatch(r)
+	chk, err := h.CheckService.PatchCheck(m.context(ctx, req.ID), req.ID, req.Update)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	m.setETag(w)
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: chk.GetID()})
 	if err != nil {
@@ -675,7 +695,7 @@ func (h *CheckHandler) handleDeleteCheck(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err = h.CheckService.DeleteCheck(ctx, i); err != nil {
+	if err = h.CheckService.DeleteCheck(decodeIfMatch(r).context(ctx, i), i); err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
@@ -126,7 +126,7 @@ func TestService_handleGetChecks(t *testing.T) {
 {
   "links": {
     "self": "/api/v2/checks?descending=false&limit=1&offset=0",
-    "next": "/api/v2/checks?descending=false&limit=1&offset=1"
+    "next": "/api/v2/checks?after=YzAxNzVmMDA3N2E3NzAwNQ&descending=false&limit=1"
   },
   "checks": [
     {
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"encoding/json"
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixCompaction         = "/api/v2/compaction"
+	compactionTombstonesPath = "/api/v2/compaction/tombstones"
+)
+
+// CompactionBackend is all services and associated parameters required to
+// construct the CompactionHandler.
+type CompactionBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	CompactionService influxdb.CompactionService
+	BucketService     influxdb.BucketService
+}
+
+// NewCompactionBackend returns a new instance of CompactionBackend.
+func NewCompactionBackend(log *zap.Logger, b *APIBackend) *CompactionBackend {
+	return &CompactionBackend{
+		log: log,
+
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		CompactionService: b.CompactionService,
+		BucketService:     b.BucketService,
+	}
+}
+
+// CompactionHandler reads and changes the compaction settings of the server
+// and compacts buckets on demand.
+type CompactionHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	CompactionService influxdb.CompactionService
+	BucketService     influxdb.BucketService
+}
+
+// NewCompactionHandler creates a new handler at /api/v2/compaction to manage
+// the compaction settings of the server and to compact buckets.
+func NewCompactionHandler(log *zap.Logger, b *CompactionBackend) *CompactionHandler {
+	h := &CompactionHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		CompactionService: b.CompactionService,
+		BucketService:     b.BucketService,
+	}
+
+	h.HandlerFunc("GET", prefixCompaction, h.handleGetCompaction)
+	h.HandlerFunc("PATCH", prefixCompaction, h.handlePatchCompaction)
+	h.HandlerFunc("POST", prefixCompaction, h.handlePostCompaction)
+	h.HandlerFunc("GET", compactionTombstonesPath, h.handleGetTombstones)
+	return h
+}
+
+func (h *CompactionHandler) handleGetCompaction(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	settings, err := h.CompactionService.CompactionSettings(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newCompactionSettingsResponse(settings)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *CompactionHandler) handlePatchCompaction(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var upd influxdb.CompactionSettingsUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	settings, err := h.CompactionService.UpdateCompactionSettings(ctx, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Compaction settings updated", zap.Any("settings", settings))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newCompactionSettingsResponse(settings)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostCompaction compacts the bucket given by the bucketID or bucket
+// query parameter, responding once the compaction has finished.
+func (h *CompactionHandler) handlePostCompaction(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	bucket, err := queryBucket(ctx, r, h.BucketService)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.CompactionService.CompactBucket(ctx, bucket.OrgID, bucket.ID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Bucket compacted", zap.String("bucketID", bucket.ID.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetTombstones serves the deleted data waiting to be removed from TSM
+// files by compaction.
+func (h *CompactionHandler) handleGetTombstones(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "CompactionHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	stats, err := h.CompactionService.TombstoneStats(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if stats.Files == nil {
+		stats.Files = []*influxdb.FileTombstoneStats{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, stats); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// newCompactionSettingsResponse returns the settings with quiet hours
+// encoded as an empty list rather than null when there are none.
+func newCompactionSettingsResponse(s *influxdb.CompactionSettings) *influxdb.CompactionSettings {
+	res := *s
+	if res.QuietHours == nil {
+		res.QuietHours = []string{}
+	}
+	return &res
+}
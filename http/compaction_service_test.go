@@ -0,0 +1,225 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCompactionHandler(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		wants  wants
+	}{
+		{
+			name:   "get settings",
+			method: "GET",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"maxConcurrentFull": 1, "throughput": 1024, "throughputBurst": 2048, "quietHours": []}`,
+			},
+		},
+		{
+			name:   "update settings",
+			method: "PATCH",
+			body:   `{"throughput": 4096, "quietHours": ["09:00-17:00"]}`,
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"maxConcurrentFull": 1, "throughput": 4096, "throughputBurst": 2048, "quietHours": ["09:00-17:00"]}`,
+			},
+		},
+		{
+			name:   "update with invalid settings",
+			method: "PATCH",
+			body:   `{"quietHours": ["nightly"]}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "invalid quiet hours"}`,
+			},
+		},
+		{
+			name:   "update with invalid json",
+			method: "PATCH",
+			body:   `{"throughput": "fast"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := influxdb.CompactionSettings{
+				MaxConcurrentFull: 1,
+				Throughput:        1024,
+				ThroughputBurst:   2048,
+			}
+			svc := mock.NewCompactionService()
+			svc.CompactionSettingsF = func(ctx context.Context) (*influxdb.CompactionSettings, error) {
+				return &settings, nil
+			}
+			svc.UpdateCompactionSettingsF = func(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+				if upd.QuietHours != nil && len(*upd.QuietHours) > 0 && (*upd.QuietHours)[0] == "nightly" {
+					return nil, &influxdb.Error{Code: influxdb.EInvalid, Msg: "invalid quiet hours"}
+				}
+				upd.Apply(&settings)
+				return &settings, nil
+			}
+
+			h := NewCompactionHandler(zaptest.NewLogger(t), &CompactionBackend{
+				HTTPErrorHandler:  kithttp.ErrorHandler(0),
+				CompactionService: svc,
+			})
+
+			r := httptest.NewRequest(tt.method, "http://any.tld"+prefixCompaction, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%s %s = %v, want %v: %s", tt.method, prefixCompaction, res.StatusCode, tt.wants.statusCode, body)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("%s %s. error unmarshaling json %v", tt.method, prefixCompaction, err)
+				} else if !eq {
+					t.Errorf("%s %s = ***%s***", tt.method, prefixCompaction, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCompactionHandler_PostCompaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		statusCode int
+		compacted  bool
+	}{
+		{
+			name:       "compact bucket",
+			query:      "?bucketID=020f755c3c082000",
+			statusCode: http.StatusNoContent,
+			compacted:  true,
+		},
+		{
+			name:       "missing bucket",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "bucket not found",
+			query:      "?bucketID=020f755c3c082001",
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucketSvc := mock.NewBucketService()
+			bucketSvc.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+				if filter.ID != nil && *filter.ID == influxdb.ID(0x020f755c3c082000) {
+					return &influxdb.Bucket{ID: *filter.ID, OrgID: influxdb.ID(0x020f755c3c083000)}, nil
+				}
+				return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+			}
+
+			var compacted bool
+			svc := mock.NewCompactionService()
+			svc.CompactBucketF = func(ctx context.Context, orgID, bucketID influxdb.ID) error {
+				if orgID != influxdb.ID(0x020f755c3c083000) || bucketID != influxdb.ID(0x020f755c3c082000) {
+					t.Errorf("compacted org %s bucket %s", orgID, bucketID)
+				}
+				compacted = true
+				return nil
+			}
+
+			h := NewCompactionHandler(zaptest.NewLogger(t), &CompactionBackend{
+				HTTPErrorHandler:  kithttp.ErrorHandler(0),
+				CompactionService: svc,
+				BucketService:     bucketSvc,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixCompaction+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("POST %s = %v, want %v: %s", prefixCompaction, res.StatusCode, tt.statusCode, body)
+			}
+			if compacted != tt.compacted {
+				t.Errorf("got compacted %v, want %v", compacted, tt.compacted)
+			}
+		})
+	}
+}
+
+func TestCompactionHandler_GetTombstones(t *testing.T) {
+	svc := mock.NewCompactionService()
+	svc.TombstoneStatsF = func(ctx context.Context) (*influxdb.TombstoneStats, error) {
+		return &influxdb.TombstoneStats{
+			Files: []*influxdb.FileTombstoneStats{{
+				Path:             "/data/000000002-000000001.tsm",
+				SizeBytes:        4096,
+				TombstoneBytes:   64,
+				TombstonedKeys:   2,
+				TombstonedBlocks: 3,
+				TombstonedBytes:  1024,
+			}},
+			TombstonedValuesRead: 1000,
+			TombstonedBlocksRead: 1,
+		}, nil
+	}
+
+	h := NewCompactionHandler(zaptest.NewLogger(t), &CompactionBackend{
+		HTTPErrorHandler:  kithttp.ErrorHandler(0),
+		CompactionService: svc,
+	})
+
+	r := httptest.NewRequest("GET", "http://any.tld"+compactionTombstonesPath, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s = %v, want %v: %s", compactionTombstonesPath, res.StatusCode, http.StatusOK, body)
+	}
+
+	exp := `{
+  "files": [{
+    "path": "/data/000000002-000000001.tsm",
+    "sizeBytes": 4096,
+    "tombstoneBytes": 64,
+    "tombstonedKeys": 2,
+    "tombstonedBlocks": 3,
+    "tombstonedBytes": 1024
+  }],
+  "tombstonedValuesRead": 1000,
+  "tombstonedBlocksRead": 1
+}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Fatalf("error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("GET %s = ***%s***", compactionTombstonesPath, diff)
+	}
+}
@@ -24,6 +24,7 @@ type DashboardBackend struct {
 	UserResourceMappingService   platform.UserResourceMappingService
 	LabelService                 platform.LabelService
 	UserService                  platform.UserService
+	RevisionService              platform.RevisionService
 }
 
 // NewDashboardBackend creates a backend used by the dashboard handler.
@@ -37,6 +38,7 @@ func NewDashboardBackend(log *zap.Logger, b *APIBackend) *DashboardBackend {
 		UserResourceMappingService:   b.UserResourceMappingService,
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
+		RevisionService:              b.RevisionService,
 	}
 }
 
@@ -52,6 +54,7 @@ type DashboardHandler struct {
 	UserResourceMappingService   platform.UserResourceMappingService
 	LabelService                 platform.LabelService
 	UserService                  platform.UserService
+	RevisionService              platform.RevisionService
 }
 
 const (
@@ -81,6 +84,7 @@ func NewDashboardHandler(log *zap.Logger, b *DashboardBackend) *DashboardHandler
 		UserResourceMappingService:   b.UserResourceMappingService,
 		LabelService:                 b.LabelService,
 		UserService:                  b.UserService,
+		RevisionService:              b.RevisionService,
 	}
 
 	h.HandlerFunc("POST", prefixDashboards, h.handlePostDashboard)
@@ -446,8 +450,14 @@ func (d getDashboardsResponse) toPlatform() []*platform.Dashboard {
 }
 
 func newGetDashboardsResponse(ctx context.Context, dashboards []*platform.Dashboard, filter platform.DashboardFilter, opts platform.FindOptions, labelService platform.LabelService) getDashboardsResponse {
+	ids := make([]platform.ID, 0, len(dashboards))
+	for _, dashboard := range dashboards {
+		if dashboard != nil {
+			ids = append(ids, dashboard.ID)
+		}
+	}
 	res := getDashboardsResponse{
-		Links:      newPagingLinks(prefixDashboards, opts, filter, len(dashboards)),
+		Links:      newPagingLinks(prefixDashboards, opts, filter, ids),
 		Dashboards: make([]dashboardResponse, 0, len(dashboards)),
 	}
 
@@ -490,6 +500,12 @@ func (h *DashboardHandler) handleGetDashboard(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	rev, hasRev, err := findRevision(ctx, h.RevisionService, req.DashboardID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
 	dashboard, err := h.DashboardService.FindDashboardByID(ctx, req.DashboardID)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
@@ -518,6 +534,10 @@ func (h *DashboardHandler) handleGetDashboard(w http.ResponseWriter, r *http.Req
 
 	h.log.Debug("Dashboard retrieved", zap.String("dashboard", fmt.Sprint(dashboard)))
 
+	if hasRev {
+		setETag(w, rev)
+	}
+
 	if err := encodeResponse(ctx, w, http.StatusOK, newDashboardResponse(dashboard, labels)); err != nil {
 		logEncodingError(h.log, r, err)
 		return
@@ -611,6 +631,7 @@ func (h *DashboardHandler) handleDeleteDashboard(w http.ResponseWriter, r *http.
 		return
 	}
 
+	ctx = decodeIfMatch(r).context(ctx, req.DashboardID)
 	if err := h.DashboardService.DeleteDashboard(ctx, req.DashboardID); err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
@@ -653,11 +674,13 @@ func (h *DashboardHandler) handlePatchDashboard(w http.ResponseWriter, r *http.R
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	dashboard, err := h.DashboardService.UpdateDashboard(ctx, req.DashboardID, req.Upd)
+	m := decodeIfMatch(r)
+	dashboard, err := h.DashboardService.UpdateDashboard(m.context(ctx, req.DashboardID), req.DashboardID, req.Upd)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	m.setETag(w)
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, platform.LabelMappingFilter{ResourceID: dashboard.ID})
 	if err != nil {
@@ -1784,6 +1784,62 @@ func TestDashboardService(t *testing.T) {
 	platformtesting.DeleteDashboard(initDashboardService, t)
 }
 
+func TestDashboardHandler_revisions(t *testing.T) {
+	svc := newInMemKVSVC(t)
+	ctx := context.Background()
+	d := &platform.Dashboard{Name: "dashboard1", OrganizationID: platform.ID(1)}
+	if err := svc.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	dashboardBackend := NewMockDashboardBackend(t)
+	dashboardBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	dashboardBackend.DashboardService = svc
+	dashboardBackend.LabelService = svc
+	dashboardBackend.RevisionService = svc
+	h := NewDashboardHandler(zaptest.NewLogger(t), dashboardBackend)
+
+	do := func(method, ifMatch, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "http://any.url/api/v2/dashboards/"+d.ID.String(), bytes.NewBufferString(body))
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do("GET", "", ""); w.Code != http.StatusOK {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+	} else if got, want := w.Header().Get("ETag"), `"0"`; got != want {
+		t.Fatalf("got ETag %s, expected %s", got, want)
+	}
+
+	// The first of two updates of the same revision is applied.
+	if w := do("PATCH", `"0"`, `{"name":"dashboard2"}`); w.Code != http.StatusOK {
+		t.Fatalf("got status %d, expected %d: %s", w.Code, http.StatusOK, w.Body)
+	} else if got, want := w.Header().Get("ETag"), `"1"`; got != want {
+		t.Fatalf("got ETag %s, expected %s", got, want)
+	}
+	if w := do("PATCH", `"0"`, `{"name":"dashboard3"}`); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusPreconditionFailed)
+	}
+	if w := do("DELETE", `"0"`, ""); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusPreconditionFailed)
+	}
+
+	// Updates without If-Match are applied whatever the revision.
+	if w := do("PATCH", "", `{"name":"dashboard3"}`); w.Code != http.StatusOK {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusOK)
+	}
+	if w := do("GET", "", ""); w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("got ETag %s, expected %s", w.Header().Get("ETag"), `"2"`)
+	}
+	if w := do("DELETE", `"1", "2"`, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("got status %d, expected %d", w.Code, http.StatusNoContent)
+	}
+}
+
 func TestService_handlePostDashboardLabel(t *testing.T) {
 	type fields struct {
 		LabelService platform.LabelService
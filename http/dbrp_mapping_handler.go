@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	http "net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixDBRPs = "/api/v2/dbrps"
+)
+
+// DBRPMappingBackend is all services and associated parameters required to
+// construct the DBRPMappingHandler.
+type DBRPMappingBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	DBRPMappingService influxdb.DBRPMappingService
+	BucketService      influxdb.BucketService
+}
+
+// NewDBRPMappingBackend returns a new instance of DBRPMappingBackend.
+func NewDBRPMappingBackend(log *zap.Logger, b *APIBackend) *DBRPMappingBackend {
+	return &DBRPMappingBackend{
+		log: log,
+
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		DBRPMappingService: b.DBRPMappingService,
+		BucketService:      b.BucketService,
+	}
+}
+
+// DBRPMappingHandler manages the mappings of 1.x databases and retention
+// policies to buckets.
+type DBRPMappingHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	DBRPMappingService influxdb.DBRPMappingService
+	BucketService      influxdb.BucketService
+}
+
+// NewDBRPMappingHandler creates a new handler at /api/v2/dbrps to manage dbrp
+// mappings.
+func NewDBRPMappingHandler(log *zap.Logger, b *DBRPMappingBackend) *DBRPMappingHandler {
+	h := &DBRPMappingHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		DBRPMappingService: b.DBRPMappingService,
+		BucketService:      b.BucketService,
+	}
+
+	h.HandlerFunc("GET", prefixDBRPs, h.handleGetDBRPMappings)
+	h.HandlerFunc("POST", prefixDBRPs, h.handlePostDBRPMapping)
+	h.HandlerFunc("DELETE", prefixDBRPs, h.handleDeleteDBRPMapping)
+	return h
+}
+
+type dbrpMappingsResponse struct {
+	Mappings []*influxdb.DBRPMapping `json:"dbrps"`
+}
+
+// handleGetDBRPMappings is the HTTP handler for the GET /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handleGetDBRPMappings(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DBRPMappingHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeDBRPMappingFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ms, _, err := h.DBRPMappingService.FindMany(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if ms == nil {
+		ms = []*influxdb.DBRPMapping{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, dbrpMappingsResponse{Mappings: ms}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodeDBRPMappingFilter decodes the cluster, db, rp and default query
+// parameters of a request.
+func decodeDBRPMappingFilter(r *http.Request) (influxdb.DBRPMappingFilter, error) {
+	var filter influxdb.DBRPMappingFilter
+	qp := r.URL.Query()
+	if cluster := qp.Get("cluster"); cluster != "" {
+		filter.Cluster = &cluster
+	}
+	if db := qp.Get("db"); db != "" {
+		filter.Database = &db
+	}
+	if rp := qp.Get("rp"); rp != "" {
+		filter.RetentionPolicy = &rp
+	}
+	if s := qp.Get("default"); s != "" {
+		def, err := strconv.ParseBool(s)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "default must be true or false",
+				Err:  err,
+			}
+		}
+		filter.Default = &def
+	}
+	return filter, nil
+}
+
+// handlePostDBRPMapping is the HTTP handler for the POST /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handlePostDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DBRPMappingHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	m, err := h.decodePostDBRPMappingRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.DBRPMappingService.Create(ctx, m); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("DBRP mapping created", zap.String("dbrp", m.Database+"/"+m.RetentionPolicy))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodePostDBRPMappingRequest decodes a mapping, whose organization is the
+// organization of its bucket if it is not given.
+func (h *DBRPMappingHandler) decodePostDBRPMappingRequest(ctx context.Context, r *http.Request) (*influxdb.DBRPMapping, error) {
+	m := &influxdb.DBRPMapping{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid request; error parsing request json",
+			Err:  err,
+		}
+	}
+	if !m.BucketID.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucketID is required",
+		}
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, m.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.OrganizationID.Valid() {
+		m.OrganizationID = b.OrgID
+	} else if b.OrgID != m.OrganizationID {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucket " + b.ID.String() + " does not belong to the organization",
+		}
+	}
+	return m, nil
+}
+
+// handleDeleteDBRPMapping is the HTTP handler for the DELETE /api/v2/dbrps
+// route.
+func (h *DBRPMappingHandler) handleDeleteDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DBRPMappingHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeDBRPMappingFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if filter.Cluster == nil || filter.Database == nil || filter.RetentionPolicy == nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "cluster, db and rp are required",
+		}, w)
+		return
+	}
+
+	if err := h.DBRPMappingService.Delete(ctx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDBRPMappingHandler_handlePostDBRPMapping(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		wants wants
+	}{
+		{
+			name: "map to bucket of its org",
+			body: `{"cluster": "c", "database": "telegraf", "retention_policy": "autogen", "default": true, "bucket_id": "020f755c3c082001"}`,
+			wants: wants{
+				statusCode: http.StatusCreated,
+				body: `{
+					"cluster": "c",
+					"database": "telegraf",
+					"retention_policy": "autogen",
+					"default": true,
+					"organization_id": "020f755c3c082000",
+					"bucket_id": "020f755c3c082001"
+				}`,
+			},
+		},
+		{
+			name: "missing bucket",
+			body: `{"cluster": "c", "database": "telegraf", "retention_policy": "autogen"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "bucketID is required"}`,
+			},
+		},
+		{
+			name: "bucket of another org",
+			body: `{"cluster": "c", "database": "telegraf", "retention_policy": "autogen", "organization_id": "020f755c3c082000", "bucket_id": "020f755c3c082003"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "bucket 020f755c3c082003 does not belong to the organization"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := mock.NewBucketService()
+			buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				orgID := influxdb.ID(0x020f755c3c082000)
+				if id == influxdb.ID(0x020f755c3c082003) {
+					orgID++
+				}
+				return &influxdb.Bucket{ID: id, OrgID: orgID}, nil
+			}
+
+			h := NewDBRPMappingHandler(zaptest.NewLogger(t), &DBRPMappingBackend{
+				HTTPErrorHandler:   kithttp.ErrorHandler(0),
+				DBRPMappingService: mock.NewDBRPMappingService(),
+				BucketService:      buckets,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixDBRPs, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handlePostDBRPMapping() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+				t.Errorf("handlePostDBRPMapping(). error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("handlePostDBRPMapping() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestDBRPMappingHandler_handleDeleteDBRPMapping(t *testing.T) {
+	var deleted string
+	svc := mock.NewDBRPMappingService()
+	svc.DeleteFn = func(ctx context.Context, cluster, db, rp string) error {
+		deleted = cluster + "/" + db + "/" + rp
+		return nil
+	}
+
+	h := NewDBRPMappingHandler(zaptest.NewLogger(t), &DBRPMappingBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		DBRPMappingService: svc,
+	})
+
+	tests := []struct {
+		query      string
+		statusCode int
+		deleted    string
+	}{
+		{query: "?cluster=c&db=telegraf&rp=autogen", statusCode: http.StatusNoContent, deleted: "c/telegraf/autogen"},
+		{query: "?cluster=c&db=telegraf", statusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		deleted = ""
+		r := httptest.NewRequest("DELETE", "http://any.tld"+prefixDBRPs+tt.query, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if got := w.Result().StatusCode; got != tt.statusCode {
+			t.Errorf("handleDeleteDBRPMapping(%s) = %v, want %v", tt.query, got, tt.statusCode)
+		}
+		if deleted != tt.deleted {
+			t.Errorf("handleDeleteDBRPMapping(%s) deleted %q, want %q", tt.query, deleted, tt.deleted)
+		}
+	}
+}
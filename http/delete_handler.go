@@ -105,7 +105,9 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !a.Allowed(*p) {
+	// Deletes are not limited to the series permissions may be restricted
+	// to.
+	if !a.Allowed(*p) || influxdb.AuthorizerSeriesScope(a, *p) != nil {
 		h.HandleHTTPError(ctx, &influxdb.Error{
 			Code: influxdb.EForbidden,
 			Op:   "http/handleDelete",
@@ -208,6 +210,13 @@ func (dr *deleteRequest) UnmarshalJSON(b []byte) error {
 		}
 	}
 	dr.Stop = stop.UnixNano()
+	if dr.Start > dr.Stop {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   "http/Delete",
+			Msg:  "start must not be after stop",
+		}
+	}
 	node, err := predicate.Parse(drd.Predicate)
 	if err != nil {
 		return err
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"fmt"
+	http "net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixDrain = "/api/v2/drain"
+
+	// DefaultDrainTimeout is how long a drain requested over the API waits
+	// for in-flight requests if no timeout is given.
+	DefaultDrainTimeout = 30 * time.Second
+)
+
+// DrainGate admits writes and queries while the server is not draining.
+type DrainGate interface {
+	// AdmitWrite and AdmitQuery record the start of a request, returning a
+	// function to call once it completes, or an error if it is refused.
+	AdmitWrite() (done func(), err error)
+	AdmitQuery() (done func(), err error)
+}
+
+// DrainBackend is all services and associated parameters required to
+// construct the DrainHandler.
+type DrainBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	DrainService influxdb.DrainService
+}
+
+// NewDrainBackend returns a new instance of DrainBackend.
+func NewDrainBackend(log *zap.Logger, b *APIBackend) *DrainBackend {
+	return &DrainBackend{
+		log: log,
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		DrainService:     b.DrainService,
+	}
+}
+
+// DrainHandler starts, reports on and resumes from drains of the server.
+type DrainHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	DrainService influxdb.DrainService
+}
+
+// NewDrainHandler creates a new handler at /api/v2/drain to manage draining
+// the server.
+func NewDrainHandler(log *zap.Logger, b *DrainBackend) *DrainHandler {
+	h := &DrainHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		DrainService: b.DrainService,
+	}
+
+	h.HandlerFunc("GET", prefixDrain, h.handleGetDrain)
+	h.HandlerFunc("POST", prefixDrain, h.handlePostDrain)
+	h.HandlerFunc("DELETE", prefixDrain, h.handleDeleteDrain)
+	return h
+}
+
+func (h *DrainHandler) handleGetDrain(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DrainHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	status, err := h.DrainService.DrainStatus(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, status); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *DrainHandler) handlePostDrain(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DrainHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	timeout := DefaultDrainTimeout
+	if s := r.URL.Query().Get("timeout"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("timeout must be a non-negative duration, got %q", s),
+			}, w)
+			return
+		}
+		timeout = d
+	}
+
+	status, err := h.DrainService.Drain(ctx, timeout)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Drain started", zap.String("state", string(status.State)))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, status); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *DrainHandler) handleDeleteDrain(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DrainHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	status, err := h.DrainService.Resume(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, status); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// drainGateHandler refuses writes and queries served by next while the server
+// is draining, and tracks those that are admitted.
+type drainGateHandler struct {
+	influxdb.HTTPErrorHandler
+	admit func(r *http.Request) (func(), error)
+	next  http.Handler
+}
+
+// newDrainWriteGate returns a handler that admits every request to next as a
+// write.
+func newDrainWriteGate(errorHandler influxdb.HTTPErrorHandler, gate DrainGate, next http.Handler) http.Handler {
+	return &drainGateHandler{
+		HTTPErrorHandler: errorHandler,
+		next:             next,
+		admit: func(r *http.Request) (func(), error) {
+			return gate.AdmitWrite()
+		},
+	}
+}
+
+// newDrainQueryGate returns a handler that admits requests to execute a query
+// on next. Other requests, such as those to analyze a query, are not gated.
+func newDrainQueryGate(errorHandler influxdb.HTTPErrorHandler, gate DrainGate, next http.Handler) http.Handler {
+	return &drainGateHandler{
+		HTTPErrorHandler: errorHandler,
+		next:             next,
+		admit: func(r *http.Request) (func(), error) {
+			if r.URL.Path == prefixLegacyQuery {
+				// 1.x clients also query with GET requests.
+				return gate.AdmitQuery()
+			}
+			if r.Method != http.MethodPost || (r.URL.Path != prefixQuery && r.URL.Path != prefixProfileQuery && r.URL.Path != grafanaAnnotationsPath) {
+				return func() {}, nil
+			}
+			return gate.AdmitQuery()
+		},
+	}
+}
+
+func (h *drainGateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	done, err := h.admit(r)
+	if err != nil {
+		h.HandleHTTPError(r.Context(), err, w)
+		return
+	}
+	defer done()
+
+	h.next.ServeHTTP(w, r)
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDrainHandler(t *testing.T) {
+	type wants struct {
+		statusCode int
+		timeout    time.Duration
+		body       string
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		wants  wants
+	}{
+		{
+			name:   "get status",
+			method: "GET",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"state": "active", "inFlightWrites": 0, "inFlightQueries": 0, "checkpointed": false}`,
+			},
+		},
+		{
+			name:   "drain with default timeout",
+			method: "POST",
+			wants: wants{
+				statusCode: http.StatusAccepted,
+				timeout:    DefaultDrainTimeout,
+				body:       `{"state": "draining", "inFlightWrites": 2, "inFlightQueries": 0, "checkpointed": false}`,
+			},
+		},
+		{
+			name:   "drain with timeout",
+			method: "POST",
+			query:  "?timeout=5s",
+			wants: wants{
+				statusCode: http.StatusAccepted,
+				timeout:    5 * time.Second,
+			},
+		},
+		{
+			name:   "drain with invalid timeout",
+			method: "POST",
+			query:  "?timeout=soon",
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "timeout must be a non-negative duration, got \"soon\""}`,
+			},
+		},
+		{
+			name:   "resume",
+			method: "DELETE",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body:       `{"state": "active", "inFlightWrites": 0, "inFlightQueries": 0, "checkpointed": false}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTimeout time.Duration
+			svc := mock.NewDrainService()
+			svc.DrainF = func(ctx context.Context, timeout time.Duration) (*influxdb.DrainStatus, error) {
+				gotTimeout = timeout
+				return &influxdb.DrainStatus{State: influxdb.DrainStateDraining, InFlightWrites: 2}, nil
+			}
+
+			h := NewDrainHandler(zaptest.NewLogger(t), &DrainBackend{
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				DrainService:     svc,
+			})
+
+			r := httptest.NewRequest(tt.method, "http://any.tld"+prefixDrain+tt.query, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("%s %s = %v, want %v: %s", tt.method, prefixDrain, res.StatusCode, tt.wants.statusCode, body)
+			}
+			if gotTimeout != tt.wants.timeout {
+				t.Errorf("got timeout %v, want %v", gotTimeout, tt.wants.timeout)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("%s %s. error unmarshaling json %v", tt.method, prefixDrain, err)
+				} else if !eq {
+					t.Errorf("%s %s = ***%s***", tt.method, prefixDrain, diff)
+				}
+			}
+		})
+	}
+}
+
+type drainGate struct {
+	err                error
+	writes, writesDone int
+	queries            int
+}
+
+func (g *drainGate) AdmitWrite() (func(), error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.writes++
+	return func() { g.writesDone++ }, nil
+}
+
+func (g *drainGate) AdmitQuery() (func(), error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	g.queries++
+	return func() {}, nil
+}
+
+func TestDrainGate(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("admits requests while active", func(t *testing.T) {
+		gate := &drainGate{}
+		writes := newDrainWriteGate(kithttp.ErrorHandler(0), gate, next)
+		queries := newDrainQueryGate(kithttp.ErrorHandler(0), gate, next)
+
+		w := httptest.NewRecorder()
+		writes.ServeHTTP(w, httptest.NewRequest("POST", "http://any.tld"+prefixWrite, nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("write = %v, want %v", w.Code, http.StatusNoContent)
+		}
+		if gate.writes != 1 || gate.writesDone != 1 {
+			t.Errorf("expected write to be admitted and completed, got %d admitted and %d completed", gate.writes, gate.writesDone)
+		}
+
+		w = httptest.NewRecorder()
+		queries.ServeHTTP(w, httptest.NewRequest("POST", "http://any.tld"+prefixQuery, nil))
+		queries.ServeHTTP(w, httptest.NewRequest("POST", "http://any.tld"+prefixQuery+"/analyze", nil))
+		if gate.queries != 1 {
+			t.Errorf("expected only query execution to be admitted, got %d", gate.queries)
+		}
+	})
+
+	t.Run("refuses requests while draining", func(t *testing.T) {
+		gate := &drainGate{err: &influxdb.Error{Code: influxdb.EUnavailable, Msg: "draining"}}
+		writes := newDrainWriteGate(kithttp.ErrorHandler(0), gate, next)
+		queries := newDrainQueryGate(kithttp.ErrorHandler(0), gate, next)
+
+		w := httptest.NewRecorder()
+		writes.ServeHTTP(w, httptest.NewRequest("POST", "http://any.tld"+prefixWrite, nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("write = %v, want %v", w.Code, http.StatusServiceUnavailable)
+		}
+
+		w = httptest.NewRecorder()
+		queries.ServeHTTP(w, httptest.NewRequest("POST", "http://any.tld"+prefixQuery, nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("query = %v, want %v", w.Code, http.StatusServiceUnavailable)
+		}
+
+		w = httptest.NewRecorder()
+		queries.ServeHTTP(w, httptest.NewRequest("GET", "http://any.tld"+prefixQuery+"/suggestions", nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("suggestions = %v, want %v", w.Code, http.StatusNoContent)
+		}
+	})
+}
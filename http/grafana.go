@@ -0,0 +1,413 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	http "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/jsonweb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixGrafana            = "/api/v2/grafana"
+	grafanaAnnotationsPath   = prefixGrafana + "/annotations"
+	grafanaDefaultAnnotation = "annotation"
+)
+
+// GrafanaBackend is all services and associated parameters required to
+// construct the GrafanaHandler.
+type GrafanaBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	FluxService         query.ProxyQueryService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewGrafanaBackend returns a new instance of GrafanaBackend.
+func NewGrafanaBackend(log *zap.Logger, b *APIBackend) *GrafanaBackend {
+	return &GrafanaBackend{
+		log: log,
+
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		FluxService:         b.FluxService,
+		OrganizationService: b.OrganizationService,
+	}
+}
+
+// GrafanaHandler implements the endpoints Grafana's JSON datasource calls, so
+// the server can be added to Grafana without a proxy. The datasource URL is
+// /api/v2/grafana, with the token sent in the Authorization header.
+type GrafanaHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	FluxService         query.ProxyQueryService
+	OrganizationService influxdb.OrganizationService
+}
+
+// NewGrafanaHandler creates a new handler at /api/v2/grafana.
+func NewGrafanaHandler(log *zap.Logger, b *GrafanaBackend) *GrafanaHandler {
+	h := &GrafanaHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		FluxService:         b.FluxService,
+		OrganizationService: b.OrganizationService,
+	}
+
+	h.HandlerFunc("GET", prefixGrafana, h.handleGetHealth)
+	h.HandlerFunc("GET", prefixGrafana+"/", h.handleGetHealth)
+	h.HandlerFunc("POST", grafanaAnnotationsPath, h.handlePostAnnotations)
+	return h
+}
+
+// handleGetHealth answers the datasource test Grafana runs when the
+// datasource is saved. Grafana treats any status other than 200 as a failure,
+// so the request fails when the token is invalid or queries can't be run.
+func (h *GrafanaHandler) handleGetHealth(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "GrafanaHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	res := h.FluxService.Check(ctx)
+	code := http.StatusOK
+	if res.Status != check.StatusPass {
+		code = http.StatusServiceUnavailable
+	} else if res.Message == "" {
+		res.Message = "Data source is working"
+	}
+
+	if err := encodeResponse(ctx, w, code, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// grafanaAnnotationRequest is the body Grafana sends to query annotations.
+type grafanaAnnotationRequest struct {
+	Range struct {
+		From time.Time `json:"from"`
+		To   time.Time `json:"to"`
+	} `json:"range"`
+
+	// Annotation is returned as is with each annotation.
+	Annotation json.RawMessage `json:"annotation"`
+}
+
+type grafanaAnnotationQuery struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// grafanaAnnotation is a single annotation returned to Grafana. Times are in
+// milliseconds since the epoch.
+type grafanaAnnotation struct {
+	Annotation json.RawMessage `json:"annotation"`
+	Time       int64           `json:"time"`
+	TimeEnd    int64           `json:"timeEnd,omitempty"`
+	Title      string          `json:"title"`
+	Text       string          `json:"text"`
+	Tags       []string        `json:"tags"`
+}
+
+// handlePostAnnotations runs the Flux query of an annotation over the time
+// range of the dashboard and returns a Grafana annotation for each row.
+//
+// The range is available to the query as v.timeRangeStart and
+// v.timeRangeStop. Each row needs a _time column, which Grafana shows the
+// annotation at, and may have a timeEnd column for a region. The text is
+// taken from a text, _message or _value column, and the title from a title
+// column or else the name of the annotation. Tags are taken from a
+// comma-separated tags column, or else from the group key of the table.
+//
+// The query is run in the organization given by the orgID or org query
+// parameter, or else the organization of the token.
+func (h *GrafanaHandler) handlePostAnnotations(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "GrafanaHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	req, q, err := decodeGrafanaAnnotationRequest(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	preq, err := h.annotationQueryRequest(ctx, r, req, q)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ctx = pcontext.SetAuthorizer(ctx, preq.Authorization)
+	results, err := query.QueryServiceProxyBridge{ProxyQueryService: h.FluxService}.Query(ctx, preq)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	defer results.Release()
+
+	annotations := []grafanaAnnotation{}
+	for results.More() {
+		err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			a, err := grafanaAnnotationsFromTable(tbl, req.Annotation, q.Name)
+			annotations = append(annotations, a...)
+			return err
+		})
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+	if err := results.Err(); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, annotations); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeGrafanaAnnotationRequest(r *http.Request) (*grafanaAnnotationRequest, *grafanaAnnotationQuery, error) {
+	req := &grafanaAnnotationRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}
+	}
+
+	q := &grafanaAnnotationQuery{}
+	if len(req.Annotation) > 0 {
+		if err := json.Unmarshal(req.Annotation, q); err != nil {
+			return nil, nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid annotation",
+				Err:  err,
+			}
+		}
+	}
+	if strings.TrimSpace(q.Query) == "" {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "annotation query must be provided",
+		}
+	}
+	if q.Name == "" {
+		q.Name = grafanaDefaultAnnotation
+	}
+	if req.Range.To.Before(req.Range.From) {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "range must not end before it starts",
+		}
+	}
+	return req, q, nil
+}
+
+// annotationQueryRequest returns the request to run the annotation query with
+// the authorization of the caller.
+func (h *GrafanaHandler) annotationQueryRequest(ctx context.Context, r *http.Request, req *grafanaAnnotationRequest, q *grafanaAnnotationQuery) (*query.Request, error) {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the annotation request",
+			Err:  err,
+		}
+	}
+
+	var orgID influxdb.ID
+	if r.URL.Query().Get(Org) != "" || r.URL.Query().Get(OrgID) != "" {
+		o, err := queryOrganization(ctx, r, h.OrganizationService)
+		if err != nil {
+			return nil, err
+		}
+		orgID = o.ID
+	} else if auth, ok := a.(*influxdb.Authorization); ok {
+		orgID = auth.OrgID
+	} else {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "Please provide either orgID or org",
+		}
+	}
+
+	var token *influxdb.Authorization
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		token = a
+	case *influxdb.Session:
+		token = a.EphemeralAuth(orgID)
+	case *jsonweb.Token:
+		token = a.EphemeralAuth(orgID)
+	default:
+		return nil, influxdb.ErrAuthorizerNotSupported
+	}
+
+	return &query.Request{
+		Authorization:  token,
+		OrganizationID: orgID,
+		Compiler: lang.FluxCompiler{
+			Now:    time.Now(),
+			Extern: grafanaTimeRangeExtern(req.Range.From, req.Range.To),
+			Query:  q.Query,
+		},
+		Source: r.Header.Get("User-Agent"),
+	}, nil
+}
+
+// grafanaTimeRangeExtern defines option v = {timeRangeStart, timeRangeStop},
+// the variables dashboard queries use for their time range.
+func grafanaTimeRangeExtern(from, to time.Time) *ast.File {
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.OptionStatement{
+				Assignment: &ast.VariableAssignment{
+					ID: &ast.Identifier{Name: "v"},
+					Init: &ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key:   &ast.Identifier{Name: "timeRangeStart"},
+								Value: &ast.DateTimeLiteral{Value: from},
+							},
+							{
+								Key:   &ast.Identifier{Name: "timeRangeStop"},
+								Value: &ast.DateTimeLiteral{Value: to},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// grafanaAnnotationsFromTable returns an annotation for each row of tbl. Rows
+// of tables without a _time column are skipped.
+func grafanaAnnotationsFromTable(tbl flux.Table, annotation json.RawMessage, name string) ([]grafanaAnnotation, error) {
+	cols := tbl.Cols()
+	timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, cols)
+	if timeIdx < 0 || cols[timeIdx].Type != flux.TTime {
+		return nil, tbl.Do(func(flux.ColReader) error { return nil })
+	}
+	timeEndIdx := execute.ColIdx("timeEnd", cols)
+	titleIdx := execute.ColIdx("title", cols)
+	tagsIdx := execute.ColIdx("tags", cols)
+	textIdx := execute.ColIdx("text", cols)
+	if textIdx < 0 {
+		textIdx = execute.ColIdx("_message", cols)
+	}
+	if textIdx < 0 {
+		textIdx = execute.ColIdx(execute.DefaultValueColLabel, cols)
+	}
+
+	// Unless there is a tags column, the tags are the group key of the
+	// table, without the bounds of the time range.
+	var keyTags []string
+	key := tbl.Key()
+	for j, c := range key.Cols() {
+		if c.Label == execute.DefaultStartColLabel || c.Label == execute.DefaultStopColLabel || key.IsNull(j) {
+			continue
+		}
+		keyTags = append(keyTags, c.Label+"="+key.ValueString(j))
+	}
+
+	var annotations []grafanaAnnotation
+	err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			if cr.Times(timeIdx).IsNull(i) {
+				continue
+			}
+
+			a := grafanaAnnotation{
+				Annotation: annotation,
+				Time:       cr.Times(timeIdx).Value(i) / int64(time.Millisecond),
+				Title:      name,
+				Tags:       keyTags,
+			}
+			if a.Tags == nil {
+				a.Tags = []string{}
+			}
+			if timeEndIdx >= 0 && cols[timeEndIdx].Type == flux.TTime && !cr.Times(timeEndIdx).IsNull(i) {
+				a.TimeEnd = cr.Times(timeEndIdx).Value(i) / int64(time.Millisecond)
+			}
+			if s, ok := grafanaColumnString(cr, i, titleIdx); ok && s != "" {
+				a.Title = s
+			}
+			if s, ok := grafanaColumnString(cr, i, textIdx); ok {
+				a.Text = s
+			}
+			if s, ok := grafanaColumnString(cr, i, tagsIdx); ok {
+				a.Tags = []string{}
+				for _, t := range strings.Split(s, ",") {
+					if t = strings.TrimSpace(t); t != "" {
+						a.Tags = append(a.Tags, t)
+					}
+				}
+			}
+			annotations = append(annotations, a)
+		}
+		return nil
+	})
+	return annotations, err
+}
+
+// grafanaColumnString formats the value of column j in row i. It returns
+// false if there is no such column or the value is null.
+func grafanaColumnString(cr flux.ColReader, i, j int) (string, bool) {
+	if j < 0 {
+		return "", false
+	}
+
+	switch cr.Cols()[j].Type {
+	case flux.TString:
+		if vs := cr.Strings(j); !vs.IsNull(i) {
+			return vs.ValueString(i), true
+		}
+	case flux.TFloat:
+		if vs := cr.Floats(j); !vs.IsNull(i) {
+			return strconv.FormatFloat(vs.Value(i), 'f', -1, 64), true
+		}
+	case flux.TInt:
+		if vs := cr.Ints(j); !vs.IsNull(i) {
+			return strconv.FormatInt(vs.Value(i), 10), true
+		}
+	case flux.TUInt:
+		if vs := cr.UInts(j); !vs.IsNull(i) {
+			return strconv.FormatUint(vs.Value(i), 10), true
+		}
+	case flux.TBool:
+		if vs := cr.Bools(j); !vs.IsNull(i) {
+			return strconv.FormatBool(vs.Value(i)), true
+		}
+	case flux.TTime:
+		if vs := cr.Times(j); !vs.IsNull(i) {
+			return time.Unix(0, vs.Value(i)).UTC().Format(time.RFC3339Nano), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestGrafanaHandler_Health(t *testing.T) {
+	h := NewGrafanaHandler(zaptest.NewLogger(t), &GrafanaBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		FluxService:      &querymock.ProxyQueryService{},
+	})
+
+	for _, path := range []string{prefixGrafana, prefixGrafana + "/"} {
+		r := httptest.NewRequest("GET", "http://any.tld"+path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		body, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("GET %s = %v, want %v: %s", path, res.StatusCode, http.StatusOK, body)
+		}
+		if eq, diff, err := jsonEqual(string(body), `{"name": "Mock Proxy Query Service", "status": "pass", "message": "Data source is working"}`); err != nil {
+			t.Errorf("GET %s. error unmarshaling json %v", path, err)
+		} else if !eq {
+			t.Errorf("GET %s = ***%s***", path, diff)
+		}
+	}
+}
+
+func TestGrafanaHandler_PostAnnotations(t *testing.T) {
+	const results = `#datatype,string,long,dateTime:RFC3339,dateTime:RFC3339,dateTime:RFC3339,string,string,string
+#group,false,false,true,true,false,false,true,true
+#default,_result,,,,,,,
+,result,table,_start,_stop,_time,_message,_measurement,host
+,,0,2019-01-01T00:00:00Z,2019-01-02T00:00:00Z,2019-01-01T10:00:00Z,deployed v1,deploys,a
+,,0,2019-01-01T00:00:00Z,2019-01-02T00:00:00Z,2019-01-01T12:00:00Z,deployed v2,deploys,a
+
+#datatype,string,long,dateTime:RFC3339,string,string
+#group,false,false,false,false,false
+#default,_result,,,,
+,result,table,_time,text,tags
+,,1,2019-01-01T11:00:00Z,outage,"incident, sev1"
+
+`
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name:       "annotations from rows",
+			body:       `{"range": {"from": "2019-01-01T00:00:00Z", "to": "2019-01-02T00:00:00Z"}, "annotation": {"name": "deploys", "query": "from(bucket: \"events\")"}}`,
+			statusCode: http.StatusOK,
+			wantBody: `[
+  {"annotation": {"name": "deploys", "query": "from(bucket: \"events\")"}, "time": 1546336800000, "title": "deploys", "text": "deployed v1", "tags": ["_measurement=deploys", "host=a"]},
+  {"annotation": {"name": "deploys", "query": "from(bucket: \"events\")"}, "time": 1546344000000, "title": "deploys", "text": "deployed v2", "tags": ["_measurement=deploys", "host=a"]},
+  {"annotation": {"name": "deploys", "query": "from(bucket: \"events\")"}, "time": 1546340400000, "title": "deploys", "text": "outage", "tags": ["incident", "sev1"]}
+]`,
+		},
+		{
+			name:       "organization from query parameter",
+			query:      "?orgID=020f755c3c082001",
+			body:       `{"range": {"from": "2019-01-01T00:00:00Z", "to": "2019-01-02T00:00:00Z"}, "annotation": {"query": "from(bucket: \"events\")"}}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "missing query",
+			body:       `{"range": {"from": "2019-01-01T00:00:00Z", "to": "2019-01-02T00:00:00Z"}, "annotation": {"name": "deploys"}}`,
+			statusCode: http.StatusBadRequest,
+			wantBody:   `{"code": "invalid", "message": "annotation query must be provided"}`,
+		},
+		{
+			name:       "invalid range",
+			body:       `{"range": {"from": "2019-01-02T00:00:00Z", "to": "2019-01-01T00:00:00Z"}, "annotation": {"query": "from(bucket: \"events\")"}}`,
+			statusCode: http.StatusBadRequest,
+			wantBody:   `{"code": "invalid", "message": "range must not end before it starts"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := influxdb.ID(0x020f755c3c082000)
+			if tt.query != "" {
+				orgID = influxdb.ID(0x020f755c3c082001)
+			}
+
+			fluxService := &querymock.ProxyQueryService{
+				QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+					if req.Request.OrganizationID != orgID {
+						t.Errorf("got organization %s, want %s", req.Request.OrganizationID, orgID)
+					}
+					if c, ok := req.Request.Compiler.(lang.FluxCompiler); !ok || c.Extern == nil {
+						t.Errorf("expected flux compiler with time range, got %#v", req.Request.Compiler)
+					}
+					_, err := io.WriteString(w, results)
+					return flux.Statistics{}, err
+				},
+			}
+			orgService := mock.NewOrganizationService()
+			orgService.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+				return &influxdb.Organization{ID: *filter.ID}, nil
+			}
+
+			h := NewGrafanaHandler(zaptest.NewLogger(t), &GrafanaBackend{
+				HTTPErrorHandler:    kithttp.ErrorHandler(0),
+				FluxService:         fluxService,
+				OrganizationService: orgService,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+grafanaAnnotationsPath+tt.query, strings.NewReader(tt.body))
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{
+				ID:    influxdb.ID(0x020f755c3c083000),
+				OrgID: influxdb.ID(0x020f755c3c082000),
+			}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("POST %s = %v, want %v: %s", grafanaAnnotationsPath, res.StatusCode, tt.statusCode, body)
+			}
+			if tt.wantBody != "" {
+				// Wrap the bodies, as jsonEqual compares objects.
+				got, want := `{"body": `+string(body)+`}`, `{"body": `+tt.wantBody+`}`
+				if eq, diff, err := jsonEqual(got, want); err != nil {
+					t.Errorf("POST %s. error unmarshaling json %v", grafanaAnnotationsPath, err)
+				} else if !eq {
+					t.Errorf("POST %s = ***%s***", grafanaAnnotationsPath, diff)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package http
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/influxdata/influxdb/kit/check"
 )
 
 // HealthHandler returns the status of the process.
@@ -12,3 +15,35 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintln(w, msg)
 }
+
+var healthMessages = map[check.Status]string{
+	check.StatusPass: "ready for queries and writes",
+	check.StatusWarn: "degraded",
+	check.StatusFail: "unhealthy",
+}
+
+// NewHealthHandler returns a handler reporting the status of the process
+// along with the status of each of its subsystems. The process is degraded
+// if any check warns, and unhealthy, with a 503, if any check fails.
+func NewHealthHandler(checks ...check.Checker) http.Handler {
+	c := check.NewCheck()
+	for _, ch := range checks {
+		c.AddHealthCheck(ch)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := c.CheckHealth(r.Context())
+		resp.Name = "influxdb"
+		resp.Message = healthMessages[resp.Status]
+
+		code := http.StatusOK
+		if resp.Status == check.StatusFail {
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			fmt.Fprintf(w, "Error encoding health data: %v\n", err)
+		}
+	})
+}
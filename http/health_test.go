@@ -1,10 +1,13 @@
 package http
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/influxdata/influxdb/kit/check"
 )
 
 func TestHealthHandler(t *testing.T) {
@@ -53,3 +56,58 @@ func TestHealthHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestNewHealthHandler(t *testing.T) {
+	warn := check.NamedFunc("b", func(context.Context) check.Response {
+		return check.Warn("queue-full", "queue is full")
+	})
+	fail := check.NamedFunc("c", func(context.Context) check.Response {
+		return check.Fail("shutdown", "shut down")
+	})
+
+	tests := []struct {
+		name       string
+		checks     []check.Checker
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "passing checks",
+			checks:     []check.Checker{check.NamedFunc("a", func(context.Context) check.Response { return check.Pass() })},
+			statusCode: http.StatusOK,
+			body:       `{"name":"influxdb", "message":"ready for queries and writes", "status":"pass", "checks":[{"name":"a","status":"pass"}]}`,
+		},
+		{
+			name:       "warning check is degraded",
+			checks:     []check.Checker{warn},
+			statusCode: http.StatusOK,
+			body:       `{"name":"influxdb", "message":"degraded", "status":"warn", "checks":[{"name":"b","status":"warn","reason":"queue-full","message":"queue is full"}]}`,
+		},
+		{
+			name:       "failing check is unhealthy",
+			checks:     []check.Checker{warn, fail},
+			statusCode: http.StatusServiceUnavailable,
+			body: `{"name":"influxdb", "message":"unhealthy", "status":"fail", "checks":[
+				{"name":"c","status":"fail","reason":"shutdown","message":"shut down"},
+				{"name":"b","status":"warn","reason":"queue-full","message":"queue is full"}
+			]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewHealthHandler(tt.checks...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("got status code %v, want %v", res.StatusCode, tt.statusCode)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.body); err != nil {
+				t.Errorf("error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("unexpected body ***%s***", diff)
+			}
+		})
+	}
+}
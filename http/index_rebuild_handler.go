@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixIndexRebuilds = "/api/v2/index/rebuilds"
+	indexRebuildsIDPath = "/api/v2/index/rebuilds/:id"
+)
+
+// IndexRebuildBackend is all services and associated parameters required to
+// construct the IndexRebuildHandler.
+type IndexRebuildBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	IndexRebuildService influxdb.IndexRebuildService
+	BucketService       influxdb.BucketService
+}
+
+// NewIndexRebuildBackend returns a new instance of IndexRebuildBackend.
+func NewIndexRebuildBackend(log *zap.Logger, b *APIBackend) *IndexRebuildBackend {
+	return &IndexRebuildBackend{
+		log: log,
+
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		IndexRebuildService: b.IndexRebuildService,
+		BucketService:       b.BucketService,
+	}
+}
+
+// IndexRebuildHandler starts rebuilds of the series index of buckets and
+// reports on their progress.
+type IndexRebuildHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	IndexRebuildService influxdb.IndexRebuildService
+	BucketService       influxdb.BucketService
+}
+
+// NewIndexRebuildHandler creates a new handler at /api/v2/index/rebuilds to
+// rebuild the series index of buckets.
+func NewIndexRebuildHandler(log *zap.Logger, b *IndexRebuildBackend) *IndexRebuildHandler {
+	h := &IndexRebuildHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		IndexRebuildService: b.IndexRebuildService,
+		BucketService:       b.BucketService,
+	}
+
+	h.HandlerFunc("POST", prefixIndexRebuilds, h.handlePostIndexRebuild)
+	h.HandlerFunc("GET", prefixIndexRebuilds, h.handleGetIndexRebuilds)
+	h.HandlerFunc("GET", indexRebuildsIDPath, h.handleGetIndexRebuild)
+	return h
+}
+
+type indexRebuildsResponse struct {
+	Rebuilds []*influxdb.IndexRebuild `json:"rebuilds"`
+}
+
+// handlePostIndexRebuild is the HTTP handler for the POST
+// /api/v2/index/rebuilds route. It returns as soon as the rebuild has started.
+func (h *IndexRebuildHandler) handlePostIndexRebuild(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "IndexRebuildHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	rb, err := h.decodePostIndexRebuildRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.IndexRebuildService.RebuildIndex(ctx, rb); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Index rebuild started", zap.String("rebuildID", rb.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, rb); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodePostIndexRebuildRequest decodes an index rebuild, and checks that its
+// bucket belongs to its organization.
+func (h *IndexRebuildHandler) decodePostIndexRebuildRequest(ctx context.Context, r *http.Request) (*influxdb.IndexRebuild, error) {
+	rb := &influxdb.IndexRebuild{}
+	if err := json.NewDecoder(r.Body).Decode(rb); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid request; error parsing request json",
+			Err:  err,
+		}
+	}
+	if !rb.OrgID.Valid() || !rb.BucketID.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID and bucketID are required",
+		}
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, rb.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	if b.OrgID != rb.OrgID {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucket " + rb.BucketID.String() + " does not belong to the organization",
+		}
+	}
+	return rb, nil
+}
+
+// handleGetIndexRebuilds is the HTTP handler for the GET
+// /api/v2/index/rebuilds route.
+func (h *IndexRebuildHandler) handleGetIndexRebuilds(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "IndexRebuildHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter := influxdb.IndexRebuildFilter{}
+	if orgID := r.URL.Query().Get(OrgID); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		filter.OrgID = id
+	}
+
+	rebuilds, err := h.IndexRebuildService.FindIndexRebuilds(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if rebuilds == nil {
+		rebuilds = []*influxdb.IndexRebuild{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, indexRebuildsResponse{Rebuilds: rebuilds}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetIndexRebuild is the HTTP handler for the GET
+// /api/v2/index/rebuilds/:id route.
+func (h *IndexRebuildHandler) handleGetIndexRebuild(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "IndexRebuildHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(httprouter.ParamsFromContext(ctx).ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	rb, err := h.IndexRebuildService.FindIndexRebuildByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, rb); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
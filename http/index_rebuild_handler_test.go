@@ -0,0 +1,162 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIndexRebuildHandler_handlePostIndexRebuild(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		err   error
+		wants wants
+	}{
+		{
+			name: "rebuild the index of a bucket",
+			body: `{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082001"}`,
+			wants: wants{
+				statusCode: http.StatusAccepted,
+				body: `{
+					"id": "020f755c3c082010",
+					"orgID": "020f755c3c082000",
+					"bucketID": "020f755c3c082001",
+					"status": "running",
+					"series": 0,
+					"startedAt": "2019-10-01T00:00:00Z"
+				}`,
+			},
+		},
+		{
+			name: "missing bucket",
+			body: `{"orgID": "020f755c3c082000"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "orgID and bucketID are required"}`,
+			},
+		},
+		{
+			name: "bucket of another org",
+			body: `{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082003"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "bucket 020f755c3c082003 does not belong to the organization"}`,
+			},
+		},
+		{
+			name: "rebuild already running",
+			body: `{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082001"}`,
+			err:  &influxdb.Error{Code: influxdb.EConflict, Msg: "an index rebuild is already running"},
+			wants: wants{
+				statusCode: http.StatusUnprocessableEntity,
+				body:       `{"code": "conflict", "message": "an index rebuild is already running"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := mock.NewBucketService()
+			buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				orgID := influxdb.ID(0x020f755c3c082000)
+				if id == influxdb.ID(0x020f755c3c082003) {
+					orgID++
+				}
+				return &influxdb.Bucket{ID: id, OrgID: orgID}, nil
+			}
+
+			svc := mock.NewIndexRebuildService()
+			svc.RebuildIndexF = func(ctx context.Context, r *influxdb.IndexRebuild) error {
+				if tt.err != nil {
+					return tt.err
+				}
+				started := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+				r.ID, r.Status, r.StartedAt = influxdb.ID(0x020f755c3c082010), influxdb.IndexRebuildRunning, &started
+				return nil
+			}
+
+			h := NewIndexRebuildHandler(zaptest.NewLogger(t), &IndexRebuildBackend{
+				HTTPErrorHandler:    kithttp.ErrorHandler(0),
+				IndexRebuildService: svc,
+				BucketService:       buckets,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixIndexRebuilds, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handlePostIndexRebuild() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+				t.Errorf("handlePostIndexRebuild(). error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("handlePostIndexRebuild() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestIndexRebuildHandler_handleGetIndexRebuilds(t *testing.T) {
+	var gotFilter influxdb.IndexRebuildFilter
+	svc := mock.NewIndexRebuildService()
+	svc.FindIndexRebuildsF = func(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error) {
+		gotFilter = filter
+		return []*influxdb.IndexRebuild{{
+			ID:       influxdb.ID(0x020f755c3c082010),
+			OrgID:    influxdb.ID(0x020f755c3c082000),
+			BucketID: influxdb.ID(0x020f755c3c082001),
+			Status:   influxdb.IndexRebuildCompleted,
+			Series:   12,
+		}}, nil
+	}
+
+	h := NewIndexRebuildHandler(zaptest.NewLogger(t), &IndexRebuildBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		IndexRebuildService: svc,
+	})
+
+	r := httptest.NewRequest("GET", "http://any.tld"+prefixIndexRebuilds+"?orgID=020f755c3c082000", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetIndexRebuilds() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if gotFilter.OrgID == nil || *gotFilter.OrgID != influxdb.ID(0x020f755c3c082000) {
+		t.Errorf("unexpected filter %+v", gotFilter)
+	}
+	exp := `{"rebuilds": [{
+		"id": "020f755c3c082010",
+		"orgID": "020f755c3c082000",
+		"bucketID": "020f755c3c082001",
+		"status": "completed",
+		"series": 12
+	}]}`
+	if eq, diff, err := jsonEqual(string(body), exp); err != nil {
+		t.Errorf("handleGetIndexRebuilds(). error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("handleGetIndexRebuilds() = ***%s***", diff)
+	}
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	http "net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixLDAP   = "/api/v2/ldap"
+	ldapSyncPath = "/api/v2/ldap/sync"
+)
+
+// LDAPBackend is all services and associated parameters required to
+// construct the LDAPHandler.
+type LDAPBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	LDAPService     influxdb.LDAPService
+	LDAPSyncService influxdb.LDAPSyncService
+}
+
+// NewLDAPBackend returns a new instance of LDAPBackend.
+func NewLDAPBackend(log *zap.Logger, b *APIBackend) *LDAPBackend {
+	return &LDAPBackend{
+		log: log,
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		LDAPService:      b.LDAPService,
+		LDAPSyncService:  b.LDAPSyncService,
+	}
+}
+
+// LDAPHandler manages the configuration of the LDAP directory and syncs of
+// its groups.
+type LDAPHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	LDAPService     influxdb.LDAPService
+	LDAPSyncService influxdb.LDAPSyncService
+}
+
+// NewLDAPHandler creates a new handler at /api/v2/ldap to configure the LDAP
+// directory and sync its groups.
+func NewLDAPHandler(log *zap.Logger, b *LDAPBackend) *LDAPHandler {
+	h := &LDAPHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		LDAPService:     b.LDAPService,
+		LDAPSyncService: b.LDAPSyncService,
+	}
+
+	h.HandlerFunc("GET", prefixLDAP, h.handleGetLDAPConfig)
+	h.HandlerFunc("PUT", prefixLDAP, h.handlePutLDAPConfig)
+	h.HandlerFunc("POST", ldapSyncPath, h.handlePostLDAPSync)
+	return h
+}
+
+// redactLDAPConfig returns c without its bind password, which is never
+// returned by the API.
+func redactLDAPConfig(c *influxdb.LDAPConfig) *influxdb.LDAPConfig {
+	redacted := *c
+	redacted.BindPassword = ""
+	if redacted.Mappings == nil {
+		redacted.Mappings = []influxdb.LDAPGroupMapping{}
+	}
+	return &redacted
+}
+
+// handleGetLDAPConfig is the HTTP handler for the GET /api/v2/ldap route.
+func (h *LDAPHandler) handleGetLDAPConfig(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "LDAPHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	c, err := h.LDAPService.FindLDAPConfig(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, redactLDAPConfig(c)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePutLDAPConfig is the HTTP handler for the PUT /api/v2/ldap route.
+func (h *LDAPHandler) handlePutLDAPConfig(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "LDAPHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	c, err := h.decodePutLDAPConfigRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.LDAPService.PutLDAPConfig(ctx, c); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("LDAP configured", zap.Bool("enabled", c.Enabled))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, redactLDAPConfig(c)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodePutLDAPConfigRequest decodes a configuration. The bind password of
+// the current configuration is kept if none is given for the same bind DN, as
+// it is never returned by the API.
+func (h *LDAPHandler) decodePutLDAPConfigRequest(ctx context.Context, r *http.Request) (*influxdb.LDAPConfig, error) {
+	c := &influxdb.LDAPConfig{}
+	if err := json.NewDecoder(r.Body).Decode(c); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid request; error parsing request json",
+			Err:  err,
+		}
+	}
+
+	if c.BindPassword == "" && c.BindDN != "" {
+		current, err := h.LDAPService.FindLDAPConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if current.BindDN == c.BindDN {
+			c.BindPassword = current.BindPassword
+		}
+	}
+	return c, nil
+}
+
+// handlePostLDAPSync is the HTTP handler for the POST /api/v2/ldap/sync
+// route. The dryRun query parameter reports the changes the sync would make
+// without making them.
+func (h *LDAPHandler) handlePostLDAPSync(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "LDAPHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var dryRun bool
+	if s := r.URL.Query().Get("dryRun"); s != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid dryRun",
+				Err:  err,
+			}, w)
+			return
+		}
+	}
+
+	report, err := h.LDAPSyncService.SyncLDAP(ctx, dryRun)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("LDAP groups synced", zap.Bool("dryRun", dryRun), zap.Int("changes", len(report.Changes)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, report); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
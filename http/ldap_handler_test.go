@@ -0,0 +1,119 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLDAPHandler_handlePutLDAPConfig(t *testing.T) {
+	current := &influxdb.LDAPConfig{
+		URL:          "ldap://ldap.example.com",
+		BindDN:       "cn=influxdb,dc=example,dc=com",
+		BindPassword: "secret",
+	}
+	var got *influxdb.LDAPConfig
+	svc := mock.NewLDAPService()
+	svc.FindLDAPConfigFn = func(ctx context.Context) (*influxdb.LDAPConfig, error) {
+		return current, nil
+	}
+	svc.PutLDAPConfigFn = func(ctx context.Context, c *influxdb.LDAPConfig) error {
+		got = c
+		return nil
+	}
+
+	h := NewLDAPHandler(zaptest.NewLogger(t), &LDAPBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		LDAPService:      svc,
+	})
+
+	tests := []struct {
+		name         string
+		body         string
+		wantPassword string
+	}{
+		{
+			name:         "bind password of the same bind DN is kept",
+			body:         `{"url": "ldaps://ldap.example.com", "bindDN": "cn=influxdb,dc=example,dc=com"}`,
+			wantPassword: "secret",
+		},
+		{
+			name:         "bind password is replaced",
+			body:         `{"url": "ldaps://ldap.example.com", "bindDN": "cn=influxdb,dc=example,dc=com", "bindPassword": "new"}`,
+			wantPassword: "new",
+		},
+		{
+			name: "bind password of another bind DN is not kept",
+			body: `{"url": "ldaps://ldap.example.com", "bindDN": "cn=other,dc=example,dc=com"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "http://any.tld"+prefixLDAP, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("handlePutLDAPConfig() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+			}
+			if got.BindPassword != tt.wantPassword {
+				t.Errorf("got bind password %q, want %q", got.BindPassword, tt.wantPassword)
+			}
+			// The bind password is never returned.
+			if bytes.Contains(body, []byte("bindPassword")) {
+				t.Errorf("response contains the bind password: %s", body)
+			}
+		})
+	}
+}
+
+func TestLDAPHandler_handlePostLDAPSync(t *testing.T) {
+	syncs := &mock.LDAPSyncService{
+		SyncLDAPFn: func(ctx context.Context, dryRun bool) (*influxdb.LDAPSyncReport, error) {
+			return &influxdb.LDAPSyncReport{
+				DryRun: dryRun,
+				Changes: []influxdb.LDAPSyncChange{
+					{Action: influxdb.LDAPCreateUser, Username: "alice"},
+					{Action: influxdb.LDAPAddMember, Username: "alice", OrgID: influxdb.ID(0x020f755c3c082000), Role: influxdb.Owner},
+				},
+			}, nil
+		},
+	}
+
+	h := NewLDAPHandler(zaptest.NewLogger(t), &LDAPBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		LDAPSyncService:  syncs,
+	})
+
+	r := httptest.NewRequest("POST", "http://any.tld"+ldapSyncPath+"?dryRun=true", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handlePostLDAPSync() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	want := `{
+		"dryRun": true,
+		"changes": [
+			{"action": "createUser", "username": "alice"},
+			{"action": "addMember", "username": "alice", "orgID": "020f755c3c082000", "role": "owner"}
+		]
+	}`
+	if eq, diff, err := jsonEqual(string(body), want); err != nil {
+		t.Errorf("handlePostLDAPSync(). error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("handlePostLDAPSync() = ***%s***", diff)
+	}
+}
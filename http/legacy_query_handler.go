@@ -0,0 +1,219 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/http/metric"
+	"github.com/influxdata/influxdb/jsonweb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/influxql"
+	"go.uber.org/zap"
+)
+
+const prefixLegacyQuery = "/query"
+
+// LegacyQueryBackend is all services and associated parameters required to
+// construct the LegacyQueryHandler.
+type LegacyQueryBackend struct {
+	influxdb.HTTPErrorHandler
+	log                *zap.Logger
+	QueryEventRecorder metric.EventRecorder
+
+	ProxyQueryService  query.ProxyQueryService
+	DBRPMappingService influxdb.DBRPMappingService
+}
+
+// NewLegacyQueryBackend returns a new instance of LegacyQueryBackend.
+func NewLegacyQueryBackend(log *zap.Logger, b *APIBackend) *LegacyQueryBackend {
+	return &LegacyQueryBackend{
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		log:                log,
+		QueryEventRecorder: b.QueryEventRecorder,
+
+		ProxyQueryService:  b.InfluxQLService,
+		DBRPMappingService: b.DBRPMappingService,
+	}
+}
+
+// LegacyQueryHandler executes the InfluxQL queries of 1.x clients against the
+// buckets their databases and retention policies are mapped to, and responds
+// with results in the 1.x JSON format.
+type LegacyQueryHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	Now                func() time.Time
+	ProxyQueryService  query.ProxyQueryService
+	DBRPMappingService influxdb.DBRPMappingService
+
+	EventRecorder metric.EventRecorder
+}
+
+// NewLegacyQueryHandler creates a new handler at /query to execute the
+// InfluxQL queries of 1.x clients.
+func NewLegacyQueryHandler(log *zap.Logger, b *LegacyQueryBackend) *LegacyQueryHandler {
+	h := &LegacyQueryHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		Now:                time.Now,
+		ProxyQueryService:  b.ProxyQueryService,
+		DBRPMappingService: b.DBRPMappingService,
+		EventRecorder:      b.QueryEventRecorder,
+	}
+
+	h.HandlerFunc("GET", prefixLegacyQuery, h.handleQuery)
+	h.HandlerFunc("POST", prefixLegacyQuery, h.handleQuery)
+	return h
+}
+
+// Prefix provides the route prefix.
+func (*LegacyQueryHandler) Prefix() string {
+	return prefixLegacyQuery
+}
+
+// handleQuery is the HTTP handler for the GET and POST /query routes.
+func (h *LegacyQueryHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	const op = "http/handleLegacyQuery"
+	span, r := tracing.ExtractFromHTTPRequest(r, "LegacyQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var orgID influxdb.ID
+	var requestBytes int
+	sw := kithttp.NewStatusResponseWriter(w)
+	w = sw
+	defer func() {
+		h.EventRecorder.Record(ctx, metric.Event{
+			OrgID:         orgID,
+			Endpoint:      r.URL.Path,
+			RequestBytes:  requestBytes,
+			ResponseBytes: sw.ResponseBytes(),
+			Status:        sw.Code(),
+		})
+	}()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	q := r.FormValue("q")
+	if q == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  `missing required parameter "q"`,
+			Op:   op,
+		}, w)
+		return
+	}
+	requestBytes = len(q)
+
+	timeFormat, err := legacyTimeFormat(r.FormValue("epoch"))
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	db, rp := r.FormValue("db"), r.FormValue("rp")
+	m, err := findLegacyDBRPMapping(ctx, h.DBRPMappingService, db, rp)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	orgID = m.OrganizationID
+
+	var token *influxdb.Authorization
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		token = a
+	case *influxdb.Session:
+		token = a.EphemeralAuth(orgID)
+	case *jsonweb.Token:
+		token = a.EphemeralAuth(orgID)
+	default:
+		h.HandleHTTPError(ctx, influxdb.ErrAuthorizerNotSupported, w)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, token)
+
+	now := h.Now()
+	compiler := influxql.NewCompiler(h.DBRPMappingService)
+	compiler.Cluster = DefaultDBRPCluster
+	compiler.DB = db
+	compiler.RP = rp
+	compiler.Query = q
+	compiler.Now = &now
+
+	dialect := &influxql.Dialect{
+		TimeFormat: timeFormat,
+		Encoding:   influxql.JSON,
+	}
+	req := &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  token,
+			OrganizationID: orgID,
+			Compiler:       compiler,
+			Source:         r.Header.Get("User-Agent"),
+		},
+		Dialect: dialect,
+	}
+	dialect.SetHeaders(w)
+
+	cw := iocounter.Writer{Writer: w}
+	if _, err := h.ProxyQueryService.Query(ctx, &cw, req); err != nil {
+		if cw.Count() == 0 {
+			// Only record the error headers IFF nothing has been written to w.
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		_ = tracing.LogError(span, err)
+		h.log.Info("Error writing response to client",
+			zap.String("handler", "legacy_query"),
+			zap.Error(err),
+		)
+	}
+}
+
+// legacyTimeFormat returns the time format of the results of a query of a 1.x
+// epoch parameter. Times are formatted as RFC3339Nano strings by default.
+func legacyTimeFormat(epoch string) (influxql.TimeFormat, error) {
+	switch epoch {
+	case "":
+		return influxql.RFC3339Nano, nil
+	case "h":
+		return influxql.Hour, nil
+	case "m":
+		return influxql.Minute, nil
+	case "s":
+		return influxql.Second, nil
+	case "ms":
+		return influxql.Millisecond, nil
+	case "u", "us":
+		return influxql.Microsecond, nil
+	case "n", "ns":
+		return influxql.Nanosecond, nil
+	default:
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid epoch %q; valid epochs are h, m, s, ms, u and ns", epoch),
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/influxql"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLegacyQueryHandler_handleQuery(t *testing.T) {
+	type wants struct {
+		statusCode int
+		db         string
+		rp         string
+		query      string
+		timeFormat influxql.TimeFormat
+		body       string
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		form   string
+		wants  wants
+	}{
+		{
+			name:   "query with GET",
+			method: "GET",
+			url:    "/query?db=telegraf&q=SELECT+value+FROM+cpu",
+			wants: wants{
+				statusCode: http.StatusOK,
+				db:         "telegraf",
+				query:      "SELECT value FROM cpu",
+				body:       `{"results":[{"statement_id":0}]}`,
+			},
+		},
+		{
+			name:   "query with POST form",
+			method: "POST",
+			url:    "/query?db=telegraf&rp=autogen&epoch=ms",
+			form:   "q=SELECT+value+FROM+cpu",
+			wants: wants{
+				statusCode: http.StatusOK,
+				db:         "telegraf",
+				rp:         "autogen",
+				query:      "SELECT value FROM cpu",
+				timeFormat: influxql.Millisecond,
+				body:       `{"results":[{"statement_id":0}]}`,
+			},
+		},
+		{
+			name:   "missing query",
+			method: "GET",
+			url:    "/query?db=telegraf",
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "missing required parameter \"q\""}`,
+			},
+		},
+		{
+			name:   "invalid epoch",
+			method: "GET",
+			url:    "/query?db=telegraf&q=SELECT+value+FROM+cpu&epoch=d",
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "invalid epoch \"d\"; valid epochs are h, m, s, ms, u and ns"}`,
+			},
+		},
+		{
+			name:   "database not found",
+			method: "GET",
+			url:    "/query?db=metrics&q=SELECT+value+FROM+cpu",
+			wants: wants{
+				statusCode: http.StatusNotFound,
+				body:       `{"code": "not found", "message": "database not found: \"metrics\""}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings := mock.NewDBRPMappingService()
+			mapping := func(db, rp string) (*influxdb.DBRPMapping, error) {
+				if db != "telegraf" {
+					return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrDBRPMappingNotFound}
+				}
+				return &influxdb.DBRPMapping{
+					Cluster:         DefaultDBRPCluster,
+					Database:        db,
+					RetentionPolicy: "autogen",
+					Default:         true,
+					OrganizationID:  influxdb.ID(0x020f755c3c082000),
+					BucketID:        influxdb.ID(0x020f755c3c082001),
+				}, nil
+			}
+			mappings.FindByFn = func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+				return mapping(db, rp)
+			}
+			mappings.FindFn = func(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+				return mapping(*filter.Database, "")
+			}
+
+			var got *query.ProxyRequest
+			queries := &querymock.ProxyQueryService{
+				QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+					got = req
+					_, err := io.WriteString(w, `{"results":[{"statement_id":0}]}`)
+					return flux.Statistics{}, err
+				},
+			}
+
+			h := NewLegacyQueryHandler(zaptest.NewLogger(t), &LegacyQueryBackend{
+				HTTPErrorHandler:   kithttp.ErrorHandler(0),
+				log:                zaptest.NewLogger(t),
+				QueryEventRecorder: noopEventRecorder{},
+				ProxyQueryService:  queries,
+				DBRPMappingService: mappings,
+			})
+
+			r := httptest.NewRequest(tt.method, "http://any.tld"+tt.url, strings.NewReader(tt.form))
+			if tt.form != "" {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			auth := &influxdb.Authorization{ID: influxdb.ID(1), OrgID: influxdb.ID(0x020f755c3c082000)}
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handleQuery() = %v, want %v", res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.query != "" {
+				if got == nil {
+					t.Fatal("query was not executed")
+				}
+				c, ok := got.Request.Compiler.(*influxql.Compiler)
+				if !ok {
+					t.Fatalf("unexpected compiler %T", got.Request.Compiler)
+				}
+				if c.Cluster != DefaultDBRPCluster || c.DB != tt.wants.db || c.RP != tt.wants.rp || c.Query != tt.wants.query {
+					t.Errorf("unexpected compiler cluster=%q db=%q rp=%q query=%q", c.Cluster, c.DB, c.RP, c.Query)
+				}
+				if got.Request.OrganizationID != influxdb.ID(0x020f755c3c082000) {
+					t.Errorf("unexpected organization %s", got.Request.OrganizationID)
+				}
+				if got.Request.Authorization != auth {
+					t.Errorf("unexpected authorization %v", got.Request.Authorization)
+				}
+				if d, ok := got.Dialect.(*influxql.Dialect); !ok || d.TimeFormat != tt.wants.timeFormat {
+					t.Errorf("unexpected dialect %#v", got.Dialect)
+				}
+			}
+			b, _ := ioutil.ReadAll(res.Body)
+			if eq, diff, err := jsonEqual(string(b), tt.wants.body); err != nil {
+				t.Errorf("error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("handleQuery() = ***%s***", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixLegacyWrite = "/write"
+
+	// DefaultDBRPCluster is the cluster of the dbrp mappings of the databases
+	// and retention policies of the 1.x compatible endpoints.
+	DefaultDBRPCluster = "default"
+)
+
+// LegacyWriteBackend is all services and associated parameters required to
+// construct the LegacyWriteHandler.
+type LegacyWriteBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	DBRPMappingService influxdb.DBRPMappingService
+}
+
+// NewLegacyWriteBackend returns a new instance of LegacyWriteBackend.
+func NewLegacyWriteBackend(log *zap.Logger, b *APIBackend) *LegacyWriteBackend {
+	return &LegacyWriteBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		DBRPMappingService: b.DBRPMappingService,
+	}
+}
+
+// LegacyWriteHandler receives line protocol written by 1.x clients to a
+// database and retention policy, and writes it to the bucket they are mapped
+// to.
+type LegacyWriteHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	DBRPMappingService influxdb.DBRPMappingService
+
+	// WriteHandler handles the writes to /api/v2/write the 1.x writes are
+	// passed on as.
+	WriteHandler http.Handler
+}
+
+// NewLegacyWriteHandler creates a new handler at /write to receive line
+// protocol from 1.x clients. Writes are passed on to writeHandler.
+func NewLegacyWriteHandler(log *zap.Logger, b *LegacyWriteBackend, writeHandler http.Handler) *LegacyWriteHandler {
+	h := &LegacyWriteHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		DBRPMappingService: b.DBRPMappingService,
+		WriteHandler:       writeHandler,
+	}
+
+	h.HandlerFunc("POST", prefixLegacyWrite, h.handleWrite)
+	return h
+}
+
+// Prefix provides the route prefix.
+func (*LegacyWriteHandler) Prefix() string {
+	return prefixLegacyWrite
+}
+
+// handleWrite is the HTTP handler for the POST /write route.
+func (h *LegacyWriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "LegacyWriteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	qp := r.URL.Query()
+	m, err := findLegacyDBRPMapping(ctx, h.DBRPMappingService, qp.Get("db"), qp.Get("rp"))
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	params := url.Values{}
+	params.Set(Org, m.OrganizationID.String())
+	params.Set(Bucket, m.BucketID.String())
+	if p := qp.Get("precision"); p != "" {
+		params.Set("precision", legacyPrecision(p))
+	}
+
+	r = r.Clone(ctx)
+	r.URL.Path = prefixWrite
+	r.URL.RawPath = ""
+	r.URL.RawQuery = params.Encode()
+	h.WriteHandler.ServeHTTP(w, r)
+}
+
+// legacyPrecision returns the precision of writes to /api/v2/write of a 1.x
+// precision. Precisions of minutes and hours are not supported.
+func legacyPrecision(p string) string {
+	switch p {
+	case "n":
+		return "ns"
+	case "u":
+		return "us"
+	default:
+		return p
+	}
+}
+
+// findLegacyDBRPMapping returns the mapping of a database and retention policy
+// of a 1.x request, or the default mapping of the database if no retention
+// policy is given.
+func findLegacyDBRPMapping(ctx context.Context, svc influxdb.DBRPMappingService, db, rp string) (*influxdb.DBRPMapping, error) {
+	if db == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "database is required",
+		}
+	}
+
+	cluster := DefaultDBRPCluster
+	if rp != "" {
+		m, err := svc.FindBy(ctx, cluster, db, rp)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  fmt.Sprintf("retention policy not found: %s", rp),
+			}
+		}
+		return m, err
+	}
+
+	def := true
+	m, err := svc.Find(ctx, influxdb.DBRPMappingFilter{
+		Cluster:  &cluster,
+		Database: &db,
+		Default:  &def,
+	})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("database not found: %q", db),
+		}
+	}
+	return m, err
+}
@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestLegacyWriteHandler_handleWrite(t *testing.T) {
+	type wants struct {
+		statusCode int
+		path       string
+		query      string
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		url   string
+		wants wants
+	}{
+		{
+			name: "write to default retention policy",
+			url:  "/write?db=telegraf",
+			wants: wants{
+				statusCode: http.StatusNoContent,
+				path:       "/api/v2/write",
+				query:      "bucket=020f755c3c082001&org=020f755c3c082000",
+			},
+		},
+		{
+			name: "write to retention policy",
+			url:  "/write?db=telegraf&rp=weekly&precision=s",
+			wants: wants{
+				statusCode: http.StatusNoContent,
+				path:       "/api/v2/write",
+				query:      "bucket=020f755c3c082002&org=020f755c3c082000&precision=s",
+			},
+		},
+		{
+			name: "write with 1.x precision",
+			url:  "/write?db=telegraf&precision=u",
+			wants: wants{
+				statusCode: http.StatusNoContent,
+				path:       "/api/v2/write",
+				query:      "bucket=020f755c3c082001&org=020f755c3c082000&precision=us",
+			},
+		},
+		{
+			name: "missing database",
+			url:  "/write",
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "database is required"}`,
+			},
+		},
+		{
+			name: "database not found",
+			url:  "/write?db=metrics",
+			wants: wants{
+				statusCode: http.StatusNotFound,
+				body:       `{"code": "not found", "message": "database not found: \"metrics\""}`,
+			},
+		},
+		{
+			name: "retention policy not found",
+			url:  "/write?db=telegraf&rp=daily",
+			wants: wants{
+				statusCode: http.StatusNotFound,
+				body:       `{"code": "not found", "message": "retention policy not found: daily"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings := mock.NewDBRPMappingService()
+			notFound := &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrDBRPMappingNotFound}
+			mappings.FindByFn = func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+				if cluster != DefaultDBRPCluster || db != "telegraf" || rp != "weekly" {
+					return nil, notFound
+				}
+				return &influxdb.DBRPMapping{
+					Cluster:         cluster,
+					Database:        db,
+					RetentionPolicy: rp,
+					OrganizationID:  influxdb.ID(0x020f755c3c082000),
+					BucketID:        influxdb.ID(0x020f755c3c082002),
+				}, nil
+			}
+			mappings.FindFn = func(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+				if *filter.Cluster != DefaultDBRPCluster || *filter.Database != "telegraf" || !*filter.Default {
+					return nil, notFound
+				}
+				return &influxdb.DBRPMapping{
+					Cluster:         *filter.Cluster,
+					Database:        *filter.Database,
+					RetentionPolicy: "autogen",
+					Default:         true,
+					OrganizationID:  influxdb.ID(0x020f755c3c082000),
+					BucketID:        influxdb.ID(0x020f755c3c082001),
+				}, nil
+			}
+
+			var path, query, body string
+			writes := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, query = r.URL.Path, r.URL.RawQuery
+				b, _ := ioutil.ReadAll(r.Body)
+				body = string(b)
+				w.WriteHeader(http.StatusNoContent)
+			})
+
+			h := NewLegacyWriteHandler(zaptest.NewLogger(t), &LegacyWriteBackend{
+				HTTPErrorHandler:   kithttp.ErrorHandler(0),
+				log:                zaptest.NewLogger(t),
+				DBRPMappingService: mappings,
+			}, writes)
+
+			r := httptest.NewRequest("POST", "http://any.tld"+tt.url, bytes.NewBufferString("cpu value=1"))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handleWrite() = %v, want %v", res.StatusCode, tt.wants.statusCode)
+			}
+			if tt.wants.path != "" {
+				if path != tt.wants.path {
+					t.Errorf("write path = %q, want %q", path, tt.wants.path)
+				}
+				if query != tt.wants.query {
+					t.Errorf("write query = %q, want %q", query, tt.wants.query)
+				}
+				if body != "cpu value=1" {
+					t.Errorf("write body = %q, want %q", body, "cpu value=1")
+				}
+			}
+			if tt.wants.body != "" {
+				b, _ := ioutil.ReadAll(res.Body)
+				if eq, diff, err := jsonEqual(string(b), tt.wants.body); err != nil {
+					t.Errorf("error unmarshaling json %v", err)
+				} else if !eq {
+					t.Errorf("handleWrite() = ***%s***", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixLiveQuery = "/api/v2/query/live"
+
+	// defaultLiveQueryInterval is how often a live query is re-run if the
+	// request does not say.
+	defaultLiveQueryInterval = 10 * time.Second
+	// minLiveQueryInterval bounds how often a live query can be re-run.
+	minLiveQueryInterval = time.Second
+)
+
+// handleLiveQuery re-runs a query every interval and pushes its results to
+// the client as server-sent events, for as long as the client is connected.
+//
+// Each run sends a result event holding the results, encoded as they are by
+// /api/v2/query, if they changed since the last run. A run that fails sends
+// an error event and ends the stream.
+func (h *FluxHandler) handleLiveQuery(w http.ResponseWriter, r *http.Request) {
+	const op = "http/handleLiveQuery"
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	log := h.log.With(logger.TraceFields(ctx)...)
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	interval, err := decodeLiveQueryInterval(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	req, _, err := decodeQueryRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request body",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+	if req.Accept == queryMediaTypeArrow {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("live query results cannot be encoded as %s", queryMediaTypeArrow),
+			Op:   op,
+		}, w)
+		return
+	}
+	if req.PreferNoContent || req.PreferNoContentWithError {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "live queries must return their results",
+			Op:   op,
+		}, w)
+		return
+	}
+	token, err := queryAuthorization(a, req.Org.ID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, token)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// Clients reconnecting after the connection is lost wait for the next run.
+	fmt.Fprintf(w, "retry: %d\n\n", interval/time.Millisecond)
+	flushResponse(w)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last []byte
+	for id := 1; ; id++ {
+		results, err := h.runLiveQuery(ctx, req, token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			writeLiveQueryError(w, id, err)
+			flushResponse(w)
+			log.Info("Live query stopped",
+				zap.String("handler", "flux"),
+				zap.Error(err),
+			)
+			return
+		}
+
+		if id == 1 || !bytes.Equal(results, last) {
+			writeEvent(w, id, "result", results)
+			last = results
+		} else {
+			// Comments keep the connection alive while the results stay
+			// the same.
+			io.WriteString(w, ": unchanged\n\n")
+		}
+		flushResponse(w)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runLiveQuery runs req once, as of now, and returns its encoded results.
+func (h *FluxHandler) runLiveQuery(ctx context.Context, req *QueryRequest, token *influxdb.Authorization) ([]byte, error) {
+	if h.DrainGate != nil {
+		done, err := h.DrainGate.AdmitQuery()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
+	pr, err := req.proxyRequest(h.Now)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to compile query",
+			Err:  err,
+		}
+	}
+	pr.Request.Authorization = token
+	pr.Request.Source = "live"
+
+	var buf bytes.Buffer
+	if _, err := h.ProxyQueryService.Query(ctx, &buf, pr); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeLiveQueryInterval returns the interval a live query is re-run at.
+func decodeLiveQueryInterval(r *http.Request) (time.Duration, error) {
+	s := r.URL.Query().Get("interval")
+	if s == "" {
+		return defaultLiveQueryInterval, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid interval",
+			Err:  err,
+		}
+	}
+	if d < minLiveQueryInterval {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("interval must be at least %s", minLiveQueryInterval),
+		}
+	}
+	return d, nil
+}
+
+// writeEvent writes a server-sent event, with a data line for each line of
+// data.
+func writeEvent(w io.Writer, id int, event string, data []byte) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "id: %d\nevent: %s\n", id, event)
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\r\n"), "\n") {
+		buf.WriteString("data: ")
+		buf.WriteString(strings.TrimSuffix(line, "\r"))
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+	_, _ = w.Write(buf.Bytes())
+}
+
+// writeLiveQueryError writes err as an error event, encoded as errors are in
+// the bodies of responses.
+func writeLiveQueryError(w io.Writer, id int, err error) {
+	var e struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	e.Code = influxdb.ErrorCode(err)
+	e.Message = influxdb.ErrorMessage(err)
+	b, _ := json.Marshal(e)
+	writeEvent(w, id, "error", b)
+}
+
+// flushResponse sends the data written to w to the client, if w can be
+// flushed.
+func flushResponse(w http.ResponseWriter) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
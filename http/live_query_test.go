@@ -0,0 +1,97 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	influxmock "github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFluxHandler_handleLiveQuery(t *testing.T) {
+	orgService := &influxmock.OrganizationService{
+		FindOrganizationF: func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+			return &influxdb.Organization{ID: influxdb.ID(1), Name: "org"}, nil
+		},
+	}
+
+	var nows []time.Time
+	results := []string{",result,table\r\n,_result,0\r\n", ",result,table\r\n,_result,0\r\n"}
+	queryService := &mock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			nows = append(nows, req.Request.Compiler.(lang.FluxCompiler).Now)
+			if len(results) == 0 {
+				return flux.Statistics{}, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+			}
+			_, _ = io.WriteString(w, results[0])
+			results = results[1:]
+			return flux.Statistics{}, nil
+		},
+	}
+
+	h := NewFluxHandler(zaptest.NewLogger(t), &FluxBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		log:                 zaptest.NewLogger(t),
+		QueryEventRecorder:  noopEventRecorder{},
+		OrganizationService: orgService,
+		ProxyQueryService:   queryService,
+	})
+	var now time.Time
+	h.Now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	r := httptest.NewRequest("POST", "/api/v2/query/live?orgID=0000000000000001&interval=1s", bytes.NewBufferString(`{"query":"from(bucket: \"b\") |> range(start: -1m)"}`))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{OrgID: influxdb.ID(1), Status: influxdb.Active}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status code %d, expected %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got content type %q, expected text/event-stream", got)
+	}
+	want := "retry: 1000\n\n" +
+		"id: 1\nevent: result\ndata: ,result,table\ndata: ,_result,0\n\n" +
+		": unchanged\n\n" +
+		"id: 3\nevent: error\ndata: {\"code\":\"not found\",\"message\":\"bucket not found\"}\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("unexpected events\ngot:\n%s\nexpected:\n%s", got, want)
+	}
+
+	// Each run is a query as of when it is run.
+	if len(nows) != 3 || !nows[0].Before(nows[1]) || !nows[1].Before(nows[2]) {
+		t.Errorf("expected each run to be as of a later time, got %v", nows)
+	}
+}
+
+func TestFluxHandler_handleLiveQuery_invalidInterval(t *testing.T) {
+	h := NewFluxHandler(zaptest.NewLogger(t), &FluxBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		log:                zaptest.NewLogger(t),
+		QueryEventRecorder: noopEventRecorder{},
+	})
+
+	r := httptest.NewRequest("POST", "/api/v2/query/live?interval=10ms", bytes.NewBufferString(`{"query":"buckets()"}`))
+	r = r.WithContext(pcontext.SetAuthorizer(r.Context(), &influxdb.Authorization{}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("got status code %d, expected %d: %s", w.Code, http.StatusBadRequest, w.Body)
+	}
+}
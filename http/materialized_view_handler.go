@@ -0,0 +1,285 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixMaterializedViews = "/api/v2/materializedViews"
+	materializedViewsIDPath = "/api/v2/materializedViews/:id"
+)
+
+// MaterializedViewBackend is all services and associated parameters required
+// to construct the MaterializedViewHandler.
+type MaterializedViewBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	MaterializedViewService influxdb.MaterializedViewService
+	BucketService           influxdb.BucketService
+}
+
+// NewMaterializedViewBackend returns a new instance of MaterializedViewBackend.
+func NewMaterializedViewBackend(log *zap.Logger, b *APIBackend) *MaterializedViewBackend {
+	return &MaterializedViewBackend{
+		log: log,
+
+		HTTPErrorHandler:        b.HTTPErrorHandler,
+		MaterializedViewService: b.MaterializedViewService,
+		BucketService:           b.BucketService,
+	}
+}
+
+// MaterializedViewHandler manages the materialized views of buckets.
+type MaterializedViewHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	MaterializedViewService influxdb.MaterializedViewService
+	BucketService           influxdb.BucketService
+}
+
+// NewMaterializedViewHandler creates a new handler at
+// /api/v2/materializedViews to manage materialized views.
+func NewMaterializedViewHandler(log *zap.Logger, b *MaterializedViewBackend) *MaterializedViewHandler {
+	h := &MaterializedViewHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		MaterializedViewService: b.MaterializedViewService,
+		BucketService:           b.BucketService,
+	}
+
+	h.HandlerFunc("GET", prefixMaterializedViews, h.handleGetMaterializedViews)
+	h.HandlerFunc("POST", prefixMaterializedViews, h.handlePostMaterializedView)
+	h.HandlerFunc("GET", materializedViewsIDPath, h.handleGetMaterializedView)
+	h.HandlerFunc("PATCH", materializedViewsIDPath, h.handlePatchMaterializedView)
+	h.HandlerFunc("DELETE", materializedViewsIDPath, h.handleDeleteMaterializedView)
+	return h
+}
+
+type materializedViewsResponse struct {
+	Views []*influxdb.MaterializedView `json:"materializedViews"`
+}
+
+// handleGetMaterializedViews is the HTTP handler for the
+// GET /api/v2/materializedViews route.
+func (h *MaterializedViewHandler) handleGetMaterializedViews(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MaterializedViewHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeMaterializedViewFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	vs, _, err := h.MaterializedViewService.FindMaterializedViews(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if vs == nil {
+		vs = []*influxdb.MaterializedView{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, materializedViewsResponse{Views: vs}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodeMaterializedViewFilter decodes the orgID and bucketID query
+// parameters of a request.
+func decodeMaterializedViewFilter(r *http.Request) (influxdb.MaterializedViewFilter, error) {
+	var filter influxdb.MaterializedViewFilter
+	qp := r.URL.Query()
+	if s := qp.Get("orgID"); s != "" {
+		id, err := influxdb.IDFromString(s)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid orgID",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if s := qp.Get("bucketID"); s != "" {
+		id, err := influxdb.IDFromString(s)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.BucketID = id
+	}
+	return filter, nil
+}
+
+// handlePostMaterializedView is the HTTP handler for the
+// POST /api/v2/materializedViews route. The queries of the view run on
+// behalf of the user creating it.
+func (h *MaterializedViewHandler) handlePostMaterializedView(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MaterializedViewHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	v, err := h.decodePostMaterializedViewRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.MaterializedViewService.CreateMaterializedView(ctx, v); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Materialized view created", zap.String("view", v.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, v); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodePostMaterializedViewRequest decodes a view, whose organization is
+// the organization of its bucket if it is not given.
+func (h *MaterializedViewHandler) decodePostMaterializedViewRequest(ctx context.Context, r *http.Request) (*influxdb.MaterializedView, error) {
+	v := &influxdb.MaterializedView{}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid request; error parsing request json",
+			Err:  err,
+		}
+	}
+	if !v.BucketID.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucketID is required",
+		}
+	}
+
+	b, err := h.BucketService.FindBucketByID(ctx, v.BucketID)
+	if err != nil {
+		return nil, err
+	}
+	if !v.OrgID.Valid() {
+		v.OrgID = b.OrgID
+	} else if b.OrgID != v.OrgID {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucket " + b.ID.String() + " does not belong to the organization",
+		}
+	}
+
+	auth, err := pctx.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v.OwnerID = auth.GetUserID()
+	return v, nil
+}
+
+// handleGetMaterializedView is the HTTP handler for the
+// GET /api/v2/materializedViews/:id route.
+func (h *MaterializedViewHandler) handleGetMaterializedView(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MaterializedViewHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	v, err := h.MaterializedViewService.FindMaterializedViewByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, v); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePatchMaterializedView is the HTTP handler for the
+// PATCH /api/v2/materializedViews/:id route.
+func (h *MaterializedViewHandler) handlePatchMaterializedView(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MaterializedViewHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var upd influxdb.MaterializedViewUpdate
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid request; error parsing request json",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	v, err := h.MaterializedViewService.UpdateMaterializedView(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, v); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteMaterializedView is the HTTP handler for the
+// DELETE /api/v2/materializedViews/:id route.
+func (h *MaterializedViewHandler) handleDeleteMaterializedView(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MaterializedViewHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.MaterializedViewService.DeleteMaterializedView(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMaterializedViewHandler_handlePostMaterializedView(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		wants wants
+	}{
+		{
+			name: "view owned by its creator in the org of its bucket",
+			body: `{"name": "cpu 1m", "query": "from(bucket: \"telegraf\") |> range(start: v.timeRangeStart)", "bucketID": "020f755c3c082001", "every": "1m"}`,
+			wants: wants{
+				statusCode: http.StatusCreated,
+				body: `{
+					"id": "020f755c3c082010",
+					"orgID": "020f755c3c082000",
+					"name": "cpu 1m",
+					"query": "from(bucket: \"telegraf\") |> range(start: v.timeRangeStart)",
+					"bucketID": "020f755c3c082001",
+					"every": "1m0s",
+					"offset": "0s",
+					"latestCompleted": "2020-01-01T00:00:00Z",
+					"ownerID": "020f755c3c082002",
+					"createdAt": "2020-01-01T00:00:00Z",
+					"updatedAt": "2020-01-01T00:00:00Z"
+				}`,
+			},
+		},
+		{
+			name: "missing bucket",
+			body: `{"name": "cpu 1m", "query": "from(bucket: \"telegraf\") |> range(start: v.timeRangeStart)", "every": "1m"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "bucketID is required"}`,
+			},
+		},
+		{
+			name: "bucket of another org",
+			body: `{"name": "cpu 1m", "query": "from(bucket: \"telegraf\") |> range(start: v.timeRangeStart)", "orgID": "020f755c3c082000", "bucketID": "020f755c3c082003", "every": "1m"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "bucket 020f755c3c082003 does not belong to the organization"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := mock.NewBucketService()
+			buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				orgID := influxdb.ID(0x020f755c3c082000)
+				if id == influxdb.ID(0x020f755c3c082003) {
+					orgID++
+				}
+				return &influxdb.Bucket{ID: id, OrgID: orgID}, nil
+			}
+			views := mock.NewMaterializedViewService()
+			views.CreateMaterializedViewFn = func(ctx context.Context, v *influxdb.MaterializedView) error {
+				now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+				v.ID = influxdb.ID(0x020f755c3c082010)
+				v.LatestCompleted = now
+				v.SetCreatedAt(now)
+				v.SetUpdatedAt(now)
+				return nil
+			}
+
+			h := NewMaterializedViewHandler(zaptest.NewLogger(t), &MaterializedViewBackend{
+				HTTPErrorHandler:        kithttp.ErrorHandler(0),
+				MaterializedViewService: views,
+				BucketService:           buckets,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixMaterializedViews, bytes.NewBufferString(tt.body))
+			r = r.WithContext(pctx.SetAuthorizer(r.Context(), &influxdb.Authorization{UserID: influxdb.ID(0x020f755c3c082002)}))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handlePostMaterializedView() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+				t.Errorf("handlePostMaterializedView(). error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("handlePostMaterializedView() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestMaterializedViewHandler_handlePatchMaterializedView(t *testing.T) {
+	var got influxdb.MaterializedViewUpdate
+	views := mock.NewMaterializedViewService()
+	views.UpdateMaterializedViewFn = func(ctx context.Context, id influxdb.ID, upd influxdb.MaterializedViewUpdate) (*influxdb.MaterializedView, error) {
+		got = upd
+		v := &influxdb.MaterializedView{ID: id}
+		upd.Apply(v)
+		return v, nil
+	}
+
+	h := NewMaterializedViewHandler(zaptest.NewLogger(t), &MaterializedViewBackend{
+		HTTPErrorHandler:        kithttp.ErrorHandler(0),
+		MaterializedViewService: views,
+	})
+
+	// The error of a view is set by its materializer only.
+	body := `{"every": "5m", "lastError": "ignored"}`
+	r := httptest.NewRequest("PATCH", "http://any.tld"+prefixMaterializedViews+"/020f755c3c082010", bytes.NewBufferString(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	if res.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(res.Body)
+		t.Fatalf("handlePatchMaterializedView() = %v, want %v: %s", res.StatusCode, http.StatusOK, b)
+	}
+	if got.Every == nil || got.Every.Duration != 5*time.Minute {
+		t.Errorf("got every %v, want 5m", got.Every)
+	}
+	if got.LastError != nil {
+		t.Errorf("got last error %q, want none", *got.LastError)
+	}
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+const (
+	bucketsIDSchemaMeasurementsPath   = "/api/v2/buckets/:id/schema/measurements"
+	bucketsIDSchemaMeasurementsIDPath = "/api/v2/buckets/:id/schema/measurements/:measurementID"
+)
+
+type measurementSchemaResponse struct {
+	*influxdb.MeasurementSchema
+	Links map[string]string `json:"links"`
+}
+
+func newMeasurementSchemaResponse(ms *influxdb.MeasurementSchema) *measurementSchemaResponse {
+	return &measurementSchemaResponse{
+		MeasurementSchema: ms,
+		Links: map[string]string{
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", ms.BucketID),
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/schema/measurements/%s", ms.BucketID, ms.ID),
+		},
+	}
+}
+
+type measurementSchemasResponse struct {
+	MeasurementSchemas []*measurementSchemaResponse `json:"measurementSchemas"`
+}
+
+type postMeasurementSchemaRequest struct {
+	Name    string                             `json:"name"`
+	Columns []influxdb.MeasurementSchemaColumn `json:"columns"`
+}
+
+// handleGetMeasurementSchemas is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements route.
+func (h *BucketHandler) handleGetMeasurementSchemas(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	filter := influxdb.MeasurementSchemaFilter{BucketID: &id}
+	if name := r.URL.Query().Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	ss, err := h.MeasurementSchemaService.FindMeasurementSchemas(r.Context(), filter)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schemas retrieved", zap.String("schemas", fmt.Sprint(ss)))
+
+	res := &measurementSchemasResponse{
+		MeasurementSchemas: make([]*measurementSchemaResponse, 0, len(ss)),
+	}
+	for _, ms := range ss {
+		res.MeasurementSchemas = append(res.MeasurementSchemas, newMeasurementSchemaResponse(ms))
+	}
+	h.api.Respond(w, http.StatusOK, res)
+}
+
+// handlePostMeasurementSchema is the HTTP handler for the POST /api/v2/buckets/:id/schema/measurements route.
+func (h *BucketHandler) handlePostMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	var req postMeasurementSchemaRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	b, err := h.BucketService.FindBucketByID(r.Context(), id)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	ms := &influxdb.MeasurementSchema{
+		OrgID:    b.OrgID,
+		BucketID: b.ID,
+		Name:     req.Name,
+		Columns:  req.Columns,
+	}
+	if err := h.MeasurementSchemaService.CreateMeasurementSchema(r.Context(), ms); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema created", zap.String("schema", fmt.Sprint(ms)))
+
+	h.api.Respond(w, http.StatusCreated, newMeasurementSchemaResponse(ms))
+}
+
+// handleGetMeasurementSchema is the HTTP handler for the GET /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handleGetMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ms, err := h.findBucketMeasurementSchema(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema retrieved", zap.String("schema", fmt.Sprint(ms)))
+
+	h.api.Respond(w, http.StatusOK, newMeasurementSchemaResponse(ms))
+}
+
+// handlePatchMeasurementSchema is the HTTP handler for the PATCH /api/v2/buckets/:id/schema/measurements/:measurementID route.
+func (h *BucketHandler) handlePatchMeasurementSchema(w http.ResponseWriter, r *http.Request) {
+	ms, err := h.findBucketMeasurementSchema(r)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	var upd influxdb.MeasurementSchemaUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	ms, err = h.MeasurementSchemaService.UpdateMeasurementSchema(r.Context(), ms.ID, upd)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+	h.log.Debug("Measurement schema updated", zap.String("schema", fmt.Sprint(ms)))
+
+	h.api.Respond(w, http.StatusOK, newMeasurementSchemaResponse(ms))
+}
+
+// findBucketMeasurementSchema returns the measurement schema of the request,
+// which must belong to the bucket of the request.
+func (h *BucketHandler) findBucketMeasurementSchema(r *http.Request) (*influxdb.MeasurementSchema, error) {
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		return nil, err
+	}
+	msID, err := decodeIDFromCtx(r.Context(), "measurementID")
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := h.MeasurementSchemaService.FindMeasurementSchemaByID(r.Context(), msID)
+	if err != nil {
+		return nil, err
+	}
+	if ms.BucketID != id {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrMeasurementSchemaNotFound,
+		}
+	}
+	return ms, nil
+}
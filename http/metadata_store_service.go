@@ -0,0 +1,150 @@
+package http
+
+import (
+	"encoding/json"
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixMetadataStore      = "/api/v2/metadata"
+	metadataStoreCompactPath = "/api/v2/metadata/compact"
+	metadataExportPath       = "/api/v2/metadata/export"
+	metadataImportPath       = "/api/v2/metadata/import"
+)
+
+// MetadataStoreBackend is all services and associated parameters required to
+// construct the MetadataStoreHandler.
+type MetadataStoreBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	MetadataStoreService  influxdb.MetadataStoreService
+	MetadataBundleService influxdb.MetadataBundleService
+}
+
+// NewMetadataStoreBackend returns a new instance of MetadataStoreBackend.
+func NewMetadataStoreBackend(log *zap.Logger, b *APIBackend) *MetadataStoreBackend {
+	return &MetadataStoreBackend{
+		log: log,
+
+		HTTPErrorHandler:      b.HTTPErrorHandler,
+		MetadataStoreService:  b.MetadataStoreService,
+		MetadataBundleService: b.MetadataBundleService,
+	}
+}
+
+// MetadataStoreHandler reports on and compacts the metadata store, and
+// exports and imports its metadata.
+type MetadataStoreHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	MetadataStoreService  influxdb.MetadataStoreService
+	MetadataBundleService influxdb.MetadataBundleService
+}
+
+// NewMetadataStoreHandler creates a new handler at /api/v2/metadata to report
+// on and compact the metadata store, and export and import its metadata.
+func NewMetadataStoreHandler(log *zap.Logger, b *MetadataStoreBackend) *MetadataStoreHandler {
+	h := &MetadataStoreHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		MetadataStoreService:  b.MetadataStoreService,
+		MetadataBundleService: b.MetadataBundleService,
+	}
+
+	h.HandlerFunc("GET", prefixMetadataStore, h.handleGetMetadataStore)
+	h.HandlerFunc("POST", metadataStoreCompactPath, h.handlePostCompact)
+	h.HandlerFunc("GET", metadataExportPath, h.handleGetExport)
+	h.HandlerFunc("POST", metadataImportPath, h.handlePostImport)
+	return h
+}
+
+func (h *MetadataStoreHandler) handleGetMetadataStore(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MetadataStoreHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	stats, err := h.MetadataStoreService.MetadataStoreStats(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, stats); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MetadataStoreHandler) handlePostCompact(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MetadataStoreHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	c, err := h.MetadataStoreService.CompactMetadataStore(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Metadata store compacted", zap.Int64("sizeBefore", c.Before.Size), zap.Int64("sizeAfter", c.After.Size))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, c); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MetadataStoreHandler) handleGetExport(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MetadataStoreHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	b, err := h.MetadataBundleService.ExportMetadata(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, b); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *MetadataStoreHandler) handlePostImport(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "MetadataStoreHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	b := &influxdb.MetadataBundle{}
+	if err := json.NewDecoder(r.Body).Decode(b); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode metadata bundle",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.MetadataBundleService.ImportMetadata(ctx, b); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Metadata imported", zap.Int("orgs", len(b.Organizations)), zap.Int("buckets", len(b.Buckets)))
+
+	w.WriteHeader(http.StatusNoContent)
+}
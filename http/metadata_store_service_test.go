@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMetadataStoreHandler(t *testing.T) {
+	stats := influxdb.MetadataStoreStats{Path: "influxd.bolt", Size: 1 << 20, FreePageRatio: 0.75}
+	compacted := influxdb.MetadataStoreStats{Path: "influxd.bolt", Size: 1 << 18}
+	bundle := influxdb.MetadataBundle{
+		Version:       influxdb.MetadataBundleVersion,
+		ExportedAt:    time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC),
+		Organizations: []*influxdb.Organization{{ID: influxdb.ID(1), Name: "org"}},
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		reqBody    string
+		err        error
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "get stats",
+			method:     "GET",
+			path:       prefixMetadataStore,
+			statusCode: http.StatusOK,
+			body:       `{"path": "influxd.bolt", "size": 1048576, "freePageRatio": 0.75}`,
+		},
+		{
+			name:       "compact",
+			method:     "POST",
+			path:       metadataStoreCompactPath,
+			statusCode: http.StatusOK,
+			body: `{
+  "before": {"path": "influxd.bolt", "size": 1048576, "freePageRatio": 0.75},
+  "after": {"path": "influxd.bolt", "size": 262144, "freePageRatio": 0},
+  "duration": 1000000000
+}`,
+		},
+		{
+			name:       "compact unauthorized",
+			method:     "POST",
+			path:       metadataStoreCompactPath,
+			err:        &influxdb.Error{Code: influxdb.EUnauthorized, Msg: "unauthorized"},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "export",
+			method:     "GET",
+			path:       metadataExportPath,
+			statusCode: http.StatusOK,
+			body: `{
+  "version": 1,
+  "exportedAt": "2019-12-01T00:00:00Z",
+  "orgs": [{"id": "0000000000000001", "name": "org", "description": "", "createdAt": "0001-01-01T00:00:00Z", "updatedAt": "0001-01-01T00:00:00Z"}],
+  "users": null,
+  "buckets": null,
+  "authorizations": null,
+  "dashboards": null,
+  "tasks": null,
+  "userResourceMappings": null
+}`,
+		},
+		{
+			name:       "import",
+			method:     "POST",
+			path:       metadataImportPath,
+			reqBody:    `{"version": 1, "orgs": [{"id": "0000000000000001", "name": "org"}]}`,
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "import invalid bundle",
+			method:     "POST",
+			path:       metadataImportPath,
+			reqBody:    `{"version": "one"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "import into store with organizations",
+			method:     "POST",
+			path:       metadataImportPath,
+			reqBody:    `{"version": 1}`,
+			err:        &influxdb.Error{Code: influxdb.EConflict, Msg: "metadata can only be imported into a store without organizations"},
+			statusCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &mock.MetadataStoreService{
+				MetadataStoreStatsF: func(ctx context.Context) (*influxdb.MetadataStoreStats, error) {
+					return &stats, tt.err
+				},
+				CompactMetadataStoreF: func(ctx context.Context) (*influxdb.MetadataCompaction, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &influxdb.MetadataCompaction{Before: stats, After: compacted, Duration: time.Second}, nil
+				},
+			}
+
+			bundleSvc := &mock.MetadataBundleService{
+				ExportMetadataF: func(ctx context.Context) (*influxdb.MetadataBundle, error) {
+					return &bundle, tt.err
+				},
+				ImportMetadataF: func(ctx context.Context, b *influxdb.MetadataBundle) error {
+					if tt.err != nil {
+						return tt.err
+					}
+					if b.Version != 1 || len(b.Organizations) != 1 || b.Organizations[0].Name != "org" {
+						t.Errorf("unexpected bundle imported: %+v", b)
+					}
+					return nil
+				},
+			}
+
+			h := NewMetadataStoreHandler(zaptest.NewLogger(t), &MetadataStoreBackend{
+				HTTPErrorHandler:      kithttp.ErrorHandler(0),
+				MetadataStoreService:  svc,
+				MetadataBundleService: bundleSvc,
+			})
+
+			r := httptest.NewRequest(tt.method, "http://any.tld"+tt.path, strings.NewReader(tt.reqBody))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("%s %s = %v, want %v: %s", tt.method, tt.path, res.StatusCode, tt.statusCode, body)
+			}
+			if tt.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.body); err != nil {
+					t.Errorf("%s %s. error unmarshaling json %v", tt.method, tt.path, err)
+				} else if !eq {
+					t.Errorf("%s %s = ***%s***", tt.method, tt.path, diff)
+				}
+			}
+		})
+	}
+}
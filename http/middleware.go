@@ -3,16 +3,27 @@ package http
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	"github.com/influxdata/influxdb/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"go.uber.org/zap"
 )
 
+// TraceDebugHeader requests that a request be traced in full, and the read
+// statistics of its queries recorded, regardless of the sampling
+// configuration. It is only honored for authorizations that can read all
+// resources.
+const TraceDebugHeader = "Influx-Trace-Debug"
+
 // LoggingMW middleware for logging inflight http requests.
 func LoggingMW(log *zap.Logger) kithttp.Middleware {
 	return func(next http.Handler) http.Handler {
@@ -67,6 +78,40 @@ func LoggingMW(log *zap.Logger) kithttp.Middleware {
 	}
 }
 
+// traceDebugMW marks requests with a true TraceDebugHeader to be traced in
+// full. It must wrap handlers of authenticated requests.
+func traceDebugMW(errorHandler influxdb.HTTPErrorHandler) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			v := r.Header.Get(TraceDebugHeader)
+			if v == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			debug, err := strconv.ParseBool(v)
+			if err != nil {
+				errorHandler.HandleHTTPError(ctx, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("%s header must be a boolean, got %q", TraceDebugHeader, v),
+				}, w)
+				return
+			} else if !debug {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if err := authorizer.IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+				errorHandler.HandleHTTPError(ctx, err, w)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(tracing.WithDebug(ctx)))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
 type isValidMethodFn func(method string) bool
 
 func mapURLPath(rawPath string) (isValidMethodFn, bool) {
@@ -12,6 +12,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/logger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -267,3 +271,70 @@ func TestLoggingMW(t *testing.T) {
 	})
 
 }
+
+func TestTraceDebugMW(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		auth       influxdb.Authorizer
+		statusCode int
+		debug      bool
+	}{
+		{
+			name:       "no header",
+			auth:       &influxdb.Authorization{Status: influxdb.Active},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "debug not requested",
+			header:     "false",
+			auth:       &influxdb.Authorization{Status: influxdb.Active},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:       "debug with read all permissions",
+			header:     "true",
+			auth:       &influxdb.Authorization{Status: influxdb.Active, Permissions: influxdb.ReadAllPermissions()},
+			statusCode: http.StatusNoContent,
+			debug:      true,
+		},
+		{
+			name:       "debug without read all permissions",
+			header:     "true",
+			auth:       &influxdb.Authorization{Status: influxdb.Active},
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid header",
+			header:     "please",
+			auth:       &influxdb.Authorization{Status: influxdb.Active, Permissions: influxdb.ReadAllPermissions()},
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var debug bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				debug = tracing.IsDebug(r.Context())
+				w.WriteHeader(http.StatusNoContent)
+			})
+			h := traceDebugMW(kithttp.ErrorHandler(0))(next)
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixQuery, nil)
+			if tt.header != "" {
+				r.Header.Set(TraceDebugHeader, tt.header)
+			}
+			r = r.WithContext(pcontext.SetAuthorizer(r.Context(), tt.auth))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got := w.Result().StatusCode; got != tt.statusCode {
+				t.Errorf("got status code %d, want %d", got, tt.statusCode)
+			}
+			if debug != tt.debug {
+				t.Errorf("got debug %v, want %v", debug, tt.debug)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+// networkPolicyMW rejects the requests made with the authorizations of
+// organizations from addresses the network policies of the organizations do
+// not allow with a 403. Rejected attempts are logged, and recorded in the
+// audit log if as is set. It must wrap handlers of authenticated requests.
+func networkPolicyMW(log *zap.Logger, errorHandler influxdb.HTTPErrorHandler, ps influxdb.NetworkPolicyService, as influxdb.AuditLogService) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			a, ok := authorizationFromContext(r)
+			if !ok || !a.OrgID.Valid() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			p, err := ps.FindNetworkPolicy(ctx, a.OrgID)
+			if err != nil {
+				errorHandler.HandleHTTPError(ctx, err, w)
+				return
+			}
+
+			addr := sourceIP(r)
+			if p.Allows(net.ParseIP(addr)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log.Warn("Rejected request from an address not allowed by the network policy of the organization",
+				zap.Stringer("orgID", a.OrgID),
+				zap.Stringer("authorizationID", a.ID),
+				zap.String("sourceIP", addr),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+			)
+			if as != nil {
+				e := &influxdb.AuditEvent{
+					UserID:         a.GetUserID(),
+					AuthorizerID:   a.ID,
+					AuthorizerKind: a.Kind(),
+					SourceIP:       addr,
+					Method:         r.Method,
+					Path:           r.URL.Path,
+					StatusCode:     http.StatusForbidden,
+				}
+				e.ResourceType, e.ResourceID = auditResource(r.URL.Path)
+				if err := as.RecordAuditEvent(ctx, e); err != nil {
+					log.Error("Unable to record audit event", zap.String("method", e.Method), zap.String("path", e.Path), zap.Error(err))
+				}
+			}
+
+			errorHandler.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  fmt.Sprintf("source address %s is not allowed by the network policy of organization %s", addr, a.OrgID),
+			}, w)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNetworkPolicyMW(t *testing.T) {
+	ps := mock.NewNetworkPolicyService()
+	ps.FindNetworkPolicyF = func(ctx context.Context, orgID influxdb.ID) (*influxdb.NetworkPolicy, error) {
+		if orgID != 1 {
+			return &influxdb.NetworkPolicy{OrgID: orgID}, nil
+		}
+		return &influxdb.NetworkPolicy{OrgID: orgID, Allow: []string{"192.0.2.0/24"}}, nil
+	}
+	var events []*influxdb.AuditEvent
+	as := mock.NewAuditLogService()
+	as.RecordAuditEventFn = func(ctx context.Context, e *influxdb.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := networkPolicyMW(zaptest.NewLogger(t), kithttp.ErrorHandler(0), ps, as)(next)
+
+	do := func(a influxdb.Authorizer, remoteAddr string) int {
+		r := httptest.NewRequest("GET", "/api/v2/buckets/0000000000000002", nil)
+		r.RemoteAddr = remoteAddr
+		r = r.WithContext(pctx.SetAuthorizer(r.Context(), a))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	a := &influxdb.Authorization{ID: 3, OrgID: 1, UserID: 4}
+	if code := do(a, "192.0.2.1:1234"); code != http.StatusNoContent {
+		t.Errorf("got status %d from an allowed address, want %d", code, http.StatusNoContent)
+	}
+	if code := do(&influxdb.Authorization{ID: 5, OrgID: 6}, "198.51.100.1:1234"); code != http.StatusNoContent {
+		t.Errorf("got status %d for an organization without a policy, want %d", code, http.StatusNoContent)
+	}
+	if code := do(&influxdb.Session{ID: 7, UserID: 4}, "198.51.100.1:1234"); code != http.StatusNoContent {
+		t.Errorf("got status %d for a session, want %d", code, http.StatusNoContent)
+	}
+	if len(events) != 0 {
+		t.Fatalf("got %d audit events for allowed requests, want none", len(events))
+	}
+
+	if code := do(a, "198.51.100.1:1234"); code != http.StatusForbidden {
+		t.Fatalf("got status %d from an address not allowed, want %d", code, http.StatusForbidden)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d audit events, want 1", len(events))
+	}
+	if e := events[0]; e.UserID != 4 || e.AuthorizerID != 3 || e.SourceIP != "198.51.100.1" ||
+		e.ResourceType != influxdb.BucketsResourceType || e.ResourceID != 2 || e.StatusCode != http.StatusForbidden {
+		t.Errorf("unexpected audit event %+v", e)
+	}
+}
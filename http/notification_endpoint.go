@@ -191,9 +191,13 @@ func newNotificationEndpointResponse(edp influxdb.NotificationEndpoint, labels [
 }
 
 func newNotificationEndpointsResponse(ctx context.Context, edps []influxdb.NotificationEndpoint, labelService influxdb.LabelService, f influxdb.PagingFilter, opts influxdb.FindOptions) *notificationEndpointsResponse {
+	ids := make([]influxdb.ID, len(edps))
+	for i, edp := range edps {
+		ids[i] = edp.GetID()
+	}
 	resp := &notificationEndpointsResponse{
 		NotificationEndpoints: make([]notificationEndpointResponse, len(edps)),
-		Links:                 newPagingLinks(prefixNotificationEndpoints, opts, f, len(edps)),
+		Links:                 newPagingLinks(prefixNotificationEndpoints, opts, f, ids),
 	}
 	for i, edp := range edps {
 		labels, _ := labelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: edp.GetID()})
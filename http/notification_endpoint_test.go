@@ -121,7 +121,7 @@ func TestService_handleGetNotificationEndpoints(t *testing.T) {
 		{
 		  "links": {
 		    "self": "/api/v2/notificationEndpoints?descending=false&limit=1&offset=0",
-		    "next": "/api/v2/notificationEndpoints?descending=false&limit=1&offset=1"
+		    "next": "/api/v2/notificationEndpoints?after=YzAxNzVmMDA3N2E3NzAwNQ&descending=false&limit=1"
 		  },
 		  "notificationEndpoints": [
 		   {
@@ -32,6 +32,7 @@ type NotificationRuleBackend struct {
 	UserService                 influxdb.UserService
 	OrganizationService         influxdb.OrganizationService
 	TaskService                 influxdb.TaskService
+	RevisionService             influxdb.RevisionService
 }
 
 // NewNotificationRuleBackend returns a new instance of NotificationRuleBackend.
@@ -47,6 +48,7 @@ func NewNotificationRuleBackend(log *zap.Logger, b *APIBackend) *NotificationRul
 		UserService:                 b.UserService,
 		OrganizationService:         b.OrganizationService,
 		TaskService:                 b.TaskService,
+		RevisionService:             b.RevisionService,
 	}
 }
 
@@ -63,6 +65,7 @@ type NotificationRuleHandler struct {
 	UserService                 influxdb.UserService
 	OrganizationService         influxdb.OrganizationService
 	TaskService                 influxdb.TaskService
+	RevisionService             influxdb.RevisionService
 }
 
 const (
@@ -91,6 +94,7 @@ func NewNotificationRuleHandler(log *zap.Logger, b *NotificationRuleBackend) *No
 		UserService:                 b.UserService,
 		OrganizationService:         b.OrganizationService,
 		TaskService:                 b.TaskService,
+		RevisionService:             b.RevisionService,
 	}
 	h.HandlerFunc("POST", prefixNotificationRules, h.handlePostNotificationRule)
 	h.HandlerFunc("GET", prefixNotificationRules, h.handleGetNotificationRules)
@@ -224,9 +228,13 @@ func (h *NotificationRuleHandler) newNotificationRuleResponse(ctx context.Contex
 }
 
 func (h *NotificationRuleHandler) newNotificationRulesResponse(ctx context.Context, nrs []influxdb.NotificationRule, labelService influxdb.LabelService, f influxdb.PagingFilter, opts influxdb.FindOptions) (*notificationRulesResponse, error) {
+	ids := make([]influxdb.ID, len(nrs))
+	for i, nr := range nrs {
+		ids[i] = nr.GetID()
+	}
 	resp := &notificationRulesResponse{
 		NotificationRules: []*notificationRuleResponse{},
-		Links:             newPagingLinks(prefixNotificationRules, opts, f, len(nrs)),
+		Links:             newPagingLinks(prefixNotificationRules, opts, f, ids),
 	}
 	for _, nr := range nrs {
 		labels, _ := labelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: nr.GetID()})
@@ -322,6 +330,11 @@ func (h *NotificationRuleHandler) handleGetNotificationRule(w http.ResponseWrite
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	rev, hasRev, err := findRevision(ctx, h.RevisionService, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
 	nr, err := h.NotificationRuleStore.FindNotificationRuleByID(ctx, id)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
@@ -341,6 +354,9 @@ func (h *NotificationRuleHandler) handleGetNotificationRule(w http.ResponseWrite
 		return
 	}
 
+	if hasRev {
+		setETag(w, rev)
+	}
 	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
 		logEncodingError(h.log, r, err)
 		return
@@ -624,11 +640,13 @@ func (h *NotificationRuleHandler) handlePutNotificationRule(w http.ResponseWrite
 		return
 	}
 
-	nr, err := h.NotificationRuleStore.UpdateNotificationRule(ctx, nrc.GetID(), nrc, auth.GetUserID())
+	m := decodeIfMatch(r)
+	nr, err := h.NotificationRuleStore.UpdateNotificationRule(m.context(ctx, nrc.GetID()), nrc.GetID(), nrc, auth.GetUserID())
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	m.setETag(w)
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: nr.GetID()})
 	if err != nil {
@@ -659,11 +677,13 @@ func (h *NotificationRuleHandler) handlePatchNotificationRule(w http.ResponseWri
 		return
 	}
 
-	nr, err := h.NotificationRuleStore.PatchNotificationRule(ctx, req.ID, req.Update)
+	m := decodeIfMatch(r)
+	nr, err := h.NotificationRuleStore.PatchNotificationRule(m.context(ctx, req.ID), req.ID, req.Update)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	m.setETag(w)
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: nr.GetID()})
 	if err != nil {
@@ -692,7 +712,7 @@ func (h *NotificationRuleHandler) handleDeleteNotificationRule(w http.ResponseWr
 		return
 	}
 
-	if err = h.NotificationRuleStore.DeleteNotificationRule(ctx, i); err != nil {
+	if err = h.NotificationRuleStore.DeleteNotificationRule(decodeIfMatch(r).context(ctx, i), i); err != nil {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
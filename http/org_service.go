@@ -23,6 +23,8 @@ type OrgBackend struct {
 
 	OrganizationService             influxdb.OrganizationService
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
+	OrgQuotaService                 influxdb.OrgQuotaService
+	NetworkPolicyService            influxdb.NetworkPolicyService
 	UserResourceMappingService      influxdb.UserResourceMappingService
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
@@ -37,6 +39,8 @@ func NewOrgBackend(log *zap.Logger, b *APIBackend) *OrgBackend {
 
 		OrganizationService:             b.OrganizationService,
 		OrganizationOperationLogService: b.OrganizationOperationLogService,
+		OrgQuotaService:                 b.OrgQuotaService,
+		NetworkPolicyService:            b.NetworkPolicyService,
 		UserResourceMappingService:      b.UserResourceMappingService,
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
@@ -52,6 +56,8 @@ type OrgHandler struct {
 
 	OrgSVC                          influxdb.OrganizationService
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
+	OrgQuotaService                 influxdb.OrgQuotaService
+	NetworkPolicyService            influxdb.NetworkPolicyService
 	UserResourceMappingService      influxdb.UserResourceMappingService
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
@@ -62,6 +68,8 @@ const (
 	prefixOrganizations          = "/api/v2/orgs"
 	organizationsIDPath          = "/api/v2/orgs/:id"
 	organizationsIDLogPath       = "/api/v2/orgs/:id/logs"
+	organizationsIDQuotaPath     = "/api/v2/orgs/:id/quota"
+	organizationsIDNetworkPath   = "/api/v2/orgs/:id/networkPolicy"
 	organizationsIDMembersPath   = "/api/v2/orgs/:id/members"
 	organizationsIDMembersIDPath = "/api/v2/orgs/:id/members/:userID"
 	organizationsIDOwnersPath    = "/api/v2/orgs/:id/owners"
@@ -103,6 +111,8 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 
 		OrgSVC:                          b.OrganizationService,
 		OrganizationOperationLogService: b.OrganizationOperationLogService,
+		OrgQuotaService:                 b.OrgQuotaService,
+		NetworkPolicyService:            b.NetworkPolicyService,
 		UserResourceMappingService:      b.UserResourceMappingService,
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
@@ -115,6 +125,12 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 	h.HandlerFunc("GET", organizationsIDLogPath, h.handleGetOrgLog)
 	h.HandlerFunc("PATCH", organizationsIDPath, h.handlePatchOrg)
 	h.HandlerFunc("DELETE", organizationsIDPath, h.handleDeleteOrg)
+	h.HandlerFunc("GET", organizationsIDQuotaPath, h.handleGetOrgQuota)
+	h.HandlerFunc("PUT", organizationsIDQuotaPath, h.handlePutOrgQuota)
+	h.HandlerFunc("DELETE", organizationsIDQuotaPath, h.handleDeleteOrgQuota)
+	h.HandlerFunc("GET", organizationsIDNetworkPath, h.handleGetNetworkPolicy)
+	h.HandlerFunc("PUT", organizationsIDNetworkPath, h.handlePutNetworkPolicy)
+	h.HandlerFunc("DELETE", organizationsIDNetworkPath, h.handleDeleteNetworkPolicy)
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -192,15 +208,17 @@ type orgResponse struct {
 func newOrgResponse(o influxdb.Organization) orgResponse {
 	return orgResponse{
 		Links: map[string]string{
-			"self":       fmt.Sprintf("/api/v2/orgs/%s", o.ID),
-			"logs":       fmt.Sprintf("/api/v2/orgs/%s/logs", o.ID),
-			"members":    fmt.Sprintf("/api/v2/orgs/%s/members", o.ID),
-			"owners":     fmt.Sprintf("/api/v2/orgs/%s/owners", o.ID),
-			"secrets":    fmt.Sprintf("/api/v2/orgs/%s/secrets", o.ID),
-			"labels":     fmt.Sprintf("/api/v2/orgs/%s/labels", o.ID),
-			"buckets":    fmt.Sprintf("/api/v2/buckets?org=%s", o.Name),
-			"tasks":      fmt.Sprintf("/api/v2/tasks?org=%s", o.Name),
-			"dashboards": fmt.Sprintf("/api/v2/dashboards?org=%s", o.Name),
+			"self":          fmt.Sprintf("/api/v2/orgs/%s", o.ID),
+			"logs":          fmt.Sprintf("/api/v2/orgs/%s/logs", o.ID),
+			"quota":         fmt.Sprintf("/api/v2/orgs/%s/quota", o.ID),
+			"networkPolicy": fmt.Sprintf("/api/v2/orgs/%s/networkPolicy", o.ID),
+			"members":       fmt.Sprintf("/api/v2/orgs/%s/members", o.ID),
+			"owners":        fmt.Sprintf("/api/v2/orgs/%s/owners", o.ID),
+			"secrets":       fmt.Sprintf("/api/v2/orgs/%s/secrets", o.ID),
+			"labels":        fmt.Sprintf("/api/v2/orgs/%s/labels", o.ID),
+			"buckets":       fmt.Sprintf("/api/v2/buckets?org=%s", o.Name),
+			"tasks":         fmt.Sprintf("/api/v2/tasks?org=%s", o.Name),
+			"dashboards":    fmt.Sprintf("/api/v2/dashboards?org=%s", o.Name),
 		},
 		Organization: o,
 	}
@@ -309,6 +327,150 @@ func (h *OrgHandler) handlePatchOrg(w http.ResponseWriter, r *http.Request) {
 	h.API.Respond(w, http.StatusOK, newOrgResponse(*org))
 }
 
+type orgQuotaResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.OrgQuota
+}
+
+func newOrgQuotaResponse(q *influxdb.OrgQuota) *orgQuotaResponse {
+	return &orgQuotaResponse{
+		Links: map[string]string{
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", q.OrgID),
+			"self": fmt.Sprintf("/api/v2/orgs/%s/quota", q.OrgID),
+		},
+		OrgQuota: *q,
+	}
+}
+
+// handleGetOrgQuota is the HTTP handler for the GET /api/v2/orgs/:id/quota route.
+func (h *OrgHandler) handleGetOrgQuota(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	q, err := h.OrgQuotaService.FindOrgQuota(r.Context(), orgID)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newOrgQuotaResponse(q))
+}
+
+// handlePutOrgQuota is the HTTP handler for the PUT /api/v2/orgs/:id/quota route.
+func (h *OrgHandler) handlePutOrgQuota(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	var q influxdb.OrgQuota
+	if err := h.API.DecodeJSON(r.Body, &q); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	q.OrgID = orgID
+
+	if err := h.OrgQuotaService.SetOrgQuota(r.Context(), &q); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Org quota updated", zap.String("quota", fmt.Sprint(q)))
+
+	h.API.Respond(w, http.StatusOK, newOrgQuotaResponse(&q))
+}
+
+// handleDeleteOrgQuota is the HTTP handler for the DELETE /api/v2/orgs/:id/quota route.
+func (h *OrgHandler) handleDeleteOrgQuota(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.OrgQuotaService.DeleteOrgQuota(r.Context(), orgID); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusNoContent, nil)
+}
+
+type networkPolicyResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.NetworkPolicy
+}
+
+func newNetworkPolicyResponse(p *influxdb.NetworkPolicy) *networkPolicyResponse {
+	return &networkPolicyResponse{
+		Links: map[string]string{
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", p.OrgID),
+			"self": fmt.Sprintf("/api/v2/orgs/%s/networkPolicy", p.OrgID),
+		},
+		NetworkPolicy: *p,
+	}
+}
+
+// handleGetNetworkPolicy is the HTTP handler for the GET /api/v2/orgs/:id/networkPolicy route.
+func (h *OrgHandler) handleGetNetworkPolicy(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	p, err := h.NetworkPolicyService.FindNetworkPolicy(r.Context(), orgID)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newNetworkPolicyResponse(p))
+}
+
+// handlePutNetworkPolicy is the HTTP handler for the PUT /api/v2/orgs/:id/networkPolicy route.
+func (h *OrgHandler) handlePutNetworkPolicy(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	var p influxdb.NetworkPolicy
+	if err := h.API.DecodeJSON(r.Body, &p); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	p.OrgID = orgID
+
+	if err := h.NetworkPolicyService.SetNetworkPolicy(r.Context(), &p); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	h.log.Debug("Org network policy updated", zap.String("policy", fmt.Sprint(p)))
+
+	h.API.Respond(w, http.StatusOK, newNetworkPolicyResponse(&p))
+}
+
+// handleDeleteNetworkPolicy is the HTTP handler for the DELETE /api/v2/orgs/:id/networkPolicy route.
+func (h *OrgHandler) handleDeleteNetworkPolicy(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	if err := h.NetworkPolicyService.DeleteNetworkPolicy(r.Context(), orgID); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusNoContent, nil)
+}
+
 type secretsResponse struct {
 	Links   map[string]string `json:"links"`
 	Secrets []string          `json:"secrets"`
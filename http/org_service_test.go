@@ -26,6 +26,8 @@ func NewMockOrgBackend(t *testing.T) *OrgBackend {
 
 		OrganizationService:             mock.NewOrganizationService(),
 		OrganizationOperationLogService: mock.NewOrganizationOperationLogService(),
+		OrgQuotaService:                 mock.NewOrgQuotaService(),
+		NetworkPolicyService:            mock.NewNetworkPolicyService(),
 		UserResourceMappingService:      mock.NewUserResourceMappingService(),
 		SecretService:                   mock.NewSecretService(),
 		LabelService:                    mock.NewLabelService(),
@@ -108,6 +110,88 @@ func TestSecretService(t *testing.T) {
 	influxdbtesting.PatchSecrets(initSecretService, t)
 }
 
+func TestOrgQuotaService_handlePutOrgQuota(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		err   error
+		wants wants
+	}{
+		{
+			name: "set quota",
+			body: `{"orgID": "0000000000000002", "maxBuckets": 2, "maxStorageBytes": 1024}`,
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/0000000000000001",
+    "self": "/api/v2/orgs/0000000000000001/quota"
+  },
+  "orgID": "0000000000000001",
+  "maxBuckets": 2,
+  "maxStorageBytes": 1024
+}
+`,
+			},
+		},
+		{
+			name: "invalid quota",
+			body: `{"maxSeries": -1}`,
+			err: &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "quota limits must not be negative",
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *influxdb.OrgQuota
+			svc := mock.NewOrgQuotaService()
+			svc.SetOrgQuotaF = func(ctx context.Context, q *influxdb.OrgQuota) error {
+				got = q
+				return tt.err
+			}
+
+			orgBackend := NewMockOrgBackend(t)
+			orgBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			orgBackend.OrgQuotaService = svc
+			h := NewOrgHandler(zaptest.NewLogger(t), orgBackend)
+
+			r := httptest.NewRequest("PUT", "http://any.url/api/v2/orgs/0000000000000001/quota", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handlePutOrgQuota() = %v, want %v", res.StatusCode, tt.wants.statusCode)
+			}
+			if got == nil || got.OrgID != 1 {
+				t.Errorf("handlePutOrgQuota() set quota %+v, want quota of org 0000000000000001", got)
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("%q, handlePutOrgQuota(). error unmarshaling json %v", tt.name, err)
+				} else if !eq {
+					t.Errorf("%q. handlePutOrgQuota() = ***%s***", tt.name, diff)
+				}
+			}
+		})
+	}
+}
+
 func TestSecretService_handleGetSecrets(t *testing.T) {
 	type fields struct {
 		SecretService influxdb.SecretService
@@ -26,6 +26,17 @@ func decodeFindOptions(r *http.Request) (*platform.FindOptions, error) {
 		opts.Offset = o
 	}
 
+	if after := qp.Get("after"); after != "" {
+		if _, err := platform.ParsePageToken(after); err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "after is invalid",
+			}
+		}
+
+		opts.After = after
+	}
+
 	if limit := qp.Get("limit"); limit != "" {
 		l, err := strconv.Atoi(limit)
 		if err != nil {
@@ -79,8 +90,11 @@ func findOptionParams(opts ...platform.FindOptions) [][2]string {
 }
 
 // newPagingLinks returns a PagingLinks.
-// num is the number of returned results.
-func newPagingLinks(basePath string, opts platform.FindOptions, f platform.PagingFilter, num int) *platform.PagingLinks {
+// ids are the IDs of the returned results. The next link continues after the
+// last of them in the order results are listed, whatever order they are
+// returned in, so following it neither skips nor repeats results as others are
+// created or deleted. There is no prev link once a listing is continued.
+func newPagingLinks(basePath string, opts platform.FindOptions, f platform.PagingFilter, ids []platform.ID) *platform.PagingLinks {
 	u := url.URL{
 		Path: basePath,
 	}
@@ -106,14 +120,7 @@ func newPagingLinks(basePath string, opts platform.FindOptions, f platform.Pagin
 	u.RawQuery = values.Encode()
 	self = u.String()
 
-	if num >= opts.Limit {
-		nextOffset := opts.Offset + opts.Limit
-		values.Set("offset", strconv.Itoa(nextOffset))
-		u.RawQuery = values.Encode()
-		next = u.String()
-	}
-
-	if opts.Offset > 0 {
+	if opts.After == "" && opts.Offset > 0 {
 		prevOffset := opts.Offset - opts.Limit
 		if prevOffset < 0 {
 			prevOffset = 0
@@ -123,6 +130,13 @@ func newPagingLinks(basePath string, opts platform.FindOptions, f platform.Pagin
 		prev = u.String()
 	}
 
+	if len(ids) > 0 && len(ids) >= opts.Limit {
+		values.Del("offset")
+		values.Set("after", platform.PageToken(lastListed(opts, ids)))
+		u.RawQuery = values.Encode()
+		next = u.String()
+	}
+
 	links := &platform.PagingLinks{
 		Prev: prev,
 		Self: self,
@@ -131,3 +145,15 @@ func newPagingLinks(basePath string, opts platform.FindOptions, f platform.Pagin
 
 	return links
 }
+
+// lastListed returns the last of ids in the order results are listed: the
+// greatest, or the least when listing in descending order.
+func lastListed(opts platform.FindOptions, ids []platform.ID) platform.ID {
+	last := ids[0]
+	for _, id := range ids[1:] {
+		if opts.Descending == (id < last) {
+			last = id
+		}
+	}
+	return last
+}
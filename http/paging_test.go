@@ -40,6 +40,21 @@ func TestPaging_decodeFindOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "decode FindOptions with continuation token",
+			args: args{
+				map[string]string{
+					"after": "MDAwMDAwMDAwMDAwMDAwMQ",
+					"limit": "10",
+				},
+			},
+			wants: wants{
+				opts: platform.FindOptions{
+					After: "MDAwMDAwMDAwMDAwMDAwMQ",
+					Limit: 10,
+				},
+			},
+		},
 		{
 			name: "decode FindOptions with default values",
 			args: args{
@@ -75,6 +90,9 @@ func TestPaging_decodeFindOptions(t *testing.T) {
 			if opts.Offset != tt.wants.opts.Offset {
 				t.Errorf("%q. decodeFindOptions() = %v, want %v", tt.name, opts.Offset, tt.wants.opts.Offset)
 			}
+			if opts.After != tt.wants.opts.After {
+				t.Errorf("%q. decodeFindOptions() = %v, want %v", tt.name, opts.After, tt.wants.opts.After)
+			}
 			if opts.Limit != tt.wants.opts.Limit {
 				t.Errorf("%q. decodeFindOptions() = %v, want %v", tt.name, opts.Limit, tt.wants.opts.Limit)
 			}
@@ -91,7 +109,7 @@ func TestPaging_decodeFindOptions(t *testing.T) {
 func TestPaging_newPagingLinks(t *testing.T) {
 	type args struct {
 		basePath string
-		num      int
+		ids      []platform.ID
 		opts     platform.FindOptions
 		filter   mock.PagingFilter
 	}
@@ -108,7 +126,7 @@ func TestPaging_newPagingLinks(t *testing.T) {
 			name: "new PagingLinks",
 			args: args{
 				basePath: "/api/v2/buckets",
-				num:      50,
+				ids:      pageIDs(10),
 				opts: platform.FindOptions{
 					Offset:     10,
 					Limit:      10,
@@ -123,7 +141,7 @@ func TestPaging_newPagingLinks(t *testing.T) {
 				links: platform.PagingLinks{
 					Prev: "/api/v2/buckets?descending=true&limit=10&name=name&offset=0&type=type1&type=type2",
 					Self: "/api/v2/buckets?descending=true&limit=10&name=name&offset=10&type=type1&type=type2",
-					Next: "/api/v2/buckets?descending=true&limit=10&name=name&offset=20&type=type1&type=type2",
+					Next: "/api/v2/buckets?after=MDAwMDAwMDAwMDAwMDAwMQ&descending=true&limit=10&name=name&type=type1&type=type2",
 				},
 			},
 		},
@@ -131,7 +149,7 @@ func TestPaging_newPagingLinks(t *testing.T) {
 			name: "new PagingLinks with empty prev link",
 			args: args{
 				basePath: "/api/v2/buckets",
-				num:      50,
+				ids:      pageIDs(10),
 				opts: platform.FindOptions{
 					Offset:     0,
 					Limit:      10,
@@ -146,7 +164,7 @@ func TestPaging_newPagingLinks(t *testing.T) {
 				links: platform.PagingLinks{
 					Prev: "",
 					Self: "/api/v2/buckets?descending=true&limit=10&name=name&offset=0&type=type1&type=type2",
-					Next: "/api/v2/buckets?descending=true&limit=10&name=name&offset=10&type=type1&type=type2",
+					Next: "/api/v2/buckets?after=MDAwMDAwMDAwMDAwMDAwMQ&descending=true&limit=10&name=name&type=type1&type=type2",
 				},
 			},
 		},
@@ -154,7 +172,7 @@ func TestPaging_newPagingLinks(t *testing.T) {
 			name: "new PagingLinks with empty next link",
 			args: args{
 				basePath: "/api/v2/buckets",
-				num:      5,
+				ids:      pageIDs(5),
 				opts: platform.FindOptions{
 					Offset:     10,
 					Limit:      10,
@@ -173,11 +191,32 @@ func TestPaging_newPagingLinks(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "new PagingLinks continuing a listing",
+			args: args{
+				basePath: "/api/v2/buckets",
+				ids:      []platform.ID{3, 1, 2},
+				opts: platform.FindOptions{
+					After: platform.PageToken(0xff),
+					Limit: 3,
+				},
+				filter: mock.PagingFilter{
+					Name: "name",
+				},
+			},
+			wants: wants{
+				links: platform.PagingLinks{
+					Prev: "",
+					Self: "/api/v2/buckets?after=MDAwMDAwMDAwMDAwMDBmZg&descending=false&limit=3&name=name",
+					Next: "/api/v2/buckets?after=MDAwMDAwMDAwMDAwMDAwMw&descending=false&limit=3&name=name",
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			links := newPagingLinks(tt.args.basePath, tt.args.opts, tt.args.filter, tt.args.num)
+			links := newPagingLinks(tt.args.basePath, tt.args.opts, tt.args.filter, tt.args.ids)
 
 			if links.Prev != tt.wants.links.Prev {
 				t.Errorf("%q. newPagingLinks() = %v, want %v", tt.name, links.Prev, tt.wants.links.Prev)
@@ -193,3 +232,12 @@ func TestPaging_newPagingLinks(t *testing.T) {
 		})
 	}
 }
+
+// pageIDs returns the IDs 1 to n.
+func pageIDs(n int) []platform.ID {
+	ids := make([]platform.ID, n)
+	for i := range ids {
+		ids[i] = platform.ID(i + 1)
+	}
+	return ids
+}
@@ -19,8 +19,11 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h := NewAuthenticationHandler(b.Logger, b.HTTPErrorHandler)
 	h.Handler = NewAPIHandler(b, opts...)
 	h.AuthorizationService = b.AuthorizationService
+	h.AuthorizationLifecycleService = b.AuthorizationLifecycleService
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
+	h.SessionIdleTimeout = b.SessionIdleTimeout
+	h.SignedRequestVerifier = b.SignedRequestVerifier
 	h.UserService = b.UserService
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
@@ -30,6 +33,13 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
 
+	h.RegisterLegacyAuthRoute("POST", prefixLegacyWrite)
+	h.RegisterLegacyAuthRoute("GET", prefixLegacyQuery)
+	h.RegisterLegacyAuthRoute("POST", prefixLegacyQuery)
+
+	h.RegisterSignedRoute("POST", prefixWrite)
+	h.RegisterSignedRoute("POST", prefixLegacyWrite)
+
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
 
@@ -53,6 +63,8 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Serve the chronograf assets for any basepath that does not start with addressable parts
 	// of the platform API.
 	if !strings.HasPrefix(r.URL.Path, "/v1") &&
+		r.URL.Path != prefixLegacyWrite &&
+		r.URL.Path != prefixLegacyQuery &&
 		!strings.HasPrefix(r.URL.Path, "/api/v2") &&
 		!strings.HasPrefix(r.URL.Path, "/chronograf/") {
 		h.AssetHandler.ServeHTTP(w, r)
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -38,6 +39,13 @@ type QueryRequest struct {
 	AST     *ast.Package `json:"ast,omitempty"`
 	Dialect QueryDialect `json:"dialect"`
 
+	// Params are bound to the record params in flux queries.
+	Params QueryParams `json:"params,omitempty"`
+
+	// Priority is the class of the query, which decides how it is admitted
+	// for execution while queries are waiting.
+	Priority query.Priority `json:"priority,omitempty"`
+
 	// InfluxQL fields
 	Bucket string `json:"bucket,omitempty"`
 
@@ -59,8 +67,20 @@ type QueryRequest struct {
 	// To obtain a QueryRequest with no result but runtime errors,
 	// add the header `Prefer: return-no-content-with-error` to the HTTP request.
 	PreferNoContentWithError bool
+
+	// Accept is the media type the results of a flux query are encoded as.
+	// It is negotiated from the Accept header of the HTTP request. Results
+	// are encoded as annotated CSV if it is empty.
+	Accept string `json:"-"`
 }
 
+// The media types the results of flux queries can be encoded as.
+const (
+	queryMediaTypeCSV   = "text/csv"
+	queryMediaTypeJSON  = "application/json"
+	queryMediaTypeArrow = "application/vnd.apache.arrow.stream"
+)
+
 // QueryDialect is the formatting options for the query response.
 type QueryDialect struct {
 	Header         *bool    `json:"header"`
@@ -68,6 +88,11 @@ type QueryDialect struct {
 	CommentPrefix  string   `json:"commentPrefix"`
 	DateTimeFormat string   `json:"dateTimeFormat"`
 	Annotations    []string `json:"annotations"`
+
+	// IntegersAsStrings encodes integer and unsigned integer values as
+	// strings, so that clients decoding numbers as 64-bit floats, such as
+	// JavaScript, do not lose precision.
+	IntegersAsStrings bool `json:"integersAsStrings,omitempty"`
 }
 
 // WithDefaults adds default values to the request.
@@ -107,6 +132,13 @@ func (r QueryRequest) Validate() error {
 		return fmt.Errorf(`unknown query type: %s`, r.Type)
 	}
 
+	if len(r.Params) > 0 && (r.Type != "flux" || r.Spec != nil) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "params are only supported by flux queries and ASTs",
+		}
+	}
+
 	if r.Type == "influxql" && r.Bucket == "" {
 		return fmt.Errorf("bucket parameter is required for influxql queries")
 	}
@@ -252,6 +284,14 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	if err := r.Validate(); err != nil {
 		return nil, err
 	}
+	extern := r.Extern
+	if len(r.Params) > 0 {
+		var err error
+		if extern, err = r.Params.externWithParams(r.Extern); err != nil {
+			return nil, err
+		}
+	}
+
 	// Query is preferred over AST
 	var compiler flux.Compiler
 	if r.Query != "" {
@@ -268,7 +308,7 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		default:
 			compiler = lang.FluxCompiler{
 				Now:    now(),
-				Extern: r.Extern,
+				Extern: extern,
 				Query:  r.Query,
 			}
 		}
@@ -277,8 +317,8 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 			AST: r.AST,
 			Now: now(),
 		}
-		if r.Extern != nil {
-			c.PrependFile(r.Extern)
+		if extern != nil {
+			c.PrependFile(extern)
 		}
 		compiler = c
 	} else if r.Spec != nil {
@@ -300,7 +340,9 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	} else {
 		if r.Type == "influxql" {
 			// Use default transpiler dialect
-			dialect = &transpiler.Dialect{}
+			dialect = &transpiler.Dialect{
+				IntegersAsStrings: r.Dialect.IntegersAsStrings,
+			}
 		} else {
 			// TODO(nathanielc): Use commentPrefix and dateTimeFormat
 			// once they are supported.
@@ -313,6 +355,16 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 				dialect = &query.NoContentWithErrorDialect{
 					ResultEncoderConfig: encConfig,
 				}
+			} else if r.Accept == queryMediaTypeJSON {
+				dialect = &query.JSONDialect{
+					IntegersAsStrings: r.Dialect.IntegersAsStrings,
+				}
+			} else if r.Accept == queryMediaTypeArrow {
+				dialect = &query.ArrowDialect{}
+			} else if r.Dialect.IntegersAsStrings {
+				dialect = &query.StringIntegersCSVDialect{
+					ResultEncoderConfig: encConfig,
+				}
 			} else {
 				dialect = &csv.Dialect{
 					ResultEncoderConfig: encConfig,
@@ -325,6 +377,7 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		Request: query.Request{
 			OrganizationID: r.Org.ID,
 			Compiler:       compiler,
+			Priority:       r.Priority,
 		},
 		Dialect: dialect,
 	}, nil
@@ -333,7 +386,7 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 // QueryRequestFromProxyRequest converts a query.ProxyRequest into a QueryRequest.
 // The ProxyRequest must contain supported compilers and dialects otherwise an error occurs.
 func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error) {
-	qr := new(QueryRequest)
+	qr := &QueryRequest{Priority: req.Request.Priority}
 	switch c := req.Request.Compiler.(type) {
 	case lang.FluxCompiler:
 		qr.Type = "flux"
@@ -356,6 +409,19 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 		qr.Dialect.CommentPrefix = "#"
 		qr.Dialect.DateTimeFormat = "RFC3339"
 		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+	case *query.StringIntegersCSVDialect:
+		var header = !d.ResultEncoderConfig.NoHeader
+		qr.Dialect.Header = &header
+		qr.Dialect.Delimiter = string(d.ResultEncoderConfig.Delimiter)
+		qr.Dialect.CommentPrefix = "#"
+		qr.Dialect.DateTimeFormat = "RFC3339"
+		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+		qr.Dialect.IntegersAsStrings = true
+	case *query.JSONDialect:
+		qr.Accept = queryMediaTypeJSON
+		qr.Dialect.IntegersAsStrings = d.IntegersAsStrings
+	case *query.ArrowDialect:
+		qr.Accept = queryMediaTypeArrow
 	case *query.NoContentDialect:
 		qr.PreferNoContent = true
 	case *query.NoContentWithErrorDialect:
@@ -399,6 +465,7 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 	case query.PreferNoContentWErrHeaderValue:
 		req.PreferNoContentWithError = true
 	}
+	req.Accept = negotiateQueryMediaType(r.Header.Get("Accept"))
 
 	req = req.WithDefaults()
 	if err := req.Validate(); err != nil {
@@ -409,6 +476,36 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 	return &req, body.bytesRead, err
 }
 
+// negotiateQueryMediaType returns the media type of the results of a flux
+// query most preferred by an Accept header, or an empty string if the header
+// prefers none of them.
+func negotiateQueryMediaType(accept string) string {
+	var mediaType string
+	var quality float64
+	for _, s := range strings.Split(accept, ",") {
+		mt, params, err := mime.ParseMediaType(s)
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case queryMediaTypeCSV, queryMediaTypeJSON, queryMediaTypeArrow:
+		default:
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > quality {
+			mediaType, quality = mt, q
+		}
+	}
+	return mediaType
+}
+
 type countReader struct {
 	bytesRead int
 	io.Reader
@@ -431,18 +528,26 @@ func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb
 		return nil, n, err
 	}
 
-	var token *influxdb.Authorization
+	token, err := queryAuthorization(auth, req.Org.ID)
+	if err != nil {
+		return pr, n, err
+	}
+
+	pr.Request.Authorization = token
+	return pr, n, nil
+}
+
+// queryAuthorization returns the authorization queries of the organization
+// with orgID are run with on behalf of auth.
+func queryAuthorization(auth influxdb.Authorizer, orgID influxdb.ID) (*influxdb.Authorization, error) {
 	switch a := auth.(type) {
 	case *influxdb.Authorization:
-		token = a
+		return a, nil
 	case *influxdb.Session:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	case *jsonweb.Token:
-		token = a.EphemeralAuth(req.Org.ID)
+		return a.EphemeralAuth(orgID), nil
 	default:
-		return pr, n, influxdb.ErrAuthorizerNotSupported
+		return nil, influxdb.ErrAuthorizerNotSupported
 	}
-
-	pr.Request.Authorization = token
-	return pr, n, nil
 }
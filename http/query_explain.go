@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+)
+
+const (
+	prefixExplainQuery = "/api/v2/query/explain"
+	prefixProfileQuery = "/api/v2/query/profile"
+)
+
+// handleExplainQuery returns the physical plan of a query without executing
+// it, including the operations pushed down into its storage reads.
+func (h *FluxHandler) handleExplainQuery(w http.ResponseWriter, r *http.Request) {
+	const op = "http/handleExplainQuery"
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	pr, err := h.decodeExplainQueryRequest(ctx, r, op)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, pr.Request.Authorization)
+
+	e, err := h.QueryExplainer.Explain(ctx, &pr.Request)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, e); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleProfileQuery executes a query, discarding its results, and returns
+// its plan along with the time it spent in each phase and the data each of
+// its storage reads scanned.
+func (h *FluxHandler) handleProfileQuery(w http.ResponseWriter, r *http.Request) {
+	const op = "http/handleProfileQuery"
+	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	pr, err := h.decodeExplainQueryRequest(ctx, r, op)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, pr.Request.Authorization)
+
+	e, err := h.QueryExplainer.Explain(ctx, &pr.Request)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	// The results are discarded, but errors reading them fail the request.
+	pr.Dialect = query.NewNoContentWithErrorDialect()
+	stats, err := h.ProxyQueryService.Query(ctx, ioutil.Discard, pr)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, query.NewProfile(e, stats)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodeExplainQueryRequest decodes a query request to explain or profile,
+// authorized as the query would be.
+func (h *FluxHandler) decodeExplainQueryRequest(ctx context.Context, r *http.Request, op string) (*query.ProxyRequest, error) {
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Op:   op,
+			Err:  err,
+		}
+	}
+
+	req, _, err := decodeQueryRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request body",
+			Op:   op,
+			Err:  err,
+		}
+	}
+	token, err := queryAuthorization(a, req.Org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, err := req.proxyRequest(h.Now)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to compile query",
+			Op:   op,
+			Err:  err,
+		}
+	}
+	pr.Request.Authorization = token
+	pr.Request.Source = r.Header.Get("User-Agent")
+	return pr, nil
+}
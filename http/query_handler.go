@@ -48,6 +48,11 @@ type FluxBackend struct {
 
 	OrganizationService influxdb.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+	QueryExplainer      query.Explainer
+
+	// DrainGate, if set, refuses the runs of live queries while the server
+	// is draining.
+	DrainGate DrainGate
 }
 
 // NewFluxBackend returns a new instance of FluxBackend.
@@ -62,6 +67,8 @@ func NewFluxBackend(log *zap.Logger, b *APIBackend) *FluxBackend {
 			DefaultService:  b.FluxService,
 		},
 		OrganizationService: b.OrganizationService,
+		QueryExplainer:      b.QueryExplainer,
+		DrainGate:           b.DrainGate,
 	}
 }
 
@@ -79,6 +86,8 @@ type FluxHandler struct {
 	Now                 func() time.Time
 	OrganizationService influxdb.OrganizationService
 	ProxyQueryService   query.ProxyQueryService
+	QueryExplainer      query.Explainer
+	DrainGate           DrainGate
 
 	EventRecorder metric.EventRecorder
 }
@@ -98,12 +107,17 @@ func NewFluxHandler(log *zap.Logger, b *FluxBackend) *FluxHandler {
 
 		ProxyQueryService:   b.ProxyQueryService,
 		OrganizationService: b.OrganizationService,
+		QueryExplainer:      b.QueryExplainer,
 		EventRecorder:       b.QueryEventRecorder,
+		DrainGate:           b.DrainGate,
 	}
 
 	// query reponses can optionally be gzip encoded
 	qh := gziphandler.GzipHandler(http.HandlerFunc(h.handleQuery))
 	h.Handler("POST", prefixQuery, qh)
+	h.HandlerFunc("POST", prefixLiveQuery, h.handleLiveQuery)
+	h.HandlerFunc("POST", prefixExplainQuery, h.handleExplainQuery)
+	h.HandlerFunc("POST", prefixProfileQuery, h.handleProfileQuery)
 	h.HandlerFunc("POST", "/api/v2/query/ast", h.postFluxAST)
 	h.HandlerFunc("POST", "/api/v2/query/analyze", h.postQueryAnalyze)
 	h.HandlerFunc("GET", "/api/v2/query/suggestions", h.getFluxSuggestions)
@@ -181,7 +195,11 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	hd.SetHeaders(w)
 
 	cw := iocounter.Writer{Writer: w}
-	if _, err := h.ProxyQueryService.Query(ctx, &cw, req); err != nil {
+	stats, err := h.ProxyQueryService.Query(ctx, &cw, req)
+	if tracing.IsDebug(ctx) {
+		logQueryStatistics(log, stats)
+	}
+	if err != nil {
 		if cw.Count() == 0 {
 			// Only record the error headers IFF nothing has been written to w.
 			h.HandleHTTPError(ctx, err, w)
@@ -195,6 +213,21 @@ func (h *FluxHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// logQueryStatistics logs the statistics of a query traced in full, including
+// the data read from storage.
+func logQueryStatistics(log *zap.Logger, stats flux.Statistics) {
+	log.Info("Query statistics",
+		zap.String("handler", "flux"),
+		zap.Duration("total_duration", stats.TotalDuration),
+		zap.Duration("compile_duration", stats.CompileDuration),
+		zap.Duration("queue_duration", stats.QueueDuration),
+		zap.Duration("plan_duration", stats.PlanDuration),
+		zap.Duration("execute_duration", stats.ExecuteDuration),
+		zap.Int64("max_allocated", stats.MaxAllocated),
+		zap.Any("metadata", stats.Metadata),
+	)
+}
+
 type langRequest struct {
 	Query string `json:"query"`
 }
@@ -390,7 +423,11 @@ func (s *FluxService) Query(ctx context.Context, w io.Writer, r *query.ProxyRequ
 	SetToken(s.Token, hreq)
 
 	hreq.Header.Set("Content-Type", "application/json")
-	hreq.Header.Set("Accept", "text/csv")
+	if qreq.Accept != "" {
+		hreq.Header.Set("Accept", qreq.Accept)
+	} else {
+		hreq.Header.Set("Accept", "text/csv")
+	}
 	if r.Request.Source != "" {
 		hreq.Header.Add("User-Agent", r.Request.Source)
 	} else if s.Name != "" {
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/influxdb"
+)
+
+// queryParamsIdentifier is the identifier the params of a flux query are
+// bound to.
+const queryParamsIdentifier = "params"
+
+var queryParamKeyRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// QueryParams are the parameters of a flux query. They are bound to the
+// record params in the query, so that values given by users are never
+// written into its source:
+//
+//	from(bucket: params.bucket) |> range(start: params.start)
+//
+// Values are strings, numbers, booleans, and arrays and objects of them.
+type QueryParams map[string]interface{}
+
+// UnmarshalJSON decodes params, keeping the numbers without a fraction or
+// exponent integers.
+func (p *QueryParams) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+	*p = m
+	return nil
+}
+
+// assignment returns the statement binding the params to the params
+// identifier.
+func (p QueryParams) assignment() (ast.Statement, error) {
+	obj, err := queryParamsObject(p, queryParamsIdentifier)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.VariableAssignment{
+		ID:   &ast.Identifier{Name: queryParamsIdentifier},
+		Init: obj,
+	}, nil
+}
+
+// externWithParams returns extern with the statement binding the params
+// appended to it. extern is not modified.
+func (p QueryParams) externWithParams(extern *ast.File) (*ast.File, error) {
+	stmt, err := p.assignment()
+	if err != nil {
+		return nil, err
+	}
+	if extern == nil {
+		return &ast.File{Body: []ast.Statement{stmt}}, nil
+	}
+	f := *extern
+	f.Body = append(append([]ast.Statement(nil), extern.Body...), stmt)
+	return &f, nil
+}
+
+func queryParamsObject(m map[string]interface{}, path string) (*ast.ObjectExpression, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	obj := &ast.ObjectExpression{}
+	for _, k := range keys {
+		if !queryParamKeyRE.MatchString(k) {
+			return nil, invalidQueryParam(path, fmt.Sprintf("key %q is not a valid identifier", k))
+		}
+		v, err := queryParamExpression(m[k], path+"."+k)
+		if err != nil {
+			return nil, err
+		}
+		obj.Properties = append(obj.Properties, &ast.Property{
+			Key:   &ast.Identifier{Name: k},
+			Value: v,
+		})
+	}
+	return obj, nil
+}
+
+func queryParamExpression(v interface{}, path string) (ast.Expression, error) {
+	switch v := v.(type) {
+	case string:
+		// Values are bound as literals and never interpolated, but values
+		// holding interpolations point to source built from user input.
+		if strings.Contains(v, "${") {
+			return nil, invalidQueryParam(path, "string interpolation is not allowed")
+		}
+		return &ast.StringLiteral{Value: v}, nil
+	case bool:
+		return &ast.BooleanLiteral{Value: v}, nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return &ast.IntegerLiteral{Value: i}, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, invalidQueryParam(path, fmt.Sprintf("number %s is out of range", v))
+		}
+		return &ast.FloatLiteral{Value: f}, nil
+	case int:
+		return &ast.IntegerLiteral{Value: int64(v)}, nil
+	case int64:
+		return &ast.IntegerLiteral{Value: v}, nil
+	case float64:
+		return &ast.FloatLiteral{Value: v}, nil
+	case []interface{}:
+		arr := &ast.ArrayExpression{}
+		for i, e := range v {
+			expr, err := queryParamExpression(e, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements = append(arr.Elements, expr)
+		}
+		return arr, nil
+	case map[string]interface{}:
+		return queryParamsObject(v, path)
+	case nil:
+		return nil, invalidQueryParam(path, "null is not allowed")
+	default:
+		return nil, invalidQueryParam(path, fmt.Sprintf("unsupported type %T", v))
+	}
+}
+
+func invalidQueryParam(path, msg string) error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid query param %s: %s", path, msg),
+	}
+}
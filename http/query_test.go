@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -212,6 +213,7 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 		Query   string
 		Type    string
 		Dialect QueryDialect
+		Accept  string
 		org     *platform.Organization
 	}
 	tests := []struct {
@@ -255,6 +257,83 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid query with json results",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:         ",",
+					DateTimeFormat:    "RFC3339",
+					IntegersAsStrings: true,
+				},
+				Accept: "application/json",
+				org:    &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &query.JSONDialect{
+					IntegersAsStrings: true,
+				},
+			},
+		},
+		{
+			name: "valid query with arrow results",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				Accept: "application/vnd.apache.arrow.stream",
+				org:    &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &query.ArrowDialect{},
+			},
+		},
+		{
+			name: "valid query with integers as strings",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:         ",",
+					DateTimeFormat:    "RFC3339",
+					IntegersAsStrings: true,
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &query.StringIntegersCSVDialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:  false,
+						Delimiter: ',',
+					},
+				},
+			},
+		},
 		{
 			name: "valid AST",
 			fields: fields{
@@ -371,6 +450,7 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				Query:   tt.fields.Query,
 				Type:    tt.fields.Type,
 				Dialect: tt.fields.Dialect,
+				Accept:  tt.fields.Accept,
 				Org:     tt.fields.org,
 			}
 			got, err := r.proxyRequest(tt.now)
@@ -385,6 +465,95 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 	}
 }
 
+func TestQueryRequest_Params(t *testing.T) {
+	var r QueryRequest
+	if err := json.Unmarshal([]byte(`{
+		"query": "from(bucket: params.bucket)",
+		"params": {"bucket": "b", "n": 1, "ratio": 1.5, "hosts": ["a", "b"], "opts": {"ok": true}}
+	}`), &r); err != nil {
+		t.Fatal(err)
+	}
+	r = r.WithDefaults()
+	r.Org = &platform.Organization{}
+
+	got, err := r.proxyRequest(func() time.Time { return time.Unix(1, 1) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	extern := got.Request.Compiler.(lang.FluxCompiler).Extern
+	want := &ast.File{
+		Body: []ast.Statement{
+			&ast.VariableAssignment{
+				ID: &ast.Identifier{Name: "params"},
+				Init: &ast.ObjectExpression{
+					Properties: []*ast.Property{
+						{Key: &ast.Identifier{Name: "bucket"}, Value: &ast.StringLiteral{Value: "b"}},
+						{Key: &ast.Identifier{Name: "hosts"}, Value: &ast.ArrayExpression{
+							Elements: []ast.Expression{&ast.StringLiteral{Value: "a"}, &ast.StringLiteral{Value: "b"}},
+						}},
+						{Key: &ast.Identifier{Name: "n"}, Value: &ast.IntegerLiteral{Value: 1}},
+						{Key: &ast.Identifier{Name: "opts"}, Value: &ast.ObjectExpression{
+							Properties: []*ast.Property{
+								{Key: &ast.Identifier{Name: "ok"}, Value: &ast.BooleanLiteral{Value: true}},
+							},
+						}},
+						{Key: &ast.Identifier{Name: "ratio"}, Value: &ast.FloatLiteral{Value: 1.5}},
+					},
+				},
+			},
+		},
+	}
+	if !cmp.Equal(extern, want, cmpOptions...) {
+		t.Errorf("unexpected extern -want/+got\n%s", cmp.Diff(want, extern, cmpOptions...))
+	}
+
+	for _, tt := range []struct {
+		name   string
+		params QueryParams
+		typ    string
+		msg    string
+	}{
+		{
+			name:   "interpolation",
+			params: QueryParams{"bucket": "${secret}"},
+			typ:    "flux",
+			msg:    "invalid query param params.bucket: string interpolation is not allowed",
+		},
+		{
+			name:   "invalid key",
+			params: QueryParams{"a-b": "c"},
+			typ:    "flux",
+			msg:    `invalid query param params: key "a-b" is not a valid identifier`,
+		},
+		{
+			name:   "null",
+			params: QueryParams{"hosts": []interface{}{"a", nil}},
+			typ:    "flux",
+			msg:    "invalid query param params.hosts[1]: null is not allowed",
+		},
+		{
+			name:   "influxql",
+			params: QueryParams{"bucket": "b"},
+			typ:    "influxql",
+			msg:    "params are only supported by flux queries and ASTs",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := QueryRequest{
+				Type:   tt.typ,
+				Query:  "from(bucket: params.bucket)",
+				Bucket: "b",
+				Params: tt.params,
+				Org:    &platform.Organization{},
+			}.WithDefaults()
+			_, err := r.ProxyRequest()
+			if platform.ErrorCode(err) != platform.EInvalid || platform.ErrorMessage(err) != tt.msg {
+				t.Errorf("got error %v, want %q", err, tt.msg)
+			}
+		})
+	}
+}
+
 func Test_decodeQueryRequest(t *testing.T) {
 	type args struct {
 		ctx context.Context
@@ -451,6 +620,69 @@ func Test_decodeQueryRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid query request with json results",
+			args: args{
+				r: func() *http.Request {
+					r := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "from()"}`))
+					r.Header.Set("Accept", "text/csv;q=0.5, application/json")
+					return r
+				}(),
+				svc: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{
+							ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+						}, nil
+					},
+				},
+			},
+			want: &QueryRequest{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Header:         func(x bool) *bool { return &x }(true),
+				},
+				Accept: "application/json",
+				Org: &platform.Organization{
+					ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+				},
+			},
+		},
+		{
+			name: "valid query request with priority",
+			args: args{
+				r: httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "from()", "priority": "dashboard"}`)),
+				svc: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{
+							ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+						}, nil
+					},
+				},
+			},
+			want: &QueryRequest{
+				Query:    "from()",
+				Type:     "flux",
+				Priority: query.PriorityDashboard,
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Header:         func(x bool) *bool { return &x }(true),
+				},
+				Org: &platform.Organization{
+					ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+				},
+			},
+		},
+		{
+			name: "error decoding unknown priority",
+			args: args{
+				r: httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "from()", "priority": "urgent"}`)),
+			},
+			wantErr: true,
+		},
 		{
 			name: "error decoding json",
 			args: args{
@@ -480,6 +712,27 @@ func Test_decodeQueryRequest(t *testing.T) {
 	}
 }
 
+func Test_negotiateQueryMediaType(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: ""},
+		{accept: "*/*", want: ""},
+		{accept: "text/csv", want: "text/csv"},
+		{accept: "application/json", want: "application/json"},
+		{accept: "application/vnd.apache.arrow.stream", want: "application/vnd.apache.arrow.stream"},
+		{accept: "text/html, application/json;q=0.9, */*;q=0.8", want: "application/json"},
+		{accept: "application/json;q=0.5, application/vnd.apache.arrow.stream", want: "application/vnd.apache.arrow.stream"},
+		{accept: "text/csv, application/json", want: "text/csv"},
+	}
+	for _, tt := range tests {
+		if got := negotiateQueryMediaType(tt.accept); got != tt.want {
+			t.Errorf("negotiateQueryMediaType(%q) = %q, want %q", tt.accept, got, tt.want)
+		}
+	}
+}
+
 func Test_decodeProxyQueryRequest(t *testing.T) {
 	type args struct {
 		ctx  context.Context
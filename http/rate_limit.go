@@ -0,0 +1,236 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Names of the limits of AuthorizationRateLimits, as reported by metrics.
+const (
+	rateLimitRequests   = "requests"
+	rateLimitWriteBytes = "write-bytes"
+)
+
+// TokenRateLimiter enforces the rate limits of authorizations, and exports
+// the rate of the requests made with each authorization with limits as
+// metrics.
+type TokenRateLimiter struct {
+	mu       sync.Mutex
+	limiters map[influxdb.ID]*tokenLimiter
+	now      func() time.Time
+
+	requests   *prometheus.CounterVec
+	writeBytes *prometheus.CounterVec
+	limited    *prometheus.CounterVec
+}
+
+// NewTokenRateLimiter returns a new TokenRateLimiter.
+func NewTokenRateLimiter() *TokenRateLimiter {
+	const namespace = "http"
+	const subsystem = "api_token"
+
+	return &TokenRateLimiter{
+		limiters: make(map[influxdb.ID]*tokenLimiter),
+		now:      time.Now,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Number of requests made with each authorization with rate limits",
+		}, []string{"authorization_id"}),
+		writeBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "write_bytes_total",
+			Help:      "Number of bytes written with each authorization with rate limits",
+		}, []string{"authorization_id"}),
+		limited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rate_limited_total",
+			Help:      "Number of requests rejected because they would exceed the rate limits of an authorization",
+		}, []string{"authorization_id", "limit"}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (l *TokenRateLimiter) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		l.requests,
+		l.writeBytes,
+		l.limited,
+	}
+}
+
+// limiter returns the limiter of an authorization, updated to its current
+// limits. l.mu must be held.
+func (l *TokenRateLimiter) limiter(a *influxdb.Authorization, now time.Time) *tokenLimiter {
+	tl, ok := l.limiters[a.ID]
+	if !ok {
+		tl = &tokenLimiter{}
+		l.limiters[a.ID] = tl
+	}
+	tl.requests.setRate(a.RateLimits.MaxRequestsPerSecond, now)
+	tl.writeBytes.setRate(float64(a.RateLimits.MaxWriteBytesPerSecond), now)
+	return tl
+}
+
+// Allow returns how long to wait before making a request with the
+// authorization, if the request would exceed one of its limits, and the name
+// of that limit. A request to write points is limited by the bytes written
+// by the previous requests.
+func (l *TokenRateLimiter) Allow(a *influxdb.Authorization, write bool) (time.Duration, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	tl := l.limiter(a, now)
+	if write {
+		if d := tl.writeBytes.wait(0, now); d > 0 {
+			return d, rateLimitWriteBytes
+		}
+	}
+	if d := tl.requests.wait(1, now); d > 0 {
+		return d, rateLimitRequests
+	}
+	tl.requests.take(1, now)
+	return 0, ""
+}
+
+// Wrote charges the bytes written with the authorization to its limits.
+func (l *TokenRateLimiter) Wrote(a *influxdb.Authorization, n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.limiter(a, now).writeBytes.take(float64(n), now)
+}
+
+type tokenLimiter struct {
+	requests   tokenBucket
+	writeBytes tokenBucket
+}
+
+// tokenBucket holds up to a second's worth of tokens, refilled at rate per
+// second. Taking tokens may leave the bucket in debt, which is paid back
+// before tokens are available again. A bucket with a rate of zero is always
+// full.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) size() float64 {
+	return math.Max(b.rate, 1)
+}
+
+func (b *tokenBucket) setRate(rate float64, now time.Time) {
+	if rate == b.rate {
+		return
+	}
+	if b.rate == 0 {
+		// The bucket was unlimited, and so full.
+		b.tokens = math.Max(rate, 1)
+	} else {
+		b.refill(now)
+		b.tokens = math.Min(b.tokens, math.Max(rate, 1))
+	}
+	b.rate = rate
+	b.last = now
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if b.rate > 0 && now.After(b.last) {
+		b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*b.rate, b.size())
+	}
+	b.last = now
+}
+
+// wait returns how long until n tokens are available, or zero if they are.
+// Waiting for zero tokens waits until the bucket is out of debt.
+func (b *tokenBucket) wait(n float64, now time.Time) time.Duration {
+	if b.rate == 0 {
+		return 0
+	}
+	b.refill(now)
+	missing := n - b.tokens
+	if missing <= 0 {
+		return 0
+	}
+	return time.Duration(missing / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) take(n float64, now time.Time) {
+	if b.rate == 0 {
+		return
+	}
+	b.refill(now)
+	b.tokens -= n
+}
+
+// rateLimitMW rejects the requests made with authorizations that would exceed
+// their rate limits with a 429 and a Retry-After header. It must wrap
+// handlers of authenticated requests.
+func rateLimitMW(errorHandler influxdb.HTTPErrorHandler, l *TokenRateLimiter) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			a, ok := authorizationFromContext(r)
+			if !ok || a.RateLimits == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			id := a.ID.String()
+			write := r.URL.Path == prefixWrite || r.URL.Path == prefixLegacyWrite
+			if d, limit := l.Allow(a, write); d > 0 {
+				l.limited.WithLabelValues(id, limit).Inc()
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+				errorHandler.HandleHTTPError(ctx, &influxdb.Error{
+					Code: influxdb.ETooManyRequests,
+					Msg:  fmt.Sprintf("authorization %s exceeded its %s rate limit", id, limit),
+				}, w)
+				return
+			}
+			l.requests.WithLabelValues(id).Inc()
+
+			if !write {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cr := &countReader{Reader: r.Body}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{cr, r.Body}
+			next.ServeHTTP(w, r)
+
+			l.Wrote(a, int64(cr.bytesRead))
+			l.writeBytes.WithLabelValues(id).Add(float64(cr.bytesRead))
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// authorizationFromContext returns the authorization a request is made with,
+// if it is made with one rather than a session.
+func authorizationFromContext(r *http.Request) (*influxdb.Authorization, bool) {
+	auth, err := pctx.GetAuthorizer(r.Context())
+	if err != nil {
+		return nil, false
+	}
+	a, ok := auth.(*influxdb.Authorization)
+	return a, ok
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRateLimitMW(t *testing.T) {
+	now := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	l := NewTokenRateLimiter()
+	l.now = func() time.Time { return now }
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err != nil {
+			t.Fatal(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := rateLimitMW(kithttp.ErrorHandler(0), l)(next)
+
+	a := &influxdb.Authorization{
+		ID: influxdb.ID(1),
+		RateLimits: &influxdb.AuthorizationRateLimits{
+			MaxRequestsPerSecond:   2,
+			MaxWriteBytesPerSecond: 100,
+		},
+	}
+	do := func(a influxdb.Authorizer, path string, body []byte) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", path, bytes.NewReader(body))
+		if a != nil {
+			r = r.WithContext(pctx.SetAuthorizer(context.Background(), a))
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("requests", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			if w := do(a, "/api/v2/buckets", nil); w.Code != http.StatusNoContent {
+				t.Fatalf("request %d got status %d, want %d", i, w.Code, http.StatusNoContent)
+			}
+		}
+		w := do(a, "/api/v2/buckets", nil)
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusTooManyRequests)
+		}
+		if got := w.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("got Retry-After %q, want %q", got, "1")
+		}
+
+		// Unlimited authorizations and sessions are not limited.
+		if w := do(&influxdb.Authorization{ID: influxdb.ID(2)}, "/api/v2/buckets", nil); w.Code != http.StatusNoContent {
+			t.Errorf("got status %d for unlimited authorization, want %d", w.Code, http.StatusNoContent)
+		}
+		if w := do(&influxdb.Session{ID: influxdb.ID(3)}, "/api/v2/buckets", nil); w.Code != http.StatusNoContent {
+			t.Errorf("got status %d for session, want %d", w.Code, http.StatusNoContent)
+		}
+
+		now = now.Add(time.Second)
+	})
+
+	t.Run("write bytes", func(t *testing.T) {
+		// The bucket goes into debt for a second and a half.
+		if w := do(a, prefixWrite, make([]byte, 250)); w.Code != http.StatusNoContent {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusNoContent)
+		}
+		w := do(a, prefixWrite, make([]byte, 10))
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusTooManyRequests)
+		}
+		if got := w.Header().Get("Retry-After"); got != "2" {
+			t.Errorf("got Retry-After %q, want %q", got, "2")
+		}
+
+		// Other requests are not limited by the bytes written.
+		if w := do(a, "/api/v2/buckets", nil); w.Code != http.StatusNoContent {
+			t.Errorf("got status %d, want %d", w.Code, http.StatusNoContent)
+		}
+
+		now = now.Add(2 * time.Second)
+		if w := do(a, prefixWrite, make([]byte, 10)); w.Code != http.StatusNoContent {
+			t.Fatalf("got status %d, want %d", w.Code, http.StatusNoContent)
+		}
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		id := a.ID.String()
+		if got := testutil.ToFloat64(l.requests.WithLabelValues(id)); got != 5 {
+			t.Errorf("got %v requests, want 5", got)
+		}
+		if got := testutil.ToFloat64(l.writeBytes.WithLabelValues(id)); got != 260 {
+			t.Errorf("got %v bytes written, want 260", got)
+		}
+		if got := testutil.ToFloat64(l.limited.WithLabelValues(id, rateLimitRequests)); got != 1 {
+			t.Errorf("got %v requests limited by requests, want 1", got)
+		}
+		if got := testutil.ToFloat64(l.limited.WithLabelValues(id, rateLimitWriteBytes)); got != 1 {
+			t.Errorf("got %v requests limited by write bytes, want 1", got)
+		}
+	})
+}
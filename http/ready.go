@@ -6,24 +6,44 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/toml"
 )
 
 // ReadyHandler is a default readiness handler. The default behaviour is always ready.
 func ReadyHandler() http.Handler {
+	return NewReadyHandler()
+}
+
+// NewReadyHandler returns a readiness handler reporting the status of each
+// of checks. The process is not ready, with a 503, while any check fails.
+func NewReadyHandler(checks ...check.Checker) http.Handler {
+	c := check.NewCheck()
+	for _, ch := range checks {
+		c.AddReadyCheck(ch)
+	}
+
 	up := time.Now()
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+		resp := c.CheckReady(r.Context())
 
 		var status = struct {
 			Status string    `json:"status"`
 			Start  time.Time `json:"started"`
 			// TODO(jsteenb2): learn why and leave comment for this being a toml.Duration
-			Up toml.Duration `json:"up"`
+			Up     toml.Duration   `json:"up"`
+			Checks check.Responses `json:"checks,omitempty"`
 		}{
 			Status: "ready",
 			Start:  up,
 			Up:     toml.Duration(time.Since(up)),
+			Checks: resp.Checks,
+		}
+		if resp.Status == check.StatusFail {
+			status.Status = "unready"
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
 		}
 
 		enc := json.NewEncoder(w)
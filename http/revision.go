@@ -0,0 +1,84 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+)
+
+// setETag sets the ETag of a response with a resource at revision rev.
+func setETag(w http.ResponseWriter, rev uint64) {
+	w.Header().Set("ETag", `"`+strconv.FormatUint(rev, 10)+`"`)
+}
+
+// findRevision returns the revision of the resource with id, and false if
+// revisions are not found by s.
+//
+// Handlers find the revision before the resource, so that the resource they
+// respond with is never older than its ETag, and set the ETag once the
+// resource has been found, so that the revisions of resources are not
+// disclosed to users who cannot read them.
+func findRevision(ctx context.Context, s influxdb.RevisionService, id influxdb.ID) (uint64, bool, error) {
+	if s == nil {
+		return 0, false, nil
+	}
+	rev, err := s.FindRevision(ctx, id)
+	if err != nil {
+		return 0, false, err
+	}
+	return rev, true, nil
+}
+
+// ifMatch is the precondition of the If-Match header of a request updating
+// or deleting a resource.
+type ifMatch struct {
+	revisions []uint64
+	ok        bool
+}
+
+// decodeIfMatch returns the revisions in the If-Match header of r. The
+// precondition holds for no revision if none of the entity tags is one of a
+// revision, and for any revision if the header is missing or is "*".
+func decodeIfMatch(r *http.Request) ifMatch {
+	h := strings.TrimSpace(r.Header.Get("If-Match"))
+	if h == "" || h == "*" {
+		return ifMatch{}
+	}
+
+	m := ifMatch{ok: true}
+	for _, tag := range strings.Split(h, ",") {
+		// Weak entity tags never match under the strong comparison
+		// If-Match requires.
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		rev, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		m.revisions = append(m.revisions, rev)
+	}
+	return m
+}
+
+// context returns ctx with the precondition on the revision of the resource
+// with id.
+func (m ifMatch) context(ctx context.Context, id influxdb.ID) context.Context {
+	if !m.ok {
+		return ctx
+	}
+	return influxdb.NewContextWithExpectedRevisions(ctx, id, m.revisions...)
+}
+
+// setETag sets the ETag of the response to the update of a resource made under
+// the precondition, if the revision it was updated to is known. Each update
+// increments the revision of the resource.
+func (m ifMatch) setETag(w http.ResponseWriter) {
+	if m.ok && len(m.revisions) == 1 {
+		setETag(w, m.revisions[0]+1)
+	}
+}
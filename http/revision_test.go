@@ -0,0 +1,28 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_decodeIfMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   ifMatch
+	}{
+		{header: "", want: ifMatch{}},
+		{header: "*", want: ifMatch{}},
+		{header: `"3"`, want: ifMatch{revisions: []uint64{3}, ok: true}},
+		{header: `"3", W/"4", "5"`, want: ifMatch{revisions: []uint64{3, 5}, ok: true}},
+		{header: `"abc"`, want: ifMatch{ok: true}},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("PATCH", "http://any.url", nil)
+		r.Header.Set("If-Match", tt.header)
+		if got := decodeIfMatch(r); !cmp.Equal(got, tt.want, cmp.AllowUnexported(ifMatch{})) {
+			t.Errorf("decodeIfMatch(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixRunningQueries = "/api/v2/queries"
+	runningQueriesIDPath = "/api/v2/queries/:id"
+)
+
+// RunningQueryBackend is all services and associated parameters required to
+// construct the RunningQueryHandler.
+type RunningQueryBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	RunningQueryService influxdb.RunningQueryService
+}
+
+// NewRunningQueryBackend returns a new instance of RunningQueryBackend.
+func NewRunningQueryBackend(log *zap.Logger, b *APIBackend) *RunningQueryBackend {
+	return &RunningQueryBackend{
+		log: log,
+
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		RunningQueryService: b.RunningQueryService,
+	}
+}
+
+// RunningQueryHandler lists and kills the queries being executed by the
+// server.
+type RunningQueryHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	RunningQueryService influxdb.RunningQueryService
+}
+
+// NewRunningQueryHandler creates a new handler at /api/v2/queries to manage
+// the queries being executed.
+func NewRunningQueryHandler(log *zap.Logger, b *RunningQueryBackend) *RunningQueryHandler {
+	h := &RunningQueryHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		RunningQueryService: b.RunningQueryService,
+	}
+
+	h.HandlerFunc("GET", prefixRunningQueries, h.handleGetRunningQueries)
+	h.HandlerFunc("GET", runningQueriesIDPath, h.handleGetRunningQuery)
+	h.HandlerFunc("DELETE", runningQueriesIDPath, h.handleDeleteRunningQuery)
+	return h
+}
+
+type runningQueriesResponse struct {
+	Queries []*influxdb.RunningQuery `json:"queries"`
+}
+
+// handleGetRunningQueries is the HTTP handler for the GET /api/v2/queries
+// route.
+func (h *RunningQueryHandler) handleGetRunningQueries(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter := influxdb.RunningQueryFilter{}
+	if orgID := r.URL.Query().Get(OrgID); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		filter.OrgID = id
+	}
+
+	queries, err := h.RunningQueryService.FindRunningQueries(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if queries == nil {
+		queries = []*influxdb.RunningQuery{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, runningQueriesResponse{Queries: queries}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetRunningQuery is the HTTP handler for the GET /api/v2/queries/:id
+// route.
+func (h *RunningQueryHandler) handleGetRunningQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(httprouter.ParamsFromContext(ctx).ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	q, err := h.RunningQueryService.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, q); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteRunningQuery is the HTTP handler for the DELETE
+// /api/v2/queries/:id route. It kills the query, which then fails.
+func (h *RunningQueryHandler) handleDeleteRunningQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(httprouter.ParamsFromContext(ctx).ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RunningQueryService.KillRunningQuery(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Query killed", zap.String("queryID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
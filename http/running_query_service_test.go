@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunningQueryHandler_handleGetRunningQueries(t *testing.T) {
+	var gotFilter influxdb.RunningQueryFilter
+	svc := mock.NewRunningQueryService()
+	svc.FindRunningQueriesF = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		gotFilter = filter
+		return []*influxdb.RunningQuery{
+			{
+				ID:            influxdb.ID(1),
+				OrgID:         influxdb.ID(0x020f755c3c082000),
+				Source:        "influx",
+				Query:         `from(bucket: "b") |> range(start: -1h)`,
+				State:         "executing",
+				StartedAt:     time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+				Runtime:       influxdb.Duration{Duration: 90 * time.Second},
+				ValuesScanned: 1000,
+				BytesScanned:  8000,
+			},
+		}, nil
+	}
+
+	h := NewRunningQueryHandler(zaptest.NewLogger(t), &RunningQueryBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		RunningQueryService: svc,
+	})
+
+	r := httptest.NewRequest("GET", "http://any.tld"+prefixRunningQueries+"?orgID=020f755c3c082000", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("handleGetRunningQueries() = %v, want %v: %s", res.StatusCode, http.StatusOK, body)
+	}
+	if gotFilter.OrgID == nil || *gotFilter.OrgID != influxdb.ID(0x020f755c3c082000) {
+		t.Errorf("got filter org %v, want 020f755c3c082000", gotFilter.OrgID)
+	}
+	want := `{
+		"queries": [
+			{
+				"id": "0000000000000001",
+				"orgID": "020f755c3c082000",
+				"source": "influx",
+				"query": "from(bucket: \"b\") |> range(start: -1h)",
+				"state": "executing",
+				"startedAt": "2019-10-01T00:00:00Z",
+				"runtime": "1m30s",
+				"valuesScanned": 1000,
+				"bytesScanned": 8000
+			}
+		]
+	}`
+	if eq, diff, err := jsonEqual(string(body), want); err != nil {
+		t.Errorf("handleGetRunningQueries(). error unmarshaling json %v", err)
+	} else if !eq {
+		t.Errorf("handleGetRunningQueries() = ***%s***", diff)
+	}
+}
+
+func TestRunningQueryHandler_handleDeleteRunningQuery(t *testing.T) {
+	var killed []influxdb.ID
+	svc := mock.NewRunningQueryService()
+	svc.KillRunningQueryF = func(ctx context.Context, id influxdb.ID) error {
+		if id != influxdb.ID(1) {
+			return &influxdb.Error{Code: influxdb.ENotFound, Msg: influxdb.ErrRunningQueryNotFound}
+		}
+		killed = append(killed, id)
+		return nil
+	}
+
+	h := NewRunningQueryHandler(zaptest.NewLogger(t), &RunningQueryBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		RunningQueryService: svc,
+	})
+
+	tests := []struct {
+		id         string
+		statusCode int
+		body       string
+	}{
+		{
+			id:         "0000000000000001",
+			statusCode: http.StatusNoContent,
+		},
+		{
+			id:         "0000000000000002",
+			statusCode: http.StatusNotFound,
+			body:       `{"code": "not found", "message": "running query not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("DELETE", "http://any.tld"+prefixRunningQueries+"/"+tt.id, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		body, _ := ioutil.ReadAll(res.Body)
+
+		if res.StatusCode != tt.statusCode {
+			t.Errorf("handleDeleteRunningQuery() = %v, want %v: %s", res.StatusCode, tt.statusCode, body)
+		}
+		if tt.body == "" {
+			continue
+		}
+		if eq, diff, err := jsonEqual(string(body), tt.body); err != nil {
+			t.Errorf("handleDeleteRunningQuery(). error unmarshaling json %v", err)
+		} else if !eq {
+			t.Errorf("handleDeleteRunningQuery() = ***%s***", diff)
+		}
+	}
+
+	if len(killed) != 1 {
+		t.Errorf("got %d queries killed, want 1", len(killed))
+	}
+}
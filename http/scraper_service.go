@@ -21,6 +21,7 @@ type ScraperBackend struct {
 	log *zap.Logger
 
 	ScraperStorageService      influxdb.ScraperTargetStoreService
+	ScraperStatusService       influxdb.ScraperTargetStatusService
 	BucketService              influxdb.BucketService
 	OrganizationService        influxdb.OrganizationService
 	UserService                influxdb.UserService
@@ -35,6 +36,7 @@ func NewScraperBackend(log *zap.Logger, b *APIBackend) *ScraperBackend {
 		log:              log,
 
 		ScraperStorageService:      b.ScraperTargetStoreService,
+		ScraperStatusService:       b.ScraperTargetStatusService,
 		BucketService:              b.BucketService,
 		OrganizationService:        b.OrganizationService,
 		UserService:                b.UserService,
@@ -52,6 +54,7 @@ type ScraperHandler struct {
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	ScraperStorageService      influxdb.ScraperTargetStoreService
+	ScraperStatusService       influxdb.ScraperTargetStatusService
 	BucketService              influxdb.BucketService
 	OrganizationService        influxdb.OrganizationService
 }
@@ -76,6 +79,7 @@ func NewScraperHandler(log *zap.Logger, b *ScraperBackend) *ScraperHandler {
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		ScraperStorageService:      b.ScraperStorageService,
+		ScraperStatusService:       b.ScraperStatusService,
 		BucketService:              b.BucketService,
 		OrganizationService:        b.OrganizationService,
 	}
@@ -575,9 +579,10 @@ type targetLinks struct {
 
 type targetResponse struct {
 	influxdb.ScraperTarget
-	Org    string      `json:"org,omitempty"`
-	Bucket string      `json:"bucket,omitempty"`
-	Links  targetLinks `json:"links"`
+	Org    string                        `json:"org,omitempty"`
+	Bucket string                        `json:"bucket,omitempty"`
+	Status *influxdb.ScraperTargetStatus `json:"status,omitempty"`
+	Links  targetLinks                   `json:"links"`
 }
 
 func (h *ScraperHandler) newListTargetsResponse(ctx context.Context, targets []influxdb.ScraperTarget) (getTargetsResponse, error) {
@@ -626,5 +631,13 @@ func (h *ScraperHandler) newTargetResponse(ctx context.Context, target influxdb.
 		res.OrgID = influxdb.InvalidID()
 	}
 
+	if h.ScraperStatusService != nil {
+		status, err := h.ScraperStatusService.FindTargetStatus(ctx, target.ID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return res, err
+		}
+		res.Status = status
+	}
+
 	return res, nil
 }
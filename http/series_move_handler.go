@@ -0,0 +1,199 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/predicate"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixSeriesMoves = "/api/v2/moves"
+	seriesMovesIDPath = "/api/v2/moves/:id"
+)
+
+// SeriesMoveBackend is all services and associated parameters required to
+// construct the SeriesMoveHandler.
+type SeriesMoveBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	SeriesMoveService influxdb.SeriesMoveService
+	BucketService     influxdb.BucketService
+}
+
+// NewSeriesMoveBackend returns a new instance of SeriesMoveBackend.
+func NewSeriesMoveBackend(log *zap.Logger, b *APIBackend) *SeriesMoveBackend {
+	return &SeriesMoveBackend{
+		log: log,
+
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		SeriesMoveService: b.SeriesMoveService,
+		BucketService:     b.BucketService,
+	}
+}
+
+// SeriesMoveHandler starts moves of series between buckets and reports on
+// their progress.
+type SeriesMoveHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	SeriesMoveService influxdb.SeriesMoveService
+	BucketService     influxdb.BucketService
+}
+
+// NewSeriesMoveHandler creates a new handler at /api/v2/moves to move series
+// between buckets.
+func NewSeriesMoveHandler(log *zap.Logger, b *SeriesMoveBackend) *SeriesMoveHandler {
+	h := &SeriesMoveHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		SeriesMoveService: b.SeriesMoveService,
+		BucketService:     b.BucketService,
+	}
+
+	h.HandlerFunc("POST", prefixSeriesMoves, h.handlePostSeriesMove)
+	h.HandlerFunc("GET", prefixSeriesMoves, h.handleGetSeriesMoves)
+	h.HandlerFunc("GET", seriesMovesIDPath, h.handleGetSeriesMove)
+	return h
+}
+
+type seriesMovesResponse struct {
+	Moves []*influxdb.SeriesMove `json:"moves"`
+}
+
+// handlePostSeriesMove is the HTTP handler for the POST /api/v2/moves route.
+// It returns as soon as the move has started.
+func (h *SeriesMoveHandler) handlePostSeriesMove(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SeriesMoveHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	m, pred, err := h.decodePostSeriesMoveRequest(ctx, r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.SeriesMoveService.MoveSeries(ctx, m, pred); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Series move started", zap.String("moveID", m.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// decodePostSeriesMoveRequest decodes a series move and its predicate, and
+// checks that both buckets belong to the organization of the move.
+func (h *SeriesMoveHandler) decodePostSeriesMoveRequest(ctx context.Context, r *http.Request) (*influxdb.SeriesMove, influxdb.Predicate, error) {
+	m := &influxdb.SeriesMove{}
+	if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid request; error parsing request json",
+			Err:  err,
+		}
+	}
+	if !m.OrgID.Valid() || !m.SourceBucketID.Valid() || !m.DestinationBucketID.Valid() {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID, sourceBucketID and destinationBucketID are required",
+		}
+	}
+
+	for _, id := range []influxdb.ID{m.SourceBucketID, m.DestinationBucketID} {
+		b, err := h.BucketService.FindBucketByID(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		if b.OrgID != m.OrgID {
+			return nil, nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "bucket " + id.String() + " does not belong to the organization",
+			}
+		}
+	}
+
+	node, err := predicate.Parse(m.Predicate)
+	if err != nil {
+		return nil, nil, err
+	}
+	pred, err := predicate.New(node)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, pred, nil
+}
+
+// handleGetSeriesMoves is the HTTP handler for the GET /api/v2/moves route.
+func (h *SeriesMoveHandler) handleGetSeriesMoves(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SeriesMoveHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter := influxdb.SeriesMoveFilter{}
+	if orgID := r.URL.Query().Get(OrgID); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		filter.OrgID = id
+	}
+
+	moves, err := h.SeriesMoveService.FindSeriesMoves(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if moves == nil {
+		moves = []*influxdb.SeriesMove{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, seriesMovesResponse{Moves: moves}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleGetSeriesMove is the HTTP handler for the GET /api/v2/moves/:id route.
+func (h *SeriesMoveHandler) handleGetSeriesMove(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SeriesMoveHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(httprouter.ParamsFromContext(ctx).ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	m, err := h.SeriesMoveService.FindSeriesMoveByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSeriesMoveHandler_handlePostSeriesMove(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+		pred       bool
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		wants wants
+	}{
+		{
+			name: "move series matching a predicate",
+			body: `{"orgID": "020f755c3c082000", "sourceBucketID": "020f755c3c082001", "destinationBucketID": "020f755c3c082002", "predicate": "team=\"a\""}`,
+			wants: wants{
+				statusCode: http.StatusAccepted,
+				pred:       true,
+				body: `{
+					"id": "020f755c3c082010",
+					"orgID": "020f755c3c082000",
+					"sourceBucketID": "020f755c3c082001",
+					"destinationBucketID": "020f755c3c082002",
+					"predicate": "team=\"a\"",
+					"status": "running",
+					"series": 0,
+					"startedAt": "2019-10-01T00:00:00Z"
+				}`,
+			},
+		},
+		{
+			name: "missing destination bucket",
+			body: `{"orgID": "020f755c3c082000", "sourceBucketID": "020f755c3c082001"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "orgID, sourceBucketID and destinationBucketID are required"}`,
+			},
+		},
+		{
+			name: "bucket of another org",
+			body: `{"orgID": "020f755c3c082000", "sourceBucketID": "020f755c3c082001", "destinationBucketID": "020f755c3c082003"}`,
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "bucket 020f755c3c082003 does not belong to the organization"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets := mock.NewBucketService()
+			buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				orgID := influxdb.ID(0x020f755c3c082000)
+				if id == influxdb.ID(0x020f755c3c082003) {
+					orgID++
+				}
+				return &influxdb.Bucket{ID: id, OrgID: orgID}, nil
+			}
+
+			var gotPred influxdb.Predicate
+			svc := mock.NewSeriesMoveService()
+			svc.MoveSeriesF = func(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error {
+				started := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+				m.ID, m.Status, m.StartedAt = influxdb.ID(0x020f755c3c082010), influxdb.SeriesMoveRunning, &started
+				gotPred = pred
+				return nil
+			}
+
+			h := NewSeriesMoveHandler(zaptest.NewLogger(t), &SeriesMoveBackend{
+				HTTPErrorHandler:  kithttp.ErrorHandler(0),
+				SeriesMoveService: svc,
+				BucketService:     buckets,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixSeriesMoves, bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handlePostSeriesMove() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if got := gotPred != nil; got != tt.wants.pred {
+				t.Errorf("got predicate %v, want predicate %v", got, tt.wants.pred)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+				t.Errorf("handlePostSeriesMove(). error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("handlePostSeriesMove() = ***%s***", diff)
+			}
+		})
+	}
+}
+
+func TestSeriesMoveHandler_handleGetSeriesMove(t *testing.T) {
+	svc := mock.NewSeriesMoveService()
+	svc.FindSeriesMoveByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error) {
+		if id != influxdb.ID(0x020f755c3c082010) {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "series move not found"}
+		}
+		return &influxdb.SeriesMove{
+			ID:                  id,
+			OrgID:               influxdb.ID(0x020f755c3c082000),
+			SourceBucketID:      influxdb.ID(0x020f755c3c082001),
+			DestinationBucketID: influxdb.ID(0x020f755c3c082002),
+			Status:              influxdb.SeriesMoveFailed,
+			Error:               "engine closed",
+		}, nil
+	}
+
+	h := NewSeriesMoveHandler(zaptest.NewLogger(t), &SeriesMoveBackend{
+		HTTPErrorHandler:  kithttp.ErrorHandler(0),
+		SeriesMoveService: svc,
+	})
+
+	tests := []struct {
+		id         string
+		statusCode int
+		body       string
+	}{
+		{
+			id:         "020f755c3c082010",
+			statusCode: http.StatusOK,
+			body: `{
+				"id": "020f755c3c082010",
+				"orgID": "020f755c3c082000",
+				"sourceBucketID": "020f755c3c082001",
+				"destinationBucketID": "020f755c3c082002",
+				"status": "failed",
+				"error": "engine closed",
+				"series": 0
+			}`,
+		},
+		{
+			id:         "020f755c3c082011",
+			statusCode: http.StatusNotFound,
+			body:       `{"code": "not found", "message": "series move not found"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://any.tld"+prefixSeriesMoves+"/"+tt.id, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		res := w.Result()
+		body, _ := ioutil.ReadAll(res.Body)
+
+		if res.StatusCode != tt.statusCode {
+			t.Errorf("handleGetSeriesMove() = %v, want %v: %s", res.StatusCode, tt.statusCode, body)
+		}
+		if eq, diff, err := jsonEqual(string(body), tt.body); err != nil {
+			t.Errorf("handleGetSeriesMove(). error unmarshaling json %v", err)
+		} else if !eq {
+			t.Errorf("handleGetSeriesMove() = ***%s***", diff)
+		}
+	}
+}
@@ -95,6 +95,13 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The client is recorded so that users can tell their sessions apart.
+	s.UserAgent = r.UserAgent()
+	s.SourceIP = sourceIP(r)
+	if err := h.SessionService.RenewSession(ctx, s, s.ExpiresAt); err != nil {
+		h.log.Warn("Unable to record client of session", zap.String("user", req.Username), zap.Error(err))
+	}
+
 	encodeCookieSession(w, s)
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -0,0 +1,203 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixSessions = "/api/v2/sessions"
+	sessionsIDPath = "/api/v2/sessions/:id"
+)
+
+// SessionManagementBackend is all services and associated parameters
+// required to construct the SessionManagementHandler.
+type SessionManagementBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	SessionManagementService influxdb.SessionManagementService
+}
+
+// NewSessionManagementBackend returns a new instance of
+// SessionManagementBackend.
+func NewSessionManagementBackend(log *zap.Logger, b *APIBackend) *SessionManagementBackend {
+	return &SessionManagementBackend{
+		log: log,
+
+		HTTPErrorHandler:         b.HTTPErrorHandler,
+		SessionManagementService: b.SessionManagementService,
+	}
+}
+
+// SessionManagementHandler lists and revokes the sessions of users.
+type SessionManagementHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	SessionManagementService influxdb.SessionManagementService
+}
+
+// NewSessionManagementHandler creates a new handler at /api/v2/sessions to
+// list and revoke sessions.
+func NewSessionManagementHandler(log *zap.Logger, b *SessionManagementBackend) *SessionManagementHandler {
+	h := &SessionManagementHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		SessionManagementService: b.SessionManagementService,
+	}
+
+	h.HandlerFunc("GET", prefixSessions, h.handleGetSessions)
+	h.HandlerFunc("DELETE", prefixSessions, h.handleDeleteSessions)
+	h.HandlerFunc("DELETE", sessionsIDPath, h.handleDeleteSession)
+	return h
+}
+
+type sessionResponse struct {
+	ID        influxdb.ID `json:"id"`
+	UserID    influxdb.ID `json:"userID"`
+	UserAgent string      `json:"userAgent,omitempty"`
+	SourceIP  string      `json:"sourceIP,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+	// Current is true for the session the request is made with.
+	Current bool `json:"current"`
+}
+
+type sessionsResponse struct {
+	Sessions []sessionResponse `json:"sessions"`
+}
+
+func newSessionsResponse(ctx context.Context, ss []*influxdb.Session) sessionsResponse {
+	var current influxdb.ID
+	if a, err := pctx.GetAuthorizer(ctx); err == nil && a.Kind() == influxdb.SessionAuthorizionKind {
+		current = a.Identifier()
+	}
+
+	res := sessionsResponse{Sessions: make([]sessionResponse, 0, len(ss))}
+	for _, s := range ss {
+		res.Sessions = append(res.Sessions, sessionResponse{
+			ID:        s.ID,
+			UserID:    s.UserID,
+			UserAgent: s.UserAgent,
+			SourceIP:  s.SourceIP,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+			Current:   current.Valid() && s.ID == current,
+		})
+	}
+	return res
+}
+
+// handleGetSessions is the HTTP handler for the GET /api/v2/sessions route.
+// It lists the sessions of the user of the userID query parameter, or every
+// session the request is authorized to see.
+func (h *SessionManagementHandler) handleGetSessions(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SessionManagementHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var filter influxdb.SessionFilter
+	if s := r.URL.Query().Get("userID"); s != "" {
+		id, err := influxdb.IDFromString(s)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid userID",
+				Err:  err,
+			}, w)
+			return
+		}
+		filter.UserID = id
+	}
+	opts, err := decodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ss, _, err := h.SessionManagementService.FindSessions(ctx, filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newSessionsResponse(ctx, ss)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteSessions is the HTTP handler for the DELETE /api/v2/sessions
+// route. It revokes all of the sessions of the user of the userID query
+// parameter, or of the user making the request.
+func (h *SessionManagementHandler) handleDeleteSessions(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SessionManagementHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var userID influxdb.ID
+	if s := r.URL.Query().Get("userID"); s != "" {
+		if err := userID.DecodeFromString(s); err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid userID",
+				Err:  err,
+			}, w)
+			return
+		}
+	} else {
+		id, err := pctx.GetUserID(ctx)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		userID = id
+	}
+
+	if err := h.SessionManagementService.RevokeSessions(ctx, userID); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDeleteSession is the HTTP handler for the DELETE
+// /api/v2/sessions/:id route.
+func (h *SessionManagementHandler) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SessionManagementHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(httprouter.ParamsFromContext(ctx).ByName("id")); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid session id",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	if err := h.SessionManagementService.RevokeSession(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
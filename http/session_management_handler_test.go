@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	pctx "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSessionManagementHandler(t *testing.T) {
+	created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var revoked, revokedUser influxdb.ID
+	svc := &mock.SessionManagementService{
+		FindSessionsFn: func(ctx context.Context, filter influxdb.SessionFilter, opt ...influxdb.FindOptions) ([]*influxdb.Session, int, error) {
+			if filter.UserID == nil || *filter.UserID != 2 {
+				t.Fatalf("unexpected filter %+v", filter)
+			}
+			return []*influxdb.Session{
+				{ID: 1, UserID: 2, UserAgent: "curl/7.64.1", SourceIP: "192.0.2.1", CreatedAt: created, ExpiresAt: created.Add(time.Hour)},
+				{ID: 3, UserID: 2, CreatedAt: created, ExpiresAt: created.Add(time.Hour)},
+			}, 2, nil
+		},
+		RevokeSessionFn: func(ctx context.Context, id influxdb.ID) error {
+			revoked = id
+			return nil
+		},
+		RevokeSessionsFn: func(ctx context.Context, userID influxdb.ID) error {
+			revokedUser = userID
+			return nil
+		},
+	}
+
+	h := NewSessionManagementHandler(zaptest.NewLogger(t), &SessionManagementBackend{
+		HTTPErrorHandler:         kithttp.ErrorHandler(0),
+		SessionManagementService: svc,
+	})
+	do := func(method, path string) *http.Response {
+		r := httptest.NewRequest(method, "http://any.tld"+path, nil)
+		r = r.WithContext(pctx.SetAuthorizer(r.Context(), &influxdb.Session{ID: 3, UserID: 2}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Result()
+	}
+
+	t.Run("list sessions", func(t *testing.T) {
+		res := do("GET", prefixSessions+"?userID=0000000000000002")
+		body, _ := ioutil.ReadAll(res.Body)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("unexpected status code: %d: %s", res.StatusCode, body)
+		}
+		want := `{"sessions": [
+			{"id": "0000000000000001", "userID": "0000000000000002", "userAgent": "curl/7.64.1", "sourceIP": "192.0.2.1", "createdAt": "2020-01-01T00:00:00Z", "expiresAt": "2020-01-01T01:00:00Z", "current": false},
+			{"id": "0000000000000003", "userID": "0000000000000002", "createdAt": "2020-01-01T00:00:00Z", "expiresAt": "2020-01-01T01:00:00Z", "current": true}
+		]}`
+		if eq, diff, err := jsonEqual(string(body), want); err != nil {
+			t.Fatal(err)
+		} else if !eq {
+			t.Fatalf("unexpected body: %s", diff)
+		}
+	})
+
+	t.Run("revoke session", func(t *testing.T) {
+		if res := do("DELETE", prefixSessions+"/0000000000000001"); res.StatusCode != http.StatusNoContent {
+			t.Fatalf("unexpected status code: %d", res.StatusCode)
+		}
+		if revoked != 1 {
+			t.Errorf("got session %s revoked, want 0000000000000001", revoked)
+		}
+	})
+
+	t.Run("revoke sessions of the current user", func(t *testing.T) {
+		if res := do("DELETE", prefixSessions); res.StatusCode != http.StatusNoContent {
+			t.Fatalf("unexpected status code: %d", res.StatusCode)
+		}
+		if revokedUser != 2 {
+			t.Errorf("got sessions of user %s revoked, want 0000000000000002", revokedUser)
+		}
+	})
+}
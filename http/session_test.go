@@ -37,10 +37,14 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 		password string
 	}
 	type wants struct {
-		cookie string
-		code   int
+		cookie    string
+		code      int
+		userAgent string
 	}
 
+	// The client of sessions is recorded by renewing them.
+	var renewed *platform.Session
+
 	tests := []struct {
 		name   string
 		fields fields
@@ -60,6 +64,10 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 							UserID:    platform.ID(1),
 						}, nil
 					},
+					RenewSessionFn: func(ctx context.Context, s *platform.Session, expiresAt time.Time) error {
+						renewed = s
+						return nil
+					},
 				},
 				PasswordsService: &mock.PasswordsService{
 					ComparePasswordFn: func(context.Context, platform.ID, string) error {
@@ -72,8 +80,9 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 				password: "supersecret",
 			},
 			wants: wants{
-				cookie: "session=abc123xyz",
-				code:   http.StatusNoContent,
+				cookie:    "session=abc123xyz",
+				code:      http.StatusNoContent,
+				userAgent: "influx",
 			},
 		},
 	}
@@ -88,8 +97,14 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/signin", nil)
 			r.SetBasicAuth(tt.args.user, tt.args.password)
+			r.Header.Set("User-Agent", "influx")
+			renewed = nil
 			h.ServeHTTP(w, r)
 
+			if renewed == nil || renewed.UserAgent != tt.wants.userAgent || renewed.SourceIP != "192.0.2.1" {
+				t.Errorf("expected client of session to be recorded: got %+v", renewed)
+			}
+
 			if got, want := w.Code, tt.wants.code; got != want {
 				t.Errorf("bad status code: got %d want %d", got, want)
 			}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"fmt"
+	http "net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixShardStats = "/api/v2/stats/shards"
+)
+
+// ShardStatsBackend is all services and associated parameters required to
+// construct the ShardStatsHandler.
+type ShardStatsBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	ShardStatsService influxdb.ShardStatsService
+}
+
+// NewShardStatsBackend returns a new instance of ShardStatsBackend.
+func NewShardStatsBackend(log *zap.Logger, b *APIBackend) *ShardStatsBackend {
+	return &ShardStatsBackend{
+		log: log,
+
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		ShardStatsService: b.ShardStatsService,
+	}
+}
+
+// ShardStatsHandler serves the load on each shard, to find the hottest shards.
+type ShardStatsHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	ShardStatsService influxdb.ShardStatsService
+}
+
+// NewShardStatsHandler creates a new handler at /api/v2/stats/shards to serve
+// shard statistics.
+func NewShardStatsHandler(log *zap.Logger, b *ShardStatsBackend) *ShardStatsHandler {
+	h := &ShardStatsHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		ShardStatsService: b.ShardStatsService,
+	}
+
+	h.HandlerFunc("GET", prefixShardStats, h.handleGetShardStats)
+	return h
+}
+
+type shardStatsResponse struct {
+	Stats []*influxdb.ShardStats `json:"stats"`
+}
+
+func (h *ShardStatsHandler) handleGetShardStats(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ShardStatsHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeShardStatsFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	stats, err := h.ShardStatsService.FindShardStats(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if stats == nil {
+		stats = []*influxdb.ShardStats{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, shardStatsResponse{Stats: stats}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeShardStatsFilter(r *http.Request) (*influxdb.ShardStatsFilter, error) {
+	filter := &influxdb.ShardStatsFilter{}
+	qp := r.URL.Query()
+
+	if orgID := qp.Get(OrgID); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		filter.OrgID = id
+	}
+
+	if bucketID := qp.Get(BucketID); bucketID != "" {
+		id, err := influxdb.IDFromString(bucketID)
+		if err != nil {
+			return nil, err
+		}
+		filter.BucketID = id
+	}
+
+	filter.SortBy = qp.Get("sortBy")
+
+	if limit := qp.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("limit must be a positive integer, got %q", limit),
+			}
+		}
+		filter.Limit = l
+	}
+
+	return filter, nil
+}
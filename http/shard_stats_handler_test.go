@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestShardStatsHandler_handleGetShardStats(t *testing.T) {
+	type wants struct {
+		statusCode int
+		filter     *influxdb.ShardStatsFilter
+		body       string
+	}
+
+	orgID := influxdb.ID(0x020f755c3c082000)
+
+	tests := []struct {
+		name        string
+		queryParams map[string][]string
+		wants       wants
+	}{
+		{
+			name:        "all stats",
+			queryParams: map[string][]string{},
+			wants: wants{
+				statusCode: http.StatusOK,
+				filter:     &influxdb.ShardStatsFilter{},
+				body: `{"stats": [{
+					"orgID": "020f755c3c082000",
+					"bucketID": "020f755c3c082001",
+					"writePointsPerSecond": 150.5,
+					"writeBytesPerSecond": 4096,
+					"cacheBytes": 1048576,
+					"tsmFiles": 4,
+					"compactionBacklog": 3,
+					"tombstonedBytes": 512,
+					"cursors": 20,
+					"cursorLatencySeconds": 0.25
+				}]}`,
+			},
+		},
+		{
+			name: "hottest shards of an org",
+			queryParams: map[string][]string{
+				"orgID":  {"020f755c3c082000"},
+				"sortBy": {"compactions"},
+				"limit":  {"5"},
+			},
+			wants: wants{
+				statusCode: http.StatusOK,
+				filter: &influxdb.ShardStatsFilter{
+					OrgID:  &orgID,
+					SortBy: influxdb.ShardStatsSortCompactions,
+					Limit:  5,
+				},
+			},
+		},
+		{
+			name: "invalid limit",
+			queryParams: map[string][]string{
+				"limit": {"0"},
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "limit must be a positive integer, got \"0\""}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFilter *influxdb.ShardStatsFilter
+			svc := mock.NewShardStatsService()
+			svc.FindShardStatsF = func(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error) {
+				gotFilter = &filter
+				return []*influxdb.ShardStats{{
+					OrgID:                orgID,
+					BucketID:             influxdb.ID(0x020f755c3c082001),
+					WritePointsPerSecond: 150.5,
+					WriteBytesPerSecond:  4096,
+					CacheBytes:           1 << 20,
+					TSMFiles:             4,
+					CompactionBacklog:    3,
+					TombstonedBytes:      512,
+					Cursors:              20,
+					CursorLatency:        0.25,
+				}}, nil
+			}
+
+			h := NewShardStatsHandler(zaptest.NewLogger(t), &ShardStatsBackend{
+				HTTPErrorHandler:  kithttp.ErrorHandler(0),
+				ShardStatsService: svc,
+			})
+
+			r := httptest.NewRequest("GET", "http://any.tld"+prefixShardStats, nil)
+			qp := r.URL.Query()
+			for k, vs := range tt.queryParams {
+				for _, v := range vs {
+					qp.Add(k, v)
+				}
+			}
+			r.URL.RawQuery = qp.Encode()
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handleGetShardStats() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if tt.wants.filter != nil {
+				if gotFilter == nil {
+					t.Fatal("expected FindShardStats to be called")
+				}
+				if !shardStatsFilterEqual(*gotFilter, *tt.wants.filter) {
+					t.Errorf("got filter %+v, want %+v", *gotFilter, *tt.wants.filter)
+				}
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("handleGetShardStats(). error unmarshaling json %v", err)
+				} else if !eq {
+					t.Errorf("handleGetShardStats() = ***%s***", diff)
+				}
+			}
+		})
+	}
+}
+
+func shardStatsFilterEqual(a, b influxdb.ShardStatsFilter) bool {
+	idEqual := func(x, y *influxdb.ID) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return idEqual(a.OrgID, b.OrgID) && idEqual(a.BucketID, b.BucketID) &&
+		a.SortBy == b.SortBy && a.Limit == b.Limit
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+// Headers of signed requests. Requests are signed with the token of an
+// authorization, which is not sent with them, as the key identified by the ID
+// of the authorization.
+const (
+	SignatureKeyIDHeader     = "X-Influx-Key-Id"
+	SignatureTimestampHeader = "X-Influx-Timestamp"
+	SignatureHeader          = "X-Influx-Signature"
+)
+
+// DefaultSignedRequestMaxSkew is the default for how far the timestamp of a
+// signed request may be from the time it is received.
+const DefaultSignedRequestMaxSkew = 5 * time.Minute
+
+// SignRequest signs r with the token of the authorization of keyID at time t,
+// setting the signature headers of r. It reads the body of r, and replaces it
+// with a copy.
+//
+// The signature is the hex encoded HMAC-SHA256, keyed with the token, of the
+// method, path, raw query, Unix timestamp in seconds and hex encoded SHA-256 of
+// the body of the request, each followed by a newline.
+func SignRequest(r *http.Request, keyID influxdb.ID, token string, t time.Time) error {
+	body, err := readSignedBody(r, 0)
+	if err != nil {
+		return err
+	}
+
+	ts := strconv.FormatInt(t.Unix(), 10)
+	r.Header.Set(SignatureKeyIDHeader, keyID.String())
+	r.Header.Set(SignatureTimestampHeader, ts)
+	r.Header.Set(SignatureHeader, hex.EncodeToString(requestSignature(r, ts, body, token)))
+	return nil
+}
+
+func requestSignature(r *http.Request, ts string, body []byte, token string) []byte {
+	sum := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(token))
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%s\n%s\n", r.Method, r.URL.Path, r.URL.RawQuery, ts, hex.EncodeToString(sum[:]))
+	return mac.Sum(nil)
+}
+
+// readSignedBody reads the body of r, up to max bytes if max is positive, and
+// replaces it with a copy.
+func readSignedBody(r *http.Request, max int64) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	defer r.Body.Close()
+
+	var rd io.Reader = r.Body
+	if max > 0 {
+		rd = io.LimitReader(r.Body, max+1)
+	}
+	body, err := ioutil.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	if max > 0 && int64(len(body)) > max {
+		return nil, fmt.Errorf("signed request body exceeds %d bytes", max)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// SignedRequestVerifier verifies the signatures of signed requests, rejecting
+// requests signed too long before or after they are received, and signatures
+// that were already used.
+type SignedRequestVerifier struct {
+	// MaxSkew is how far the timestamp of a signed request may be from the
+	// time it is received.
+	MaxSkew time.Duration
+
+	// MaxBodyBytes is the size of the largest body of a signed request. The
+	// body is read before the request is handled to verify it. A value of zero
+	// specifies there is no limit.
+	MaxBodyBytes int64
+
+	mu sync.Mutex
+	// seen holds the signatures used within MaxSkew, and the time after
+	// which the timestamps they were made with are too old to be accepted.
+	seen      map[string]time.Time
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+// NewSignedRequestVerifier returns a new SignedRequestVerifier accepting
+// requests signed up to maxSkew before or after they are received.
+func NewSignedRequestVerifier(maxSkew time.Duration) *SignedRequestVerifier {
+	return &SignedRequestVerifier{
+		MaxSkew: maxSkew,
+		seen:    make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Verify returns an error unless r is signed with one of tokens within
+// MaxSkew of now, with a signature that was not used before. It reads the
+// body of r, and replaces it with a copy.
+func (v *SignedRequestVerifier) Verify(r *http.Request, tokens ...string) error {
+	ts := r.Header.Get(SignatureTimestampHeader)
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "invalid signature timestamp",
+			Err:  err,
+		}
+	}
+	sig, err := hex.DecodeString(r.Header.Get(SignatureHeader))
+	if err != nil || len(sig) == 0 {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "invalid signature",
+		}
+	}
+
+	now := v.now()
+	t := time.Unix(sec, 0)
+	if d := now.Sub(t); d > v.MaxSkew || d < -v.MaxSkew {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("signature timestamp is more than %s away from the time of the server", v.MaxSkew),
+		}
+	}
+
+	body, err := readSignedBody(r, v.MaxBodyBytes)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "unable to read signed request body",
+			Err:  err,
+		}
+	}
+
+	valid := false
+	for _, token := range tokens {
+		if token != "" && hmac.Equal(sig, requestSignature(r, ts, body, token)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "signature does not match",
+		}
+	}
+
+	if !v.use(r.Header.Get(SignatureKeyIDHeader)+":"+hex.EncodeToString(sig), t.Add(v.MaxSkew), now) {
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "signature was already used",
+		}
+	}
+	return nil
+}
+
+// use records the use of a signature until expires, and returns false if it
+// was already used.
+func (v *SignedRequestVerifier) use(sig string, expires, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !now.Before(v.nextSweep) {
+		for k, exp := range v.seen {
+			if now.After(exp) {
+				delete(v.seen, k)
+			}
+		}
+		v.nextSweep = now.Add(v.MaxSkew)
+	}
+
+	if _, ok := v.seen[sig]; ok {
+		return false
+	}
+	v.seen[sig] = expires
+	return true
+}
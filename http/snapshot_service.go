@@ -0,0 +1,77 @@
+package http
+
+import (
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const prefixSnapshots = "/api/v2/snapshots"
+
+// SnapshotBackend is all services and associated parameters required to
+// construct the SnapshotHandler.
+type SnapshotBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	SnapshotService influxdb.SnapshotService
+}
+
+// NewSnapshotBackend returns a new instance of SnapshotBackend.
+func NewSnapshotBackend(log *zap.Logger, b *APIBackend) *SnapshotBackend {
+	return &SnapshotBackend{
+		log: log,
+
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		SnapshotService:  b.SnapshotService,
+	}
+}
+
+// SnapshotHandler creates snapshots of the storage engine while it is running.
+type SnapshotHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	SnapshotService influxdb.SnapshotService
+}
+
+// NewSnapshotHandler creates a new handler at /api/v2/snapshots to create
+// snapshots of the storage engine. The files of a snapshot are downloaded
+// from /api/v2/backup.
+func NewSnapshotHandler(log *zap.Logger, b *SnapshotBackend) *SnapshotHandler {
+	h := &SnapshotHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		SnapshotService: b.SnapshotService,
+	}
+
+	h.HandlerFunc("POST", prefixSnapshots, h.handlePostSnapshot)
+	return h
+}
+
+// handlePostSnapshot is the HTTP handler for the POST /api/v2/snapshots route.
+func (h *SnapshotHandler) handlePostSnapshot(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "SnapshotHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	manifest, err := h.SnapshotService.CreateSnapshot(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Snapshot created", zap.Int("snapshotID", manifest.ID))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, manifest); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSnapshotHandler_handlePostSnapshot(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		err   error
+		wants wants
+	}{
+		{
+			name: "create snapshot",
+			wants: wants{
+				statusCode: http.StatusCreated,
+				body: `{
+					"id": 3,
+					"checkpointID": 42,
+					"createdAt": "2019-10-01T00:00:00Z",
+					"files": [
+						{"name": "000000001-000000001.tsm", "size": 1024},
+						{"name": "000000001-000000001.tombstone", "size": 16}
+					]
+				}`,
+			},
+		},
+		{
+			name: "engine closed",
+			err: &influxdb.Error{
+				Code: influxdb.EUnavailable,
+				Msg:  "engine closed",
+			},
+			wants: wants{
+				statusCode: http.StatusServiceUnavailable,
+				body:       `{"code": "unavailable", "message": "engine closed"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mock.NewSnapshotService()
+			svc.CreateSnapshotF = func(ctx context.Context) (*influxdb.SnapshotManifest, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &influxdb.SnapshotManifest{
+					ID:           3,
+					CheckpointID: 42,
+					CreatedAt:    time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+					Files: []influxdb.SnapshotFile{
+						{Name: "000000001-000000001.tsm", Size: 1024},
+						{Name: "000000001-000000001.tombstone", Size: 16},
+					},
+				}, nil
+			}
+
+			h := NewSnapshotHandler(zaptest.NewLogger(t), &SnapshotBackend{
+				HTTPErrorHandler: kithttp.ErrorHandler(0),
+				SnapshotService:  svc,
+			})
+
+			r := httptest.NewRequest("POST", "http://any.tld"+prefixSnapshots, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("POST %s = %v, want %v: %s", prefixSnapshots, res.StatusCode, tt.wants.statusCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+				t.Errorf("POST %s. error unmarshaling json %v", prefixSnapshots, err)
+			} else if !eq {
+				t.Errorf("POST %s = ***%s***", prefixSnapshots, diff)
+			}
+		})
+	}
+}
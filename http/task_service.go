@@ -34,6 +34,7 @@ type TaskBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	RevisionService            influxdb.RevisionService
 }
 
 // NewTaskBackend returns a new instance of TaskBackend.
@@ -48,6 +49,7 @@ func NewTaskBackend(log *zap.Logger, b *APIBackend) *TaskBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		RevisionService:            b.RevisionService,
 	}
 }
 
@@ -64,6 +66,7 @@ type TaskHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	RevisionService            influxdb.RevisionService
 }
 
 const (
@@ -96,6 +99,7 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		RevisionService:            b.RevisionService,
 	}
 
 	h.HandlerFunc("GET", prefixTasks, h.handleGetTasks)
@@ -630,6 +634,12 @@ func (h *TaskHandler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rev, hasRev, err := findRevision(ctx, h.RevisionService, req.TaskID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
 	task, err := h.TaskService.FindTaskByID(ctx, req.TaskID)
 	if err != nil {
 		err = &influxdb.Error{
@@ -651,6 +661,9 @@ func (h *TaskHandler) handleGetTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	h.log.Debug("Task retrieved", zap.String("tasks", fmt.Sprint(task)))
+	if hasRev {
+		setETag(w, rev)
+	}
 	if err := encodeResponse(ctx, w, http.StatusOK, newTaskResponse(*task, labels)); err != nil {
 		logEncodingError(h.log, r, err)
 		return
@@ -695,7 +708,8 @@ func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
-	task, err := h.TaskService.UpdateTask(ctx, req.TaskID, req.Update)
+	m := decodeIfMatch(r)
+	task, err := h.TaskService.UpdateTask(m.context(ctx, req.TaskID), req.TaskID, req.Update)
 	if err != nil {
 		err := &influxdb.Error{
 			Err: err,
@@ -707,6 +721,7 @@ func (h *TaskHandler) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		h.HandleHTTPError(ctx, err, w)
 		return
 	}
+	m.setETag(w)
 
 	labels, err := h.LabelService.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: task.ID})
 	if err != nil {
@@ -772,7 +787,7 @@ func (h *TaskHandler) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.TaskService.DeleteTask(ctx, req.TaskID); err != nil {
+	if err := h.TaskService.DeleteTask(decodeIfMatch(r).context(ctx, req.TaskID), req.TaskID); err != nil {
 		err := &influxdb.Error{
 			Err: err,
 			Msg: "failed to delete task",
@@ -27,6 +27,24 @@ func GetToken(r *http.Request) (string, error) {
 	return header[len(tokenScheme):], nil
 }
 
+// GetLegacyToken will parse the token of a request to a 1.x compatible
+// endpoint. 1.x clients authenticate with a username and password, so the
+// token is also read from the password of the basic authentication or from the
+// p query parameter of a request without a token in its Authorization Header.
+func GetLegacyToken(r *http.Request) (string, error) {
+	t, err := GetToken(r)
+	if err == nil {
+		return t, nil
+	}
+	if _, p, ok := r.BasicAuth(); ok && p != "" {
+		return p, nil
+	}
+	if p := r.URL.Query().Get("p"); p != "" {
+		return p, nil
+	}
+	return "", err
+}
+
 // SetToken adds the token to the request.
 func SetToken(token string, req *http.Request) {
 	req.Header.Set("Authorization", fmt.Sprintf("%s%s", tokenScheme, token))
@@ -99,3 +99,57 @@ func TestSetToken(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLegacyToken(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func() *http.Request
+		want string
+		err  error
+	}{
+		{
+			name: "token header",
+			req: func() *http.Request {
+				r := httptest.NewRequest("POST", "/write?db=telegraf&p=tok1", nil)
+				r.Header.Set("Authorization", "Token tok2")
+				return r
+			},
+			want: "tok2",
+		},
+		{
+			name: "basic auth password",
+			req: func() *http.Request {
+				r := httptest.NewRequest("POST", "/write?db=telegraf", nil)
+				r.SetBasicAuth("user", "tok3")
+				return r
+			},
+			want: "tok3",
+		},
+		{
+			name: "password query parameter",
+			req: func() *http.Request {
+				return httptest.NewRequest("POST", "/write?db=telegraf&u=user&p=tok4", nil)
+			},
+			want: "tok4",
+		},
+		{
+			name: "no token",
+			req: func() *http.Request {
+				return httptest.NewRequest("POST", "/write?db=telegraf", nil)
+			},
+			err: ErrAuthHeaderMissing,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLegacyToken(tt.req())
+			if err != tt.err {
+				t.Errorf("err incorrect want %v, got %v", tt.err, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("result incorrect want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -95,10 +95,13 @@ func (r getVariablesResponse) ToPlatform() []*platform.Variable {
 }
 
 func newGetVariablesResponse(ctx context.Context, variables []*platform.Variable, f platform.VariableFilter, opts platform.FindOptions, labelService platform.LabelService) getVariablesResponse {
-	num := len(variables)
+	ids := make([]platform.ID, len(variables))
+	for i, variable := range variables {
+		ids[i] = variable.ID
+	}
 	resp := getVariablesResponse{
-		Variables: make([]variableResponse, 0, num),
-		Links:     newPagingLinks(prefixVariables, opts, f, num),
+		Variables: make([]variableResponse, 0, len(variables)),
+		Links:     newPagingLinks(prefixVariables, opts, f, ids),
 	}
 
 	for _, variable := range variables {
@@ -0,0 +1,77 @@
+package http
+
+import (
+	http "net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const prefixWALRecovery = "/api/v2/wal/recovery"
+
+// WALRecoveryBackend is all services and associated parameters required to
+// construct the WALRecoveryHandler.
+type WALRecoveryBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	WALRecoveryService influxdb.WALRecoveryService
+}
+
+// NewWALRecoveryBackend returns a new instance of WALRecoveryBackend.
+func NewWALRecoveryBackend(log *zap.Logger, b *APIBackend) *WALRecoveryBackend {
+	return &WALRecoveryBackend{
+		log: log,
+
+		HTTPErrorHandler:   b.HTTPErrorHandler,
+		WALRecoveryService: b.WALRecoveryService,
+	}
+}
+
+// WALRecoveryHandler reports on the replay of the WAL when the storage engine
+// was opened.
+type WALRecoveryHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	WALRecoveryService influxdb.WALRecoveryService
+}
+
+// NewWALRecoveryHandler creates a new handler at /api/v2/wal/recovery to
+// report on the replay of the WAL.
+func NewWALRecoveryHandler(log *zap.Logger, b *WALRecoveryBackend) *WALRecoveryHandler {
+	h := &WALRecoveryHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		WALRecoveryService: b.WALRecoveryService,
+	}
+
+	h.HandlerFunc("GET", prefixWALRecovery, h.handleGetWALRecovery)
+	return h
+}
+
+// handleGetWALRecovery is the HTTP handler for the GET /api/v2/wal/recovery
+// route.
+func (h *WALRecoveryHandler) handleGetWALRecovery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WALRecoveryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	report, err := h.WALRecoveryService.WALRecoveryReport(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, report); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
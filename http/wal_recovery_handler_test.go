@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWALRecoveryHandler_handleGetWALRecovery(t *testing.T) {
+	type wants struct {
+		statusCode int
+		body       string
+	}
+
+	tests := []struct {
+		name  string
+		err   error
+		wants wants
+	}{
+		{
+			name: "get recovery report",
+			wants: wants{
+				statusCode: http.StatusOK,
+				body: `{
+					"startedAt": "2019-10-01T00:00:00Z",
+					"completedAt": "2019-10-01T00:00:02Z",
+					"segments": [
+						{"name": "_00001.wal", "size": 2048, "entriesReplayed": 10, "entriesDropped": 0, "corruptEntries": 0, "truncatedBytes": 0},
+						{"name": "_00002.wal", "size": 1024, "entriesReplayed": 4, "entriesDropped": 2, "corruptEntries": 1, "truncatedBytes": 512, "corruption": "snappy: corrupt input"}
+					],
+					"buckets": [
+						{"orgID": "020f755c3c082000", "bucketID": "020f755c3c082001", "pointsRecovered": 140, "pointsDropped": 20}
+					]
+				}`,
+			},
+		},
+		{
+			name: "wal disabled",
+			err: &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  "the WAL is disabled",
+			},
+			wants: wants{
+				statusCode: http.StatusNotFound,
+				body:       `{"code": "not found", "message": "the WAL is disabled"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := mock.NewWALRecoveryService()
+			svc.WALRecoveryReportF = func(ctx context.Context) (*influxdb.WALRecoveryReport, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &influxdb.WALRecoveryReport{
+					StartedAt:   time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC),
+					CompletedAt: time.Date(2019, 10, 1, 0, 0, 2, 0, time.UTC),
+					Segments: []influxdb.WALSegmentRecovery{
+						{Name: "_00001.wal", Size: 2048, EntriesReplayed: 10},
+						{Name: "_00002.wal", Size: 1024, EntriesReplayed: 4, EntriesDropped: 2, CorruptEntries: 1, TruncatedBytes: 512, Corruption: "snappy: corrupt input"},
+					},
+					Buckets: []influxdb.WALBucketRecovery{
+						{OrgID: influxdb.ID(0x020f755c3c082000), BucketID: influxdb.ID(0x020f755c3c082001), PointsRecovered: 140, PointsDropped: 20},
+					},
+				}, nil
+			}
+
+			h := NewWALRecoveryHandler(zaptest.NewLogger(t), &WALRecoveryBackend{
+				HTTPErrorHandler:   kithttp.ErrorHandler(0),
+				WALRecoveryService: svc,
+			})
+
+			r := httptest.NewRequest("GET", "http://any.tld"+prefixWALRecovery, nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("GET %s = %v, want %v: %s", prefixWALRecovery, res.StatusCode, tt.wants.statusCode, body)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+				t.Errorf("GET %s. error unmarshaling json %v", prefixWALRecovery, err)
+			} else if !eq {
+				t.Errorf("GET %s = ***%s***", prefixWALRecovery, diff)
+			}
+		})
+	}
+}
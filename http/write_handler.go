@@ -3,11 +3,13 @@ package http
 import (
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 
 	"github.com/influxdata/httprouter"
 	"github.com/influxdata/influxdb"
@@ -28,16 +30,44 @@ var (
 	ErrMaxBatchSizeExceeded = errors.New("points batch is too large")
 )
 
+// Headers of write responses hinting at how clients should shape their
+// writes given the current load on the server.
+const (
+	// WriteBatchSizeHeader is the preferred number of lines per write.
+	WriteBatchSizeHeader = "Influx-Write-Batch-Size"
+	// WriteBackoffHeader is the number of milliseconds to wait between writes.
+	WriteBackoffHeader = "Influx-Write-Backoff-Ms"
+	// WriteCompressionHeader is the preferred Content-Encoding of writes,
+	// either gzip or identity.
+	WriteCompressionHeader = "Influx-Write-Compression"
+)
+
+// IdempotencyKeyHeader is the header of a write request holding a key that
+// identifies its batch. A batch written with the key of a batch recently
+// written to the same bucket is dropped, so that writes that timed out can be
+// retried safely.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// WriteHinter provides hints on how clients should shape their writes.
+type WriteHinter interface {
+	WriteHints() storage.WriteHints
+}
+
 // WriteBackend is all services and associated parameters required to construct
 // the WriteHandler.
 type WriteBackend struct {
 	influxdb.HTTPErrorHandler
 	log                *zap.Logger
 	WriteEventRecorder metric.EventRecorder
+	WriteHinter        WriteHinter
 
 	PointsWriter        storage.PointsWriter
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
+
+	// MeasurementSchemaService, if set, provides the schemas that the points
+	// written to buckets with an explicit schema must conform to.
+	MeasurementSchemaService influxdb.MeasurementSchemaService
 }
 
 // NewWriteBackend returns a new instance of WriteBackend.
@@ -46,10 +76,13 @@ func NewWriteBackend(log *zap.Logger, b *APIBackend) *WriteBackend {
 		HTTPErrorHandler:   b.HTTPErrorHandler,
 		log:                log,
 		WriteEventRecorder: b.WriteEventRecorder,
+		WriteHinter:        b.WriteHinter,
 
 		PointsWriter:        b.PointsWriter,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
+
+		MeasurementSchemaService: b.MeasurementSchemaService,
 	}
 }
 
@@ -64,13 +97,26 @@ type WriteHandler struct {
 
 	PointsWriter storage.PointsWriter
 
+	// MeasurementSchemaService, if set, provides the schemas that the points
+	// written to buckets with an explicit schema must conform to.
+	MeasurementSchemaService influxdb.MeasurementSchemaService
+
 	EventRecorder metric.EventRecorder
 
+	// WriteHinter, if set, provides the hints returned in the headers of
+	// write responses.
+	WriteHinter WriteHinter
+
 	maxBatchSizeBytes int64
 	parserOptions     []models.ParserOption
 	parserMaxBytes    int
 	parserMaxLines    int
 	parserMaxValues   int
+
+	// writeSlots holds a value for each write being handled, if the number
+	// of concurrent writes is limited.
+	maxConcurrentWrites int
+	writeSlots          chan struct{}
 }
 
 // WriteHandlerOption is a functional option for a *WriteHandler
@@ -108,6 +154,15 @@ func WithParserMaxValues(n int) WriteHandlerOption {
 	}
 }
 
+// WithMaxConcurrentWrites specifies the maximum number of write requests that
+// may be handled at once. Writes beyond it are rejected with a 429 response.
+// When n is zero, there is no limit.
+func WithMaxConcurrentWrites(n int) WriteHandlerOption {
+	return func(w *WriteHandler) {
+		w.maxConcurrentWrites = n
+	}
+}
+
 // Prefix provides the route prefix.
 func (*WriteHandler) Prefix() string {
 	return prefixWrite
@@ -117,6 +172,10 @@ const (
 	prefixWrite          = "/api/v2/write"
 	errInvalidGzipHeader = "gzipped HTTP body contains an invalid header"
 	errInvalidPrecision  = "invalid precision; valid precision units are ns, us, ms, and s"
+
+	// writeRetryAfter is the number of seconds clients are asked to wait
+	// before retrying writes rejected for exceeding the concurrent writes.
+	writeRetryAfter = 1
 )
 
 // NewWriteHandler creates a new handler at /api/v2/write to receive line protocol.
@@ -130,6 +189,9 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
 		EventRecorder:       b.WriteEventRecorder,
+		WriteHinter:         b.WriteHinter,
+
+		MeasurementSchemaService: b.MeasurementSchemaService,
 	}
 
 	for _, opt := range opts {
@@ -146,6 +208,9 @@ func NewWriteHandler(log *zap.Logger, b *WriteBackend, opts ...WriteHandlerOptio
 	if h.parserMaxValues > 0 {
 		h.parserOptions = append(h.parserOptions, models.WithParserMaxValues(h.parserMaxValues))
 	}
+	if h.maxConcurrentWrites > 0 {
+		h.writeSlots = make(chan struct{}, h.maxConcurrentWrites)
+	}
 
 	h.HandlerFunc("POST", prefixWrite, h.handleWrite)
 	return h
@@ -184,6 +249,23 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		})
 	}()
 
+	if h.writeSlots != nil {
+		select {
+		case h.writeSlots <- struct{}{}:
+			defer func() { <-h.writeSlots }()
+		default:
+			w.Header().Set("Retry-After", strconv.Itoa(writeRetryAfter))
+			handleError(nil, influxdb.ETooManyRequests, "too many concurrent writes")
+			return
+		}
+	}
+
+	if h.maxBatchSizeBytes > 0 && r.ContentLength > h.maxBatchSizeBytes {
+		// The body is larger than the limit before it is even decompressed.
+		handleError(ErrMaxBatchSizeExceeded, influxdb.ETooLarge, "unable to read data")
+		return
+	}
+
 	a, err := pcontext.GetAuthorizer(ctx)
 	if err != nil {
 		h.HandleHTTPError(ctx, err, w)
@@ -248,6 +330,11 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		handleError(err, influxdb.EForbidden, "insufficient permissions for write")
 		return
 	}
+	scope := influxdb.AuthorizerSeriesScope(a, *p)
+
+	if h.WriteHinter != nil {
+		setWriteHints(w, h.WriteHinter.WriteHints())
+	}
 
 	data, err := readWriteRequest(ctx, r.Body, r.Header.Get("Content-Encoding"), h.maxBatchSizeBytes)
 	if err != nil {
@@ -284,6 +371,13 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		options = append(options, req.Precision)
 	}
 
+	// The lines of the points are needed to report those that are dropped,
+	// that do not conform to the schema of the bucket, or that are outside of
+	// the series the write is restricted to.
+	var stats models.ParserStats
+	options = append(options, models.WithParserStats(&stats))
+	checkSchema := bucket.ExplicitSchema() && h.MeasurementSchemaService != nil
+
 	points, err := models.ParsePointsWithOptions(data, mm, options...)
 	span.LogKV("values_total", len(points))
 	span.Finish()
@@ -301,8 +395,40 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if scope != nil {
+		if err := checkSeriesScope(scope, points, stats.Lines); err != nil {
+			log.Info("Points outside of the series the write is restricted to", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	if checkSchema {
+		if err := checkMeasurementSchemas(ctx, h.MeasurementSchemaService, bucket.ID, points, stats.Lines); err != nil {
+			log.Info("Points do not conform to the schema of the bucket", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		ctx = storage.NewContextWithIdempotencyKey(ctx, key)
+	}
+
 	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
+		if pwe, ok := partialWriteError(err); ok {
+			// Some points were dropped by the storage engine, and the others
+			// were written.
+			log.Info("Points dropped from write", zap.Error(err))
+			writePartialWriteResponse(w, pwe, len(points), stats.Lines)
+			return
+		}
 		log.Error("Error writing points", zap.Error(err))
+		if influxdb.ErrorCode(err) == influxdb.EUnprocessableEntity {
+			// Points were dropped by a limit of the storage engine.
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
 		handleError(err, influxdb.EInternal, "unexpected error writing points to database")
 		return
 	}
@@ -310,6 +436,86 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// maxPartialWriteFailures is the maximum number of failures listed in the
+// response to a partial write.
+const maxPartialWriteFailures = 100
+
+// partialWriteResponse is the body of the response to a write of which some
+// points were dropped by the storage engine. The fields of a line are written
+// as separate points.
+type partialWriteResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	// Accepted and Dropped are the numbers of points written and dropped.
+	Accepted int `json:"accepted"`
+	Dropped  int `json:"dropped"`
+
+	// Failures lists, by line, the first points that were dropped.
+	Failures []partialWriteFailure `json:"failures"`
+}
+
+type partialWriteFailure struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// partialWriteError returns the partial write error of an error returned by
+// WritePoints, if some points were written.
+func partialWriteError(err error) (tsdb.PartialWriteError, bool) {
+	if e, ok := err.(*influxdb.Error); ok {
+		err = e.Err
+	}
+	pwe, ok := err.(tsdb.PartialWriteError)
+	return pwe, ok
+}
+
+// writePartialWriteResponse writes the response to a write of n points of
+// which those of pwe were dropped. lines holds the line of each point.
+func writePartialWriteResponse(w http.ResponseWriter, pwe tsdb.PartialWriteError, n int, lines []int) {
+	res := partialWriteResponse{
+		Code:     influxdb.EUnprocessableEntity,
+		Message:  pwe.Error(),
+		Accepted: n - len(pwe.Points),
+		Dropped:  len(pwe.Points),
+		Failures: []partialWriteFailure{},
+	}
+
+	lastLine, lastReason := -1, ""
+	for _, p := range pwe.Points {
+		if len(res.Failures) == maxPartialWriteFailures {
+			break
+		}
+
+		line := p.Index + 1
+		if p.Index >= 0 && p.Index < len(lines) {
+			line = lines[p.Index]
+		}
+		// Do not repeat the reason a line was dropped for each of its fields.
+		if line == lastLine && p.Reason == lastReason {
+			continue
+		}
+		lastLine, lastReason = line, p.Reason
+		res.Failures = append(res.Failures, partialWriteFailure{Line: line, Reason: p.Reason})
+	}
+
+	w.Header().Set(kithttp.PlatformErrorCodeHeader, influxdb.EUnprocessableEntity)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// setWriteHints sets the headers of a write response to hints.
+func setWriteHints(w http.ResponseWriter, hints storage.WriteHints) {
+	compression := "identity"
+	if hints.Compress {
+		compression = "gzip"
+	}
+	w.Header().Set(WriteBatchSizeHeader, strconv.Itoa(hints.BatchSize))
+	w.Header().Set(WriteBackoffHeader, strconv.FormatInt(hints.Backoff.Milliseconds(), 10))
+	w.Header().Set(WriteCompressionHeader, compression)
+}
+
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
 	qp := r.URL.Query()
 	p := qp.Get("precision")
@@ -417,6 +623,9 @@ func (s *WriteService) Write(ctx context.Context, orgID, bucketID influxdb.ID, r
 
 	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
 	req.Header.Set("Content-Encoding", "gzip")
+	if key := storage.IdempotencyKeyFromContext(ctx); key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
 	SetToken(s.Token, req)
 
 	org, err := orgID.Encode()
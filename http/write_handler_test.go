@@ -3,20 +3,26 @@ package http
 import (
 	"compress/gzip"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/http/metric"
 	httpmock "github.com/influxdata/influxdb/http/mock"
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
 	influxtesting "github.com/influxdata/influxdb/testing"
+	"github.com/influxdata/influxdb/tsdb"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -142,6 +148,24 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"internal error","message":"unexpected error writing points to database: error"}`,
 			},
 		},
+		{
+			name: "points dropped by a storage limit returns 422 error",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1",
+				auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:      testOrg("043e0780ee2b1000"),
+				bucket:   testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeErr: &influxdb.Error{Code: influxdb.EUnprocessableEntity, Msg: "partial write: dropped=1"},
+			},
+			wants: wants{
+				code: 422,
+				body: `{"code":"unprocessable entity","message":"partial write: dropped=1"}`,
+			},
+		},
 		{
 			name: "empty request body returns 400 error",
 			request: request{
@@ -311,6 +335,39 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"request too large","message":"points: number of lines exceeded"}`,
 			},
 		},
+		{
+			name: "points of the series the permission is restricted to are accepted",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1\nm1,t1=v1 f2=2",
+				auth:   scopedBucketWritePermission("043e0780ee2b1000", "04504b356e23b000", "m1"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "points outside of the series the permission is restricted to are forbidden",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1\nm2,t1=v1 f1=1,f2=2",
+				auth:   scopedBucketWritePermission("043e0780ee2b1000", "04504b356e23b000", "m1"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 403,
+				body: `{"code":"forbidden","message":"line 2: series of measurement \"m2\" is not allowed to be written"}`,
+			},
+		},
 		{
 			name: "values limit rejected",
 			request: request{
@@ -376,6 +433,296 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 	}
 }
 
+type writeHinterFunc func() storage.WriteHints
+
+func (fn writeHinterFunc) WriteHints() storage.WriteHints { return fn() }
+
+func TestWriteHandler_writeHints(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+	}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter:        &mock.PointsWriter{},
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+		WriteHinter: writeHinterFunc(func() storage.WriteHints {
+			return storage.WriteHints{BatchSize: 7500, Backoff: 2500 * time.Millisecond, Compress: true}
+		}),
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+	r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader("m1,t1=v1 f1=1"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if got, want := w.Code, http.StatusNoContent; got != want {
+		t.Fatalf("unexpected status code: got %d want %d", got, want)
+	}
+
+	for header, want := range map[string]string{
+		WriteBatchSizeHeader:   "7500",
+		WriteBackoffHeader:     "2500",
+		WriteCompressionHeader: "gzip",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("unexpected %s header: got %q want %q", header, got, want)
+		}
+	}
+}
+
+type pointsWriterFunc func(ctx context.Context, points []models.Point) error
+
+func (fn pointsWriterFunc) WritePoints(ctx context.Context, points []models.Point) error {
+	return fn(ctx, points)
+}
+
+func TestWriteHandler_idempotencyKey(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+	}
+
+	var keys []string
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter: pointsWriterFunc(func(ctx context.Context, points []models.Point) error {
+			keys = append(keys, storage.IdempotencyKeyFromContext(ctx))
+			return nil
+		}),
+		WriteEventRecorder: &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+	for _, key := range []string{"batch-1", ""} {
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader("m1,t1=v1 f1=1"))
+		if key != "" {
+			r.Header.Set(IdempotencyKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if got, want := w.Code, http.StatusNoContent; got != want {
+			t.Fatalf("unexpected status code: got %d want %d", got, want)
+		}
+	}
+
+	if want := []string{"batch-1", ""}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("unexpected idempotency keys: got %q want %q", keys, want)
+	}
+}
+
+func TestWriteHandler_maxConcurrentWrites(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+	}
+
+	writing, release := make(chan struct{}), make(chan struct{})
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter: pointsWriterFunc(func(ctx context.Context, points []models.Point) error {
+			writing <- struct{}{}
+			<-release
+			return nil
+		}),
+		WriteEventRecorder: &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b), WithMaxConcurrentWrites(1))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+	write := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader("m1,t1=v1 f1=1"))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- write() }()
+	<-writing
+
+	// The first write holds the only slot, so the second is rejected.
+	w := write()
+	if got, want := w.Code, http.StatusTooManyRequests; got != want {
+		t.Fatalf("unexpected status code: got %d want %d", got, want)
+	}
+	if got, want := w.Header().Get("Retry-After"), "1"; got != want {
+		t.Errorf("unexpected Retry-After header: got %q want %q", got, want)
+	}
+	if got, want := w.Body.String(), `{"code":"too many requests","message":"too many concurrent writes"}`; got != want {
+		t.Errorf("unexpected body: got %s want %s", got, want)
+	}
+
+	close(release)
+	if got, want := (<-done).Code, http.StatusNoContent; got != want {
+		t.Fatalf("unexpected status code: got %d want %d", got, want)
+	}
+
+	// The slot is released once the first write is handled.
+	go func() { <-writing }()
+	if got, want := write().Code, http.StatusNoContent; got != want {
+		t.Fatalf("unexpected status code: got %d want %d", got, want)
+	}
+}
+
+func TestWriteHandler_explicitSchema(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		b := testBucket("043e0780ee2b1000", "04504b356e23b000")
+		b.SchemaType = influxdb.SchemaTypeExplicit
+		return b, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasF = func(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+		return []*influxdb.MeasurementSchema{{
+			Name: "cpu",
+			Columns: []influxdb.MeasurementSchemaColumn{
+				{Name: "host", Type: influxdb.SchemaColumnTypeTag},
+				{Name: "usage", Type: influxdb.SchemaColumnTypeField, DataType: influxdb.SchemaDataTypeFloat},
+				{Name: "cores", Type: influxdb.SchemaColumnTypeField, DataType: influxdb.SchemaDataTypeInteger},
+			},
+		}}, nil
+	}
+
+	var written int
+	b := &APIBackend{
+		HTTPErrorHandler:         DefaultErrorHandler,
+		Logger:                   zaptest.NewLogger(t),
+		OrganizationService:      orgs,
+		BucketService:            buckets,
+		MeasurementSchemaService: schemas,
+		PointsWriter: pointsWriterFunc(func(ctx context.Context, points []models.Point) error {
+			written += len(points)
+			return nil
+		}),
+		WriteEventRecorder: &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+	write := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := write("cpu,host=a usage=1.5,cores=4i"); w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: got %d want %d: %s", w.Code, http.StatusNoContent, w.Body)
+	} else if written != 2 {
+		t.Fatalf("unexpected points written: got %d want 2", written)
+	}
+
+	w := write("cpu,host=a usage=1.5\n\ncpu,host=a usage=\"high\"\ncpu,region=west usage=1,cores=2i\nmem used=1i\ncpu cores=1.5")
+	if got, want := w.Code, http.StatusBadRequest; got != want {
+		t.Fatalf("unexpected status code: got %d want %d", got, want)
+	} else if written != 2 {
+		t.Fatalf("unexpected points written: got %d want 2", written)
+	}
+	for _, want := range []string{
+		`line 3: field \"usage\" of measurement \"cpu\" has type string, expected float`,
+		`line 4: tag \"region\" is not in the schema of measurement \"cpu\"`,
+		`line 5: measurement \"mem\" is not in the schema of the bucket`,
+		`line 6: field \"cores\" of measurement \"cpu\" has type float, expected integer`,
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("expected error %s in response: %s", want, w.Body)
+		}
+	}
+	if strings.Contains(w.Body.String(), "line 1:") || strings.Count(w.Body.String(), "line 4:") != 1 {
+		t.Errorf("unexpected errors in response: %s", w.Body)
+	}
+}
+
+func TestWriteHandler_partialWrite(t *testing.T) {
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return testOrg("043e0780ee2b1000"), nil
+	}
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(context.Context, influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		return testBucket("043e0780ee2b1000", "04504b356e23b000"), nil
+	}
+
+	// The engine drops both fields of the second line, and the third line.
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		OrganizationService: orgs,
+		BucketService:       buckets,
+		PointsWriter: pointsWriterFunc(func(ctx context.Context, points []models.Point) error {
+			return &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Err: tsdb.PartialWriteError{
+					Reason:  "limit exceeded",
+					Dropped: 2,
+					Points: []tsdb.DroppedPoint{
+						{Index: 1, Reason: "limit exceeded"},
+						{Index: 2, Reason: "limit exceeded"},
+						{Index: 3, Reason: "outside retention"},
+					},
+				},
+			}
+		}),
+		WriteEventRecorder: &metric.NopEventRecorder{},
+	}
+	writeHandler := NewWriteHandler(zaptest.NewLogger(t), NewWriteBackend(zaptest.NewLogger(t), b))
+	handler := httpmock.NewAuthMiddlewareHandler(writeHandler, bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"))
+
+	r := httptest.NewRequest("POST", "http://localhost:9999/api/v2/write?org=043e0780ee2b1000&bucket=04504b356e23b000",
+		strings.NewReader("cpu,host=a usage=1\ncpu,host=b usage=1,cores=2i\n\ncpu,host=c usage=1"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	if got, want := w.Code, http.StatusUnprocessableEntity; got != want {
+		t.Fatalf("unexpected status code: got %d want %d: %s", got, want, w.Body)
+	}
+	var res partialWriteResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	want := partialWriteResponse{
+		Code:     influxdb.EUnprocessableEntity,
+		Message:  "partial write: limit exceeded dropped=2",
+		Accepted: 1,
+		Dropped:  3,
+		Failures: []partialWriteFailure{
+			{Line: 2, Reason: "limit exceeded"},
+			{Line: 4, Reason: "outside retention"},
+		},
+	}
+	if !reflect.DeepEqual(res, want) {
+		t.Fatalf("unexpected response: got %+v want %+v", res, want)
+	}
+}
+
 var DefaultErrorHandler = kithttp.ErrorHandler(0)
 
 func bucketWritePermission(org, bucket string) *influxdb.Authorization {
@@ -397,6 +744,12 @@ func bucketWritePermission(org, bucket string) *influxdb.Authorization {
 	}
 }
 
+func scopedBucketWritePermission(org, bucket string, measurements ...string) *influxdb.Authorization {
+	a := bucketWritePermission(org, bucket)
+	a.Permissions[0].Resource.Measurements = measurements
+	return a
+}
+
 func testOrg(org string) *influxdb.Organization {
 	oid := influxtesting.MustIDBase16(org)
 	return &influxdb.Organization{
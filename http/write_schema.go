@@ -0,0 +1,57 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+)
+
+// checkMeasurementSchemas returns an error listing, by line, the points that
+// do not conform to the measurement schemas of the bucket with an explicit
+// schema. lines holds the line of each point.
+func checkMeasurementSchemas(ctx context.Context, svc influxdb.MeasurementSchemaService, bucketID influxdb.ID, points []models.Point, lines []int) error {
+	schemas, err := svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: &bucketID})
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*influxdb.MeasurementSchema, len(schemas))
+	for _, ms := range schemas {
+		byName[ms.Name] = ms
+	}
+
+	var (
+		failed   []string
+		lastLine = -1
+		lastErr  string
+	)
+	for i, p := range points {
+		msg := storage.CheckPointSchema(p, byName)
+		if msg == "" {
+			continue
+		}
+
+		line := i + 1
+		if i < len(lines) {
+			line = lines[i]
+		}
+		// The fields of a line are parsed as separate points, so do not
+		// repeat the error of a measurement or tag for each of them.
+		if line == lastLine && msg == lastErr {
+			continue
+		}
+		lastLine, lastErr = line, msg
+		failed = append(failed, fmt.Sprintf("line %d: %s", line, msg))
+	}
+
+	if len(failed) > 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  strings.Join(failed, "\n"),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+// checkSeriesScope returns an error listing, by line, the points that are
+// not of the series of the bucket the permission to write is restricted to.
+// lines holds the line of each point.
+func checkSeriesScope(scope influxdb.SeriesScope, points []models.Point, lines []int) error {
+	m, err := scope.Matcher()
+	if err != nil {
+		return err
+	}
+
+	var (
+		failed   []string
+		lastLine = -1
+	)
+	for i, p := range points {
+		tags := p.Tags()
+		measurement := string(tags.Get(models.MeasurementTagKeyBytes))
+		if m.Match(measurement, func(key string) string { return string(tags.Get([]byte(key))) }) {
+			continue
+		}
+
+		line := i + 1
+		if i < len(lines) {
+			line = lines[i]
+		}
+		// The fields of a line are parsed as separate points.
+		if line == lastLine {
+			continue
+		}
+		lastLine = line
+		failed = append(failed, fmt.Sprintf("line %d: series of measurement %q is not allowed to be written", line, measurement))
+	}
+
+	if len(failed) > 0 {
+		return &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  strings.Join(failed, "\n"),
+		}
+	}
+	return nil
+}
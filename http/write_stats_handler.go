@@ -0,0 +1,127 @@
+package http
+
+import (
+	"fmt"
+	http "net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixWriteStats = "/api/v2/stats/writes"
+)
+
+// WriteStatsBackend is all services and associated parameters required to
+// construct the WriteStatsHandler.
+type WriteStatsBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	WriteStatsService influxdb.WriteStatsService
+}
+
+// NewWriteStatsBackend returns a new instance of WriteStatsBackend.
+func NewWriteStatsBackend(log *zap.Logger, b *APIBackend) *WriteStatsBackend {
+	return &WriteStatsBackend{
+		log: log,
+
+		HTTPErrorHandler:  b.HTTPErrorHandler,
+		WriteStatsService: b.WriteStatsService,
+	}
+}
+
+// WriteStatsHandler serves per-measurement write statistics.
+type WriteStatsHandler struct {
+	influxdb.HTTPErrorHandler
+	*httprouter.Router
+
+	log *zap.Logger
+
+	WriteStatsService influxdb.WriteStatsService
+}
+
+// NewWriteStatsHandler creates a new handler at /api/v2/stats/writes to serve
+// write statistics.
+func NewWriteStatsHandler(log *zap.Logger, b *WriteStatsBackend) *WriteStatsHandler {
+	h := &WriteStatsHandler{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		Router:           NewRouter(b.HTTPErrorHandler),
+		log:              log,
+
+		WriteStatsService: b.WriteStatsService,
+	}
+
+	h.HandlerFunc("GET", prefixWriteStats, h.handleGetWriteStats)
+	return h
+}
+
+type writeStatsResponse struct {
+	Stats []*influxdb.MeasurementWriteStats `json:"stats"`
+}
+
+func (h *WriteStatsHandler) handleGetWriteStats(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "WriteStatsHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+
+	filter, err := decodeWriteStatsFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	stats, err := h.WriteStatsService.FindMeasurementWriteStats(ctx, *filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if stats == nil {
+		stats = []*influxdb.MeasurementWriteStats{}
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, writeStatsResponse{Stats: stats}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeWriteStatsFilter(r *http.Request) (*influxdb.WriteStatsFilter, error) {
+	filter := &influxdb.WriteStatsFilter{}
+	qp := r.URL.Query()
+
+	if orgID := qp.Get(OrgID); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			return nil, err
+		}
+		filter.OrgID = id
+	}
+
+	if bucketID := qp.Get(BucketID); bucketID != "" {
+		id, err := influxdb.IDFromString(bucketID)
+		if err != nil {
+			return nil, err
+		}
+		filter.BucketID = id
+	}
+
+	filter.SortBy = qp.Get("sortBy")
+
+	if limit := qp.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("limit must be a positive integer, got %q", limit),
+			}
+		}
+		filter.Limit = l
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWriteStatsHandler_handleGetWriteStats(t *testing.T) {
+	type wants struct {
+		statusCode int
+		filter     *influxdb.WriteStatsFilter
+		body       string
+	}
+
+	orgID, bucketID := influxdb.ID(0x020f755c3c082000), influxdb.ID(0x020f755c3c082001)
+
+	tests := []struct {
+		name        string
+		queryParams map[string][]string
+		wants       wants
+	}{
+		{
+			name:        "all stats",
+			queryParams: map[string][]string{},
+			wants: wants{
+				statusCode: http.StatusOK,
+				filter:     &influxdb.WriteStatsFilter{},
+				body: `{"stats": [{
+					"orgID": "020f755c3c082000",
+					"bucketID": "020f755c3c082001",
+					"measurement": "cpu",
+					"points": 10,
+					"bytes": 200,
+					"newSeries": 2,
+					"totalPoints": 20,
+					"totalBytes": 400,
+					"totalSeries": 3
+				}]}`,
+			},
+		},
+		{
+			name: "filtered and sorted",
+			queryParams: map[string][]string{
+				"orgID":    {"020f755c3c082000"},
+				"bucketID": {"020f755c3c082001"},
+				"sortBy":   {"bytes"},
+				"limit":    {"5"},
+			},
+			wants: wants{
+				statusCode: http.StatusOK,
+				filter: &influxdb.WriteStatsFilter{
+					OrgID:    &orgID,
+					BucketID: &bucketID,
+					SortBy:   influxdb.WriteStatsSortBytes,
+					Limit:    5,
+				},
+			},
+		},
+		{
+			name: "invalid limit",
+			queryParams: map[string][]string{
+				"limit": {"-1"},
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+				body:       `{"code": "invalid", "message": "limit must be a positive integer, got \"-1\""}`,
+			},
+		},
+		{
+			name: "invalid org id",
+			queryParams: map[string][]string{
+				"orgID": {"bad"},
+			},
+			wants: wants{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFilter *influxdb.WriteStatsFilter
+			svc := mock.NewWriteStatsService()
+			svc.FindMeasurementWriteStatsF = func(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+				gotFilter = &filter
+				return []*influxdb.MeasurementWriteStats{{
+					OrgID:       orgID,
+					BucketID:    bucketID,
+					Measurement: "cpu",
+					Points:      10,
+					Bytes:       200,
+					NewSeries:   2,
+					TotalPoints: 20,
+					TotalBytes:  400,
+					TotalSeries: 3,
+				}}, nil
+			}
+
+			h := NewWriteStatsHandler(zaptest.NewLogger(t), &WriteStatsBackend{
+				HTTPErrorHandler:  kithttp.ErrorHandler(0),
+				WriteStatsService: svc,
+			})
+
+			r := httptest.NewRequest("GET", "http://any.tld"+prefixWriteStats, nil)
+			qp := r.URL.Query()
+			for k, vs := range tt.queryParams {
+				for _, v := range vs {
+					qp.Add(k, v)
+				}
+			}
+			r.URL.RawQuery = qp.Encode()
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.wants.statusCode {
+				t.Errorf("handleGetWriteStats() = %v, want %v: %s", res.StatusCode, tt.wants.statusCode, body)
+			}
+			if tt.wants.filter != nil {
+				if gotFilter == nil {
+					t.Fatal("expected FindMeasurementWriteStats to be called")
+				}
+				if !writeStatsFilterEqual(*gotFilter, *tt.wants.filter) {
+					t.Errorf("got filter %+v, want %+v", *gotFilter, *tt.wants.filter)
+				}
+			}
+			if tt.wants.body != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wants.body); err != nil {
+					t.Errorf("handleGetWriteStats(). error unmarshaling json %v", err)
+				} else if !eq {
+					t.Errorf("handleGetWriteStats() = ***%s***", diff)
+				}
+			}
+		})
+	}
+}
+
+func writeStatsFilterEqual(a, b influxdb.WriteStatsFilter) bool {
+	idEqual := func(x, y *influxdb.ID) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	return idEqual(a.OrgID, b.OrgID) && idEqual(a.BucketID, b.BucketID) &&
+		a.SortBy == b.SortBy && a.Limit == b.Limit
+}
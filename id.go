@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"reflect"
 	"strconv"
+	"sync"
 	"unsafe"
 )
 
@@ -36,6 +37,33 @@ type IDGenerator interface {
 	ID() ID
 }
 
+// SequentialIDGenerator generates consecutive IDs, so that states built with
+// it are reproducible.
+//
+// Safe for concurrent use by multiple goroutines.
+type SequentialIDGenerator struct {
+	mu   sync.Mutex
+	next ID
+}
+
+// NewSequentialIDGenerator creates a SequentialIDGenerator whose first ID is
+// start, or 1 if start is not valid.
+func NewSequentialIDGenerator(start ID) *SequentialIDGenerator {
+	if !start.Valid() {
+		start = 1
+	}
+	return &SequentialIDGenerator{next: start}
+}
+
+// ID returns the next ID in the sequence.
+func (g *SequentialIDGenerator) ID() ID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := g.next
+	g.next++
+	return id
+}
+
 // IDFromString creates an ID from a given string.
 //
 // It errors if the input string does not match a valid ID.
@@ -234,6 +234,19 @@ func TestID_GoString(t *testing.T) {
 	}
 }
 
+func TestSequentialIDGenerator(t *testing.T) {
+	gen := influxdb.NewSequentialIDGenerator(0x1000)
+	for _, exp := range []influxdb.ID{0x1000, 0x1001, 0x1002} {
+		if got := gen.ID(); got != exp {
+			t.Fatalf("unexpected ID; got=%s, exp=%s", got, exp)
+		}
+	}
+
+	if got := influxdb.NewSequentialIDGenerator(0).ID(); got != 1 {
+		t.Fatalf("unexpected first ID of invalid start; got=%s, exp=%s", got, influxdb.ID(1))
+	}
+}
+
 func BenchmarkIDEncode(b *testing.B) {
 	var id influxdb.ID
 	id.DecodeFromString("5ca1ab1eba5eba11")
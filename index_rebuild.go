@@ -0,0 +1,52 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// IndexRebuildStatus is the state of an index rebuild.
+type IndexRebuildStatus string
+
+// Index rebuild statuses.
+const (
+	IndexRebuildRunning   IndexRebuildStatus = "running"
+	IndexRebuildCompleted IndexRebuildStatus = "completed"
+	IndexRebuildFailed    IndexRebuildStatus = "failed"
+)
+
+// IndexRebuild rebuilds the series index of a bucket from the data stored for
+// it, replacing the series indexed for the bucket.
+type IndexRebuild struct {
+	ID       ID `json:"id,omitempty"`
+	OrgID    ID `json:"orgID"`
+	BucketID ID `json:"bucketID"`
+
+	Status IndexRebuildStatus `json:"status"`
+	Error  string             `json:"error,omitempty"`
+
+	// Series is the number of series of the bucket found in its data.
+	Series int `json:"series"`
+
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// IndexRebuildFilter restricts the index rebuilds returned by a lookup.
+type IndexRebuildFilter struct {
+	OrgID *ID
+}
+
+// IndexRebuildService rebuilds the series index of buckets in the background.
+type IndexRebuildService interface {
+	// RebuildIndex starts rebuilding the series index of r.BucketID. It sets
+	// the ID, status and start time of r, and returns without waiting for the
+	// rebuild to finish.
+	RebuildIndex(ctx context.Context, r *IndexRebuild) error
+
+	// FindIndexRebuildByID returns a single index rebuild by ID.
+	FindIndexRebuildByID(ctx context.Context, id ID) (*IndexRebuild, error)
+
+	// FindIndexRebuilds returns the index rebuilds matching filter.
+	FindIndexRebuilds(ctx context.Context, filter IndexRebuildFilter) ([]*IndexRebuild, error)
+}
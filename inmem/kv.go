@@ -20,9 +20,10 @@ const cursorBatchSize = 1000
 
 // KVStore is an in memory btree backed kv.Store.
 type KVStore struct {
-	mu      sync.RWMutex
-	buckets map[string]*Bucket
-	ro      map[string]*bucket
+	mu       sync.RWMutex
+	buckets  map[string]*Bucket
+	ro       map[string]*bucket
+	watchers kv.Watchers
 }
 
 // NewKVStore creates an instance of a KVStore.
@@ -50,17 +51,30 @@ func (s *KVStore) Update(ctx context.Context, fn func(kv.Tx) error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return fn(&Tx{
+	tx := &Tx{
 		kv:       s,
 		writable: true,
 		ctx:      ctx,
-	})
+		events:   &kv.EventRecorder{},
+	}
+	err := fn(tx)
+
+	// Transactions are not rolled back, so the changes made are published
+	// even if fn fails.
+	s.watchers.Publish(tx.events.Events...)
+	return err
 }
 
 func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
 	panic("not implemented")
 }
 
+// Watch returns a channel of the changes made to the keys of bucket with
+// prefix.
+func (s *KVStore) Watch(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error) {
+	return s.watchers.Watch(ctx, bucket, prefix), nil
+}
+
 // Flush removes all data from the buckets.  Used for testing.
 func (s *KVStore) Flush(ctx context.Context) {
 	s.mu.Lock()
@@ -88,6 +102,7 @@ type Tx struct {
 	kv       *KVStore
 	writable bool
 	ctx      context.Context
+	events   *kv.EventRecorder
 }
 
 // Context returns the context for the transaction.
@@ -108,10 +123,9 @@ func (t *Tx) createBucketIfNotExists(b []byte) (kv.Bucket, error) {
 			bkt = &Bucket{btree: btree.New(2)}
 			t.kv.buckets[string(b)] = bkt
 			t.kv.ro[string(b)] = &bucket{Bucket: bkt}
-			return bkt, nil
 		}
 
-		return bkt, nil
+		return t.events.Bucket(b, bkt), nil
 	}
 
 	return nil, kv.ErrTxNotWritable
@@ -125,7 +139,7 @@ func (t *Tx) Bucket(b []byte) (kv.Bucket, error) {
 	}
 
 	if t.writable {
-		return bkt, nil
+		return t.events.Bucket(b, bkt), nil
 	}
 
 	return t.kv.ro[string(b)], nil
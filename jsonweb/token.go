@@ -107,6 +107,11 @@ func (t *Token) Allowed(p influxdb.Permission) bool {
 	return false
 }
 
+// SeriesScope returns the series of the bucket of p the token allows p on.
+func (t *Token) SeriesScope(p influxdb.Permission) influxdb.SeriesScope {
+	return influxdb.PermissionSeriesScope(p, t.Permissions)
+}
+
 // Identifier returns the identifier for this Token
 // as found in the standard claims
 func (t *Token) Identifier() influxdb.ID {
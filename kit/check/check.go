@@ -17,6 +17,9 @@ type Status string
 const (
 	// StatusFail indicates a specific check has failed.
 	StatusFail Status = "fail"
+	// StatusWarn indicates a specific check has passed, but the service is
+	// degraded.
+	StatusWarn Status = "warn"
 	// StatusPass indicates a specific check has passed.
 	StatusPass Status = "pass"
 
@@ -24,6 +27,22 @@ const (
 	DefaultCheckName = "internal"
 )
 
+// severity ranks s, so that the status of a group of checks is the status of
+// its most severe check. Unknown statuses, like those of manual overrides,
+// rank above failures.
+func (s Status) severity() int {
+	switch s {
+	case StatusPass:
+		return 0
+	case StatusWarn:
+		return 1
+	case StatusFail:
+		return 2
+	default:
+		return 3
+	}
+}
+
 // Check wraps a map of service names to status checkers.
 type Check struct {
 	healthChecks   []Checker
@@ -86,7 +105,7 @@ func (c *Check) CheckHealth(ctx context.Context) Response {
 	}
 	for i, ch := range c.healthChecks {
 		resp := ch.Check(ctx)
-		if resp.Status != StatusPass && !overriding {
+		if resp.Status.severity() > response.Status.severity() && !overriding {
 			response.Status = resp.Status
 		}
 		response.Checks[i] = resp
@@ -114,7 +133,7 @@ func (c *Check) CheckReady(ctx context.Context) Response {
 	}
 	for i, c := range c.readyChecks {
 		resp := c.Check(ctx)
-		if resp.Status != StatusPass && !overriding {
+		if resp.Status.severity() > response.Status.severity() && !overriding {
 			response.Status = resp.Status
 		}
 		response.Checks[i] = resp
@@ -188,7 +207,7 @@ func (c *Check) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // accompanying the payload is the primary means for signaling the status of the
 // checks. The possible status codes are:
 //
-// - 200 OK: All checks pass.
+// - 200 OK: All checks pass, some possibly with warnings.
 // - 503 Service Unavailable: Some checks are failing.
 // - 500 Internal Server Error: There was a problem serializing the Response.
 func writeResponse(w http.ResponseWriter, resp Response) {
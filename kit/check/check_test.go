@@ -165,6 +165,64 @@ func TestHealthSorting(t *testing.T) {
 	}
 }
 
+func TestHealthWarn(t *testing.T) {
+	c, ts := buildCheckWithServer()
+	defer ts.Close()
+
+	c.AddHealthCheck(mockPass("a"))
+	c.AddHealthCheck(Named("b", CheckerFunc(func(context.Context) Response {
+		return Warn("queue-full", "%d queued", 2)
+	})))
+
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status code. expected %v, actual %v", http.StatusOK, resp.StatusCode)
+	}
+	actual, err := respBuilder(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Response{
+		Name:   "Health",
+		Status: "warn",
+		Checks: Responses{
+			Response{Name: "b", Status: "warn", Reason: "queue-full", Message: "2 queued"},
+			Response{Name: "a", Status: "pass"},
+		},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("unexpected response. expected %v, actual %v", expected, actual)
+	}
+
+	// Failures are more severe than warnings, whatever the order of the checks.
+	c.AddHealthCheck(mockFail("c"))
+	c.AddHealthCheck(Named("d", CheckerFunc(func(context.Context) Response {
+		return Warn("behind", "behind")
+	})))
+
+	resp, err = http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("unexpected status code. expected %v, actual %v", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	actual, err = respBuilder(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.Status != StatusFail {
+		t.Errorf("unexpected status. expected %v, actual %v", StatusFail, actual.Status)
+	}
+	if names := []string{actual.Checks[0].Name, actual.Checks[1].Name, actual.Checks[2].Name, actual.Checks[3].Name}; !reflect.DeepEqual(names, []string{"c", "b", "d", "a"}) {
+		t.Errorf("unexpected order of checks %v", names)
+	}
+}
+
 func TestForceHealthy(t *testing.T) {
 	c, ts := buildCheckWithServer()
 	defer ts.Close()
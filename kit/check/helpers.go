@@ -71,3 +71,23 @@ func Error(err error) Response {
 		Message: err.Error(),
 	}
 }
+
+// Warn is a utility function to generate a passing status with a printf
+// message for a degraded service, and the reason it is degraded.
+func Warn(reason, msg string, args ...interface{}) Response {
+	return Response{
+		Status:  StatusWarn,
+		Reason:  reason,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
+
+// Fail is a utility function to generate a failing status with a printf
+// message, and the reason for the failure.
+func Fail(reason, msg string, args ...interface{}) Response {
+	return Response{
+		Status:  StatusFail,
+		Reason:  reason,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
@@ -68,6 +68,11 @@ func ExtractFromHTTPRequest(req *http.Request, handlerName string) (opentracing.
 }
 
 func annotateSpan(span opentracing.Span, handlerName string, req *http.Request) {
+	// The priority is set first, as the tags of a span that is not sampled
+	// are discarded.
+	if IsDebug(req.Context()) {
+		ext.SamplingPriority.Set(span, 1)
+	}
 	if route := httprouter.MatchedRouteFromContext(req.Context()); route != "" {
 		span.SetTag("route", route)
 	}
@@ -75,6 +80,21 @@ func annotateSpan(span opentracing.Span, handlerName string, req *http.Request)
 	span.LogKV("path", req.URL.Path)
 }
 
+type debugContextKey struct{}
+
+// WithDebug returns a context for a request that is traced in full. The span
+// started for the request by ExtractFromHTTPRequest, and so all of its
+// children, are sampled regardless of the sampler of the tracer.
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugContextKey{}, true)
+}
+
+// IsDebug reports whether ctx is for a request that is traced in full.
+func IsDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(debugContextKey{}).(bool)
+	return debug
+}
+
 // StartSpanFromContext is an improved opentracing.StartSpanFromContext.
 // Uses the calling function as the operation name, and logs the filename and line number.
 //
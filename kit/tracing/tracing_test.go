@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/httprouter"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/uber/jaeger-client-go"
 )
 
 func TestInjectAndExtractHTTPRequest(t *testing.T) {
@@ -116,6 +117,37 @@ func TestExtractHTTPRequest(t *testing.T) {
 	}
 }
 
+func TestExtractHTTPRequest_Debug(t *testing.T) {
+	tracer, closer := jaeger.NewTracer("test", jaeger.NewConstSampler(false), jaeger.NewInMemoryReporter())
+	defer closer.Close()
+
+	oldTracer := opentracing.GlobalTracer()
+	opentracing.SetGlobalTracer(tracer)
+	defer opentracing.SetGlobalTracer(oldTracer)
+
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprint(debug), func(t *testing.T) {
+			request, err := http.NewRequest(http.MethodPost, "http://localhost/api/v2/query", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if debug {
+				request = request.WithContext(WithDebug(request.Context()))
+			}
+
+			span, request := ExtractFromHTTPRequest(request, "FluxHandler")
+			if _, sampled, _ := InfoFromSpan(span); sampled != debug {
+				t.Errorf("request span sampled: expect %v got %v", debug, sampled)
+			}
+
+			child, _ := StartSpanFromContext(request.Context())
+			if _, sampled, _ := InfoFromSpan(child); sampled != debug {
+				t.Errorf("child span sampled: expect %v got %v", debug, sampled)
+			}
+		})
+	}
+}
+
 func TestStartSpanFromContext(t *testing.T) {
 	tracer := mocktracer.New()
 
@@ -0,0 +1,146 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var auditLogBucket = []byte("auditlogv1")
+
+var _ influxdb.AuditLogService = (*Service)(nil)
+
+func (s *Service) initializeAuditLog(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(auditLogBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// auditEventKey returns the key of an event: the time it was recorded
+// followed by its ID, so that events are iterated in the order they were
+// recorded.
+func auditEventKey(e *influxdb.AuditEvent) ([]byte, error) {
+	id, err := e.ID.Encode()
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, 8, 8+len(id))
+	binary.BigEndian.PutUint64(key, uint64(e.Time.UnixNano()))
+	return append(key, id...), nil
+}
+
+// RecordAuditEvent appends an event to the audit log.
+func (s *Service) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	e.ID = s.IDGenerator.ID()
+	if e.Time.IsZero() {
+		e.Time = s.TimeGenerator.Now()
+	}
+	e.Time = e.Time.UTC()
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		key, err := auditEventKey(e)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(auditLogBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, data)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRecordAuditEvent,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindAuditEvents returns the events of the audit log that match filter.
+// Events are returned in the order they were recorded, or in reverse order
+// if opt is descending.
+func (s *Service) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+
+	var (
+		es []*influxdb.AuditEvent
+		n  int
+	)
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(auditLogBucket)
+		if err != nil {
+			return err
+		}
+
+		var seek []byte
+		if filter.Since != nil {
+			seek = make([]byte, 8)
+			binary.BigEndian.PutUint64(seek, uint64(filter.Since.UnixNano()))
+		}
+		cur, err := b.ForwardCursor(seek)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			e := &influxdb.AuditEvent{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return err
+			}
+			if filter.Until != nil && !e.Time.Before(*filter.Until) {
+				break
+			}
+			if !filter.Matches(e) {
+				continue
+			}
+
+			n++
+			switch {
+			case o.Descending:
+				// Only the last events, of which the page is made, are kept.
+				es = append(es, e)
+				if o.Limit > 0 && len(es) > o.Offset+o.Limit {
+					es = es[1:]
+				}
+			case n > o.Offset && (o.Limit == 0 || len(es) < o.Limit):
+				es = append(es, e)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindAuditEvents,
+			Err: err,
+		}
+	}
+
+	if o.Descending {
+		for i, j := 0, len(es)-1; i < j; i, j = i+1, j-1 {
+			es[i], es[j] = es[j], es[i]
+		}
+		if o.Offset < len(es) {
+			es = es[o.Offset:]
+		} else {
+			es = nil
+		}
+	}
+	if es == nil {
+		es = []*influxdb.AuditEvent{}
+	}
+	return es, n, nil
+}
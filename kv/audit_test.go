@@ -0,0 +1,112 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltAuditLogService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing audit log service: %v", err)
+	}
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, rt := range []influxdb.ResourceType{
+		influxdb.BucketsResourceType,
+		influxdb.OrgsResourceType,
+		influxdb.BucketsResourceType,
+		influxdb.BucketsResourceType,
+	} {
+		e := &influxdb.AuditEvent{
+			Time:         start.Add(time.Duration(i) * time.Minute),
+			UserID:       1,
+			Method:       "PATCH",
+			ResourceType: rt,
+			ResourceID:   influxdb.ID(10 + i),
+			StatusCode:   200,
+		}
+		if err := svc.RecordAuditEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		if !e.ID.Valid() {
+			t.Fatal("expected recorded event to have an ID")
+		}
+	}
+
+	ids := func(es []*influxdb.AuditEvent) []influxdb.ID {
+		out := make([]influxdb.ID, len(es))
+		for i, e := range es {
+			out[i] = e.ResourceID
+		}
+		return out
+	}
+	buckets := influxdb.BucketsResourceType
+	since := start.Add(time.Minute)
+	until := start.Add(3 * time.Minute)
+
+	tests := []struct {
+		name   string
+		filter influxdb.AuditEventFilter
+		opts   influxdb.FindOptions
+		want   []influxdb.ID
+		total  int
+	}{
+		{
+			name:  "every event",
+			want:  []influxdb.ID{10, 11, 12, 13},
+			total: 4,
+		},
+		{
+			name:   "events of a resource type",
+			filter: influxdb.AuditEventFilter{ResourceType: &buckets},
+			want:   []influxdb.ID{10, 12, 13},
+			total:  3,
+		},
+		{
+			name:   "events of a period",
+			filter: influxdb.AuditEventFilter{Since: &since, Until: &until},
+			want:   []influxdb.ID{11, 12},
+			total:  2,
+		},
+		{
+			name:  "page of events",
+			opts:  influxdb.FindOptions{Offset: 1, Limit: 2},
+			want:  []influxdb.ID{11, 12},
+			total: 4,
+		},
+		{
+			name:  "page of events in descending order",
+			opts:  influxdb.FindOptions{Offset: 1, Limit: 2, Descending: true},
+			want:  []influxdb.ID{12, 11},
+			total: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			es, n, err := svc.FindAuditEvents(ctx, tt.filter, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, ids(es)); diff != "" {
+				t.Errorf("unexpected events: -want/+got\n%s", diff)
+			}
+			if n != tt.total {
+				t.Errorf("unexpected total count: got %d, want %d", n, tt.total)
+			}
+		})
+	}
+}
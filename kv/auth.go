@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/buger/jsonparser"
 	influxdb "github.com/influxdata/influxdb"
@@ -13,6 +14,17 @@ import (
 var (
 	authBucket = []byte("authorizationsv1")
 	authIndex  = []byte("authorizationindexv1")
+
+	// authUserIndex and authOrgIndex index authorizations by the IDs of
+	// their user and organization.
+	authUserIndex = NewIndex(NewIndexMapping(authBucket, []byte("authorizationbyuserindexv1"), foreignID(func(v []byte) (influxdb.ID, error) {
+		id, _, err := jsonp.GetOptionalID(v, "userID")
+		return id, err
+	})))
+	authOrgIndex = NewIndex(NewIndexMapping(authBucket, []byte("authorizationbyorgindexv1"), foreignID(func(v []byte) (influxdb.ID, error) {
+		id, _, err := jsonp.GetOptionalID(v, "orgID")
+		return id, err
+	})))
 )
 
 var _ influxdb.AuthorizationService = (*Service)(nil)
@@ -126,7 +138,22 @@ func (s *Service) findAuthorizationByToken(ctx context.Context, tx Tx, n string)
 			Err:  err,
 		}
 	}
-	return s.findAuthorizationByID(ctx, tx, id)
+	auth, err := s.findAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token the authorization had before it was rotated is only valid
+	// during the grace period of the rotation.
+	if n != auth.Token {
+		if n != auth.PreviousToken || auth.PreviousTokenExpiresAt == nil || !s.TimeGenerator.Now().Before(*auth.PreviousTokenExpiresAt) {
+			return nil, &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  "authorization not found",
+			}
+		}
+	}
+	return auth, nil
 }
 
 func authorizationsPredicateFn(f influxdb.AuthorizationFilter) CursorPredicateFunc {
@@ -281,14 +308,23 @@ func (s *Service) findAuthorizations(ctx context.Context, tx Tx, f influxdb.Auth
 	}
 
 	var as []*influxdb.Authorization
-	pred := authorizationsPredicateFn(f)
 	filterFn := filterAuthorizationsFn(f)
-	err := s.forEachAuthorization(ctx, tx, pred, func(a *influxdb.Authorization) bool {
+	fn := func(a *influxdb.Authorization) bool {
 		if filterFn(a) {
 			as = append(as, a)
 		}
 		return true
-	})
+	}
+
+	var err error
+	switch {
+	case f.UserID != nil && f.UserID.Valid():
+		err = s.forEachIndexedAuthorization(ctx, tx, authUserIndex, *f.UserID, fn)
+	case f.OrgID != nil && f.OrgID.Valid():
+		err = s.forEachIndexedAuthorization(ctx, tx, authOrgIndex, *f.OrgID, fn)
+	default:
+		err = s.forEachAuthorization(ctx, tx, authorizationsPredicateFn(f), fn)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -335,6 +371,12 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 	a.ID = s.IDGenerator.ID()
 
 	now := s.TimeGenerator.Now()
+	if a.Expired(now) {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "expiresAt must be in the future",
+		}
+	}
 	a.SetCreatedAt(now)
 	a.SetUpdatedAt(now)
 
@@ -407,9 +449,46 @@ func (s *Service) putAuthorization(ctx context.Context, tx Tx, a *influxdb.Autho
 		}
 	}
 
+	return s.updateAuthorizationIndexes(tx, a, encodedID, (*Index).Insert)
+}
+
+// updateAuthorizationIndexes applies update, an Insert or Delete of Index, to
+// the entries of a in the indexes of authorizations by user and organization.
+func (s *Service) updateAuthorizationIndexes(tx Tx, a *influxdb.Authorization, encodedID []byte, update func(*Index, Tx, []byte, []byte) error) error {
+	for _, idx := range []struct {
+		index *Index
+		id    influxdb.ID
+	}{
+		{authUserIndex, a.UserID},
+		{authOrgIndex, a.OrgID},
+	} {
+		if !idx.id.Valid() {
+			continue
+		}
+		fk, _ := idx.id.Encode()
+		if err := update(idx.index, tx, fk, encodedID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// forEachIndexedAuthorization will iterate through the authorizations under
+// id in idx while fn returns true.
+func (s *Service) forEachIndexedAuthorization(ctx context.Context, tx Tx, idx *Index, id influxdb.ID, fn func(*influxdb.Authorization) bool) error {
+	fk, err := id.Encode()
+	if err != nil {
+		return err
+	}
+	return idx.Walk(ctx, tx, fk, false, func(k, v []byte) (bool, error) {
+		a := &influxdb.Authorization{}
+		if err := decodeAuthorization(v, a); err != nil {
+			return false, err
+		}
+		return fn(a), nil
+	})
+}
+
 func authIndexKey(n string) []byte {
 	return []byte(n)
 }
@@ -481,6 +560,13 @@ func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 			Err: err,
 		}
 	}
+	if a.PreviousToken != "" {
+		if err := idx.Delete(authIndexKey(a.PreviousToken)); err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+	}
 	encodedID, err := id.Encode()
 	if err != nil {
 		return &influxdb.Error{
@@ -498,7 +584,7 @@ func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 			Err: err,
 		}
 	}
-	return nil
+	return s.updateAuthorizationIndexes(tx, a, encodedID, (*Index).Delete)
 }
 
 // UpdateAuthorization updates the status and description if available.
@@ -524,6 +610,15 @@ func (s *Service) updateAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 	if upd.Description != nil {
 		a.Description = *upd.Description
 	}
+	if upd.ExpiresAt != nil {
+		a.ExpiresAt = upd.ExpiresAt
+	}
+	if upd.RateLimits != nil {
+		if err := upd.RateLimits.Valid(); err != nil {
+			return nil, err
+		}
+		a.RateLimits = upd.RateLimits
+	}
 
 	now := s.TimeGenerator.Now()
 	a.SetUpdatedAt(now)
@@ -564,3 +659,276 @@ func (s *Service) uniqueAuthToken(ctx context.Context, tx Tx, a *influxdb.Author
 	// should provide some debugging information.
 	return err
 }
+
+// CreateAuthorizationBatch creates the write-only authorizations described by
+// b in a single transaction, mapping each of them to the label of b.
+func (s *Service) CreateAuthorizationBatch(ctx context.Context, b *influxdb.AuthorizationBatch) ([]*influxdb.Authorization, error) {
+	if err := b.Valid(); err != nil {
+		return nil, err
+	}
+
+	var as []*influxdb.Authorization
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		l, err := s.findLabelByID(ctx, tx, b.LabelID)
+		if err != nil {
+			return err
+		}
+		if l.OrgID != b.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "label does not belong to the organization of the batch",
+			}
+		}
+
+		bucket, err := s.findBucketByID(ctx, tx, b.BucketID)
+		if err != nil {
+			return err
+		}
+		if bucket.OrgID != b.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "bucket does not belong to the organization of the batch",
+			}
+		}
+
+		as = make([]*influxdb.Authorization, 0, b.Count)
+		for i := 0; i < b.Count; i++ {
+			a, err := b.Authorization()
+			if err != nil {
+				return err
+			}
+			if err := s.createAuthorization(ctx, tx, a); err != nil {
+				return err
+			}
+
+			m := &influxdb.LabelMapping{
+				LabelID:      b.LabelID,
+				ResourceID:   a.ID,
+				ResourceType: influxdb.AuthorizationsResourceType,
+			}
+			if err := s.putLabelMapping(ctx, tx, m); err != nil {
+				return err
+			}
+			as = append(as, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// DeleteLabeledAuthorizations deletes every authorization in an organization
+// mapped to a label, along with its label mapping, and returns the number
+// deleted.
+func (s *Service) DeleteLabeledAuthorizations(ctx context.Context, orgID, labelID influxdb.ID) (int, error) {
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		ids, err := s.findLabeledAuthorizationIDs(ctx, tx, orgID, labelID)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := s.deleteAuthorization(ctx, tx, id); err != nil {
+				return err
+			}
+
+			m := &influxdb.LabelMapping{
+				LabelID:      labelID,
+				ResourceID:   id,
+				ResourceType: influxdb.AuthorizationsResourceType,
+			}
+			if err := s.deleteLabelMapping(ctx, tx, m); err != nil {
+				return err
+			}
+		}
+		n = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// findLabeledAuthorizationIDs returns the IDs of the authorizations in an
+// organization that are mapped to a label.
+func (s *Service) findLabeledAuthorizationIDs(ctx context.Context, tx Tx, orgID, labelID influxdb.ID) ([]influxdb.ID, error) {
+	l, err := s.findLabelByID(ctx, tx, labelID)
+	if err != nil {
+		return nil, err
+	}
+	if l.OrgID != orgID {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrLabelNotFound,
+		}
+	}
+
+	idx, err := tx.Bucket(labelMappingBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []influxdb.ID
+	var ferr error
+	pred := authorizationsPredicateFn(influxdb.AuthorizationFilter{OrgID: &l.OrgID})
+	err = s.forEachAuthorization(ctx, tx, pred, func(a *influxdb.Authorization) bool {
+		if a.OrgID != l.OrgID {
+			return true
+		}
+
+		key, err := labelMappingKey(&influxdb.LabelMapping{LabelID: labelID, ResourceID: a.ID})
+		if err != nil {
+			ferr = err
+			return false
+		}
+
+		if _, err := idx.Get(key); IsNotFound(err) {
+			return true
+		} else if err != nil {
+			ferr = err
+			return false
+		}
+		ids = append(ids, a.ID)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, ferr
+}
+
+var _ influxdb.AuthorizationLifecycleService = (*Service)(nil)
+
+// RotateAuthorization issues a new token for an authorization. Its current
+// token remains valid for grace, and the token it had before, if any, is no
+// longer valid.
+func (s *Service) RotateAuthorization(ctx context.Context, id influxdb.ID, grace time.Duration) (*influxdb.Authorization, error) {
+	if grace < 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Op:   influxdb.OpRotateAuthorization,
+			Msg:  "grace period cannot be negative",
+		}
+	}
+
+	var a *influxdb.Authorization
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		auth, err := s.findAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		idx, err := authIndexBucket(tx)
+		if err != nil {
+			return err
+		}
+		if auth.PreviousToken != "" {
+			if err := idx.Delete(authIndexKey(auth.PreviousToken)); err != nil {
+				return err
+			}
+		}
+
+		now := s.TimeGenerator.Now()
+		if grace > 0 {
+			expiresAt := now.Add(grace)
+			auth.PreviousToken = auth.Token
+			auth.PreviousTokenExpiresAt = &expiresAt
+		} else {
+			if err := idx.Delete(authIndexKey(auth.Token)); err != nil {
+				return err
+			}
+			auth.PreviousToken = ""
+			auth.PreviousTokenExpiresAt = nil
+		}
+
+		token, err := s.TokenGenerator.Token()
+		if err != nil {
+			return err
+		}
+		auth.Token = token
+		if err := s.uniqueAuthToken(ctx, tx, auth); err != nil {
+			return err
+		}
+
+		auth.SetUpdatedAt(now)
+		if err := s.putAuthorization(ctx, tx, auth); err != nil {
+			return err
+		}
+		a = auth
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpRotateAuthorization,
+			Err: err,
+		}
+	}
+	return a, nil
+}
+
+// TouchAuthorization records that an authorization was used at t.
+func (s *Service) TouchAuthorization(ctx context.Context, id influxdb.ID, t time.Time) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		a, err := s.findAuthorizationByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		a.LastUsedAt = &t
+		a.Stale = false
+		return s.putAuthorization(ctx, tx, a)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpTouchAuthorization,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FlagStaleAuthorizations flags the authorizations that have not been used
+// since before as stale.
+func (s *Service) FlagStaleAuthorizations(ctx context.Context, before time.Time) (int, error) {
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var changed []*influxdb.Authorization
+		err := s.forEachAuthorization(ctx, tx, nil, func(a *influxdb.Authorization) bool {
+			lastUsed := a.CreatedAt
+			if a.LastUsedAt != nil {
+				lastUsed = *a.LastUsedAt
+			}
+
+			stale := lastUsed.Before(before)
+			if stale {
+				n++
+			}
+			if stale != a.Stale {
+				a.Stale = stale
+				changed = append(changed, a)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, a := range changed {
+			if err := s.putAuthorization(ctx, tx, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, &influxdb.Error{
+			Op:  influxdb.OpFlagStaleAuthorizations,
+			Err: err,
+		}
+	}
+	return n, nil
+}
@@ -3,9 +3,12 @@ package kv_test
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
 	influxdbtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -32,6 +35,9 @@ func initAuthorizationService(s kv.Store, f influxdbtesting.AuthorizationFields,
 	svc.IDGenerator = f.IDGenerator
 	svc.TokenGenerator = f.TokenGenerator
 	svc.TimeGenerator = f.TimeGenerator
+	if f.TimeGenerator == nil {
+		svc.TimeGenerator = influxdb.RealTimeGenerator{}
+	}
 
 	ctx := context.Background()
 	if err := svc.Initialize(ctx); err != nil {
@@ -76,3 +82,197 @@ func initAuthorizationService(s kv.Store, f influxdbtesting.AuthorizationFields,
 		}
 	}
 }
+
+func TestService_AuthorizationBatch(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing authorization service: %v", err)
+	}
+
+	user := &influxdb.User{Name: "fleet"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "fleet"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &influxdb.Bucket{Name: "devices", OrgID: org.ID}
+	if err := svc.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+	label := &influxdb.Label{Name: "edge", OrgID: org.ID}
+	if err := svc.CreateLabel(ctx, label); err != nil {
+		t.Fatal(err)
+	}
+	other := &influxdb.Authorization{
+		OrgID:       org.ID,
+		UserID:      user.ID,
+		Permissions: influxdb.OperPermissions(),
+	}
+	if err := svc.CreateAuthorization(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	as, err := svc.CreateAuthorizationBatch(ctx, &influxdb.AuthorizationBatch{
+		OrgID:    org.ID,
+		UserID:   user.ID,
+		BucketID: bucket.ID,
+		LabelID:  label.ID,
+		Count:    3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(as) != 3 {
+		t.Fatalf("unexpected number of authorizations: got %d, want 3", len(as))
+	}
+
+	want := []influxdb.Permission{{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucket.ID, OrgID: &org.ID},
+	}}
+	for _, a := range as {
+		got, err := svc.FindAuthorizationByToken(ctx, a.Token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got.Permissions); diff != "" {
+			t.Fatalf("unexpected permissions: -want/+got\n%s", diff)
+		}
+
+		ls, err := svc.FindResourceLabels(ctx, influxdb.LabelMappingFilter{ResourceID: a.ID, ResourceType: influxdb.AuthorizationsResourceType})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(ls) != 1 || ls[0].ID != label.ID {
+			t.Fatalf("unexpected labels: %v", ls)
+		}
+	}
+
+	n, err := svc.DeleteLabeledAuthorizations(ctx, org.ID, label.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Fatalf("unexpected number of deleted authorizations: got %d, want 3", n)
+	}
+
+	remaining, _, err := svc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{OrgID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(remaining) != 1 || remaining[0].ID != other.ID {
+		t.Fatalf("unexpected remaining authorizations: %v", remaining)
+	}
+}
+
+func TestService_AuthorizationLifecycle(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing authorization service: %v", err)
+	}
+
+	user := &influxdb.User{Name: "ci"}
+	if err := svc.CreateUser(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	org := &influxdb.Organization{Name: "ci"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	expired := now.Add(-time.Hour)
+	if err := svc.CreateAuthorization(ctx, &influxdb.Authorization{
+		OrgID:       org.ID,
+		UserID:      user.ID,
+		Permissions: influxdb.OperPermissions(),
+		ExpiresAt:   &expired,
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected authorization expiring in the past to be invalid, got %v", err)
+	}
+
+	a := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID, Permissions: influxdb.OperPermissions()}
+	if err := svc.CreateAuthorization(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	used := &influxdb.Authorization{OrgID: org.ID, UserID: user.ID, Permissions: influxdb.OperPermissions()}
+	if err := svc.CreateAuthorization(ctx, used); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first token remains valid for the grace period of the rotation, and
+	// is revoked by the next.
+	first := a.Token
+	rotated, err := svc.RotateAuthorization(ctx, a.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := rotated.Token
+	for _, token := range []string{first, second} {
+		if _, err := svc.FindAuthorizationByToken(ctx, token); err != nil {
+			t.Fatalf("token %q is not valid: %v", token, err)
+		}
+	}
+
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now.Add(time.Hour)}
+	if _, err := svc.FindAuthorizationByToken(ctx, first); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected token to have expired at the end of the grace period, got %v", err)
+	}
+
+	rotated, err = svc.RotateAuthorization(ctx, a.ID, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{first, second} {
+		if _, err := svc.FindAuthorizationByToken(ctx, token); influxdb.ErrorCode(err) != influxdb.ENotFound {
+			t.Fatalf("expected token %q to be revoked, got %v", token, err)
+		}
+	}
+	if _, err := svc.FindAuthorizationByToken(ctx, rotated.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Authorizations not used since before are stale until they are used.
+	if err := svc.TouchAuthorization(ctx, used.ID, now.Add(12*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	n, err := svc.FlagStaleAuthorizations(ctx, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected number of stale authorizations: got %d, want 1", n)
+	}
+	if got, err := svc.FindAuthorizationByID(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	} else if !got.Stale {
+		t.Fatal("expected unused authorization to be stale")
+	}
+	if got, err := svc.FindAuthorizationByID(ctx, used.ID); err != nil {
+		t.Fatal(err)
+	} else if got.Stale || !got.LastUsedAt.Equal(now.Add(12*time.Hour)) {
+		t.Fatalf("unexpected used authorization: %+v", got)
+	}
+
+	if err := svc.TouchAuthorization(ctx, a.ID, now.Add(13*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := svc.FindAuthorizationByID(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	} else if got.Stale {
+		t.Fatal("expected authorization to no longer be stale once used")
+	}
+}
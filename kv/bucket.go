@@ -10,14 +10,27 @@ import (
 	icontext "github.com/influxdata/influxdb/context"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/resource"
+	"go.uber.org/zap"
 )
 
 var (
 	bucketBucket = []byte("bucketsv1")
 	bucketIndex  = []byte("bucketindexv1")
+
+	// bucketOrgIndex indexes the buckets in use by the ID of their
+	// organization.
+	bucketOrgIndex = NewIndex(NewIndexMapping(bucketBucket, []byte("bucketsbyorgindexv1"), foreignID(func(v []byte) (influxdb.ID, error) {
+		var b influxdb.Bucket
+		if err := json.Unmarshal(v, &b); err != nil || b.DeletedAt != nil {
+			return 0, err
+		}
+		return b.OrgID, nil
+	})))
 )
 
 var _ influxdb.BucketService = (*Service)(nil)
+var _ influxdb.BucketRestoreService = (*Service)(nil)
+var _ influxdb.BucketTransferService = (*Service)(nil)
 var _ influxdb.BucketOperationLogService = (*Service)(nil)
 
 func (s *Service) initializeBuckets(ctx context.Context, tx Tx) error {
@@ -74,6 +87,23 @@ func (s *Service) FindBucketByID(ctx context.Context, id influxdb.ID) (*influxdb
 }
 
 func (s *Service) findBucketByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Bucket, error) {
+	b, err := s.getBucket(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.DeletedAt != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "bucket not found",
+		}
+	}
+
+	return b, nil
+}
+
+// getBucket returns the bucket of id, even if it is deleted.
+func (s *Service) getBucket(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Bucket, error) {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -133,29 +163,57 @@ func (s *Service) FindBucketByName(ctx context.Context, orgID influxdb.ID, n str
 	return b, err
 }
 
-// CreateSystemBuckets creates the task and monitoring system buckets for an organization
-func (s *Service) createSystemBuckets(ctx context.Context, tx Tx, o *influxdb.Organization) error {
-	tb := &influxdb.Bucket{
-		OrgID:           o.ID,
-		Type:            influxdb.BucketTypeSystem,
-		Name:            influxdb.TasksSystemBucketName,
-		RetentionPeriod: influxdb.TasksSystemBucketRetention,
-		Description:     "System bucket for task logs",
-	}
+// SystemBucketsConfig holds the settings of the _tasks and _monitoring system
+// buckets created with each organization. Once created, their settings are
+// changed through the bucket API like those of other buckets.
+type SystemBucketsConfig struct {
+	// The retention periods of the buckets. A value of 0 keeps the defaults,
+	// influxdb.TasksSystemBucketRetention and
+	// influxdb.MonitoringSystemBucketRetention.
+	TasksRetention      time.Duration
+	MonitoringRetention time.Duration
+
+	// The shard group duration and series limit of both buckets. A shard
+	// group duration of 0 leaves their data unpartitioned, and a series limit
+	// of 0 leaves it to the storage engine.
+	ShardGroupDuration time.Duration
+	MaxSeries          int
+}
 
-	if err := s.createBucket(ctx, tx, tb); err != nil {
-		return err
+// systemBucket returns the system bucket of orgID named name, with the
+// configured settings.
+func (s *Service) systemBucket(orgID influxdb.ID, name string) *influxdb.Bucket {
+	c := s.Config.SystemBuckets
+	b := &influxdb.Bucket{
+		OrgID:              orgID,
+		Type:               influxdb.BucketTypeSystem,
+		Name:               name,
+		ShardGroupDuration: c.ShardGroupDuration,
+		MaxSeries:          c.MaxSeries,
+	}
+	switch name {
+	case influxdb.TasksSystemBucketName:
+		b.RetentionPeriod = influxdb.TasksSystemBucketRetention
+		if c.TasksRetention != 0 {
+			b.RetentionPeriod = c.TasksRetention
+		}
+		b.Description = "System bucket for task logs"
+	case influxdb.MonitoringSystemBucketName:
+		b.RetentionPeriod = influxdb.MonitoringSystemBucketRetention
+		if c.MonitoringRetention != 0 {
+			b.RetentionPeriod = c.MonitoringRetention
+		}
+		b.Description = "System bucket for monitoring logs"
 	}
+	return b
+}
 
-	mb := &influxdb.Bucket{
-		OrgID:           o.ID,
-		Type:            influxdb.BucketTypeSystem,
-		Name:            influxdb.MonitoringSystemBucketName,
-		RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-		Description:     "System bucket for monitoring logs",
+// CreateSystemBuckets creates the task and monitoring system buckets for an organization
+func (s *Service) createSystemBuckets(ctx context.Context, tx Tx, o *influxdb.Organization) error {
+	if err := s.createBucket(ctx, tx, s.systemBucket(o.ID, influxdb.TasksSystemBucketName)); err != nil {
+		return err
 	}
-
-	return s.createBucket(ctx, tx, mb)
+	return s.createBucket(ctx, tx, s.systemBucket(o.ID, influxdb.MonitoringSystemBucketName))
 }
 
 func (s *Service) findBucketByName(ctx context.Context, tx Tx, orgID influxdb.ID, n string) (*influxdb.Bucket, error) {
@@ -183,23 +241,13 @@ func (s *Service) findBucketByName(ctx context.Context, tx Tx, orgID influxdb.ID
 	if IsNotFound(err) {
 		switch n {
 		case influxdb.TasksSystemBucketName:
-			return &influxdb.Bucket{
-				ID:              influxdb.TasksSystemBucketID,
-				Type:            influxdb.BucketTypeSystem,
-				Name:            influxdb.TasksSystemBucketName,
-				RetentionPeriod: influxdb.TasksSystemBucketRetention,
-				Description:     "System bucket for task logs",
-				OrgID:           orgID,
-			}, nil
+			b := s.systemBucket(orgID, n)
+			b.ID = influxdb.TasksSystemBucketID
+			return b, nil
 		case influxdb.MonitoringSystemBucketName:
-			return &influxdb.Bucket{
-				ID:              influxdb.MonitoringSystemBucketID,
-				Type:            influxdb.BucketTypeSystem,
-				Name:            influxdb.MonitoringSystemBucketName,
-				RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-				Description:     "System bucket for monitoring logs",
-				OrgID:           orgID,
-			}, nil
+			b := s.systemBucket(orgID, n)
+			b.ID = influxdb.MonitoringSystemBucketID
+			return b, nil
 		default:
 			return nil, &influxdb.Error{
 				Code: influxdb.ENotFound,
@@ -231,7 +279,7 @@ func (s *Service) FindBucket(ctx context.Context, filter influxdb.BucketFilter)
 	var b *influxdb.Bucket
 	var err error
 
-	if filter.ID != nil {
+	if filter.ID != nil && !filter.Deleted {
 		b, err = s.FindBucketByID(ctx, *filter.ID)
 		if err != nil {
 			return nil, &influxdb.Error{
@@ -241,7 +289,7 @@ func (s *Service) FindBucket(ctx context.Context, filter influxdb.BucketFilter)
 		return b, nil
 	}
 
-	if filter.Name != nil && filter.OrganizationID != nil {
+	if filter.Name != nil && filter.OrganizationID != nil && !filter.Deleted {
 		return s.FindBucketByName(ctx, *filter.OrganizationID, *filter.Name)
 	}
 
@@ -255,7 +303,7 @@ func (s *Service) FindBucket(ctx context.Context, filter influxdb.BucketFilter)
 		}
 
 		filterFn := filterBucketsFn(filter)
-		return s.forEachBucket(ctx, tx, false, func(bkt *influxdb.Bucket) bool {
+		return s.forEachStoredBucket(ctx, tx, false, filter.Deleted, func(bkt *influxdb.Bucket) bool {
 			if filterFn(bkt) {
 				b = bkt
 				return false
@@ -315,7 +363,7 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	if filter.ID != nil {
+	if filter.ID != nil && !filter.Deleted {
 		b, err := s.FindBucketByID(ctx, *filter.ID)
 		if err != nil {
 			return nil, 0, err
@@ -324,7 +372,7 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 		return []*influxdb.Bucket{b}, 1, nil
 	}
 
-	if filter.Name != nil && filter.OrganizationID != nil {
+	if filter.Name != nil && filter.OrganizationID != nil && !filter.Deleted {
 		b, err := s.FindBucketByName(ctx, *filter.OrganizationID, *filter.Name)
 		if err != nil {
 			return nil, 0, err
@@ -353,6 +401,16 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 		return bs, len(bs), nil
 	}
 
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Nor on pages continuing a listing, which would list them again, nor
+	// on listings of deleted buckets.
+	if len(opts) > 0 && opts[0].After != "" || filter.Deleted {
+		return bs, len(bs), nil
+	}
+
 	needsSystemBuckets := true
 	for _, b := range bs {
 		if b.Type == influxdb.BucketTypeSystem {
@@ -362,31 +420,15 @@ func (s *Service) FindBuckets(ctx context.Context, filter influxdb.BucketFilter,
 	}
 
 	if needsSystemBuckets {
-		tb := &influxdb.Bucket{
-			ID:              influxdb.TasksSystemBucketID,
-			Type:            influxdb.BucketTypeSystem,
-			Name:            influxdb.TasksSystemBucketName,
-			RetentionPeriod: influxdb.TasksSystemBucketRetention,
-			Description:     "System bucket for task logs",
-		}
-
+		tb := s.systemBucket(0, influxdb.TasksSystemBucketName)
+		tb.ID = influxdb.TasksSystemBucketID
 		bs = append(bs, tb)
 
-		mb := &influxdb.Bucket{
-			ID:              influxdb.MonitoringSystemBucketID,
-			Type:            influxdb.BucketTypeSystem,
-			Name:            influxdb.MonitoringSystemBucketName,
-			RetentionPeriod: influxdb.MonitoringSystemBucketRetention,
-			Description:     "System bucket for monitoring logs",
-		}
-
+		mb := s.systemBucket(0, influxdb.MonitoringSystemBucketName)
+		mb.ID = influxdb.MonitoringSystemBucketID
 		bs = append(bs, mb)
 	}
 
-	if err != nil {
-		return nil, 0, err
-	}
-
 	return bs, len(bs), nil
 }
 
@@ -405,29 +447,28 @@ func (s *Service) findBuckets(ctx context.Context, tx Tx, filter influxdb.Bucket
 		filter.OrganizationID = &o.ID
 	}
 
-	var offset, limit, count int
-	var descending bool
-	if len(opts) > 0 {
-		offset = opts[0].Offset
-		limit = opts[0].Limit
-		descending = opts[0].Descending
+	p, err := newPager(opts...)
+	if err != nil {
+		return nil, err
 	}
 
 	filterFn := filterBucketsFn(filter)
-	err := s.forEachBucket(ctx, tx, descending, func(b *influxdb.Bucket) bool {
-		if filterFn(b) {
-			if count >= offset {
-				bs = append(bs, b)
-			}
-			count++
-		}
-
-		if limit > 0 && len(bs) >= limit {
-			return false
+	visit := func(b *influxdb.Bucket) bool {
+		if filterFn(b) && p.include(b.ID) {
+			bs = append(bs, b)
 		}
+		return !p.full(len(bs))
+	}
 
-		return true
-	})
+	switch {
+	case filter.Deleted:
+		// Deleted buckets are not indexed by their organization.
+		err = s.forEachStoredBucket(ctx, tx, p.descending, true, visit)
+	case filter.OrganizationID != nil && filter.OrganizationID.Valid():
+		err = s.forEachOrgBucket(ctx, tx, *filter.OrganizationID, p.descending, visit)
+	default:
+		err = s.forEachBucket(ctx, tx, p.descending, visit)
+	}
 
 	if err != nil {
 		return nil, &influxdb.Error{
@@ -498,7 +539,7 @@ func (s *Service) createBucket(ctx context.Context, tx Tx, b *influxdb.Bucket) (
 		OrganizationID: b.OrgID,
 		UserID:         uid,
 		ResourceBody:   v,
-		Time:           time.Now(),
+		Time:           s.Now(),
 	})
 }
 
@@ -526,7 +567,7 @@ func (s *Service) PutBucket(ctx context.Context, b *influxdb.Bucket) error {
 			OrganizationID: b.OrgID,
 			UserID:         uid,
 			ResourceBody:   v,
-			Time:           time.Now(),
+			Time:           s.Now(),
 		})
 	})
 }
@@ -564,7 +605,12 @@ func (s *Service) putBucket(ctx context.Context, tx Tx, b *influxdb.Bucket, v []
 			Err: err,
 		}
 	}
-	return nil
+
+	if !b.OrgID.Valid() {
+		return nil
+	}
+	orgID, _ := b.OrgID.Encode()
+	return bucketOrgIndex.Insert(tx, orgID, encodedID)
 }
 
 // bucketIndexKey is a combination of the orgID and the bucket name.
@@ -582,8 +628,14 @@ func bucketIndexKey(b *influxdb.Bucket) ([]byte, error) {
 	return k, nil
 }
 
-// forEachBucket will iterate through all buckets while fn returns true.
+// forEachBucket will iterate through all buckets in use while fn returns true.
 func (s *Service) forEachBucket(ctx context.Context, tx Tx, descending bool, fn func(*influxdb.Bucket) bool) error {
+	return s.forEachStoredBucket(ctx, tx, descending, false, fn)
+}
+
+// forEachStoredBucket will iterate through the deleted buckets, or the buckets
+// in use, while fn returns true.
+func (s *Service) forEachStoredBucket(ctx context.Context, tx Tx, descending, deleted bool, fn func(*influxdb.Bucket) bool) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -608,6 +660,9 @@ func (s *Service) forEachBucket(ctx context.Context, tx Tx, descending bool, fn
 			return err
 		}
 
+		if (b.DeletedAt != nil) != deleted {
+			continue
+		}
 		if !fn(b) {
 			break
 		}
@@ -616,6 +671,26 @@ func (s *Service) forEachBucket(ctx context.Context, tx Tx, descending bool, fn
 	return nil
 }
 
+// forEachOrgBucket will iterate through the buckets of an organization while
+// fn returns true.
+func (s *Service) forEachOrgBucket(ctx context.Context, tx Tx, orgID influxdb.ID, descending bool, fn func(*influxdb.Bucket) bool) error {
+	encodedOrgID, err := orgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	return bucketOrgIndex.Walk(ctx, tx, encodedOrgID, descending, func(k, v []byte) (bool, error) {
+		b := &influxdb.Bucket{}
+		if err := json.Unmarshal(v, b); err != nil {
+			return false, err
+		}
+		return fn(b), nil
+	})
+}
+
 func (s *Service) validBucketName(ctx context.Context, tx Tx, b *influxdb.Bucket) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -675,6 +750,45 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.ShardGroupDuration != nil {
+		b.ShardGroupDuration = *upd.ShardGroupDuration
+	}
+
+	if upd.Precision != nil {
+		b.Precision = *upd.Precision
+	}
+
+	if upd.CompactionStrategy != nil {
+		b.CompactionStrategy = *upd.CompactionStrategy
+	}
+
+	if upd.CacheBudget != nil {
+		b.CacheBudget = *upd.CacheBudget
+	}
+
+	if upd.CacheWriteColdDuration != nil {
+		b.CacheWriteColdDuration = *upd.CacheWriteColdDuration
+	}
+
+	if upd.MaxSeries != nil {
+		b.MaxSeries = *upd.MaxSeries
+	}
+
+	if upd.MeasurementRetention != nil {
+		b.MeasurementRetention = upd.MeasurementRetention
+	}
+
+	if upd.Rollups != nil {
+		b.Rollups = upd.Rollups
+	}
+
+	if upd.Metadata != nil {
+		b.Metadata = upd.Metadata
+		if len(b.Metadata) == 0 {
+			b.Metadata = nil
+		}
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
@@ -725,7 +839,7 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		OrganizationID: b.OrgID,
 		UserID:         uid,
 		ResourceBody:   v,
-		Time:           time.Now(),
+		Time:           s.Now(),
 	}); err != nil {
 		return nil, &influxdb.Error{
 			Err: err,
@@ -738,7 +852,7 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 // DeleteBucket deletes a bucket and prunes it from the index.
 func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
-		bucket, err := s.findBucketByID(ctx, tx, id)
+		bucket, err := s.getBucket(ctx, tx, id)
 		if err != nil && !IsNotFound(err) {
 			return err
 		}
@@ -761,30 +875,71 @@ func (s *Service) DeleteBucket(ctx context.Context, id influxdb.ID) error {
 			ResourceType:   influxdb.BucketsResourceType,
 			OrganizationID: bucket.OrgID,
 			UserID:         uid,
-			Time:           time.Now(),
+			Time:           s.Now(),
 		})
 	})
 }
 
-func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error {
-	b, pe := s.findBucketByID(ctx, tx, id)
-	if pe != nil {
-		return pe
+// WatchCreatedBuckets returns a channel of the buckets created in the store.
+// The channel is closed when ctx is done.
+func (s *Service) WatchCreatedBuckets(ctx context.Context) (<-chan *influxdb.Bucket, error) {
+	return s.watchBuckets(ctx, EventCreate)
+}
+
+// WatchDeletedBuckets returns a channel of the buckets deleted from the store,
+// as they were before they were deleted. The channel is closed when ctx is
+// done.
+func (s *Service) WatchDeletedBuckets(ctx context.Context) (<-chan *influxdb.Bucket, error) {
+	return s.watchBuckets(ctx, EventDelete)
+}
+
+// watchBuckets returns a channel of the buckets changed in the store by events
+// of typ, as they were before a delete or after any other change.
+func (s *Service) watchBuckets(ctx context.Context, typ EventType) (<-chan *influxdb.Bucket, error) {
+	events, err := s.kv.Watch(ctx, bucketBucket, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	key, pe := bucketIndexKey(b)
+	buckets := make(chan *influxdb.Bucket)
+	go func() {
+		defer close(buckets)
+		for e := range events {
+			if e.Type != typ {
+				continue
+			}
+
+			v := e.Value
+			if typ == EventDelete {
+				v = e.PrevValue
+			}
+			b := &influxdb.Bucket{}
+			if err := json.Unmarshal(v, b); err != nil {
+				s.log.Info("Failed to decode bucket", zap.Stringer("event", typ), zap.Error(err))
+				continue
+			}
+
+			select {
+			case buckets <- b:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return buckets, nil
+}
+
+func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error {
+	b, pe := s.getBucket(ctx, tx, id)
 	if pe != nil {
 		return pe
 	}
 
-	idx, err := s.bucketsIndexBucket(tx)
-	if err != nil {
-		return err
-	}
-
-	if err := idx.Delete(key); err != nil {
-		return &influxdb.Error{
-			Err: err,
+	// The index entries of a deleted bucket were removed when it was
+	// deleted, and its name may since be that of another bucket.
+	if b.DeletedAt == nil {
+		if err := s.deleteBucketIndexes(ctx, tx, b); err != nil {
+			return err
 		}
 	}
 
@@ -814,7 +969,279 @@ func (s *Service) deleteBucket(ctx context.Context, tx Tx, id influxdb.ID) error
 		return err
 	}
 
-	return nil
+	return s.deleteBucketMeasurementSchemas(ctx, tx, id)
+}
+
+// deleteBucketIndexes removes the entries of b from the index of buckets by
+// name and the index of buckets by organization.
+func (s *Service) deleteBucketIndexes(ctx context.Context, tx Tx, b *influxdb.Bucket) error {
+	key, err := bucketIndexKey(b)
+	if err != nil {
+		return err
+	}
+
+	idx, err := s.bucketsIndexBucket(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := idx.Delete(key); err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	if !b.OrgID.Valid() {
+		return nil
+	}
+	encodedID, err := b.ID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	orgID, _ := b.OrgID.Encode()
+	return bucketOrgIndex.Delete(tx, orgID, encodedID)
+}
+
+// SoftDeleteBucket marks a bucket deleted, keeping it to be restored until it
+// is deleted by DeleteBucket. A deleted bucket is not found by name or ID, and
+// its name may be given to another bucket.
+func (s *Service) SoftDeleteBucket(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		b, err := s.findBucketByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if b.Type == influxdb.BucketTypeSystem {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "system buckets cannot be deleted",
+			}
+		}
+
+		if err := s.deleteBucketIndexes(ctx, tx, b); err != nil {
+			return err
+		}
+
+		now := s.Now()
+		b.DeletedAt = &now
+		v, err := json.Marshal(b)
+		if err != nil {
+			return influxdb.ErrInternalBucketServiceError(influxdb.OpDeleteBucket, err)
+		}
+
+		// The bucket is written over rather than moved, so that it is not
+		// reported to watchers of deleted buckets until it is dropped.
+		encodedID, _ := id.Encode()
+		bkt, err := s.bucketsBucket(tx)
+		if err != nil {
+			return err
+		}
+		if err := bkt.Put(encodedID, v); err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
+		uid, _ := icontext.GetUserID(ctx)
+		return s.audit.Log(resource.Change{
+			Type:           resource.Delete,
+			ResourceID:     id,
+			ResourceType:   influxdb.BucketsResourceType,
+			OrganizationID: b.OrgID,
+			UserID:         uid,
+			ResourceBody:   v,
+			Time:           s.Now(),
+		})
+	})
+}
+
+// FindDeletedBucketByID returns a bucket marked deleted by SoftDeleteBucket.
+func (s *Service) FindDeletedBucketByID(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b *influxdb.Bucket
+	err := s.kv.View(ctx, func(tx Tx) error {
+		bkt, err := s.findDeletedBucketByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		b = bkt
+		return nil
+	})
+	return b, err
+}
+
+func (s *Service) findDeletedBucketByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Bucket, error) {
+	b, err := s.getBucket(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.DeletedAt == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "deleted bucket not found",
+		}
+	}
+	return b, nil
+}
+
+// RestoreBucket undeletes a bucket marked deleted by SoftDeleteBucket.
+func (s *Service) RestoreBucket(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b *influxdb.Bucket
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		bkt, err := s.restoreBucket(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		b = bkt
+		return nil
+	})
+	return b, err
+}
+
+func (s *Service) restoreBucket(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.Bucket, error) {
+	b, err := s.findDeletedBucketByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findOrganizationByID(ctx, tx, b.OrgID); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	if err := s.validBucketName(ctx, tx, b); err != nil {
+		return nil, err
+	}
+
+	b.DeletedAt = nil
+	b.UpdatedAt = s.Now()
+	v, err := json.Marshal(b)
+	if err != nil {
+		return nil, influxdb.ErrInternalBucketServiceError(influxdb.OpUpdateBucket, err)
+	}
+	if err := s.putBucket(ctx, tx, b, v); err != nil {
+		return nil, err
+	}
+
+	uid, _ := icontext.GetUserID(ctx)
+	if err := s.audit.Log(resource.Change{
+		Type:           resource.Update,
+		ResourceID:     b.ID,
+		ResourceType:   influxdb.BucketsResourceType,
+		OrganizationID: b.OrgID,
+		UserID:         uid,
+		ResourceBody:   v,
+		Time:           s.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// RenameBucket gives a bucket another name.
+func (s *Service) RenameBucket(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+	return s.UpdateBucket(ctx, id, influxdb.BucketUpdate{Name: &name})
+}
+
+// TransferBucket moves a bucket to the organization of orgID. The bucket keeps
+// the ID of the organization its data is stored under in StorageOrgID, so its
+// data stays where it is.
+func (s *Service) TransferBucket(ctx context.Context, id, orgID influxdb.ID) (*influxdb.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b *influxdb.Bucket
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		bkt, err := s.transferBucket(ctx, tx, id, orgID)
+		if err != nil {
+			return err
+		}
+		b = bkt
+		return nil
+	})
+	return b, err
+}
+
+func (s *Service) transferBucket(ctx context.Context, tx Tx, id, orgID influxdb.ID) (*influxdb.Bucket, error) {
+	b, err := s.findBucketByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Type == influxdb.BucketTypeSystem {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "system buckets cannot be transferred",
+		}
+	}
+	if b.OrgID == orgID {
+		return b, nil
+	}
+
+	if _, err := s.findOrganizationByID(ctx, tx, orgID); err != nil {
+		return nil, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	// The bucket is indexed by the organization it is in.
+	if err := s.deleteBucketIndexes(ctx, tx, b); err != nil {
+		return nil, err
+	}
+
+	if !b.StorageOrgID.Valid() {
+		b.StorageOrgID = b.OrgID
+	}
+	b.OrgID = orgID
+	if b.StorageOrgID == orgID {
+		b.StorageOrgID = 0
+	}
+
+	if err := s.validBucketName(ctx, tx, b); err != nil {
+		return nil, err
+	}
+
+	b.UpdatedAt = s.Now()
+
+	if err := s.appendBucketEventToLog(ctx, tx, b.ID, bucketTransferredEvent); err != nil {
+		return nil, err
+	}
+
+	v, err := json.Marshal(b)
+	if err != nil {
+		return nil, influxdb.ErrInternalBucketServiceError(influxdb.OpTransferBucket, err)
+	}
+	if err := s.putBucket(ctx, tx, b, v); err != nil {
+		return nil, err
+	}
+
+	uid, _ := icontext.GetUserID(ctx)
+	if err := s.audit.Log(resource.Change{
+		Type:           resource.Update,
+		ResourceID:     b.ID,
+		ResourceType:   influxdb.BucketsResourceType,
+		OrganizationID: b.OrgID,
+		UserID:         uid,
+		ResourceBody:   v,
+		Time:           s.Now(),
+	}); err != nil {
+		return nil, err
+	}
+	return b, nil
 }
 
 const bucketOperationLogKeyPrefix = "bucket"
@@ -860,8 +1287,9 @@ func (s *Service) GetBucketOperationLog(ctx context.Context, id influxdb.ID, opt
 
 // TODO(desa): what do we want these to be?
 const (
-	bucketCreatedEvent = "Bucket Created"
-	bucketUpdatedEvent = "Bucket Updated"
+	bucketCreatedEvent     = "Bucket Created"
+	bucketUpdatedEvent     = "Bucket Updated"
+	bucketTransferredEvent = "Bucket Transferred"
 )
 
 func (s *Service) appendBucketEventToLog(ctx context.Context, tx Tx, id influxdb.ID, st string) error {
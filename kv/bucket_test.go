@@ -63,3 +63,172 @@ func initBucketService(s kv.Store, f influxdbtesting.BucketFields, t *testing.T)
 		}
 	}
 }
+
+func TestService_SoftDeleteBucket(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	deleted := &influxdb.Bucket{OrgID: org.ID, Name: "bucket"}
+	if err := svc.CreateBucket(ctx, deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.SoftDeleteBucket(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bucket is only listed with the deleted buckets.
+	bs, _, err := svc.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &org.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range bs {
+		if b.ID == deleted.ID {
+			t.Fatalf("expected deleted bucket not to be listed")
+		}
+	}
+	bs, _, err = svc.FindBuckets(ctx, influxdb.BucketFilter{Deleted: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 1 || bs[0].ID != deleted.ID || bs[0].DeletedAt == nil {
+		t.Fatalf("expected the deleted bucket to be listed, got %+v", bs)
+	}
+
+	// Its name is free to be given to another bucket, which then keeps it
+	// from being restored.
+	other := &influxdb.Bucket{OrgID: org.ID, Name: "bucket"}
+	if err := svc.CreateBucket(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RestoreBucket(ctx, deleted.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected conflict restoring bucket, got %v", err)
+	}
+
+	// Deleting it for good leaves the other bucket of its name.
+	if err := svc.DeleteBucket(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	b, err := svc.FindBucketByName(ctx, org.ID, "bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.ID != other.ID {
+		t.Fatalf("got bucket %s, expected %s", b.ID, other.ID)
+	}
+	if _, err := svc.FindDeletedBucketByID(ctx, deleted.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted bucket to be gone, got %v", err)
+	}
+
+	// A restored bucket is found by name again.
+	if err := svc.SoftDeleteBucket(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindBucketByName(ctx, org.ID, "bucket"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected deleted bucket not to be found, got %v", err)
+	}
+	if _, err := svc.RestoreBucket(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	name := "bucket"
+	bs, _, err = svc.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &org.ID, Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 1 || bs[0].ID != other.ID || bs[0].DeletedAt != nil {
+		t.Fatalf("expected the restored bucket, got %+v", bs)
+	}
+}
+
+func TestService_TransferBucket(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	from := &influxdb.Organization{Name: "from"}
+	to := &influxdb.Organization{Name: "to"}
+	for _, o := range []*influxdb.Organization{from, to} {
+		if err := svc.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := &influxdb.Bucket{OrgID: from.ID, Name: "bucket"}
+	taken := &influxdb.Bucket{OrgID: from.ID, Name: "taken"}
+	for _, bkt := range []*influxdb.Bucket{b, taken, {OrgID: to.ID, Name: "taken"}} {
+		if err := svc.CreateBucket(ctx, bkt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := svc.TransferBucket(ctx, b.ID, to.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.OrgID != to.ID || got.StorageOrgID != from.ID || got.DataOrgID() != from.ID {
+		t.Fatalf("got bucket in org %s with data under %s, expected org %s with data under %s", got.OrgID, got.StorageOrgID, to.ID, from.ID)
+	}
+
+	// The bucket is found in the organization it was transferred to.
+	if _, err := svc.FindBucketByName(ctx, from.ID, "bucket"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected bucket not to be found in org it was transferred from, got %v", err)
+	}
+	if got, err := svc.FindBucketByName(ctx, to.ID, "bucket"); err != nil || got.ID != b.ID {
+		t.Fatalf("expected bucket to be found in org it was transferred to, got %v, %v", got, err)
+	}
+	bs, _, err := svc.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &from.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bkt := range bs {
+		if bkt.ID == b.ID {
+			t.Fatalf("expected bucket not to be listed in org it was transferred from")
+		}
+	}
+
+	if _, err := svc.TransferBucket(ctx, taken.ID, to.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected conflict transferring bucket with a name taken in org, got %v", err)
+	}
+	if _, err := svc.TransferBucket(ctx, b.ID, influxdb.ID(1)); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected missing org not to be found, got %v", err)
+	}
+
+	// The data of a bucket transferred back is stored under its organization.
+	if _, err := svc.RenameBucket(ctx, b.ID, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	got, err = svc.TransferBucket(ctx, b.ID, from.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "renamed" || got.OrgID != from.ID || got.StorageOrgID.Valid() {
+		t.Fatalf("unexpected bucket transferred back: %+v", got)
+	}
+
+	system, err := svc.FindBucketByName(ctx, from.ID, influxdb.TasksSystemBucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.TransferBucket(ctx, system.ID, to.ID); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected system bucket not to be transferred, got %v", err)
+	}
+}
@@ -205,12 +205,17 @@ func (s *Service) FindChecks(ctx context.Context, filter influxdb.CheckFilter, o
 		if len(opts) > 0 {
 			opt = opts[0]
 		}
+		after, err := afterKey(opt)
+		if err != nil {
+			return err
+		}
 
 		filterFn := filterChecksFn(idMap, filter)
 		return s.checkStore.Find(ctx, tx, FindOpts{
 			Descending: opt.Descending,
 			Offset:     opt.Offset,
 			Limit:      opt.Limit,
+			After:      after,
 			FilterEntFn: func(k []byte, v interface{}) bool {
 				ch, ok := v.(influxdb.Check)
 				if err := IsErrUnexpectedDecodeVal(ok); err != nil {
@@ -375,6 +380,10 @@ func (s *Service) updateCheck(ctx context.Context, tx Tx, id influxdb.ID, chk in
 		return nil, err
 	}
 
+	if err := s.updateRevision(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
 	if chk.GetName() != current.GetName() {
 		c0, err := s.findCheckByName(ctx, tx, current.GetOrgID(), chk.GetName())
 		if err == nil && c0.GetID() != id {
@@ -442,6 +451,10 @@ func (s *Service) patchCheck(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		return nil, err
 	}
 
+	if err := s.updateRevision(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
 	if upd.Name != nil {
 		c.SetName(*upd.Name)
 	}
@@ -482,6 +495,10 @@ func (s *Service) DeleteCheck(ctx context.Context, id influxdb.ID) error {
 	}
 
 	return s.kv.Update(ctx, func(tx Tx) error {
+		if err := s.deleteRevision(ctx, tx, id); err != nil {
+			return err
+		}
+
 		err := s.checkStore.DeleteEnt(ctx, tx, Entity{
 			PK: EncID(id),
 		})
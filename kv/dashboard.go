@@ -185,7 +185,7 @@ func (s *Service) FindDashboards(ctx context.Context, filter influxdb.DashboardF
 	return ds, len(ds), nil
 }
 
-func (s *Service) findOrganizationDashboards(ctx context.Context, tx Tx, orgID influxdb.ID) ([]*influxdb.Dashboard, error) {
+func (s *Service) findOrganizationDashboards(ctx context.Context, tx Tx, orgID influxdb.ID, p *pager) ([]*influxdb.Dashboard, error) {
 	idx, err := tx.Bucket(orgDashboardIndex)
 	if err != nil {
 		return nil, err
@@ -196,18 +196,34 @@ func (s *Service) findOrganizationDashboards(ctx context.Context, tx Tx, orgID i
 		return nil, err
 	}
 
-	// TODO(desa): support find options.
 	cur, err := idx.ForwardCursor(prefix, WithCursorPrefix(prefix))
 	if err != nil {
 		return nil, err
 	}
 
-	ds := []*influxdb.Dashboard{}
+	var ids []influxdb.ID
 	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
 		_, id, err := decodeOrgDashboardIndexKey(k)
 		if err != nil {
 			return nil, err
 		}
+		ids = append(ids, id)
+	}
+
+	if p.descending {
+		for l, r := 0, len(ids)-1; l < r; l, r = l+1, r-1 {
+			ids[l], ids[r] = ids[r], ids[l]
+		}
+	}
+
+	ds := []*influxdb.Dashboard{}
+	for _, id := range ids {
+		if p.full(len(ds)) {
+			break
+		}
+		if !p.include(id) {
+			continue
+		}
 
 		d, err := s.findDashboardByID(ctx, tx, id)
 		if err != nil {
@@ -237,8 +253,13 @@ func decodeOrgDashboardIndexKey(indexKey []byte) (orgID influxdb.ID, dashID infl
 }
 
 func (s *Service) findDashboards(ctx context.Context, tx Tx, filter influxdb.DashboardFilter, opts ...influxdb.FindOptions) ([]*influxdb.Dashboard, error) {
+	p, err := newPager(opts...)
+	if err != nil {
+		return nil, err
+	}
+
 	if filter.OrganizationID != nil {
-		return s.findOrganizationDashboards(ctx, tx, *filter.OrganizationID)
+		return s.findOrganizationDashboards(ctx, tx, *filter.OrganizationID, p)
 	}
 
 	if filter.Organization != nil {
@@ -246,30 +267,16 @@ func (s *Service) findDashboards(ctx context.Context, tx Tx, filter influxdb.Das
 		if err != nil {
 			return nil, err
 		}
-		return s.findOrganizationDashboards(ctx, tx, o.ID)
-	}
-
-	var offset, limit, count int
-	var descending bool
-	if len(opts) > 0 {
-		offset = opts[0].Offset
-		limit = opts[0].Limit
-		descending = opts[0].Descending
+		return s.findOrganizationDashboards(ctx, tx, o.ID, p)
 	}
 
 	ds := []*influxdb.Dashboard{}
 	filterFn := filterDashboardsFn(filter)
-	err := s.forEachDashboard(ctx, tx, descending, func(d *influxdb.Dashboard) bool {
-		if filterFn(d) {
-			if count >= offset {
-				ds = append(ds, d)
-			}
-			count++
-		}
-		if limit > 0 && len(ds) >= limit {
-			return false
+	err = s.forEachDashboard(ctx, tx, p.descending, func(d *influxdb.Dashboard) bool {
+		if filterFn(d) && p.include(d.ID) {
+			ds = append(ds, d)
 		}
-		return true
+		return !p.full(len(ds))
 	})
 
 	if err != nil {
@@ -371,6 +378,10 @@ func (s *Service) ReplaceDashboardCells(ctx context.Context, id influxdb.ID, cs
 			return err
 		}
 
+		if err := s.updateRevision(ctx, tx, d.ID); err != nil {
+			return err
+		}
+
 		return s.putDashboardWithMeta(ctx, tx, d)
 	})
 	if err != nil {
@@ -397,6 +408,10 @@ func (s *Service) addDashboardCell(ctx context.Context, tx Tx, id influxdb.ID, c
 		return err
 	}
 
+	if err := s.updateRevision(ctx, tx, d.ID); err != nil {
+		return err
+	}
+
 	return s.putDashboardWithMeta(ctx, tx, d)
 }
 
@@ -451,6 +466,12 @@ func (s *Service) RemoveDashboardCell(ctx context.Context, dashboardID, cellID i
 			}
 		}
 
+		if err := s.updateRevision(ctx, tx, d.ID); err != nil {
+			return &influxdb.Error{
+				Err: err,
+			}
+		}
+
 		if err := s.putDashboardWithMeta(ctx, tx, d); err != nil {
 			return &influxdb.Error{
 				Err: err,
@@ -592,6 +613,10 @@ func (s *Service) UpdateDashboardCellView(ctx context.Context, dashboardID, cell
 			return err
 		}
 
+		if err := s.updateRevision(ctx, tx, dashboardID); err != nil {
+			return err
+		}
+
 		v = view
 		return nil
 	})
@@ -644,6 +669,10 @@ func (s *Service) UpdateDashboardCell(ctx context.Context, dashboardID, cellID i
 			return err
 		}
 
+		if err := s.updateRevision(ctx, tx, d.ID); err != nil {
+			return err
+		}
+
 		return s.putDashboardWithMeta(ctx, tx, d)
 	})
 
@@ -826,6 +855,10 @@ func (s *Service) updateDashboard(ctx context.Context, tx Tx, id influxdb.ID, up
 		return nil, err
 	}
 
+	if err := s.updateRevision(ctx, tx, d.ID); err != nil {
+		return nil, err
+	}
+
 	if err := s.putDashboardWithMeta(ctx, tx, d); err != nil {
 		return nil, err
 	}
@@ -851,6 +884,10 @@ func (s *Service) deleteDashboard(ctx context.Context, tx Tx, id influxdb.ID) er
 		return err
 	}
 
+	if err := s.deleteRevision(ctx, tx, id); err != nil {
+		return err
+	}
+
 	for _, cell := range d.Cells {
 		if err := s.deleteDashboardCellView(ctx, tx, d.ID, cell.ID); err != nil {
 			return &influxdb.Error{
@@ -0,0 +1,242 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	dbrpMappingBucket = []byte("dbrpmappingsv1")
+)
+
+var _ influxdb.DBRPMappingService = (*Service)(nil)
+
+func (s *Service) initializeDBRPMappings(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(dbrpMappingBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// dbrpMappingKey returns the key of the mapping of a cluster, database and
+// retention policy. Valid names cannot contain a slash.
+func dbrpMappingKey(cluster, db, rp string) []byte {
+	return []byte(path.Join(cluster, db, rp))
+}
+
+// FindBy returns the dbrp mapping of a cluster, database and retention policy.
+func (s *Service) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	var m *influxdb.DBRPMapping
+	err := s.kv.View(ctx, func(tx Tx) error {
+		dm, err := s.findDBRPMapping(ctx, tx, cluster, db, rp)
+		if err != nil {
+			return err
+		}
+		m = dm
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindDBRPMappingBy,
+			Err: err,
+		}
+	}
+	return m, nil
+}
+
+func (s *Service) findDBRPMapping(ctx context.Context, tx Tx, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	b, err := tx.Bucket(dbrpMappingBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(dbrpMappingKey(cluster, db, rp))
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrDBRPMappingNotFound,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	m := &influxdb.DBRPMapping{}
+	if err := json.Unmarshal(v, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Find returns the first dbrp mapping that matches filter.
+func (s *Service) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	if filter.Cluster == nil && filter.Database == nil && filter.RetentionPolicy == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "no filter parameters provided",
+		}
+	}
+
+	ms, _, err := s.FindMany(ctx, filter)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindDBRPMapping,
+			Err: err,
+		}
+	}
+	if len(ms) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrDBRPMappingNotFound,
+		}
+	}
+	return ms[0], nil
+}
+
+// FindMany returns the dbrp mappings that match filter and their count.
+func (s *Service) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	ms := []*influxdb.DBRPMapping{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		if filter.Cluster != nil && filter.Database != nil && filter.RetentionPolicy != nil {
+			m, err := s.findDBRPMapping(ctx, tx, *filter.Cluster, *filter.Database, *filter.RetentionPolicy)
+			if err != nil {
+				return err
+			}
+			if filter.Default == nil || *filter.Default == m.Default {
+				ms = append(ms, m)
+			}
+			return nil
+		}
+
+		return s.forEachDBRPMapping(ctx, tx, func(m *influxdb.DBRPMapping) error {
+			if dbrpMappingMatches(filter, m) {
+				ms = append(ms, m)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindDBRPMappings,
+			Err: err,
+		}
+	}
+	return ms, len(ms), nil
+}
+
+func dbrpMappingMatches(filter influxdb.DBRPMappingFilter, m *influxdb.DBRPMapping) bool {
+	return (filter.Cluster == nil || *filter.Cluster == m.Cluster) &&
+		(filter.Database == nil || *filter.Database == m.Database) &&
+		(filter.RetentionPolicy == nil || *filter.RetentionPolicy == m.RetentionPolicy) &&
+		(filter.Default == nil || *filter.Default == m.Default)
+}
+
+func (s *Service) forEachDBRPMapping(ctx context.Context, tx Tx, fn func(m *influxdb.DBRPMapping) error) error {
+	b, err := tx.Bucket(dbrpMappingBucket)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		m := &influxdb.DBRPMapping{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return err
+		}
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
+
+// Create creates a dbrp mapping. Creating a mapping identical to an existing
+// one is not an error. A default mapping replaces the previous default mapping
+// of the cluster and database, which becomes a mapping of its retention policy
+// only.
+func (s *Service) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		existing, err := s.findDBRPMapping(ctx, tx, m.Cluster, m.Database, m.RetentionPolicy)
+		if err == nil {
+			if !existing.Equal(m) {
+				return &influxdb.Error{
+					Code: influxdb.EConflict,
+					Msg:  "dbrp mapping already exists",
+				}
+			}
+			return nil
+		} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return err
+		}
+
+		if m.Default {
+			var defaults []*influxdb.DBRPMapping
+			err := s.forEachDBRPMapping(ctx, tx, func(dm *influxdb.DBRPMapping) error {
+				if dm.Default && dm.Cluster == m.Cluster && dm.Database == m.Database {
+					defaults = append(defaults, dm)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for _, dm := range defaults {
+				dm.Default = false
+				if err := s.putDBRPMapping(ctx, tx, dm); err != nil {
+					return err
+				}
+			}
+		}
+		return s.putDBRPMapping(ctx, tx, m)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateDBRPMapping,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putDBRPMapping(ctx context.Context, tx Tx, m *influxdb.DBRPMapping) error {
+	v, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(dbrpMappingBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(dbrpMappingKey(m.Cluster, m.Database, m.RetentionPolicy), v)
+}
+
+// Delete removes a dbrp mapping. Deleting a mapping that does not exist is not
+// an error.
+func (s *Service) Delete(ctx context.Context, cluster, db, rp string) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(dbrpMappingBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(dbrpMappingKey(cluster, db, rp))
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteDBRPMapping,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltDBRPMappingService(t *testing.T) {
+	influxdbtesting.CreateDBRPMapping(initBoltDBRPMappingService, t)
+	influxdbtesting.FindDBRPMappingByKey(initBoltDBRPMappingService, t)
+	influxdbtesting.FindDBRPMappings(initBoltDBRPMappingService, t)
+	influxdbtesting.DeleteDBRPMapping(initBoltDBRPMappingService, t)
+	influxdbtesting.FindDBRPMapping(initBoltDBRPMappingService, t)
+}
+
+func initBoltDBRPMappingService(f influxdbtesting.DBRPMappingFields, t *testing.T) (influxdb.DBRPMappingService, func()) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, closeSvc := initDBRPMappingService(s, f, t)
+	return svc, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initDBRPMappingService(s kv.Store, f influxdbtesting.DBRPMappingFields, t *testing.T) (influxdb.DBRPMappingService, func()) {
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing dbrp mapping service: %v", err)
+	}
+
+	if err := f.Populate(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+	return svc, func() {}
+}
+
+func TestBoltDBRPMappingService_DefaultMapping(t *testing.T) {
+	svc, done := initBoltDBRPMappingService(influxdbtesting.DBRPMappingFields{}, t)
+	defer done()
+	ctx := context.Background()
+
+	for _, rp := range []string{"autogen", "weekly"} {
+		m := &influxdb.DBRPMapping{
+			Cluster:         "cluster",
+			Database:        "telegraf",
+			RetentionPolicy: rp,
+			Default:         true,
+			OrganizationID:  1,
+			BucketID:        2,
+		}
+		if err := svc.Create(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The last default mapping of a database replaces the previous one.
+	defaults := true
+	ms, n, err := svc.FindMany(ctx, influxdb.DBRPMappingFilter{Default: &defaults})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || ms[0].RetentionPolicy != "weekly" {
+		t.Fatalf("got default mappings %+v, expected the mapping of weekly", ms)
+	}
+	if m, err := svc.FindBy(ctx, "cluster", "telegraf", "autogen"); err != nil {
+		t.Fatal(err)
+	} else if m.Default {
+		t.Fatal("got default mapping of autogen, expected it to be replaced")
+	}
+}
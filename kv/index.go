@@ -0,0 +1,226 @@
+package kv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+// IndexMapping describes a secondary index of the values of a source bucket
+// by a foreign key found in each value, such as the ID of the organization of
+// a resource.
+type IndexMapping interface {
+	// SourceBucket is the bucket of the values indexed.
+	SourceBucket() []byte
+	// IndexBucket is the bucket of the entries of the index.
+	IndexBucket() []byte
+	// IndexSourceOn returns the foreign key of a value of the source bucket,
+	// or nil if the value is not indexed.
+	IndexSourceOn(value []byte) (foreignKey []byte, err error)
+}
+
+type indexMapping struct {
+	source []byte
+	index  []byte
+	fn     func(value []byte) ([]byte, error)
+}
+
+// NewIndexMapping returns an IndexMapping of the values of the bucket source
+// in the bucket index, by the foreign key fn returns for each value.
+func NewIndexMapping(source, index []byte, fn func(value []byte) ([]byte, error)) IndexMapping {
+	return &indexMapping{source: source, index: index, fn: fn}
+}
+
+func (m *indexMapping) SourceBucket() []byte { return m.source }
+func (m *indexMapping) IndexBucket() []byte  { return m.index }
+
+func (m *indexMapping) IndexSourceOn(value []byte) ([]byte, error) { return m.fn(value) }
+
+// Index is a secondary index of the values of a source bucket. Its entries are
+// keyed by the foreign key and the primary key of each value, so the values of
+// a foreign key are found by a prefix scan of the index rather than a scan of
+// the whole source bucket.
+//
+// The index does not maintain itself: services insert and delete the entry of
+// a value in the transaction which puts or deletes the value. Populate fills
+// the index from the values already stored, and IndexMigration applies it as a
+// migration of the store.
+type Index struct {
+	IndexMapping
+}
+
+// NewIndex returns an Index of mapping.
+func NewIndex(mapping IndexMapping) *Index {
+	return &Index{IndexMapping: mapping}
+}
+
+// indexKey returns the key of the entry of primaryKey under foreignKey. The
+// foreign key is prefixed by its length, so that a foreign key which is a
+// prefix of another does not match its entries.
+func indexKey(foreignKey, primaryKey []byte) []byte {
+	k := make([]byte, 0, 2+len(foreignKey)+len(primaryKey))
+	k = indexPrefix(k, foreignKey)
+	return append(k, primaryKey...)
+}
+
+func indexPrefix(b, foreignKey []byte) []byte {
+	b = append(b, byte(len(foreignKey)>>8), byte(len(foreignKey)))
+	return append(b, foreignKey...)
+}
+
+// Insert adds the entry of the value of primaryKey under foreignKey.
+func (i *Index) Insert(tx Tx, foreignKey, primaryKey []byte) error {
+	if len(foreignKey) > 0xffff {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("index %s: foreign key too long", i.IndexBucket()),
+		}
+	}
+
+	bkt, err := tx.Bucket(i.IndexBucket())
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+	if err := bkt.Put(indexKey(foreignKey, primaryKey), primaryKey); err != nil {
+		return UnexpectedIndexError(err)
+	}
+	return nil
+}
+
+// Delete removes the entry of the value of primaryKey under foreignKey.
+func (i *Index) Delete(tx Tx, foreignKey, primaryKey []byte) error {
+	bkt, err := tx.Bucket(i.IndexBucket())
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+	if err := bkt.Delete(indexKey(foreignKey, primaryKey)); err != nil {
+		return UnexpectedIndexError(err)
+	}
+	return nil
+}
+
+// Walk calls visit with the primary key and value of each value of the source
+// bucket under foreignKey, in the order of their primary keys, until visit
+// returns false or an error. Entries whose values no longer exist are skipped.
+func (i *Index) Walk(ctx context.Context, tx Tx, foreignKey []byte, descending bool, visit func(k, v []byte) (bool, error)) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	idx, err := tx.Bucket(i.IndexBucket())
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+	src, err := tx.Bucket(i.SourceBucket())
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+
+	prefix := indexPrefix(nil, foreignKey)
+	cur, err := idx.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+
+	// The primary keys are read before any value, as cursors of some stores
+	// may not be used while other keys are read.
+	var keys [][]byte
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		keys = append(keys, append([]byte(nil), v...))
+	}
+	if err := cur.Err(); err != nil {
+		cur.Close()
+		return UnexpectedIndexError(err)
+	}
+	if err := cur.Close(); err != nil {
+		return UnexpectedIndexError(err)
+	}
+
+	if descending {
+		for l, r := 0, len(keys)-1; l < r; l, r = l+1, r-1 {
+			keys[l], keys[r] = keys[r], keys[l]
+		}
+	}
+
+	for _, k := range keys {
+		v, err := src.Get(k)
+		if IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if ok, err := visit(k, v); err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// Populate inserts the entry of every value of the source bucket.
+func (i *Index) Populate(ctx context.Context, tx Tx) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// The index bucket is created even if there is nothing to index, as
+	// buckets are not created by read-only transactions.
+	if _, err := tx.Bucket(i.IndexBucket()); err != nil {
+		return UnexpectedIndexError(err)
+	}
+	src, err := tx.Bucket(i.SourceBucket())
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+	cur, err := src.ForwardCursor(nil)
+	if err != nil {
+		return UnexpectedIndexError(err)
+	}
+
+	type entry struct{ fk, pk []byte }
+	var entries []entry
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		fk, err := i.IndexSourceOn(v)
+		if err != nil {
+			cur.Close()
+			return err
+		}
+		if fk != nil {
+			entries = append(entries, entry{fk: fk, pk: append([]byte(nil), k...)})
+		}
+	}
+	if err := cur.Err(); err != nil {
+		cur.Close()
+		return UnexpectedIndexError(err)
+	}
+	if err := cur.Close(); err != nil {
+		return UnexpectedIndexError(err)
+	}
+
+	for _, e := range entries {
+		if err := i.Insert(tx, e.fk, e.pk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexMigration returns a migration which populates i from the values of
+// its source bucket.
+func IndexMigration(name string, i *Index) Migration {
+	return Migration{
+		Name: name,
+		Up:   i.Populate,
+	}
+}
+
+// foreignID returns a function which returns the encoded ID fn finds in a
+// value, or nil if the ID is not valid.
+func foreignID(fn func(value []byte) (influxdb.ID, error)) func([]byte) ([]byte, error) {
+	return func(value []byte) ([]byte, error) {
+		id, err := fn(value)
+		if err != nil || !id.Valid() {
+			return nil, err
+		}
+		return id.Encode()
+	}
+}
@@ -0,0 +1,112 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestIndex(t *testing.T) {
+	var (
+		source = []byte("indexsource")
+		index  = kv.NewIndex(kv.NewIndexMapping(source, []byte("indexbyowner"), func(v []byte) ([]byte, error) {
+			// values are "<owner>:<name>"; values without an owner are not indexed.
+			for i, b := range v {
+				if b == ':' {
+					if i == 0 {
+						return nil, nil
+					}
+					return v[:i], nil
+				}
+			}
+			return nil, nil
+		}))
+	)
+
+	walk := func(t *testing.T, store kv.Store, fk string, descending bool) []string {
+		t.Helper()
+		var got []string
+		view(t, store, func(tx kv.Tx) error {
+			return index.Walk(context.Background(), tx, []byte(fk), descending, func(k, v []byte) (bool, error) {
+				got = append(got, string(k)+"="+string(v))
+				return true, nil
+			})
+		})
+		return got
+	}
+
+	store, done, err := NewTestBoltStore(t)
+	require.NoError(t, err)
+	defer done()
+
+	// Walking before anything is indexed finds nothing.
+	update(t, store, func(tx kv.Tx) error {
+		return index.Populate(context.Background(), tx)
+	})
+	assert.Empty(t, walk(t, store, "a", false))
+
+	values := map[string]string{
+		"1": "a:one",
+		"2": "ab:two",
+		"3": "a:three",
+		"4": ":four",
+	}
+	update(t, store, func(tx kv.Tx) error {
+		b, err := tx.Bucket(source)
+		if err != nil {
+			return err
+		}
+		for k, v := range values {
+			if err := b.Put([]byte(k), []byte(v)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	m := kv.NewMigrator(zaptest.NewLogger(t), kv.IndexMigration("index source by owner", index))
+	require.NoError(t, m.Up(context.Background(), store))
+
+	assert.Equal(t, []string{"1=a:one", "3=a:three"}, walk(t, store, "a", false))
+	assert.Equal(t, []string{"3=a:three", "1=a:one"}, walk(t, store, "a", true))
+	assert.Equal(t, []string{"2=ab:two"}, walk(t, store, "ab", false))
+	assert.Empty(t, walk(t, store, "", false))
+
+	// Entries of deleted values are skipped, and deleted entries are not found.
+	update(t, store, func(tx kv.Tx) error {
+		b, err := tx.Bucket(source)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete([]byte("1")); err != nil {
+			return err
+		}
+		return index.Delete(tx, []byte("ab"), []byte("2"))
+	})
+	assert.Equal(t, []string{"3=a:three"}, walk(t, store, "a", false))
+	assert.Empty(t, walk(t, store, "ab", false))
+
+	// Walk stops when visit returns false.
+	update(t, store, func(tx kv.Tx) error {
+		b, err := tx.Bucket(source)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte("5"), []byte("a:five")); err != nil {
+			return err
+		}
+		return index.Insert(tx, []byte("a"), []byte("5"))
+	})
+	var n int
+	view(t, store, func(tx kv.Tx) error {
+		return index.Walk(context.Background(), tx, []byte("a"), false, func(k, v []byte) (bool, error) {
+			n++
+			return false, nil
+		})
+	})
+	assert.Equal(t, 1, n)
+}
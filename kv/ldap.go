@@ -0,0 +1,194 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	ldapBucket            = []byte("ldapv1")
+	ldapConfigKey         = []byte("config")
+	ldapMembershipsBucket = []byte("ldapmembershipsv1")
+)
+
+var _ influxdb.LDAPService = (*Service)(nil)
+
+func (s *Service) initializeLDAP(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(ldapBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(ldapMembershipsBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindLDAPConfig returns the configuration of the LDAP directory.
+func (s *Service) FindLDAPConfig(ctx context.Context) (*influxdb.LDAPConfig, error) {
+	c := &influxdb.LDAPConfig{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(ldapBucket)
+		if err != nil {
+			return err
+		}
+
+		data, err := b.Get(ldapConfigKey)
+		if IsNotFound(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := json.Unmarshal(data, c); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Err:  err,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLDAPConfig,
+			Err: err,
+		}
+	}
+	return c, nil
+}
+
+// PutLDAPConfig replaces the configuration of the LDAP directory.
+func (s *Service) PutLDAPConfig(ctx context.Context, c *influxdb.LDAPConfig) error {
+	if err := c.Validate(); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutLDAPConfig,
+			Err: err,
+		}
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(ldapBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(ldapConfigKey, data)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutLDAPConfig,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindLDAPMemberships returns the memberships added by syncs of the LDAP
+// directory.
+func (s *Service) FindLDAPMemberships(ctx context.Context) ([]*influxdb.LDAPMembership, error) {
+	ms := []*influxdb.LDAPMembership{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(ldapMembershipsBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, data := cur.Next(); k != nil; k, data = cur.Next() {
+			m := &influxdb.LDAPMembership{}
+			if err := json.Unmarshal(data, m); err != nil {
+				return err
+			}
+			ms = append(ms, m)
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindLDAPMemberships,
+			Err: err,
+		}
+	}
+	return ms, nil
+}
+
+// PutLDAPMembership records a membership added by a sync of the LDAP
+// directory.
+func (s *Service) PutLDAPMembership(ctx context.Context, m *influxdb.LDAPMembership) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		key, err := ldapMembershipKey(m.OrgID, m.UserID)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(ldapMembershipsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, data)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpPutLDAPMembership,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteLDAPMembership removes the record of a membership added by a sync of
+// the LDAP directory.
+func (s *Service) DeleteLDAPMembership(ctx context.Context, orgID, userID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		key, err := ldapMembershipKey(orgID, userID)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(ldapMembershipsBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(key)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteLDAPMembership,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func ldapMembershipKey(orgID, userID influxdb.ID) ([]byte, error) {
+	o, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	u, err := userID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	return append(o, u...), nil
+}
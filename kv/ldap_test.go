@@ -0,0 +1,83 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltLDAPService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing ldap service: %v", err)
+	}
+
+	c, err := svc.FindLDAPConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Enabled {
+		t.Fatal("expected the directory to be disabled before it is configured")
+	}
+
+	c = &influxdb.LDAPConfig{
+		Enabled:              true,
+		URL:                  "ldap://ldap.example.com",
+		UserSearchBase:       "ou=people,dc=example,dc=com",
+		UserFilter:           "(uid=%s)",
+		UsernameAttribute:    "uid",
+		GroupMemberAttribute: "member",
+		SyncInterval:         influxdb.Duration{Duration: time.Hour},
+		Mappings: []influxdb.LDAPGroupMapping{
+			{GroupDN: "cn=admins,ou=groups,dc=example,dc=com", OrgID: 1, Role: influxdb.Owner},
+		},
+	}
+	if err := svc.PutLDAPConfig(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+	got, err := svc.FindLDAPConfig(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Fatalf("got config %+v, want %+v", got, c)
+	}
+
+	invalid := *c
+	invalid.UserFilter = "(uid=admin)"
+	if err := svc.PutLDAPConfig(ctx, &invalid); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v configuring a user filter without the user name, want an invalid error", err)
+	}
+
+	ms := []*influxdb.LDAPMembership{
+		{OrgID: 1, UserID: 2, Role: influxdb.Owner},
+		{OrgID: 1, UserID: 3, Role: influxdb.Member},
+	}
+	for _, m := range ms {
+		if err := svc.PutLDAPMembership(ctx, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.DeleteLDAPMembership(ctx, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	gotMs, err := svc.FindLDAPMemberships(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotMs, ms[1:]) {
+		t.Fatalf("got memberships %+v, want %+v", gotMs, ms[1:])
+	}
+}
@@ -0,0 +1,234 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	materializedViewBucket = []byte("materializedviewsv1")
+)
+
+var _ influxdb.MaterializedViewService = (*Service)(nil)
+
+func (s *Service) initializeMaterializedViews(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(materializedViewBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindMaterializedViewByID returns a materialized view by ID, with the
+// authorization of its owner.
+func (s *Service) FindMaterializedViewByID(ctx context.Context, id influxdb.ID) (*influxdb.MaterializedView, error) {
+	var v *influxdb.MaterializedView
+	err := s.kv.View(ctx, func(tx Tx) error {
+		mv, err := s.findMaterializedViewByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		ps, err := s.maxPermissions(ctx, tx, mv.OwnerID)
+		if err != nil {
+			return err
+		}
+		mv.Authorization = &influxdb.Authorization{
+			Status:      influxdb.Active,
+			ID:          influxdb.ID(1),
+			OrgID:       mv.OrgID,
+			UserID:      mv.OwnerID,
+			Permissions: ps,
+		}
+		v = mv
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindMaterializedViewByID,
+			Err: err,
+		}
+	}
+	return v, nil
+}
+
+func (s *Service) findMaterializedViewByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.MaterializedView, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(materializedViewBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := b.Get(key)
+	if IsNotFound(err) {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrMaterializedViewNotFound,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	v := &influxdb.MaterializedView{}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return v, nil
+}
+
+// FindMaterializedViews returns the materialized views that match filter and
+// their count.
+func (s *Service) FindMaterializedViews(ctx context.Context, filter influxdb.MaterializedViewFilter, opt ...influxdb.FindOptions) ([]*influxdb.MaterializedView, int, error) {
+	vs := []*influxdb.MaterializedView{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(materializedViewBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, data := cur.Next(); k != nil; k, data = cur.Next() {
+			v := &influxdb.MaterializedView{}
+			if err := json.Unmarshal(data, v); err != nil {
+				return err
+			}
+			if materializedViewMatches(filter, v) {
+				vs = append(vs, v)
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindMaterializedViews,
+			Err: err,
+		}
+	}
+	return vs, len(vs), nil
+}
+
+func materializedViewMatches(filter influxdb.MaterializedViewFilter, v *influxdb.MaterializedView) bool {
+	return (filter.OrgID == nil || *filter.OrgID == v.OrgID) &&
+		(filter.BucketID == nil || *filter.BucketID == v.BucketID)
+}
+
+// CreateMaterializedView creates a materialized view. Unless the view is
+// given the end of the latest window it has materialized, its first window
+// is the one it is created in.
+func (s *Service) CreateMaterializedView(ctx context.Context, v *influxdb.MaterializedView) error {
+	if err := v.Validate(); err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateMaterializedView,
+			Err: err,
+		}
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		v.ID = s.IDGenerator.ID()
+		now := s.Now()
+		v.SetCreatedAt(now)
+		v.SetUpdatedAt(now)
+		if v.LatestCompleted.IsZero() {
+			v.LatestCompleted = now.UTC().Truncate(v.Every.Duration)
+		}
+		v.LastError = ""
+		return s.putMaterializedView(ctx, tx, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpCreateMaterializedView,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) putMaterializedView(ctx context.Context, tx Tx, v *influxdb.MaterializedView) error {
+	key, err := v.ID.Encode()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(materializedViewBucket)
+	if err != nil {
+		return err
+	}
+	return b.Put(key, data)
+}
+
+// UpdateMaterializedView updates a materialized view. Changing its query does
+// not materialize the windows it has already materialized again.
+func (s *Service) UpdateMaterializedView(ctx context.Context, id influxdb.ID, upd influxdb.MaterializedViewUpdate) (*influxdb.MaterializedView, error) {
+	var v *influxdb.MaterializedView
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		mv, err := s.findMaterializedViewByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		upd.Apply(mv)
+		if err := mv.Validate(); err != nil {
+			return err
+		}
+		mv.SetUpdatedAt(s.Now())
+		if err := s.putMaterializedView(ctx, tx, mv); err != nil {
+			return err
+		}
+		v = mv
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpUpdateMaterializedView,
+			Err: err,
+		}
+	}
+	return v, nil
+}
+
+// DeleteMaterializedView removes a materialized view.
+func (s *Service) DeleteMaterializedView(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findMaterializedViewByID(ctx, tx, id); err != nil {
+			return err
+		}
+
+		key, err := id.Encode()
+		if err != nil {
+			return err
+		}
+		b, err := tx.Bucket(materializedViewBucket)
+		if err != nil {
+			return err
+		}
+		return b.Delete(key)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteMaterializedView,
+			Err: err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltMaterializedViewService(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	now := time.Date(2020, 1, 1, 10, 7, 30, 0, time.UTC)
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	svc.TimeGenerator = mock.TimeGenerator{FakeValue: now}
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing materialized view service: %v", err)
+	}
+
+	v := &influxdb.MaterializedView{
+		OrgID:    1,
+		Name:     "cpu 5m",
+		Query:    `from(bucket: "telegraf") |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> aggregateWindow(every: 5m, fn: mean)`,
+		BucketID: 2,
+		OwnerID:  4,
+		Every:    influxdb.Duration{Duration: 5 * time.Minute},
+	}
+	if err := svc.CreateMaterializedView(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+	// The first window of a view is the one it is created in.
+	if want := time.Date(2020, 1, 1, 10, 5, 0, 0, time.UTC); !v.LatestCompleted.Equal(want) {
+		t.Fatalf("got latest completed %v, want %v", v.LatestCompleted, want)
+	}
+
+	invalid := *v
+	invalid.Query = `from(bucket: "telegraf") |> range(start: -5m)`
+	if err := svc.CreateMaterializedView(ctx, &invalid); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v creating a view not reading its window, want an invalid error", err)
+	}
+
+	other := &influxdb.MaterializedView{
+		OrgID:    1,
+		Name:     "mem 1h",
+		Query:    `from(bucket: "telegraf") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)`,
+		BucketID: 3,
+		OwnerID:  4,
+		Every:    influxdb.Duration{Duration: time.Hour},
+	}
+	if err := svc.CreateMaterializedView(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	bucketID := influxdb.ID(2)
+	vs, n, err := svc.FindMaterializedViews(ctx, influxdb.MaterializedViewFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || vs[0].ID != v.ID {
+		t.Fatalf("got views %+v of bucket 2, want the view %v", vs, v.ID)
+	}
+
+	lastError := "failed"
+	upd, err := svc.UpdateMaterializedView(ctx, v.ID, influxdb.MaterializedViewUpdate{LastError: &lastError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upd.LastError != lastError || upd.Name != v.Name {
+		t.Fatalf("got updated view %+v", upd)
+	}
+
+	every := influxdb.Duration{}
+	if _, err := svc.UpdateMaterializedView(ctx, v.ID, influxdb.MaterializedViewUpdate{Every: &every}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v updating the view to an invalid one, want an invalid error", err)
+	}
+
+	if err := svc.DeleteMaterializedView(ctx, v.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindMaterializedViewByID(ctx, v.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("got error %v finding a deleted view, want a not found error", err)
+	}
+	if err := svc.DeleteMaterializedView(ctx, v.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("got error %v deleting a deleted view, want a not found error", err)
+	}
+}
@@ -0,0 +1,173 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MeasurementSchemaService = (*Service)(nil)
+
+func newMeasurementSchemaStore() *IndexStore {
+	const resource = "measurement schema"
+
+	var decodeSchemaEntFn DecodeBucketValFn = func(key, val []byte) ([]byte, interface{}, error) {
+		var ms influxdb.MeasurementSchema
+		return key, &ms, json.Unmarshal(val, &ms)
+	}
+
+	var decValToEntFn ConvertValToEntFn = func(_ []byte, i interface{}) (Entity, error) {
+		ms, ok := i.(*influxdb.MeasurementSchema)
+		if err := IsErrUnexpectedDecodeVal(ok); err != nil {
+			return Entity{}, err
+		}
+		return measurementSchemaEntity(ms), nil
+	}
+
+	return &IndexStore{
+		Resource:   resource,
+		EntStore:   NewStoreBase(resource, []byte("measurementschemasv1"), EncIDKey, EncBodyJSON, decodeSchemaEntFn, decValToEntFn),
+		IndexStore: NewOrgNameKeyStore(resource, []byte("measurementschemasindexv1"), true),
+	}
+}
+
+// measurementSchemaEntity returns the entity of ms, unique by the name of the
+// measurement within its bucket.
+func measurementSchemaEntity(ms *influxdb.MeasurementSchema) Entity {
+	return Entity{
+		PK:        EncID(ms.ID),
+		UniqueKey: Encode(EncID(ms.BucketID), EncString(ms.Name)),
+		Body:      ms,
+	}
+}
+
+// FindMeasurementSchemaByID returns a single measurement schema by ID.
+func (s *Service) FindMeasurementSchemaByID(ctx context.Context, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	var ms *influxdb.MeasurementSchema
+	err := s.kv.View(ctx, func(tx Tx) error {
+		m, err := s.findMeasurementSchemaByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		ms = m
+		return nil
+	})
+	return ms, err
+}
+
+func (s *Service) findMeasurementSchemaByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.MeasurementSchema, error) {
+	body, err := s.measurementSchemaStore.FindEnt(ctx, tx, Entity{PK: EncID(id)})
+	if err != nil {
+		return nil, err
+	}
+
+	ms, ok := body.(*influxdb.MeasurementSchema)
+	return ms, IsErrUnexpectedDecodeVal(ok)
+}
+
+// FindMeasurementSchemas returns the measurement schemas that match filter.
+func (s *Service) FindMeasurementSchemas(ctx context.Context, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	schemas := []*influxdb.MeasurementSchema{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		ms, err := s.findMeasurementSchemas(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		schemas = ms
+		return nil
+	})
+	return schemas, err
+}
+
+func (s *Service) findMeasurementSchemas(ctx context.Context, tx Tx, filter influxdb.MeasurementSchemaFilter) ([]*influxdb.MeasurementSchema, error) {
+	schemas := []*influxdb.MeasurementSchema{}
+	err := s.measurementSchemaStore.Find(ctx, tx, FindOpts{
+		FilterEntFn: filterMeasurementSchemasFn(filter),
+		CaptureFn: func(key []byte, decodedVal interface{}) error {
+			schemas = append(schemas, decodedVal.(*influxdb.MeasurementSchema))
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
+
+func filterMeasurementSchemasFn(filter influxdb.MeasurementSchemaFilter) func([]byte, interface{}) bool {
+	return func(key []byte, val interface{}) bool {
+		ms, ok := val.(*influxdb.MeasurementSchema)
+		if !ok {
+			return false
+		}
+
+		return (filter.ID == nil || ms.ID == *filter.ID) &&
+			(filter.BucketID == nil || ms.BucketID == *filter.BucketID) &&
+			(filter.Name == nil || ms.Name == *filter.Name)
+	}
+}
+
+// CreateMeasurementSchema creates a new measurement schema of a bucket with an
+// explicit schema and sets ms.ID with the new identifier.
+func (s *Service) CreateMeasurementSchema(ctx context.Context, ms *influxdb.MeasurementSchema) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if err := ms.Valid(); err != nil {
+			return err
+		}
+
+		b, err := s.findBucketByID(ctx, tx, ms.BucketID)
+		if err != nil {
+			return err
+		}
+		if !b.ExplicitSchema() {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Op:   influxdb.OpCreateMeasurementSchema,
+				Msg:  fmt.Sprintf("bucket %q does not have an explicit schema", b.Name),
+			}
+		}
+
+		ms.ID = s.IDGenerator.ID()
+		ms.OrgID = b.OrgID
+		now := s.Now()
+		ms.CreatedAt = now
+		ms.UpdatedAt = now
+		return s.measurementSchemaStore.Put(ctx, tx, measurementSchemaEntity(ms), PutNew())
+	})
+}
+
+// UpdateMeasurementSchema adds columns to a single measurement schema.
+func (s *Service) UpdateMeasurementSchema(ctx context.Context, id influxdb.ID, upd influxdb.MeasurementSchemaUpdate) (*influxdb.MeasurementSchema, error) {
+	var ms *influxdb.MeasurementSchema
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		m, err := s.findMeasurementSchemaByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := upd.Apply(m); err != nil {
+			return err
+		}
+		m.UpdatedAt = s.Now()
+		ms = m
+
+		return s.measurementSchemaStore.Put(ctx, tx, measurementSchemaEntity(m), PutUpdate())
+	})
+	return ms, err
+}
+
+// deleteBucketMeasurementSchemas removes the measurement schemas of the
+// bucket with id.
+func (s *Service) deleteBucketMeasurementSchemas(ctx context.Context, tx Tx, id influxdb.ID) error {
+	schemas, err := s.findMeasurementSchemas(ctx, tx, influxdb.MeasurementSchemaFilter{BucketID: &id})
+	if err != nil {
+		return err
+	}
+	for _, ms := range schemas {
+		if err := s.measurementSchemaStore.DeleteEnt(ctx, tx, measurementSchemaEntity(ms)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_MeasurementSchemas(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org1"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	explicit := &influxdb.Bucket{OrgID: org.ID, Name: "explicit", SchemaType: influxdb.SchemaTypeExplicit}
+	implicit := &influxdb.Bucket{OrgID: org.ID, Name: "implicit"}
+	for _, b := range []*influxdb.Bucket{explicit, implicit} {
+		if err := svc.CreateBucket(ctx, b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	columns := []influxdb.MeasurementSchemaColumn{
+		{Name: "host", Type: influxdb.SchemaColumnTypeTag},
+		{Name: "usage", Type: influxdb.SchemaColumnTypeField, DataType: influxdb.SchemaDataTypeFloat},
+	}
+	ms := &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "cpu", Columns: columns}
+	if err := svc.CreateMeasurementSchema(ctx, ms); err != nil {
+		t.Fatal(err)
+	}
+	if ms.OrgID != org.ID {
+		t.Fatalf("got org ID %s, expected %s", ms.OrgID, org.ID)
+	}
+
+	// Measurements have a single schema per bucket, and only buckets with an
+	// explicit schema have measurement schemas.
+	if err := svc.CreateMeasurementSchema(ctx, &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "cpu", Columns: columns}); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EConflict)
+	}
+	if err := svc.CreateMeasurementSchema(ctx, &influxdb.MeasurementSchema{BucketID: implicit.ID, Name: "cpu", Columns: columns}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+	if err := svc.CreateMeasurementSchema(ctx, &influxdb.MeasurementSchema{BucketID: explicit.ID, Name: "mem", Columns: columns[:1]}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+
+	// Columns may be added, but not changed.
+	added := append(columns[:2:2], influxdb.MeasurementSchemaColumn{Name: "cores", Type: influxdb.SchemaColumnTypeField, DataType: influxdb.SchemaDataTypeInteger})
+	if updated, err := svc.UpdateMeasurementSchema(ctx, ms.ID, influxdb.MeasurementSchemaUpdate{Columns: added}); err != nil {
+		t.Fatal(err)
+	} else if len(updated.Columns) != 3 {
+		t.Fatalf("got %d columns, expected 3", len(updated.Columns))
+	}
+	changed := []influxdb.MeasurementSchemaColumn{columns[0], {Name: "usage", Type: influxdb.SchemaColumnTypeField, DataType: influxdb.SchemaDataTypeString}}
+	if _, err := svc.UpdateMeasurementSchema(ctx, ms.ID, influxdb.MeasurementSchemaUpdate{Columns: changed}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+
+	name := "cpu"
+	if ss, err := svc.FindMeasurementSchemas(ctx, influxdb.MeasurementSchemaFilter{BucketID: &explicit.ID, Name: &name}); err != nil {
+		t.Fatal(err)
+	} else if len(ss) != 1 || ss[0].ID != ms.ID || len(ss[0].Columns) != 3 {
+		t.Fatalf("got schemas %+v, expected the updated schema of cpu", ss)
+	}
+
+	// The schemas of a bucket are deleted with it.
+	if err := svc.DeleteBucket(ctx, explicit.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindMeasurementSchemaByID(ctx, ms.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("got error %v, expected %s", err, influxdb.ENotFound)
+	}
+}
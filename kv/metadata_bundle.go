@@ -0,0 +1,227 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.MetadataBundleService = (*Service)(nil)
+
+// ExportMetadata returns a bundle of the organizations, users, buckets,
+// authorizations, dashboards, tasks and user resource mappings of the store.
+func (s *Service) ExportMetadata(ctx context.Context) (*influxdb.MetadataBundle, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	b := &influxdb.MetadataBundle{
+		Version:    influxdb.MetadataBundleVersion,
+		ExportedAt: s.Now(),
+	}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		return s.exportMetadata(ctx, tx, b)
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Msg: "failed to export metadata",
+			Err: err,
+		}
+	}
+	return b, nil
+}
+
+func (s *Service) exportMetadata(ctx context.Context, tx Tx, b *influxdb.MetadataBundle) error {
+	err := forEachOrganization(ctx, tx, func(o *influxdb.Organization) bool {
+		b.Organizations = append(b.Organizations, o)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachUser(ctx, tx, func(u *influxdb.User) bool {
+		b.Users = append(b.Users, u)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachBucket(ctx, tx, false, func(bkt *influxdb.Bucket) bool {
+		b.Buckets = append(b.Buckets, bkt)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachAuthorization(ctx, tx, nil, func(a *influxdb.Authorization) bool {
+		b.Authorizations = append(b.Authorizations, a)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.forEachDashboard(ctx, tx, false, func(d *influxdb.Dashboard) bool {
+		b.Dashboards = append(b.Dashboards, d)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	// The views are read once the dashboards are listed, as cursors of some
+	// stores may not be used while other keys are read.
+	for _, d := range b.Dashboards {
+		for _, c := range d.Cells {
+			v, err := s.findDashboardCellView(ctx, tx, d.ID, c.ID)
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				continue
+			} else if err != nil {
+				return err
+			}
+			c.View = v
+		}
+	}
+
+	if b.Tasks, err = s.exportTasks(tx); err != nil {
+		return err
+	}
+
+	return s.forEachUserResourceMapping(ctx, tx, nil, func(m *influxdb.UserResourceMapping) bool {
+		b.UserResourceMappings = append(b.UserResourceMappings, m)
+		return true
+	})
+}
+
+// exportTasks returns the tasks as they are stored, without the owners and
+// run times findTaskByID fills in.
+func (s *Service) exportTasks(tx Tx) ([]*influxdb.Task, error) {
+	b, err := tx.Bucket(taskBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	defer cur.Close()
+
+	var ts []*influxdb.Task
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		t := &kvTask{}
+		if err := json.Unmarshal(v, t); err != nil {
+			return nil, influxdb.ErrInternalTaskServiceError(err)
+		}
+		ts = append(ts, kvToInfluxTask(t))
+	}
+	return ts, cur.Err()
+}
+
+// ImportMetadata puts the resources of b with their IDs, and marks the store
+// as onboarded if b has organizations. The resources are put in a single
+// transaction, so nothing is imported if any of them fails.
+func (s *Service) ImportMetadata(ctx context.Context, b *influxdb.MetadataBundle) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if b.Version != influxdb.MetadataBundleVersion {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("metadata bundle version %d is not supported, expected version %d", b.Version, influxdb.MetadataBundleVersion),
+		}
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.importMetadata(ctx, tx, b)
+	})
+}
+
+func (s *Service) importMetadata(ctx context.Context, tx Tx, b *influxdb.MetadataBundle) error {
+	// The indexes by name of organizations, users and buckets are overwritten
+	// by a put, so resources are only imported into a store without them.
+	var exists bool
+	err := forEachOrganization(ctx, tx, func(*influxdb.Organization) bool {
+		exists = true
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "metadata can only be imported into a store without organizations",
+		}
+	}
+
+	for _, o := range b.Organizations {
+		v, err := json.Marshal(o)
+		if err != nil {
+			return influxdb.ErrInternalOrgServiceError(influxdb.OpPutOrganization, err)
+		}
+		if err := s.putOrganization(ctx, tx, o, v); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range b.Users {
+		if err := s.putUser(ctx, tx, u); err != nil {
+			return err
+		}
+	}
+
+	for _, bkt := range b.Buckets {
+		v, err := json.Marshal(bkt)
+		if err != nil {
+			return influxdb.ErrInternalBucketServiceError(influxdb.OpPutBucket, err)
+		}
+		if err := s.putBucket(ctx, tx, bkt, v); err != nil {
+			return err
+		}
+	}
+
+	for _, a := range b.Authorizations {
+		if err := s.putAuthorization(ctx, tx, a); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range b.Dashboards {
+		for _, c := range d.Cells {
+			// Views are stored apart from the dashboard.
+			v := c.View
+			c.View = nil
+			if err := s.createCellView(ctx, tx, d.ID, c.ID, v); err != nil {
+				return err
+			}
+		}
+		if err := s.putOrganizationDashboardIndex(ctx, tx, d); err != nil {
+			return err
+		}
+		if err := s.putDashboard(ctx, tx, d); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range b.Tasks {
+		if _, err := s.putTask(ctx, tx, t); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range b.UserResourceMappings {
+		if err := s.putUserResourceMapping(ctx, tx, m); err != nil {
+			return err
+		}
+	}
+
+	if len(b.Organizations) == 0 {
+		return nil
+	}
+	return s.putOnboardingStatus(ctx, tx, true)
+}
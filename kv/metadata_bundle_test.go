@@ -0,0 +1,107 @@
+package kv_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_ImportMetadata(t *testing.T) {
+	var closeFns []func()
+	defer func() {
+		for _, fn := range closeFns {
+			fn()
+		}
+	}()
+	newService := func(t *testing.T) *kv.Service {
+		t.Helper()
+		store, done, err := NewTestBoltStore(t)
+		require.NoError(t, err)
+		closeFns = append(closeFns, done)
+
+		svc := kv.NewService(zaptest.NewLogger(t), store)
+		require.NoError(t, svc.Initialize(context.Background()))
+		return svc
+	}
+
+	ctx := context.Background()
+	src := newService(t)
+	res, err := src.Generate(ctx, &influxdb.OnboardingRequest{
+		User:     "admin",
+		Password: "password",
+		Org:      "org",
+		Bucket:   "bucket",
+	})
+	require.NoError(t, err)
+
+	ctx = icontext.SetAuthorizer(ctx, res.Auth)
+	d := &influxdb.Dashboard{OrganizationID: res.Org.ID, Name: "dashboard"}
+	require.NoError(t, src.CreateDashboard(ctx, d))
+	cell := &influxdb.Cell{CellProperty: influxdb.CellProperty{W: 4, H: 4}}
+	require.NoError(t, src.AddDashboardCell(ctx, d.ID, cell, influxdb.AddDashboardCellOptions{
+		View: &influxdb.View{
+			ViewContents: influxdb.ViewContents{Name: "view"},
+			Properties:   influxdb.EmptyViewProperties{},
+		},
+	}))
+	task, err := src.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: res.Org.ID,
+		OwnerID:        res.User.ID,
+		Flux:           `option task = {name: "task", every: 1h} from(bucket: "bucket") |> range(start: -1h)`,
+	})
+	require.NoError(t, err)
+
+	exported, err := src.ExportMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, influxdb.MetadataBundleVersion, exported.Version)
+
+	// The bundle is imported as it is read from its encoding.
+	raw, err := json.Marshal(exported)
+	require.NoError(t, err)
+	bundle := &influxdb.MetadataBundle{}
+	require.NoError(t, json.Unmarshal(raw, bundle))
+
+	dst := newService(t)
+	require.NoError(t, dst.ImportMetadata(ctx, bundle))
+
+	reexported, err := dst.ExportMetadata(ctx)
+	require.NoError(t, err)
+	reexported.ExportedAt = exported.ExportedAt
+	assert.Equal(t, exported, reexported)
+
+	onboarding, err := dst.IsOnboarding(ctx)
+	require.NoError(t, err)
+	assert.False(t, onboarding)
+
+	auth, err := dst.FindAuthorizationByToken(ctx, res.Auth.Token)
+	require.NoError(t, err)
+	assert.Equal(t, res.Auth.ID, auth.ID)
+
+	b, err := dst.FindBucketByName(ctx, res.Org.ID, "bucket")
+	require.NoError(t, err)
+	assert.Equal(t, res.Bucket.ID, b.ID)
+
+	v, err := dst.GetDashboardCellView(ctx, d.ID, cell.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "view", v.Name)
+
+	tasks, _, err := dst.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &res.Org.ID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, task.ID, tasks[0].ID)
+
+	// Resources are not imported over those of a store.
+	err = dst.ImportMetadata(ctx, bundle)
+	assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+
+	bundle.Version = influxdb.MetadataBundleVersion + 1
+	err = newService(t).ImportMetadata(ctx, bundle)
+	assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+}
@@ -0,0 +1,186 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var migrationBucket = []byte("migrationsv1")
+
+// Migration is a change to the buckets or the encoding of the data of the
+// store. Up applies the change and Down reverts it. Both must be idempotent,
+// as a migration interrupted before it is recorded is applied again. A
+// migration without Down cannot be reverted.
+type Migration struct {
+	Name string
+	Up   func(ctx context.Context, tx Tx) error
+	Down func(ctx context.Context, tx Tx) error
+}
+
+// migrationRecord records the application of a migration in the store.
+type migrationRecord struct {
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"appliedAt"`
+}
+
+// Migrator applies migrations to a store in the order they are added. The
+// schema version of the store is the number of migrations applied to it,
+// which are recorded in the store with the migrations.
+type Migrator struct {
+	log        *zap.Logger
+	migrations []Migration
+}
+
+// NewMigrator returns a Migrator of the migrations provided.
+func NewMigrator(log *zap.Logger, ms ...Migration) *Migrator {
+	return &Migrator{log: log, migrations: ms}
+}
+
+// AddMigrations adds migrations to apply after those of the migrator. The
+// migrations of a migrator must never be reordered or removed once released.
+func (m *Migrator) AddMigrations(ms ...Migration) {
+	m.migrations = append(m.migrations, ms...)
+}
+
+// Version returns the schema version of the store, and the latest schema
+// version of the migrator.
+func (m *Migrator) Version(ctx context.Context, store Store) (current, latest int, err error) {
+	err = store.Update(ctx, func(tx Tx) error {
+		records, err := m.records(tx)
+		current = len(records)
+		return err
+	})
+	return current, len(m.migrations), err
+}
+
+// Up applies the migrations that have not been applied to the store, each in
+// its own transaction.
+func (m *Migrator) Up(ctx context.Context, store Store) error {
+	for i := range m.migrations {
+		mig := m.migrations[i]
+		applied := false
+		err := store.Update(ctx, func(tx Tx) error {
+			records, err := m.records(tx)
+			if err != nil || len(records) > i {
+				return err
+			}
+
+			if err := mig.Up(ctx, tx); err != nil {
+				return err
+			}
+			applied = true
+			return m.putRecord(tx, i+1, migrationRecord{Name: mig.Name, AppliedAt: time.Now().UTC()})
+		})
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  fmt.Sprintf("failed to apply migration %d %q", i+1, mig.Name),
+				Err:  err,
+			}
+		}
+		if applied {
+			m.log.Info("Applied migration", zap.Int("version", i+1), zap.String("name", mig.Name))
+		}
+	}
+	return nil
+}
+
+// Down reverts the migrations applied to the store after the schema version
+// provided, the latest first, each in its own transaction.
+func (m *Migrator) Down(ctx context.Context, store Store, version int) error {
+	current, _, err := m.Version(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	for i := current - 1; i >= version && i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Down == nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("migration %d %q cannot be reverted", i+1, mig.Name),
+			}
+		}
+
+		err := store.Update(ctx, func(tx Tx) error {
+			if err := mig.Down(ctx, tx); err != nil {
+				return err
+			}
+			b, err := tx.Bucket(migrationBucket)
+			if err != nil {
+				return UnexpectedBucketError(err)
+			}
+			return b.Delete(migrationKey(i + 1))
+		})
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  fmt.Sprintf("failed to revert migration %d %q", i+1, mig.Name),
+				Err:  err,
+			}
+		}
+		m.log.Info("Reverted migration", zap.Int("version", i+1), zap.String("name", mig.Name))
+	}
+	return nil
+}
+
+// records returns the records of the migrations applied to the store, in the
+// order they were applied. It fails if they are not the first migrations of
+// the migrator, as when the store was migrated by a later release.
+func (m *Migrator) records(tx Tx) ([]migrationRecord, error) {
+	b, err := tx.Bucket(migrationBucket)
+	if err != nil {
+		return nil, UnexpectedBucketError(err)
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	var records []migrationRecord
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		var r migrationRecord
+		if err := json.Unmarshal(v, &r); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+
+		version := len(records)
+		if int(binary.BigEndian.Uint64(k)) != version {
+			return nil, fmt.Errorf("migration %d is missing from the store", version)
+		}
+		if version > len(m.migrations) {
+			return nil, fmt.Errorf("store has schema version %d, newer than the latest supported version %d", version, len(m.migrations))
+		}
+		if name := m.migrations[version-1].Name; r.Name != name {
+			return nil, fmt.Errorf("migration %d of the store is %q, expected %q", version, r.Name, name)
+		}
+	}
+	return records, cur.Err()
+}
+
+func (m *Migrator) putRecord(tx Tx, version int, r migrationRecord) error {
+	v, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(migrationBucket)
+	if err != nil {
+		return UnexpectedBucketError(err)
+	}
+	return b.Put(migrationKey(version), v)
+}
+
+func migrationKey(version int) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, uint64(version))
+	return k
+}
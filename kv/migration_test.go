@@ -0,0 +1,86 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+
+	var ups, downs []string
+	migration := func(name string, reversible bool) kv.Migration {
+		m := kv.Migration{
+			Name: name,
+			Up: func(ctx context.Context, tx kv.Tx) error {
+				ups = append(ups, name)
+				return nil
+			},
+		}
+		if reversible {
+			m.Down = func(ctx context.Context, tx kv.Tx) error {
+				downs = append(downs, name)
+				return nil
+			}
+		}
+		return m
+	}
+	checkVersion := func(m *kv.Migrator, expCurrent, expLatest int) {
+		t.Helper()
+		current, latest, err := m.Version(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if current != expCurrent || latest != expLatest {
+			t.Fatalf("got version %d of %d, expected %d of %d", current, latest, expCurrent, expLatest)
+		}
+	}
+
+	m := kv.NewMigrator(zaptest.NewLogger(t), migration("a", false), migration("b", true))
+	checkVersion(m, 0, 2)
+	if err := m.Up(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(m, 2, 2)
+
+	// Migrations already applied are not applied again.
+	m.AddMigrations(migration("c", true))
+	if err := m.Up(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(m, 3, 3)
+	if got, exp := len(ups), 3; got != exp {
+		t.Fatalf("got migrations %v applied, expected %d", ups, exp)
+	}
+
+	// A store migrated by a later release is not migrated.
+	old := kv.NewMigrator(zaptest.NewLogger(t), migration("a", false), migration("b", true))
+	if err := old.Up(ctx, store); err == nil {
+		t.Fatal("expected an error migrating a store with a newer schema version")
+	}
+
+	// Migrations are reverted latest first, down to the first irreversible
+	// migration.
+	if err := m.Down(ctx, store, 1); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(m, 1, 3)
+	if got := downs; len(got) != 2 || got[0] != "c" || got[1] != "b" {
+		t.Fatalf("got migrations %v reverted, expected c and b", got)
+	}
+	if err := m.Down(ctx, store, 0); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+
+	// Reverted migrations are applied again.
+	if err := m.Up(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	checkVersion(m, 3, 3)
+}
@@ -0,0 +1,129 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	networkPolicyBucket = []byte("orgnetworkpoliciesv1")
+)
+
+var _ influxdb.NetworkPolicyService = (*Service)(nil)
+
+func (s *Service) initializeNetworkPolicies(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(networkPolicyBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindNetworkPolicy returns the network policy of an organization, or a
+// policy allowing every address if it has none.
+func (s *Service) FindNetworkPolicy(ctx context.Context, orgID influxdb.ID) (*influxdb.NetworkPolicy, error) {
+	var p *influxdb.NetworkPolicy
+	err := s.kv.View(ctx, func(tx Tx) error {
+		np, err := s.findNetworkPolicy(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		p = np
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindNetworkPolicy,
+			Err: err,
+		}
+	}
+	return p, nil
+}
+
+func (s *Service) findNetworkPolicy(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.NetworkPolicy, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := tx.Bucket(networkPolicyBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return &influxdb.NetworkPolicy{OrgID: orgID}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	p := &influxdb.NetworkPolicy{}
+	if err := json.Unmarshal(v, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetNetworkPolicy replaces the network policy of an organization.
+func (s *Service) SetNetworkPolicy(ctx context.Context, p *influxdb.NetworkPolicy) error {
+	if err := p.Valid(); err != nil {
+		return err
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, p.OrgID); err != nil {
+			return err
+		}
+
+		key, err := p.OrgID.Encode()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(networkPolicyBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpSetNetworkPolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteNetworkPolicy removes the network policy of an organization.
+func (s *Service) DeleteNetworkPolicy(ctx context.Context, orgID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.deleteNetworkPolicy(ctx, tx, orgID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteNetworkPolicy,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteNetworkPolicy(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	key, err := orgID.Encode()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(networkPolicyBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
@@ -0,0 +1,59 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_NetworkPolicies(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org1"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	// Organizations without a policy allow every address.
+	if p, err := svc.FindNetworkPolicy(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(p, &influxdb.NetworkPolicy{OrgID: org.ID}) {
+		t.Fatalf("got policy %+v, expected an empty policy", p)
+	}
+
+	policy := &influxdb.NetworkPolicy{OrgID: org.ID, Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.0.0.1"}}
+	if err := svc.SetNetworkPolicy(ctx, policy); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := svc.FindNetworkPolicy(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(p, policy) {
+		t.Fatalf("got policy %+v, expected %+v", p, policy)
+	}
+
+	if err := svc.SetNetworkPolicy(ctx, &influxdb.NetworkPolicy{OrgID: org.ID, Allow: []string{"10.0.0.0/33"}}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+	if err := svc.SetNetworkPolicy(ctx, &influxdb.NetworkPolicy{OrgID: org.ID + 1}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("got error %v, expected %s", err, influxdb.ENotFound)
+	}
+
+	// The policy of an organization is deleted with it.
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := svc.FindNetworkPolicy(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(p, &influxdb.NetworkPolicy{OrgID: org.ID}) {
+		t.Fatalf("got policy %+v, expected an empty policy", p)
+	}
+}
@@ -264,12 +264,17 @@ func (s *Service) findNotificationEndpoints(ctx context.Context, tx Tx, filter i
 	if len(opt) > 0 {
 		o = opt[0]
 	}
+	after, err := afterKey(o)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	edps := make([]influxdb.NotificationEndpoint, 0)
 	err = s.endpointStore.Find(ctx, tx, FindOpts{
 		Descending:  o.Descending,
 		Offset:      o.Offset,
 		Limit:       o.Limit,
+		After:       after,
 		FilterEntFn: filterEndpointsFn(idMap, filter),
 		CaptureFn: func(k []byte, v interface{}) error {
 			edp, ok := v.(influxdb.NotificationEndpoint)
@@ -183,6 +183,10 @@ func (s *Service) updateNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return nil, err
 	}
 
+	if err := s.updateRevision(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
 	// ID and OrganizationID can not be updated
 	nr.SetID(current.GetID())
 	nr.SetOrgID(current.GetOrgID())
@@ -234,6 +238,10 @@ func (s *Service) patchNotificationRule(ctx context.Context, tx Tx, id influxdb.
 		return nil, err
 	}
 
+	if err := s.updateRevision(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
 	if upd.Name != nil {
 		nr.SetName(*upd.Name)
 	}
@@ -374,27 +382,16 @@ func (s *Service) findNotificationRules(ctx context.Context, tx Tx, filter influ
 		filter.OrgID = &o.ID
 	}
 
-	var offset, limit, count int
-	var descending bool
-	if len(opt) > 0 {
-		offset = opt[0].Offset
-		limit = opt[0].Limit
-		descending = opt[0].Descending
+	p, err := newPager(opt...)
+	if err != nil {
+		return nrs, 0, err
 	}
 	filterFn := filterNotificationRulesFn(idMap, filter)
-	err = s.forEachNotificationRule(ctx, tx, descending, func(nr influxdb.NotificationRule) bool {
-		if filterFn(nr) {
-			if count >= offset {
-				nrs = append(nrs, nr)
-			}
-			count++
+	err = s.forEachNotificationRule(ctx, tx, p.descending, func(nr influxdb.NotificationRule) bool {
+		if filterFn(nr) && p.include(nr.GetID()) {
+			nrs = append(nrs, nr)
 		}
-
-		if limit > 0 && len(nrs) >= limit {
-			return false
-		}
-
-		return true
+		return !p.full(len(nrs))
 	})
 
 	return nrs, len(nrs), err
@@ -466,6 +463,10 @@ func (s *Service) deleteNotificationRule(ctx context.Context, tx Tx, id influxdb
 		return err
 	}
 
+	if err := s.deleteRevision(ctx, tx, id); err != nil {
+		return err
+	}
+
 	if err := s.deleteTask(ctx, tx, r.GetTaskID()); err != nil {
 		return err
 	}
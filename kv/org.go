@@ -313,7 +313,7 @@ func (s *Service) createOrganization(ctx context.Context, tx Tx, o *influxdb.Org
 		OrganizationID: o.ID,
 		UserID:         uid,
 		ResourceBody:   v,
-		Time:           time.Now(),
+		Time:           s.Now(),
 	})
 }
 
@@ -341,7 +341,7 @@ func (s *Service) PutOrganization(ctx context.Context, o *influxdb.Organization)
 			OrganizationID: o.ID,
 			UserID:         uid,
 			ResourceBody:   v,
-			Time:           time.Now(),
+			Time:           s.Now(),
 		})
 	})
 }
@@ -492,7 +492,7 @@ func (s *Service) updateOrganization(ctx context.Context, tx Tx, id influxdb.ID,
 		OrganizationID: o.ID,
 		UserID:         uid,
 		ResourceBody:   v,
-		Time:           time.Now(),
+		Time:           s.Now(),
 	}); err != nil {
 		return nil, &influxdb.Error{
 			Err: err,
@@ -527,6 +527,12 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 		if pe := s.deleteOrganization(ctx, tx, id); pe != nil {
 			return pe
 		}
+		if err := s.deleteOrgQuota(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := s.deleteNetworkPolicy(ctx, tx, id); err != nil {
+			return err
+		}
 
 		uid, _ := icontext.GetUserID(ctx)
 		return s.audit.Log(resource.Change{
@@ -535,7 +541,7 @@ func (s *Service) DeleteOrganization(ctx context.Context, id influxdb.ID) error
 			ResourceType:   influxdb.OrgsResourceType,
 			OrganizationID: id,
 			UserID:         uid,
-			Time:           time.Now(),
+			Time:           s.Now(),
 		})
 	})
 	if err != nil {
@@ -0,0 +1,62 @@
+package kv
+
+import (
+	"github.com/influxdata/influxdb"
+)
+
+// pager selects the entries of a page of a listing of resources in the order
+// of their IDs, by the continuation token or offset of its find options.
+type pager struct {
+	after      influxdb.ID
+	descending bool
+	offset     int
+	limit      int
+	count      int
+}
+
+func newPager(opts ...influxdb.FindOptions) (*pager, error) {
+	p := &pager{after: influxdb.InvalidID()}
+	if len(opts) == 0 {
+		return p, nil
+	}
+
+	after, err := opts[0].AfterID()
+	if err != nil {
+		return nil, err
+	}
+	p.after = after
+	p.descending = opts[0].Descending
+	p.offset = opts[0].Offset
+	p.limit = opts[0].Limit
+	return p, nil
+}
+
+// include reports whether the entry of id, which matches the filter of the
+// listing, is in the page. It must be called for each matching entry in the
+// order they are listed.
+func (p *pager) include(id influxdb.ID) bool {
+	if p.after.Valid() {
+		if p.descending {
+			return id < p.after
+		}
+		return id > p.after
+	}
+
+	p.count++
+	return p.count > p.offset
+}
+
+// full reports whether a page of n entries is complete.
+func (p *pager) full(n int) bool {
+	return p.limit > 0 && n >= p.limit
+}
+
+// afterKey returns the key of the entry the continuation token of opts was
+// issued for, or nil if it is not set.
+func afterKey(opts influxdb.FindOptions) ([]byte, error) {
+	id, err := opts.AfterID()
+	if err != nil || !id.Valid() {
+		return nil, err
+	}
+	return id.Encode()
+}
@@ -0,0 +1,162 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	orgQuotaBucket = []byte("orgquotasv1")
+)
+
+var _ influxdb.OrgQuotaService = (*Service)(nil)
+
+func (s *Service) initializeOrgQuotas(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(orgQuotaBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindOrgQuota returns the quota of an organization, or a quota without limits
+// if it has none.
+func (s *Service) FindOrgQuota(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgQuota, error) {
+	var q *influxdb.OrgQuota
+	err := s.kv.View(ctx, func(tx Tx) error {
+		oq, err := s.findOrgQuota(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		q = oq
+		return nil
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgQuota,
+			Err: err,
+		}
+	}
+	return q, nil
+}
+
+func (s *Service) findOrgQuota(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.OrgQuota, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := tx.Bucket(orgQuotaBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return &influxdb.OrgQuota{OrgID: orgID}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	q := &influxdb.OrgQuota{}
+	if err := json.Unmarshal(v, q); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// FindOrgQuotas returns the quotas of every organization with a quota.
+func (s *Service) FindOrgQuotas(ctx context.Context) ([]*influxdb.OrgQuota, error) {
+	qs := []*influxdb.OrgQuota{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(orgQuotaBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			q := &influxdb.OrgQuota{}
+			if err := json.Unmarshal(v, q); err != nil {
+				return err
+			}
+			qs = append(qs, q)
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpFindOrgQuotas,
+			Err: err,
+		}
+	}
+	return qs, nil
+}
+
+// SetOrgQuota replaces the quota of an organization.
+func (s *Service) SetOrgQuota(ctx context.Context, q *influxdb.OrgQuota) error {
+	if err := q.Valid(); err != nil {
+		return err
+	}
+
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, q.OrgID); err != nil {
+			return err
+		}
+
+		key, err := q.OrgID.Encode()
+		if err != nil {
+			return err
+		}
+		v, err := json.Marshal(q)
+		if err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(orgQuotaBucket)
+		if err != nil {
+			return err
+		}
+		return b.Put(key, v)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpSetOrgQuota,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// DeleteOrgQuota removes the limits of an organization.
+func (s *Service) DeleteOrgQuota(ctx context.Context, orgID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		return s.deleteOrgQuota(ctx, tx, orgID)
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpDeleteOrgQuota,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteOrgQuota(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	key, err := orgID.Encode()
+	if err != nil {
+		return err
+	}
+
+	b, err := tx.Bucket(orgQuotaBucket)
+	if err != nil {
+		return err
+	}
+	return b.Delete(key)
+}
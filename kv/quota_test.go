@@ -0,0 +1,64 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_OrgQuotas(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org1"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	// Organizations without a quota are unlimited.
+	if q, err := svc.FindOrgQuota(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(q, &influxdb.OrgQuota{OrgID: org.ID}) {
+		t.Fatalf("got quota %+v, expected no limits", q)
+	}
+
+	quota := &influxdb.OrgQuota{OrgID: org.ID, MaxBuckets: 2, MaxQueriesPerMinute: 10}
+	if err := svc.SetOrgQuota(ctx, quota); err != nil {
+		t.Fatal(err)
+	}
+	if q, err := svc.FindOrgQuota(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(q, quota) {
+		t.Fatalf("got quota %+v, expected %+v", q, quota)
+	}
+	if qs, err := svc.FindOrgQuotas(ctx); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(qs, []*influxdb.OrgQuota{quota}) {
+		t.Fatalf("got quotas %+v, expected %+v", qs, quota)
+	}
+
+	if err := svc.SetOrgQuota(ctx, &influxdb.OrgQuota{OrgID: org.ID, MaxSeries: -1}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+	if err := svc.SetOrgQuota(ctx, &influxdb.OrgQuota{OrgID: org.ID + 1, MaxSeries: 1}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("got error %v, expected %s", err, influxdb.ENotFound)
+	}
+
+	// The quota of an organization is deleted with it.
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	}
+	if qs, err := svc.FindOrgQuotas(ctx); err != nil {
+		t.Fatal(err)
+	} else if len(qs) != 0 {
+		t.Fatalf("got quotas %+v, expected none", qs)
+	}
+}
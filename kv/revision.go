@@ -0,0 +1,117 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	revisionBucket = []byte("revisionsv1")
+)
+
+var _ influxdb.RevisionService = (*Service)(nil)
+
+func (s *Service) initializeRevisions(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(revisionBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindRevision returns the revision of the resource with id. Resources that
+// have never been updated are at revision 0.
+func (s *Service) FindRevision(ctx context.Context, id influxdb.ID) (uint64, error) {
+	var rev uint64
+	err := s.kv.View(ctx, func(tx Tx) error {
+		r, err := s.findRevision(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		rev = r
+		return nil
+	})
+	if err != nil {
+		return 0, &influxdb.Error{
+			Op:  influxdb.OpFindRevision,
+			Err: err,
+		}
+	}
+	return rev, nil
+}
+
+func (s *Service) findRevision(ctx context.Context, tx Tx, id influxdb.ID) (uint64, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	b, err := tx.Bucket(revisionBucket)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if len(v) != 8 {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "invalid revision",
+		}
+	}
+	return binary.BigEndian.Uint64(v), nil
+}
+
+// updateRevision increments the revision of the resource with id, once the
+// revision expected by ctx, if any, has been checked.
+func (s *Service) updateRevision(ctx context.Context, tx Tx, id influxdb.ID) error {
+	rev, err := s.findRevision(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := influxdb.CheckExpectedRevision(ctx, id, rev); err != nil {
+		return err
+	}
+
+	key, err := id.Encode()
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(revisionBucket)
+	if err != nil {
+		return err
+	}
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], rev+1)
+	return b.Put(key, v[:])
+}
+
+// deleteRevision removes the revision of the resource with id, once the
+// revision expected by ctx, if any, has been checked.
+func (s *Service) deleteRevision(ctx context.Context, tx Tx, id influxdb.ID) error {
+	rev, err := s.findRevision(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := influxdb.CheckExpectedRevision(ctx, id, rev); err != nil {
+		return err
+	}
+
+	key, err := id.Encode()
+	if err != nil {
+		return err
+	}
+	b, err := tx.Bucket(revisionBucket)
+	if err != nil {
+		return err
+	}
+	if err := b.Delete(key); err != nil && !IsNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestService_Revisions(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	org := &influxdb.Organization{Name: "org1"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	d := &influxdb.Dashboard{Name: "dashboard1", OrganizationID: org.ID}
+	if err := svc.CreateDashboard(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	revision := func(want uint64) {
+		t.Helper()
+		if rev, err := svc.FindRevision(ctx, d.ID); err != nil {
+			t.Fatal(err)
+		} else if rev != want {
+			t.Fatalf("got revision %d, expected %d", rev, want)
+		}
+	}
+	rename := func(ctx context.Context, name string) error {
+		_, err := svc.UpdateDashboard(ctx, d.ID, influxdb.DashboardUpdate{Name: &name})
+		return err
+	}
+
+	// Dashboards that have never been updated are at revision 0.
+	revision(0)
+
+	if err := rename(ctx, "dashboard2"); err != nil {
+		t.Fatal(err)
+	}
+	revision(1)
+	if err := svc.AddDashboardCell(ctx, d.ID, &influxdb.Cell{}, influxdb.AddDashboardCellOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	revision(2)
+
+	// Updates expecting other revisions fail and leave the dashboard as it is.
+	if err := rename(influxdb.NewContextWithExpectedRevisions(ctx, d.ID, 1), "dashboard3"); influxdb.ErrorCode(err) != influxdb.EPreconditionFailed {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EPreconditionFailed)
+	}
+	if err := svc.DeleteDashboard(influxdb.NewContextWithExpectedRevisions(ctx, d.ID), d.ID); influxdb.ErrorCode(err) != influxdb.EPreconditionFailed {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EPreconditionFailed)
+	}
+	if got, err := svc.FindDashboardByID(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	} else if got.Name != "dashboard2" {
+		t.Fatalf("got dashboard named %q, expected dashboard2", got.Name)
+	}
+	revision(2)
+
+	// The revisions expected of other resources are ignored.
+	if err := rename(influxdb.NewContextWithExpectedRevisions(ctx, d.ID+1, 7), "dashboard3"); err != nil {
+		t.Fatal(err)
+	}
+	revision(3)
+	if err := rename(influxdb.NewContextWithExpectedRevisions(ctx, d.ID, 2, 3), "dashboard4"); err != nil {
+		t.Fatal(err)
+	}
+	revision(4)
+
+	// The revision of a dashboard is deleted with it.
+	if err := svc.DeleteDashboard(influxdb.NewContextWithExpectedRevisions(ctx, d.ID, 4), d.ID); err != nil {
+		t.Fatal(err)
+	}
+	revision(0)
+}
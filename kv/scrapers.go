@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/influxdata/influxdb"
 )
@@ -35,6 +36,12 @@ var (
 		Code: influxdb.EInvalid,
 		Msg:  "provided organization ID has invalid format",
 	}
+
+	// ErrScraperStatusNotFound is used when a scraper target has no recorded status.
+	ErrScraperStatusNotFound = &influxdb.Error{
+		Msg:  "scraper target status is not found",
+		Code: influxdb.ENotFound,
+	}
 )
 
 // UnexpectedScrapersBucketError is used when the error comes from an internal system.
@@ -76,13 +83,18 @@ func InternalScraperServiceError(err error) *influxdb.Error {
 }
 
 var (
-	scrapersBucket = []byte("scraperv2")
+	scrapersBucket      = []byte("scraperv2")
+	scraperStatusBucket = []byte("scraperstatusv1")
 )
 
 var _ influxdb.ScraperTargetStoreService = (*Service)(nil)
+var _ influxdb.ScraperTargetStatusService = (*Service)(nil)
 
 func (s *Service) initializeScraperTargets(ctx context.Context, tx Tx) error {
-	_, err := s.scrapersBucket(tx)
+	if _, err := s.scrapersBucket(tx); err != nil {
+		return err
+	}
+	_, err := s.scraperStatusBucket(tx)
 	return err
 }
 
@@ -95,6 +107,15 @@ func (s *Service) scrapersBucket(tx Tx) (Bucket, error) {
 	return b, nil
 }
 
+func (s *Service) scraperStatusBucket(tx Tx) (Bucket, error) {
+	b, err := tx.Bucket(scraperStatusBucket)
+	if err != nil {
+		return nil, UnexpectedScrapersBucketError(err)
+	}
+
+	return b, nil
+}
+
 // ListTargets will list all scrape targets.
 func (s *Service) ListTargets(ctx context.Context, filter influxdb.ScraperTargetFilter) ([]influxdb.ScraperTarget, error) {
 	targets := []influxdb.ScraperTarget{}
@@ -172,6 +193,10 @@ func (s *Service) addTarget(ctx context.Context, tx Tx, target *influxdb.Scraper
 		return ErrInvalidScrapersBucketID
 	}
 
+	if err := target.Valid(); err != nil {
+		return err
+	}
+
 	target.ID = s.IDGenerator.ID()
 	if err := s.putTarget(ctx, tx, target); err != nil {
 		return err
@@ -220,6 +245,14 @@ func (s *Service) removeTarget(ctx context.Context, tx Tx, id influxdb.ID) error
 		return InternalScraperServiceError(err)
 	}
 
+	statusBucket, err := s.scraperStatusBucket(tx)
+	if err != nil {
+		return err
+	}
+	if err := statusBucket.Delete(encID); err != nil && !IsNotFound(err) {
+		return InternalScraperServiceError(err)
+	}
+
 	return s.deleteUserResourceMappings(ctx, tx, influxdb.UserResourceMappingFilter{
 		ResourceID:   id,
 		ResourceType: influxdb.ScraperResourceType,
@@ -255,6 +288,9 @@ func (s *Service) updateTarget(ctx context.Context, tx Tx, update *influxdb.Scra
 	if !update.OrgID.Valid() {
 		update.OrgID = target.OrgID
 	}
+	if err := update.Valid(); err != nil {
+		return nil, err
+	}
 	target = update
 	return target, s.putTarget(ctx, tx, target)
 }
@@ -329,6 +365,8 @@ func (s *Service) putTarget(ctx context.Context, tx Tx, target *influxdb.Scraper
 }
 
 // unmarshalScraper turns the stored byte slice in the kv into a *influxdb.ScraperTarget.
+// Targets stored with their interval and timeout in nanoseconds, rather than
+// as duration strings, are decoded as well.
 func unmarshalScraper(v []byte) (*influxdb.ScraperTarget, error) {
 	s := &influxdb.ScraperTarget{}
 	if err := json.Unmarshal(v, s); err != nil {
@@ -344,3 +382,99 @@ func marshalScraper(sc *influxdb.ScraperTarget) ([]byte, error) {
 	}
 	return v, nil
 }
+
+// FindTargetStatus retrieves the status of the most recent scrapes of a target.
+func (s *Service) FindTargetStatus(ctx context.Context, id influxdb.ID) (*influxdb.ScraperTargetStatus, error) {
+	var status *influxdb.ScraperTargetStatus
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		status, err = s.findTargetStatus(ctx, tx, id)
+		return err
+	})
+
+	return status, err
+}
+
+func (s *Service) findTargetStatus(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.ScraperTargetStatus, error) {
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidScraperID
+	}
+
+	bucket, err := s.scraperStatusBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := bucket.Get(encID)
+	if IsNotFound(err) {
+		return nil, ErrScraperStatusNotFound
+	}
+	if err != nil {
+		return nil, InternalScraperServiceError(err)
+	}
+
+	status := &influxdb.ScraperTargetStatus{}
+	if err := json.Unmarshal(v, status); err != nil {
+		return nil, CorruptScraperError(err)
+	}
+	return status, nil
+}
+
+// RecordTargetScrape updates the status of a target with the outcome of a scrape.
+func (s *Service) RecordTargetScrape(ctx context.Context, id influxdb.ID, start time.Time, dur time.Duration, scrapeErr error) (*influxdb.ScraperTargetStatus, error) {
+	var status *influxdb.ScraperTargetStatus
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		status, err = s.recordTargetScrape(ctx, tx, id, start, dur, scrapeErr)
+		return err
+	})
+
+	return status, err
+}
+
+func (s *Service) recordTargetScrape(ctx context.Context, tx Tx, id influxdb.ID, start time.Time, dur time.Duration, scrapeErr error) (*influxdb.ScraperTargetStatus, error) {
+	// Targets removed while being scraped do not get a status.
+	if _, err := s.findTargetByID(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	status, err := s.findTargetStatus(ctx, tx, id)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		status, err = &influxdb.ScraperTargetStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status.LastScrape = start
+	status.LastDuration = influxdb.Duration{Duration: dur}
+	if scrapeErr != nil {
+		status.LastError = scrapeErr.Error()
+		status.ConsecutiveFailures++
+	} else {
+		status.LastSuccess = start
+		status.LastError = ""
+		status.ConsecutiveFailures = 0
+	}
+
+	v, err := json.Marshal(status)
+	if err != nil {
+		return nil, ErrUnprocessableScraper(err)
+	}
+
+	encID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidScraperID
+	}
+
+	bucket, err := s.scraperStatusBucket(tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := bucket.Put(encID, v); err != nil {
+		return nil, UnexpectedScrapersBucketError(err)
+	}
+
+	return status, nil
+}
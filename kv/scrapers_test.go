@@ -1,8 +1,12 @@
 package kv_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kv"
@@ -67,3 +71,114 @@ func initScraperTargetStoreService(s kv.Store, f influxdbtesting.TargetFields, t
 		}
 	}
 }
+
+func TestService_RecordTargetScrape(t *testing.T) {
+	s, closeFn, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeFn()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing service: %v", err)
+	}
+
+	target := &influxdb.ScraperTarget{
+		ID:       influxdb.ID(1),
+		Name:     "target",
+		Type:     influxdb.PrometheusScraperType,
+		URL:      "http://localhost:9090/metrics",
+		OrgID:    influxdb.ID(2),
+		BucketID: influxdb.ID(3),
+	}
+	if err := svc.PutTarget(ctx, target); err != nil {
+		t.Fatalf("failed to populate target: %v", err)
+	}
+
+	if _, err := svc.FindTargetStatus(ctx, target.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	start := time.Unix(100, 0).UTC()
+	if _, err := svc.RecordTargetScrape(ctx, target.ID, start, time.Second, nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 2; i++ {
+		status, err := svc.RecordTargetScrape(ctx, target.ID, start.Add(time.Duration(i)*time.Minute), time.Second, errors.New("connection refused"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.ConsecutiveFailures != i {
+			t.Fatalf("unexpected consecutive failures: got %d, want %d", status.ConsecutiveFailures, i)
+		}
+	}
+
+	status, err := svc.FindTargetStatus(ctx, target.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(2 * time.Minute); !status.LastScrape.Equal(want) {
+		t.Errorf("unexpected last scrape: got %v, want %v", status.LastScrape, want)
+	}
+	if !status.LastSuccess.Equal(start) {
+		t.Errorf("unexpected last success: got %v, want %v", status.LastSuccess, start)
+	}
+	if status.LastError != "connection refused" {
+		t.Errorf("unexpected last error: got %q", status.LastError)
+	}
+
+	if _, err := svc.RecordTargetScrape(ctx, influxdb.ID(99), start, time.Second, nil); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected not found error for unknown target, got %v", err)
+	}
+
+	if err := svc.RemoveTarget(ctx, target.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindTargetStatus(ctx, target.ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Errorf("expected status to be removed with target, got %v", err)
+	}
+}
+
+func TestService_ScraperTargetDurations(t *testing.T) {
+	s, closeFn, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeFn()
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing service: %v", err)
+	}
+
+	// Targets used to be stored with their durations in nanoseconds.
+	err = s.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("scraperv2"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("0000000000000001"), []byte(`{"id":"0000000000000001","name":"target","type":"prometheus","url":"http://localhost:9090/metrics","interval":30000000000,"timeout":10000000000}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := svc.GetTargetByID(ctx, influxdb.ID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.Interval.Value() != 30*time.Second || target.Timeout.Value() != 10*time.Second {
+		t.Fatalf("unexpected durations: interval %v, timeout %v", target.Interval, target.Timeout)
+	}
+
+	b, err := json.Marshal(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte(`"interval":"30s","timeout":"10s"`)) {
+		t.Fatalf("expected durations encoded as strings, got %s", b)
+	}
+}
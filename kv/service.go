@@ -50,6 +50,12 @@ type Service struct {
 	checkStore    *IndexStore
 	endpointStore *IndexStore
 	variableStore *IndexStore
+
+	measurementSchemaStore *IndexStore
+
+	// Migrator applies the migrations of the store when the service is
+	// initialized.
+	Migrator *Migrator
 }
 
 // NewService returns an instance of a Service.
@@ -68,6 +74,8 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		endpointStore:  newEndpointStore(),
 		variableStore:  newVariableStore(),
 		indexer:        NewIndexer(log, kv),
+
+		measurementSchemaStore: newMeasurementSchemaStore(),
 	}
 
 	if len(configs) > 0 {
@@ -80,107 +88,180 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 	if s.clock == nil {
 		s.clock = clock.New()
 	}
+	if s.Config.IDGenerator != nil {
+		s.IDGenerator = s.Config.IDGenerator
+	}
+	if s.Config.TimeGenerator != nil {
+		s.TimeGenerator = s.Config.TimeGenerator
+	}
+
+	s.Migrator = NewMigrator(log, s.migrations()...)
 
 	return s
 }
 
+// migrations returns the migrations of the store, in the order they are
+// applied. Changes to the buckets or the encoding of the data of the store are
+// made by adding a migration; released migrations must not be changed.
+func (s *Service) migrations() []Migration {
+	return []Migration{
+		{
+			// The buckets of every resource. Stores created before
+			// migrations were recorded already have them.
+			Name: "create initial buckets",
+			Up:   s.createInitialBuckets,
+		},
+		IndexMigration("index buckets by organization", bucketOrgIndex),
+		IndexMigration("index user resource mappings by user", urmUserIndex),
+		IndexMigration("index authorizations by user", authUserIndex),
+		IndexMigration("index authorizations by organization", authOrgIndex),
+		{
+			Name: "create organization quotas bucket",
+			Up:   s.initializeOrgQuotas,
+		},
+		{
+			Name: "create dbrp mappings bucket",
+			Up:   s.initializeDBRPMappings,
+		},
+		{
+			Name: "create revisions bucket",
+			Up:   s.initializeRevisions,
+		},
+		{
+			Name: "create materialized views bucket",
+			Up:   s.initializeMaterializedViews,
+		},
+		{
+			Name: "create ldap buckets",
+			Up:   s.initializeLDAP,
+		},
+		{
+			Name: "create audit log bucket",
+			Up:   s.initializeAuditLog,
+		},
+		{
+			Name: "create organization network policies bucket",
+			Up:   s.initializeNetworkPolicies,
+		},
+	}
+}
+
 // ServiceConfig allows us to configure Services
 type ServiceConfig struct {
 	SessionLength time.Duration
-	Clock         clock.Clock
+	// SessionMaxLength is how long sessions may be renewed for after they
+	// are created. Sessions may be renewed indefinitely if it is zero.
+	SessionMaxLength time.Duration
+	Clock            clock.Clock
+
+	// IDGenerator and TimeGenerator replace the generators of the IDs and
+	// the creation and update times of resources when set, for instance
+	// to build reproducible states in tests.
+	IDGenerator   influxdb.IDGenerator
+	TimeGenerator influxdb.TimeGenerator
+
+	// SystemBuckets holds the settings of the system buckets created with
+	// each organization.
+	SystemBuckets SystemBucketsConfig
 }
 
-// Initialize creates Buckets needed.
+// Initialize applies the migrations of the store that have not been applied.
 func (s *Service) Initialize(ctx context.Context) error {
-	return s.kv.Update(ctx, func(tx Tx) error {
-		if err := s.initializeAuths(ctx, tx); err != nil {
-			return err
-		}
+	return s.Migrator.Up(ctx, s.kv)
+}
 
-		if err := s.initializeDocuments(ctx, tx); err != nil {
-			return err
-		}
+// createInitialBuckets creates the buckets of every resource.
+func (s *Service) createInitialBuckets(ctx context.Context, tx Tx) error {
+	if err := s.initializeAuths(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeBuckets(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeDocuments(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeDashboards(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeBuckets(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeKVLog(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeDashboards(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeLabels(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeKVLog(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeOnboarding(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeLabels(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeOrgs(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeOnboarding(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeTasks(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeOrgs(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := s.initializeTasks(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializePasswords(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializePasswords(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeScraperTargets(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeScraperTargets(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeSecrets(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeSecrets(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeSessions(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeSessions(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeSources(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeSources(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeTelegraf(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeTelegraf(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeURMs(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeURMs(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.variableStore.Init(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.variableStore.Init(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.initializeVariablesOrgIndex(tx); err != nil {
-			return err
-		}
+	if err := s.initializeVariablesOrgIndex(tx); err != nil {
+		return err
+	}
 
-		if err := s.checkStore.Init(ctx, tx); err != nil {
-			return err
+	if err := s.checkStore.Init(ctx, tx); err != nil {
+		return err
 
-		}
+	}
 
-		if err := s.initializeNotificationRule(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.initializeNotificationRule(ctx, tx); err != nil {
+		return err
+	}
 
-		if err := s.endpointStore.Init(ctx, tx); err != nil {
-			return err
-		}
+	if err := s.endpointStore.Init(ctx, tx); err != nil {
+		return err
+	}
 
-		return s.initializeUsers(ctx, tx)
-	})
+	if err := s.measurementSchemaStore.Init(ctx, tx); err != nil {
+		return err
+	}
 
+	return s.initializeUsers(ctx, tx)
 }
 
 func (s *Service) Stop() {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
 	"github.com/influxdata/influxdb/kv"
 	"go.uber.org/zap/zaptest"
 )
@@ -26,6 +27,10 @@ func (s mockStore) Backup(ctx context.Context, w io.Writer) error {
 	return nil
 }
 
+func (s mockStore) Watch(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error) {
+	return nil, nil
+}
+
 func TestNewService(t *testing.T) {
 	s := kv.NewService(zaptest.NewLogger(t), mockStore{})
 
@@ -43,3 +48,35 @@ func TestNewService(t *testing.T) {
 		t.Errorf("Service config not set by constructor")
 	}
 }
+
+func TestNewService_Generators(t *testing.T) {
+	start := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	s := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore(), kv.ServiceConfig{
+		SessionLength: influxdb.DefaultSessionLength,
+		IDGenerator:   influxdb.NewSequentialIDGenerator(0x1000),
+		TimeGenerator: influxdb.NewStepTimeGenerator(start, 0),
+	})
+	ctx := context.Background()
+	if err := s.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	u := &influxdb.User{Name: "user"}
+	if err := s.CreateUser(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != 0x1000 {
+		t.Errorf("unexpected user ID; got=%s, exp=%s", u.ID, influxdb.ID(0x1000))
+	}
+
+	sn, err := s.CreateSession(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sn.ID != 0x1001 {
+		t.Errorf("unexpected session ID; got=%s, exp=%s", sn.ID, influxdb.ID(0x1001))
+	}
+	if !sn.CreatedAt.Equal(start) {
+		t.Errorf("unexpected session creation time; got=%v, exp=%v", sn.CreatedAt, start)
+	}
+}
@@ -3,6 +3,7 @@ package kv
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"time"
 
 	"github.com/influxdata/influxdb"
@@ -12,7 +13,10 @@ var (
 	sessionBucket = []byte("sessionsv1")
 )
 
-var _ influxdb.SessionService = (*Service)(nil)
+var (
+	_ influxdb.SessionService           = (*Service)(nil)
+	_ influxdb.SessionManagementService = (*Service)(nil)
+)
 
 func (s *Service) initializeSessions(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket([]byte(sessionBucket)); err != nil {
@@ -21,7 +25,8 @@ func (s *Service) initializeSessions(ctx context.Context, tx Tx) error {
 	return nil
 }
 
-// RenewSession extends the expire time to newExpiration.
+// RenewSession extends the expire time to newExpiration, or to the maximum
+// length of the session if it is sooner.
 func (s *Service) RenewSession(ctx context.Context, session *influxdb.Session, newExpiration time.Time) error {
 	if session == nil {
 		return &influxdb.Error{
@@ -29,7 +34,7 @@ func (s *Service) RenewSession(ctx context.Context, session *influxdb.Session, n
 		}
 	}
 	return s.kv.Update(ctx, func(tx Tx) error {
-		session.ExpiresAt = newExpiration
+		session.ExpiresAt = s.sessionExpiration(session, newExpiration)
 		if err := s.putSession(ctx, tx, session); err != nil {
 			return &influxdb.Error{
 				Err: err,
@@ -177,7 +182,7 @@ func (s *Service) ExpireSession(ctx context.Context, key string) error {
 			return err
 		}
 
-		sn.ExpiresAt = time.Now()
+		sn.ExpiresAt = s.Now()
 
 		if err := s.putSession(ctx, tx, sn); err != nil {
 			return err
@@ -223,8 +228,8 @@ func (s *Service) createSession(ctx context.Context, tx Tx, user string) (*influ
 	}
 	sn.Key = k
 	sn.UserID = u.ID
-	sn.CreatedAt = time.Now()
-	sn.ExpiresAt = sn.CreatedAt.Add(s.Config.SessionLength)
+	sn.CreatedAt = s.Now()
+	sn.ExpiresAt = s.sessionExpiration(sn, sn.CreatedAt.Add(s.Config.SessionLength))
 	// TODO(desa): not totally sure what to do here. Possibly we should have a maximal privilege permission.
 	sn.Permissions = []influxdb.Permission{}
 
@@ -234,3 +239,183 @@ func (s *Service) createSession(ctx context.Context, tx Tx, user string) (*influ
 
 	return sn, nil
 }
+
+// sessionExpiration returns expiresAt, or the end of the maximum length of sn
+// if it is sooner.
+func (s *Service) sessionExpiration(sn *influxdb.Session, expiresAt time.Time) time.Time {
+	if s.Config.SessionMaxLength <= 0 {
+		return expiresAt
+	}
+	if max := sn.CreatedAt.Add(s.Config.SessionMaxLength); max.Before(expiresAt) {
+		return max
+	}
+	return expiresAt
+}
+
+// FindSessions returns the unexpired sessions that match filter, oldest first.
+func (s *Service) FindSessions(ctx context.Context, filter influxdb.SessionFilter, opt ...influxdb.FindOptions) ([]*influxdb.Session, int, error) {
+	var ss []*influxdb.Session
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		ss, err = s.findSessions(ctx, tx, filter)
+		return err
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Op:  influxdb.OpFindSessions,
+			Err: err,
+		}
+	}
+
+	n := len(ss)
+	if len(opt) > 0 {
+		o := opt[0]
+		if o.Descending {
+			for i, j := 0, len(ss)-1; i < j; i, j = i+1, j-1 {
+				ss[i], ss[j] = ss[j], ss[i]
+			}
+		}
+		if o.Offset > 0 {
+			if o.Offset >= len(ss) {
+				ss = ss[:0]
+			} else {
+				ss = ss[o.Offset:]
+			}
+		}
+		if o.Limit > 0 && o.Limit < len(ss) {
+			ss = ss[:o.Limit]
+		}
+	}
+	return ss, n, nil
+}
+
+// findSessions returns the unexpired sessions that match filter, oldest
+// first, without their keys and permissions.
+func (s *Service) findSessions(ctx context.Context, tx Tx, filter influxdb.SessionFilter) ([]*influxdb.Session, error) {
+	b, err := tx.Bucket(sessionBucket)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	now := s.Now()
+	ss := []*influxdb.Session{}
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		sn := &influxdb.Session{}
+		if err := json.Unmarshal(v, sn); err != nil {
+			return nil, err
+		}
+		if !now.Before(sn.ExpiresAt) {
+			continue
+		}
+		if filter.ID != nil && sn.ID != *filter.ID {
+			continue
+		}
+		if filter.UserID != nil && sn.UserID != *filter.UserID {
+			continue
+		}
+		sn.Key = ""
+		sn.Permissions = nil
+		ss = append(ss, sn)
+	}
+	if err := cur.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ss, func(i, j int) bool {
+		if !ss[i].CreatedAt.Equal(ss[j].CreatedAt) {
+			return ss[i].CreatedAt.Before(ss[j].CreatedAt)
+		}
+		return ss[i].ID < ss[j].ID
+	})
+	return ss, nil
+}
+
+// RevokeSession removes an unexpired session.
+func (s *Service) RevokeSession(ctx context.Context, id influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		n, err := s.revokeSessions(ctx, tx, influxdb.SessionFilter{ID: &id})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return &influxdb.Error{
+				Code: influxdb.ENotFound,
+				Msg:  influxdb.ErrSessionNotFound,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRevokeSession,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// RevokeSessions removes all of the sessions of a user.
+func (s *Service) RevokeSessions(ctx context.Context, userID influxdb.ID) error {
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		_, err := s.revokeSessions(ctx, tx, influxdb.SessionFilter{UserID: &userID})
+		return err
+	})
+	if err != nil {
+		return &influxdb.Error{
+			Op:  influxdb.OpRevokeSessions,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// revokeSessions removes the sessions that match filter, expired or not, and
+// returns the number of unexpired sessions removed.
+func (s *Service) revokeSessions(ctx context.Context, tx Tx, filter influxdb.SessionFilter) (int, error) {
+	b, err := tx.Bucket(sessionBucket)
+	if err != nil {
+		return 0, err
+	}
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.Now()
+	var keys [][]byte
+	var n int
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		sn := &influxdb.Session{}
+		if err := json.Unmarshal(v, sn); err != nil {
+			cur.Close()
+			return 0, err
+		}
+		if filter.ID != nil && sn.ID != *filter.ID {
+			continue
+		}
+		if filter.UserID != nil && sn.UserID != *filter.UserID {
+			continue
+		}
+		keys = append(keys, append([]byte(nil), k...))
+		if now.Before(sn.ExpiresAt) {
+			n++
+		}
+	}
+	err = cur.Err()
+	cur.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
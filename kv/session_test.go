@@ -3,6 +3,7 @@ package kv_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kv"
@@ -55,3 +56,77 @@ func initSessionService(s kv.Store, f influxdbtesting.SessionFields, t *testing.
 		}
 	}
 }
+
+func TestService_SessionManagement(t *testing.T) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s, kv.ServiceConfig{
+		SessionLength:    time.Hour,
+		SessionMaxLength: 2 * time.Hour,
+	})
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing session service: %v", err)
+	}
+
+	var users []*influxdb.User
+	for _, name := range []string{"alice", "bob"} {
+		u := &influxdb.User{Name: name}
+		if err := svc.CreateUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		users = append(users, u)
+	}
+	var sessions []*influxdb.Session
+	for _, u := range []*influxdb.User{users[0], users[0], users[1]} {
+		sn, err := svc.CreateSession(ctx, u.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions = append(sessions, sn)
+	}
+
+	// Sessions are not renewed past their maximum length.
+	if err := svc.RenewSession(ctx, sessions[0], sessions[0].CreatedAt.Add(3*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sessions[0].ExpiresAt, sessions[0].CreatedAt.Add(2*time.Hour); !got.Equal(want) {
+		t.Errorf("got session expiring at %v, want %v", got, want)
+	}
+
+	ss, n, err := svc.FindSessions(ctx, influxdb.SessionFilter{UserID: &users[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(ss) != 2 || ss[0].ID != sessions[0].ID || ss[1].ID != sessions[1].ID {
+		t.Fatalf("got sessions %+v, want the two sessions of alice", ss)
+	}
+	if ss[0].Key != "" {
+		t.Error("expected sessions to be listed without their key")
+	}
+
+	if err := svc.RevokeSession(ctx, sessions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.FindSession(ctx, sessions[0].Key); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected revoked session to be gone, got %v", err)
+	}
+	if err := svc.RevokeSession(ctx, sessions[0].ID); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected revoking a revoked session to fail, got %v", err)
+	}
+
+	if err := svc.RevokeSessions(ctx, users[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	ss, n, err = svc.FindSessions(ctx, influxdb.SessionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || ss[0].ID != sessions[2].ID {
+		t.Fatalf("got sessions %+v, want the session of bob", ss)
+	}
+}
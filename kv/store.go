@@ -32,6 +32,10 @@ type Store interface {
 	Update(context.Context, func(Tx) error) error
 	// Backup copies all K:Vs to a writer, file format determined by implementation.
 	Backup(ctx context.Context, w io.Writer) error
+	// Watch returns a channel of the changes committed to the keys of bucket
+	// with prefix, in the order they are committed. The channel is closed when
+	// the context is done.
+	Watch(ctx context.Context, bucket, prefix []byte) (<-chan Event, error)
 }
 
 // Tx is a transaction in the store.
@@ -1,6 +1,7 @@
 package kv
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -197,9 +198,12 @@ type (
 	// will count it towards the number of entries seen and the capture func will be
 	// run with it provided to it.
 	FindOpts struct {
-		Descending  bool
-		Offset      int
-		Limit       int
+		Descending bool
+		Offset     int
+		Limit      int
+		// After is the key of the entry the previous page of results ended on.
+		// When set, entries start after it, and Offset is ignored.
+		After       []byte
 		Prefix      []byte
 		CaptureFn   FindCaptureFn
 		FilterEntFn FilterFn
@@ -237,10 +241,14 @@ func (s *StoreBase) Find(ctx context.Context, tx Tx, opts FindOpts) error {
 		descending: opts.Descending,
 		limit:      opts.Limit,
 		offset:     opts.Offset,
+		after:      opts.After,
 		prefix:     opts.Prefix,
 		decodeFn:   s.DecodeEntFn,
 		filterFn:   opts.FilterEntFn,
 	}
+	if len(opts.After) > 0 {
+		iter.offset = 0
+	}
 
 	for k, v, err := iter.Next(ctx); k != nil; k, v, err = iter.Next(ctx) {
 		if err != nil {
@@ -502,6 +510,7 @@ type iterator struct {
 	descending bool
 	limit      int
 	offset     int
+	after      []byte
 	prefix     []byte
 
 	nextFn func() (key, val []byte)
@@ -554,6 +563,15 @@ func (i *iterator) isNext(k []byte, v interface{}) bool {
 		return false
 	}
 
+	if len(i.after) > 0 {
+		c := bytes.Compare(k, i.after)
+		if i.descending && c >= 0 || !i.descending && c <= 0 {
+			return false
+		}
+		i.counter++
+		return true
+	}
+
 	// increase counter here since the entity is a valid ent
 	// and counts towards the total the user is looking for
 	// 	i.e. limit = 5 => 5 valid ents
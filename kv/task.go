@@ -51,6 +51,8 @@ type kvTask struct {
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
 	LastRunError    string                 `json:"lastRunError,omitempty"`
 	Offset          influxdb.Duration      `json:"offset,omitempty"`
+	Retry           int64                  `json:"retry,omitempty"`
+	RetryBackoff    influxdb.Duration      `json:"retryBackoff,omitempty"`
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
@@ -74,6 +76,8 @@ func kvToInfluxTask(k *kvTask) *influxdb.Task {
 		LastRunStatus:   k.LastRunStatus,
 		LastRunError:    k.LastRunError,
 		Offset:          k.Offset.Duration,
+		Retry:           k.Retry,
+		RetryBackoff:    k.RetryBackoff.Duration,
 		LatestCompleted: k.LatestCompleted,
 		LatestScheduled: k.LatestScheduled,
 		CreatedAt:       k.CreatedAt,
@@ -631,6 +635,69 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 
 	}
 
+	if err := setTaskRetry(task, opt); err != nil {
+		return nil, err
+	}
+
+	taskBytes, err := s.putTask(ctx, tx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.createTaskURM(ctx, tx, task); err != nil {
+		s.log.Info("Error creating user resource mapping for task", zap.Stringer("taskID", task.ID), zap.Error(err))
+	}
+
+	// populate permissions so the task can be used immediately
+	// if we cant populate here we shouldn't error.
+	ps, _ := s.maxPermissions(ctx, tx, task.OwnerID)
+	task.Authorization = &influxdb.Authorization{
+		Status:      influxdb.Active,
+		ID:          influxdb.ID(1),
+		OrgID:       task.OrganizationID,
+		Permissions: ps,
+	}
+
+	uid, _ := icontext.GetUserID(ctx)
+	if err := s.audit.Log(resource.Change{
+		Type:           resource.Create,
+		ResourceID:     task.ID,
+		ResourceType:   influxdb.TasksResourceType,
+		OrganizationID: task.OrganizationID,
+		UserID:         uid,
+		ResourceBody:   taskBytes,
+		Time:           s.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// setTaskRetry sets how many times runs of task are attempted, and how long
+// to wait before retrying them, from the options of its script. Runs of
+// tasks that are attempted only once leave both unset.
+func setTaskRetry(task *influxdb.Task, opt options.Options) error {
+	task.Retry = 0
+	if opt.Retry != nil && *opt.Retry > 1 {
+		task.Retry = *opt.Retry
+	}
+
+	var backoff time.Duration
+	if opt.RetryBackoff != nil {
+		var err error
+		backoff, err = time.ParseDuration(opt.RetryBackoff.String())
+		if err != nil {
+			return influxdb.ErrTaskTimeParse(err)
+		}
+	}
+	task.RetryBackoff = backoff
+	return nil
+}
+
+// putTask writes the task and its entry in the index of the tasks of its
+// organization, returning the encoded task.
+func (s *Service) putTask(ctx context.Context, tx Tx, task *influxdb.Task) ([]byte, error) {
 	taskBucket, err := tx.Bucket(taskBucket)
 	if err != nil {
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
@@ -668,34 +735,7 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
-	if err := s.createTaskURM(ctx, tx, task); err != nil {
-		s.log.Info("Error creating user resource mapping for task", zap.Stringer("taskID", task.ID), zap.Error(err))
-	}
-
-	// populate permissions so the task can be used immediately
-	// if we cant populate here we shouldn't error.
-	ps, _ := s.maxPermissions(ctx, tx, task.OwnerID)
-	task.Authorization = &influxdb.Authorization{
-		Status:      influxdb.Active,
-		ID:          influxdb.ID(1),
-		OrgID:       task.OrganizationID,
-		Permissions: ps,
-	}
-
-	uid, _ := icontext.GetUserID(ctx)
-	if err := s.audit.Log(resource.Change{
-		Type:           resource.Create,
-		ResourceID:     task.ID,
-		ResourceType:   influxdb.TasksResourceType,
-		OrganizationID: task.OrganizationID,
-		UserID:         uid,
-		ResourceBody:   taskBytes,
-		Time:           time.Now(),
-	}); err != nil {
-		return nil, err
-	}
-
-	return task, nil
+	return taskBytes, nil
 }
 
 func (s *Service) createTaskURM(ctx context.Context, tx Tx, t *influxdb.Task) error {
@@ -764,6 +804,9 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 			}
 		}
 		task.Offset = off
+		if err := setTaskRetry(task, options); err != nil {
+			return nil, err
+		}
 		task.UpdatedAt = updatedAt
 	}
 
@@ -815,6 +858,14 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		}
 	}
 
+	// the revision only changes with the definition of the task, not with the
+	// state of its runs
+	if task.UpdatedAt.Equal(updatedAt) || influxdb.HasExpectedRevisions(ctx, id) {
+		if err := s.updateRevision(ctx, tx, id); err != nil {
+			return nil, err
+		}
+	}
+
 	// save the updated task
 	bucket, err := tx.Bucket(taskBucket)
 	if err != nil {
@@ -843,7 +894,7 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		OrganizationID: task.OrganizationID,
 		UserID:         uid,
 		ResourceBody:   taskBytes,
-		Time:           time.Now(),
+		Time:           s.Now(),
 	}); err != nil {
 		return nil, err
 	}
@@ -889,6 +940,10 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		return err
 	}
 
+	if err := s.deleteRevision(ctx, tx, id); err != nil {
+		return err
+	}
+
 	// remove the orgs index
 	orgKey, err := taskOrgKey(task.OrganizationID, task.ID)
 	if err != nil {
@@ -948,7 +1003,7 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		ResourceType:   influxdb.TasksResourceType,
 		OrganizationID: task.OrganizationID,
 		UserID:         uid,
-		Time:           time.Now(),
+		Time:           s.Now(),
 	})
 }
 
@@ -1232,7 +1287,7 @@ func (s *Service) forceRun(ctx context.Context, tx Tx, taskID influxdb.ID, sched
 		ID:           s.IDGenerator.ID(),
 		TaskID:       taskID,
 		Status:       backend.RunScheduled.String(),
-		RequestedAt:  time.Now().UTC(),
+		RequestedAt:  s.clock.Now().UTC(),
 		ScheduledFor: t,
 		Log:          []influxdb.Log{},
 	}
@@ -239,6 +239,62 @@ func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	}
 }
 
+func TestService_UpdateTask_Revision(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	c := clock.NewMock()
+	c.Set(time.Unix(1000, 0))
+
+	ts := newService(t, ctx, c)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Status:         string(backend.TaskActive),
+	})
+	if err != nil {
+		t.Fatal("CreateTask", err)
+	}
+
+	revision := func(want uint64) {
+		t.Helper()
+		if rev, err := ts.Service.FindRevision(ctx, task.ID); err != nil {
+			t.Fatal("FindRevision", err)
+		} else if rev != want {
+			t.Fatalf("got revision %d, expected %d", rev, want)
+		}
+	}
+
+	// Updates of the state of the runs of a task leave its revision as it is.
+	c.Add(time.Second)
+	now := c.Now()
+	if _, err := ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{LatestCompleted: &now, LatestScheduled: &now}); err != nil {
+		t.Fatal("UpdateTask", err)
+	}
+	revision(0)
+
+	c.Add(time.Second)
+	desc := "a description"
+	if _, err := ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Description: &desc}); err != nil {
+		t.Fatal("UpdateTask", err)
+	}
+	revision(1)
+
+	// Updates expecting a revision are checked even if they change nothing.
+	if _, err := ts.Service.UpdateTask(influxdb.NewContextWithExpectedRevisions(ctx, task.ID, 0), task.ID, influxdb.TaskUpdate{}); influxdb.ErrorCode(err) != influxdb.EPreconditionFailed {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EPreconditionFailed)
+	}
+	if _, err := ts.Service.UpdateTask(influxdb.NewContextWithExpectedRevisions(ctx, task.ID, 1), task.ID, influxdb.TaskUpdate{}); err != nil {
+		t.Fatal("UpdateTask", err)
+	}
+	revision(2)
+}
+
 func TestTaskRunCancellation(t *testing.T) {
 	store, close, err := NewTestBoltStore(t)
 	if err != nil {
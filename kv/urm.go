@@ -14,6 +14,14 @@ import (
 var (
 	urmBucket = []byte("userresourcemappingsv1")
 
+	// urmUserIndex indexes user resource mappings by the ID of their user, so
+	// that the resources owned by a user are found without a scan.
+	urmUserIndex = NewIndex(NewIndexMapping(urmBucket, []byte("userresourcemappingsbyuserindexv1"), foreignID(func(v []byte) (influxdb.ID, error) {
+		var m influxdb.UserResourceMapping
+		err := json.Unmarshal(v, &m)
+		return m.UserID, err
+	})))
+
 	// ErrInvalidURMID is used when the service was provided
 	// an invalid ID format.
 	ErrInvalidURMID = &influxdb.Error{
@@ -124,18 +132,41 @@ func userResourceMappingPredicate(filter influxdb.UserResourceMappingFilter) Cur
 
 func (s *Service) findUserResourceMappings(ctx context.Context, tx Tx, filter influxdb.UserResourceMappingFilter) ([]*influxdb.UserResourceMapping, error) {
 	ms := []*influxdb.UserResourceMapping{}
-	pred := userResourceMappingPredicate(filter)
 	filterFn := filterMappingsFn(filter)
-	err := s.forEachUserResourceMapping(ctx, tx, pred, func(m *influxdb.UserResourceMapping) bool {
+	fn := func(m *influxdb.UserResourceMapping) bool {
 		if filterFn(m) {
 			ms = append(ms, m)
 		}
 		return true
-	})
+	}
+
+	// Mappings are keyed by resource, so those of a user alone are found
+	// through the index of their users.
+	if filter.UserID.Valid() && !filter.ResourceID.Valid() {
+		return ms, s.forEachUserMapping(ctx, tx, filter.UserID, fn)
+	}
 
+	err := s.forEachUserResourceMapping(ctx, tx, userResourceMappingPredicate(filter), fn)
 	return ms, err
 }
 
+// forEachUserMapping will iterate through the mappings of a user while fn
+// returns true.
+func (s *Service) forEachUserMapping(ctx context.Context, tx Tx, userID influxdb.ID, fn func(*influxdb.UserResourceMapping) bool) error {
+	encodedID, err := userID.Encode()
+	if err != nil {
+		return ErrInvalidURMID
+	}
+
+	return urmUserIndex.Walk(ctx, tx, encodedID, false, func(k, v []byte) (bool, error) {
+		m := &influxdb.UserResourceMapping{}
+		if err := json.Unmarshal(v, m); err != nil {
+			return false, CorruptURMError(err)
+		}
+		return fn(m), nil
+	})
+}
+
 func (s *Service) findUserResourceMapping(ctx context.Context, tx Tx, filter influxdb.UserResourceMappingFilter) (*influxdb.UserResourceMapping, error) {
 	ms, err := s.findUserResourceMappings(ctx, tx, filter)
 	if err != nil {
@@ -165,6 +196,20 @@ func (s *Service) createUserResourceMapping(ctx context.Context, tx Tx, m *influ
 		return err
 	}
 
+	if err := s.putUserResourceMapping(ctx, tx, m); err != nil {
+		return err
+	}
+
+	if m.ResourceType == influxdb.OrgsResourceType {
+		return s.createOrgDependentMappings(ctx, tx, m)
+	}
+
+	return nil
+}
+
+// putUserResourceMapping writes m and its index entry, without the mappings
+// of the resources of an organization.
+func (s *Service) putUserResourceMapping(ctx context.Context, tx Tx, m *influxdb.UserResourceMapping) error {
 	v, err := json.Marshal(m)
 	if err != nil {
 		return ErrUnprocessableMapping(err)
@@ -184,11 +229,7 @@ func (s *Service) createUserResourceMapping(ctx context.Context, tx Tx, m *influ
 		return UnavailableURMServiceError(err)
 	}
 
-	if m.ResourceType == influxdb.OrgsResourceType {
-		return s.createOrgDependentMappings(ctx, tx, m)
-	}
-
-	return nil
+	return s.insertURMUserIndex(tx, m, key)
 }
 
 // This method creates the user/resource mappings for resources that belong to an organization.
@@ -343,7 +384,7 @@ func (s *Service) deleteUserResourceMapping(ctx context.Context, tx Tx, filter i
 	if err := b.Delete(key); err != nil {
 		return UnavailableURMServiceError(err)
 	}
-	return nil
+	return s.deleteURMUserIndex(tx, ms[0], key)
 }
 
 func (s *Service) deleteUserResourceMappings(ctx context.Context, tx Tx, filter influxdb.UserResourceMappingFilter) error {
@@ -373,10 +414,33 @@ func (s *Service) deleteUserResourceMappings(ctx context.Context, tx Tx, filter
 		if err := b.Delete(key); err != nil {
 			return UnavailableURMServiceError(err)
 		}
+		if err := s.deleteURMUserIndex(tx, m, key); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// insertURMUserIndex adds the mapping m stored at key to the index of the
+// mappings of its user.
+func (s *Service) insertURMUserIndex(tx Tx, m *influxdb.UserResourceMapping, key []byte) error {
+	userID, err := m.UserID.Encode()
+	if err != nil {
+		return ErrInvalidURMID
+	}
+	return urmUserIndex.Insert(tx, userID, key)
+}
+
+// deleteURMUserIndex removes the mapping m stored at key from the index of
+// the mappings of its user.
+func (s *Service) deleteURMUserIndex(tx Tx, m *influxdb.UserResourceMapping, key []byte) error {
+	userID, err := m.UserID.Encode()
+	if err != nil {
+		return ErrInvalidURMID
+	}
+	return urmUserIndex.Delete(tx, userID, key)
+}
+
 // This method deletes the user/resource mappings for resources that belong to an organization.
 func (s *Service) deleteOrgDependentMappings(ctx context.Context, tx Tx, m *influxdb.UserResourceMapping) error {
 	bf := influxdb.BucketFilter{OrganizationID: &m.ResourceID}
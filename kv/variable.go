@@ -123,13 +123,18 @@ func (s *Service) findVariables(ctx context.Context, tx Tx, filter influxdb.Vari
 	if len(opt) > 0 {
 		o = opt[0]
 	}
+	after, err := afterKey(o)
+	if err != nil {
+		return nil, err
+	}
 
 	// TODO(jsteenb2): investigate why we don't implement the find options for vars?
 	variables := make([]*influxdb.Variable, 0)
-	err := s.variableStore.Find(ctx, tx, FindOpts{
+	err = s.variableStore.Find(ctx, tx, FindOpts{
 		Descending:  o.Descending,
 		Limit:       o.Limit,
 		Offset:      o.Offset,
+		After:       after,
 		FilterEntFn: filterVariablesFn(filter),
 		CaptureFn: func(key []byte, decodedVal interface{}) error {
 			variables = append(variables, decodedVal.(*influxdb.Variable))
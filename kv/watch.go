@@ -0,0 +1,230 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// EventType is the type of a change to a key of a bucket.
+type EventType int
+
+const (
+	// EventCreate is the creation of a key.
+	EventCreate EventType = iota + 1
+	// EventUpdate is a change to the value of an existing key.
+	EventUpdate
+	// EventDelete is the deletion of a key.
+	EventDelete
+)
+
+// String returns the name of the event type.
+func (t EventType) String() string {
+	switch t {
+	case EventCreate:
+		return "create"
+	case EventUpdate:
+		return "update"
+	case EventDelete:
+		return "delete"
+	}
+	return "unknown"
+}
+
+// Event is a change to a key of a bucket committed to a store.
+type Event struct {
+	Type   EventType
+	Bucket []byte
+	Key    []byte
+	// Value is the value of the key after the change; it is nil for a delete.
+	Value []byte
+	// PrevValue is the value of the key before the change; it is nil for a
+	// create.
+	PrevValue []byte
+}
+
+// Watchers sends the events published by a store to the watchers of the keys
+// changed. The zero value is ready to use.
+//
+// Publish never blocks: the events of each watcher are queued until the
+// watcher receives them, so a slow watcher does not hold up the store.
+type Watchers struct {
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+}
+
+// Watch returns a channel of the events published for the keys of bucket with
+// prefix, in the order they are published. The channel is closed when ctx is
+// done.
+func (w *Watchers) Watch(ctx context.Context, bucket, prefix []byte) <-chan Event {
+	wt := &watcher{
+		bucket: append([]byte(nil), bucket...),
+		prefix: append([]byte(nil), prefix...),
+		notify: make(chan struct{}, 1),
+		events: make(chan Event),
+	}
+
+	w.mu.Lock()
+	if w.watchers == nil {
+		w.watchers = make(map[*watcher]struct{})
+	}
+	w.watchers[wt] = struct{}{}
+	w.mu.Unlock()
+
+	go func() {
+		wt.run(ctx)
+
+		w.mu.Lock()
+		delete(w.watchers, wt)
+		w.mu.Unlock()
+		close(wt.events)
+	}()
+
+	return wt.events
+}
+
+// Publish sends events to the watchers of their keys. Stores must publish the
+// events of a transaction once it is committed, in the order transactions are
+// committed.
+func (w *Watchers) Publish(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for wt := range w.watchers {
+		wt.push(events)
+	}
+}
+
+// watcher is the queue of events of a call to Watch.
+type watcher struct {
+	bucket []byte
+	prefix []byte
+
+	mu     sync.Mutex
+	queue  []Event
+	notify chan struct{}
+	events chan Event
+}
+
+// push queues the events the watcher is watching for.
+func (wt *watcher) push(events []Event) {
+	wt.mu.Lock()
+	n := len(wt.queue)
+	for _, e := range events {
+		if bytes.Equal(e.Bucket, wt.bucket) && bytes.HasPrefix(e.Key, wt.prefix) {
+			wt.queue = append(wt.queue, e)
+		}
+	}
+	queued := len(wt.queue) > n
+	wt.mu.Unlock()
+
+	if queued {
+		select {
+		case wt.notify <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// run sends the queued events to the watcher until ctx is done.
+func (wt *watcher) run(ctx context.Context) {
+	for {
+		wt.mu.Lock()
+		queue := wt.queue
+		wt.queue = nil
+		wt.mu.Unlock()
+
+		for _, e := range queue {
+			select {
+			case wt.events <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-wt.notify:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// EventRecorder records the changes made to the buckets of a writable
+// transaction as events, for stores to publish once the transaction is
+// committed.
+type EventRecorder struct {
+	Events []Event
+}
+
+// Bucket returns b, named name, recording the changes made through it.
+func (r *EventRecorder) Bucket(name []byte, b Bucket) Bucket {
+	return &recordedBucket{Bucket: b, name: name, r: r}
+}
+
+// recordedBucket is a Bucket which records its changes as events.
+type recordedBucket struct {
+	Bucket
+	name []byte
+	r    *EventRecorder
+}
+
+// Put sets the value of key, recording an EventCreate or EventUpdate.
+func (b *recordedBucket) Put(key, value []byte) error {
+	prev, err := b.Bucket.Get(key)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+	prev = copyBytes(prev)
+
+	if err := b.Bucket.Put(key, value); err != nil {
+		return err
+	}
+
+	typ := EventUpdate
+	if prev == nil {
+		typ = EventCreate
+	}
+	b.r.Events = append(b.r.Events, Event{
+		Type:      typ,
+		Bucket:    copyBytes(b.name),
+		Key:       copyBytes(key),
+		Value:     copyBytes(value),
+		PrevValue: prev,
+	})
+	return nil
+}
+
+// Delete deletes key, recording an EventDelete if it exists.
+func (b *recordedBucket) Delete(key []byte) error {
+	prev, err := b.Bucket.Get(key)
+	if IsNotFound(err) {
+		return b.Bucket.Delete(key)
+	} else if err != nil {
+		return err
+	}
+	prev = copyBytes(prev)
+
+	if err := b.Bucket.Delete(key); err != nil {
+		return err
+	}
+
+	b.r.Events = append(b.r.Events, Event{
+		Type:      EventDelete,
+		Bucket:    copyBytes(b.name),
+		Key:       copyBytes(key),
+		PrevValue: prev,
+	})
+	return nil
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,201 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ops for LDAP.
+var (
+	OpFindLDAPConfig       = "FindLDAPConfig"
+	OpPutLDAPConfig        = "PutLDAPConfig"
+	OpFindLDAPMemberships  = "FindLDAPMemberships"
+	OpPutLDAPMembership    = "PutLDAPMembership"
+	OpDeleteLDAPMembership = "DeleteLDAPMembership"
+	OpSyncLDAP             = "SyncLDAP"
+)
+
+// LDAPService manages the configuration of the LDAP directory users are
+// authenticated against and whose groups are synced into organizations, and
+// the memberships of organizations that the sync manages.
+type LDAPService interface {
+	// FindLDAPConfig returns the configuration of the directory. It is
+	// disabled if it has never been configured.
+	FindLDAPConfig(ctx context.Context) (*LDAPConfig, error)
+
+	// PutLDAPConfig replaces the configuration of the directory.
+	PutLDAPConfig(ctx context.Context, c *LDAPConfig) error
+
+	// FindLDAPMemberships returns the memberships of organizations added by
+	// syncs of the directory.
+	FindLDAPMemberships(ctx context.Context) ([]*LDAPMembership, error)
+
+	// PutLDAPMembership records a membership added by a sync.
+	PutLDAPMembership(ctx context.Context, m *LDAPMembership) error
+
+	// DeleteLDAPMembership removes the record of the membership of the
+	// user of an organization.
+	DeleteLDAPMembership(ctx context.Context, orgID, userID ID) error
+}
+
+// LDAPSyncService syncs the groups of the LDAP directory into organizations.
+type LDAPSyncService interface {
+	// SyncLDAP adds the members of the groups of the directory to the
+	// organizations the groups are mapped to, and removes the memberships it
+	// has added of users who are no longer members of the groups. A dry run
+	// reports the changes a sync would make without making them.
+	SyncLDAP(ctx context.Context, dryRun bool) (*LDAPSyncReport, error)
+}
+
+// LDAPConfig configures the LDAP directory, such as Active Directory, that
+// users are authenticated against and whose groups are synced into
+// organizations.
+type LDAPConfig struct {
+	Enabled bool `json:"enabled"`
+
+	// URL is the ldap:// or ldaps:// URL of the directory.
+	URL                string `json:"url"`
+	StartTLS           bool   `json:"startTLS,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+
+	// BindDN and BindPassword are the credentials the directory is searched
+	// with. The directory is searched anonymously without them.
+	BindDN       string `json:"bindDN,omitempty"`
+	BindPassword string `json:"bindPassword,omitempty"`
+
+	// UserSearchBase is the DN the entries of users are searched under with
+	// UserFilter, in which %s is replaced by the name of a user, such as
+	// (uid=%s), or (sAMAccountName=%s) for Active Directory.
+	UserSearchBase string `json:"userSearchBase"`
+	UserFilter     string `json:"userFilter"`
+	// UsernameAttribute is the attribute of the entries of users holding
+	// their names.
+	UsernameAttribute string `json:"usernameAttribute"`
+	// GroupMemberAttribute is the attribute of the entries of groups holding
+	// the DNs of their members, such as member or uniqueMember.
+	GroupMemberAttribute string `json:"groupMemberAttribute"`
+
+	// SyncInterval is how often the groups are synced. Groups are only
+	// synced on request if it is 0.
+	SyncInterval Duration `json:"syncInterval"`
+
+	Mappings []LDAPGroupMapping `json:"mappings"`
+}
+
+// LDAPGroupMapping maps the members of a group of the directory to a role in
+// an organization.
+type LDAPGroupMapping struct {
+	GroupDN string   `json:"groupDN"`
+	OrgID   ID       `json:"orgID"`
+	Role    UserType `json:"role"`
+}
+
+// Validate returns an error if the configuration is not valid. A disabled
+// configuration may be incomplete.
+func (c *LDAPConfig) Validate() error {
+	if c.SyncInterval.Duration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "syncInterval cannot be negative",
+		}
+	}
+	for i, m := range c.Mappings {
+		if m.GroupDN == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("groupDN of mapping %d is required", i),
+			}
+		}
+		if !m.OrgID.Valid() {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("orgID of mapping %d is required", i),
+			}
+		}
+		if err := m.Role.Valid(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid role of mapping %d", i),
+				Err:  err,
+			}
+		}
+	}
+	if !c.Enabled {
+		return nil
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "url must be an ldap:// or ldaps:// URL",
+		}
+	}
+	if u.Scheme == "ldaps" && c.StartTLS {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "startTLS cannot be used with an ldaps:// URL",
+		}
+	}
+	if c.UserSearchBase == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "userSearchBase is required",
+		}
+	}
+	if strings.Count(c.UserFilter, "%s") != 1 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "userFilter must contain %s once",
+		}
+	}
+	if c.UsernameAttribute == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "usernameAttribute is required",
+		}
+	}
+	if c.GroupMemberAttribute == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "groupMemberAttribute is required",
+		}
+	}
+	return nil
+}
+
+// LDAPMembership is a membership of an organization added by a sync of the
+// directory.
+type LDAPMembership struct {
+	OrgID  ID       `json:"orgID"`
+	UserID ID       `json:"userID"`
+	Role   UserType `json:"role"`
+}
+
+// LDAPSyncAction is a change made by a sync of the directory.
+type LDAPSyncAction string
+
+// Changes made by syncs of the directory.
+const (
+	LDAPCreateUser   LDAPSyncAction = "createUser"
+	LDAPAddMember    LDAPSyncAction = "addMember"
+	LDAPUpdateRole   LDAPSyncAction = "updateRole"
+	LDAPRemoveMember LDAPSyncAction = "removeMember"
+)
+
+// LDAPSyncChange is a change made, or that would be made, by a sync.
+type LDAPSyncChange struct {
+	Action   LDAPSyncAction `json:"action"`
+	Username string         `json:"username"`
+	OrgID    ID             `json:"orgID,omitempty"`
+	Role     UserType       `json:"role,omitempty"`
+}
+
+// LDAPSyncReport reports the changes made by a sync, in the order they were
+// made.
+type LDAPSyncReport struct {
+	DryRun  bool             `json:"dryRun"`
+	Changes []LDAPSyncChange `json:"changes"`
+}
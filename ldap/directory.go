@@ -0,0 +1,215 @@
+// Package ldap integrates an LDAP directory, such as Active Directory: it
+// authenticates users against the directory and syncs the members of its
+// groups into organizations.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	goldap "github.com/go-ldap/ldap"
+	"github.com/influxdata/influxdb"
+)
+
+// ErrUserNotFound is returned by directories that have no user of a name.
+var ErrUserNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "user not found in ldap directory",
+}
+
+// requestTimeout is how long requests to the directory may take.
+const requestTimeout = 10 * time.Second
+
+// Directory is a connection to an LDAP directory.
+type Directory interface {
+	// Authenticate returns an error if password is not the password of the
+	// user of the directory with username, and ErrUserNotFound if the
+	// directory has no such user.
+	Authenticate(ctx context.Context, username, password string) error
+
+	// GroupMembers returns the names of the users who are members of the
+	// group with the DN groupDN.
+	GroupMembers(ctx context.Context, groupDN string) ([]string, error)
+
+	// Close closes the connection.
+	Close() error
+}
+
+// DialFunc connects to the directory configured by c.
+type DialFunc func(ctx context.Context, c *influxdb.LDAPConfig) (Directory, error)
+
+// Dial connects to the directory configured by c, and binds with its
+// credentials if it has any.
+func Dial(ctx context.Context, c *influxdb.LDAPConfig) (Directory, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid ldap url",
+			Err:  err,
+		}
+	}
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	var conn *goldap.Conn
+	if u.Scheme == "ldaps" {
+		conn, err = goldap.DialTLS("tcp", hostPort(u, goldap.DefaultLdapsPort), tlsConfig)
+	} else {
+		conn, err = goldap.Dial("tcp", hostPort(u, goldap.DefaultLdapPort))
+	}
+	if err != nil {
+		return nil, directoryError("unable to connect to ldap directory", err)
+	}
+
+	conn.SetTimeout(requestTimeout)
+
+	d := &directory{conn: conn, config: c}
+	if c.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			d.Close()
+			return nil, directoryError("unable to start tls with ldap directory", err)
+		}
+	}
+	if err := d.bind(); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func directoryError(msg string, err error) error {
+	return &influxdb.Error{
+		Code: influxdb.EUnavailable,
+		Msg:  msg,
+		Err:  err,
+	}
+}
+
+type directory struct {
+	conn   *goldap.Conn
+	config *influxdb.LDAPConfig
+}
+
+// bind binds with the credentials of the configuration.
+func (d *directory) bind() error {
+	if d.config.BindDN == "" {
+		return nil
+	}
+	if err := d.conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+		return directoryError("unable to bind to ldap directory", err)
+	}
+	return nil
+}
+
+func (d *directory) Authenticate(ctx context.Context, username, password string) error {
+	// Binding without a password is an anonymous bind, which the directory
+	// may allow for any DN.
+	if password == "" {
+		return errIncorrectPassword
+	}
+
+	res, err := d.conn.Search(goldap.NewSearchRequest(
+		d.config.UserSearchBase,
+		goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(d.config.UserFilter, goldap.EscapeFilter(username)),
+		[]string{"dn"},
+		nil,
+	))
+	// The search is limited to two entries, more than one of which means
+	// the name is ambiguous.
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) || (err == nil && len(res.Entries) > 1) {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("more than one user of ldap directory is named %q", username),
+		}
+	} else if err != nil {
+		return directoryError("unable to search ldap directory", err)
+	}
+	if len(res.Entries) == 0 {
+		return ErrUserNotFound
+	}
+
+	err = d.conn.Bind(res.Entries[0].DN, password)
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+		return errIncorrectPassword
+	} else if err != nil {
+		return directoryError("unable to bind to ldap directory", err)
+	}
+	// Further operations are made with the credentials of the configuration.
+	return d.bind()
+}
+
+var errIncorrectPassword = &influxdb.Error{
+	Code: influxdb.EForbidden,
+	Msg:  "your username or password is incorrect",
+}
+
+func (d *directory) GroupMembers(ctx context.Context, groupDN string) ([]string, error) {
+	group, err := d.entry(groupDN, d.config.GroupMemberAttribute)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("group %q not found in ldap directory", groupDN),
+		}
+	}
+
+	var names []string
+	for _, dn := range group.GetAttributeValues(d.config.GroupMemberAttribute) {
+		member, err := d.entry(dn, d.config.UsernameAttribute)
+		if err != nil {
+			return nil, err
+		}
+		// Members that are not users, such as nested groups, have no name.
+		if member == nil {
+			continue
+		}
+		if name := member.GetAttributeValue(d.config.UsernameAttribute); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// entry returns the attribute of the entry with dn, or nil if there is no
+// such entry.
+func (d *directory) entry(dn, attribute string) (*goldap.Entry, error) {
+	res, err := d.conn.Search(goldap.NewSearchRequest(
+		dn,
+		goldap.ScopeBaseObject, goldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)",
+		[]string{attribute},
+		nil,
+	))
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultNoSuchObject) {
+		return nil, nil
+	} else if err != nil {
+		return nil, directoryError("unable to search ldap directory", err)
+	}
+	if len(res.Entries) == 0 {
+		return nil, nil
+	}
+	return res.Entries[0], nil
+}
+
+func (d *directory) Close() error {
+	d.conn.Close()
+	return nil
+}
@@ -0,0 +1,69 @@
+package ldap
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.PasswordsService = (*PasswordsService)(nil)
+
+// PasswordsService authenticates the users of the directory against it while
+// it is enabled. Other users, and every user while the directory is
+// unavailable, are authenticated with the passwords of the wrapped service.
+type PasswordsService struct {
+	influxdb.PasswordsService
+
+	LDAPService influxdb.LDAPService
+	UserService influxdb.UserService
+	Dial        DialFunc
+
+	log *zap.Logger
+}
+
+// NewPasswordsService returns a PasswordsService authenticating users against
+// the directory configured by ldapSvc, or with the passwords of s.
+func NewPasswordsService(log *zap.Logger, s influxdb.PasswordsService, ldapSvc influxdb.LDAPService, userSvc influxdb.UserService) *PasswordsService {
+	return &PasswordsService{
+		PasswordsService: s,
+		LDAPService:      ldapSvc,
+		UserService:      userSvc,
+		Dial:             Dial,
+		log:              log,
+	}
+}
+
+// ComparePassword checks the password of the user against the directory if
+// the directory has a user of the same name.
+func (s *PasswordsService) ComparePassword(ctx context.Context, userID influxdb.ID, password string) error {
+	c, err := s.LDAPService.FindLDAPConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !c.Enabled {
+		return s.PasswordsService.ComparePassword(ctx, userID, password)
+	}
+
+	u, err := s.UserService.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	dir, err := s.Dial(ctx, c)
+	if err != nil {
+		s.log.Warn("Unable to authenticate user against ldap directory", zap.String("user", u.Name), zap.Error(err))
+		return s.PasswordsService.ComparePassword(ctx, userID, password)
+	}
+	defer dir.Close()
+
+	err = dir.Authenticate(ctx, u.Name, password)
+	switch {
+	case err == ErrUserNotFound:
+		return s.PasswordsService.ComparePassword(ctx, userID, password)
+	case influxdb.ErrorCode(err) == influxdb.EUnavailable:
+		s.log.Warn("Unable to authenticate user against ldap directory", zap.String("user", u.Name), zap.Error(err))
+		return s.PasswordsService.ComparePassword(ctx, userID, password)
+	}
+	return err
+}
@@ -0,0 +1,57 @@
+package ldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestPasswordsService_ComparePassword(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	local := &influxdb.User{Name: "admin"}
+	directoryUser := &influxdb.User{Name: "alice"}
+	for _, u := range []*influxdb.User{local, directoryUser} {
+		if err := svc.CreateUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.SetPassword(ctx, local.ID, "localpassword"); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := &fakeDirectory{passwords: map[string]string{"alice": "ldappassword"}}
+	s := NewPasswordsService(zaptest.NewLogger(t), svc, svc, svc)
+	s.Dial = dir.dial
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		userID   influxdb.ID
+		password string
+		wantErr  bool
+	}{
+		{name: "local user while disabled", userID: local.ID, password: "localpassword"},
+		{name: "directory user while disabled", userID: directoryUser.ID, password: "ldappassword", wantErr: true},
+		{name: "local user", enabled: true, userID: local.ID, password: "localpassword"},
+		{name: "directory user", enabled: true, userID: directoryUser.ID, password: "ldappassword"},
+		{name: "directory user with wrong password", enabled: true, userID: directoryUser.ID, password: "localpassword", wantErr: true},
+		{name: "directory user without password", enabled: true, userID: directoryUser.ID, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := testConfig()
+			c.Enabled = tt.enabled
+			if err := svc.PutLDAPConfig(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+			err := s.ComparePassword(ctx, tt.userID, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComparePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package ldap
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	influxlogger "github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.LDAPSyncService = (*Syncer)(nil)
+
+// Syncer syncs the members of the groups of the directory into the
+// organizations the groups are mapped to.
+//
+// The memberships added by syncs are recorded, so that the memberships of
+// users who are no longer members of the groups are removed, while
+// memberships added otherwise are left as they are.
+type Syncer struct {
+	LDAPService                influxdb.LDAPService
+	UserService                influxdb.UserService
+	UserResourceMappingService influxdb.UserResourceMappingService
+	Dial                       DialFunc
+
+	// Interval is how often the syncer checks whether the groups are due to
+	// be synced.
+	Interval time.Duration
+
+	log *zap.Logger
+	now func() time.Time
+
+	mu       sync.Mutex
+	lastSync time.Time
+}
+
+// NewSyncer returns a Syncer checking whether the groups are due to be synced
+// every minute.
+func NewSyncer(log *zap.Logger) *Syncer {
+	return &Syncer{
+		Dial:     Dial,
+		Interval: time.Minute,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// Run syncs the groups each sync interval of the configuration of the
+// directory until ctx is done.
+func (s *Syncer) Run(ctx context.Context) {
+	logger := s.log.With(
+		zap.String("service", "ldap_syncer"),
+		influxlogger.DurationLiteral("interval", s.Interval),
+	)
+
+	logger.Info("Starting")
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.syncIfDue(ctx); err != nil {
+				logger.Warn("Unable to sync ldap groups", zap.Error(err))
+			}
+		case <-ctx.Done():
+			logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+func (s *Syncer) syncIfDue(ctx context.Context) error {
+	c, err := s.LDAPService.FindLDAPConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if !c.Enabled || c.SyncInterval.Duration == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	due := s.now().Sub(s.lastSync) >= c.SyncInterval.Duration
+	s.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	_, err = s.SyncLDAP(ctx, false)
+	return err
+}
+
+// SyncLDAP syncs the groups of the directory. Syncs are made one at a time.
+func (s *Syncer) SyncLDAP(ctx context.Context, dryRun bool) (*influxdb.LDAPSyncReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report, err := s.sync(ctx, dryRun)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Op:  influxdb.OpSyncLDAP,
+			Err: err,
+		}
+	}
+	if !dryRun {
+		s.lastSync = s.now()
+	}
+	return report, nil
+}
+
+func (s *Syncer) sync(ctx context.Context, dryRun bool) (*influxdb.LDAPSyncReport, error) {
+	c, err := s.LDAPService.FindLDAPConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Enabled {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "ldap is not enabled",
+		}
+	}
+
+	roles, err := s.groupRoles(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := s.LDAPService.FindLDAPMemberships(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sy := &syncRun{
+		Syncer:   s,
+		dryRun:   dryRun,
+		report:   &influxdb.LDAPSyncReport{DryRun: dryRun, Changes: []influxdb.LDAPSyncChange{}},
+		users:    make(map[string]*influxdb.User),
+		recorded: make(map[membershipKey]*influxdb.LDAPMembership, len(recorded)),
+	}
+	for _, m := range recorded {
+		sy.recorded[membershipKey{orgID: m.OrgID, userID: m.UserID}] = m
+	}
+
+	orgIDs := make([]influxdb.ID, 0, len(roles))
+	for orgID := range roles {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	desired := make(map[membershipKey]bool)
+	for _, orgID := range orgIDs {
+		names := make([]string, 0, len(roles[orgID]))
+		for name := range roles[orgID] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			u, err := sy.user(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			if u.ID.Valid() {
+				desired[membershipKey{orgID: orgID, userID: u.ID}] = true
+			}
+			if err := sy.member(ctx, orgID, u, roles[orgID][name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, m := range recorded {
+		if desired[membershipKey{orgID: m.OrgID, userID: m.UserID}] {
+			continue
+		}
+		if err := sy.removeMember(ctx, m); err != nil {
+			return nil, err
+		}
+	}
+	return sy.report, nil
+}
+
+// groupRoles returns the role of the members of the groups of the directory
+// in each organization the groups are mapped to. The members of several
+// groups mapped to an organization are owners if any of them is.
+func (s *Syncer) groupRoles(ctx context.Context, c *influxdb.LDAPConfig) (map[influxdb.ID]map[string]influxdb.UserType, error) {
+	dir, err := s.Dial(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	defer dir.Close()
+
+	roles := make(map[influxdb.ID]map[string]influxdb.UserType)
+	for _, m := range c.Mappings {
+		names, err := dir.GroupMembers(ctx, m.GroupDN)
+		if err != nil {
+			return nil, err
+		}
+		if roles[m.OrgID] == nil {
+			roles[m.OrgID] = make(map[string]influxdb.UserType)
+		}
+		for _, name := range names {
+			if roles[m.OrgID][name] != influxdb.Owner {
+				roles[m.OrgID][name] = m.Role
+			}
+		}
+	}
+	return roles, nil
+}
+
+type membershipKey struct {
+	orgID, userID influxdb.ID
+}
+
+// syncRun is a sync in progress.
+type syncRun struct {
+	*Syncer
+	dryRun bool
+	report *influxdb.LDAPSyncReport
+
+	users    map[string]*influxdb.User
+	recorded map[membershipKey]*influxdb.LDAPMembership
+}
+
+func (s *syncRun) change(action influxdb.LDAPSyncAction, username string, orgID influxdb.ID, role influxdb.UserType) {
+	s.report.Changes = append(s.report.Changes, influxdb.LDAPSyncChange{
+		Action:   action,
+		Username: username,
+		OrgID:    orgID,
+		Role:     role,
+	})
+}
+
+// user returns the user with name, creating it if there is none. Users
+// created by dry runs have no ID.
+func (s *syncRun) user(ctx context.Context, name string) (*influxdb.User, error) {
+	if u, ok := s.users[name]; ok {
+		return u, nil
+	}
+
+	u, err := s.UserService.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		u = &influxdb.User{Name: name, Status: influxdb.Active}
+		if !s.dryRun {
+			if err := s.UserService.CreateUser(ctx, u); err != nil {
+				return nil, err
+			}
+		}
+		s.change(influxdb.LDAPCreateUser, name, 0, "")
+	} else if err != nil {
+		return nil, err
+	}
+
+	s.users[name] = u
+	return u, nil
+}
+
+// member makes u a member of the organization with role. Memberships added
+// otherwise than by syncs are left as they are.
+func (s *syncRun) member(ctx context.Context, orgID influxdb.ID, u *influxdb.User, role influxdb.UserType) error {
+	if !u.ID.Valid() {
+		s.change(influxdb.LDAPAddMember, u.Name, orgID, role)
+		return nil
+	}
+
+	urms, _, err := s.UserResourceMappingService.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+		ResourceID:   orgID,
+		ResourceType: influxdb.OrgsResourceType,
+		UserID:       u.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	recorded := s.recorded[membershipKey{orgID: orgID, userID: u.ID}]
+	switch {
+	case len(urms) == 0:
+		s.change(influxdb.LDAPAddMember, u.Name, orgID, role)
+	case recorded == nil || urms[0].UserType == role:
+		return nil
+	default:
+		s.change(influxdb.LDAPUpdateRole, u.Name, orgID, role)
+		if s.dryRun {
+			return nil
+		}
+		if err := s.UserResourceMappingService.DeleteUserResourceMapping(ctx, orgID, u.ID); err != nil {
+			return err
+		}
+	}
+	if s.dryRun {
+		return nil
+	}
+
+	if err := s.UserResourceMappingService.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       u.ID,
+		UserType:     role,
+		MappingType:  influxdb.UserMappingType,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   orgID,
+	}); err != nil {
+		return err
+	}
+	return s.LDAPService.PutLDAPMembership(ctx, &influxdb.LDAPMembership{
+		OrgID:  orgID,
+		UserID: u.ID,
+		Role:   role,
+	})
+}
+
+// removeMember removes a membership added by a sync.
+func (s *syncRun) removeMember(ctx context.Context, m *influxdb.LDAPMembership) error {
+	name := m.UserID.String()
+	u, err := s.UserService.FindUserByID(ctx, m.UserID)
+	if err == nil {
+		name = u.Name
+	} else if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+
+	s.change(influxdb.LDAPRemoveMember, name, m.OrgID, m.Role)
+	if s.dryRun {
+		return nil
+	}
+
+	err = s.UserResourceMappingService.DeleteUserResourceMapping(ctx, m.OrgID, m.UserID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return s.LDAPService.DeleteLDAPMembership(ctx, m.OrgID, m.UserID)
+}
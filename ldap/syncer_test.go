@@ -0,0 +1,179 @@
+package ldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeDirectory is a directory of the users with passwords and the members of
+// groups.
+type fakeDirectory struct {
+	passwords map[string]string
+	groups    map[string][]string
+}
+
+func (d *fakeDirectory) dial(ctx context.Context, c *influxdb.LDAPConfig) (Directory, error) {
+	return d, nil
+}
+
+func (d *fakeDirectory) Authenticate(ctx context.Context, username, password string) error {
+	p, ok := d.passwords[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if password == "" || p != password {
+		return errIncorrectPassword
+	}
+	return nil
+}
+
+func (d *fakeDirectory) GroupMembers(ctx context.Context, groupDN string) ([]string, error) {
+	return d.groups[groupDN], nil
+}
+
+func (d *fakeDirectory) Close() error { return nil }
+
+func newTestService(t *testing.T) *kv.Service {
+	t.Helper()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func testConfig(mappings ...influxdb.LDAPGroupMapping) *influxdb.LDAPConfig {
+	return &influxdb.LDAPConfig{
+		Enabled:              true,
+		URL:                  "ldap://ldap.example.com",
+		UserSearchBase:       "ou=people,dc=example,dc=com",
+		UserFilter:           "(uid=%s)",
+		UsernameAttribute:    "uid",
+		GroupMemberAttribute: "member",
+		Mappings:             mappings,
+	}
+}
+
+func TestSyncer_SyncLDAP(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	org := &influxdb.Organization{Name: "ops"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	// A member added by hand is left as it is.
+	manual := &influxdb.User{Name: "carol"}
+	if err := svc.CreateUser(ctx, manual); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+		UserID:       manual.ID,
+		UserType:     influxdb.Member,
+		ResourceType: influxdb.OrgsResourceType,
+		ResourceID:   org.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.PutLDAPConfig(ctx, testConfig(
+		influxdb.LDAPGroupMapping{GroupDN: "cn=admins", OrgID: org.ID, Role: influxdb.Owner},
+		influxdb.LDAPGroupMapping{GroupDN: "cn=ops", OrgID: org.ID, Role: influxdb.Member},
+	)); err != nil {
+		t.Fatal(err)
+	}
+	dir := &fakeDirectory{
+		groups: map[string][]string{
+			"cn=admins": {"alice"},
+			"cn=ops":    {"alice", "bob", "carol"},
+		},
+	}
+
+	s := NewSyncer(zaptest.NewLogger(t))
+	s.LDAPService = svc
+	s.UserService = svc
+	s.UserResourceMappingService = svc
+	s.Dial = dir.dial
+
+	roles := func() map[string]influxdb.UserType {
+		t.Helper()
+		urms, _, err := svc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceID:   org.ID,
+			ResourceType: influxdb.OrgsResourceType,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		roles := make(map[string]influxdb.UserType)
+		for _, urm := range urms {
+			u, err := svc.FindUserByID(ctx, urm.UserID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			roles[u.Name] = urm.UserType
+		}
+		return roles
+	}
+	syncLDAP := func(dryRun bool, want []influxdb.LDAPSyncChange) {
+		t.Helper()
+		report, err := s.SyncLDAP(ctx, dryRun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, report.Changes); diff != "" {
+			t.Fatalf("unexpected changes (dry run %v):\n%s", dryRun, diff)
+		}
+	}
+
+	added := []influxdb.LDAPSyncChange{
+		{Action: influxdb.LDAPCreateUser, Username: "alice"},
+		{Action: influxdb.LDAPAddMember, Username: "alice", OrgID: org.ID, Role: influxdb.Owner},
+		{Action: influxdb.LDAPCreateUser, Username: "bob"},
+		{Action: influxdb.LDAPAddMember, Username: "bob", OrgID: org.ID, Role: influxdb.Member},
+	}
+	syncLDAP(true, added)
+	if diff := cmp.Diff(map[string]influxdb.UserType{"carol": influxdb.Member}, roles()); diff != "" {
+		t.Fatalf("dry run changed memberships:\n%s", diff)
+	}
+
+	syncLDAP(false, added)
+	if diff := cmp.Diff(map[string]influxdb.UserType{
+		"alice": influxdb.Owner,
+		"bob":   influxdb.Member,
+		"carol": influxdb.Member,
+	}, roles()); diff != "" {
+		t.Fatalf("unexpected memberships:\n%s", diff)
+	}
+	syncLDAP(false, []influxdb.LDAPSyncChange{})
+
+	dir.groups = map[string][]string{
+		"cn=admins": {"bob"},
+		"cn=ops":    {"bob"},
+	}
+	syncLDAP(false, []influxdb.LDAPSyncChange{
+		{Action: influxdb.LDAPUpdateRole, Username: "bob", OrgID: org.ID, Role: influxdb.Owner},
+		{Action: influxdb.LDAPRemoveMember, Username: "alice", OrgID: org.ID, Role: influxdb.Owner},
+	})
+	if diff := cmp.Diff(map[string]influxdb.UserType{
+		"bob":   influxdb.Owner,
+		"carol": influxdb.Member,
+	}, roles()); diff != "" {
+		t.Fatalf("unexpected memberships:\n%s", diff)
+	}
+}
+
+func TestSyncer_SyncLDAP_disabled(t *testing.T) {
+	svc := newTestService(t)
+
+	s := NewSyncer(zaptest.NewLogger(t))
+	s.LDAPService = svc
+	if _, err := s.SyncLDAP(context.Background(), false); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v syncing a disabled directory, want an invalid error", err)
+	}
+}
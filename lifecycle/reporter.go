@@ -0,0 +1,253 @@
+// Package lifecycle periodically compiles a summary of the data lifecycle of
+// each organization: data written, data expired by retention, changes in
+// series cardinality and the headroom left under the series limit.
+//
+// Reports are written to the monitoring system bucket of each organization,
+// and can optionally be sent to administrators as a digest.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	influxlogger "github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// MeasurementName is the measurement reports are written to in the
+// monitoring system bucket.
+const MeasurementName = "lifecycle"
+
+// Report summarises the data lifecycle of an organization over a period.
+type Report struct {
+	OrgID influxdb.ID `json:"orgID"`
+	Org   string      `json:"org"`
+
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Data written during the period.
+	PointsWritten uint64 `json:"pointsWritten"`
+	BytesWritten  uint64 `json:"bytesWritten"`
+
+	// RetentionExpiries is the number of times data was expired from the
+	// organization's buckets by retention during the period.
+	RetentionExpiries uint64 `json:"retentionExpiries"`
+
+	// Series is the estimated number of series of the organization at the
+	// end of the period, and SeriesChange its change over the period.
+	Series       uint64 `json:"series"`
+	SeriesChange int64  `json:"seriesChange"`
+
+	// MaxBucketSeries is the estimated number of series of the organization's
+	// largest bucket. If a per-bucket series limit is configured, SeriesLimit
+	// holds it and SeriesHeadroom the number of series that bucket can still
+	// add.
+	MaxBucketSeries uint64 `json:"maxBucketSeries"`
+	SeriesLimit     uint64 `json:"seriesLimit,omitempty"`
+	SeriesHeadroom  uint64 `json:"seriesHeadroom,omitempty"`
+}
+
+// A RetentionCounter counts the expiries of data by retention.
+type RetentionCounter interface {
+	// RetentionExpiries returns the number of times data has been expired
+	// from the buckets of each organization.
+	RetentionExpiries() map[influxdb.ID]uint64
+}
+
+// A Notifier sends a digest of reports to administrators.
+type Notifier interface {
+	Notify(ctx context.Context, reports []*Report) error
+}
+
+// totals holds the cumulative counters reports are computed from.
+type totals struct {
+	points, bytes uint64
+	expiries      uint64
+	series        uint64
+}
+
+// Reporter compiles and publishes lifecycle reports every interval.
+type Reporter struct {
+	OrganizationService influxdb.OrganizationService
+	BucketService       influxdb.BucketService
+	WriteStatsService   influxdb.WriteStatsService
+	CardinalityService  influxdb.CardinalityService
+	RetentionCounter    RetentionCounter
+	PointsWriter        storage.PointsWriter
+
+	// Notifier, if set, is sent the reports of every organization after
+	// they are written.
+	Notifier Notifier
+
+	// SeriesLimit is the maximum number of series of a bucket. Zero means
+	// there is no limit.
+	SeriesLimit int
+
+	Interval time.Duration
+
+	log   *zap.Logger
+	now   func() time.Time
+	start time.Time
+	prev  map[influxdb.ID]totals
+}
+
+// NewReporter returns a Reporter publishing reports every 24 hours.
+func NewReporter(log *zap.Logger) *Reporter {
+	return &Reporter{
+		Interval: 24 * time.Hour,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// Report compiles and publishes reports each interval until ctx is done. The
+// first report covers the period from when Report is called.
+func (r *Reporter) Report(ctx context.Context) {
+	logger := r.log.With(
+		zap.String("service", "lifecycle_reporter"),
+		influxlogger.DurationLiteral("interval", r.Interval),
+	)
+
+	logger.Info("Starting")
+	if _, err := r.Compile(ctx); err != nil {
+		logger.Warn("Unable to compile lifecycle baseline", zap.Error(err))
+	}
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			reports, err := r.Compile(ctx)
+			if err != nil {
+				logger.Warn("Unable to compile lifecycle reports", zap.Error(err))
+				continue
+			}
+			if err := r.Publish(ctx, reports); err != nil {
+				logger.Warn("Unable to publish lifecycle reports", zap.Error(err))
+			}
+		case <-ctx.Done():
+			logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// Compile returns a report for each organization covering the period since
+// the previous call. The first call reports everything tracked so far.
+func (r *Reporter) Compile(ctx context.Context) ([]*Report, error) {
+	orgs, _, err := r.OrganizationService.FindOrganizations(ctx, influxdb.OrganizationFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	var expiries map[influxdb.ID]uint64
+	if r.RetentionCounter != nil {
+		expiries = r.RetentionCounter.RetentionExpiries()
+	}
+
+	end := r.now().UTC()
+	reports := make([]*Report, 0, len(orgs))
+	current := make(map[influxdb.ID]totals, len(orgs))
+	for _, o := range orgs {
+		cur := totals{expiries: expiries[o.ID]}
+		rep := &Report{OrgID: o.ID, Org: o.Name, Start: r.start, End: end}
+
+		stats, err := r.WriteStatsService.FindMeasurementWriteStats(ctx, influxdb.WriteStatsFilter{OrgID: &o.ID})
+		if err != nil {
+			return nil, fmt.Errorf("unable to find write stats of org %s: %v", o.ID, err)
+		}
+		for _, st := range stats {
+			cur.points += st.TotalPoints
+			cur.bytes += st.TotalBytes
+		}
+
+		cards, err := r.CardinalityService.FindMeasurementCardinality(ctx, influxdb.CardinalityFilter{OrgID: &o.ID})
+		if err != nil {
+			return nil, fmt.Errorf("unable to find cardinality of org %s: %v", o.ID, err)
+		}
+		bucketSeries := make(map[influxdb.ID]uint64)
+		for _, c := range cards {
+			cur.series += c.Series
+			bucketSeries[c.BucketID] += c.Series
+		}
+		for _, n := range bucketSeries {
+			if n > rep.MaxBucketSeries {
+				rep.MaxBucketSeries = n
+			}
+		}
+
+		prev := r.prev[o.ID]
+		rep.PointsWritten = delta(cur.points, prev.points)
+		rep.BytesWritten = delta(cur.bytes, prev.bytes)
+		rep.RetentionExpiries = delta(cur.expiries, prev.expiries)
+		rep.Series = cur.series
+		rep.SeriesChange = int64(cur.series) - int64(prev.series)
+		if r.SeriesLimit > 0 {
+			rep.SeriesLimit = uint64(r.SeriesLimit)
+			rep.SeriesHeadroom = delta(rep.SeriesLimit, rep.MaxBucketSeries)
+		}
+
+		current[o.ID] = cur
+		reports = append(reports, rep)
+	}
+
+	r.start, r.prev = end, current
+	return reports, nil
+}
+
+// delta returns the increase of a cumulative counter from prev to cur. Counters
+// can decrease when their data is deleted, which is not an increase.
+func delta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// Publish writes each report to the monitoring system bucket of its
+// organization, then sends them to the Notifier, if any.
+func (r *Reporter) Publish(ctx context.Context, reports []*Report) error {
+	for _, rep := range reports {
+		b, err := r.BucketService.FindBucketByName(ctx, rep.OrgID, influxdb.MonitoringSystemBucketName)
+		if err != nil {
+			return fmt.Errorf("unable to find monitoring bucket of org %s: %v", rep.OrgID, err)
+		}
+
+		fields := map[string]interface{}{
+			"pointsWritten":     int64(rep.PointsWritten),
+			"bytesWritten":      int64(rep.BytesWritten),
+			"retentionExpiries": int64(rep.RetentionExpiries),
+			"series":            int64(rep.Series),
+			"seriesChange":      rep.SeriesChange,
+			"maxBucketSeries":   int64(rep.MaxBucketSeries),
+		}
+		if rep.SeriesLimit > 0 {
+			fields["seriesLimit"] = int64(rep.SeriesLimit)
+			fields["seriesHeadroom"] = int64(rep.SeriesHeadroom)
+		}
+
+		pt, err := models.NewPoint(MeasurementName, nil, fields, rep.End)
+		if err != nil {
+			return err
+		}
+		points, err := tsdb.ExplodePoints(rep.OrgID, b.ID, models.Points{pt})
+		if err != nil {
+			return err
+		}
+		if err := r.PointsWriter.WritePoints(ctx, points); err != nil {
+			return fmt.Errorf("unable to write report of org %s: %v", rep.OrgID, err)
+		}
+	}
+
+	if r.Notifier == nil || len(reports) == 0 {
+		return nil
+	}
+	return r.Notifier.Notify(ctx, reports)
+}
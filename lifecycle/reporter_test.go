@@ -0,0 +1,219 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type retentionCounter map[influxdb.ID]uint64
+
+func (c retentionCounter) RetentionExpiries() map[influxdb.ID]uint64 { return c }
+
+type notifier struct {
+	reports []*Report
+}
+
+func (n *notifier) Notify(ctx context.Context, reports []*Report) error {
+	n.reports = reports
+	return nil
+}
+
+// testReporter returns a Reporter for one org, whose write, cardinality and
+// retention totals are read from the returned values.
+func testReporter(t *testing.T) (*Reporter, *influxdb.MeasurementWriteStats, []*influxdb.MeasurementCardinality, retentionCounter) {
+	orgID := influxdb.ID(1)
+	stats := &influxdb.MeasurementWriteStats{OrgID: orgID, BucketID: 2, Measurement: "cpu"}
+	cards := []*influxdb.MeasurementCardinality{
+		{OrgID: orgID, BucketID: 2, Measurement: "cpu"},
+		{OrgID: orgID, BucketID: 2, Measurement: "mem"},
+		{OrgID: orgID, BucketID: 3, Measurement: "cpu"},
+	}
+	expiries := retentionCounter{}
+
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationsF = func(ctx context.Context, filter influxdb.OrganizationFilter, opt ...influxdb.FindOptions) ([]*influxdb.Organization, int, error) {
+		return []*influxdb.Organization{{ID: orgID, Name: "org"}}, 1, nil
+	}
+	writeStats := mock.NewWriteStatsService()
+	writeStats.FindMeasurementWriteStatsF = func(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+		if filter.OrgID == nil || *filter.OrgID != orgID {
+			t.Fatalf("unexpected write stats filter %+v", filter)
+		}
+		st := *stats
+		return []*influxdb.MeasurementWriteStats{&st}, nil
+	}
+	cardinality := mock.NewCardinalityService()
+	cardinality.FindMeasurementCardinalityF = func(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+		if filter.OrgID == nil || *filter.OrgID != orgID {
+			t.Fatalf("unexpected cardinality filter %+v", filter)
+		}
+		return cards, nil
+	}
+
+	r := NewReporter(zaptest.NewLogger(t))
+	r.OrganizationService = orgs
+	r.WriteStatsService = writeStats
+	r.CardinalityService = cardinality
+	r.RetentionCounter = expiries
+	return r, stats, cards, expiries
+}
+
+func TestReporter_Compile(t *testing.T) {
+	r, stats, cards, expiries := testReporter(t)
+	r.SeriesLimit = 100
+
+	start := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	r.now = func() time.Time { return now }
+
+	stats.TotalPoints, stats.TotalBytes = 10, 200
+	cards[0].Series, cards[1].Series, cards[2].Series = 20, 10, 5
+	expiries[1] = 3
+	if _, err := r.Compile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(24 * time.Hour)
+	stats.TotalPoints, stats.TotalBytes = 15, 300
+	cards[0].Series, cards[1].Series, cards[2].Series = 60, 10, 8
+	expiries[1] = 4
+	reports, err := r.Compile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []*Report{{
+		OrgID:             1,
+		Org:               "org",
+		Start:             start,
+		End:               now,
+		PointsWritten:     5,
+		BytesWritten:      100,
+		RetentionExpiries: 1,
+		Series:            78,
+		SeriesChange:      43,
+		MaxBucketSeries:   70,
+		SeriesLimit:       100,
+		SeriesHeadroom:    30,
+	}}
+	if !reflect.DeepEqual(reports, exp) {
+		t.Fatalf("got %+v, expected %+v", reports[0], exp[0])
+	}
+
+	// Deleted data reduces the series, but is not reported as negative writes.
+	now = now.Add(24 * time.Hour)
+	stats.TotalPoints, stats.TotalBytes = 0, 0
+	cards[0].Series, cards[1].Series, cards[2].Series = 0, 0, 8
+	reports, err = r.Compile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep := reports[0]; rep.PointsWritten != 0 || rep.BytesWritten != 0 || rep.SeriesChange != -70 || rep.SeriesHeadroom != 92 {
+		t.Fatalf("unexpected report after deletion: %+v", rep)
+	}
+}
+
+func TestReporter_Publish(t *testing.T) {
+	r, _, _, _ := testReporter(t)
+	r.SeriesLimit = 100
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+		if name != influxdb.MonitoringSystemBucketName {
+			t.Fatalf("got bucket name %q, expected %q", name, influxdb.MonitoringSystemBucketName)
+		}
+		return &influxdb.Bucket{ID: influxdb.MonitoringSystemBucketID, OrgID: orgID}, nil
+	}
+	pw := &mock.PointsWriter{}
+	n := &notifier{}
+	r.BucketService, r.PointsWriter, r.Notifier = buckets, pw, n
+
+	end := time.Date(2019, 10, 2, 0, 0, 0, 0, time.UTC)
+	reports := []*Report{{
+		OrgID:           1,
+		End:             end,
+		PointsWritten:   5,
+		Series:          78,
+		SeriesChange:    -2,
+		MaxBucketSeries: 70,
+		SeriesLimit:     100,
+		SeriesHeadroom:  30,
+	}}
+	if err := r.Publish(context.Background(), reports); err != nil {
+		t.Fatal(err)
+	}
+
+	// Points are exploded into one point per field.
+	if got, exp := len(pw.Points), 8; got != exp {
+		t.Fatalf("got %d points, expected %d", got, exp)
+	}
+	name := tsdb.EncodeName(1, influxdb.MonitoringSystemBucketID)
+	fields := make(map[string]interface{})
+	for _, pt := range pw.Points {
+		if string(pt.Name()) != string(name[:]) {
+			t.Fatalf("got point name %x, expected %x", pt.Name(), name)
+		} else if !pt.Time().Equal(end) {
+			t.Fatalf("got point time %v, expected %v", pt.Time(), end)
+		}
+		if m := pt.Tags().Get(models.MeasurementTagKeyBytes); string(m) != MeasurementName {
+			t.Fatalf("got measurement %q, expected %q", m, MeasurementName)
+		}
+		iter := pt.FieldIterator()
+		for iter.Next() {
+			v, err := iter.IntegerValue()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields[string(iter.FieldKey())] = v
+		}
+	}
+	if got, exp := fields["seriesChange"], int64(-2); got != exp {
+		t.Fatalf("got seriesChange %v, expected %v", got, exp)
+	} else if got, exp := fields["seriesHeadroom"], int64(30); got != exp {
+		t.Fatalf("got seriesHeadroom %v, expected %v", got, exp)
+	}
+
+	if !reflect.DeepEqual(n.reports, reports) {
+		t.Fatalf("got notified %+v, expected %+v", n.reports, reports)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got digest
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("got content type %q, expected application/json", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	n := NewWebhookNotifier(srv.URL)
+	reports := []*Report{{OrgID: 1, Org: "org", PointsWritten: 5}}
+	if err := n.Notify(context.Background(), reports); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Reports) != 1 || got.Reports[0].Org != "org" || got.Reports[0].PointsWritten != 5 {
+		t.Fatalf("unexpected digest %+v", got)
+	}
+
+	status = http.StatusInternalServerError
+	if err := n.Notify(context.Background(), reports); err == nil {
+		t.Fatal("expected error for failed webhook")
+	}
+}
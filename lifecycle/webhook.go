@@ -0,0 +1,58 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier posts a JSON digest of reports to a URL.
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookNotifier returns a WebhookNotifier posting to url.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// digest is the body of a webhook request.
+type digest struct {
+	Reports []*Report `json:"reports"`
+}
+
+// Notify posts reports to the webhook. A response with a status other than 2xx
+// is an error.
+func (n *WebhookNotifier) Notify(ctx context.Context, reports []*Report) error {
+	body, err := json.Marshal(digest{Reports: reports})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("lifecycle webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
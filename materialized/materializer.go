@@ -0,0 +1,172 @@
+// Package materialized maintains materialized views: it runs the query of
+// each view for every window that has ended since the view was last
+// materialized, writing its results to the bucket of the view.
+package materialized
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	influxlogger "github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+// Materializer materializes the windows of views every interval.
+type Materializer struct {
+	ViewService  influxdb.MaterializedViewService
+	QueryService query.QueryService
+
+	Interval time.Duration
+
+	// MaxWindows is the maximum number of windows of a view materialized in
+	// a pass, so that a view that is far behind catches up over several
+	// passes without holding up the others. Zero means there is no limit.
+	MaxWindows int
+
+	log *zap.Logger
+	now func() time.Time
+}
+
+// NewMaterializer returns a Materializer materializing views every 10
+// seconds, up to 100 windows of a view at a time.
+func NewMaterializer(log *zap.Logger) *Materializer {
+	return &Materializer{
+		Interval:   10 * time.Second,
+		MaxWindows: 100,
+		log:        log,
+		now:        time.Now,
+	}
+}
+
+// Run materializes views each interval until ctx is done.
+func (m *Materializer) Run(ctx context.Context) {
+	logger := m.log.With(
+		zap.String("service", "materializer"),
+		influxlogger.DurationLiteral("interval", m.Interval),
+	)
+
+	logger.Info("Starting")
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.Materialize(ctx); err != nil {
+				logger.Warn("Unable to materialize views", zap.Error(err))
+			}
+		case <-ctx.Done():
+			logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// Materialize materializes the windows of every view that have ended. The
+// windows of a view are materialized in order, and the first that fails is
+// recorded as the error of the view and tried again in the next pass.
+func (m *Materializer) Materialize(ctx context.Context) error {
+	vs, _, err := m.ViewService.FindMaterializedViews(ctx, influxdb.MaterializedViewFilter{})
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := m.materializeView(ctx, v.ID); err != nil {
+			m.log.Info("Unable to materialize view",
+				zap.String("view_id", v.ID.String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// materializeView materializes the windows of the view with id that have
+// ended, up to MaxWindows of them.
+func (m *Materializer) materializeView(ctx context.Context, id influxdb.ID) error {
+	v, err := m.ViewService.FindMaterializedViewByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	now := m.now().UTC()
+	for n := 0; m.MaxWindows <= 0 || n < m.MaxWindows; n++ {
+		start, stop, ok := v.NextWindow(now)
+		if !ok {
+			return nil
+		}
+
+		upd := influxdb.MaterializedViewUpdate{}
+		runErr := m.materializeWindow(ctx, v, start, stop)
+		if runErr != nil {
+			msg := fmt.Sprintf("unable to materialize window [%s, %s): %v",
+				start.Format(time.RFC3339), stop.Format(time.RFC3339), runErr)
+			upd.LastError = &msg
+		} else {
+			noError := ""
+			upd.LatestCompleted = &stop
+			upd.LastError = &noError
+		}
+
+		// The view may have been deleted while its window was running.
+		updated, err := m.ViewService.UpdateMaterializedView(ctx, v.ID, upd)
+		if err != nil {
+			return err
+		}
+		if runErr != nil {
+			return runErr
+		}
+		updated.Authorization = v.Authorization
+		v = updated
+	}
+	return nil
+}
+
+// materializeWindow runs the query of v for the window from start to stop,
+// on behalf of the owner of the view.
+func (m *Materializer) materializeWindow(ctx context.Context, v *influxdb.MaterializedView, start, stop time.Time) error {
+	pkg, err := v.WindowQuery(start, stop)
+	if err != nil {
+		return err
+	}
+
+	req := &query.Request{
+		Authorization:  v.Authorization,
+		OrganizationID: v.OrgID,
+		Compiler: lang.ASTCompiler{
+			AST: pkg,
+			Now: stop,
+		},
+		// Views are maintained in the background, and must not starve
+		// queries users are waiting for.
+		Priority: query.PriorityBatch,
+	}
+	req.WithReturnNoContent(true)
+	ctx = icontext.SetAuthorizer(ctx, v.Authorization)
+	it, err := m.QueryService.Query(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer it.Release()
+
+	for it.More() {
+		res := it.Next()
+		err := res.Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(flux.ColReader) error {
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
@@ -0,0 +1,99 @@
+package materialized
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMaterializer_Materialize(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &influxdb.MaterializedView{
+		OrgID:           1,
+		Name:            "cpu 5m",
+		Query:           `from(bucket: "telegraf") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)`,
+		BucketID:        2,
+		OwnerID:         3,
+		Every:           influxdb.Duration{Duration: 5 * time.Minute},
+		Offset:          influxdb.Duration{Duration: time.Minute},
+		LatestCompleted: time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+	if err := svc.CreateMaterializedView(ctx, v); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		windows []time.Time
+		fail    time.Time
+	)
+	qs := &mock.QueryService{
+		QueryF: func(ctx context.Context, req *query.Request) (flux.ResultIterator, error) {
+			if req.Priority != query.PriorityBatch || req.Authorization.UserID != v.OwnerID {
+				t.Errorf("unexpected request of priority %v on behalf of %v", req.Priority, req.Authorization.UserID)
+			}
+			c := req.Compiler.(lang.ASTCompiler)
+			if !strings.Contains(ast.Format(c.AST), `|> to(bucketID: "0000000000000002", orgID: "0000000000000001")`) {
+				t.Errorf("query does not write to the bucket of the view:\n%s", ast.Format(c.AST))
+			}
+			windows = append(windows, c.Now)
+			if c.Now.Equal(fail) {
+				return nil, errors.New("query failed")
+			}
+			return flux.NewSliceResultIterator(nil), nil
+		},
+	}
+
+	m := NewMaterializer(zaptest.NewLogger(t))
+	m.ViewService = svc
+	m.QueryService = qs
+	m.MaxWindows = 2
+	m.now = func() time.Time { return time.Date(2020, 1, 1, 10, 17, 0, 0, time.UTC) }
+
+	at := func(min int) time.Time { return time.Date(2020, 1, 1, 10, min, 0, 0, time.UTC) }
+	check := func(wantWindows []time.Time, wantLatest time.Time, wantError string) {
+		t.Helper()
+		if err := m.Materialize(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(windows, wantWindows) {
+			t.Errorf("materialized windows ending at %v, want %v", windows, wantWindows)
+		}
+		got, err := svc.FindMaterializedViewByID(ctx, v.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.LatestCompleted.Equal(wantLatest) || !strings.Contains(got.LastError, wantError) {
+			t.Errorf("got latest completed %v and error %q, want %v and %q", got.LatestCompleted, got.LastError, wantLatest, wantError)
+		}
+		windows = nil
+	}
+
+	// A view catches up at most MaxWindows windows at a time.
+	check([]time.Time{at(5), at(10)}, at(10), "")
+	// A failed window is retried in the next pass.
+	fail = at(15)
+	check([]time.Time{at(15)}, at(10), "query failed")
+	fail = time.Time{}
+	// The window ending at 10:20 is not materialized before its offset has
+	// passed.
+	m.now = func() time.Time { return time.Date(2020, 1, 1, 10, 20, 30, 0, time.UTC) }
+	check([]time.Time{at(15)}, at(15), "")
+}
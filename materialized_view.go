@@ -0,0 +1,274 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// ops for materialized views.
+var (
+	OpFindMaterializedViewByID = "FindMaterializedViewByID"
+	OpFindMaterializedViews    = "FindMaterializedViews"
+	OpCreateMaterializedView   = "CreateMaterializedView"
+	OpUpdateMaterializedView   = "UpdateMaterializedView"
+	OpDeleteMaterializedView   = "DeleteMaterializedView"
+)
+
+// ErrMaterializedViewNotFound is the error msg for a missing materialized view.
+const ErrMaterializedViewNotFound = "materialized view not found"
+
+// MaterializedViewService manages materialized views.
+type MaterializedViewService interface {
+	// FindMaterializedViewByID returns a single materialized view by ID,
+	// with the authorization its queries run with.
+	FindMaterializedViewByID(ctx context.Context, id ID) (*MaterializedView, error)
+
+	// FindMaterializedViews returns a list of materialized views that match
+	// filter and the total count of matching views.
+	FindMaterializedViews(ctx context.Context, filter MaterializedViewFilter, opt ...FindOptions) ([]*MaterializedView, int, error)
+
+	// CreateMaterializedView creates a new materialized view and sets v.ID
+	// with the new identifier.
+	CreateMaterializedView(ctx context.Context, v *MaterializedView) error
+
+	// UpdateMaterializedView updates a single materialized view with
+	// changeset. Returns the new view state after update.
+	UpdateMaterializedView(ctx context.Context, id ID, upd MaterializedViewUpdate) (*MaterializedView, error)
+
+	// DeleteMaterializedView removes a materialized view by ID. The data it
+	// has materialized is left in its bucket.
+	DeleteMaterializedView(ctx context.Context, id ID) error
+}
+
+// MaterializedView is a Flux query whose results are maintained in a bucket.
+// The query is run for each window of Every, once the window has ended and
+// Offset has passed, and its results are written to the bucket. Each window
+// is materialized once, so the query only reads the data of new windows.
+//
+// The query reads the window it is run for from v.timeRangeStart and
+// v.timeRangeStop, like the queries of dashboards.
+type MaterializedView struct {
+	ID          ID     `json:"id,omitempty"`
+	OrgID       ID     `json:"orgID"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Query is the Flux query of the view. Its last statement is an
+	// expression whose tables are written to the bucket.
+	Query    string `json:"query"`
+	BucketID ID     `json:"bucketID"`
+
+	Every  Duration `json:"every"`
+	Offset Duration `json:"offset"`
+
+	// LatestCompleted is the end of the latest window materialized. Setting
+	// it to an earlier time materializes the windows since again.
+	LatestCompleted time.Time `json:"latestCompleted"`
+	// LastError is the error of the latest window that failed to be
+	// materialized, if it has not been materialized since.
+	LastError string `json:"lastError,omitempty"`
+
+	// OwnerID is the user the queries of the view run on behalf of.
+	OwnerID       ID             `json:"ownerID"`
+	Authorization *Authorization `json:"-"`
+
+	CRUDLog
+}
+
+// Validate returns an error if the view is not valid.
+func (v *MaterializedView) Validate() error {
+	if v.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "name is required",
+		}
+	}
+	if !v.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+	if !v.BucketID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "bucketID is required",
+		}
+	}
+	if !v.OwnerID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "ownerID is required",
+		}
+	}
+	if v.Every.Duration <= 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "every must be positive",
+		}
+	}
+	if v.Offset.Duration < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "offset cannot be negative",
+		}
+	}
+	_, err := v.parseQuery()
+	return err
+}
+
+// parseQuery parses the query of the view and checks that it can be
+// materialized.
+func (v *MaterializedView) parseQuery() (*ast.Package, error) {
+	pkg, err := safeParseSource(v.Query)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Check(pkg) > 0 {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "invalid query",
+			Err:  ast.GetError(pkg),
+		}
+	}
+
+	body := pkg.Files[0].Body
+	if len(body) == 0 {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "query is required",
+		}
+	}
+	if _, ok := body[len(body)-1].(*ast.ExpressionStatement); !ok {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "the last statement of the query must be an expression of the tables to materialize",
+		}
+	}
+
+	windowed := false
+	ast.Walk(ast.CreateVisitor(func(n ast.Node) {
+		if m, ok := n.(*ast.MemberExpression); ok {
+			id, ok := m.Object.(*ast.Identifier)
+			if ok && id.Name == "v" && propertyName(m.Property) == "timeRangeStart" {
+				windowed = true
+			}
+		}
+	}), pkg)
+	if !windowed {
+		return nil, &Error{
+			Code: EInvalid,
+			Msg:  "the query must read the window it is run for from v.timeRangeStart",
+		}
+	}
+	return pkg, nil
+}
+
+func propertyName(key ast.PropertyKey) string {
+	switch k := key.(type) {
+	case *ast.Identifier:
+		return k.Name
+	case *ast.StringLiteral:
+		return k.Value
+	}
+	return ""
+}
+
+// WindowQuery returns the query materializing the window from start to stop:
+// the query of the view, run with v.timeRangeStart and v.timeRangeStop set
+// to the window, with the tables of its last statement written to the bucket
+// of the view.
+func (v *MaterializedView) WindowQuery(start, stop time.Time) (*ast.Package, error) {
+	pkg, err := v.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	file := pkg.Files[0]
+	last := file.Body[len(file.Body)-1].(*ast.ExpressionStatement)
+	last.Expression = &ast.PipeExpression{
+		Argument: last.Expression,
+		Call: &ast.CallExpression{
+			Callee: &ast.Identifier{Name: "to"},
+			Arguments: []ast.Expression{&ast.ObjectExpression{
+				Properties: []*ast.Property{
+					{Key: &ast.Identifier{Name: "bucketID"}, Value: &ast.StringLiteral{Value: v.BucketID.String()}},
+					{Key: &ast.Identifier{Name: "orgID"}, Value: &ast.StringLiteral{Value: v.OrgID.String()}},
+				},
+			}},
+		},
+	}
+
+	window := &ast.OptionStatement{
+		Assignment: &ast.VariableAssignment{
+			ID: &ast.Identifier{Name: "v"},
+			Init: &ast.ObjectExpression{
+				Properties: []*ast.Property{
+					{Key: &ast.Identifier{Name: "timeRangeStart"}, Value: &ast.DateTimeLiteral{Value: start.UTC()}},
+					{Key: &ast.Identifier{Name: "timeRangeStop"}, Value: &ast.DateTimeLiteral{Value: stop.UTC()}},
+				},
+			},
+		},
+	}
+	file.Body = append([]ast.Statement{window}, file.Body...)
+	return pkg, nil
+}
+
+// NextWindow returns the next window of the view to materialize at now, if
+// it has ended and its offset has passed.
+func (v *MaterializedView) NextWindow(now time.Time) (start, stop time.Time, ok bool) {
+	start = v.LatestCompleted
+	stop = start.Add(v.Every.Duration)
+	if stop.Add(v.Offset.Duration).After(now) {
+		return start, stop, false
+	}
+	return start, stop, true
+}
+
+// MaterializedViewFilter represents a set of filters that restrict the
+// returned materialized views.
+type MaterializedViewFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
+
+// MaterializedViewUpdate is the changeset of a materialized view.
+type MaterializedViewUpdate struct {
+	Name            *string    `json:"name,omitempty"`
+	Description     *string    `json:"description,omitempty"`
+	Query           *string    `json:"query,omitempty"`
+	Every           *Duration  `json:"every,omitempty"`
+	Offset          *Duration  `json:"offset,omitempty"`
+	LatestCompleted *time.Time `json:"latestCompleted,omitempty"`
+
+	// LastError is set by the materializer of the view, and cannot be
+	// updated by users.
+	LastError *string `json:"-"`
+}
+
+// Apply applies the changeset to v.
+func (u MaterializedViewUpdate) Apply(v *MaterializedView) {
+	if u.Name != nil {
+		v.Name = *u.Name
+	}
+	if u.Description != nil {
+		v.Description = *u.Description
+	}
+	if u.Query != nil {
+		v.Query = *u.Query
+	}
+	if u.Every != nil {
+		v.Every = *u.Every
+	}
+	if u.Offset != nil {
+		v.Offset = *u.Offset
+	}
+	if u.LatestCompleted != nil {
+		v.LatestCompleted = *u.LatestCompleted
+	}
+	if u.LastError != nil {
+		v.LastError = *u.LastError
+	}
+}
@@ -0,0 +1,210 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// Schema types of buckets.
+const (
+	// SchemaTypeImplicit is the schema type of a bucket whose measurements,
+	// tag keys and field types are defined by the points written to it.
+	SchemaTypeImplicit = "implicit"
+	// SchemaTypeExplicit is the schema type of a bucket whose measurements,
+	// tag keys and field types are declared up front by measurement schemas.
+	// Points that do not conform to them are rejected.
+	SchemaTypeExplicit = "explicit"
+)
+
+// ErrMeasurementSchemaNotFound is the error msg for a missing measurement schema.
+const ErrMeasurementSchemaNotFound = "measurement schema not found"
+
+// ops for measurement schema error.
+const (
+	OpFindMeasurementSchemaByID = "FindMeasurementSchemaByID"
+	OpFindMeasurementSchemas    = "FindMeasurementSchemas"
+	OpCreateMeasurementSchema   = "CreateMeasurementSchema"
+	OpUpdateMeasurementSchema   = "UpdateMeasurementSchema"
+)
+
+// MeasurementSchemaService describes a service for managing the measurement
+// schemas of buckets with an explicit schema.
+type MeasurementSchemaService interface {
+	// FindMeasurementSchemaByID returns a single measurement schema by ID.
+	FindMeasurementSchemaByID(ctx context.Context, id ID) (*MeasurementSchema, error)
+
+	// FindMeasurementSchemas returns the measurement schemas that match filter.
+	FindMeasurementSchemas(ctx context.Context, filter MeasurementSchemaFilter) ([]*MeasurementSchema, error)
+
+	// CreateMeasurementSchema creates a new measurement schema and sets s.ID
+	// with the new identifier.
+	CreateMeasurementSchema(ctx context.Context, s *MeasurementSchema) error
+
+	// UpdateMeasurementSchema updates the columns of a single measurement
+	// schema. Columns may be added, but not removed or changed.
+	UpdateMeasurementSchema(ctx context.Context, id ID, upd MeasurementSchemaUpdate) (*MeasurementSchema, error)
+}
+
+// MeasurementSchema declares the tag keys and field types of a measurement of
+// a bucket with an explicit schema.
+type MeasurementSchema struct {
+	ID       ID                        `json:"id,omitempty"`
+	OrgID    ID                        `json:"orgID,omitempty"`
+	BucketID ID                        `json:"bucketID,omitempty"`
+	Name     string                    `json:"name"`
+	Columns  []MeasurementSchemaColumn `json:"columns"`
+	CRUDLog
+}
+
+// MeasurementSchemaColumn is a tag or field of a measurement schema. Fields
+// have the data type of their values.
+type MeasurementSchemaColumn struct {
+	Name     string           `json:"name"`
+	Type     SchemaColumnType `json:"type"`
+	DataType SchemaDataType   `json:"dataType,omitempty"`
+}
+
+// SchemaColumnType is the type of a column of a measurement schema.
+type SchemaColumnType string
+
+// Column types of measurement schemas.
+const (
+	SchemaColumnTypeTag   SchemaColumnType = "tag"
+	SchemaColumnTypeField SchemaColumnType = "field"
+)
+
+// SchemaDataType is the data type of the values of a field of a measurement
+// schema.
+type SchemaDataType string
+
+// Data types of the fields of measurement schemas.
+const (
+	SchemaDataTypeFloat    SchemaDataType = "float"
+	SchemaDataTypeInteger  SchemaDataType = "integer"
+	SchemaDataTypeUnsigned SchemaDataType = "unsigned"
+	SchemaDataTypeString   SchemaDataType = "string"
+	SchemaDataTypeBoolean  SchemaDataType = "boolean"
+)
+
+// Valid returns true if t is a known data type.
+func (t SchemaDataType) Valid() bool {
+	switch t {
+	case SchemaDataTypeFloat, SchemaDataTypeInteger, SchemaDataTypeUnsigned, SchemaDataTypeString, SchemaDataTypeBoolean:
+		return true
+	}
+	return false
+}
+
+// MeasurementSchemaFilter represents a set of filters that restrict the
+// returned measurement schemas.
+type MeasurementSchemaFilter struct {
+	ID       *ID
+	BucketID *ID
+	Name     *string
+}
+
+// MeasurementSchemaUpdate represents updates to a measurement schema. Columns
+// replaces the columns of the schema, and must hold all of its existing
+// columns unchanged.
+type MeasurementSchemaUpdate struct {
+	Columns []MeasurementSchemaColumn `json:"columns"`
+}
+
+// Valid returns an error if the measurement schema has no name, no field,
+// duplicate columns or columns of an unknown type.
+func (s *MeasurementSchema) Valid() error {
+	if s.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "measurement schema must have a name",
+		}
+	}
+	return validSchemaColumns(s.Name, s.Columns)
+}
+
+func validSchemaColumns(name string, columns []MeasurementSchemaColumn) error {
+	var fields int
+	seen := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		if c.Name == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("column of measurement %q must have a name", name),
+			}
+		} else if c.Name == "time" || c.Name == "_measurement" || c.Name == "_field" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("column name %q of measurement %q is reserved", c.Name, name),
+			}
+		}
+		if _, ok := seen[c.Name]; ok {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("duplicate column %q of measurement %q", c.Name, name),
+			}
+		}
+		seen[c.Name] = struct{}{}
+
+		switch c.Type {
+		case SchemaColumnTypeTag:
+			if c.DataType != "" {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("tag %q of measurement %q must not have a data type", c.Name, name),
+				}
+			}
+		case SchemaColumnTypeField:
+			if !c.DataType.Valid() {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("invalid data type %q of field %q of measurement %q: must be one of float, integer, unsigned, string or boolean", c.DataType, c.Name, name),
+				}
+			}
+			fields++
+		default:
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid type %q of column %q of measurement %q: must be tag or field", c.Type, c.Name, name),
+			}
+		}
+	}
+
+	if fields == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("measurement schema %q must have a field", name),
+		}
+	}
+	return nil
+}
+
+// Column returns the column of the schema with name, or nil if it has none.
+func (s *MeasurementSchema) Column(name string) *MeasurementSchemaColumn {
+	for i := range s.Columns {
+		if s.Columns[i].Name == name {
+			return &s.Columns[i]
+		}
+	}
+	return nil
+}
+
+// Apply applies the update to the measurement schema, returning an error if
+// the update is invalid or removes or changes an existing column.
+func (u MeasurementSchemaUpdate) Apply(s *MeasurementSchema) error {
+	if err := validSchemaColumns(s.Name, u.Columns); err != nil {
+		return err
+	}
+
+	updated := &MeasurementSchema{Name: s.Name, Columns: u.Columns}
+	for _, c := range s.Columns {
+		if uc := updated.Column(c.Name); uc == nil || *uc != c {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("column %q of measurement %q may not be removed or changed", c.Name, s.Name),
+			}
+		}
+	}
+
+	s.Columns = u.Columns
+	return nil
+}
@@ -0,0 +1,67 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// MetadataStoreStats describes the file of the metadata store.
+type MetadataStoreStats struct {
+	Path string `json:"path"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// FreePageRatio is the ratio of the pages of the file which are free, and
+	// would be reclaimed by compacting it.
+	FreePageRatio float64 `json:"freePageRatio"`
+}
+
+// MetadataCompaction is the result of compacting the metadata store.
+type MetadataCompaction struct {
+	Before   MetadataStoreStats `json:"before"`
+	After    MetadataStoreStats `json:"after"`
+	Duration time.Duration      `json:"duration"`
+}
+
+// MetadataStoreService reports on and compacts the file of the metadata store.
+type MetadataStoreService interface {
+	// MetadataStoreStats returns the size and free space of the file.
+	MetadataStoreStats(ctx context.Context) (*MetadataStoreStats, error)
+
+	// CompactMetadataStore rewrites the file without its free pages and
+	// swaps it for the file in use. Reads and writes of the metadata store
+	// wait until it completes.
+	CompactMetadataStore(ctx context.Context) (*MetadataCompaction, error)
+}
+
+// MetadataBundleVersion is the version of the format of the metadata bundles
+// exported by this version of influxdb.
+const MetadataBundleVersion = 1
+
+// MetadataBundle is a portable copy of the metadata of an instance, which is
+// imported into another instance to migrate between metadata stores or to
+// restore one. The views of the cells of dashboards are kept in their cells.
+//
+// The passwords of users are not part of a bundle; they are set again after
+// the bundle is imported.
+type MetadataBundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exportedAt"`
+
+	Organizations        []*Organization        `json:"orgs"`
+	Users                []*User                `json:"users"`
+	Buckets              []*Bucket              `json:"buckets"`
+	Authorizations       []*Authorization       `json:"authorizations"`
+	Dashboards           []*Dashboard           `json:"dashboards"`
+	Tasks                []*Task                `json:"tasks"`
+	UserResourceMappings []*UserResourceMapping `json:"userResourceMappings"`
+}
+
+// MetadataBundleService exports and imports the metadata of an instance.
+type MetadataBundleService interface {
+	// ExportMetadata returns a bundle of all of the metadata.
+	ExportMetadata(ctx context.Context) (*MetadataBundle, error)
+
+	// ImportMetadata puts the resources of a bundle, keeping their IDs. It
+	// is only allowed while the metadata store has no organizations.
+	ImportMetadata(ctx context.Context, b *MetadataBundle) error
+}
@@ -0,0 +1,36 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.AuditLogService = (*AuditLogService)(nil)
+
+// AuditLogService is a mock implementation of a influxdb.AuditLogService.
+type AuditLogService struct {
+	RecordAuditEventFn func(context.Context, *influxdb.AuditEvent) error
+	FindAuditEventsFn  func(context.Context, influxdb.AuditEventFilter, ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error)
+}
+
+// NewAuditLogService returns a mock AuditLogService where its methods will
+// return zero values.
+func NewAuditLogService() *AuditLogService {
+	return &AuditLogService{
+		RecordAuditEventFn: func(context.Context, *influxdb.AuditEvent) error { return nil },
+		FindAuditEventsFn: func(context.Context, influxdb.AuditEventFilter, ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+			return nil, 0, nil
+		},
+	}
+}
+
+// RecordAuditEvent appends an event to the audit log.
+func (s *AuditLogService) RecordAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	return s.RecordAuditEventFn(ctx, e)
+}
+
+// FindAuditEvents returns the events of the audit log that match filter.
+func (s *AuditLogService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	return s.FindAuditEventsFn(ctx, filter, opt...)
+}
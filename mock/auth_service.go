@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 )
@@ -67,3 +68,40 @@ func (s *AuthorizationService) DeleteAuthorization(ctx context.Context, id platf
 func (s *AuthorizationService) UpdateAuthorization(ctx context.Context, id platform.ID, upd *platform.AuthorizationUpdate) (*platform.Authorization, error) {
 	return s.UpdateAuthorizationFn(ctx, id, upd)
 }
+
+var _ platform.AuthorizationLifecycleService = (*AuthorizationLifecycleService)(nil)
+
+// AuthorizationLifecycleService is a mock implementation of a
+// platform.AuthorizationLifecycleService.
+type AuthorizationLifecycleService struct {
+	RotateAuthorizationFn     func(context.Context, platform.ID, time.Duration) (*platform.Authorization, error)
+	TouchAuthorizationFn      func(context.Context, platform.ID, time.Time) error
+	FlagStaleAuthorizationsFn func(context.Context, time.Time) (int, error)
+}
+
+// NewAuthorizationLifecycleService returns a mock AuthorizationLifecycleService
+// where its methods will return zero values.
+func NewAuthorizationLifecycleService() *AuthorizationLifecycleService {
+	return &AuthorizationLifecycleService{
+		RotateAuthorizationFn: func(context.Context, platform.ID, time.Duration) (*platform.Authorization, error) {
+			return nil, nil
+		},
+		TouchAuthorizationFn:      func(context.Context, platform.ID, time.Time) error { return nil },
+		FlagStaleAuthorizationsFn: func(context.Context, time.Time) (int, error) { return 0, nil },
+	}
+}
+
+// RotateAuthorization issues a new token for an authorization.
+func (s *AuthorizationLifecycleService) RotateAuthorization(ctx context.Context, id platform.ID, grace time.Duration) (*platform.Authorization, error) {
+	return s.RotateAuthorizationFn(ctx, id, grace)
+}
+
+// TouchAuthorization records that an authorization was used at t.
+func (s *AuthorizationLifecycleService) TouchAuthorization(ctx context.Context, id platform.ID, t time.Time) error {
+	return s.TouchAuthorizationFn(ctx, id, t)
+}
+
+// FlagStaleAuthorizations flags the authorizations not used since before.
+func (s *AuthorizationLifecycleService) FlagStaleAuthorizations(ctx context.Context, before time.Time) (int, error) {
+	return s.FlagStaleAuthorizationsFn(ctx, before)
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"io"
 	"time"
 
 	platform "github.com/influxdata/influxdb"
@@ -104,3 +105,55 @@ func (s *BucketService) DeleteBucket(ctx context.Context, id platform.ID) error
 	defer s.DeleteBucketCalls.IncrFn()()
 	return s.DeleteBucketFn(ctx, id)
 }
+
+// BucketRestoreService is a mock implementation of a platform.BucketRestoreService.
+type BucketRestoreService struct {
+	FindDeletedBucketByIDFn func(context.Context, platform.ID) (*platform.Bucket, error)
+	RestoreBucketFn         func(context.Context, platform.ID) (*platform.Bucket, error)
+}
+
+// FindDeletedBucketByID returns a deleted bucket by ID.
+func (s *BucketRestoreService) FindDeletedBucketByID(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+	return s.FindDeletedBucketByIDFn(ctx, id)
+}
+
+// RestoreBucket restores a deleted bucket by ID.
+func (s *BucketRestoreService) RestoreBucket(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+	return s.RestoreBucketFn(ctx, id)
+}
+
+// BucketTransferService is a mock implementation of a platform.BucketTransferService.
+type BucketTransferService struct {
+	RenameBucketFn   func(context.Context, platform.ID, string) (*platform.Bucket, error)
+	TransferBucketFn func(context.Context, platform.ID, platform.ID) (*platform.Bucket, error)
+}
+
+// RenameBucket gives a bucket another name.
+func (s *BucketTransferService) RenameBucket(ctx context.Context, id platform.ID, name string) (*platform.Bucket, error) {
+	return s.RenameBucketFn(ctx, id, name)
+}
+
+// TransferBucket moves a bucket to another organization.
+func (s *BucketTransferService) TransferBucket(ctx context.Context, id, orgID platform.ID) (*platform.Bucket, error) {
+	return s.TransferBucketFn(ctx, id, orgID)
+}
+
+// BucketUsageService is a mock implementation of a platform.BucketUsageService.
+type BucketUsageService struct {
+	FindBucketUsageFn func(context.Context, platform.ID) (*platform.BucketUsage, error)
+}
+
+// FindBucketUsage returns the storage used by a bucket.
+func (s *BucketUsageService) FindBucketUsage(ctx context.Context, id platform.ID) (*platform.BucketUsage, error) {
+	return s.FindBucketUsageFn(ctx, id)
+}
+
+// BucketExportService is a mock implementation of a platform.BucketExportService.
+type BucketExportService struct {
+	ExportBucketFn func(context.Context, platform.ID, int64, int64, string, io.Writer) error
+}
+
+// ExportBucket writes the points of a bucket to w.
+func (s *BucketExportService) ExportBucket(ctx context.Context, id platform.ID, start, stop int64, cursor string, w io.Writer) error {
+	return s.ExportBucketFn(ctx, id, start, stop, cursor, w)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CardinalityService = &CardinalityService{}
+
+// CardinalityService is a mock cardinality service.
+type CardinalityService struct {
+	FindMeasurementCardinalityF func(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error)
+}
+
+// NewCardinalityService returns a mock CardinalityService where its methods
+// will return zero values.
+func NewCardinalityService() *CardinalityService {
+	return &CardinalityService{
+		FindMeasurementCardinalityF: func(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindMeasurementCardinality calls FindMeasurementCardinalityF.
+func (s *CardinalityService) FindMeasurementCardinality(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+	return s.FindMeasurementCardinalityF(ctx, filter)
+}
@@ -0,0 +1,58 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.CompactionService = &CompactionService{}
+
+// CompactionService is a mock compaction service.
+type CompactionService struct {
+	CompactionSettingsF       func(ctx context.Context) (*influxdb.CompactionSettings, error)
+	UpdateCompactionSettingsF func(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error)
+	CompactBucketF            func(ctx context.Context, orgID, bucketID influxdb.ID) error
+	TombstoneStatsF           func(ctx context.Context) (*influxdb.TombstoneStats, error)
+}
+
+// NewCompactionService returns a mock CompactionService where its methods
+// will return the zero settings and stats and compacting succeeds.
+func NewCompactionService() *CompactionService {
+	return &CompactionService{
+		CompactionSettingsF: func(ctx context.Context) (*influxdb.CompactionSettings, error) {
+			return &influxdb.CompactionSettings{}, nil
+		},
+		UpdateCompactionSettingsF: func(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+			s := &influxdb.CompactionSettings{}
+			upd.Apply(s)
+			return s, nil
+		},
+		CompactBucketF: func(ctx context.Context, orgID, bucketID influxdb.ID) error {
+			return nil
+		},
+		TombstoneStatsF: func(ctx context.Context) (*influxdb.TombstoneStats, error) {
+			return &influxdb.TombstoneStats{}, nil
+		},
+	}
+}
+
+// CompactionSettings calls CompactionSettingsF.
+func (s *CompactionService) CompactionSettings(ctx context.Context) (*influxdb.CompactionSettings, error) {
+	return s.CompactionSettingsF(ctx)
+}
+
+// UpdateCompactionSettings calls UpdateCompactionSettingsF.
+func (s *CompactionService) UpdateCompactionSettings(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+	return s.UpdateCompactionSettingsF(ctx, upd)
+}
+
+// CompactBucket calls CompactBucketF.
+func (s *CompactionService) CompactBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	return s.CompactBucketF(ctx, orgID, bucketID)
+}
+
+// TombstoneStats calls TombstoneStatsF.
+func (s *CompactionService) TombstoneStats(ctx context.Context) (*influxdb.TombstoneStats, error) {
+	return s.TombstoneStatsF(ctx)
+}
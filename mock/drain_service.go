@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.DrainService = &DrainService{}
+
+// DrainService is a mock drain service.
+type DrainService struct {
+	DrainF       func(ctx context.Context, timeout time.Duration) (*influxdb.DrainStatus, error)
+	ResumeF      func(ctx context.Context) (*influxdb.DrainStatus, error)
+	DrainStatusF func(ctx context.Context) (*influxdb.DrainStatus, error)
+}
+
+// NewDrainService returns a mock DrainService where its methods will return
+// an active status.
+func NewDrainService() *DrainService {
+	active := func(ctx context.Context) (*influxdb.DrainStatus, error) {
+		return &influxdb.DrainStatus{State: influxdb.DrainStateActive}, nil
+	}
+	return &DrainService{
+		DrainF: func(ctx context.Context, timeout time.Duration) (*influxdb.DrainStatus, error) {
+			return active(ctx)
+		},
+		ResumeF:      active,
+		DrainStatusF: active,
+	}
+}
+
+// Drain calls DrainF.
+func (s *DrainService) Drain(ctx context.Context, timeout time.Duration) (*influxdb.DrainStatus, error) {
+	return s.DrainF(ctx, timeout)
+}
+
+// Resume calls ResumeF.
+func (s *DrainService) Resume(ctx context.Context) (*influxdb.DrainStatus, error) {
+	return s.ResumeF(ctx)
+}
+
+// DrainStatus calls DrainStatusF.
+func (s *DrainService) DrainStatus(ctx context.Context) (*influxdb.DrainStatus, error) {
+	return s.DrainStatusF(ctx)
+}
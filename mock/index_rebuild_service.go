@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.IndexRebuildService = &IndexRebuildService{}
+
+// IndexRebuildService is a mock index rebuild service.
+type IndexRebuildService struct {
+	RebuildIndexF         func(ctx context.Context, r *influxdb.IndexRebuild) error
+	FindIndexRebuildByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.IndexRebuild, error)
+	FindIndexRebuildsF    func(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error)
+}
+
+// NewIndexRebuildService returns a mock IndexRebuildService where its methods
+// will return zero values.
+func NewIndexRebuildService() *IndexRebuildService {
+	return &IndexRebuildService{
+		RebuildIndexF: func(ctx context.Context, r *influxdb.IndexRebuild) error {
+			return nil
+		},
+		FindIndexRebuildByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.IndexRebuild, error) {
+			return nil, nil
+		},
+		FindIndexRebuildsF: func(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error) {
+			return nil, nil
+		},
+	}
+}
+
+// RebuildIndex calls RebuildIndexF.
+func (s *IndexRebuildService) RebuildIndex(ctx context.Context, r *influxdb.IndexRebuild) error {
+	return s.RebuildIndexF(ctx, r)
+}
+
+// FindIndexRebuildByID calls FindIndexRebuildByIDF.
+func (s *IndexRebuildService) FindIndexRebuildByID(ctx context.Context, id influxdb.ID) (*influxdb.IndexRebuild, error) {
+	return s.FindIndexRebuildByIDF(ctx, id)
+}
+
+// FindIndexRebuilds calls FindIndexRebuildsF.
+func (s *IndexRebuildService) FindIndexRebuilds(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error) {
+	return s.FindIndexRebuildsF(ctx, filter)
+}
@@ -14,6 +14,7 @@ type Store struct {
 	ViewFn   func(func(kv.Tx) error) error
 	UpdateFn func(func(kv.Tx) error) error
 	BackupFn func(ctx context.Context, w io.Writer) error
+	WatchFn  func(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error)
 }
 
 // View opens up a transaction that will not write to any data. Implementing interfaces
@@ -31,6 +32,11 @@ func (s *Store) Backup(ctx context.Context, w io.Writer) error {
 	return s.BackupFn(ctx, w)
 }
 
+// Watch returns a channel of the changes committed to the keys of bucket with prefix.
+func (s *Store) Watch(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error) {
+	return s.WatchFn(ctx, bucket, prefix)
+}
+
 var _ (kv.Tx) = (*Tx)(nil)
 
 // Tx is mock of a kv.Tx.
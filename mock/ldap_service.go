@@ -0,0 +1,65 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var (
+	_ platform.LDAPService     = (*LDAPService)(nil)
+	_ platform.LDAPSyncService = (*LDAPSyncService)(nil)
+)
+
+// LDAPService is a mock implementation of platform.LDAPService.
+type LDAPService struct {
+	FindLDAPConfigFn       func(ctx context.Context) (*platform.LDAPConfig, error)
+	PutLDAPConfigFn        func(ctx context.Context, c *platform.LDAPConfig) error
+	FindLDAPMembershipsFn  func(ctx context.Context) ([]*platform.LDAPMembership, error)
+	PutLDAPMembershipFn    func(ctx context.Context, m *platform.LDAPMembership) error
+	DeleteLDAPMembershipFn func(ctx context.Context, orgID, userID platform.ID) error
+}
+
+// NewLDAPService returns a mock LDAP service whose methods do nothing.
+func NewLDAPService() *LDAPService {
+	return &LDAPService{
+		FindLDAPConfigFn: func(ctx context.Context) (*platform.LDAPConfig, error) {
+			return &platform.LDAPConfig{}, nil
+		},
+		PutLDAPConfigFn: func(ctx context.Context, c *platform.LDAPConfig) error { return nil },
+		FindLDAPMembershipsFn: func(ctx context.Context) ([]*platform.LDAPMembership, error) {
+			return nil, nil
+		},
+		PutLDAPMembershipFn:    func(ctx context.Context, m *platform.LDAPMembership) error { return nil },
+		DeleteLDAPMembershipFn: func(ctx context.Context, orgID, userID platform.ID) error { return nil },
+	}
+}
+
+func (s *LDAPService) FindLDAPConfig(ctx context.Context) (*platform.LDAPConfig, error) {
+	return s.FindLDAPConfigFn(ctx)
+}
+
+func (s *LDAPService) PutLDAPConfig(ctx context.Context, c *platform.LDAPConfig) error {
+	return s.PutLDAPConfigFn(ctx, c)
+}
+
+func (s *LDAPService) FindLDAPMemberships(ctx context.Context) ([]*platform.LDAPMembership, error) {
+	return s.FindLDAPMembershipsFn(ctx)
+}
+
+func (s *LDAPService) PutLDAPMembership(ctx context.Context, m *platform.LDAPMembership) error {
+	return s.PutLDAPMembershipFn(ctx, m)
+}
+
+func (s *LDAPService) DeleteLDAPMembership(ctx context.Context, orgID, userID platform.ID) error {
+	return s.DeleteLDAPMembershipFn(ctx, orgID, userID)
+}
+
+// LDAPSyncService is a mock implementation of platform.LDAPSyncService.
+type LDAPSyncService struct {
+	SyncLDAPFn func(ctx context.Context, dryRun bool) (*platform.LDAPSyncReport, error)
+}
+
+func (s *LDAPSyncService) SyncLDAP(ctx context.Context, dryRun bool) (*platform.LDAPSyncReport, error) {
+	return s.SyncLDAPFn(ctx, dryRun)
+}
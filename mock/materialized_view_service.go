@@ -0,0 +1,57 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.MaterializedViewService = (*MaterializedViewService)(nil)
+
+// MaterializedViewService is a mock implementation of
+// platform.MaterializedViewService.
+type MaterializedViewService struct {
+	FindMaterializedViewByIDFn func(ctx context.Context, id platform.ID) (*platform.MaterializedView, error)
+	FindMaterializedViewsFn    func(ctx context.Context, filter platform.MaterializedViewFilter, opt ...platform.FindOptions) ([]*platform.MaterializedView, int, error)
+	CreateMaterializedViewFn   func(ctx context.Context, v *platform.MaterializedView) error
+	UpdateMaterializedViewFn   func(ctx context.Context, id platform.ID, upd platform.MaterializedViewUpdate) (*platform.MaterializedView, error)
+	DeleteMaterializedViewFn   func(ctx context.Context, id platform.ID) error
+}
+
+// NewMaterializedViewService returns a mock materialized view service whose
+// methods do nothing.
+func NewMaterializedViewService() *MaterializedViewService {
+	return &MaterializedViewService{
+		FindMaterializedViewByIDFn: func(ctx context.Context, id platform.ID) (*platform.MaterializedView, error) {
+			return nil, nil
+		},
+		FindMaterializedViewsFn: func(ctx context.Context, filter platform.MaterializedViewFilter, opt ...platform.FindOptions) ([]*platform.MaterializedView, int, error) {
+			return nil, 0, nil
+		},
+		CreateMaterializedViewFn: func(ctx context.Context, v *platform.MaterializedView) error { return nil },
+		UpdateMaterializedViewFn: func(ctx context.Context, id platform.ID, upd platform.MaterializedViewUpdate) (*platform.MaterializedView, error) {
+			return nil, nil
+		},
+		DeleteMaterializedViewFn: func(ctx context.Context, id platform.ID) error { return nil },
+	}
+}
+
+func (s *MaterializedViewService) FindMaterializedViewByID(ctx context.Context, id platform.ID) (*platform.MaterializedView, error) {
+	return s.FindMaterializedViewByIDFn(ctx, id)
+}
+
+func (s *MaterializedViewService) FindMaterializedViews(ctx context.Context, filter platform.MaterializedViewFilter, opt ...platform.FindOptions) ([]*platform.MaterializedView, int, error) {
+	return s.FindMaterializedViewsFn(ctx, filter, opt...)
+}
+
+func (s *MaterializedViewService) CreateMaterializedView(ctx context.Context, v *platform.MaterializedView) error {
+	return s.CreateMaterializedViewFn(ctx, v)
+}
+
+func (s *MaterializedViewService) UpdateMaterializedView(ctx context.Context, id platform.ID, upd platform.MaterializedViewUpdate) (*platform.MaterializedView, error) {
+	return s.UpdateMaterializedViewFn(ctx, id, upd)
+}
+
+func (s *MaterializedViewService) DeleteMaterializedView(ctx context.Context, id platform.ID) error {
+	return s.DeleteMaterializedViewFn(ctx, id)
+}
@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.MetadataStoreService = &MetadataStoreService{}
+
+// MetadataStoreService is a mock metadata store service.
+type MetadataStoreService struct {
+	MetadataStoreStatsF   func(ctx context.Context) (*influxdb.MetadataStoreStats, error)
+	CompactMetadataStoreF func(ctx context.Context) (*influxdb.MetadataCompaction, error)
+}
+
+// MetadataStoreStats calls MetadataStoreStatsF.
+func (s *MetadataStoreService) MetadataStoreStats(ctx context.Context) (*influxdb.MetadataStoreStats, error) {
+	return s.MetadataStoreStatsF(ctx)
+}
+
+// CompactMetadataStore calls CompactMetadataStoreF.
+func (s *MetadataStoreService) CompactMetadataStore(ctx context.Context) (*influxdb.MetadataCompaction, error) {
+	return s.CompactMetadataStoreF(ctx)
+}
+
+var _ influxdb.MetadataBundleService = &MetadataBundleService{}
+
+// MetadataBundleService is a mock metadata bundle service.
+type MetadataBundleService struct {
+	ExportMetadataF func(ctx context.Context) (*influxdb.MetadataBundle, error)
+	ImportMetadataF func(ctx context.Context, b *influxdb.MetadataBundle) error
+}
+
+// ExportMetadata calls ExportMetadataF.
+func (s *MetadataBundleService) ExportMetadata(ctx context.Context) (*influxdb.MetadataBundle, error) {
+	return s.ExportMetadataF(ctx)
+}
+
+// ImportMetadata calls ImportMetadataF.
+func (s *MetadataBundleService) ImportMetadata(ctx context.Context, b *influxdb.MetadataBundle) error {
+	return s.ImportMetadataF(ctx, b)
+}
@@ -0,0 +1,48 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.NetworkPolicyService = &NetworkPolicyService{}
+
+// NetworkPolicyService is a mock network policy service.
+type NetworkPolicyService struct {
+	FindNetworkPolicyF   func(ctx context.Context, orgID influxdb.ID) (*influxdb.NetworkPolicy, error)
+	SetNetworkPolicyF    func(ctx context.Context, p *influxdb.NetworkPolicy) error
+	DeleteNetworkPolicyF func(ctx context.Context, orgID influxdb.ID) error
+}
+
+// NewNetworkPolicyService returns a mock NetworkPolicyService where its
+// methods will return policies allowing every address and changing policies
+// succeeds.
+func NewNetworkPolicyService() *NetworkPolicyService {
+	return &NetworkPolicyService{
+		FindNetworkPolicyF: func(ctx context.Context, orgID influxdb.ID) (*influxdb.NetworkPolicy, error) {
+			return &influxdb.NetworkPolicy{OrgID: orgID}, nil
+		},
+		SetNetworkPolicyF: func(ctx context.Context, p *influxdb.NetworkPolicy) error {
+			return nil
+		},
+		DeleteNetworkPolicyF: func(ctx context.Context, orgID influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindNetworkPolicy calls FindNetworkPolicyF.
+func (s *NetworkPolicyService) FindNetworkPolicy(ctx context.Context, orgID influxdb.ID) (*influxdb.NetworkPolicy, error) {
+	return s.FindNetworkPolicyF(ctx, orgID)
+}
+
+// SetNetworkPolicy calls SetNetworkPolicyF.
+func (s *NetworkPolicyService) SetNetworkPolicy(ctx context.Context, p *influxdb.NetworkPolicy) error {
+	return s.SetNetworkPolicyF(ctx, p)
+}
+
+// DeleteNetworkPolicy calls DeleteNetworkPolicyF.
+func (s *NetworkPolicyService) DeleteNetworkPolicy(ctx context.Context, orgID influxdb.ID) error {
+	return s.DeleteNetworkPolicyF(ctx, orgID)
+}
@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.OrgQuotaService = &OrgQuotaService{}
+
+// OrgQuotaService is a mock org quota service.
+type OrgQuotaService struct {
+	FindOrgQuotaF   func(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgQuota, error)
+	FindOrgQuotasF  func(ctx context.Context) ([]*influxdb.OrgQuota, error)
+	SetOrgQuotaF    func(ctx context.Context, q *influxdb.OrgQuota) error
+	DeleteOrgQuotaF func(ctx context.Context, orgID influxdb.ID) error
+}
+
+// NewOrgQuotaService returns a mock OrgQuotaService where its methods will
+// return quotas without limits and changing quotas succeeds.
+func NewOrgQuotaService() *OrgQuotaService {
+	return &OrgQuotaService{
+		FindOrgQuotaF: func(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgQuota, error) {
+			return &influxdb.OrgQuota{OrgID: orgID}, nil
+		},
+		FindOrgQuotasF: func(ctx context.Context) ([]*influxdb.OrgQuota, error) {
+			return nil, nil
+		},
+		SetOrgQuotaF: func(ctx context.Context, q *influxdb.OrgQuota) error {
+			return nil
+		},
+		DeleteOrgQuotaF: func(ctx context.Context, orgID influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindOrgQuota calls FindOrgQuotaF.
+func (s *OrgQuotaService) FindOrgQuota(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgQuota, error) {
+	return s.FindOrgQuotaF(ctx, orgID)
+}
+
+// FindOrgQuotas calls FindOrgQuotasF.
+func (s *OrgQuotaService) FindOrgQuotas(ctx context.Context) ([]*influxdb.OrgQuota, error) {
+	return s.FindOrgQuotasF(ctx)
+}
+
+// SetOrgQuota calls SetOrgQuotaF.
+func (s *OrgQuotaService) SetOrgQuota(ctx context.Context, q *influxdb.OrgQuota) error {
+	return s.SetOrgQuotaF(ctx, q)
+}
+
+// DeleteOrgQuota calls DeleteOrgQuotaF.
+func (s *OrgQuotaService) DeleteOrgQuota(ctx context.Context, orgID influxdb.ID) error {
+	return s.DeleteOrgQuotaF(ctx, orgID)
+}
@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SeriesMoveService = &SeriesMoveService{}
+
+// SeriesMoveService is a mock series move service.
+type SeriesMoveService struct {
+	MoveSeriesF         func(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error
+	FindSeriesMoveByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error)
+	FindSeriesMovesF    func(ctx context.Context, filter influxdb.SeriesMoveFilter) ([]*influxdb.SeriesMove, error)
+}
+
+// NewSeriesMoveService returns a mock SeriesMoveService where its methods
+// will return zero values.
+func NewSeriesMoveService() *SeriesMoveService {
+	return &SeriesMoveService{
+		MoveSeriesF: func(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error {
+			return nil
+		},
+		FindSeriesMoveByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error) {
+			return nil, nil
+		},
+		FindSeriesMovesF: func(ctx context.Context, filter influxdb.SeriesMoveFilter) ([]*influxdb.SeriesMove, error) {
+			return nil, nil
+		},
+	}
+}
+
+// MoveSeries calls MoveSeriesF.
+func (s *SeriesMoveService) MoveSeries(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error {
+	return s.MoveSeriesF(ctx, m, pred)
+}
+
+// FindSeriesMoveByID calls FindSeriesMoveByIDF.
+func (s *SeriesMoveService) FindSeriesMoveByID(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error) {
+	return s.FindSeriesMoveByIDF(ctx, id)
+}
+
+// FindSeriesMoves calls FindSeriesMovesF.
+func (s *SeriesMoveService) FindSeriesMoves(ctx context.Context, filter influxdb.SeriesMoveFilter) ([]*influxdb.SeriesMove, error) {
+	return s.FindSeriesMovesF(ctx, filter)
+}
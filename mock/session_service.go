@@ -49,3 +49,40 @@ func (s *SessionService) ExpireSession(ctx context.Context, key string) error {
 func (s *SessionService) RenewSession(ctx context.Context, session *platform.Session, expiredAt time.Time) error {
 	return s.RenewSessionFn(ctx, session, expiredAt)
 }
+
+var _ platform.SessionManagementService = (*SessionManagementService)(nil)
+
+// SessionManagementService is a mock implementation of a
+// platform.SessionManagementService.
+type SessionManagementService struct {
+	FindSessionsFn   func(context.Context, platform.SessionFilter, ...platform.FindOptions) ([]*platform.Session, int, error)
+	RevokeSessionFn  func(context.Context, platform.ID) error
+	RevokeSessionsFn func(context.Context, platform.ID) error
+}
+
+// NewSessionManagementService returns a mock SessionManagementService where
+// its methods will return zero values.
+func NewSessionManagementService() *SessionManagementService {
+	return &SessionManagementService{
+		FindSessionsFn: func(context.Context, platform.SessionFilter, ...platform.FindOptions) ([]*platform.Session, int, error) {
+			return nil, 0, nil
+		},
+		RevokeSessionFn:  func(context.Context, platform.ID) error { return nil },
+		RevokeSessionsFn: func(context.Context, platform.ID) error { return nil },
+	}
+}
+
+// FindSessions returns the unexpired sessions that match filter.
+func (s *SessionManagementService) FindSessions(ctx context.Context, filter platform.SessionFilter, opt ...platform.FindOptions) ([]*platform.Session, int, error) {
+	return s.FindSessionsFn(ctx, filter, opt...)
+}
+
+// RevokeSession revokes a session.
+func (s *SessionManagementService) RevokeSession(ctx context.Context, id platform.ID) error {
+	return s.RevokeSessionFn(ctx, id)
+}
+
+// RevokeSessions revokes all of the sessions of a user.
+func (s *SessionManagementService) RevokeSessions(ctx context.Context, userID platform.ID) error {
+	return s.RevokeSessionsFn(ctx, userID)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.ShardStatsService = &ShardStatsService{}
+
+// ShardStatsService is a mock shard stats service.
+type ShardStatsService struct {
+	FindShardStatsF func(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error)
+}
+
+// NewShardStatsService returns a mock ShardStatsService where its methods will
+// return zero values.
+func NewShardStatsService() *ShardStatsService {
+	return &ShardStatsService{
+		FindShardStatsF: func(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindShardStats calls FindShardStatsF.
+func (s *ShardStatsService) FindShardStats(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error) {
+	return s.FindShardStatsF(ctx, filter)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SnapshotService = &SnapshotService{}
+
+// SnapshotService is a mock snapshot service.
+type SnapshotService struct {
+	CreateSnapshotF func(ctx context.Context) (*influxdb.SnapshotManifest, error)
+}
+
+// NewSnapshotService returns a mock SnapshotService where its methods will
+// return zero values.
+func NewSnapshotService() *SnapshotService {
+	return &SnapshotService{
+		CreateSnapshotF: func(ctx context.Context) (*influxdb.SnapshotManifest, error) {
+			return nil, nil
+		},
+	}
+}
+
+// CreateSnapshot calls CreateSnapshotF.
+func (s *SnapshotService) CreateSnapshot(ctx context.Context) (*influxdb.SnapshotManifest, error) {
+	return s.CreateSnapshotF(ctx)
+}
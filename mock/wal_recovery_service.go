@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.WALRecoveryService = &WALRecoveryService{}
+
+// WALRecoveryService is a mock WAL recovery service.
+type WALRecoveryService struct {
+	WALRecoveryReportF func(ctx context.Context) (*influxdb.WALRecoveryReport, error)
+}
+
+// NewWALRecoveryService returns a mock WALRecoveryService where its methods
+// will return zero values.
+func NewWALRecoveryService() *WALRecoveryService {
+	return &WALRecoveryService{
+		WALRecoveryReportF: func(ctx context.Context) (*influxdb.WALRecoveryReport, error) {
+			return nil, nil
+		},
+	}
+}
+
+// WALRecoveryReport calls WALRecoveryReportF.
+func (s *WALRecoveryService) WALRecoveryReport(ctx context.Context) (*influxdb.WALRecoveryReport, error) {
+	return s.WALRecoveryReportF(ctx)
+}
@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.WriteStatsService = &WriteStatsService{}
+
+// WriteStatsService is a mock write stats service.
+type WriteStatsService struct {
+	FindMeasurementWriteStatsF func(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error)
+}
+
+// NewWriteStatsService returns a mock WriteStatsService where its methods will
+// return zero values.
+func NewWriteStatsService() *WriteStatsService {
+	return &WriteStatsService{
+		FindMeasurementWriteStatsF: func(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+			return nil, nil
+		},
+	}
+}
+
+// FindMeasurementWriteStats calls FindMeasurementWriteStatsF.
+func (s *WriteStatsService) FindMeasurementWriteStats(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+	return s.FindMeasurementWriteStatsF(ctx, filter)
+}
@@ -29,6 +29,10 @@ var (
 type ParserStats struct {
 	// BytesN reports the number of bytes allocated to parse the request.
 	BytesN int
+
+	// Lines reports the line of the source buffer, counting from one, of each
+	// parsed point.
+	Lines []int
 }
 
 type ParserOption func(*pointsParser)
@@ -92,6 +96,7 @@ type pointsParser struct {
 	defaultTime time.Time // truncated time to assign to points which have no associated timestamp.
 	precision   string
 	points      []Point
+	line        int // line of the source buffer being parsed
 	state       parserState
 	stats       *ParserStats
 }
@@ -127,11 +132,17 @@ func (pp *pointsParser) parsePoints(buf []byte) (err error) {
 	pp.points = make([]Point, 0, lineCount+1)
 
 	var (
-		pos    int
-		block  []byte
-		failed []string
+		pos     int
+		counted int
+		block   []byte
+		failed  []string
 	)
+	pp.line = 1
 	for pos < len(buf) && pp.state == parserStateOK {
+		if pp.stats != nil {
+			pp.line += bytes.Count(buf[counted:pos], []byte{'\n'})
+			counted = pos
+		}
 		pos, block = scanLine(buf, pos)
 		pos++
 
@@ -295,6 +306,9 @@ func (pp *pointsParser) append(p point) error {
 		return errLimit
 	}
 	pp.points = append(pp.points, &p)
+	if pp.stats != nil {
+		pp.stats.Lines = append(pp.stats.Lines, pp.line)
+	}
 	return nil
 }
 
@@ -2515,6 +2515,23 @@ func TestParsePointsWithOptions(t *testing.T) {
 	}
 }
 
+func TestParsePointsWithOptions_Lines(t *testing.T) {
+	buf := []byte("# comment\ncpu value=1,load=2 1\n\nmem text=\"a\nb\" 1\n  disk used=3 1\n")
+	encoded := EncodeName(ID(1000), ID(2000))
+	mm := models.EscapeMeasurement(encoded[:])
+
+	var stats models.ParserStats
+	points, err := models.ParsePointsWithOptions(buf, mm, models.WithParserStats(&stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := stats.Lines, []int{2, 2, 4, 6}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got lines %v, expected %v", got, exp)
+	} else if len(points) != len(exp) {
+		t.Fatalf("got %d points, expected %d", len(points), len(exp))
+	}
+}
+
 func TestNewPointsWithBytesWithCorruptData(t *testing.T) {
 	corrupted := []byte{0, 0, 0, 3, 102, 111, 111, 0, 0, 0, 4, 61, 34, 65, 34, 1, 0, 0, 0, 14, 206, 86, 119, 24, 32, 72, 233, 168, 2, 148}
 	p, err := models.NewPointFromBytes(corrupted)
@@ -0,0 +1,103 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ops for network policies.
+var (
+	OpFindNetworkPolicy   = "FindNetworkPolicy"
+	OpSetNetworkPolicy    = "SetNetworkPolicy"
+	OpDeleteNetworkPolicy = "DeleteNetworkPolicy"
+)
+
+// NetworkPolicy restricts the addresses the tokens of an organization may be
+// used from. Addresses are given in CIDR notation, or as single IP addresses.
+type NetworkPolicy struct {
+	OrgID ID `json:"orgID"`
+
+	// Allow lists the networks the tokens may be used from. If it is empty,
+	// they may be used from any address that is not denied.
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny lists the networks the tokens may not be used from, even if they
+	// are allowed.
+	Deny []string `json:"deny,omitempty"`
+}
+
+// Valid returns an error if an address of the policy cannot be parsed.
+func (p *NetworkPolicy) Valid() error {
+	if !p.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization id must be provided",
+		}
+	}
+	for _, s := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if _, err := parseNetwork(s); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("invalid network %q", s),
+				Err:  err,
+			}
+		}
+	}
+	return nil
+}
+
+// Allows returns true if the policy allows the tokens of the organization to
+// be used from ip. A nil ip, such as that of an address that cannot be
+// parsed, is only allowed by policies without networks. Networks of the
+// policy that cannot be parsed are ignored.
+func (p *NetworkPolicy) Allows(ip net.IP) bool {
+	if ip == nil {
+		return len(p.Allow) == 0 && len(p.Deny) == 0
+	}
+	if containsIP(p.Deny, ip) {
+		return false
+	}
+	return len(p.Allow) == 0 || containsIP(p.Allow, ip)
+}
+
+func containsIP(networks []string, ip net.IP) bool {
+	for _, s := range networks {
+		if n, err := parseNetwork(s); err == nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetwork parses a network in CIDR notation, or a single IP address as
+// the network of that address alone.
+func parseNetwork(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		return n, err
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address: %s", s)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// NetworkPolicyService manages the network policies of organizations.
+type NetworkPolicyService interface {
+	// FindNetworkPolicy returns the network policy of an organization. An
+	// organization without a policy has a policy allowing every address.
+	FindNetworkPolicy(ctx context.Context, orgID ID) (*NetworkPolicy, error)
+
+	// SetNetworkPolicy replaces the network policy of an organization.
+	SetNetworkPolicy(ctx context.Context, p *NetworkPolicy) error
+
+	// DeleteNetworkPolicy removes the network policy of an organization.
+	DeleteNetworkPolicy(ctx context.Context, orgID ID) error
+}
@@ -0,0 +1,70 @@
+package influxdb_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestNetworkPolicy_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy influxdb.NetworkPolicy
+		ip     string
+		want   bool
+	}{
+		{
+			name: "empty policy allows every address",
+			ip:   "192.0.2.1",
+			want: true,
+		},
+		{
+			name:   "allowed network",
+			policy: influxdb.NetworkPolicy{Allow: []string{"192.0.2.0/24"}},
+			ip:     "192.0.2.1",
+			want:   true,
+		},
+		{
+			name:   "address outside of the allowed networks",
+			policy: influxdb.NetworkPolicy{Allow: []string{"192.0.2.0/24", "2001:db8::/32"}},
+			ip:     "198.51.100.1",
+		},
+		{
+			name:   "denied address",
+			policy: influxdb.NetworkPolicy{Deny: []string{"192.0.2.1"}},
+			ip:     "192.0.2.1",
+		},
+		{
+			name:   "deny takes precedence over allow",
+			policy: influxdb.NetworkPolicy{Allow: []string{"192.0.2.0/24"}, Deny: []string{"192.0.2.128/25"}},
+			ip:     "192.0.2.200",
+		},
+		{
+			name:   "allowed ipv6 address",
+			policy: influxdb.NetworkPolicy{Allow: []string{"2001:db8::/32"}},
+			ip:     "2001:db8::1",
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Allows(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNetworkPolicy_Valid(t *testing.T) {
+	p := &influxdb.NetworkPolicy{OrgID: 1, Allow: []string{"192.0.2.0/24", "2001:db8::1"}}
+	if err := p.Valid(); err != nil {
+		t.Fatal(err)
+	}
+
+	p.Deny = []string{"192.0.2"}
+	if err := p.Valid(); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, influxdb.EInvalid)
+	}
+}
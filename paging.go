@@ -1,6 +1,7 @@
 package influxdb
 
 import (
+	"encoding/base64"
 	"strconv"
 )
 
@@ -24,8 +25,12 @@ type PagingLinks struct {
 
 // FindOptions represents options passed to all find methods with multiple results.
 type FindOptions struct {
-	Limit      int
-	Offset     int
+	Limit  int
+	Offset int
+	// After is the continuation token of the previous page of results. When
+	// set, results start after the entry the previous page ended on, and
+	// Offset is ignored.
+	After      string
 	SortBy     string
 	Descending bool
 }
@@ -34,7 +39,12 @@ type FindOptions struct {
 func (f FindOptions) QueryParams() map[string][]string {
 	qp := map[string][]string{
 		"descending": {strconv.FormatBool(f.Descending)},
-		"offset":     {strconv.Itoa(f.Offset)},
+	}
+
+	if f.After != "" {
+		qp["after"] = []string{f.After}
+	} else {
+		qp["offset"] = []string{strconv.Itoa(f.Offset)}
 	}
 
 	if f.Limit > 0 {
@@ -47,3 +57,40 @@ func (f FindOptions) QueryParams() map[string][]string {
 
 	return qp
 }
+
+// AfterID returns the ID of the entry the continuation token After was issued
+// for. It returns an invalid ID if After is not set.
+func (f FindOptions) AfterID() (ID, error) {
+	if f.After == "" {
+		return InvalidID(), nil
+	}
+	return ParsePageToken(f.After)
+}
+
+// PageToken returns the continuation token of a page of results which ended
+// on the entry of id. Entries are listed in the order of their IDs, so a token
+// remains valid as entries are created or deleted.
+func PageToken(id ID) string {
+	b, _ := id.Encode()
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParsePageToken returns the ID of the entry token was issued for.
+func ParsePageToken(token string) (ID, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return InvalidID(), &Error{
+			Code: EInvalid,
+			Msg:  "continuation token is invalid",
+		}
+	}
+
+	var id ID
+	if err := id.Decode(b); err != nil {
+		return InvalidID(), &Error{
+			Code: EInvalid,
+			Msg:  "continuation token is invalid",
+		}
+	}
+	return id, nil
+}
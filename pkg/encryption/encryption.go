@@ -0,0 +1,203 @@
+// Package encryption provides authenticated encryption of data at rest.
+//
+// Data is sealed with AES-GCM using keys supplied by a KeyProvider. Every
+// sealed message records the ID of the key that sealed it, so keys can be
+// rotated by making a new key current while older keys remain available to
+// open existing data.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// KeySize is the size in bytes of the AES-256 keys used to seal data.
+const KeySize = 32
+
+// headerSize is the size of the key ID and nonce prepended to sealed data.
+const headerSize = 4 + 12
+
+var (
+	// ErrKeyNotFound is returned when data was sealed with a key that is not
+	// known to the KeyProvider.
+	ErrKeyNotFound = errors.New("encryption key not found")
+
+	// ErrShortMessage is returned when opening data that is too short to
+	// have been sealed.
+	ErrShortMessage = errors.New("encrypted message too short")
+
+	// ErrCipherInUse is returned when acquiring the default Cipher while
+	// another Cipher is the default.
+	ErrCipherInUse = errors.New("another encryption cipher is in use")
+)
+
+// KeyProvider supplies the keys used to seal and open data.
+type KeyProvider interface {
+	// CurrentKey returns the ID and value of the key used to seal new data.
+	CurrentKey() (uint32, []byte, error)
+
+	// Key returns the value of the key with the given ID. It returns
+	// ErrKeyNotFound if the key is unknown.
+	Key(id uint32) ([]byte, error)
+}
+
+// Cipher seals and opens data using the keys of a KeyProvider. It is safe for
+// concurrent use.
+type Cipher struct {
+	keys KeyProvider
+
+	mu    sync.RWMutex
+	aeads map[uint32]cipher.AEAD
+}
+
+// NewCipher returns a Cipher using the keys of kp.
+func NewCipher(kp KeyProvider) *Cipher {
+	return &Cipher{
+		keys:  kp,
+		aeads: make(map[uint32]cipher.AEAD),
+	}
+}
+
+// CurrentKeyID returns the ID of the key used to seal new data.
+func (c *Cipher) CurrentKeyID() (uint32, error) {
+	id, _, err := c.keys.CurrentKey()
+	return id, err
+}
+
+// Seal encrypts and authenticates plaintext with the current key, appends the
+// result to dst and returns the updated slice.
+func (c *Cipher) Seal(dst, plaintext []byte) ([]byte, error) {
+	id, key, err := c.keys.CurrentKey()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := c.aead(id, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var hdr [headerSize]byte
+	binary.BigEndian.PutUint32(hdr[:4], id)
+	if _, err := io.ReadFull(rand.Reader, hdr[4:]); err != nil {
+		return nil, err
+	}
+
+	dst = append(dst, hdr[:]...)
+	return aead.Seal(dst, hdr[4:], plaintext, hdr[:4]), nil
+}
+
+// Open decrypts and authenticates data sealed by Seal, appends the result to
+// dst and returns the updated slice.
+func (c *Cipher) Open(dst, sealed []byte) ([]byte, error) {
+	id, ok := KeyID(sealed)
+	if !ok {
+		return nil, ErrShortMessage
+	}
+	aead, err := c.aead(id, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < headerSize+aead.Overhead() {
+		return nil, ErrShortMessage
+	}
+	return aead.Open(dst, sealed[4:headerSize], sealed[headerSize:], sealed[:4])
+}
+
+// Overhead returns the number of bytes Seal adds to the plaintext.
+func (c *Cipher) Overhead() int {
+	// AES-GCM always has a 16 byte tag.
+	return headerSize + 16
+}
+
+// aead returns the AEAD for the key with the given ID, creating it from key,
+// or from the KeyProvider if key is nil.
+func (c *Cipher) aead(id uint32, key []byte) (cipher.AEAD, error) {
+	c.mu.RLock()
+	aead := c.aeads[id]
+	c.mu.RUnlock()
+	if aead != nil {
+		return aead, nil
+	}
+
+	if key == nil {
+		var err error
+		if key, err = c.keys.Key(id); err != nil {
+			return nil, fmt.Errorf("key %d: %v", id, err)
+		}
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("key %d: %v", id, err)
+	}
+	aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.aeads[id] = aead
+	c.mu.Unlock()
+	return aead, nil
+}
+
+// KeyID returns the ID of the key that sealed data, and false if data is too
+// short to have been sealed.
+func KeyID(sealed []byte) (uint32, bool) {
+	if len(sealed) < headerSize {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(sealed[:4]), true
+}
+
+var (
+	defaultCipher atomic.Value
+	acquireMu     sync.Mutex
+)
+
+// SetDefault sets the Cipher used to encrypt data written by the storage
+// engine, and to decrypt data it reads. A nil Cipher disables encryption of
+// new data.
+func SetDefault(c *Cipher) {
+	defaultCipher.Store(&c)
+}
+
+// Default returns the Cipher set by SetDefault, or nil if encryption is
+// disabled.
+func Default() *Cipher {
+	if c, ok := defaultCipher.Load().(**Cipher); ok {
+		return *c
+	}
+	return nil
+}
+
+// Acquire makes c the default Cipher until it is released. The default Cipher
+// is shared by every storage engine of the process, so Acquire fails with
+// ErrCipherInUse while another Cipher is acquired.
+func Acquire(c *Cipher) error {
+	acquireMu.Lock()
+	defer acquireMu.Unlock()
+
+	if cur := Default(); cur != nil && cur != c {
+		return ErrCipherInUse
+	}
+	SetDefault(c)
+	return nil
+}
+
+// Release disables encryption if c is the default Cipher.
+func Release(c *Cipher) {
+	acquireMu.Lock()
+	defer acquireMu.Unlock()
+
+	if c != nil && Default() == c {
+		SetDefault(nil)
+	}
+}
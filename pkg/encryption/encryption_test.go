@@ -0,0 +1,116 @@
+package encryption_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+)
+
+func mustKeyRing(t *testing.T, ids ...uint32) *encryption.KeyRing {
+	t.Helper()
+	keys := make(map[uint32][]byte)
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(id)}, encryption.KeySize)
+	}
+	r, err := encryption.NewKeyRing(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	c := encryption.NewCipher(mustKeyRing(t, 1))
+	plaintext := []byte("cpu,host=a value=1")
+
+	sealed, err := c.Seal(nil, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(sealed), len(plaintext)+c.Overhead(); got != want {
+		t.Fatalf("unexpected sealed length: got %d, want %d", got, want)
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Fatal("sealed data contains plaintext")
+	}
+
+	got, err := c.Open(nil, sealed)
+	if err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(got, plaintext) {
+		t.Fatalf("unexpected plaintext: got %q, want %q", got, plaintext)
+	}
+
+	sealed[len(sealed)-1] ^= 1
+	if _, err := c.Open(nil, sealed); err == nil {
+		t.Fatal("expected error opening tampered data")
+	}
+}
+
+func TestCipher_Rotation(t *testing.T) {
+	sealed, err := encryption.NewCipher(mustKeyRing(t, 1)).Seal(nil, []byte("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After rotation, new data is sealed with the new key and old data can
+	// still be opened.
+	c := encryption.NewCipher(mustKeyRing(t, 1, 2))
+	if id, err := c.CurrentKeyID(); err != nil || id != 2 {
+		t.Fatalf("unexpected current key: %d, %v", id, err)
+	}
+	if got, err := c.Open(nil, sealed); err != nil || string(got) != "old" {
+		t.Fatalf("unexpected result opening old data: %q, %v", got, err)
+	}
+	resealed, err := c.Seal(nil, []byte("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := encryption.KeyID(resealed); id != 2 {
+		t.Fatalf("unexpected key id: got %d, want 2", id)
+	}
+
+	// Data sealed with a removed key can not be opened.
+	if _, err := encryption.NewCipher(mustKeyRing(t, 2)).Open(nil, sealed); err == nil {
+		t.Fatal("expected error opening data sealed with a removed key")
+	}
+}
+
+func TestLoadKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "keys")
+	data := "# keys\n1 AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=\n\n7 BwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwc=\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := encryption.LoadKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, key, err := r.CurrentKey()
+	if err != nil {
+		t.Fatal(err)
+	} else if id != 7 || !bytes.Equal(key, bytes.Repeat([]byte{7}, encryption.KeySize)) {
+		t.Fatalf("unexpected current key %d: %x", id, key)
+	}
+	if _, err := r.Key(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("1 AQEB\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := encryption.LoadKeyFile(path); err == nil {
+		t.Fatal("expected error loading a short key")
+	}
+}
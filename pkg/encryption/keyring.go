@@ -0,0 +1,100 @@
+package encryption
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// KeyRing is a KeyProvider holding a fixed set of keys. The key with the
+// highest ID is the current key.
+type KeyRing struct {
+	keys    map[uint32][]byte
+	current uint32
+}
+
+// NewKeyRing returns a KeyRing holding keys, indexed by their ID. It returns
+// an error if there are no keys or any key is not KeySize bytes long.
+func NewKeyRing(keys map[uint32][]byte) (*KeyRing, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no encryption keys")
+	}
+
+	r := &KeyRing{keys: make(map[uint32][]byte, len(keys))}
+	first := true
+	for id, key := range keys {
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("encryption key %d is %d bytes; must be %d", id, len(key), KeySize)
+		}
+		r.keys[id] = append([]byte(nil), key...)
+		if first || id > r.current {
+			r.current, first = id, false
+		}
+	}
+	return r, nil
+}
+
+// CurrentKey returns the key with the highest ID.
+func (r *KeyRing) CurrentKey() (uint32, []byte, error) {
+	return r.current, r.keys[r.current], nil
+}
+
+// Key returns the key with the given ID.
+func (r *KeyRing) Key(id uint32) ([]byte, error) {
+	key, ok := r.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return key, nil
+}
+
+// LoadKeyFile reads a KeyRing from a file. Each non-empty line of the file
+// that does not start with # holds a key ID and a base64 encoded key,
+// separated by whitespace:
+//
+//	# rotated 2019-11-01
+//	1 3q2+7wAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+//	2 yv66vgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+//
+// Keys are rotated by adding a key with a higher ID. Older keys must be kept
+// for as long as any data sealed with them remains.
+func LoadKeyFile(path string) (*KeyRing, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys := make(map[uint32][]byte)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a key id and a key", path, n)
+		}
+		id, err := strconv.ParseUint(fields[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid key id: %v", path, n, err)
+		}
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid key: %v", path, n, err)
+		}
+		if _, ok := keys[uint32(id)]; ok {
+			return nil, fmt.Errorf("%s:%d: duplicate key id %d", path, n, id)
+		}
+		keys[uint32(id)] = key
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewKeyRing(keys)
+}
@@ -0,0 +1,320 @@
+// Package fault injects faults into the file operations of the storage engine,
+// so that recovery from failed fsyncs, torn WAL writes, slow disks and
+// corrupt blocks can be tested.
+//
+// Faults are only injected once an Injector has been enabled. While no
+// Injector is enabled the hooks called by the engine do nothing.
+package fault
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInjected is returned by an operation failed by an injected fault.
+var ErrInjected = errors.New("injected fault")
+
+// ErrInjectorInUse is returned when acquiring an Injector while another
+// Injector is enabled.
+var ErrInjectorInUse = errors.New("another fault injector is enabled")
+
+// Point identifies a file operation faults can be injected into.
+type Point string
+
+// Injection points.
+const (
+	WALWrite Point = "wal-write" // writing an entry to a WAL segment
+	WALSync  Point = "wal-sync"  // fsyncing a WAL segment
+	TSMWrite Point = "tsm-write" // writing a TSM file
+	TSMSync  Point = "tsm-sync"  // fsyncing a TSM file
+	TSMRead  Point = "tsm-read"  // reading a block from a TSM file
+)
+
+// Kind is a kind of fault.
+type Kind string
+
+// Fault kinds.
+const (
+	// KindError fails the operation with ErrInjected.
+	KindError Kind = "error"
+
+	// KindDelay stalls the operation, as a slow disk would.
+	KindDelay Kind = "delay"
+
+	// KindTorn writes only part of the data, then fails the write. It can
+	// only be injected into WALWrite.
+	KindTorn Kind = "torn"
+
+	// KindCorrupt flips a bit of the data read. It can only be injected into
+	// TSMRead.
+	KindCorrupt Kind = "corrupt"
+)
+
+// Fault describes a fault to inject.
+type Fault struct {
+	Point Point
+	Kind  Kind
+
+	// Delay is how long a KindDelay fault stalls an operation.
+	Delay time.Duration
+
+	// Probability is the chance of the fault being injected into each
+	// operation, in the range (0, 1]. Zero injects it into every operation.
+	Probability float64
+}
+
+// String returns the fault in the format accepted by Parse.
+func (f Fault) String() string {
+	s := string(f.Point) + ":" + string(f.Kind)
+	if f.Kind == KindDelay {
+		s += ":" + f.Delay.String()
+	}
+	if f.Probability > 0 && f.Probability < 1 {
+		s += "@" + strconv.FormatFloat(f.Probability, 'g', -1, 64)
+	}
+	return s
+}
+
+// Valid returns an error if the fault can not be injected.
+func (f Fault) Valid() error {
+	switch f.Point {
+	case WALWrite, WALSync, TSMWrite, TSMSync, TSMRead:
+	default:
+		return fmt.Errorf("unknown fault injection point %q", f.Point)
+	}
+
+	switch f.Kind {
+	case KindError:
+	case KindDelay:
+		if f.Delay <= 0 {
+			return fmt.Errorf("%s: delay must be positive", f.Point)
+		}
+	case KindTorn:
+		if f.Point != WALWrite {
+			return fmt.Errorf("%s: torn writes can only be injected into %s", f.Point, WALWrite)
+		}
+	case KindCorrupt:
+		if f.Point != TSMRead {
+			return fmt.Errorf("%s: corruption can only be injected into %s", f.Point, TSMRead)
+		}
+	default:
+		return fmt.Errorf("%s: unknown fault kind %q", f.Point, f.Kind)
+	}
+
+	if f.Probability < 0 || f.Probability > 1 {
+		return fmt.Errorf("%s: probability must be between 0 and 1", f.Point)
+	}
+	return nil
+}
+
+// Parse parses a comma separated list of faults. Each fault has the form
+//
+//	point:kind[:delay][@probability]
+//
+// For example, "wal-sync:error@0.01,tsm-write:delay:200ms,tsm-read:corrupt"
+// fails 1% of WAL fsyncs, slows every TSM file write by 200ms and corrupts
+// every block read.
+func Parse(s string) ([]Fault, error) {
+	var faults []Fault
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		var f Fault
+		if i := strings.LastIndex(spec, "@"); i >= 0 {
+			p, err := strconv.ParseFloat(spec[i+1:], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fault %q: invalid probability: %v", spec, err)
+			}
+			f.Probability, spec = p, spec[:i]
+		}
+
+		parts := strings.SplitN(spec, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid fault %q: expected point:kind", spec)
+		}
+		f.Point, f.Kind = Point(parts[0]), Kind(parts[1])
+		if len(parts) == 3 {
+			if f.Kind != KindDelay {
+				return nil, fmt.Errorf("invalid fault %q: only delays take a duration", spec)
+			}
+			d, err := time.ParseDuration(parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid fault %q: %v", spec, err)
+			}
+			f.Delay = d
+		}
+
+		if err := f.Valid(); err != nil {
+			return nil, err
+		}
+		faults = append(faults, f)
+	}
+	return faults, nil
+}
+
+// Injector injects a set of faults. It is safe for concurrent use.
+type Injector struct {
+	faults map[Point][]Fault
+
+	mu   sync.Mutex
+	rand *rand.Rand
+	n    map[Point]uint64
+}
+
+// NewInjector returns an Injector injecting faults.
+func NewInjector(faults ...Fault) (*Injector, error) {
+	i := &Injector{
+		faults: make(map[Point][]Fault),
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		n:      make(map[Point]uint64),
+	}
+	for _, f := range faults {
+		if err := f.Valid(); err != nil {
+			return nil, err
+		}
+		i.faults[f.Point] = append(i.faults[f.Point], f)
+	}
+	return i, nil
+}
+
+// Injected returns the number of faults injected into operations at p.
+func (i *Injector) Injected(p Point) uint64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.n[p]
+}
+
+// fire returns true if f should be injected into the current operation, and
+// counts it if so.
+func (i *Injector) fire(f Fault) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if f.Probability > 0 && i.rand.Float64() >= f.Probability {
+		return false
+	}
+	i.n[f.Point]++
+	return true
+}
+
+// Err stalls the operation at p for any KindDelay faults injected into it, and
+// returns ErrInjected if a KindError fault is injected.
+func (i *Injector) Err(p Point) error {
+	var err error
+	for _, f := range i.faults[p] {
+		switch f.Kind {
+		case KindDelay:
+			if i.fire(f) {
+				time.Sleep(f.Delay)
+			}
+		case KindError:
+			if err == nil && i.fire(f) {
+				err = fmt.Errorf("%s: %w", p, ErrInjected)
+			}
+		}
+	}
+	return err
+}
+
+// Torn returns a prefix of b if a KindTorn fault is injected into the write at
+// p, or b otherwise.
+func (i *Injector) Torn(p Point, b []byte) []byte {
+	for _, f := range i.faults[p] {
+		if f.Kind == KindTorn && len(b) > 0 && i.fire(f) {
+			return b[:len(b)/2]
+		}
+	}
+	return b
+}
+
+// Corrupt returns a copy of b with a bit flipped if a KindCorrupt fault is
+// injected into the read at p, or b otherwise. b itself is never modified, as
+// it may be read-only.
+func (i *Injector) Corrupt(p Point, b []byte) []byte {
+	for _, f := range i.faults[p] {
+		if f.Kind == KindCorrupt && len(b) > 0 && i.fire(f) {
+			c := append([]byte(nil), b...)
+			i.mu.Lock()
+			n := i.rand.Intn(len(c) * 8)
+			i.mu.Unlock()
+			c[n/8] ^= 1 << uint(n%8)
+			return c
+		}
+	}
+	return b
+}
+
+var (
+	enabled   atomic.Value
+	acquireMu sync.Mutex
+)
+
+// Enable starts injecting the faults of i into the storage engine. A nil
+// Injector stops injecting faults.
+func Enable(i *Injector) {
+	enabled.Store(&i)
+}
+
+// Enabled returns the enabled Injector, or nil if none is enabled.
+func Enabled() *Injector {
+	if i, ok := enabled.Load().(**Injector); ok {
+		return *i
+	}
+	return nil
+}
+
+// Acquire enables i until it is released. The enabled Injector is shared by
+// every storage engine of the process, so Acquire fails with ErrInjectorInUse
+// while another Injector is acquired.
+func Acquire(i *Injector) error {
+	acquireMu.Lock()
+	defer acquireMu.Unlock()
+
+	if cur := Enabled(); cur != nil && cur != i {
+		return ErrInjectorInUse
+	}
+	Enable(i)
+	return nil
+}
+
+// Release stops injecting faults if i is the enabled Injector.
+func Release(i *Injector) {
+	acquireMu.Lock()
+	defer acquireMu.Unlock()
+
+	if i != nil && Enabled() == i {
+		Enable(nil)
+	}
+}
+
+// Err calls Err on the enabled Injector, if any.
+func Err(p Point) error {
+	if i := Enabled(); i != nil {
+		return i.Err(p)
+	}
+	return nil
+}
+
+// Torn calls Torn on the enabled Injector, if any.
+func Torn(p Point, b []byte) []byte {
+	if i := Enabled(); i != nil {
+		return i.Torn(p, b)
+	}
+	return b
+}
+
+// Corrupt calls Corrupt on the enabled Injector, if any.
+func Corrupt(p Point, b []byte) []byte {
+	if i := Enabled(); i != nil {
+		return i.Corrupt(p, b)
+	}
+	return b
+}
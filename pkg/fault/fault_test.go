@@ -0,0 +1,96 @@
+package fault_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/fault"
+)
+
+func TestParse(t *testing.T) {
+	faults, err := fault.Parse("wal-sync:error@0.25, tsm-write:delay:200ms,tsm-read:corrupt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []fault.Fault{
+		{Point: fault.WALSync, Kind: fault.KindError, Probability: 0.25},
+		{Point: fault.TSMWrite, Kind: fault.KindDelay, Delay: 200 * time.Millisecond},
+		{Point: fault.TSMRead, Kind: fault.KindCorrupt},
+	}
+	if len(faults) != len(exp) {
+		t.Fatalf("got %d faults, expected %d", len(faults), len(exp))
+	}
+	for i := range exp {
+		if faults[i] != exp[i] {
+			t.Errorf("fault %d: got %v, expected %v", i, faults[i], exp[i])
+		}
+	}
+
+	for _, s := range []string{
+		"wal-sync",
+		"disk:error",
+		"wal-sync:explode",
+		"wal-sync:torn",
+		"wal-write:corrupt",
+		"wal-write:delay",
+		"wal-write:error:1s",
+		"wal-write:error@2",
+	} {
+		if _, err := fault.Parse(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestInjector(t *testing.T) {
+	i, err := fault.NewInjector(
+		fault.Fault{Point: fault.WALSync, Kind: fault.KindError},
+		fault.Fault{Point: fault.WALWrite, Kind: fault.KindTorn},
+		fault.Fault{Point: fault.TSMRead, Kind: fault.KindCorrupt},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.Err(fault.WALSync); !errors.Is(err, fault.ErrInjected) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := i.Err(fault.TSMSync); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := []byte("0123456789")
+	if got := i.Torn(fault.WALWrite, data); len(got) >= len(data) {
+		t.Fatalf("expected a torn write, got %q", got)
+	}
+
+	got := i.Corrupt(fault.TSMRead, data)
+	if bytes.Equal(got, data) {
+		t.Fatal("expected corrupt data")
+	} else if string(data) != "0123456789" {
+		t.Fatal("original data was modified")
+	}
+
+	if got, exp := i.Injected(fault.WALSync), uint64(1); got != exp {
+		t.Fatalf("got %d injected faults, expected %d", got, exp)
+	}
+}
+
+func TestEnable(t *testing.T) {
+	if err := fault.Err(fault.WALSync); err != nil {
+		t.Fatalf("unexpected error with no injector: %v", err)
+	}
+
+	i, err := fault.NewInjector(fault.Fault{Point: fault.WALSync, Kind: fault.KindError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fault.Enable(i)
+	defer fault.Enable(nil)
+
+	if err := fault.Err(fault.WALSync); err == nil {
+		t.Fatal("expected injected error")
+	}
+}
@@ -52,3 +52,30 @@ type nopWriteCloser struct {
 }
 
 func (d nopWriteCloser) Close() error { return nil }
+
+func TestAdjustableRate_SetRate(t *testing.T) {
+	r := limiter.NewAdjustableRate(0, 0)
+	if rate, burst := r.Limits(); rate != 0 || burst != 0 {
+		t.Fatalf("got rate %d burst %d, expected unlimited", rate, burst)
+	}
+
+	b := nopWriteCloser{bytes.NewBuffer(nil)}
+	w := limiter.NewWriterWithRate(b, r)
+	if n, err := w.Write(make([]byte, 1024)); err != nil || n != 1024 {
+		t.Fatalf("unlimited write: got n=%d err=%v", n, err)
+	}
+
+	r.SetRate(10, 0)
+	if rate, burst := r.Limits(); rate != 10 || burst != 10 {
+		t.Fatalf("got rate %d burst %d, expected 10 and 10", rate, burst)
+	}
+
+	// The initial burst is spent, so writing a burst must wait for it.
+	start := time.Now()
+	if _, err := w.Write(make([]byte, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Fatalf("write was not limited: took %s", elapsed)
+	}
+}
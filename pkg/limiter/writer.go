@@ -3,7 +3,9 @@ package limiter
 import (
 	"context"
 	"io"
+	"math"
 	"os"
+	"sync"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -26,6 +28,81 @@ func NewRate(bytesPerSec, burstLimit int) Rate {
 	return limiter
 }
 
+// AdjustableRate is a Rate whose limits can be changed while it is in use.
+type AdjustableRate struct {
+	mu      sync.RWMutex
+	limiter *rate.Limiter // nil if unlimited
+}
+
+// NewAdjustableRate returns a Rate that limits to bytesPerSec with a maximum
+// burst of burstLimit. A bytesPerSec of 0 disables limiting, and a burstLimit
+// of 0 allows bursts of bytesPerSec.
+func NewAdjustableRate(bytesPerSec, burstLimit int) *AdjustableRate {
+	r := &AdjustableRate{}
+	r.SetRate(bytesPerSec, burstLimit)
+	return r
+}
+
+// SetRate changes the limits of the rate. Waits that are in progress complete
+// under the previous limits.
+func (r *AdjustableRate) SetRate(bytesPerSec, burstLimit int) {
+	var l *rate.Limiter
+	if bytesPerSec > 0 {
+		if burstLimit <= 0 {
+			burstLimit = bytesPerSec
+		}
+		l = NewRate(bytesPerSec, burstLimit).(*rate.Limiter)
+	}
+
+	r.mu.Lock()
+	r.limiter = l
+	r.mu.Unlock()
+}
+
+// Limits returns the current rate and burst limit. A rate of 0 means the
+// rate is unlimited.
+func (r *AdjustableRate) Limits() (bytesPerSec, burstLimit int) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.limiter == nil {
+		return 0, 0
+	}
+	return int(r.limiter.Limit()), r.limiter.Burst()
+}
+
+// WaitN blocks until n bytes are allowed to be written.
+func (r *AdjustableRate) WaitN(ctx context.Context, n int) error {
+	r.mu.RLock()
+	l := r.limiter
+	r.mu.RUnlock()
+	if l == nil {
+		return nil
+	}
+
+	// The burst may have been lowered since the caller sized n by it.
+	for n > 0 {
+		m := n
+		if m > l.Burst() {
+			m = l.Burst()
+		}
+		if err := l.WaitN(ctx, m); err != nil {
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+// Burst returns the maximum number of bytes that can be waited for at once.
+func (r *AdjustableRate) Burst() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.limiter == nil {
+		return math.MaxInt32
+	}
+	return r.limiter.Burst()
+}
+
 // NewWriter returns a writer that implements io.Writer with rate limiting.
 // The limiter use a token bucket approach and limits the rate to bytesPerSec
 // with a maximum burst of burstLimit.
@@ -0,0 +1,123 @@
+package parquet
+
+import (
+	"encoding/binary"
+)
+
+// Type identifiers of the Thrift compact protocol.
+const (
+	thriftBoolTrue  = 1
+	thriftBoolFalse = 2
+	thriftByte      = 3
+	thriftI32       = 5
+	thriftI64       = 6
+	thriftBinary    = 8
+	thriftList      = 9
+	thriftStruct    = 12
+)
+
+// thriftWriter encodes the Parquet metadata structures using the Thrift
+// compact protocol. Only the types needed by the metadata are supported.
+type thriftWriter struct {
+	b []byte
+
+	// last holds the ID of the last field written in each open struct, as
+	// field IDs are encoded as deltas.
+	last []int16
+}
+
+func (w *thriftWriter) uvarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.b = append(w.b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func (w *thriftWriter) varint(v int64) {
+	w.uvarint(uint64((v << 1) ^ (v >> 63)))
+}
+
+// field writes the header of the field with id and type typ.
+func (w *thriftWriter) field(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.b = append(w.b, byte(delta)<<4|typ)
+	} else {
+		w.b = append(w.b, typ)
+		w.varint(int64(id))
+	}
+	*last = id
+}
+
+// beginStruct begins a struct, either at the top level or as the value of a
+// field or list element whose header has already been written.
+func (w *thriftWriter) beginStruct() {
+	w.last = append(w.last, 0)
+}
+
+// endStruct writes the stop field that ends the current struct.
+func (w *thriftWriter) endStruct() {
+	w.b = append(w.b, 0)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) structField(id int16) {
+	w.field(id, thriftStruct)
+	w.beginStruct()
+}
+
+func (w *thriftWriter) boolField(id int16, v bool) {
+	if v {
+		w.field(id, thriftBoolTrue)
+	} else {
+		w.field(id, thriftBoolFalse)
+	}
+}
+
+func (w *thriftWriter) byteField(id int16, v int8) {
+	w.field(id, thriftByte)
+	w.b = append(w.b, byte(v))
+}
+
+func (w *thriftWriter) i32Field(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.varint(int64(v))
+}
+
+func (w *thriftWriter) i64Field(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.varint(v)
+}
+
+func (w *thriftWriter) stringField(id int16, v string) {
+	w.field(id, thriftBinary)
+	w.uvarint(uint64(len(v)))
+	w.b = append(w.b, v...)
+}
+
+// listField writes the header of a list field of n elements of type typ. The
+// elements are written next.
+func (w *thriftWriter) listField(id int16, typ byte, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.b = append(w.b, byte(n)<<4|typ)
+	} else {
+		w.b = append(w.b, 0xf0|typ)
+		w.uvarint(uint64(n))
+	}
+}
+
+// i32List writes a list field of i32 values.
+func (w *thriftWriter) i32List(id int16, vs []int32) {
+	w.listField(id, thriftI32, len(vs))
+	for _, v := range vs {
+		w.varint(int64(v))
+	}
+}
+
+// stringList writes a list field of string values.
+func (w *thriftWriter) stringList(id int16, vs []string) {
+	w.listField(id, thriftBinary, len(vs))
+	for _, v := range vs {
+		w.uvarint(uint64(len(v)))
+		w.b = append(w.b, v...)
+	}
+}
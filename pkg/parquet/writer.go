@@ -0,0 +1,446 @@
+// Package parquet writes data in the Apache Parquet columnar file format.
+//
+// Only what is needed to export time series is supported: a flat schema of
+// required or optional columns, written as uncompressed, plain encoded data
+// pages with a single page per column chunk.
+package parquet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// magic begins and ends every Parquet file.
+const magic = "PAR1"
+
+// DefaultRowGroupSize is the default number of rows buffered before they are
+// written as a row group.
+const DefaultRowGroupSize = 100000
+
+// Kind is the kind of values held by a column.
+type Kind int
+
+const (
+	// Timestamp columns hold int64 nanoseconds since the Unix epoch, UTC.
+	Timestamp Kind = iota
+
+	// String columns hold UTF-8 strings, written from string or []byte.
+	String
+
+	// Double columns hold float64 values.
+	Double
+
+	// Int64 columns hold int64 values.
+	Int64
+
+	// Uint64 columns hold uint64 values.
+	Uint64
+
+	// Boolean columns hold bool values.
+	Boolean
+)
+
+// Physical types, encodings and other enumerations of the Parquet format.
+const (
+	typeBoolean   = 0
+	typeInt64     = 2
+	typeDouble    = 5
+	typeByteArray = 6
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	convertedUTF8   = 0
+	convertedUint64 = 14
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData = 0
+)
+
+// ErrClosed is returned when writing to a closed Writer.
+var ErrClosed = errors.New("parquet: writer closed")
+
+// Column describes a column of a Parquet file.
+type Column struct {
+	Name string
+	Kind Kind
+
+	// Optional columns may hold null values.
+	Optional bool
+}
+
+// columnChunk holds the values of a column buffered for the next row group.
+type columnChunk struct {
+	defs   []bool // whether each value is set, for optional columns
+	values []byte // plain encoded values that are set
+	bits   int    // number of boolean values packed into values
+}
+
+// chunkMeta describes a column chunk written to the file.
+type chunkMeta struct {
+	offset int64
+	size   int64
+	values int64
+}
+
+// rowGroup describes a row group written to the file.
+type rowGroup struct {
+	columns []chunkMeta
+	size    int64
+	rows    int64
+}
+
+// Writer writes rows to a Parquet file. Rows are buffered in memory until
+// RowGroupSize rows have been written, or Flush is called, and then written
+// as a row group. The file is not valid until Close has been called.
+type Writer struct {
+	w       io.Writer
+	n       int64 // number of bytes written to w
+	columns []Column
+	chunks  []columnChunk
+	rows    int
+	groups  []rowGroup
+	closed  bool
+
+	// RowGroupSize is the number of rows buffered before they are written.
+	RowGroupSize int
+}
+
+// NewWriter returns a new Writer of rows with columns to w.
+func NewWriter(w io.Writer, columns []Column) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet: no columns")
+	}
+	names := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		if c.Name == "" {
+			return nil, errors.New("parquet: empty column name")
+		} else if _, ok := names[c.Name]; ok {
+			return nil, fmt.Errorf("parquet: duplicate column %q", c.Name)
+		} else if c.Kind < Timestamp || c.Kind > Boolean {
+			return nil, fmt.Errorf("parquet: invalid kind of column %q", c.Name)
+		}
+		names[c.Name] = struct{}{}
+	}
+
+	pw := &Writer{
+		w:            w,
+		columns:      columns,
+		chunks:       make([]columnChunk, len(columns)),
+		RowGroupSize: DefaultRowGroupSize,
+	}
+	if err := pw.write([]byte(magic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+// Columns returns the columns of the file.
+func (w *Writer) Columns() []Column { return w.columns }
+
+// Write writes a row with a value for each column. Null values of optional
+// columns are given as nil.
+func (w *Writer) Write(row []interface{}) error {
+	if w.closed {
+		return ErrClosed
+	} else if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: got %d values, expected %d", len(row), len(w.columns))
+	}
+
+	// Validate the whole row first, so that a bad row is not partly written.
+	for i, v := range row {
+		if err := w.columns[i].check(v); err != nil {
+			return err
+		}
+	}
+	for i, v := range row {
+		w.chunks[i].add(w.columns[i], v)
+	}
+
+	w.rows++
+	if w.RowGroupSize > 0 && w.rows >= w.RowGroupSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+// check returns an error if v cannot be written to the column.
+func (c Column) check(v interface{}) error {
+	if v == nil {
+		if !c.Optional {
+			return fmt.Errorf("parquet: null value for required column %q", c.Name)
+		}
+		return nil
+	}
+
+	var ok bool
+	switch c.Kind {
+	case Timestamp, Int64:
+		_, ok = v.(int64)
+	case String:
+		switch v.(type) {
+		case string, []byte:
+			ok = true
+		}
+	case Double:
+		_, ok = v.(float64)
+	case Uint64:
+		_, ok = v.(uint64)
+	case Boolean:
+		_, ok = v.(bool)
+	}
+	if !ok {
+		return fmt.Errorf("parquet: invalid value of type %T for column %q", v, c.Name)
+	}
+	return nil
+}
+
+// add appends v, which has been checked, to the chunk.
+func (c *columnChunk) add(col Column, v interface{}) {
+	if col.Optional {
+		c.defs = append(c.defs, v != nil)
+	}
+	if v == nil {
+		return
+	}
+
+	var buf [8]byte
+	switch v := v.(type) {
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		c.values = append(c.values, buf[:]...)
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], v)
+		c.values = append(c.values, buf[:]...)
+	case float64:
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		c.values = append(c.values, buf[:]...)
+	case string:
+		binary.LittleEndian.PutUint32(buf[:4], uint32(len(v)))
+		c.values = append(c.values, buf[:4]...)
+		c.values = append(c.values, v...)
+	case []byte:
+		binary.LittleEndian.PutUint32(buf[:4], uint32(len(v)))
+		c.values = append(c.values, buf[:4]...)
+		c.values = append(c.values, v...)
+	case bool:
+		if c.bits%8 == 0 {
+			c.values = append(c.values, 0)
+		}
+		if v {
+			c.values[len(c.values)-1] |= 1 << uint(c.bits%8)
+		}
+		c.bits++
+	}
+}
+
+// Flush writes the buffered rows as a row group.
+func (w *Writer) Flush() error {
+	if w.closed {
+		return ErrClosed
+	} else if w.rows == 0 {
+		return nil
+	}
+
+	g := rowGroup{
+		columns: make([]chunkMeta, len(w.columns)),
+		rows:    int64(w.rows),
+	}
+	for i := range w.chunks {
+		offset := w.n
+		if err := w.writePage(&w.chunks[i], w.rows); err != nil {
+			return err
+		}
+		g.columns[i] = chunkMeta{offset: offset, size: w.n - offset, values: int64(w.rows)}
+		g.size += w.n - offset
+		w.chunks[i] = columnChunk{}
+	}
+	w.groups = append(w.groups, g)
+	w.rows = 0
+	return nil
+}
+
+// writePage writes the values of the chunk as a single data page of n values.
+func (w *Writer) writePage(c *columnChunk, n int) error {
+	var data []byte
+	if c.defs != nil {
+		levels := encodeLevels(c.defs)
+		var buf [4]byte
+		binary.LittleEndian.PutUint32(buf[:], uint32(len(levels)))
+		data = append(data, buf[:]...)
+		data = append(data, levels...)
+	}
+	data = append(data, c.values...)
+
+	var t thriftWriter
+	t.beginStruct()
+	t.i32Field(1, pageTypeData)
+	t.i32Field(2, int32(len(data)))
+	t.i32Field(3, int32(len(data)))
+	t.structField(5)
+	t.i32Field(1, int32(n))
+	t.i32Field(2, encodingPlain)
+	t.i32Field(3, encodingRLE)
+	t.i32Field(4, encodingRLE)
+	t.endStruct()
+	t.endStruct()
+
+	if err := w.write(t.b); err != nil {
+		return err
+	}
+	return w.write(data)
+}
+
+// encodeLevels encodes definition levels of a bit width of one as a single
+// bit-packed run of the RLE/bit-packing hybrid encoding.
+func encodeLevels(defs []bool) []byte {
+	groups := (len(defs) + 7) / 8
+	var buf [binary.MaxVarintLen64]byte
+	b := append([]byte(nil), buf[:binary.PutUvarint(buf[:], uint64(groups)<<1|1)]...)
+
+	packed := make([]byte, groups)
+	for i, def := range defs {
+		if def {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return append(b, packed...)
+}
+
+// Close writes any buffered rows and the file footer. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+
+	meta := w.fileMetaData()
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(len(meta)))
+	if err := w.write(meta); err != nil {
+		return err
+	} else if err := w.write(buf[:]); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+// fileMetaData returns the encoded metadata of the file.
+func (w *Writer) fileMetaData() []byte {
+	var t thriftWriter
+	t.beginStruct()
+	t.i32Field(1, 1) // version
+
+	t.listField(2, thriftStruct, len(w.columns)+1)
+	t.beginStruct()
+	t.stringField(4, "schema")
+	t.i32Field(5, int32(len(w.columns)))
+	t.endStruct()
+	for _, c := range w.columns {
+		c.writeSchemaElement(&t)
+	}
+
+	var rows int64
+	for _, g := range w.groups {
+		rows += g.rows
+	}
+	t.i64Field(3, rows)
+
+	t.listField(4, thriftStruct, len(w.groups))
+	for _, g := range w.groups {
+		t.beginStruct()
+		t.listField(1, thriftStruct, len(g.columns))
+		for i, cm := range g.columns {
+			t.beginStruct()
+			t.i64Field(2, cm.offset)
+			t.structField(3)
+			t.i32Field(1, w.columns[i].physicalType())
+			t.i32List(2, []int32{encodingPlain, encodingRLE})
+			t.stringList(3, []string{w.columns[i].Name})
+			t.i32Field(4, 0) // uncompressed
+			t.i64Field(5, cm.values)
+			t.i64Field(6, cm.size)
+			t.i64Field(7, cm.size)
+			t.i64Field(9, cm.offset)
+			t.endStruct()
+			t.endStruct()
+		}
+		t.i64Field(2, g.size)
+		t.i64Field(3, g.rows)
+		t.endStruct()
+	}
+
+	t.stringField(6, "influxdb")
+	t.endStruct()
+	return t.b
+}
+
+// physicalType returns the Parquet type used to store the column's values.
+func (c Column) physicalType() int32 {
+	switch c.Kind {
+	case String:
+		return typeByteArray
+	case Double:
+		return typeDouble
+	case Boolean:
+		return typeBoolean
+	default:
+		return typeInt64
+	}
+}
+
+// writeSchemaElement writes the schema element describing the column.
+func (c Column) writeSchemaElement(t *thriftWriter) {
+	t.beginStruct()
+	t.i32Field(1, c.physicalType())
+	if c.Optional {
+		t.i32Field(3, repetitionOptional)
+	} else {
+		t.i32Field(3, repetitionRequired)
+	}
+	t.stringField(4, c.Name)
+
+	switch c.Kind {
+	case Timestamp:
+		// Nanosecond timestamps have only a logical type.
+		t.structField(10)
+		t.structField(8)
+		t.boolField(1, true) // adjusted to UTC
+		t.structField(2)
+		t.structField(3) // nanoseconds
+		t.endStruct()
+		t.endStruct()
+		t.endStruct()
+		t.endStruct()
+	case String:
+		t.i32Field(6, convertedUTF8)
+		t.structField(10)
+		t.structField(1)
+		t.endStruct()
+		t.endStruct()
+	case Uint64:
+		t.i32Field(6, convertedUint64)
+		t.structField(10)
+		t.structField(10)
+		t.byteField(1, 64)
+		t.boolField(2, false)
+		t.endStruct()
+		t.endStruct()
+	}
+	t.endStruct()
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.n += int64(n)
+	return err
+}
@@ -0,0 +1,90 @@
+package parquet_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/parquet"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, []parquet.Column{
+		{Name: "time", Kind: parquet.Timestamp},
+		{Name: "host", Kind: parquet.String, Optional: true},
+		{Name: "usage", Kind: parquet.Double, Optional: true},
+		{Name: "count", Kind: parquet.Int64, Optional: true},
+		{Name: "total", Kind: parquet.Uint64, Optional: true},
+		{Name: "up", Kind: parquet.Boolean, Optional: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.RowGroupSize = 2
+
+	for _, row := range [][]interface{}{
+		{int64(1), "a", 1.5, int64(1), uint64(1), true},
+		{int64(2), []byte("b"), nil, int64(2), nil, false},
+		{int64(3), nil, 2.5, nil, uint64(3), nil},
+	} {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write([]interface{}{int64(4), nil, nil, nil, nil, nil}); err != parquet.ErrClosed {
+		t.Fatalf("got error %v, exp %v", err, parquet.ErrClosed)
+	}
+
+	b := buf.Bytes()
+	if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+		t.Fatal("expected file to begin and end with magic")
+	}
+	n := int(binary.LittleEndian.Uint32(b[len(b)-8:]))
+	if n <= 0 || n > len(b)-12 {
+		t.Fatalf("invalid footer length %d", n)
+	}
+	footer := b[len(b)-8-n : len(b)-8]
+	for _, c := range w.Columns() {
+		if !bytes.Contains(footer, []byte(c.Name)) {
+			t.Fatalf("footer does not describe column %q", c.Name)
+		}
+	}
+}
+
+func TestWriter_Write_Invalid(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, []parquet.Column{
+		{Name: "time", Kind: parquet.Timestamp},
+		{Name: "value", Kind: parquet.Double, Optional: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range [][]interface{}{
+		{nil, 1.5},
+		{int64(1), "a"},
+		{int64(1)},
+	} {
+		if err := w.Write(row); err == nil {
+			t.Fatalf("expected error writing %v", row)
+		}
+	}
+}
+
+func TestNewWriter_InvalidColumns(t *testing.T) {
+	for _, columns := range [][]parquet.Column{
+		nil,
+		{{Name: "", Kind: parquet.Double}},
+		{{Name: "a", Kind: parquet.Double}, {Name: "a", Kind: parquet.Int64}},
+		{{Name: "a", Kind: parquet.Kind(-1)}},
+	} {
+		if _, err := parquet.NewWriter(&bytes.Buffer{}, columns); err == nil {
+			t.Fatalf("expected error for columns %v", columns)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+// Package postgres provides a kv.Store backed by PostgreSQL, so that metadata
+// can be stored apart from the node and managed with standard database tools.
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/kv"
+	_ "github.com/lib/pq" // registers the postgres driver
+	"go.uber.org/zap"
+)
+
+// check that *KVStore implement kv.Store interface.
+var _ kv.Store = (*KVStore)(nil)
+
+// updateLockID is the key of the advisory lock held by update transactions.
+// Like boltdb, the store has a single writer at a time, so that updates never
+// fail to serialize with each other.
+const updateLockID = 0x6b76757064 // "kvupd"
+
+// cursorBatchSize is the number of pairs read at a time by forward cursors.
+const cursorBatchSize = 1000
+
+// KVStore is a kv.Store backed by PostgreSQL. Buckets are rows of the
+// kv_buckets table, and the pairs of every bucket are rows of the kv_pairs
+// table. View transactions read a consistent snapshot of the store, and update
+// transactions are applied one at a time.
+//
+// Watchers only see the changes committed through the store itself, not those
+// of other processes sharing the database.
+type KVStore struct {
+	dsn string
+	db  *sql.DB
+	log *zap.Logger
+
+	// updateMu is held while an update is committed and its changes are
+	// published, so watchers see the changes in the order they are committed.
+	updateMu sync.Mutex
+	watchers kv.Watchers
+}
+
+// NewKVStore returns an instance of KVStore connecting to the database of the
+// provided data source name.
+func NewKVStore(log *zap.Logger, dsn string) *KVStore {
+	return &KVStore{
+		dsn: dsn,
+		log: log,
+	}
+}
+
+// Open connects to the database and migrates its schema to the current
+// version.
+func (s *KVStore) Open(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	db, err := sql.Open("postgres", s.dsn)
+	if err != nil {
+		return fmt.Errorf("unable to open postgres database: %v", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("unable to connect to postgres database: %v", err)
+	}
+	if err := migrate(ctx, db); err != nil {
+		db.Close()
+		return fmt.Errorf("unable to migrate postgres database: %v", err)
+	}
+	s.db = db
+
+	s.log.Info("Resources opened", zap.String("store", "postgres"))
+	return nil
+}
+
+// Close the connection to the database.
+func (s *KVStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Flush removes all keys and buckets from the store.
+func (s *KVStore) Flush(ctx context.Context) {
+	_ = s.Update(ctx, func(tx kv.Tx) error {
+		_, err := tx.(*Tx).tx.ExecContext(ctx, `DELETE FROM kv_buckets`)
+		return err
+	})
+}
+
+// View opens up a read only transaction against the store, reading a snapshot
+// of the store as of when it started.
+func (s *KVStore) View(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return fn(&Tx{
+		tx:  tx,
+		ctx: ctx,
+	})
+}
+
+// Update opens up an update transaction against the store. It is committed if
+// fn returns no error, and rolled back otherwise.
+func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.updateMu.Lock()
+	defer s.updateMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, updateLockID); err != nil {
+		return err
+	}
+
+	events := &kv.EventRecorder{}
+	if err := fn(&Tx{tx: tx, ctx: ctx, writable: true, events: events}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.watchers.Publish(events.Events...)
+	return nil
+}
+
+// Watch returns a channel of the changes committed through the store to the
+// keys of bucket with prefix.
+func (s *KVStore) Watch(ctx context.Context, bucket, prefix []byte) (<-chan kv.Event, error) {
+	return s.watchers.Watch(ctx, bucket, prefix), nil
+}
+
+// Backup copies all K:Vs to a writer, in BoltDB format, so that it can be
+// restored to a bolt store.
+func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := ioutil.TempFile("", "influxd-postgres-backup")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = s.View(ctx, func(tx kv.Tx) error {
+		rows, err := tx.(*Tx).tx.QueryContext(ctx, `SELECT b.name, p.key, p.value FROM kv_buckets b LEFT JOIN kv_pairs p ON p.bucket = b.name ORDER BY b.name, p.key`)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		return db.Update(func(btx *bolt.Tx) error {
+			for rows.Next() {
+				var name, key, value []byte
+				if err := rows.Scan(&name, &key, &value); err != nil {
+					return err
+				}
+				b, err := btx.CreateBucketIfNotExists(name)
+				if err != nil {
+					return err
+				}
+				if key == nil {
+					continue
+				}
+				if err := b.Put(key, value); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(btx *bolt.Tx) error {
+		_, err := btx.WriteTo(w)
+		return err
+	})
+}
+
+// Tx is a light wrapper around a database transaction. It implements kv.Tx.
+type Tx struct {
+	tx       *sql.Tx
+	ctx      context.Context
+	writable bool
+	events   *kv.EventRecorder
+}
+
+// Context returns the context for the transaction.
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// WithContext sets the context for the transaction.
+func (tx *Tx) WithContext(ctx context.Context) {
+	tx.ctx = ctx
+}
+
+// Bucket retrieves the bucket named b, creating it in update transactions if
+// it does not exist.
+func (tx *Tx) Bucket(b []byte) (kv.Bucket, error) {
+	if tx.writable {
+		if _, err := tx.tx.ExecContext(tx.ctx, `INSERT INTO kv_buckets (name) VALUES ($1) ON CONFLICT DO NOTHING`, b); err != nil {
+			return nil, err
+		}
+		return tx.events.Bucket(b, &Bucket{tx: tx, name: b}), nil
+	}
+
+	var exists bool
+	if err := tx.tx.QueryRowContext(tx.ctx, `SELECT EXISTS (SELECT 1 FROM kv_buckets WHERE name = $1)`, b).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, kv.ErrTxNotWritable
+	}
+	return &Bucket{tx: tx, name: b}, nil
+}
+
+// Bucket implements kv.Bucket.
+type Bucket struct {
+	tx   *Tx
+	name []byte
+}
+
+// Get retrieves the value at the provided key.
+func (b *Bucket) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := b.tx.tx.QueryRowContext(b.tx.ctx, `SELECT value FROM kv_pairs WHERE bucket = $1 AND key = $2`, b.name, key).Scan(&value)
+	if err == sql.ErrNoRows || (err == nil && len(value) == 0) {
+		return nil, kv.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Put sets the value at the provided key.
+func (b *Bucket) Put(key []byte, value []byte) error {
+	if !b.tx.writable {
+		return kv.ErrTxNotWritable
+	}
+	_, err := b.tx.tx.ExecContext(b.tx.ctx, `INSERT INTO kv_pairs (bucket, key, value) VALUES ($1, $2, $3)
+		ON CONFLICT (bucket, key) DO UPDATE SET value = EXCLUDED.value`, b.name, key, value)
+	return err
+}
+
+// Delete removes the provided key.
+func (b *Bucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return kv.ErrTxNotWritable
+	}
+	_, err := b.tx.tx.ExecContext(b.tx.ctx, `DELETE FROM kv_pairs WHERE bucket = $1 AND key = $2`, b.name, key)
+	return err
+}
+
+// Cursor reads the pairs of the bucket matching the provided hints and
+// returns a static cursor over them.
+func (b *Bucket) Cursor(opts ...kv.CursorHint) (kv.Cursor, error) {
+	var o kv.CursorHints
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var prefix []byte
+	if o.KeyPrefix != nil {
+		prefix = []byte(*o.KeyPrefix)
+	}
+
+	var (
+		pairs     []kv.Pair
+		seek      = prefix
+		exclusive bool
+	)
+	for {
+		batch, err := b.pairs(seek, exclusive, kv.CursorAscending)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range batch {
+			if !bytes.HasPrefix(p.Key, prefix) {
+				return kv.NewStaticCursor(pairs), nil
+			}
+			if o.PredicateFn == nil || o.PredicateFn(p.Key, p.Value) {
+				pairs = append(pairs, p)
+			}
+			seek, exclusive = p.Key, true
+		}
+		if len(batch) < cursorBatchSize {
+			return kv.NewStaticCursor(pairs), nil
+		}
+	}
+}
+
+// pairs reads the next batch of pairs of the bucket from seek in the provided
+// direction. The pair at seek is excluded if exclusive is true.
+func (b *Bucket) pairs(seek []byte, exclusive bool, direction kv.CursorDirection) ([]kv.Pair, error) {
+	if seek == nil {
+		seek = []byte{}
+	}
+
+	query := `SELECT key, value FROM kv_pairs WHERE bucket = $1 AND key >= $2 ORDER BY key LIMIT $3`
+	switch {
+	case direction == kv.CursorAscending && exclusive:
+		query = `SELECT key, value FROM kv_pairs WHERE bucket = $1 AND key > $2 ORDER BY key LIMIT $3`
+	case direction == kv.CursorDescending && exclusive:
+		query = `SELECT key, value FROM kv_pairs WHERE bucket = $1 AND key < $2 ORDER BY key DESC LIMIT $3`
+	case direction == kv.CursorDescending:
+		query = `SELECT key, value FROM kv_pairs WHERE bucket = $1 AND key <= $2 ORDER BY key DESC LIMIT $3`
+	}
+
+	rows, err := b.tx.tx.QueryContext(b.tx.ctx, query, b.name, seek, cursorBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []kv.Pair
+	for rows.Next() {
+		var p kv.Pair
+		if err := rows.Scan(&p.Key, &p.Value); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// last returns the last key of the bucket, or nil if it is empty.
+func (b *Bucket) last() ([]byte, error) {
+	var key []byte
+	err := b.tx.tx.QueryRowContext(b.tx.ctx, `SELECT key FROM kv_pairs WHERE bucket = $1 ORDER BY key DESC LIMIT 1`, b.name).Scan(&key)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return key, err
+}
+
+// ForwardCursor retrieves a cursor for iterating through the entries
+// in the key value store in a given direction (ascending / descending).
+func (b *Bucket) ForwardCursor(seek []byte, opts ...kv.CursorOption) (kv.ForwardCursor, error) {
+	config := kv.NewCursorConfig(opts...)
+	if config.Prefix != nil && !bytes.HasPrefix(seek, config.Prefix) {
+		return nil, fmt.Errorf("seek bytes %q not prefixed with %q: %w", string(seek), string(config.Prefix), kv.ErrSeekMissingPrefix)
+	}
+
+	if len(seek) == 0 && config.Direction == kv.CursorDescending {
+		last, err := b.last()
+		if err != nil {
+			return nil, err
+		}
+		if last == nil {
+			return &ForwardCursor{done: true}, nil
+		}
+		seek = last
+	}
+
+	return &ForwardCursor{
+		bucket:    b,
+		config:    config,
+		seek:      seek,
+		skipFirst: config.SkipFirst,
+	}, nil
+}
+
+// ForwardCursor is a kv.ForwardCursor reading the pairs of a bucket in
+// batches.
+type ForwardCursor struct {
+	bucket    *Bucket
+	config    kv.CursorConfig
+	seek      []byte
+	exclusive bool
+	skipFirst bool
+
+	batch []kv.Pair
+	n     int
+
+	done   bool
+	closed bool
+	// error found during iteration
+	err error
+}
+
+// Next returns the next key/value pair in the cursor.
+func (c *ForwardCursor) Next() ([]byte, []byte) {
+	for {
+		if c.err != nil || c.closed {
+			return nil, nil
+		}
+
+		if c.n >= len(c.batch) {
+			if c.done {
+				return nil, nil
+			}
+			c.batch, c.err = c.bucket.pairs(c.seek, c.exclusive, c.config.Direction)
+			c.n = 0
+			c.done = len(c.batch) < cursorBatchSize
+			if len(c.batch) > 0 {
+				c.seek, c.exclusive = c.batch[len(c.batch)-1].Key, true
+			}
+			continue
+		}
+
+		p := c.batch[c.n]
+		c.n++
+
+		if c.skipFirst {
+			c.skipFirst = false
+			continue
+		}
+		if c.config.Prefix != nil && !bytes.HasPrefix(p.Key, c.config.Prefix) {
+			c.batch, c.done = nil, true
+			return nil, nil
+		}
+		if fn := c.config.Hints.PredicateFn; fn != nil && !fn(p.Key, p.Value) {
+			continue
+		}
+		return p.Key, p.Value
+	}
+}
+
+// Err returns a non-nil error when an error occurred during cursor iteration.
+func (c *ForwardCursor) Err() error {
+	return c.err
+}
+
+// Close closes the cursor.
+func (c *ForwardCursor) Close() error {
+	c.closed = true
+	return nil
+}
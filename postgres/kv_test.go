@@ -0,0 +1,55 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/postgres"
+	platformtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+// NewTestKVStore returns a store of the database of INFLUXDB_POSTGRES_TEST_DSN,
+// emptied of all buckets, skipping the test if it is not set.
+func NewTestKVStore(t *testing.T) (*postgres.KVStore, func()) {
+	dsn := os.Getenv("INFLUXDB_POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("INFLUXDB_POSTGRES_TEST_DSN is not set")
+	}
+
+	s := postgres.NewKVStore(zaptest.NewLogger(t), dsn)
+	if err := s.Open(context.Background()); err != nil {
+		t.Fatalf("failed to open postgres store: %v", err)
+	}
+	s.Flush(context.Background())
+	return s, func() { s.Close() }
+}
+
+func initKVStore(f platformtesting.KVStoreFields, t *testing.T) (kv.Store, func()) {
+	s, closeFn := NewTestKVStore(t)
+
+	err := s.Update(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket(f.Bucket)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range f.Pairs {
+			if err := b.Put(p.Key, p.Value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to put keys: %v", err)
+	}
+	return s, closeFn
+}
+
+func TestKVStore(t *testing.T) {
+	platformtesting.KVStore(initKVStore, t)
+}
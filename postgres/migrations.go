@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migrationLockID is the key of the advisory lock held while migrating the
+// schema, so that stores opening the same database concurrently do not
+// apply a migration twice.
+const migrationLockID = 0x6b766d6967 // "kvmig"
+
+// migration is a change to the schema of the store.
+type migration struct {
+	version    int
+	statements []string
+}
+
+// migrations are the changes to the schema of the store, in the order they are
+// applied. Migrations must never be changed or removed once released; the
+// schema is changed by appending a new migration.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: []string{
+			`CREATE TABLE kv_buckets (
+				name BYTEA PRIMARY KEY
+			)`,
+			`CREATE TABLE kv_pairs (
+				bucket BYTEA NOT NULL REFERENCES kv_buckets (name) ON DELETE CASCADE,
+				key    BYTEA NOT NULL,
+				value  BYTEA NOT NULL,
+				PRIMARY KEY (bucket, key)
+			)`,
+		},
+	},
+}
+
+// migrate applies the migrations that have not been applied to db yet.
+func migrate(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS kv_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return err
+	}
+
+	var version int
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM kv_migrations`).Scan(&version); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		for _, stmt := range m.statements {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("unable to apply migration %d: %v", m.version, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO kv_migrations (version) VALUES ($1)`, m.version); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
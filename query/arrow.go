@@ -0,0 +1,166 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+)
+
+const (
+	ArrowDialectType = "arrow"
+
+	// ArrowResultMetadataKey and ArrowGroupKeyMetadataKey are the keys of
+	// the schema metadata holding the name of the result of a table and the
+	// JSON array of the labels of the columns of its group key.
+	ArrowResultMetadataKey   = "flux.result"
+	ArrowGroupKeyMetadataKey = "flux.groupKey"
+)
+
+// ArrowDialect is a dialect that encodes each table of the results as an
+// Apache Arrow IPC stream, and writes the streams one after the other. Clients
+// read the streams until the response ends. Times are encoded as timestamps
+// of nanoseconds in UTC.
+//
+// An error encountered after the results have begun to be written cannot be
+// encoded, so the last stream is left without its end-of-stream marker.
+type ArrowDialect struct{}
+
+func NewArrowDialect() *ArrowDialect {
+	return &ArrowDialect{}
+}
+
+func (d *ArrowDialect) Encoder() flux.MultiResultEncoder {
+	return &ArrowEncoder{}
+}
+
+func (d *ArrowDialect) DialectType() flux.DialectType {
+	return ArrowDialectType
+}
+
+func (d *ArrowDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/vnd.apache.arrow.stream")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// ArrowEncoder encodes results as described by ArrowDialect.
+type ArrowEncoder struct{}
+
+func (e *ArrowEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+	for results.More() {
+		res := results.Next()
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			return encodeArrowTable(wc, res.Name(), tbl)
+		}); err != nil {
+			return wc.Count(), err
+		}
+	}
+	return wc.Count(), results.Err()
+}
+
+// encodeArrowTable writes the Arrow IPC stream of a table of the result
+// named name to w. Each buffer of the table is written as a record batch.
+func encodeArrowTable(w io.Writer, name string, tbl flux.Table) error {
+	schema, err := arrowSchema(name, tbl)
+	if err != nil {
+		return err
+	}
+
+	aw := ipc.NewWriter(w, ipc.WithSchema(schema))
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		cols := make([]array.Interface, len(cr.Cols()))
+		for j, c := range cr.Cols() {
+			cols[j] = arrowColumn(cr, c.Type, j)
+		}
+		rec := array.NewRecord(schema, cols, int64(cr.Len()))
+		for _, col := range cols {
+			col.Release()
+		}
+		defer rec.Release()
+		return aw.Write(rec)
+	}); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// arrowSchema returns the schema of the Arrow stream of a table.
+func arrowSchema(name string, tbl flux.Table) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(tbl.Cols()))
+	for j, c := range tbl.Cols() {
+		typ, err := arrowType(c.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields[j] = arrow.Field{Name: c.Label, Type: typ, Nullable: true}
+	}
+
+	labels := make([]string, len(tbl.Key().Cols()))
+	for j, c := range tbl.Key().Cols() {
+		labels[j] = c.Label
+	}
+	key, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	md := arrow.NewMetadata(
+		[]string{ArrowResultMetadataKey, ArrowGroupKeyMetadataKey},
+		[]string{name, string(key)},
+	)
+	return arrow.NewSchema(fields, &md), nil
+}
+
+// arrowType returns the Arrow data type of the columns of type typ.
+func arrowType(typ flux.ColType) (arrow.DataType, error) {
+	switch typ {
+	case flux.TBool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case flux.TInt:
+		return arrow.PrimitiveTypes.Int64, nil
+	case flux.TUInt:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case flux.TFloat:
+		return arrow.PrimitiveTypes.Float64, nil
+	case flux.TString:
+		return arrow.BinaryTypes.String, nil
+	case flux.TTime:
+		return arrow.FixedWidthTypes.Timestamp_ns, nil
+	default:
+		return nil, fmt.Errorf("unsupported column type %s", typ)
+	}
+}
+
+// arrowColumn returns column j of cr, whose type is typ, as an array of its
+// Arrow data type. The array shares the buffers of the column and must be
+// released.
+func arrowColumn(cr flux.ColReader, typ flux.ColType, j int) array.Interface {
+	var data *array.Data
+	switch typ {
+	case flux.TBool:
+		data = cr.Bools(j).Data()
+	case flux.TInt:
+		data = cr.Ints(j).Data()
+	case flux.TUInt:
+		data = cr.UInts(j).Data()
+	case flux.TFloat:
+		data = cr.Floats(j).Data()
+	case flux.TString:
+		data = cr.Strings(j).Data()
+	case flux.TTime:
+		data = cr.Times(j).Data()
+	}
+
+	// The type of the column is known to be supported by arrowSchema.
+	dt, _ := arrowType(typ)
+	data = array.NewData(dt, data.Len(), data.Buffers(), nil, data.NullN(), data.Offset())
+	defer data.Release()
+	return array.MakeFromData(data)
+}
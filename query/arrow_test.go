@@ -0,0 +1,83 @@
+package query_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestArrowDialect(t *testing.T) {
+	r := executetest.NewResult([]*executetest.Table{{
+		KeyCols: []string{"host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "host", Type: flux.TString},
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(0), "a", 0.5},
+			{execute.Time(10), "a", nil},
+		},
+	}, {
+		KeyCols: []string{"host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "host", Type: flux.TString},
+			{Label: "_value", Type: flux.TInt},
+		},
+		Data: [][]interface{}{
+			{execute.Time(20), "b", int64(3)},
+		},
+	}})
+	r.Nm = "_result"
+
+	var buf bytes.Buffer
+	if _, err := query.NewArrowDialect().Encoder().Encode(&buf, flux.NewSliceResultIterator([]flux.Result{r})); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each table is read from its own stream.
+	var hosts []string
+	var rows int64
+	for buf.Len() > 0 {
+		rdr, err := ipc.NewReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema := rdr.Schema()
+		md := schema.Metadata()
+		if i := md.FindKey(query.ArrowResultMetadataKey); i < 0 || md.Values()[i] != "_result" {
+			t.Errorf("got schema metadata %v, want result _result", md)
+		}
+		if i := md.FindKey(query.ArrowGroupKeyMetadataKey); i < 0 || md.Values()[i] != `["host"]` {
+			t.Errorf("got schema metadata %v, want group key [\"host\"]", md)
+		}
+		if typ := schema.Field(0).Type; typ.ID() != arrow.TIMESTAMP {
+			t.Errorf("got _time of type %s", typ)
+		}
+
+		for rdr.Next() {
+			rec := rdr.Record()
+			rows += rec.NumRows()
+			hosts = append(hosts, rec.Column(1).(*array.String).Value(0))
+		}
+		if err := rdr.Err(); err != nil {
+			t.Fatal(err)
+		}
+		rdr.Release()
+	}
+
+	if rows != 3 {
+		t.Errorf("got %d rows, want 3", rows)
+	}
+	if len(hosts) != 2 || hosts[0] != "a" || hosts[1] != "b" {
+		t.Errorf("got records of hosts %v, want [a b]", hosts)
+	}
+}
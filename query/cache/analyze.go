@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+)
+
+// pureImports are the packages whose functions neither read data nor have
+// side effects.
+var pureImports = map[string]bool{
+	"date":    true,
+	"math":    true,
+	"regexp":  true,
+	"strings": true,
+}
+
+// uncacheableCalls are the functions of the universe whose results are not
+// only the data of buckets, or which have side effects.
+var uncacheableCalls = map[string]bool{
+	"buckets": true,
+	"to":      true,
+}
+
+// analysis describes a cacheable query.
+type analysis struct {
+	// query and extern are the query and its extern, formatted.
+	query  string
+	extern string
+
+	// bucketNames and bucketIDs are the buckets the query reads.
+	bucketNames []string
+	bucketIDs   []influxdb.ID
+}
+
+// analyze returns the analysis of a query, or false if its results cannot be
+// cached. The results of a query can be cached if it reads nothing but
+// buckets it names, and has no side effects.
+func analyze(q string, extern *ast.File) (*analysis, bool) {
+	pkg := parser.ParseSource(q)
+	if ast.Check(pkg) > 0 {
+		return nil, false
+	}
+
+	a := &analysis{query: ast.Format(pkg)}
+	if extern != nil {
+		a.extern = ast.Format(extern)
+	}
+	strings := externStrings(extern)
+
+	ok := true
+	for _, f := range pkg.Files {
+		for _, imp := range f.Imports {
+			if !pureImports[imp.Path.Value] {
+				return nil, false
+			}
+		}
+		ast.Visit(f, func(n ast.Node) {
+			call, isCall := n.(*ast.CallExpression)
+			if !ok || !isCall {
+				return
+			}
+			switch callee := call.Callee.(type) {
+			case *ast.Identifier:
+				if uncacheableCalls[callee.Name] {
+					ok = false
+				} else if callee.Name == "from" {
+					ok = a.from(call, strings)
+				}
+			case *ast.MemberExpression:
+				if uncacheableCalls[callee.Property.Key()] {
+					ok = false
+				}
+			default:
+				ok = false
+			}
+		})
+	}
+	if !ok || len(a.bucketNames)+len(a.bucketIDs) == 0 {
+		return nil, false
+	}
+	return a, true
+}
+
+// from adds the bucket read by a call to from(), or returns false if the
+// bucket is not known before the query runs or belongs to another
+// organization.
+func (a *analysis) from(call *ast.CallExpression, strings map[string]string) bool {
+	if len(call.Arguments) != 1 {
+		return false
+	}
+	args, ok := call.Arguments[0].(*ast.ObjectExpression)
+	if !ok || args.With != nil {
+		return false
+	}
+
+	for _, p := range args.Properties {
+		v, ok := stringValue(p.Value, strings)
+		if !ok {
+			return false
+		}
+		switch p.Key.Key() {
+		case "bucket":
+			a.bucketNames = append(a.bucketNames, v)
+		case "bucketID":
+			id, err := influxdb.IDFromString(v)
+			if err != nil {
+				return false
+			}
+			a.bucketIDs = append(a.bucketIDs, *id)
+		default:
+			// Buckets of other organizations and hosts are not cached.
+			return false
+		}
+	}
+	return true
+}
+
+// stringValue returns the value of a string literal, or of a property of a
+// record in the extern, like params.bucket or v.bucket.
+func stringValue(e ast.Expression, strings map[string]string) (string, bool) {
+	switch e := e.(type) {
+	case *ast.StringLiteral:
+		return e.Value, true
+	case *ast.MemberExpression:
+		obj, ok := e.Object.(*ast.Identifier)
+		if !ok {
+			return "", false
+		}
+		v, ok := strings[obj.Name+"."+e.Property.Key()]
+		return v, ok
+	default:
+		return "", false
+	}
+}
+
+// externStrings returns the string properties of the records assigned in
+// extern, keyed by the record identifier and the property key.
+func externStrings(extern *ast.File) map[string]string {
+	strings := make(map[string]string)
+	if extern == nil {
+		return strings
+	}
+	for _, stmt := range extern.Body {
+		if opt, ok := stmt.(*ast.OptionStatement); ok {
+			stmt = opt.Assignment
+		}
+		va, ok := stmt.(*ast.VariableAssignment)
+		if !ok {
+			continue
+		}
+		obj, ok := va.Init.(*ast.ObjectExpression)
+		if !ok || obj.With != nil {
+			continue
+		}
+		for _, p := range obj.Properties {
+			if s, ok := p.Value.(*ast.StringLiteral); ok {
+				strings[va.ID.Name+"."+p.Key.Key()] = s.Value
+			}
+		}
+	}
+	return strings
+}
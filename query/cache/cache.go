@@ -0,0 +1,373 @@
+// Package cache caches the results of queries, so that identical queries run
+// at about the same time, such as those of a dashboard loaded by many users,
+// are executed once.
+//
+// Queries are cached by organization, formatted query source and dialect,
+// and are run as of the start of the current window of the cache's TTL, so
+// that their relative time ranges select the same data all window long.
+// Results are invalidated when the buckets they read are written to or
+// deleted from, and expire at the end of the window.
+//
+// Only queries known to read nothing but the buckets of their organization
+// are cached; queries with side effects, such as those calling to(), and
+// queries reading from other sources are always executed.
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Config configures a Cache.
+type Config struct {
+	// TTL is how long results are cached for. Queries are run as of the
+	// start of the current window of the TTL.
+	TTL time.Duration
+
+	// MaxBytes is the maximum size of the cached results. The oldest results
+	// are evicted to make room for new ones.
+	MaxBytes int64
+}
+
+// Cache caches the results of queries until their TTL expires or the
+// buckets they read change.
+type Cache struct {
+	config        Config
+	bucketService influxdb.BucketService
+	log           *zap.Logger
+	now           func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	byBucket map[influxdb.ID]map[*entry]struct{}
+	order    *list.List // Completed entries, oldest first.
+	size     int64
+
+	metrics *metrics
+}
+
+// entry is the cached results of a query. Queries identical to one in
+// flight wait for its results.
+type entry struct {
+	key     string
+	buckets []influxdb.ID
+	expires time.Time
+
+	done    chan struct{} // Closed once the query completes.
+	results []byte        // Nil if the query failed, or was invalidated.
+	stale   bool          // Set if a bucket changes while the query is in flight.
+	elem    *list.Element
+}
+
+// New returns a cache with config, which finds the buckets queries read by
+// name in bucketService.
+func New(log *zap.Logger, bucketService influxdb.BucketService, config Config) *Cache {
+	return &Cache{
+		config:        config,
+		bucketService: bucketService,
+		log:           log,
+		now:           time.Now,
+		entries:       make(map[string]*entry),
+		byBucket:      make(map[influxdb.ID]map[*entry]struct{}),
+		order:         list.New(),
+		metrics:       newMetrics(),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return c.metrics.PrometheusCollectors()
+}
+
+// ProxyQueryService returns a query service that serves the queries of next
+// from the cache.
+func (c *Cache) ProxyQueryService(next query.ProxyQueryService) query.ProxyQueryService {
+	return &proxyQueryService{cache: c, next: next}
+}
+
+// Invalidate evicts the results of the queries that read the bucket with id.
+func (c *Cache) Invalidate(id influxdb.ID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for e := range c.byBucket[id] {
+		if e.elem == nil {
+			// The query is in flight, so its results may or may not
+			// include the change.
+			e.stale = true
+			continue
+		}
+		c.remove(e)
+	}
+}
+
+// lookup returns the entry for key, and true if the caller is to run the
+// query and complete the entry.
+func (c *Cache) lookup(key string, buckets []influxdb.ID, expires time.Time) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		if e.elem == nil || c.now().Before(e.expires) {
+			return e, false
+		}
+		c.remove(e)
+	}
+
+	e := &entry{
+		key:     key,
+		buckets: buckets,
+		expires: expires,
+		done:    make(chan struct{}),
+	}
+	c.entries[key] = e
+	for _, id := range buckets {
+		m := c.byBucket[id]
+		if m == nil {
+			m = make(map[*entry]struct{})
+			c.byBucket[id] = m
+		}
+		m[e] = struct{}{}
+	}
+	return e, true
+}
+
+// complete stores the results of the query of e, unless they could be stale
+// or are too large, and releases the queries waiting for them. Results is
+// nil if the query failed.
+func (c *Cache) complete(e *entry, results []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(e.done)
+
+	if results == nil || e.stale || int64(len(results)) > c.config.MaxBytes {
+		c.remove(e)
+		return
+	}
+
+	// Evict expired results, and then the oldest, to make room.
+	now := c.now()
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if old := el.Value.(*entry); !now.Before(old.expires) {
+			c.remove(old)
+		}
+		el = next
+	}
+	for c.size+int64(len(results)) > c.config.MaxBytes {
+		c.remove(c.order.Front().Value.(*entry))
+		c.metrics.evictions.Inc()
+	}
+
+	e.results = results
+	e.elem = c.order.PushBack(e)
+	c.size += int64(len(results))
+	c.metrics.bytes.Set(float64(c.size))
+}
+
+// remove removes e from the cache. Queries waiting for e keep its results.
+func (c *Cache) remove(e *entry) {
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+	for _, id := range e.buckets {
+		delete(c.byBucket[id], e)
+		if len(c.byBucket[id]) == 0 {
+			delete(c.byBucket, id)
+		}
+	}
+	if e.elem != nil {
+		c.order.Remove(e.elem)
+		c.size -= int64(len(e.results))
+		c.metrics.bytes.Set(float64(c.size))
+	}
+}
+
+// request returns the key of req and the buckets it reads, along with the
+// request to run as of the start of the current window, or false if its
+// results cannot be cached.
+func (c *Cache) request(ctx context.Context, req *query.ProxyRequest) (string, []influxdb.ID, *query.ProxyRequest, time.Time, bool) {
+	compiler, ok := req.Request.Compiler.(lang.FluxCompiler)
+	if !ok || req.Request.Authorization == nil {
+		return "", nil, nil, time.Time{}, false
+	}
+	switch req.Dialect.(type) {
+	case *query.NoContentDialect, *query.NoContentWithErrorDialect:
+		// Results are not wanted, so the query is run for its side effects.
+		return "", nil, nil, time.Time{}, false
+	}
+
+	a, ok := analyze(compiler.Query, compiler.Extern)
+	if !ok {
+		return "", nil, nil, time.Time{}, false
+	}
+
+	orgID := req.Request.OrganizationID
+	buckets := make([]influxdb.ID, 0, len(a.bucketIDs)+len(a.bucketNames))
+	buckets = append(buckets, a.bucketIDs...)
+	for _, name := range a.bucketNames {
+		b, err := c.bucketService.FindBucketByName(ctx, orgID, name)
+		if err != nil {
+			// The query fails to find the bucket too.
+			return "", nil, nil, time.Time{}, false
+		}
+		buckets = append(buckets, b.ID)
+	}
+
+	// Results may have been cached for other users, so only users who can
+	// read every series of each bucket are served them.
+	for _, id := range buckets {
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		if err != nil || !req.Request.Authorization.Allowed(*p) || req.Request.Authorization.SeriesScope(*p) != nil {
+			return "", nil, nil, time.Time{}, false
+		}
+	}
+
+	now := compiler.Now
+	if now.IsZero() {
+		now = c.now()
+	}
+	now = now.Truncate(c.config.TTL)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%d\x00%T%+v", orgID, a.query, a.extern, now.UnixNano(), req.Dialect, req.Dialect)
+	key := hex.EncodeToString(h.Sum(nil))
+
+	compiler.Now = now
+	r := *req
+	r.Request.Compiler = compiler
+	return key, buckets, &r, now.Add(c.config.TTL), true
+}
+
+// proxyQueryService serves queries from a cache.
+type proxyQueryService struct {
+	cache *Cache
+	next  query.ProxyQueryService
+}
+
+// Query writes the cached results of req to w, or runs it and caches its
+// results.
+func (s *proxyQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	key, buckets, r, expires, ok := s.cache.request(ctx, req)
+	if !ok {
+		s.cache.metrics.requests.WithLabelValues("bypass").Inc()
+		return s.next.Query(ctx, w, req)
+	}
+
+	e, run := s.cache.lookup(key, buckets, expires)
+	if !run {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return flux.Statistics{}, ctx.Err()
+		}
+		if e.results != nil {
+			s.cache.metrics.requests.WithLabelValues("hit").Inc()
+			_, err := w.Write(e.results)
+			return flux.Statistics{Metadata: flux.Metadata{"cache": []interface{}{"hit"}}}, err
+		}
+		// The query failed or was invalidated, so it is run again.
+		s.cache.metrics.requests.WithLabelValues("bypass").Inc()
+		return s.next.Query(ctx, w, r)
+	}
+
+	s.cache.metrics.requests.WithLabelValues("miss").Inc()
+	buf := &limitedBuffer{max: s.cache.config.MaxBytes}
+	stats, err := s.next.Query(ctx, io.MultiWriter(w, buf), r)
+	if err != nil || buf.overflow {
+		s.cache.complete(e, nil)
+		return stats, err
+	}
+	s.cache.complete(e, buf.Bytes())
+	return stats, nil
+}
+
+// Check checks the query service the cache is in front of.
+func (s *proxyQueryService) Check(ctx context.Context) check.Response {
+	return s.next.Check(ctx)
+}
+
+// limitedBuffer buffers up to max bytes, and drops what is written after.
+type limitedBuffer struct {
+	bytes.Buffer
+	max      int64
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow || int64(b.Len()+len(p)) > b.max {
+		b.overflow = true
+		b.Reset()
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// PointsWriter writes points.
+type PointsWriter interface {
+	WritePoints(context.Context, []models.Point) error
+}
+
+// PointsWriter returns a points writer that invalidates the results of the
+// queries reading the buckets next writes to.
+func (c *Cache) PointsWriter(next PointsWriter) PointsWriter {
+	return &pointsWriter{cache: c, next: next}
+}
+
+type pointsWriter struct {
+	cache *Cache
+	next  PointsWriter
+}
+
+// WritePoints writes points, and then invalidates the results of the
+// queries reading the buckets written to, even if the write failed part way.
+func (w *pointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	defer func() {
+		var last influxdb.ID
+		for _, p := range points {
+			if _, id := tsdb.DecodeNameSlice(p.Name()); id != last {
+				w.cache.Invalidate(id)
+				last = id
+			}
+		}
+	}()
+	return w.next.WritePoints(ctx, points)
+}
+
+// DeleteService returns a delete service that invalidates the results of
+// the queries reading the buckets next deletes from.
+func (c *Cache) DeleteService(next influxdb.DeleteService) influxdb.DeleteService {
+	return &deleteService{cache: c, next: next}
+}
+
+type deleteService struct {
+	cache *Cache
+	next  influxdb.DeleteService
+}
+
+// DeleteBucketRangePredicate deletes data, and then invalidates the results
+// of the queries reading the bucket.
+func (s *deleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	defer s.cache.Invalidate(bucketID)
+	return s.next.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
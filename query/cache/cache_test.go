@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	influxmock "github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID    = influxdb.ID(1)
+	bucketID = influxdb.ID(2)
+)
+
+func TestAnalyze(t *testing.T) {
+	extern := &ast.File{Body: []ast.Statement{
+		&ast.OptionStatement{Assignment: &ast.VariableAssignment{
+			ID: &ast.Identifier{Name: "v"},
+			Init: &ast.ObjectExpression{Properties: []*ast.Property{
+				{Key: &ast.Identifier{Name: "bucket"}, Value: &ast.StringLiteral{Value: "b2"}},
+			}},
+		}},
+	}}
+
+	for _, tt := range []struct {
+		query   string
+		buckets []string
+		ok      bool
+	}{
+		{query: `from(bucket: "b") |> range(start: -1h)`, buckets: []string{"b"}, ok: true},
+		{query: `import "strings" from(bucket: "b") |> range(start: -1h) |> map(fn: (r) => ({r with m: strings.toUpper(v: r._measurement)}))`, buckets: []string{"b"}, ok: true},
+		{query: `from(bucket: v.bucket) |> range(start: -1h)`, buckets: []string{"b2"}, ok: true},
+		{query: `from(bucketID: "0000000000000002") |> range(start: -1h)`, ok: true},
+		{query: `from(bucket: v.other) |> range(start: -1h)`},
+		{query: `from(bucket: "b", org: "other") |> range(start: -1h)`},
+		{query: `from(bucket: "b") |> range(start: -1h) |> to(bucket: "c")`},
+		{query: `import "experimental" from(bucket: "b") |> range(start: -1h) |> experimental.to(bucket: "c")`},
+		{query: `import "http" http.post(url: "http://example.com")`},
+		{query: `buckets()`},
+		{query: `from(bucket: `},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			a, ok := analyze(tt.query, extern)
+			if ok != tt.ok {
+				t.Fatalf("got cacheable %v, expected %v", ok, tt.ok)
+			}
+			if ok && fmt.Sprint(a.bucketNames) != fmt.Sprint(tt.buckets) {
+				t.Errorf("got buckets %v, expected %v", a.bucketNames, tt.buckets)
+			}
+		})
+	}
+}
+
+// newTestCache returns a cache in front of a query service writing the
+// number of queries it ran, and the time the last was run as of.
+func newTestCache(t *testing.T) (*Cache, query.ProxyQueryService, *int) {
+	bucketService := influxmock.NewBucketService()
+	bucketService.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+	}
+	c := New(zaptest.NewLogger(t), bucketService, Config{TTL: time.Minute, MaxBytes: 1024})
+	c.now = func() time.Time { return time.Unix(600, 0) }
+
+	var runs int
+	next := &mock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			runs++
+			_, err := fmt.Fprintf(w, "run %d as of %d", runs, req.Request.Compiler.(lang.FluxCompiler).Now.Unix())
+			return flux.Statistics{}, err
+		},
+	}
+	return c, c.ProxyQueryService(next), &runs
+}
+
+func newTestRequest(q string, now time.Time, permissions ...influxdb.Permission) *query.ProxyRequest {
+	if permissions == nil {
+		permissions = []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: idPtr(orgID)},
+		}}
+	}
+	return &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  &influxdb.Authorization{OrgID: orgID, Status: influxdb.Active, Permissions: permissions},
+			OrganizationID: orgID,
+			Compiler:       lang.FluxCompiler{Query: q, Now: now},
+		},
+		Dialect: &csv.Dialect{},
+	}
+}
+
+func runQuery(t *testing.T, s query.ProxyQueryService, req *query.ProxyRequest) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := s.Query(context.Background(), &buf, req); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCache(t *testing.T) {
+	c, s, runs := newTestCache(t)
+	q := `from(bucket: "b") |> range(start: -1h)`
+
+	if got := runQuery(t, s, newTestRequest(q, time.Unix(610, 0))); got != "run 1 as of 600" {
+		t.Fatalf("got results %q, expected the query to run as of the start of the window", got)
+	}
+	// The same query, formatted differently and run later in the window,
+	// is served from the cache.
+	if got := runQuery(t, s, newTestRequest("from(bucket:\"b\")\n  |> range(start: -1h)", time.Unix(650, 0))); got != "run 1 as of 600" {
+		t.Fatalf("got results %q, expected the cached results", got)
+	}
+
+	// Users who cannot read the bucket are not served the results.
+	other := newTestRequest(q, time.Unix(610, 0), influxdb.Permission{
+		Action:   influxdb.ReadAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: idPtr(orgID), ID: idPtr(bucketID + 1)},
+	})
+	if got := runQuery(t, s, other); got != "run 2 as of 610" {
+		t.Fatalf("got results %q, expected the query to run", got)
+	}
+
+	// Writes to the bucket invalidate the results.
+	pw := c.PointsWriter(pointsWriterFunc(func(ctx context.Context, points []models.Point) error { return nil }))
+	name := tsdb.EncodeNameSlice(orgID, bucketID)
+	if err := pw.WritePoints(context.Background(), []models.Point{models.MustNewPoint(string(name), nil, models.Fields{"v": 1.0}, time.Unix(0, 0))}); err != nil {
+		t.Fatal(err)
+	}
+	if got := runQuery(t, s, newTestRequest(q, time.Unix(610, 0))); got != "run 3 as of 600" {
+		t.Fatalf("got results %q, expected the query to run", got)
+	}
+	if got := runQuery(t, s, newTestRequest(q, time.Unix(610, 0))); got != "run 3 as of 600" {
+		t.Fatalf("got results %q, expected the cached results", got)
+	}
+
+	// Queries with side effects are always run.
+	if got := runQuery(t, s, newTestRequest(q+` |> to(bucket: "c")`, time.Unix(610, 0))); got != "run 4 as of 610" {
+		t.Fatalf("got results %q, expected the query to run", got)
+	}
+	if *runs != 4 {
+		t.Fatalf("got %d runs, expected 4", *runs)
+	}
+}
+
+type pointsWriterFunc func(ctx context.Context, points []models.Point) error
+
+func (f pointsWriterFunc) WritePoints(ctx context.Context, points []models.Point) error {
+	return f(ctx, points)
+}
+
+func idPtr(id influxdb.ID) *influxdb.ID {
+	return &id
+}
@@ -0,0 +1,48 @@
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	namespace = "query"
+	subsystem = "cache"
+)
+
+type metrics struct {
+	requests  *prometheus.CounterVec
+	evictions prometheus.Counter
+	bytes     prometheus.Gauge
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Count of the queries served by the cache, by whether their results were cached or cannot be",
+		}, []string{"result"}),
+
+		evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Count of the results evicted to make room for others",
+		}),
+
+		bytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "bytes",
+			Help:      "Size of the cached results",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *metrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.requests,
+		m.evictions,
+		m.bytes,
+	}
+}
@@ -0,0 +1,134 @@
+package control
+
+import (
+	"sync"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// DefaultPriorityWeights are the weights of the priorities of queries used
+// when none are configured.
+var DefaultPriorityWeights = map[query.Priority]int{
+	query.PriorityInteractive: 8,
+	query.PriorityDashboard:   4,
+	query.PriorityBatch:       1,
+}
+
+// admissionQueue holds the queries waiting to be executed. Queries are
+// admitted by weighted round robin between their priorities, and round robin
+// between the organizations waiting in each priority, so that neither
+// background queries nor a busy organization can starve the others.
+type admissionQueue struct {
+	mu      sync.Mutex
+	size    int
+	len     int
+	classes []*priorityQueue
+
+	// ready holds a token for each query pushed to the queue, which is
+	// received before it is popped.
+	ready chan struct{}
+}
+
+// priorityQueue holds the waiting queries of a priority by organization.
+type priorityQueue struct {
+	weight int
+
+	// credit is the share of admissions the priority is owed, as counted by
+	// smooth weighted round robin.
+	credit int
+
+	len   int
+	orgs  map[influxdb.ID][]*Query
+	order []influxdb.ID // Organizations with waiting queries, next first.
+}
+
+func newAdmissionQueue(size int, weights map[query.Priority]int) *admissionQueue {
+	aq := &admissionQueue{
+		size:    size,
+		classes: make([]*priorityQueue, len(query.Priorities)),
+		ready:   make(chan struct{}, size),
+	}
+	for _, p := range query.Priorities {
+		aq.classes[p] = &priorityQueue{
+			weight: weights[p],
+			orgs:   make(map[influxdb.ID][]*Query),
+		}
+	}
+	return aq
+}
+
+// Len returns the number of waiting queries.
+func (aq *admissionQueue) Len() int {
+	aq.mu.Lock()
+	defer aq.mu.Unlock()
+	return aq.len
+}
+
+// Size returns the maximum number of waiting queries.
+func (aq *admissionQueue) Size() int {
+	return aq.size
+}
+
+// push adds q to the queue, unless it is full.
+func (aq *admissionQueue) push(q *Query) bool {
+	aq.mu.Lock()
+	if aq.len >= aq.size {
+		aq.mu.Unlock()
+		return false
+	}
+	pq := aq.classes[q.priority]
+	if len(pq.orgs[q.orgID]) == 0 {
+		pq.order = append(pq.order, q.orgID)
+	}
+	pq.orgs[q.orgID] = append(pq.orgs[q.orgID], q)
+	pq.len++
+	aq.len++
+	aq.mu.Unlock()
+
+	// There are never more tokens than queries, so this does not block.
+	aq.ready <- struct{}{}
+	return true
+}
+
+// pop removes and returns the next query to execute. A token must have been
+// received from ready before it is called.
+func (aq *admissionQueue) pop() *Query {
+	aq.mu.Lock()
+	defer aq.mu.Unlock()
+
+	var (
+		next  *priorityQueue
+		total int
+	)
+	for _, pq := range aq.classes {
+		if pq.len == 0 {
+			continue
+		}
+		pq.credit += pq.weight
+		total += pq.weight
+		if next == nil || pq.credit > next.credit {
+			next = pq
+		}
+	}
+	next.credit -= total
+
+	orgID := next.order[0]
+	queries := next.orgs[orgID]
+	q := queries[0]
+	queries[0] = nil
+	if len(queries) == 1 {
+		delete(next.orgs, orgID)
+		next.order = next.order[1:]
+	} else {
+		next.orgs[orgID] = queries[1:]
+		next.order = append(next.order[1:], orgID)
+	}
+	next.len--
+	if next.len == 0 {
+		// A priority is owed nothing for the time it had no queries waiting.
+		next.credit = 0
+	}
+	aq.len--
+	return q
+}
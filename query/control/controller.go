@@ -30,6 +30,7 @@ import (
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/kit/errors"
 	"github.com/influxdata/influxdb/kit/prom"
 	"github.com/influxdata/influxdb/kit/tracing"
@@ -50,7 +51,7 @@ type Controller struct {
 	lastID     uint64
 	queriesMu  sync.RWMutex
 	queries    map[QueryID]*Query
-	queryQueue chan *Query
+	queryQueue *admissionQueue
 	wg         sync.WaitGroup
 	shutdown   bool
 	done       chan struct{}
@@ -58,6 +59,11 @@ type Controller struct {
 	abort      chan struct{}
 	memory     *memoryManager
 
+	// orgMemory holds the memory quotas of the queries of each
+	// organization that had one.
+	orgMemoryMu sync.RWMutex
+	orgMemory   map[influxdb.ID]*orgMemory
+
 	metrics   *controllerMetrics
 	labelKeys []string
 
@@ -92,7 +98,13 @@ type Config struct {
 	// QueueSize is the number of queries that are allowed to be awaiting execution before new queries are
 	// rejected.
 	QueueSize int
-	Logger    *zap.Logger
+
+	// PriorityWeights are the shares of the queries admitted for execution
+	// given to each priority while queries of several priorities wait. If
+	// it is unset, DefaultPriorityWeights are used.
+	PriorityWeights map[query.Priority]int
+
+	Logger *zap.Logger
 	// MetricLabelKeys is a list of labels to add to the metrics produced by the controller.
 	// The value for a given key will be read off the context.
 	// The context value must be a string or an implementation of the Stringer interface.
@@ -108,6 +120,9 @@ func (c *Config) complete() (Config, error) {
 	if config.InitialMemoryBytesQuotaPerQuery == 0 {
 		config.InitialMemoryBytesQuotaPerQuery = config.MemoryBytesQuotaPerQuery
 	}
+	if config.PriorityWeights == nil {
+		config.PriorityWeights = DefaultPriorityWeights
+	}
 
 	if err := config.validate(true); err != nil {
 		return Config{}, err
@@ -136,6 +151,13 @@ func (c *Config) validate(isComplete bool) error {
 	if c.QueueSize <= 0 {
 		return errors.New("QueueSize must be positive")
 	}
+	if c.PriorityWeights != nil {
+		for _, p := range query.Priorities {
+			if c.PriorityWeights[p] <= 0 {
+				return fmt.Errorf("PriorityWeights must be positive for the %s priority", p)
+			}
+		}
+	}
 	return nil
 }
 
@@ -174,10 +196,11 @@ func New(config Config) (*Controller, error) {
 	}
 	ctrl := &Controller{
 		queries:      make(map[QueryID]*Query),
-		queryQueue:   make(chan *Query, c.QueueSize),
+		queryQueue:   newAdmissionQueue(c.QueueSize, c.PriorityWeights),
 		done:         make(chan struct{}),
 		abort:        make(chan struct{}),
 		memory:       mm,
+		orgMemory:    make(map[influxdb.ID]*orgMemory),
 		log:          logger,
 		metrics:      newControllerMetrics(c.MetricLabelKeys),
 		labelKeys:    c.MetricLabelKeys,
@@ -198,6 +221,13 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if !req.Priority.Valid() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown query priority %d", int(req.Priority)),
+		}
+	}
+
 	// Set the request on the context so platform specific Flux operations can retrieve it later.
 	ctx = query.ContextWithRequest(ctx, req)
 	// Set the org label value for controller metrics
@@ -206,7 +236,7 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	for _, dep := range c.dependencies {
 		ctx = dep.Inject(ctx)
 	}
-	q, err := c.query(ctx, req.Compiler)
+	q, err := c.query(ctx, req.OrganizationID, req.Compiler)
 	if err != nil {
 		return q, err
 	}
@@ -216,8 +246,8 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 
 // query submits a query for execution returning immediately.
 // Done must be called on any returned Query objects.
-func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Query, error) {
-	q, err := c.createQuery(ctx, compiler.CompilerType())
+func (c *Controller) query(ctx context.Context, orgID influxdb.ID, compiler flux.Compiler) (flux.Query, error) {
+	q, err := c.createQuery(ctx, orgID, compiler.CompilerType())
 	if err != nil {
 		return nil, handleFluxError(err)
 	}
@@ -237,7 +267,7 @@ func (c *Controller) query(ctx context.Context, compiler flux.Compiler) (flux.Qu
 	return q, nil
 }
 
-func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Query, error) {
+func (c *Controller) createQuery(ctx context.Context, orgID influxdb.ID, ct flux.CompilerType) (*Query, error) {
 	c.queriesMu.RLock()
 	if c.shutdown {
 		c.queriesMu.RUnlock()
@@ -262,7 +292,10 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	}
 	compileLabelValues[len(compileLabelValues)-1] = string(ct)
 
-	cctx, cancel := context.WithCancel(ctx)
+	// The storage reads of the query count the data they scan in
+	// scanStats, which is reported by the running query API.
+	scanStats := &query.ScanStatistics{}
+	cctx, cancel := context.WithCancel(query.ContextWithScanStatistics(ctx, scanStats))
 	parentSpan, parentCtx := StartSpanFromContext(
 		cctx,
 		"all",
@@ -271,6 +304,7 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	)
 	q := &Query{
 		id:                 id,
+		orgID:              orgID,
 		labelValues:        labelValues,
 		compileLabelValues: compileLabelValues,
 		state:              Created,
@@ -280,6 +314,13 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 		parentSpan:         parentSpan,
 		cancel:             cancel,
 		doneCh:             make(chan struct{}),
+		startedAt:          time.Now(),
+		scanStats:          scanStats,
+	}
+	if req := query.RequestFromContext(ctx); req != nil {
+		q.source = req.Source
+		q.priority = req.Priority
+		q.text = req.QueryText()
 	}
 
 	// Lock the queries mutex for the rest of this method.
@@ -362,15 +403,12 @@ func (c *Controller) enqueueQuery(q *Query) error {
 		}
 	}
 
-	select {
-	case c.queryQueue <- q:
-	default:
+	if !c.queryQueue.push(q) {
 		return &flux.Error{
 			Code: codes.ResourceExhausted,
 			Msg:  "queue length exceeded",
 		}
 	}
-
 	return nil
 }
 
@@ -379,8 +417,8 @@ func (c *Controller) processQueryQueue() {
 		select {
 		case <-c.done:
 			return
-		case q := <-c.queryQueue:
-			c.executeQuery(q)
+		case <-c.queryQueue.ready:
+			c.executeQuery(c.queryQueue.pop())
 		}
 	}
 }
@@ -418,7 +456,10 @@ func (c *Controller) executeQuery(q *Query) {
 		return
 	}
 
-	q.c.createAllocator(q)
+	if err := q.c.createAllocator(q); err != nil {
+		q.setErr(err)
+		return
+	}
 	exec, err := q.program.Start(ctx, q.alloc)
 	if err != nil {
 		q.setErr(err)
@@ -456,6 +497,28 @@ func (c *Controller) Queries() []*Query {
 	return queries
 }
 
+// CheckName returns the name of the controller's health check.
+func (c *Controller) CheckName() string {
+	return "query-controller"
+}
+
+// Check reports whether the controller accepts queries. It is degraded
+// once its queue is full, as new queries are then rejected until a
+// queued query starts.
+func (c *Controller) Check(ctx context.Context) check.Response {
+	c.queriesMu.RLock()
+	shutdown, active := c.shutdown, len(c.queries)
+	c.queriesMu.RUnlock()
+	if shutdown {
+		return check.Fail("shutdown", "query controller is shut down")
+	}
+
+	if queued, size := c.queryQueue.Len(), c.queryQueue.Size(); queued >= size {
+		return check.Warn("queue-full", "query queue is full with %d queries", queued)
+	}
+	return check.Info("%d active queries", active)
+}
+
 // Shutdown will signal to the Controller that it should not accept any
 // new queries and that it should finish executing any existing queries.
 // This will return once the Controller's run loop has been exited and all
@@ -507,7 +570,18 @@ func (c *Controller) PrometheusCollectors() []prometheus.Collector {
 
 // Query represents a single request.
 type Query struct {
-	id QueryID
+	id    QueryID
+	orgID influxdb.ID
+
+	// priority decides when the query is admitted for execution.
+	priority query.Priority
+
+	// source, text, startedAt and scanStats describe the query to the
+	// running query API.
+	source    string
+	text      string
+	startedAt time.Time
+	scanStats *query.ScanStatistics
 
 	labelValues        []string
 	compileLabelValues []string
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/arrow"
 	"github.com/influxdata/flux/codes"
@@ -20,6 +21,8 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/stdlib/universe"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/builtin"
 	"github.com/influxdata/influxdb/query/control"
@@ -318,6 +321,9 @@ func TestController_AfterShutdown(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	if got := ctrl.Check(context.Background()); got.Status != check.StatusPass {
+		t.Errorf("unexpected check status -want/+got\n\t- %q\n\t+ %q", check.StatusPass, got.Status)
+	}
 	shutdown(t, ctrl)
 
 	// No point in continuing. The shutdown didn't work
@@ -326,6 +332,10 @@ func TestController_AfterShutdown(t *testing.T) {
 		return
 	}
 
+	if got := ctrl.Check(context.Background()); got.Status != check.StatusFail || got.Reason != "shutdown" {
+		t.Errorf("unexpected check status %q with reason %q", got.Status, got.Reason)
+	}
+
 	if _, err := ctrl.Query(context.Background(), makeRequest(mockCompiler)); err == nil {
 		t.Error("expected error")
 	} else if got, want := err.Error(), "query controller shutdown"; got != want {
@@ -791,12 +801,128 @@ func TestController_QueueSize(t *testing.T) {
 		}()
 	}
 
+	if got := ctrl.Check(context.Background()); got.Status != check.StatusWarn || got.Reason != "queue-full" {
+		t.Errorf("unexpected check status %q with reason %q", got.Status, got.Reason)
+	}
+
 	_, err = ctrl.Query(context.Background(), makeRequest(compiler))
 	if err == nil {
 		t.Fatal("expected an error about queue length exceeded")
 	}
 }
 
+func TestController_PriorityAdmission(t *testing.T) {
+	config := config
+	config.QueueSize = 6
+	config.PriorityWeights = map[query.Priority]int{
+		query.PriorityInteractive: 2,
+		query.PriorityDashboard:   2,
+		query.PriorityBatch:       1,
+	}
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	var (
+		mu       sync.Mutex
+		executed []string
+	)
+	compiler := func(name string, block chan struct{}) flux.Compiler {
+		return &mock.Compiler{
+			CompileFn: func(ctx context.Context) (flux.Program, error) {
+				return &mock.Program{
+					ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+						mu.Lock()
+						executed = append(executed, name)
+						mu.Unlock()
+						if block != nil {
+							<-block
+						}
+					},
+				}, nil
+			},
+		}
+	}
+	run := func(name string, orgID platform.ID, priority query.Priority, block chan struct{}) *sync.WaitGroup {
+		q, err := ctrl.Query(context.Background(), &query.Request{
+			OrganizationID: orgID,
+			Compiler:       compiler(name, block),
+			Priority:       priority,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range q.Results() {
+				// discard the results
+			}
+			q.Done()
+		}()
+		return &wg
+	}
+
+	// Queue queries behind one holding the only slot of execution.
+	block := make(chan struct{})
+	run("blocking", 1, query.PriorityInteractive, block)
+	for {
+		mu.Lock()
+		n := len(executed)
+		mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	var queued []*sync.WaitGroup
+	for _, q := range []struct {
+		name     string
+		orgID    platform.ID
+		priority query.Priority
+	}{
+		{name: "batch 1", orgID: 1, priority: query.PriorityBatch},
+		{name: "batch 2", orgID: 1, priority: query.PriorityBatch},
+		{name: "org 1 a", orgID: 1, priority: query.PriorityInteractive},
+		{name: "org 1 b", orgID: 1, priority: query.PriorityInteractive},
+		{name: "org 1 c", orgID: 1, priority: query.PriorityInteractive},
+		{name: "org 2", orgID: 2, priority: query.PriorityInteractive},
+	} {
+		queued = append(queued, run(q.name, q.orgID, q.priority, nil))
+	}
+	close(block)
+	for _, wg := range queued {
+		wg.Wait()
+	}
+
+	// Interactive queries are admitted twice as often as batch ones, which
+	// are not starved, and organizations take turns.
+	want := []string{"blocking", "org 1 a", "batch 1", "org 2", "org 1 b", "batch 2", "org 1 c"}
+	mu.Lock()
+	defer mu.Unlock()
+	if !cmp.Equal(want, executed) {
+		t.Fatalf("unexpected order of execution -want/+got:\n%s", cmp.Diff(want, executed))
+	}
+}
+
+func TestController_InvalidPriority(t *testing.T) {
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	req := makeRequest(mockCompiler)
+	req.Priority = query.Priority(len(query.Priorities))
+	if _, err := ctrl.Query(context.Background(), req); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("expected an invalid priority to be rejected, got %v", err)
+	}
+}
+
 // Test that rapidly starting and canceling the query and then calling done will correctly
 // cancel the query and not result in a race condition.
 func TestController_CancelDone(t *testing.T) {
@@ -1254,6 +1380,234 @@ func TestController_ReserveMemoryWithoutExceedingMax(t *testing.T) {
 	}
 }
 
+func TestController_OrgMemoryQuotaPerQuery(t *testing.T) {
+	config := config
+	config.InitialMemoryBytesQuotaPerQuery = 16
+	config.MemoryBytesQuotaPerQuery = 4096
+
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	const limitedOrg, otherOrg = platform.ID(1), platform.ID(2)
+	ctrl.SetOrgQuota(&platform.OrgQuota{OrgID: limitedOrg, MaxQueryMemoryBytesPerQuery: 1024})
+
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					if err := alloc.Account(2048); err != nil {
+						q.SetErr(err)
+					}
+				},
+			}, nil
+		},
+	}
+
+	for _, tt := range []struct {
+		orgID   platform.ID
+		wantErr bool
+	}{
+		{orgID: limitedOrg, wantErr: true},
+		{orgID: otherOrg},
+	} {
+		req := makeRequest(compiler)
+		req.OrganizationID = tt.orgID
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range q.Results() {
+			// discard the results
+		}
+		q.Done()
+
+		if err := q.Err(); tt.wantErr != (err != nil) {
+			t.Errorf("unexpected error of a query of org %s: %v", tt.orgID, err)
+		}
+	}
+}
+
+func TestController_OrgMaxMemory(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 2
+	config.QueueSize = 2
+	config.InitialMemoryBytesQuotaPerQuery = 16
+	config.MemoryBytesQuotaPerQuery = 4096
+
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	orgID := platform.ID(1)
+	ctrl.SetOrgQuota(&platform.OrgQuota{OrgID: orgID, MaxQueryMemoryBytes: 2048})
+
+	allocated, release := make(chan struct{}), make(chan struct{})
+	newCompiler := func(size int, hold bool) flux.Compiler {
+		return &mock.Compiler{
+			CompileFn: func(ctx context.Context) (flux.Program, error) {
+				return &mock.Program{
+					ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+						if err := alloc.Account(size); err != nil {
+							q.SetErr(err)
+							return
+						}
+						if hold {
+							close(allocated)
+							<-release
+						}
+					},
+				}, nil
+			},
+		}
+	}
+	run := func(c flux.Compiler) flux.Query {
+		req := makeRequest(c)
+		req.OrganizationID = orgID
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+
+	// The first query holds most of the memory of the organization, so
+	// the second one cannot have the memory it needs.
+	held := run(newCompiler(1500, true))
+	<-allocated
+
+	q := run(newCompiler(1000, false))
+	for range q.Results() {
+		// discard the results
+	}
+	q.Done()
+	if err := q.Err(); err == nil {
+		t.Fatal("expected the query to exceed the memory of the organization")
+	} else if got, want := platform.ErrorCode(err), platform.EInvalid; got != want {
+		t.Errorf("unexpected error code: got %s, want %s", got, want)
+	}
+
+	// The memory of the first query is given back once it is done.
+	close(release)
+	consumeResults(t, held)
+	consumeResults(t, run(newCompiler(1000, false)))
+
+	// Removing the quota lifts the limit.
+	ctrl.SetOrgQuota(&platform.OrgQuota{OrgID: orgID})
+	consumeResults(t, run(newCompiler(3000, false)))
+}
+
+func TestController_RunningQueries(t *testing.T) {
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	started := make(chan struct{})
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					query.ScanStatisticsFromContext(ctx).Add(10, 80)
+					close(started)
+					<-ctx.Done()
+				},
+			}, nil
+		},
+	}
+
+	orgID := platform.ID(1)
+	req := makeRequest(compiler)
+	req.OrganizationID = orgID
+	req.Source = "test"
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	queries, err := ctrl.FindRunningQueries(context.Background(), platform.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 {
+		t.Fatalf("got %d running queries, want 1", len(queries))
+	}
+	rq := queries[0]
+	if rq.OrgID != orgID || rq.Source != "test" || rq.State != "executing" {
+		t.Errorf("unexpected running query: %+v", rq)
+	}
+	if rq.ValuesScanned != 10 || rq.BytesScanned != 80 {
+		t.Errorf("got %d values and %d bytes scanned, want 10 and 80", rq.ValuesScanned, rq.BytesScanned)
+	}
+
+	otherOrg := platform.ID(2)
+	if queries, err := ctrl.FindRunningQueries(context.Background(), platform.RunningQueryFilter{OrgID: &otherOrg}); err != nil {
+		t.Fatal(err)
+	} else if len(queries) != 0 {
+		t.Errorf("got %d running queries of another org, want 0", len(queries))
+	}
+
+	if err := ctrl.KillRunningQuery(context.Background(), rq.ID); err != nil {
+		t.Fatal(err)
+	}
+	if rq, err := ctrl.FindRunningQueryByID(context.Background(), rq.ID); err != nil {
+		t.Fatal(err)
+	} else if rq.State != "canceled" {
+		t.Errorf("got state %q of a killed query, want canceled", rq.State)
+	}
+	for range q.Results() {
+		// discard the results
+	}
+	q.Done()
+
+	if err := ctrl.KillRunningQuery(context.Background(), rq.ID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("got error %v killing a finished query, want not found", err)
+	}
+}
+
+func TestController_Explain(t *testing.T) {
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	e, err := ctrl.Explain(context.Background(), makeRequest(lang.FluxCompiler{
+		Query: `
+import "csv"
+
+csv.from(csv: "#datatype,string,long,double\n#group,false,false,false\n#default,,,\n,result,table,_value\n,,0,1.0\n")
+	|> filter(fn: (r) => r._value > 0.0)
+	|> yield(name: "filtered")`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, n := range e.Nodes {
+		got = append(got, n.ID+" "+strings.Join(n.Predecessors, ","))
+	}
+	want := []string{"fromCSV0 ", "filter1 fromCSV0", "yield2 filter1"}
+	if !cmp.Equal(got, want) {
+		t.Errorf("unexpected plan nodes -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	if !strings.Contains(e.Plan, "fromCSV0 -> filter1") {
+		t.Errorf("unexpected plan:\n%s", e.Plan)
+	}
+
+	// Queries are explained without being executed.
+	if n := len(ctrl.Queries()); n != 0 {
+		t.Errorf("got %d queries, want 0", n)
+	}
+}
+
 func consumeResults(tb testing.TB, q flux.Query) {
 	tb.Helper()
 	for res := range q.Results() {
@@ -0,0 +1,154 @@
+package control
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/repl"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ query.Explainer = (*Controller)(nil)
+
+// Explain plans the query of req as the controller would execute it, without
+// executing it. The query is evaluated, so functions that read data while it
+// is evaluated, like tableFind, are run. Options of the planner set by the
+// query are ignored.
+func (c *Controller) Explain(ctx context.Context, req *query.Request) (*query.Explanation, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ctx = query.ContextWithRequest(ctx, req)
+	for _, dep := range c.dependencies {
+		ctx = dep.Inject(ctx)
+	}
+
+	spec, err := c.explainSpec(ctx, req.Compiler)
+	if err != nil {
+		return nil, handleFluxError(err)
+	}
+	ps, err := plan.PlannerBuilder{}.Build().Plan(spec)
+	if err != nil {
+		return nil, handleFluxError(err)
+	}
+	return query.NewExplanation(ps), nil
+}
+
+// explainSpec returns the spec of the query compiled by compiler.
+func (c *Controller) explainSpec(ctx context.Context, compiler flux.Compiler) (*flux.Spec, error) {
+	var (
+		pkg *ast.Package
+		now time.Time
+	)
+	switch compiler := compiler.(type) {
+	case repl.Compiler:
+		return compiler.Spec, nil
+	case lang.FluxCompiler:
+		pkg = parser.ParseSource(compiler.Query)
+		if ast.Check(pkg) > 0 {
+			return nil, ast.GetError(pkg)
+		}
+		if compiler.Extern != nil {
+			pkg.Files = append([]*ast.File{compiler.Extern}, pkg.Files...)
+		}
+		now = compiler.Now
+	case lang.ASTCompiler:
+		pkg, now = compiler.AST, compiler.Now
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("queries compiled by the %s compiler cannot be explained", compiler.CompilerType()),
+		}
+	}
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	// Functions that read data while the query is evaluated are bounded by
+	// the memory quota of a query.
+	limit := c.memory.memoryBytesQuotaPerQuery
+	ctx = lang.ExecutionDependencies{
+		Allocator: &memory.Allocator{Limit: &limit},
+		Logger:    c.log,
+	}.Inject(ctx)
+	sideEffects, scope, err := flux.EvalAST(ctx, pkg, flux.SetNowOption(now))
+	if err != nil {
+		return nil, err
+	}
+	if nowOpt, ok := scope.Lookup(flux.NowOption); ok {
+		v, err := nowOpt.Function().Call(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		now = v.Time().Time()
+	}
+	return specFromSideEffects(sideEffects, now)
+}
+
+// specFromSideEffects builds the spec of the tables yielded by a query from
+// the side effects of its evaluation, as flux does when it starts a program,
+// so that the operations of the spec have the same IDs.
+func specFromSideEffects(sideEffects []interpreter.SideEffect, now time.Time) (*flux.Spec, error) {
+	ider := &specIDer{ids: make(map[*flux.TableObject]flux.OperationID)}
+	spec := &flux.Spec{Now: now}
+	visited := make(map[*flux.TableObject]bool)
+	for _, se := range sideEffects {
+		if t, ok := se.Value.(*flux.TableObject); ok && !visited[t] {
+			buildSpec(t, ider, spec, visited)
+		}
+	}
+	if len(spec.Operations) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "the query returns no streaming data",
+		}
+	}
+	return spec, nil
+}
+
+func buildSpec(t *flux.TableObject, ider *specIDer, spec *flux.Spec, visited map[*flux.TableObject]bool) {
+	t.Parents.Range(func(i int, v values.Value) {
+		if p := v.(*flux.TableObject); !visited[p] {
+			buildSpec(p, ider, spec, visited)
+		}
+	})
+
+	id := ider.ID(t)
+	t.Parents.Range(func(i int, v values.Value) {
+		spec.Edges = append(spec.Edges, flux.Edge{
+			Parent: ider.ID(v.(*flux.TableObject)),
+			Child:  id,
+		})
+	})
+
+	visited[t] = true
+	spec.Operations = append(spec.Operations, t.Operation(ider))
+}
+
+// specIDer assigns the IDs of the operations of a spec in the order their
+// table objects are visited.
+type specIDer struct {
+	next int
+	ids  map[*flux.TableObject]flux.OperationID
+}
+
+func (i *specIDer) ID(t *flux.TableObject) flux.OperationID {
+	id, ok := i.ids[t]
+	if !ok {
+		id = flux.OperationID(fmt.Sprintf("%s%d", t.Kind, i.next))
+		i.next++
+		i.ids[t] = id
+	}
+	return id
+}
@@ -5,9 +5,17 @@ import (
 	"math"
 	"sync/atomic"
 
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/influxdb"
 )
 
+// orgInitialMemoryBytes bounds the memory initially given to each query of
+// an organization with a max-query-memory-bytes quota, so that its running
+// queries share the memory of the organization as they grow.
+const orgInitialMemoryBytes = 1 << 20
+
 type memoryManager struct {
 	// initialBytesQuotaPerQuery is the initial amount of memory
 	// allocated for each query. It does not count against the
@@ -28,18 +36,68 @@ type memoryManager struct {
 	unlimited bool
 }
 
+// orgMemory holds the memory quotas of the queries of an organization. Its
+// attributes are accessed with atomic operations.
+type orgMemory struct {
+	// maxBytes is the maximum amount of memory given to the running
+	// queries of the organization together. Zero leaves it unlimited.
+	maxBytes int64
+
+	// maxBytesPerQuery is the maximum amount of memory given to each query
+	// of the organization, if it is less than memoryBytesQuotaPerQuery.
+	// Zero leaves it unlimited.
+	maxBytesPerQuery int64
+
+	// usedBytes is the amount of memory given to the running queries of
+	// the organization that are limited by maxBytes.
+	usedBytes int64
+}
+
+// SetOrgQuota sets the memory quotas of the queries of an organization. The
+// other limits of the quota are ignored. Queries already running keep their
+// per-query quota.
+func (c *Controller) SetOrgQuota(q *influxdb.OrgQuota) {
+	c.orgMemoryMu.Lock()
+	defer c.orgMemoryMu.Unlock()
+	om, ok := c.orgMemory[q.OrgID]
+	if !ok {
+		if q.MaxQueryMemoryBytes == 0 && q.MaxQueryMemoryBytesPerQuery == 0 {
+			return
+		}
+		// Organizations keep their orgMemory once they had a quota, so
+		// that the memory given to their running queries stays counted.
+		om = &orgMemory{}
+		c.orgMemory[q.OrgID] = om
+	}
+	atomic.StoreInt64(&om.maxBytes, q.MaxQueryMemoryBytes)
+	atomic.StoreInt64(&om.maxBytesPerQuery, q.MaxQueryMemoryBytesPerQuery)
+}
+
 // createAllocator will construct an allocator and memory manager
-// for the given query.
-func (c *Controller) createAllocator(q *Query) {
+// for the given query. It returns an error if the organization of
+// the query has no memory left for it.
+func (c *Controller) createAllocator(q *Query) error {
 	q.memoryManager = &queryMemoryManager{
-		m:     c.memory,
-		limit: c.memory.initialBytesQuotaPerQuery,
+		m:        c.memory,
+		limit:    c.memory.initialBytesQuotaPerQuery,
+		maxBytes: c.memory.memoryBytesQuotaPerQuery,
 	}
+
+	c.orgMemoryMu.RLock()
+	om := c.orgMemory[q.orgID]
+	c.orgMemoryMu.RUnlock()
+	if om != nil {
+		if err := q.memoryManager.reserveOrgMemory(q.orgID, om); err != nil {
+			return err
+		}
+	}
+
 	q.alloc = &memory.Allocator{
 		// Use an anonymous function to ensure the value is copied.
 		Limit:   func(v int64) *int64 { return &v }(q.memoryManager.limit),
 		Manager: q.memoryManager,
 	}
+	return nil
 }
 
 // queryMemoryManager is a memory manager for a specific query.
@@ -47,6 +105,56 @@ type queryMemoryManager struct {
 	m     *memoryManager
 	limit int64
 	given int64
+
+	// maxBytes is the maximum amount of memory that may be
+	// allocated to the query.
+	maxBytes int64
+
+	// org holds the memory of the organization of the query, if
+	// the memory of the query counts against it. orgGiven is the
+	// memory of the organization given to the query.
+	org      *orgMemory
+	orgGiven int64
+}
+
+// reserveOrgMemory subjects the query to the memory quotas of its
+// organization. If the organization has a max-query-memory-bytes
+// quota, the initial memory of the query is reserved from it.
+func (q *queryMemoryManager) reserveOrgMemory(orgID influxdb.ID, om *orgMemory) error {
+	if max := atomic.LoadInt64(&om.maxBytesPerQuery); max > 0 && max < q.maxBytes {
+		q.maxBytes = max
+	}
+	if q.limit > q.maxBytes {
+		q.limit = q.maxBytes
+	}
+
+	max := atomic.LoadInt64(&om.maxBytes)
+	if max <= 0 {
+		return nil
+	}
+	if q.limit > orgInitialMemoryBytes {
+		q.limit = orgInitialMemoryBytes
+	}
+	for {
+		used := atomic.LoadInt64(&om.usedBytes)
+		if used >= max {
+			return &flux.Error{
+				Code: codes.ResourceExhausted,
+				Msg:  influxdb.ErrQuotaExceeded(orgID, influxdb.QuotaMaxQueryMemoryBytes, used, max).Msg,
+			}
+		}
+		// Start with what is left if the initial memory does not fit.
+		limit := q.limit
+		if used+limit > max {
+			limit = max - used
+		}
+		if atomic.CompareAndSwapInt64(&om.usedBytes, used, used+limit) {
+			q.limit = limit
+			q.org = om
+			q.orgGiven = limit
+			return nil
+		}
+	}
 }
 
 // RequestMemory will determine if the query can be given more memory
@@ -60,8 +168,8 @@ type queryMemoryManager struct {
 // too much about the specific message or structure.
 func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 	// It can be determined statically if we are going to violate
-	// the memoryBytesQuotaPerQuery.
-	if q.limit+want > q.m.memoryBytesQuotaPerQuery {
+	// the quota of the query.
+	if q.limit+want > q.maxBytes {
 		return 0, errors.New("query hit hard limit")
 	}
 
@@ -76,17 +184,40 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 			}
 		}
 
+		// The organization of the query may have less memory left.
+		var orgUsed int64
+		available := unused
+		if q.org != nil {
+			orgUsed = atomic.LoadInt64(&q.org.usedBytes)
+			orgUnused := atomic.LoadInt64(&q.org.maxBytes) - orgUsed
+			if orgUnused < want {
+				return 0, errors.New("organization hit memory quota")
+			}
+			if orgUnused < available {
+				available = orgUnused
+			}
+		}
+
 		// The memory allocator will only request the bare amount of
 		// memory it needs, but it will probably ask for more memory
 		// so, if possible, give it more so it isn't repeatedly calling
 		// this method.
-		given := q.giveMemory(want, unused)
+		given := q.giveMemory(want, available)
 
-		// Reserve this memory for our own use.
+		// Reserve this memory from the organization, and then from
+		// the controller.
+		if q.org != nil {
+			if !atomic.CompareAndSwapInt64(&q.org.usedBytes, orgUsed, orgUsed+given) {
+				continue
+			}
+		}
 		if !q.m.unlimited {
 			if !atomic.CompareAndSwapInt64(&q.m.unusedMemoryBytes, unused, unused-given) {
 				// The unused value has changed so someone may have taken
 				// the memory that we wanted. Retry.
+				if q.org != nil {
+					atomic.AddInt64(&q.org.usedBytes, -given)
+				}
 				continue
 			}
 		}
@@ -95,6 +226,9 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 		// counter for the limit.
 		q.limit += given
 		q.given += given
+		if q.org != nil {
+			q.orgGiven += given
+		}
 		return given, nil
 	}
 }
@@ -106,12 +240,12 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 func (q *queryMemoryManager) giveMemory(want, unused int64) int64 {
 	// If we can safely double the limit, then just do that.
 	if q.limit > want && q.limit < unused {
-		if q.limit*2 <= q.m.memoryBytesQuotaPerQuery {
+		if q.limit*2 <= q.maxBytes {
 			return q.limit
 		}
 		// Doubling the limit sends us over the quota.
 		// Determine what would be our maximum amount.
-		max := q.m.memoryBytesQuotaPerQuery - q.limit
+		max := q.maxBytes - q.limit
 		if max > want {
 			return max
 		}
@@ -140,6 +274,10 @@ func (q *queryMemoryManager) Release() {
 	if !q.m.unlimited {
 		atomic.AddInt64(&q.m.unusedMemoryBytes, q.given)
 	}
+	if q.org != nil {
+		atomic.AddInt64(&q.org.usedBytes, -q.orgGiven)
+	}
 	q.limit = q.m.initialBytesQuotaPerQuery
 	q.given = 0
+	q.orgGiven = 0
 }
@@ -0,0 +1,80 @@
+package control
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.RunningQueryService = (*Controller)(nil)
+
+// FindRunningQueryByID returns the query with id, if it has not finished.
+func (c *Controller) FindRunningQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+	q, err := c.findQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	return q.runningQuery(time.Now()), nil
+}
+
+// FindRunningQueries returns the queries that have not finished, longest
+// running first.
+func (c *Controller) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	now := time.Now()
+	queries := c.Queries()
+	rqs := make([]*influxdb.RunningQuery, 0, len(queries))
+	for _, q := range queries {
+		if filter.OrgID != nil && q.orgID != *filter.OrgID {
+			continue
+		}
+		rqs = append(rqs, q.runningQuery(now))
+	}
+	sort.Slice(rqs, func(i, j int) bool {
+		if !rqs[i].StartedAt.Equal(rqs[j].StartedAt) {
+			return rqs[i].StartedAt.Before(rqs[j].StartedAt)
+		}
+		return rqs[i].ID < rqs[j].ID
+	})
+	return rqs, nil
+}
+
+// KillRunningQuery cancels the query with id. Its storage reads stop, and
+// the query fails as canceled.
+func (c *Controller) KillRunningQuery(ctx context.Context, id influxdb.ID) error {
+	q, err := c.findQuery(id)
+	if err != nil {
+		return err
+	}
+	q.Cancel()
+	return nil
+}
+
+func (c *Controller) findQuery(id influxdb.ID) (*Query, error) {
+	c.queriesMu.RLock()
+	q, ok := c.queries[QueryID(id)]
+	c.queriesMu.RUnlock()
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  influxdb.ErrRunningQueryNotFound,
+		}
+	}
+	return q, nil
+}
+
+// runningQuery describes q as of now.
+func (q *Query) runningQuery(now time.Time) *influxdb.RunningQuery {
+	return &influxdb.RunningQuery{
+		ID:            influxdb.ID(q.id),
+		OrgID:         q.orgID,
+		Source:        q.source,
+		Query:         q.text,
+		State:         q.State().String(),
+		StartedAt:     q.startedAt,
+		Runtime:       influxdb.Duration{Duration: now.Sub(q.startedAt)},
+		ValuesScanned: q.scanStats.Values(),
+		BytesScanned:  q.scanStats.Bytes(),
+	}
+}
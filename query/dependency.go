@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
@@ -49,6 +50,20 @@ func (b *BucketLookup) LookupName(ctx context.Context, orgID platform.ID, id pla
 	return bucket.Name
 }
 
+// LookupRetentionPeriod returns the retention period of a bucket given its
+// organization ID and its bucket ID, and whether the bucket was found.
+func (b *BucketLookup) LookupRetentionPeriod(ctx context.Context, orgID platform.ID, id platform.ID) (time.Duration, bool) {
+	filter := platform.BucketFilter{
+		OrganizationID: &orgID,
+		ID:             &id,
+	}
+	bucket, err := b.BucketService.FindBucket(ctx, filter)
+	if err != nil || bucket == nil {
+		return 0, false
+	}
+	return bucket.RetentionPeriod, true
+}
+
 func (b *BucketLookup) FindAllBuckets(ctx context.Context, orgID platform.ID) ([]*platform.Bucket, int) {
 	oid := platform.ID(orgID)
 	filter := platform.BucketFilter{
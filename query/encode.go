@@ -13,15 +13,31 @@ const (
 	NoContentWErrDialectType = "no-content-with-error"
 )
 
-// AddDialectMappings adds the mappings for the no-content dialects.
+// AddDialectMappings adds the mappings for the no-content, string integers,
+// JSON and Arrow dialects.
 func AddDialectMappings(mappings flux.DialectMappings) error {
 	if err := mappings.Add(NoContentDialectType, func() flux.Dialect {
 		return NewNoContentDialect()
 	}); err != nil {
 		return err
 	}
-	return mappings.Add(NoContentWErrDialectType, func() flux.Dialect {
+	if err := mappings.Add(NoContentWErrDialectType, func() flux.Dialect {
 		return NewNoContentWithErrorDialect()
+	}); err != nil {
+		return err
+	}
+	if err := mappings.Add(StringIntegersCSVDialectType, func() flux.Dialect {
+		return NewStringIntegersCSVDialect()
+	}); err != nil {
+		return err
+	}
+	if err := mappings.Add(JSONDialectType, func() flux.Dialect {
+		return NewJSONDialect()
+	}); err != nil {
+		return err
+	}
+	return mappings.Add(ArrowDialectType, func() flux.Dialect {
+		return NewArrowDialect()
 	})
 }
 
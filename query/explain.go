@@ -0,0 +1,124 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+	platform "github.com/influxdata/influxdb"
+)
+
+// ReadProfileMetadataKey is the key of the statistics metadata of a query
+// holding the *ReadProfile of each of its storage reads.
+const ReadProfileMetadataKey = "influxdb/read-profile"
+
+// Explainer plans queries without executing them.
+type Explainer interface {
+	Explain(ctx context.Context, req *Request) (*Explanation, error)
+}
+
+// Explanation describes the physical plan of a query.
+type Explanation struct {
+	// Plan is the plan formatted as a DOT graph.
+	Plan string `json:"plan"`
+
+	// Nodes are the nodes of the plan, each after its predecessors.
+	Nodes []*PlanNode `json:"nodes"`
+}
+
+// PlanNode is a node of the physical plan of a query.
+type PlanNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+
+	// Details describe the procedure of the node, such as the operations
+	// the planner pushed down into a storage read.
+	Details      []string `json:"details,omitempty"`
+	Predecessors []string `json:"predecessors,omitempty"`
+
+	// Read is the profile of the storage read of the node, if the query was
+	// profiled.
+	Read *ReadProfile `json:"read,omitempty"`
+}
+
+// NewExplanation returns the explanation of the physical plan p.
+func NewExplanation(p *plan.Spec) *Explanation {
+	e := &Explanation{Plan: fmt.Sprintf("%v", plan.Formatted(p, plan.WithDetails()))}
+	_ = p.BottomUpWalk(func(pn plan.Node) error {
+		n := &PlanNode{
+			ID:   string(pn.ID()),
+			Kind: string(pn.Kind()),
+		}
+		if d, ok := pn.ProcedureSpec().(plan.Detailer); ok {
+			n.Details = strings.Split(strings.TrimSpace(d.PlanDetails()), "\n")
+		}
+		for _, pred := range pn.Predecessors() {
+			n.Predecessors = append(n.Predecessors, string(pred.ID()))
+		}
+		e.Nodes = append(e.Nodes, n)
+		return nil
+	})
+	return e
+}
+
+// ReadProfile is the profile of a storage read of a query.
+type ReadProfile struct {
+	// DatasetID identifies the plan node of the read, as the dataset
+	// executing it.
+	DatasetID string `json:"-"`
+
+	Operation     string            `json:"operation"`
+	Duration      platform.Duration `json:"duration"`
+	ScannedValues int64             `json:"scannedValues"`
+	ScannedBytes  int64             `json:"scannedBytes"`
+}
+
+// ReadProfiles returns the profiles of the storage reads of a query, given
+// the statistics of its execution.
+func ReadProfiles(stats flux.Statistics) []*ReadProfile {
+	var reads []*ReadProfile
+	for _, v := range stats.Metadata[ReadProfileMetadataKey] {
+		if rp, ok := v.(*ReadProfile); ok {
+			reads = append(reads, rp)
+		}
+	}
+	return reads
+}
+
+// Profile describes the execution of a query.
+type Profile struct {
+	*Explanation
+
+	TotalDuration   platform.Duration `json:"totalDuration"`
+	CompileDuration platform.Duration `json:"compileDuration"`
+	QueueDuration   platform.Duration `json:"queueDuration"`
+	PlanDuration    platform.Duration `json:"planDuration"`
+	ExecuteDuration platform.Duration `json:"executeDuration"`
+	MaxAllocated    int64             `json:"maxAllocated"`
+}
+
+// NewProfile returns the profile of a query with the plan explained by e,
+// given the statistics of its execution. The profiles of its storage reads
+// are set on the nodes of e.
+func NewProfile(e *Explanation, stats flux.Statistics) *Profile {
+	reads := make(map[string]*ReadProfile)
+	for _, rp := range ReadProfiles(stats) {
+		reads[rp.DatasetID] = rp
+	}
+	for _, n := range e.Nodes {
+		n.Read = reads[execute.DatasetIDFromNodeID(plan.NodeID(n.ID)).String()]
+	}
+
+	return &Profile{
+		Explanation:     e,
+		TotalDuration:   platform.Duration{Duration: stats.TotalDuration},
+		CompileDuration: platform.Duration{Duration: stats.CompileDuration},
+		QueueDuration:   platform.Duration{Duration: stats.QueueDuration},
+		PlanDuration:    platform.Duration{Duration: stats.PlanDuration},
+		ExecuteDuration: platform.Duration{Duration: stats.ExecuteDuration},
+		MaxAllocated:    stats.MaxAllocated,
+	}
+}
@@ -21,6 +21,10 @@ type Dialect struct {
 	Encoding    EncodingFormat    // Encoding is the format of the results; defaults to JSON.
 	ChunkSize   int               // Chunks is the number of points per chunk encoding batch; defaults to 0 or no chunking.
 	Compression CompressionFormat // Compression is the compression of the result output; defaults to None.
+
+	// IntegersAsStrings encodes integer and unsigned integer values as
+	// strings, so JavaScript clients do not lose precision.
+	IntegersAsStrings bool
 }
 
 func (d *Dialect) SetHeaders(w http.ResponseWriter) {
@@ -37,7 +41,7 @@ func (d *Dialect) SetHeaders(w http.ResponseWriter) {
 func (d *Dialect) Encoder() flux.MultiResultEncoder {
 	switch d.Encoding {
 	case JSON, JSONPretty:
-		return new(MultiResultEncoder)
+		return &MultiResultEncoder{IntegersAsStrings: d.IntegersAsStrings, TimeFormat: d.TimeFormat}
 	default:
 		panic("not implemented")
 	}
@@ -13,7 +13,14 @@ import (
 )
 
 // MultiResultEncoder encodes results as InfluxQL JSON format.
-type MultiResultEncoder struct{}
+type MultiResultEncoder struct {
+	// IntegersAsStrings encodes integer and unsigned integer values as
+	// strings rather than JSON numbers.
+	IntegersAsStrings bool
+
+	// TimeFormat is the format of the timestamps of the results.
+	TimeFormat TimeFormat
+}
 
 // Encode writes a collection of results to the influxdb 1.X http response format.
 // Expectations/Assumptions:
@@ -125,7 +132,12 @@ func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (i
 					case flux.TInt:
 						vs := cr.Ints(idx)
 						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
+							if !vs.IsValid(i) {
+								continue
+							}
+							if e.IntegersAsStrings {
+								values[i][j] = strconv.FormatInt(vs.Value(i), 10)
+							} else {
 								values[i][j] = vs.Value(i)
 							}
 						}
@@ -139,7 +151,12 @@ func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (i
 					case flux.TUInt:
 						vs := cr.UInts(idx)
 						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
+							if !vs.IsValid(i) {
+								continue
+							}
+							if e.IntegersAsStrings {
+								values[i][j] = strconv.FormatUint(vs.Value(i), 10)
+							} else {
 								values[i][j] = vs.Value(i)
 							}
 						}
@@ -154,7 +171,7 @@ func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (i
 						vs := cr.Times(idx)
 						for i := 0; i < vs.Len(); i++ {
 							if vs.IsValid(i) {
-								values[i][j] = execute.Time(vs.Value(i)).Time().Format(time.RFC3339Nano)
+								values[i][j] = formatTime(vs.Value(i), e.TimeFormat)
 							}
 						}
 					default:
@@ -188,3 +205,26 @@ func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (i
 func NewMultiResultEncoder() *MultiResultEncoder {
 	return new(MultiResultEncoder)
 }
+
+// formatTime returns a timestamp in a time format, either as an RFC3339Nano
+// string or as the number of units since the unix epoch.
+func formatTime(ts int64, f TimeFormat) interface{} {
+	var d time.Duration
+	switch f {
+	case Hour:
+		d = time.Hour
+	case Minute:
+		d = time.Minute
+	case Second:
+		d = time.Second
+	case Millisecond:
+		d = time.Millisecond
+	case Microsecond:
+		d = time.Microsecond
+	case Nanosecond:
+		return ts
+	default:
+		return execute.Time(ts).Time().Format(time.RFC3339Nano)
+	}
+	return ts / int64(d)
+}
@@ -16,6 +16,7 @@ import (
 func TestMultiResultEncoder_Encode(t *testing.T) {
 	for _, tt := range []struct {
 		name string
+		enc  *influxql.MultiResultEncoder
 		in   flux.ResultIterator
 		out  string
 	}{
@@ -78,6 +79,50 @@ func TestMultiResultEncoder_Encode(t *testing.T) {
 			),
 			out: `{"results":[{"statement_id":0,"series":[{"columns":["name"],"values":[["telegraf"]]}]}]}`,
 		},
+		{
+			name: "Integers As Strings",
+			enc:  &influxql.MultiResultEncoder{IntegersAsStrings: true},
+			in: flux.NewSliceResultIterator(
+				[]flux.Result{&executetest.Result{
+					Nm: "0",
+					Tbls: []*executetest.Table{{
+						KeyCols: []string{"_measurement"},
+						ColMeta: []flux.ColMeta{
+							{Label: "_time", Type: flux.TTime},
+							{Label: "_measurement", Type: flux.TString},
+							{Label: "count", Type: flux.TInt},
+							{Label: "bytes", Type: flux.TUInt},
+						},
+						Data: [][]interface{}{
+							{ts("2018-05-24T09:00:00Z"), "m0", int64(9007199254740993), uint64(18446744073709551615)},
+							{ts("2018-05-24T09:00:10Z"), "m0", nil, nil},
+						},
+					}},
+				}},
+			),
+			out: `{"results":[{"statement_id":0,"series":[{"name":"m0","columns":["time","count","bytes"],"values":[["2018-05-24T09:00:00Z","9007199254740993","18446744073709551615"],["2018-05-24T09:00:10Z",null,null]]}]}]}`,
+		},
+		{
+			name: "Epoch Milliseconds",
+			enc:  &influxql.MultiResultEncoder{TimeFormat: influxql.Millisecond},
+			in: flux.NewSliceResultIterator(
+				[]flux.Result{&executetest.Result{
+					Nm: "0",
+					Tbls: []*executetest.Table{{
+						KeyCols: []string{"_measurement"},
+						ColMeta: []flux.ColMeta{
+							{Label: "_time", Type: flux.TTime},
+							{Label: "_measurement", Type: flux.TString},
+							{Label: "value", Type: flux.TFloat},
+						},
+						Data: [][]interface{}{
+							{ts("2018-05-24T09:00:00Z"), "m0", float64(2)},
+						},
+					}},
+				}},
+			),
+			out: `{"results":[{"statement_id":0,"series":[{"name":"m0","columns":["time","value"],"values":[[1527152400000,2]]}]}]}`,
+		},
 		{
 			name: "Error",
 			in:   &resultErrorIterator{Error: "expected"},
@@ -90,7 +135,10 @@ func TestMultiResultEncoder_Encode(t *testing.T) {
 			tt.out += "\n"
 
 			var buf bytes.Buffer
-			enc := influxql.NewMultiResultEncoder()
+			enc := tt.enc
+			if enc == nil {
+				enc = influxql.NewMultiResultEncoder()
+			}
 			n, err := enc.Encode(&buf, tt.in)
 			if err != nil {
 				t.Fatalf("unexpected error: %s", err)
@@ -702,9 +702,13 @@ func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error)
 		}
 		if rp != "" {
 			filter.RetentionPolicy = &rp
+		} else {
+			// Without a retention policy the default mapping of the database
+			// is used, whereas the mapping of a retention policy is used
+			// whether it is the default or not.
+			defaultRP := true
+			filter.Default = &defaultRP
 		}
-		defaultRP := rp == ""
-		filter.Default = &defaultRP
 		mapping, err := t.dbrpMappingSvc.Find(context.TODO(), filter)
 		if err != nil {
 			if !t.config.FallbackToDBRP {
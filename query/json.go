@@ -0,0 +1,251 @@
+package query
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+)
+
+const JSONDialectType = "json"
+
+// JSONDialect is a dialect that encodes the rows of the tables of results as
+// JSON objects keyed by the labels of their columns:
+//
+//	{"results":[{"name":"_result","tables":[{"groupKey":{"host":"a"},"rows":[{"_time":"...","host":"a","_value":1}]}]}]}
+//
+// Times are encoded as RFC3339Nano strings, and floats that are not finite as
+// null. An error encountered after the results have begun to be written is
+// encoded in the "error" field that follows them.
+type JSONDialect struct {
+	// IntegersAsStrings encodes integer and unsigned integer values as
+	// strings.
+	IntegersAsStrings bool `json:"integersAsStrings,omitempty"`
+}
+
+func NewJSONDialect() *JSONDialect {
+	return &JSONDialect{}
+}
+
+func (d *JSONDialect) Encoder() flux.MultiResultEncoder {
+	return &JSONEncoder{IntegersAsStrings: d.IntegersAsStrings}
+}
+
+func (d *JSONDialect) DialectType() flux.DialectType {
+	return JSONDialectType
+}
+
+func (d *JSONDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// JSONEncoder encodes results as described by JSONDialect.
+type JSONEncoder struct {
+	IntegersAsStrings bool
+}
+
+func (e *JSONEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	if e.IntegersAsStrings {
+		results = StringIntegers(results)
+	}
+
+	wc := &iocounter.Writer{Writer: w}
+	enc := &jsonResultEncoder{w: bufio.NewWriter(wc)}
+	if err := enc.encode(results); err != nil {
+		// If nothing has been written to w yet, return the error as-is,
+		// so that it can be responded with. Otherwise the error is encoded
+		// after the results written so far.
+		if wc.Count() == 0 {
+			return 0, err
+		}
+		if err := enc.encodeError(err); err != nil {
+			return wc.Count(), err
+		}
+	}
+	err := enc.w.Flush()
+	return wc.Count(), err
+}
+
+// jsonResultEncoder writes the JSON encoding of results. It tracks the
+// objects it has opened, so that they can be closed if an error is encoded.
+type jsonResultEncoder struct {
+	w   *bufio.Writer
+	buf []byte
+
+	inResult bool
+	inTable  bool
+}
+
+func (e *jsonResultEncoder) encode(results flux.ResultIterator) error {
+	e.w.WriteString(`{"results":[`)
+	for n := 0; results.More(); n++ {
+		res := results.Next()
+		if n > 0 {
+			e.w.WriteByte(',')
+		}
+		e.buf = append(e.buf[:0], `{"name":`...)
+		e.buf = appendJSONString(e.buf, res.Name())
+		e.buf = append(e.buf, `,"tables":[`...)
+		e.w.Write(e.buf)
+		e.inResult = true
+
+		ntables := 0
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			if ntables > 0 {
+				e.w.WriteByte(',')
+			}
+			ntables++
+			return e.encodeTable(tbl)
+		}); err != nil {
+			return err
+		}
+		e.w.WriteString(`]}`)
+		e.inResult = false
+	}
+	if err := results.Err(); err != nil {
+		return err
+	}
+	_, err := e.w.WriteString(`]}`)
+	return err
+}
+
+func (e *jsonResultEncoder) encodeTable(tbl flux.Table) error {
+	key := tbl.Key()
+	e.buf = append(e.buf[:0], `{"groupKey":{`...)
+	for j, c := range key.Cols() {
+		if j > 0 {
+			e.buf = append(e.buf, ',')
+		}
+		e.buf = appendJSONString(e.buf, c.Label)
+		e.buf = append(e.buf, ':')
+		if key.IsNull(j) {
+			e.buf = append(e.buf, "null"...)
+			continue
+		}
+		switch c.Type {
+		case flux.TBool:
+			e.buf = strconv.AppendBool(e.buf, key.ValueBool(j))
+		case flux.TInt:
+			e.buf = strconv.AppendInt(e.buf, key.ValueInt(j), 10)
+		case flux.TUInt:
+			e.buf = strconv.AppendUint(e.buf, key.ValueUInt(j), 10)
+		case flux.TFloat:
+			e.buf = appendJSONFloat(e.buf, key.ValueFloat(j))
+		case flux.TString:
+			e.buf = appendJSONString(e.buf, key.ValueString(j))
+		case flux.TTime:
+			e.buf = appendJSONTime(e.buf, int64(key.ValueTime(j)))
+		default:
+			e.buf = append(e.buf, "null"...)
+		}
+	}
+	e.buf = append(e.buf, `},"rows":[`...)
+	e.w.Write(e.buf)
+	e.inTable = true
+
+	nrows := 0
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		cols := cr.Cols()
+		for i := 0; i < cr.Len(); i++ {
+			e.buf = e.buf[:0]
+			if nrows > 0 {
+				e.buf = append(e.buf, ',')
+			}
+			nrows++
+			e.buf = append(e.buf, '{')
+			for j, c := range cols {
+				if j > 0 {
+					e.buf = append(e.buf, ',')
+				}
+				e.buf = appendJSONString(e.buf, c.Label)
+				e.buf = append(e.buf, ':')
+				e.buf = appendJSONColValue(e.buf, cr, c.Type, i, j)
+			}
+			e.buf = append(e.buf, '}')
+			if _, err := e.w.Write(e.buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	e.w.WriteString(`]}`)
+	e.inTable = false
+	// Flush the writer after each table.
+	return e.w.Flush()
+}
+
+// encodeError closes the objects left open by an error and encodes the error.
+func (e *jsonResultEncoder) encodeError(err error) error {
+	if e.inTable {
+		e.w.WriteString(`]}`)
+	}
+	if e.inResult {
+		e.w.WriteString(`]}`)
+	}
+	e.buf = append(e.buf[:0], `],"error":`...)
+	e.buf = appendJSONString(e.buf, err.Error())
+	e.buf = append(e.buf, '}')
+	_, werr := e.w.Write(e.buf)
+	return werr
+}
+
+// appendJSONColValue appends the JSON encoding of the value of row i of
+// column j of cr, whose type is typ, to b.
+func appendJSONColValue(b []byte, cr flux.ColReader, typ flux.ColType, i, j int) []byte {
+	switch typ {
+	case flux.TBool:
+		if vs := cr.Bools(j); vs.IsValid(i) {
+			return strconv.AppendBool(b, vs.Value(i))
+		}
+	case flux.TInt:
+		if vs := cr.Ints(j); vs.IsValid(i) {
+			return strconv.AppendInt(b, vs.Value(i), 10)
+		}
+	case flux.TUInt:
+		if vs := cr.UInts(j); vs.IsValid(i) {
+			return strconv.AppendUint(b, vs.Value(i), 10)
+		}
+	case flux.TFloat:
+		if vs := cr.Floats(j); vs.IsValid(i) {
+			return appendJSONFloat(b, vs.Value(i))
+		}
+	case flux.TString:
+		if vs := cr.Strings(j); vs.IsValid(i) {
+			return appendJSONString(b, vs.ValueString(i))
+		}
+	case flux.TTime:
+		if vs := cr.Times(j); vs.IsValid(i) {
+			return appendJSONTime(b, vs.Value(i))
+		}
+	}
+	return append(b, "null"...)
+}
+
+// appendJSONFloat appends the JSON encoding of f to b. JSON has no encoding
+// of NaN and infinities, so they are encoded as null.
+func appendJSONFloat(b []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(b, "null"...)
+	}
+	return strconv.AppendFloat(b, f, 'g', -1, 64)
+}
+
+func appendJSONString(b []byte, s string) []byte {
+	octets, _ := json.Marshal(s)
+	return append(b, octets...)
+}
+
+func appendJSONTime(b []byte, ns int64) []byte {
+	b = append(b, '"')
+	b = time.Unix(0, ns).UTC().AppendFormat(b, time.RFC3339Nano)
+	return append(b, '"')
+}
@@ -0,0 +1,91 @@
+package query_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestJSONDialect(t *testing.T) {
+	newResult := func() flux.Result {
+		r := executetest.NewResult([]*executetest.Table{{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TInt},
+				{Label: "ratio", Type: flux.TFloat},
+				{Label: "ok", Type: flux.TBool},
+			},
+			Data: [][]interface{}{
+				{execute.Time(0), `say "a"`, int64(9007199254740993), 0.5, true},
+				{execute.Time(10), `say "a"`, nil, math.NaN(), false},
+			},
+		}})
+		r.Nm = "_result"
+		return r
+	}
+
+	for _, tt := range []struct {
+		name    string
+		dialect *query.JSONDialect
+		exp     string
+	}{
+		{
+			name:    "integers",
+			dialect: query.NewJSONDialect(),
+			exp: `{"results":[{"name":"_result","tables":[{"groupKey":{"host":"say \"a\""},"rows":[` +
+				`{"_time":"1970-01-01T00:00:00Z","host":"say \"a\"","_value":9007199254740993,"ratio":0.5,"ok":true},` +
+				`{"_time":"1970-01-01T00:00:00.00000001Z","host":"say \"a\"","_value":null,"ratio":null,"ok":false}]}]}]}`,
+		},
+		{
+			name:    "integers as strings",
+			dialect: &query.JSONDialect{IntegersAsStrings: true},
+			exp: `{"results":[{"name":"_result","tables":[{"groupKey":{"host":"say \"a\""},"rows":[` +
+				`{"_time":"1970-01-01T00:00:00Z","host":"say \"a\"","_value":"9007199254740993","ratio":0.5,"ok":true},` +
+				`{"_time":"1970-01-01T00:00:00.00000001Z","host":"say \"a\"","_value":null,"ratio":null,"ok":false}]}]}]}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if _, err := tt.dialect.Encoder().Encode(&buf, flux.NewSliceResultIterator([]flux.Result{newResult()})); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.exp, buf.String()); diff != "" {
+				t.Fatalf("unexpected output -want/+got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONDialect_Error(t *testing.T) {
+	r := executetest.NewResult([]*executetest.Table{{
+		ColMeta: []flux.ColMeta{
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{{1.0}},
+	}, {
+		ColMeta: []flux.ColMeta{
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Err: errors.New("expected error"),
+	}})
+	r.Nm = "_result"
+
+	// The error is encoded after the results written before it.
+	var buf bytes.Buffer
+	if _, err := query.NewJSONDialect().Encoder().Encode(&buf, flux.NewSliceResultIterator([]flux.Result{r})); err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"results":[{"name":"_result","tables":[{"groupKey":{},"rows":[{"_value":1}]},{"groupKey":{},"rows":[]}]}],"error":"expected error"}`
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("unexpected output -want/+got:\n%s", diff)
+	}
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"fmt"
+)
+
+// Priority is the class of a query, which decides how it is admitted for
+// execution while queries are waiting.
+type Priority int
+
+// The priorities of queries. Queries with no priority are interactive.
+const (
+	// PriorityInteractive is the class of queries run on behalf of a
+	// user waiting for their results.
+	PriorityInteractive Priority = iota
+	// PriorityDashboard is the class of queries refreshing dashboards.
+	PriorityDashboard
+	// PriorityBatch is the class of background queries, such as those of
+	// tasks.
+	PriorityBatch
+
+	numPriorities
+)
+
+// Priorities are the priorities of queries, from highest to lowest.
+var Priorities = []Priority{PriorityInteractive, PriorityDashboard, PriorityBatch}
+
+var priorityNames = [...]string{
+	PriorityInteractive: "interactive",
+	PriorityDashboard:   "dashboard",
+	PriorityBatch:       "batch",
+}
+
+// ParsePriority returns the priority named s.
+func ParsePriority(s string) (Priority, error) {
+	for p, name := range priorityNames {
+		if name == s {
+			return Priority(p), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown query priority %q", s)
+}
+
+// Valid reports whether p is a known priority.
+func (p Priority) Valid() bool {
+	return p >= 0 && p < numPriorities
+}
+
+func (p Priority) String() string {
+	if !p.Valid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// MarshalText encodes the priority as its name.
+func (p Priority) MarshalText() ([]byte, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("unknown query priority %d", int(p))
+	}
+	return []byte(priorityNames[p]), nil
+}
+
+// UnmarshalText decodes the priority from its name.
+func (p *Priority) UnmarshalText(text []byte) error {
+	v, err := ParsePriority(string(text))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
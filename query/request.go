@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
 	platform "github.com/influxdata/influxdb"
 )
 
@@ -33,6 +35,10 @@ type Request struct {
 	// Source represents the ultimate source of the request.
 	Source string `json:"source"`
 
+	// Priority is the class of the query, which decides how it is admitted
+	// for execution while queries are waiting.
+	Priority Priority `json:"priority,omitempty"`
+
 	// compilerMappings maps compiler types to creation methods
 	compilerMappings flux.CompilerMappings
 
@@ -79,6 +85,20 @@ func (r *Request) WithCompilerMappings(mappings flux.CompilerMappings) {
 	r.compilerMappings = mappings
 }
 
+// QueryText returns the text of the query compiled by the request's
+// compiler, or "" if it is not known.
+func (r *Request) QueryText() string {
+	switch c := r.Compiler.(type) {
+	case lang.FluxCompiler:
+		return c.Query
+	case lang.ASTCompiler:
+		if c.AST != nil {
+			return ast.Format(c.AST)
+		}
+	}
+	return ""
+}
+
 // UnmarshalJSON populates the request from the JSON data.
 // WithCompilerMappings must have been called or an error will occur.
 func (r *Request) UnmarshalJSON(data []byte) error {
@@ -0,0 +1,54 @@
+package query
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ScanStatistics counts the data read from storage by a query while it
+// runs. Its methods are safe to call concurrently, and on a nil
+// *ScanStatistics.
+type ScanStatistics struct {
+	values int64
+	bytes  int64
+}
+
+// Add counts values and bytes read from storage.
+func (s *ScanStatistics) Add(values, bytes int64) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.values, values)
+	atomic.AddInt64(&s.bytes, bytes)
+}
+
+// Values returns the number of values read so far.
+func (s *ScanStatistics) Values() int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&s.values)
+}
+
+// Bytes returns the number of bytes read so far.
+func (s *ScanStatistics) Bytes() int64 {
+	if s == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&s.bytes)
+}
+
+type scanStatisticsContextKey struct{}
+
+// ContextWithScanStatistics returns a new context with a reference to the
+// statistics of the data read from storage by a query.
+func ContextWithScanStatistics(ctx context.Context, s *ScanStatistics) context.Context {
+	return context.WithValue(ctx, scanStatisticsContextKey{}, s)
+}
+
+// ScanStatisticsFromContext retrieves the *ScanStatistics of a context. If
+// none exists on the context nil is returned, which counts nothing.
+func ScanStatisticsFromContext(ctx context.Context) *ScanStatistics {
+	s, _ := ctx.Value(scanStatisticsContextKey{}).(*ScanStatistics)
+	return s
+}
@@ -0,0 +1,144 @@
+// Package slowlog records the queries that run longer, or scan more data,
+// than configured thresholds. Each query is written as a point to the
+// monitoring system bucket of its organization, where it can be queried with
+// Flux like any other data:
+//
+//	from(bucket: "_monitoring")
+//	  |> range(start: -1d)
+//	  |> filter(fn: (r) => r._measurement == "slow_queries")
+//	  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+package slowlog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// MeasurementName is the measurement slow queries are written to in the
+// monitoring system bucket.
+const MeasurementName = "slow_queries"
+
+// Config holds the thresholds beyond which a query is recorded. A zero
+// threshold is disabled; a query is recorded once it reaches any other.
+type Config struct {
+	// Duration is the total duration of a query, from when it is received
+	// to when its results are written.
+	Duration time.Duration
+
+	// ScannedValues and ScannedBytes are the data read from storage by a
+	// query.
+	ScannedValues int64
+	ScannedBytes  int64
+}
+
+// Enabled reports whether any threshold is set.
+func (c Config) Enabled() bool {
+	return c.Duration > 0 || c.ScannedValues > 0 || c.ScannedBytes > 0
+}
+
+// Logger is a query.Logger recording slow queries.
+type Logger struct {
+	bucketService influxdb.BucketService
+	pointsWriter  storage.PointsWriter
+	config        Config
+	log           *zap.Logger
+}
+
+// New returns a Logger recording the queries that reach the thresholds of
+// config.
+func New(log *zap.Logger, bucketService influxdb.BucketService, pointsWriter storage.PointsWriter, config Config) *Logger {
+	return &Logger{
+		bucketService: bucketService,
+		pointsWriter:  pointsWriter,
+		config:        config,
+		log:           log,
+	}
+}
+
+var _ query.Logger = (*Logger)(nil)
+
+// Log records q if it reached a threshold of the logger.
+func (l *Logger) Log(q query.Log) error {
+	var values, bytes int64
+	for _, rp := range query.ReadProfiles(q.Statistics) {
+		values += rp.ScannedValues
+		bytes += rp.ScannedBytes
+	}
+	if !l.slow(q.Statistics.TotalDuration, values, bytes) {
+		return nil
+	}
+
+	if err := l.record(context.Background(), q, values, bytes); err != nil {
+		l.log.Warn("Unable to record slow query",
+			zap.String("org_id", q.OrganizationID.String()),
+			zap.String("trace_id", q.TraceID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// slow reports whether a query reached any of the thresholds.
+func (l *Logger) slow(d time.Duration, values, bytes int64) bool {
+	c := l.config
+	return (c.Duration > 0 && d >= c.Duration) ||
+		(c.ScannedValues > 0 && values >= c.ScannedValues) ||
+		(c.ScannedBytes > 0 && bytes >= c.ScannedBytes)
+}
+
+// record writes q to the monitoring system bucket of its organization.
+func (l *Logger) record(ctx context.Context, q query.Log, values, bytes int64) error {
+	b, err := l.bucketService.FindBucketByName(ctx, q.OrganizationID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return fmt.Errorf("unable to find monitoring bucket of org %s: %v", q.OrganizationID, err)
+	}
+
+	tags := map[string]string{"orgID": q.OrganizationID.String()}
+	stats := q.Statistics
+	fields := map[string]interface{}{
+		"totalDuration":   int64(stats.TotalDuration),
+		"compileDuration": int64(stats.CompileDuration),
+		"queueDuration":   int64(stats.QueueDuration),
+		"planDuration":    int64(stats.PlanDuration),
+		"executeDuration": int64(stats.ExecuteDuration),
+		"maxAllocated":    stats.MaxAllocated,
+		"scannedValues":   values,
+		"scannedBytes":    bytes,
+		"responseSize":    q.ResponseSize,
+	}
+	if pr := q.ProxyRequest; pr != nil {
+		if a := pr.Request.Authorization; a != nil && a.UserID.Valid() {
+			tags["userID"] = a.UserID.String()
+		}
+		if text := pr.Request.QueryText(); text != "" {
+			fields["query"] = text
+		}
+		if pr.Request.Source != "" {
+			fields["source"] = pr.Request.Source
+		}
+	}
+	if q.TraceID != "" {
+		fields["traceID"] = q.TraceID
+	}
+	if q.Error != nil {
+		fields["error"] = q.Error.Error()
+	}
+
+	pt, err := models.NewPoint(MeasurementName, models.NewTags(tags), fields, q.Time)
+	if err != nil {
+		return err
+	}
+	points, err := tsdb.ExplodePoints(q.OrganizationID, b.ID, models.Points{pt})
+	if err != nil {
+		return err
+	}
+	return l.pointsWriter.WritePoints(ctx, points)
+}
@@ -0,0 +1,120 @@
+package slowlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+// testLog returns the log of a query of org 1 by user 2, which took d and
+// scanned values.
+func testLog(d time.Duration, values int64) query.Log {
+	return query.Log{
+		Time:           time.Date(2019, 10, 2, 0, 0, 0, 0, time.UTC),
+		OrganizationID: 1,
+		Error:          errors.New("oops"),
+		ProxyRequest: &query.ProxyRequest{
+			Request: query.Request{
+				Authorization:  &influxdb.Authorization{UserID: 2, Token: "secret"},
+				OrganizationID: 1,
+				Compiler:       lang.FluxCompiler{Query: `from(bucket: "b") |> range(start: -1h)`},
+				Source:         "curl",
+			},
+		},
+		Statistics: flux.Statistics{
+			TotalDuration: d,
+			Metadata: flux.Metadata{
+				query.ReadProfileMetadataKey: []interface{}{
+					&query.ReadProfile{ScannedValues: values, ScannedBytes: 8 * values},
+				},
+			},
+		},
+	}
+}
+
+func TestLogger_Log(t *testing.T) {
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByNameFn = func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+		if name != influxdb.MonitoringSystemBucketName {
+			t.Fatalf("got bucket name %q, expected %q", name, influxdb.MonitoringSystemBucketName)
+		}
+		return &influxdb.Bucket{ID: influxdb.MonitoringSystemBucketID, OrgID: orgID}, nil
+	}
+	pw := &mock.PointsWriter{}
+	l := New(zaptest.NewLogger(t), buckets, pw, Config{Duration: time.Second, ScannedValues: 100})
+
+	// Neither threshold is reached.
+	if err := l.Log(testLog(time.Millisecond, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if len(pw.Points) != 0 {
+		t.Fatalf("expected a fast query not to be recorded, got %d points", len(pw.Points))
+	}
+
+	// The scan threshold is reached.
+	if err := l.Log(testLog(time.Millisecond, 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	name := tsdb.EncodeName(1, influxdb.MonitoringSystemBucketID)
+	fields := make(map[string]interface{})
+	for _, pt := range pw.Points {
+		if string(pt.Name()) != string(name[:]) {
+			t.Fatalf("got point name %x, expected %x", pt.Name(), name)
+		}
+		if m := pt.Tags().Get(models.MeasurementTagKeyBytes); string(m) != MeasurementName {
+			t.Fatalf("got measurement %q, expected %q", m, MeasurementName)
+		}
+		if u := pt.Tags().GetString("userID"); u != influxdb.ID(2).String() {
+			t.Fatalf("got user %q, expected %q", u, influxdb.ID(2))
+		}
+		iter := pt.FieldIterator()
+		for iter.Next() {
+			var (
+				v   interface{}
+				err error
+			)
+			switch iter.Type() {
+			case models.Integer:
+				v, err = iter.IntegerValue()
+			case models.String:
+				v = iter.StringValue()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields[string(iter.FieldKey())] = v
+		}
+	}
+	for k, exp := range map[string]interface{}{
+		"query":         `from(bucket: "b") |> range(start: -1h)`,
+		"source":        "curl",
+		"error":         "oops",
+		"totalDuration": int64(time.Millisecond),
+		"scannedValues": int64(100),
+		"scannedBytes":  int64(800),
+	} {
+		if got := fields[k]; got != exp {
+			t.Errorf("got %s %v, expected %v", k, got, exp)
+		}
+	}
+
+	// The duration threshold is reached.
+	pw.Points = nil
+	if err := l.Log(testLog(2*time.Second, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if len(pw.Points) == 0 {
+		t.Fatal("expected a slow query to be recorded")
+	}
+}
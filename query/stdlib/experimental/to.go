@@ -15,7 +15,6 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
-	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/tsdb"
 )
 
@@ -194,7 +193,7 @@ type ToTransformation struct {
 	cache    execute.TableBuilderCache
 	spec     *ToOpSpec
 	deps     influxdb.ToDependencies
-	buf      *storage.BufferedPointsWriter
+	w        *influxdb.ToWriter
 }
 
 // RetractTable retracts the table for the transformation for the `to` flux function.
@@ -267,7 +266,7 @@ func NewToTransformation(ctx context.Context, d execute.Dataset, cache execute.T
 		cache:    cache,
 		spec:     spec.Spec,
 		deps:     deps,
-		buf:      storage.NewBufferedPointsWriter(influxdb.DefaultBufferSize, deps.PointsWriter),
+		w:        influxdb.NewToWriter(deps, orgID, *bucketID),
 	}, nil
 }
 
@@ -292,7 +291,7 @@ func (t *ToTransformation) Finish(id execute.DatasetID, err error) {
 		t.d.Finish(err)
 		return
 	}
-	err = t.buf.Flush(t.ctx)
+	err = t.w.Flush(t.ctx)
 	t.d.Finish(err)
 }
 
@@ -469,6 +468,6 @@ func (t *ToTransformation) writeTable(ctx context.Context, tbl flux.Table) error
 			}
 		}
 
-		return t.buf.WritePoints(ctx, points)
+		return t.w.WritePoints(ctx, points)
 	})
 }
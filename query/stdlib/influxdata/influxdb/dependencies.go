@@ -19,6 +19,7 @@ type StorageDependencies struct {
 	FromDeps   FromDependencies
 	BucketDeps BucketDependencies
 	ToDeps     ToDependencies
+	JoinDeps   JoinDependencies
 }
 
 func (d StorageDependencies) Inject(ctx context.Context) context.Context {
@@ -35,6 +36,7 @@ func (d StorageDependencies) PrometheusCollectors() []prometheus.Collector {
 		d.FromDeps,
 		d.BucketDeps,
 		d.ToDeps,
+		d.JoinDeps,
 	}
 	collectors := make([]prometheus.Collector, 0, len(depS))
 	for _, v := range depS {
@@ -0,0 +1,347 @@
+package influxdb
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+const AlignedJoinKind = "AlignedJoinKind"
+
+func init() {
+	execute.RegisterTransformation(AlignedJoinKind, createAlignedJoinTransformation)
+}
+
+// AlignedJoinProcedureSpec joins the series of two reads of the same time
+// range on their time, as a join of the reads would. Each pair of tables is
+// joined as soon as both have been read, instead of once both reads have
+// finished, so neither the joined tables nor the tables of both reads are
+// held until the end of the query.
+type AlignedJoinProcedureSpec struct {
+	plan.DefaultCost
+	TableNames []string
+	On         []string
+}
+
+func (s *AlignedJoinProcedureSpec) Kind() plan.ProcedureKind {
+	return AlignedJoinKind
+}
+
+func (s *AlignedJoinProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(AlignedJoinProcedureSpec)
+	ns.TableNames = append([]string(nil), s.TableNames...)
+	ns.On = append([]string(nil), s.On...)
+	return ns
+}
+
+// PlanDetails implements plan.Detailer, describing the columns joined on.
+func (s *AlignedJoinProcedureSpec) PlanDetails() string {
+	return fmt.Sprintf("tables: [%s]\non: [%s]\n", strings.Join(s.TableNames, ", "), strings.Join(s.On, ", "))
+}
+
+func createAlignedJoinTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*AlignedJoinProcedureSpec)
+	if !ok {
+		return nil, nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  fmt.Sprintf("invalid spec type %T", spec),
+		}
+	}
+	parents := a.Parents()
+	if len(parents) != 2 {
+		return nil, nil, &flux.Error{
+			Code: codes.Unimplemented,
+			Msg:  "joins currently must only have two parents",
+		}
+	}
+	d := execute.NewPassthroughDataset(id)
+	t := newAlignedJoinTransformation(id, d, a.Allocator(), s, parents)
+	if deps, ok := a.Context().Value(dependenciesKey).(StorageDependencies); ok {
+		t.spill = deps.JoinDeps
+	}
+	return t, d, nil
+}
+
+// alignedJoinTransformation joins the tables of its parents pairwise. Tables
+// are buffered until they cannot be joined with any table still to be read:
+// once a parent has finished, the tables of the other parent have been
+// joined with all of its tables and are released, and those read afterwards
+// are joined without being buffered. Tables buffered beyond the memory limit
+// of the spill configuration are written to disk and read back to be joined.
+type alignedJoinTransformation struct {
+	mu sync.Mutex
+
+	id    execute.DatasetID
+	d     *execute.PassthroughDataset
+	alloc *memory.Allocator
+	spec  *universe.MergeJoinProcedureSpec
+	on    map[string]bool
+
+	parents     []execute.DatasetID
+	tableNames  map[execute.DatasetID]string
+	parentState map[execute.DatasetID]*alignedJoinParentState
+
+	spill     JoinDependencies
+	size      int64 // Estimated size of the tables buffered in memory.
+	tempBytes int64 // Size of the tables spilled to disk.
+
+	err error
+}
+
+type alignedJoinParentState struct {
+	tables     []flux.BufferedTable
+	sizes      []int64
+	spilled    joinSpillFile
+	mark       execute.Time
+	processing execute.Time
+	finished   bool
+}
+
+func newAlignedJoinTransformation(id execute.DatasetID, d *execute.PassthroughDataset, alloc *memory.Allocator, spec *AlignedJoinProcedureSpec, parents []execute.DatasetID) *alignedJoinTransformation {
+	t := &alignedJoinTransformation{
+		id:    id,
+		d:     d,
+		alloc: alloc,
+		spec: &universe.MergeJoinProcedureSpec{
+			TableNames: spec.TableNames,
+			On:         spec.On,
+		},
+		on:          make(map[string]bool, len(spec.On)),
+		parents:     parents,
+		tableNames:  make(map[execute.DatasetID]string, len(parents)),
+		parentState: make(map[execute.DatasetID]*alignedJoinParentState, len(parents)),
+	}
+	for _, label := range spec.On {
+		t.on[label] = true
+	}
+	for i, parent := range parents {
+		t.tableNames[parent] = spec.TableNames[i]
+		t.parentState[parent] = new(alignedJoinParentState)
+	}
+	return t
+}
+
+func (t *alignedJoinTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+// Process joins tbl with each table of the other parent it may match. It is
+// buffered to be joined with the tables the other parent has yet to send.
+func (t *alignedJoinTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	buf, err := execute.CopyTable(tbl)
+	if err != nil {
+		return err
+	}
+	other := t.parentState[t.other(id)]
+	for _, o := range other.tables {
+		if !t.mayJoin(buf.Key(), o.Key()) {
+			continue
+		}
+		if id == t.parents[0] {
+			err = t.join(buf.Copy(), o.Copy())
+		} else {
+			err = t.join(o.Copy(), buf.Copy())
+		}
+		if err != nil {
+			buf.Done()
+			return err
+		}
+	}
+	for _, st := range other.spilled.tables {
+		if !t.mayJoin(buf.Key(), st.key) {
+			continue
+		}
+		o, err := other.spilled.read(st, t.alloc)
+		if err != nil {
+			buf.Done()
+			return err
+		}
+		if id == t.parents[0] {
+			err = t.join(buf.Copy(), o)
+		} else {
+			err = t.join(o, buf.Copy())
+		}
+		if err != nil {
+			buf.Done()
+			return err
+		}
+	}
+
+	if other.finished {
+		buf.Done()
+		return nil
+	}
+	return t.buffer(t.parentState[id], buf)
+}
+
+// buffer holds buf until the other parent finishes, spilling it to disk if
+// the tables held in memory would exceed the memory limit.
+func (t *alignedJoinTransformation) buffer(state *alignedJoinParentState, buf flux.BufferedTable) error {
+	size := tableSize(buf)
+	if !t.spill.spillEnabled() || buf.Empty() || t.size+size <= t.spill.SpillMemoryBytes {
+		state.tables = append(state.tables, buf)
+		state.sizes = append(state.sizes, size)
+		t.size += size
+		return nil
+	}
+	defer buf.Done()
+
+	n, err := state.spilled.write(t.spill.SpillDir, buf.Copy())
+	t.tempBytes += n
+	joinSpillMetrics.Bytes.Add(float64(n))
+	joinSpillMetrics.TempBytes.Add(float64(n))
+	if err != nil {
+		return err
+	}
+	joinSpillMetrics.Tables.Inc()
+	if t.spill.SpillMaxTempBytes > 0 && t.tempBytes > t.spill.SpillMaxTempBytes {
+		joinSpillMetrics.LimitExceeded.Inc()
+		return errJoinSpillLimitExceeded
+	}
+	return nil
+}
+
+// release releases the tables buffered for a parent.
+func (t *alignedJoinTransformation) release(state *alignedJoinParentState) {
+	for i, tbl := range state.tables {
+		tbl.Done()
+		t.size -= state.sizes[i]
+	}
+	state.tables, state.sizes = nil, nil
+	n := state.spilled.release()
+	t.tempBytes -= n
+	joinSpillMetrics.TempBytes.Sub(float64(n))
+}
+
+// mayJoin reports whether tables with keys left and right may have rows to
+// join, which they do not if they have different values in any column they
+// are joined on.
+func (t *alignedJoinTransformation) mayJoin(left, right flux.GroupKey) bool {
+	for j, c := range left.Cols() {
+		if !t.on[c.Label] {
+			continue
+		}
+		k := execute.ColIdx(c.Label, right.Cols())
+		if k < 0 {
+			continue
+		}
+		if !left.Value(j).Equal(right.Value(k)) {
+			return false
+		}
+	}
+	return true
+}
+
+// join joins the pair of tables left and right as a join would, and passes
+// the joined table on.
+func (t *alignedJoinTransformation) join(left, right flux.Table) error {
+	cache := universe.NewMergeJoinCache(t.alloc, t.parents, t.tableNames, t.spec.On)
+	d := execute.NewDataset(t.id, execute.DiscardingMode, cache)
+	joined := &joinedTables{d: t.d}
+	d.AddTransformation(joined)
+	jt := universe.NewMergeJoinTransformation(d, cache, t.spec, t.parents, t.tableNames)
+
+	if err := jt.Process(t.parents[0], left); err != nil {
+		right.Done()
+		return err
+	}
+	if err := jt.Process(t.parents[1], right); err != nil {
+		return err
+	}
+	jt.Finish(t.parents[0], nil)
+	jt.Finish(t.parents[1], nil)
+	return joined.err
+}
+
+func (t *alignedJoinTransformation) other(id execute.DatasetID) execute.DatasetID {
+	if id == t.parents[0] {
+		return t.parents[1]
+	}
+	return t.parents[0]
+}
+
+func (t *alignedJoinTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.parentState[id].mark = mark
+
+	min := execute.Time(math.MaxInt64)
+	for _, state := range t.parentState {
+		if state.mark < min {
+			min = state.mark
+		}
+	}
+	return t.d.UpdateWatermark(min)
+}
+
+func (t *alignedJoinTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.parentState[id].processing = pt
+
+	min := execute.Time(math.MaxInt64)
+	for _, state := range t.parentState {
+		if state.processing < min {
+			min = state.processing
+		}
+	}
+	return t.d.UpdateProcessingTime(min)
+}
+
+// Finish releases the tables of the other parent, which have been joined
+// with every table of the finished one.
+func (t *alignedJoinTransformation) Finish(id execute.DatasetID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Only report the first error that occurs.
+	if t.err == nil && err != nil {
+		t.err = err
+	}
+
+	t.parentState[id].finished = true
+	other := t.parentState[t.other(id)]
+	t.release(other)
+
+	if other.finished {
+		t.release(t.parentState[id])
+		t.d.Finish(t.err)
+	}
+}
+
+// joinedTables passes on the table joined from a pair of tables.
+type joinedTables struct {
+	d   *execute.PassthroughDataset
+	err error
+}
+
+func (j *joinedTables) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return nil
+}
+
+func (j *joinedTables) Process(id execute.DatasetID, tbl flux.Table) error {
+	return j.d.Process(tbl)
+}
+
+func (j *joinedTables) UpdateWatermark(id execute.DatasetID, t execute.Time) error {
+	return nil
+}
+
+func (j *joinedTables) UpdateProcessingTime(id execute.DatasetID, t execute.Time) error {
+	return nil
+}
+
+func (j *joinedTables) Finish(id execute.DatasetID, err error) {
+	j.err = err
+}
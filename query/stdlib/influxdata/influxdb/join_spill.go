@@ -0,0 +1,380 @@
+package influxdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// JoinDependencies configures how aligned joins spill the tables they
+// buffer to disk.
+type JoinDependencies struct {
+	// SpillDir is the directory temporary files are written to. An empty
+	// directory uses the default directory for temporary files.
+	SpillDir string
+
+	// SpillMemoryBytes is the estimated size in bytes of the tables a join
+	// buffers in memory before spilling them to disk. A value of 0 disables
+	// spilling.
+	SpillMemoryBytes int64
+
+	// SpillMaxTempBytes is the maximum number of bytes of temporary files of
+	// each join. A value of 0 does not limit them.
+	SpillMaxTempBytes int64
+}
+
+// spillEnabled returns true if buffered tables are spilled to disk.
+func (d JoinDependencies) spillEnabled() bool { return d.SpillMemoryBytes > 0 }
+
+// PrometheusCollectors satisfies the PrometheusCollector interface.
+func (d JoinDependencies) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		joinSpillMetrics.Tables,
+		joinSpillMetrics.Bytes,
+		joinSpillMetrics.TempBytes,
+		joinSpillMetrics.LimitExceeded,
+	}
+}
+
+// joinSpillMetrics are shared by all joins that spill to disk.
+var joinSpillMetrics = newJoinSpillMetrics()
+
+type joinSpillMetricsT struct {
+	Tables        prometheus.Counter // Number of tables written.
+	Bytes         prometheus.Counter // Number of bytes written.
+	TempBytes     prometheus.Gauge   // Number of bytes of temporary files.
+	LimitExceeded prometheus.Counter // Number of joins that exceeded their limit.
+}
+
+func newJoinSpillMetrics() *joinSpillMetricsT {
+	const (
+		namespace = "query"
+		subsystem = "join_spill"
+	)
+	return &joinSpillMetricsT{
+		Tables: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "tables_total",
+			Help:      "Number of tables spilled to disk by joins.",
+		}),
+		Bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "bytes_total",
+			Help:      "Number of bytes of tables spilled to disk by joins.",
+		}),
+		TempBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "temp_bytes",
+			Help:      "Number of bytes of temporary files held by running joins.",
+		}),
+		LimitExceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "limit_exceeded_total",
+			Help:      "Number of joins that exceeded their temporary disk space limit.",
+		}),
+	}
+}
+
+// errJoinSpillLimitExceeded is returned when a join needs more temporary
+// disk space than it is allowed.
+var errJoinSpillLimitExceeded = &flux.Error{
+	Code: codes.ResourceExhausted,
+	Msg:  "join exceeded its temporary disk space limit",
+}
+
+// spilledTable is a table written to the spill file of a parent.
+type spilledTable struct {
+	key    flux.GroupKey
+	offset int64
+	size   int64
+}
+
+// joinSpillFile holds the tables of one parent of a join that did not fit
+// in memory.
+type joinSpillFile struct {
+	f      *os.File
+	size   int64
+	tables []spilledTable
+}
+
+// write appends tbl to the file, creating it in dir if needed, and returns
+// the number of bytes written.
+func (s *joinSpillFile) write(dir string, tbl flux.Table) (int64, error) {
+	if s.f == nil {
+		f, err := ioutil.TempFile(dir, "influxd-join-spill-")
+		if err != nil {
+			return 0, err
+		}
+		s.f = f
+	}
+
+	key := tbl.Key()
+	w := &spillWriter{w: bufio.NewWriter(s.f)}
+	if err := w.writeTable(tbl); err != nil {
+		return 0, err
+	}
+	if err := w.w.Flush(); err != nil {
+		return 0, err
+	}
+	s.tables = append(s.tables, spilledTable{key: key, offset: s.size, size: w.n})
+	s.size += w.n
+	return w.n, nil
+}
+
+// read reads back the table st.
+func (s *joinSpillFile) read(st spilledTable, alloc *memory.Allocator) (flux.Table, error) {
+	r := &spillReader{r: bufio.NewReader(io.NewSectionReader(s.f, st.offset, st.size))}
+	return r.readTable(alloc)
+}
+
+// release closes and removes the file, returning its size.
+func (s *joinSpillFile) release() int64 {
+	if s.f == nil {
+		return 0
+	}
+	s.f.Close()
+	os.Remove(s.f.Name())
+	size := s.size
+	*s = joinSpillFile{}
+	return size
+}
+
+// tableSize estimates the size in bytes of the buffered table tbl.
+func tableSize(tbl flux.BufferedTable) int64 {
+	var size int64
+	_ = tbl.Copy().Do(func(cr flux.ColReader) error {
+		for j, c := range cr.Cols() {
+			if c.Type == flux.TString {
+				vs := cr.Strings(j)
+				size += int64(len(vs.ValueBytes()) + 4*vs.Len())
+				continue
+			}
+			size += int64(8 * cr.Len())
+		}
+		return nil
+	})
+	return size
+}
+
+// spillWriter writes tables as their group key, columns and rows. Every
+// value is preceded by a byte that is 1 if it is null.
+type spillWriter struct {
+	w   *bufio.Writer
+	n   int64
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (w *spillWriter) writeTable(tbl flux.Table) error {
+	key := tbl.Key()
+	w.writeUvarint(uint64(len(key.Cols())))
+	for j, c := range key.Cols() {
+		w.writeCol(c)
+		w.writeValue(c.Type, key.Value(j))
+	}
+
+	cols := tbl.Cols()
+	w.writeUvarint(uint64(len(cols)))
+	for _, c := range cols {
+		w.writeCol(c)
+	}
+	return tbl.Do(func(cr flux.ColReader) error {
+		w.writeUvarint(uint64(cr.Len()))
+		for i := 0; i < cr.Len(); i++ {
+			for j, c := range cols {
+				w.writeValue(c.Type, execute.ValueForRow(cr, i, j))
+			}
+		}
+		return nil
+	})
+}
+
+func (w *spillWriter) write(p []byte) {
+	n, _ := w.w.Write(p)
+	w.n += int64(n)
+}
+
+func (w *spillWriter) writeUvarint(v uint64) {
+	w.write(w.buf[:binary.PutUvarint(w.buf[:], v)])
+}
+
+func (w *spillWriter) writeVarint(v int64) {
+	w.write(w.buf[:binary.PutVarint(w.buf[:], v)])
+}
+
+func (w *spillWriter) writeString(s string) {
+	w.writeUvarint(uint64(len(s)))
+	n, _ := w.w.WriteString(s)
+	w.n += int64(n)
+}
+
+func (w *spillWriter) writeCol(c flux.ColMeta) {
+	w.writeString(c.Label)
+	w.writeUvarint(uint64(c.Type))
+}
+
+func (w *spillWriter) writeValue(typ flux.ColType, v values.Value) {
+	if v.IsNull() {
+		w.write([]byte{1})
+		return
+	}
+	w.write([]byte{0})
+	switch typ {
+	case flux.TBool:
+		if v.Bool() {
+			w.write([]byte{1})
+		} else {
+			w.write([]byte{0})
+		}
+	case flux.TInt:
+		w.writeVarint(v.Int())
+	case flux.TUInt:
+		w.writeUvarint(v.UInt())
+	case flux.TFloat:
+		w.writeUvarint(math.Float64bits(v.Float()))
+	case flux.TString:
+		w.writeString(v.Str())
+	case flux.TTime:
+		w.writeVarint(int64(v.Time()))
+	}
+}
+
+// spillReader reads tables written by a spillWriter.
+type spillReader struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (r *spillReader) readTable(alloc *memory.Allocator) (flux.Table, error) {
+	keyCols := make([]flux.ColMeta, r.readUvarint())
+	keyValues := make([]values.Value, len(keyCols))
+	for j := range keyCols {
+		keyCols[j] = r.readCol()
+		keyValues[j] = r.readValue(keyCols[j].Type)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(keyCols, keyValues), alloc)
+	cols := make([]flux.ColMeta, r.readUvarint())
+	for j := range cols {
+		cols[j] = r.readCol()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if _, err := b.AddCol(cols[j]); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		n, err := binary.ReadUvarint(r.r)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			for j, c := range cols {
+				v := r.readValue(c.Type)
+				if r.err != nil {
+					return nil, r.err
+				}
+				if err := b.AppendValue(j, v); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return b.Table()
+}
+
+func (r *spillReader) readUvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(r.r)
+	r.setErr(err)
+	return v
+}
+
+func (r *spillReader) readVarint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(r.r)
+	r.setErr(err)
+	return v
+}
+
+func (r *spillReader) readByte() byte {
+	if r.err != nil {
+		return 0
+	}
+	v, err := r.r.ReadByte()
+	r.setErr(err)
+	return v
+}
+
+func (r *spillReader) readString() string {
+	n := r.readUvarint()
+	if r.err != nil {
+		return ""
+	}
+	buf := make([]byte, n)
+	_, err := io.ReadFull(r.r, buf)
+	r.setErr(err)
+	return string(buf)
+}
+
+func (r *spillReader) readCol() flux.ColMeta {
+	label := r.readString()
+	return flux.ColMeta{Label: label, Type: flux.ColType(r.readUvarint())}
+}
+
+func (r *spillReader) readValue(typ flux.ColType) values.Value {
+	if r.readByte() == 1 {
+		return values.NewNull(flux.SemanticType(typ))
+	}
+	switch typ {
+	case flux.TBool:
+		return values.NewBool(r.readByte() == 1)
+	case flux.TInt:
+		return values.NewInt(r.readVarint())
+	case flux.TUInt:
+		return values.NewUInt(r.readUvarint())
+	case flux.TFloat:
+		return values.NewFloat(math.Float64frombits(r.readUvarint()))
+	case flux.TString:
+		return values.NewString(r.readString())
+	case flux.TTime:
+		return values.NewTime(values.Time(r.readVarint()))
+	}
+	r.setErr(fmt.Errorf("unexpected column type %v in spilled table", typ))
+	return nil
+}
+
+func (r *spillReader) setErr(err error) {
+	if r.err != nil || err == nil {
+		return
+	}
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	r.err = err
+}
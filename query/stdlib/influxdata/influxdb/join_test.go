@@ -0,0 +1,155 @@
+package influxdb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/memory"
+)
+
+// joinedTableStore collects the tables passed on by a join.
+type joinedTableStore struct {
+	tables []*executetest.Table
+	err    error
+}
+
+func (s *joinedTableStore) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return nil
+}
+
+func (s *joinedTableStore) Process(id execute.DatasetID, tbl flux.Table) error {
+	t, err := executetest.ConvertTable(tbl)
+	if err != nil {
+		return err
+	}
+	s.tables = append(s.tables, t)
+	return nil
+}
+
+func (s *joinedTableStore) UpdateWatermark(id execute.DatasetID, t execute.Time) error {
+	return nil
+}
+
+func (s *joinedTableStore) UpdateProcessingTime(id execute.DatasetID, t execute.Time) error {
+	return nil
+}
+
+func (s *joinedTableStore) Finish(id execute.DatasetID, err error) {
+	s.err = err
+}
+
+func alignedJoinTestTable(host string, v float64) *executetest.Table {
+	return &executetest.Table{
+		KeyCols: []string{"_measurement", "host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_measurement", Type: flux.TString},
+			{Label: "host", Type: flux.TString},
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "region", Type: flux.TString},
+			{Label: "ok", Type: flux.TBool},
+		},
+		Data: [][]interface{}{
+			{"m", host, execute.Time(1), v, "", true},
+			{"m", host, execute.Time(2), v + 1, nil, false},
+			{"m", host, execute.Time(3), nil, "west", nil},
+		},
+	}
+}
+
+// runAlignedJoin joins tables for hosts a, b and c read by both parents,
+// finishing the left parent before the right one has sent every table.
+func runAlignedJoin(t *testing.T, deps JoinDependencies) ([]*executetest.Table, error) {
+	t.Helper()
+	parents := []execute.DatasetID{executetest.RandomDatasetID(), executetest.RandomDatasetID()}
+	d := execute.NewPassthroughDataset(executetest.RandomDatasetID())
+	store := new(joinedTableStore)
+	d.AddTransformation(store)
+
+	tr := newAlignedJoinTransformation(executetest.RandomDatasetID(), d, &memory.Allocator{}, &AlignedJoinProcedureSpec{
+		TableNames: []string{"a", "b"},
+		On:         []string{"_time", "_measurement", "host"},
+	}, parents)
+	tr.spill = deps
+
+	hosts := []string{"a", "b", "c"}
+	for i, host := range hosts {
+		if err := tr.Process(parents[0], alignedJoinTestTable(host, float64(i))); err != nil {
+			tr.Finish(parents[0], err)
+			tr.Finish(parents[1], err)
+			return nil, err
+		}
+	}
+	for i, host := range hosts {
+		if i == len(hosts)-1 {
+			tr.Finish(parents[0], nil)
+		}
+		if err := tr.Process(parents[1], alignedJoinTestTable(host, float64(10*i))); err != nil {
+			tr.Finish(parents[1], err)
+			return nil, err
+		}
+	}
+	tr.Finish(parents[1], nil)
+	if store.err != nil {
+		return nil, store.err
+	}
+	if tr.size != 0 || tr.tempBytes != 0 {
+		t.Fatalf("join still holds %d bytes in memory and %d on disk", tr.size, tr.tempBytes)
+	}
+	executetest.NormalizeTables(store.tables)
+	return store.tables, nil
+}
+
+func TestAlignedJoin_Spill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "join-spill-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	want, err := runAlignedJoin(t, JoinDependencies{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 3 {
+		t.Fatalf("got %d joined tables, want 3", len(want))
+	}
+
+	got, err := runAlignedJoin(t, JoinDependencies{SpillDir: dir, SpillMemoryBytes: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Fatalf("unexpected tables when spilling -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	if files, err := ioutil.ReadDir(dir); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 0 {
+		t.Fatalf("join left %d temporary files behind", len(files))
+	}
+}
+
+func TestAlignedJoin_SpillLimitExceeded(t *testing.T) {
+	dir, err := ioutil.TempDir("", "join-spill-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = runAlignedJoin(t, JoinDependencies{SpillDir: dir, SpillMemoryBytes: 1, SpillMaxTempBytes: 1})
+	if err != errJoinSpillLimitExceeded {
+		t.Fatalf("got error %v, want %v", err, errJoinSpillLimitExceeded)
+	}
+
+	if files, err := ioutil.ReadDir(dir); err != nil {
+		t.Fatal(err)
+	} else if len(files) != 0 {
+		t.Fatalf("join left %d temporary files behind", len(files))
+	}
+}
@@ -3,6 +3,8 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
@@ -15,6 +17,7 @@ import (
 const (
 	ReadRangePhysKind     = "ReadRangePhysKind"
 	ReadGroupPhysKind     = "ReadGroupPhysKind"
+	ReadAggregatePhysKind = "ReadAggregatePhysKind"
 	ReadTagKeysPhysKind   = "ReadTagKeysPhysKind"
 	ReadTagValuesPhysKind = "ReadTagValuesPhysKind"
 )
@@ -44,6 +47,20 @@ func (s *ReadGroupPhysSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// PlanDetails implements plan.Detailer, describing the grouping and
+// aggregate pushed down into the read.
+func (s *ReadGroupPhysSpec) PlanDetails() string {
+	mode := "by"
+	if s.GroupMode == flux.GroupModeExcept {
+		mode = "except"
+	}
+	details := s.ReadRangePhysSpec.PlanDetails() + fmt.Sprintf("group: %s [%s]\n", mode, strings.Join(s.GroupKeys, ", "))
+	if s.AggregateMethod != "" {
+		details += fmt.Sprintf("aggregate: %s\n", s.AggregateMethod)
+	}
+	return details
+}
+
 type ReadRangePhysSpec struct {
 	plan.DefaultCost
 
@@ -109,6 +126,24 @@ func (s *ReadRangePhysSpec) LookupBucketID(ctx context.Context, orgID influxdb.I
 	}
 }
 
+// PlanDetails implements plan.Detailer, describing the bucket and range of
+// the read and the filter pushed down into it.
+func (s *ReadRangePhysSpec) PlanDetails() string {
+	var b strings.Builder
+	if s.Bucket != "" {
+		fmt.Fprintf(&b, "bucket: %s\n", s.Bucket)
+	} else {
+		fmt.Fprintf(&b, "bucketID: %s\n", s.BucketID)
+	}
+	fmt.Fprintf(&b, "range: [%s, %s)\n",
+		s.Bounds.Start.Time(s.Bounds.Now).Format(time.RFC3339Nano),
+		s.Bounds.Stop.Time(s.Bounds.Now).Format(time.RFC3339Nano))
+	if s.FilterSet {
+		fmt.Fprintf(&b, "filter: %v\n", semantic.Formatted(s.Filter))
+	}
+	return b.String()
+}
+
 // TimeBounds implements plan.BoundsAwareProcedureSpec.
 func (s *ReadRangePhysSpec) TimeBounds(predecessorBounds *plan.Bounds) *plan.Bounds {
 	return &plan.Bounds{
@@ -117,6 +152,31 @@ func (s *ReadRangePhysSpec) TimeBounds(predecessorBounds *plan.Bounds) *plan.Bou
 	}
 }
 
+// ReadAggregatePhysSpec reads the series of a ReadRangePhysSpec with the
+// points of each series aggregated into one by storage.
+type ReadAggregatePhysSpec struct {
+	ReadRangePhysSpec
+
+	AggregateMethod string
+}
+
+func (s *ReadAggregatePhysSpec) Kind() plan.ProcedureKind {
+	return ReadAggregatePhysKind
+}
+
+func (s *ReadAggregatePhysSpec) Copy() plan.ProcedureSpec {
+	ns := new(ReadAggregatePhysSpec)
+	ns.ReadRangePhysSpec = *s.ReadRangePhysSpec.Copy().(*ReadRangePhysSpec)
+	ns.AggregateMethod = s.AggregateMethod
+	return ns
+}
+
+// PlanDetails implements plan.Detailer, describing the aggregate pushed
+// down into the read.
+func (s *ReadAggregatePhysSpec) PlanDetails() string {
+	return s.ReadRangePhysSpec.PlanDetails() + fmt.Sprintf("aggregate: %s\n", s.AggregateMethod)
+}
+
 type ReadTagKeysPhysSpec struct {
 	ReadRangePhysSpec
 }
@@ -146,3 +206,8 @@ func (s *ReadTagValuesPhysSpec) Copy() plan.ProcedureSpec {
 	ns.TagKey = s.TagKey
 	return ns
 }
+
+// PlanDetails implements plan.Detailer.
+func (s *ReadTagValuesPhysSpec) PlanDetails() string {
+	return s.ReadRangePhysSpec.PlanDetails() + fmt.Sprintf("tag key: %s\n", s.TagKey)
+}
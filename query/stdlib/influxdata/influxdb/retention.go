@@ -0,0 +1,63 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// RetentionLookup is implemented by a BucketLookup that can also find the
+// retention period of a bucket.
+type RetentionLookup interface {
+	LookupRetentionPeriod(ctx context.Context, orgID, id platform.ID) (time.Duration, bool)
+}
+
+// checkRetention compares the bounds of a read against the retention period of
+// the bucket being read. If the bounds start before the oldest time that can
+// still be stored in the bucket, the returned metadata notes the effective
+// start of the read, or an error is returned if the dependencies are
+// configured to reject such reads.
+func checkRetention(ctx context.Context, deps FromDependencies, orgID, bucketID platform.ID, bounds *execute.Bounds, now time.Time) (flux.Metadata, error) {
+	rl, ok := deps.BucketLookup.(RetentionLookup)
+	if !ok || bounds == nil {
+		return nil, nil
+	}
+
+	rp, ok := rl.LookupRetentionPeriod(ctx, orgID, bucketID)
+	if !ok || rp <= 0 {
+		return nil, nil
+	}
+
+	start := bounds.Start.Time()
+	oldest := now.Add(-rp)
+	if !start.Before(oldest) {
+		return nil, nil
+	}
+
+	msg := fmt.Sprintf("range start %s is before the retention period of bucket %s; data is only available from %s",
+		start.UTC().Format(time.RFC3339Nano), bucketID, oldest.UTC().Format(time.RFC3339Nano))
+	if deps.RejectOutsideRetention {
+		return nil, &flux.Error{
+			Code: codes.Invalid,
+			Msg:  msg,
+		}
+	}
+
+	if deps.Logger != nil {
+		deps.Logger.Info("Query range truncated by bucket retention period",
+			zap.String("org_id", orgID.String()),
+			zap.String("bucket_id", bucketID.String()),
+			zap.Time("start", start),
+			zap.Time("effective_start", oldest))
+	}
+	return flux.Metadata{
+		"influxdb/retention-warnings":        []interface{}{msg},
+		"influxdb/retention-effective-start": []interface{}{oldest.UTC().Format(time.RFC3339Nano)},
+	}, nil
+}
@@ -0,0 +1,71 @@
+package influxdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/execute"
+	platform "github.com/influxdata/influxdb"
+)
+
+type retentionLookup struct {
+	rp time.Duration
+}
+
+func (retentionLookup) Lookup(context.Context, platform.ID, string) (platform.ID, bool) {
+	return 0, false
+}
+
+func (retentionLookup) LookupName(context.Context, platform.ID, platform.ID) string {
+	return ""
+}
+
+func (l retentionLookup) LookupRetentionPeriod(context.Context, platform.ID, platform.ID) (time.Duration, bool) {
+	return l.rp, true
+}
+
+func TestCheckRetention(t *testing.T) {
+	now := time.Date(2019, 11, 1, 0, 0, 0, 0, time.UTC)
+	bounds := func(start time.Time) *execute.Bounds {
+		return &execute.Bounds{
+			Start: execute.Time(start.UnixNano()),
+			Stop:  execute.Time(now.UnixNano()),
+		}
+	}
+
+	deps := FromDependencies{BucketLookup: retentionLookup{rp: 24 * time.Hour}}
+
+	// A range within the retention period is not annotated.
+	md, err := checkRetention(context.Background(), deps, 1, 2, bounds(now.Add(-time.Hour)), now)
+	if err != nil {
+		t.Fatal(err)
+	} else if md != nil {
+		t.Fatalf("unexpected metadata: %v", md)
+	}
+
+	// A range extending beyond the retention period is annotated with its
+	// effective start.
+	md, err = checkRetention(context.Background(), deps, 1, 2, bounds(now.Add(-48*time.Hour)), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := md["influxdb/retention-effective-start"], "2019-10-31T00:00:00Z"; len(got) != 1 || got[0] != want {
+		t.Fatalf("unexpected effective start: got %v, want %v", got, want)
+	}
+	if got := md["influxdb/retention-warnings"]; len(got) != 1 {
+		t.Fatalf("expected a warning, got %v", got)
+	}
+
+	// The same range fails when such reads are rejected.
+	deps.RejectOutsideRetention = true
+	if _, err := checkRetention(context.Background(), deps, 1, 2, bounds(now.Add(-48*time.Hour)), now); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Buckets with infinite retention are never annotated.
+	deps.BucketLookup = retentionLookup{rp: platform.InfiniteRetention}
+	if md, err := checkRetention(context.Background(), deps, 1, 2, bounds(time.Unix(0, 0)), now); err != nil || md != nil {
+		t.Fatalf("unexpected result: %v, %v", md, err)
+	}
+}
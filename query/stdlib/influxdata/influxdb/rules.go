@@ -14,9 +14,19 @@ func init() {
 		PushDownRangeRule{},
 		PushDownFilterRule{},
 		PushDownGroupRule{},
+		PushDownBareAggregateRule{AggregateKind: universe.CountKind},
+		PushDownBareAggregateRule{AggregateKind: universe.SumKind},
+		PushDownBareAggregateRule{AggregateKind: universe.MinKind},
+		PushDownBareAggregateRule{AggregateKind: universe.MaxKind},
+		PushDownBareAggregateRule{AggregateKind: universe.MeanKind},
+		PushDownGroupAggregateRule{AggregateKind: universe.CountKind},
+		PushDownGroupAggregateRule{AggregateKind: universe.SumKind},
+		PushDownGroupAggregateRule{AggregateKind: universe.MinKind},
+		PushDownGroupAggregateRule{AggregateKind: universe.MaxKind},
 		PushDownReadTagKeysRule{},
 		PushDownReadTagValuesRule{},
 		SortedPivotRule{},
+		AlignedJoinRule{},
 	)
 }
 
@@ -58,6 +68,130 @@ func (rule PushDownGroupRule) Rewrite(node plan.Node) (plan.Node, bool, error) {
 	}), true, nil
 }
 
+// PushDownBareAggregateRule pushes down an aggregate of each series read by
+// ReadRange to storage, which then sends a single point for each series
+// instead of all of its points:
+//
+//	ReadRange |> count()  =>  ReadAggregate(count) |> sum()
+//
+// The aggregate is left in the plan to turn the point of each series into
+// the row it would have returned: the counts are summed, and the sum, min,
+// max or mean of a single point is that point.
+type PushDownBareAggregateRule struct {
+	// AggregateKind is one of count, sum, min, max or mean.
+	AggregateKind plan.ProcedureKind
+}
+
+func (rule PushDownBareAggregateRule) Name() string {
+	return "PushDownBareAggregateRule(" + string(rule.AggregateKind) + ")"
+}
+
+func (rule PushDownBareAggregateRule) Pattern() plan.Pattern {
+	return plan.Pat(rule.AggregateKind, plan.Pat(ReadRangePhysKind))
+}
+
+func (rule PushDownBareAggregateRule) Rewrite(pn plan.Node) (plan.Node, bool, error) {
+	fromNode := pn.Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(*ReadRangePhysSpec)
+
+	merge, ok := mergeSeriesAggregate(pn.ProcedureSpec())
+	if !ok {
+		return pn, false, nil
+	}
+
+	if err := fromNode.ReplaceSpec(&ReadAggregatePhysSpec{
+		ReadRangePhysSpec: *fromSpec.Copy().(*ReadRangePhysSpec),
+		AggregateMethod:   string(rule.AggregateKind),
+	}); err != nil {
+		return nil, false, err
+	}
+	if merge != nil {
+		if err := pn.ReplaceSpec(merge); err != nil {
+			return nil, false, err
+		}
+	}
+	return pn, true, nil
+}
+
+// PushDownGroupAggregateRule pushes down an aggregate of the groups read by
+// ReadGroup to storage, which then sends a single point for each series of
+// a group instead of all of its points:
+//
+//	ReadGroup |> max()  =>  ReadGroup(max) |> max()
+//
+// The aggregate is left in the plan to merge the points of the series of
+// each group into the row of the group. The mean is not pushed down, as the
+// mean of a group is not the mean of the means of its series.
+type PushDownGroupAggregateRule struct {
+	// AggregateKind is one of count, sum, min or max.
+	AggregateKind plan.ProcedureKind
+}
+
+func (rule PushDownGroupAggregateRule) Name() string {
+	return "PushDownGroupAggregateRule(" + string(rule.AggregateKind) + ")"
+}
+
+func (rule PushDownGroupAggregateRule) Pattern() plan.Pattern {
+	return plan.Pat(rule.AggregateKind, plan.Pat(ReadGroupPhysKind))
+}
+
+func (rule PushDownGroupAggregateRule) Rewrite(pn plan.Node) (plan.Node, bool, error) {
+	fromNode := pn.Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(*ReadGroupPhysSpec)
+
+	if fromSpec.AggregateMethod != "" || fromSpec.GroupMode != flux.GroupModeBy {
+		return pn, false, nil
+	}
+	if _, ok := pn.ProcedureSpec().(*universe.MeanProcedureSpec); ok {
+		return pn, false, nil
+	}
+	merge, ok := mergeSeriesAggregate(pn.ProcedureSpec())
+	if !ok {
+		return pn, false, nil
+	}
+
+	spec := fromSpec.Copy().(*ReadGroupPhysSpec)
+	spec.AggregateMethod = string(rule.AggregateKind)
+	if err := fromNode.ReplaceSpec(spec); err != nil {
+		return nil, false, err
+	}
+	if merge != nil {
+		if err := pn.ReplaceSpec(merge); err != nil {
+			return nil, false, err
+		}
+	}
+	return pn, true, nil
+}
+
+// mergeSeriesAggregate returns the aggregate that merges the points of each
+// series aggregated by storage as spec would have aggregated all of their
+// points, or nil if spec does. It returns false if storage cannot compute
+// spec, as it only aggregates the values of the series.
+func mergeSeriesAggregate(spec plan.ProcedureSpec) (plan.ProcedureSpec, bool) {
+	switch spec := spec.(type) {
+	case *universe.CountProcedureSpec:
+		if !isValueColumns(spec.Columns) {
+			return nil, false
+		}
+		return &universe.SumProcedureSpec{AggregateConfig: spec.AggregateConfig}, true
+	case *universe.SumProcedureSpec:
+		return nil, isValueColumns(spec.Columns)
+	case *universe.MeanProcedureSpec:
+		return nil, isValueColumns(spec.Columns)
+	case *universe.MinProcedureSpec:
+		return nil, spec.Column == execute.DefaultValueColLabel
+	case *universe.MaxProcedureSpec:
+		return nil, spec.Column == execute.DefaultValueColLabel
+	default:
+		return nil, false
+	}
+}
+
+// isValueColumns returns true if columns is only the value column.
+func isValueColumns(columns []string) bool {
+	return len(columns) == 1 && columns[0] == execute.DefaultValueColLabel
+}
+
 // PushDownRangeRule pushes down a range filter to storage
 type PushDownRangeRule struct{}
 
@@ -595,3 +729,56 @@ func (SortedPivotRule) Rewrite(pn plan.Node) (plan.Node, bool, error) {
 	}
 	return pn, false, nil
 }
+
+// AlignedJoinRule replaces a join on time of two reads of the same time
+// range with an aligned join, which joins each pair of series as soon as
+// both have been read:
+//
+//	join(tables: {a: ReadRange, b: ReadRange}, on: ["_time", ...])  =>  AlignedJoin
+//
+// The reads run concurrently either way; the aligned join only changes how
+// long their tables are held.
+type AlignedJoinRule struct{}
+
+func (AlignedJoinRule) Name() string {
+	return "AlignedJoinRule"
+}
+
+func (AlignedJoinRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.MergeJoinKind, plan.Pat(ReadRangePhysKind), plan.Pat(ReadRangePhysKind))
+}
+
+func (AlignedJoinRule) Rewrite(pn plan.Node) (plan.Node, bool, error) {
+	joinSpec := pn.ProcedureSpec().(*universe.MergeJoinProcedureSpec)
+	left := pn.Predecessors()[0].ProcedureSpec().(*ReadRangePhysSpec)
+	right := pn.Predecessors()[1].ProcedureSpec().(*ReadRangePhysSpec)
+
+	if len(joinSpec.TableNames) != 2 {
+		return pn, false, nil
+	}
+
+	// The series are aligned by time only if both reads are of the
+	// same range, and they are joined on time.
+	lb, rb := left.TimeBounds(nil), right.TimeBounds(nil)
+	if lb.Start != rb.Start || lb.Stop != rb.Stop {
+		return pn, false, nil
+	}
+	onTime := false
+	for _, label := range joinSpec.On {
+		if label == execute.DefaultTimeColLabel {
+			onTime = true
+			break
+		}
+	}
+	if !onTime {
+		return pn, false, nil
+	}
+
+	if err := pn.ReplaceSpec(&AlignedJoinProcedureSpec{
+		TableNames: append([]string(nil), joinSpec.TableNames...),
+		On:         append([]string(nil), joinSpec.On...),
+	}); err != nil {
+		return nil, false, err
+	}
+	return pn, true, nil
+}
@@ -853,6 +853,324 @@ func TestPushDownGroupRule(t *testing.T) {
 	}
 }
 
+func TestPushDownBareAggregateRule(t *testing.T) {
+	readRange := influxdb.ReadRangePhysSpec{
+		Bucket: "my-bucket",
+		Bounds: flux.Bounds{
+			Start: fluxTime(5),
+			Stop:  fluxTime(10),
+		},
+	}
+	readAggregate := func(method string) *influxdb.ReadAggregatePhysSpec {
+		return &influxdb.ReadAggregatePhysSpec{
+			ReadRangePhysSpec: readRange,
+			AggregateMethod:   method,
+		}
+	}
+	rules := []plan.Rule{
+		influxdb.PushDownBareAggregateRule{AggregateKind: universe.CountKind},
+		influxdb.PushDownBareAggregateRule{AggregateKind: universe.SumKind},
+		influxdb.PushDownBareAggregateRule{AggregateKind: universe.MinKind},
+		influxdb.PushDownBareAggregateRule{AggregateKind: universe.MaxKind},
+		influxdb.PushDownBareAggregateRule{AggregateKind: universe.MeanKind},
+	}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name: "count",
+			// ReadRange -> count => ReadAggregate(count) -> sum
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", readAggregate("count")),
+					plan.CreatePhysicalNode("count", &universe.SumProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name: "sum",
+			// ReadRange -> sum => ReadAggregate(sum) -> sum
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", readAggregate("sum")),
+					plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name: "min",
+			// ReadRange -> min => ReadAggregate(min) -> min
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("min", &universe.MinProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", readAggregate("min")),
+					plan.CreatePhysicalNode("min", &universe.MinProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name: "mean with successor",
+			// ReadRange -> mean -> yield => ReadAggregate(mean) -> mean -> yield
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("mean", &universe.MeanProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+					plan.CreatePhysicalNode("yield", &universe.YieldProcedureSpec{Name: "result"}),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", readAggregate("mean")),
+					plan.CreatePhysicalNode("mean", &universe.MeanProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+					plan.CreatePhysicalNode("yield", &universe.YieldProcedureSpec{Name: "result"}),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+		},
+		{
+			Name: "other column",
+			// ReadRange -> max(column: "_time") => no change
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("max", &universe.MaxProcedureSpec{
+						SelectorConfig: execute.SelectorConfig{Column: "_time"},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name: "multiple columns",
+			// ReadRange -> count(columns: ["_value", "host"]) => no change
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{
+						AggregateConfig: execute.AggregateConfig{Columns: []string{"_value", "host"}},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name: "with multiple successors",
+			//
+			// count    group       count    group
+			//     \    /       =>      \    /
+			//    ReadRange            ReadRange
+			//
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+					plan.CreatePhysicalNode("group", &universe.GroupProcedureSpec{
+						GroupMode: flux.GroupModeBy,
+						GroupKeys: []string{"host"},
+					}),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{0, 2},
+				},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
+
+func TestPushDownGroupAggregateRule(t *testing.T) {
+	readGroup := influxdb.ReadGroupPhysSpec{
+		ReadRangePhysSpec: influxdb.ReadRangePhysSpec{
+			Bucket: "my-bucket",
+			Bounds: flux.Bounds{
+				Start: fluxTime(5),
+				Stop:  fluxTime(10),
+			},
+		},
+		GroupMode: flux.GroupModeBy,
+		GroupKeys: []string{"host"},
+	}
+	readGroupAggregate := func(method string) *influxdb.ReadGroupPhysSpec {
+		spec := readGroup
+		spec.AggregateMethod = method
+		return &spec
+	}
+	rules := []plan.Rule{
+		influxdb.PushDownGroupAggregateRule{AggregateKind: universe.CountKind},
+		influxdb.PushDownGroupAggregateRule{AggregateKind: universe.SumKind},
+		influxdb.PushDownGroupAggregateRule{AggregateKind: universe.MinKind},
+		influxdb.PushDownGroupAggregateRule{AggregateKind: universe.MaxKind},
+		influxdb.PushDownGroupAggregateRule{AggregateKind: universe.MeanKind},
+	}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name: "count",
+			// ReadGroup -> count => ReadGroup(count) -> sum
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", &readGroup),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", readGroupAggregate("count")),
+					plan.CreatePhysicalNode("count", &universe.SumProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name: "max",
+			// ReadGroup -> max => ReadGroup(max) -> max
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", &readGroup),
+					plan.CreatePhysicalNode("max", &universe.MaxProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", readGroupAggregate("max")),
+					plan.CreatePhysicalNode("max", &universe.MaxProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name: "mean",
+			// ReadGroup -> mean => no change
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", &readGroup),
+					plan.CreatePhysicalNode("mean", &universe.MeanProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name: "already aggregated",
+			// ReadGroup(count) -> sum => no change
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", readGroupAggregate("count")),
+					plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+						AggregateConfig: execute.DefaultAggregateConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name: "other column",
+			// ReadGroup -> min(column: "host") => no change
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadGroup", &readGroup),
+					plan.CreatePhysicalNode("min", &universe.MinProcedureSpec{
+						SelectorConfig: execute.SelectorConfig{Column: "host"},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
+
 func TestReadTagKeysRule(t *testing.T) {
 	fromSpec := influxdb.FromProcedureSpec{
 		Bucket: "my-bucket",
@@ -1284,3 +1602,98 @@ func TestReadTagValuesRule(t *testing.T) {
 		})
 	}
 }
+
+func TestAlignedJoinRule(t *testing.T) {
+	readRange := func(bucket string, stop int64) *influxdb.ReadRangePhysSpec {
+		return &influxdb.ReadRangePhysSpec{
+			Bucket: bucket,
+			Bounds: flux.Bounds{
+				Start: fluxTime(5),
+				Stop:  fluxTime(stop),
+			},
+		}
+	}
+	join := func(on ...string) *universe.MergeJoinProcedureSpec {
+		return &universe.MergeJoinProcedureSpec{
+			TableNames: []string{"a", "b"},
+			On:         on,
+		}
+	}
+
+	// The joins left unchanged are spelled out rather than using NoChange,
+	// as copying a join does not copy its table names.
+	tests := []plantest.RuleTestCase{
+		{
+			Name: "aligned",
+			// join(ReadRange, ReadRange)  =>  AlignedJoin(ReadRange, ReadRange)
+			Rules: []plan.Rule{influxdb.AlignedJoinRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange0", readRange("a", 10)),
+					plan.CreatePhysicalNode("ReadRange1", readRange("b", 10)),
+					plan.CreatePhysicalNode("join", join("_time", "host")),
+				},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange0", readRange("a", 10)),
+					plan.CreatePhysicalNode("ReadRange1", readRange("b", 10)),
+					plan.CreatePhysicalNode("join", &influxdb.AlignedJoinProcedureSpec{
+						TableNames: []string{"a", "b"},
+						On:         []string{"_time", "host"},
+					}),
+				},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			},
+		},
+		{
+			Name:  "different ranges",
+			Rules: []plan.Rule{influxdb.AlignedJoinRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange0", readRange("a", 10)),
+					plan.CreatePhysicalNode("ReadRange1", readRange("b", 20)),
+					plan.CreatePhysicalNode("join", join("_time", "host")),
+				},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange0", readRange("a", 10)),
+					plan.CreatePhysicalNode("ReadRange1", readRange("b", 20)),
+					plan.CreatePhysicalNode("join", join("_time", "host")),
+				},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			},
+		},
+		{
+			Name:  "not on time",
+			Rules: []plan.Rule{influxdb.AlignedJoinRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange0", readRange("a", 10)),
+					plan.CreatePhysicalNode("ReadRange1", readRange("b", 10)),
+					plan.CreatePhysicalNode("join", join("host")),
+				},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange0", readRange("a", 10)),
+					plan.CreatePhysicalNode("ReadRange1", readRange("b", 10)),
+					plan.CreatePhysicalNode("join", join("host")),
+				},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 func init() {
 	execute.RegisterSource(ReadRangePhysKind, createReadFilterSource)
 	execute.RegisterSource(ReadGroupPhysKind, createReadGroupSource)
+	execute.RegisterSource(ReadAggregatePhysKind, createReadAggregateSource)
 	execute.RegisterSource(ReadTagKeysPhysKind, createReadTagKeysSource)
 	execute.RegisterSource(ReadTagValuesPhysKind, createReadTagValuesSource)
 }
@@ -32,8 +33,10 @@ type Source struct {
 	id execute.DatasetID
 	ts []execute.Transformation
 
-	alloc *memory.Allocator
-	stats cursors.CursorStats
+	alloc    *memory.Allocator
+	stats    cursors.CursorStats
+	meta     flux.Metadata
+	duration time.Duration
 
 	runner runner
 
@@ -46,13 +49,16 @@ func (s *Source) Run(ctx context.Context) {
 	labelValues := s.m.getLabelValues(ctx, s.orgID, s.op)
 	start := time.Now()
 	var err error
-	if flux.IsExperimentalTracingEnabled() {
+	// Requests traced in full record the read statistics of each source.
+	if flux.IsExperimentalTracingEnabled() || tracing.IsDebug(ctx) {
 		span, ctxWithSpan := tracing.StartSpanFromContextWithOperationName(ctx, "source-"+s.op)
 		err = s.runner.run(ctxWithSpan)
+		span.LogKV("scanned_values", s.stats.ScannedValues, "scanned_bytes", s.stats.ScannedBytes, "duplicate_values", s.stats.DuplicateValues)
 		span.Finish()
 	} else {
 		err = s.runner.run(ctx)
 	}
+	s.duration = time.Since(start)
 	s.m.recordMetrics(labelValues, start)
 	for _, t := range s.ts {
 		t.Finish(s.id, err)
@@ -64,10 +70,20 @@ func (s *Source) AddTransformation(t execute.Transformation) {
 }
 
 func (s *Source) Metadata() flux.Metadata {
-	return flux.Metadata{
-		"influxdb/scanned-bytes":  []interface{}{s.stats.ScannedBytes},
-		"influxdb/scanned-values": []interface{}{s.stats.ScannedValues},
+	md := flux.Metadata{
+		"influxdb/scanned-bytes":    []interface{}{s.stats.ScannedBytes},
+		"influxdb/scanned-values":   []interface{}{s.stats.ScannedValues},
+		"influxdb/duplicate-values": []interface{}{s.stats.DuplicateValues},
+		query.ReadProfileMetadataKey: []interface{}{&query.ReadProfile{
+			DatasetID:     s.id.String(),
+			Operation:     s.op,
+			Duration:      platform.Duration{Duration: s.duration},
+			ScannedValues: int64(s.stats.ScannedValues),
+			ScannedBytes:  int64(s.stats.ScannedBytes),
+		}},
 	}
+	md.AddAll(s.meta)
+	return md
 }
 
 func (s *Source) processTables(ctx context.Context, tables TableIterator, watermark execute.Time) error {
@@ -82,6 +98,14 @@ func (s *Source) processTables(ctx context.Context, tables TableIterator, waterm
 	stats := tables.Statistics()
 	s.stats.ScannedValues += stats.ScannedValues
 	s.stats.ScannedBytes += stats.ScannedBytes
+	s.stats.DuplicateValues += stats.DuplicateValues
+
+	if mt, ok := tables.(MetadataTableIterator); ok {
+		if s.meta == nil {
+			s.meta = make(flux.Metadata)
+		}
+		s.meta.AddAll(mt.Metadata())
+	}
 
 	for _, t := range s.ts {
 		if err := t.UpdateWatermark(s.id, watermark); err != nil {
@@ -181,11 +205,16 @@ func createReadFilterSource(s plan.ProcedureSpec, id execute.DatasetID, a execut
 		return nil, err
 	}
 
+	meta, err := checkRetention(ctx, deps, orgID, bucketID, bounds, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	var filter *semantic.FunctionExpression
 	if spec.FilterSet {
 		filter = spec.Filter
 	}
-	return ReadFilterSource(
+	src := ReadFilterSource(
 		id,
 		deps.Reader,
 		ReadFilterSpec{
@@ -195,7 +224,9 @@ func createReadFilterSource(s plan.ProcedureSpec, id execute.DatasetID, a execut
 			Predicate:      filter,
 		},
 		a,
-	), nil
+	)
+	src.(*readFilterSource).meta = meta
+	return src, nil
 }
 
 type readGroupSource struct {
@@ -258,11 +289,16 @@ func createReadGroupSource(s plan.ProcedureSpec, id execute.DatasetID, a execute
 		return nil, err
 	}
 
+	meta, err := checkRetention(ctx, deps, orgID, bucketID, bounds, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	var filter *semantic.FunctionExpression
 	if spec.FilterSet {
 		filter = spec.Filter
 	}
-	return ReadGroupSource(
+	src := ReadGroupSource(
 		id,
 		deps.Reader,
 		ReadGroupSpec{
@@ -277,7 +313,102 @@ func createReadGroupSource(s plan.ProcedureSpec, id execute.DatasetID, a execute
 			AggregateMethod: spec.AggregateMethod,
 		},
 		a,
-	), nil
+	)
+	src.(*readGroupSource).meta = meta
+	return src, nil
+}
+
+type readAggregateSource struct {
+	Source
+	reader   Reader
+	readSpec ReadAggregateSpec
+}
+
+func ReadAggregateSource(id execute.DatasetID, r Reader, readSpec ReadAggregateSpec, a execute.Administration) execute.Source {
+	src := new(readAggregateSource)
+
+	src.id = id
+	src.alloc = a.Allocator()
+
+	src.reader = r
+	src.readSpec = readSpec
+
+	src.m = GetStorageDependencies(a.Context()).FromDeps.Metrics
+	src.orgID = readSpec.OrganizationID
+	src.op = "readAggregate"
+
+	src.runner = src
+	return src
+}
+
+func (s *readAggregateSource) run(ctx context.Context) error {
+	stop := s.readSpec.Bounds.Stop
+	tables, err := s.reader.ReadAggregate(
+		ctx,
+		s.readSpec,
+		s.alloc,
+	)
+	if err != nil {
+		return err
+	}
+	return s.processTables(ctx, tables, stop)
+}
+
+func createReadAggregateSource(s plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	span, ctx := tracing.StartSpanFromContext(a.Context())
+	defer span.Finish()
+
+	spec := s.(*ReadAggregatePhysSpec)
+
+	bounds := a.StreamContext().Bounds()
+	if bounds == nil {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  "nil bounds passed to from",
+		}
+	}
+
+	deps := GetStorageDependencies(a.Context()).FromDeps
+
+	req := query.RequestFromContext(a.Context())
+	if req == nil {
+		return nil, &flux.Error{
+			Code: codes.Internal,
+			Msg:  "missing request on context",
+		}
+	}
+
+	orgID := req.OrganizationID
+	bucketID, err := spec.LookupBucketID(ctx, orgID, deps.BucketLookup)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := checkRetention(ctx, deps, orgID, bucketID, bounds, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var filter *semantic.FunctionExpression
+	if spec.FilterSet {
+		filter = spec.Filter
+	}
+	src := ReadAggregateSource(
+		id,
+		deps.Reader,
+		ReadAggregateSpec{
+			ReadFilterSpec: ReadFilterSpec{
+				OrganizationID: orgID,
+				BucketID:       bucketID,
+				Bounds:         *bounds,
+				Predicate:      filter,
+			},
+			AggregateMethod: spec.AggregateMethod,
+		},
+		a,
+	)
+	src.(*readAggregateSource).meta = meta
+	return src, nil
 }
 
 func createReadTagKeysSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 type HostLookup interface {
@@ -35,6 +36,15 @@ type FromDependencies struct {
 	BucketLookup       BucketLookup
 	OrganizationLookup OrganizationLookup
 	Metrics            *metrics
+
+	// RejectOutsideRetention causes reads whose range starts before the
+	// retention period of their bucket to fail, rather than be annotated
+	// with the effective start of the range.
+	RejectOutsideRetention bool
+
+	// Logger, if set, logs reads whose range is truncated by the retention
+	// period of their bucket.
+	Logger *zap.Logger
 }
 
 func (d FromDependencies) Validate() error {
@@ -108,6 +118,14 @@ type ReadFilterSpec struct {
 	Bounds execute.Bounds
 
 	Predicate *semantic.FunctionExpression
+
+	// ExcludeTagKeys are tag keys left out of the tables read, although the
+	// predicate may still refer to them. Keys grouped by are never left out.
+	ExcludeTagKeys []string
+
+	// DuplicateResolution selects the value kept of points with the same
+	// timestamp in overlapping TSM files.
+	DuplicateResolution cursors.DuplicateResolution
 }
 
 type ReadGroupSpec struct {
@@ -119,6 +137,16 @@ type ReadGroupSpec struct {
 	AggregateMethod string
 }
 
+// ReadAggregateSpec reads the series selected by a ReadFilterSpec, with the
+// points of each series aggregated into one.
+type ReadAggregateSpec struct {
+	ReadFilterSpec
+
+	// AggregateMethod is the aggregate computed, one of count, sum, min,
+	// max or mean.
+	AggregateMethod string
+}
+
 type ReadTagKeysSpec struct {
 	ReadFilterSpec
 }
@@ -131,6 +159,7 @@ type ReadTagValuesSpec struct {
 type Reader interface {
 	ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadGroup(ctx context.Context, spec ReadGroupSpec, alloc *memory.Allocator) (TableIterator, error)
+	ReadAggregate(ctx context.Context, spec ReadAggregateSpec, alloc *memory.Allocator) (TableIterator, error)
 
 	ReadTagKeys(ctx context.Context, spec ReadTagKeysSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadTagValues(ctx context.Context, spec ReadTagValuesSpec, alloc *memory.Allocator) (TableIterator, error)
@@ -143,3 +172,10 @@ type TableIterator interface {
 	flux.TableIterator
 	Statistics() cursors.CursorStats
 }
+
+// MetadataTableIterator is a TableIterator that also reports how the storage
+// engine read its tables, which is added to the metadata of the query.
+type MetadataTableIterator interface {
+	TableIterator
+	Metadata() flux.Metadata
+}
@@ -277,7 +277,7 @@ type ToTransformation struct {
 	spec               *ToProcedureSpec
 	implicitTagColumns bool
 	deps               ToDependencies
-	buf                *storage.BufferedPointsWriter
+	w                  *ToWriter
 }
 
 // RetractTable retracts the table for the transformation for the `to` flux function.
@@ -352,7 +352,7 @@ func NewToTransformation(ctx context.Context, d execute.Dataset, cache execute.T
 		spec:               toSpec,
 		implicitTagColumns: spec.TagColumns == nil,
 		deps:               deps,
-		buf:                storage.NewBufferedPointsWriter(DefaultBufferSize, deps.PointsWriter),
+		w:                  NewToWriter(deps, *orgID, *bucketID),
 	}, nil
 }
 
@@ -457,7 +457,7 @@ func (t *ToTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute
 // Finish is called after the `to` flux function's transformation is done processing.
 func (t *ToTransformation) Finish(id execute.DatasetID, err error) {
 	if err == nil {
-		err = t.buf.Flush(t.Ctx)
+		err = t.w.Flush(t.Ctx)
 	}
 	t.d.Finish(err)
 }
@@ -467,6 +467,20 @@ type ToDependencies struct {
 	BucketLookup       BucketLookup
 	OrganizationLookup OrganizationLookup
 	PointsWriter       storage.PointsWriter
+
+	// MaxBatchLines and MaxBatchBytes cap the points and the size as line
+	// protocol of each batch of points written, as the write API caps each
+	// write. Zero means no limit.
+	MaxBatchLines int
+	MaxBatchBytes int
+
+	// WriteHinter, if set, spaces out batches as storage asks writers to.
+	WriteHinter WriteHinter
+
+	// BucketService and MeasurementSchemaService, if set, check points
+	// written to buckets with an explicit schema against it.
+	BucketService            platform.BucketService
+	MeasurementSchemaService platform.MeasurementSchemaService
 }
 
 // Validate returns an error if any required field is unset.
@@ -670,7 +684,7 @@ func writeTable(ctx context.Context, t *ToTransformation, tbl flux.Table) (err e
 			}
 		}
 
-		return t.buf.WritePoints(ctx, points)
+		return t.w.WritePoints(ctx, points)
 	})
 }
 
@@ -0,0 +1,305 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	platform "github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// maxToWriteFailures is the maximum number of reasons points were dropped
+// listed in the error of a call to to().
+const maxToWriteFailures = 10
+
+// WriteHinter advises writers on how to shape their writes given the load
+// on storage.
+type WriteHinter interface {
+	WriteHints() storage.WriteHints
+}
+
+// ToWriter writes the points of a call to to() to a bucket in batches.
+// Batches stay within the limits of the write API, their points are checked
+// against the schema of the bucket as written points are, and they are
+// spaced out as storage asks writers to while it is loaded.
+//
+// Points dropped by storage do not fail the batch they are written in. The
+// others are written, and Flush reports how many were dropped and why.
+//
+// If the permission of the query to write to the bucket is restricted to
+// some of its series, points of other series fail the write.
+type ToWriter struct {
+	deps     ToDependencies
+	orgID    platform.ID
+	bucketID platform.ID
+
+	// scope matches the series the query may write, if its permission to
+	// write is restricted. It is read with the first points written.
+	scope     *platform.SeriesMatcher
+	scopeRead bool
+
+	// schemas are the measurement schemas of the bucket, if it has an
+	// explicit schema. They are read with the first points written.
+	schemas     map[string]*platform.MeasurementSchema
+	schemasRead bool
+
+	buf       []models.Point
+	bytes     int
+	lastWrite time.Time
+
+	written, dropped int
+	failures         []string
+	err              error
+}
+
+// NewToWriter returns a writer of points to the bucket with bucketID of the
+// organization with orgID.
+func NewToWriter(deps ToDependencies, orgID, bucketID platform.ID) *ToWriter {
+	return &ToWriter{
+		deps:     deps,
+		orgID:    orgID,
+		bucketID: bucketID,
+		buf:      make([]models.Point, 0, DefaultBufferSize),
+	}
+}
+
+// WritePoints buffers points, writing a batch whenever it is full.
+func (w *ToWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.checkScope(ctx, points); err != nil {
+		w.err = err
+		return err
+	}
+	if err := w.checkSchema(ctx, points); err != nil {
+		w.err = err
+		return err
+	}
+
+	for _, p := range points {
+		size := p.StringSize() + 1 // A point is written as a line.
+		if max := w.deps.MaxBatchBytes; max > 0 && size > max {
+			w.err = &flux.Error{
+				Code: codes.Invalid,
+				Msg:  fmt.Sprintf("point of %d bytes is larger than the maximum size of a write of %d bytes", size, max),
+			}
+			return w.err
+		}
+		if w.full(size) {
+			if err := w.write(ctx); err != nil {
+				return err
+			}
+		}
+		w.buf = append(w.buf, p)
+		w.bytes += size
+	}
+	return nil
+}
+
+// full reports whether a point of size bytes does not fit in the batch.
+func (w *ToWriter) full(size int) bool {
+	n := len(w.buf)
+	if n == 0 {
+		return false
+	}
+	if n >= DefaultBufferSize {
+		return true
+	}
+	if max := w.deps.MaxBatchLines; max > 0 && n >= max {
+		return true
+	}
+	if max := w.deps.MaxBatchBytes; max > 0 && w.bytes+size > max {
+		return true
+	}
+	return false
+}
+
+// Flush writes the buffered points. It returns an error if any of the points
+// written were dropped by storage.
+func (w *ToWriter) Flush(ctx context.Context) error {
+	if err := w.write(ctx); err != nil {
+		return err
+	}
+	if w.dropped == 0 {
+		return nil
+	}
+	return &flux.Error{
+		Code: codes.Invalid,
+		Msg: fmt.Sprintf("partial write: %d of %d points were dropped: %s",
+			w.dropped, w.dropped+w.written, strings.Join(w.failures, "; ")),
+	}
+}
+
+// write writes the batch of buffered points.
+func (w *ToWriter) write(ctx context.Context) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	if err := w.backoff(ctx); err != nil {
+		w.err = err
+		return err
+	}
+
+	err := w.deps.PointsWriter.WritePoints(ctx, w.buf)
+	w.lastWrite = time.Now()
+	if err != nil {
+		pwe, ok := partialWriteError(err)
+		if !ok {
+			w.err = err
+			return err
+		}
+		w.dropped += len(pwe.Points)
+		w.written += len(w.buf) - len(pwe.Points)
+		w.addFailures(pwe)
+	} else {
+		w.written += len(w.buf)
+	}
+
+	for i := range w.buf {
+		w.buf[i] = nil
+	}
+	w.buf, w.bytes = w.buf[:0], 0
+	return nil
+}
+
+// backoff waits for the time storage asks writers to leave between writes,
+// if any, since the previous write.
+func (w *ToWriter) backoff(ctx context.Context) error {
+	if w.deps.WriteHinter == nil || w.lastWrite.IsZero() {
+		return nil
+	}
+	d := w.deps.WriteHinter.WriteHints().Backoff - time.Since(w.lastWrite)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// addFailures records the distinct reasons points of pwe were dropped.
+func (w *ToWriter) addFailures(pwe tsdb.PartialWriteError) {
+	reasons := make([]string, 0, len(pwe.Points))
+	for _, p := range pwe.Points {
+		reasons = append(reasons, p.Reason)
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, pwe.Reason)
+	}
+	for _, r := range reasons {
+		if len(w.failures) == maxToWriteFailures {
+			return
+		}
+		seen := false
+		for _, f := range w.failures {
+			if f == r {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			w.failures = append(w.failures, r)
+		}
+	}
+}
+
+// checkScope returns an error if any of points is not of the series the
+// authorizer of the query is restricted to write.
+func (w *ToWriter) checkScope(ctx context.Context, points []models.Point) error {
+	if !w.scopeRead {
+		w.scopeRead = true
+		a, err := icontext.GetAuthorizer(ctx)
+		if err != nil {
+			return nil
+		}
+		p, err := platform.NewPermissionAtID(w.bucketID, platform.WriteAction, platform.BucketsResourceType, w.orgID)
+		if err != nil {
+			return err
+		}
+		if scope := platform.AuthorizerSeriesScope(a, *p); scope != nil {
+			if w.scope, err = scope.Matcher(); err != nil {
+				return err
+			}
+		}
+	}
+	if w.scope == nil {
+		return nil
+	}
+
+	for _, p := range points {
+		tags := p.Tags()
+		measurement := string(tags.Get(models.MeasurementTagKeyBytes))
+		if !w.scope.Match(measurement, func(key string) string { return string(tags.Get([]byte(key))) }) {
+			return &flux.Error{
+				Code: codes.PermissionDenied,
+				Msg:  fmt.Sprintf("series of measurement %q is not allowed to be written", measurement),
+			}
+		}
+	}
+	return nil
+}
+
+// checkSchema returns an error if any of points does not conform to the
+// schema of the bucket, if it has an explicit schema.
+func (w *ToWriter) checkSchema(ctx context.Context, points []models.Point) error {
+	if !w.schemasRead {
+		w.schemasRead = true
+		if w.deps.BucketService == nil || w.deps.MeasurementSchemaService == nil {
+			return nil
+		}
+		b, err := w.deps.BucketService.FindBucketByID(ctx, w.bucketID)
+		if err != nil {
+			return err
+		}
+		if !b.ExplicitSchema() {
+			return nil
+		}
+		schemas, err := w.deps.MeasurementSchemaService.FindMeasurementSchemas(ctx, platform.MeasurementSchemaFilter{BucketID: &w.bucketID})
+		if err != nil {
+			return err
+		}
+		w.schemas = make(map[string]*platform.MeasurementSchema, len(schemas))
+		for _, ms := range schemas {
+			w.schemas[ms.Name] = ms
+		}
+	}
+	if w.schemas == nil {
+		return nil
+	}
+
+	for _, p := range points {
+		if msg := storage.CheckPointSchema(p, w.schemas); msg != "" {
+			return &flux.Error{
+				Code: codes.Invalid,
+				Msg:  "point does not conform to the schema of the bucket: " + msg,
+			}
+		}
+	}
+	return nil
+}
+
+// partialWriteError returns the error of a write of which some points were
+// dropped by storage, if err is one.
+func partialWriteError(err error) (tsdb.PartialWriteError, bool) {
+	if e, ok := err.(*platform.Error); ok {
+		err = e.Err
+	}
+	pwe, ok := err.(tsdb.PartialWriteError)
+	return pwe, ok
+}
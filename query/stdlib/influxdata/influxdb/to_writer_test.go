@@ -0,0 +1,133 @@
+package influxdb_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// batchWriter records the size of each batch written, dropping the points
+// of the batches listed in drop.
+type batchWriter struct {
+	batches []int
+	drop    map[int]string
+}
+
+func (w *batchWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.batches = append(w.batches, len(points))
+	reason, ok := w.drop[len(w.batches)-1]
+	if !ok {
+		return nil
+	}
+	pwe := tsdb.PartialWriteError{Reason: reason, Dropped: 1}
+	pwe.Points = append(pwe.Points, tsdb.DroppedPoint{Index: 0, Reason: reason})
+	return pwe
+}
+
+func toPoints(t *testing.T, measurement string, n int) []models.Point {
+	t.Helper()
+	name := tsdb.EncodeNameString(1, 2)
+	points := make([]models.Point, 0, n)
+	for i := 0; i < n; i++ {
+		tags := models.NewTags(map[string]string{
+			models.MeasurementTagKey: measurement,
+			models.FieldKeyTagKey:    "v",
+			"host":                   fmt.Sprintf("h%d", i),
+		})
+		pt, err := models.NewPoint(name, tags, models.Fields{"v": float64(i)}, time.Unix(int64(i), 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		points = append(points, pt)
+	}
+	return points
+}
+
+func TestToWriter_Batches(t *testing.T) {
+	ctx := context.Background()
+	pw := &batchWriter{}
+	w := influxdb.NewToWriter(influxdb.ToDependencies{PointsWriter: pw, MaxBatchLines: 2}, 1, 2)
+
+	for i := 0; i < 2; i++ {
+		if err := w.WritePoints(ctx, toPoints(t, "cpu", 3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(pw.batches, []int{2, 2, 2}) {
+		t.Fatalf("unexpected batches %v", pw.batches)
+	}
+
+	// A point larger than a write is rejected.
+	w = influxdb.NewToWriter(influxdb.ToDependencies{PointsWriter: pw, MaxBatchBytes: 10}, 1, 2)
+	if err := w.WritePoints(ctx, toPoints(t, "cpu", 1)); err == nil {
+		t.Fatal("expected an error writing a point larger than a write")
+	}
+}
+
+func TestToWriter_PartialWrite(t *testing.T) {
+	ctx := context.Background()
+	pw := &batchWriter{drop: map[int]string{0: "field type conflict"}}
+	w := influxdb.NewToWriter(influxdb.ToDependencies{PointsWriter: pw, MaxBatchLines: 2}, 1, 2)
+
+	// The batches after the partial write are written.
+	if err := w.WritePoints(ctx, toPoints(t, "cpu", 3)); err != nil {
+		t.Fatal(err)
+	}
+	err := w.Flush(ctx)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 points were dropped: field type conflict") {
+		t.Fatalf("unexpected error of a partial write: %v", err)
+	}
+	if !cmp.Equal(pw.batches, []int{2, 1}) {
+		t.Fatalf("unexpected batches %v", pw.batches)
+	}
+}
+
+func TestToWriter_ExplicitSchema(t *testing.T) {
+	ctx := context.Background()
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+		return &platform.Bucket{ID: id, SchemaType: platform.SchemaTypeExplicit}, nil
+	}
+	schemas := mock.NewMeasurementSchemaService()
+	schemas.FindMeasurementSchemasF = func(ctx context.Context, filter platform.MeasurementSchemaFilter) ([]*platform.MeasurementSchema, error) {
+		return []*platform.MeasurementSchema{{
+			Name: "cpu",
+			Columns: []platform.MeasurementSchemaColumn{
+				{Name: "host", Type: platform.SchemaColumnTypeTag},
+				{Name: "v", Type: platform.SchemaColumnTypeField, DataType: platform.SchemaDataTypeFloat},
+			},
+		}}, nil
+	}
+	pw := &mock.PointsWriter{}
+	deps := influxdb.ToDependencies{
+		PointsWriter:             pw,
+		BucketService:            buckets,
+		MeasurementSchemaService: schemas,
+	}
+
+	w := influxdb.NewToWriter(deps, 1, 2)
+	if err := w.WritePoints(ctx, toPoints(t, "cpu", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WritePoints(ctx, toPoints(t, "mem", 1)); err == nil || !strings.Contains(err.Error(), `measurement "mem" is not in the schema`) {
+		t.Fatalf("unexpected error writing a point outside of the schema: %v", err)
+	}
+	if err := w.Flush(ctx); err == nil {
+		t.Fatal("expected the writer to fail after a point outside of the schema")
+	}
+	if len(pw.Points) != 0 {
+		t.Fatalf("expected no points to be written, got %d", len(pw.Points))
+	}
+}
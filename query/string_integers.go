@@ -0,0 +1,221 @@
+package query
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/values"
+)
+
+const StringIntegersCSVDialectType = "csv-string-integers"
+
+// StringIntegersCSVDialect is a CSV dialect that encodes integer and unsigned
+// integer columns as strings, so that clients decoding numbers as 64-bit
+// floats, such as JavaScript, do not lose precision.
+type StringIntegersCSVDialect struct {
+	csv.ResultEncoderConfig
+}
+
+func NewStringIntegersCSVDialect() *StringIntegersCSVDialect {
+	return &StringIntegersCSVDialect{
+		ResultEncoderConfig: csv.DefaultEncoderConfig(),
+	}
+}
+
+func (d *StringIntegersCSVDialect) Encoder() flux.MultiResultEncoder {
+	return &StringIntegersEncoder{
+		Encoder: csv.NewMultiResultEncoder(d.ResultEncoderConfig),
+	}
+}
+
+func (d *StringIntegersCSVDialect) DialectType() flux.DialectType {
+	return StringIntegersCSVDialectType
+}
+
+func (d *StringIntegersCSVDialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// StringIntegersEncoder converts the integer and unsigned integer columns of
+// the results to strings before encoding them with Encoder.
+type StringIntegersEncoder struct {
+	Encoder flux.MultiResultEncoder
+}
+
+func (e *StringIntegersEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	return e.Encoder.Encode(w, StringIntegers(results))
+}
+
+// StringIntegers returns a ResultIterator with the integer and unsigned
+// integer columns of results, including those in group keys, converted to
+// strings holding their decimal representation.
+func StringIntegers(results flux.ResultIterator) flux.ResultIterator {
+	return &stringIntegersResultIterator{ResultIterator: results}
+}
+
+type stringIntegersResultIterator struct {
+	flux.ResultIterator
+}
+
+func (r *stringIntegersResultIterator) Next() flux.Result {
+	return &stringIntegersResult{Result: r.ResultIterator.Next()}
+}
+
+type stringIntegersResult struct {
+	flux.Result
+}
+
+func (r *stringIntegersResult) Tables() flux.TableIterator {
+	return &stringIntegersTableIterator{TableIterator: r.Result.Tables()}
+}
+
+type stringIntegersTableIterator struct {
+	flux.TableIterator
+}
+
+func (it *stringIntegersTableIterator) Do(f func(flux.Table) error) error {
+	return it.TableIterator.Do(func(tbl flux.Table) error {
+		return f(newStringIntegersTable(tbl))
+	})
+}
+
+// isIntegerCol returns true if columns of type typ are converted to strings.
+func isIntegerCol(typ flux.ColType) bool {
+	return typ == flux.TInt || typ == flux.TUInt
+}
+
+// stringIntegersCols returns cols with the integer columns typed as strings.
+func stringIntegersCols(cols []flux.ColMeta) ([]flux.ColMeta, bool) {
+	var converted []flux.ColMeta
+	for j, c := range cols {
+		if !isIntegerCol(c.Type) {
+			continue
+		}
+		if converted == nil {
+			converted = append([]flux.ColMeta(nil), cols...)
+		}
+		converted[j].Type = flux.TString
+	}
+	return converted, converted != nil
+}
+
+// stringIntegersKey returns key with the values of its integer columns
+// converted to strings.
+func stringIntegersKey(key flux.GroupKey) flux.GroupKey {
+	cols, ok := stringIntegersCols(key.Cols())
+	if !ok {
+		return key
+	}
+	vs := make([]values.Value, len(cols))
+	for j, c := range key.Cols() {
+		switch {
+		case !isIntegerCol(c.Type):
+			vs[j] = key.Value(j)
+		case key.IsNull(j):
+			vs[j] = values.NewNull(flux.SemanticType(flux.TString))
+		case c.Type == flux.TInt:
+			vs[j] = values.NewString(strconv.FormatInt(key.ValueInt(j), 10))
+		default:
+			vs[j] = values.NewString(strconv.FormatUint(key.ValueUInt(j), 10))
+		}
+	}
+	return execute.NewGroupKey(cols, vs)
+}
+
+type stringIntegersTable struct {
+	flux.Table
+	key  flux.GroupKey
+	cols []flux.ColMeta
+}
+
+func newStringIntegersTable(tbl flux.Table) flux.Table {
+	cols, ok := stringIntegersCols(tbl.Cols())
+	if !ok {
+		return tbl
+	}
+	return &stringIntegersTable{
+		Table: tbl,
+		key:   stringIntegersKey(tbl.Key()),
+		cols:  cols,
+	}
+}
+
+func (t *stringIntegersTable) Key() flux.GroupKey   { return t.key }
+func (t *stringIntegersTable) Cols() []flux.ColMeta { return t.cols }
+
+func (t *stringIntegersTable) Do(f func(flux.ColReader) error) error {
+	return t.Table.Do(func(cr flux.ColReader) error {
+		scr := &stringIntegersColReader{
+			ColReader: cr,
+			key:       t.key,
+			cols:      t.cols,
+			strs:      make([]*array.Binary, len(t.cols)),
+		}
+		defer scr.release()
+		return f(scr)
+	})
+}
+
+// stringIntegersColReader converts the integer columns of a ColReader to
+// strings as they are read.
+type stringIntegersColReader struct {
+	flux.ColReader
+	key  flux.GroupKey
+	cols []flux.ColMeta
+	strs []*array.Binary
+}
+
+func (cr *stringIntegersColReader) Key() flux.GroupKey   { return cr.key }
+func (cr *stringIntegersColReader) Cols() []flux.ColMeta { return cr.cols }
+
+func (cr *stringIntegersColReader) Strings(j int) *array.Binary {
+	typ := cr.ColReader.Cols()[j].Type
+	if !isIntegerCol(typ) {
+		return cr.ColReader.Strings(j)
+	}
+	if cr.strs[j] != nil {
+		return cr.strs[j]
+	}
+
+	b := arrow.NewStringBuilder(nil)
+	b.Resize(cr.Len())
+	if typ == flux.TInt {
+		vs := cr.ColReader.Ints(j)
+		for i := 0; i < vs.Len(); i++ {
+			if vs.IsValid(i) {
+				b.AppendString(strconv.FormatInt(vs.Value(i), 10))
+			} else {
+				b.AppendNull()
+			}
+		}
+	} else {
+		vs := cr.ColReader.UInts(j)
+		for i := 0; i < vs.Len(); i++ {
+			if vs.IsValid(i) {
+				b.AppendString(strconv.FormatUint(vs.Value(i), 10))
+			} else {
+				b.AppendNull()
+			}
+		}
+	}
+	cr.strs[j] = b.NewBinaryArray()
+	b.Release()
+	return cr.strs[j]
+}
+
+// release releases the converted columns. They are only valid for as long as
+// the ColReader they were read from.
+func (cr *stringIntegersColReader) release() {
+	for _, a := range cr.strs {
+		if a != nil {
+			a.Release()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package query_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query"
+)
+
+func TestStringIntegersCSVDialect(t *testing.T) {
+	r := executetest.NewResult([]*executetest.Table{{
+		KeyCols: []string{"shard"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TInt},
+			{Label: "shard", Type: flux.TUInt},
+			{Label: "ratio", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{
+			{execute.Time(0), int64(9007199254740993), uint64(18446744073709551615), 0.5},
+			{execute.Time(10), nil, uint64(18446744073709551615), 1.5},
+		},
+	}})
+	r.Nm = "_result"
+
+	d := query.NewStringIntegersCSVDialect()
+	d.Annotations = []string{"datatype", "group"}
+
+	var buf bytes.Buffer
+	if _, err := d.Encoder().Encode(&buf, flux.NewSliceResultIterator([]flux.Result{r})); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := strings.Join([]string{
+		"#datatype,string,long,dateTime:RFC3339,string,string,double",
+		"#group,false,false,false,false,true,false",
+		",result,table,_time,_value,shard,ratio",
+		",_result,0,1970-01-01T00:00:00Z,9007199254740993,18446744073709551615,0.5",
+		",_result,0,1970-01-01T00:00:00.00000001Z,,18446744073709551615,1.5",
+		"",
+		"",
+	}, "\r\n")
+	if diff := cmp.Diff(exp, buf.String()); diff != "" {
+		t.Fatalf("unexpected output -want/+got:\n%s", diff)
+	}
+
+	// The converted values decode as strings.
+	results := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	ri, err := results.Decode(nopCloser{bytes.NewReader(buf.Bytes())})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ri.Release()
+	for ri.More() {
+		if err := ri.Next().Tables().Do(func(tbl flux.Table) error {
+			for _, c := range tbl.Cols() {
+				if c.Type == flux.TInt || c.Type == flux.TUInt {
+					t.Errorf("column %s decoded as %s", c.Label, c.Type)
+				}
+			}
+			tbl.Done()
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+type nopCloser struct {
+	*bytes.Reader
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,103 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ops for org quotas.
+var (
+	OpFindOrgQuota   = "FindOrgQuota"
+	OpFindOrgQuotas  = "FindOrgQuotas"
+	OpSetOrgQuota    = "SetOrgQuota"
+	OpDeleteOrgQuota = "DeleteOrgQuota"
+)
+
+// Names of the limits of an OrgQuota.
+const (
+	QuotaMaxBuckets          = "max-buckets"
+	QuotaMaxSeries           = "max-series"
+	QuotaMaxStorageBytes     = "max-storage-bytes"
+	QuotaMaxQueriesPerMinute = "max-queries-per-minute"
+
+	QuotaMaxQueryMemoryBytes         = "max-query-memory-bytes"
+	QuotaMaxQueryMemoryBytesPerQuery = "max-query-memory-bytes-per-query"
+)
+
+// OrgQuota limits the resources used by an organization. A limit of zero
+// leaves the resource unlimited.
+type OrgQuota struct {
+	OrgID ID `json:"orgID"`
+
+	// MaxBuckets is the maximum number of buckets of the organization.
+	MaxBuckets int `json:"maxBuckets,omitempty"`
+
+	// MaxSeries is the maximum number of series of all of the buckets of the
+	// organization. Points that would create a new series beyond it are
+	// dropped.
+	MaxSeries int `json:"maxSeries,omitempty"`
+
+	// MaxStorageBytes is the size of the data of the organization in TSM
+	// files beyond which writes to it are rejected.
+	MaxStorageBytes int64 `json:"maxStorageBytes,omitempty"`
+
+	// MaxQueriesPerMinute is the maximum number of reads of the data of the
+	// organization accepted by the storage engine each minute.
+	MaxQueriesPerMinute int `json:"maxQueriesPerMinute,omitempty"`
+
+	// MaxQueryMemoryBytes is the maximum amount of memory used by all of the
+	// running queries of the organization together. Queries that would
+	// exceed it fail.
+	MaxQueryMemoryBytes int64 `json:"maxQueryMemoryBytes,omitempty"`
+
+	// MaxQueryMemoryBytesPerQuery is the maximum amount of memory used by
+	// each query of the organization. Queries that would exceed it fail.
+	MaxQueryMemoryBytesPerQuery int64 `json:"maxQueryMemoryBytesPerQuery,omitempty"`
+}
+
+// Valid returns an error if a limit of the quota is negative.
+func (q *OrgQuota) Valid() error {
+	if !q.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "organization id must be provided",
+		}
+	}
+	if q.MaxBuckets < 0 || q.MaxSeries < 0 || q.MaxStorageBytes < 0 || q.MaxQueriesPerMinute < 0 ||
+		q.MaxQueryMemoryBytes < 0 || q.MaxQueryMemoryBytesPerQuery < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "quota limits must not be negative",
+		}
+	}
+	return nil
+}
+
+// ErrQuotaExceeded returns the error of an action of the organization of
+// orgID rejected by the named limit of its quota.
+func ErrQuotaExceeded(orgID ID, limit string, n, max int64) *Error {
+	code := EForbidden
+	if limit == QuotaMaxQueriesPerMinute {
+		code = ETooManyRequests
+	}
+	return &Error{
+		Code: code,
+		Msg:  fmt.Sprintf("organization %s exceeded its %s quota (%d/%d)", orgID, limit, n, max),
+	}
+}
+
+// OrgQuotaService manages the quotas of organizations.
+type OrgQuotaService interface {
+	// FindOrgQuota returns the quota of an organization. An organization
+	// without a quota has a quota without limits.
+	FindOrgQuota(ctx context.Context, orgID ID) (*OrgQuota, error)
+
+	// FindOrgQuotas returns the quotas of every organization with a quota.
+	FindOrgQuotas(ctx context.Context) ([]*OrgQuota, error)
+
+	// SetOrgQuota replaces the quota of an organization.
+	SetOrgQuota(ctx context.Context, q *OrgQuota) error
+
+	// DeleteOrgQuota removes the limits of an organization.
+	DeleteOrgQuota(ctx context.Context, orgID ID) error
+}
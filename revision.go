@@ -0,0 +1,62 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ops for revisions.
+var (
+	OpFindRevision = "FindRevision"
+)
+
+// RevisionService finds the revisions of resources. The revision of a resource
+// starts at 0 and is incremented each time the resource is updated, so that
+// clients can make their updates conditional on the resource not having been
+// changed since they read it.
+//
+// Dashboards, tasks, checks and notification rules have revisions.
+type RevisionService interface {
+	// FindRevision returns the revision of the resource with id.
+	FindRevision(ctx context.Context, id ID) (uint64, error)
+}
+
+type expectedRevisionsKey struct{}
+
+// expectedRevisions are the revisions a resource is expected to be at.
+type expectedRevisions struct {
+	id        ID
+	revisions []uint64
+}
+
+// NewContextWithExpectedRevisions returns a new context with the precondition
+// that the resource with id is at one of revisions when it is updated or
+// deleted with the context. Without revisions, the precondition never holds.
+func NewContextWithExpectedRevisions(ctx context.Context, id ID, revisions ...uint64) context.Context {
+	return context.WithValue(ctx, expectedRevisionsKey{}, &expectedRevisions{id: id, revisions: revisions})
+}
+
+// CheckExpectedRevision returns an EPreconditionFailed error if ctx expects the
+// resource with id to be at revisions other than rev.
+func CheckExpectedRevision(ctx context.Context, id ID, rev uint64) error {
+	exp, ok := ctx.Value(expectedRevisionsKey{}).(*expectedRevisions)
+	if !ok || exp.id != id {
+		return nil
+	}
+	for _, r := range exp.revisions {
+		if r == rev {
+			return nil
+		}
+	}
+	return &Error{
+		Code: EPreconditionFailed,
+		Msg:  fmt.Sprintf("resource %s has been modified; its revision is %d", id, rev),
+	}
+}
+
+// HasExpectedRevisions reports whether ctx expects the resource with id to be
+// at some revisions.
+func HasExpectedRevisions(ctx context.Context, id ID) bool {
+	exp, ok := ctx.Value(expectedRevisionsKey{}).(*expectedRevisions)
+	return ok && exp.id == id
+}
@@ -0,0 +1,60 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// ErrRunningQueryNotFound is the error message for a missing running query.
+const ErrRunningQueryNotFound = "running query not found"
+
+// ops for running queries.
+var (
+	OpFindRunningQueryByID = "FindRunningQueryByID"
+	OpFindRunningQueries   = "FindRunningQueries"
+	OpKillRunningQuery     = "KillRunningQuery"
+)
+
+// RunningQuery is a query being executed by the server.
+type RunningQuery struct {
+	ID    ID `json:"id"`
+	OrgID ID `json:"orgID"`
+
+	// Source is what sent the query, such as the user agent of the client
+	// or "live" for live queries.
+	Source string `json:"source,omitempty"`
+
+	// Query is the text of the query, if it is known.
+	Query string `json:"query,omitempty"`
+
+	// State is the state of the query, such as queueing or executing.
+	State string `json:"state"`
+
+	StartedAt time.Time `json:"startedAt"`
+	Runtime   Duration  `json:"runtime"`
+
+	// ValuesScanned and BytesScanned count the data the query has read from
+	// storage so far.
+	ValuesScanned int64 `json:"valuesScanned"`
+	BytesScanned  int64 `json:"bytesScanned"`
+}
+
+// RunningQueryFilter represents a set of filters that restrict the returned
+// running queries.
+type RunningQueryFilter struct {
+	OrgID *ID
+}
+
+// RunningQueryService lists and kills the queries being executed by the
+// server.
+type RunningQueryService interface {
+	// FindRunningQueryByID returns a single query being executed.
+	FindRunningQueryByID(ctx context.Context, id ID) (*RunningQuery, error)
+
+	// FindRunningQueries returns the queries being executed, longest
+	// running first.
+	FindRunningQueries(ctx context.Context, filter RunningQueryFilter) ([]*RunningQuery, error)
+
+	// KillRunningQuery cancels a query being executed, which then fails.
+	KillRunningQuery(ctx context.Context, id ID) error
+}
@@ -2,6 +2,9 @@ package influxdb
 
 import (
 	"context"
+	"fmt"
+	"regexp"
+	"time"
 )
 
 // ErrScraperTargetNotFound is the error msg for a missing scraper target.
@@ -24,6 +27,182 @@ type ScraperTarget struct {
 	URL      string      `json:"url"`
 	OrgID    ID          `json:"orgID,omitempty"`
 	BucketID ID          `json:"bucketID,omitempty"`
+
+	// Interval is the time between scrapes of the target. When unset or zero
+	// the scheduler's interval is used.
+	Interval *Duration `json:"interval,omitempty"`
+	// Timeout is the maximum duration of a single scrape. When unset or zero
+	// the scrape is not limited.
+	Timeout *Duration `json:"timeout,omitempty"`
+
+	Auth         *ScraperAuth         `json:"auth,omitempty"`
+	TLS          *ScraperTLSConfig    `json:"tls,omitempty"`
+	RelabelRules []ScraperRelabelRule `json:"relabelRules,omitempty"`
+}
+
+// Valid returns an error if the target's optional configuration is invalid.
+func (t *ScraperTarget) Valid() error {
+	if t.Interval.Value() < 0 || t.Timeout.Value() < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "scraper target interval and timeout must not be negative",
+		}
+	}
+	if t.Auth != nil {
+		if err := t.Auth.Valid(); err != nil {
+			return err
+		}
+	}
+	if t.TLS != nil {
+		if err := t.TLS.Valid(); err != nil {
+			return err
+		}
+	}
+	for i := range t.RelabelRules {
+		if err := t.RelabelRules[i].Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScraperAuthType is the method used to authenticate with a scraper target.
+type ScraperAuthType string
+
+// Scraper auth types
+const (
+	ScraperAuthNone   ScraperAuthType = ""
+	ScraperAuthBasic  ScraperAuthType = "basic"
+	ScraperAuthBearer ScraperAuthType = "bearer"
+)
+
+// ScraperAuth holds the credentials used to authenticate with a scraper target.
+// Passwords and tokens are not stored on the target; they are read from the
+// organization's secrets using the given keys.
+type ScraperAuth struct {
+	Type ScraperAuthType `json:"type"`
+	// Username is the basic auth username.
+	Username string `json:"username,omitempty"`
+	// PasswordSecret is the key of the secret holding the basic auth password.
+	PasswordSecret string `json:"passwordSecret,omitempty"`
+	// TokenSecret is the key of the secret holding the bearer token.
+	TokenSecret string `json:"tokenSecret,omitempty"`
+}
+
+// Valid returns an error if the auth configuration is incomplete.
+func (a *ScraperAuth) Valid() error {
+	switch a.Type {
+	case ScraperAuthNone:
+		return nil
+	case ScraperAuthBasic:
+		if a.Username == "" {
+			return &Error{Code: EInvalid, Msg: "basic auth requires a username"}
+		}
+	case ScraperAuthBearer:
+		if a.TokenSecret == "" {
+			return &Error{Code: EInvalid, Msg: "bearer auth requires a token secret"}
+		}
+	default:
+		return &Error{Code: EInvalid, Msg: fmt.Sprintf("unsupported scraper auth type %q", a.Type)}
+	}
+	return nil
+}
+
+// ScraperTLSConfig configures TLS connections to a scraper target. Setting a
+// client certificate enables mutual TLS.
+type ScraperTLSConfig struct {
+	// CACert is a PEM encoded bundle of certificate authorities used to verify
+	// the target. When empty the system roots are used.
+	CACert string `json:"caCert,omitempty"`
+	// ClientCert is the PEM encoded client certificate.
+	ClientCert string `json:"clientCert,omitempty"`
+	// ClientKeySecret is the key of the secret holding the PEM encoded client key.
+	ClientKeySecret    string `json:"clientKeySecret,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// Valid returns an error if the TLS configuration is incomplete.
+func (c *ScraperTLSConfig) Valid() error {
+	if (c.ClientCert == "") != (c.ClientKeySecret == "") {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "client certificate and client key secret must be set together",
+		}
+	}
+	return nil
+}
+
+// ScraperRelabelAction is the action performed by a relabel rule.
+type ScraperRelabelAction string
+
+// Relabel actions, which match those of Prometheus.
+const (
+	// RelabelReplace sets TargetLabel to Replacement when Regex matches.
+	RelabelReplace ScraperRelabelAction = "replace"
+	// RelabelKeep drops metrics whose source labels do not match Regex.
+	RelabelKeep ScraperRelabelAction = "keep"
+	// RelabelDrop drops metrics whose source labels match Regex.
+	RelabelDrop ScraperRelabelAction = "drop"
+	// RelabelLabelDrop removes all labels whose name matches Regex.
+	RelabelLabelDrop ScraperRelabelAction = "labeldrop"
+	// RelabelLabelKeep removes all labels whose name does not match Regex.
+	RelabelLabelKeep ScraperRelabelAction = "labelkeep"
+)
+
+// ScraperMetricNameLabel is the pseudo label holding the metric name during
+// relabeling. Rules may use it to rename, keep or drop metrics by name.
+const ScraperMetricNameLabel = "__name__"
+
+// ScraperRelabelRule rewrites the labels of scraped metrics before they are
+// written. Empty fields take the Prometheus defaults.
+type ScraperRelabelRule struct {
+	SourceLabels []string             `json:"sourceLabels,omitempty"`
+	Separator    string               `json:"separator,omitempty"`   // default ";"
+	Regex        string               `json:"regex,omitempty"`       // default "(.*)"
+	TargetLabel  string               `json:"targetLabel,omitempty"` // required for replace
+	Replacement  string               `json:"replacement,omitempty"` // default "$1"
+	Action       ScraperRelabelAction `json:"action,omitempty"`      // default replace
+}
+
+// Valid returns an error if the rule cannot be applied.
+func (r *ScraperRelabelRule) Valid() error {
+	if _, err := regexp.Compile(r.Regex); err != nil {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("invalid relabel regex %q", r.Regex),
+			Err:  err,
+		}
+	}
+	switch r.Action {
+	case "", RelabelReplace:
+		if r.TargetLabel == "" {
+			return &Error{Code: EInvalid, Msg: "replace relabel rule requires a target label"}
+		}
+	case RelabelKeep, RelabelDrop, RelabelLabelDrop, RelabelLabelKeep:
+	default:
+		return &Error{Code: EInvalid, Msg: fmt.Sprintf("unsupported relabel action %q", r.Action)}
+	}
+	return nil
+}
+
+// ScraperTargetStatus reports the outcome of the most recent scrapes of a target.
+type ScraperTargetStatus struct {
+	LastScrape          time.Time `json:"lastScrape"`
+	LastSuccess         time.Time `json:"lastSuccess"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastDuration        Duration  `json:"lastDuration"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+}
+
+// ScraperTargetStatusService stores the status of scraper targets.
+type ScraperTargetStatusService interface {
+	// FindTargetStatus returns the status of the target. A target that has not
+	// been scraped returns a not found error.
+	FindTargetStatus(ctx context.Context, id ID) (*ScraperTargetStatus, error)
+	// RecordTargetScrape updates the status of the target with the outcome of
+	// a scrape that began at start and took dur. A nil err marks a success.
+	RecordTargetScrape(ctx context.Context, id ID, start time.Time, dur time.Duration, err error) (*ScraperTargetStatus, error)
 }
 
 // ScraperTargetStoreService defines the crud service for ScraperTarget.
@@ -0,0 +1,152 @@
+// Package secret provides secret services layered over the stores of secrets,
+// such as Vault or AWS Secrets Manager.
+package secret
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.SecretService = (*CachingService)(nil)
+
+// CachingService wraps a SecretService, reading secrets through a cache that
+// holds them for up to a TTL. Secrets written through the service invalidate
+// the cached secrets of their organization; secrets written to the store
+// otherwise are read once the cached secrets expire.
+type CachingService struct {
+	s   influxdb.SecretService
+	ttl time.Duration
+	now func() time.Time
+
+	mu   sync.Mutex
+	orgs map[influxdb.ID]*orgSecrets
+	// gens counts the invalidations of the secrets of each organization, so
+	// that secrets read before an invalidation are not cached after it.
+	gens map[influxdb.ID]uint64
+}
+
+type orgSecrets struct {
+	values map[string]cachedSecret
+
+	keys          []string
+	keysExpiresAt time.Time
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewCachingService returns a CachingService caching the secrets of s for ttl.
+func NewCachingService(s influxdb.SecretService, ttl time.Duration) *CachingService {
+	return &CachingService{
+		s:    s,
+		ttl:  ttl,
+		now:  time.Now,
+		orgs: make(map[influxdb.ID]*orgSecrets),
+		gens: make(map[influxdb.ID]uint64),
+	}
+}
+
+// LoadSecret retrieves the secret value v found at key k for organization
+// orgID, from the cache if it holds it.
+func (s *CachingService) LoadSecret(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+	s.mu.Lock()
+	now := s.now()
+	if o, ok := s.orgs[orgID]; ok {
+		if c, ok := o.values[k]; ok && now.Before(c.expiresAt) {
+			s.mu.Unlock()
+			return c.value, nil
+		}
+	}
+	gen := s.gens[orgID]
+	s.mu.Unlock()
+
+	v, err := s.s.LoadSecret(ctx, orgID, k)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o := s.org(orgID, gen); o != nil {
+		o.values[k] = cachedSecret{value: v, expiresAt: now.Add(s.ttl)}
+	}
+	return v, nil
+}
+
+// GetSecretKeys retrieves all secret keys that are stored for the
+// organization orgID, from the cache if it holds them.
+func (s *CachingService) GetSecretKeys(ctx context.Context, orgID influxdb.ID) ([]string, error) {
+	s.mu.Lock()
+	now := s.now()
+	if o, ok := s.orgs[orgID]; ok && o.keys != nil && now.Before(o.keysExpiresAt) {
+		keys := append([]string(nil), o.keys...)
+		s.mu.Unlock()
+		return keys, nil
+	}
+	gen := s.gens[orgID]
+	s.mu.Unlock()
+
+	keys, err := s.s.GetSecretKeys(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if o := s.org(orgID, gen); o != nil {
+		o.keys = append(make([]string, 0, len(keys)), keys...)
+		o.keysExpiresAt = now.Add(s.ttl)
+	}
+	return keys, nil
+}
+
+// org returns the cached secrets of an organization, or nil if they were
+// invalidated since gen. s.mu must be held.
+func (s *CachingService) org(orgID influxdb.ID, gen uint64) *orgSecrets {
+	if s.gens[orgID] != gen {
+		return nil
+	}
+	o, ok := s.orgs[orgID]
+	if !ok {
+		o = &orgSecrets{values: make(map[string]cachedSecret)}
+		s.orgs[orgID] = o
+	}
+	return o
+}
+
+// invalidate removes the cached secrets of an organization.
+func (s *CachingService) invalidate(orgID influxdb.ID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.orgs, orgID)
+	s.gens[orgID]++
+}
+
+// PutSecret stores the secret pair (k,v) for the organization orgID.
+func (s *CachingService) PutSecret(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+	defer s.invalidate(orgID)
+	return s.s.PutSecret(ctx, orgID, k, v)
+}
+
+// PutSecrets puts all provided secrets and overwrites any previous values.
+func (s *CachingService) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	defer s.invalidate(orgID)
+	return s.s.PutSecrets(ctx, orgID, m)
+}
+
+// PatchSecrets patches all provided secrets and updates any previous values.
+func (s *CachingService) PatchSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
+	defer s.invalidate(orgID)
+	return s.s.PatchSecrets(ctx, orgID, m)
+}
+
+// DeleteSecret removes a single secret from the secret store.
+func (s *CachingService) DeleteSecret(ctx context.Context, orgID influxdb.ID, ks ...string) error {
+	defer s.invalidate(orgID)
+	return s.s.DeleteSecret(ctx, orgID, ks...)
+}
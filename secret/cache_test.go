@@ -0,0 +1,82 @@
+package secret_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/secret"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func initCachingService(f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing secret service: %v", err)
+	}
+
+	s := secret.NewCachingService(svc, time.Minute)
+	for _, sec := range f.Secrets {
+		for k, v := range sec.Env {
+			if err := s.PutSecret(ctx, sec.OrganizationID, k, v); err != nil {
+				t.Fatalf("failed to populate secrets: %v", err)
+			}
+		}
+	}
+	return s, func() {}
+}
+
+func TestCachingService(t *testing.T) {
+	influxdbtesting.SecretService(initCachingService, t)
+}
+
+func TestCachingService_LoadSecret(t *testing.T) {
+	var loads int
+	values := map[string]string{"api_key": "abc123"}
+	inner := mock.NewSecretService()
+	inner.LoadSecretFn = func(ctx context.Context, orgID influxdb.ID, k string) (string, error) {
+		loads++
+		return values[k], nil
+	}
+	inner.PutSecretFn = func(ctx context.Context, orgID influxdb.ID, k string, v string) error {
+		values[k] = v
+		return nil
+	}
+
+	s := secret.NewCachingService(inner, 10*time.Millisecond)
+	ctx := context.Background()
+	load := func(want string, wantLoads int) {
+		t.Helper()
+		v, err := s.LoadSecret(ctx, influxdb.ID(1), "api_key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != want {
+			t.Errorf("got secret %q, want %q", v, want)
+		}
+		if loads != wantLoads {
+			t.Errorf("got %d loads from the store, want %d", loads, wantLoads)
+		}
+	}
+
+	load("abc123", 1)
+	load("abc123", 1)
+
+	// Writes invalidate the cached secrets of their organization.
+	if err := s.PutSecret(ctx, influxdb.ID(1), "api_key", "xyz789"); err != nil {
+		t.Fatal(err)
+	}
+	load("xyz789", 2)
+
+	// Secrets written to the store otherwise are read once they expire.
+	values["api_key"] = "def456"
+	load("xyz789", 2)
+	time.Sleep(20 * time.Millisecond)
+	load("def456", 3)
+}
@@ -0,0 +1,56 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// SeriesMoveStatus is the state of a series move.
+type SeriesMoveStatus string
+
+// Series move statuses.
+const (
+	SeriesMoveRunning   SeriesMoveStatus = "running"
+	SeriesMoveCompleted SeriesMoveStatus = "completed"
+	SeriesMoveFailed    SeriesMoveStatus = "failed"
+)
+
+// SeriesMove moves the series of a bucket matching a predicate into another
+// bucket of the same organization. The data is copied into the destination
+// bucket, then deleted from the source bucket.
+type SeriesMove struct {
+	ID                  ID     `json:"id,omitempty"`
+	OrgID               ID     `json:"orgID"`
+	SourceBucketID      ID     `json:"sourceBucketID"`
+	DestinationBucketID ID     `json:"destinationBucketID"`
+	Predicate           string `json:"predicate,omitempty"`
+
+	Status SeriesMoveStatus `json:"status"`
+	Error  string           `json:"error,omitempty"`
+
+	// Series is the number of series copied into the destination bucket.
+	Series int `json:"series"`
+
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// SeriesMoveFilter restricts the series moves returned by a lookup.
+type SeriesMoveFilter struct {
+	OrgID *ID
+}
+
+// SeriesMoveService moves series between buckets in the background.
+type SeriesMoveService interface {
+	// MoveSeries starts moving the series of m.SourceBucketID that match pred
+	// into m.DestinationBucketID. All series are moved if pred is nil. It sets
+	// the ID, status and start time of m, and returns without waiting for the
+	// move to finish.
+	MoveSeries(ctx context.Context, m *SeriesMove, pred Predicate) error
+
+	// FindSeriesMoveByID returns a single series move by ID.
+	FindSeriesMoveByID(ctx context.Context, id ID) (*SeriesMove, error)
+
+	// FindSeriesMoves returns the series moves matching filter.
+	FindSeriesMoves(ctx context.Context, filter SeriesMoveFilter) ([]*SeriesMove, error)
+}
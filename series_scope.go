@@ -0,0 +1,234 @@
+package influxdb
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// SeriesScope is the part of the data of a bucket that permissions restricted
+// to some of its series allow access to: the series matched by any of its
+// resources. A nil scope is every series of the bucket.
+//
+// Restricted permissions allow the series in their scope to be read and
+// written. Operations on a bucket as a whole, such as updating it or deleting
+// its data, require a permission that is not restricted.
+type SeriesScope []Resource
+
+// SeriesScoper is implemented by authorizers whose permissions on buckets may
+// be restricted to some of their series.
+type SeriesScoper interface {
+	// SeriesScope returns the scope of the permissions of the authorizer
+	// that match p.
+	SeriesScope(p Permission) SeriesScope
+}
+
+// AuthorizerSeriesScope returns the series of the bucket of p that a is
+// allowed p on, if a is allowed p.
+func AuthorizerSeriesScope(a Authorizer, p Permission) SeriesScope {
+	if s, ok := a.(SeriesScoper); ok {
+		return s.SeriesScope(p)
+	}
+	return nil
+}
+
+// PermissionSeriesScope returns the scope of the permissions of ps that match
+// p. It is nil if any of them is not restricted to some series.
+func PermissionSeriesScope(p Permission, ps []Permission) SeriesScope {
+	var scope SeriesScope
+	for _, perm := range ps {
+		if !perm.Matches(p) {
+			continue
+		}
+		if !perm.Resource.Scoped() {
+			return nil
+		}
+		scope = append(scope, perm.Resource)
+	}
+	return scope
+}
+
+// Scoped reports whether the resource is restricted to some series of
+// buckets.
+func (r Resource) Scoped() bool {
+	return len(r.Measurements) > 0 || len(r.Tags) > 0
+}
+
+// validScope returns an error if the series the resource is restricted to
+// are not valid.
+func (r Resource) validScope() error {
+	if !r.Scoped() {
+		return nil
+	}
+	if r.Type != BucketsResourceType {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "only permissions on buckets can be restricted to measurements and tags",
+		}
+	}
+	for _, m := range r.Measurements {
+		if m == "" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "measurement of permission cannot be empty",
+			}
+		}
+	}
+	for _, t := range r.Tags {
+		if err := t.Valid(); err != nil {
+			return err
+		}
+		if t.Key == "_measurement" || t.Key == "_field" {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("tag rules of permission cannot match %s", t.Key),
+			}
+		}
+		if t.Operator == RegexEqual || t.Operator == NotRegexEqual {
+			if _, err := regexp.Compile(t.Value); err != nil {
+				return &Error{
+					Code: EInvalid,
+					Msg:  fmt.Sprintf("invalid regular expression of tag %s", t.Key),
+					Err:  err,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Contains reports whether every series r may be restricted to is in the
+// scope.
+func (s SeriesScope) Contains(r Resource) bool {
+	if s == nil {
+		return true
+	}
+	if !r.Scoped() {
+		return false
+	}
+	for _, sr := range s {
+		if sr.containsScope(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsScope reports whether r is restricted to the series o is
+// restricted to, or more: o is of measurements of r, and has all of the tag
+// rules of r.
+func (r Resource) containsScope(o Resource) bool {
+	if len(r.Measurements) > 0 {
+		if len(o.Measurements) == 0 {
+			return false
+		}
+		for _, m := range o.Measurements {
+			if !containsString(r.Measurements, m) {
+				return false
+			}
+		}
+	}
+	for _, t := range r.Tags {
+		found := false
+		for _, ot := range o.Tags {
+			if ot == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SeriesMatcher matches the series of a scope.
+type SeriesMatcher struct {
+	resources []resourceMatcher
+}
+
+type resourceMatcher struct {
+	measurements map[string]bool
+	tags         []tagMatcher
+}
+
+type tagMatcher struct {
+	TagRule
+	re *regexp.Regexp
+}
+
+// Matcher returns a matcher of the series of the scope.
+func (s SeriesScope) Matcher() (*SeriesMatcher, error) {
+	m := &SeriesMatcher{resources: make([]resourceMatcher, 0, len(s))}
+	for _, r := range s {
+		rm := resourceMatcher{tags: make([]tagMatcher, 0, len(r.Tags))}
+		if len(r.Measurements) > 0 {
+			rm.measurements = make(map[string]bool, len(r.Measurements))
+			for _, name := range r.Measurements {
+				rm.measurements[name] = true
+			}
+		}
+		for _, t := range r.Tags {
+			tm := tagMatcher{TagRule: t}
+			if t.Operator == RegexEqual || t.Operator == NotRegexEqual {
+				re, err := regexp.Compile(t.Value)
+				if err != nil {
+					return nil, &Error{
+						Code: EInvalid,
+						Msg:  fmt.Sprintf("invalid regular expression of tag %s", t.Key),
+						Err:  err,
+					}
+				}
+				tm.re = re
+			}
+			rm.tags = append(rm.tags, tm)
+		}
+		m.resources = append(m.resources, rm)
+	}
+	return m, nil
+}
+
+// Match reports whether the series of measurement whose tags have the values
+// returned by tag is in the scope. A series without a tag has an empty value
+// for it.
+func (m *SeriesMatcher) Match(measurement string, tag func(key string) string) bool {
+	for _, r := range m.resources {
+		if r.match(measurement, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r resourceMatcher) match(measurement string, tag func(key string) string) bool {
+	if r.measurements != nil && !r.measurements[measurement] {
+		return false
+	}
+	for _, t := range r.tags {
+		v := tag(t.Key)
+		var ok bool
+		switch t.Operator {
+		case Equal:
+			ok = v == t.Value
+		case NotEqual:
+			ok = v != t.Value
+		case RegexEqual:
+			ok = t.re.MatchString(v)
+		case NotRegexEqual:
+			ok = !t.re.MatchString(v)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package influxdb_test
+
+import (
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+)
+
+func TestPermissionSeriesScope(t *testing.T) {
+	team := platform.TagRule{Tag: platform.Tag{Key: "team", Value: "a"}, Operator: platform.Equal}
+	scoped := platform.Permission{
+		Action: platform.ReadAction,
+		Resource: platform.Resource{
+			Type:         platform.BucketsResourceType,
+			OrgID:        influxdbtesting.IDPtr(1),
+			ID:           influxdbtesting.IDPtr(2),
+			Measurements: []string{"cpu", "mem"},
+			Tags:         []platform.TagRule{team},
+		},
+	}
+	bucket := platform.Permission{
+		Action: platform.ReadAction,
+		Resource: platform.Resource{
+			Type:  platform.BucketsResourceType,
+			OrgID: influxdbtesting.IDPtr(1),
+			ID:    influxdbtesting.IDPtr(2),
+		},
+	}
+
+	if scope := platform.PermissionSeriesScope(bucket, []platform.Permission{scoped}); len(scope) != 1 {
+		t.Fatalf("got scope %v, expected the scope of the restricted permission", scope)
+	}
+	// Any permission that is not restricted allows every series.
+	orgWide, _ := platform.NewPermission(platform.ReadAction, platform.BucketsResourceType, 1)
+	if scope := platform.PermissionSeriesScope(bucket, []platform.Permission{scoped, *orgWide}); scope != nil {
+		t.Fatalf("got scope %v, expected every series", scope)
+	}
+
+	scope := platform.SeriesScope{scoped.Resource}
+	contains := []struct {
+		r    platform.Resource
+		want bool
+	}{
+		{r: platform.Resource{Measurements: []string{"cpu"}, Tags: []platform.TagRule{team}}, want: true},
+		{r: platform.Resource{Measurements: []string{"cpu"}, Tags: []platform.TagRule{team, {Tag: platform.Tag{Key: "host", Value: "h"}}}}, want: true},
+		{r: platform.Resource{Measurements: []string{"cpu", "disk"}, Tags: []platform.TagRule{team}}, want: false},
+		{r: platform.Resource{Measurements: []string{"cpu"}}, want: false},
+		{r: platform.Resource{Tags: []platform.TagRule{team}}, want: false},
+		{r: platform.Resource{}, want: false},
+	}
+	for _, c := range contains {
+		if got := scope.Contains(c.r); got != c.want {
+			t.Errorf("Contains(%+v) = %v, want %v", c.r, got, c.want)
+		}
+	}
+}
+
+func TestSeriesMatcher_Match(t *testing.T) {
+	scope := platform.SeriesScope{
+		{
+			Measurements: []string{"cpu"},
+			Tags: []platform.TagRule{
+				{Tag: platform.Tag{Key: "team", Value: "^(a|b)$"}, Operator: platform.RegexEqual},
+				{Tag: platform.Tag{Key: "env", Value: "prod"}, Operator: platform.NotEqual},
+			},
+		},
+		{
+			Tags: []platform.TagRule{
+				{Tag: platform.Tag{Key: "team", Value: "c"}, Operator: platform.Equal},
+			},
+		},
+	}
+	m, err := scope.Matcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		measurement string
+		tags        map[string]string
+		want        bool
+	}{
+		{measurement: "cpu", tags: map[string]string{"team": "a"}, want: true},
+		{measurement: "cpu", tags: map[string]string{"team": "b", "env": "dev"}, want: true},
+		{measurement: "cpu", tags: map[string]string{"team": "b", "env": "prod"}, want: false},
+		{measurement: "mem", tags: map[string]string{"team": "a"}, want: false},
+		{measurement: "cpu", tags: map[string]string{}, want: false},
+		{measurement: "mem", tags: map[string]string{"team": "c"}, want: true},
+	}
+	for _, tt := range tests {
+		got := m.Match(tt.measurement, func(key string) string { return tt.tags[key] })
+		if got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.measurement, tt.tags, got, tt.want)
+		}
+	}
+}
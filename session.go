@@ -26,6 +26,12 @@ var (
 	OpCreateSession = "CreateSession"
 	// OpRenewSession = "RenewSession"
 	OpRenewSession = "RenewSession"
+	// OpFindSessions represents the operation that lists the active sessions.
+	OpFindSessions = "FindSessions"
+	// OpRevokeSession represents the operation that revokes a session.
+	OpRevokeSession = "RevokeSession"
+	// OpRevokeSessions represents the operation that revokes the sessions of a user.
+	OpRevokeSessions = "RevokeSessions"
 )
 
 // SessionAuthorizionKind defines the type of authorizer
@@ -40,6 +46,11 @@ type Session struct {
 	ExpiresAt   time.Time    `json:"expiresAt"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions,omitempty"`
+
+	// UserAgent and SourceIP identify the client the session was signed in
+	// with.
+	UserAgent string `json:"userAgent,omitempty"`
+	SourceIP  string `json:"sourceIP,omitempty"`
 }
 
 // Expired returns an error if the session is expired.
@@ -64,6 +75,11 @@ func (s *Session) Allowed(p Permission) bool {
 	return PermissionAllowed(p, s.Permissions)
 }
 
+// SeriesScope returns the series of the bucket of p the session allows p on.
+func (s *Session) SeriesScope(p Permission) SeriesScope {
+	return PermissionSeriesScope(p, s.Permissions)
+}
+
 // Kind returns session and is used for auditing.
 func (s *Session) Kind() string { return SessionAuthorizionKind }
 
@@ -94,3 +110,24 @@ type SessionService interface {
 	CreateSession(ctx context.Context, user string) (*Session, error)
 	RenewSession(ctx context.Context, session *Session, newExpiration time.Time) error
 }
+
+// SessionFilter represents a set of filters that restrict the returned
+// sessions.
+type SessionFilter struct {
+	ID     *ID
+	UserID *ID
+}
+
+// SessionManagementService lists and revokes the active sessions of users.
+type SessionManagementService interface {
+	// FindSessions returns the unexpired sessions that match filter, oldest
+	// first, and the total count of matching sessions. The sessions have no
+	// key nor permissions.
+	FindSessions(ctx context.Context, filter SessionFilter, opt ...FindOptions) ([]*Session, int, error)
+
+	// RevokeSession revokes a session, signing out the client using it.
+	RevokeSession(ctx context.Context, id ID) error
+
+	// RevokeSessions revokes all of the sessions of a user.
+	RevokeSessions(ctx context.Context, userID ID) error
+}
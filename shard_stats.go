@@ -0,0 +1,61 @@
+package influxdb
+
+import "context"
+
+// ShardStats summarises the recent load on the data of a single bucket, which
+// forms one shard of the storage engine.
+type ShardStats struct {
+	OrgID    ID `json:"orgID"`
+	BucketID ID `json:"bucketID"`
+
+	// Write throughput over the most recently completed tracking interval.
+	WritePointsPerSecond float64 `json:"writePointsPerSecond"`
+	WriteBytesPerSecond  float64 `json:"writeBytesPerSecond"`
+
+	// CacheBytes is the size of the bucket's data held in the cache waiting
+	// to be written to TSM files.
+	CacheBytes uint64 `json:"cacheBytes"`
+
+	// TSMFiles is the number of TSM files holding the bucket's data.
+	// CompactionBacklog is the number of those that are still waiting to be
+	// compacted to the highest level.
+	TSMFiles          int `json:"tsmFiles"`
+	CompactionBacklog int `json:"compactionBacklog"`
+
+	// TombstonedBytes is the size of the bucket's data that has been deleted
+	// but is still held in TSM files, until they are compacted.
+	TombstonedBytes uint64 `json:"tombstonedBytes"`
+
+	// Cursors is the number of cursors created to read the bucket during the
+	// most recently completed tracking interval, and CursorLatency is the
+	// mean time in seconds taken to create them.
+	Cursors       uint64  `json:"cursors"`
+	CursorLatency float64 `json:"cursorLatencySeconds"`
+}
+
+// Shard stats sort keys.
+const (
+	ShardStatsSortWrites      = "writes"
+	ShardStatsSortCache       = "cache"
+	ShardStatsSortCompactions = "compactions"
+	ShardStatsSortCursors     = "cursors"
+	ShardStatsSortTombstones  = "tombstones"
+)
+
+// ShardStatsFilter restricts and orders the results of a shard stats lookup.
+type ShardStatsFilter struct {
+	OrgID    *ID
+	BucketID *ID
+
+	// SortBy is one of the ShardStatsSort keys. Results are ranked in
+	// descending order, so the hottest shards are returned first.
+	SortBy string
+
+	// Limit is the maximum number of results returned. Zero means no limit.
+	Limit int
+}
+
+// ShardStatsService provides ranked per-shard load statistics.
+type ShardStatsService interface {
+	FindShardStats(ctx context.Context, filter ShardStatsFilter) ([]*ShardStats, error)
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/escape"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// exportFlushSize is the size the line protocol of an export is buffered up
+// to before it is written.
+const exportFlushSize = 64 * 1024
+
+// exportSeries is a series of a bucket being exported.
+type exportSeries struct {
+	key  []byte
+	tags models.Tags
+}
+
+// ExportBucket writes the points of a bucket with times in [start, stop) to w
+// as line protocol. The series are exported in the order of their keys, and a
+// comment holding a cursor follows the points of each of them. An export
+// given a cursor resumes after the series it was written after.
+func (e *Engine) ExportBucket(ctx context.Context, orgID, bucketID influxdb.ID, start, stop int64, cursor string, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	after, err := decodeExportCursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	name := tsdb.EncodeName(orgID, bucketID)
+	series, err := e.exportSeries(ctx, name, after)
+	if err != nil {
+		return err
+	}
+
+	itr, err := e.CreateCursorIterator(ctx)
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+
+	req := cursors.CursorRequest{
+		Name:      name[:],
+		Ascending: true,
+		StartTime: start,
+		EndTime:   stop,
+	}
+	var buf []byte
+	for _, s := range series {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		req.Tags = s.tags
+		req.Field = string(s.tags.Get(models.FieldKeyTagKeyBytes))
+		cur, err := itr.Next(ctx, &req)
+		if err != nil {
+			return err
+		} else if cur == nil {
+			continue
+		}
+
+		n := len(buf)
+		if buf, err = appendExportLines(buf, s.tags, cur); err != nil {
+			return err
+		}
+		if len(buf) == n {
+			continue
+		}
+		buf = append(buf, influxdb.BucketExportCursorPrefix...)
+		buf = append(buf, base64.RawURLEncoding.EncodeToString(s.key)...)
+		buf = append(buf, '\n')
+
+		if len(buf) >= exportFlushSize {
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+			buf = buf[:0]
+		}
+	}
+
+	if len(buf) > 0 {
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportSeries returns the series of a bucket whose keys sort after the key
+// after, sorted by key.
+func (e *Engine) exportSeries(ctx context.Context, name [influxdb.IDLength]byte, after []byte) ([]exportSeries, error) {
+	sc, err := e.CreateSeriesCursor(ctx, SeriesCursorRequest{Name: name}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer sc.Close()
+
+	var series []exportSeries
+	for {
+		row, err := sc.Next()
+		if err != nil {
+			return nil, err
+		} else if row == nil {
+			break
+		}
+
+		key := row.Tags.HashKey()
+		if after != nil && bytes.Compare(key, after) <= 0 {
+			continue
+		}
+		series = append(series, exportSeries{key: key, tags: row.Tags.Clone()})
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		return bytes.Compare(series[i].key, series[j].key) < 0
+	})
+	return series, nil
+}
+
+// decodeExportCursor returns the series key of a cursor of an export, or nil
+// if no cursor is given.
+func decodeExportCursor(cursor string) ([]byte, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(key) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid export cursor",
+			Err:  err,
+		}
+	}
+	return key, nil
+}
+
+// appendExportLines appends a line of line protocol for each value of cur to
+// buf and closes cur. The measurement and field of the values are read from
+// the tags of their series.
+func appendExportLines(buf []byte, tags models.Tags, cur cursors.Cursor) ([]byte, error) {
+	defer cur.Close()
+
+	// The measurement and field are the first and last tags of a series.
+	if len(tags) < 2 {
+		return buf, nil
+	}
+	var prefix []byte
+	prefix = append(prefix, models.EscapeMeasurement(tags[0].Value)...)
+	prefix = tags[1 : len(tags)-1].AppendHashKey(prefix)
+	prefix = append(prefix, ' ')
+	prefix = append(prefix, escape.Bytes(tags[len(tags)-1].Value)...)
+	prefix = append(prefix, '=')
+
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, v := range a.Values {
+				buf = append(buf, prefix...)
+				buf = strconv.AppendFloat(buf, v, 'f', -1, 64)
+				buf = appendExportTime(buf, a.Timestamps[i])
+			}
+		}
+	case cursors.IntegerArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, v := range a.Values {
+				buf = append(buf, prefix...)
+				buf = append(strconv.AppendInt(buf, v, 10), 'i')
+				buf = appendExportTime(buf, a.Timestamps[i])
+			}
+		}
+	case cursors.UnsignedArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, v := range a.Values {
+				buf = append(buf, prefix...)
+				buf = append(strconv.AppendUint(buf, v, 10), 'u')
+				buf = appendExportTime(buf, a.Timestamps[i])
+			}
+		}
+	case cursors.BooleanArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, v := range a.Values {
+				buf = append(buf, prefix...)
+				buf = strconv.AppendBool(buf, v)
+				buf = appendExportTime(buf, a.Timestamps[i])
+			}
+		}
+	case cursors.StringArrayCursor:
+		for a := cur.Next(); a.Len() > 0; a = cur.Next() {
+			for i, v := range a.Values {
+				buf = append(buf, prefix...)
+				buf = append(buf, '"')
+				buf = append(buf, models.EscapeStringField(v)...)
+				buf = append(buf, '"')
+				buf = appendExportTime(buf, a.Timestamps[i])
+			}
+		}
+	}
+	return buf, cur.Err()
+}
+
+// appendExportTime appends the timestamp ending a line of line protocol to buf.
+func appendExportTime(buf []byte, ts int64) []byte {
+	buf = append(buf, ' ')
+	buf = strconv.AppendInt(buf, ts, 10)
+	return append(buf, '\n')
+}
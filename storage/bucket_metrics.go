@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const bucketSubsystem = "bucket" // sub-system associated with metrics for the usage of each bucket.
+
+// BucketMetricLabelName returns the name of the label of the bucket metrics
+// holding the value of the metadata key of each bucket. Characters that may
+// not be used in label names are replaced with underscores.
+func BucketMetricLabelName(key string) string {
+	name := []byte("bucket_" + key)
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// bucketMetrics reports the storage used by, and the writes to, each bucket
+// with data in an engine. The metrics are labelled with the values of the
+// configured metadata keys of each bucket, so that the usage of buckets can be
+// charged back to the teams they are labelled with.
+//
+// Unlike the other metrics of the engine, the values are read from the engine
+// when they are collected.
+type bucketMetrics struct {
+	engine *Engine
+	keys   []string
+
+	DiskBytes         *prometheus.Desc
+	Series            *prometheus.Desc
+	WritePointsPerSec *prometheus.Desc
+	WriteBytesPerSec  *prometheus.Desc
+}
+
+func newBucketMetrics(e *Engine, keys []string, labels prometheus.Labels) *bucketMetrics {
+	m := &bucketMetrics{engine: e}
+	names := []string{"bucket"}
+	seen := make(map[string]bool)
+	for _, k := range keys {
+		// Keys sharing a label name are reported by the first of them.
+		name := BucketMetricLabelName(k)
+		if k == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		m.keys = append(m.keys, k)
+		names = append(names, name)
+	}
+
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, bucketSubsystem, name), help, names, labels)
+	}
+	m.DiskBytes = desc("disk_bytes", "Size of the bucket's data in TSM files.")
+	m.Series = desc("series", "Number of series of the bucket in the index.")
+	m.WritePointsPerSec = desc("write_points_per_second", "Rate of points written to the bucket over the last shard stats interval.")
+	m.WriteBytesPerSec = desc("write_bytes_per_second", "Rate of bytes of line protocol written to the bucket over the last shard stats interval.")
+	return m
+}
+
+// Describe satisfies the prometheus.Collector interface.
+func (m *bucketMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.DiskBytes
+	ch <- m.Series
+	ch <- m.WritePointsPerSec
+	ch <- m.WriteBytesPerSec
+}
+
+// Collect satisfies the prometheus.Collector interface.
+func (m *bucketMetrics) Collect(ch chan<- prometheus.Metric) {
+	usage, err := m.engine.bucketUsage()
+	if err != nil {
+		m.engine.logger.Debug("Unable to collect bucket metrics", zap.Error(err))
+		return
+	}
+
+	for bucketID, u := range usage {
+		values := m.engine.bucketMetricLabelValues(bucketID, m.keys)
+		ch <- prometheus.MustNewConstMetric(m.DiskBytes, prometheus.GaugeValue, float64(u.diskBytes), values...)
+		ch <- prometheus.MustNewConstMetric(m.Series, prometheus.GaugeValue, float64(u.series), values...)
+		if u.writes != nil {
+			ch <- prometheus.MustNewConstMetric(m.WritePointsPerSec, prometheus.GaugeValue, u.writes.WritePointsPerSecond, values...)
+			ch <- prometheus.MustNewConstMetric(m.WriteBytesPerSec, prometheus.GaugeValue, u.writes.WriteBytesPerSecond, values...)
+		}
+	}
+}
+
+// bucketUsage is the storage used by, and the writes to, a bucket.
+type bucketUsage struct {
+	diskBytes int
+	series    int
+	writes    *influxdb.ShardStats
+}
+
+// bucketUsage returns the usage of each bucket with data in the engine. The
+// writes are only set if shard stats are tracked.
+func (e *Engine) bucketUsage() (map[influxdb.ID]*bucketUsage, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	usage := make(map[influxdb.ID]*bucketUsage)
+	get := func(name string) *bucketUsage {
+		if len(name) != influxdb.IDLength {
+			return nil
+		}
+		_, bucketID := tsdb.DecodeNameSlice([]byte(name))
+		u := usage[bucketID]
+		if u == nil {
+			u = &bucketUsage{}
+			usage[bucketID] = u
+		}
+		return u
+	}
+
+	sizes, err := e.engine.MeasurementStats()
+	if err != nil {
+		return nil, err
+	}
+	for name, n := range sizes {
+		if u := get(name); u != nil {
+			u.diskBytes += n
+		}
+	}
+
+	series, err := e.index.MeasurementCardinalityStats()
+	if err != nil {
+		return nil, err
+	}
+	for name, n := range series {
+		if u := get(name); u != nil {
+			u.series += n
+		}
+	}
+
+	if e.shardStats == nil {
+		return usage, nil
+	}
+	writes, err := e.shardStats.Stats(influxdb.ShardStatsFilter{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range writes {
+		if u := usage[st.BucketID]; u != nil {
+			u.writes = st
+		}
+	}
+	return usage, nil
+}
+
+// SetBucketMetadata sets the metadata of a bucket, the values of whose
+// configured keys label the bucket metrics.
+func (e *Engine) SetBucketMetadata(bucketID influxdb.ID, md map[string]string) {
+	e.retentionMu.Lock()
+	defer e.retentionMu.Unlock()
+	if len(md) == 0 {
+		delete(e.bucketMetadata, bucketID)
+		return
+	}
+	e.bucketMetadata[bucketID] = md
+}
+
+// bucketMetricLabelValues returns the values of the labels of the bucket
+// metrics of a bucket: its ID followed by the values of keys in its metadata.
+func (e *Engine) bucketMetricLabelValues(bucketID influxdb.ID, keys []string) []string {
+	e.retentionMu.RLock()
+	defer e.retentionMu.RUnlock()
+	md := e.bucketMetadata[bucketID]
+	values := make([]string, 0, len(keys)+1)
+	values = append(values, bucketID.String())
+	for _, k := range keys {
+		values = append(values, md[k])
+	}
+	return values
+}
@@ -3,9 +3,16 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
 )
 
 // BucketDeleter defines the behaviour of deleting a bucket.
@@ -13,14 +20,362 @@ type BucketDeleter interface {
 	DeleteBucket(context.Context, platform.ID, platform.ID) error
 }
 
+// BucketSoftDeleter defines the behaviour of marking a bucket deleted, so it
+// can be restored until it is deleted for good.
+type BucketSoftDeleter interface {
+	platform.BucketRestoreService
+	SoftDeleteBucket(ctx context.Context, id platform.ID) error
+}
+
+// BucketRangeDeleter defines the behaviour of deleting the data of a bucket, or
+// of a single measurement within it, over a time range.
+type BucketRangeDeleter interface {
+	DeleteBucketRange(ctx context.Context, orgID, bucketID platform.ID, measurement string, min, max int64) error
+}
+
+// ShardGroupDurationSetter defines the behaviour of partitioning the data of a
+// bucket into shard groups.
+type ShardGroupDurationSetter interface {
+	SetBucketShardGroupDuration(bucketID platform.ID, d time.Duration)
+}
+
+// RetentionPeriodSetter defines the behaviour of rejecting writes that fall
+// outside the retention period of a bucket.
+type RetentionPeriodSetter interface {
+	SetBucketRetentionPeriod(bucketID platform.ID, d time.Duration)
+}
+
+// PrecisionSetter defines the behaviour of truncating the timestamps written
+// to a bucket to its precision.
+type PrecisionSetter interface {
+	SetBucketPrecision(bucketID platform.ID, d time.Duration)
+}
+
+// CompactionStrategySetter defines the behaviour of planning the compactions
+// of the data of a bucket with the strategy it selects.
+type CompactionStrategySetter interface {
+	SetBucketCompactionStrategy(bucketID platform.ID, name string)
+}
+
+// CacheBudgetSetter defines the behaviour of limiting the size of the data of
+// a bucket held in the cache.
+type CacheBudgetSetter interface {
+	SetBucketCacheBudget(bucketID platform.ID, n uint64)
+}
+
+// CacheWriteColdSetter defines the behaviour of snapshotting the data of a
+// bucket held in the cache once it has not been written to for a while.
+type CacheWriteColdSetter interface {
+	SetBucketCacheWriteColdDuration(bucketID platform.ID, d time.Duration)
+}
+
+// SeriesLimitSetter defines the behaviour of limiting the number of series of
+// a bucket.
+type SeriesLimitSetter interface {
+	SetBucketSeriesLimit(bucketID platform.ID, n int)
+}
+
+// RollupSetter defines the behaviour of downsampling the data written to a
+// bucket into other buckets as it is written.
+type RollupSetter interface {
+	SetBucketRollups(bucketID platform.ID, rules []platform.Rollup)
+}
+
+// StorageOrgSetter defines the behaviour of keeping the data of a bucket
+// transferred to another organization under the organization it is stored
+// under.
+type StorageOrgSetter interface {
+	SetBucketStorageOrg(bucketID, orgID platform.ID)
+}
+
+// MetadataSetter defines the behaviour of labelling the metrics of a bucket
+// with its metadata.
+type MetadataSetter interface {
+	SetBucketMetadata(bucketID platform.ID, md map[string]string)
+}
+
+// BucketUsageReader defines the behaviour of reading the storage used by the
+// data of a bucket.
+type BucketUsageReader interface {
+	BucketUsage(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketUsage, error)
+}
+
+// BucketExporter defines the behaviour of exporting the data of a bucket as
+// line protocol.
+type BucketExporter interface {
+	ExportBucket(ctx context.Context, orgID, bucketID platform.ID, start, stop int64, cursor string, w io.Writer) error
+}
+
+// BucketSettingsSetter is an engine that is kept informed of the settings of
+// each bucket.
+type BucketSettingsSetter interface {
+	ShardGroupDurationSetter
+	RetentionPeriodSetter
+	PrecisionSetter
+	CompactionStrategySetter
+	CacheBudgetSetter
+	CacheWriteColdSetter
+	SeriesLimitSetter
+	RollupSetter
+	StorageOrgSetter
+	MetadataSetter
+}
+
+// MinShardGroupDuration is the shortest shard group duration a bucket can
+// have.
+const MinShardGroupDuration = time.Hour
+
+// DefaultShardGroupDuration returns the shard group duration used for a
+// bucket with the retention period rp when none is given.
+func DefaultShardGroupDuration(rp time.Duration) time.Duration {
+	switch {
+	case rp == platform.InfiniteRetention:
+		return 7 * 24 * time.Hour
+	case rp < 2*24*time.Hour:
+		return time.Hour
+	case rp < 180*24*time.Hour:
+		return 24 * time.Hour
+	default:
+		return 7 * 24 * time.Hour
+	}
+}
+
+func validateShardGroupDuration(rp, sgd time.Duration) error {
+	if sgd < MinShardGroupDuration {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "shard group duration must be at least " + MinShardGroupDuration.String(),
+		}
+	}
+	if rp >= MinShardGroupDuration && sgd > rp {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "shard group duration must not be longer than the retention period",
+		}
+	}
+	return nil
+}
+
+// validatePrecision returns an error if precision is not empty or one of the
+// precisions accepted when writing points.
+func validatePrecision(precision string) error {
+	if precision != "" && !models.ValidPrecision(precision) {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid precision %q: must be one of ns, us, ms or s", precision),
+		}
+	}
+	return nil
+}
+
+// validateCompactionStrategy returns an error if strategy is not empty or the
+// name of a registered compaction strategy.
+func validateCompactionStrategy(strategy string) error {
+	if err := tsm1.ValidateCompactionStrategy(strategy); err != nil {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("%v: must be one of %s", err, strings.Join(tsm1.CompactionStrategyNames(), ", ")),
+		}
+	}
+	return nil
+}
+
+// validateCacheBudget returns an error if n is negative.
+func validateCacheBudget(n int64) error {
+	if n < 0 {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "cache budget must not be negative",
+		}
+	}
+	return nil
+}
+
+// validateCacheWriteColdDuration returns an error if d is negative.
+func validateCacheWriteColdDuration(d time.Duration) error {
+	if d < 0 {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "cache write cold duration must not be negative",
+		}
+	}
+	return nil
+}
+
+// validateSchemaType returns an error if schemaType is not empty or one of the
+// schema types of buckets.
+func validateSchemaType(schemaType string) error {
+	if schemaType != "" && schemaType != platform.SchemaTypeImplicit && schemaType != platform.SchemaTypeExplicit {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  fmt.Sprintf("invalid schema type %q: must be one of %s or %s", schemaType, platform.SchemaTypeImplicit, platform.SchemaTypeExplicit),
+		}
+	}
+	return nil
+}
+
+// validateMaxSeries returns an error if n is negative.
+func validateMaxSeries(n int) error {
+	if n < 0 {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "series limit must not be negative",
+		}
+	}
+	return nil
+}
+
+// validateMetadata returns an error if a key of the metadata of a bucket is
+// empty.
+func validateMetadata(md map[string]string) error {
+	if _, ok := md[""]; ok {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "metadata keys must not be empty",
+		}
+	}
+	return nil
+}
+
+// validateMeasurementRetention returns an error if a rule has no measurement,
+// a retention period shorter than a second or the measurement of an earlier
+// rule.
+func validateMeasurementRetention(rules []platform.MeasurementRetention) error {
+	seen := make(map[string]struct{}, len(rules))
+	for _, mr := range rules {
+		if mr.Measurement == "" {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "measurement retention rule must have a measurement",
+			}
+		} else if mr.RetentionPeriod < time.Second {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("retention period of measurement %q must be greater than or equal to one second", mr.Measurement),
+			}
+		}
+		if _, ok := seen[mr.Measurement]; ok {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("duplicate retention rule for measurement %q", mr.Measurement),
+			}
+		}
+		seen[mr.Measurement] = struct{}{}
+	}
+	return nil
+}
+
+// validateRollups returns an error if a rule has a window shorter than a
+// second, an unknown function, or a target bucket that is bucketID or is not a
+// bucket of orgID.
+func (s *BucketService) validateRollups(ctx context.Context, orgID, bucketID platform.ID, rules []platform.Rollup) error {
+	for _, r := range rules {
+		if r.Every < time.Second {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "rollup window must be greater than or equal to one second",
+			}
+		}
+		if !validRollupFunction(r.Function) {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("invalid rollup function %q: must be one of %s", r.Function, strings.Join(platform.RollupFunctions, ", ")),
+			}
+		}
+		if r.TargetBucketID == bucketID {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "rollup target bucket must be another bucket",
+			}
+		}
+
+		target, err := s.inner.FindBucketByID(ctx, r.TargetBucketID)
+		if platform.ErrorCode(err) == platform.ENotFound || err == nil && target.OrgID != orgID {
+			return &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  fmt.Sprintf("rollup target bucket %s must be a bucket of the organization", r.TargetBucketID),
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validRollupFunction(fn string) bool {
+	for _, f := range platform.RollupFunctions {
+		if fn == f {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadBucketSettings passes the settings of every bucket found by finder on to
+// engine. It is called when the engine is opened, as the engine does not
+// persist bucket settings itself.
+func LoadBucketSettings(ctx context.Context, finder BucketFinder, engine BucketSettingsSetter) error {
+	buckets, _, err := finder.FindBuckets(ctx, platform.BucketFilter{})
+	if err != nil {
+		return err
+	}
+	for _, b := range buckets {
+		engine.SetBucketShardGroupDuration(b.ID, b.ShardGroupDuration)
+		engine.SetBucketRetentionPeriod(b.ID, b.MaxRetentionPeriod())
+		engine.SetBucketPrecision(b.ID, time.Duration(models.GetPrecisionMultiplier(b.Precision)))
+		engine.SetBucketCompactionStrategy(b.ID, b.CompactionStrategy)
+		engine.SetBucketCacheBudget(b.ID, uint64(b.CacheBudget))
+		engine.SetBucketCacheWriteColdDuration(b.ID, b.CacheWriteColdDuration)
+		engine.SetBucketSeriesLimit(b.ID, b.MaxSeries)
+		engine.SetBucketRollups(b.ID, b.Rollups)
+		engine.SetBucketStorageOrg(b.ID, b.StorageOrgID)
+		engine.SetBucketMetadata(b.ID, b.Metadata)
+	}
+	return nil
+}
+
+// DropDeletedBuckets removes the data of each bucket received from deleted from
+// engine and resets its settings, until deleted is closed. It keeps engine in
+// step with buckets deleted other than through a BucketService of engine, as by
+// another instance sharing the metadata store.
+func DropDeletedBuckets(ctx context.Context, log *zap.Logger, deleted <-chan *platform.Bucket, engine BucketDeleter) {
+	s := &BucketService{engine: engine}
+	for b := range deleted {
+		if err := engine.DeleteBucket(ctx, b.DataOrgID(), b.ID); err != nil {
+			log.Error("Failed to delete data of deleted bucket", zap.Stringer("bucket_id", b.ID), zap.Error(err))
+			continue
+		}
+		s.setBucketSettings(&platform.Bucket{ID: b.ID, RetentionPeriod: platform.InfiniteRetention})
+	}
+}
+
+// LoadCreatedBuckets passes the settings of each bucket received from created
+// on to engine, until created is closed. It keeps engine in step with buckets
+// created other than through a BucketService of engine, as the system buckets
+// created with each organization.
+func LoadCreatedBuckets(ctx context.Context, created <-chan *platform.Bucket, engine BucketDeleter) {
+	s := &BucketService{engine: engine}
+	for b := range created {
+		s.setBucketSettings(b)
+	}
+}
+
 // BucketService wraps an existing platform.BucketService implementation.
 //
 // BucketService ensures that when a bucket is deleted, all stored data
 // associated with the bucket is either removed, or marked to be removed via a
-// future compaction.
+// future compaction. If the engine implements any of the setters of
+// BucketSettingsSetter, it is kept informed of those settings of each bucket.
+//
+// If the service has a grace period for deleted buckets and the wrapped
+// service implements BucketSoftDeleter, a deleted bucket is only marked
+// deleted, and its data is kept until PurgeDeletedBuckets drops it once the
+// grace period has passed.
 type BucketService struct {
 	inner  platform.BucketService
 	engine BucketDeleter
+
+	gracePeriod time.Duration
 }
 
 // NewBucketService returns a new BucketService for the provided BucketDeleter,
@@ -32,6 +387,13 @@ func NewBucketService(s platform.BucketService, engine BucketDeleter) *BucketSer
 	}
 }
 
+// WithDeleteGracePeriod sets how long deleted buckets are kept to be
+// restored before their data is dropped. A value of 0 drops the data of a
+// bucket when it is deleted.
+func (s *BucketService) WithDeleteGracePeriod(d time.Duration) {
+	s.gracePeriod = d
+}
+
 // FindBucketByID returns a single bucket by ID.
 func (s *BucketService) FindBucketByID(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
@@ -85,7 +447,51 @@ func (s *BucketService) CreateBucket(ctx context.Context, b *platform.Bucket) er
 	if s.inner == nil || s.engine == nil {
 		return errors.New("nil inner BucketService or Engine")
 	}
-	return s.inner.CreateBucket(ctx, b)
+
+	if b.ShardGroupDuration == 0 {
+		b.ShardGroupDuration = DefaultShardGroupDuration(b.RetentionPeriod)
+	}
+	if err := validateShardGroupDuration(b.RetentionPeriod, b.ShardGroupDuration); err != nil {
+		return err
+	}
+	if err := validatePrecision(b.Precision); err != nil {
+		return err
+	}
+	if err := validateCompactionStrategy(b.CompactionStrategy); err != nil {
+		return err
+	}
+	if err := validateCacheBudget(b.CacheBudget); err != nil {
+		return err
+	}
+	if err := validateCacheWriteColdDuration(b.CacheWriteColdDuration); err != nil {
+		return err
+	}
+	if err := validateMaxSeries(b.MaxSeries); err != nil {
+		return err
+	}
+	if err := validateMeasurementRetention(b.MeasurementRetention); err != nil {
+		return err
+	}
+	if err := validateSchemaType(b.SchemaType); err != nil {
+		return err
+	}
+	if err := s.validateRollups(ctx, b.OrgID, b.ID, b.Rollups); err != nil {
+		return err
+	}
+	if err := validateMetadata(b.Metadata); err != nil {
+		return err
+	}
+	if b.Type != platform.BucketTypeSystem {
+		if err := s.checkBucketQuota(ctx, b.OrgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.inner.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+	s.setBucketSettings(b)
+	return nil
 }
 
 // UpdateBucket updates a single bucket with changeset.
@@ -97,7 +503,89 @@ func (s *BucketService) UpdateBucket(ctx context.Context, id platform.ID, upd pl
 	if s.inner == nil || s.engine == nil {
 		return nil, errors.New("nil inner BucketService or Engine")
 	}
-	return s.inner.UpdateBucket(ctx, id, upd)
+
+	if upd.Precision != nil {
+		if err := validatePrecision(*upd.Precision); err != nil {
+			return nil, err
+		}
+	}
+	if upd.CompactionStrategy != nil {
+		if err := validateCompactionStrategy(*upd.CompactionStrategy); err != nil {
+			return nil, err
+		}
+	}
+	if upd.CacheBudget != nil {
+		if err := validateCacheBudget(*upd.CacheBudget); err != nil {
+			return nil, err
+		}
+	}
+	if upd.CacheWriteColdDuration != nil {
+		if err := validateCacheWriteColdDuration(*upd.CacheWriteColdDuration); err != nil {
+			return nil, err
+		}
+	}
+	if upd.MaxSeries != nil {
+		if err := validateMaxSeries(*upd.MaxSeries); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateMeasurementRetention(upd.MeasurementRetention); err != nil {
+		return nil, err
+	}
+	if err := validateMetadata(upd.Metadata); err != nil {
+		return nil, err
+	}
+	if len(upd.Rollups) > 0 {
+		b, err := s.inner.FindBucketByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		// The data of rollups is written under the organization of the data
+		// of their bucket, which its targets do not share once it is
+		// transferred.
+		if b.StorageOrgID.Valid() {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "rollups cannot be set on a bucket transferred from another organization",
+			}
+		}
+		if err := s.validateRollups(ctx, b.OrgID, id, upd.Rollups); err != nil {
+			return nil, err
+		}
+	}
+
+	if upd.RetentionPeriod != nil || upd.ShardGroupDuration != nil {
+		b, err := s.inner.FindBucketByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		rp, sgd := b.RetentionPeriod, b.ShardGroupDuration
+		if upd.RetentionPeriod != nil {
+			rp = *upd.RetentionPeriod
+		}
+		if upd.ShardGroupDuration != nil {
+			sgd = *upd.ShardGroupDuration
+		} else if sgd != 0 && validateShardGroupDuration(rp, sgd) != nil {
+			// The retention period has been shortened below the current
+			// shard group duration, so fall back to the default.
+			sgd = DefaultShardGroupDuration(rp)
+			upd.ShardGroupDuration = &sgd
+		}
+
+		if upd.ShardGroupDuration != nil {
+			if err := validateShardGroupDuration(rp, sgd); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	b, err := s.inner.UpdateBucket(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	s.setBucketSettings(b)
+	return b, nil
 }
 
 // DeleteBucket removes a bucket by ID.
@@ -105,6 +593,11 @@ func (s *BucketService) DeleteBucket(ctx context.Context, bucketID platform.ID)
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if sd, ok := s.inner.(BucketSoftDeleter); ok && s.gracePeriod > 0 {
+		// The bucket keeps its settings until its data is dropped.
+		return sd.SoftDeleteBucket(ctx, bucketID)
+	}
+
 	bucket, err := s.FindBucketByID(ctx, bucketID)
 	if err != nil {
 		return err
@@ -113,8 +606,270 @@ func (s *BucketService) DeleteBucket(ctx context.Context, bucketID platform.ID)
 	// The data is dropped first from the storage engine. If this fails for any
 	// reason, then the bucket will still be available in the future to retrieve
 	// the orgID, which is needed for the engine.
-	if err := s.engine.DeleteBucket(ctx, bucket.OrgID, bucketID); err != nil {
+	if err := s.engine.DeleteBucket(ctx, bucket.DataOrgID(), bucketID); err != nil {
 		return err
 	}
-	return s.inner.DeleteBucket(ctx, bucketID)
+	if err := s.inner.DeleteBucket(ctx, bucketID); err != nil {
+		return err
+	}
+	s.setBucketSettings(&platform.Bucket{ID: bucketID, RetentionPeriod: platform.InfiniteRetention})
+	return nil
+}
+
+// RenameBucket gives a bucket another name.
+func (s *BucketService) RenameBucket(ctx context.Context, id platform.ID, name string) (*platform.Bucket, error) {
+	return s.UpdateBucket(ctx, id, platform.BucketUpdate{Name: &name})
+}
+
+// TransferBucket moves a bucket to another organization. The data of the
+// bucket stays under the organization it is stored under, and the engine is
+// told to read and write it there.
+//
+// A bucket with rollups, or which is the target of the rollups of another
+// bucket, cannot be transferred, as rollups stay within an organization.
+func (s *BucketService) TransferBucket(ctx context.Context, id, orgID platform.ID) (*platform.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ts, ok := s.inner.(platform.BucketTransferService)
+	if !ok {
+		return nil, &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Msg:  "buckets cannot be transferred",
+		}
+	}
+
+	b, err := s.inner.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.OrgID != orgID {
+		if err := s.validateTransfer(ctx, b); err != nil {
+			return nil, err
+		}
+		if err := s.checkBucketQuota(ctx, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err = ts.TransferBucket(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	s.setBucketSettings(b)
+	return b, nil
+}
+
+// FindBucketUsage returns the storage used by the data of a bucket.
+func (s *BucketService) FindBucketUsage(ctx context.Context, id platform.ID) (*platform.BucketUsage, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ur, ok := s.engine.(BucketUsageReader)
+	if !ok {
+		return nil, &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Msg:  "bucket usage is not tracked",
+		}
+	}
+
+	b, err := s.inner.FindBucketByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return ur.BucketUsage(ctx, b.DataOrgID(), b.ID)
+}
+
+// checkBucketQuota returns an error if the organization may not have another
+// bucket under its max-buckets quota. System buckets are not counted.
+func (s *BucketService) checkBucketQuota(ctx context.Context, orgID platform.ID) error {
+	qc, ok := s.engine.(BucketQuotaChecker)
+	if !ok {
+		return nil
+	}
+
+	bs, _, err := s.inner.FindBuckets(ctx, platform.BucketFilter{OrganizationID: &orgID})
+	if err != nil {
+		return err
+	}
+	var n int
+	for _, b := range bs {
+		if b.Type != platform.BucketTypeSystem {
+			n++
+		}
+	}
+	return qc.CheckBucketQuota(orgID, n)
+}
+
+// ExportBucket writes the points of a bucket with times in [start, stop) to w
+// as line protocol.
+func (s *BucketService) ExportBucket(ctx context.Context, id platform.ID, start, stop int64, cursor string, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ex, ok := s.engine.(BucketExporter)
+	if !ok {
+		return &platform.Error{
+			Code: platform.EMethodNotAllowed,
+			Msg:  "bucket export is not supported",
+		}
+	}
+
+	b, err := s.inner.FindBucketByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ex.ExportBucket(ctx, b.DataOrgID(), b.ID, start, stop, cursor, w)
+}
+
+// validateTransfer returns an error if b has rollups or is the target of the
+// rollups of another bucket of its organization.
+func (s *BucketService) validateTransfer(ctx context.Context, b *platform.Bucket) error {
+	if len(b.Rollups) > 0 {
+		return &platform.Error{
+			Code: platform.EInvalid,
+			Msg:  "buckets with rollups cannot be transferred",
+		}
+	}
+
+	buckets, _, err := s.inner.FindBuckets(ctx, platform.BucketFilter{OrganizationID: &b.OrgID})
+	if err != nil {
+		return err
+	}
+	for _, src := range buckets {
+		for _, r := range src.Rollups {
+			if r.TargetBucketID == b.ID {
+				return &platform.Error{
+					Code: platform.EInvalid,
+					Msg:  fmt.Sprintf("bucket is the target of the rollups of bucket %s and cannot be transferred", src.ID),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FindDeletedBucketByID returns a deleted bucket by ID.
+func (s *BucketService) FindDeletedBucketByID(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sd, err := s.softDeleter()
+	if err != nil {
+		return nil, err
+	}
+	return sd.FindDeletedBucketByID(ctx, id)
+}
+
+// RestoreBucket undeletes a deleted bucket whose data is yet to be dropped.
+func (s *BucketService) RestoreBucket(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	sd, err := s.softDeleter()
+	if err != nil {
+		return nil, err
+	}
+	b, err := sd.RestoreBucket(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// The settings of the bucket are not loaded by the engine when it is
+	// opened while the bucket is deleted.
+	s.setBucketSettings(b)
+	return b, nil
+}
+
+func (s *BucketService) softDeleter() (BucketSoftDeleter, error) {
+	sd, ok := s.inner.(BucketSoftDeleter)
+	if !ok || s.gracePeriod <= 0 {
+		return nil, &platform.Error{
+			Code: platform.ENotFound,
+			Msg:  "deleted buckets are not kept",
+		}
+	}
+	return sd, nil
+}
+
+// PurgeDeletedBuckets drops the data of the buckets deleted more than the
+// grace period before now, and then deletes them for good.
+func (s *BucketService) PurgeDeletedBuckets(ctx context.Context, now time.Time) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, err := s.softDeleter(); err != nil {
+		return nil
+	}
+
+	bs, _, err := s.inner.FindBuckets(ctx, platform.BucketFilter{Deleted: true})
+	if err != nil {
+		return err
+	}
+	for _, b := range bs {
+		if b.DeletedAt == nil || now.Sub(*b.DeletedAt) < s.gracePeriod {
+			continue
+		}
+		if err := s.engine.DeleteBucket(ctx, b.DataOrgID(), b.ID); err != nil {
+			return err
+		}
+		if err := s.inner.DeleteBucket(ctx, b.ID); err != nil {
+			return err
+		}
+		s.setBucketSettings(&platform.Bucket{ID: b.ID, RetentionPeriod: platform.InfiniteRetention})
+	}
+	return nil
+}
+
+// PurgeDeletedBucketsEvery calls PurgeDeletedBuckets of s every interval,
+// until ctx is done.
+func PurgeDeletedBucketsEvery(ctx context.Context, log *zap.Logger, s *BucketService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.PurgeDeletedBuckets(ctx, now); err != nil {
+				log.Error("Failed to purge deleted buckets", zap.Error(err))
+			}
+		}
+	}
+}
+
+// setBucketSettings passes the settings of a bucket on to the engine, if it
+// uses them.
+func (s *BucketService) setBucketSettings(b *platform.Bucket) {
+	if e, ok := s.engine.(ShardGroupDurationSetter); ok {
+		e.SetBucketShardGroupDuration(b.ID, b.ShardGroupDuration)
+	}
+	if e, ok := s.engine.(RetentionPeriodSetter); ok {
+		// Writes are only rejected if they are outside of the retention
+		// period of all of the measurements of the bucket.
+		e.SetBucketRetentionPeriod(b.ID, b.MaxRetentionPeriod())
+	}
+	if e, ok := s.engine.(PrecisionSetter); ok {
+		e.SetBucketPrecision(b.ID, time.Duration(models.GetPrecisionMultiplier(b.Precision)))
+	}
+	if e, ok := s.engine.(CompactionStrategySetter); ok {
+		e.SetBucketCompactionStrategy(b.ID, b.CompactionStrategy)
+	}
+	if e, ok := s.engine.(CacheBudgetSetter); ok {
+		e.SetBucketCacheBudget(b.ID, uint64(b.CacheBudget))
+	}
+	if e, ok := s.engine.(CacheWriteColdSetter); ok {
+		e.SetBucketCacheWriteColdDuration(b.ID, b.CacheWriteColdDuration)
+	}
+	if e, ok := s.engine.(SeriesLimitSetter); ok {
+		e.SetBucketSeriesLimit(b.ID, b.MaxSeries)
+	}
+	if e, ok := s.engine.(RollupSetter); ok {
+		e.SetBucketRollups(b.ID, b.Rollups)
+	}
+	if e, ok := s.engine.(StorageOrgSetter); ok {
+		e.SetBucketStorageOrg(b.ID, b.StorageOrgID)
+	}
+	if e, ok := s.engine.(MetadataSetter); ok {
+		e.SetBucketMetadata(b.ID, b.Metadata)
+	}
 }
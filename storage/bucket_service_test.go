@@ -2,7 +2,9 @@ package storage_test
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/inmem"
@@ -55,6 +57,642 @@ func TestBucketService(t *testing.T) {
 	}
 }
 
+func TestBucketService_DeleteGracePeriod(t *testing.T) {
+	ctx := context.Background()
+	inmemService := newInMemKVSVC(t)
+	engine := NewMockSettingsEngine()
+	service := storage.NewBucketService(inmemService, engine)
+	service.WithDeleteGracePeriod(24 * time.Hour)
+
+	org := &platform.Organization{Name: "org1"}
+	if err := inmemService.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &platform.Bucket{OrgID: org.ID, Name: "bucket1", RetentionPeriod: 72 * time.Hour}
+	if err := service.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	// A deleted bucket is hidden, but its data is kept.
+	if err := service.DeleteBucket(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if engine.bucketID.Valid() {
+		t.Fatalf("expected the data of bucket %s to be kept", engine.bucketID)
+	}
+	if _, err := service.FindBucketByID(ctx, bucket.ID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected deleted bucket not to be found, got %v", err)
+	}
+
+	// A restored bucket is found again, with its settings.
+	engine.retentions[bucket.ID] = 0
+	if _, err := service.RestoreBucket(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.FindBucketByID(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.retentions[bucket.ID]; got != 72*time.Hour {
+		t.Fatalf("got retention period %s, expected 72h", got)
+	}
+
+	// The data of a deleted bucket is dropped once its grace period has passed.
+	if err := service.DeleteBucket(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := service.FindDeletedBucketByID(ctx, bucket.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.PurgeDeletedBuckets(ctx, deleted.DeletedAt.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if engine.bucketID.Valid() {
+		t.Fatalf("expected the data of bucket %s to be kept during its grace period", engine.bucketID)
+	}
+	if err := service.PurgeDeletedBuckets(ctx, deleted.DeletedAt.Add(24*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if engine.orgID != org.ID || engine.bucketID != bucket.ID {
+		t.Errorf("got org ID %s and bucket ID %s, expected %s and %s", engine.orgID, engine.bucketID, org.ID, bucket.ID)
+	}
+	if got, ok := engine.retentions[bucket.ID]; !ok || got != 0 {
+		t.Errorf("got retention period %s, expected it reset", got)
+	}
+	if _, err := service.FindDeletedBucketByID(ctx, bucket.ID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("expected purged bucket not to be found, got %v", err)
+	}
+}
+
+func TestDropDeletedBuckets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inmemService := newInMemKVSVC(t)
+	org := &platform.Organization{Name: "org1"}
+	if err := inmemService.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	bucket := &platform.Bucket{OrgID: org.ID, Name: "bucket1"}
+	if err := inmemService.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+
+	watched, err := inmemService.WatchDeletedBuckets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bucket is deleted without going through a storage BucketService.
+	if err := inmemService.DeleteBucket(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := make(chan *platform.Bucket, 1)
+	select {
+	case b := <-watched:
+		deleted <- b
+		close(deleted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for deleted bucket")
+	}
+
+	engine := NewMockSettingsEngine()
+	storage.DropDeletedBuckets(ctx, zaptest.NewLogger(t), deleted, engine)
+
+	if engine.orgID != org.ID || engine.bucketID != bucket.ID {
+		t.Errorf("got org ID %s and bucket ID %s, expected %s and %s", engine.orgID, engine.bucketID, org.ID, bucket.ID)
+	}
+	if got, ok := engine.retentions[bucket.ID]; !ok || got != 0 {
+		t.Errorf("got retention period %s, expected it reset", got)
+	}
+}
+
+func TestLoadCreatedBuckets(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inmemService := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore(), kv.ServiceConfig{
+		SystemBuckets: kv.SystemBucketsConfig{
+			MonitoringRetention: 48 * time.Hour,
+			MaxSeries:           1000,
+		},
+	})
+	if err := inmemService.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	watched, err := inmemService.WatchCreatedBuckets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The system buckets are created with the organization, without going
+	// through a storage BucketService.
+	org := &platform.Organization{Name: "org1"}
+	if err := inmemService.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	created := make(chan *platform.Bucket, 2)
+	for i := 0; i < cap(created); i++ {
+		select {
+		case b := <-watched:
+			created <- b
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for created bucket")
+		}
+	}
+	close(created)
+
+	engine := NewMockSettingsEngine()
+	storage.LoadCreatedBuckets(ctx, created, engine)
+
+	for _, exp := range []struct {
+		name      string
+		retention time.Duration
+	}{
+		{platform.TasksSystemBucketName, platform.TasksSystemBucketRetention},
+		{platform.MonitoringSystemBucketName, 48 * time.Hour},
+	} {
+		b, err := inmemService.FindBucketByName(ctx, org.ID, exp.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := engine.retentions[b.ID]; got != exp.retention {
+			t.Errorf("%s: got retention period %s, expected %s", exp.name, got, exp.retention)
+		}
+		if got := engine.limits[b.ID]; got != 1000 {
+			t.Errorf("%s: got series limit %d, expected 1000", exp.name, got)
+		}
+	}
+}
+
+func TestBucketService_ShardGroupDuration(t *testing.T) {
+	inmemService := newInMemKVSVC(t)
+	engine := &MockShardGroupEngine{durations: make(map[platform.ID]time.Duration)}
+	service := storage.NewBucketService(inmemService, engine)
+
+	org := &platform.Organization{Name: "org1"}
+	if err := inmemService.CreateOrganization(context.TODO(), org); err != nil {
+		t.Fatal(err)
+	}
+
+	// New buckets are given a default shard group duration for their retention.
+	bucket := &platform.Bucket{OrgID: org.ID, Name: "short", RetentionPeriod: 24 * time.Hour}
+	if err := service.CreateBucket(context.TODO(), bucket); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.durations[bucket.ID], time.Hour; bucket.ShardGroupDuration != exp || got != exp {
+		t.Fatalf("got shard group duration %s on bucket and %s on engine, expected %s", bucket.ShardGroupDuration, got, exp)
+	}
+
+	// Shard group durations are persisted and passed on to the engine.
+	sgd := 12 * time.Hour
+	if _, err := service.UpdateBucket(context.TODO(), bucket.ID, platform.BucketUpdate{ShardGroupDuration: &sgd}); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := inmemService.FindBucketByID(context.TODO(), bucket.ID); err != nil {
+		t.Fatal(err)
+	} else if b.ShardGroupDuration != sgd {
+		t.Fatalf("got persisted shard group duration %s, expected %s", b.ShardGroupDuration, sgd)
+	} else if got := engine.durations[bucket.ID]; got != sgd {
+		t.Fatalf("got engine shard group duration %s, expected %s", got, sgd)
+	}
+
+	// Shortening the retention period below the shard group duration resets
+	// it to the default.
+	rp := 6 * time.Hour
+	if b, err := service.UpdateBucket(context.TODO(), bucket.ID, platform.BucketUpdate{RetentionPeriod: &rp}); err != nil {
+		t.Fatal(err)
+	} else if b.ShardGroupDuration != time.Hour {
+		t.Fatalf("got shard group duration %s, expected %s", b.ShardGroupDuration, time.Hour)
+	}
+
+	for _, sgd := range []time.Duration{time.Minute, 7 * time.Hour} {
+		sgd := sgd
+		_, err := service.UpdateBucket(context.TODO(), bucket.ID, platform.BucketUpdate{ShardGroupDuration: &sgd})
+		if platform.ErrorCode(err) != platform.EInvalid {
+			t.Fatalf("got error %v for shard group duration %s, expected %s", err, sgd, platform.EInvalid)
+		}
+	}
+
+	if err := service.CreateBucket(context.TODO(), &platform.Bucket{OrgID: org.ID, Name: "invalid", RetentionPeriod: 24 * time.Hour, ShardGroupDuration: 48 * time.Hour}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, platform.EInvalid)
+	}
+
+	// Deleting the bucket stops its data being partitioned.
+	if err := service.DeleteBucket(context.TODO(), bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := engine.durations[bucket.ID]; ok {
+		t.Fatal("expected shard group duration to be removed from engine")
+	}
+}
+
+func TestBucketService_Settings(t *testing.T) {
+	hours := func(n int) time.Duration { return time.Duration(n) * time.Hour }
+	strp := func(s string) *string { return &s }
+	int64p := func(n int64) *int64 { return &n }
+	intp := func(n int) *int { return &n }
+	durp := func(d time.Duration) *time.Duration { return &d }
+
+	tests := []struct {
+		name string
+		// bucket is created with the setting, which the engine is given as
+		// created.
+		bucket  platform.Bucket
+		created interface{}
+		// update changes the setting, which is persisted as persisted and
+		// given to the engine, and to an engine loading the buckets, as
+		// updated.
+		update    platform.BucketUpdate
+		persisted interface{}
+		updated   interface{}
+		setting   func(b *platform.Bucket) interface{}
+		engine    func(e *MockSettingsEngine, id platform.ID) interface{}
+		// invalidUpdates and invalidBuckets have invalid settings.
+		invalidUpdates []platform.BucketUpdate
+		invalidBuckets []platform.Bucket
+	}{
+		{
+			name:      "precision",
+			bucket:    platform.Bucket{Precision: "s"},
+			created:   time.Second,
+			update:    platform.BucketUpdate{Precision: strp("ms")},
+			persisted: "ms",
+			updated:   time.Millisecond,
+			setting:   func(b *platform.Bucket) interface{} { return b.Precision },
+			engine:    func(e *MockSettingsEngine, id platform.ID) interface{} { return e.precisions[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{Precision: strp("m")},
+			},
+			invalidBuckets: []platform.Bucket{
+				{Precision: "h"},
+			},
+		},
+		{
+			name:      "compaction strategy",
+			bucket:    platform.Bucket{CompactionStrategy: "append-only"},
+			created:   "append-only",
+			update:    platform.BucketUpdate{CompactionStrategy: strp("high-churn")},
+			persisted: "high-churn",
+			updated:   "high-churn",
+			setting:   func(b *platform.Bucket) interface{} { return b.CompactionStrategy },
+			engine:    func(e *MockSettingsEngine, id platform.ID) interface{} { return e.strategies[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{CompactionStrategy: strp("unknown")},
+			},
+			invalidBuckets: []platform.Bucket{
+				{CompactionStrategy: "unknown"},
+			},
+		},
+		{
+			name:      "cache budget",
+			bucket:    platform.Bucket{CacheBudget: 1 << 20},
+			created:   uint64(1 << 20),
+			update:    platform.BucketUpdate{CacheBudget: int64p(1 << 10)},
+			persisted: int64(1 << 10),
+			updated:   uint64(1 << 10),
+			setting:   func(b *platform.Bucket) interface{} { return b.CacheBudget },
+			engine:    func(e *MockSettingsEngine, id platform.ID) interface{} { return e.budgets[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{CacheBudget: int64p(-1)},
+			},
+			invalidBuckets: []platform.Bucket{
+				{CacheBudget: -1},
+			},
+		},
+		{
+			name:      "cache write cold duration",
+			bucket:    platform.Bucket{CacheWriteColdDuration: time.Minute},
+			created:   time.Minute,
+			update:    platform.BucketUpdate{CacheWriteColdDuration: durp(5 * time.Second)},
+			persisted: 5 * time.Second,
+			updated:   5 * time.Second,
+			setting:   func(b *platform.Bucket) interface{} { return b.CacheWriteColdDuration },
+			engine:    func(e *MockSettingsEngine, id platform.ID) interface{} { return e.colds[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{CacheWriteColdDuration: durp(-time.Second)},
+			},
+		},
+		{
+			name:      "series limit",
+			bucket:    platform.Bucket{MaxSeries: 10},
+			created:   10,
+			update:    platform.BucketUpdate{MaxSeries: intp(1000)},
+			persisted: 1000,
+			updated:   1000,
+			setting:   func(b *platform.Bucket) interface{} { return b.MaxSeries },
+			engine:    func(e *MockSettingsEngine, id platform.ID) interface{} { return e.limits[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{MaxSeries: intp(-1)},
+			},
+			invalidBuckets: []platform.Bucket{
+				{MaxSeries: -1},
+			},
+		},
+		{
+			// Writes are accepted within the longest retention period of the
+			// bucket, and measurement rules are replaced by updates.
+			name: "measurement retention",
+			bucket: platform.Bucket{
+				RetentionPeriod: hours(30 * 24),
+				MeasurementRetention: []platform.MeasurementRetention{
+					{Measurement: "events", RetentionPeriod: hours(2 * 365 * 24)},
+				},
+			},
+			created: hours(2 * 365 * 24),
+			update: platform.BucketUpdate{MeasurementRetention: []platform.MeasurementRetention{
+				{Measurement: "debug", RetentionPeriod: time.Hour},
+			}},
+			persisted: []platform.MeasurementRetention{
+				{Measurement: "debug", RetentionPeriod: time.Hour},
+			},
+			updated: hours(30 * 24),
+			setting: func(b *platform.Bucket) interface{} { return b.MeasurementRetention },
+			engine:  func(e *MockSettingsEngine, id platform.ID) interface{} { return e.retentions[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{MeasurementRetention: []platform.MeasurementRetention{{RetentionPeriod: time.Hour}}},
+				{MeasurementRetention: []platform.MeasurementRetention{{Measurement: "debug"}}},
+				{MeasurementRetention: []platform.MeasurementRetention{
+					{Measurement: "debug", RetentionPeriod: time.Hour},
+					{Measurement: "debug", RetentionPeriod: 2 * time.Hour},
+				}},
+			},
+			invalidBuckets: []platform.Bucket{
+				{MeasurementRetention: []platform.MeasurementRetention{{RetentionPeriod: time.Hour}}},
+			},
+		},
+		{
+			// The metadata is replaced by updates.
+			name:      "metadata",
+			bucket:    platform.Bucket{Metadata: map[string]string{"team": "platform", "cost-center": "42"}},
+			created:   map[string]string{"team": "platform", "cost-center": "42"},
+			update:    platform.BucketUpdate{Metadata: map[string]string{"team": "storage"}},
+			persisted: map[string]string{"team": "storage"},
+			updated:   map[string]string{"team": "storage"},
+			setting:   func(b *platform.Bucket) interface{} { return b.Metadata },
+			engine:    func(e *MockSettingsEngine, id platform.ID) interface{} { return e.metadata[id] },
+			invalidUpdates: []platform.BucketUpdate{
+				{Metadata: map[string]string{"": "storage"}},
+			},
+			invalidBuckets: []platform.Bucket{
+				{Metadata: map[string]string{"": "platform"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			inmemService := newInMemKVSVC(t)
+			engine := NewMockSettingsEngine()
+			service := storage.NewBucketService(inmemService, engine)
+
+			org := &platform.Organization{Name: "org1"}
+			if err := inmemService.CreateOrganization(ctx, org); err != nil {
+				t.Fatal(err)
+			}
+
+			bucket := tt.bucket
+			bucket.OrgID, bucket.Name = org.ID, "bucket"
+			if err := service.CreateBucket(ctx, &bucket); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.engine(engine, bucket.ID); !reflect.DeepEqual(got, tt.created) {
+				t.Fatalf("got engine setting %v, expected %v", got, tt.created)
+			}
+
+			// Settings are persisted and passed on to the engine.
+			if _, err := service.UpdateBucket(ctx, bucket.ID, tt.update); err != nil {
+				t.Fatal(err)
+			}
+			if b, err := inmemService.FindBucketByID(ctx, bucket.ID); err != nil {
+				t.Fatal(err)
+			} else if got := tt.setting(b); !reflect.DeepEqual(got, tt.persisted) {
+				t.Fatalf("got persisted setting %v, expected %v", got, tt.persisted)
+			}
+			if got := tt.engine(engine, bucket.ID); !reflect.DeepEqual(got, tt.updated) {
+				t.Fatalf("got engine setting %v, expected %v", got, tt.updated)
+			}
+
+			for _, upd := range tt.invalidUpdates {
+				if _, err := service.UpdateBucket(ctx, bucket.ID, upd); platform.ErrorCode(err) != platform.EInvalid {
+					t.Fatalf("got error %v for update %+v, expected %s", err, upd, platform.EInvalid)
+				}
+			}
+			for _, b := range tt.invalidBuckets {
+				b.OrgID, b.Name = org.ID, "invalid"
+				if err := service.CreateBucket(ctx, &b); platform.ErrorCode(err) != platform.EInvalid {
+					t.Fatalf("got error %v for bucket %+v, expected %s", err, b, platform.EInvalid)
+				}
+			}
+
+			// Buckets are loaded with their settings.
+			engine = NewMockSettingsEngine()
+			if err := storage.LoadBucketSettings(ctx, inmemService, engine); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.engine(engine, bucket.ID); !reflect.DeepEqual(got, tt.updated) {
+				t.Fatalf("got loaded engine setting %v, expected %v", got, tt.updated)
+			}
+		})
+	}
+}
+
+func TestBucketService_Rollups(t *testing.T) {
+	inmemService := newInMemKVSVC(t)
+	engine := NewMockSettingsEngine()
+	service := storage.NewBucketService(inmemService, engine)
+
+	org := &platform.Organization{Name: "org1"}
+	other := &platform.Organization{Name: "org2"}
+	for _, o := range []*platform.Organization{org, other} {
+		if err := inmemService.CreateOrganization(context.TODO(), o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	target := &platform.Bucket{OrgID: org.ID, Name: "10s"}
+	foreign := &platform.Bucket{OrgID: other.ID, Name: "foreign"}
+	for _, b := range []*platform.Bucket{target, foreign} {
+		if err := service.CreateBucket(context.TODO(), b); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rules := []platform.Rollup{{Every: 10 * time.Second, Function: platform.RollupFunctionMean, TargetBucketID: target.ID}}
+	bucket := &platform.Bucket{OrgID: org.ID, Name: "raw", Rollups: rules}
+	if err := service.CreateBucket(context.TODO(), bucket); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.rollups[bucket.ID]; !reflect.DeepEqual(got, rules) {
+		t.Fatalf("got engine rollups %+v, expected %+v", got, rules)
+	}
+
+	for _, rule := range []platform.Rollup{
+		{Every: time.Millisecond, Function: platform.RollupFunctionMean, TargetBucketID: target.ID},
+		{Every: time.Minute, Function: "median", TargetBucketID: target.ID},
+		{Every: time.Minute, Function: platform.RollupFunctionMax, TargetBucketID: bucket.ID},
+		{Every: time.Minute, Function: platform.RollupFunctionMax, TargetBucketID: foreign.ID},
+	} {
+		if _, err := service.UpdateBucket(context.TODO(), bucket.ID, platform.BucketUpdate{Rollups: []platform.Rollup{rule}}); platform.ErrorCode(err) != platform.EInvalid {
+			t.Fatalf("got error %v for rule %+v, expected %s", err, rule, platform.EInvalid)
+		}
+	}
+
+	// Rollups are removed with an empty list.
+	if _, err := service.UpdateBucket(context.TODO(), bucket.ID, platform.BucketUpdate{Rollups: []platform.Rollup{}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.rollups[bucket.ID]; len(got) != 0 {
+		t.Fatalf("got engine rollups %+v, expected none", got)
+	}
+}
+
+func TestBucketService_TransferBucket(t *testing.T) {
+	ctx := context.Background()
+	inmemService := newInMemKVSVC(t)
+	engine := NewMockSettingsEngine()
+	service := storage.NewBucketService(inmemService, engine)
+
+	from := &platform.Organization{Name: "org1"}
+	to := &platform.Organization{Name: "org2"}
+	for _, o := range []*platform.Organization{from, to} {
+		if err := inmemService.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	target := &platform.Bucket{OrgID: from.ID, Name: "10s"}
+	if err := service.CreateBucket(ctx, target); err != nil {
+		t.Fatal(err)
+	}
+	rules := []platform.Rollup{{Every: 10 * time.Second, Function: platform.RollupFunctionMean, TargetBucketID: target.ID}}
+	raw := &platform.Bucket{OrgID: from.ID, Name: "raw", Rollups: rules}
+	if err := service.CreateBucket(ctx, raw); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rollups stay within an organization.
+	for _, b := range []*platform.Bucket{raw, target} {
+		if _, err := service.TransferBucket(ctx, b.ID, to.ID); platform.ErrorCode(err) != platform.EInvalid {
+			t.Fatalf("got error %v transferring bucket %s, expected %s", err, b.Name, platform.EInvalid)
+		}
+	}
+	if _, err := service.UpdateBucket(ctx, raw.ID, platform.BucketUpdate{Rollups: []platform.Rollup{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The engine keeps the data of a transferred bucket under the
+	// organization it was transferred from.
+	b, err := service.TransferBucket(ctx, target.ID, to.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.OrgID != to.ID {
+		t.Fatalf("got bucket in org %s, expected %s", b.OrgID, to.ID)
+	}
+	if got := engine.orgs[target.ID]; got != from.ID {
+		t.Fatalf("got data of bucket stored under org %s, expected %s", got, from.ID)
+	}
+
+	rules = []platform.Rollup{{Every: 10 * time.Second, Function: platform.RollupFunctionMean, TargetBucketID: raw.ID}}
+	if _, err := service.UpdateBucket(ctx, target.ID, platform.BucketUpdate{Rollups: rules}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("got error %v setting rollups of transferred bucket, expected %s", err, platform.EInvalid)
+	}
+
+	if _, err := service.TransferBucket(ctx, target.ID, from.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.orgs[target.ID]; got.Valid() {
+		t.Fatalf("got data of bucket stored under org %s, expected its own org", got)
+	}
+}
+
+func TestBucketService_SchemaType(t *testing.T) {
+	inmemService := newInMemKVSVC(t)
+	service := storage.NewBucketService(inmemService, NewMockSettingsEngine())
+
+	org := &platform.Organization{Name: "org1"}
+	if err := inmemService.CreateOrganization(context.TODO(), org); err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := &platform.Bucket{OrgID: org.ID, Name: "strict", SchemaType: platform.SchemaTypeExplicit}
+	if err := service.CreateBucket(context.TODO(), bucket); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := inmemService.FindBucketByID(context.TODO(), bucket.ID); err != nil {
+		t.Fatal(err)
+	} else if !b.ExplicitSchema() {
+		t.Fatalf("got schema type %q, expected %q", b.SchemaType, platform.SchemaTypeExplicit)
+	}
+
+	if err := service.CreateBucket(context.TODO(), &platform.Bucket{OrgID: org.ID, Name: "invalid", SchemaType: "strict"}); platform.ErrorCode(err) != platform.EInvalid {
+		t.Fatalf("got error %v, expected %s", err, platform.EInvalid)
+	}
+}
+
+// bucketQuotaEngine limits every organization to maxBuckets buckets.
+type bucketQuotaEngine struct {
+	MockDeleter
+	maxBuckets int
+}
+
+func (e *bucketQuotaEngine) CheckBucketQuota(orgID platform.ID, n int) error {
+	if n >= e.maxBuckets {
+		return platform.ErrQuotaExceeded(orgID, platform.QuotaMaxBuckets, int64(n), int64(e.maxBuckets))
+	}
+	return nil
+}
+
+func TestBucketService_BucketQuota(t *testing.T) {
+	ctx := context.Background()
+	inmemService := newInMemKVSVC(t)
+	service := storage.NewBucketService(inmemService, &bucketQuotaEngine{maxBuckets: 1})
+
+	org1 := &platform.Organization{Name: "org1"}
+	org2 := &platform.Organization{Name: "org2"}
+	for _, o := range []*platform.Organization{org1, org2} {
+		if err := inmemService.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The system buckets of an organization are not counted.
+	b1 := &platform.Bucket{OrgID: org1.ID, Name: "b1"}
+	if err := service.CreateBucket(ctx, b1); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.CreateBucket(ctx, &platform.Bucket{OrgID: org1.ID, Name: "b2"}); platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("got error %v, expected %s", err, platform.EForbidden)
+	}
+
+	b3 := &platform.Bucket{OrgID: org2.ID, Name: "b3"}
+	if err := service.CreateBucket(ctx, b3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.TransferBucket(ctx, b3.ID, org1.ID); platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("got error %v, expected %s", err, platform.EForbidden)
+	}
+}
+
+func TestDefaultShardGroupDuration(t *testing.T) {
+	for _, tt := range []struct {
+		rp, exp time.Duration
+	}{
+		{rp: 0, exp: 7 * 24 * time.Hour},
+		{rp: time.Hour, exp: time.Hour},
+		{rp: 2 * 24 * time.Hour, exp: 24 * time.Hour},
+		{rp: 30 * 24 * time.Hour, exp: 24 * time.Hour},
+		{rp: 365 * 24 * time.Hour, exp: 7 * 24 * time.Hour},
+	} {
+		if got := storage.DefaultShardGroupDuration(tt.rp); got != tt.exp {
+			t.Errorf("got %s for retention %s, expected %s", got, tt.rp, tt.exp)
+		}
+	}
+}
+
 type MockDeleter struct {
 	orgID, bucketID platform.ID
 }
@@ -64,6 +702,84 @@ func (m *MockDeleter) DeleteBucket(_ context.Context, orgID, bucketID platform.I
 	return nil
 }
 
+type MockShardGroupEngine struct {
+	MockDeleter
+	durations map[platform.ID]time.Duration
+}
+
+func (m *MockShardGroupEngine) SetBucketShardGroupDuration(bucketID platform.ID, d time.Duration) {
+	if d == 0 {
+		delete(m.durations, bucketID)
+		return
+	}
+	m.durations[bucketID] = d
+}
+
+type MockSettingsEngine struct {
+	MockDeleter
+	precisions map[platform.ID]time.Duration
+	strategies map[platform.ID]string
+	budgets    map[platform.ID]uint64
+	colds      map[platform.ID]time.Duration
+	limits     map[platform.ID]int
+	retentions map[platform.ID]time.Duration
+	rollups    map[platform.ID][]platform.Rollup
+	orgs       map[platform.ID]platform.ID
+	metadata   map[platform.ID]map[string]string
+}
+
+func NewMockSettingsEngine() *MockSettingsEngine {
+	return &MockSettingsEngine{
+		precisions: make(map[platform.ID]time.Duration),
+		strategies: make(map[platform.ID]string),
+		budgets:    make(map[platform.ID]uint64),
+		colds:      make(map[platform.ID]time.Duration),
+		limits:     make(map[platform.ID]int),
+		retentions: make(map[platform.ID]time.Duration),
+		rollups:    make(map[platform.ID][]platform.Rollup),
+		orgs:       make(map[platform.ID]platform.ID),
+		metadata:   make(map[platform.ID]map[string]string),
+	}
+}
+
+func (m *MockSettingsEngine) SetBucketShardGroupDuration(bucketID platform.ID, d time.Duration) {}
+
+func (m *MockSettingsEngine) SetBucketRetentionPeriod(bucketID platform.ID, d time.Duration) {
+	m.retentions[bucketID] = d
+}
+
+func (m *MockSettingsEngine) SetBucketPrecision(bucketID platform.ID, d time.Duration) {
+	m.precisions[bucketID] = d
+}
+
+func (m *MockSettingsEngine) SetBucketCompactionStrategy(bucketID platform.ID, name string) {
+	m.strategies[bucketID] = name
+}
+
+func (m *MockSettingsEngine) SetBucketCacheBudget(bucketID platform.ID, n uint64) {
+	m.budgets[bucketID] = n
+}
+
+func (m *MockSettingsEngine) SetBucketCacheWriteColdDuration(bucketID platform.ID, d time.Duration) {
+	m.colds[bucketID] = d
+}
+
+func (m *MockSettingsEngine) SetBucketSeriesLimit(bucketID platform.ID, n int) {
+	m.limits[bucketID] = n
+}
+
+func (m *MockSettingsEngine) SetBucketRollups(bucketID platform.ID, rules []platform.Rollup) {
+	m.rollups[bucketID] = rules
+}
+
+func (m *MockSettingsEngine) SetBucketStorageOrg(bucketID, orgID platform.ID) {
+	m.orgs[bucketID] = orgID
+}
+
+func (m *MockSettingsEngine) SetBucketMetadata(bucketID platform.ID, md map[string]string) {
+	m.metadata[bucketID] = md
+}
+
 func newInMemKVSVC(t *testing.T) *kv.Service {
 	t.Helper()
 
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// BucketUsage returns the storage used by the data of a bucket. The points
+// of the bucket are counted from the headers of all of its blocks, so it is
+// more expensive than the per-bucket metrics of the engine.
+func (e *Engine) BucketUsage(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.BucketUsage, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	name := tsdb.EncodeName(e.storageOrgID(orgID, bucketID), bucketID)
+	series, err := e.index.MeasurementCardinalityStats()
+	if err != nil {
+		return nil, err
+	}
+	stats, err := e.engine.BucketDataStats(name[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &influxdb.BucketUsage{
+		BucketID:   bucketID,
+		DiskBytes:  int64(stats.DiskBytes),
+		CacheBytes: int64(stats.CacheBytes),
+		Series:     int64(series[string(name[:])]),
+		Points:     int64(stats.Points),
+	}, nil
+}
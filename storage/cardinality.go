@@ -0,0 +1,283 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// Names of the cardinality limits enforced by WritePoints.
+const (
+	LimitMaxSeriesPerBucket = "max-series-per-bucket"
+	LimitMaxValuesPerTag    = "max-values-per-tag"
+	LimitMaxSeriesPerOrg    = "org-max-series"
+)
+
+// CardinalityLimitError describes a point that was dropped because it would
+// have created a series beyond a cardinality limit.
+type CardinalityLimitError struct {
+	// Limit is the name of the limit that was reached.
+	Limit string
+
+	// Measurement is the measurement of the dropped point.
+	Measurement string
+
+	// Tag and Value are the tag that would have had too many values, if the
+	// limit is LimitMaxValuesPerTag.
+	Tag   string
+	Value string
+
+	// N is the current cardinality and Max the configured limit.
+	N, Max int
+}
+
+func (e *CardinalityLimitError) Error() string {
+	if e.Limit == LimitMaxValuesPerTag {
+		return fmt.Sprintf("%s limit exceeded (%d/%d): measurement=%q tag=%q value=%q", e.Limit, e.N, e.Max, e.Measurement, e.Tag, e.Value)
+	}
+	return fmt.Sprintf("%s limit exceeded (%d/%d): measurement=%q", e.Limit, e.N, e.Max, e.Measurement)
+}
+
+// bucketCardinality is the cardinality of a bucket as seen by a single write.
+// Counts are read from the index the first time they are needed and then
+// include the series and tag values added by the write.
+type bucketCardinality struct {
+	maxSeries int
+	seriesN   int // -1 until read from the index
+
+	valuesN   map[string]int
+	newValues map[string]map[string]struct{}
+}
+
+// orgCardinality is the number of series of an organization as seen by a
+// single write, counted like the series of a bucketCardinality.
+type orgCardinality struct {
+	maxSeries int
+	seriesN   int // -1 until read from the index
+}
+
+// limitCardinality drops the points of collection that would create a new
+// series in a bucket beyond the configured limits, and returns the error for
+// the first point dropped. It must be called under e.mu.
+//
+// The limits are checked against the index at the start of each write, so
+// concurrent writes may exceed them slightly.
+func (e *Engine) limitCardinality(collection *tsdb.SeriesCollection) (*CardinalityLimitError, error) {
+	e.retentionMu.RLock()
+	seriesLimitN := len(e.seriesLimits)
+	e.retentionMu.RUnlock()
+	if e.config.MaxSeriesPerBucket <= 0 && e.config.MaxValuesPerTag <= 0 && seriesLimitN == 0 && !e.quotas.hasSeriesQuotas() {
+		return nil, nil
+	}
+
+	var (
+		firstErr *CardinalityLimitError
+		buckets  = make(map[string]*bucketCardinality)
+		orgs     = make(map[string]*orgCardinality)
+		created  = make(map[string]struct{})
+		buf      = make([]byte, 1024)
+		j        int
+	)
+
+	for iter := collection.Iterator(); iter.Next(); {
+		name, tags, key := iter.Name(), iter.Tags(), iter.Key()
+
+		// Points of existing series, or of series already created by this
+		// write, never change the cardinality.
+		if _, ok := created[string(key)]; ok || e.seriesExists(name, tags, buf) {
+			collection.Copy(j, iter.Index())
+			j++
+			continue
+		}
+
+		b := buckets[string(name)]
+		if b == nil {
+			b = &bucketCardinality{
+				maxSeries: e.bucketSeriesLimit(name),
+				seriesN:   -1,
+				valuesN:   make(map[string]int),
+				newValues: make(map[string]map[string]struct{}),
+			}
+			buckets[string(name)] = b
+		}
+
+		o := orgs[string(name[:influxdb.OrgIDLength])]
+		if o == nil {
+			o = &orgCardinality{
+				maxSeries: e.orgSeriesLimit(name),
+				seriesN:   -1,
+			}
+			orgs[string(name[:influxdb.OrgIDLength])] = o
+		}
+
+		limitErr, err := e.checkOrgCardinality(o, name, tags)
+		if err == nil && limitErr == nil {
+			limitErr, err = e.checkCardinality(b, name, tags)
+		}
+		if err != nil {
+			return nil, err
+		} else if limitErr != nil {
+			if firstErr == nil {
+				firstErr = limitErr
+			}
+			e.writeLimits.IncLimited(limitErr.Limit)
+			collection.Invalidate(iter.Index(), limitErr.Error())
+			continue
+		}
+		o.seriesN++
+
+		created[string(key)] = struct{}{}
+		collection.Copy(j, iter.Index())
+		j++
+	}
+	collection.Truncate(j)
+
+	return firstErr, nil
+}
+
+// checkCardinality returns an error if a new series with tags would exceed a
+// limit for the bucket. Otherwise the series is added to the counts of b.
+func (e *Engine) checkCardinality(b *bucketCardinality, name []byte, tags models.Tags) (*CardinalityLimitError, error) {
+	maxSeries, maxValues := b.maxSeries, e.config.MaxValuesPerTag
+	measurement := string(tags[0].Value)
+
+	if maxSeries > 0 {
+		if b.seriesN < 0 {
+			n, err := e.bucketSeriesN(name, maxSeries)
+			if err != nil {
+				return nil, err
+			}
+			b.seriesN = n
+		}
+		if b.seriesN >= maxSeries {
+			return &CardinalityLimitError{
+				Limit:       LimitMaxSeriesPerBucket,
+				Measurement: measurement,
+				N:           b.seriesN,
+				Max:         maxSeries,
+			}, nil
+		}
+	}
+
+	// Find the tag values the series would add, skipping the measurement and
+	// field keys.
+	var added models.Tags
+	if maxValues > 0 {
+		for _, t := range tags[1 : len(tags)-1] {
+			if _, ok := b.newValues[string(t.Key)][string(t.Value)]; ok {
+				continue
+			}
+			exists, err := e.index.HasTagValue(name, t.Key, t.Value)
+			if err != nil {
+				return nil, err
+			} else if exists {
+				continue
+			}
+
+			n, ok := b.valuesN[string(t.Key)]
+			if !ok {
+				vn, err := e.tagValueN(name, t.Key, int64(maxValues))
+				if err != nil {
+					return nil, err
+				}
+				n = int(vn)
+				b.valuesN[string(t.Key)] = n
+			}
+			if n >= maxValues {
+				return &CardinalityLimitError{
+					Limit:       LimitMaxValuesPerTag,
+					Measurement: measurement,
+					Tag:         string(t.Key),
+					Value:       string(t.Value),
+					N:           n,
+					Max:         maxValues,
+				}, nil
+			}
+			added = append(added, t)
+		}
+	}
+
+	b.seriesN++
+	for _, t := range added {
+		values := b.newValues[string(t.Key)]
+		if values == nil {
+			values = make(map[string]struct{})
+			b.newValues[string(t.Key)] = values
+		}
+		values[string(t.Value)] = struct{}{}
+		b.valuesN[string(t.Key)]++
+	}
+	return nil, nil
+}
+
+// checkOrgCardinality returns an error if a new series with tags would exceed
+// the max-series quota of the organization of the bucket name.
+func (e *Engine) checkOrgCardinality(o *orgCardinality, name []byte, tags models.Tags) (*CardinalityLimitError, error) {
+	if o.maxSeries <= 0 {
+		return nil, nil
+	}
+	if o.seriesN < 0 {
+		n, err := e.orgSeriesN(name)
+		if err != nil {
+			return nil, err
+		}
+		o.seriesN = n
+	}
+	if o.seriesN < o.maxSeries {
+		return nil, nil
+	}
+
+	orgID, _ := tsdb.DecodeNameSlice(name)
+	e.quotaStats.IncExceeded(orgID, influxdb.QuotaMaxSeries, 1)
+	return &CardinalityLimitError{
+		Limit:       LimitMaxSeriesPerOrg,
+		Measurement: string(tags[0].Value),
+		N:           o.seriesN,
+		Max:         o.maxSeries,
+	}, nil
+}
+
+// bucketSeriesLimit returns the series limit of the bucket name, or the limit
+// configured for every bucket if it has none of its own.
+func (e *Engine) bucketSeriesLimit(name []byte) int {
+	_, bucketID := tsdb.DecodeNameSlice(name)
+	e.retentionMu.RLock()
+	defer e.retentionMu.RUnlock()
+	if n, ok := e.seriesLimits[bucketID]; ok {
+		return n
+	}
+	return e.config.MaxSeriesPerBucket
+}
+
+// seriesExists returns true if the series is in the series file and has not
+// been deleted.
+func (e *Engine) seriesExists(name []byte, tags models.Tags, buf []byte) bool {
+	id := e.sfile.SeriesID(name, tags, buf)
+	return !id.IsZero() && !e.sfile.IsDeleted(id)
+}
+
+// bucketSeriesN returns the number of series of the bucket name in the index,
+// up to limit.
+func (e *Engine) bucketSeriesN(name []byte, limit int) (int, error) {
+	itr, err := e.index.MeasurementSeriesIDIterator(name)
+	if err != nil {
+		return 0, err
+	} else if itr == nil {
+		return 0, nil
+	}
+	defer itr.Close()
+
+	var n int
+	for n < limit {
+		elem, err := itr.Next()
+		if err != nil {
+			return 0, err
+		} else if elem.SeriesID.IsZero() {
+			break
+		}
+		n++
+	}
+	return n, nil
+}
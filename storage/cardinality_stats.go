@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/pkg/fs"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// cardinalityStatsVersion is the version of the persisted cardinality file
+// format.
+const cardinalityStatsVersion = 1
+
+// measurementCardinalityCounter holds the sketches of the series and tag values
+// of a single measurement within a bucket.
+type measurementCardinalityCounter struct {
+	orgID, bucketID influxdb.ID
+	measurement     string
+
+	series *hll.Plus
+	tags   map[string]*hll.Plus
+}
+
+func newMeasurementCardinalityCounter(orgID, bucketID influxdb.ID, measurement string) *measurementCardinalityCounter {
+	return &measurementCardinalityCounter{
+		orgID:       orgID,
+		bucketID:    bucketID,
+		measurement: measurement,
+		series:      hll.NewDefaultPlus(),
+		tags:        make(map[string]*hll.Plus),
+	}
+}
+
+// add adds a series with key and tags to the sketches. The measurement and
+// field key tags are skipped.
+func (c *measurementCardinalityCounter) add(key []byte, tags models.Tags) {
+	c.series.Add(key)
+	if len(tags) < 2 {
+		return
+	}
+	for _, t := range tags[1 : len(tags)-1] {
+		s := c.tags[string(t.Key)]
+		if s == nil {
+			s = hll.NewDefaultPlus()
+			c.tags[string(t.Key)] = s
+		}
+		s.Add(t.Value)
+	}
+}
+
+// cardinality returns the public representation of the counter.
+func (c *measurementCardinalityCounter) cardinality() *influxdb.MeasurementCardinality {
+	mc := &influxdb.MeasurementCardinality{
+		OrgID:       c.orgID,
+		BucketID:    c.bucketID,
+		Measurement: c.measurement,
+		Series:      c.series.Count(),
+		Tags:        make([]influxdb.TagKeyCardinality, 0, len(c.tags)),
+	}
+	for k, s := range c.tags {
+		mc.Tags = append(mc.Tags, influxdb.TagKeyCardinality{Key: k, Values: s.Count()})
+	}
+	sort.Slice(mc.Tags, func(i, j int) bool {
+		a, b := mc.Tags[i], mc.Tags[j]
+		if a.Values != b.Values {
+			return a.Values > b.Values
+		}
+		return a.Key < b.Key
+	})
+	return mc
+}
+
+// cardinalityTracker estimates the series cardinality of each measurement, and
+// the number of values of each of its tag keys, from the series written to
+// it. HyperLogLog sketches are used so memory use is bounded regardless of
+// cardinality.
+type cardinalityTracker struct {
+	mu       sync.Mutex
+	path     string
+	counters map[string]*measurementCardinalityCounter
+}
+
+func newCardinalityTracker(path string) *cardinalityTracker {
+	return &cardinalityTracker{
+		path:     path,
+		counters: make(map[string]*measurementCardinalityCounter),
+	}
+}
+
+// add adds the series with key to the sketches of its measurement. It must be
+// called with t.mu held.
+func (t *cardinalityTracker) add(name []byte, tags models.Tags, key []byte) {
+	// Only series carrying an encoded org and bucket name, and a measurement
+	// tag, can be attributed.
+	if len(name) != len(tsdb.EncodeName(0, 0)) || tags.Len() == 0 || !bytes.Equal(tags[0].Key, models.MeasurementTagKeyBytes) {
+		return
+	}
+
+	k := string(name) + string(tags[0].Value)
+	c := t.counters[k]
+	if c == nil {
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		c = newMeasurementCardinalityCounter(orgID, bucketID, string(tags[0].Value))
+		t.counters[k] = c
+	}
+	c.add(key, tags)
+}
+
+// Record adds the series of the points in collection to the sketches.
+func (t *cardinalityTracker) Record(collection *tsdb.SeriesCollection) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	var buf []byte
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for iter := collection.Iterator(); iter.Next(); {
+		name, tags := iter.Name(), iter.Tags()
+		buf = tsdb.AppendSeriesKey(buf[:0], name, tags)
+		t.add(name, tags, buf)
+	}
+}
+
+// RecordSeriesKey adds the series with the series file key to the sketches.
+func (t *cardinalityTracker) RecordSeriesKey(key []byte) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	name, tags := tsdb.ParseSeriesKey(key)
+	t.mu.Lock()
+	t.add(name, tags, key)
+	t.mu.Unlock()
+}
+
+// Cardinality returns the estimates matching filter, ranked by series in
+// descending order.
+func (t *cardinalityTracker) Cardinality(filter influxdb.CardinalityFilter) []*influxdb.MeasurementCardinality {
+	if t == nil {
+		return nil // Tracking disabled
+	}
+
+	t.mu.Lock()
+	cards := make([]*influxdb.MeasurementCardinality, 0, len(t.counters))
+	for _, c := range t.counters {
+		if filter.OrgID != nil && *filter.OrgID != c.orgID {
+			continue
+		}
+		if filter.BucketID != nil && *filter.BucketID != c.bucketID {
+			continue
+		}
+		if filter.Measurement != "" && filter.Measurement != c.measurement {
+			continue
+		}
+		cards = append(cards, c.cardinality())
+	}
+	t.mu.Unlock()
+
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := cards[i], cards[j]
+		if a.Series != b.Series {
+			return a.Series > b.Series
+		}
+		if a.BucketID != b.BucketID {
+			return a.BucketID < b.BucketID
+		}
+		return a.Measurement < b.Measurement
+	})
+
+	if filter.Limit > 0 && len(cards) > filter.Limit {
+		cards = cards[:filter.Limit]
+	}
+	return cards
+}
+
+// DeleteBucket removes the sketches of all measurements of the bucket.
+func (t *cardinalityTracker) DeleteBucket(orgID, bucketID influxdb.ID) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	prefix := tsdb.EncodeNameString(orgID, bucketID)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k := range t.counters {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			delete(t.counters, k)
+		}
+	}
+}
+
+// persistedCardinalityStats is the on-disk representation of the tracker.
+type persistedCardinalityStats struct {
+	Version      int                               `json:"version"`
+	Time         time.Time                         `json:"time"`
+	Measurements []persistedMeasurementCardinality `json:"measurements"`
+}
+
+type persistedMeasurementCardinality struct {
+	OrgID       influxdb.ID       `json:"orgID"`
+	BucketID    influxdb.ID       `json:"bucketID"`
+	Measurement string            `json:"measurement"`
+	Series      []byte            `json:"series"`
+	Tags        map[string][]byte `json:"tags"`
+}
+
+// Save writes the tracker's sketches to its path, replacing any existing file.
+func (t *cardinalityTracker) Save() error {
+	if t == nil {
+		return nil // Tracking disabled
+	}
+
+	t.mu.Lock()
+	ps := persistedCardinalityStats{
+		Version:      cardinalityStatsVersion,
+		Time:         time.Now().UTC(),
+		Measurements: make([]persistedMeasurementCardinality, 0, len(t.counters)),
+	}
+	for _, c := range t.counters {
+		pm := persistedMeasurementCardinality{
+			OrgID:       c.orgID,
+			BucketID:    c.bucketID,
+			Measurement: c.measurement,
+			Tags:        make(map[string][]byte, len(c.tags)),
+		}
+		var err error
+		if pm.Series, err = c.series.MarshalBinary(); err != nil {
+			t.mu.Unlock()
+			return err
+		}
+		for k, s := range c.tags {
+			if pm.Tags[k], err = s.MarshalBinary(); err != nil {
+				t.mu.Unlock()
+				return err
+			}
+		}
+		ps.Measurements = append(ps.Measurements, pm)
+	}
+	t.mu.Unlock()
+
+	data, err := json.Marshal(ps)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := t.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0666); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(tmpPath, t.path)
+}
+
+// Load reads the tracker's sketches from its path. It returns false if there
+// is no file to read.
+func (t *cardinalityTracker) Load() (bool, error) {
+	if t == nil {
+		return false, nil // Tracking disabled
+	}
+
+	data, err := ioutil.ReadFile(t.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	var ps persistedCardinalityStats
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return false, fmt.Errorf("unable to decode cardinality file %q: %v", t.path, err)
+	} else if ps.Version != cardinalityStatsVersion {
+		return false, fmt.Errorf("incompatible cardinality file version: %d", ps.Version)
+	}
+
+	counters := make(map[string]*measurementCardinalityCounter, len(ps.Measurements))
+	for _, m := range ps.Measurements {
+		c := newMeasurementCardinalityCounter(m.OrgID, m.BucketID, m.Measurement)
+		if err := c.series.UnmarshalBinary(m.Series); err != nil {
+			return false, fmt.Errorf("unable to decode series sketch for measurement %q: %v", m.Measurement, err)
+		}
+		for k, data := range m.Tags {
+			s := hll.NewDefaultPlus()
+			if err := s.UnmarshalBinary(data); err != nil {
+				return false, fmt.Errorf("unable to decode sketch for tag %q of measurement %q: %v", k, m.Measurement, err)
+			}
+			c.tags[k] = s
+		}
+		counters[tsdb.EncodeNameString(m.OrgID, m.BucketID)+m.Measurement] = c
+	}
+
+	t.mu.Lock()
+	t.counters = counters
+	t.mu.Unlock()
+	return true, nil
+}
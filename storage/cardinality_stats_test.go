@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestCardinalityTracker(t *testing.T) {
+	org, bucket := influxdb.ID(0x3131), influxdb.ID(0x3232)
+	tracker := newCardinalityTracker("")
+
+	tracker.Record(writeStatsCollection(t, org, bucket, "cpu,host=a,region=east v=1 1\ncpu,host=b,region=east v=1 1\ncpu,host=c,region=west v=1 1\nmem,host=a v=1 1"))
+	tracker.Record(writeStatsCollection(t, org, bucket, "cpu,host=a,region=east v=2 2\ncpu,host=a,region=east w=2 2"))
+
+	cards := tracker.Cardinality(influxdb.CardinalityFilter{})
+	if got, exp := len(cards), 2; got != exp {
+		t.Fatalf("got %d measurements, expected %d", got, exp)
+	}
+
+	exp := &influxdb.MeasurementCardinality{
+		OrgID:       org,
+		BucketID:    bucket,
+		Measurement: "cpu",
+		Series:      4,
+		Tags: []influxdb.TagKeyCardinality{
+			{Key: "host", Values: 3},
+			{Key: "region", Values: 2},
+		},
+	}
+	if !reflect.DeepEqual(cards[0], exp) {
+		t.Fatalf("got %+v, expected %+v", cards[0], exp)
+	}
+	if got, exp := cards[1].Measurement, "mem"; got != exp {
+		t.Fatalf("got measurement %q, expected %q", got, exp)
+	}
+
+	cards = tracker.Cardinality(influxdb.CardinalityFilter{Measurement: "mem"})
+	if len(cards) != 1 || cards[0].Series != 1 {
+		t.Fatalf("unexpected cardinality for measurement filter: %+v", cards)
+	}
+	if cards := tracker.Cardinality(influxdb.CardinalityFilter{Limit: 1}); len(cards) != 1 || cards[0].Measurement != "cpu" {
+		t.Fatalf("unexpected cardinality with limit: %+v", cards)
+	}
+}
+
+func TestCardinalityTracker_RecordSeriesKey(t *testing.T) {
+	org, bucket := influxdb.ID(0x3131), influxdb.ID(0x3232)
+	collection := writeStatsCollection(t, org, bucket, "cpu,host=a v=1 1\ncpu,host=b v=1 1")
+
+	// Series read back from the series file are counted as the same series as
+	// those written.
+	tracker := newCardinalityTracker("")
+	tracker.Record(collection)
+	for iter := collection.Iterator(); iter.Next(); {
+		tracker.RecordSeriesKey(tsdb.AppendSeriesKey(nil, iter.Name(), iter.Tags()))
+	}
+
+	cards := tracker.Cardinality(influxdb.CardinalityFilter{})
+	if len(cards) != 1 || cards[0].Series != 2 || cards[0].Tags[0].Values != 2 {
+		t.Fatalf("unexpected cardinality: %+v", cards)
+	}
+}
+
+func TestCardinalityTracker_DeleteBucket(t *testing.T) {
+	tracker := newCardinalityTracker("")
+	tracker.Record(writeStatsCollection(t, 1, 2, "cpu v=1 1"))
+	tracker.Record(writeStatsCollection(t, 1, 3, "cpu v=1 1"))
+
+	tracker.DeleteBucket(1, 2)
+	cards := tracker.Cardinality(influxdb.CardinalityFilter{})
+	if len(cards) != 1 || cards[0].BucketID != 3 {
+		t.Fatalf("unexpected cardinality after bucket deletion: %+v", cards)
+	}
+}
+
+func TestCardinalityTracker_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cardinality")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, DefaultCardinalityFileName)
+	tracker := newCardinalityTracker(path)
+	tracker.Record(writeStatsCollection(t, 1, 2, "cpu,host=a v=1 1\ncpu,host=b v=1 1\nmem v=1 1"))
+	if err := tracker.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	other := newCardinalityTracker(path)
+	if ok, err := other.Load(); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("expected cardinality file to be loaded")
+	}
+
+	exp := tracker.Cardinality(influxdb.CardinalityFilter{})
+	got := other.Cardinality(influxdb.CardinalityFilter{})
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %+v, expected %+v", got, exp)
+	}
+
+	// Series already seen before the restart are not counted again.
+	other.Record(writeStatsCollection(t, 1, 2, "cpu,host=a v=2 2"))
+	if got := other.Cardinality(influxdb.CardinalityFilter{Measurement: "cpu"}); got[0].Series != 2 {
+		t.Fatalf("unexpected cardinality after reload: %+v", got[0])
+	}
+
+	// A missing file is not an error.
+	if ok, err := newCardinalityTracker(filepath.Join(dir, "missing")).Load(); err != nil || ok {
+		t.Fatalf("got loaded %v and error %v for missing file", ok, err)
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/fs"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+// Checkpoint writes the contents of the cache to TSM files and removes the WAL
+// segments they cover, so that the WAL does not need to be replayed when the
+// engine is next opened. If a snapshot is already in progress, Checkpoint waits
+// for it and then snapshots any data written since.
+//
+// The returned ID covers every write accepted by the engine before Checkpoint
+// was called. It is recorded on disk before Checkpoint returns, so IDs taken
+// after a restart are never smaller than those taken before it.
+func (e *Engine) Checkpoint(ctx context.Context) (influxdb.CheckpointID, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	closing := e.closing
+	e.mu.RUnlock()
+	if closing == nil {
+		return 0, ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return 0, ErrEngineReadOnly
+	}
+
+	e.checkpointMu.Lock()
+	defer e.checkpointMu.Unlock()
+
+	// Writes are counted once they are in the cache, so every write counted
+	// here is included in the snapshot below.
+	id := influxdb.CheckpointID(atomic.LoadUint64(&e.writeN))
+
+	for {
+		err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusDrain)
+		if err == nil {
+			break
+		} else if err != tsm1.ErrSnapshotInProgress {
+			return 0, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-closing:
+			return 0, ErrEngineClosed
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	if id != e.lastCheckpoint {
+		if err := e.saveCheckpoint(id); err != nil {
+			return 0, err
+		}
+		e.lastCheckpoint = id
+	}
+	span.LogKV("checkpoint_id", uint64(id))
+	return id, nil
+}
+
+// LastCheckpoint returns the ID of the most recent checkpoint, which may have
+// been taken before the engine was last opened.
+func (e *Engine) LastCheckpoint() influxdb.CheckpointID {
+	e.checkpointMu.Lock()
+	defer e.checkpointMu.Unlock()
+	return e.lastCheckpoint
+}
+
+// loadCheckpoint reads the ID of the last checkpoint and continues counting
+// writes from it. A missing file is not an error.
+func (e *Engine) loadCheckpoint() error {
+	path := e.config.GetCheckpointPath(e.path)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to decode checkpoint file %q: %v", path, err)
+	}
+
+	e.checkpointMu.Lock()
+	e.lastCheckpoint = influxdb.CheckpointID(id)
+	e.checkpointMu.Unlock()
+	atomic.StoreUint64(&e.writeN, id)
+
+	e.logger.Info("Loaded checkpoint", zap.Uint64("checkpoint_id", id))
+	return nil
+}
+
+// saveCheckpoint durably records id as the last checkpoint.
+func (e *Engine) saveCheckpoint(id influxdb.CheckpointID) error {
+	path := e.config.GetCheckpointPath(e.path)
+	tmpPath := path + ".tmp"
+
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%d\n", uint64(id)); err != nil {
+		return err
+	} else if err := f.Sync(); err != nil {
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(tmpPath, path)
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+var _ influxdb.CompactionService = (*Engine)(nil)
+
+// CompactionSettings returns the current compaction settings of the engine.
+func (e *Engine) CompactionSettings(ctx context.Context) (*influxdb.CompactionSettings, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s := e.engine.CompactionSettings()
+	return &s, nil
+}
+
+// UpdateCompactionSettings changes the compaction settings of the running engine.
+// The settings are not persisted, so the configured settings are used again
+// when the engine is next opened.
+func (e *Engine) UpdateCompactionSettings(ctx context.Context, upd influxdb.CompactionSettingsUpdate) (*influxdb.CompactionSettings, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s := e.engine.CompactionSettings()
+	upd.Apply(&s)
+	if err := e.engine.SetCompactionSettings(s); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+
+	s = e.engine.CompactionSettings()
+	return &s, nil
+}
+
+// CompactBucket snapshots the cache and fully compacts the TSM files containing
+// data for the bucket. It blocks until the compaction has finished or ctx is
+// done.
+func (e *Engine) CompactBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return ErrEngineReadOnly
+	}
+
+	encoded := tsdb.EncodeName(e.storageOrgID(orgID, bucketID), bucketID)
+	name := models.EscapeMeasurement(encoded[:])
+	return e.engine.CompactPrefix(ctx, name)
+}
+
+// TombstoneStats returns the deleted data held in the TSM files of the engine,
+// ordered with the files holding the most deleted data first.
+func (e *Engine) TombstoneStats(ctx context.Context) (*influxdb.TombstoneStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	ts := e.engine.TombstoneStats()
+	stats := &influxdb.TombstoneStats{
+		Files:                make([]*influxdb.FileTombstoneStats, 0, len(ts.Files)),
+		TombstonedValuesRead: ts.TombstonedValuesRead,
+		TombstonedBlocksRead: ts.TombstonedBlocksRead,
+	}
+	for _, f := range ts.Files {
+		stats.Files = append(stats.Files, &influxdb.FileTombstoneStats{
+			Path:             f.Path,
+			SizeBytes:        uint64(f.Size),
+			TombstoneBytes:   uint64(f.TombstoneSize),
+			TombstonedKeys:   f.TombstonedKeys,
+			TombstonedBlocks: f.TombstonedBlocks,
+			TombstonedBytes:  f.TombstonedBytes,
+		})
+	}
+	sort.SliceStable(stats.Files, func(i, j int) bool {
+		return stats.Files[i].TombstonedBytes > stats.Files[j].TombstonedBytes
+	})
+	return stats, nil
+}
@@ -12,11 +12,27 @@ import (
 
 // Default configuration values.
 const (
-	DefaultRetentionInterval       = time.Hour
-	DefaultSeriesFileDirectoryName = "_series"
-	DefaultIndexDirectoryName      = "index"
-	DefaultWALDirectoryName        = "wal"
-	DefaultEngineDirectoryName     = "data"
+	DefaultRetentionInterval          = time.Hour
+	DefaultWriteStatsInterval         = 10 * time.Minute
+	DefaultShardStatsInterval         = time.Minute
+	DefaultCardinalityInterval        = 10 * time.Minute
+	DefaultReadOnlyRefreshInterval    = 30 * time.Second
+	DefaultTombstoneCheckInterval     = time.Hour
+	DefaultTombstoneThreshold         = 0.25
+	DefaultSeriesFileCompactInterval  = time.Hour
+	DefaultSeriesFileCompactThreshold = 0.25
+	DefaultIdempotencyKeyTTL          = 10 * time.Minute
+	DefaultMaxIdempotencyKeys         = 100000
+	DefaultMaxDedupePoints            = 1000000
+	DefaultRollupDelay                = 10 * time.Second
+	DefaultSeriesFileDirectoryName    = "_series"
+	DefaultIndexDirectoryName         = "index"
+	DefaultWALDirectoryName           = "wal"
+	DefaultEngineDirectoryName        = "data"
+	DefaultWriteStatsFileName         = "write_stats"
+	DefaultCheckpointFileName         = "checkpoint"
+	DefaultCardinalityFileName        = "cardinality"
+	DefaultIdempotencyKeysFileName    = "idempotency_keys"
 )
 
 // Config holds the configuration for an Engine.
@@ -24,6 +40,97 @@ type Config struct {
 	// Frequency of retention in seconds.
 	RetentionInterval toml.Duration `toml:"retention-interval"`
 
+	// Length of each per-measurement write stats interval. The stats are
+	// persisted at the end of every interval. A value of 0 disables tracking.
+	WriteStatsInterval toml.Duration `toml:"write-stats-interval"`
+
+	// Length of each per-shard load tracking interval. Write throughput and
+	// cursor latency are reported for the last completed interval. A value of
+	// 0 disables tracking.
+	ShardStatsInterval toml.Duration `toml:"shard-stats-interval"`
+
+	// Keys of the metadata of buckets whose values label the per-bucket
+	// metrics of disk usage, series and write throughput.
+	BucketMetricLabels []string `toml:"bucket-metric-labels"`
+
+	// How often the per-measurement series cardinality sketches are persisted
+	// with the index. A value of 0 disables tracking.
+	CardinalityInterval toml.Duration `toml:"cardinality-interval"`
+
+	// How often TSM files are checked for data removed by deletes. A value of
+	// 0 disables the check.
+	TombstoneCheckInterval toml.Duration `toml:"tombstone-check-interval"`
+
+	// The fraction of a TSM file's size, between 0 and 1, taken by deleted
+	// data at which the file is rewritten to reclaim the space.
+	TombstoneThreshold float64 `toml:"tombstone-threshold"`
+
+	// How often the series file is checked for deleted series. A value of 0
+	// disables the check.
+	SeriesFileCompactInterval toml.Duration `toml:"series-file-compact-interval"`
+
+	// The fraction of the series file's segments, between 0 and 1, taken by
+	// deleted series at which they are rewritten to remove them.
+	SeriesFileCompactThreshold float64 `toml:"series-file-compact-threshold"`
+
+	// Points more than this far in the future are dropped by WritePoints. A
+	// value of 0 accepts points at any time in the future.
+	FutureWriteTolerance toml.Duration `toml:"future-write-tolerance"`
+
+	// The maximum number of series a bucket can have. Points that would
+	// create a new series beyond it are dropped. A value of 0 disables the
+	// limit.
+	MaxSeriesPerBucket int `toml:"max-series-per-bucket"`
+
+	// The maximum number of values a tag key can have in a bucket. Points
+	// that would add a value beyond it are dropped. A value of 0 disables the
+	// limit.
+	MaxValuesPerTag int `toml:"max-values-per-tag"`
+
+	// How long the idempotency key of a write is remembered. A batch written
+	// to a bucket with the key of a batch written to it within this period is
+	// dropped. The keys are recorded with the engine, so they are remembered
+	// across restarts. A value of 0 disables idempotency keys.
+	IdempotencyKeyTTL toml.Duration `toml:"idempotency-key-ttl"`
+
+	// The maximum number of idempotency keys remembered. The oldest keys are
+	// forgotten first. A value of 0 disables the limit.
+	MaxIdempotencyKeys int `toml:"max-idempotency-keys"`
+
+	// How long written field values are remembered. A point whose field
+	// values are those last written to the same series, fields and time within
+	// this period is dropped. A value of 0 disables deduplication.
+	DedupeWindow toml.Duration `toml:"dedupe-window"`
+
+	// The maximum number of field values remembered for deduplication. The
+	// oldest values are forgotten first. A value of 0 disables the limit.
+	MaxDedupePoints int `toml:"max-dedupe-points"`
+
+	// How long after the end of a window of a bucket rollup its aggregate is
+	// written to the target bucket. Points written to the window afterwards
+	// are not rolled up.
+	RollupDelay toml.Duration `toml:"rollup-delay"`
+
+	// Path to a file of keys used to encrypt TSM and WAL data at rest. The
+	// key with the highest ID encrypts new data, and existing files are
+	// encrypted with it in the background. An empty path disables encryption.
+	EncryptionKeyFile string `toml:"encryption-key-file"`
+
+	// Faults to inject into the engine's file operations, for testing
+	// recovery procedures. See fault.Parse for the format. Never set this on
+	// a server holding data you want to keep.
+	FaultInjection string `toml:"fault-injection"`
+
+	// Opens the series file, index and TSM files read-only. Writes, deletes
+	// and compactions are rejected, so that the engine can serve reads from a
+	// snapshot or a volume replicated from another server.
+	ReadOnly bool `toml:"read-only"`
+
+	// How often a read-only engine checks its files for changes made outside
+	// of it. Changed files are reopened once they have stopped changing for a
+	// whole interval. A value of 0 disables refreshing.
+	ReadOnlyRefreshInterval toml.Duration `toml:"read-only-refresh-interval"`
+
 	// Series file config.
 	SeriesFilePath string `toml:"series-file-path"` // Overrides the default path.
 
@@ -46,11 +153,23 @@ type Config struct {
 // NewConfig initialises a new config for an Engine.
 func NewConfig() Config {
 	return Config{
-		RetentionInterval: toml.Duration(DefaultRetentionInterval),
-		TSDB:              tsdb.NewConfig(),
-		WAL:               tsm1.NewWALConfig(),
-		Engine:            tsm1.NewConfig(),
-		Index:             tsi1.NewConfig(),
+		RetentionInterval:          toml.Duration(DefaultRetentionInterval),
+		WriteStatsInterval:         toml.Duration(DefaultWriteStatsInterval),
+		ShardStatsInterval:         toml.Duration(DefaultShardStatsInterval),
+		CardinalityInterval:        toml.Duration(DefaultCardinalityInterval),
+		ReadOnlyRefreshInterval:    toml.Duration(DefaultReadOnlyRefreshInterval),
+		TombstoneCheckInterval:     toml.Duration(DefaultTombstoneCheckInterval),
+		TombstoneThreshold:         DefaultTombstoneThreshold,
+		SeriesFileCompactInterval:  toml.Duration(DefaultSeriesFileCompactInterval),
+		SeriesFileCompactThreshold: DefaultSeriesFileCompactThreshold,
+		IdempotencyKeyTTL:          toml.Duration(DefaultIdempotencyKeyTTL),
+		MaxIdempotencyKeys:         DefaultMaxIdempotencyKeys,
+		MaxDedupePoints:            DefaultMaxDedupePoints,
+		RollupDelay:                toml.Duration(DefaultRollupDelay),
+		TSDB:                       tsdb.NewConfig(),
+		WAL:                        tsm1.NewWALConfig(),
+		Engine:                     tsm1.NewConfig(),
+		Index:                      tsi1.NewConfig(),
 	}
 }
 
@@ -85,3 +204,25 @@ func (c Config) GetEnginePath(base string) string {
 	}
 	return filepath.Join(base, DefaultEngineDirectoryName)
 }
+
+// GetWriteStatsPath returns the path to the persisted write stats file.
+func (c Config) GetWriteStatsPath(base string) string {
+	return filepath.Join(base, DefaultWriteStatsFileName)
+}
+
+// GetCardinalityPath returns the path to the persisted cardinality sketches,
+// which are kept with the index.
+func (c Config) GetCardinalityPath(base string) string {
+	return filepath.Join(c.GetIndexPath(base), DefaultCardinalityFileName)
+}
+
+// GetIdempotencyKeysPath returns the path to the file recording the
+// idempotency keys of recent writes.
+func (c Config) GetIdempotencyKeysPath(base string) string {
+	return filepath.Join(base, DefaultIdempotencyKeysFileName)
+}
+
+// GetCheckpointPath returns the path to the file recording the last checkpoint.
+func (c Config) GetCheckpointPath(base string) string {
+	return filepath.Join(base, DefaultCheckpointFileName)
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// dedupedValue is the value last written to a field of a series at a time.
+type dedupedValue struct {
+	value string
+	seq   uint64
+}
+
+// dedupedField is a field value written within the dedupe window.
+type dedupedField struct {
+	key string
+	at  int64
+	seq uint64
+}
+
+// dedupeWindow records the field values written recently, so that points
+// whose field values are those last written to the same series, fields and
+// time within the window are dropped. A point overwriting a value with another
+// value is never dropped. The values are held in memory, so they are forgotten
+// when the engine is closed.
+type dedupeWindow struct {
+	window time.Duration
+	max    int
+
+	mu     sync.Mutex
+	values map[string]dedupedValue
+	order  []dedupedField // in the order the values were written, from head
+	head   int
+	seq    uint64
+	key    []byte
+	value  []byte
+}
+
+func newDedupeWindow(window time.Duration, max int) *dedupeWindow {
+	return &dedupeWindow{
+		window: window,
+		max:    max,
+		values: make(map[string]dedupedValue),
+	}
+}
+
+// enabled reports whether duplicate points are dropped.
+func (d *dedupeWindow) enabled() bool {
+	return d.window > 0
+}
+
+// duplicate reports whether every field value of p is the value last written
+// to its field within the window before now.
+func (d *dedupeWindow) duplicate(p models.Point, now time.Time) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expire(now)
+
+	var n int
+	for iter := p.FieldIterator(); iter.Next(); n++ {
+		d.appendKey(p, iter)
+		v, ok := d.values[string(d.key)]
+		if !ok || v.value != string(d.appendValue(iter)) {
+			return false
+		}
+	}
+	return n > 0
+}
+
+// record records the field values of the points of collection as written at
+// now.
+func (d *dedupeWindow) record(collection *tsdb.SeriesCollection, now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	at := now.UnixNano()
+	for iter := collection.Iterator(); iter.Next(); {
+		p := iter.Point()
+		for fi := p.FieldIterator(); fi.Next(); {
+			d.appendKey(p, fi)
+			d.appendValue(fi)
+			if v, ok := d.values[string(d.key)]; ok && v.value == string(d.value) {
+				continue
+			}
+
+			d.seq++
+			key := string(d.key)
+			d.values[key] = dedupedValue{value: string(d.value), seq: d.seq}
+			d.order = append(d.order, dedupedField{key: key, at: at, seq: d.seq})
+		}
+	}
+	d.expire(now)
+}
+
+// expire forgets the values written before now-window, and the oldest values
+// beyond max. It must be called with mu held.
+func (d *dedupeWindow) expire(now time.Time) {
+	min := now.Add(-d.window).UnixNano()
+
+	for ; d.head < len(d.order); d.head++ {
+		f := d.order[d.head]
+		if f.at >= min && (d.max <= 0 || len(d.order)-d.head <= d.max) {
+			break
+		}
+		// The field may have been overwritten since, in which case the
+		// later value is forgotten with its own entry.
+		if v := d.values[f.key]; v.seq == f.seq {
+			delete(d.values, f.key)
+		}
+		d.order[d.head] = dedupedField{}
+	}
+
+	// The forgotten entries are reclaimed once they are half of the slice, so
+	// each entry is copied at most once on average.
+	if d.head > 0 && d.head >= len(d.order)/2 {
+		n := copy(d.order, d.order[d.head:])
+		d.order = d.order[:n]
+		d.head = 0
+	}
+}
+
+// appendKey sets key to the identity of the current field of p: its series
+// key, field key and time.
+func (d *dedupeWindow) appendKey(p models.Point, iter models.FieldIterator) {
+	d.key = append(d.key[:0], p.Key()...)
+	d.key = append(d.key, 0)
+	d.key = append(d.key, iter.FieldKey()...)
+	d.key = append(d.key, 0)
+	d.key = appendUint64(d.key, uint64(p.UnixNano()))
+}
+
+// appendValue sets value to the type and value of the current field of iter.
+func (d *dedupeWindow) appendValue(iter models.FieldIterator) []byte {
+	d.value = append(d.value[:0], byte(iter.Type()))
+	switch iter.Type() {
+	case models.Float:
+		v, _ := iter.FloatValue()
+		d.value = appendUint64(d.value, math.Float64bits(v))
+	case models.Integer:
+		v, _ := iter.IntegerValue()
+		d.value = appendUint64(d.value, uint64(v))
+	case models.Unsigned:
+		v, _ := iter.UnsignedValue()
+		d.value = appendUint64(d.value, v)
+	case models.Boolean:
+		if v, _ := iter.BooleanValue(); v {
+			d.value = append(d.value, 1)
+		}
+	case models.String:
+		d.value = append(d.value, iter.StringValue()...)
+	}
+	return d.value
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestDedupeWindow(t *testing.T) {
+	d := newDedupeWindow(time.Minute, 2)
+	now := time.Unix(0, 0)
+
+	p := func(host string, v float64) models.Point {
+		return models.MustNewPoint(
+			"cpu",
+			models.NewTags(map[string]string{"host": host}),
+			map[string]interface{}{"value": v},
+			time.Unix(1, 0),
+		)
+	}
+	check := func(p models.Point, now time.Time, exp bool) {
+		t.Helper()
+		if got := d.duplicate(p, now); got != exp {
+			t.Fatalf("%s: got duplicate %v, expected %v", p, got, exp)
+		}
+	}
+
+	d.record(tsdb.NewSeriesCollection([]models.Point{p("a", 1)}), now)
+	check(p("a", 1), now.Add(30*time.Second), true)
+
+	// Points with other values or series keys are not duplicates.
+	check(p("a", 2), now, false)
+	check(p("b", 1), now, false)
+
+	// The oldest points are forgotten beyond the limit, and all points after
+	// the window.
+	d.record(tsdb.NewSeriesCollection([]models.Point{p("b", 1), p("c", 1)}), now)
+	check(p("a", 1), now, false)
+	check(p("b", 1), now, true)
+	check(p("c", 1), now.Add(2*time.Minute), false)
+
+	// A point overwriting a value is not a duplicate of the value it
+	// overwrote.
+	d.record(tsdb.NewSeriesCollection([]models.Point{p("d", 1)}), now)
+	d.record(tsdb.NewSeriesCollection([]models.Point{p("d", 2)}), now)
+	check(p("d", 2), now, true)
+	check(p("d", 1), now, false)
+	d.record(tsdb.NewSeriesCollection([]models.Point{p("d", 1)}), now)
+	check(p("d", 1), now, true)
+	check(p("d", 2), now, false)
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb"
@@ -17,7 +18,10 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/fault"
 	"github.com/influxdata/influxdb/pkg/limiter"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
@@ -61,6 +65,68 @@ type Engine struct {
 	retentionEnforcer        runner
 	retentionEnforcerLimiter runnable
 
+	writeStats  *writeStatsTracker
+	writeLimits *writeLimitTracker
+	quotas      *orgQuotas
+	quotaStats  *quotaTracker
+	shardStats  *shardStatsTracker
+	cardinality *cardinalityTracker
+	seriesMoves *seriesMoves
+	rebuilds    *indexRebuilds
+	idempotency *idempotencyKeys
+	dedupe      *dedupeWindow
+	rollups     *rollups
+
+	// rollupFinder, if set, provides the rollup rules of the buckets when
+	// the engine is opened.
+	rollupFinder BucketFinder
+
+	// walRecovery reports on the replay of the WAL when the engine was last
+	// opened. It is guarded by mu.
+	walRecovery *influxdb.WALRecoveryReport
+
+	// writeN counts the write batches accepted by the engine. It must be
+	// accessed atomically.
+	writeN uint64
+
+	checkpointMu   sync.Mutex // serializes checkpoints
+	lastCheckpoint influxdb.CheckpointID
+
+	// retentionPeriods holds the retention period of each bucket, used to
+	// drop writes that would be deleted by the next retention check.
+	retentionMu      sync.RWMutex
+	retentionPeriods map[influxdb.ID]time.Duration
+
+	// precisions holds the precision of each bucket with a precision coarser
+	// than a nanosecond. It is guarded by retentionMu.
+	precisions map[influxdb.ID]time.Duration
+
+	// seriesLimits holds the series limit of each bucket with its own limit,
+	// overriding MaxSeriesPerBucket. It is guarded by retentionMu.
+	seriesLimits map[influxdb.ID]int
+
+	// storageOrgs holds the ID of the organization the data of each bucket
+	// transferred from another organization is stored under. It is guarded
+	// by retentionMu.
+	storageOrgs map[influxdb.ID]influxdb.ID
+
+	// bucketMetadata holds the metadata of each bucket with metadata, whose
+	// configured keys label the bucket metrics. It is guarded by retentionMu.
+	bucketMetadata map[influxdb.ID]map[string]string
+	bucketMetrics  *bucketMetrics
+
+	// keyProvider supplies the keys used to encrypt data at rest, if any.
+	keyProvider encryption.KeyProvider
+
+	// cipher and injector are the default cipher and fault injector acquired
+	// by the engine while it is open, if any.
+	cipher   *encryption.Cipher
+	injector *fault.Injector
+
+	// timeGen provides the current time for write windows, retention and
+	// the start and end of operations.
+	timeGen influxdb.TimeGenerator
+
 	defaultMetricLabels prometheus.Labels
 
 	// Tracks all goroutines started by the Engine.
@@ -155,31 +221,94 @@ func WithCompactionSemaphore(s influxdb.Semaphore) Option {
 	}
 }
 
+// WithKeyProvider sets the provider of the keys used to encrypt TSM and WAL
+// data at rest, overriding the key file in the Config.
+func WithKeyProvider(kp encryption.KeyProvider) Option {
+	return func(e *Engine) {
+		e.keyProvider = kp
+	}
+}
+
+// WithIDGenerator sets the generator of the IDs of series moves and index
+// rebuilds.
+func WithIDGenerator(gen influxdb.IDGenerator) Option {
+	return func(e *Engine) {
+		e.seriesMoves.idGen = gen
+		e.rebuilds.idGen = gen
+	}
+}
+
+// WithTimeGenerator sets the source of the current time of the engine, used
+// for write windows, retention and the times of series moves and index
+// rebuilds.
+func WithTimeGenerator(gen influxdb.TimeGenerator) Option {
+	return func(e *Engine) {
+		e.timeGen = gen
+	}
+}
+
 // NewEngine initialises a new storage engine, including a series file, index and
 // TSM engine.
 func NewEngine(path string, c Config, options ...Option) *Engine {
+	// Replaying the WAL would write to the index, so a read-only engine only
+	// serves data already in TSM files.
+	if c.ReadOnly {
+		c.WAL.Enabled = false
+	}
+
 	e := &Engine{
 		config:              c,
 		path:                path,
 		defaultMetricLabels: prometheus.Labels{},
+		retentionPeriods:    make(map[influxdb.ID]time.Duration),
+		precisions:          make(map[influxdb.ID]time.Duration),
+		seriesLimits:        make(map[influxdb.ID]int),
+		storageOrgs:         make(map[influxdb.ID]influxdb.ID),
+		bucketMetadata:      make(map[influxdb.ID]map[string]string),
+		seriesMoves:         newSeriesMoves(),
+		rebuilds:            newIndexRebuilds(),
+		idempotency:         newIdempotencyKeys(time.Duration(c.IdempotencyKeyTTL), c.MaxIdempotencyKeys),
+		dedupe:              newDedupeWindow(time.Duration(c.DedupeWindow), c.MaxDedupePoints),
+		rollups:             newRollups(time.Duration(c.RollupDelay)),
+		quotas:              newOrgQuotas(),
+		timeGen:             influxdb.RealTimeGenerator{},
 		logger:              zap.NewNop(),
 	}
 
 	// Initialize series file.
 	e.sfile = tsdb.NewSeriesFile(c.GetSeriesFilePath(path))
 	e.sfile.LargeWriteThreshold = c.TSDB.LargeSeriesWriteThreshold
+	e.sfile.ReadOnly = c.ReadOnly
 
 	// Initialise index.
-	e.index = tsi1.NewIndex(e.sfile, c.Index,
-		tsi1.WithPath(c.GetIndexPath(path)))
+	indexOptions := []tsi1.IndexOption{tsi1.WithPath(c.GetIndexPath(path))}
+	if c.ReadOnly {
+		indexOptions = append(indexOptions, tsi1.WithReadOnly())
+	}
+	e.index = tsi1.NewIndex(e.sfile, c.Index, indexOptions...)
 
 	// Initialize WAL
 	e.wal = wal.NewWAL(c.GetWALPath(path))
 	e.wal.WithFsyncDelay(time.Duration(c.WAL.FsyncDelay))
+	e.wal.WithFsyncBatchBytes(int(c.WAL.FsyncMaxBatchBytes))
 	e.wal.SetEnabled(c.WAL.Enabled)
 
 	// Initialise Engine
-	e.engine = tsm1.NewEngine(c.GetEnginePath(path), e.index, c.Engine, tsm1.WithSnapshotter(e))
+	engineOptions := []tsm1.EngineOption{tsm1.WithSnapshotter(e)}
+	if c.ReadOnly {
+		engineOptions = append(engineOptions, tsm1.WithReadOnly())
+	}
+	e.engine = tsm1.NewEngine(c.GetEnginePath(path), e.index, c.Engine, engineOptions...)
+
+	// Initialise write stats tracking.
+	if c.WriteStatsInterval > 0 && !c.ReadOnly {
+		e.writeStats = newWriteStatsTracker(c.GetWriteStatsPath(path))
+	}
+
+	// Initialise cardinality tracking.
+	if c.CardinalityInterval > 0 {
+		e.cardinality = newCardinalityTracker(c.GetCardinalityPath(path))
+	}
 
 	// Apply options.
 	for _, option := range options {
@@ -193,7 +322,14 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	e.wal.SetDefaultMetricLabels(e.defaultMetricLabels)
 	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
 		r.SetDefaultMetricLabels(e.defaultMetricLabels)
+		r.timeGen = e.timeGen
 	}
+	e.writeLimits = newWriteLimitTracker(e.defaultMetricLabels)
+	e.quotaStats = newQuotaTracker(e.defaultMetricLabels)
+	if c.ShardStatsInterval > 0 {
+		e.shardStats = newShardStatsTracker(e.defaultMetricLabels)
+	}
+	e.bucketMetrics = newBucketMetrics(e, c.BucketMetricLabels, e.defaultMetricLabels)
 
 	return e
 }
@@ -215,6 +351,7 @@ func (e *Engine) WithLogger(log *zap.Logger) {
 	e.index.WithLogger(e.logger)
 	e.engine.WithLogger(e.logger)
 	e.wal.WithLogger(e.logger)
+	e.idempotency.logger = e.logger
 	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
 		r.WithLogger(e.logger)
 	}
@@ -229,6 +366,10 @@ func (e *Engine) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, tsm1.PrometheusCollectors()...)
 	metrics = append(metrics, wal.PrometheusCollectors()...)
 	metrics = append(metrics, RetentionPrometheusCollectors()...)
+	metrics = append(metrics, WriteLimitPrometheusCollectors()...)
+	metrics = append(metrics, ShardPrometheusCollectors()...)
+	metrics = append(metrics, QuotaPrometheusCollectors()...)
+	metrics = append(metrics, e.bucketMetrics)
 	return metrics
 }
 
@@ -245,6 +386,22 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	// The cipher and fault injector are process wide, so they are released
+	// if the engine fails to open.
+	defer func() {
+		if err != nil {
+			e.releaseGlobals()
+		}
+	}()
+
+	if err := e.openFaultInjection(); err != nil {
+		return err
+	}
+
+	if err := e.openEncryption(); err != nil {
+		return err
+	}
+
 	// Open the services in order and clean up if any fail.
 	var oh openHelper
 	oh.Open(ctx, e.sfile)
@@ -255,28 +412,257 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
+	// The windows of rollups are rebuilt from the WAL, so their rules are
+	// needed to replay it.
+	if err := e.loadRollupRules(ctx); err != nil {
+		return err
+	}
+
 	if err := e.replayWAL(); err != nil {
 		return err
 	}
 
+	if err := e.writeStats.Load(); err != nil {
+		e.logger.Warn("Unable to load write stats", zap.Error(err))
+	}
+
+	cardinalityLoaded, err := e.cardinality.Load()
+	if err != nil {
+		e.logger.Warn("Unable to load cardinality sketches", zap.Error(err))
+	}
+
+	if err := e.loadCheckpoint(); err != nil {
+		return err
+	}
+
+	if e.config.IdempotencyKeyTTL > 0 && !e.config.ReadOnly {
+		path := e.config.GetIdempotencyKeysPath(e.path)
+		if err := e.idempotency.Open(path, e.timeGen.Now()); err != nil {
+			return err
+		}
+	}
+
 	e.closing = make(chan struct{})
 
 	// TODO(edd) background tasks will be run in priority order via a scheduler.
 	// For now we will just run on an interval as we only have the retention
 	// policy enforcer.
-	if e.retentionEnforcer != nil {
+	if e.retentionEnforcer != nil && !e.config.ReadOnly {
 		e.runRetentionEnforcer()
 	}
 
+	if e.writeStats != nil {
+		e.runWriteStatsTracker()
+	}
+
+	if e.shardStats != nil {
+		e.runShardStatsTracker()
+	}
+
+	if e.cardinality != nil && !e.config.ReadOnly {
+		e.runCardinalityTracker(!cardinalityLoaded)
+	}
+
+	if e.keyProvider != nil && !e.config.ReadOnly {
+		e.runEncryptionMigration()
+	}
+
+	if e.config.TombstoneCheckInterval > 0 && !e.config.ReadOnly {
+		e.runTombstoneCompactor()
+	}
+
+	if e.config.SeriesFileCompactInterval > 0 && !e.config.ReadOnly {
+		e.runSeriesFileCompactor()
+	}
+
+	if !e.config.ReadOnly {
+		e.runRollups()
+	}
+
+	if e.config.ReadOnly {
+		e.runReadOnlyRefresher()
+	}
+
 	return nil
 }
 
-// replayWAL reads the WAL segment files and replays them.
+// openFaultInjection starts injecting the faults in the config, if any.
+func (e *Engine) openFaultInjection() error {
+	if e.config.FaultInjection == "" {
+		return nil
+	}
+
+	faults, err := fault.Parse(e.config.FaultInjection)
+	if err != nil {
+		return err
+	}
+	injector, err := fault.NewInjector(faults...)
+	if err != nil {
+		return err
+	}
+	if err := fault.Acquire(injector); err != nil {
+		return err
+	}
+	e.injector = injector
+	e.logger.Warn("Injecting faults into storage engine file operations", zap.String("faults", e.config.FaultInjection))
+	return nil
+}
+
+// openEncryption enables encryption of data at rest if a key provider or key
+// file is configured. Keys must be available before the WAL is replayed.
+//
+// The cipher is shared by the engines of the process, so an engine cannot be
+// opened while another engine encrypts its data with other keys, or without
+// encryption while another engine encrypts its data.
+func (e *Engine) openEncryption() error {
+	if e.keyProvider == nil && e.config.EncryptionKeyFile != "" {
+		kp, err := encryption.LoadKeyFile(e.config.EncryptionKeyFile)
+		if err != nil {
+			return fmt.Errorf("unable to load encryption keys: %v", err)
+		}
+		e.keyProvider = kp
+	}
+
+	if e.keyProvider == nil {
+		if encryption.Default() != nil {
+			return fmt.Errorf("unable to open unencrypted engine: %v", encryption.ErrCipherInUse)
+		}
+		return nil
+	}
+
+	cipher := encryption.NewCipher(e.keyProvider)
+	if err := encryption.Acquire(cipher); err != nil {
+		return fmt.Errorf("unable to enable encryption: %v", err)
+	}
+	e.cipher = cipher
+	return nil
+}
+
+// releaseGlobals releases the cipher and fault injector acquired by the
+// engine, so that other engines of the process can be opened.
+func (e *Engine) releaseGlobals() {
+	encryption.Release(e.cipher)
+	fault.Release(e.injector)
+	e.cipher, e.injector = nil, nil
+}
+
+// runEncryptionMigration encrypts existing TSM files with the current key in
+// a separate goroutine, stopping when the engine is closed.
+func (e *Engine) runEncryptionMigration() {
+	ctx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+
+		go func() {
+			select {
+			case <-closing:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		start := time.Now()
+		if err := e.engine.EncryptFiles(ctx); err != nil && err != context.Canceled {
+			e.logger.Warn("Unable to encrypt TSM files", zap.Error(err))
+			return
+		}
+		e.logger.Info("Encrypted TSM files", zap.Duration("duration", time.Since(start)))
+	}()
+}
+
+// runTombstoneCompactor rewrites the TSM files holding enough deleted data
+// every TombstoneCheckInterval in a separate goroutine, stopping when the
+// engine is closed.
+func (e *Engine) runTombstoneCompactor() {
+	interval := time.Duration(e.config.TombstoneCheckInterval)
+	l := e.logger.With(zap.String("component", "tombstone_compactor"), logger.DurationLiteral("check_interval", interval))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+	ticker := time.NewTicker(interval)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		defer ticker.Stop()
+
+		go func() {
+			select {
+			case <-closing:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for {
+			select {
+			case <-closing:
+				return
+			case <-ticker.C:
+				n, err := e.engine.CompactTombstones(ctx, e.config.TombstoneThreshold)
+				if err != nil && err != context.Canceled {
+					l.Warn("Unable to compact tombstoned TSM files", zap.Error(err))
+				} else if n > 0 {
+					l.Info("Compacted tombstoned TSM files", zap.Int("generations", n))
+				}
+			}
+		}
+	}()
+}
+
+// runSeriesFileCompactor removes deleted series from the series file every
+// SeriesFileCompactInterval in a separate goroutine, stopping when the engine
+// is closed.
+func (e *Engine) runSeriesFileCompactor() {
+	interval := time.Duration(e.config.SeriesFileCompactInterval)
+	l := e.logger.With(zap.String("component", "series_file_compactor"), logger.DurationLiteral("check_interval", interval))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+	ticker := time.NewTicker(interval)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		defer ticker.Stop()
+
+		go func() {
+			select {
+			case <-closing:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		for {
+			select {
+			case <-closing:
+				return
+			case <-ticker.C:
+				n, err := e.sfile.CompactSegments(ctx, e.config.SeriesFileCompactThreshold)
+				if err != nil && err != context.Canceled {
+					l.Warn("Unable to compact series file", zap.Error(err))
+				} else if n > 0 {
+					l.Info("Compacted series file", zap.Int("series_removed", n))
+				}
+			}
+		}
+	}()
+}
+
+// replayWAL reads the WAL segment files and replays them, and records the
+// recovery report of the replay.
 func (e *Engine) replayWAL() error {
 	if !e.config.WAL.Enabled {
 		return nil
 	}
-	now := time.Now()
+	now := e.timeGen.Now()
 
 	walPaths, err := wal.SegmentFileNames(e.wal.Path())
 	if err != nil {
@@ -291,17 +677,26 @@ func (e *Engine) replayWAL() error {
 	defer func() { e.engine.Cache.SetMaxSize(limit) }()
 	e.engine.Cache.SetMaxSize(0)
 
+	rec := newWALRecovery()
+
 	// Execute all the entries in the WAL again
 	reader := wal.NewWALReader(walPaths)
 	reader.WithLogger(e.logger)
+	reader.WithDropped(func(entry wal.WALEntry) {
+		if en, ok := entry.(*wal.WriteWALEntry); ok {
+			rec.dropPoints(tsm1.ValuesToPoints(en.Values))
+		}
+	})
 	err = reader.Read(func(entry wal.WALEntry) error {
 		switch en := entry.(type) {
 		case *wal.WriteWALEntry:
 			points := tsm1.ValuesToPoints(en.Values)
 			err := e.writePointsLocked(context.Background(), tsdb.NewSeriesCollection(points), en.Values)
-			if _, ok := err.(tsdb.PartialWriteError); ok {
-				err = nil
+			var rejected [][]byte
+			if pwe, ok := err.(tsdb.PartialWriteError); ok {
+				rejected, err = pwe.DroppedKeys, nil
 			}
+			rec.recoverPoints(points, rejected)
 			return err
 
 		case *wal.DeleteBucketRangeWALEntry:
@@ -319,10 +714,9 @@ func (e *Engine) replayWAL() error {
 		return nil
 	})
 
-	e.logger.Info("Reloaded WAL",
-		zap.String("path", e.wal.Path()),
-		zap.Duration("duration", time.Since(now)),
-		zap.Error(err))
+	report := rec.report(reader.Segments(), now, e.timeGen.Now(), err)
+	e.walRecovery = report
+	logWALRecovery(e.logger, e.wal.Path(), report)
 
 	return err
 }
@@ -393,6 +787,143 @@ func (e *Engine) runRetentionEnforcer() {
 	}()
 }
 
+// runWriteStatsTracker completes a write stats interval and persists the stats
+// every WriteStatsInterval in a separate goroutine.
+func (e *Engine) runWriteStatsTracker() {
+	interval := time.Duration(e.config.WriteStatsInterval)
+	l := e.logger.With(zap.String("component", "write_stats"), logger.DurationLiteral("interval", interval))
+
+	ticker := time.NewTicker(interval)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.closing:
+				if err := e.writeStats.Save(); err != nil {
+					l.Warn("Unable to persist write stats", zap.Error(err))
+				}
+				return
+			case <-ticker.C:
+				e.writeStats.Roll()
+				if err := e.writeStats.Save(); err != nil {
+					l.Warn("Unable to persist write stats", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// runCardinalityTracker persists the cardinality sketches every
+// CardinalityInterval in a separate goroutine. If rebuild is true the
+// sketches are first seeded from the series in the index.
+func (e *Engine) runCardinalityTracker(rebuild bool) {
+	interval := time.Duration(e.config.CardinalityInterval)
+	l := e.logger.With(zap.String("component", "cardinality"), logger.DurationLiteral("interval", interval))
+	closing := e.closing
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		if rebuild {
+			start := time.Now()
+			if err := e.rebuildCardinality(closing); err != nil {
+				l.Warn("Unable to rebuild cardinality sketches", zap.Error(err))
+			} else {
+				l.Info("Rebuilt cardinality sketches", zap.Duration("duration", time.Since(start)))
+			}
+		}
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closing:
+				if err := e.cardinality.Save(); err != nil {
+					l.Warn("Unable to persist cardinality sketches", zap.Error(err))
+				}
+				return
+			case <-ticker.C:
+				if err := e.cardinality.Save(); err != nil {
+					l.Warn("Unable to persist cardinality sketches", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// rebuildCardinality adds every series in the index to the cardinality
+// sketches. It stops early, without error, if closing is closed.
+func (e *Engine) rebuildCardinality(closing <-chan struct{}) error {
+	itr, err := e.index.MeasurementIterator()
+	if err != nil {
+		return err
+	} else if itr == nil {
+		return nil
+	}
+	defer itr.Close()
+
+	for {
+		name, err := itr.Next()
+		if err != nil {
+			return err
+		} else if name == nil {
+			return nil
+		}
+
+		sitr, err := e.index.MeasurementSeriesIDIterator(name)
+		if err != nil {
+			return err
+		} else if sitr == nil {
+			continue
+		}
+
+		for {
+			select {
+			case <-closing:
+				sitr.Close()
+				return nil
+			default:
+			}
+
+			elem, err := sitr.Next()
+			if err != nil {
+				sitr.Close()
+				return err
+			} else if elem.SeriesID.IsZero() {
+				break
+			}
+			if key := e.sfile.SeriesKey(elem.SeriesID); len(key) > 0 {
+				e.cardinality.RecordSeriesKey(key)
+			}
+		}
+		if err := sitr.Close(); err != nil {
+			return err
+		}
+	}
+}
+
+// runShardStatsTracker completes a shard stats interval every
+// ShardStatsInterval in a separate goroutine.
+func (e *Engine) runShardStatsTracker() {
+	ticker := time.NewTicker(time.Duration(e.config.ShardStatsInterval))
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.closing:
+				return
+			case now := <-ticker.C:
+				e.shardStats.Roll(now, e.engine.BucketStorageStats())
+			}
+		}
+	}()
+}
+
 // Close closes the store and all underlying resources. It returns an error if
 // any of the underlying systems fail to close.
 func (e *Engine) Close() error {
@@ -419,6 +950,8 @@ func (e *Engine) Close() error {
 	ch.Close(e.wal)
 	ch.Close(e.index)
 	ch.Close(e.sfile)
+	ch.Close(e.idempotency)
+	e.releaseGlobals()
 	return ch.Done()
 }
 
@@ -430,6 +963,8 @@ func (e *Engine) CreateSeriesCursor(ctx context.Context, req SeriesCursorRequest
 		return nil, ErrEngineClosed
 	}
 
+	orgID, bucketID := tsdb.DecodeName(req.Name)
+	req.Name = tsdb.EncodeName(e.storageOrgID(orgID, bucketID), bucketID)
 	return newSeriesCursor(req, e.index, e.sfile, cond)
 }
 
@@ -440,7 +975,12 @@ func (e *Engine) CreateCursorIterator(ctx context.Context) (tsdb.CursorIterator,
 	if e.closing == nil {
 		return nil, ErrEngineClosed
 	}
-	return e.engine.CreateCursorIterator(ctx)
+
+	itr, err := e.engine.CreateCursorIterator(ctx)
+	if err != nil || e.shardStats == nil {
+		return itr, err
+	}
+	return &shardCursorIterator{CursorIterator: itr, stats: e.shardStats}, nil
 }
 
 // WritePoints writes the provided points to the engine.
@@ -450,34 +990,62 @@ func (e *Engine) CreateCursorIterator(ctx context.Context) (tsdb.CursorIterator,
 // there are any field type conflicts.
 //
 // Appropriate errors are returned in those cases.
+//
+// If ctx holds an idempotency key, points written to a bucket with the key of
+// a batch written to it within the configured IdempotencyKeyTTL are dropped,
+// and the error of the first write is returned.
+//
+// Points identical to a point written within the configured DedupeWindow are
+// dropped without an error.
 func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	collection, j := tsdb.NewSeriesCollection(points), 0
+	if e.config.ReadOnly {
+		return ErrEngineReadOnly
+	}
 
-	// dropPoint should be called whenever there is reason to drop a point from
-	// the batch.
-	dropPoint := func(key []byte, reason string) {
-		if collection.Reason == "" {
-			collection.Reason = reason
-		}
-		collection.Dropped++
-		collection.DroppedKeys = append(collection.DroppedKeys, key)
+	e.renameToStorageOrgs(points)
+
+	// A batch with an idempotency key is dropped if a batch with the same key
+	// was recently written to its bucket.
+	key := IdempotencyKeyFromContext(ctx)
+	if key == "" || e.config.IdempotencyKeyTTL <= 0 || len(points) == 0 || len(points[0].Name()) != 16 {
+		return e.writePoints(ctx, points)
+	}
+	dup, err := e.idempotency.write(ctx, string(points[0].Name())+key, e.timeGen.Now(), func() error {
+		return e.writePoints(ctx, points)
+	})
+	if dup {
+		org, bucket := tsdb.DecodeNameSlice(points[0].Name())
+		e.logger.Debug("Dropped duplicate write",
+			zap.String("org_id", org.String()),
+			zap.String("bucket_id", bucket.String()),
+			zap.String("idempotency_key", key))
 	}
+	return err
+}
+
+// writePoints writes points to the WAL and the cache, dropping the points that
+// are invalid or outside the limits of their bucket.
+func (e *Engine) writePoints(ctx context.Context, points []models.Point) error {
+	collection, j := tsdb.NewSeriesCollection(points), 0
+	now := e.timeGen.Now()
+	bucketWindow, maxTime := e.writeWindow(now)
+	var duplicates map[influxdb.ID]int
 
 	for iter := collection.Iterator(); iter.Next(); {
 		tags := iter.Tags()
 
 		// Not enough tags present.
 		if tags.Len() < 2 {
-			dropPoint(iter.Key(), fmt.Sprintf("missing required tags: parsed tags: %q", tags))
+			collection.Invalidate(iter.Index(), fmt.Sprintf("missing required tags: parsed tags: %q", tags))
 			continue
 		}
 
 		// First tag key is not measurement tag.
 		if !bytes.Equal(tags[0].Key, models.MeasurementTagKeyBytes) {
-			dropPoint(iter.Key(), fmt.Sprintf("missing required measurement tag as first tag, got: %q", tags[0].Key))
+			collection.Invalidate(iter.Index(), fmt.Sprintf("missing required measurement tag as first tag, got: %q", tags[0].Key))
 			continue
 		}
 
@@ -485,26 +1053,56 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 
 		// Last tag key is not field tag.
 		if !bytes.Equal(fkey, models.FieldKeyTagKeyBytes) {
-			dropPoint(iter.Key(), fmt.Sprintf("missing required field key tag as last tag, got: %q", tags[0].Key))
+			collection.Invalidate(iter.Index(), fmt.Sprintf("missing required field key tag as last tag, got: %q", tags[0].Key))
 			continue
 		}
 
 		// The value representing the underlying field key is invalid if it's "time".
 		if bytes.Equal(fval, timeBytes) {
-			dropPoint(iter.Key(), fmt.Sprintf("invalid field key: input field %q is invalid", timeBytes))
+			collection.Invalidate(iter.Index(), fmt.Sprintf("invalid field key: input field %q is invalid", timeBytes))
 			continue
 		}
 
 		// Filter out any tags with key equal to "time": they are invalid.
 		if tags.Get(timeBytes) != nil {
-			dropPoint(iter.Key(), fmt.Sprintf("invalid tag key: input tag %q on measurement %q is invalid", timeBytes, iter.Name()))
+			collection.Invalidate(iter.Index(), fmt.Sprintf("invalid tag key: input tag %q on measurement %q is invalid", timeBytes, iter.Name()))
 			continue
 		}
 
 		// Drop any point with invalid unicode characters in any of the tag keys or values.
 		// This will also cover validating the value used to represent the field key.
 		if !models.ValidTagTokens(tags) {
-			dropPoint(iter.Key(), fmt.Sprintf("key contains invalid unicode: %q", iter.Key()))
+			collection.Invalidate(iter.Index(), fmt.Sprintf("key contains invalid unicode: %q", iter.Key()))
+			continue
+		}
+
+		// Truncate the time to the precision of the bucket, so the timestamps
+		// of its points share a larger divisor and are encoded more compactly.
+		minTime, precision := bucketWindow(iter.Name())
+		if precision > 1 {
+			p := iter.Point()
+			p.SetTime(time.Unix(0, truncateTime(p.UnixNano(), precision)))
+		}
+
+		// Drop any point that would be deleted by the next retention check, or
+		// that is too far in the future.
+		if t := iter.Point().UnixNano(); t < minTime {
+			collection.Invalidate(iter.Index(), fmt.Sprintf("point time %s is outside the retention period of the bucket", time.Unix(0, t).UTC().Format(time.RFC3339Nano)))
+			continue
+		} else if t > maxTime {
+			collection.Invalidate(iter.Index(), fmt.Sprintf("point time %s is beyond the future write tolerance", time.Unix(0, t).UTC().Format(time.RFC3339Nano)))
+			continue
+		}
+
+		// Drop any point whose field values are those last written to its
+		// fields within the dedupe window. They are already stored, so the
+		// point is not reported as dropped.
+		if e.dedupe.enabled() && e.dedupe.duplicate(iter.Point(), now) {
+			if duplicates == nil {
+				duplicates = make(map[influxdb.ID]int)
+			}
+			_, bucketID := tsdb.DecodeNameSlice(iter.Name())
+			duplicates[bucketID]++
 			continue
 		}
 
@@ -513,6 +1111,10 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	}
 	collection.Truncate(j)
 
+	for bucketID, n := range duplicates {
+		e.writeLimits.AddDuplicates(bucketID, n)
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -520,6 +1122,17 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		return ErrEngineClosed
 	}
 
+	// Reject the write if an organization has exceeded its storage quota.
+	if err := e.checkStorageQuotas(collection); err != nil {
+		return err
+	}
+
+	// Drop any point that would create a series beyond the cardinality limits.
+	limitErr, err := e.limitCardinality(collection)
+	if err != nil {
+		return err
+	}
+
 	// Convert the collection to values for adding to the WAL/Cache.
 	values, err := tsm1.CollectionToValues(collection)
 	if err != nil {
@@ -531,7 +1144,25 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 		return err
 	}
 
-	return e.writePointsLocked(ctx, collection, values)
+	err = e.writePointsLocked(ctx, collection, values)
+	pwe, ok := err.(tsdb.PartialWriteError)
+	if err == nil || ok {
+		e.writeStats.Record(collection)
+		e.shardStats.RecordWrite(collection)
+		e.cardinality.Record(collection)
+		if e.dedupe.enabled() {
+			e.dedupe.record(collection, now)
+		}
+		atomic.AddUint64(&e.writeN, 1)
+	}
+	if ok && limitErr != nil {
+		pwe.Err = limitErr
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Err:  pwe,
+		}
+	}
+	return err
 }
 
 // writePointsLocked does the work of writing points and must be called under some sort of lock.
@@ -539,10 +1170,6 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
-	// TODO(jeff): keep track of the values in the collection so that partial write
-	// errors get tracked all the way. Right now, the engine doesn't drop any values
-	// but if it ever did, the errors could end up missing some data.
-
 	// Add new series to the index and series file.
 	if err := e.index.CreateSeriesListIfNotExists(collection); err != nil {
 		return err
@@ -562,6 +1189,7 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 	if err := e.engine.WriteValues(values); err != nil {
 		return err
 	}
+	e.rollups.add(collection, e.timeGen.Now())
 
 	return collection.PartialWriteError()
 }
@@ -604,11 +1232,182 @@ func (e *Engine) CommitSegments(ctx context.Context, segs []string, fn func() er
 func (e *Engine) DeleteBucket(ctx context.Context, orgID, bucketID platform.ID) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
-	return e.DeleteBucketRange(ctx, orgID, bucketID, math.MinInt64, math.MaxInt64)
+	orgID = e.storageOrgID(orgID, bucketID)
+	if err := e.DeleteBucketRange(ctx, orgID, bucketID, "", math.MinInt64, math.MaxInt64); err != nil {
+		return err
+	}
+	e.writeStats.DeleteBucket(orgID, bucketID)
+	e.cardinality.DeleteBucket(orgID, bucketID)
+	return nil
+}
+
+// SetBucketRetentionPeriod sets the retention period of a bucket. Points
+// written to the bucket with a time before the retention period are dropped.
+// A period of zero accepts points at any time.
+func (e *Engine) SetBucketRetentionPeriod(bucketID platform.ID, d time.Duration) {
+	e.retentionMu.Lock()
+	defer e.retentionMu.Unlock()
+	if d <= 0 {
+		delete(e.retentionPeriods, bucketID)
+		return
+	}
+	e.retentionPeriods[bucketID] = d
+}
+
+// SetBucketPrecision sets the precision of the timestamps of a bucket. The
+// times of points written to the bucket are truncated to a multiple of the
+// precision, which lets them be compressed better. A precision of zero or one
+// nanosecond keeps the times unchanged.
+func (e *Engine) SetBucketPrecision(bucketID platform.ID, d time.Duration) {
+	e.retentionMu.Lock()
+	defer e.retentionMu.Unlock()
+	if d <= time.Nanosecond {
+		delete(e.precisions, bucketID)
+		return
+	}
+	e.precisions[bucketID] = d
+}
+
+// writeWindow returns a function giving the earliest time a point can be
+// written to the bucket of a measurement name along with the precision, in
+// nanoseconds, of the bucket. It also returns the latest time a point can be
+// written to any bucket.
+func (e *Engine) writeWindow(now time.Time) (func(name []byte) (int64, int64), int64) {
+	maxTime := int64(math.MaxInt64)
+	if d := time.Duration(e.config.FutureWriteTolerance); d > 0 {
+		maxTime = now.Add(d).UnixNano()
+	}
+
+	var lastName []byte
+	var lastMin, lastPrecision int64
+	bucketWindow := func(name []byte) (int64, int64) {
+		if lastName != nil && bytes.Equal(name, lastName) {
+			return lastMin, lastPrecision
+		}
+
+		lastName, lastMin, lastPrecision = name, math.MinInt64, 1
+		if len(name) != 16 {
+			return lastMin, lastPrecision
+		}
+
+		_, bucketID := tsdb.DecodeNameSlice(name)
+		e.retentionMu.RLock()
+		rp, precision := e.retentionPeriods[bucketID], e.precisions[bucketID]
+		e.retentionMu.RUnlock()
+		if rp > 0 {
+			lastMin = now.Add(-rp).UnixNano()
+		}
+		if precision > 0 {
+			lastPrecision = int64(precision)
+		}
+		return lastMin, lastPrecision
+	}
+	return bucketWindow, maxTime
+}
+
+// truncateTime rounds t down to a multiple of precision.
+func truncateTime(t, precision int64) int64 {
+	r := t % precision
+	if r < 0 {
+		r += precision
+	}
+	return t - r
+}
+
+// SetBucketSeriesLimit sets the maximum number of series of a bucket,
+// overriding the limit configured for every bucket. A limit of zero removes
+// it.
+func (e *Engine) SetBucketSeriesLimit(bucketID platform.ID, n int) {
+	e.retentionMu.Lock()
+	defer e.retentionMu.Unlock()
+	if n <= 0 {
+		delete(e.seriesLimits, bucketID)
+		return
+	}
+	e.seriesLimits[bucketID] = n
+}
+
+// SetBucketStorageOrg sets the ID of the organization the data of a bucket is
+// stored under, once the bucket is transferred to another organization. The
+// engine reads and writes the data of the bucket under that organization
+// whichever organization it is given, so the data is not copied. An invalid
+// ID stores the data of the bucket under the organization it is given.
+//
+// The statistics of the data of the bucket are reported under the
+// organization its data is stored under.
+func (e *Engine) SetBucketStorageOrg(bucketID, orgID platform.ID) {
+	e.retentionMu.Lock()
+	defer e.retentionMu.Unlock()
+	if !orgID.Valid() {
+		delete(e.storageOrgs, bucketID)
+		return
+	}
+	e.storageOrgs[bucketID] = orgID
+}
+
+// storageOrgID returns the ID of the organization the data of the bucket of
+// orgID is stored under.
+func (e *Engine) storageOrgID(orgID, bucketID platform.ID) platform.ID {
+	e.retentionMu.RLock()
+	defer e.retentionMu.RUnlock()
+	if id, ok := e.storageOrgs[bucketID]; ok {
+		return id
+	}
+	return orgID
+}
+
+// renameToStorageOrgs renames the points written to buckets transferred from
+// another organization to the organization their data is stored under.
+func (e *Engine) renameToStorageOrgs(points []models.Point) {
+	e.retentionMu.RLock()
+	defer e.retentionMu.RUnlock()
+	if len(e.storageOrgs) == 0 {
+		return
+	}
+	for _, p := range points {
+		name := p.Name()
+		if len(name) != influxdb.IDLength {
+			continue
+		}
+		orgID, bucketID := tsdb.DecodeNameSlice(name)
+		if id, ok := e.storageOrgs[bucketID]; ok && id != orgID {
+			encoded := tsdb.EncodeName(id, bucketID)
+			p.SetName(string(models.EscapeMeasurement(encoded[:])))
+		}
+	}
 }
 
-// DeleteBucketRange deletes an entire bucket from the storage engine.
-func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID platform.ID, min, max int64) error {
+// SetBucketShardGroupDuration sets the duration of the shard groups that the
+// data of a bucket is partitioned into. A duration of zero disables
+// partitioning for the bucket.
+func (e *Engine) SetBucketShardGroupDuration(bucketID platform.ID, d time.Duration) {
+	e.engine.SetShardGroupDuration(bucketID, d)
+}
+
+// SetBucketCompactionStrategy sets the strategy used to plan the compactions
+// of the data of a bucket. An empty name selects the default strategy.
+func (e *Engine) SetBucketCompactionStrategy(bucketID platform.ID, name string) {
+	e.engine.SetCompactionStrategy(bucketID, name)
+}
+
+// SetBucketCacheBudget sets the maximum size in bytes of the data of a bucket
+// held in the cache. A budget of zero removes it.
+func (e *Engine) SetBucketCacheBudget(bucketID platform.ID, n uint64) {
+	e.engine.SetCacheBudget(bucketID, n)
+}
+
+// SetBucketCacheWriteColdDuration sets how long the data of a bucket stays in
+// the cache without being written to before it is snapshotted on its own. A
+// duration of zero removes it.
+func (e *Engine) SetBucketCacheWriteColdDuration(bucketID platform.ID, d time.Duration) {
+	e.engine.SetCacheWriteColdDuration(bucketID, d)
+}
+
+// DeleteBucketRange deletes the data of a bucket within [min, max] from the
+// storage engine. If measurement is not empty, only the data of that
+// measurement is deleted. Series left without any data are removed from the
+// index.
+func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID platform.ID, measurement string, min, max int64) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -616,14 +1415,51 @@ func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID platform
 	defer e.mu.RUnlock()
 	if e.closing == nil {
 		return ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return ErrEngineReadOnly
+	}
+	orgID = e.storageOrgID(orgID, bucketID)
+
+	var pred tsm1.Predicate
+	var predData []byte
+	if measurement != "" {
+		var err error
+		if pred, err = measurementPredicate(measurement); err != nil {
+			return err
+		}
+		// Marshal the predicate to add it to the WAL.
+		if predData, err = pred.Marshal(); err != nil {
+			return err
+		}
 	}
 
 	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
-	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max, nil); err != nil {
+	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max, predData); err != nil {
 		return err
 	}
 
-	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, nil)
+	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, pred)
+}
+
+// measurementPredicate returns a predicate matching the series keys of the
+// measurement.
+func measurementPredicate(measurement string) (tsm1.Predicate, error) {
+	return tsm1.NewProtobufPredicate(&datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{
+					NodeType: datatypes.NodeTypeTagRef,
+					Value:    &datatypes.Node_TagRefValue{TagRefValue: models.MeasurementTagKey},
+				},
+				{
+					NodeType: datatypes.NodeTypeLiteral,
+					Value:    &datatypes.Node_StringValue{StringValue: measurement},
+				},
+			},
+		},
+	})
 }
 
 // DeleteBucketRangePredicate deletes data within a bucket from the storage engine. Any data
@@ -636,7 +1472,10 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 	defer e.mu.RUnlock()
 	if e.closing == nil {
 		return ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return ErrEngineReadOnly
 	}
+	orgID = e.storageOrgID(orgID, bucketID)
 
 	var predData []byte
 	var err error
@@ -677,6 +1516,8 @@ func (e *Engine) CreateBackup(ctx context.Context) (int, []string, error) {
 
 	if e.closing == nil {
 		return 0, nil, ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return 0, nil, ErrEngineReadOnly
 	}
 
 	if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusBackup); err != nil {
@@ -815,3 +1656,60 @@ func (e *Engine) MeasurementStats() (tsm1.MeasurementStats, error) {
 	}
 	return e.engine.MeasurementStats()
 }
+
+// FindMeasurementWriteStats returns per-measurement write statistics matching
+// the filter, ranked by the filter's sort key.
+func (e *Engine) FindMeasurementWriteStats(ctx context.Context, filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	return e.writeStats.Stats(filter)
+}
+
+// RetentionExpiries returns the number of times the retention enforcer has
+// expired data from the buckets of each org since the engine was created.
+func (e *Engine) RetentionExpiries() map[influxdb.ID]uint64 {
+	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
+		return r.Expiries()
+	}
+	return nil
+}
+
+// FindMeasurementCardinality returns the estimated series cardinality of each
+// measurement matching the filter, ranked by series.
+func (e *Engine) FindMeasurementCardinality(ctx context.Context, filter influxdb.CardinalityFilter) ([]*influxdb.MeasurementCardinality, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	return e.cardinality.Cardinality(filter), nil
+}
+
+// FindShardStats returns the load on each shard matching the filter, ranked
+// by the filter's sort key.
+func (e *Engine) FindShardStats(ctx context.Context, filter influxdb.ShardStatsFilter) ([]*influxdb.ShardStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	// The storage used by each bucket is only read when tracking is enabled.
+	var storage map[influxdb.ID]*tsm1.BucketStorageStats
+	if e.shardStats != nil {
+		storage = e.engine.BucketStorageStats()
+	}
+	return e.shardStats.Stats(filter, storage)
+}
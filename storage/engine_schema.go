@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/influxdata/influxql"
 )
@@ -19,7 +21,7 @@ func (e *Engine) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, start
 		return cursors.EmptyStringIterator, nil
 	}
 
-	return e.engine.TagKeys(ctx, orgID, bucketID, start, end, predicate)
+	return e.engine.TagKeys(ctx, e.storageOrgID(orgID, bucketID), bucketID, start, end, predicate)
 }
 
 // TagValues returns an iterator which enumerates the values for the specific
@@ -34,5 +36,71 @@ func (e *Engine) TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tag
 		return cursors.EmptyStringIterator, nil
 	}
 
-	return e.engine.TagValues(ctx, orgID, bucketID, tagKey, start, end, predicate)
+	return e.engine.TagValues(ctx, e.storageOrgID(orgID, bucketID), bucketID, tagKey, start, end, predicate)
+}
+
+// GroupCardinality estimates the number of groups produced by grouping the
+// series in the bucket by tagKeys, and returns it with the number of series in
+// the bucket. The estimate is taken from the index, ignoring time ranges and
+// predicates, so it is an upper bound on both. Both are zero if the engine is
+// closed.
+func (e *Engine) GroupCardinality(ctx context.Context, orgID, bucketID influxdb.ID, tagKeys []string) (groups, series int64, err error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return 0, 0, nil
+	}
+
+	name := tsdb.EncodeName(e.storageOrgID(orgID, bucketID), bucketID)
+	stats, err := e.index.MeasurementCardinalityStats()
+	if err != nil {
+		return 0, 0, err
+	}
+	series = int64(stats[string(name[:])])
+
+	groups = 1
+	for _, key := range tagKeys {
+		if groups >= series {
+			break
+		}
+
+		n, err := e.tagValueN(name[:], []byte(key), series/groups+1)
+		if err != nil {
+			return 0, 0, err
+		} else if n > 0 {
+			groups *= n
+		}
+	}
+
+	if groups > series {
+		groups = series
+	}
+	return groups, series, nil
+}
+
+// tagValueN returns the number of values of the tag key in the index, up to
+// limit.
+func (e *Engine) tagValueN(name, key []byte, limit int64) (int64, error) {
+	itr, err := e.index.TagValueIterator(name, key)
+	if err != nil {
+		return 0, err
+	} else if itr == nil {
+		return 0, nil
+	}
+	defer itr.Close()
+
+	var n int64
+	for n < limit {
+		v, err := itr.Next()
+		if err != nil {
+			return 0, err
+		} else if v == nil {
+			break
+		}
+		n++
+	}
+	return n, nil
 }
@@ -1,21 +1,31 @@
 package storage_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/prom/promtest"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -255,6 +265,104 @@ func TestEngine_DeleteBucket(t *testing.T) {
 	}
 }
 
+func TestEngine_GroupCardinality(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	var points []models.Point
+	for i := 0; i < 20; i++ {
+		points = append(points, models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{
+				models.FieldKeyTagKey:    "value",
+				models.MeasurementTagKey: "cpu",
+				"region":                 fmt.Sprintf("region%d", i%2),
+				"host":                   fmt.Sprintf("server%d", i),
+			}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		))
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		keys   []string
+		groups int64
+	}{
+		{keys: nil, groups: 1},
+		{keys: []string{"region"}, groups: 2},
+		{keys: []string{"region", models.FieldKeyTagKey}, groups: 2},
+		{keys: []string{"host"}, groups: 20},
+		{keys: []string{"region", "host"}, groups: 20},
+		{keys: []string{"missing"}, groups: 1},
+	} {
+		groups, series, err := engine.GroupCardinality(context.Background(), engine.org, engine.bucket, tt.keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if groups != tt.groups || series != 20 {
+			t.Errorf("got %d groups and %d series for %v, exp %d groups and 20 series", groups, series, tt.keys, tt.groups)
+		}
+	}
+}
+
+func TestEngine_SetBucketStorageOrg(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	point := func(orgID influxdb.ID, host string) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(orgID, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+	series := func(orgID influxdb.ID) int64 {
+		t.Helper()
+		_, n, err := engine.GroupCardinality(context.Background(), orgID, engine.bucket, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{point(engine.org, "server0")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the bucket is transferred, its data is written and read under the
+	// organization it was transferred from.
+	orgID := engine.org + 1
+	engine.SetBucketStorageOrg(engine.bucket, engine.org)
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{point(orgID, "server1")}); err != nil {
+		t.Fatal(err)
+	}
+	if got := series(orgID); got != 2 {
+		t.Fatalf("got %d series in transferred bucket, exp 2", got)
+	}
+
+	engine.SetBucketStorageOrg(engine.bucket, 0)
+	if got := series(orgID); got != 0 {
+		t.Fatalf("got %d series under organization the bucket was transferred to, exp 0", got)
+	}
+	if got := series(engine.org); got != 2 {
+		t.Fatalf("got %d series under organization the bucket was transferred from, exp 2", got)
+	}
+
+	engine.SetBucketStorageOrg(engine.bucket, engine.org)
+	if err := engine.DeleteBucket(context.Background(), orgID, engine.bucket); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.SeriesCardinality(); got != 0 {
+		t.Fatalf("got %d series after deleting transferred bucket, exp 0", got)
+	}
+}
+
 func TestEngine_DeleteBucket_Predicate(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
@@ -352,134 +460,1029 @@ func TestEngine_DeleteBucket_Predicate(t *testing.T) {
 
 }
 
-func TestEngine_OpenClose(t *testing.T) {
+func TestEngine_MoveSeries(t *testing.T) {
 	engine := NewDefaultEngine()
+	defer engine.Close()
 	engine.MustOpen()
 
-	if err := engine.Close(); err != nil {
+	dst := engine.bucket + 1
+	p := func(m, v string) models.Point {
+		tags := map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m, "host": v}
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(tags),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		p("cpu", "a"),
+		p("cpu", "b"),
+		p("mem", "a"),
+	})
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	if err := engine.Open(context.Background()); err != nil {
+	pred, err := tsm1.NewProtobufPredicate(&datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+			Children: []*datatypes.Node{
+				{NodeType: datatypes.NodeTypeTagRef,
+					Value: &datatypes.Node_TagRefValue{TagRefValue: models.MeasurementTagKey},
+				},
+				{NodeType: datatypes.NodeTypeLiteral,
+					Value: &datatypes.Node_StringValue{StringValue: "cpu"},
+				},
+			},
+		},
+	})
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	if err := engine.Close(); err != nil {
+	m := &influxdb.SeriesMove{OrgID: engine.org, SourceBucketID: engine.bucket, DestinationBucketID: engine.bucket}
+	if err := engine.MoveSeries(context.Background(), m, pred); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected invalid error for the same bucket", err)
+	}
+
+	m.DestinationBucketID = dst
+	if err := engine.MoveSeries(context.Background(), m, pred); err != nil {
+		t.Fatal(err)
+	} else if m.ID == 0 || m.Status != influxdb.SeriesMoveRunning {
+		t.Fatalf("unexpected started move %+v", m)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for m.Status == influxdb.SeriesMoveRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the move to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if m, err = engine.FindSeriesMoveByID(context.Background(), m.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m.Status != influxdb.SeriesMoveCompleted || m.Series != 2 || m.FinishedAt == nil {
+		t.Fatalf("unexpected finished move %+v", m)
+	}
+
+	measurements := func(bucketID influxdb.ID) []string {
+		iter, err := engine.TagValues(context.Background(), engine.org, bucketID, models.MeasurementTagKey, math.MinInt64, math.MaxInt64, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cursors.StringIteratorToSlice(iter)
+	}
+	if got, exp := measurements(engine.bucket), []string{"mem"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got source measurements %v, expected %v", got, exp)
+	}
+	if got, exp := measurements(dst), []string{"cpu"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got destination measurements %v, expected %v", got, exp)
+	}
+
+	moves, err := engine.FindSeriesMoves(context.Background(), influxdb.SeriesMoveFilter{OrgID: &engine.org})
+	if err != nil {
 		t.Fatal(err)
+	} else if len(moves) != 1 || moves[0].ID != m.ID {
+		t.Fatalf("unexpected moves %+v", moves)
 	}
 }
 
-func TestEngine_InitializeMetrics(t *testing.T) {
+func TestEngine_RebuildIndex(t *testing.T) {
 	engine := NewDefaultEngine()
-
+	defer engine.Close()
 	engine.MustOpen()
-	reg := prometheus.NewRegistry()
-	reg.MustRegister(engine.PrometheusCollectors()...)
 
-	mfs, err := reg.Gather()
+	other := engine.bucket + 1
+	p := func(bucketID influxdb.ID, m, v string) models.Point {
+		tags := map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m, "host": v}
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, bucketID),
+			models.NewTags(tags),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		p(engine.bucket, "cpu", "a"),
+		p(engine.bucket, "cpu", "b"),
+		p(engine.bucket, "mem", "a"),
+		p(other, "disk", "a"),
+	})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	files := promtest.MustFindMetric(t, mfs, "storage_tsm_files_total", prometheus.Labels{
-		"node_id":   fmt.Sprint(engine.nodeID),
-		"engine_id": fmt.Sprint(engine.engineID),
-		"level":     "1",
-	})
-	if m, got, exp := files, files.GetGauge().GetValue(), 0.0; got != exp {
-		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	r := &influxdb.IndexRebuild{OrgID: engine.org, BucketID: engine.bucket}
+	if err := engine.RebuildIndex(context.Background(), r); err != nil {
+		t.Fatal(err)
+	} else if r.ID == 0 || r.Status != influxdb.IndexRebuildRunning {
+		t.Fatalf("unexpected started rebuild %+v", r)
 	}
 
-	bytes := promtest.MustFindMetric(t, mfs, "storage_tsm_files_disk_bytes", prometheus.Labels{
-		"node_id":   fmt.Sprint(engine.nodeID),
-		"engine_id": fmt.Sprint(engine.engineID),
-		"level":     "1",
-	})
-	if m, got, exp := bytes, bytes.GetGauge().GetValue(), 0.0; got != exp {
-		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	deadline := time.Now().Add(10 * time.Second)
+	for r.Status == influxdb.IndexRebuildRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the rebuild to finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r, err = engine.FindIndexRebuildByID(context.Background(), r.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.Status != influxdb.IndexRebuildCompleted || r.Series != 3 || r.FinishedAt == nil {
+		t.Fatalf("unexpected finished rebuild %+v", r)
 	}
 
-	if err := engine.Close(); err != nil {
+	measurements := func(bucketID influxdb.ID) []string {
+		iter, err := engine.TagValues(context.Background(), engine.org, bucketID, models.MeasurementTagKey, math.MinInt64, math.MaxInt64, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cursors.StringIteratorToSlice(iter)
+	}
+	if got, exp := measurements(engine.bucket), []string{"cpu", "mem"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got rebuilt measurements %v, expected %v", got, exp)
+	}
+	if got, exp := measurements(other), []string{"disk"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got other measurements %v, expected %v", got, exp)
+	}
+
+	// The rebuilt index accepts writes.
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p(engine.bucket, "gpu", "a")}); err != nil {
 		t.Fatal(err)
 	}
+	if got, exp := measurements(engine.bucket), []string{"cpu", "gpu", "mem"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got measurements %v, expected %v", got, exp)
+	}
+
+	rebuilds, err := engine.FindIndexRebuilds(context.Background(), influxdb.IndexRebuildFilter{OrgID: &engine.org})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rebuilds) != 1 || rebuilds[0].ID != r.ID {
+		t.Fatalf("unexpected rebuilds %+v", rebuilds)
+	}
 }
 
-// Ensures that when a shard is closed, it removes any series meta-data
-// from the index.
-func TestEngineClose_RemoveIndex(t *testing.T) {
-	engine := NewDefaultEngine()
+func TestEngine_Generators(t *testing.T) {
+	path, err := ioutil.TempDir("", "storage_engine_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	start := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+	engine := storage.NewEngine(path, storage.NewConfig(),
+		storage.WithEngineID(rand.Int()),
+		storage.WithNodeID(rand.Int()),
+		storage.WithIDGenerator(influxdb.NewSequentialIDGenerator(0x1000)),
+		storage.WithTimeGenerator(influxdb.NewStepTimeGenerator(start, 0)),
+	)
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 	defer engine.Close()
-	engine.MustOpen()
 
-	pt := models.MustNewPoint(
-		"cpu",
-		models.Tags{
-			{Key: models.MeasurementTagKeyBytes, Value: []byte("cpu")},
-			{Key: []byte("host"), Value: []byte("server")},
-			{Key: models.FieldKeyTagKeyBytes, Value: []byte("value")},
-		},
-		map[string]interface{}{"value": 1.0},
-		time.Unix(1, 2),
-	)
+	r := &influxdb.IndexRebuild{OrgID: 1, BucketID: 2}
+	if err := engine.RebuildIndex(context.Background(), r); err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != 0x1000 {
+		t.Fatalf("got rebuild ID %s, expected %s", r.ID, influxdb.ID(0x1000))
+	}
+	if r.StartedAt == nil || !r.StartedAt.Equal(start) {
+		t.Fatalf("got rebuild start %v, expected %v", r.StartedAt, start)
+	}
+}
 
-	err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt})
+func TestEngine_Encryption_ReleasedOnClose(t *testing.T) {
+	keys, err := encryption.NewKeyRing(map[uint32][]byte{1: bytes.Repeat([]byte{1}, encryption.KeySize)})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
-		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	newEngine := func(opts ...storage.Option) (*storage.Engine, func()) {
+		path, err := ioutil.TempDir("", "storage_engine_test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		opts = append(opts, storage.WithEngineID(rand.Int()), storage.WithNodeID(rand.Int()))
+		return storage.NewEngine(path, storage.NewConfig(), opts...), func() { os.RemoveAll(path) }
 	}
 
-	// ensure the index gets loaded after closing and opening the shard
-	engine.Engine.Close() // Don't destroy temporary data.
-	engine.Open(context.Background())
+	encrypted, cleanup := newEngine(storage.WithKeyProvider(keys))
+	defer cleanup()
+	if err := encrypted.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
 
-	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
-		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	// An engine of the same process would otherwise encrypt its data with the
+	// keys of the encrypted engine.
+	plain, cleanup := newEngine()
+	defer cleanup()
+	if err := plain.Open(context.Background()); err == nil {
+		plain.Close()
+		t.Fatal("expected an error opening an unencrypted engine alongside an encrypted one")
 	}
-}
 
-func TestEngine_WALDisabled(t *testing.T) {
-	config := storage.NewConfig()
-	config.WAL.Enabled = false
+	if err := encrypted.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if encryption.Default() != nil {
+		t.Fatal("expected the cipher of the engine to be released on close")
+	}
+	if err := plain.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	plain.Close()
+}
 
-	engine := NewEngine(config, rand.Int(), rand.Int())
+func TestEngine_SubscribeWAL(t *testing.T) {
+	engine := NewDefaultEngine()
 	defer engine.Close()
 	engine.MustOpen()
 
-	pt := models.MustNewPoint(
-		"cpu",
-		models.Tags{
-			{Key: models.MeasurementTagKeyBytes, Value: []byte("cpu")},
-			{Key: []byte("host"), Value: []byte("server")},
-			{Key: models.FieldKeyTagKeyBytes, Value: []byte("value")},
-		},
-		map[string]interface{}{"value": 1.0},
-		time.Unix(1, 2),
-	)
+	sub, err := engine.SubscribeWAL("export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
 
-	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+	p := func(field string, v interface{}) models.Point {
+		tags := map[string]string{models.FieldKeyTagKey: field, models.MeasurementTagKey: "cpu", "host": "a"}
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(tags),
+			map[string]interface{}{field: v},
+			time.Unix(1, 0),
+		)
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("usage", 1.5), p("count", int64(2))}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	rec, err := sub.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Writes) != 1 || rec.Writes[0].OrgID != engine.org || rec.Writes[0].BucketID != engine.bucket {
+		t.Fatalf("unexpected writes %+v", rec.Writes)
+	}
+	var got []string
+	for _, p := range rec.Writes[0].Points {
+		got = append(got, p.String())
+	}
+	if exp := []string{"cpu,host=a count=2i,usage=1.5 1000000000"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got points %q, expected %q", got, exp)
+	}
+	if err := sub.Ack(rec.Offset); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.UnsubscribeWAL("export"); err != nil {
 		t.Fatal(err)
 	}
 }
 
-func TestEngine_WriteConflictingBatch(t *testing.T) {
+func TestEngine_WALRecoveryReport(t *testing.T) {
 	engine := NewDefaultEngine()
 	defer engine.Close()
 	engine.MustOpen()
 
-	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	for i := 0; i < 3; i++ {
+		tags := map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu"}
+		pt := models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(tags),
+			map[string]interface{}{"value": float64(i)},
+			time.Unix(int64(i), 0),
+		)
+		if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine.Engine.Close() // Don't remove the data
 
-	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
-		models.MustNewPoint(
-			name,
-			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+	// Corrupt the second entry of the WAL, so that the third is dropped.
+	segs, err := wal.SegmentFileNames(storage.NewConfig().GetWALPath(engine.path))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(segs) != 1 {
+		t.Fatalf("got %d WAL segments, expected 1", len(segs))
+	}
+	f, err := os.OpenFile(segs[0], os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := wal.NewWALSegmentReader(ioutil.NopCloser(f))
+	if !r.Next() {
+		t.Fatal("expected WAL entry")
+	}
+	if _, err := f.WriteAt([]byte{0xff, 0xff, 0xff, 0xff}, r.Count()+5); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	engine.MustOpen()
+	report, err := engine.WALRecoveryReport(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Segments) != 1 {
+		t.Fatalf("got %d segments, expected 1", len(report.Segments))
+	}
+	seg := report.Segments[0]
+	if seg.EntriesReplayed != 1 || seg.CorruptEntries != 1 || seg.EntriesDropped != 1 || seg.TruncatedBytes == 0 || seg.Corruption == "" {
+		t.Fatalf("unexpected segment report %+v", seg)
+	}
+	if exp := []influxdb.WALBucketRecovery{{
+		OrgID:           engine.org,
+		BucketID:        engine.bucket,
+		PointsRecovered: 1,
+		PointsDropped:   1,
+	}}; !reflect.DeepEqual(report.Buckets, exp) {
+		t.Fatalf("got buckets %+v, expected %+v", report.Buckets, exp)
+	}
+}
+
+func TestEngine_WritePoints_IdempotencyKey(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	p := func(host string) models.Point {
+		tags := map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(tags),
 			map[string]interface{}{"value": 1.0},
-			time.Unix(1, 2),
-		),
-		models.MustNewPoint(
-			name,
+			time.Unix(1, 0),
+		)
+	}
+
+	// The retry of the batch with host=a is dropped, but a batch with another
+	// key is written.
+	ctx := storage.NewContextWithIdempotencyKey(context.Background(), "batch-1")
+	for _, host := range []string{"a", "b"} {
+		if err := engine.Engine.WritePoints(ctx, []models.Point{p(host)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx = storage.NewContextWithIdempotencyKey(context.Background(), "batch-2")
+	if err := engine.Engine.WritePoints(ctx, []models.Point{p("c")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// The keys are remembered across a restart.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	engine.MustOpen()
+	if err := engine.Engine.WritePoints(ctx, []models.Point{p("d")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index after reopening", got, exp)
+	}
+}
+
+func TestEngine_WritePoints_Dedupe(t *testing.T) {
+	c := storage.NewConfig()
+	c.DedupeWindow = toml.Duration(time.Minute)
+	engine := NewEngine(c, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	p := func(v float64) models.Point {
+		tags := map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "a"}
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(tags),
+			map[string]interface{}{"value": v},
+			time.Unix(1, 0),
+		)
+	}
+
+	// The resent point is dropped without an error, but a point with another
+	// value is written.
+	for _, v := range []float64{1, 1, 2} {
+		if err := engine.Engine.WritePoints(context.Background(), []models.Point{p(v)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := engine.FindMeasurementWriteStats(context.Background(), influxdb.WriteStatsFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].TotalPoints != 2 {
+		t.Fatalf("got write stats %+v, expected 2 points written", stats)
+	}
+}
+
+func TestEngine_DeleteBucketRange_Measurement(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	p := func(m, tag string, ts int64) models.Point {
+		tags := map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m, "tag": tag}
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(tags),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, ts),
+		)
+	}
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		p("cpu", "a", 10),
+		p("cpu", "a", 20),
+		p("cpu", "b", 10),
+		p("mem", "a", 10),
+		p("mem", "b", 20),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(4); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// Only cpu,tag=b has no data left after the range is removed.
+	if err := engine.DeleteBucketRange(context.Background(), engine.org, engine.bucket, "cpu", 0, 15); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(3); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// Other measurements are left alone.
+	if err := engine.DeleteBucketRange(context.Background(), engine.org, engine.bucket, "cpu", math.MinInt64, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// An empty measurement removes the data of the whole bucket.
+	if err := engine.DeleteBucketRange(context.Background(), engine.org, engine.bucket, "", math.MinInt64, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(0); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_Checkpoint(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	write := func(e *storage.Engine) {
+		t.Helper()
+		err := e.WritePoints(context.TODO(), []models.Point{models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkpoint := func(e *storage.Engine, exp influxdb.CheckpointID) {
+		t.Helper()
+		id, err := e.Checkpoint(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		} else if id != exp {
+			t.Fatalf("got checkpoint %d, exp %d", id, exp)
+		}
+	}
+
+	checkpoint(engine.Engine, 0)
+	write(engine.Engine)
+	write(engine.Engine)
+	checkpoint(engine.Engine, 2)
+	checkpoint(engine.Engine, 2)
+
+	// Checkpoint IDs continue from the last checkpoint after reopening.
+	write(engine.Engine)
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	engine.Engine = storage.NewEngine(engine.path, storage.NewConfig(), storage.WithEngineID(engine.engineID), storage.WithNodeID(engine.nodeID))
+	engine.MustOpen()
+
+	if got, exp := engine.LastCheckpoint(), influxdb.CheckpointID(2); got != exp {
+		t.Fatalf("got last checkpoint %d, exp %d", got, exp)
+	}
+	write(engine.Engine)
+	checkpoint(engine.Engine, 3)
+}
+
+func TestEngine_CreateSnapshot(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{models.MustNewPoint(
+		tsdb.EncodeNameString(engine.org, engine.bucket),
+		models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := engine.CreateSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := manifest.CheckpointID, influxdb.CheckpointID(1); got != exp {
+		t.Fatalf("got checkpoint %d, exp %d", got, exp)
+	}
+	// The cache was written to a TSM file before the files were linked.
+	if got, exp := len(manifest.Files), 1; got != exp {
+		t.Fatalf("got %d files, exp %d: %v", got, exp, manifest.Files)
+	} else if f := manifest.Files[0]; filepath.Ext(f.Name) != "."+tsm1.TSMFileExtension || f.Size == 0 {
+		t.Fatalf("unexpected snapshot file %+v", f)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(engine.InternalBackupPath(manifest.ID), storage.SnapshotManifestFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved influxdb.SnapshotManifest
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(saved.Files, manifest.Files) || saved.ID != manifest.ID {
+		t.Fatalf("got saved manifest %+v, exp %+v", saved, *manifest)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.FetchBackupFile(context.Background(), manifest.ID, manifest.Files[0].Name, &buf); err != nil {
+		t.Fatal(err)
+	} else if got, exp := int64(buf.Len()), manifest.Files[0].Size; got != exp {
+		t.Fatalf("got %d bytes, exp %d", got, exp)
+	}
+}
+
+func TestEngine_ReadOnly(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+
+	write := func(e *storage.Engine, host string) {
+		t.Helper()
+		err := e.WritePoints(context.TODO(), []models.Point{models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Writes a point with a writable engine, and flushes it to a TSM file.
+	writeExternal := func(host string) {
+		t.Helper()
+		engine.Engine = storage.NewEngine(engine.path, storage.NewConfig(), storage.WithEngineID(engine.engineID), storage.WithNodeID(engine.nodeID))
+		engine.MustOpen()
+		write(engine.Engine, host)
+		if _, err := engine.Checkpoint(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if err := engine.Engine.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeExternal("a")
+
+	config := storage.NewConfig()
+	config.ReadOnly = true
+	config.ReadOnlyRefreshInterval = toml.Duration(10 * time.Millisecond)
+	ro := &Engine{path: engine.path, Engine: storage.NewEngine(engine.path, config, storage.WithEngineID(engine.engineID), storage.WithNodeID(engine.nodeID))}
+	ro.MustOpen()
+	defer ro.Engine.Close()
+
+	if got, exp := ro.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %v series, exp %v series in index", got, exp)
+	}
+
+	err := ro.WritePoints(context.TODO(), []models.Point{models.MustNewPoint(
+		tsdb.EncodeNameString(engine.org, engine.bucket),
+		models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "b"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)})
+	if err != storage.ErrEngineReadOnly {
+		t.Fatalf("got write error %v, exp %v", err, storage.ErrEngineReadOnly)
+	}
+	if err := ro.DeleteBucket(context.Background(), engine.org, engine.bucket); err != storage.ErrEngineReadOnly {
+		t.Fatalf("got delete error %v, exp %v", err, storage.ErrEngineReadOnly)
+	}
+	if err := ro.CompactBucket(context.Background(), engine.org, engine.bucket); err != storage.ErrEngineReadOnly {
+		t.Fatalf("got compaction error %v, exp %v", err, storage.ErrEngineReadOnly)
+	}
+
+	// Changes made to the files outside of the engine are picked up.
+	writeExternal("b")
+	for deadline := time.Now().Add(5 * time.Second); ro.SeriesCardinality() != 2; {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %v series, exp 2 series after refresh", ro.SeriesCardinality())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEngine_MeasurementCardinality(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	var points []models.Point
+	for _, host := range []string{"a", "b", "c"} {
+		points = append(points, models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		))
+	}
+	if err := engine.WritePoints(context.TODO(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	cardinality := func() []*influxdb.MeasurementCardinality {
+		t.Helper()
+		cards, err := engine.FindMeasurementCardinality(context.Background(), influxdb.CardinalityFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cards
+	}
+	if cards := cardinality(); len(cards) != 1 || cards[0].Series != 3 || cards[0].Tags[0].Values != 3 {
+		t.Fatalf("unexpected cardinality: %+v", cards)
+	}
+
+	// The sketches are rebuilt from the index if their file is missing.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := storage.NewConfig().GetCardinalityPath(engine.path)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected cardinality file to be saved: %v", err)
+	} else if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	engine.MustOpen()
+
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		if cards := cardinality(); len(cards) == 1 && cards[0].Series == 3 {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("unexpected cardinality after rebuild: %+v", cards)
+		}
+	}
+}
+
+func TestEngine_OpenClose(t *testing.T) {
+	engine := NewDefaultEngine()
+	engine.MustOpen()
+
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_InitializeMetrics(t *testing.T) {
+	engine := NewDefaultEngine()
+
+	engine.MustOpen()
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(engine.PrometheusCollectors()...)
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := promtest.MustFindMetric(t, mfs, "storage_tsm_files_total", prometheus.Labels{
+		"node_id":   fmt.Sprint(engine.nodeID),
+		"engine_id": fmt.Sprint(engine.engineID),
+		"level":     "1",
+	})
+	if m, got, exp := files, files.GetGauge().GetValue(), 0.0; got != exp {
+		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	}
+
+	bytes := promtest.MustFindMetric(t, mfs, "storage_tsm_files_disk_bytes", prometheus.Labels{
+		"node_id":   fmt.Sprint(engine.nodeID),
+		"engine_id": fmt.Sprint(engine.engineID),
+		"level":     "1",
+	})
+	if m, got, exp := bytes, bytes.GetGauge().GetValue(), 0.0; got != exp {
+		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	}
+
+	if err := engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_BucketMetrics(t *testing.T) {
+	config := storage.NewConfig()
+	config.BucketMetricLabels = []string{"team", "cost-center"}
+	engine := NewEngine(config, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	engine.SetBucketMetadata(engine.bucket, map[string]string{"team": "platform", "owner": "ops"})
+	var points []models.Point
+	for _, host := range []string{"server0", "server1"} {
+		points = append(points, models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		))
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), points); err != nil {
+		t.Fatal(err)
+	}
+	// The size of the data on disk is only known once it is in TSM files.
+	if _, err := engine.Checkpoint(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(engine.PrometheusCollectors()...)
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labels := prometheus.Labels{
+		"node_id":            fmt.Sprint(engine.nodeID),
+		"engine_id":          fmt.Sprint(engine.engineID),
+		"bucket":             engine.bucket.String(),
+		"bucket_team":        "platform",
+		"bucket_cost_center": "",
+	}
+	series := promtest.MustFindMetric(t, mfs, "storage_bucket_series", labels)
+	if got, exp := series.GetGauge().GetValue(), 2.0; got != exp {
+		t.Errorf("got %v series, expected %v", got, exp)
+	}
+	disk := promtest.MustFindMetric(t, mfs, "storage_bucket_disk_bytes", labels)
+	if got := disk.GetGauge().GetValue(); got <= 0 {
+		t.Errorf("got %v bytes on disk, expected more than 0", got)
+	}
+}
+
+func TestEngine_BucketUsage(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	write := func(host string, times ...int64) {
+		t.Helper()
+		var points []models.Point
+		for _, ts := range times {
+			points = append(points, models.MustNewPoint(
+				tsdb.EncodeNameString(engine.org, engine.bucket),
+				models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+				map[string]interface{}{"value": 1.0},
+				time.Unix(ts, 0),
+			))
+		}
+		if err := engine.Engine.WritePoints(context.TODO(), points); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The points of the bucket are counted in TSM files and the cache.
+	write("server0", 1, 2, 3)
+	if _, err := engine.Checkpoint(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	write("server1", 4, 5)
+
+	u, err := engine.BucketUsage(context.Background(), engine.org, engine.bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.BucketID != engine.bucket {
+		t.Errorf("got usage of bucket %s, exp %s", u.BucketID, engine.bucket)
+	}
+	if got, exp := u.Points, int64(5); got != exp {
+		t.Errorf("got %d points, exp %d", got, exp)
+	}
+	if got, exp := u.Series, int64(2); got != exp {
+		t.Errorf("got %d series, exp %d", got, exp)
+	}
+	if u.DiskBytes <= 0 || u.CacheBytes <= 0 {
+		t.Errorf("got %d bytes on disk and %d in the cache, exp both more than 0", u.DiskBytes, u.CacheBytes)
+	}
+
+	// Other buckets have no usage.
+	u, err = engine.BucketUsage(context.Background(), engine.org, engine.bucket+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *u != (influxdb.BucketUsage{BucketID: engine.bucket + 1}) {
+		t.Errorf("got usage %+v of empty bucket", *u)
+	}
+}
+
+func TestEngine_ExportBucket(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	points := []models.Point{
+		models.MustNewPoint(name, models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server 1"}), map[string]interface{}{"value": 1.5}, time.Unix(0, 10)),
+		models.MustNewPoint(name, models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server 1"}), map[string]interface{}{"value": 2.5}, time.Unix(0, 20)),
+		models.MustNewPoint(name, models.NewTags(map[string]string{models.FieldKeyTagKey: "count", models.MeasurementTagKey: "cpu", "host": "server 1"}), map[string]interface{}{"count": int64(3)}, time.Unix(0, 10)),
+		models.MustNewPoint(name, models.NewTags(map[string]string{models.FieldKeyTagKey: "msg", models.MeasurementTagKey: "log,s"}), map[string]interface{}{"msg": `say "hi"`}, time.Unix(0, 10)),
+	}
+	if err := engine.Engine.WritePoints(context.TODO(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.ExportBucket(context.Background(), engine.org, engine.bucket, 0, 20, "", &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines, tokens []string
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		if strings.HasPrefix(line, influxdb.BucketExportCursorPrefix) {
+			tokens = append(tokens, strings.TrimPrefix(line, influxdb.BucketExportCursorPrefix))
+			continue
+		}
+		lines = append(lines, line)
+	}
+	exp := []string{
+		`cpu,host=server\ 1 count=3i 10`,
+		`cpu,host=server\ 1 value=1.5 10`,
+		`log\,s msg="say \"hi\"" 10`,
+	}
+	if !reflect.DeepEqual(lines, exp) {
+		t.Fatalf("got lines %q, exp %q", lines, exp)
+	}
+	if len(tokens) != 3 {
+		t.Fatalf("got %d cursor tokens, exp 3", len(tokens))
+	}
+
+	// The exported line protocol is parsed back into the points.
+	if _, err := models.ParsePoints(buf.Bytes(), []byte(name)); err != nil {
+		t.Fatal(err)
+	}
+
+	// An export resumes after the series of its cursor.
+	buf.Reset()
+	if err := engine.ExportBucket(context.Background(), engine.org, engine.bucket, 0, 20, tokens[0], &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); strings.Contains(got, "count=") || !strings.Contains(got, "value=1.5") {
+		t.Fatalf("got resumed export %q", got)
+	}
+
+	if err := engine.ExportBucket(context.Background(), engine.org, engine.bucket, 0, 20, "!", &buf); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v for invalid cursor, exp invalid", err)
+	}
+}
+
+// Ensures that when a shard is closed, it removes any series meta-data
+// from the index.
+func TestEngineClose_RemoveIndex(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.Tags{
+			{Key: models.MeasurementTagKeyBytes, Value: []byte("cpu")},
+			{Key: []byte("host"), Value: []byte("server")},
+			{Key: models.FieldKeyTagKeyBytes, Value: []byte("value")},
+		},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// ensure the index gets loaded after closing and opening the shard
+	engine.Engine.Close() // Don't destroy temporary data.
+	engine.Open(context.Background())
+
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_WALDisabled(t *testing.T) {
+	config := storage.NewConfig()
+	config.WAL.Enabled = false
+
+	engine := NewEngine(config, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	pt := models.MustNewPoint(
+		"cpu",
+		models.Tags{
+			{Key: models.MeasurementTagKeyBytes, Value: []byte("cpu")},
+			{Key: []byte("host"), Value: []byte("server")},
+			{Key: models.FieldKeyTagKeyBytes, Value: []byte("value")},
+		},
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_WriteHints(t *testing.T) {
+	config := storage.NewConfig()
+	config.Engine.Cache.MaxMemorySize = toml.Size(16 << 10)
+
+	engine := NewEngine(config, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	if got, exp := engine.WriteHints(), (storage.WriteHints{BatchSize: storage.DefaultWriteHintBatchSize}); got != exp {
+		t.Fatalf("got hints %+v, expected %+v", got, exp)
+	}
+
+	// Fill the cache until clients are asked to back off.
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	for i := 0; engine.WriteHints().Backoff == 0; i++ {
+		err := engine.Engine.WritePoints(context.TODO(), []models.Point{models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(int64(i), 0),
+		)})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := engine.WriteHints(); !got.Compress || got.BatchSize <= storage.DefaultWriteHintBatchSize {
+		t.Fatalf("unexpected hints %+v", got)
+	}
+}
+
+func TestEngine_WriteConflictingBatch(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		),
+		models.MustNewPoint(
+			name,
 			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
 			map[string]interface{}{"value": 2},
 			time.Unix(1, 2),
@@ -490,6 +1493,278 @@ func TestEngine_WriteConflictingBatch(t *testing.T) {
 	}
 }
 
+func TestEngine_WritePoints_FieldTypeConflict(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	newPoint := func(host string, v interface{}) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": v},
+			time.Unix(1, 2),
+		)
+	}
+
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{newPoint("a", 1.0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The point conflicting with the type of its series is dropped, and the
+	// other point is written.
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{newPoint("b", 1.0), newPoint("a", int64(1))})
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatal("expected partial write error. got:", err)
+	}
+	if len(perr.Points) != 1 || perr.Points[0].Index != 1 || !strings.Contains(perr.Points[0].Reason, "type mismatch") {
+		t.Fatalf("got dropped points %+v, expected a type mismatch of point 1", perr.Points)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_WritePoints_RetentionWindow(t *testing.T) {
+	c := storage.NewConfig()
+	c.FutureWriteTolerance = toml.Duration(time.Hour)
+	engine := NewEngine(c, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+	engine.SetBucketRetentionPeriod(engine.bucket, 24*time.Hour)
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	newPoint := func(host string, ts time.Time) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			ts,
+		)
+	}
+
+	now := time.Now()
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		newPoint("a", now),
+		newPoint("b", now.Add(-48*time.Hour)),
+		newPoint("c", now.Add(2*time.Hour)),
+		newPoint("d", now.Add(-time.Hour)),
+	})
+	perr, ok := err.(tsdb.PartialWriteError)
+	if !ok {
+		t.Fatal("expected partial write error. got:", err)
+	} else if perr.Dropped != 2 {
+		t.Fatalf("got %d dropped points, expected 2: %v", perr.Dropped, perr)
+	} else if len(perr.Points) != 2 || perr.Points[0].Index != 1 || perr.Points[1].Index != 2 {
+		t.Fatalf("got dropped points %+v, expected points 1 and 2", perr.Points)
+	}
+
+	// Removing the retention period accepts old points.
+	engine.SetBucketRetentionPeriod(engine.bucket, 0)
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{newPoint("b", now.Add(-48*time.Hour))}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEngine_WritePoints_CardinalityLimits(t *testing.T) {
+	config := storage.NewConfig()
+	config.MaxSeriesPerBucket = 3
+	config.MaxValuesPerTag = 2
+
+	engine := NewEngine(config, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	p := func(m, host, field string) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: field, models.MeasurementTagKey: m, "host": host}),
+			map[string]interface{}{field: 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	limitError := func(err error) *storage.CardinalityLimitError {
+		t.Helper()
+		if code := influxdb.ErrorCode(err); code != influxdb.EUnprocessableEntity {
+			t.Fatalf("got error code %q, exp %q: %v", code, influxdb.EUnprocessableEntity, err)
+		}
+		var limitErr *storage.CardinalityLimitError
+		if !errors.As(err.(*influxdb.Error).Err, &limitErr) {
+			t.Fatalf("got error %#v, exp a cardinality limit error", err)
+		}
+		return limitErr
+	}
+
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("cpu", "a", "value"), p("cpu", "b", "value"), p("cpu", "a", "value")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A third value of host is rejected.
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("cpu", "c", "value"), p("cpu", "a", "value")})
+	exp := &storage.CardinalityLimitError{Limit: storage.LimitMaxValuesPerTag, Measurement: "cpu", Tag: "host", Value: "c", N: 2, Max: 2}
+	if got := limitError(err); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got error %#v, exp %#v", got, exp)
+	}
+
+	// A new series with existing tag values is accepted.
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("cpu", "a", "idle")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fourth series is rejected.
+	err = engine.Engine.WritePoints(context.TODO(), []models.Point{p("mem", "b", "value")})
+	exp = &storage.CardinalityLimitError{Limit: storage.LimitMaxSeriesPerBucket, Measurement: "mem", N: 3, Max: 3}
+	if got := limitError(err); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got error %#v, exp %#v", got, exp)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(3); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_WritePoints_BucketSeriesLimit(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+	engine.SetBucketSeriesLimit(engine.bucket, 1)
+
+	p := func(host string) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, engine.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("a")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second series is rejected by the bucket's own limit.
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("b")})
+	if code := influxdb.ErrorCode(err); code != influxdb.EUnprocessableEntity {
+		t.Fatalf("got error code %q, exp %q: %v", code, influxdb.EUnprocessableEntity, err)
+	}
+
+	// Removing the limit accepts it.
+	engine.SetBucketSeriesLimit(engine.bucket, 0)
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p("b")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_OrgQuotas(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+	engine.SetOrgQuota(&influxdb.OrgQuota{OrgID: engine.org, MaxSeries: 1, MaxBuckets: 1, MaxQueriesPerMinute: 2})
+
+	p := func(bucketID influxdb.ID, host string) models.Point {
+		return models.MustNewPoint(
+			tsdb.EncodeNameString(engine.org, bucketID),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+	}
+
+	// The series quota counts the series of every bucket of the organization.
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p(engine.bucket, "a")}); err != nil {
+		t.Fatal(err)
+	}
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{p(engine.bucket+1, "b")})
+	if code := influxdb.ErrorCode(err); code != influxdb.EUnprocessableEntity {
+		t.Fatalf("got error code %q, exp %q: %v", code, influxdb.EUnprocessableEntity, err)
+	}
+
+	if err := engine.CheckBucketQuota(engine.org, 0); err != nil {
+		t.Fatal(err)
+	}
+	if code := influxdb.ErrorCode(engine.CheckBucketQuota(engine.org, 1)); code != influxdb.EForbidden {
+		t.Fatalf("got error code %q, exp %q", code, influxdb.EForbidden)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := engine.AdmitOrgQuery(engine.org); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if code := influxdb.ErrorCode(engine.AdmitOrgQuery(engine.org)); code != influxdb.ETooManyRequests {
+		t.Fatalf("got error code %q, exp %q", code, influxdb.ETooManyRequests)
+	}
+	if err := engine.AdmitOrgQuery(engine.org + 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Writes are rejected once the data of the organization fills its storage
+	// quota.
+	if _, err := engine.Checkpoint(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	engine.SetOrgQuota(&influxdb.OrgQuota{OrgID: engine.org, MaxStorageBytes: 1})
+	err = engine.Engine.WritePoints(context.TODO(), []models.Point{p(engine.bucket, "a")})
+	if code := influxdb.ErrorCode(err); code != influxdb.EForbidden {
+		t.Fatalf("got error code %q, exp %q: %v", code, influxdb.EForbidden, err)
+	}
+
+	// Removing the quota accepts them.
+	engine.SetOrgQuota(&influxdb.OrgQuota{OrgID: engine.org})
+	if err := engine.Engine.WritePoints(context.TODO(), []models.Point{p(engine.bucket+1, "b")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := engine.SeriesCardinality(), int64(2); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_WritePoints_BucketPrecision(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+	engine.SetBucketPrecision(engine.bucket, time.Second)
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	tags := models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "a"})
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{
+		models.MustNewPoint(name, tags, map[string]interface{}{"value": 1.0}, time.Unix(10, 999999999)),
+		models.MustNewPoint(name, tags, map[string]interface{}{"value": 2.0}, time.Unix(-10, -1)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	itr, err := engine.CreateCursorIterator(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+		Name:      []byte(name),
+		Tags:      tags,
+		Field:     "value",
+		StartTime: math.MinInt64,
+		EndTime:   math.MaxInt64,
+		Ascending: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cur.Close()
+
+	a := cur.(cursors.FloatArrayCursor).Next()
+	if got, exp := a.Timestamps, []int64{-11e9, 10e9}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got timestamps %v, expected %v", got, exp)
+	}
+}
+
 // BenchmarkWritePoints_100K demonstrates the impact that batch size has on
 // writing a fixed number of points into storage. In this case 100K points are
 // written according to varying batch sizes.
@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// compactIdempotencyKeysMin is the number of records below which the file of
+// idempotency keys is never compacted.
+const compactIdempotencyKeysMin = 1024
+
+type idempotencyKeyKey struct{}
+
+// NewContextWithIdempotencyKey returns a new context with key, identifying the
+// batch of points written with the context. The engine drops a batch written
+// with the same key to the same bucket as a recent batch, so that clients can
+// retry writes that timed out without writing the points twice.
+func NewContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key of ctx, or an empty
+// string if none has been set.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+// idempotentWrite is the write of a batch with an idempotency key.
+type idempotentWrite struct {
+	key  string
+	at   time.Time
+	done chan struct{}
+	err  error // the error of the write, once done is closed
+}
+
+// idempotencyKeys records the keys of the batches written recently.
+//
+// Once opened, the key of each batch that wrote points is appended to a file
+// before the write returns, and the file is replayed when the keys are opened
+// again, so that batches retried across a restart are dropped too. A batch is
+// written before its key is recorded, so a batch retried after a crash
+// between the two is written again.
+type idempotencyKeys struct {
+	ttl time.Duration
+	max int
+
+	mu     sync.Mutex
+	writes map[string]*idempotentWrite
+	order  []*idempotentWrite // in the order the writes started
+
+	fileMu  sync.Mutex
+	path    string
+	f       *os.File
+	records int // the number of records in f
+	buf     []byte
+
+	logger *zap.Logger
+}
+
+func newIdempotencyKeys(ttl time.Duration, max int) *idempotencyKeys {
+	return &idempotencyKeys{
+		ttl:    ttl,
+		max:    max,
+		writes: make(map[string]*idempotentWrite),
+		logger: zap.NewNop(),
+	}
+}
+
+// write calls fn to write the batch with key, unless a batch with key has been
+// written in the last ttl. A duplicate batch waits for the write of the first
+// and returns its error, and write returns true. Keys of writes that failed
+// without writing any points are forgotten, so the batch can be retried.
+func (k *idempotencyKeys) write(ctx context.Context, key string, now time.Time, fn func() error) (bool, error) {
+	k.mu.Lock()
+	k.expire(now)
+	w, dup := k.writes[key]
+	if !dup {
+		w = &idempotentWrite{key: key, at: now, done: make(chan struct{})}
+		k.writes[key] = w
+		k.order = append(k.order, w)
+	}
+	k.mu.Unlock()
+
+	if dup {
+		return true, k.wait(ctx, w)
+	}
+
+	w.err = fn()
+	if !pointsWritten(w.err) {
+		k.mu.Lock()
+		if k.writes[key] == w {
+			delete(k.writes, key)
+		}
+		k.mu.Unlock()
+	} else if err := k.append(w); err != nil {
+		// The points are written, so the write succeeds, but a retry after a
+		// restart is not recognized.
+		k.logger.Warn("Unable to record idempotency key", zap.Error(err))
+	}
+	close(w.done)
+	return false, w.err
+}
+
+// wait waits for the first write of a batch to complete.
+func (k *idempotencyKeys) wait(ctx context.Context, w *idempotentWrite) error {
+	select {
+	case <-w.done:
+		return w.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// expire forgets the keys of writes started before now-ttl, and the oldest
+// keys beyond max. It must be called with mu held.
+func (k *idempotencyKeys) expire(now time.Time) {
+	var i int
+	for ; i < len(k.order); i++ {
+		w := k.order[i]
+		if !w.at.Before(now.Add(-k.ttl)) && (k.max <= 0 || len(k.order)-i < k.max) {
+			break
+		}
+		if k.writes[w.key] == w {
+			delete(k.writes, w.key)
+		}
+	}
+	if i > 0 {
+		k.order = append(k.order[:0], k.order[i:]...)
+	}
+}
+
+// Open replays the keys recorded in the file at path which have not expired
+// by now, and records the keys of later writes in it.
+func (k *idempotencyKeys) Open(path string, now time.Time) error {
+	k.fileMu.Lock()
+	defer k.fileMu.Unlock()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	k.mu.Lock()
+	k.writes = make(map[string]*idempotentWrite)
+	k.order = nil
+	for len(data) > 0 {
+		w, n := decodeIdempotentWrite(data)
+		if n == 0 {
+			// The last record was only partly written.
+			k.logger.Warn("Truncated idempotency keys file", zap.String("path", path), zap.Int("bytes", len(data)))
+			break
+		}
+		data = data[n:]
+		k.writes[w.key] = w
+		k.order = append(k.order, w)
+	}
+	k.expire(now)
+	k.mu.Unlock()
+
+	k.path = path
+	return k.compact()
+}
+
+// Close closes the file recording the keys. The keys are kept in memory.
+func (k *idempotencyKeys) Close() error {
+	k.fileMu.Lock()
+	defer k.fileMu.Unlock()
+
+	if k.f == nil {
+		return nil
+	}
+	err := k.f.Close()
+	k.f = nil
+	return err
+}
+
+// append durably records the key of w, if the keys have been opened.
+func (k *idempotencyKeys) append(w *idempotentWrite) error {
+	k.fileMu.Lock()
+	defer k.fileMu.Unlock()
+
+	if k.f == nil {
+		return nil
+	}
+
+	k.buf = appendIdempotentWrite(k.buf[:0], w)
+	if _, err := k.f.Write(k.buf); err != nil {
+		return err
+	} else if err := k.f.Sync(); err != nil {
+		return err
+	}
+	k.records++
+
+	// Records of expired keys are dropped once they are most of the file.
+	k.mu.Lock()
+	n := len(k.writes)
+	k.mu.Unlock()
+	if k.records >= compactIdempotencyKeysMin && k.records > 2*n {
+		return k.compact()
+	}
+	return nil
+}
+
+// compact replaces the file with the records of the keys in memory. It must
+// be called with fileMu held.
+func (k *idempotencyKeys) compact() error {
+	k.mu.Lock()
+	var buf []byte
+	var n int
+	for _, w := range k.order {
+		if k.writes[w.key] == w {
+			buf = appendIdempotentWrite(buf, w)
+			n++
+		}
+	}
+	k.mu.Unlock()
+
+	tmpPath := k.path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := os.Rename(tmpPath, k.path); err != nil {
+		f.Close()
+		return err
+	}
+
+	if k.f != nil {
+		k.f.Close()
+	}
+	k.f, k.records = f, n
+	return nil
+}
+
+// appendIdempotentWrite appends the record of the key of w to b: the time the
+// write started, the key, and the error of the write, each of the latter
+// prefixed by its length.
+func appendIdempotentWrite(b []byte, w *idempotentWrite) []byte {
+	var msg string
+	if w.err != nil {
+		msg = w.err.Error()
+	}
+
+	var buf [binary.MaxVarintLen64]byte
+	b = appendUint64(b, uint64(w.at.UnixNano()))
+	b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(w.key)))]...)
+	b = append(b, w.key...)
+	b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(msg)))]...)
+	return append(b, msg...)
+}
+
+// decodeIdempotentWrite decodes the write of the record at the start of b,
+// and returns the length of the record, or 0 if b holds no whole record. The
+// error of a write that wrote some of its points is returned as an
+// EUnprocessableEntity error with its message.
+func decodeIdempotentWrite(b []byte) (*idempotentWrite, int) {
+	if len(b) < 8 {
+		return nil, 0
+	}
+	at := int64(binary.BigEndian.Uint64(b))
+	n := 8
+
+	var fields [2]string
+	for i := range fields {
+		l, m := binary.Uvarint(b[n:])
+		if m <= 0 || uint64(len(b)-n-m) < l {
+			return nil, 0
+		}
+		n += m
+		fields[i] = string(b[n : n+int(l)])
+		n += int(l)
+	}
+
+	w := &idempotentWrite{key: fields[0], at: time.Unix(0, at), done: make(chan struct{})}
+	if fields[1] != "" {
+		w.err = &influxdb.Error{Code: influxdb.EUnprocessableEntity, Msg: fields[1]}
+	}
+	close(w.done)
+	return w, n
+}
+
+// pointsWritten reports whether a write that returned err wrote any points.
+func pointsWritten(err error) bool {
+	if err == nil {
+		return true
+	}
+	if _, ok := err.(tsdb.PartialWriteError); ok {
+		return true
+	}
+	return influxdb.ErrorCode(err) == influxdb.EUnprocessableEntity
+}
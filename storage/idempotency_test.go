@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestIdempotencyKeys_Write(t *testing.T) {
+	k := newIdempotencyKeys(time.Minute, 2)
+	now := time.Unix(0, 0)
+
+	var n int
+	write := func(err error) func() error {
+		return func() error {
+			n++
+			return err
+		}
+	}
+
+	check := func(key string, now time.Time, fn func() error, expDup bool, expN int) {
+		t.Helper()
+		dup, _ := k.write(context.Background(), key, now, fn)
+		if dup != expDup {
+			t.Fatalf("write %q: got duplicate %v, expected %v", key, dup, expDup)
+		}
+		if n != expN {
+			t.Fatalf("write %q: got %d writes, expected %d", key, n, expN)
+		}
+	}
+
+	check("a", now, write(nil), false, 1)
+	check("a", now.Add(30*time.Second), write(nil), true, 1)
+
+	// Writes that dropped some points are not retried, but writes that
+	// failed are.
+	check("b", now, write(tsdb.PartialWriteError{Dropped: 1}), false, 2)
+	check("b", now, write(nil), true, 2)
+	check("c", now, write(errors.New("disk full")), false, 3)
+	check("c", now, write(nil), false, 4)
+
+	// The oldest keys are forgotten beyond the limit, and all keys after the
+	// TTL.
+	check("a", now, write(nil), false, 5)
+	check("c", now.Add(2*time.Minute), write(nil), false, 6)
+}
+
+func TestIdempotencyKeys_WriteConcurrent(t *testing.T) {
+	k := newIdempotencyKeys(time.Minute, 0)
+	now := time.Unix(0, 0)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	errFirst := tsdb.PartialWriteError{Dropped: 1}
+	go k.write(context.Background(), "a", now, func() error {
+		close(started)
+		<-release
+		return errFirst
+	})
+	<-started
+
+	done := make(chan error)
+	go func() {
+		dup, err := k.write(context.Background(), "a", now, func() error {
+			t.Error("duplicate batch was written")
+			return nil
+		})
+		if !dup {
+			t.Error("expected duplicate")
+		}
+		done <- err
+	}()
+
+	close(release)
+	if err := <-done; err == nil || err.Error() != errFirst.Error() {
+		t.Fatalf("got error %v, expected %v", err, errFirst)
+	}
+}
+
+func TestIdempotencyKeys_Open(t *testing.T) {
+	dir, err := ioutil.TempDir("", "idempotency-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys")
+	now := time.Unix(0, 0)
+
+	k := newIdempotencyKeys(time.Minute, 0)
+	if err := k.Open(path, now); err != nil {
+		t.Fatal(err)
+	}
+	write := func(k *idempotencyKeys, key string, now time.Time, err error) (bool, error) {
+		return k.write(context.Background(), key, now, func() error { return err })
+	}
+	errPartial := tsdb.PartialWriteError{Reason: "field type conflict", Dropped: 1}
+	write(k, "a", now, nil)
+	write(k, "b", now.Add(30*time.Second), errPartial)
+	write(k, "c", now, errors.New("disk full"))
+	if err := k.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Written keys are replayed with the errors of their writes, but keys of
+	// failed writes are not, and expired keys are dropped.
+	k = newIdempotencyKeys(time.Minute, 0)
+	if err := k.Open(path, now.Add(45*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if dup, err := write(k, "a", now.Add(45*time.Second), nil); !dup || err != nil {
+		t.Fatalf("a: got duplicate %v, error %v", dup, err)
+	}
+	if dup, err := write(k, "b", now.Add(45*time.Second), nil); !dup || err == nil || err.Error() != errPartial.Error() {
+		t.Fatalf("b: got duplicate %v, error %v", dup, err)
+	}
+	if dup, _ := write(k, "c", now.Add(45*time.Second), nil); dup {
+		t.Fatal("c: got duplicate")
+	}
+	if err := k.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	k = newIdempotencyKeys(time.Minute, 0)
+	if err := k.Open(path, now.Add(75*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	if dup, _ := write(k, "a", now.Add(75*time.Second), nil); dup {
+		t.Fatal("a: got duplicate after expiry")
+	}
+	if dup, _ := write(k, "b", now.Add(75*time.Second), nil); !dup {
+		t.Fatal("b: expected duplicate")
+	}
+}
+
+func TestIdempotencyKeys_OpenTruncated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "idempotency-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keys")
+	now := time.Unix(0, 0)
+
+	// The last record is partly written.
+	data := appendIdempotentWrite(nil, &idempotentWrite{key: "a", at: now})
+	data = appendIdempotentWrite(data, &idempotentWrite{key: "b", at: now})
+	if err := ioutil.WriteFile(path, data[:len(data)-1], 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	k := newIdempotencyKeys(time.Minute, 0)
+	if err := k.Open(path, now); err != nil {
+		t.Fatal(err)
+	}
+	defer k.Close()
+	if dup, _ := k.write(context.Background(), "a", now, func() error { return nil }); !dup {
+		t.Fatal("a: expected duplicate")
+	}
+	if dup, _ := k.write(context.Background(), "b", now, func() error { return nil }); dup {
+		t.Fatal("b: got duplicate")
+	}
+}
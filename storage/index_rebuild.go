@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/snowflake"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.IndexRebuildService = (*Engine)(nil)
+
+// indexRebuildBatchSize is the number of series added to a rebuilt index at a
+// time.
+const indexRebuildBatchSize = 10000
+
+// indexRebuilds holds the index rebuilds started since the engine was opened.
+type indexRebuilds struct {
+	mu       sync.RWMutex
+	idGen    influxdb.IDGenerator
+	rebuilds map[influxdb.ID]*influxdb.IndexRebuild
+}
+
+func newIndexRebuilds() *indexRebuilds {
+	return &indexRebuilds{
+		idGen:    snowflake.NewIDGenerator(),
+		rebuilds: make(map[influxdb.ID]*influxdb.IndexRebuild),
+	}
+}
+
+// update applies fn to the index rebuild with id.
+func (s *indexRebuilds) update(id influxdb.ID, fn func(r *influxdb.IndexRebuild)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.rebuilds[id])
+}
+
+// RebuildIndex starts rebuilding the series of a bucket in the index from its
+// TSM data. The series of other buckets are kept.
+//
+// The index continues to serve reads and writes while the new index is built,
+// and is then replaced by it. Only one index rebuild runs at a time. Index
+// rebuilds are kept in memory, so they are forgotten when the process exits,
+// and rebuilds in progress fail when the engine is closed.
+func (e *Engine) RebuildIndex(ctx context.Context, r *influxdb.IndexRebuild) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return ErrEngineReadOnly
+	}
+
+	e.rebuilds.mu.Lock()
+	for _, rb := range e.rebuilds.rebuilds {
+		if rb.Status == influxdb.IndexRebuildRunning {
+			e.rebuilds.mu.Unlock()
+			return &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "an index rebuild is already running",
+			}
+		}
+	}
+	now := e.timeGen.Now().UTC()
+	r.ID = e.rebuilds.idGen.ID()
+	r.Status = influxdb.IndexRebuildRunning
+	r.Error = ""
+	r.Series = 0
+	r.StartedAt, r.FinishedAt = &now, nil
+	rb := *r
+	e.rebuilds.rebuilds[rb.ID] = &rb
+	e.rebuilds.mu.Unlock()
+
+	// The rebuild outlives the request that started it, but not the engine.
+	rebuildCtx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		select {
+		case <-closing:
+			cancel()
+		case <-rebuildCtx.Done():
+		}
+	}()
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		e.runIndexRebuild(rebuildCtx, rb)
+	}()
+	return nil
+}
+
+// runIndexRebuild rebuilds the index, recording the outcome.
+func (e *Engine) runIndexRebuild(ctx context.Context, r influxdb.IndexRebuild) {
+	log := e.logger.With(zap.String("rebuild_id", r.ID.String()),
+		zap.String("bucket_id", r.BucketID.String()))
+	log.Info("Rebuilding index")
+
+	series, err := e.rebuildIndex(ctx, r)
+
+	now := e.timeGen.Now().UTC()
+	e.rebuilds.update(r.ID, func(rb *influxdb.IndexRebuild) {
+		rb.Series = series
+		rb.FinishedAt = &now
+		if err != nil {
+			rb.Status = influxdb.IndexRebuildFailed
+			rb.Error = err.Error()
+		} else {
+			rb.Status = influxdb.IndexRebuildCompleted
+		}
+	})
+
+	if err != nil {
+		log.Info("Unable to rebuild index", zap.Error(err))
+		return
+	}
+	log.Info("Rebuilt index", zap.Int("series", series))
+}
+
+// rebuildIndex rebuilds the index with the series of other buckets taken from
+// the index, and those of the bucket taken from its TSM data. It returns the
+// number of series of the bucket.
+func (e *Engine) rebuildIndex(ctx context.Context, r influxdb.IndexRebuild) (int, error) {
+	name := tsdb.EncodeName(e.storageOrgID(r.OrgID, r.BucketID), r.BucketID)
+	prefix := models.EscapeMeasurement(name[:])
+
+	var (
+		ids     []uint64
+		indexed bool
+		after   []byte
+		series  int
+	)
+	next := func() (*tsdb.SeriesCollection, error) {
+		// The series of other buckets are taken from the index first. They are
+		// listed once the rebuild records changes to the index, so that none
+		// are missed.
+		if !indexed {
+			if ids == nil {
+				ids = e.index.SeriesIDSet().Slice()
+			}
+			collection := &tsdb.SeriesCollection{}
+			for len(ids) > 0 && collection.Length() < indexRebuildBatchSize {
+				key := e.sfile.SeriesKey(tsdb.NewSeriesID(ids[0]))
+				ids = ids[1:]
+				if len(key) == 0 {
+					continue
+				}
+				seriesName, tags := tsdb.ParseSeriesKey(key)
+				if bytes.Equal(seriesName, name[:]) {
+					continue
+				}
+				collection.Keys = append(collection.Keys, models.MakeKey(seriesName, tags))
+				collection.Names = append(collection.Names, seriesName)
+				collection.Tags = append(collection.Tags, tags)
+				collection.Types = append(collection.Types, e.sfile.SeriesIDTypedBySeriesKey(key).Type())
+			}
+			if collection.Length() > 0 {
+				return collection, nil
+			}
+
+			// The data written to the cache so far is read from TSM files, and
+			// later writes are recorded by the rebuild.
+			indexed = true
+			if err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusIndexRebuild); err != nil {
+				return nil, err
+			}
+		}
+
+		collection, last, err := e.engine.PrefixSeries(ctx, prefix, after, indexRebuildBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		after = last
+		series += collection.Length()
+		return collection, nil
+	}
+
+	err := e.index.Rebuild(ctx, next)
+	return series, err
+}
+
+// FindIndexRebuildByID returns the index rebuild with id.
+func (e *Engine) FindIndexRebuildByID(ctx context.Context, id influxdb.ID) (*influxdb.IndexRebuild, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.rebuilds.mu.RLock()
+	defer e.rebuilds.mu.RUnlock()
+	r, ok := e.rebuilds.rebuilds[id]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "index rebuild not found",
+		}
+	}
+	rb := *r
+	return &rb, nil
+}
+
+// FindIndexRebuilds returns the index rebuilds matching filter, in the order
+// they were started.
+func (e *Engine) FindIndexRebuilds(ctx context.Context, filter influxdb.IndexRebuildFilter) ([]*influxdb.IndexRebuild, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.rebuilds.mu.RLock()
+	defer e.rebuilds.mu.RUnlock()
+	rebuilds := make([]*influxdb.IndexRebuild, 0, len(e.rebuilds.rebuilds))
+	for _, r := range e.rebuilds.rebuilds {
+		if filter.OrgID != nil && r.OrgID != *filter.OrgID {
+			continue
+		}
+		rb := *r
+		rebuilds = append(rebuilds, &rb)
+	}
+	sort.Slice(rebuilds, func(i, j int) bool { return rebuilds[i].ID < rebuilds[j].ID })
+	return rebuilds, nil
+}
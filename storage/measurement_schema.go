@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+// schemaDataTypes maps the types of parsed field values to the data types of
+// measurement schemas.
+var schemaDataTypes = map[models.FieldType]influxdb.SchemaDataType{
+	models.Float:    influxdb.SchemaDataTypeFloat,
+	models.Integer:  influxdb.SchemaDataTypeInteger,
+	models.Unsigned: influxdb.SchemaDataTypeUnsigned,
+	models.String:   influxdb.SchemaDataTypeString,
+	models.Boolean:  influxdb.SchemaDataTypeBoolean,
+}
+
+// CheckPointSchema returns why p does not conform to its measurement schema
+// in schemas, or an empty string if it does.
+func CheckPointSchema(p models.Point, schemas map[string]*influxdb.MeasurementSchema) string {
+	tags := p.Tags()
+	measurement := string(tags.Get(models.MeasurementTagKeyBytes))
+	ms := schemas[measurement]
+	if ms == nil {
+		return fmt.Sprintf("measurement %q is not in the schema of the bucket", measurement)
+	}
+
+	for _, t := range tags {
+		k := string(t.Key)
+		if k == models.MeasurementTagKey || k == models.FieldKeyTagKey {
+			continue
+		}
+		if c := ms.Column(k); c == nil || c.Type != influxdb.SchemaColumnTypeTag {
+			return fmt.Sprintf("tag %q is not in the schema of measurement %q", k, measurement)
+		}
+	}
+
+	iter := p.FieldIterator()
+	for iter.Next() {
+		k := string(iter.FieldKey())
+		c := ms.Column(k)
+		if c == nil || c.Type != influxdb.SchemaColumnTypeField {
+			return fmt.Sprintf("field %q is not in the schema of measurement %q", k, measurement)
+		}
+		if dt := schemaDataTypes[iter.Type()]; dt != c.DataType {
+			return fmt.Sprintf("field %q of measurement %q has type %s, expected %s", k, measurement, dt, c.DataType)
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"sync"
 
+	"github.com/influxdata/influxdb"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -11,8 +12,11 @@ import (
 // storage.Engine instantiations. This allows multiple Engines to be
 // monitored within the same process.
 var (
-	rms *retentionMetrics
-	mmu sync.RWMutex
+	rms  *retentionMetrics
+	wlms *writeLimitMetrics
+	sms  *shardMetrics
+	qms  *quotaMetrics
+	mmu  sync.RWMutex
 )
 
 // RetentionPrometheusCollectors returns all prometheus metrics for retention.
@@ -27,6 +31,45 @@ func RetentionPrometheusCollectors() []prometheus.Collector {
 	return collectors
 }
 
+// WriteLimitPrometheusCollectors returns all prometheus metrics for the
+// cardinality limits of writes.
+func WriteLimitPrometheusCollectors() []prometheus.Collector {
+	mmu.RLock()
+	defer mmu.RUnlock()
+
+	var collectors []prometheus.Collector
+	if wlms != nil {
+		collectors = append(collectors, wlms.PrometheusCollectors()...)
+	}
+	return collectors
+}
+
+// ShardPrometheusCollectors returns all prometheus metrics for the load on
+// each shard.
+func ShardPrometheusCollectors() []prometheus.Collector {
+	mmu.RLock()
+	defer mmu.RUnlock()
+
+	var collectors []prometheus.Collector
+	if sms != nil {
+		collectors = append(collectors, sms.PrometheusCollectors()...)
+	}
+	return collectors
+}
+
+// QuotaPrometheusCollectors returns all prometheus metrics for the quotas of
+// organizations.
+func QuotaPrometheusCollectors() []prometheus.Collector {
+	mmu.RLock()
+	defer mmu.RUnlock()
+
+	var collectors []prometheus.Collector
+	if qms != nil {
+		collectors = append(collectors, qms.PrometheusCollectors()...)
+	}
+	return collectors
+}
+
 // namespace is the leading part of all published metrics for the Storage service.
 const namespace = "storage"
 
@@ -88,3 +131,228 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 		rm.CheckDuration,
 	}
 }
+
+const writerSubsystem = "writer" // sub-system associated with metrics for writing points.
+
+// writeLimitMetrics is a set of metrics concerned with the points dropped by
+// cardinality limits and the dedupe window.
+type writeLimitMetrics struct {
+	labels     prometheus.Labels
+	Limited    *prometheus.CounterVec
+	Duplicates *prometheus.CounterVec
+}
+
+func newWriteLimitMetrics(labels prometheus.Labels) *writeLimitMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	limitedNames := append(append([]string(nil), names...), "limit")
+	sort.Strings(limitedNames)
+
+	duplicatesNames := append(append([]string(nil), names...), "bucket")
+	sort.Strings(duplicatesNames)
+
+	return &writeLimitMetrics{
+		labels: labels,
+		Limited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: writerSubsystem,
+			Name:      "cardinality_limited_points_total",
+			Help:      "Number of points dropped because they would exceed a cardinality limit.",
+		}, limitedNames),
+		Duplicates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: writerSubsystem,
+			Name:      "duplicate_points_total",
+			Help:      "Number of points dropped because an identical point was written within the dedupe window.",
+		}, duplicatesNames),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *writeLimitMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Limited,
+		m.Duplicates,
+	}
+}
+
+// writeLimitTracker records the points dropped by cardinality limits.
+type writeLimitTracker struct {
+	metrics *writeLimitMetrics
+	labels  prometheus.Labels
+}
+
+func newWriteLimitTracker(defaultLabels prometheus.Labels) *writeLimitTracker {
+	mmu.Lock()
+	if wlms == nil {
+		wlms = newWriteLimitMetrics(defaultLabels)
+	}
+	mmu.Unlock()
+
+	return &writeLimitTracker{metrics: wlms, labels: defaultLabels}
+}
+
+// IncLimited signals that a point was dropped by the named limit.
+func (t *writeLimitTracker) IncLimited(limit string) {
+	labels := make(prometheus.Labels, len(t.labels)+1)
+	for k, v := range t.labels {
+		labels[k] = v
+	}
+	labels["limit"] = limit
+
+	t.metrics.Limited.With(labels).Inc()
+}
+
+// AddDuplicates signals that n points written to a bucket were dropped as
+// duplicates.
+func (t *writeLimitTracker) AddDuplicates(bucketID influxdb.ID, n int) {
+	labels := make(prometheus.Labels, len(t.labels)+1)
+	for k, v := range t.labels {
+		labels[k] = v
+	}
+	labels["bucket"] = bucketID.String()
+
+	t.metrics.Duplicates.With(labels).Add(float64(n))
+}
+
+const shardSubsystem = "shard" // sub-system associated with metrics for the load on each shard.
+
+// shardMetrics is a set of metrics concerned with the load on the data of
+// each bucket.
+type shardMetrics struct {
+	labels            prometheus.Labels
+	WritePoints       *prometheus.CounterVec
+	WriteBytes        *prometheus.CounterVec
+	CacheBytes        *prometheus.GaugeVec
+	CompactionBacklog *prometheus.GaugeVec
+	TombstonedBytes   *prometheus.GaugeVec
+	CursorDuration    *prometheus.HistogramVec
+}
+
+func newShardMetrics(labels prometheus.Labels) *shardMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	names = append(names, "bucket")
+	sort.Strings(names)
+
+	return &shardMetrics{
+		labels: labels,
+		WritePoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: shardSubsystem,
+			Name:      "write_points_total",
+			Help:      "Number of points written to the shard.",
+		}, names),
+		WriteBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: shardSubsystem,
+			Name:      "write_bytes_total",
+			Help:      "Number of bytes of line protocol written to the shard.",
+		}, names),
+		CacheBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: shardSubsystem,
+			Name:      "cache_bytes",
+			Help:      "Size of the shard's data in the cache.",
+		}, names),
+		CompactionBacklog: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: shardSubsystem,
+			Name:      "compaction_backlog_files",
+			Help:      "Number of the shard's TSM files waiting to be fully compacted.",
+		}, names),
+		TombstonedBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: shardSubsystem,
+			Name:      "tombstoned_bytes",
+			Help:      "Size of the shard's deleted data still held in TSM files.",
+		}, names),
+		CursorDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: shardSubsystem,
+			Name:      "cursor_duration_seconds",
+			Help:      "Time taken to create a cursor reading the shard.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *shardMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.WritePoints,
+		m.WriteBytes,
+		m.CacheBytes,
+		m.CompactionBacklog,
+		m.TombstonedBytes,
+		m.CursorDuration,
+	}
+}
+
+const quotaSubsystem = "quota" // sub-system associated with metrics for the quotas of organizations.
+
+// quotaMetrics is a set of metrics concerned with the actions rejected by the
+// quotas of organizations.
+type quotaMetrics struct {
+	labels   prometheus.Labels
+	Exceeded *prometheus.CounterVec
+}
+
+func newQuotaMetrics(labels prometheus.Labels) *quotaMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	names = append(names, "org", "quota")
+	sort.Strings(names)
+
+	return &quotaMetrics{
+		labels: labels,
+		Exceeded: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: quotaSubsystem,
+			Name:      "exceeded_total",
+			Help:      "Number of points, writes and reads rejected because they would exceed the quota of an organization.",
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *quotaMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Exceeded,
+	}
+}
+
+// quotaTracker records the actions rejected by the quotas of organizations.
+type quotaTracker struct {
+	metrics *quotaMetrics
+	labels  prometheus.Labels
+}
+
+func newQuotaTracker(defaultLabels prometheus.Labels) *quotaTracker {
+	mmu.Lock()
+	if qms == nil {
+		qms = newQuotaMetrics(defaultLabels)
+	}
+	mmu.Unlock()
+
+	return &quotaTracker{metrics: qms, labels: defaultLabels}
+}
+
+// IncExceeded signals that n actions of an organization were rejected by the
+// named limit of its quota.
+func (t *quotaTracker) IncExceeded(orgID influxdb.ID, quota string, n int) {
+	labels := make(prometheus.Labels, len(t.labels)+2)
+	for k, v := range t.labels {
+		labels[k] = v
+	}
+	labels["org"] = orgID.String()
+	labels["quota"] = quota
+
+	t.metrics.Exceeded.With(labels).Add(float64(n))
+}
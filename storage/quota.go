@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// orgStorageRefresh is how long the size of the data of each organization is
+// reused by writes before it is read again from the TSM files.
+const orgStorageRefresh = 10 * time.Second
+
+// OrgQuotaSetter defines the behaviour of enforcing the quotas of
+// organizations.
+type OrgQuotaSetter interface {
+	SetOrgQuota(q *influxdb.OrgQuota)
+}
+
+// OrgQuotaSetters passes the quotas of organizations on to each of its
+// setters, such as an engine and a query controller.
+type OrgQuotaSetters []OrgQuotaSetter
+
+// SetOrgQuota passes q on to each setter.
+func (s OrgQuotaSetters) SetOrgQuota(q *influxdb.OrgQuota) {
+	for _, setter := range s {
+		setter.SetOrgQuota(q)
+	}
+}
+
+// BucketQuotaChecker defines the behaviour of checking the max-buckets quota
+// of an organization.
+type BucketQuotaChecker interface {
+	CheckBucketQuota(orgID influxdb.ID, n int) error
+}
+
+// LoadOrgQuotas passes the quota of every organization found by finder on to
+// engine. It is called when the engine is opened, as the engine does not
+// persist quotas itself.
+func LoadOrgQuotas(ctx context.Context, finder influxdb.OrgQuotaService, engine OrgQuotaSetter) error {
+	qs, err := finder.FindOrgQuotas(ctx)
+	if err != nil {
+		return err
+	}
+	for _, q := range qs {
+		engine.SetOrgQuota(q)
+	}
+	return nil
+}
+
+// OrgQuotaService wraps an influxdb.OrgQuotaService, keeping the engine
+// informed of the quota of each organization.
+type OrgQuotaService struct {
+	inner  influxdb.OrgQuotaService
+	engine OrgQuotaSetter
+}
+
+var _ influxdb.OrgQuotaService = (*OrgQuotaService)(nil)
+
+// NewOrgQuotaService returns a new OrgQuotaService.
+func NewOrgQuotaService(s influxdb.OrgQuotaService, engine OrgQuotaSetter) *OrgQuotaService {
+	return &OrgQuotaService{
+		inner:  s,
+		engine: engine,
+	}
+}
+
+// FindOrgQuota returns the quota of an organization.
+func (s *OrgQuotaService) FindOrgQuota(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgQuota, error) {
+	return s.inner.FindOrgQuota(ctx, orgID)
+}
+
+// FindOrgQuotas returns the quotas of every organization with a quota.
+func (s *OrgQuotaService) FindOrgQuotas(ctx context.Context) ([]*influxdb.OrgQuota, error) {
+	return s.inner.FindOrgQuotas(ctx)
+}
+
+// SetOrgQuota replaces the quota of an organization and passes it on to the
+// engine.
+func (s *OrgQuotaService) SetOrgQuota(ctx context.Context, q *influxdb.OrgQuota) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.inner.SetOrgQuota(ctx, q); err != nil {
+		return err
+	}
+	s.engine.SetOrgQuota(q)
+	return nil
+}
+
+// DeleteOrgQuota removes the limits of an organization and of the engine.
+func (s *OrgQuotaService) DeleteOrgQuota(ctx context.Context, orgID influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.inner.DeleteOrgQuota(ctx, orgID); err != nil {
+		return err
+	}
+	s.engine.SetOrgQuota(&influxdb.OrgQuota{OrgID: orgID})
+	return nil
+}
+
+// orgQuotas holds the quotas of organizations enforced by an engine, and the
+// usage they are checked against.
+type orgQuotas struct {
+	mu     sync.Mutex
+	quotas map[influxdb.ID]influxdb.OrgQuota
+
+	// queries counts the reads of each organization since minute.
+	minute  time.Time
+	queries map[influxdb.ID]int
+
+	// storage holds the size of the data of each organization in TSM files,
+	// as read at storageAt.
+	storageAt time.Time
+	storage   map[influxdb.ID]int64
+}
+
+func newOrgQuotas() *orgQuotas {
+	return &orgQuotas{
+		quotas:  make(map[influxdb.ID]influxdb.OrgQuota),
+		queries: make(map[influxdb.ID]int),
+	}
+}
+
+// get returns the quota of an organization and whether it has one.
+func (q *orgQuotas) get(orgID influxdb.ID) (influxdb.OrgQuota, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	oq, ok := q.quotas[orgID]
+	return oq, ok
+}
+
+// hasSeriesQuotas returns true if an organization has a max-series quota.
+func (q *orgQuotas) hasSeriesQuotas() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, oq := range q.quotas {
+		if oq.MaxSeries > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SetOrgQuota sets the quota enforced for an organization. A quota without
+// limits removes the quota of the organization.
+func (e *Engine) SetOrgQuota(q *influxdb.OrgQuota) {
+	e.quotas.mu.Lock()
+	defer e.quotas.mu.Unlock()
+	if *q == (influxdb.OrgQuota{OrgID: q.OrgID}) {
+		delete(e.quotas.quotas, q.OrgID)
+		return
+	}
+	e.quotas.quotas[q.OrgID] = *q
+}
+
+// CheckBucketQuota returns an error if an organization with n buckets may not
+// create another bucket.
+func (e *Engine) CheckBucketQuota(orgID influxdb.ID, n int) error {
+	q, ok := e.quotas.get(orgID)
+	if !ok || q.MaxBuckets <= 0 || n < q.MaxBuckets {
+		return nil
+	}
+	e.quotaStats.IncExceeded(orgID, influxdb.QuotaMaxBuckets, 1)
+	return influxdb.ErrQuotaExceeded(orgID, influxdb.QuotaMaxBuckets, int64(n), int64(q.MaxBuckets))
+}
+
+// AdmitOrgQuery returns an error if a read of the data of an organization
+// would exceed its max-queries-per-minute quota. Otherwise the read is counted
+// towards the quota.
+func (e *Engine) AdmitOrgQuery(orgID influxdb.ID) error {
+	now := e.timeGen.Now()
+	q := e.quotas
+	q.mu.Lock()
+	oq, ok := q.quotas[orgID]
+	if !ok || oq.MaxQueriesPerMinute <= 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	if now.Sub(q.minute) >= time.Minute {
+		q.minute = now
+		q.queries = make(map[influxdb.ID]int)
+	}
+	n := q.queries[orgID]
+	if n < oq.MaxQueriesPerMinute {
+		q.queries[orgID] = n + 1
+		q.mu.Unlock()
+		return nil
+	}
+	q.mu.Unlock()
+
+	e.quotaStats.IncExceeded(orgID, influxdb.QuotaMaxQueriesPerMinute, 1)
+	return influxdb.ErrQuotaExceeded(orgID, influxdb.QuotaMaxQueriesPerMinute, int64(n), int64(oq.MaxQueriesPerMinute))
+}
+
+// checkStorageQuotas returns an error if an organization written to by
+// collection has at least as much data in TSM files as its max-storage-bytes
+// quota. The whole write is rejected, as none of it may be stored. It must be
+// called under e.mu.
+//
+// The size of the data of each organization is only read again every
+// orgStorageRefresh, so writes may exceed the quota slightly.
+func (e *Engine) checkStorageQuotas(collection *tsdb.SeriesCollection) error {
+	q := e.quotas
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.quotas) == 0 {
+		return nil
+	}
+
+	checked := make(map[influxdb.ID]struct{})
+	for iter := collection.Iterator(); iter.Next(); {
+		name := iter.Name()
+		if len(name) != influxdb.IDLength {
+			continue
+		}
+		orgID, _ := tsdb.DecodeNameSlice(name)
+		if _, ok := checked[orgID]; ok {
+			continue
+		}
+		checked[orgID] = struct{}{}
+
+		oq, ok := q.quotas[orgID]
+		if !ok || oq.MaxStorageBytes <= 0 {
+			continue
+		}
+
+		if now := e.timeGen.Now(); q.storage == nil || now.Sub(q.storageAt) >= orgStorageRefresh {
+			storage, err := e.orgStorage()
+			if err != nil {
+				return err
+			}
+			q.storage, q.storageAt = storage, now
+		}
+		if n := q.storage[orgID]; n >= oq.MaxStorageBytes {
+			e.quotaStats.IncExceeded(orgID, influxdb.QuotaMaxStorageBytes, 1)
+			e.logger.Debug("Rejected write exceeding storage quota", zap.Stringer("org_id", orgID), zap.Int64("bytes", n))
+			return influxdb.ErrQuotaExceeded(orgID, influxdb.QuotaMaxStorageBytes, n, oq.MaxStorageBytes)
+		}
+	}
+	return nil
+}
+
+// orgStorage returns the size of the data of each organization in TSM files.
+func (e *Engine) orgStorage() (map[influxdb.ID]int64, error) {
+	sizes, err := e.engine.MeasurementStats()
+	if err != nil {
+		return nil, err
+	}
+	storage := make(map[influxdb.ID]int64)
+	for name, n := range sizes {
+		if len(name) != influxdb.IDLength {
+			continue
+		}
+		orgID, _ := tsdb.DecodeNameSlice([]byte(name))
+		storage[orgID] += int64(n)
+	}
+	return storage, nil
+}
+
+// orgSeriesLimit returns the max-series quota of the organization of the
+// bucket name, or 0 if it has none.
+func (e *Engine) orgSeriesLimit(name []byte) int {
+	orgID, _ := tsdb.DecodeNameSlice(name)
+	q, _ := e.quotas.get(orgID)
+	return q.MaxSeries
+}
+
+// orgSeriesN returns the number of series of all of the buckets of the
+// organization of the bucket name in the index.
+func (e *Engine) orgSeriesN(name []byte) (int, error) {
+	stats, err := e.index.MeasurementCardinalityStats()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for k, v := range stats {
+		if len(k) == influxdb.IDLength && k[:influxdb.OrgIDLength] == string(name[:influxdb.OrgIDLength]) {
+			n += v
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// ErrEngineReadOnly is returned when writing to, deleting from or compacting
+// an engine opened read-only.
+var ErrEngineReadOnly = &influxdb.Error{
+	Code: influxdb.EForbidden,
+	Msg:  "storage engine is read-only",
+}
+
+// filesFingerprint returns a hash of the names, sizes and modification times of
+// the series file, index and TSM files, which changes whenever they are
+// modified outside of the engine.
+func (e *Engine) filesFingerprint() (uint64, error) {
+	h := fnv.New64a()
+	for _, root := range []string{
+		e.config.GetSeriesFilePath(e.path),
+		e.config.GetIndexPath(e.path),
+		e.config.GetEnginePath(e.path),
+	} {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil // Removed while walking.
+			} else if err != nil {
+				return err
+			} else if info.IsDir() {
+				return nil
+			}
+			_, err = fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+	return h.Sum64(), nil
+}
+
+// runReadOnlyRefresher checks the files of a read-only engine for changes in a
+// separate goroutine, and reopens them once changes have settled.
+func (e *Engine) runReadOnlyRefresher() {
+	interval := time.Duration(e.config.ReadOnlyRefreshInterval)
+	if interval == 0 {
+		e.logger.Info("Read-only refresh disabled")
+		return
+	}
+
+	loaded, err := e.filesFingerprint()
+	if err != nil {
+		e.logger.Warn("Unable to check read-only files for changes", zap.Error(err))
+	}
+
+	closing := e.closing
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		pending := loaded
+		for {
+			select {
+			case <-closing:
+				return
+			case <-ticker.C:
+			}
+
+			current, err := e.filesFingerprint()
+			if err != nil {
+				e.logger.Warn("Unable to check read-only files for changes", zap.Error(err))
+				continue
+			}
+
+			// Files are only reopened once they have stopped changing for a
+			// whole interval, so a sync in progress is not opened half done.
+			if current == loaded || current != pending {
+				pending = current
+				continue
+			}
+
+			start := time.Now()
+			if err := e.reopenReadOnly(context.Background()); err != nil {
+				e.logger.Error("Unable to reopen read-only files", zap.Error(err))
+				continue
+			}
+			loaded = current
+			e.logger.Info("Reopened read-only files", zap.Duration("duration", time.Since(start)))
+		}
+	}()
+}
+
+// reopenReadOnly closes and opens the series file, index and TSM files, and
+// reloads the cardinality sketches, so that changes made to them outside of
+// the engine are visible.
+func (e *Engine) reopenReadOnly(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	var ch closeHelper
+	ch.Close(e.engine)
+	ch.Close(e.index)
+	ch.Close(e.sfile)
+	if err := ch.Done(); err != nil {
+		return err
+	}
+
+	var oh openHelper
+	oh.Open(ctx, e.sfile)
+	oh.Open(ctx, e.index)
+	oh.Open(ctx, e.engine)
+	if err := oh.Done(); err != nil {
+		return err
+	}
+
+	if _, err := e.cardinality.Load(); err != nil {
+		e.logger.Warn("Unable to load cardinality sketches", zap.Error(err))
+	}
+	return nil
+}
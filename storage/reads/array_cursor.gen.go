@@ -209,6 +209,121 @@ func (c floatArraySumCursor) Next() *cursors.FloatArray {
 	}
 }
 
+// floatArrayMinCursor selects the first point with the smallest value.
+type floatArrayMinCursor struct {
+	cursors.FloatArrayCursor
+	ts  [1]int64
+	vs  [1]float64
+	res *cursors.FloatArray
+}
+
+func newFloatArrayMinCursor(cur cursors.FloatArrayCursor) *floatArrayMinCursor {
+	return &floatArrayMinCursor{
+		FloatArrayCursor: cur,
+		res:              &cursors.FloatArray{},
+	}
+}
+
+func (c *floatArrayMinCursor) Stats() cursors.CursorStats { return c.FloatArrayCursor.Stats() }
+
+func (c *floatArrayMinCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, min := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v < min {
+				ts, min = a.Timestamps[i], v
+			}
+		}
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = min
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+// floatArrayMaxCursor selects the first point with the largest value.
+type floatArrayMaxCursor struct {
+	cursors.FloatArrayCursor
+	ts  [1]int64
+	vs  [1]float64
+	res *cursors.FloatArray
+}
+
+func newFloatArrayMaxCursor(cur cursors.FloatArrayCursor) *floatArrayMaxCursor {
+	return &floatArrayMaxCursor{
+		FloatArrayCursor: cur,
+		res:              &cursors.FloatArray{},
+	}
+}
+
+func (c *floatArrayMaxCursor) Stats() cursors.CursorStats { return c.FloatArrayCursor.Stats() }
+
+func (c *floatArrayMaxCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, max := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v > max {
+				ts, max = a.Timestamps[i], v
+			}
+		}
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = max
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+// floatFloatMeanArrayCursor computes the mean of the values as a float.
+type floatFloatMeanArrayCursor struct {
+	cursors.FloatArrayCursor
+}
+
+func (c *floatFloatMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatFloatMeanArrayCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var sum float64
+	var count int
+	for {
+		for _, v := range a.Values {
+			sum += float64(v)
+		}
+		count += len(a.Values)
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			res := cursors.NewFloatArrayLen(1)
+			res.Timestamps[0] = ts
+			res.Values[0] = sum / float64(count)
+			return res
+		}
+	}
+}
+
 type integerFloatCountArrayCursor struct {
 	cursors.FloatArrayCursor
 }
@@ -438,6 +553,121 @@ func (c integerArraySumCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+// integerArrayMinCursor selects the first point with the smallest value.
+type integerArrayMinCursor struct {
+	cursors.IntegerArrayCursor
+	ts  [1]int64
+	vs  [1]int64
+	res *cursors.IntegerArray
+}
+
+func newIntegerArrayMinCursor(cur cursors.IntegerArrayCursor) *integerArrayMinCursor {
+	return &integerArrayMinCursor{
+		IntegerArrayCursor: cur,
+		res:                &cursors.IntegerArray{},
+	}
+}
+
+func (c *integerArrayMinCursor) Stats() cursors.CursorStats { return c.IntegerArrayCursor.Stats() }
+
+func (c *integerArrayMinCursor) Next() *cursors.IntegerArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, min := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v < min {
+				ts, min = a.Timestamps[i], v
+			}
+		}
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = min
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+// integerArrayMaxCursor selects the first point with the largest value.
+type integerArrayMaxCursor struct {
+	cursors.IntegerArrayCursor
+	ts  [1]int64
+	vs  [1]int64
+	res *cursors.IntegerArray
+}
+
+func newIntegerArrayMaxCursor(cur cursors.IntegerArrayCursor) *integerArrayMaxCursor {
+	return &integerArrayMaxCursor{
+		IntegerArrayCursor: cur,
+		res:                &cursors.IntegerArray{},
+	}
+}
+
+func (c *integerArrayMaxCursor) Stats() cursors.CursorStats { return c.IntegerArrayCursor.Stats() }
+
+func (c *integerArrayMaxCursor) Next() *cursors.IntegerArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, max := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v > max {
+				ts, max = a.Timestamps[i], v
+			}
+		}
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = max
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+// floatIntegerMeanArrayCursor computes the mean of the values as a float.
+type floatIntegerMeanArrayCursor struct {
+	cursors.IntegerArrayCursor
+}
+
+func (c *floatIntegerMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *floatIntegerMeanArrayCursor) Next() *cursors.FloatArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var sum float64
+	var count int
+	for {
+		for _, v := range a.Values {
+			sum += float64(v)
+		}
+		count += len(a.Values)
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			res := cursors.NewFloatArrayLen(1)
+			res.Timestamps[0] = ts
+			res.Values[0] = sum / float64(count)
+			return res
+		}
+	}
+}
+
 type integerIntegerCountArrayCursor struct {
 	cursors.IntegerArrayCursor
 }
@@ -667,6 +897,121 @@ func (c unsignedArraySumCursor) Next() *cursors.UnsignedArray {
 	}
 }
 
+// unsignedArrayMinCursor selects the first point with the smallest value.
+type unsignedArrayMinCursor struct {
+	cursors.UnsignedArrayCursor
+	ts  [1]int64
+	vs  [1]uint64
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedArrayMinCursor(cur cursors.UnsignedArrayCursor) *unsignedArrayMinCursor {
+	return &unsignedArrayMinCursor{
+		UnsignedArrayCursor: cur,
+		res:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c *unsignedArrayMinCursor) Stats() cursors.CursorStats { return c.UnsignedArrayCursor.Stats() }
+
+func (c *unsignedArrayMinCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, min := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v < min {
+				ts, min = a.Timestamps[i], v
+			}
+		}
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = min
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+// unsignedArrayMaxCursor selects the first point with the largest value.
+type unsignedArrayMaxCursor struct {
+	cursors.UnsignedArrayCursor
+	ts  [1]int64
+	vs  [1]uint64
+	res *cursors.UnsignedArray
+}
+
+func newUnsignedArrayMaxCursor(cur cursors.UnsignedArrayCursor) *unsignedArrayMaxCursor {
+	return &unsignedArrayMaxCursor{
+		UnsignedArrayCursor: cur,
+		res:                 &cursors.UnsignedArray{},
+	}
+}
+
+func (c *unsignedArrayMaxCursor) Stats() cursors.CursorStats { return c.UnsignedArrayCursor.Stats() }
+
+func (c *unsignedArrayMaxCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	ts, max := a.Timestamps[0], a.Values[0]
+	for {
+		for i, v := range a.Values {
+			if v > max {
+				ts, max = a.Timestamps[i], v
+			}
+		}
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.ts[0] = ts
+			c.vs[0] = max
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return c.res
+		}
+	}
+}
+
+// floatUnsignedMeanArrayCursor computes the mean of the values as a float.
+type floatUnsignedMeanArrayCursor struct {
+	cursors.UnsignedArrayCursor
+}
+
+func (c *floatUnsignedMeanArrayCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *floatUnsignedMeanArrayCursor) Next() *cursors.FloatArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return &cursors.FloatArray{}
+	}
+
+	ts := a.Timestamps[0]
+	var sum float64
+	var count int
+	for {
+		for _, v := range a.Values {
+			sum += float64(v)
+		}
+		count += len(a.Values)
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			res := cursors.NewFloatArrayLen(1)
+			res.Timestamps[0] = ts
+			res.Values[0] = sum / float64(count)
+			return res
+		}
+	}
+}
+
 type integerUnsignedCountArrayCursor struct {
 	cursors.UnsignedArrayCursor
 }
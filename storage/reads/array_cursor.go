@@ -26,6 +26,12 @@ func newAggregateArrayCursor(ctx context.Context, agg *datatypes.Aggregate, curs
 		return newSumArrayCursor(cursor)
 	case datatypes.AggregateTypeCount:
 		return newCountArrayCursor(cursor)
+	case datatypes.AggregateTypeMin:
+		return newMinArrayCursor(cursor)
+	case datatypes.AggregateTypeMax:
+		return newMaxArrayCursor(cursor)
+	case datatypes.AggregateTypeMean:
+		return newMeanArrayCursor(cursor)
 	default:
 		// TODO(sgc): should be validated higher up
 		panic("invalid aggregate")
@@ -41,8 +47,48 @@ func newSumArrayCursor(cur cursors.Cursor) cursors.Cursor {
 	case cursors.UnsignedArrayCursor:
 		return newUnsignedArraySumCursor(cur)
 	default:
-		// TODO(sgc): propagate an error instead?
-		return nil
+		// Values that cannot be summed are read as they are, so that the
+		// query fails as it would have had the sum not been pushed down.
+		return cur
+	}
+}
+
+func newMinArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatArrayMinCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerArrayMinCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedArrayMinCursor(cur)
+	default:
+		return cur
+	}
+}
+
+func newMaxArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return newFloatArrayMaxCursor(cur)
+	case cursors.IntegerArrayCursor:
+		return newIntegerArrayMaxCursor(cur)
+	case cursors.UnsignedArrayCursor:
+		return newUnsignedArrayMaxCursor(cur)
+	default:
+		return cur
+	}
+}
+
+func newMeanArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return &floatFloatMeanArrayCursor{FloatArrayCursor: cur}
+	case cursors.IntegerArrayCursor:
+		return &floatIntegerMeanArrayCursor{IntegerArrayCursor: cur}
+	case cursors.UnsignedArrayCursor:
+		return &floatUnsignedMeanArrayCursor{UnsignedArrayCursor: cur}
+	default:
+		return cur
 	}
 }
 
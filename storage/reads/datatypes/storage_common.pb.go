@@ -26,6 +26,39 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion2 // please upgrade the proto package
 
+// DuplicateResolution selects the value read when the overlapping blocks of
+// several TSM files hold values with the same timestamp.
+type DuplicateResolution int32
+
+const (
+	// DuplicateResolutionNewest reads the value of the newest file.
+	DuplicateResolutionNewest DuplicateResolution = 0
+	// DuplicateResolutionMax reads the largest value.
+	DuplicateResolutionMax DuplicateResolution = 1
+	// DuplicateResolutionMin reads the smallest value.
+	DuplicateResolutionMin DuplicateResolution = 2
+)
+
+var DuplicateResolution_name = map[int32]string{
+	0: "DUPLICATE_NEWEST",
+	1: "DUPLICATE_MAX",
+	2: "DUPLICATE_MIN",
+}
+
+var DuplicateResolution_value = map[string]int32{
+	"DUPLICATE_NEWEST": 0,
+	"DUPLICATE_MAX":    1,
+	"DUPLICATE_MIN":    2,
+}
+
+func (x DuplicateResolution) String() string {
+	return proto.EnumName(DuplicateResolution_name, int32(x))
+}
+
+func (DuplicateResolution) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_715e4bf4cdf1f73d, []int{0}
+}
+
 type ReadGroupRequest_Group int32
 
 const (
@@ -96,18 +129,30 @@ const (
 	AggregateTypeNone  Aggregate_AggregateType = 0
 	AggregateTypeSum   Aggregate_AggregateType = 1
 	AggregateTypeCount Aggregate_AggregateType = 2
+	// MIN and MAX select the point with the smallest or largest value, and
+	// its timestamp. The first such point is selected.
+	AggregateTypeMin Aggregate_AggregateType = 3
+	AggregateTypeMax Aggregate_AggregateType = 4
+	// MEAN is the mean of the values, as a float.
+	AggregateTypeMean Aggregate_AggregateType = 5
 )
 
 var Aggregate_AggregateType_name = map[int32]string{
 	0: "NONE",
 	1: "SUM",
 	2: "COUNT",
+	3: "MIN",
+	4: "MAX",
+	5: "MEAN",
 }
 
 var Aggregate_AggregateType_value = map[string]int32{
 	"NONE":  0,
 	"SUM":   1,
 	"COUNT": 2,
+	"MIN":   3,
+	"MAX":   4,
+	"MEAN":  5,
 }
 
 func (x Aggregate_AggregateType) String() string {
@@ -181,6 +226,25 @@ type ReadFilterRequest struct {
 	ReadSource *types.Any     `protobuf:"bytes,1,opt,name=read_source,json=readSource,proto3" json:"read_source,omitempty"`
 	Range      TimestampRange `protobuf:"bytes,2,opt,name=range,proto3" json:"range"`
 	Predicate  *Predicate     `protobuf:"bytes,3,opt,name=predicate,proto3" json:"predicate,omitempty"`
+	// MaxFramePoints limits the number of points sent in each points frame.
+	// A value of 0 uses the server limit. A request may lower, but never raise,
+	// the server limit.
+	MaxFramePoints uint32 `protobuf:"varint,4,opt,name=max_frame_points,json=maxFramePoints,proto3" json:"max_frame_points,omitempty"`
+	// MaxMessageBytes limits the approximate size in bytes of each ReadResponse
+	// message. A value of 0 uses the server limit. A request may lower, but never
+	// raise, the server limit.
+	MaxMessageBytes uint32 `protobuf:"varint,5,opt,name=max_message_bytes,json=maxMessageBytes,proto3" json:"max_message_bytes,omitempty"`
+	// ExcludeTagKeys lists tag keys removed from the tags of each series in
+	// the response. The predicate may still refer to them.
+	ExcludeTagKeys []string `protobuf:"bytes,6,rep,name=exclude_tag_keys,json=excludeTagKeys,proto3" json:"exclude_tag_keys,omitempty"`
+	// DuplicateResolution selects the value read when overlapping TSM files
+	// hold values with the same timestamp. The number of values resolved is
+	// reported in the duplicate-values trailer of the response.
+	DuplicateResolution DuplicateResolution `protobuf:"varint,7,opt,name=duplicate_resolution,json=duplicateResolution,proto3,enum=influxdata.platform.storage.DuplicateResolution" json:"duplicate_resolution,omitempty"`
+	// Aggregate, if set, aggregates the points of each series into a single
+	// point. Stores report the aggregates they support with
+	// reads.AggregateCapability.
+	Aggregate *Aggregate `protobuf:"bytes,8,opt,name=aggregate,proto3" json:"aggregate,omitempty"`
 }
 
 func (m *ReadFilterRequest) Reset()         { *m = ReadFilterRequest{} }
@@ -226,6 +290,20 @@ type ReadGroupRequest struct {
 	Group     ReadGroupRequest_Group `protobuf:"varint,5,opt,name=group,proto3,enum=influxdata.platform.storage.ReadGroupRequest_Group" json:"group,omitempty"`
 	Aggregate *Aggregate             `protobuf:"bytes,6,opt,name=aggregate,proto3" json:"aggregate,omitempty"`
 	Hints     HintFlags              `protobuf:"fixed32,7,opt,name=hints,proto3,casttype=HintFlags" json:"hints,omitempty"`
+	// MaxFramePoints limits the number of points sent in each points frame.
+	// See ReadFilterRequest.MaxFramePoints.
+	MaxFramePoints uint32 `protobuf:"varint,8,opt,name=max_frame_points,json=maxFramePoints,proto3" json:"max_frame_points,omitempty"`
+	// MaxMessageBytes limits the approximate size in bytes of each ReadResponse
+	// message. See ReadFilterRequest.MaxMessageBytes.
+	MaxMessageBytes uint32 `protobuf:"varint,9,opt,name=max_message_bytes,json=maxMessageBytes,proto3" json:"max_message_bytes,omitempty"`
+	// ExcludeTagKeys lists tag keys removed from the tags of each series and
+	// from the tag keys of each group in the response. The predicate may still
+	// refer to them. Keys in GroupKeys are never removed.
+	ExcludeTagKeys []string `protobuf:"bytes,10,rep,name=exclude_tag_keys,json=excludeTagKeys,proto3" json:"exclude_tag_keys,omitempty"`
+	// DuplicateResolution selects the value read when overlapping TSM files
+	// hold values with the same timestamp. See
+	// ReadFilterRequest.DuplicateResolution.
+	DuplicateResolution DuplicateResolution `protobuf:"varint,11,opt,name=duplicate_resolution,json=duplicateResolution,proto3,enum=influxdata.platform.storage.DuplicateResolution" json:"duplicate_resolution,omitempty"`
 }
 
 func (m *ReadGroupRequest) Reset()         { *m = ReadGroupRequest{} }
@@ -1146,6 +1224,7 @@ func (m *StringValuesResponse) XXX_DiscardUnknown() {
 var xxx_messageInfo_StringValuesResponse proto.InternalMessageInfo
 
 func init() {
+	proto.RegisterEnum("influxdata.platform.storage.DuplicateResolution", DuplicateResolution_name, DuplicateResolution_value)
 	proto.RegisterEnum("influxdata.platform.storage.ReadGroupRequest_Group", ReadGroupRequest_Group_name, ReadGroupRequest_Group_value)
 	proto.RegisterEnum("influxdata.platform.storage.ReadGroupRequest_HintFlags", ReadGroupRequest_HintFlags_name, ReadGroupRequest_HintFlags_value)
 	proto.RegisterEnum("influxdata.platform.storage.Aggregate_AggregateType", Aggregate_AggregateType_name, Aggregate_AggregateType_value)
@@ -1175,101 +1254,117 @@ func init() {
 func init() { proto.RegisterFile("storage_common.proto", fileDescriptor_715e4bf4cdf1f73d) }
 
 var fileDescriptor_715e4bf4cdf1f73d = []byte{
-	// 1498 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x57, 0xcb, 0x6f, 0xdb, 0xc8,
-	0x19, 0x17, 0xf5, 0x34, 0x3f, 0xc9, 0x32, 0x3d, 0x51, 0x5d, 0x87, 0x69, 0x24, 0x56, 0x28, 0x52,
-	0x17, 0x49, 0xe4, 0xd4, 0x49, 0xd1, 0x20, 0x6d, 0x0f, 0x96, 0x23, 0x5b, 0x6a, 0x6c, 0xc9, 0xa0,
-	0xe4, 0x00, 0xe9, 0x45, 0x18, 0xdb, 0x63, 0x86, 0x88, 0x44, 0xb2, 0x24, 0x15, 0x58, 0x40, 0x2f,
-	0xbd, 0x05, 0x3a, 0xb5, 0x97, 0x1e, 0x0a, 0x08, 0x58, 0x60, 0x8f, 0x7b, 0xdf, 0xbf, 0x21, 0x87,
-	0x05, 0x36, 0xc7, 0x3d, 0x09, 0xbb, 0x0a, 0xb0, 0xc0, 0xfe, 0x0b, 0x7b, 0x5a, 0xcc, 0x0c, 0x47,
-	0xa2, 0x6c, 0xc3, 0x96, 0xf6, 0xb4, 0xc8, 0x6d, 0xe6, 0x7b, 0xfc, 0xbe, 0x07, 0xbf, 0xc7, 0x10,
-	0x72, 0x9e, 0x6f, 0xbb, 0xd8, 0x20, 0xed, 0x13, 0xbb, 0xdb, 0xb5, 0xad, 0x92, 0xe3, 0xda, 0xbe,
-	0x8d, 0xee, 0x98, 0xd6, 0x59, 0xa7, 0x77, 0x7e, 0x8a, 0x7d, 0x5c, 0x72, 0x3a, 0xd8, 0x3f, 0xb3,
-	0xdd, 0x6e, 0x29, 0x90, 0x54, 0x73, 0x86, 0x6d, 0xd8, 0x4c, 0x6e, 0x93, 0x9e, 0xb8, 0x8a, 0x7a,
-	0xc7, 0xb0, 0x6d, 0xa3, 0x43, 0x36, 0xd9, 0xed, 0xb8, 0x77, 0xb6, 0x49, 0xba, 0x8e, 0xdf, 0x0f,
-	0x98, 0xb7, 0x2f, 0x32, 0xb1, 0x25, 0x58, 0x2b, 0x8e, 0x4b, 0x4e, 0xcd, 0x13, 0xec, 0x13, 0x4e,
-	0x28, 0xfe, 0x20, 0xc1, 0xaa, 0x4e, 0xf0, 0xe9, 0xae, 0xd9, 0xf1, 0x89, 0xab, 0x93, 0x7f, 0xf6,
-	0x88, 0xe7, 0xa3, 0x0a, 0xa4, 0x5d, 0x82, 0x4f, 0xdb, 0x9e, 0xdd, 0x73, 0x4f, 0xc8, 0xba, 0xa4,
-	0x49, 0x1b, 0xe9, 0xad, 0x5c, 0x89, 0xe3, 0x96, 0x04, 0x6e, 0x69, 0xdb, 0xea, 0x97, 0xb3, 0xe3,
-	0x51, 0x01, 0x28, 0x42, 0x93, 0xc9, 0xea, 0xe0, 0x4e, 0xce, 0x68, 0x0f, 0x12, 0x2e, 0xb6, 0x0c,
-	0xb2, 0x1e, 0x65, 0x00, 0xf7, 0x4b, 0xd7, 0x04, 0x5a, 0x6a, 0x99, 0x5d, 0xe2, 0xf9, 0xb8, 0xeb,
-	0xe8, 0x54, 0xa5, 0x1c, 0x7f, 0x3f, 0x2a, 0x44, 0x74, 0xae, 0x8f, 0x9e, 0x83, 0x3c, 0x71, 0x7c,
-	0x3d, 0xc6, 0xc0, 0xee, 0x5d, 0x0b, 0x76, 0x28, 0xa4, 0xf5, 0xa9, 0x62, 0xf1, 0xab, 0x04, 0x28,
-	0xd4, 0xd3, 0x3d, 0xd7, 0xee, 0x39, 0x9f, 0x74, 0xa8, 0xe8, 0x01, 0x80, 0x41, 0xa3, 0x6c, 0xbf,
-	0x21, 0x7d, 0x6f, 0x3d, 0xae, 0xc5, 0x36, 0xe4, 0xf2, 0xf2, 0x78, 0x54, 0x90, 0x59, 0xec, 0x2f,
-	0x48, 0xdf, 0xd3, 0x65, 0x43, 0x1c, 0x51, 0x0d, 0x12, 0xec, 0xb2, 0x9e, 0xd0, 0xa4, 0x8d, 0xec,
-	0xd6, 0xe3, 0x6b, 0xed, 0x5d, 0xcc, 0x60, 0x89, 0x5f, 0x38, 0x02, 0x75, 0x1f, 0x1b, 0x86, 0x4b,
-	0x0c, 0xea, 0x7e, 0x72, 0x0e, 0xf7, 0xb7, 0x85, 0xb4, 0x3e, 0x55, 0x44, 0x0f, 0x20, 0xf1, 0xda,
-	0xb4, 0x7c, 0x6f, 0x3d, 0xa5, 0x49, 0x1b, 0xa9, 0xf2, 0xda, 0x78, 0x54, 0x48, 0x54, 0x29, 0xe1,
-	0xc7, 0x51, 0x41, 0xa6, 0x87, 0xdd, 0x0e, 0x36, 0x3c, 0x9d, 0x0b, 0x15, 0xf7, 0x20, 0xc1, 0x7c,
-	0x40, 0x77, 0x01, 0xf6, 0xf4, 0xc6, 0xd1, 0x61, 0xbb, 0xde, 0xa8, 0x57, 0x94, 0x88, 0xba, 0x3c,
-	0x18, 0x6a, 0x3c, 0xe2, 0xba, 0x6d, 0x11, 0x74, 0x1b, 0x96, 0x38, 0xbb, 0xfc, 0x4a, 0x89, 0xaa,
-	0xe9, 0xc1, 0x50, 0x4b, 0x31, 0x66, 0xb9, 0xaf, 0xc6, 0xdf, 0x7d, 0x9e, 0x8f, 0x14, 0xbf, 0x90,
-	0x60, 0x8a, 0x8e, 0xee, 0x80, 0x5c, 0xad, 0xd5, 0x5b, 0x02, 0x2c, 0x33, 0x18, 0x6a, 0x4b, 0x94,
-	0xcb, 0xb0, 0x7e, 0x07, 0xd9, 0x80, 0xd9, 0x3e, 0x6c, 0xd4, 0xea, 0xad, 0xa6, 0x22, 0xa9, 0xca,
-	0x60, 0xa8, 0x65, 0xb8, 0xc4, 0xa1, 0x4d, 0x3d, 0x0b, 0x4b, 0x35, 0x2b, 0x7a, 0xad, 0xd2, 0x54,
-	0xa2, 0x61, 0xa9, 0x26, 0x71, 0x4d, 0xe2, 0xa1, 0x4d, 0xc8, 0x31, 0xa9, 0xe6, 0x4e, 0xb5, 0x72,
-	0xb0, 0xdd, 0xde, 0xde, 0xdf, 0x6f, 0xb7, 0x6a, 0x07, 0x15, 0x25, 0xae, 0xfe, 0x6a, 0x30, 0xd4,
-	0x56, 0xa9, 0x6c, 0xf3, 0xe4, 0x35, 0xe9, 0xe2, 0xed, 0x4e, 0x87, 0x96, 0x4e, 0xe0, 0xed, 0xd7,
-	0x12, 0xc8, 0x93, 0xec, 0xa1, 0x2a, 0xc4, 0xfd, 0xbe, 0xc3, 0x0b, 0x38, 0xbb, 0xf5, 0x64, 0xbe,
-	0x9c, 0x4f, 0x4f, 0xad, 0xbe, 0x43, 0x74, 0x86, 0x50, 0x3c, 0x87, 0xe5, 0x19, 0x32, 0x2a, 0x40,
-	0x3c, 0xc8, 0x01, 0xf3, 0x67, 0x86, 0xc9, 0x92, 0x71, 0x17, 0x62, 0xcd, 0xa3, 0x03, 0x45, 0x52,
-	0x73, 0x83, 0xa1, 0xa6, 0xcc, 0xf0, 0x9b, 0xbd, 0x2e, 0xfa, 0x2d, 0x24, 0x76, 0x1a, 0x47, 0xf5,
-	0x96, 0x12, 0x55, 0xd7, 0x06, 0x43, 0x0d, 0xcd, 0x08, 0xec, 0xd8, 0x3d, 0xcb, 0x0f, 0x22, 0x7a,
-	0x08, 0xb1, 0x16, 0x36, 0x90, 0x02, 0xb1, 0x37, 0xa4, 0xcf, 0x22, 0xc9, 0xe8, 0xf4, 0x88, 0x72,
-	0x90, 0x78, 0x8b, 0x3b, 0x3d, 0xde, 0x5d, 0x19, 0x9d, 0x5f, 0x8a, 0xff, 0xcd, 0x42, 0x86, 0x56,
-	0xa3, 0x4e, 0x3c, 0xc7, 0xb6, 0x3c, 0x82, 0x0e, 0x20, 0x79, 0xe6, 0xe2, 0x2e, 0xf1, 0xd6, 0x25,
-	0x2d, 0xb6, 0x91, 0xde, 0xda, 0xbc, 0xb1, 0x90, 0x85, 0x6a, 0x69, 0x97, 0xea, 0x05, 0x9d, 0x18,
-	0x80, 0xa8, 0xef, 0x92, 0x90, 0x60, 0x74, 0xb4, 0x2f, 0x1a, 0x24, 0xc5, 0x2a, 0xfa, 0xc9, 0xfc,
-	0xb8, 0xac, 0xc0, 0x18, 0x48, 0x35, 0x22, 0x7a, 0xa4, 0x01, 0x49, 0x8f, 0x7d, 0xf9, 0x60, 0xda,
-	0xfc, 0x69, 0x7e, 0x38, 0x5e, 0x31, 0x02, 0x2f, 0x80, 0x41, 0x0e, 0x64, 0xce, 0x3a, 0x36, 0xf6,
-	0xdb, 0x0e, 0x2b, 0xbb, 0x60, 0x06, 0x3d, 0x5b, 0x20, 0x7a, 0xaa, 0xcd, 0x6b, 0x96, 0x27, 0x62,
-	0x65, 0x3c, 0x2a, 0xa4, 0x43, 0xd4, 0x6a, 0x44, 0x4f, 0x9f, 0x4d, 0xaf, 0xe8, 0x1c, 0xb2, 0xa6,
-	0xe5, 0x13, 0x83, 0xb8, 0xc2, 0x26, 0x1f, 0x55, 0x7f, 0x9d, 0xdf, 0x66, 0x8d, 0xeb, 0x87, 0xad,
-	0xae, 0x8e, 0x47, 0x85, 0xe5, 0x19, 0x7a, 0x35, 0xa2, 0x2f, 0x9b, 0x61, 0x02, 0xfa, 0x17, 0xac,
-	0xf4, 0x2c, 0xcf, 0x34, 0x2c, 0x72, 0x2a, 0x4c, 0xc7, 0x99, 0xe9, 0xbf, 0xcd, 0x6f, 0xfa, 0x28,
-	0x00, 0x08, 0xdb, 0x46, 0xe3, 0x51, 0x21, 0x3b, 0xcb, 0xa8, 0x46, 0xf4, 0x6c, 0x6f, 0x86, 0x42,
-	0xe3, 0x3e, 0xb6, 0xed, 0x0e, 0xc1, 0x96, 0x30, 0x9e, 0x58, 0x34, 0xee, 0x32, 0xd7, 0xbf, 0x14,
-	0xf7, 0x0c, 0x9d, 0xc6, 0x7d, 0x1c, 0x26, 0x20, 0x1f, 0x96, 0x3d, 0xdf, 0x35, 0x2d, 0x43, 0x18,
-	0xe6, 0xc3, 0xf5, 0x2f, 0x0b, 0xd4, 0x0e, 0x53, 0x0f, 0xdb, 0x55, 0xc6, 0xa3, 0x42, 0x26, 0x4c,
-	0xae, 0x46, 0xf4, 0x8c, 0x17, 0xba, 0x97, 0x93, 0x10, 0xa7, 0xc8, 0xea, 0x39, 0xc0, 0xb4, 0x92,
-	0xd1, 0x3d, 0x58, 0xf2, 0xb1, 0xc1, 0x77, 0x0b, 0xed, 0xb4, 0x4c, 0x39, 0x3d, 0x1e, 0x15, 0x52,
-	0x2d, 0x6c, 0xb0, 0xcd, 0x92, 0xf2, 0xf9, 0x01, 0x95, 0x01, 0x39, 0xd8, 0xf5, 0x4d, 0xdf, 0xb4,
-	0x2d, 0x2a, 0xdd, 0x7e, 0x8b, 0x3b, 0xb4, 0x3a, 0xa9, 0x46, 0x6e, 0x3c, 0x2a, 0x28, 0x87, 0x82,
-	0xfb, 0x82, 0xf4, 0x5f, 0xe2, 0x8e, 0xa7, 0x2b, 0xce, 0x05, 0x8a, 0xfa, 0x7f, 0x09, 0xd2, 0xa1,
-	0xaa, 0x47, 0xcf, 0x20, 0xee, 0x63, 0x43, 0x74, 0xb8, 0x76, 0xfd, 0x9e, 0xc5, 0x46, 0xd0, 0xd2,
-	0x4c, 0x07, 0x35, 0x40, 0xa6, 0x82, 0x6d, 0x36, 0x28, 0xa3, 0x6c, 0x50, 0x6e, 0xcd, 0x9f, 0xbf,
-	0xe7, 0xd8, 0xc7, 0x6c, 0x4c, 0x2e, 0x9d, 0x06, 0x27, 0xf5, 0xef, 0xa0, 0x5c, 0x6c, 0x1d, 0x94,
-	0x07, 0xf0, 0xc5, 0x7e, 0xe7, 0x6e, 0x2a, 0x7a, 0x88, 0x82, 0xd6, 0x20, 0xc9, 0xc6, 0x17, 0x4f,
-	0x84, 0xa4, 0x07, 0x37, 0x75, 0x1f, 0xd0, 0xe5, 0x96, 0x58, 0x10, 0x2d, 0x36, 0x41, 0x3b, 0x80,
-	0x5b, 0x57, 0x54, 0xf9, 0x82, 0x70, 0xf1, 0xb0, 0x73, 0x97, 0xeb, 0x76, 0x41, 0xb4, 0xa5, 0x09,
-	0xda, 0x0b, 0x58, 0xbd, 0x54, 0x8c, 0x0b, 0x82, 0xc9, 0x02, 0xac, 0xd8, 0x04, 0x99, 0x01, 0x04,
-	0xab, 0x2a, 0x19, 0x2c, 0xda, 0x88, 0x7a, 0x6b, 0x30, 0xd4, 0x56, 0x26, 0xac, 0x60, 0xd7, 0x16,
-	0x20, 0x39, 0xd9, 0xd7, 0xb3, 0x02, 0xdc, 0x97, 0x60, 0x13, 0x7d, 0x29, 0xc1, 0x92, 0xf8, 0xde,
-	0xe8, 0x37, 0x90, 0xd8, 0xdd, 0x6f, 0x6c, 0xb7, 0x94, 0x88, 0xba, 0x3a, 0x18, 0x6a, 0xcb, 0x82,
-	0xc1, 0x3e, 0x3d, 0xd2, 0x20, 0x55, 0xab, 0xb7, 0x2a, 0x7b, 0x15, 0x5d, 0x40, 0x0a, 0x7e, 0xf0,
-	0x39, 0x51, 0x11, 0x96, 0x8e, 0xea, 0xcd, 0xda, 0x5e, 0xbd, 0xf2, 0x5c, 0x89, 0xf2, 0x1d, 0x29,
-	0x44, 0xc4, 0x37, 0xa2, 0x28, 0xe5, 0x46, 0x63, 0xbf, 0xb2, 0x5d, 0x57, 0x62, 0xb3, 0x28, 0x41,
-	0xde, 0x51, 0x1e, 0x92, 0xcd, 0x96, 0x5e, 0xab, 0xef, 0x29, 0x71, 0x15, 0x0d, 0x86, 0x5a, 0x56,
-	0x08, 0xf0, 0x54, 0x06, 0x8e, 0x7f, 0x26, 0x41, 0x6e, 0x07, 0x3b, 0xf8, 0xd8, 0xec, 0x98, 0xbe,
-	0x49, 0xbc, 0xc9, 0x6e, 0x6c, 0x40, 0xfc, 0x04, 0x3b, 0xa2, 0x6f, 0xae, 0x1f, 0x1b, 0x57, 0x01,
-	0x50, 0xa2, 0x57, 0xb1, 0x7c, 0xb7, 0xaf, 0x33, 0x20, 0xf5, 0xcf, 0x20, 0x4f, 0x48, 0xe1, 0x95,
-	0x2d, 0x5f, 0xb1, 0xb2, 0xe5, 0x60, 0x65, 0x3f, 0x8b, 0x3e, 0x95, 0x8a, 0x4f, 0x21, 0x3b, 0xfb,
-	0x00, 0xa6, 0xb2, 0x9e, 0x8f, 0x5d, 0x9f, 0xe9, 0xc7, 0x74, 0x7e, 0xa1, 0x98, 0xc4, 0x3a, 0x65,
-	0xfa, 0x31, 0x9d, 0x1e, 0x8b, 0xdf, 0x4b, 0x90, 0x15, 0x43, 0x66, 0xfa, 0x7c, 0xa7, 0xad, 0x3d,
-	0xf7, 0xf3, 0xbd, 0x85, 0x0d, 0x4f, 0x3c, 0xdf, 0xfd, 0xc9, 0xf9, 0x97, 0xf6, 0xa7, 0xf2, 0xef,
-	0x28, 0x28, 0x2d, 0x6c, 0xbc, 0x64, 0x15, 0xfe, 0x49, 0x87, 0x8a, 0x7e, 0x0d, 0xa9, 0x60, 0x97,
-	0xb0, 0x3d, 0x2e, 0xeb, 0x49, 0xbe, 0x3d, 0x8a, 0x25, 0xc8, 0xf1, 0xca, 0x16, 0x59, 0x08, 0x0a,
-	0x79, 0x3a, 0x07, 0xd8, 0xea, 0x11, 0x73, 0x60, 0xeb, 0x7f, 0x71, 0x48, 0x35, 0xb9, 0x25, 0x64,
-	0x02, 0x4c, 0x7f, 0x6a, 0x51, 0xe9, 0xc6, 0x19, 0x3f, 0xf3, 0xf7, 0xab, 0xfe, 0x61, 0xee, 0x9d,
-	0xf0, 0x48, 0x42, 0x06, 0xc8, 0x93, 0x3f, 0x22, 0xf4, 0x70, 0xa1, 0x3f, 0xa7, 0xc5, 0x0c, 0xbd,
-	0x01, 0xb1, 0x60, 0xd1, 0xfd, 0x9b, 0xb6, 0x5e, 0xa8, 0x43, 0xd4, 0x3f, 0x5e, 0x2b, 0x7c, 0x55,
-	0x8a, 0x1f, 0x49, 0xc8, 0x06, 0x79, 0x52, 0x7f, 0x37, 0x44, 0x75, 0xb1, 0x4e, 0x7f, 0x9e, 0xc1,
-	0x57, 0x90, 0x09, 0x4f, 0x1d, 0xb4, 0x76, 0xa9, 0xae, 0x2b, 0x5d, 0xc7, 0xef, 0xdf, 0x00, 0x7e,
-	0xd5, 0xe0, 0x2a, 0xff, 0xfe, 0xfd, 0x77, 0xf9, 0xc8, 0xfb, 0x71, 0x5e, 0xfa, 0x30, 0xce, 0x4b,
-	0xdf, 0x8e, 0xf3, 0xd2, 0x7f, 0x3e, 0xe6, 0x23, 0x1f, 0x3e, 0xe6, 0x23, 0xdf, 0x7c, 0xcc, 0x47,
-	0xfe, 0xc1, 0x5e, 0x04, 0xf4, 0x41, 0xe0, 0x1d, 0x27, 0x99, 0xad, 0xc7, 0x3f, 0x05, 0x00, 0x00,
-	0xff, 0xff, 0x7f, 0x0e, 0xbb, 0xbb, 0xa6, 0x11, 0x00, 0x00,
+	// 1753 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xdc, 0x58, 0xcb, 0x6f, 0x23, 0x49,
+	0x19, 0x77, 0xfb, 0xdd, 0x9f, 0x1f, 0xe9, 0x54, 0x4c, 0x36, 0xd3, 0xc3, 0xd8, 0x8d, 0x85, 0x96,
+	0xc0, 0xee, 0x38, 0x43, 0x66, 0x11, 0xab, 0x61, 0x11, 0xb2, 0x13, 0x4f, 0x6c, 0x26, 0xb6, 0xa3,
+	0xb6, 0xb3, 0xb0, 0x5c, 0xac, 0x4a, 0x5c, 0xe9, 0x6d, 0x8d, 0xdd, 0x6d, 0xba, 0xdb, 0x4b, 0x2c,
+	0xed, 0x85, 0x13, 0x2b, 0x9f, 0xe0, 0xc2, 0x01, 0xc9, 0x12, 0x12, 0x47, 0x24, 0xb8, 0xf1, 0x37,
+	0xcc, 0x71, 0x6f, 0x70, 0xb2, 0xc0, 0x23, 0x71, 0xe0, 0x4f, 0xe0, 0x84, 0xaa, 0xaa, 0xdb, 0xee,
+	0x4e, 0x4c, 0x62, 0xef, 0x48, 0x08, 0xcd, 0xad, 0xea, 0x7b, 0xfc, 0xbe, 0xaa, 0xef, 0x59, 0xdd,
+	0x90, 0xb3, 0x1d, 0xd3, 0xc2, 0x1a, 0xe9, 0x5e, 0x9a, 0x83, 0x81, 0x69, 0x94, 0x86, 0x96, 0xe9,
+	0x98, 0xe8, 0xa1, 0x6e, 0x5c, 0xf5, 0x47, 0xd7, 0x3d, 0xec, 0xe0, 0xd2, 0xb0, 0x8f, 0x9d, 0x2b,
+	0xd3, 0x1a, 0x94, 0x5c, 0x49, 0x39, 0xa7, 0x99, 0x9a, 0xc9, 0xe4, 0x0e, 0xe8, 0x8a, 0xab, 0xc8,
+	0x0f, 0x35, 0xd3, 0xd4, 0xfa, 0xe4, 0x80, 0xed, 0x2e, 0x46, 0x57, 0x07, 0x64, 0x30, 0x74, 0xc6,
+	0x2e, 0xf3, 0xc1, 0x4d, 0x26, 0x36, 0x3c, 0xd6, 0xd6, 0xd0, 0x22, 0x3d, 0xfd, 0x12, 0x3b, 0x84,
+	0x13, 0x8a, 0xff, 0x8a, 0xc2, 0xb6, 0x4a, 0x70, 0xef, 0xb9, 0xde, 0x77, 0x88, 0xa5, 0x92, 0x9f,
+	0x8f, 0x88, 0xed, 0xa0, 0x2a, 0xa4, 0x2c, 0x82, 0x7b, 0x5d, 0xdb, 0x1c, 0x59, 0x97, 0x64, 0x4f,
+	0x50, 0x84, 0xfd, 0xd4, 0x61, 0xae, 0xc4, 0x71, 0x4b, 0x1e, 0x6e, 0xa9, 0x6c, 0x8c, 0x2b, 0xd9,
+	0xf9, 0xac, 0x00, 0x14, 0xa1, 0xcd, 0x64, 0x55, 0xb0, 0x16, 0x6b, 0x74, 0x02, 0x31, 0x0b, 0x1b,
+	0x1a, 0xd9, 0x0b, 0x33, 0x80, 0xf7, 0x4a, 0x77, 0x5c, 0xb4, 0xd4, 0xd1, 0x07, 0xc4, 0x76, 0xf0,
+	0x60, 0xa8, 0x52, 0x95, 0x4a, 0xf4, 0xd5, 0xac, 0x10, 0x52, 0xb9, 0x3e, 0x3a, 0x06, 0x71, 0x71,
+	0xf0, 0xbd, 0x08, 0x03, 0x7b, 0xf7, 0x4e, 0xb0, 0x33, 0x4f, 0x5a, 0x5d, 0x2a, 0xa2, 0x8f, 0x40,
+	0x1a, 0xe0, 0xeb, 0xee, 0x95, 0x85, 0x07, 0xa4, 0x3b, 0x34, 0x75, 0xc3, 0xb1, 0xf7, 0xa2, 0x8a,
+	0xb0, 0x9f, 0xa9, 0xa0, 0xf9, 0xac, 0x90, 0x6d, 0xe0, 0xeb, 0xe7, 0x94, 0x75, 0xc6, 0x38, 0x6a,
+	0x76, 0x10, 0xd8, 0xa3, 0x1f, 0xc1, 0x36, 0xd5, 0x1e, 0x10, 0xdb, 0xa6, 0x11, 0xbc, 0x18, 0x3b,
+	0xc4, 0xde, 0x8b, 0x31, 0xf5, 0x9d, 0xf9, 0xac, 0xb0, 0xd5, 0xc0, 0xd7, 0x0d, 0xce, 0xab, 0x50,
+	0x96, 0xba, 0x35, 0x08, 0x12, 0xa8, 0x79, 0x72, 0x7d, 0xd9, 0x1f, 0xf5, 0x48, 0xd7, 0xc1, 0x5a,
+	0xf7, 0x25, 0x19, 0xdb, 0x7b, 0x71, 0x25, 0xb2, 0x2f, 0x72, 0xf3, 0x55, 0xce, 0xeb, 0x60, 0xed,
+	0x05, 0x19, 0xdb, 0x6a, 0x96, 0x04, 0xf6, 0xe8, 0x73, 0xc8, 0xf5, 0x46, 0xc3, 0x3e, 0xbb, 0x49,
+	0xd7, 0x22, 0xb6, 0xd9, 0x1f, 0x39, 0xba, 0x69, 0xec, 0x25, 0x14, 0x61, 0x3f, 0x7b, 0xf8, 0xe4,
+	0x4e, 0x6f, 0x1c, 0x7b, 0x8a, 0xea, 0x42, 0xaf, 0xf2, 0xce, 0x7c, 0x56, 0xd8, 0x59, 0xc1, 0x50,
+	0x77, 0x7a, 0xb7, 0x89, 0x34, 0x00, 0x58, 0xd3, 0x2c, 0xa2, 0xd1, 0x00, 0x24, 0xd7, 0x08, 0x40,
+	0xd9, 0x93, 0x56, 0x97, 0x8a, 0xc5, 0x5f, 0x25, 0x41, 0xa2, 0xa9, 0x72, 0x62, 0x99, 0xa3, 0xe1,
+	0xdb, 0x9d, 0x6b, 0xef, 0x03, 0x68, 0xf4, 0x96, 0x3c, 0xcc, 0x51, 0x16, 0xe6, 0xcc, 0x7c, 0x56,
+	0x10, 0xd9, 0xdd, 0x59, 0x84, 0x45, 0xcd, 0x5b, 0xa2, 0x3a, 0xc4, 0xd8, 0x86, 0xe5, 0x53, 0xf6,
+	0xf0, 0xe9, 0x9d, 0xf6, 0x6e, 0x7a, 0xb0, 0xc4, 0x37, 0x1c, 0x21, 0x18, 0xa9, 0xf8, 0x57, 0x8c,
+	0x14, 0x7a, 0x1f, 0x62, 0x9f, 0xb2, 0xfa, 0xa0, 0xe9, 0x95, 0xa8, 0xec, 0xce, 0x67, 0x85, 0x58,
+	0x8d, 0x12, 0xfe, 0x3d, 0x2b, 0x88, 0x74, 0xf1, 0xbc, 0x8f, 0x35, 0x5b, 0xe5, 0x42, 0x2b, 0x0b,
+	0x2b, 0xf9, 0x66, 0x85, 0x25, 0xbe, 0x61, 0x61, 0xc1, 0x1b, 0x17, 0x56, 0xea, 0x7f, 0x51, 0x58,
+	0xc5, 0x13, 0x88, 0xb1, 0xf0, 0xa1, 0x47, 0x00, 0x27, 0x6a, 0xeb, 0xfc, 0xac, 0xdb, 0x6c, 0x35,
+	0xab, 0x52, 0x48, 0xce, 0x4c, 0xa6, 0x0a, 0x4f, 0x96, 0xa6, 0x69, 0x10, 0xf4, 0x00, 0x92, 0x9c,
+	0x5d, 0xf9, 0x44, 0x0a, 0xcb, 0xa9, 0xc9, 0x54, 0x49, 0x30, 0x66, 0x65, 0x2c, 0x47, 0xbf, 0xf8,
+	0x43, 0x3e, 0x54, 0xfc, 0xa3, 0x00, 0xcb, 0xc0, 0xa0, 0x87, 0x20, 0xd6, 0xea, 0xcd, 0x8e, 0x07,
+	0x96, 0x9e, 0x4c, 0x95, 0x24, 0xe5, 0x32, 0xac, 0x6f, 0x42, 0xd6, 0x65, 0x76, 0xcf, 0x5a, 0xf5,
+	0x66, 0xa7, 0x2d, 0x09, 0xb2, 0x34, 0x99, 0x2a, 0x69, 0x2e, 0xe1, 0x86, 0xc5, 0x27, 0xd5, 0xae,
+	0xaa, 0xf5, 0x6a, 0x5b, 0x0a, 0xfb, 0xa5, 0xda, 0xc4, 0xd2, 0x89, 0x8d, 0x0e, 0x20, 0xc7, 0xa4,
+	0xda, 0x47, 0xb5, 0x6a, 0xa3, 0xdc, 0x2d, 0x9f, 0x9e, 0x76, 0x3b, 0xf5, 0x46, 0x55, 0x8a, 0xca,
+	0x5f, 0x9b, 0x4c, 0x95, 0x6d, 0x2a, 0xdb, 0xbe, 0xfc, 0x94, 0x0c, 0x70, 0xb9, 0xdf, 0xa7, 0x55,
+	0xe7, 0x9e, 0xf6, 0xcf, 0x61, 0x10, 0x17, 0x89, 0x87, 0x6a, 0x10, 0x75, 0xc6, 0x43, 0x5e, 0xfb,
+	0xd9, 0xc3, 0x0f, 0xd6, 0x4b, 0xd7, 0xe5, 0xaa, 0x33, 0x1e, 0x12, 0x95, 0x21, 0x14, 0xff, 0x2a,
+	0x40, 0x26, 0x40, 0x47, 0x05, 0x88, 0xba, 0x4e, 0x60, 0x07, 0x0a, 0x30, 0x99, 0x37, 0x1e, 0x41,
+	0xa4, 0x7d, 0xde, 0x90, 0x04, 0x39, 0x37, 0x99, 0x2a, 0x52, 0x80, 0xdf, 0x1e, 0x0d, 0xd0, 0x37,
+	0x20, 0x76, 0xd4, 0x3a, 0x6f, 0x76, 0xa4, 0xb0, 0xbc, 0x3b, 0x99, 0x2a, 0x28, 0x20, 0x70, 0x64,
+	0x8e, 0x0c, 0x87, 0x22, 0x34, 0xea, 0x4d, 0x29, 0xb2, 0x02, 0xa1, 0xa1, 0x1b, 0x8c, 0x5d, 0xfe,
+	0xa9, 0x14, 0x5d, 0xc5, 0xc6, 0xd7, 0xf4, 0x80, 0x8d, 0x6a, 0xb9, 0x29, 0xc5, 0x56, 0x1c, 0xb0,
+	0x41, 0xb0, 0xe1, 0x7a, 0xec, 0x31, 0x44, 0x3a, 0x58, 0x43, 0x12, 0x44, 0x5e, 0x92, 0x31, 0xf3,
+	0x54, 0x5a, 0xa5, 0x4b, 0x94, 0x83, 0xd8, 0x67, 0xb8, 0x3f, 0xe2, 0x8d, 0x2f, 0xad, 0xf2, 0x4d,
+	0xf1, 0x37, 0x59, 0x48, 0xd3, 0x46, 0xa1, 0x12, 0x7b, 0x68, 0x1a, 0x36, 0x41, 0x0d, 0x88, 0xb3,
+	0xfa, 0xb4, 0xf7, 0x04, 0x25, 0xb2, 0x9f, 0x3a, 0x3c, 0xb8, 0xb7, 0xc7, 0x78, 0xaa, 0x25, 0x56,
+	0xac, 0x6e, 0x93, 0x74, 0x41, 0xe4, 0x2f, 0xe2, 0x10, 0x63, 0x74, 0x74, 0xea, 0xf5, 0xae, 0x04,
+	0x6b, 0x36, 0x1f, 0xac, 0x8f, 0xcb, 0x12, 0x98, 0x81, 0xd4, 0x42, 0x5e, 0xfb, 0x6a, 0x41, 0xdc,
+	0x66, 0x99, 0xe5, 0x0e, 0x82, 0xef, 0xad, 0x0f, 0xc7, 0x33, 0xd2, 0xc3, 0x73, 0x61, 0xd0, 0x10,
+	0xd2, 0x57, 0x7d, 0x13, 0x3b, 0x5e, 0x5f, 0xe2, 0xe3, 0xe1, 0xd9, 0x06, 0xb7, 0xa7, 0xda, 0xbc,
+	0x26, 0xb8, 0x23, 0xb6, 0xe6, 0xb3, 0x42, 0xca, 0x47, 0xad, 0x85, 0xd4, 0xd4, 0xd5, 0x72, 0x8b,
+	0xae, 0x21, 0xab, 0x1b, 0x0e, 0xd1, 0x88, 0xe5, 0xd9, 0xe4, 0x53, 0xe4, 0xa3, 0xf5, 0x6d, 0xd6,
+	0xb9, 0xbe, 0xdf, 0xea, 0xf6, 0x7c, 0x56, 0xc8, 0x04, 0xe8, 0xb5, 0x90, 0x9a, 0xd1, 0xfd, 0x04,
+	0xf4, 0x39, 0x6c, 0x8d, 0x0c, 0x5b, 0xd7, 0x0c, 0xd2, 0xf3, 0xbf, 0x6f, 0x52, 0x87, 0x3f, 0x5c,
+	0xdf, 0xf4, 0xb9, 0x0b, 0xe0, 0xb7, 0xcd, 0xda, 0x68, 0x90, 0x51, 0x0b, 0xa9, 0xd9, 0x51, 0x80,
+	0x42, 0xef, 0x7d, 0x61, 0x9a, 0x7d, 0x82, 0x0d, 0xcf, 0x78, 0x6c, 0xd3, 0x7b, 0x57, 0xb8, 0xfe,
+	0xad, 0x7b, 0x07, 0xe8, 0xf4, 0xde, 0x17, 0x7e, 0x02, 0x72, 0x20, 0x63, 0x3b, 0x96, 0x6e, 0x68,
+	0x9e, 0x61, 0x3e, 0xf7, 0x7e, 0xb0, 0x41, 0xee, 0x30, 0x75, 0xbf, 0x5d, 0x69, 0x3e, 0x2b, 0xa4,
+	0xfd, 0xe4, 0x5a, 0x48, 0x4d, 0xdb, 0xbe, 0x7d, 0x25, 0x0e, 0x51, 0x8a, 0x2c, 0x5f, 0x03, 0x2c,
+	0x33, 0x19, 0xbd, 0x0b, 0xc9, 0xc5, 0x10, 0xa2, 0x95, 0x96, 0xae, 0xa4, 0xe6, 0xb3, 0x42, 0xc2,
+	0x9b, 0x3e, 0x09, 0x87, 0x2f, 0x50, 0x05, 0xd0, 0x10, 0x5b, 0x8e, 0x4e, 0xa7, 0x00, 0x95, 0xee,
+	0x7e, 0x86, 0xfb, 0x34, 0x3b, 0xa9, 0x46, 0x6e, 0x3e, 0x2b, 0x48, 0x67, 0x1e, 0xf7, 0x05, 0x19,
+	0x7f, 0x8c, 0xfb, 0xb6, 0x2a, 0x0d, 0x6f, 0x50, 0xe4, 0xdf, 0x09, 0x90, 0xf2, 0x65, 0x3d, 0x7a,
+	0x06, 0x51, 0x07, 0x6b, 0x5e, 0x85, 0x2b, 0x77, 0x3f, 0x81, 0xb0, 0xe6, 0x96, 0x34, 0xd3, 0x41,
+	0x2d, 0x10, 0xa9, 0x60, 0x97, 0x35, 0xe2, 0x30, 0x6b, 0xc4, 0x87, 0xeb, 0xfb, 0xef, 0x18, 0x3b,
+	0x98, 0xb5, 0xe1, 0x64, 0xcf, 0x5d, 0xc9, 0x3f, 0x06, 0xe9, 0x66, 0xe9, 0xa0, 0x3c, 0x80, 0xe3,
+	0x3d, 0xbd, 0xf8, 0x31, 0x25, 0xd5, 0x47, 0x41, 0xbb, 0x10, 0x67, 0xed, 0x8b, 0x3b, 0x42, 0x50,
+	0xdd, 0x9d, 0x7c, 0x0a, 0xe8, 0x76, 0x49, 0x6c, 0x88, 0x16, 0x59, 0xa0, 0x35, 0x60, 0x67, 0x45,
+	0x96, 0x6f, 0x08, 0x17, 0xf5, 0x1f, 0xee, 0x76, 0xde, 0x6e, 0x88, 0x96, 0x5c, 0xa0, 0xbd, 0x80,
+	0xed, 0x5b, 0xc9, 0xb8, 0x21, 0x98, 0xe8, 0x81, 0x15, 0xdb, 0x20, 0x32, 0x00, 0x77, 0x12, 0xc6,
+	0xdd, 0x41, 0x1e, 0x92, 0x77, 0x26, 0x53, 0x65, 0x6b, 0xc1, 0x72, 0x67, 0x79, 0x01, 0xe2, 0x8b,
+	0xf7, 0x40, 0x50, 0x80, 0x9f, 0xc5, 0x9d, 0x44, 0x7f, 0x11, 0x20, 0xe9, 0xc5, 0x1b, 0x7d, 0x1d,
+	0x62, 0xcf, 0x4f, 0x5b, 0xe5, 0x8e, 0x14, 0x92, 0xb7, 0x27, 0x53, 0x25, 0xe3, 0x31, 0x58, 0xe8,
+	0x91, 0x02, 0x89, 0x7a, 0xb3, 0x53, 0x3d, 0xa9, 0xaa, 0x1e, 0xa4, 0xc7, 0x77, 0xc3, 0x89, 0x8a,
+	0x90, 0x3c, 0x6f, 0xb6, 0xeb, 0x27, 0xcd, 0xea, 0xb1, 0x14, 0xe6, 0x13, 0xd2, 0x13, 0xf1, 0x62,
+	0x44, 0x51, 0x2a, 0xad, 0xd6, 0x29, 0x1d, 0x92, 0x91, 0x20, 0x8a, 0xeb, 0x77, 0x94, 0x87, 0x78,
+	0xbb, 0xa3, 0xd6, 0x9b, 0x27, 0x52, 0x54, 0x46, 0x93, 0xa9, 0x92, 0xf5, 0x04, 0xb8, 0x2b, 0xdd,
+	0x83, 0xff, 0x5e, 0x80, 0xdc, 0x11, 0x1e, 0xe2, 0x0b, 0xbd, 0xaf, 0x3b, 0x3a, 0xb1, 0x17, 0xb3,
+	0xb1, 0x05, 0xd1, 0x4b, 0x3c, 0xf4, 0xea, 0xe6, 0xee, 0xb6, 0xb1, 0x0a, 0x80, 0x12, 0xed, 0xaa,
+	0xe1, 0x58, 0x63, 0x95, 0x01, 0xc9, 0xdf, 0x07, 0x71, 0x41, 0xf2, 0x8f, 0x6c, 0x71, 0xc5, 0xc8,
+	0x16, 0xdd, 0x91, 0xfd, 0x2c, 0xfc, 0xa1, 0x50, 0xfc, 0x10, 0xb2, 0xc1, 0x6f, 0x13, 0x2a, 0x6b,
+	0x3b, 0xd8, 0x72, 0x98, 0x7e, 0x44, 0xe5, 0x1b, 0x8a, 0x49, 0x8c, 0x1e, 0xd3, 0x8f, 0xa8, 0x74,
+	0x59, 0xfc, 0xa7, 0x00, 0x59, 0xaf, 0xc9, 0x2c, 0xbf, 0xac, 0x68, 0x69, 0xaf, 0xfd, 0x65, 0xd5,
+	0xc1, 0x9a, 0xed, 0x7d, 0x59, 0x39, 0x8b, 0xf5, 0xff, 0xd9, 0x97, 0x55, 0xf1, 0x97, 0x61, 0x90,
+	0x3a, 0x58, 0xfb, 0x98, 0x65, 0xf8, 0x5b, 0x7d, 0x55, 0xf4, 0x0e, 0x24, 0xdc, 0x59, 0xc2, 0xe6,
+	0xb8, 0xa8, 0xc6, 0xf9, 0xf4, 0x28, 0x96, 0x20, 0xc7, 0x33, 0xdb, 0xf3, 0x82, 0x9b, 0xc8, 0xcb,
+	0x3e, 0xc0, 0x46, 0x8f, 0xd7, 0x07, 0xbe, 0xf3, 0x27, 0x01, 0x56, 0x7d, 0x92, 0xa0, 0xa7, 0x20,
+	0x1d, 0x9f, 0x9f, 0x9d, 0xd6, 0x8f, 0xca, 0x9d, 0x6a, 0xb7, 0x59, 0xfd, 0x49, 0xb5, 0x4d, 0x0b,
+	0xf9, 0xd1, 0x64, 0xaa, 0x3c, 0x58, 0x21, 0xde, 0x24, 0xbf, 0xa0, 0xbe, 0x7e, 0x0c, 0x99, 0xa5,
+	0x12, 0x7d, 0xd9, 0x0a, 0xb2, 0x3c, 0x99, 0x2a, 0xbb, 0x2b, 0x34, 0xe8, 0xfb, 0x36, 0x28, 0x5e,
+	0x6f, 0x4a, 0xe1, 0xff, 0x2e, 0xae, 0xbb, 0xaf, 0xdd, 0xc3, 0xdf, 0x46, 0x21, 0xd1, 0xe6, 0xae,
+	0x41, 0x3a, 0xc0, 0xf2, 0x0f, 0x15, 0x2a, 0xdd, 0x3b, 0x94, 0x02, 0xbf, 0xb2, 0xe4, 0x6f, 0xaf,
+	0x3d, 0xc4, 0x9e, 0x08, 0x48, 0x03, 0x71, 0xf1, 0x75, 0x8d, 0x1e, 0x6f, 0xf4, 0x15, 0xbe, 0x99,
+	0xa1, 0x97, 0xe0, 0xbd, 0x08, 0xd0, 0x7b, 0xf7, 0x8d, 0x69, 0x5f, 0x49, 0xcb, 0xdf, 0xbd, 0x53,
+	0x78, 0x55, 0x4e, 0x3c, 0x11, 0x90, 0x09, 0xe2, 0xa2, 0x60, 0xee, 0xb9, 0xd5, 0xcd, 0xc2, 0xfa,
+	0x6a, 0x06, 0x3f, 0x81, 0xb4, 0xbf, 0x4d, 0xa2, 0xdd, 0x5b, 0x85, 0x58, 0xa5, 0xbf, 0x2b, 0xef,
+	0x01, 0x5f, 0xd5, 0x69, 0x2b, 0xdf, 0x7a, 0xf5, 0x8f, 0x7c, 0xe8, 0xd5, 0x3c, 0x2f, 0x7c, 0x39,
+	0xcf, 0x0b, 0x7f, 0x9f, 0xe7, 0x85, 0x5f, 0xbf, 0xce, 0x87, 0xbe, 0x7c, 0x9d, 0x0f, 0xfd, 0xed,
+	0x75, 0x3e, 0xf4, 0x33, 0xf6, 0x84, 0xa1, 0x2f, 0x18, 0xfb, 0x22, 0xce, 0x6c, 0x3d, 0xfd, 0xcf,
+	0x00, 0xbf, 0xa3, 0x2c, 0xfa, 0x73, 0x15, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1638,6 +1733,46 @@ func (m *ReadFilterRequest) MarshalTo(dAtA []byte) (int, error) {
 		}
 		i += n3
 	}
+	if m.MaxFramePoints != 0 {
+		dAtA[i] = 0x20
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.MaxFramePoints))
+	}
+	if m.MaxMessageBytes != 0 {
+		dAtA[i] = 0x28
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.MaxMessageBytes))
+	}
+	if len(m.ExcludeTagKeys) > 0 {
+		for _, s := range m.ExcludeTagKeys {
+			dAtA[i] = 0x32
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.DuplicateResolution != 0 {
+		dAtA[i] = 0x38
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.DuplicateResolution))
+	}
+	if m.Aggregate != nil {
+		dAtA[i] = 0x42
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.Aggregate.Size()))
+		n4, err := m.Aggregate.MarshalTo(dAtA[i:])
+		if err != nil {
+			return 0, err
+		}
+		i += n4
+	}
 	return i, nil
 }
 
@@ -1720,6 +1855,36 @@ func (m *ReadGroupRequest) MarshalTo(dAtA []byte) (int, error) {
 		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(m.Hints))
 		i += 4
 	}
+	if m.MaxFramePoints != 0 {
+		dAtA[i] = 0x40
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.MaxFramePoints))
+	}
+	if m.MaxMessageBytes != 0 {
+		dAtA[i] = 0x48
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.MaxMessageBytes))
+	}
+	if len(m.ExcludeTagKeys) > 0 {
+		for _, s := range m.ExcludeTagKeys {
+			dAtA[i] = 0x52
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				dAtA[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			dAtA[i] = uint8(l)
+			i++
+			i += copy(dAtA[i:], s)
+		}
+	}
+	if m.DuplicateResolution != 0 {
+		dAtA[i] = 0x58
+		i++
+		i = encodeVarintStorageCommon(dAtA, i, uint64(m.DuplicateResolution))
+	}
 	return i, nil
 }
 
@@ -2222,7 +2387,7 @@ func (m *CapabilitiesResponse) MarshalTo(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if len(m.Caps) > 0 {
-		for k := range m.Caps {
+		for k, _ := range m.Caps {
 			dAtA[i] = 0xa
 			i++
 			v := m.Caps[k]
@@ -2418,6 +2583,25 @@ func (m *ReadFilterRequest) Size() (n int) {
 		l = m.Predicate.Size()
 		n += 1 + l + sovStorageCommon(uint64(l))
 	}
+	if m.MaxFramePoints != 0 {
+		n += 1 + sovStorageCommon(uint64(m.MaxFramePoints))
+	}
+	if m.MaxMessageBytes != 0 {
+		n += 1 + sovStorageCommon(uint64(m.MaxMessageBytes))
+	}
+	if len(m.ExcludeTagKeys) > 0 {
+		for _, s := range m.ExcludeTagKeys {
+			l = len(s)
+			n += 1 + l + sovStorageCommon(uint64(l))
+		}
+	}
+	if m.DuplicateResolution != 0 {
+		n += 1 + sovStorageCommon(uint64(m.DuplicateResolution))
+	}
+	if m.Aggregate != nil {
+		l = m.Aggregate.Size()
+		n += 1 + l + sovStorageCommon(uint64(l))
+	}
 	return n
 }
 
@@ -2453,6 +2637,21 @@ func (m *ReadGroupRequest) Size() (n int) {
 	if m.Hints != 0 {
 		n += 5
 	}
+	if m.MaxFramePoints != 0 {
+		n += 1 + sovStorageCommon(uint64(m.MaxFramePoints))
+	}
+	if m.MaxMessageBytes != 0 {
+		n += 1 + sovStorageCommon(uint64(m.MaxMessageBytes))
+	}
+	if len(m.ExcludeTagKeys) > 0 {
+		for _, s := range m.ExcludeTagKeys {
+			l = len(s)
+			n += 1 + l + sovStorageCommon(uint64(l))
+		}
+	}
+	if m.DuplicateResolution != 0 {
+		n += 1 + sovStorageCommon(uint64(m.DuplicateResolution))
+	}
 	return n
 }
 
@@ -2957,6 +3156,131 @@ func (m *ReadFilterRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxFramePoints", wireType)
+			}
+			m.MaxFramePoints = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxFramePoints |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMessageBytes", wireType)
+			}
+			m.MaxMessageBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMessageBytes |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExcludeTagKeys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExcludeTagKeys = append(m.ExcludeTagKeys, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DuplicateResolution", wireType)
+			}
+			m.DuplicateResolution = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DuplicateResolution |= DuplicateResolution(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Aggregate", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Aggregate == nil {
+				m.Aggregate = &Aggregate{}
+			}
+			if err := m.Aggregate.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorageCommon(dAtA[iNdEx:])
@@ -3212,6 +3536,95 @@ func (m *ReadGroupRequest) Unmarshal(dAtA []byte) error {
 			}
 			m.Hints = HintFlags(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
 			iNdEx += 4
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxFramePoints", wireType)
+			}
+			m.MaxFramePoints = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxFramePoints |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMessageBytes", wireType)
+			}
+			m.MaxMessageBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMessageBytes |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExcludeTagKeys", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStorageCommon
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExcludeTagKeys = append(m.ExcludeTagKeys, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DuplicateResolution", wireType)
+			}
+			m.DuplicateResolution = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStorageCommon
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DuplicateResolution |= DuplicateResolution(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStorageCommon(dAtA[iNdEx:])
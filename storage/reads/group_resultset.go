@@ -26,15 +26,97 @@ type groupResultSet struct {
 	rgc     groupByCursor
 	km      keyMerger
 
+	// groupN and seriesN are estimates of the number of groups and series
+	// read, used to choose the strategy for grouping series.
+	groupN, seriesN int64
+	strategy        GroupStrategy
+
+	// spill configures the sort of series to spill to disk, in which case
+	// merger reads the sorted series back.
+	spill  SpillConfig
+	merger *spillMerger
+
 	newCursorFn func() (SeriesCursor, error)
 	nextGroupFn func(c *groupResultSet) GroupCursor
 	sortFn      func(c *groupResultSet) (int, error)
 
+	err error
 	eof bool
 }
 
 type GroupOption func(g *groupResultSet)
 
+// GroupStrategy identifies how series are arranged into groups by a
+// datatypes.GroupBy request.
+type GroupStrategy int
+
+const (
+	// GroupStrategyNone is used by datatypes.GroupNone requests, which
+	// produce a single group.
+	GroupStrategyNone GroupStrategy = iota
+
+	// GroupStrategySort sorts all series by their group key. It is used
+	// when most groups contain a single series, or there are too many
+	// groups to hash.
+	GroupStrategySort
+
+	// GroupStrategyHash hashes series into groups and then sorts the
+	// groups, which is cheaper when there are several series per group.
+	GroupStrategyHash
+)
+
+func (s GroupStrategy) String() string {
+	switch s {
+	case GroupStrategyNone:
+		return "none"
+	case GroupStrategySort:
+		return "sort"
+	case GroupStrategyHash:
+		return "hash"
+	default:
+		return fmt.Sprintf("GroupStrategy(%d)", int(s))
+	}
+}
+
+const (
+	// hashGroupMaxN is the largest estimated number of groups that are
+	// hash grouped, beyond which the per-group overhead of the hash table
+	// outweighs the cost of sorting all series.
+	hashGroupMaxN = 1 << 17
+
+	// hashGroupMinSeriesPerGroup is the fewest estimated series per group
+	// for which hash grouping is used.
+	hashGroupMinSeriesPerGroup = 4
+)
+
+// chooseGroupStrategy returns the strategy for grouping series into groups,
+// given estimates of the number of each. The sort strategy is used when the
+// estimates are unknown.
+func chooseGroupStrategy(groupN, seriesN int64) GroupStrategy {
+	if groupN <= 0 || groupN > hashGroupMaxN || groupN*hashGroupMinSeriesPerGroup > seriesN {
+		return GroupStrategySort
+	}
+	return GroupStrategyHash
+}
+
+// GroupOptionCardinality configures the estimated number of groups and
+// series read by the request, which determines the GroupStrategy of a
+// datatypes.GroupBy request.
+func GroupOptionCardinality(groupN, seriesN int64) GroupOption {
+	return func(g *groupResultSet) {
+		g.groupN, g.seriesN = groupN, seriesN
+	}
+}
+
+// GroupOptionSpill configures the series sorted by a datatypes.GroupBy
+// request to be spilled to disk once they take more memory than allowed by
+// cfg.
+func GroupOptionSpill(cfg SpillConfig) GroupOption {
+	return func(g *groupResultSet) {
+		g.spill = cfg
+	}
+}
+
 // GroupOptionNilSortLo configures nil values to be sorted lower than any
 // other value
 func GroupOptionNilSortLo() GroupOption {
@@ -57,6 +139,18 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadGroupRequest, new
 		o(g)
 	}
 
+	// Tags are removed before series are grouped, so that they are also
+	// removed from the tag keys of each group.
+	if exclude := excludeTagKeys(req.ExcludeTagKeys, req.GroupKeys); len(exclude) > 0 {
+		g.newCursorFn = func() (SeriesCursor, error) {
+			cur, err := newCursorFn()
+			if cur == nil || err != nil {
+				return cur, err
+			}
+			return newExcludeTagsSeriesCursor(cur, exclude), nil
+		}
+	}
+
 	g.mb = newMultiShardArrayCursors(ctx, req.Range.Start, req.Range.End, true, math.MaxInt64)
 
 	for i, k := range req.GroupKeys {
@@ -65,7 +159,12 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadGroupRequest, new
 
 	switch req.Group {
 	case datatypes.GroupBy:
-		g.sortFn = groupBySort
+		g.strategy = chooseGroupStrategy(g.groupN, g.seriesN)
+		if g.strategy == GroupStrategyHash {
+			g.sortFn = groupByHashSort
+		} else {
+			g.sortFn = groupBySort
+		}
 		g.nextGroupFn = groupByNextGroup
 		g.rgc = groupByCursor{
 			ctx:  ctx,
@@ -83,7 +182,11 @@ func NewGroupResultSet(ctx context.Context, req *datatypes.ReadGroupRequest, new
 	}
 
 	n, err := g.sort()
-	if n == 0 || err != nil {
+	if err != nil {
+		// The error is returned by Err once no groups are returned.
+		g.err, g.eof = err, true
+		return g
+	} else if n == 0 {
 		return nil
 	}
 
@@ -99,14 +202,26 @@ var (
 	nilSortHi = []byte{0xff} // sort nil values
 )
 
-func (g *groupResultSet) Err() error { return nil }
+func (g *groupResultSet) Err() error { return g.err }
+
+// GroupStrategy returns the strategy used to group series.
+func (g *groupResultSet) GroupStrategy() GroupStrategy { return g.strategy }
 
-func (g *groupResultSet) Close() {}
+func (g *groupResultSet) Close() {
+	if g.merger != nil {
+		g.merger.close()
+		g.merger = nil
+	}
+}
 
 func (g *groupResultSet) Next() GroupCursor {
 	if g.eof {
 		return nil
 	}
+	if err := g.ctx.Err(); err != nil {
+		g.err = err
+		return nil
+	}
 
 	return g.nextGroupFn(g)
 }
@@ -114,7 +229,7 @@ func (g *groupResultSet) Next() GroupCursor {
 func (g *groupResultSet) sort() (int, error) {
 	span, _ := tracing.StartSpanFromContext(g.ctx)
 	defer span.Finish()
-	span.LogKV("group_type", g.req.Group.String())
+	span.LogKV("group_type", g.req.Group.String(), "group_strategy", g.strategy.String())
 
 	n, err := g.sortFn(g)
 
@@ -196,7 +311,7 @@ func groupNoneSort(g *groupResultSet) (int, error) {
 	}
 
 	cur.Close()
-	return n, nil
+	return n, cur.Err()
 }
 
 func groupByNextGroup(g *groupResultSet) GroupCursor {
@@ -224,6 +339,44 @@ func groupByNextGroup(g *groupResultSet) GroupCursor {
 	return &g.rgc
 }
 
+// groupBySpilledNextGroup returns the next group of the series read back
+// from disk.
+func groupBySpilledNextGroup(g *groupResultSet) GroupCursor {
+	row, err := g.merger.next()
+	if err != nil {
+		g.err, g.eof = err, true
+		return nil
+	} else if row == nil {
+		g.eof = true
+		return nil
+	}
+
+	for i := range g.keys {
+		g.rgc.vals[i] = row.Tags.Get(g.keys[i])
+	}
+
+	g.km.clear()
+	g.km.mergeTagKeys(row.Tags)
+	rows := []*SeriesRow{row}
+	for next := g.merger.peek(); next != nil && bytes.Equal(row.SortKey, next.SortKey); next = g.merger.peek() {
+		if next, err = g.merger.next(); err != nil {
+			g.err, g.eof = err, true
+			return nil
+		}
+		g.km.mergeTagKeys(next.Tags)
+		rows = append(rows, next)
+	}
+
+	g.rgc.reset(rows)
+	g.rgc.keys = g.km.get()
+
+	if g.merger.peek() == nil {
+		g.eof = true
+	}
+
+	return &g.rgc
+}
+
 func groupBySort(g *groupResultSet) (int, error) {
 	cur, err := g.newCursorFn()
 	if err != nil {
@@ -232,48 +385,188 @@ func groupBySort(g *groupResultSet) (int, error) {
 		return 0, nil
 	}
 
+	rb := g.newSeriesRowBuilder()
+	if g.spill.Enabled() {
+		defer cur.Close()
+		return groupBySpillSort(g, cur, rb, nil)
+	}
+
 	var rows []*SeriesRow
-	vals := make([][]byte, len(g.keys))
-	tagsBuf := &tagsBuffer{sz: 4096}
 	allTime := g.req.Hints.HintSchemaAllTime()
 
 	row := cur.Next()
 	for row != nil {
 		if allTime || g.seriesHasPoints(row) {
-			nr := *row
-			nr.SeriesTags = tagsBuf.copyTags(nr.SeriesTags)
-			nr.Tags = tagsBuf.copyTags(nr.Tags)
-
-			l := len(g.keys) // for sort key separators
-			for i, k := range g.keys {
-				vals[i] = nr.Tags.Get(k)
-				if len(vals[i]) == 0 {
-					vals[i] = g.nilSort
-				}
-				l += len(vals[i])
+			rows = append(rows, rb.build(row))
+		}
+		row = cur.Next()
+	}
+	if err := cur.Err(); err != nil {
+		cur.Close()
+		return 0, err
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return bytes.Compare(rows[i].SortKey, rows[j].SortKey) == -1
+	})
+
+	g.rows = rows
+
+	cur.Close()
+	return len(rows), nil
+}
+
+// groupBySpillSort sorts the series read from cur after rows, spilling them to
+// disk once they take more memory than allowed. Series spilled to disk are
+// read back by groupBySpilledNextGroup.
+func groupBySpillSort(g *groupResultSet, cur SeriesCursor, rb *seriesRowBuilder, rows []*SeriesRow) (int, error) {
+	sorter := newSeriesRowSorter(g.spill)
+	defer sorter.close()
+
+	add := func(row *SeriesRow) error {
+		spilled, err := sorter.add(row)
+		if spilled {
+			// The tags of the spilled rows are no longer referenced.
+			rb.tagsBuf = &tagsBuffer{sz: rb.tagsBuf.sz}
+		}
+		return err
+	}
+
+	n := 0
+	for _, row := range rows {
+		if err := add(row); err != nil {
+			return 0, err
+		}
+		n++
+	}
+
+	allTime := g.req.Hints.HintSchemaAllTime()
+	for row := cur.Next(); row != nil; row = cur.Next() {
+		if allTime || g.seriesHasPoints(row) {
+			if err := add(rb.build(row)); err != nil {
+				return 0, err
 			}
+			n++
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return 0, err
+	}
 
-			nr.SortKey = make([]byte, 0, l)
-			for _, v := range vals {
-				nr.SortKey = append(nr.SortKey, v...)
-				nr.SortKey = append(nr.SortKey, ',')
+	rows, merger, err := sorter.finish()
+	if err != nil {
+		return 0, err
+	}
+	g.rows, g.merger = rows, merger
+	if merger != nil {
+		g.nextGroupFn = groupBySpilledNextGroup
+	}
+	return n, nil
+}
+
+// groupByHashSort arranges the series in the same order as groupBySort by
+// hashing each series into its group and sorting only the groups.
+func groupByHashSort(g *groupResultSet) (int, error) {
+	cur, err := g.newCursorFn()
+	if err != nil {
+		return 0, err
+	} else if cur == nil {
+		return 0, nil
+	}
+
+	var groups [][]*SeriesRow
+	index := make(map[string]int, g.groupN)
+	rb := g.newSeriesRowBuilder()
+	allTime := g.req.Hints.HintSchemaAllTime()
+
+	n := 0
+	var size int64
+	row := cur.Next()
+	for row != nil {
+		if allTime || g.seriesHasPoints(row) {
+			nr := rb.build(row)
+			i, ok := index[string(nr.SortKey)]
+			if !ok {
+				i = len(groups)
+				index[string(nr.SortKey)] = i
+				groups = append(groups, nil)
 			}
+			groups[i] = append(groups[i], nr)
+			n++
 
-			rows = append(rows, &nr)
+			// Sort the series instead once they need to be spilled to disk,
+			// which keeps the order of the series of each group.
+			if size += seriesRowSize(nr); g.spill.Enabled() && size >= g.spill.MemoryBytes {
+				rows := make([]*SeriesRow, 0, n)
+				for _, group := range groups {
+					rows = append(rows, group...)
+				}
+				g.strategy = GroupStrategySort
+				defer cur.Close()
+				return groupBySpillSort(g, cur, rb, rows)
+			}
 		}
 		row = cur.Next()
 	}
+	if err := cur.Err(); err != nil {
+		cur.Close()
+		return 0, err
+	}
 
-	sort.Slice(rows, func(i, j int) bool {
-		return bytes.Compare(rows[i].SortKey, rows[j].SortKey) == -1
+	sort.Slice(groups, func(i, j int) bool {
+		return bytes.Compare(groups[i][0].SortKey, groups[j][0].SortKey) == -1
 	})
 
+	rows := make([]*SeriesRow, 0, n)
+	for _, group := range groups {
+		rows = append(rows, group...)
+	}
 	g.rows = rows
 
 	cur.Close()
 	return len(rows), nil
 }
 
+// seriesRowBuilder copies the series rows read by a groupResultSet and
+// computes their sort keys.
+type seriesRowBuilder struct {
+	keys    [][]byte
+	nilSort []byte
+	vals    [][]byte
+	tagsBuf *tagsBuffer
+}
+
+func (g *groupResultSet) newSeriesRowBuilder() *seriesRowBuilder {
+	return &seriesRowBuilder{
+		keys:    g.keys,
+		nilSort: g.nilSort,
+		vals:    make([][]byte, len(g.keys)),
+		tagsBuf: &tagsBuffer{sz: 4096},
+	}
+}
+
+func (b *seriesRowBuilder) build(row *SeriesRow) *SeriesRow {
+	nr := *row
+	nr.SeriesTags = b.tagsBuf.copyTags(nr.SeriesTags)
+	nr.Tags = b.tagsBuf.copyTags(nr.Tags)
+
+	l := len(b.keys) // for sort key separators
+	for i, k := range b.keys {
+		b.vals[i] = nr.Tags.Get(k)
+		if len(b.vals[i]) == 0 {
+			b.vals[i] = b.nilSort
+		}
+		l += len(b.vals[i])
+	}
+
+	nr.SortKey = make([]byte, 0, l)
+	for _, v := range b.vals {
+		nr.SortKey = append(nr.SortKey, v...)
+		nr.SortKey = append(nr.SortKey, ',')
+	}
+	return &nr
+}
+
 type groupNoneCursor struct {
 	ctx  context.Context
 	mb   multiShardCursors
@@ -2,6 +2,8 @@ package reads_test
 
 import (
 	"context"
+	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
 
@@ -235,6 +237,217 @@ group:
 	}
 }
 
+func TestNewGroupResultSet_GroupStrategy(t *testing.T) {
+	newCursor := func() (reads.SeriesCursor, error) {
+		return &sliceSeriesCursor{
+			rows: newSeriesRows(
+				"cpu,tag0=val00,tag1=val10",
+				"cpu,tag0=val00,tag1=val11",
+				"cpu,tag0=val01,tag1=val10",
+				"cpu,tag0=val01,tag1=val11",
+				"cpu,tag0=val02,tag1=val10",
+				"cpu,tag0=val03,tag1=val10",
+				"cpu,tag0=val04,tag1=val10",
+				"cpu,tag0=val05,tag1=val10",
+			)}, nil
+	}
+
+	tests := []struct {
+		name            string
+		groupN, seriesN int64
+		exp             reads.GroupStrategy
+	}{
+		{name: "no estimate", exp: reads.GroupStrategySort},
+		{name: "few groups", groupN: 2, seriesN: 8, exp: reads.GroupStrategyHash},
+		{name: "one series per group", groupN: 8, seriesN: 8, exp: reads.GroupStrategySort},
+		{name: "too many groups", groupN: 1 << 20, seriesN: 1 << 24, exp: reads.GroupStrategySort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hints datatypes.HintFlags
+			hints.SetHintSchemaAllTime()
+			rs := reads.NewGroupResultSet(context.Background(), &datatypes.ReadGroupRequest{
+				Group:     datatypes.GroupBy,
+				GroupKeys: []string{"tag1"},
+				Hints:     hints,
+			}, newCursor, reads.GroupOptionCardinality(tt.groupN, tt.seriesN))
+
+			if got := rs.(interface{ GroupStrategy() reads.GroupStrategy }).GroupStrategy(); got != tt.exp {
+				t.Fatalf("unexpected strategy: got %s, exp %s", got, tt.exp)
+			}
+
+			sb := new(strings.Builder)
+			GroupResultSetToString(sb, rs, SkipNilCursor())
+
+			var groups []string
+			for _, line := range strings.Split(sb.String(), "\n") {
+				if strings.HasPrefix(line, "  partition key:") {
+					groups = append(groups, line)
+				}
+			}
+			if exp := []string{"  partition key: val10", "  partition key: val11"}; !cmp.Equal(groups, exp) {
+				t.Errorf("unexpected groups; -got/+exp\n%s", cmp.Diff(groups, exp))
+			}
+			if got, exp := strings.Count(sb.String(), "series:"), 8; got != exp {
+				t.Errorf("unexpected number of series: got %d, exp %d", got, exp)
+			}
+		})
+	}
+}
+
+func TestNewGroupResultSet_Spill(t *testing.T) {
+	newCursor := func() (reads.SeriesCursor, error) {
+		return &sliceSeriesCursor{
+			rows: newSeriesRows(
+				"cpu,tag0=val03,tag1=val11",
+				"cpu,tag0=val00,tag1=val10",
+				"mem,tag0=val01",
+				"cpu,tag0=val01,tag1=val11",
+				"cpu,tag0=val02,tag1=val10",
+				"cpu,tag0=val00,tag1=val11",
+				"mem,tag0=val00",
+				"cpu,tag0=val01,tag1=val10",
+			)}, nil
+	}
+
+	read := func(t *testing.T, opts ...reads.GroupOption) (string, error) {
+		t.Helper()
+		var hints datatypes.HintFlags
+		hints.SetHintSchemaAllTime()
+		rs := reads.NewGroupResultSet(context.Background(), &datatypes.ReadGroupRequest{
+			Group:     datatypes.GroupBy,
+			GroupKeys: []string{"tag1"},
+			Hints:     hints,
+		}, newCursor, opts...)
+
+		defer rs.Close()
+
+		sb := new(strings.Builder)
+		GroupResultSetToString(sb, rs, SkipNilCursor())
+		return sb.String(), rs.Err()
+	}
+
+	// Hash grouping keeps the order of the series of each group.
+	exp, err := read(t, reads.GroupOptionCardinality(3, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		groupN, seriesN int64
+		memoryBytes     int64
+	}{
+		{name: "sort in memory", memoryBytes: 1 << 20},
+		{name: "sort spilled", memoryBytes: 1},
+		{name: "hash spilled", groupN: 3, seriesN: 8, memoryBytes: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "reads-spill-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			got, err := read(t,
+				reads.GroupOptionCardinality(tt.groupN, tt.seriesN),
+				reads.GroupOptionSpill(reads.SpillConfig{Dir: dir, MemoryBytes: tt.memoryBytes}))
+			if err != nil {
+				t.Fatal(err)
+			} else if !cmp.Equal(got, exp) {
+				t.Errorf("unexpected value; -got/+exp\n%s", cmp.Diff(strings.Split(got, "\n"), strings.Split(exp, "\n")))
+			}
+
+			if fis, err := ioutil.ReadDir(dir); err != nil {
+				t.Fatal(err)
+			} else if len(fis) != 0 {
+				t.Errorf("got %d temporary files after close, exp 0", len(fis))
+			}
+		})
+	}
+
+	t.Run("temp limit", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "reads-spill-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		_, err = read(t, reads.GroupOptionSpill(reads.SpillConfig{Dir: dir, MemoryBytes: 1, MaxTempBytes: 64}))
+		if err != reads.ErrSpillLimitExceeded {
+			t.Fatalf("unexpected error: got %v, exp %v", err, reads.ErrSpillLimitExceeded)
+		}
+
+		if fis, err := ioutil.ReadDir(dir); err != nil {
+			t.Fatal(err)
+		} else if len(fis) != 0 {
+			t.Errorf("got %d temporary files, exp 0", len(fis))
+		}
+	})
+}
+
+func TestNewGroupResultSet_ExcludeTagKeys(t *testing.T) {
+	newCursor := func() (reads.SeriesCursor, error) {
+		return &sliceSeriesCursor{
+			rows: newSeriesRows(
+				"cpu,host=a,region=west,tag0=val00",
+				"cpu,host=b,region=west,tag0=val01",
+				"cpu,host=c,region=east,tag0=val00",
+			)}, nil
+	}
+
+	var hints datatypes.HintFlags
+	hints.SetHintSchemaAllTime()
+
+	// Keys grouped by are kept even if they are excluded.
+	rs := reads.NewGroupResultSet(context.Background(), &datatypes.ReadGroupRequest{
+		Group:          datatypes.GroupBy,
+		GroupKeys:      []string{"region"},
+		ExcludeTagKeys: []string{"host", "region"},
+		Hints:          hints,
+	}, newCursor)
+
+	sb := new(strings.Builder)
+	GroupResultSetToString(sb, rs, SkipNilCursor())
+
+	exp := `group:
+  tag key      : _m,region,tag0
+  partition key: east
+    series: _m=cpu,region=east,tag0=val00
+group:
+  tag key      : _m,region,tag0
+  partition key: west
+    series: _m=cpu,region=west,tag0=val00
+    series: _m=cpu,region=west,tag0=val01
+`
+	if got := sb.String(); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected value; -got/+exp\n%s", cmp.Diff(strings.Split(got, "\n"), strings.Split(exp, "\n")))
+	}
+
+	rs = reads.NewGroupResultSet(context.Background(), &datatypes.ReadGroupRequest{
+		Group:          datatypes.GroupNone,
+		ExcludeTagKeys: []string{"host", "tag0"},
+		Hints:          hints,
+	}, newCursor)
+
+	sb = new(strings.Builder)
+	GroupResultSetToString(sb, rs, SkipNilCursor())
+
+	exp = `group:
+  tag key      : _m,region
+  partition key: 
+    series: _m=cpu,region=west
+    series: _m=cpu,region=west
+    series: _m=cpu,region=east
+`
+	if got := sb.String(); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected value; -got/+exp\n%s", cmp.Diff(strings.Split(got, "\n"), strings.Split(exp, "\n")))
+	}
+}
+
 func TestNewGroupResultSet_GroupNone_NoDataReturnsNil(t *testing.T) {
 	newCursor := func() (reads.SeriesCursor, error) {
 		return &sliceSeriesCursor{
@@ -265,6 +478,40 @@ func TestNewGroupResultSet_GroupBy_NoDataReturnsNil(t *testing.T) {
 	}
 }
 
+func TestNewGroupResultSet_Canceled(t *testing.T) {
+	newCursor := func() (reads.SeriesCursor, error) {
+		return &sliceSeriesCursor{
+			rows: newSeriesRows(
+				"cpu,tag0=val00",
+				"cpu,tag0=val01",
+			)}, nil
+	}
+
+	var hints datatypes.HintFlags
+	hints.SetHintSchemaAllTime()
+	ctx, cancel := context.WithCancel(context.Background())
+	rs := reads.NewGroupResultSet(ctx, &datatypes.ReadGroupRequest{Group: datatypes.GroupBy, GroupKeys: []string{"tag0"}, Hints: hints}, newCursor)
+	if rs == nil {
+		t.Fatal("expected a result set")
+	}
+	defer rs.Close()
+
+	if gc := rs.Next(); gc == nil {
+		t.Fatal("expected a group")
+	} else {
+		gc.Close()
+	}
+
+	// No more groups are read once the read is cancelled.
+	cancel()
+	if gc := rs.Next(); gc != nil {
+		t.Error("expected no group after the read is cancelled")
+	}
+	if err := rs.Err(); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
 func TestNewGroupResultSet_SortOrder(t *testing.T) {
 	tests := []struct {
 		name string
@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/values"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/tsdb/cursors"
@@ -51,6 +52,22 @@ func (r *storeReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec
 	}, nil
 }
 
+func (r *storeReader) ReadAggregate(ctx context.Context, spec influxdb.ReadAggregateSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	fi := &filterIterator{
+		ctx:   ctx,
+		s:     r.s,
+		spec:  spec.ReadFilterSpec,
+		cache: newTagsCache(0),
+		alloc: alloc,
+	}
+	if agg, err := determineAggregateMethod(spec.AggregateMethod); err != nil {
+		return nil, err
+	} else if agg != datatypes.AggregateTypeNone {
+		fi.agg = &datatypes.Aggregate{Type: agg}
+	}
+	return fi, nil
+}
+
 func (r *storeReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeysSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
 	var predicate *datatypes.Predicate
 	if spec.Predicate != nil {
@@ -100,6 +117,12 @@ type filterIterator struct {
 	stats cursors.CursorStats
 	cache *tagsCache
 	alloc *memory.Allocator
+
+	// agg aggregates the points of each series, if set. localAgg is set
+	// instead of the aggregate of the request if the store cannot compute
+	// it.
+	agg      *datatypes.Aggregate
+	localAgg *datatypes.Aggregate
 }
 
 func (fi *filterIterator) Statistics() cursors.CursorStats { return fi.stats }
@@ -130,6 +153,16 @@ func (fi *filterIterator) Do(f func(flux.Table) error) error {
 	req.Predicate = predicate
 	req.Range.Start = int64(fi.spec.Bounds.Start)
 	req.Range.End = int64(fi.spec.Bounds.Stop)
+	req.ExcludeTagKeys = fi.spec.ExcludeTagKeys
+	req.DuplicateResolution = datatypes.DuplicateResolution(fi.spec.DuplicateResolution)
+
+	if fi.agg != nil {
+		if hasAggregate(fi.s, fi.agg.Type) {
+			req.Aggregate = fi.agg
+		} else {
+			fi.localAgg = fi.agg
+		}
+	}
 
 	rs, err := fi.s.ReadFilter(fi.ctx, &req)
 	if err != nil {
@@ -164,6 +197,9 @@ func (fi *filterIterator) handleRead(f func(flux.Table) error, rs ResultSet) err
 READ:
 	for rs.Next() {
 		cur = rs.Cursor()
+		if fi.localAgg != nil {
+			cur = newAggregateArrayCursor(fi.ctx, fi.localAgg, cur)
+		}
 		if cur == nil {
 			// no data for series key + field combination
 			continue
@@ -211,6 +247,8 @@ READ:
 		stats := table.Statistics()
 		fi.stats.ScannedValues += stats.ScannedValues
 		fi.stats.ScannedBytes += stats.ScannedBytes
+		fi.stats.DuplicateValues += stats.DuplicateValues
+		query.ScanStatisticsFromContext(fi.ctx).Add(int64(stats.ScannedValues), int64(stats.ScannedBytes))
 		table.Close()
 		table = nil
 	}
@@ -218,16 +256,28 @@ READ:
 }
 
 type groupIterator struct {
-	ctx   context.Context
-	s     Store
-	spec  influxdb.ReadGroupSpec
-	stats cursors.CursorStats
-	cache *tagsCache
-	alloc *memory.Allocator
+	ctx      context.Context
+	s        Store
+	spec     influxdb.ReadGroupSpec
+	stats    cursors.CursorStats
+	strategy GroupStrategy
+	cache    *tagsCache
+	alloc    *memory.Allocator
+
+	// localAgg aggregates the points of each series of the groups, if the
+	// store cannot compute the aggregate of the request.
+	localAgg *datatypes.Aggregate
 }
 
 func (gi *groupIterator) Statistics() cursors.CursorStats { return gi.stats }
 
+// Metadata reports the strategy used to group series.
+func (gi *groupIterator) Metadata() flux.Metadata {
+	return flux.Metadata{
+		"influxdb/group-strategy": []interface{}{gi.strategy.String()},
+	}
+}
+
 func (gi *groupIterator) Do(f func(flux.Table) error) error {
 	src := gi.s.GetSource(
 		uint64(gi.spec.OrganizationID),
@@ -257,11 +307,17 @@ func (gi *groupIterator) Do(f func(flux.Table) error) error {
 
 	req.Group = convertGroupMode(gi.spec.GroupMode)
 	req.GroupKeys = gi.spec.GroupKeys
+	req.ExcludeTagKeys = gi.spec.ExcludeTagKeys
+	req.DuplicateResolution = datatypes.DuplicateResolution(gi.spec.DuplicateResolution)
 
 	if agg, err := determineAggregateMethod(gi.spec.AggregateMethod); err != nil {
 		return err
 	} else if agg != datatypes.AggregateTypeNone {
-		req.Aggregate = &datatypes.Aggregate{Type: agg}
+		if hasAggregate(gi.s, agg) {
+			req.Aggregate = &datatypes.Aggregate{Type: agg}
+		} else {
+			gi.localAgg = &datatypes.Aggregate{Type: agg}
+		}
 	}
 
 	rs, err := gi.s.ReadGroup(gi.ctx, &req)
@@ -272,6 +328,10 @@ func (gi *groupIterator) Do(f func(flux.Table) error) error {
 	if rs == nil {
 		return nil
 	}
+
+	if s, ok := rs.(interface{ GroupStrategy() GroupStrategy }); ok {
+		gi.strategy = s.GroupStrategy()
+	}
 	return gi.handleRead(f, rs)
 }
 
@@ -297,7 +357,7 @@ func (gi *groupIterator) handleRead(f func(flux.Table) error, rs GroupResultSet)
 		gi.cache.Release()
 	}()
 
-	gc = rs.Next()
+	gc = gi.nextGroup(rs)
 READ:
 	for gc != nil {
 		for gc.Next() {
@@ -309,7 +369,7 @@ READ:
 
 		if cur == nil {
 			gc.Close()
-			gc = rs.Next()
+			gc = gi.nextGroup(rs)
 			continue
 		}
 
@@ -355,14 +415,44 @@ READ:
 		stats := table.Statistics()
 		gi.stats.ScannedValues += stats.ScannedValues
 		gi.stats.ScannedBytes += stats.ScannedBytes
+		gi.stats.DuplicateValues += stats.DuplicateValues
+		query.ScanStatisticsFromContext(gi.ctx).Add(int64(stats.ScannedValues), int64(stats.ScannedBytes))
 		table.Close()
 		table = nil
 
-		gc = rs.Next()
+		gc = gi.nextGroup(rs)
 	}
 	return rs.Err()
 }
 
+// nextGroup returns the next group of rs, with the points of each series
+// aggregated if the store did not aggregate them.
+func (gi *groupIterator) nextGroup(rs GroupResultSet) GroupCursor {
+	gc := rs.Next()
+	if gc == nil || gi.localAgg == nil {
+		return gc
+	}
+	return &aggregateGroupCursor{GroupCursor: gc, ctx: gi.ctx, agg: gi.localAgg}
+}
+
+// aggregateGroupCursor aggregates the points of each series of a group.
+type aggregateGroupCursor struct {
+	GroupCursor
+	ctx context.Context
+	agg *datatypes.Aggregate
+}
+
+func (c *aggregateGroupCursor) Cursor() cursors.Cursor {
+	return newAggregateArrayCursor(c.ctx, c.agg, c.GroupCursor.Cursor())
+}
+
+// hasAggregate reports whether s computes the aggregate typ of the points of
+// each series it reads.
+func hasAggregate(s Store, typ datatypes.Aggregate_AggregateType) bool {
+	c, ok := s.(AggregateCapability)
+	return ok && c.HasAggregate(typ)
+}
+
 func determineAggregateMethod(agg string) (datatypes.Aggregate_AggregateType, error) {
 	if agg == "" {
 		return datatypes.AggregateTypeNone, nil
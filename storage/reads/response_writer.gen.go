@@ -44,7 +44,7 @@ func (w *ResponseWriter) streamFloatArraySeries(cur cursors.FloatArrayCursor) {
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz > w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -58,7 +58,7 @@ func (w *ResponseWriter) streamFloatArrayPoints(cur cursors.FloatArrayCursor) {
 	w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 
 	var seriesValueCount = 0
-	for {
+	for w.err == nil {
 		// If the number of values produced by cur > 1000,
 		// cur.Next() will produce batches of values that are of
 		// length ≤ 1000.
@@ -73,34 +73,57 @@ func (w *ResponseWriter) streamFloatArrayPoints(cur cursors.FloatArrayCursor) {
 		}
 
 		seriesValueCount += a.Len()
-		// As specified in the struct definition, w.sz is an estimated
-		// size (in bytes) of the buffered data. It is therefore a
-		// deliberate choice to accumulate using the array Size, which is
-		// cheap to calculate. Calling frame.Size() can be expensive
-		// when using varint encoding for numbers.
-		w.sz += a.Size()
-
-		frame.Timestamps = append(frame.Timestamps, a.Timestamps...)
-		frame.Values = append(frame.Values, a.Values...)
-
-		// given the expectation of cur.Next, we attempt to limit
-		// the number of values appended to the frame to batchSize (1000)
-		needsFrame := len(frame.Timestamps) >= batchSize
-
-		if w.sz >= writeSize {
-			needsFrame = true
-			w.Flush()
-			if w.err != nil {
-				break
+
+		for i := 0; i < a.Len(); {
+			if len(frame.Timestamps) >= w.maxFramePoints {
+				// new frames are returned with Timestamps and Values preallocated
+				// to a minimum of batchSize length to reduce further allocations.
+				p = w.getFloatPointsFrame()
+				frame = p.FloatPoints
+				w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 			}
-		}
 
-		if needsFrame {
-			// new frames are returned with Timestamps and Values preallocated
-			// to a minimum of batchSize length to reduce further allocations.
-			p = w.getFloatPointsFrame()
-			frame = p.FloatPoints
-			w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+			// Determine how many of the remaining values fit in the frame
+			// without exceeding maxMessageBytes. As specified in the struct
+			// definition, w.sz is an estimated size (in bytes) of the buffered
+			// data. It is therefore a deliberate choice to estimate using the
+			// array Size, which is cheap to calculate. Calling frame.Size()
+			// can be expensive when using varint encoding for numbers.
+			n := a.Len() - i
+			if m := w.maxFramePoints - len(frame.Timestamps); n > m {
+				n = m
+			}
+			vsz := a.Size() / a.Len()
+			if m := (w.maxMessageBytes - w.sz) / vsz; n > m {
+				n = m
+			}
+			if n < 0 {
+				n = 0
+			}
+			sz := n * vsz
+
+			if n == 0 {
+				if w.sz > 0 {
+					// Send the pending data and continue with a new frame.
+					w.Flush()
+					if w.err != nil {
+						break
+					}
+					p = w.getFloatPointsFrame()
+					frame = p.FloatPoints
+					w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+					continue
+				}
+
+				// A single value exceeds maxMessageBytes, so it is sent
+				// in a message of its own.
+				n, sz = 1, vsz
+			}
+
+			w.sz += sz
+			frame.Timestamps = append(frame.Timestamps, a.Timestamps[i:i+n]...)
+			frame.Values = append(frame.Values, a.Values[i:i+n]...)
+			i += n
 		}
 	}
 
@@ -109,7 +132,7 @@ func (w *ResponseWriter) streamFloatArrayPoints(cur cursors.FloatArrayCursor) {
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz >= w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -147,7 +170,7 @@ func (w *ResponseWriter) streamIntegerArraySeries(cur cursors.IntegerArrayCursor
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz > w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -161,7 +184,7 @@ func (w *ResponseWriter) streamIntegerArrayPoints(cur cursors.IntegerArrayCursor
 	w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 
 	var seriesValueCount = 0
-	for {
+	for w.err == nil {
 		// If the number of values produced by cur > 1000,
 		// cur.Next() will produce batches of values that are of
 		// length ≤ 1000.
@@ -176,34 +199,57 @@ func (w *ResponseWriter) streamIntegerArrayPoints(cur cursors.IntegerArrayCursor
 		}
 
 		seriesValueCount += a.Len()
-		// As specified in the struct definition, w.sz is an estimated
-		// size (in bytes) of the buffered data. It is therefore a
-		// deliberate choice to accumulate using the array Size, which is
-		// cheap to calculate. Calling frame.Size() can be expensive
-		// when using varint encoding for numbers.
-		w.sz += a.Size()
-
-		frame.Timestamps = append(frame.Timestamps, a.Timestamps...)
-		frame.Values = append(frame.Values, a.Values...)
-
-		// given the expectation of cur.Next, we attempt to limit
-		// the number of values appended to the frame to batchSize (1000)
-		needsFrame := len(frame.Timestamps) >= batchSize
-
-		if w.sz >= writeSize {
-			needsFrame = true
-			w.Flush()
-			if w.err != nil {
-				break
+
+		for i := 0; i < a.Len(); {
+			if len(frame.Timestamps) >= w.maxFramePoints {
+				// new frames are returned with Timestamps and Values preallocated
+				// to a minimum of batchSize length to reduce further allocations.
+				p = w.getIntegerPointsFrame()
+				frame = p.IntegerPoints
+				w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 			}
-		}
 
-		if needsFrame {
-			// new frames are returned with Timestamps and Values preallocated
-			// to a minimum of batchSize length to reduce further allocations.
-			p = w.getIntegerPointsFrame()
-			frame = p.IntegerPoints
-			w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+			// Determine how many of the remaining values fit in the frame
+			// without exceeding maxMessageBytes. As specified in the struct
+			// definition, w.sz is an estimated size (in bytes) of the buffered
+			// data. It is therefore a deliberate choice to estimate using the
+			// array Size, which is cheap to calculate. Calling frame.Size()
+			// can be expensive when using varint encoding for numbers.
+			n := a.Len() - i
+			if m := w.maxFramePoints - len(frame.Timestamps); n > m {
+				n = m
+			}
+			vsz := a.Size() / a.Len()
+			if m := (w.maxMessageBytes - w.sz) / vsz; n > m {
+				n = m
+			}
+			if n < 0 {
+				n = 0
+			}
+			sz := n * vsz
+
+			if n == 0 {
+				if w.sz > 0 {
+					// Send the pending data and continue with a new frame.
+					w.Flush()
+					if w.err != nil {
+						break
+					}
+					p = w.getIntegerPointsFrame()
+					frame = p.IntegerPoints
+					w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+					continue
+				}
+
+				// A single value exceeds maxMessageBytes, so it is sent
+				// in a message of its own.
+				n, sz = 1, vsz
+			}
+
+			w.sz += sz
+			frame.Timestamps = append(frame.Timestamps, a.Timestamps[i:i+n]...)
+			frame.Values = append(frame.Values, a.Values[i:i+n]...)
+			i += n
 		}
 	}
 
@@ -212,7 +258,7 @@ func (w *ResponseWriter) streamIntegerArrayPoints(cur cursors.IntegerArrayCursor
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz >= w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -250,7 +296,7 @@ func (w *ResponseWriter) streamUnsignedArraySeries(cur cursors.UnsignedArrayCurs
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz > w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -264,7 +310,7 @@ func (w *ResponseWriter) streamUnsignedArrayPoints(cur cursors.UnsignedArrayCurs
 	w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 
 	var seriesValueCount = 0
-	for {
+	for w.err == nil {
 		// If the number of values produced by cur > 1000,
 		// cur.Next() will produce batches of values that are of
 		// length ≤ 1000.
@@ -279,34 +325,57 @@ func (w *ResponseWriter) streamUnsignedArrayPoints(cur cursors.UnsignedArrayCurs
 		}
 
 		seriesValueCount += a.Len()
-		// As specified in the struct definition, w.sz is an estimated
-		// size (in bytes) of the buffered data. It is therefore a
-		// deliberate choice to accumulate using the array Size, which is
-		// cheap to calculate. Calling frame.Size() can be expensive
-		// when using varint encoding for numbers.
-		w.sz += a.Size()
-
-		frame.Timestamps = append(frame.Timestamps, a.Timestamps...)
-		frame.Values = append(frame.Values, a.Values...)
-
-		// given the expectation of cur.Next, we attempt to limit
-		// the number of values appended to the frame to batchSize (1000)
-		needsFrame := len(frame.Timestamps) >= batchSize
-
-		if w.sz >= writeSize {
-			needsFrame = true
-			w.Flush()
-			if w.err != nil {
-				break
+
+		for i := 0; i < a.Len(); {
+			if len(frame.Timestamps) >= w.maxFramePoints {
+				// new frames are returned with Timestamps and Values preallocated
+				// to a minimum of batchSize length to reduce further allocations.
+				p = w.getUnsignedPointsFrame()
+				frame = p.UnsignedPoints
+				w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 			}
-		}
 
-		if needsFrame {
-			// new frames are returned with Timestamps and Values preallocated
-			// to a minimum of batchSize length to reduce further allocations.
-			p = w.getUnsignedPointsFrame()
-			frame = p.UnsignedPoints
-			w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+			// Determine how many of the remaining values fit in the frame
+			// without exceeding maxMessageBytes. As specified in the struct
+			// definition, w.sz is an estimated size (in bytes) of the buffered
+			// data. It is therefore a deliberate choice to estimate using the
+			// array Size, which is cheap to calculate. Calling frame.Size()
+			// can be expensive when using varint encoding for numbers.
+			n := a.Len() - i
+			if m := w.maxFramePoints - len(frame.Timestamps); n > m {
+				n = m
+			}
+			vsz := a.Size() / a.Len()
+			if m := (w.maxMessageBytes - w.sz) / vsz; n > m {
+				n = m
+			}
+			if n < 0 {
+				n = 0
+			}
+			sz := n * vsz
+
+			if n == 0 {
+				if w.sz > 0 {
+					// Send the pending data and continue with a new frame.
+					w.Flush()
+					if w.err != nil {
+						break
+					}
+					p = w.getUnsignedPointsFrame()
+					frame = p.UnsignedPoints
+					w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+					continue
+				}
+
+				// A single value exceeds maxMessageBytes, so it is sent
+				// in a message of its own.
+				n, sz = 1, vsz
+			}
+
+			w.sz += sz
+			frame.Timestamps = append(frame.Timestamps, a.Timestamps[i:i+n]...)
+			frame.Values = append(frame.Values, a.Values[i:i+n]...)
+			i += n
 		}
 	}
 
@@ -315,7 +384,7 @@ func (w *ResponseWriter) streamUnsignedArrayPoints(cur cursors.UnsignedArrayCurs
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz >= w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -353,7 +422,7 @@ func (w *ResponseWriter) streamStringArraySeries(cur cursors.StringArrayCursor)
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz > w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -367,7 +436,7 @@ func (w *ResponseWriter) streamStringArrayPoints(cur cursors.StringArrayCursor)
 	w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 
 	var seriesValueCount = 0
-	for {
+	for w.err == nil {
 		// If the number of values produced by cur > 1000,
 		// cur.Next() will produce batches of values that are of
 		// length ≤ 1000.
@@ -382,34 +451,59 @@ func (w *ResponseWriter) streamStringArrayPoints(cur cursors.StringArrayCursor)
 		}
 
 		seriesValueCount += a.Len()
-		// As specified in the struct definition, w.sz is an estimated
-		// size (in bytes) of the buffered data. It is therefore a
-		// deliberate choice to accumulate using the array Size, which is
-		// cheap to calculate. Calling frame.Size() can be expensive
-		// when using varint encoding for numbers.
-		w.sz += a.Size()
-
-		frame.Timestamps = append(frame.Timestamps, a.Timestamps...)
-		frame.Values = append(frame.Values, a.Values...)
-
-		// given the expectation of cur.Next, we attempt to limit
-		// the number of values appended to the frame to batchSize (1000)
-		needsFrame := len(frame.Timestamps) >= batchSize
-
-		if w.sz >= writeSize {
-			needsFrame = true
-			w.Flush()
-			if w.err != nil {
-				break
+
+		for i := 0; i < a.Len(); {
+			if len(frame.Timestamps) >= w.maxFramePoints {
+				// new frames are returned with Timestamps and Values preallocated
+				// to a minimum of batchSize length to reduce further allocations.
+				p = w.getStringPointsFrame()
+				frame = p.StringPoints
+				w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 			}
-		}
 
-		if needsFrame {
-			// new frames are returned with Timestamps and Values preallocated
-			// to a minimum of batchSize length to reduce further allocations.
-			p = w.getStringPointsFrame()
-			frame = p.StringPoints
-			w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+			// Determine how many of the remaining values fit in the frame
+			// without exceeding maxMessageBytes. As specified in the struct
+			// definition, w.sz is an estimated size (in bytes) of the buffered
+			// data. It is therefore a deliberate choice to estimate using the
+			// array Size, which is cheap to calculate. Calling frame.Size()
+			// can be expensive when using varint encoding for numbers.
+			n := a.Len() - i
+			if m := w.maxFramePoints - len(frame.Timestamps); n > m {
+				n = m
+			}
+			// String values vary in size, so they are measured individually.
+			var sz int
+			for j := i; j < i+n; j++ {
+				vsz := 8 + len(a.Values[j])
+				if w.sz+sz+vsz > w.maxMessageBytes {
+					n = j - i
+					break
+				}
+				sz += vsz
+			}
+
+			if n == 0 {
+				if w.sz > 0 {
+					// Send the pending data and continue with a new frame.
+					w.Flush()
+					if w.err != nil {
+						break
+					}
+					p = w.getStringPointsFrame()
+					frame = p.StringPoints
+					w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+					continue
+				}
+
+				// A single value exceeds maxMessageBytes, so it is sent
+				// in a message of its own.
+				n, sz = 1, 8+len(a.Values[i])
+			}
+
+			w.sz += sz
+			frame.Timestamps = append(frame.Timestamps, a.Timestamps[i:i+n]...)
+			frame.Values = append(frame.Values, a.Values[i:i+n]...)
+			i += n
 		}
 	}
 
@@ -418,7 +512,7 @@ func (w *ResponseWriter) streamStringArrayPoints(cur cursors.StringArrayCursor)
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz >= w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -456,7 +550,7 @@ func (w *ResponseWriter) streamBooleanArraySeries(cur cursors.BooleanArrayCursor
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz > w.maxMessageBytes {
 		w.Flush()
 	}
 }
@@ -470,7 +564,7 @@ func (w *ResponseWriter) streamBooleanArrayPoints(cur cursors.BooleanArrayCursor
 	w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
 
 	var seriesValueCount = 0
-	for {
+	for w.err == nil {
 		// If the number of values produced by cur > 1000,
 		// cur.Next() will produce batches of values that are of
 		// length ≤ 1000.
@@ -485,34 +579,57 @@ func (w *ResponseWriter) streamBooleanArrayPoints(cur cursors.BooleanArrayCursor
 		}
 
 		seriesValueCount += a.Len()
-		// As specified in the struct definition, w.sz is an estimated
-		// size (in bytes) of the buffered data. It is therefore a
-		// deliberate choice to accumulate using the array Size, which is
-		// cheap to calculate. Calling frame.Size() can be expensive
-		// when using varint encoding for numbers.
-		w.sz += a.Size()
-
-		frame.Timestamps = append(frame.Timestamps, a.Timestamps...)
-		frame.Values = append(frame.Values, a.Values...)
-
-		// given the expectation of cur.Next, we attempt to limit
-		// the number of values appended to the frame to batchSize (1000)
-		needsFrame := len(frame.Timestamps) >= batchSize
-
-		if w.sz >= writeSize {
-			needsFrame = true
-			w.Flush()
-			if w.err != nil {
-				break
+
+		for i := 0; i < a.Len(); {
+			if len(frame.Timestamps) >= w.maxFramePoints {
+				// new frames are returned with Timestamps and Values preallocated
+				// to a minimum of batchSize length to reduce further allocations.
+				p = w.getBooleanPointsFrame()
+				frame = p.BooleanPoints
+				w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+			}
+
+			// Determine how many of the remaining values fit in the frame
+			// without exceeding maxMessageBytes. As specified in the struct
+			// definition, w.sz is an estimated size (in bytes) of the buffered
+			// data. It is therefore a deliberate choice to estimate using the
+			// array Size, which is cheap to calculate. Calling frame.Size()
+			// can be expensive when using varint encoding for numbers.
+			n := a.Len() - i
+			if m := w.maxFramePoints - len(frame.Timestamps); n > m {
+				n = m
+			}
+			vsz := a.Size() / a.Len()
+			if m := (w.maxMessageBytes - w.sz) / vsz; n > m {
+				n = m
+			}
+			if n < 0 {
+				n = 0
+			}
+			sz := n * vsz
+
+			if n == 0 {
+				if w.sz > 0 {
+					// Send the pending data and continue with a new frame.
+					w.Flush()
+					if w.err != nil {
+						break
+					}
+					p = w.getBooleanPointsFrame()
+					frame = p.BooleanPoints
+					w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+					continue
+				}
+
+				// A single value exceeds maxMessageBytes, so it is sent
+				// in a message of its own.
+				n, sz = 1, vsz
 			}
-		}
 
-		if needsFrame {
-			// new frames are returned with Timestamps and Values preallocated
-			// to a minimum of batchSize length to reduce further allocations.
-			p = w.getBooleanPointsFrame()
-			frame = p.BooleanPoints
-			w.res.Frames = append(w.res.Frames, datatypes.ReadResponse_Frame{Data: p})
+			w.sz += sz
+			frame.Timestamps = append(frame.Timestamps, a.Timestamps[i:i+n]...)
+			frame.Values = append(frame.Values, a.Values[i:i+n]...)
+			i += n
 		}
 	}
 
@@ -521,7 +638,7 @@ func (w *ResponseWriter) streamBooleanArrayPoints(cur cursors.BooleanArrayCursor
 		w.sz -= w.sf.Size()
 		w.putSeriesFrame(w.res.Frames[ss].Data.(*datatypes.ReadResponse_Frame_Series))
 		w.res.Frames = w.res.Frames[:ss]
-	} else if w.sz > writeSize {
+	} else if w.sz >= w.maxMessageBytes {
 		w.Flush()
 	}
 }
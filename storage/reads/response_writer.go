@@ -23,6 +23,35 @@ const (
 	writeSize  = 64 << 10 // 64k
 )
 
+// ResponseWriterOption sets an optional parameter on a ResponseWriter.
+type ResponseWriterOption func(*ResponseWriter)
+
+// WithMaxFramePoints limits the number of points sent in a single points frame.
+// The default is 1000. Values less than 1 are ignored. If more than one limit
+// is given, the smallest wins, which allows a server to apply a limit requested
+// by a client without permitting the client to raise the server's own limit.
+func WithMaxFramePoints(n int) ResponseWriterOption {
+	return func(w *ResponseWriter) {
+		if n > 0 && (!w.maxFramePointsSet || n < w.maxFramePoints) {
+			w.maxFramePoints, w.maxFramePointsSet = n, true
+		}
+	}
+}
+
+// WithMaxMessageBytes limits the approximate size in bytes of each
+// ReadResponse sent to the stream. Points frames, including string frames, are
+// split so that a message does not exceed the limit, unless a single value is
+// larger than the limit, in which case the value is sent in a message of its
+// own. The default is 64KB. Values less than 1 are ignored and, as with
+// WithMaxFramePoints, the smallest of several limits wins.
+func WithMaxMessageBytes(n int) ResponseWriterOption {
+	return func(w *ResponseWriter) {
+		if n > 0 && (!w.maxMessageBytesSet || n < w.maxMessageBytes) {
+			w.maxMessageBytes, w.maxMessageBytesSet = n, true
+		}
+	}
+}
+
 type ResponseWriter struct {
 	stream ResponseStream
 	res    *datatypes.ReadResponse
@@ -32,9 +61,14 @@ type ResponseWriter struct {
 	sf *datatypes.ReadResponse_SeriesFrame
 	ss int // pointer to current series frame; used to skip writing if no points
 	// sz is an estimated size in bytes for pending writes to flush periodically
-	// when the size exceeds writeSize.
+	// when the size exceeds maxMessageBytes.
 	sz int
 
+	maxFramePoints     int
+	maxFramePointsSet  bool
+	maxMessageBytes    int
+	maxMessageBytesSet bool
+
 	vc int // total value count
 
 	buffer struct {
@@ -50,12 +84,19 @@ type ResponseWriter struct {
 	hints datatypes.HintFlags
 }
 
-func NewResponseWriter(stream ResponseStream, hints datatypes.HintFlags) *ResponseWriter {
+func NewResponseWriter(stream ResponseStream, hints datatypes.HintFlags, opts ...ResponseWriterOption) *ResponseWriter {
 	rw := &ResponseWriter{stream: stream,
 		res: &datatypes.ReadResponse{
 			Frames: make([]datatypes.ReadResponse_Frame, 0, frameCount),
 		},
 		hints: hints,
+
+		maxFramePoints:  batchSize,
+		maxMessageBytes: writeSize,
+	}
+
+	for _, o := range opts {
+		o(rw)
 	}
 
 	return rw
@@ -83,7 +124,8 @@ func (w *ResponseWriter) WriteResultSet(rs ResultSet) error {
 	stats := rs.Stats()
 	w.stream.SetTrailer(metadata.Pairs(
 		"scanned-bytes", fmt.Sprint(stats.ScannedBytes),
-		"scanned-values", fmt.Sprint(stats.ScannedValues)))
+		"scanned-values", fmt.Sprint(stats.ScannedValues),
+		"duplicate-values", fmt.Sprint(stats.DuplicateValues)))
 
 	return nil
 }
@@ -114,7 +156,8 @@ func (w *ResponseWriter) WriteGroupResultSet(rs GroupResultSet) error {
 
 	w.stream.SetTrailer(metadata.Pairs(
 		"scanned-bytes", fmt.Sprint(stats.ScannedBytes),
-		"scanned-values", fmt.Sprint(stats.ScannedValues)))
+		"scanned-values", fmt.Sprint(stats.ScannedValues),
+		"duplicate-values", fmt.Sprint(stats.DuplicateValues)))
 
 	return nil
 }
@@ -532,3 +532,89 @@ func TestResponseWriter_WriteGroupResultSet(t *testing.T) {
 		})
 	})
 }
+
+func TestResponseWriter_WriteResultSet_FrameLimits(t *testing.T) {
+	writeResultSet := func(t *testing.T, gens []gen.SeriesGenerator, opts ...reads.ResponseWriterOption) (sendSummary, []int, int) {
+		t.Helper()
+
+		var (
+			ss        sendSummary
+			maxPoints int
+			sizes     []int
+		)
+		send := ss.makeSendFunc()
+
+		stream := mock.NewResponseStream()
+		stream.SendFunc = func(r *datatypes.ReadResponse) error {
+			sizes = append(sizes, r.Size())
+			for i := range r.Frames {
+				switch p := r.Frames[i].Data.(type) {
+				case *datatypes.ReadResponse_Frame_FloatPoints:
+					if n := len(p.FloatPoints.Values); n > maxPoints {
+						maxPoints = n
+					}
+				case *datatypes.ReadResponse_Frame_StringPoints:
+					if n := len(p.StringPoints.Values); n > maxPoints {
+						maxPoints = n
+					}
+				}
+			}
+			return send(r)
+		}
+
+		w := reads.NewResponseWriter(stream, 0, opts...)
+		cur := newSeriesGeneratorSeriesCursor(gen.NewMergedSeriesGenerator(gens))
+		rs := reads.NewFilteredResultSet(context.Background(), &datatypes.ReadFilterRequest{}, cur)
+		if err := w.WriteResultSet(rs); err != nil {
+			t.Fatalf("unexpected err: %v", err)
+		}
+		w.Flush()
+
+		return ss, sizes, maxPoints
+	}
+
+	t.Run("max frame points", func(t *testing.T) {
+		ss, _, maxPoints := writeResultSet(t,
+			[]gen.SeriesGenerator{makeTypedSeries("m0", "t", "f0", 3.3, 2500, 1)},
+			reads.WithMaxFramePoints(500),
+			reads.WithMaxFramePoints(2000), // larger limits do not raise the smallest
+		)
+		assert.Equal(t, ss, sendSummary{seriesCount: 1, floatCount: 2500})
+		if maxPoints != 500 {
+			t.Errorf("got max %d points per frame, expected 500", maxPoints)
+		}
+	})
+
+	t.Run("max message bytes", func(t *testing.T) {
+		const limit = 4 << 10
+		ss, sizes, _ := writeResultSet(t,
+			[]gen.SeriesGenerator{
+				makeTypedSeries("m0", "t", "f0", 3.3, 2000, 1),
+				makeTypedSeries("m0", "t", "s0", strings.Repeat("aaa", 100), 1000, 1),
+			},
+			reads.WithMaxMessageBytes(limit),
+		)
+		assert.Equal(t, ss, sendSummary{seriesCount: 2, floatCount: 2000, stringCount: 1000})
+		for i, sz := range sizes {
+			// The estimate ignores protobuf framing overhead for each value.
+			if sz > limit+limit/4 {
+				t.Errorf("message %d: got size %d, expected at most ~%d", i, sz, limit)
+			}
+		}
+	})
+
+	t.Run("oversize string values", func(t *testing.T) {
+		const limit = 1 << 10
+		ss, sizes, maxPoints := writeResultSet(t,
+			[]gen.SeriesGenerator{makeTypedSeries("m0", "t", "s0", strings.Repeat("a", 2*limit), 10, 1)},
+			reads.WithMaxMessageBytes(limit),
+		)
+		assert.Equal(t, ss, sendSummary{seriesCount: 1, stringCount: 10})
+		if maxPoints != 1 {
+			t.Errorf("got max %d points per frame, expected 1", maxPoints)
+		}
+		if got, exp := len(sizes), 10; got < exp {
+			t.Errorf("got %d messages, expected at least %d", got, exp)
+		}
+	})
+}
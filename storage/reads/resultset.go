@@ -23,14 +23,19 @@ type resultSet struct {
 }
 
 func NewFilteredResultSet(ctx context.Context, req *datatypes.ReadFilterRequest, cur SeriesCursor) ResultSet {
+	var agg *datatypes.Aggregate
+	if req.Aggregate != nil && req.Aggregate.Type != datatypes.AggregateTypeNone {
+		agg = req.Aggregate
+	}
 	return &resultSet{
 		ctx: ctx,
-		cur: cur,
+		agg: agg,
+		cur: newExcludeTagsSeriesCursor(cur, excludeTagKeys(req.ExcludeTagKeys, nil)),
 		mb:  newMultiShardArrayCursors(ctx, req.Range.Start, req.Range.End, true, math.MaxInt64),
 	}
 }
 
-func (r *resultSet) Err() error { return nil }
+func (r *resultSet) Err() error { return r.cur.Err() }
 
 // Close closes the result set. Close is idempotent.
 func (r *resultSet) Close() {
@@ -0,0 +1,134 @@
+package reads_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+func TestNewFilteredResultSet_ExcludeTagKeys(t *testing.T) {
+	cur := &sliceSeriesCursor{
+		rows: newSeriesRows(
+			"cpu,host=a,region=west",
+			"cpu,host=b,region=east",
+		)}
+
+	rs := reads.NewFilteredResultSet(context.Background(), &datatypes.ReadFilterRequest{
+		ExcludeTagKeys: []string{"host"},
+	}, cur)
+
+	sb := new(strings.Builder)
+	ResultSetToString(sb, rs, SkipNilCursor())
+
+	exp := `series: _m=cpu,region=west
+series: _m=cpu,region=east
+`
+	if got := sb.String(); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected value; -got/+exp\n%s", cmp.Diff(strings.Split(got, "\n"), strings.Split(exp, "\n")))
+	}
+
+	// The tags used to read the series are unchanged.
+	if got := cur.rows[0].SeriesTags.GetString("host"); got != "a" {
+		t.Errorf("unexpected series tag host=%q", got)
+	}
+}
+
+func TestNewFilteredResultSet_Aggregate(t *testing.T) {
+	tests := []struct {
+		agg datatypes.Aggregate_AggregateType
+		exp string
+	}{
+		{
+			agg: datatypes.AggregateTypeCount,
+			exp: `series: _m=cpu,host=a
+  cursor:Integer
+                     1 |                    5
+`,
+		},
+		{
+			agg: datatypes.AggregateTypeSum,
+			exp: `series: _m=cpu,host=a
+  cursor:Integer
+                     1 |                   14
+`,
+		},
+		{
+			// The first of the smallest values is selected.
+			agg: datatypes.AggregateTypeMin,
+			exp: `series: _m=cpu,host=a
+  cursor:Integer
+                     2 |                    1
+`,
+		},
+		{
+			agg: datatypes.AggregateTypeMax,
+			exp: `series: _m=cpu,host=a
+  cursor:Integer
+                     5 |                    5
+`,
+		},
+		{
+			agg: datatypes.AggregateTypeMean,
+			exp: `series: _m=cpu,host=a
+  cursor:Float
+                     1 |               2.80
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.agg.String(), func(t *testing.T) {
+			rows := newSeriesRows("cpu,host=a")
+			rows[0].Query = tsdb.CursorIterators{&integerSliceCursorIterator{
+				arrays: []*cursors.IntegerArray{
+					{Timestamps: []int64{1, 2, 3}, Values: []int64{3, 1, 4}},
+					{Timestamps: []int64{4, 5}, Values: []int64{1, 5}},
+				},
+			}}
+
+			rs := reads.NewFilteredResultSet(context.Background(), &datatypes.ReadFilterRequest{
+				Aggregate: &datatypes.Aggregate{Type: tt.agg},
+			}, &sliceSeriesCursor{rows: rows})
+
+			sb := new(strings.Builder)
+			ResultSetToString(sb, rs)
+			if got := sb.String(); !cmp.Equal(got, tt.exp) {
+				t.Errorf("unexpected value; -got/+exp\n%s", cmp.Diff(strings.Split(got, "\n"), strings.Split(tt.exp, "\n")))
+			}
+		})
+	}
+}
+
+// integerSliceCursorIterator returns a cursor reading arrays.
+type integerSliceCursorIterator struct {
+	arrays []*cursors.IntegerArray
+}
+
+func (ci *integerSliceCursorIterator) Next(ctx context.Context, r *cursors.CursorRequest) (cursors.Cursor, error) {
+	return &integerSliceCursor{arrays: ci.arrays}, nil
+}
+
+func (ci *integerSliceCursorIterator) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+type integerSliceCursor struct {
+	arrays []*cursors.IntegerArray
+}
+
+func (c *integerSliceCursor) Next() *cursors.IntegerArray {
+	if len(c.arrays) == 0 {
+		return &cursors.IntegerArray{}
+	}
+	a := c.arrays[0]
+	c.arrays = c.arrays[1:]
+	return a
+}
+
+func (c *integerSliceCursor) Close()                     {}
+func (c *integerSliceCursor) Err() error                 { return nil }
+func (c *integerSliceCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
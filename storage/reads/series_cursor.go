@@ -1,6 +1,7 @@
 package reads
 
 import (
+	"bytes"
 	"context"
 
 	"github.com/influxdata/influxdb/models"
@@ -49,3 +50,64 @@ func (c *limitSeriesCursor) Next() *SeriesRow {
 	c.c++
 	return c.SeriesCursor.Next()
 }
+
+// excludeTagsSeriesCursor removes tag keys from the Tags of the rows of a
+// SeriesCursor. The SeriesTags used to read the series are left unchanged.
+type excludeTagsSeriesCursor struct {
+	SeriesCursor
+	keys [][]byte
+	row  SeriesRow
+	tags models.Tags
+}
+
+// newExcludeTagsSeriesCursor returns cur if there are no keys to remove.
+func newExcludeTagsSeriesCursor(cur SeriesCursor, keys [][]byte) SeriesCursor {
+	if len(keys) == 0 {
+		return cur
+	}
+	return &excludeTagsSeriesCursor{SeriesCursor: cur, keys: keys}
+}
+
+func (c *excludeTagsSeriesCursor) Next() *SeriesRow {
+	row := c.SeriesCursor.Next()
+	if row == nil {
+		return nil
+	}
+
+	c.row = *row
+	c.tags = c.tags[:0]
+	for _, t := range row.Tags {
+		if !c.excluded(t.Key) {
+			c.tags = append(c.tags, t)
+		}
+	}
+	c.row.Tags = c.tags
+	return &c.row
+}
+
+func (c *excludeTagsSeriesCursor) excluded(key []byte) bool {
+	for _, k := range c.keys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeTagKeys returns the keys of exclude that are not in keep.
+func excludeTagKeys(exclude, keep []string) [][]byte {
+	var keys [][]byte
+	for _, k := range exclude {
+		found := false
+		for _, kk := range keep {
+			if k == kk {
+				found = true
+				break
+			}
+		}
+		if !found {
+			keys = append(keys, []byte(k))
+		}
+	}
+	return keys
+}
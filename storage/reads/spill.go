@@ -0,0 +1,420 @@
+package reads
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Default spill configuration values.
+const (
+	DefaultSpillMemoryBytes  = 256 << 20 // 256MB
+	DefaultSpillMaxTempBytes = 1 << 30   // 1GB
+)
+
+// ErrSpillLimitExceeded is returned when a query needs more temporary disk
+// space than it is allowed.
+var ErrSpillLimitExceeded = errors.New("query exceeded its temporary disk space limit")
+
+// SpillConfig configures how series read by a grouped query are spilled to
+// disk when they are sorted.
+type SpillConfig struct {
+	// The directory temporary files are written to. An empty directory uses
+	// the default directory for temporary files.
+	Dir string
+
+	// The estimated size in bytes of the series a query sorts in memory
+	// before spilling them to disk. A value of 0 disables spilling.
+	MemoryBytes int64
+
+	// The maximum number of bytes of temporary files of each query. A value
+	// of 0 does not limit them.
+	MaxTempBytes int64
+}
+
+// Enabled returns true if series are spilled to disk.
+func (c SpillConfig) Enabled() bool { return c.MemoryBytes > 0 }
+
+// spillMetrics are shared by all queries that spill to disk.
+var spillMetrics = newSpillMetrics()
+
+type spillMetricsT struct {
+	Runs          prometheus.Counter // Number of sorted runs written.
+	Bytes         prometheus.Counter // Number of bytes written.
+	TempBytes     prometheus.Gauge   // Number of bytes of temporary files.
+	LimitExceeded prometheus.Counter // Number of queries that exceeded their limit.
+}
+
+func newSpillMetrics() *spillMetricsT {
+	const (
+		namespace = "storage"
+		subsystem = "reads_spill"
+	)
+	return &spillMetricsT{
+		Runs: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "runs_total",
+			Help:      "Number of sorted runs of series spilled to disk by queries.",
+		}),
+		Bytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "bytes_total",
+			Help:      "Number of bytes of series spilled to disk by queries.",
+		}),
+		TempBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "temp_bytes",
+			Help:      "Number of bytes of temporary files held by running queries.",
+		}),
+		LimitExceeded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "limit_exceeded_total",
+			Help:      "Number of queries that exceeded their temporary disk space limit.",
+		}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the reads package.
+func PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		spillMetrics.Runs,
+		spillMetrics.Bytes,
+		spillMetrics.TempBytes,
+		spillMetrics.LimitExceeded,
+	}
+}
+
+// seriesRowSorter sorts series rows by their sort key, spilling sorted runs
+// of rows to disk once their estimated size exceeds the memory limit. Rows
+// with equal sort keys keep the order they were added in.
+type seriesRowSorter struct {
+	cfg SpillConfig
+
+	rows []*SeriesRow
+	size int64
+
+	// The cursor iterators and value conditions are not written to disk.
+	// Rows read from one cursor share their iterators, and the conditions
+	// are written as expressions.
+	query cursors.CursorIterators
+
+	runs      []*spillRun
+	tempBytes int64
+}
+
+func newSeriesRowSorter(cfg SpillConfig) *seriesRowSorter {
+	return &seriesRowSorter{cfg: cfg}
+}
+
+// add adds a row to the sorter. It returns true if the rows added so far
+// were spilled to disk, so that buffers they refer to can be reused.
+func (s *seriesRowSorter) add(row *SeriesRow) (bool, error) {
+	s.rows = append(s.rows, row)
+	s.size += seriesRowSize(row)
+	if s.size < s.cfg.MemoryBytes {
+		return false, nil
+	}
+	return true, s.spill()
+}
+
+// spill writes the rows held in memory to disk as a sorted run.
+func (s *seriesRowSorter) spill() error {
+	if len(s.rows) == 0 {
+		return nil
+	}
+	sortSeriesRows(s.rows)
+
+	f, err := ioutil.TempFile(s.cfg.Dir, "influxd-spill-")
+	if err != nil {
+		return err
+	}
+	run := &spillRun{f: f}
+	s.runs = append(s.runs, run)
+
+	s.query = s.rows[0].Query
+	w := bufio.NewWriter(f)
+	var buf []byte
+	for _, row := range s.rows {
+		buf = appendSeriesRow(buf[:0], row)
+		if s.cfg.MaxTempBytes > 0 && s.tempBytes+int64(len(buf)) > s.cfg.MaxTempBytes {
+			spillMetrics.LimitExceeded.Inc()
+			return ErrSpillLimitExceeded
+		}
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+		s.tempBytes += int64(len(buf))
+		run.size += int64(len(buf))
+		spillMetrics.TempBytes.Add(float64(len(buf)))
+		spillMetrics.Bytes.Add(float64(len(buf)))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	spillMetrics.Runs.Inc()
+
+	s.rows, s.size = nil, 0
+	return nil
+}
+
+// finish returns the sorted rows if they were all held in memory, or a merger
+// reading them from disk otherwise.
+func (s *seriesRowSorter) finish() ([]*SeriesRow, *spillMerger, error) {
+	if len(s.runs) == 0 {
+		sortSeriesRows(s.rows)
+		return s.rows, nil, nil
+	}
+
+	if err := s.spill(); err != nil {
+		return nil, nil, err
+	}
+
+	m := &spillMerger{runs: s.runs, query: s.query}
+	s.runs = nil
+	for i, run := range m.runs {
+		if _, err := run.f.Seek(0, io.SeekStart); err != nil {
+			m.close()
+			return nil, nil, err
+		}
+		run.r = bufio.NewReader(run.f)
+		run.i = i
+		if err := m.advance(run); err != nil {
+			m.close()
+			return nil, nil, err
+		}
+	}
+	heap.Init(m)
+	return nil, m, nil
+}
+
+// close removes the runs spilled to disk.
+func (s *seriesRowSorter) close() {
+	for _, run := range s.runs {
+		run.close()
+	}
+	s.runs = nil
+}
+
+// sortSeriesRows sorts rows by their sort key, keeping the order of rows with
+// equal keys.
+func sortSeriesRows(rows []*SeriesRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return bytes.Compare(rows[i].SortKey, rows[j].SortKey) == -1
+	})
+}
+
+// seriesRowSize returns the estimated size in bytes of a row in memory.
+func seriesRowSize(row *SeriesRow) int64 {
+	const (
+		rowOverhead = 200 // row, slice and string headers
+		tagOverhead = 48  // tag and slice headers
+	)
+	n := rowOverhead + len(row.SortKey) + len(row.Name) + len(row.Field)
+	for _, tags := range []models.Tags{row.SeriesTags, row.Tags} {
+		for _, t := range tags {
+			n += tagOverhead + len(t.Key) + len(t.Value)
+		}
+	}
+	return int64(n)
+}
+
+// spillRun is a sorted run of rows spilled to disk.
+type spillRun struct {
+	f    *os.File
+	r    *bufio.Reader
+	size int64
+
+	i   int        // position of the run, used to order equal rows
+	row *SeriesRow // next row of the run
+}
+
+func (r *spillRun) close() {
+	r.f.Close()
+	os.Remove(r.f.Name())
+	spillMetrics.TempBytes.Sub(float64(r.size))
+}
+
+// spillMerger merges the sorted runs spilled by a seriesRowSorter.
+type spillMerger struct {
+	runs  []*spillRun // heap of the runs with rows left
+	done  []*spillRun // runs with no rows left
+	query cursors.CursorIterators
+}
+
+func (m *spillMerger) Len() int { return len(m.runs) }
+func (m *spillMerger) Less(i, j int) bool {
+	if cmp := bytes.Compare(m.runs[i].row.SortKey, m.runs[j].row.SortKey); cmp != 0 {
+		return cmp == -1
+	}
+	return m.runs[i].i < m.runs[j].i
+}
+func (m *spillMerger) Swap(i, j int)      { m.runs[i], m.runs[j] = m.runs[j], m.runs[i] }
+func (m *spillMerger) Push(x interface{}) { m.runs = append(m.runs, x.(*spillRun)) }
+func (m *spillMerger) Pop() interface{} {
+	run := m.runs[len(m.runs)-1]
+	m.runs = m.runs[:len(m.runs)-1]
+	return run
+}
+
+// advance reads the next row of a run.
+func (m *spillMerger) advance(run *spillRun) error {
+	row, err := readSeriesRow(run.r)
+	if err == io.EOF {
+		run.row = nil
+		return nil
+	} else if err != nil {
+		return err
+	}
+	row.Query = m.query
+	run.row = row
+	return nil
+}
+
+// peek returns the next row without removing it, or nil if all rows have
+// been read.
+func (m *spillMerger) peek() *SeriesRow {
+	if len(m.runs) == 0 {
+		return nil
+	}
+	return m.runs[0].row
+}
+
+// next removes and returns the next row, or nil if all rows have been read.
+func (m *spillMerger) next() (*SeriesRow, error) {
+	row := m.peek()
+	if row == nil {
+		return nil, nil
+	}
+	if err := m.advance(m.runs[0]); err != nil {
+		return nil, err
+	}
+	if m.runs[0].row == nil {
+		m.done = append(m.done, heap.Remove(m, 0).(*spillRun))
+	} else {
+		heap.Fix(m, 0)
+	}
+	return row, nil
+}
+
+// close removes the runs.
+func (m *spillMerger) close() {
+	for _, run := range append(m.runs, m.done...) {
+		run.close()
+	}
+	m.runs, m.done = nil, nil
+}
+
+// appendSeriesRow appends the encoding of a row, without its cursor
+// iterators, to dst.
+func appendSeriesRow(dst []byte, row *SeriesRow) []byte {
+	dst = appendSpillBytes(dst, row.SortKey)
+	dst = appendSpillBytes(dst, row.Name)
+	dst = appendSpillTags(dst, row.SeriesTags)
+	dst = appendSpillTags(dst, row.Tags)
+	dst = appendSpillBytes(dst, []byte(row.Field))
+	var cond string
+	if row.ValueCond != nil {
+		cond = row.ValueCond.String()
+	}
+	return appendSpillBytes(dst, []byte(cond))
+}
+
+func appendSpillBytes(dst, b []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(b)))
+	return append(append(dst, buf[:n]...), b...)
+}
+
+func appendSpillTags(dst []byte, tags models.Tags) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(tags)))
+	dst = append(dst, buf[:n]...)
+	for _, t := range tags {
+		dst = appendSpillBytes(dst, t.Key)
+		dst = appendSpillBytes(dst, t.Value)
+	}
+	return dst
+}
+
+// readSeriesRow reads a row encoded by appendSeriesRow. It returns io.EOF
+// if r holds no more rows.
+func readSeriesRow(r *bufio.Reader) (*SeriesRow, error) {
+	var row SeriesRow
+	var err error
+	if row.SortKey, err = readSpillBytes(r); err != nil {
+		return nil, err
+	} else if row.Name, err = readSpillBytes(r); err != nil {
+		return nil, unexpectedEOF(err)
+	} else if row.SeriesTags, err = readSpillTags(r); err != nil {
+		return nil, unexpectedEOF(err)
+	} else if row.Tags, err = readSpillTags(r); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	field, err := readSpillBytes(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	row.Field = string(field)
+
+	cond, err := readSpillBytes(r)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	} else if len(cond) > 0 {
+		if row.ValueCond, err = influxql.ParseExpr(string(cond)); err != nil {
+			return nil, fmt.Errorf("invalid spilled value condition: %v", err)
+		}
+	}
+	return &row, nil
+}
+
+func readSpillBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return b, nil
+}
+
+func readSpillTags(r *bufio.Reader) (models.Tags, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	tags := make(models.Tags, n)
+	for i := range tags {
+		if tags[i].Key, err = readSpillBytes(r); err != nil {
+			return nil, unexpectedEOF(err)
+		} else if tags[i].Value, err = readSpillBytes(r); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+	return tags, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
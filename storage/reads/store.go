@@ -82,3 +82,12 @@ type Store interface {
 
 	GetSource(orgID, bucketID uint64) proto.Message
 }
+
+// AggregateCapability is implemented by stores that aggregate the points of
+// each series read by ReadFilter and ReadGroup, as requested by the Aggregate
+// of the request. The points read from other stores are aggregated by the
+// reader.
+type AggregateCapability interface {
+	// HasAggregate reports whether the store computes the aggregate typ.
+	HasAggregate(typ datatypes.Aggregate_AggregateType) bool
+}
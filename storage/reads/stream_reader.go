@@ -86,6 +86,13 @@ func (rc *StorageReadClient) Stats() (stats cursors.CursorStats) {
 		}
 		stats.ScannedValues += v
 	}
+	for _, s := range rc.trailer.Get("duplicate-values") {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			continue
+		}
+		stats.DuplicateValues += v
+	}
 	return stats
 }
 
@@ -103,6 +103,9 @@ type floatGroupTable struct {
 	mu  sync.Mutex
 	gc  GroupCursor
 	cur cursors.FloatArrayCursor
+
+	// stats are the statistics of the cursors of the table already read.
+	stats cursors.CursorStats
 }
 
 func newFloatGroupTable(
@@ -170,6 +173,7 @@ RETRY:
 }
 
 func (t *floatGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -193,10 +197,10 @@ func (t *floatGroupTable) advanceCursor() bool {
 }
 
 func (t *floatGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
+	cs := t.stats
+	if t.cur != nil {
+		cs.Add(t.cur.Stats())
 	}
-	cs := t.cur.Stats()
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
@@ -288,6 +292,9 @@ type integerGroupTable struct {
 	mu  sync.Mutex
 	gc  GroupCursor
 	cur cursors.IntegerArrayCursor
+
+	// stats are the statistics of the cursors of the table already read.
+	stats cursors.CursorStats
 }
 
 func newIntegerGroupTable(
@@ -355,6 +362,7 @@ RETRY:
 }
 
 func (t *integerGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -378,10 +386,10 @@ func (t *integerGroupTable) advanceCursor() bool {
 }
 
 func (t *integerGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
+	cs := t.stats
+	if t.cur != nil {
+		cs.Add(t.cur.Stats())
 	}
-	cs := t.cur.Stats()
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
@@ -473,6 +481,9 @@ type unsignedGroupTable struct {
 	mu  sync.Mutex
 	gc  GroupCursor
 	cur cursors.UnsignedArrayCursor
+
+	// stats are the statistics of the cursors of the table already read.
+	stats cursors.CursorStats
 }
 
 func newUnsignedGroupTable(
@@ -540,6 +551,7 @@ RETRY:
 }
 
 func (t *unsignedGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -563,10 +575,10 @@ func (t *unsignedGroupTable) advanceCursor() bool {
 }
 
 func (t *unsignedGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
+	cs := t.stats
+	if t.cur != nil {
+		cs.Add(t.cur.Stats())
 	}
-	cs := t.cur.Stats()
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
@@ -658,6 +670,9 @@ type stringGroupTable struct {
 	mu  sync.Mutex
 	gc  GroupCursor
 	cur cursors.StringArrayCursor
+
+	// stats are the statistics of the cursors of the table already read.
+	stats cursors.CursorStats
 }
 
 func newStringGroupTable(
@@ -725,6 +740,7 @@ RETRY:
 }
 
 func (t *stringGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -748,10 +764,10 @@ func (t *stringGroupTable) advanceCursor() bool {
 }
 
 func (t *stringGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
+	cs := t.stats
+	if t.cur != nil {
+		cs.Add(t.cur.Stats())
 	}
-	cs := t.cur.Stats()
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
@@ -843,6 +859,9 @@ type booleanGroupTable struct {
 	mu  sync.Mutex
 	gc  GroupCursor
 	cur cursors.BooleanArrayCursor
+
+	// stats are the statistics of the cursors of the table already read.
+	stats cursors.CursorStats
 }
 
 func newBooleanGroupTable(
@@ -910,6 +929,7 @@ RETRY:
 }
 
 func (t *booleanGroupTable) advanceCursor() bool {
+	t.stats.Add(t.cur.Stats())
 	t.cur.Close()
 	t.cur = nil
 	for t.gc.Next() {
@@ -933,10 +953,10 @@ func (t *booleanGroupTable) advanceCursor() bool {
 }
 
 func (t *booleanGroupTable) Statistics() cursors.CursorStats {
-	if t.cur == nil {
-		return cursors.CursorStats{}
+	cs := t.stats
+	if t.cur != nil {
+		cs.Add(t.cur.Stats())
 	}
-	cs := t.cur.Stats()
 	return cursors.CursorStats{
 		ScannedValues: cs.ScannedValues,
 		ScannedBytes:  cs.ScannedBytes,
@@ -0,0 +1,86 @@
+package readservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	icontext "github.com/influxdata/influxdb/context"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenScheme is the scheme of the token in the authorization metadata of a
+// request, as in the Authorization header of the HTTP API.
+const tokenScheme = "Token "
+
+// authenticate returns ctx with the authorization of the token in the
+// authorization metadata of the request.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) != 1 || !strings.HasPrefix(values[0], tokenScheme) {
+		return nil, status.Error(codes.Unauthenticated, "token required")
+	}
+
+	auth, err := s.AuthorizationService.FindAuthorizationByToken(ctx, values[0][len(tokenScheme):])
+	if err != nil {
+		s.Logger.Info("Unauthorized", zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "unauthorized access")
+	} else if !auth.IsActive() {
+		return nil, status.Error(codes.Unauthenticated, "authorization is inactive")
+	}
+	return icontext.SetAuthorizer(ctx, auth), nil
+}
+
+// unaryAuth authenticates unary requests before they are handled.
+func (s *Server) unaryAuth(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuth authenticates streaming requests before they are handled.
+func (s *Server) streamAuth(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticatedStream is a stream whose context holds its authorization.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context { return s.ctx }
+
+// authorizeRead returns an error unless the authorization of ctx may read the
+// bucket of source.
+func authorizeRead(ctx context.Context, source *types.Any) error {
+	if source == nil {
+		return status.Error(codes.InvalidArgument, "missing read source")
+	}
+	rs, err := getReadSource(*source)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	p, err := influxdb.NewPermissionAtID(influxdb.ID(rs.BucketID), influxdb.ReadAction, influxdb.BucketsResourceType, influxdb.ID(rs.OrganizationID))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := authorizer.IsAllowed(ctx, *p); err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return nil
+}
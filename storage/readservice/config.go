@@ -0,0 +1,222 @@
+package readservice
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb/toml"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Default gRPC server configuration values.
+//
+// The keepalive defaults differ from those of gRPC, which close connections
+// whose clients, or load balancers in front of them, ping more often than
+// every five minutes. Long-lived read streams are kept alive by pinging, so
+// pings are permitted every ten seconds, even without active streams.
+const (
+	DefaultGRPCMaxRecvMsgSize               = 4 << 20 // 4MB
+	DefaultGRPCMaxSendMsgSize               = math.MaxInt32
+	DefaultGRPCWriteBufferSize              = 32 << 10 // 32KB
+	DefaultGRPCReadBufferSize               = 32 << 10 // 32KB
+	DefaultGRPCMaxHeaderListSize            = 16 << 20 // 16MB
+	DefaultGRPCKeepaliveTime                = time.Minute
+	DefaultGRPCKeepaliveTimeout             = 20 * time.Second
+	DefaultGRPCKeepaliveMinTime             = 10 * time.Second
+	DefaultGRPCKeepalivePermitWithoutStream = true
+)
+
+// minWindowSize is the smallest HTTP/2 flow control window gRPC accepts.
+const minWindowSize = 64 << 10
+
+// GRPCConfig holds the configuration of the gRPC server of the storage read
+// service.
+type GRPCConfig struct {
+	// The address the server listens on. An empty address disables the
+	// server.
+	BindAddress string `toml:"bind-address"`
+
+	// The paths of the PEM-encoded certificate and key the server secures
+	// connections with. They are required to serve.
+	TLSCert string `toml:"tls-cert"`
+	TLSKey  string `toml:"tls-key"`
+
+	// The maximum number of concurrent streams, or read requests, of each
+	// client connection. A value of 0 does not limit them.
+	MaxConcurrentStreams int `toml:"max-concurrent-streams"`
+
+	// The maximum size in bytes of request and response messages.
+	MaxRecvMsgSize int `toml:"max-recv-msg-size"`
+	MaxSendMsgSize int `toml:"max-send-msg-size"`
+
+	// The HTTP/2 flow control windows in bytes of each stream and of each
+	// connection. A value of 0 lets gRPC size the windows dynamically from
+	// the bandwidth-delay product of the connection; otherwise the value must
+	// be at least 64KB.
+	InitialWindowSize     int `toml:"initial-window-size"`
+	InitialConnWindowSize int `toml:"initial-conn-window-size"`
+
+	// The sizes in bytes of the write and read buffers of each connection.
+	// A write buffer of 0 writes every frame to the connection as it is sent.
+	WriteBufferSize int `toml:"write-buffer-size"`
+	ReadBufferSize  int `toml:"read-buffer-size"`
+
+	// The maximum size in bytes of the HTTP/2 header list of a request.
+	MaxHeaderListSize int `toml:"max-header-list-size"`
+
+	// How long a connection is idle before the server pings the client, and
+	// how long it waits for a reply before closing the connection.
+	KeepaliveTime    toml.Duration `toml:"keepalive-time"`
+	KeepaliveTimeout toml.Duration `toml:"keepalive-timeout"`
+
+	// How often clients may ping the server, and whether they may do so
+	// without active streams. Clients that ping more often are disconnected.
+	KeepaliveMinTime             toml.Duration `toml:"keepalive-min-time"`
+	KeepalivePermitWithoutStream bool          `toml:"keepalive-permit-without-stream"`
+
+	// How long a connection may be idle or open before it is closed, and how
+	// long its streams then have to complete. A value of 0 does not limit it.
+	MaxConnectionIdle     toml.Duration `toml:"max-connection-idle"`
+	MaxConnectionAge      toml.Duration `toml:"max-connection-age"`
+	MaxConnectionAgeGrace toml.Duration `toml:"max-connection-age-grace"`
+}
+
+// NewGRPCConfig returns a new GRPCConfig with default values.
+func NewGRPCConfig() GRPCConfig {
+	return GRPCConfig{
+		MaxRecvMsgSize:               DefaultGRPCMaxRecvMsgSize,
+		MaxSendMsgSize:               DefaultGRPCMaxSendMsgSize,
+		WriteBufferSize:              DefaultGRPCWriteBufferSize,
+		ReadBufferSize:               DefaultGRPCReadBufferSize,
+		MaxHeaderListSize:            DefaultGRPCMaxHeaderListSize,
+		KeepaliveTime:                toml.Duration(DefaultGRPCKeepaliveTime),
+		KeepaliveTimeout:             toml.Duration(DefaultGRPCKeepaliveTimeout),
+		KeepaliveMinTime:             toml.Duration(DefaultGRPCKeepaliveMinTime),
+		KeepalivePermitWithoutStream: DefaultGRPCKeepalivePermitWithoutStream,
+	}
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c GRPCConfig) Validate() error {
+	if c.MaxConcurrentStreams < 0 || int64(c.MaxConcurrentStreams) > math.MaxUint32 {
+		return fmt.Errorf("grpc max-concurrent-streams out of range: %d", c.MaxConcurrentStreams)
+	}
+	for _, v := range []struct {
+		name string
+		n    int
+	}{
+		{"max-recv-msg-size", c.MaxRecvMsgSize},
+		{"max-send-msg-size", c.MaxSendMsgSize},
+		{"write-buffer-size", c.WriteBufferSize},
+		{"read-buffer-size", c.ReadBufferSize},
+		{"max-header-list-size", c.MaxHeaderListSize},
+	} {
+		if v.n < 0 || int64(v.n) > math.MaxInt32 {
+			return fmt.Errorf("grpc %s out of range: %d", v.name, v.n)
+		}
+	}
+	for _, v := range []struct {
+		name string
+		n    int
+	}{
+		{"initial-window-size", c.InitialWindowSize},
+		{"initial-conn-window-size", c.InitialConnWindowSize},
+	} {
+		if v.n != 0 && (v.n < minWindowSize || int64(v.n) > math.MaxInt32) {
+			return fmt.Errorf("grpc %s must be 0 or between %d and %d: %d", v.name, minWindowSize, math.MaxInt32, v.n)
+		}
+	}
+	if c.MaxRecvMsgSize == 0 || c.MaxSendMsgSize == 0 {
+		return errors.New("grpc message sizes must be greater than 0")
+	}
+	if c.KeepaliveTime < toml.Duration(time.Second) || c.KeepaliveTimeout <= 0 {
+		return errors.New("grpc keepalive time must be at least 1s and timeout greater than 0")
+	}
+	if c.BindAddress != "" && (c.TLSCert == "" || c.TLSKey == "") {
+		return errors.New("grpc tls-cert and tls-key are required to serve")
+	}
+	if c.KeepaliveMinTime < 0 || c.MaxConnectionIdle < 0 || c.MaxConnectionAge < 0 || c.MaxConnectionAgeGrace < 0 {
+		return errors.New("grpc durations must not be negative")
+	}
+	return nil
+}
+
+// ServerOptions returns the options of a gRPC server with the configuration.
+func (c GRPCConfig) ServerOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(c.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(c.MaxSendMsgSize),
+		grpc.WriteBufferSize(c.WriteBufferSize),
+		grpc.ReadBufferSize(c.ReadBufferSize),
+		grpc.MaxHeaderListSize(uint32(c.MaxHeaderListSize)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  time.Duration(c.KeepaliveTime),
+			Timeout:               time.Duration(c.KeepaliveTimeout),
+			MaxConnectionIdle:     infinite(c.MaxConnectionIdle),
+			MaxConnectionAge:      infinite(c.MaxConnectionAge),
+			MaxConnectionAgeGrace: infinite(c.MaxConnectionAgeGrace),
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             time.Duration(c.KeepaliveMinTime),
+			PermitWithoutStream: c.KeepalivePermitWithoutStream,
+		}),
+	}
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(c.MaxConcurrentStreams)))
+	}
+	if c.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(int32(c.InitialWindowSize)))
+	}
+	if c.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(int32(c.InitialConnWindowSize)))
+	}
+	return opts
+}
+
+// infinite returns d, or the duration gRPC treats as infinite if d is 0.
+func infinite(d toml.Duration) time.Duration {
+	if d == 0 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(d)
+}
+
+// Effective returns the settings of the server as they are applied, keyed by
+// name. Settings left to gRPC are described rather than given as 0.
+func (c GRPCConfig) Effective() map[string]string {
+	size := func(n int, zero string) string {
+		if n == 0 {
+			return zero
+		}
+		return strconv.Itoa(n)
+	}
+	duration := func(d toml.Duration) string {
+		if d == 0 {
+			return "infinity"
+		}
+		return time.Duration(d).String()
+	}
+
+	return map[string]string{
+		"bind-address":                    c.BindAddress,
+		"max-concurrent-streams":          size(c.MaxConcurrentStreams, "unlimited"),
+		"max-recv-msg-size":               strconv.Itoa(c.MaxRecvMsgSize),
+		"max-send-msg-size":               strconv.Itoa(c.MaxSendMsgSize),
+		"initial-window-size":             size(c.InitialWindowSize, "dynamic"),
+		"initial-conn-window-size":        size(c.InitialConnWindowSize, "dynamic"),
+		"write-buffer-size":               strconv.Itoa(c.WriteBufferSize),
+		"read-buffer-size":                strconv.Itoa(c.ReadBufferSize),
+		"max-header-list-size":            strconv.Itoa(c.MaxHeaderListSize),
+		"keepalive-time":                  time.Duration(c.KeepaliveTime).String(),
+		"keepalive-timeout":               time.Duration(c.KeepaliveTimeout).String(),
+		"keepalive-min-time":              time.Duration(c.KeepaliveMinTime).String(),
+		"keepalive-permit-without-stream": strconv.FormatBool(c.KeepalivePermitWithoutStream),
+		"max-connection-idle":             duration(c.MaxConnectionIdle),
+		"max-connection-age":              duration(c.MaxConnectionAge),
+		"max-connection-age-grace":        duration(c.MaxConnectionAgeGrace),
+	}
+}
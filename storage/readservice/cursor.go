@@ -26,6 +26,7 @@ var (
 )
 
 type indexSeriesCursor struct {
+	ctx          context.Context
 	sqry         storage.SeriesCursor
 	err          error
 	cond         influxql.Expr
@@ -53,7 +54,7 @@ func newIndexSeriesCursor(ctx context.Context, src *readSource, predicate *datat
 		Ascending:  true,
 		Ordered:    true,
 	}
-	p := &indexSeriesCursor{row: reads.SeriesRow{Query: tsdb.CursorIterators{queries}}}
+	p := &indexSeriesCursor{ctx: ctx, row: reads.SeriesRow{Query: tsdb.CursorIterators{queries}}}
 
 	if root := predicate.GetRoot(); root != nil {
 		if p.cond, err = reads.NodeToExpr(root, nil); err != nil {
@@ -108,6 +109,14 @@ func (c *indexSeriesCursor) Next() *reads.SeriesRow {
 		return nil
 	}
 
+	// Stop reading series once the read is cancelled, such as when the
+	// query reading them is killed.
+	if err := c.ctx.Err(); err != nil {
+		c.err = err
+		c.Close()
+		return nil
+	}
+
 	// next series key
 	sr, err := c.sqry.Next()
 	if err != nil {
@@ -0,0 +1,113 @@
+package readservice
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+)
+
+// restrictToScope returns pred restricted to the series of the bucket of
+// source the authorizer on ctx may read, if its permissions on the bucket are
+// restricted to some of its series. Reads without an authorizer are made on
+// behalf of the system, and are not restricted.
+func restrictToScope(ctx context.Context, source *readSource, pred *datatypes.Predicate) (*datatypes.Predicate, error) {
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return pred, nil
+	}
+
+	p, err := influxdb.NewPermissionAtID(influxdb.ID(source.BucketID), influxdb.ReadAction, influxdb.BucketsResourceType, influxdb.ID(source.OrganizationID))
+	if err != nil {
+		return nil, err
+	}
+	scope := influxdb.AuthorizerSeriesScope(a, *p)
+	if scope == nil {
+		return pred, nil
+	}
+
+	node := scopeNode(scope)
+	if root := pred.GetRoot(); root != nil {
+		node = logicalNode(datatypes.LogicalAnd, parenNode(root), parenNode(node))
+	}
+	return &datatypes.Predicate{Root: node}, nil
+}
+
+// scopeNode returns the predicate node matching the series of scope.
+func scopeNode(scope influxdb.SeriesScope) *datatypes.Node {
+	resources := make([]*datatypes.Node, 0, len(scope))
+	for _, r := range scope {
+		var rules []*datatypes.Node
+		if len(r.Measurements) > 0 {
+			measurements := make([]*datatypes.Node, 0, len(r.Measurements))
+			for _, m := range r.Measurements {
+				measurements = append(measurements, comparisonNode(datatypes.ComparisonEqual, models.MeasurementTagKey, stringNode(m)))
+			}
+			rules = append(rules, parenNode(logicalNode(datatypes.LogicalOr, measurements...)))
+		}
+		for _, t := range r.Tags {
+			switch t.Operator {
+			case influxdb.Equal:
+				rules = append(rules, comparisonNode(datatypes.ComparisonEqual, t.Key, stringNode(t.Value)))
+			case influxdb.NotEqual:
+				rules = append(rules, comparisonNode(datatypes.ComparisonNotEqual, t.Key, stringNode(t.Value)))
+			case influxdb.RegexEqual:
+				rules = append(rules, comparisonNode(datatypes.ComparisonRegex, t.Key, regexNode(t.Value)))
+			case influxdb.NotRegexEqual:
+				rules = append(rules, comparisonNode(datatypes.ComparisonNotRegex, t.Key, regexNode(t.Value)))
+			}
+		}
+		resources = append(resources, parenNode(logicalNode(datatypes.LogicalAnd, rules...)))
+	}
+	return logicalNode(datatypes.LogicalOr, resources...)
+}
+
+// logicalNode returns the node combining children with op. A single child is
+// returned as is, as logical expressions must have more than one.
+func logicalNode(op datatypes.Node_Logical, children ...*datatypes.Node) *datatypes.Node {
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLogicalExpression,
+		Value:    &datatypes.Node_Logical_{Logical: op},
+		Children: children,
+	}
+}
+
+func parenNode(n *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeParenExpression,
+		Children: []*datatypes.Node{n},
+	}
+}
+
+func comparisonNode(op datatypes.Node_Comparison, key string, value *datatypes.Node) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: op},
+		Children: []*datatypes.Node{
+			{
+				NodeType: datatypes.NodeTypeTagRef,
+				Value:    &datatypes.Node_TagRefValue{TagRefValue: key},
+			},
+			value,
+		},
+	}
+}
+
+func stringNode(s string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_StringValue{StringValue: s},
+	}
+}
+
+func regexNode(s string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLiteral,
+		Value:    &datatypes.Node_RegexValue{RegexValue: s},
+	}
+}
@@ -0,0 +1,79 @@
+package readservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+)
+
+func TestRestrictToScope(t *testing.T) {
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	source := &readSource{OrganizationID: uint64(orgID), BucketID: uint64(bucketID)}
+	pred := &datatypes.Predicate{
+		Root: comparisonNode(datatypes.ComparisonEqual, "host", stringNode("a")),
+	}
+	permission := func(r influxdb.Resource) influxdb.Permission {
+		r.Type = influxdb.BucketsResourceType
+		r.OrgID = &orgID
+		r.ID = &bucketID
+		return influxdb.Permission{Action: influxdb.ReadAction, Resource: r}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		pred        *datatypes.Predicate
+		want        string
+	}{
+		{
+			name:        "unrestricted",
+			permissions: []influxdb.Permission{permission(influxdb.Resource{})},
+			pred:        pred,
+			want:        `'host' = "a"`,
+		},
+		{
+			name: "measurements",
+			permissions: []influxdb.Permission{
+				permission(influxdb.Resource{Measurements: []string{"cpu", "mem"}}),
+			},
+			pred: pred,
+			want: "( 'host' = \"a\" ) AND ( ( ( '\x00' = \"cpu\" OR '\x00' = \"mem\" ) ) )",
+		},
+		{
+			name: "measurements and tags of several permissions",
+			permissions: []influxdb.Permission{
+				permission(influxdb.Resource{
+					Measurements: []string{"cpu"},
+					Tags: []influxdb.TagRule{
+						{Tag: influxdb.Tag{Key: "team", Value: "a"}, Operator: influxdb.NotEqual},
+					},
+				}),
+				permission(influxdb.Resource{
+					Tags: []influxdb.TagRule{
+						{Tag: influxdb.Tag{Key: "team", Value: "b"}, Operator: influxdb.Equal},
+					},
+				}),
+			},
+			want: "( ( '\x00' = \"cpu\" ) AND 'team' != \"a\" ) OR ( 'team' = \"b\" )",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+				Status:      influxdb.Active,
+				Permissions: tt.permissions,
+			})
+			got, err := restrictToScope(ctx, source, tt.pred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := reads.PredicateToExprString(got); s != tt.want {
+				t.Errorf("got predicate %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
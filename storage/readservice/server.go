@@ -0,0 +1,211 @@
+package readservice
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Server serves the storage read service over gRPC. Connections are secured
+// with TLS, and each request must carry a token, as "Token <token>" in its
+// authorization metadata, which may read the bucket requested.
+type Server struct {
+	mu     sync.Mutex
+	store  reads.Store
+	config GRPCConfig
+	ln     net.Listener
+	srv    *grpc.Server
+	wg     sync.WaitGroup
+
+	AuthorizationService influxdb.AuthorizationService
+	Logger               *zap.Logger
+}
+
+// NewServer returns a new Server serving reads of store with config.
+func NewServer(store reads.Store, config GRPCConfig) *Server {
+	return &Server{
+		store:  store,
+		config: config,
+		Logger: zap.NewNop(),
+	}
+}
+
+// Open starts listening on the configured address and serving requests.
+func (s *Server) Open() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.srv != nil {
+		return nil
+	} else if err := s.config.Validate(); err != nil {
+		return err
+	} else if s.AuthorizationService == nil {
+		return errors.New("grpc server requires an authorization service")
+	}
+
+	creds, err := credentials.NewServerTLSFromFile(s.config.TLSCert, s.config.TLSKey)
+	if err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp", s.config.BindAddress)
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	s.srv = grpc.NewServer(append(s.config.ServerOptions(),
+		grpc.Creds(creds),
+		grpc.UnaryInterceptor(s.unaryAuth),
+		grpc.StreamInterceptor(s.streamAuth),
+	)...)
+	datatypes.RegisterStorageServer(s.srv, s)
+
+	settings := s.config.Effective()
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := []zap.Field{zap.String("transport", "grpc"), zap.Stringer("addr", ln.Addr())}
+	for _, k := range keys {
+		fields = append(fields, zap.String(k, settings[k]))
+	}
+	s.Logger.Info("Listening", fields...)
+
+	s.wg.Add(1)
+	go func(srv *grpc.Server) {
+		defer s.wg.Done()
+		if err := srv.Serve(ln); err != nil && err != grpc.ErrServerStopped {
+			s.Logger.Error("Failed grpc service", zap.Error(err))
+		}
+	}(s.srv)
+	return nil
+}
+
+// Close stops accepting connections and waits for the requests being served
+// to complete.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	srv := s.srv
+	s.srv = nil
+	s.mu.Unlock()
+
+	if srv == nil {
+		return nil
+	}
+	srv.GracefulStop()
+	s.wg.Wait()
+	return nil
+}
+
+// Addr returns the address the server is listening on, or nil if it is not
+// open.
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.srv == nil {
+		return nil
+	}
+	return s.ln.Addr()
+}
+
+// ReadFilter implements datatypes.StorageServer.
+func (s *Server) ReadFilter(req *datatypes.ReadFilterRequest, stream datatypes.Storage_ReadFilterServer) error {
+	if err := authorizeRead(stream.Context(), req.ReadSource); err != nil {
+		return err
+	}
+	rs, err := s.store.ReadFilter(stream.Context(), req)
+	if err != nil {
+		return err
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(stream, 0,
+		reads.WithMaxFramePoints(int(req.MaxFramePoints)),
+		reads.WithMaxMessageBytes(int(req.MaxMessageBytes)))
+	if err := w.WriteResultSet(rs); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// ReadGroup implements datatypes.StorageServer.
+func (s *Server) ReadGroup(req *datatypes.ReadGroupRequest, stream datatypes.Storage_ReadGroupServer) error {
+	if err := authorizeRead(stream.Context(), req.ReadSource); err != nil {
+		return err
+	}
+	rs, err := s.store.ReadGroup(stream.Context(), req)
+	if err != nil {
+		return err
+	} else if rs == nil {
+		return nil
+	}
+	defer rs.Close()
+
+	w := reads.NewResponseWriter(stream, req.Hints,
+		reads.WithMaxFramePoints(int(req.MaxFramePoints)),
+		reads.WithMaxMessageBytes(int(req.MaxMessageBytes)))
+	if err := w.WriteGroupResultSet(rs); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// TagKeys implements datatypes.StorageServer.
+func (s *Server) TagKeys(req *datatypes.TagKeysRequest, stream datatypes.Storage_TagKeysServer) error {
+	if err := authorizeRead(stream.Context(), req.TagsSource); err != nil {
+		return err
+	}
+	itr, err := s.store.TagKeys(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	w := reads.NewStringIteratorWriter(stream)
+	if err := w.WriteStringIterator(itr); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// TagValues implements datatypes.StorageServer.
+func (s *Server) TagValues(req *datatypes.TagValuesRequest, stream datatypes.Storage_TagValuesServer) error {
+	if err := authorizeRead(stream.Context(), req.TagsSource); err != nil {
+		return err
+	}
+	itr, err := s.store.TagValues(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	w := reads.NewStringIteratorWriter(stream)
+	if err := w.WriteStringIterator(itr); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Err()
+}
+
+// Capabilities implements datatypes.StorageServer. The effective settings of
+// the server are returned with a "grpc." prefix, so that clients can check
+// them at runtime.
+func (s *Server) Capabilities(context.Context, *types.Empty) (*datatypes.CapabilitiesResponse, error) {
+	caps := make(map[string]string)
+	for k, v := range s.config.Effective() {
+		caps["grpc."+k] = v
+	}
+	return &datatypes.CapabilitiesResponse{Caps: caps}, nil
+}
@@ -0,0 +1,265 @@
+package readservice_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/storage/reads"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type tagKeysStore struct {
+	reads.Store
+	keys []string
+}
+
+func (s *tagKeysStore) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
+	return cursors.NewStringSliceIterator(s.keys), nil
+}
+
+func (s *tagKeysStore) GetSource(orgID, bucketID uint64) proto.Message {
+	return readservice.NewStore(nil).GetSource(orgID, bucketID)
+}
+
+var (
+	orgID    = influxdb.ID(1)
+	bucketID = influxdb.ID(2)
+)
+
+// openServer opens a server of store with config, secured with a new
+// certificate, which authorizes the token "reader" to read bucketID. It
+// returns a connection to the server.
+func openServer(t *testing.T, store reads.Store, config readservice.GRPCConfig) (*grpc.ClientConn, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "readservice-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM := newCertificate(t)
+	config.BindAddress = "127.0.0.1:0"
+	config.TLSCert = filepath.Join(dir, "cert.pem")
+	config.TLSKey = filepath.Join(dir, "key.pem")
+	if err := ioutil.WriteFile(config.TLSCert, certPEM, 0600); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(config.TLSKey, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	auths := mock.NewAuthorizationService()
+	auths.FindAuthorizationByTokenFn = func(_ context.Context, token string) (*influxdb.Authorization, error) {
+		if token != "reader" {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+		}
+		p, err := influxdb.NewPermissionAtID(bucketID, influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+		if err != nil {
+			return nil, err
+		}
+		return &influxdb.Authorization{Status: influxdb.Active, Permissions: []influxdb.Permission{*p}}, nil
+	}
+
+	s := readservice.NewServer(store, config)
+	s.AuthorizationService = auths
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, s.Addr().String(),
+		grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(pool, "127.0.0.1")),
+		grpc.WithBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, func() {
+		conn.Close()
+		s.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// newCertificate returns a new PEM-encoded self-signed certificate for
+// 127.0.0.1, and its key.
+func newCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// tagKeysRequest returns a request for the tag keys of bucket.
+func tagKeysRequest(t *testing.T, store reads.Store, bucket influxdb.ID) *datatypes.TagKeysRequest {
+	t.Helper()
+	source, err := types.MarshalAny(store.GetSource(uint64(orgID), uint64(bucket)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &datatypes.TagKeysRequest{TagsSource: source}
+}
+
+func TestServer(t *testing.T) {
+	config := readservice.NewGRPCConfig()
+	config.MaxConcurrentStreams = 100
+	config.InitialWindowSize = 1 << 20
+
+	store := &tagKeysStore{keys: []string{"host", "region"}}
+	conn, done := openServer(t, store, config)
+	defer done()
+	client := datatypes.NewStorageClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Token reader")
+
+	// The effective settings are visible to clients.
+	caps, err := client.Capabilities(ctx, &types.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, exp := range map[string]string{
+		"grpc.max-concurrent-streams":          "100",
+		"grpc.initial-window-size":             "1048576",
+		"grpc.initial-conn-window-size":        "dynamic",
+		"grpc.keepalive-min-time":              "10s",
+		"grpc.keepalive-permit-without-stream": "true",
+		"grpc.max-connection-age":              "infinity",
+	} {
+		if got := caps.Caps[k]; got != exp {
+			t.Errorf("unexpected %s: got %q, exp %q", k, got, exp)
+		}
+	}
+
+	stream, err := client.TagKeys(ctx, tagKeysRequest(t, store, bucketID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range res.Values {
+			keys = append(keys, string(v))
+		}
+	}
+	if exp := []string{"host", "region"}; !cmp.Equal(keys, exp) {
+		t.Fatalf("unexpected tag keys: %v", cmp.Diff(keys, exp))
+	}
+}
+
+func TestServer_Unauthorized(t *testing.T) {
+	store := &tagKeysStore{keys: []string{"host", "region"}}
+	conn, done := openServer(t, store, readservice.NewGRPCConfig())
+	defer done()
+	client := datatypes.NewStorageClient(conn)
+
+	for _, tt := range []struct {
+		name   string
+		md     []string
+		bucket influxdb.ID
+		code   codes.Code
+	}{
+		{name: "no token", bucket: bucketID, code: codes.Unauthenticated},
+		{name: "unknown token", md: []string{"authorization", "Token writer"}, bucket: bucketID, code: codes.Unauthenticated},
+		{name: "bad scheme", md: []string{"authorization", "Bearer reader"}, bucket: bucketID, code: codes.Unauthenticated},
+		{name: "other bucket", md: []string{"authorization", "Token reader"}, bucket: bucketID + 1, code: codes.PermissionDenied},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if tt.md != nil {
+				ctx = metadata.AppendToOutgoingContext(ctx, tt.md...)
+			}
+
+			stream, err := client.TagKeys(ctx, tagKeysRequest(t, store, tt.bucket))
+			if err == nil {
+				var res *datatypes.StringValuesResponse
+				if res, err = stream.Recv(); err == nil {
+					t.Fatalf("unexpected tag keys: %v", res.Values)
+				}
+			}
+			if got := status.Code(err); got != tt.code {
+				t.Fatalf("unexpected code: got %v, exp %v: %v", got, tt.code, err)
+			}
+		})
+	}
+
+	// The settings of the server are not disclosed without a token either.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := client.Capabilities(ctx, &types.Empty{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGRPCConfig_Validate(t *testing.T) {
+	if err := readservice.NewGRPCConfig().Validate(); err != nil {
+		t.Fatalf("unexpected error for default config: %v", err)
+	}
+
+	for _, fn := range []func(c *readservice.GRPCConfig){
+		func(c *readservice.GRPCConfig) { c.MaxConcurrentStreams = -1 },
+		func(c *readservice.GRPCConfig) { c.MaxRecvMsgSize = 0 },
+		func(c *readservice.GRPCConfig) { c.InitialWindowSize = 1024 },
+		func(c *readservice.GRPCConfig) { c.KeepaliveTime = toml.Duration(time.Millisecond) },
+		func(c *readservice.GRPCConfig) { c.MaxConnectionAge = -1 },
+		func(c *readservice.GRPCConfig) { c.BindAddress = ":8082" },
+	} {
+		c := readservice.NewGRPCConfig()
+		fn(&c)
+		if err := c.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", c)
+		}
+	}
+}
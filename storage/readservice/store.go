@@ -23,15 +23,38 @@ type Viewer interface {
 	CreateSeriesCursor(ctx context.Context, req storage.SeriesCursorRequest, cond influxql.Expr) (storage.SeriesCursor, error)
 	TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
 	TagValues(ctx context.Context, orgID, bucketID influxdb.ID, tagKey string, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error)
+	GroupCardinality(ctx context.Context, orgID, bucketID influxdb.ID, tagKeys []string) (groups, series int64, err error)
+}
+
+// QueryAdmitter is implemented by viewers that limit the reads of the data of
+// each organization.
+type QueryAdmitter interface {
+	AdmitOrgQuery(orgID influxdb.ID) error
 }
 
 type store struct {
 	viewer Viewer
+	spill  reads.SpillConfig
+}
+
+// StoreOption configures a store.
+type StoreOption func(s *store)
+
+// WithSpill configures the series sorted by grouped reads to be spilled to
+// disk according to cfg.
+func WithSpill(cfg reads.SpillConfig) StoreOption {
+	return func(s *store) {
+		s.spill = cfg
+	}
 }
 
 // NewStore creates a store used to query time-series data.
-func NewStore(viewer Viewer) reads.Store {
-	return &store{viewer: viewer}
+func NewStore(viewer Viewer, opts ...StoreOption) reads.Store {
+	s := &store{viewer: viewer}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
 }
 
 func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (reads.ResultSet, error) {
@@ -46,6 +69,21 @@ func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest
 	if err != nil {
 		return nil, err
 	}
+	if err := s.admit(&source); err != nil {
+		return nil, err
+	}
+
+	pred, err := restrictToScope(ctx, &source, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	if pred != req.Predicate {
+		r := *req
+		r.Predicate = pred
+		req = &r
+	}
+
+	ctx = cursors.NewContextWithDuplicateResolution(ctx, cursors.DuplicateResolution(req.DuplicateResolution))
 
 	var cur reads.SeriesCursor
 	if cur, err = newIndexSeriesCursor(ctx, &source, req.Predicate, s.viewer); err != nil {
@@ -57,6 +95,18 @@ func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest
 	return reads.NewFilteredResultSet(ctx, req, cur), nil
 }
 
+// HasAggregate reports whether the store computes the aggregate typ of the
+// points of each series it reads.
+func (s *store) HasAggregate(typ datatypes.Aggregate_AggregateType) bool {
+	switch typ {
+	case datatypes.AggregateTypeCount, datatypes.AggregateTypeSum,
+		datatypes.AggregateTypeMin, datatypes.AggregateTypeMax, datatypes.AggregateTypeMean:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *store) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (reads.GroupResultSet, error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
@@ -69,12 +119,62 @@ func (s *store) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.admit(&source); err != nil {
+		return nil, err
+	}
+
+	pred, err := restrictToScope(ctx, &source, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	if pred != req.Predicate {
+		r := *req
+		r.Predicate = pred
+		req = &r
+	}
+
+	ctx = cursors.NewContextWithDuplicateResolution(ctx, cursors.DuplicateResolution(req.DuplicateResolution))
 
 	newCursor := func() (reads.SeriesCursor, error) {
 		return newIndexSeriesCursor(ctx, &source, req.Predicate, s.viewer)
 	}
 
-	return reads.NewGroupResultSet(ctx, req, newCursor), nil
+	opts := []reads.GroupOption{reads.GroupOptionSpill(s.spill)}
+	if req.Group == datatypes.GroupBy {
+		groups, series, err := s.viewer.GroupCardinality(ctx, influxdb.ID(source.OrganizationID), influxdb.ID(source.BucketID), indexTagKeys(req.GroupKeys))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, reads.GroupOptionCardinality(groups, series))
+	}
+
+	return reads.NewGroupResultSet(ctx, req, newCursor, opts...), nil
+}
+
+// admit returns an error if the viewer does not accept another read of the
+// data of the organization of source.
+func (s *store) admit(source *readSource) error {
+	if qa, ok := s.viewer.(QueryAdmitter); ok {
+		return qa.AdmitOrgQuery(influxdb.ID(source.OrganizationID))
+	}
+	return nil
+}
+
+// indexTagKeys returns the keys as they are stored in the index, where the
+// measurement and field are stored as tags with reserved keys.
+func indexTagKeys(keys []string) []string {
+	a := make([]string, len(keys))
+	for i, k := range keys {
+		switch k {
+		case measurementKey:
+			a[i] = models.MeasurementTagKey
+		case fieldKey:
+			a[i] = models.FieldKeyTagKey
+		default:
+			a[i] = k
+		}
+	}
+	return a
 }
 
 func (s *store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error) {
@@ -92,9 +192,17 @@ func (s *store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		req.Range.End = models.MaxNanoTime
 	}
 
+	readSource, err := getReadSource(*req.TagsSource)
+	if err != nil {
+		return nil, err
+	}
+	pred, err := restrictToScope(ctx, &readSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
 	var expr influxql.Expr
-	var err error
-	if root := req.Predicate.GetRoot(); root != nil {
+	if root := pred.GetRoot(); root != nil {
 		expr, err = reads.NodeToExpr(root, nil)
 		if err != nil {
 			return nil, err
@@ -109,10 +217,6 @@ func (s *store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		}
 	}
 
-	readSource, err := getReadSource(*req.TagsSource)
-	if err != nil {
-		return nil, err
-	}
 	return s.viewer.TagKeys(ctx, influxdb.ID(readSource.OrganizationID), influxdb.ID(readSource.BucketID), req.Range.Start, req.Range.End, expr)
 }
 
@@ -135,9 +239,17 @@ func (s *store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 		return nil, errors.New("missing tag key")
 	}
 
+	readSource, err := getReadSource(*req.TagsSource)
+	if err != nil {
+		return nil, err
+	}
+	pred, err := restrictToScope(ctx, &readSource, req.Predicate)
+	if err != nil {
+		return nil, err
+	}
+
 	var expr influxql.Expr
-	var err error
-	if root := req.Predicate.GetRoot(); root != nil {
+	if root := pred.GetRoot(); root != nil {
 		expr, err = reads.NodeToExpr(root, nil)
 		if err != nil {
 			return nil, err
@@ -152,10 +264,6 @@ func (s *store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 		}
 	}
 
-	readSource, err := getReadSource(*req.TagsSource)
-	if err != nil {
-		return nil, err
-	}
 	return s.viewer.TagValues(ctx, influxdb.ID(readSource.OrganizationID), influxdb.ID(readSource.BucketID), req.TagKey, req.Range.Start, req.Range.End, expr)
 }
 
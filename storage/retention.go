@@ -3,12 +3,16 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -21,7 +25,8 @@ const (
 
 // A Deleter implementation is capable of deleting data from a storage engine.
 type Deleter interface {
-	DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64) error
+	DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error
+	DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error
 }
 
 // A Snapshotter implementation can take snapshots of the entire engine.
@@ -51,7 +56,13 @@ type retentionEnforcer struct {
 
 	logger *zap.Logger
 
+	// timeGen provides the time that retention periods are relative to.
+	timeGen influxdb.TimeGenerator
+
 	tracker *retentionTracker
+
+	mu      sync.Mutex
+	expired map[influxdb.ID]uint64 // expiries of each org's buckets
 }
 
 // newRetentionEnforcer returns a new enforcer that ensures expired data is
@@ -63,8 +74,22 @@ func newRetentionEnforcer(engine Deleter, snapshotter Snapshotter, bucketService
 		Snapshotter:   snapshotter,
 		BucketService: bucketService,
 		logger:        zap.NewNop(),
+		timeGen:       influxdb.RealTimeGenerator{},
 		tracker:       newRetentionTracker(newRetentionMetrics(nil), nil),
+		expired:       make(map[influxdb.ID]uint64),
+	}
+}
+
+// Expiries returns the number of times data has been expired from the buckets
+// of each org.
+func (s *retentionEnforcer) Expiries() map[influxdb.ID]uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expired := make(map[influxdb.ID]uint64, len(s.expired))
+	for orgID, n := range s.expired {
+		expired[orgID] = n
 	}
+	return expired
 }
 
 // SetDefaultMetricLabels sets the default labels for the retention metrics.
@@ -103,20 +128,21 @@ func (s *retentionEnforcer) run() {
 	log, logEnd := logger.NewOperation(ctx, s.logger, "Data retention check", "data_retention_check")
 	defer logEnd()
 
-	now := time.Now().UTC()
+	now := s.timeGen.Now().UTC()
 	buckets, err := s.getBucketInformation(ctx)
 	if err != nil {
 		log.Error("Unable to determine bucket information", zap.Error(err))
 	} else {
 		s.expireData(ctx, buckets, now)
 	}
-	s.tracker.CheckDuration(time.Since(now), err == nil)
+	s.tracker.CheckDuration(s.timeGen.Now().Sub(now), err == nil)
 }
 
 // expireData runs a delete operation on the storage engine.
 //
 // Any series data that (1) belongs to a bucket in the provided list and
-// (2) falls outside the bucket's indicated retention period will be deleted.
+// (2) falls outside the retention period of its measurement, or else of the
+// bucket, will be deleted.
 func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.Bucket, now time.Time) {
 	logger, logEnd := logger.NewOperation(ctx, s.logger, "Data deletion", "data_deletion",
 		zap.Int("buckets", len(buckets)))
@@ -136,7 +162,7 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			zap.String("system_type", b.Type.String()),
 		}
 
-		if b.RetentionPeriod == 0 {
+		if b.RetentionPeriod == 0 && len(b.MeasurementRetention) == 0 {
 			logger.Debug("Skipping bucket with infinite retention", bucketFields...)
 			skipInf++
 			continue
@@ -146,9 +172,6 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			continue
 		}
 
-		min := int64(math.MinInt64)
-		max := now.Add(-b.RetentionPeriod).UnixNano()
-
 		span, ctx := tracing.StartSpanFromContext(ctx)
 		span.LogKV(
 			"bucket_id", b.ID,
@@ -156,15 +179,16 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 			"system_type", b.Type,
 			"retention_period", b.RetentionPeriod,
 			"retention_policy", b.RetentionPolicyName,
-			"from", time.Unix(0, min).UTC(),
-			"to", time.Unix(0, max).UTC(),
 		)
 
-		err := s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, min, max)
+		err := s.expireBucket(ctx, b, now)
 		if err != nil {
-			logger.Info("Unable to delete bucket range",
-				append(bucketFields, zap.Time("min", time.Unix(0, min)), zap.Time("max", time.Unix(0, max)), zap.Error(err))...)
+			logger.Info("Unable to delete bucket range", append(bucketFields, zap.Error(err))...)
 			tracing.LogError(span, err)
+		} else {
+			s.mu.Lock()
+			s.expired[b.OrgID]++
+			s.mu.Unlock()
 		}
 		s.tracker.IncChecks(err == nil)
 		span.Finish()
@@ -175,6 +199,77 @@ func (s *retentionEnforcer) expireData(ctx context.Context, buckets []*influxdb.
 	}
 }
 
+// expireBucket deletes the data of each measurement of b with a retention rule
+// that is outside of the retention period of the rule, then the data of the
+// other measurements that is outside of the retention period of b.
+func (s *retentionEnforcer) expireBucket(ctx context.Context, b *influxdb.Bucket, now time.Time) error {
+	min := int64(math.MinInt64)
+	for _, mr := range b.MeasurementRetention {
+		max := retentionMax(b, mr.RetentionPeriod, now)
+		if err := s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, mr.Measurement, min, max); err != nil {
+			return fmt.Errorf("measurement %q: %v", mr.Measurement, err)
+		}
+	}
+
+	if b.RetentionPeriod == 0 {
+		return nil
+	}
+	max := retentionMax(b, b.RetentionPeriod, now)
+	if len(b.MeasurementRetention) == 0 {
+		return s.Engine.DeleteBucketRange(ctx, b.OrgID, b.ID, "", min, max)
+	}
+
+	// The measurements with retention rules are excluded from the delete.
+	pred, err := excludeMeasurementsPredicate(b.MeasurementRetention)
+	if err != nil {
+		return err
+	}
+	return s.Engine.DeleteBucketRangePredicate(ctx, b.OrgID, b.ID, min, max, pred)
+}
+
+// retentionMax returns the time of the latest data of b outside of the
+// retention period d.
+func retentionMax(b *influxdb.Bucket, d time.Duration, now time.Time) int64 {
+	max := now.Add(-d).UnixNano()
+	if b.ShardGroupDuration > 0 {
+		// Expire whole shard groups only, so that entire blocks are dropped
+		// rather than rewritten.
+		max = tsm1.ShardGroupStart(max, b.ShardGroupDuration) - 1
+	}
+	return max
+}
+
+// excludeMeasurementsPredicate returns a predicate matching the series keys of
+// every measurement but those of rules.
+func excludeMeasurementsPredicate(rules []influxdb.MeasurementRetention) (tsm1.Predicate, error) {
+	var root *datatypes.Node
+	for _, mr := range rules {
+		node := &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonNotEqual},
+			Children: []*datatypes.Node{
+				{
+					NodeType: datatypes.NodeTypeTagRef,
+					Value:    &datatypes.Node_TagRefValue{TagRefValue: models.MeasurementTagKey},
+				},
+				{
+					NodeType: datatypes.NodeTypeLiteral,
+					Value:    &datatypes.Node_StringValue{StringValue: mr.Measurement},
+				},
+			},
+		}
+		if root != nil {
+			node = &datatypes.Node{
+				NodeType: datatypes.NodeTypeLogicalExpression,
+				Value:    &datatypes.Node_Logical_{Logical: datatypes.LogicalAnd},
+				Children: []*datatypes.Node{root, node},
+			}
+		}
+		root = node
+	}
+	return tsm1.NewProtobufPredicate(&datatypes.Predicate{Root: root})
+}
+
 // getBucketInformation returns a slice of buckets to run retention on.
 func (s *retentionEnforcer) getBucketInformation(ctx context.Context) ([]*influxdb.Bucket, error) {
 	ctx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
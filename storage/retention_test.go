@@ -215,7 +215,7 @@ func TestRetentionService(t *testing.T) {
 	}
 
 	gotMatched := map[string]struct{}{}
-	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, from, to int64) error {
+	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, from, to int64) error {
 		if from != math.MinInt64 {
 			t.Fatalf("got from %d, expected %d", from, int64(math.MinInt64))
 		}
@@ -240,6 +240,128 @@ func TestRetentionService(t *testing.T) {
 	})
 }
 
+func TestRetentionService_ShardGroupDuration(t *testing.T) {
+	t.Parallel()
+	engine := NewTestEngine()
+	service := newRetentionEnforcer(engine, &TestSnapshotter{}, NewTestBucketFinder())
+	now := time.Date(2018, 4, 10, 23, 12, 33, 0, time.UTC)
+
+	orgID, bucketID := tsdb.DecodeName(tsdb.EncodeName(1, 2))
+	buckets := []*influxdb.Bucket{{
+		OrgID:              orgID,
+		ID:                 bucketID,
+		RetentionPeriod:    3 * 24 * time.Hour,
+		ShardGroupDuration: 24 * time.Hour,
+	}}
+
+	var got int64
+	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, from, to int64) error {
+		got = to
+		return nil
+	}
+
+	service.expireData(context.Background(), buckets, now)
+
+	// Only the shard groups ending before the retention period are expired.
+	if exp := time.Date(2018, 4, 7, 0, 0, 0, 0, time.UTC).UnixNano() - 1; got != exp {
+		t.Fatalf("got to %d, expected %d", got, exp)
+	}
+}
+
+func TestRetentionService_MeasurementRetention(t *testing.T) {
+	t.Parallel()
+	engine := NewTestEngine()
+	service := newRetentionEnforcer(engine, &TestSnapshotter{}, NewTestBucketFinder())
+	now := time.Date(2018, 4, 10, 23, 12, 33, 0, time.UTC)
+
+	orgID, bucketID := tsdb.DecodeName(tsdb.EncodeName(1, 2))
+	buckets := []*influxdb.Bucket{{
+		OrgID:           orgID,
+		ID:              bucketID,
+		RetentionPeriod: 30 * 24 * time.Hour,
+		MeasurementRetention: []influxdb.MeasurementRetention{
+			{Measurement: "events", RetentionPeriod: 2 * 365 * 24 * time.Hour},
+			{Measurement: "debug", RetentionPeriod: time.Hour},
+		},
+	}}
+
+	measurements := map[string]int64{}
+	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, from, to int64) error {
+		measurements[measurement] = to
+		return nil
+	}
+	var pred influxdb.Predicate
+	var predTo int64
+	engine.DeleteBucketRangePredicateFn = func(ctx context.Context, orgID, bucketID influxdb.ID, from, to int64, p influxdb.Predicate) error {
+		pred, predTo = p, to
+		return nil
+	}
+
+	service.expireData(context.Background(), buckets, now)
+
+	// Each measurement with a rule is expired with its own retention period.
+	if exp := map[string]int64{
+		"events": now.Add(-2 * 365 * 24 * time.Hour).UnixNano(),
+		"debug":  now.Add(-time.Hour).UnixNano(),
+	}; !reflect.DeepEqual(measurements, exp) {
+		t.Fatalf("got measurement deletes %v, expected %v", measurements, exp)
+	}
+
+	// The other measurements are expired with the retention period of the
+	// bucket.
+	if pred == nil {
+		t.Fatal("expected a delete of the other measurements")
+	} else if exp := now.Add(-30 * 24 * time.Hour).UnixNano(); predTo != exp {
+		t.Fatalf("got to %d, expected %d", predTo, exp)
+	}
+	name := tsdb.EncodeName(orgID, bucketID)
+	for m, exp := range map[string]bool{"cpu": true, "events": false, "debug": false} {
+		key := string(name[:]) + ",\x00=" + m + ",host=a#!~#v"
+		if got := pred.Matches([]byte(key)); got != exp {
+			t.Errorf("got match %v for %q, expected %v", got, m, exp)
+		}
+	}
+
+	// A bucket with infinite retention only expires measurements with rules.
+	buckets[0].RetentionPeriod = 0
+	pred = nil
+	measurements = map[string]int64{}
+	service.expireData(context.Background(), buckets, now)
+	if len(measurements) != 2 {
+		t.Fatalf("got %d measurement deletes, expected 2", len(measurements))
+	} else if pred != nil {
+		t.Fatal("unexpected delete of the other measurements")
+	}
+}
+
+func TestRetentionService_Expiries(t *testing.T) {
+	t.Parallel()
+	engine := NewTestEngine()
+	service := newRetentionEnforcer(engine, &TestSnapshotter{}, NewTestBucketFinder())
+	now := time.Date(2018, 4, 10, 23, 12, 33, 0, time.UTC)
+
+	buckets := []*influxdb.Bucket{
+		{OrgID: 1, ID: 2, RetentionPeriod: time.Hour},
+		{OrgID: 1, ID: 3, RetentionPeriod: time.Hour},
+		{OrgID: 4, ID: 5, RetentionPeriod: time.Hour},
+		{OrgID: 4, ID: 6},
+	}
+	engine.DeleteBucketRangeFn = func(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, from, to int64) error {
+		if bucketID == 3 {
+			return errors.New("delete failed")
+		}
+		return nil
+	}
+
+	service.expireData(context.Background(), buckets, now)
+	service.expireData(context.Background(), buckets, now)
+
+	// Only successful deletes are counted.
+	if got, exp := service.Expiries(), map[influxdb.ID]uint64{1: 2, 4: 2}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got expiries %v, expected %v", got, exp)
+	}
+}
+
 func TestMetrics_Retention(t *testing.T) {
 	t.Parallel()
 	// metrics to be shared by multiple file stores.
@@ -314,17 +436,23 @@ func (r *MockRunner) run() {
 }
 
 type TestEngine struct {
-	DeleteBucketRangeFn func(context.Context, influxdb.ID, influxdb.ID, int64, int64) error
+	DeleteBucketRangeFn          func(context.Context, influxdb.ID, influxdb.ID, string, int64, int64) error
+	DeleteBucketRangePredicateFn func(context.Context, influxdb.ID, influxdb.ID, int64, int64, influxdb.Predicate) error
 }
 
 func NewTestEngine() *TestEngine {
 	return &TestEngine{
-		DeleteBucketRangeFn: func(context.Context, influxdb.ID, influxdb.ID, int64, int64) error { return nil },
+		DeleteBucketRangeFn:          func(context.Context, influxdb.ID, influxdb.ID, string, int64, int64) error { return nil },
+		DeleteBucketRangePredicateFn: func(context.Context, influxdb.ID, influxdb.ID, int64, int64, influxdb.Predicate) error { return nil },
 	}
 }
 
-func (e *TestEngine) DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64) error {
-	return e.DeleteBucketRangeFn(ctx, orgID, bucketID, min, max)
+func (e *TestEngine) DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, measurement string, min, max int64) error {
+	return e.DeleteBucketRangeFn(ctx, orgID, bucketID, measurement, min, max)
+}
+
+func (e *TestEngine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	return e.DeleteBucketRangePredicateFn(ctx, orgID, bucketID, min, max, pred)
 }
 
 type TestSnapshotter struct{}
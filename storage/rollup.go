@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// rollupFlushInterval is how often the windows of rollups are checked for
+// those that have closed.
+const rollupFlushInterval = time.Second
+
+// rollupKey identifies a window of a series field aggregated by a rollup.
+type rollupKey struct {
+	rule        influxdb.Rollup
+	org, bucket influxdb.ID
+	key         string // series key
+	field       string
+	start       int64
+}
+
+// rollupWindow is the aggregate of the values of a window.
+type rollupWindow struct {
+	tags     models.Tags
+	count    int64
+	sum      float64
+	min, max float64
+	last     float64
+	lastTime int64
+}
+
+func (w *rollupWindow) add(t int64, v float64) {
+	if w.count == 0 || v < w.min {
+		w.min = v
+	}
+	if w.count == 0 || v > w.max {
+		w.max = v
+	}
+	if w.count == 0 || t >= w.lastTime {
+		w.last, w.lastTime = v, t
+	}
+	w.count++
+	w.sum += v
+}
+
+// value returns the aggregate of the window computed by fn.
+func (w *rollupWindow) value(fn string) interface{} {
+	switch fn {
+	case influxdb.RollupFunctionCount:
+		return w.count
+	case influxdb.RollupFunctionSum:
+		return w.sum
+	case influxdb.RollupFunctionMean:
+		return w.sum / float64(w.count)
+	case influxdb.RollupFunctionMin:
+		return w.min
+	case influxdb.RollupFunctionMax:
+		return w.max
+	default:
+		return w.last
+	}
+}
+
+// rollups downsamples the points written to buckets into other buckets with
+// the rollup rules of each bucket, as they are written.
+//
+// The windows being aggregated are only held in memory. They are rebuilt when
+// the WAL is replayed, so the rules must be set before the engine is opened.
+// A window closes delay after its end, and values added to it afterwards are
+// ignored, so that the aggregate written for it is never overwritten with
+// that of a part of its values.
+type rollups struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	rules   map[influxdb.ID][]influxdb.Rollup
+	windows map[rollupKey]*rollupWindow
+}
+
+func newRollups(delay time.Duration) *rollups {
+	return &rollups{
+		delay:   delay,
+		rules:   make(map[influxdb.ID][]influxdb.Rollup),
+		windows: make(map[rollupKey]*rollupWindow),
+	}
+}
+
+// setRules sets the rollup rules of a bucket. The windows of the rules it no
+// longer has are dropped.
+func (r *rollups) setRules(bucketID influxdb.ID, rules []influxdb.Rollup) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(rules) == 0 {
+		delete(r.rules, bucketID)
+	} else {
+		r.rules[bucketID] = append([]influxdb.Rollup(nil), rules...)
+	}
+
+	for k := range r.windows {
+		if k.bucket == bucketID && !hasRollup(rules, k.rule) {
+			delete(r.windows, k)
+		}
+	}
+}
+
+func hasRollup(rules []influxdb.Rollup, rule influxdb.Rollup) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds the numeric values of the points of collection to the windows of
+// the rollups of their buckets that have not closed by now.
+func (r *rollups) add(collection *tsdb.SeriesCollection, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.rules) == 0 {
+		return
+	}
+
+	for iter := collection.Iterator(); iter.Next(); {
+		org, bucket := tsdb.DecodeNameSlice(iter.Name())
+		rules := r.rules[bucket]
+		if len(rules) == 0 {
+			continue
+		}
+
+		p, tags := iter.Point(), iter.Tags()
+		measurement := string(tags.Get(models.MeasurementTagKeyBytes))
+		t := p.UnixNano()
+		for _, rule := range rules {
+			if rule.Measurement != "" && rule.Measurement != measurement {
+				continue
+			}
+			start := truncateTime(t, int64(rule.Every))
+			if start+int64(rule.Every)+int64(r.delay) <= now.UnixNano() {
+				continue
+			}
+
+			for fi := p.FieldIterator(); fi.Next(); {
+				v, ok := rollupValue(fi)
+				if !ok {
+					continue
+				}
+
+				k := rollupKey{
+					rule:   rule,
+					org:    org,
+					bucket: bucket,
+					key:    string(iter.Key()),
+					field:  string(fi.FieldKey()),
+					start:  start,
+				}
+				w := r.windows[k]
+				if w == nil {
+					w = &rollupWindow{tags: tags.Clone()}
+					r.windows[k] = w
+				}
+				w.add(t, v)
+			}
+		}
+	}
+}
+
+// rollupValue returns the value of the current field of fi as a float, if it
+// is numeric.
+func rollupValue(fi models.FieldIterator) (float64, bool) {
+	switch fi.Type() {
+	case models.Float:
+		v, err := fi.FloatValue()
+		return v, err == nil
+	case models.Integer:
+		v, err := fi.IntegerValue()
+		return float64(v), err == nil
+	case models.Unsigned:
+		v, err := fi.UnsignedValue()
+		return float64(v), err == nil
+	default:
+		return 0, false
+	}
+}
+
+// flush returns the aggregates of the windows that have closed by now, as
+// points of the target buckets of their rules, and forgets the windows.
+func (r *rollups) flush(now time.Time) ([]models.Point, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var points []models.Point
+	for k, w := range r.windows {
+		if k.start+int64(k.rule.Every)+int64(r.delay) > now.UnixNano() {
+			continue
+		}
+		delete(r.windows, k)
+
+		name := tsdb.EncodeName(k.org, k.rule.TargetBucketID)
+		p, err := models.NewPoint(string(name[:]), w.tags, models.Fields{k.field: w.value(k.rule.Function)}, time.Unix(0, k.start))
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// SetBucketRollups sets the rollup rules downsampling the data written to a
+// bucket. The rules of every bucket must be set before the engine is opened,
+// with WithRollupRules, for the windows being aggregated to be rebuilt from the
+// WAL.
+func (e *Engine) SetBucketRollups(bucketID influxdb.ID, rules []influxdb.Rollup) {
+	e.rollups.setRules(bucketID, rules)
+}
+
+// WithRollupRules sets the rollup rules of every bucket found by finder on the
+// engine when it is opened, before the WAL is replayed.
+func WithRollupRules(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.rollupFinder = finder
+	}
+}
+
+// loadRollupRules sets the rollup rules of every bucket found by the rollup
+// finder of the engine, if it has one.
+func (e *Engine) loadRollupRules(ctx context.Context) error {
+	if e.rollupFinder == nil {
+		return nil
+	}
+
+	buckets, _, err := e.rollupFinder.FindBuckets(ctx, influxdb.BucketFilter{})
+	if err != nil {
+		return err
+	}
+	for _, b := range buckets {
+		e.rollups.setRules(b.ID, b.Rollups)
+	}
+	return nil
+}
+
+// runRollups writes the aggregates of the windows of rollups as they close
+// in a separate goroutine, stopping when the engine is closed.
+func (e *Engine) runRollups() {
+	l := e.logger.With(zap.String("component", "rollups"), logger.DurationLiteral("delay", e.rollups.delay))
+	closing := e.closing
+	ticker := time.NewTicker(rollupFlushInterval)
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-closing:
+				return
+			case <-ticker.C:
+				if err := e.flushRollups(context.Background()); err != nil {
+					l.Warn("Unable to write rollups", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// flushRollups writes the aggregates of the windows of rollups that have
+// closed.
+func (e *Engine) flushRollups(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	points, err := e.rollups.flush(e.timeGen.Now())
+	if err != nil || len(points) == 0 {
+		return err
+	}
+	return e.WritePoints(ctx, points)
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestRollups(t *testing.T) {
+	org, bucket, target := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+	r := newRollups(5 * time.Second)
+	r.setRules(bucket, []influxdb.Rollup{
+		{Measurement: "cpu", Every: 10 * time.Second, Function: influxdb.RollupFunctionMean, TargetBucketID: target},
+		{Measurement: "mem", Every: time.Minute, Function: influxdb.RollupFunctionCount, TargetBucketID: target},
+	})
+
+	name := tsdb.EncodeNameString(org, bucket)
+	p := func(m string, v interface{}, sec int64) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m}),
+			map[string]interface{}{"value": v},
+			time.Unix(sec, 0),
+		)
+	}
+
+	now := time.Unix(12, 0)
+	r.add(tsdb.NewSeriesCollection([]models.Point{
+		p("cpu", 1.0, 1), p("cpu", int64(2), 9), p("cpu", 4.0, 10), p("cpu", "high", 2), p("mem", 1.0, 2),
+	}), now)
+
+	// Windows are only written once they have closed.
+	if points, err := r.flush(time.Unix(14, 0)); err != nil {
+		t.Fatal(err)
+	} else if len(points) != 0 {
+		t.Fatalf("got points %v, expected none", points)
+	}
+
+	points, err := r.flush(time.Unix(15, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("got points %v, expected one", points)
+	}
+	if got, exp := points[0].Name(), tsdb.EncodeName(org, target); !reflect.DeepEqual(got, exp[:]) {
+		t.Fatalf("got name %x, expected %x", got, exp)
+	}
+	if fields, err := points[0].Fields(); err != nil {
+		t.Fatal(err)
+	} else if got := fields["value"]; got != 1.5 {
+		t.Fatalf("got mean %v, expected 1.5", got)
+	}
+	if got := points[0].Time(); !got.Equal(time.Unix(0, 0)) {
+		t.Fatalf("got time %s, expected the start of the window", got)
+	}
+
+	// Points of closed windows are ignored.
+	r.add(tsdb.NewSeriesCollection([]models.Point{p("cpu", 8.0, 3)}), time.Unix(15, 0))
+	if points, err := r.flush(time.Unix(25, 0)); err != nil {
+		t.Fatal(err)
+	} else if len(points) != 1 {
+		t.Fatalf("got points %v, expected the window at 10s", points)
+	}
+
+	// Windows of removed rules are dropped.
+	r.setRules(bucket, nil)
+	if points, err := r.flush(time.Unix(3600, 0)); err != nil {
+		t.Fatal(err)
+	} else if len(points) != 0 {
+		t.Fatalf("got points %v, expected none", points)
+	}
+}
+
+func TestEngine_Rollups_ReplayWAL(t *testing.T) {
+	path := MustTempDir()
+	defer os.RemoveAll(path)
+
+	org, bucket, target := influxdb.ID(1), influxdb.ID(2), influxdb.ID(3)
+	finder := NewTestBucketFinder()
+	finder.FindBucketsFn = func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return []*influxdb.Bucket{{
+			ID:      bucket,
+			OrgID:   org,
+			Rollups: []influxdb.Rollup{{Every: time.Minute, Function: influxdb.RollupFunctionSum, TargetBucketID: target}},
+		}}, 1, nil
+	}
+	now := time.Unix(30, 0)
+	open := func() *Engine {
+		e := NewEngine(path, NewConfig(), WithNodeID(100), WithEngineID(30), WithRollupRules(finder), WithTimeGenerator(influxdb.NewStepTimeGenerator(now, 0)))
+		if err := e.Open(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	e := open()
+	err := e.WritePoints(context.Background(), []models.Point{
+		models.MustNewPoint(
+			tsdb.EncodeNameString(org, bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu"}),
+			map[string]interface{}{"value": 2.0},
+			time.Unix(10, 0),
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The open window is rebuilt from the WAL.
+	e = open()
+	defer e.Close()
+	points, err := e.rollups.flush(time.Unix(120, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 1 {
+		t.Fatalf("got points %v, expected one", points)
+	}
+	if fields, err := points[0].Fields(); err != nil {
+		t.Fatal(err)
+	} else if got := fields["value"]; got != 2.0 {
+		t.Fatalf("got sum %v, expected 2", got)
+	}
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/snowflake"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"go.uber.org/zap"
+)
+
+var _ influxdb.SeriesMoveService = (*Engine)(nil)
+
+// seriesMoves holds the series moves started since the engine was opened.
+type seriesMoves struct {
+	mu    sync.RWMutex
+	idGen influxdb.IDGenerator
+	moves map[influxdb.ID]*influxdb.SeriesMove
+}
+
+func newSeriesMoves() *seriesMoves {
+	return &seriesMoves{
+		idGen: snowflake.NewIDGenerator(),
+		moves: make(map[influxdb.ID]*influxdb.SeriesMove),
+	}
+}
+
+// update applies fn to the series move with id.
+func (s *seriesMoves) update(id influxdb.ID, fn func(m *influxdb.SeriesMove)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.moves[id])
+}
+
+// MoveSeries starts moving the series of a bucket that match pred into another
+// bucket. The data is copied into the destination bucket, then deleted from
+// the source bucket.
+//
+// Series moves are kept in memory, so they are forgotten when the process
+// exits, and moves in progress fail when the engine is closed. Points written to the source
+// bucket while a move is in progress may be deleted without being copied.
+func (e *Engine) MoveSeries(ctx context.Context, m *influxdb.SeriesMove, pred influxdb.Predicate) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if m.SourceBucketID == m.DestinationBucketID {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "source and destination buckets must differ",
+		}
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	} else if e.config.ReadOnly {
+		return ErrEngineReadOnly
+	}
+
+	now := e.timeGen.Now().UTC()
+	m.ID = e.seriesMoves.idGen.ID()
+	m.Status = influxdb.SeriesMoveRunning
+	m.Error = ""
+	m.Series = 0
+	m.StartedAt, m.FinishedAt = &now, nil
+
+	mv := *m
+	e.seriesMoves.mu.Lock()
+	e.seriesMoves.moves[mv.ID] = &mv
+	e.seriesMoves.mu.Unlock()
+
+	// The move outlives the request that started it, but not the engine.
+	moveCtx, cancel := context.WithCancel(context.Background())
+	closing := e.closing
+	e.wg.Add(2)
+	go func() {
+		defer e.wg.Done()
+		select {
+		case <-closing:
+			cancel()
+		case <-moveCtx.Done():
+		}
+	}()
+	go func() {
+		defer e.wg.Done()
+		defer cancel()
+		e.runSeriesMove(moveCtx, mv, pred)
+	}()
+	return nil
+}
+
+// runSeriesMove copies the data of a series move and deletes it from the
+// source bucket, recording the outcome.
+func (e *Engine) runSeriesMove(ctx context.Context, m influxdb.SeriesMove, pred influxdb.Predicate) {
+	log := e.logger.With(zap.String("move_id", m.ID.String()),
+		zap.String("source_bucket_id", m.SourceBucketID.String()),
+		zap.String("destination_bucket_id", m.DestinationBucketID.String()))
+	log.Info("Moving series")
+
+	series, err := e.moveSeries(ctx, m, pred)
+
+	now := e.timeGen.Now().UTC()
+	e.seriesMoves.update(m.ID, func(mv *influxdb.SeriesMove) {
+		mv.Series = series
+		mv.FinishedAt = &now
+		if err != nil {
+			mv.Status = influxdb.SeriesMoveFailed
+			mv.Error = err.Error()
+		} else {
+			mv.Status = influxdb.SeriesMoveCompleted
+		}
+	})
+
+	if err != nil {
+		log.Info("Unable to move series", zap.Error(err))
+		return
+	}
+	log.Info("Moved series", zap.Int("series", series))
+}
+
+func (e *Engine) moveSeries(ctx context.Context, m influxdb.SeriesMove, pred influxdb.Predicate) (int, error) {
+	src := tsdb.EncodeName(e.storageOrgID(m.OrgID, m.SourceBucketID), m.SourceBucketID)
+	dst := tsdb.EncodeName(e.storageOrgID(m.OrgID, m.DestinationBucketID), m.DestinationBucketID)
+
+	var tpred tsm1.Predicate
+	if pred != nil {
+		tpred = pred
+	}
+	collection, err := e.engine.CopyPrefix(ctx,
+		models.EscapeMeasurement(src[:]), models.EscapeMeasurement(dst[:]), tpred)
+	if err != nil {
+		return 0, err
+	}
+	e.cardinality.Record(collection)
+
+	// Once copied, the data is deleted even if the engine is closing, so that
+	// it is not left in both buckets.
+	err = e.DeleteBucketRangePredicate(context.Background(), m.OrgID, m.SourceBucketID, math.MinInt64, math.MaxInt64, pred)
+	return collection.Length(), err
+}
+
+// FindSeriesMoveByID returns the series move with id.
+func (e *Engine) FindSeriesMoveByID(ctx context.Context, id influxdb.ID) (*influxdb.SeriesMove, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.seriesMoves.mu.RLock()
+	defer e.seriesMoves.mu.RUnlock()
+	m, ok := e.seriesMoves.moves[id]
+	if !ok {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "series move not found",
+		}
+	}
+	mv := *m
+	return &mv, nil
+}
+
+// FindSeriesMoves returns the series moves matching filter, in the order they
+// were started.
+func (e *Engine) FindSeriesMoves(ctx context.Context, filter influxdb.SeriesMoveFilter) ([]*influxdb.SeriesMove, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.seriesMoves.mu.RLock()
+	defer e.seriesMoves.mu.RUnlock()
+	moves := make([]*influxdb.SeriesMove, 0, len(e.seriesMoves.moves))
+	for _, m := range e.seriesMoves.moves {
+		if filter.OrgID != nil && m.OrgID != *filter.OrgID {
+			continue
+		}
+		mv := *m
+		moves = append(moves, &mv)
+	}
+	sort.Slice(moves, func(i, j int) bool { return moves[i].ID < moves[j].ID })
+	return moves, nil
+}
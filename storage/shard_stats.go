@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// shardCounter accumulates the load on a single bucket.
+type shardCounter struct {
+	orgID influxdb.ID
+
+	// Counters for the interval currently being tracked.
+	points, bytes uint64
+	cursors       uint64
+	cursorTime    time.Duration
+
+	// Values captured when the last interval was completed.
+	pointsRate, bytesRate float64
+	lastCursors           uint64
+	cursorLatency         float64
+}
+
+// shardStatsTracker tracks the writes to, and cursors created for, each
+// bucket, so that the hottest shards can be found. The storage used by each
+// bucket is read from the engine when needed.
+type shardStatsTracker struct {
+	mu       sync.Mutex
+	counters map[influxdb.ID]*shardCounter
+	start    time.Time
+
+	metrics *shardMetrics
+	labels  prometheus.Labels
+}
+
+func newShardStatsTracker(defaultLabels prometheus.Labels) *shardStatsTracker {
+	mmu.Lock()
+	if sms == nil {
+		sms = newShardMetrics(defaultLabels)
+	}
+	mmu.Unlock()
+
+	return &shardStatsTracker{
+		counters: make(map[influxdb.ID]*shardCounter),
+		start:    time.Now(),
+		metrics:  sms,
+		labels:   defaultLabels,
+	}
+}
+
+// counter returns the counter for the bucket encoded in name, or nil if name
+// is not an encoded org and bucket. It must be called with t.mu held.
+func (t *shardStatsTracker) counter(name []byte) (influxdb.ID, *shardCounter) {
+	if len(name) != len(tsdb.EncodeName(0, 0)) {
+		return 0, nil
+	}
+	orgID, bucketID := tsdb.DecodeNameSlice(name)
+	c := t.counters[bucketID]
+	if c == nil {
+		c = &shardCounter{orgID: orgID}
+		t.counters[bucketID] = c
+	}
+	return bucketID, c
+}
+
+// bucketLabels returns the metric labels for a bucket. The metrics are shared
+// by all engines, so only the labels they were created with are set.
+func (t *shardStatsTracker) bucketLabels(bucketID influxdb.ID) prometheus.Labels {
+	labels := make(prometheus.Labels, len(t.metrics.labels)+1)
+	for k := range t.metrics.labels {
+		labels[k] = t.labels[k]
+	}
+	labels["bucket"] = bucketID.String()
+	return labels
+}
+
+// RecordWrite adds the points in collection to the tracked statistics.
+func (t *shardStatsTracker) RecordWrite(collection *tsdb.SeriesCollection) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	type write struct{ points, bytes uint64 }
+	writes := make(map[influxdb.ID]*write)
+
+	t.mu.Lock()
+	for iter := collection.Iterator(); iter.Next(); {
+		bucketID, c := t.counter(iter.Name())
+		if c == nil {
+			continue
+		}
+		sz := uint64(iter.Point().StringSize())
+		c.points++
+		c.bytes += sz
+
+		w := writes[bucketID]
+		if w == nil {
+			w = &write{}
+			writes[bucketID] = w
+		}
+		w.points++
+		w.bytes += sz
+	}
+	t.mu.Unlock()
+
+	for bucketID, w := range writes {
+		labels := t.bucketLabels(bucketID)
+		t.metrics.WritePoints.With(labels).Add(float64(w.points))
+		t.metrics.WriteBytes.With(labels).Add(float64(w.bytes))
+	}
+}
+
+// RecordCursor records that a cursor taking d to create was created for the
+// bucket encoded in name.
+func (t *shardStatsTracker) RecordCursor(name []byte, d time.Duration) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	t.mu.Lock()
+	bucketID, c := t.counter(name)
+	if c != nil {
+		c.cursors++
+		c.cursorTime += d
+	}
+	t.mu.Unlock()
+
+	if c != nil {
+		t.metrics.CursorDuration.With(t.bucketLabels(bucketID)).Observe(d.Seconds())
+	}
+}
+
+// Roll completes the current tracking interval at now, making its rates
+// available via Stats, and begins a new one. The storage gauges are updated
+// from storage.
+func (t *shardStatsTracker) Roll(now time.Time, storage map[influxdb.ID]*tsm1.BucketStorageStats) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	t.mu.Lock()
+	elapsed := now.Sub(t.start).Seconds()
+	for _, c := range t.counters {
+		c.pointsRate, c.bytesRate = 0, 0
+		if elapsed > 0 {
+			c.pointsRate = float64(c.points) / elapsed
+			c.bytesRate = float64(c.bytes) / elapsed
+		}
+		c.lastCursors, c.cursorLatency = c.cursors, 0
+		if c.cursors > 0 {
+			c.cursorLatency = (c.cursorTime / time.Duration(c.cursors)).Seconds()
+		}
+		c.points, c.bytes, c.cursors, c.cursorTime = 0, 0, 0, 0
+	}
+	t.start = now
+	t.mu.Unlock()
+
+	t.metrics.CacheBytes.Reset()
+	t.metrics.CompactionBacklog.Reset()
+	t.metrics.TombstonedBytes.Reset()
+	for bucketID, s := range storage {
+		labels := t.bucketLabels(bucketID)
+		t.metrics.CacheBytes.With(labels).Set(float64(s.CacheBytes))
+		t.metrics.CompactionBacklog.With(labels).Set(float64(s.UncompactedFiles))
+		t.metrics.TombstonedBytes.With(labels).Set(float64(s.TombstonedBytes))
+	}
+}
+
+// Stats returns the statistics of the shards matching filter, combining the
+// tracked load with storage, ranked in descending order.
+func (t *shardStatsTracker) Stats(filter influxdb.ShardStatsFilter, storage map[influxdb.ID]*tsm1.BucketStorageStats) ([]*influxdb.ShardStats, error) {
+	var less func(a, b *influxdb.ShardStats) bool
+	switch filter.SortBy {
+	case "", influxdb.ShardStatsSortWrites:
+		less = func(a, b *influxdb.ShardStats) bool {
+			if a.WritePointsPerSecond != b.WritePointsPerSecond {
+				return a.WritePointsPerSecond > b.WritePointsPerSecond
+			}
+			return a.WriteBytesPerSecond > b.WriteBytesPerSecond
+		}
+	case influxdb.ShardStatsSortCache:
+		less = func(a, b *influxdb.ShardStats) bool {
+			return a.CacheBytes > b.CacheBytes
+		}
+	case influxdb.ShardStatsSortCompactions:
+		less = func(a, b *influxdb.ShardStats) bool {
+			if a.CompactionBacklog != b.CompactionBacklog {
+				return a.CompactionBacklog > b.CompactionBacklog
+			}
+			return a.TSMFiles > b.TSMFiles
+		}
+	case influxdb.ShardStatsSortCursors:
+		less = func(a, b *influxdb.ShardStats) bool {
+			if a.CursorLatency != b.CursorLatency {
+				return a.CursorLatency > b.CursorLatency
+			}
+			return a.Cursors > b.Cursors
+		}
+	case influxdb.ShardStatsSortTombstones:
+		less = func(a, b *influxdb.ShardStats) bool {
+			return a.TombstonedBytes > b.TombstonedBytes
+		}
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown sort key %q", filter.SortBy),
+		}
+	}
+
+	if t == nil {
+		return nil, nil // Tracking disabled
+	}
+
+	byBucket := make(map[influxdb.ID]*influxdb.ShardStats)
+	get := func(orgID, bucketID influxdb.ID) *influxdb.ShardStats {
+		st := byBucket[bucketID]
+		if st == nil {
+			st = &influxdb.ShardStats{OrgID: orgID, BucketID: bucketID}
+			byBucket[bucketID] = st
+		}
+		return st
+	}
+
+	t.mu.Lock()
+	for bucketID, c := range t.counters {
+		st := get(c.orgID, bucketID)
+		st.WritePointsPerSecond = c.pointsRate
+		st.WriteBytesPerSecond = c.bytesRate
+		st.Cursors = c.lastCursors
+		st.CursorLatency = c.cursorLatency
+	}
+	t.mu.Unlock()
+
+	for bucketID, s := range storage {
+		st := get(s.OrgID, bucketID)
+		st.CacheBytes = s.CacheBytes
+		st.TSMFiles = s.Files
+		st.CompactionBacklog = s.UncompactedFiles
+		st.TombstonedBytes = s.TombstonedBytes
+	}
+
+	stats := make([]*influxdb.ShardStats, 0, len(byBucket))
+	for _, st := range byBucket {
+		if filter.OrgID != nil && *filter.OrgID != st.OrgID {
+			continue
+		}
+		if filter.BucketID != nil && *filter.BucketID != st.BucketID {
+			continue
+		}
+		stats = append(stats, st)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if less(a, b) {
+			return true
+		} else if less(b, a) {
+			return false
+		}
+		return a.BucketID < b.BucketID
+	})
+
+	if filter.Limit > 0 && len(stats) > filter.Limit {
+		stats = stats[:filter.Limit]
+	}
+	return stats, nil
+}
+
+// shardCursorIterator records the time taken to create each cursor with a
+// shardStatsTracker.
+type shardCursorIterator struct {
+	tsdb.CursorIterator
+	stats *shardStatsTracker
+}
+
+func (i *shardCursorIterator) Next(ctx context.Context, r *tsdb.CursorRequest) (tsdb.Cursor, error) {
+	start := time.Now()
+	cur, err := i.CursorIterator.Next(ctx, r)
+	i.stats.RecordCursor(r.Name, time.Since(start))
+	return cur, err
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestShardStatsTracker(t *testing.T) {
+	org, hot, cold := influxdb.ID(0x3131), influxdb.ID(0x3232), influxdb.ID(0x3333)
+	tracker := newShardStatsTracker(prometheus.Labels{})
+	start := tracker.start
+
+	tracker.RecordWrite(writeStatsCollection(t, org, hot, "cpu,host=a v=1 1\ncpu,host=b v=1 1\ncpu,host=c v=1 1\ncpu,host=d v=1 1"))
+	tracker.RecordWrite(writeStatsCollection(t, org, cold, "mem,host=a v=1 1\nmem,host=b v=1 1"))
+	name := tsdb.EncodeName(org, cold)
+	tracker.RecordCursor(name[:], 10*time.Millisecond)
+	tracker.RecordCursor(name[:], 30*time.Millisecond)
+
+	storage := map[influxdb.ID]*tsm1.BucketStorageStats{
+		cold: {OrgID: org, CacheBytes: 1024, Files: 3, UncompactedFiles: 2},
+	}
+	tracker.Roll(start.Add(2*time.Second), storage)
+
+	// Writes in the next interval are not reported until it completes.
+	tracker.RecordWrite(writeStatsCollection(t, org, cold, "mem,host=a v=2 2"))
+
+	stats, err := tracker.Stats(influxdb.ShardStatsFilter{}, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(stats), 2; got != exp {
+		t.Fatalf("got %d stats, expected %d", got, exp)
+	}
+	if got, exp := stats[0].BucketID, hot; got != exp {
+		t.Fatalf("got hottest bucket %v, expected %v", got, exp)
+	}
+	if got, exp := stats[0].WritePointsPerSecond, 2.0; got != exp {
+		t.Errorf("got %v points per second, expected %v", got, exp)
+	}
+
+	st := stats[1]
+	if st.OrgID != org || st.CacheBytes != 1024 || st.TSMFiles != 3 || st.CompactionBacklog != 2 {
+		t.Errorf("unexpected storage stats: %+v", st)
+	}
+	if got, exp := st.Cursors, uint64(2); got != exp {
+		t.Errorf("got %d cursors, expected %d", got, exp)
+	}
+	if got, exp := st.CursorLatency, 0.02; got != exp {
+		t.Errorf("got cursor latency %v, expected %v", got, exp)
+	}
+
+	stats, err = tracker.Stats(influxdb.ShardStatsFilter{SortBy: influxdb.ShardStatsSortCompactions, Limit: 1}, storage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].BucketID != cold {
+		t.Fatalf("unexpected stats sorted by compactions: %+v", stats)
+	}
+
+	if _, err := tracker.Stats(influxdb.ShardStatsFilter{SortBy: "bad"}, storage); err == nil {
+		t.Fatal("expected error for unknown sort key")
+	}
+}
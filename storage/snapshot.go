@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+// SnapshotManifestFilename is the name of the file holding the manifest of a
+// snapshot, within the snapshot directory.
+const SnapshotManifestFilename = "manifest.json"
+
+var _ influxdb.SnapshotService = (*Engine)(nil)
+
+// CreateSnapshot creates a snapshot of all TSM data in the Engine while it
+// continues to accept writes.
+//  1. Checkpoint the engine, writing the cache to TSM files.
+//  2. Create hard links to all TSM and tombstone files, in a new directory
+//     within the engine root directory. The set of files is taken under the
+//     file store lock, so it is never a mix of files from before and after a
+//     compaction.
+//  3. Write the manifest of the snapshot into that directory, and return it.
+//
+// The files of the snapshot are fetched with FetchBackupFile, like those of a
+// backup.
+func (e *Engine) CreateSnapshot(ctx context.Context) (*influxdb.SnapshotManifest, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	checkpointID, err := e.Checkpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	id, snapshotPath, err := e.engine.FileStore.CreateSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := writeSnapshotManifest(id, checkpointID, snapshotPath)
+	if err != nil {
+		_ = os.RemoveAll(snapshotPath)
+		return nil, err
+	}
+	span.LogKV("snapshot_id", id, "files", len(manifest.Files))
+	return manifest, nil
+}
+
+// writeSnapshotManifest lists the files of the snapshot in dir, and writes
+// them to the manifest file of the snapshot.
+func writeSnapshotManifest(id int, checkpointID influxdb.CheckpointID, dir string) (*influxdb.SnapshotManifest, error) {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &influxdb.SnapshotManifest{
+		ID:           id,
+		CheckpointID: checkpointID,
+		CreatedAt:    time.Now().UTC(),
+		Files:        make([]influxdb.SnapshotFile, 0, len(fileInfos)),
+	}
+	for _, fi := range fileInfos {
+		manifest.Files = append(manifest.Files, influxdb.SnapshotFile{
+			Name: fi.Name(),
+			Size: fi.Size(),
+		})
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, SnapshotManifestFilename), data, 0666); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -39,6 +39,7 @@ type walMetrics struct {
 	CurrentSegmentBytes *prometheus.GaugeVec
 	Segments            *prometheus.GaugeVec
 	Writes              *prometheus.CounterVec
+	SyncLatency         *prometheus.HistogramVec
 }
 
 // newWALMetrics initialises the prometheus metrics for tracking the WAL.
@@ -77,6 +78,13 @@ func newWALMetrics(labels prometheus.Labels) *walMetrics {
 			Name:      "writes_total",
 			Help:      "Number of writes to the WAL.",
 		}, writeNames),
+		SyncLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "sync_latency_seconds",
+			Help:      "Time from the first write to the WAL since the last fsync until the next fsync completes.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, names),
 	}
 }
 
@@ -87,5 +95,6 @@ func (m *walMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.CurrentSegmentBytes,
 		m.Segments,
 		m.Writes,
+		m.SyncLatency,
 	}
 }
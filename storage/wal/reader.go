@@ -1,6 +1,10 @@
 package wal
 
 import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
 	"os"
 	"sort"
 
@@ -9,9 +13,31 @@ import (
 
 // WALReader helps one read out the WAL into entries.
 type WALReader struct {
-	files  []string
-	logger *zap.Logger
-	r      *WALSegmentReader
+	files    []string
+	logger   *zap.Logger
+	r        *WALSegmentReader
+	dropped  func(WALEntry)
+	segments []SegmentRecovery
+}
+
+// SegmentRecovery describes the read of a segment file by a WALReader.
+type SegmentRecovery struct {
+	Path string
+	Size int64
+
+	// Entries is the number of entries read from the segment.
+	Entries int
+
+	// Dropped is the number of valid entries that followed a corrupt entry,
+	// and CorruptEntries the number of entries that could not be read. Both
+	// were truncated from the segment.
+	Dropped        int
+	CorruptEntries int
+
+	// Truncated is the number of bytes truncated from the segment and Err the
+	// error reading its first corrupt entry.
+	Truncated int64
+	Err       error
 }
 
 // NewWALReader constructs a WALReader over the given set of files.
@@ -27,6 +53,13 @@ func NewWALReader(files []string) *WALReader {
 // WithLogger sets the logger for the WALReader.
 func (r *WALReader) WithLogger(logger *zap.Logger) { r.logger = logger }
 
+// WithDropped sets fn to be called with each valid entry that is truncated
+// from a segment file because it follows a corrupt entry.
+func (r *WALReader) WithDropped(fn func(WALEntry)) { r.dropped = fn }
+
+// Segments returns a description of each segment file read so far.
+func (r *WALReader) Segments() []SegmentRecovery { return r.segments }
+
 // Read calls the callback with every entry in the WAL files. If, during
 // reading of a segment file, corruption is encountered, that segment file
 // is truncated up to and including the last valid byte, and processing
@@ -55,6 +88,9 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 	}
 	r.logger.Info("Reading file", zap.String("path", file), zap.Int64("size", stat.Size()))
 
+	r.segments = append(r.segments, SegmentRecovery{Path: file, Size: stat.Size()})
+	seg := &r.segments[len(r.segments)-1]
+
 	if stat.Size() == 0 {
 		return nil
 	}
@@ -71,6 +107,10 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 		if err != nil {
 			n := r.r.Count()
 			r.logger.Info("File corrupt", zap.Error(err), zap.String("path", file), zap.Int64("pos", n))
+			seg.Err, seg.Truncated = err, stat.Size()-n
+			if err := r.scanTruncated(f, n, seg); err != nil {
+				return err
+			}
 			if err := f.Truncate(n); err != nil {
 				return err
 			}
@@ -80,7 +120,60 @@ func (r *WALReader) readFile(file string, cb func(WALEntry) error) error {
 		if err := cb(entry); err != nil {
 			return err
 		}
+		seg.Entries++
 	}
 
 	return r.r.Close()
 }
+
+// scanTruncated counts the entries of f from the corrupt entry at pos to the
+// end of the file, which are about to be truncated. The valid entries are
+// passed to the dropped callback. The scan stops at the first entry whose
+// length runs past the end of the file.
+func (r *WALReader) scanTruncated(f *os.File, pos int64, seg *SegmentRecovery) error {
+	buf := make([]byte, seg.Size-pos)
+	if _, err := f.ReadAt(buf, pos); err != nil && err != io.EOF {
+		return err
+	}
+
+	// The corrupt entry itself.
+	seg.CorruptEntries++
+	buf = skipEntry(buf)
+
+	sr := NewWALSegmentReader(ioutil.NopCloser(nil))
+	for len(buf) > 0 {
+		next := skipEntry(buf)
+		if next == nil {
+			seg.CorruptEntries++
+			break
+		}
+
+		sr.Reset(ioutil.NopCloser(bytes.NewReader(buf[:len(buf)-len(next)])))
+		if !sr.Next() {
+			break
+		}
+		if entry, err := sr.Read(); err != nil {
+			seg.CorruptEntries++
+		} else {
+			seg.Dropped++
+			if r.dropped != nil {
+				r.dropped(entry)
+			}
+		}
+		buf = next
+	}
+	return nil
+}
+
+// skipEntry returns the data following the encoded entry at the start of b,
+// or nil if its length runs past the end of b.
+func skipEntry(b []byte) []byte {
+	if len(b) < 5 {
+		return nil
+	}
+	n := uint64(binary.BigEndian.Uint32(b[1:5])) + 5
+	if n > uint64(len(b)) {
+		return nil
+	}
+	return b[n:]
+}
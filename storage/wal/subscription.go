@@ -0,0 +1,350 @@
+package wal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// subscriptionsFile is the name of the file in the WAL directory holding the
+// offsets acknowledged by each subscription.
+const subscriptionsFile = "subscriptions.json"
+
+var (
+	// ErrWALDisabled is returned when subscribing to a disabled WAL.
+	ErrWALDisabled = errors.New("WAL disabled")
+
+	// ErrSubscriptionNotFound is returned when acknowledging an offset of a
+	// subscription that is not registered.
+	ErrSubscriptionNotFound = errors.New("WAL subscription not found")
+)
+
+// An Offset is the position of an entry in the WAL.
+type Offset struct {
+	SegmentID int   `json:"segmentID"`
+	Position  int64 `json:"position"`
+}
+
+// Less returns true if o is before other.
+func (o Offset) Less(other Offset) bool {
+	if o.SegmentID != other.SegmentID {
+		return o.SegmentID < other.SegmentID
+	}
+	return o.Position < other.Position
+}
+
+// Subscribe registers the subscription with name, or resumes it from its last
+// acknowledged offset if it is already registered. A new subscription starts
+// with the next entry written to the WAL.
+//
+// The WAL retains the segments holding entries that a registered subscription
+// has not acknowledged, whether or not it is being consumed, until the
+// subscription is removed with Unsubscribe.
+func (l *WAL) Subscribe(name string) (*Subscription, error) {
+	if !l.enabled {
+		return nil, ErrWALDisabled
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-l.closing:
+		return nil, ErrWALClosed
+	default:
+	}
+
+	off, ok := l.subscriptions[name]
+	if !ok {
+		off = l.endOffset()
+		l.subscriptions[name] = off
+		if err := l.saveSubscriptions(); err != nil {
+			delete(l.subscriptions, name)
+			return nil, err
+		}
+	}
+	return &Subscription{wal: l, name: name, pos: off}, nil
+}
+
+// Unsubscribe removes the subscription with name, releasing the segments
+// retained for it.
+func (l *WAL) Unsubscribe(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.subscriptions[name]; !ok {
+		return ErrSubscriptionNotFound
+	}
+	delete(l.subscriptions, name)
+	if err := l.saveSubscriptions(); err != nil {
+		return err
+	}
+	return l.releaseSegments()
+}
+
+// Subscriptions returns the offset acknowledged by each registered
+// subscription.
+func (l *WAL) Subscriptions() map[string]Offset {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	subs := make(map[string]Offset, len(l.subscriptions))
+	for name, off := range l.subscriptions {
+		subs[name] = off
+	}
+	return subs
+}
+
+// ack records that the subscription with name has consumed the entries before
+// off, and removes the segments no longer retained for any subscription.
+func (l *WAL) ack(name string, off Offset) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev, ok := l.subscriptions[name]
+	if !ok {
+		return ErrSubscriptionNotFound
+	} else if !prev.Less(off) {
+		return nil
+	}
+	l.subscriptions[name] = off
+	if err := l.saveSubscriptions(); err != nil {
+		return err
+	}
+	return l.releaseSegments()
+}
+
+// endOffset returns the offset of the next entry written to the WAL. Callers
+// must ensure a lock on the WAL is obtained.
+func (l *WAL) endOffset() Offset {
+	if l.currentSegmentWriter == nil {
+		return Offset{SegmentID: l.currentSegmentID + 1}
+	}
+	return Offset{SegmentID: l.currentSegmentID, Position: l.syncedSize}
+}
+
+// minSubscriptionSegment returns the ID of the oldest segment a subscription
+// has not fully acknowledged, and false if there are no subscriptions.
+func (l *WAL) minSubscriptionSegment() (int, bool) {
+	var (
+		min int
+		ok  bool
+	)
+	for _, off := range l.subscriptions {
+		if !ok || off.SegmentID < min {
+			min, ok = off.SegmentID, true
+		}
+	}
+	return min, ok
+}
+
+// retained returns true if the segment file fn is retained for a subscription,
+// in which case it is removed once every subscription has acknowledged it.
+// Callers must ensure a write lock on the WAL is obtained.
+func (l *WAL) retained(fn string) bool {
+	min, ok := l.minSubscriptionSegment()
+	if !ok {
+		return false
+	}
+	id, err := idFromFileName(fn)
+	if err != nil || id < min {
+		return false
+	}
+	l.released[id] = fn
+	return true
+}
+
+// releaseSegments removes the released segments no longer retained for any
+// subscription. Callers must ensure a write lock on the WAL is obtained.
+func (l *WAL) releaseSegments() error {
+	min, ok := l.minSubscriptionSegment()
+	var n int
+	for id, fn := range l.released {
+		if ok && id >= min {
+			continue
+		}
+		if err := os.RemoveAll(fn); err != nil {
+			return err
+		}
+		delete(l.released, id)
+		n++
+	}
+	if n == 0 || l.tracker == nil {
+		return nil
+	}
+	return l.updateSegmentStats()
+}
+
+// loadSubscriptions reads the subscriptions registered in the WAL directory.
+func (l *WAL) loadSubscriptions() error {
+	l.subscriptions = make(map[string]Offset)
+	l.released = make(map[int]string)
+
+	b, err := ioutil.ReadFile(filepath.Join(l.path, subscriptionsFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &l.subscriptions); err != nil {
+		return fmt.Errorf("error reading WAL subscriptions: %v", err)
+	}
+	return nil
+}
+
+// saveSubscriptions writes the registered subscriptions to the WAL directory.
+func (l *WAL) saveSubscriptions() error {
+	b, err := json.Marshal(l.subscriptions)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(l.path, subscriptionsFile)
+	if err := ioutil.WriteFile(path+".tmp", b, 0666); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// notifySynced wakes the subscriptions waiting for entries. Callers must ensure
+// a write lock on the WAL is obtained.
+func (l *WAL) notifySynced() {
+	close(l.synced)
+	l.synced = make(chan struct{})
+}
+
+// readyC is a closed channel, for subscriptions that need not wait.
+var readyC = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// A Subscription reads the entries written to the WAL once they are fsynced.
+// It is not safe for concurrent use.
+type Subscription struct {
+	wal  *WAL
+	name string
+	pos  Offset
+
+	r       *WALSegmentReader
+	start   int64 // position of the first entry of r
+	limited bool  // r stops at the fsynced size of the current segment
+}
+
+// Name returns the name of the subscription.
+func (s *Subscription) Name() string { return s.name }
+
+// Next returns the next entry of the WAL and the offset following it, waiting
+// for the entry to be written and fsynced or ctx to be done.
+func (s *Subscription) Next(ctx context.Context) (WALEntry, Offset, error) {
+	for {
+		if s.r == nil {
+			wait, err := s.open()
+			if err != nil {
+				return nil, Offset{}, err
+			} else if wait != nil {
+				select {
+				case <-wait:
+				case <-ctx.Done():
+					return nil, Offset{}, ctx.Err()
+				}
+				continue
+			}
+		}
+
+		if !s.r.Next() {
+			limited := s.limited
+			s.closeReader()
+			if !limited {
+				// The segment is complete.
+				s.pos = Offset{SegmentID: s.pos.SegmentID + 1}
+			}
+			continue
+		}
+
+		entry, err := s.r.Read()
+		if err != nil {
+			s.closeReader()
+			return nil, Offset{}, fmt.Errorf("error reading WAL segment %d at %d: %v", s.pos.SegmentID, s.pos.Position, err)
+		}
+		s.pos.Position = s.start + s.r.Count()
+		return entry, s.pos, nil
+	}
+}
+
+// open opens a reader of the segment at the position of s, or returns a
+// channel to wait on if there are no entries to read.
+func (s *Subscription) open() (<-chan struct{}, error) {
+	l := s.wal
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	select {
+	case <-l.closing:
+		return nil, ErrWALClosed
+	default:
+	}
+
+	limit := int64(-1)
+	if s.pos.SegmentID > l.currentSegmentID || (s.pos.SegmentID == l.currentSegmentID && l.currentSegmentWriter == nil) {
+		// The segment has yet to be created.
+		return l.synced, nil
+	} else if s.pos.SegmentID == l.currentSegmentID {
+		if s.pos.Position >= l.syncedSize {
+			return l.synced, nil
+		}
+		limit = l.syncedSize
+	}
+
+	f, err := os.Open(segmentFileName(l.path, s.pos.SegmentID))
+	if os.IsNotExist(err) && limit < 0 {
+		// The segment was removed before the subscription was registered.
+		s.pos = Offset{SegmentID: s.pos.SegmentID + 1}
+		return readyC, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(s.pos.Position, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit-s.pos.Position)
+	}
+	s.r = NewWALSegmentReader(readCloser{Reader: r, Closer: f})
+	s.start, s.limited = s.pos.Position, limit >= 0
+	return nil, nil
+}
+
+func (s *Subscription) closeReader() {
+	if s.r != nil {
+		s.r.Close()
+		s.r = nil
+	}
+}
+
+// Ack acknowledges that the entries before off have been consumed, so that
+// the subscription resumes from off and the segments before it are no longer
+// retained.
+func (s *Subscription) Ack(off Offset) error {
+	return s.wal.ack(s.name, off)
+}
+
+// Close closes the subscription. It remains registered with the WAL.
+func (s *Subscription) Close() error {
+	s.closeReader()
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
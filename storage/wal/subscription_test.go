@@ -0,0 +1,125 @@
+package wal
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/value"
+)
+
+func TestWAL_Subscribe(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer func() { w.Close() }()
+
+	sub, err := w.Subscribe("export")
+	if err != nil {
+		t.Fatalf("error subscribing: %v", err)
+	}
+	defer sub.Close()
+
+	write := func(v float64) {
+		t.Helper()
+		if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+			"cpu,host=A#!~#value": []value.Value{value.NewValue(1, v)},
+		}); err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	}
+	next := func(sub *Subscription, exp float64) Offset {
+		t.Helper()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		entry, off, err := sub.Next(ctx)
+		if err != nil {
+			t.Fatalf("error reading subscription: %v", err)
+		}
+		we, ok := entry.(*WriteWALEntry)
+		if !ok {
+			t.Fatalf("unexpected entry %T", entry)
+		}
+		if got := we.Values["cpu,host=A#!~#value"][0].Value(); got != exp {
+			t.Fatalf("got value %v, expected %v", got, exp)
+		}
+		return off
+	}
+
+	write(1.1)
+	write(2.2)
+	off1 := next(sub, 1.1)
+	off2 := next(sub, 2.2)
+	if !off1.Less(off2) {
+		t.Fatalf("got offset %+v after %+v", off2, off1)
+	}
+
+	// No more entries have been written.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := sub.Next(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("got error %v, expected %v", err, context.DeadlineExceeded)
+	}
+
+	// The closed segment is retained until the subscription acknowledges it.
+	if err := w.CloseSegment(); err != nil {
+		t.Fatal(err)
+	}
+	closed, err := w.ClosedSegments()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(closed) != 1 {
+		t.Fatalf("got closed segments %v", closed)
+	}
+	if err := w.Remove(context.Background(), closed); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(closed[0]); err != nil {
+		t.Fatalf("retained segment: %v", err)
+	}
+	if err := sub.Ack(off1); err != nil {
+		t.Fatal(err)
+	}
+
+	write(3.3)
+	off3 := next(sub, 3.3)
+	if off3.SegmentID != off2.SegmentID+1 {
+		t.Fatalf("got offset %+v in the segment after %+v", off3, off2)
+	}
+	if err := sub.Ack(off3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(closed[0]); !os.IsNotExist(err) {
+		t.Fatalf("released segment: %v", err)
+	}
+
+	// The subscription resumes from its acknowledged offset.
+	write(4.4)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w = NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	if got, exp := w.Subscriptions(), map[string]Offset{"export": off3}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got subscriptions %+v, expected %+v", got, exp)
+	}
+	sub, err = w.Subscribe("export")
+	if err != nil {
+		t.Fatalf("error subscribing: %v", err)
+	}
+	next(sub, 4.4)
+
+	if err := w.Unsubscribe("export"); err != nil {
+		t.Fatal(err)
+	} else if err := sub.Ack(off3); err != ErrSubscriptionNotFound {
+		t.Fatalf("got error %v, expected %v", err, ErrSubscriptionNotFound)
+	}
+}
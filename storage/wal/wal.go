@@ -23,6 +23,8 @@ import (
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/fault"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/pool"
 	"github.com/influxdata/influxdb/tsdb/value"
@@ -63,6 +65,10 @@ const (
 
 	// DeleteBucketRangeWALEntryType indicates a delete bucket range entry.
 	DeleteBucketRangeWALEntryType WalEntryType = 0x04
+
+	// encryptedWALEntryFlag is set in the type of an entry whose compressed
+	// data is sealed by an encryption.Cipher.
+	encryptedWALEntryFlag WalEntryType = 0x80
 )
 
 var (
@@ -83,6 +89,7 @@ type WAL struct {
 	// goroutines waiting for the next fsync
 	syncCount   uint64
 	syncWaiters chan chan error
+	syncNow     chan struct{} // signals the scheduled fsync to run before its delay
 
 	mu            sync.RWMutex
 	lastWriteTime time.Time
@@ -94,6 +101,19 @@ type WAL struct {
 	currentSegmentID     int
 	currentSegmentWriter *WALSegmentWriter
 
+	// segmentBase is the size of the current segment when it was opened, and
+	// syncedSize the size of the current segment up to the last fsync. synced
+	// is closed and replaced whenever syncedSize or the current segment change.
+	segmentBase int64
+	syncedSize  int64
+	synced      chan struct{}
+
+	// subscriptions holds the offset acknowledged by each subscription, and
+	// released the segments removed from the WAL but retained until every
+	// subscription has acknowledged them.
+	subscriptions map[string]Offset
+	released      map[int]string
+
 	// cache and flush variables
 	once    sync.Once
 	closing chan struct{}
@@ -103,6 +123,16 @@ type WAL struct {
 	// is opened if a non-default value is required.
 	syncDelay time.Duration
 
+	// syncBatchBytes sets the number of bytes written since the last fsync
+	// that causes an fsync before syncDelay has passed. A value of 0 (default)
+	// only fsyncs after syncDelay.
+	syncBatchBytes int
+
+	// syncBytes and syncStart are the number of bytes written since the last
+	// fsync and the time of the first of those writes.
+	syncBytes int
+	syncStart time.Time
+
 	// WALOutput is the writer used by the logger.
 	logger *zap.Logger // Logger to be used for important messages
 
@@ -126,8 +156,13 @@ func NewWAL(path string) *WAL {
 		SegmentSize: DefaultSegmentSize,
 		closing:     make(chan struct{}),
 		syncWaiters: make(chan chan error, 1024),
+		syncNow:     make(chan struct{}, 1),
+		synced:      make(chan struct{}),
 		limiter:     limiter.NewFixed(defaultWaitingWALWrites),
 		logger:      logger,
+
+		subscriptions: make(map[string]Offset),
+		released:      make(map[int]string),
 	}
 }
 
@@ -136,6 +171,13 @@ func (l *WAL) WithFsyncDelay(delay time.Duration) {
 	l.syncDelay = delay
 }
 
+// WithFsyncBatchBytes sets the number of bytes written since the last fsync
+// that causes an fsync without waiting for the fsync delay. It should be called
+// before the WAL is opened.
+func (l *WAL) WithFsyncBatchBytes(n int) {
+	l.syncBatchBytes = n
+}
+
 // SetEnabled sets if the WAL is enabled and should be called before the WAL is opened.
 func (l *WAL) SetEnabled(enabled bool) {
 	l.enabled = enabled
@@ -223,12 +265,17 @@ func (l *WAL) Open(ctx context.Context) error {
 				return err
 			}
 			l.currentSegmentWriter = NewWALSegmentWriter(fd)
+			l.segmentBase, l.syncedSize = stat.Size(), stat.Size()
 
 			// Reset the current segment size stat
 			l.tracker.SetCurrentSegmentSize(uint64(stat.Size()))
 		}
 	}
 
+	if err := l.loadSubscriptions(); err != nil {
+		return err
+	}
+
 	var totalOldDiskSize int64
 	for _, seg := range segments {
 		stat, err := os.Stat(seg)
@@ -279,19 +326,21 @@ func (l *WAL) scheduleSync() {
 		for {
 			select {
 			case <-timerCh:
-				l.mu.Lock()
-				if len(l.syncWaiters) == 0 {
-					atomic.StoreUint64(&l.syncCount, 0)
-					l.mu.Unlock()
-					return
-				}
-
-				l.sync()
-				l.mu.Unlock()
+			case <-l.syncNow:
 			case <-l.closing:
 				atomic.StoreUint64(&l.syncCount, 0)
 				return
 			}
+
+			l.mu.Lock()
+			if len(l.syncWaiters) == 0 {
+				atomic.StoreUint64(&l.syncCount, 0)
+				l.mu.Unlock()
+				return
+			}
+
+			l.sync()
+			l.mu.Unlock()
 		}
 	}()
 }
@@ -300,6 +349,14 @@ func (l *WAL) scheduleSync() {
 // a write lock on the WAL is obtained before calling sync.
 func (l *WAL) sync() {
 	err := l.currentSegmentWriter.sync()
+	if err == nil {
+		l.syncedSize = l.segmentBase + int64(l.currentSegmentWriter.size)
+		l.notifySynced()
+	}
+	if l.syncBytes > 0 {
+		l.tracker.ObserveSyncLatency(time.Since(l.syncStart))
+		l.syncBytes = 0
+	}
 	for len(l.syncWaiters) > 0 {
 		errC := <-l.syncWaiters
 		errC <- err
@@ -382,10 +439,17 @@ func (l *WAL) Remove(ctx context.Context, files []string) error {
 
 	for i, fn := range files {
 		span.LogKV(fmt.Sprintf("path-%d", i), fn)
+		if l.retained(fn) {
+			continue
+		}
 		os.RemoveAll(fn)
 	}
+	return l.updateSegmentStats()
+}
 
-	// Refresh the on-disk size stats
+// updateSegmentStats refreshes the on-disk size stats. Callers must ensure a
+// write lock on the WAL is obtained.
+func (l *WAL) updateSegmentStats() error {
 	segments, err := SegmentFileNames(l.path)
 	if err != nil {
 		return err
@@ -434,6 +498,15 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 	compressed := snappy.Encode(encBuf, b)
 	bytesPool.Put(bytes)
 
+	entryType := entry.Type()
+	if cipher := encryption.Default(); cipher != nil {
+		if compressed, err = cipher.Seal(nil, compressed); err != nil {
+			bytesPool.Put(encBuf)
+			return -1, fmt.Errorf("error encrypting WAL entry: %v", err)
+		}
+		entryType |= encryptedWALEntryFlag
+	}
+
 	syncErr := make(chan error)
 
 	segID, err := func() (int, error) {
@@ -453,7 +526,7 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 		}
 
 		// write and sync
-		if err := l.currentSegmentWriter.Write(entry.Type(), compressed); err != nil {
+		if err := l.currentSegmentWriter.Write(entryType, compressed); err != nil {
 			return -1, fmt.Errorf("error writing WAL entry: %v", err)
 		}
 
@@ -462,6 +535,16 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 		default:
 			return -1, fmt.Errorf("error syncing wal")
 		}
+		if l.syncBytes == 0 {
+			l.syncStart = time.Now()
+		}
+		l.syncBytes += len(compressed)
+		if l.syncBatchBytes > 0 && l.syncBytes >= l.syncBatchBytes {
+			select {
+			case l.syncNow <- struct{}{}:
+			default:
+			}
+		}
 		l.scheduleSync()
 
 		// Update stats for current segment size
@@ -556,6 +639,7 @@ func (l *WAL) Close() error {
 
 		// Close, but don't set to nil so future goroutines can still be signaled
 		close(l.closing)
+		l.notifySynced()
 
 		if l.currentSegmentWriter != nil {
 			l.sync()
@@ -589,12 +673,13 @@ func (l *WAL) newSegmentFile() error {
 		l.tracker.SetOldSegmentSize(uint64(l.currentSegmentWriter.size))
 	}
 
-	fileName := filepath.Join(l.path, fmt.Sprintf("%s%05d.%s", WALFilePrefix, l.currentSegmentID, WALFileExtension))
-	fd, err := os.OpenFile(fileName, os.O_CREATE|os.O_RDWR, 0666)
+	fd, err := os.OpenFile(segmentFileName(l.path, l.currentSegmentID), os.O_CREATE|os.O_RDWR, 0666)
 	if err != nil {
 		return err
 	}
 	l.currentSegmentWriter = NewWALSegmentWriter(fd)
+	l.segmentBase, l.syncedSize = 0, 0
+	l.notifySynced()
 	l.tracker.IncSegments()
 
 	// Reset the current segment size stat
@@ -666,6 +751,11 @@ func (t *walTracker) SetCurrentSegmentSize(sz uint64) {
 func (t *walTracker) CurrentSegmentSize() uint64 { return atomic.LoadUint64(&t.oldSegmentBytes) }
 
 // SetSegments sets the number of segments files on disk.
+// ObserveSyncLatency records the time a batch of writes waited to be fsynced.
+func (t *walTracker) ObserveSyncLatency(d time.Duration) {
+	t.metrics.SyncLatency.With(t.labels).Observe(d.Seconds())
+}
+
 func (t *walTracker) SetSegments(sz uint64) {
 	labels := t.labels
 	t.metrics.Segments.With(labels).Set(float64(sz))
@@ -1089,6 +1179,10 @@ func (w *WALSegmentWriter) path() string {
 
 // Write writes entryType and the buffer containing compressed entry data.
 func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) error {
+	if err := fault.Err(fault.WALWrite); err != nil {
+		return err
+	}
+
 	var buf [5]byte
 	buf[0] = byte(entryType)
 	binary.BigEndian.PutUint32(buf[1:5], uint32(len(compressed)))
@@ -1097,6 +1191,16 @@ func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) erro
 		return err
 	}
 
+	// A torn write leaves a partial entry at the end of the segment, as a
+	// crash part way through the write would.
+	if torn := fault.Torn(fault.WALWrite, compressed); len(torn) < len(compressed) {
+		if _, err := w.bw.Write(torn); err != nil {
+			return err
+		}
+		w.size += len(buf) + len(torn)
+		return fmt.Errorf("torn write: %w", fault.ErrInjected)
+	}
+
 	if _, err := w.bw.Write(compressed); err != nil {
 		return err
 	}
@@ -1113,6 +1217,10 @@ func (w *WALSegmentWriter) sync() error {
 		return err
 	}
 
+	if err := fault.Err(fault.WALSync); err != nil {
+		return err
+	}
+
 	if f, ok := w.w.(*os.File); ok {
 		return f.Sync()
 	}
@@ -1188,7 +1296,21 @@ func (r *WALSegmentReader) Next() bool {
 	}
 	nReadOK += n
 
-	decLen, err := snappy.DecodedLen(b[:length])
+	compressed := b[:length]
+	if WalEntryType(entryType)&encryptedWALEntryFlag != 0 {
+		cipher := encryption.Default()
+		if cipher == nil {
+			r.err = fmt.Errorf("unable to decrypt wal entry: no encryption keys configured")
+			return true
+		}
+		if compressed, err = cipher.Open(nil, compressed); err != nil {
+			r.err = fmt.Errorf("unable to decrypt wal entry: %v", err)
+			return true
+		}
+		entryType &^= byte(encryptedWALEntryFlag)
+	}
+
+	decLen, err := snappy.DecodedLen(compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1196,7 +1318,7 @@ func (r *WALSegmentReader) Next() bool {
 	decBuf := *(getBuf(decLen))
 	defer putBuf(&decBuf)
 
-	data, err := snappy.Decode(decBuf, b[:length])
+	data, err := snappy.Decode(decBuf, compressed)
 	if err != nil {
 		r.err = err
 		return true
@@ -1253,6 +1375,11 @@ func (r *WALSegmentReader) Close() error {
 	return err
 }
 
+// segmentFileName returns the path of the segment file with id in dir.
+func segmentFileName(dir string, id int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%05d.%s", WALFilePrefix, id, WALFileExtension))
+}
+
 // idFromFileName parses the segment file ID from its name.
 func idFromFileName(name string) (int, error) {
 	parts := strings.Split(filepath.Base(name), ".")
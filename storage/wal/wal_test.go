@@ -4,14 +4,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/snappy"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/fault"
 	"github.com/influxdata/influxdb/tsdb/value"
 )
 
@@ -308,6 +312,164 @@ func TestWAL_ClosedSegments(t *testing.T) {
 	}
 }
 
+func TestWAL_FsyncBatchBytes(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	// Writes would wait an hour for their fsync without the batch limit.
+	w := NewWAL(dir)
+	w.WithFsyncDelay(time.Hour)
+	w.WithFsyncBatchBytes(1)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+			"cpu,host=A#!~#value": []value.Value{
+				value.NewValue(1, 1.1),
+			},
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the write to be fsynced")
+	}
+}
+
+func TestWAL_Encryption(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	keys, err := encryption.NewKeyRing(map[uint32][]byte{1: make([]byte, encryption.KeySize)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	encryption.SetDefault(encryption.NewCipher(keys))
+	defer encryption.SetDefault(nil)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+		"cpu,host=A#!~#value": []value.Value{
+			value.NewValue(1, "secret"),
+		},
+	}); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("error closing wal: %v", err)
+	}
+
+	paths, err := SegmentFileNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 1 {
+		t.Fatalf("unexpected segments: %v", paths)
+	}
+	data, err := ioutil.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if data[0]&byte(encryptedWALEntryFlag) == 0 {
+		t.Fatalf("expected encrypted entry, got type %x", data[0])
+	}
+
+	read := func() (WALEntry, error) {
+		f, err := os.Open(paths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewWALSegmentReader(f)
+		defer r.Close()
+		if !r.Next() {
+			t.Fatal("expected next, got false")
+		}
+		return r.Read()
+	}
+
+	entry, err := read()
+	if err != nil {
+		t.Fatalf("error reading entry: %v", err)
+	}
+	we, ok := entry.(*WriteWALEntry)
+	if !ok {
+		t.Fatalf("expected WriteWALEntry: got %#v", entry)
+	}
+	if got := we.Values["cpu,host=A#!~#value"]; len(got) != 1 || got[0].Value() != "secret" {
+		t.Fatalf("unexpected values: %v", got)
+	}
+
+	// Entries can not be read without the key.
+	encryption.SetDefault(nil)
+	if _, err := read(); err == nil {
+		t.Fatal("expected error reading encrypted entry without keys")
+	}
+}
+
+// Ensures the entries written before a torn write can be read back.
+func TestWALSegmentWriter_TornWrite(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+	w := NewWALSegmentWriter(f)
+
+	entry := &WriteWALEntry{
+		Values: map[string][]value.Value{
+			"cpu,host=A#!~#value": []value.Value{value.NewValue(1, 1.1)},
+		},
+	}
+	if err := w.Write(mustMarshalEntry(entry)); err != nil {
+		fatal(t, "write points", err)
+	}
+
+	injector, err := fault.NewInjector(fault.Fault{Point: fault.WALWrite, Kind: fault.KindTorn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fault.Enable(injector)
+	err = w.Write(mustMarshalEntry(entry))
+	fault.Enable(nil)
+	if err == nil {
+		t.Fatal("expected torn write to fail")
+	}
+
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		fatal(t, "seek", err)
+	}
+
+	r := NewWALSegmentReader(f)
+	if !r.Next() {
+		t.Fatal("expected next, got false")
+	}
+	if _, err := r.Read(); err != nil {
+		fatal(t, "read entry", err)
+	}
+	valid := r.Count()
+
+	if !r.Next() {
+		t.Fatal("expected next, got false")
+	}
+	if _, err := r.Read(); err == nil {
+		t.Fatal("expected error reading torn entry")
+	}
+	if got := r.Count(); got != valid {
+		t.Fatalf("got valid size %d, expected %d", got, valid)
+	}
+}
+
 func TestWALWriter_Corrupt(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -365,6 +527,89 @@ func TestWALWriter_Corrupt(t *testing.T) {
 	}
 }
 
+func TestWALReader_Corrupt(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+	defer f.Close()
+	w := NewWALSegmentWriter(f)
+
+	first := &WriteWALEntry{
+		Values: map[string][]value.Value{
+			"cpu,host=A#!~#value": []value.Value{value.NewValue(1, 1.1)},
+		},
+	}
+	if err := w.Write(mustMarshalEntry(first)); err != nil {
+		fatal(t, "write points", err)
+	}
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+	valid := MustReadFileSize(f)
+
+	// An entry that is not valid snappy data, followed by a valid entry and
+	// a torn entry header.
+	if _, err := f.Write([]byte{1, 0, 0, 0, 4, 0xff, 0xff, 0xff, 0xff}); err != nil {
+		fatal(t, "corrupt WAL segment", err)
+	}
+	second := &WriteWALEntry{
+		Values: map[string][]value.Value{
+			"cpu,host=B#!~#value": []value.Value{value.NewValue(2, 2.2)},
+		},
+	}
+	if err := w.Write(mustMarshalEntry(second)); err != nil {
+		fatal(t, "write points", err)
+	}
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+	if _, err := f.Write([]byte{1, 0}); err != nil {
+		fatal(t, "corrupt WAL segment", err)
+	}
+	size := MustReadFileSize(f)
+
+	var read, dropped []WALEntry
+	r := NewWALReader([]string{f.Name()})
+	r.WithDropped(func(entry WALEntry) { dropped = append(dropped, entry) })
+	if err := r.Read(func(entry WALEntry) error {
+		read = append(read, entry)
+		return nil
+	}); err != nil {
+		fatal(t, "read WAL", err)
+	}
+
+	if len(read) != 1 || read[0].(*WriteWALEntry).Values["cpu,host=A#!~#value"] == nil {
+		t.Fatalf("unexpected entries read: %v", read)
+	}
+	if len(dropped) != 1 || dropped[0].(*WriteWALEntry).Values["cpu,host=B#!~#value"] == nil {
+		t.Fatalf("unexpected entries dropped: %v", dropped)
+	}
+
+	segs := r.Segments()
+	if len(segs) != 1 {
+		t.Fatalf("got %d segments, expected 1", len(segs))
+	}
+	seg := segs[0]
+	if seg.Err == nil {
+		t.Fatal("expected corruption error")
+	}
+	seg.Err = nil
+	if exp := (SegmentRecovery{
+		Path:           f.Name(),
+		Size:           size,
+		Entries:        1,
+		Dropped:        1,
+		CorruptEntries: 2,
+		Truncated:      size - valid,
+	}); seg != exp {
+		t.Fatalf("got segment %+v, expected %+v", seg, exp)
+	}
+
+	if n := MustReadFileSize(f); n != valid {
+		t.Fatalf("got truncated size %d, expected %d", n, valid)
+	}
+}
+
 // Reproduces a `panic: runtime error: makeslice: cap out of range` when run with
 // GOARCH=386 go test -run TestWALSegmentReader_Corrupt -v ./tsdb/engine/tsm1/
 func TestWALSegmentReader_Corrupt(t *testing.T) {
@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// WALRecoveryReport returns the report of the replay of the WAL when the
+// engine was last opened.
+func (e *Engine) WALRecoveryReport(ctx context.Context) (*influxdb.WALRecoveryReport, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	if e.walRecovery == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "the WAL is disabled",
+		}
+	}
+
+	report := *e.walRecovery
+	report.Segments = append([]influxdb.WALSegmentRecovery(nil), report.Segments...)
+	report.Buckets = append([]influxdb.WALBucketRecovery(nil), report.Buckets...)
+	return &report, nil
+}
+
+// walRecovery counts the points of each bucket replayed from the WAL.
+type walRecovery struct {
+	buckets map[[16]byte]*influxdb.WALBucketRecovery
+}
+
+func newWALRecovery() *walRecovery {
+	return &walRecovery{buckets: make(map[[16]byte]*influxdb.WALBucketRecovery)}
+}
+
+// bucket returns the counts of the bucket the point with name is written to.
+func (r *walRecovery) bucket(name []byte) *influxdb.WALBucketRecovery {
+	var key [16]byte
+	copy(key[:], name)
+	b := r.buckets[key]
+	if b == nil {
+		org, bucket := tsdb.DecodeName(key)
+		b = &influxdb.WALBucketRecovery{OrgID: org, BucketID: bucket}
+		r.buckets[key] = b
+	}
+	return b
+}
+
+// recoverPoints counts points replayed from the WAL. The points of the series
+// with rejected keys were dropped by the engine.
+func (r *walRecovery) recoverPoints(points []models.Point, rejected [][]byte) {
+	var keys map[string]struct{}
+	if len(rejected) > 0 {
+		keys = make(map[string]struct{}, len(rejected))
+		for _, k := range rejected {
+			keys[string(k)] = struct{}{}
+		}
+	}
+
+	for _, p := range points {
+		b := r.bucket(p.Name())
+		if _, ok := keys[string(p.Key())]; ok {
+			b.PointsDropped++
+		} else {
+			b.PointsRecovered++
+		}
+	}
+}
+
+// dropPoints counts points truncated from the WAL.
+func (r *walRecovery) dropPoints(points []models.Point) {
+	for _, p := range points {
+		r.bucket(p.Name()).PointsDropped++
+	}
+}
+
+// report returns the recovery report of the segments read, ordered by
+// organization and bucket.
+func (r *walRecovery) report(segments []wal.SegmentRecovery, start, end time.Time, err error) *influxdb.WALRecoveryReport {
+	report := &influxdb.WALRecoveryReport{
+		StartedAt:   start,
+		CompletedAt: end,
+		Segments:    make([]influxdb.WALSegmentRecovery, 0, len(segments)),
+		Buckets:     make([]influxdb.WALBucketRecovery, 0, len(r.buckets)),
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	for _, seg := range segments {
+		sr := influxdb.WALSegmentRecovery{
+			Name:            filepath.Base(seg.Path),
+			Size:            seg.Size,
+			EntriesReplayed: seg.Entries,
+			EntriesDropped:  seg.Dropped,
+			CorruptEntries:  seg.CorruptEntries,
+			TruncatedBytes:  seg.Truncated,
+		}
+		if seg.Err != nil {
+			sr.Corruption = seg.Err.Error()
+		}
+		report.Segments = append(report.Segments, sr)
+	}
+
+	for _, b := range r.buckets {
+		report.Buckets = append(report.Buckets, *b)
+	}
+	sort.Slice(report.Buckets, func(i, j int) bool {
+		bi, bj := report.Buckets[i], report.Buckets[j]
+		if bi.OrgID != bj.OrgID {
+			return bi.OrgID < bj.OrgID
+		}
+		return bi.BucketID < bj.BucketID
+	})
+	return report
+}
+
+// logWALRecovery logs the recovery report of the WAL at path, with a warning
+// for each segment that was truncated and each bucket that lost points.
+func logWALRecovery(log *zap.Logger, path string, report *influxdb.WALRecoveryReport) {
+	var entries, points, dropped int
+	for _, seg := range report.Segments {
+		entries += seg.EntriesReplayed
+		if seg.TruncatedBytes > 0 {
+			log.Warn("Truncated corrupt WAL segment",
+				zap.String("segment", seg.Name),
+				zap.Int("entries_dropped", seg.EntriesDropped),
+				zap.Int("corrupt_entries", seg.CorruptEntries),
+				zap.Int64("truncated_bytes", seg.TruncatedBytes),
+				zap.String("corruption", seg.Corruption))
+		}
+	}
+	for _, b := range report.Buckets {
+		points += b.PointsRecovered
+		dropped += b.PointsDropped
+		if b.PointsDropped > 0 {
+			log.Warn("Dropped points replaying WAL",
+				zap.String("org_id", b.OrgID.String()),
+				zap.String("bucket_id", b.BucketID.String()),
+				zap.Int("points_recovered", b.PointsRecovered),
+				zap.Int("points_dropped", b.PointsDropped))
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("path", path),
+		zap.Duration("duration", report.CompletedAt.Sub(report.StartedAt)),
+		zap.Int("segments", len(report.Segments)),
+		zap.Int("entries_replayed", entries),
+		zap.Int("points_recovered", points),
+		zap.Int("points_dropped", dropped),
+	}
+	if report.Error != "" {
+		fields = append(fields, zap.String("error", report.Error))
+	}
+	log.Info("Reloaded WAL", fields...)
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+	"github.com/influxdata/influxdb/tsdb/value"
+)
+
+// A WALRecord holds the changes of an entry of the WAL, for change data
+// capture.
+type WALRecord struct {
+	// Offset follows the entry in the WAL. Acknowledging it confirms that the
+	// record has been consumed.
+	Offset wal.Offset
+
+	Writes  []BucketWrite
+	Deletes []BucketDelete
+}
+
+// A BucketWrite holds the points written to a bucket. The String method of
+// each point formats it as line protocol.
+type BucketWrite struct {
+	OrgID    influxdb.ID
+	BucketID influxdb.ID
+	Points   []models.Point
+}
+
+// A BucketDelete holds the range of data deleted from a bucket. Predicate is
+// the marshaled datatypes.Predicate of the delete, if any.
+type BucketDelete struct {
+	OrgID     influxdb.ID
+	BucketID  influxdb.ID
+	Min, Max  int64
+	Predicate []byte
+}
+
+// WALSubscription streams the changes written to the WAL of an engine.
+type WALSubscription struct {
+	sub *wal.Subscription
+}
+
+// SubscribeWAL registers the WAL subscription with name, or resumes it from its
+// last acknowledged offset. The WAL retains the segments the subscription has
+// not acknowledged until it is removed with UnsubscribeWAL.
+func (e *Engine) SubscribeWAL(name string) (*WALSubscription, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	sub, err := e.wal.Subscribe(name)
+	if err != nil {
+		return nil, err
+	}
+	return &WALSubscription{sub: sub}, nil
+}
+
+// UnsubscribeWAL removes the WAL subscription with name.
+func (e *Engine) UnsubscribeWAL(name string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+	return e.wal.Unsubscribe(name)
+}
+
+// Next returns the changes of the next entry of the WAL, waiting for the entry
+// to be written or ctx to be done.
+func (s *WALSubscription) Next(ctx context.Context) (*WALRecord, error) {
+	entry, off, err := s.sub.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &WALRecord{Offset: off}
+	switch en := entry.(type) {
+	case *wal.WriteWALEntry:
+		rec.Writes = walBucketWrites(en.Values)
+	case *wal.DeleteBucketRangeWALEntry:
+		rec.Deletes = []BucketDelete{{
+			OrgID:     en.OrgID,
+			BucketID:  en.BucketID,
+			Min:       en.Min,
+			Max:       en.Max,
+			Predicate: en.Predicate,
+		}}
+	}
+	return rec, nil
+}
+
+// Ack acknowledges that the records before off have been consumed.
+func (s *WALSubscription) Ack(off wal.Offset) error {
+	return s.sub.Ack(off)
+}
+
+// Close closes the subscription. It remains registered with the engine.
+func (s *WALSubscription) Close() error {
+	return s.sub.Close()
+}
+
+// walBucketWrites converts the values of a WAL entry, keyed by series and
+// field, to points grouped by bucket. The fields of a series at the same time
+// are written as a single point.
+func walBucketWrites(values map[string][]value.Value) []BucketWrite {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var (
+		writes []BucketWrite
+		key    []byte
+		point  map[int64]models.Fields
+		times  []int64
+	)
+	flush := func() {
+		if point == nil {
+			return
+		}
+		name, tags := models.ParseKeyBytes(key)
+		org, bucket := tsdb.DecodeNameSlice(name)
+		if n := len(writes); n == 0 || writes[n-1].OrgID != org || writes[n-1].BucketID != bucket {
+			writes = append(writes, BucketWrite{OrgID: org, BucketID: bucket})
+		}
+
+		m := tags.Get([]byte(models.MeasurementTagKey))
+		pointTags := make(models.Tags, 0, len(tags))
+		for _, t := range tags {
+			if k := string(t.Key); k != models.MeasurementTagKey && k != models.FieldKeyTagKey {
+				pointTags = append(pointTags, t)
+			}
+		}
+
+		sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+		w := &writes[len(writes)-1]
+		for _, ts := range times {
+			p, err := models.NewPoint(string(m), pointTags, point[ts], time.Unix(0, ts))
+			if err != nil {
+				continue
+			}
+			w.Points = append(w.Points, p)
+		}
+		point, times = nil, nil
+	}
+
+	for _, k := range keys {
+		seriesKey, field := tsm1.SeriesAndFieldFromCompositeKey([]byte(k))
+		name, tags := models.ParseKeyBytes(seriesKey)
+		if len(name) != 16 {
+			continue // not an encoded org and bucket
+		}
+
+		// The series key holds the field as a tag, so the series key without
+		// it is compared to group fields into points.
+		tags.Delete([]byte(models.FieldKeyTagKey))
+		sk := models.MakeKey(name, tags)
+		if point != nil && string(sk) != string(key) {
+			flush()
+		}
+		if point == nil {
+			key, point = sk, make(map[int64]models.Fields)
+		}
+
+		for _, v := range values[k] {
+			ts := v.UnixNano()
+			if point[ts] == nil {
+				point[ts] = make(models.Fields)
+				times = append(times, ts)
+			}
+			point[ts][string(field)] = v.Value()
+		}
+	}
+	flush()
+	return writes
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"time"
+)
+
+const (
+	// DefaultWriteHintBatchSize is the number of lines per write preferred
+	// while the engine is lightly loaded.
+	DefaultWriteHintBatchSize = 5000
+
+	// writeHintLoad is the fraction of the cache in use at which clients are
+	// asked to slow down.
+	writeHintLoad = 0.5
+
+	// maxWriteHintBackoff is the delay between writes asked for once the cache
+	// is full.
+	maxWriteHintBackoff = 10 * time.Second
+)
+
+// WriteHints advise clients on how to shape their writes given the current
+// load on the engine, so that ingest slows smoothly rather than writes being
+// rejected once the cache is full.
+type WriteHints struct {
+	// BatchSize is the preferred number of lines per write.
+	BatchSize int
+
+	// Backoff is the time clients should wait between writes.
+	Backoff time.Duration
+
+	// Compress is true if clients should gzip the body of their writes.
+	Compress bool
+}
+
+// WriteHints returns the hints for writes given the current fill of the
+// cache. Once more than half of the cache is in use, clients are asked to
+// compress their writes and to send fewer, larger batches, backing off more
+// as the cache approaches its limit.
+func (e *Engine) WriteHints() WriteHints {
+	hints := WriteHints{BatchSize: DefaultWriteHintBatchSize}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return hints
+	}
+
+	max := e.engine.Cache.MaxSize()
+	if max == 0 {
+		return hints // Unlimited cache
+	}
+	load := float64(e.engine.Cache.Size()) / float64(max)
+	if load < writeHintLoad {
+		return hints
+	} else if load > 1 {
+		load = 1
+	}
+
+	// Scale from no backoff at writeHintLoad to the maximum once full.
+	f := (load - writeHintLoad) / (1 - writeHintLoad)
+	hints.BatchSize += int(f * DefaultWriteHintBatchSize)
+	hints.Backoff = time.Duration(f * float64(maxWriteHintBackoff)).Round(time.Millisecond)
+	hints.Compress = true
+	return hints
+}
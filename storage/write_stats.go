@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/estimator/hll"
+	"github.com/influxdata/influxdb/pkg/fs"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// writeStatsVersion is the version of the persisted write stats file format.
+const writeStatsVersion = 1
+
+// measurementWriteCounter accumulates write statistics for a single measurement
+// within a bucket.
+type measurementWriteCounter struct {
+	orgID, bucketID influxdb.ID
+	measurement     string
+
+	// Counters for the interval currently being tracked.
+	points, bytes uint64
+
+	// Values captured when the last interval was completed.
+	lastPoints, lastBytes, lastNewSeries uint64
+
+	totalPoints, totalBytes uint64
+
+	// series is a sketch of all series keys written for the measurement.
+	// seriesMark is the sketch's estimate when the current interval began.
+	series     *hll.Plus
+	seriesMark uint64
+}
+
+func newMeasurementWriteCounter(orgID, bucketID influxdb.ID, measurement string) *measurementWriteCounter {
+	return &measurementWriteCounter{
+		orgID:       orgID,
+		bucketID:    bucketID,
+		measurement: measurement,
+		series:      hll.NewDefaultPlus(),
+	}
+}
+
+// stats returns the public representation of the counter.
+func (c *measurementWriteCounter) stats() *influxdb.MeasurementWriteStats {
+	return &influxdb.MeasurementWriteStats{
+		OrgID:       c.orgID,
+		BucketID:    c.bucketID,
+		Measurement: c.measurement,
+		Points:      c.lastPoints,
+		Bytes:       c.lastBytes,
+		NewSeries:   c.lastNewSeries,
+		TotalPoints: c.totalPoints,
+		TotalBytes:  c.totalBytes,
+		TotalSeries: c.series.Count(),
+	}
+}
+
+// writeStatsTracker tracks the number of points, bytes and new series written
+// to each measurement. Series are tracked with a HyperLogLog sketch so memory
+// use is bounded regardless of cardinality.
+type writeStatsTracker struct {
+	mu       sync.Mutex
+	path     string
+	counters map[string]*measurementWriteCounter
+}
+
+func newWriteStatsTracker(path string) *writeStatsTracker {
+	return &writeStatsTracker{
+		path:     path,
+		counters: make(map[string]*measurementWriteCounter),
+	}
+}
+
+// Record adds the points in collection to the tracked statistics.
+func (t *writeStatsTracker) Record(collection *tsdb.SeriesCollection) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for iter := collection.Iterator(); iter.Next(); {
+		// Only points carrying an encoded org and bucket name, and a
+		// measurement tag, can be attributed.
+		name, tags := iter.Name(), iter.Tags()
+		if len(name) != len(tsdb.EncodeName(0, 0)) || tags.Len() == 0 || !bytes.Equal(tags[0].Key, models.MeasurementTagKeyBytes) {
+			continue
+		}
+
+		key := string(name) + string(tags[0].Value)
+		c := t.counters[key]
+		if c == nil {
+			orgID, bucketID := tsdb.DecodeNameSlice(name)
+			c = newMeasurementWriteCounter(orgID, bucketID, string(tags[0].Value))
+			t.counters[key] = c
+		}
+
+		sz := uint64(iter.Point().StringSize())
+		c.points++
+		c.bytes += sz
+		c.totalPoints++
+		c.totalBytes += sz
+		c.series.Add(iter.Key())
+	}
+}
+
+// Roll completes the current tracking interval, making its counts available
+// via Stats, and begins a new one.
+func (t *writeStatsTracker) Roll() {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, c := range t.counters {
+		n := c.series.Count()
+		c.lastPoints, c.lastBytes = c.points, c.bytes
+		c.lastNewSeries = 0
+		if n > c.seriesMark {
+			c.lastNewSeries = n - c.seriesMark
+		}
+		c.points, c.bytes, c.seriesMark = 0, 0, n
+	}
+}
+
+// Stats returns the statistics matching filter, ranked in descending order.
+func (t *writeStatsTracker) Stats(filter influxdb.WriteStatsFilter) ([]*influxdb.MeasurementWriteStats, error) {
+	var less func(a, b *influxdb.MeasurementWriteStats) bool
+	switch filter.SortBy {
+	case "", influxdb.WriteStatsSortPoints:
+		less = func(a, b *influxdb.MeasurementWriteStats) bool {
+			if a.Points != b.Points {
+				return a.Points > b.Points
+			}
+			return a.TotalPoints > b.TotalPoints
+		}
+	case influxdb.WriteStatsSortBytes:
+		less = func(a, b *influxdb.MeasurementWriteStats) bool {
+			if a.Bytes != b.Bytes {
+				return a.Bytes > b.Bytes
+			}
+			return a.TotalBytes > b.TotalBytes
+		}
+	case influxdb.WriteStatsSortSeries:
+		less = func(a, b *influxdb.MeasurementWriteStats) bool {
+			if a.NewSeries != b.NewSeries {
+				return a.NewSeries > b.NewSeries
+			}
+			return a.TotalSeries > b.TotalSeries
+		}
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown sort key %q", filter.SortBy),
+		}
+	}
+
+	if t == nil {
+		return nil, nil // Tracking disabled
+	}
+
+	t.mu.Lock()
+	stats := make([]*influxdb.MeasurementWriteStats, 0, len(t.counters))
+	for _, c := range t.counters {
+		if filter.OrgID != nil && *filter.OrgID != c.orgID {
+			continue
+		}
+		if filter.BucketID != nil && *filter.BucketID != c.bucketID {
+			continue
+		}
+		stats = append(stats, c.stats())
+	}
+	t.mu.Unlock()
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if less(a, b) {
+			return true
+		} else if less(b, a) {
+			return false
+		}
+		if a.BucketID != b.BucketID {
+			return a.BucketID < b.BucketID
+		}
+		return a.Measurement < b.Measurement
+	})
+
+	if filter.Limit > 0 && len(stats) > filter.Limit {
+		stats = stats[:filter.Limit]
+	}
+	return stats, nil
+}
+
+// DeleteBucket removes all statistics associated with the bucket.
+func (t *writeStatsTracker) DeleteBucket(orgID, bucketID influxdb.ID) {
+	if t == nil {
+		return // Tracking disabled
+	}
+
+	prefix := tsdb.EncodeNameString(orgID, bucketID)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k := range t.counters {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			delete(t.counters, k)
+		}
+	}
+}
+
+// persistedWriteStats is the on-disk representation of the tracker.
+type persistedWriteStats struct {
+	Version      int                         `json:"version"`
+	Time         time.Time                   `json:"time"`
+	Measurements []persistedMeasurementStats `json:"measurements"`
+}
+
+type persistedMeasurementStats struct {
+	OrgID         influxdb.ID `json:"orgID"`
+	BucketID      influxdb.ID `json:"bucketID"`
+	Measurement   string      `json:"measurement"`
+	LastPoints    uint64      `json:"lastPoints"`
+	LastBytes     uint64      `json:"lastBytes"`
+	LastNewSeries uint64      `json:"lastNewSeries"`
+	TotalPoints   uint64      `json:"totalPoints"`
+	TotalBytes    uint64      `json:"totalBytes"`
+	SeriesMark    uint64      `json:"seriesMark"`
+	Series        []byte      `json:"series"`
+}
+
+// Save writes the tracker's state to its path, replacing any existing file.
+func (t *writeStatsTracker) Save() error {
+	if t == nil {
+		return nil // Tracking disabled
+	}
+
+	t.mu.Lock()
+	ps := persistedWriteStats{
+		Version:      writeStatsVersion,
+		Time:         time.Now().UTC(),
+		Measurements: make([]persistedMeasurementStats, 0, len(t.counters)),
+	}
+	for _, c := range t.counters {
+		series, err := c.series.MarshalBinary()
+		if err != nil {
+			t.mu.Unlock()
+			return err
+		}
+		ps.Measurements = append(ps.Measurements, persistedMeasurementStats{
+			OrgID:         c.orgID,
+			BucketID:      c.bucketID,
+			Measurement:   c.measurement,
+			LastPoints:    c.lastPoints,
+			LastBytes:     c.lastBytes,
+			LastNewSeries: c.lastNewSeries,
+			TotalPoints:   c.totalPoints,
+			TotalBytes:    c.totalBytes,
+			SeriesMark:    c.seriesMark,
+			Series:        series,
+		})
+	}
+	t.mu.Unlock()
+
+	data, err := json.Marshal(ps)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := t.path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0666); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(tmpPath, t.path)
+}
+
+// Load reads the tracker's state from its path. A missing file is not an error.
+func (t *writeStatsTracker) Load() error {
+	if t == nil {
+		return nil // Tracking disabled
+	}
+
+	data, err := ioutil.ReadFile(t.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var ps persistedWriteStats
+	if err := json.Unmarshal(data, &ps); err != nil {
+		return fmt.Errorf("unable to decode write stats file %q: %v", t.path, err)
+	} else if ps.Version != writeStatsVersion {
+		return fmt.Errorf("incompatible write stats file version: %d", ps.Version)
+	}
+
+	counters := make(map[string]*measurementWriteCounter, len(ps.Measurements))
+	for _, m := range ps.Measurements {
+		c := newMeasurementWriteCounter(m.OrgID, m.BucketID, m.Measurement)
+		if err := c.series.UnmarshalBinary(m.Series); err != nil {
+			return fmt.Errorf("unable to decode series sketch for measurement %q: %v", m.Measurement, err)
+		}
+		c.lastPoints, c.lastBytes, c.lastNewSeries = m.LastPoints, m.LastBytes, m.LastNewSeries
+		c.totalPoints, c.totalBytes, c.seriesMark = m.TotalPoints, m.TotalBytes, m.SeriesMark
+		counters[tsdb.EncodeNameString(m.OrgID, m.BucketID)+m.Measurement] = c
+	}
+
+	t.mu.Lock()
+	t.counters = counters
+	t.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func writeStatsCollection(t *testing.T, org, bucket influxdb.ID, data string) *tsdb.SeriesCollection {
+	t.Helper()
+	name := tsdb.EncodeName(org, bucket)
+	points, err := models.ParsePoints([]byte(data), name[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tsdb.NewSeriesCollection(points)
+}
+
+func TestWriteStatsTracker(t *testing.T) {
+	org, bucket := influxdb.ID(0x3131), influxdb.ID(0x3232)
+	tracker := newWriteStatsTracker("")
+
+	tracker.Record(writeStatsCollection(t, org, bucket, "cpu,host=a v=1 1\ncpu,host=b v=1 1\ncpu,host=c v=1 1\nmem,host=a v=1 1"))
+	tracker.Roll()
+	tracker.Record(writeStatsCollection(t, org, bucket, "cpu,host=a v=2 2\nmem,host=a v=2 2\nmem,host=b v=2 2"))
+
+	stats, err := tracker.Stats(influxdb.WriteStatsFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(stats), 2; got != exp {
+		t.Fatalf("got %d stats, expected %d", got, exp)
+	}
+
+	// Only the completed interval is reported; the in-progress one only
+	// contributes to the totals.
+	if got, exp := stats[0].Measurement, "cpu"; got != exp {
+		t.Fatalf("got measurement %q, expected %q", got, exp)
+	}
+	if got, exp := stats[0].Points, uint64(3); got != exp {
+		t.Errorf("got %d points, expected %d", got, exp)
+	}
+	if got, exp := stats[0].NewSeries, uint64(3); got != exp {
+		t.Errorf("got %d new series, expected %d", got, exp)
+	}
+	if got, exp := stats[0].TotalPoints, uint64(4); got != exp {
+		t.Errorf("got %d total points, expected %d", got, exp)
+	}
+	if got, exp := stats[1].TotalSeries, uint64(2); got != exp {
+		t.Errorf("got %d total series, expected %d", got, exp)
+	}
+	if stats[0].OrgID != org || stats[0].BucketID != bucket {
+		t.Errorf("got org %v bucket %v, expected org %v bucket %v", stats[0].OrgID, stats[0].BucketID, org, bucket)
+	}
+
+	tracker.Roll()
+	stats, err = tracker.Stats(influxdb.WriteStatsFilter{SortBy: influxdb.WriteStatsSortSeries, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 || stats[0].Measurement != "mem" || stats[0].NewSeries != 1 {
+		t.Fatalf("unexpected stats after second interval: %+v", stats)
+	}
+
+	if _, err := tracker.Stats(influxdb.WriteStatsFilter{SortBy: "bogus"}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("got error %v, expected invalid sort error", err)
+	}
+}
+
+func TestWriteStatsTracker_Filter(t *testing.T) {
+	tracker := newWriteStatsTracker("")
+	tracker.Record(writeStatsCollection(t, 1, 2, "cpu v=1 1"))
+	tracker.Record(writeStatsCollection(t, 1, 3, "cpu v=1 1"))
+	tracker.Record(writeStatsCollection(t, 4, 5, "cpu v=1 1"))
+
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(3)
+	stats, err := tracker.Stats(influxdb.WriteStatsFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	} else if got, exp := len(stats), 2; got != exp {
+		t.Fatalf("got %d stats, expected %d", got, exp)
+	}
+
+	stats, err = tracker.Stats(influxdb.WriteStatsFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 1 || stats[0].BucketID != bucketID {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	tracker.DeleteBucket(1, 3)
+	stats, err = tracker.Stats(influxdb.WriteStatsFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(stats) != 0 {
+		t.Fatalf("expected no stats after bucket deletion, got %+v", stats)
+	}
+}
+
+func TestWriteStatsTracker_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "write_stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, DefaultWriteStatsFileName)
+	tracker := newWriteStatsTracker(path)
+	tracker.Record(writeStatsCollection(t, 1, 2, "cpu,host=a v=1 1\nmem v=1 1"))
+	tracker.Roll()
+	if err := tracker.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	other := newWriteStatsTracker(path)
+	if err := other.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, _ := tracker.Stats(influxdb.WriteStatsFilter{})
+	got, _ := other.Stats(influxdb.WriteStatsFilter{})
+	if !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %+v, expected %+v", got, exp)
+	}
+
+	// Series already seen before the restart are not new.
+	other.Record(writeStatsCollection(t, 1, 2, "cpu,host=a v=2 2"))
+	other.Roll()
+	got, _ = other.Stats(influxdb.WriteStatsFilter{})
+	if got[0].Measurement != "cpu" || got[0].NewSeries != 0 {
+		t.Fatalf("unexpected stats after reload: %+v", got[0])
+	}
+
+	// A missing file is not an error.
+	if err := newWriteStatsTracker(filepath.Join(dir, "missing")).Load(); err != nil {
+		t.Fatal(err)
+	}
+}
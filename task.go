@@ -57,6 +57,8 @@ type Task struct {
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
 	Offset          time.Duration          `json:"offset,omitempty"`
+	Retry           int64                  `json:"retry,omitempty"`
+	RetryBackoff    time.Duration          `json:"retryBackoff,omitempty"`
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
@@ -1,7 +1,12 @@
 package backend
 
 import (
+	"context"
 	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/influxdb"
 )
 
 // IsUnrecoverable takes in an error and determines if it is permanent (requiring user intervention to fix)
@@ -29,3 +34,36 @@ func IsUnrecoverable(err error) bool {
 
 	return false
 }
+
+// IsRetryable takes in an error and determines if a run that failed with it may succeed when it is retried,
+// as for transient storage or network errors. Unrecoverable errors, and errors of the script or of the
+// resources it uses, such as invalid queries or missing permissions, are permanent.
+func IsRetryable(err error) bool {
+	if err == nil || IsUnrecoverable(err) {
+		return false
+	}
+
+	// The errors of the executor wrap the errors of the run.
+	if e, ok := err.(*influxdb.Error); ok && e.Op == "taskExecutor" && e.Err != nil {
+		err = e.Err
+	}
+	if err == context.Canceled || err == influxdb.ErrRunCanceled {
+		return false
+	}
+
+	if e, ok := err.(*influxdb.Error); ok {
+		switch influxdb.ErrorCode(e) {
+		case influxdb.EInvalid, influxdb.ENotFound, influxdb.EConflict, influxdb.EUnprocessableEntity,
+			influxdb.EForbidden, influxdb.EUnauthorized, influxdb.EMethodNotAllowed:
+			return false
+		}
+		return true
+	}
+
+	switch flux.ErrorCode(err) {
+	case codes.Canceled, codes.Invalid, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+		codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented, codes.Unauthenticated:
+		return false
+	}
+	return true
+}
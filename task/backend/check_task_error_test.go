@@ -0,0 +1,67 @@
+package backend_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/task/backend"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "unavailable storage",
+			err:  influxdb.ErrQueryError(&flux.Error{Code: codes.Unavailable, Msg: "storage unavailable"}),
+			want: true,
+		},
+		{
+			name: "network error",
+			err:  influxdb.ErrResultIteratorError(errors.New("read tcp: connection reset by peer")),
+			want: true,
+		},
+		{
+			name: "exhausted resources",
+			err:  influxdb.ErrRunExecutionError(&flux.Error{Code: codes.ResourceExhausted, Msg: "memory limit reached"}),
+			want: true,
+		},
+		{
+			name: "invalid query",
+			err:  influxdb.ErrQueryError(&flux.Error{Code: codes.Invalid, Msg: "type error"}),
+		},
+		{
+			name: "missing permissions",
+			err:  influxdb.ErrQueryError(&influxdb.Error{Code: influxdb.EUnauthorized, Msg: "unauthorized"}),
+		},
+		{
+			name: "unparseable script",
+			err:  influxdb.ErrFluxParseError(errors.New("expected RPAREN")),
+		},
+		{
+			name: "missing bucket",
+			err:  influxdb.ErrQueryError(errors.New("could not find bucket \"b\"")),
+		},
+		{
+			name: "canceled run",
+			err:  influxdb.ErrQueryError(context.Canceled),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := backend.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
@@ -19,6 +20,16 @@ import (
 
 var _ scheduler.Executor = (*Executor)(nil)
 
+const (
+	// defaultRetryBackoff is how long to wait before retrying a failed run of
+	// a task that does not set the retryBackoff option.
+	defaultRetryBackoff = 10 * time.Second
+
+	// defaultMaxRetryBackoff is the longest time to wait before retrying a
+	// failed run, however many times it was attempted.
+	defaultMaxRetryBackoff = time.Hour
+)
+
 type Promise interface {
 	ID() influxdb.ID
 	Cancel(ctx context.Context)
@@ -54,6 +65,7 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.Authorizati
 		promiseQueue:    make(chan *promise, 1000),                                //TODO(lh): make this configurable
 		workerLimit:     make(chan struct{}, 100),                                 //TODO(lh): make this configurable
 		limitFunc:       func(*influxdb.Task, *influxdb.Run) error { return nil }, // noop
+		maxRetryBackoff: defaultMaxRetryBackoff,
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -85,6 +97,9 @@ type Executor struct {
 
 	limitFunc LimitFunc
 
+	// maxRetryBackoff is the longest time to wait before retrying a failed run.
+	maxRetryBackoff time.Duration
+
 	// keep a pool of execution workers.
 	workerPool  sync.Pool
 	workerLimit chan struct{}
@@ -288,7 +303,10 @@ func (w *worker) work() {
 		}
 
 		// execute the promise
-		w.executeQuery(prom)
+		if w.executeQuery(prom) {
+			// the run failed and was queued to be retried
+			continue
+		}
 
 		// close promise done channel and set appropriate error
 		close(prom.done)
@@ -356,18 +374,68 @@ func (w *worker) finish(p *promise, rs backend.RunStatus, err error) {
 	}
 }
 
-func (w *worker) executeQuery(p *promise) {
+// executeQuery makes an attempt at the run of p. It returns true if the
+// attempt failed and the run was queued to be attempted again, in which case
+// the promise is not yet fulfilled.
+func (w *worker) executeQuery(p *promise) bool {
 	span, ctx := tracing.StartSpanFromContext(p.ctx)
 	defer span.Finish()
 
-	// start
-	w.start(p)
+	if p.attempt == 0 {
+		w.start(p)
+	} else if p.ctx.Err() != nil {
+		// the run was canceled while waiting to be retried
+		w.finish(p, backend.RunCanceled, influxdb.ErrRunCanceled)
+		return false
+	}
+	p.attempt++
 
 	pkg, err := flux.Parse(p.task.Flux)
 	if err != nil {
 		w.finish(p, backend.RunFail, influxdb.ErrFluxParseError(err))
-		return
+		return false
+	}
+
+	err = w.executeAttempt(ctx, p, pkg)
+	if err == nil {
+		w.finish(p, backend.RunSuccess, nil)
+		return false
 	}
+	attempts, backoff := retryPolicy(p.task)
+	if p.attempt >= attempts || !backend.IsRetryable(err) || p.ctx.Err() != nil {
+		w.finish(p, backend.RunFail, err)
+		return false
+	}
+
+	d := w.e.retryBackoff(backoff, p.attempt)
+	msg := fmt.Sprintf("Attempt %d of %d failed, retrying in %s: %v", p.attempt, attempts, d, err)
+	w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), msg)
+	w.e.metrics.RetryRun(p.task.ID)
+	w.e.retry(p, d)
+	return true
+}
+
+// retry queues p to be worked again once d has passed, or as soon as it is
+// canceled. No worker is held while waiting.
+func (e *Executor) retry(p *promise, d time.Duration) {
+	go func() {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-p.ctx.Done():
+		case <-timer.C:
+		}
+
+		e.promiseQueue <- p
+		e.startWorker()
+	}()
+}
+
+// executeAttempt runs the query of the run of p once, returning the error it
+// failed with.
+func (w *worker) executeAttempt(ctx context.Context, p *promise, pkg *ast.Package) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
 
 	sf := p.run.ScheduledFor
 
@@ -378,14 +446,16 @@ func (w *worker) executeQuery(p *promise) {
 			AST: pkg,
 			Now: sf,
 		},
+		// Runs of tasks are background queries, which must not starve
+		// queries users are waiting for.
+		Priority: query.PriorityBatch,
 	}
 	req.WithReturnNoContent(true)
 	ctx = icontext.SetAuthorizer(ctx, p.task.Authorization)
 	it, err := w.e.qs.Query(ctx, req)
 	if err != nil {
 		// Assume the error should not be part of the runResult.
-		w.finish(p, backend.RunFail, influxdb.ErrQueryError(err))
-		return
+		return influxdb.ErrQueryError(err)
 	}
 
 	var runErr error
@@ -407,16 +477,41 @@ func (w *worker) executeQuery(p *promise) {
 	}
 
 	if runErr != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrRunExecutionError(runErr))
-		return
+		return influxdb.ErrRunExecutionError(runErr)
 	}
 
 	if it.Err() != nil {
-		w.finish(p, backend.RunFail, influxdb.ErrResultIteratorError(it.Err()))
-		return
+		return influxdb.ErrResultIteratorError(it.Err())
+	}
+	return nil
+}
+
+// retryPolicy returns the number of times a run of t is attempted, and how
+// long to wait before retrying it the first time.
+func retryPolicy(t *influxdb.Task) (int, time.Duration) {
+	if t.Retry < 1 {
+		return 1, 0
+	}
+
+	backoff := defaultRetryBackoff
+	if t.RetryBackoff > 0 {
+		backoff = t.RetryBackoff
 	}
+	return int(t.Retry), backoff
+}
 
-	w.finish(p, backend.RunSuccess, nil)
+// retryBackoff returns how long to wait before retrying a run after its
+// attempt failed, doubling base for every attempt after the first, up to the
+// maximum backoff of the executor.
+func (e *Executor) retryBackoff(base time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < e.maxRetryBackoff; i++ {
+		d *= 2
+	}
+	if d > e.maxRetryBackoff {
+		d = e.maxRetryBackoff
+	}
+	return d
 }
 
 // RunsActive returns the current number of workers, which is equivalent to
@@ -447,6 +542,9 @@ type promise struct {
 	createdAt time.Time
 	startedAt time.Time
 
+	// attempt is the number of attempts made at the run.
+	attempt int
+
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 }
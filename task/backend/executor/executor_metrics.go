@@ -16,6 +16,7 @@ type ExecutorMetrics struct {
 	errorsCounter        *prometheus.CounterVec
 	manualRunsCounter    *prometheus.CounterVec
 	resumeRunsCounter    *prometheus.CounterVec
+	retriesCounter       *prometheus.CounterVec
 	unrecoverableCounter *prometheus.CounterVec
 	runLatency           *prometheus.HistogramVec
 }
@@ -85,6 +86,13 @@ func NewExecutorMetrics(ex *Executor) *ExecutorMetrics {
 			Help:      "Total number of runs resumed by task ID",
 		}, []string{"taskID"}),
 
+		retriesCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retries_counter",
+			Help:      "Total number of failed run attempts retried by task ID",
+		}, []string{"taskID"}),
+
 		runLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -129,6 +137,7 @@ func (em *ExecutorMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.runDuration,
 		em.manualRunsCounter,
 		em.resumeRunsCounter,
+		em.retriesCounter,
 		em.unrecoverableCounter,
 		em.runLatency,
 	}
@@ -161,6 +170,11 @@ func (em *ExecutorMetrics) LogError(taskType string, err error) {
 	}
 }
 
+// RetryRun increments the count of failed run attempts of a task that are retried.
+func (em *ExecutorMetrics) RetryRun(taskID influxdb.ID) {
+	em.retriesCounter.WithLabelValues(taskID.String()).Inc()
+}
+
 // LogUnrecoverableError increments the count of unrecoverable errors, which require admin intervention to resolve or deactivate
 // This count is separate from the errors count so that the errors metric can be used to identify only internal, rather than user errors
 // and so that unrecoverable errors can be quickly identified for deactivation
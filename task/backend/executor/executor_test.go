@@ -76,6 +76,7 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
+	t.Run("RetryFailure", testRetryFailure)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -527,6 +528,97 @@ func testErrorHandling(t *testing.T) {
 	*/
 }
 
+func testRetryFailure(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t)
+	tes.ex.maxRetryBackoff = time.Millisecond
+
+	metrics := tes.metrics
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	script := fmt.Sprintf(`
+option task = {
+			name: %q,
+			every: 1m,
+			retry: 2,
+			retryBackoff: 1s,
+}
+from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first attempt fails with a transient error, and is retried
+	tes.svc.FailNextQuery(errors.New("connection reset by peer"))
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.SucceedQuery(script)
+
+	<-promise.Done()
+
+	if got := promise.Error(); got != nil {
+		t.Fatal(got)
+	}
+
+	mg := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mg, "task_executor_retries_counter", map[string]string{"taskID": task.ID.String()})
+	if got := *m.Counter.Value; got != 1 {
+		t.Fatalf("expected 1 retry, got %v", got)
+	}
+
+	// a failure of the last attempt fails the run
+	tes.svc.FailNextQuery(errors.New("connection reset by peer"))
+	promise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("connection reset by peer"))
+
+	<-promise.Done()
+
+	if got := promise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+
+	// a run waiting to be retried does not hold a worker, and can be canceled
+	tes.ex.maxRetryBackoff = time.Hour
+	tes.svc.FailNextQuery(errors.New("connection reset by peer"))
+	promise, err = tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mg := promtest.MustGather(t, reg)
+		m := promtest.MustFindMetric(t, mg, "task_executor_retries_counter", map[string]string{"taskID": task.ID.String()})
+		if *m.Counter.Value == 3 && tes.ex.RunsActive() == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("run waiting to be retried still holds %d workers", tes.ex.RunsActive())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	promise.Cancel(cctx)
+	if got := promise.Error(); got != influxdb.ErrRunCanceled {
+		t.Fatalf("expected run to be canceled, got %v", got)
+	}
+}
+
 type taskControlService struct {
 	backend.TaskControlService
 
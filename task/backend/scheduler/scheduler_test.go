@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/influxdata/cron"
+	"github.com/influxdata/influxdb/kit/check"
 
 	"github.com/benbjohnson/clock"
 )
@@ -428,6 +429,35 @@ func TestTreeScheduler_Stop(t *testing.T) {
 	sch.Stop()
 }
 
+func TestTreeScheduler_Check(t *testing.T) {
+	mockTime := clock.NewMock()
+	mockTime.Set(time.Now())
+	exe := &mockExecutor{fn: func(l *sync.Mutex, ctx context.Context, id ID, scheduledFor time.Time) {}}
+	sch, _, err := NewScheduler(exe, &mockSchedulableService{fn: func(ctx context.Context, id ID, t time.Time) error {
+		return nil
+	}},
+		WithTime(mockTime))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := sch.Check(context.Background()); got.Status != check.StatusPass {
+		t.Fatalf("expected the scheduler to pass, got %q", got.Status)
+	}
+
+	sch.mu.Lock()
+	sch.when = mockTime.Now().Add(-2 * schedulerLagThreshold)
+	sch.mu.Unlock()
+	if got := sch.Check(context.Background()); got.Status != check.StatusWarn || got.Reason != "behind" {
+		t.Fatalf("expected the scheduler to be behind, got %q with reason %q", got.Status, got.Reason)
+	}
+
+	sch.Stop()
+	if got := sch.Check(context.Background()); got.Status != check.StatusFail || got.Reason != "stopped" {
+		t.Fatalf("expected the scheduler to be stopped, got %q with reason %q", got.Status, got.Reason)
+	}
+}
+
 func TestSchedule_panic(t *testing.T) {
 	// panics in the executor should be treated as errors
 	now := time.Now().UTC()
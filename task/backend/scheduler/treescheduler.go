@@ -10,6 +10,7 @@ import (
 	"github.com/benbjohnson/clock"
 	"github.com/cespare/xxhash"
 	"github.com/google/btree"
+	"github.com/influxdata/influxdb/kit/check"
 )
 
 const (
@@ -19,6 +20,9 @@ const (
 
 	// defaultMaxWorkers is a constant that sets the default number of maximum workers for a TreeScheduler
 	defaultMaxWorkers = 128
+
+	// schedulerLagThreshold is how far behind its next scheduled run the scheduler can fall before it is degraded.
+	schedulerLagThreshold = time.Minute
 )
 
 // TreeScheduler is a Scheduler based on a btree.
@@ -275,6 +279,27 @@ func (s *TreeScheduler) When() time.Time {
 	return w
 }
 
+// CheckName returns the name of the scheduler's health check.
+func (s *TreeScheduler) CheckName() string {
+	return "task-scheduler"
+}
+
+// Check reports whether the scheduler is running, and is degraded when it falls behind the runs it has scheduled,
+// which happens when all of its workers are busy.
+func (s *TreeScheduler) Check(ctx context.Context) check.Response {
+	select {
+	case <-s.done:
+		return check.Fail("stopped", "task scheduler is stopped")
+	default:
+	}
+
+	when := s.When()
+	if lag := s.time.Now().Sub(when); !when.IsZero() && lag > schedulerLagThreshold {
+		return check.Warn("behind", "task scheduler is %s behind schedule", lag.Round(time.Second))
+	}
+	return check.Pass()
+}
+
 func (s *TreeScheduler) release(taskID ID) {
 	when, ok := s.nextTime[taskID]
 	if !ok {
@@ -312,12 +337,12 @@ func (s *TreeScheduler) work(ctx context.Context, ch chan Item) {
 				}
 			}()
 			// report the difference between when the item was supposed to be scheduled and now
-			s.sm.reportScheduleDelay(time.Since(it.Next()))
-			preExec := time.Now()
+			s.sm.reportScheduleDelay(s.time.Since(it.Next()))
+			preExec := s.time.Now()
 			// execute
 			err = s.executor.Execute(ctx, it.id, t, it.When())
 			// report how long execution took
-			s.sm.reportExecution(err, time.Since(preExec))
+			s.sm.reportExecution(err, s.time.Since(preExec))
 			return err
 		}()
 		if err != nil {
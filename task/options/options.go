@@ -18,6 +18,7 @@ import (
 
 const maxConcurrency = 100
 const maxRetry = 10
+const maxRetryBackoff = time.Hour
 
 // Options are the task-related options that can be specified in a Flux script.
 type Options struct {
@@ -37,7 +38,14 @@ type Options struct {
 
 	Concurrency *int64 `json:"concurrency,omitempty"`
 
+	// Retry is the number of times a run is attempted. Runs failing with
+	// errors that may be transient, such as storage or network errors, are
+	// retried until they succeed or are attempted Retry times.
 	Retry *int64 `json:"retry,omitempty"`
+
+	// RetryBackoff is how long to wait before retrying a failed run the first
+	// time. The wait doubles with each following attempt.
+	RetryBackoff *Duration `json:"retryBackoff,omitempty"`
 }
 
 // Duration is a time span that supports the same units as the flux parser's time duration, as well as negative length time spans.
@@ -127,6 +135,7 @@ func (o *Options) Clear() {
 	o.Offset = nil
 	o.Concurrency = nil
 	o.Retry = nil
+	o.RetryBackoff = nil
 }
 
 // IsZero tells us if the options has been zeroed out.
@@ -136,17 +145,19 @@ func (o *Options) IsZero() bool {
 		o.Every.IsZero() &&
 		(o.Offset == nil || o.Offset.IsZero()) &&
 		o.Concurrency == nil &&
-		o.Retry == nil
+		o.Retry == nil &&
+		o.RetryBackoff == nil
 }
 
 // All the task option names we accept.
 const (
-	optName        = "name"
-	optCron        = "cron"
-	optEvery       = "every"
-	optOffset      = "offset"
-	optConcurrency = "concurrency"
-	optRetry       = "retry"
+	optName         = "name"
+	optCron         = "cron"
+	optEvery        = "every"
+	optOffset       = "offset"
+	optConcurrency  = "concurrency"
+	optRetry        = "retry"
+	optRetryBackoff = "retryBackoff"
 )
 
 // contains is a helper function to see if an array of strings contains a string
@@ -210,7 +221,7 @@ func FromScript(script string) (Options, error) {
 	if err != nil {
 		return opt, err
 	}
-	durTypes := grabTaskOptionAST(fluxAST, optEvery, optOffset)
+	durTypes := grabTaskOptionAST(fluxAST, optEvery, optOffset, optRetryBackoff)
 	// TODO(desa): should be dependencies.NewEmpty(), but for now we'll hack things together
 	ctx := newDeps().Inject(context.Background())
 	_, scope, err := flux.EvalAST(ctx, fluxAST)
@@ -313,6 +324,23 @@ func FromScript(script string) (Options, error) {
 		opt.Retry = pointer.Int64(retryVal.Int())
 	}
 
+	if backoffVal, ok := optObject.Get(optRetryBackoff); ok {
+		if err := checkNature(backoffVal.PolyType().Nature(), semantic.Duration); err != nil {
+			return opt, err
+		}
+		dur, ok := durTypes[optRetryBackoff]
+		if !ok || dur == nil {
+			return opt, ErrParseTaskOptionField(optRetryBackoff)
+		}
+		durNode, err := parseSignedDuration(dur.Location().Source)
+		if err != nil {
+			return opt, err
+		}
+		durNode.BaseNode = ast.BaseNode{}
+		opt.RetryBackoff = &Duration{}
+		opt.RetryBackoff.Node = *durNode
+	}
+
 	if err := opt.Validate(); err != nil {
 		return opt, err
 	}
@@ -373,6 +401,17 @@ func (o *Options) Validate() error {
 			errs = append(errs, fmt.Sprintf("retry exceeded max of %d", maxRetry))
 		}
 	}
+	if o.RetryBackoff != nil {
+		backoff, err := o.RetryBackoff.DurationFrom(now)
+		if err != nil {
+			return err
+		}
+		if backoff < time.Second {
+			errs = append(errs, "retryBackoff option must be at least 1 second")
+		} else if backoff > maxRetryBackoff {
+			errs = append(errs, fmt.Sprintf("retryBackoff exceeded max of %s", maxRetryBackoff))
+		}
+	}
 
 	if len(errs) == 0 {
 		return nil
@@ -414,7 +453,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optRetryBackoff:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -423,7 +462,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optRetryBackoff}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
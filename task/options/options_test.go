@@ -33,6 +33,9 @@ func scriptGenerator(opt options.Options, body string) string {
 	if opt.Retry != nil && *opt.Retry != 0 {
 		taskData = fmt.Sprintf("%s  retry: %d,\n", taskData, *opt.Retry)
 	}
+	if opt.RetryBackoff != nil && !opt.RetryBackoff.IsZero() {
+		taskData = fmt.Sprintf("%s  retryBackoff: %s,\n", taskData, opt.RetryBackoff.String())
+	}
 	if body == "" {
 		body = `from(bucket: "test")
     |> range(start:-1h)`
@@ -76,6 +79,11 @@ func TestFromScript(t *testing.T) {
 		{script: "option task = {\n  name: \"name6\",\n  concurrency: 1,\n  every: 1,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name7", Retry: pointer.Int64(20), Every: *(options.MustParseDuration("1h"))}, ""), shouldErr: true},
 		{script: "option task = {\n  name: \"name8\",\n  retry: 0,\n  every: 1m0s,\n\n}\n\nfrom(bucket: \"test\")\n    |> range(start:-1h)", shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name12", Every: *(options.MustParseDuration("1h")), Retry: pointer.Int64(3), RetryBackoff: options.MustParseDuration("30s")}, ""),
+			exp: options.Options{Name: "name12", Every: *(options.MustParseDuration("1h")), Concurrency: pointer.Int64(1), Retry: pointer.Int64(3), RetryBackoff: options.MustParseDuration("30s")},
+		},
+		{script: scriptGenerator(options.Options{Name: "name13", Every: *(options.MustParseDuration("1h")), RetryBackoff: options.MustParseDuration("500ms")}, ""), shouldErr: true},
+		{script: scriptGenerator(options.Options{Name: "name14", Every: *(options.MustParseDuration("1h")), RetryBackoff: options.MustParseDuration("2h")}, ""), shouldErr: true},
 		{script: scriptGenerator(options.Options{Name: "name9"}, ""), shouldErr: true},
 		{script: scriptGenerator(options.Options{}, ""), shouldErr: true},
 		{script: `option task = {
@@ -146,7 +154,7 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 		t.Errorf("expected error to mention unrecognized options, but it said: %v", err)
 	}
 
-	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry"}
+	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry", "retryBackoff"}
 	for _, o := range validOpts {
 		if !strings.Contains(msg, o) {
 			t.Errorf("expected error to mention valid option %q but it said: %v", o, err)
@@ -67,6 +67,10 @@ func KVStore(
 			name: "ConcurrentUpdate",
 			fn:   KVConcurrentUpdate,
 		},
+		{
+			name: "Watch",
+			fn:   KVWatch,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1421,3 +1425,126 @@ func KVConcurrentUpdate(
 		})
 	}
 }
+
+// KVWatch tests the watch method contract for the key value store.
+func KVWatch(
+	init func(KVStoreFields, *testing.T) (kv.Store, func()),
+	t *testing.T,
+) {
+	type args struct {
+		bucket []byte
+		prefix []byte
+		// updates are applied in a transaction each.
+		updates []func(kv.Bucket) error
+	}
+	type wants struct {
+		events []kv.Event
+	}
+
+	put := func(k, v string) func(kv.Bucket) error {
+		return func(b kv.Bucket) error { return b.Put([]byte(k), []byte(v)) }
+	}
+	del := func(k string) func(kv.Bucket) error {
+		return func(b kv.Bucket) error { return b.Delete([]byte(k)) }
+	}
+
+	tests := []struct {
+		name   string
+		fields KVStoreFields
+		args   args
+		wants  wants
+	}{
+		{
+			name: "watch prefix",
+			fields: KVStoreFields{
+				Bucket: []byte("bucket"),
+				Pairs: []kv.Pair{
+					{
+						Key:   []byte("aa"),
+						Value: []byte("1"),
+					},
+				},
+			},
+			args: args{
+				bucket: []byte("bucket"),
+				prefix: []byte("a"),
+				updates: []func(kv.Bucket) error{
+					put("aa", "2"),
+					put("ab", "3"),
+					put("ba", "4"),
+					del("ac"),
+					del("aa"),
+				},
+			},
+			wants: wants{
+				events: []kv.Event{
+					{
+						Type:      kv.EventUpdate,
+						Bucket:    []byte("bucket"),
+						Key:       []byte("aa"),
+						Value:     []byte("2"),
+						PrevValue: []byte("1"),
+					},
+					{
+						Type:   kv.EventCreate,
+						Bucket: []byte("bucket"),
+						Key:    []byte("ab"),
+						Value:  []byte("3"),
+					},
+					{
+						Type:      kv.EventDelete,
+						Bucket:    []byte("bucket"),
+						Key:       []byte("aa"),
+						PrevValue: []byte("2"),
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, closeFn := init(tt.fields, t)
+			defer closeFn()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			events, err := s.Watch(ctx, tt.args.bucket, tt.args.prefix)
+			if err != nil {
+				t.Fatalf("unexpected error watching: %v", err)
+			}
+
+			for _, update := range tt.args.updates {
+				err := s.Update(context.Background(), func(tx kv.Tx) error {
+					b, err := tx.Bucket(tt.args.bucket)
+					if err != nil {
+						return err
+					}
+					return update(b)
+				})
+				if err != nil {
+					t.Fatalf("error during update transaction: %v", err)
+				}
+			}
+
+			var got []kv.Event
+			timeout := time.After(5 * time.Second)
+			for len(got) < len(tt.wants.events) {
+				select {
+				case e := <-events:
+					got = append(got, e)
+				case <-timeout:
+					t.Fatalf("timed out waiting for events, got %d of %d", len(got), len(tt.wants.events))
+				}
+			}
+			if diff := cmp.Diff(got, tt.wants.events); diff != "" {
+				t.Errorf("unexpected events: -got/+exp\n%v", diff)
+			}
+
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
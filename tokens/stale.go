@@ -0,0 +1,70 @@
+// Package tokens flags the tokens of authorizations that have not been used
+// for a while as stale, so that administrators can find and revoke them.
+package tokens
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	influxlogger "github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+// StaleFlagger flags stale authorizations every interval.
+type StaleFlagger struct {
+	AuthorizationLifecycleService influxdb.AuthorizationLifecycleService
+
+	Interval time.Duration
+
+	// StaleAfter is how long authorizations may go unused before they are
+	// flagged as stale.
+	StaleAfter time.Duration
+
+	log *zap.Logger
+	now func() time.Time
+}
+
+// NewStaleFlagger returns a StaleFlagger flagging the authorizations that
+// have not been used for 90 days every hour.
+func NewStaleFlagger(log *zap.Logger) *StaleFlagger {
+	return &StaleFlagger{
+		Interval:   time.Hour,
+		StaleAfter: 90 * 24 * time.Hour,
+		log:        log,
+		now:        time.Now,
+	}
+}
+
+// Run flags stale authorizations each interval until ctx is done.
+func (f *StaleFlagger) Run(ctx context.Context) {
+	logger := f.log.With(
+		zap.String("service", "stale_token_flagger"),
+		influxlogger.DurationLiteral("interval", f.Interval),
+		influxlogger.DurationLiteral("stale_after", f.StaleAfter),
+	)
+
+	logger.Info("Starting")
+	ticker := time.NewTicker(f.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			n, err := f.Flag(ctx)
+			if err != nil {
+				logger.Warn("Unable to flag stale authorizations", zap.Error(err))
+				continue
+			}
+			logger.Debug("Flagged stale authorizations", zap.Int("stale", n))
+		case <-ctx.Done():
+			logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// Flag flags the authorizations that have not been used for StaleAfter as
+// stale, returning the number of stale authorizations.
+func (f *StaleFlagger) Flag(ctx context.Context) (int, error) {
+	return f.AuthorizationLifecycleService.FlagStaleAuthorizations(ctx, f.now().Add(-f.StaleAfter))
+}
@@ -0,0 +1,36 @@
+package tokens
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStaleFlagger_Flag(t *testing.T) {
+	now := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	var before time.Time
+	f := NewStaleFlagger(zaptest.NewLogger(t))
+	f.now = func() time.Time { return now }
+	f.StaleAfter = 30 * 24 * time.Hour
+	f.AuthorizationLifecycleService = &mock.AuthorizationLifecycleService{
+		FlagStaleAuthorizationsFn: func(ctx context.Context, t time.Time) (int, error) {
+			before = t
+			return 2, nil
+		},
+	}
+
+	n, err := f.Flag(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("unexpected number of stale authorizations: got %d, want 2", n)
+	}
+	if want := time.Date(2020, 3, 2, 0, 0, 0, 0, time.UTC); !before.Equal(want) {
+		t.Errorf("unexpected stale threshold: got %v, want %v", before, want)
+	}
+}
@@ -203,6 +203,68 @@ func (a *FloatArray) Merge(b *FloatArray) {
 	a.Values = out.Values[:k]
 }
 
+// MergeResolve overlays b to top of a like Merge, except that two values with
+// the same timestamp are resolved by r, with b holding the newer value. It
+// returns the number of values resolved. Both a and b must be sorted in
+// ascending order.
+func (a *FloatArray) MergeResolve(b *FloatArray, r DuplicateResolution) int {
+	if a.Len() == 0 || b.Len() == 0 || a.MaxTime() < b.MinTime() || b.MaxTime() < a.MinTime() {
+		a.Merge(b)
+		return 0
+	}
+
+	out := NewFloatArrayLen(a.Len() + b.Len())
+	i, j, k, n := 0, 0, 0, 0
+	for i < len(a.Timestamps) && j < len(b.Timestamps) {
+		if a.Timestamps[i] < b.Timestamps[j] {
+			out.Timestamps[k] = a.Timestamps[i]
+			out.Values[k] = a.Values[i]
+			i++
+		} else if a.Timestamps[i] == b.Timestamps[j] {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = resolveFloat(r, a.Values[i], b.Values[j])
+			i++
+			j++
+			n++
+		} else {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = b.Values[j]
+			j++
+		}
+		k++
+	}
+
+	if i < len(a.Timestamps) {
+		m := copy(out.Timestamps[k:], a.Timestamps[i:])
+		copy(out.Values[k:], a.Values[i:])
+		k += m
+	} else if j < len(b.Timestamps) {
+		m := copy(out.Timestamps[k:], b.Timestamps[j:])
+		copy(out.Values[k:], b.Values[j:])
+		k += m
+	}
+
+	a.Timestamps = out.Timestamps[:k]
+	a.Values = out.Values[:k]
+	return n
+}
+
+// resolveFloat returns the value kept by r of two values with the same
+// timestamp.
+func resolveFloat(r DuplicateResolution, older, newer float64) float64 {
+	switch r {
+	case DuplicateResolutionMax:
+		if older > newer {
+			return older
+		}
+	case DuplicateResolutionMin:
+		if older < newer {
+			return older
+		}
+	}
+	return newer
+}
+
 type IntegerArray struct {
 	Timestamps []int64
 	Values     []int64
@@ -400,6 +462,68 @@ func (a *IntegerArray) Merge(b *IntegerArray) {
 	a.Values = out.Values[:k]
 }
 
+// MergeResolve overlays b to top of a like Merge, except that two values with
+// the same timestamp are resolved by r, with b holding the newer value. It
+// returns the number of values resolved. Both a and b must be sorted in
+// ascending order.
+func (a *IntegerArray) MergeResolve(b *IntegerArray, r DuplicateResolution) int {
+	if a.Len() == 0 || b.Len() == 0 || a.MaxTime() < b.MinTime() || b.MaxTime() < a.MinTime() {
+		a.Merge(b)
+		return 0
+	}
+
+	out := NewIntegerArrayLen(a.Len() + b.Len())
+	i, j, k, n := 0, 0, 0, 0
+	for i < len(a.Timestamps) && j < len(b.Timestamps) {
+		if a.Timestamps[i] < b.Timestamps[j] {
+			out.Timestamps[k] = a.Timestamps[i]
+			out.Values[k] = a.Values[i]
+			i++
+		} else if a.Timestamps[i] == b.Timestamps[j] {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = resolveInteger(r, a.Values[i], b.Values[j])
+			i++
+			j++
+			n++
+		} else {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = b.Values[j]
+			j++
+		}
+		k++
+	}
+
+	if i < len(a.Timestamps) {
+		m := copy(out.Timestamps[k:], a.Timestamps[i:])
+		copy(out.Values[k:], a.Values[i:])
+		k += m
+	} else if j < len(b.Timestamps) {
+		m := copy(out.Timestamps[k:], b.Timestamps[j:])
+		copy(out.Values[k:], b.Values[j:])
+		k += m
+	}
+
+	a.Timestamps = out.Timestamps[:k]
+	a.Values = out.Values[:k]
+	return n
+}
+
+// resolveInteger returns the value kept by r of two values with the same
+// timestamp.
+func resolveInteger(r DuplicateResolution, older, newer int64) int64 {
+	switch r {
+	case DuplicateResolutionMax:
+		if older > newer {
+			return older
+		}
+	case DuplicateResolutionMin:
+		if older < newer {
+			return older
+		}
+	}
+	return newer
+}
+
 type UnsignedArray struct {
 	Timestamps []int64
 	Values     []uint64
@@ -597,6 +721,68 @@ func (a *UnsignedArray) Merge(b *UnsignedArray) {
 	a.Values = out.Values[:k]
 }
 
+// MergeResolve overlays b to top of a like Merge, except that two values with
+// the same timestamp are resolved by r, with b holding the newer value. It
+// returns the number of values resolved. Both a and b must be sorted in
+// ascending order.
+func (a *UnsignedArray) MergeResolve(b *UnsignedArray, r DuplicateResolution) int {
+	if a.Len() == 0 || b.Len() == 0 || a.MaxTime() < b.MinTime() || b.MaxTime() < a.MinTime() {
+		a.Merge(b)
+		return 0
+	}
+
+	out := NewUnsignedArrayLen(a.Len() + b.Len())
+	i, j, k, n := 0, 0, 0, 0
+	for i < len(a.Timestamps) && j < len(b.Timestamps) {
+		if a.Timestamps[i] < b.Timestamps[j] {
+			out.Timestamps[k] = a.Timestamps[i]
+			out.Values[k] = a.Values[i]
+			i++
+		} else if a.Timestamps[i] == b.Timestamps[j] {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = resolveUnsigned(r, a.Values[i], b.Values[j])
+			i++
+			j++
+			n++
+		} else {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = b.Values[j]
+			j++
+		}
+		k++
+	}
+
+	if i < len(a.Timestamps) {
+		m := copy(out.Timestamps[k:], a.Timestamps[i:])
+		copy(out.Values[k:], a.Values[i:])
+		k += m
+	} else if j < len(b.Timestamps) {
+		m := copy(out.Timestamps[k:], b.Timestamps[j:])
+		copy(out.Values[k:], b.Values[j:])
+		k += m
+	}
+
+	a.Timestamps = out.Timestamps[:k]
+	a.Values = out.Values[:k]
+	return n
+}
+
+// resolveUnsigned returns the value kept by r of two values with the same
+// timestamp.
+func resolveUnsigned(r DuplicateResolution, older, newer uint64) uint64 {
+	switch r {
+	case DuplicateResolutionMax:
+		if older > newer {
+			return older
+		}
+	case DuplicateResolutionMin:
+		if older < newer {
+			return older
+		}
+	}
+	return newer
+}
+
 type StringArray struct {
 	Timestamps []int64
 	Values     []string
@@ -794,6 +980,68 @@ func (a *StringArray) Merge(b *StringArray) {
 	a.Values = out.Values[:k]
 }
 
+// MergeResolve overlays b to top of a like Merge, except that two values with
+// the same timestamp are resolved by r, with b holding the newer value. It
+// returns the number of values resolved. Both a and b must be sorted in
+// ascending order.
+func (a *StringArray) MergeResolve(b *StringArray, r DuplicateResolution) int {
+	if a.Len() == 0 || b.Len() == 0 || a.MaxTime() < b.MinTime() || b.MaxTime() < a.MinTime() {
+		a.Merge(b)
+		return 0
+	}
+
+	out := NewStringArrayLen(a.Len() + b.Len())
+	i, j, k, n := 0, 0, 0, 0
+	for i < len(a.Timestamps) && j < len(b.Timestamps) {
+		if a.Timestamps[i] < b.Timestamps[j] {
+			out.Timestamps[k] = a.Timestamps[i]
+			out.Values[k] = a.Values[i]
+			i++
+		} else if a.Timestamps[i] == b.Timestamps[j] {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = resolveString(r, a.Values[i], b.Values[j])
+			i++
+			j++
+			n++
+		} else {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = b.Values[j]
+			j++
+		}
+		k++
+	}
+
+	if i < len(a.Timestamps) {
+		m := copy(out.Timestamps[k:], a.Timestamps[i:])
+		copy(out.Values[k:], a.Values[i:])
+		k += m
+	} else if j < len(b.Timestamps) {
+		m := copy(out.Timestamps[k:], b.Timestamps[j:])
+		copy(out.Values[k:], b.Values[j:])
+		k += m
+	}
+
+	a.Timestamps = out.Timestamps[:k]
+	a.Values = out.Values[:k]
+	return n
+}
+
+// resolveString returns the value kept by r of two values with the same
+// timestamp.
+func resolveString(r DuplicateResolution, older, newer string) string {
+	switch r {
+	case DuplicateResolutionMax:
+		if older > newer {
+			return older
+		}
+	case DuplicateResolutionMin:
+		if older < newer {
+			return older
+		}
+	}
+	return newer
+}
+
 type BooleanArray struct {
 	Timestamps []int64
 	Values     []bool
@@ -991,6 +1239,64 @@ func (a *BooleanArray) Merge(b *BooleanArray) {
 	a.Values = out.Values[:k]
 }
 
+// MergeResolve overlays b to top of a like Merge, except that two values with
+// the same timestamp are resolved by r, with b holding the newer value. It
+// returns the number of values resolved. Both a and b must be sorted in
+// ascending order.
+func (a *BooleanArray) MergeResolve(b *BooleanArray, r DuplicateResolution) int {
+	if a.Len() == 0 || b.Len() == 0 || a.MaxTime() < b.MinTime() || b.MaxTime() < a.MinTime() {
+		a.Merge(b)
+		return 0
+	}
+
+	out := NewBooleanArrayLen(a.Len() + b.Len())
+	i, j, k, n := 0, 0, 0, 0
+	for i < len(a.Timestamps) && j < len(b.Timestamps) {
+		if a.Timestamps[i] < b.Timestamps[j] {
+			out.Timestamps[k] = a.Timestamps[i]
+			out.Values[k] = a.Values[i]
+			i++
+		} else if a.Timestamps[i] == b.Timestamps[j] {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = resolveBoolean(r, a.Values[i], b.Values[j])
+			i++
+			j++
+			n++
+		} else {
+			out.Timestamps[k] = b.Timestamps[j]
+			out.Values[k] = b.Values[j]
+			j++
+		}
+		k++
+	}
+
+	if i < len(a.Timestamps) {
+		m := copy(out.Timestamps[k:], a.Timestamps[i:])
+		copy(out.Values[k:], a.Values[i:])
+		k += m
+	} else if j < len(b.Timestamps) {
+		m := copy(out.Timestamps[k:], b.Timestamps[j:])
+		copy(out.Values[k:], b.Values[j:])
+		k += m
+	}
+
+	a.Timestamps = out.Timestamps[:k]
+	a.Values = out.Values[:k]
+	return n
+}
+
+// resolveBoolean returns the value kept by r of two values with the same
+// timestamp.
+func resolveBoolean(r DuplicateResolution, older, newer bool) bool {
+	switch r {
+	case DuplicateResolutionMax:
+		return older || newer
+	case DuplicateResolutionMin:
+		return older && newer
+	}
+	return newer
+}
+
 type TimestampArray struct {
 	Timestamps []int64
 }
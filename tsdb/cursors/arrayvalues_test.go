@@ -457,3 +457,66 @@ func TestStringArray_Merge(t *testing.T) {
 		})
 	}
 }
+
+func TestFloatArray_MergeResolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		r         cursors.DuplicateResolution
+		a, b, exp *cursors.FloatArray
+		n         int
+	}{
+		{
+			name: "newest",
+			r:    cursors.DuplicateResolutionNewest,
+			a:    makeFloatArray(0, 0.5, 1, 1.0, 2, 2.5),
+			b:    makeFloatArray(0, 0.1, 2, 2.9, 3, 3.1),
+			exp:  makeFloatArray(0, 0.1, 1, 1.0, 2, 2.9, 3, 3.1),
+			n:    2,
+		},
+		{
+			name: "max",
+			r:    cursors.DuplicateResolutionMax,
+			a:    makeFloatArray(0, 0.5, 1, 1.0, 2, 2.5),
+			b:    makeFloatArray(0, 0.1, 2, 2.9, 3, 3.1),
+			exp:  makeFloatArray(0, 0.5, 1, 1.0, 2, 2.9, 3, 3.1),
+			n:    2,
+		},
+		{
+			name: "min",
+			r:    cursors.DuplicateResolutionMin,
+			a:    makeFloatArray(0, 0.5, 1, 1.0, 2, 2.5),
+			b:    makeFloatArray(0, 0.1, 2, 2.9, 3, 3.1),
+			exp:  makeFloatArray(0, 0.1, 1, 1.0, 2, 2.5, 3, 3.1),
+			n:    2,
+		},
+		{
+			name: "no overlap",
+			r:    cursors.DuplicateResolutionMax,
+			a:    makeFloatArray(0, 0.5, 1, 1.0),
+			b:    makeFloatArray(2, 0.1, 3, 0.2),
+			exp:  makeFloatArray(0, 0.5, 1, 1.0, 2, 0.1, 3, 0.2),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.a.MergeResolve(test.b, test.r); got != test.n {
+				t.Errorf("unexpected duplicates; got=%d, exp=%d", got, test.n)
+			}
+			if !cmp.Equal(test.a, test.exp) {
+				t.Fatalf("unexpected values -got/+exp\n%s", cmp.Diff(test.a, test.exp))
+			}
+		})
+	}
+}
+
+func TestBooleanArray_MergeResolve(t *testing.T) {
+	a := makeBooleanArray(0, true, 1, false)
+	b := makeBooleanArray(0, false, 1, true)
+	if n := a.MergeResolve(b, cursors.DuplicateResolutionMax); n != 2 {
+		t.Errorf("unexpected duplicates; got=%d, exp=2", n)
+	}
+	if exp := makeBooleanArray(0, true, 1, true); !cmp.Equal(a, exp) {
+		t.Fatalf("unexpected values -got/+exp\n%s", cmp.Diff(a, exp))
+	}
+}
@@ -66,12 +66,14 @@ func (a CursorIterators) Stats() CursorStats {
 
 // CursorStats represents stats collected by a cursor.
 type CursorStats struct {
-	ScannedValues int // number of values scanned
-	ScannedBytes  int // number of uncompressed bytes scanned
+	ScannedValues   int // number of values scanned
+	ScannedBytes    int // number of uncompressed bytes scanned
+	DuplicateValues int // number of values with the same timestamp resolved across TSM files
 }
 
 // Add adds other to s and updates s.
 func (s *CursorStats) Add(other CursorStats) {
 	s.ScannedValues += other.ScannedValues
 	s.ScannedBytes += other.ScannedBytes
+	s.DuplicateValues += other.DuplicateValues
 }
@@ -0,0 +1,34 @@
+package cursors
+
+import "context"
+
+// DuplicateResolution selects the value read when the overlapping blocks of
+// several TSM files hold values with the same timestamp.
+type DuplicateResolution int
+
+const (
+	// DuplicateResolutionNewest reads the value of the newest file.
+	DuplicateResolutionNewest DuplicateResolution = iota
+
+	// DuplicateResolutionMax reads the largest value. For boolean values,
+	// true is larger than false.
+	DuplicateResolutionMax
+
+	// DuplicateResolutionMin reads the smallest value.
+	DuplicateResolutionMin
+)
+
+type duplicateResolutionKey struct{}
+
+// NewContextWithDuplicateResolution returns a new context with r, used by the
+// cursors created with the context.
+func NewContextWithDuplicateResolution(ctx context.Context, r DuplicateResolution) context.Context {
+	return context.WithValue(ctx, duplicateResolutionKey{}, r)
+}
+
+// DuplicateResolutionFromContext returns the DuplicateResolution of ctx, or
+// DuplicateResolutionNewest if none has been set.
+func DuplicateResolutionFromContext(ctx context.Context) DuplicateResolution {
+	r, _ := ctx.Value(duplicateResolutionKey{}).(DuplicateResolution)
+	return r
+}
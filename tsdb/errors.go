@@ -21,8 +21,29 @@ type PartialWriteError struct {
 
 	// A sorted slice of series keys that were dropped.
 	DroppedKeys [][]byte
+
+	// The points that were dropped, sorted by their index in the write.
+	Points []DroppedPoint
+
+	// Err is the error that caused the first point to be dropped, if it is
+	// more specific than Reason.
+	Err error
 }
 
 func (e PartialWriteError) Error() string {
 	return fmt.Sprintf("partial write: %s dropped=%d", e.Reason, e.Dropped)
 }
+
+// Unwrap returns the error that caused the first point to be dropped.
+func (e PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
+// DroppedPoint is a point that was dropped from a write.
+type DroppedPoint struct {
+	// Index is the index of the point in the write, or -1 if it is unknown.
+	Index int
+
+	// Reason is why the point was dropped.
+	Reason string
+}
@@ -48,6 +48,10 @@ type seriesFileMetrics struct {
 	CompactionDuration *prometheus.HistogramVec // Duration of compactions.
 	// The following metrics include a ``"status" = {ok, error}` label
 	Compactions *prometheus.CounterVec // Total number of compactions.
+
+	SegmentCompactions        *prometheus.CounterVec // Total number of segment compactions.
+	SegmentCompactionProgress *prometheus.GaugeVec   // Fraction of the running segment compaction completed.
+	SeriesRemoved             *prometheus.CounterVec // Number of deleted series removed by segment compactions.
 }
 
 // newSeriesFileMetrics initialises the prometheus metrics for tracking the Series File.
@@ -93,13 +97,13 @@ func newSeriesFileMetrics(labels prometheus.Labels) *seriesFileMetrics {
 			Namespace: namespace,
 			Subsystem: seriesFileSubsystem,
 			Name:      "index_compactions_active",
-			Help:      "Number of active index compactions.",
+			Help:      "Number of active index and segment compactions.",
 		}, durationCompaction),
 		CompactionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: seriesFileSubsystem,
 			Name:      "index_compactions_duration_seconds",
-			Help:      "Time taken for a successful compaction of index.",
+			Help:      "Time taken for a successful compaction of index or segments.",
 			// 30 buckets spaced exponentially between 5s and ~53 minutes.
 			Buckets: prometheus.ExponentialBuckets(5.0, 1.25, 30),
 		}, durationCompaction),
@@ -109,6 +113,24 @@ func newSeriesFileMetrics(labels prometheus.Labels) *seriesFileMetrics {
 			Name:      "compactions_total",
 			Help:      "Number of compactions.",
 		}, totalCompactions),
+		SegmentCompactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: seriesFileSubsystem,
+			Name:      "segment_compactions_total",
+			Help:      "Number of segment compactions.",
+		}, totalCompactions),
+		SegmentCompactionProgress: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: seriesFileSubsystem,
+			Name:      "segment_compaction_progress",
+			Help:      "Fraction of the entries of the running segment compaction processed.",
+		}, names),
+		SeriesRemoved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: seriesFileSubsystem,
+			Name:      "series_removed",
+			Help:      "Number of deleted series removed from segments by segment compactions.",
+		}, names),
 	}
 }
 
@@ -122,5 +144,8 @@ func (m *seriesFileMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.CompactionsActive,
 		m.CompactionDuration,
 		m.Compactions,
+		m.SegmentCompactions,
+		m.SegmentCompactionProgress,
+		m.SeriesRemoved,
 	}
 }
@@ -31,11 +31,14 @@ func TestMetrics_SeriesPartition(t *testing.T) {
 		base + "disk_bytes",
 		base + "segments_total",
 		base + "index_compactions_active",
+		base + "segment_compaction_progress",
 	}
 
 	counters := []string{
 		base + "series_created",
 		base + "compactions_total",
+		base + "segment_compactions_total",
+		base + "series_removed",
 	}
 
 	histograms := []string{
@@ -51,11 +54,16 @@ func TestMetrics_SeriesPartition(t *testing.T) {
 		labels := tracker.Labels()
 		labels["component"] = "index"
 		tracker.metrics.CompactionsActive.With(labels).Add(float64(i + len(gauges[3])))
+		tracker.SetSegmentCompactionProgress(float64(i + len(gauges[4])))
 
 		tracker.AddSeriesCreated(uint64(i + len(counters[0])))
 		labels = tracker.Labels()
 		labels["status"] = "ok"
 		tracker.metrics.Compactions.With(labels).Add(float64(i + len(counters[1])))
+		labels = tracker.Labels()
+		labels["status"] = "ok"
+		tracker.metrics.SegmentCompactions.With(labels).Add(float64(i + len(counters[2])))
+		tracker.AddSeriesRemoved(uint64(i + len(counters[3])))
 
 		labels = tracker.Labels()
 		labels["component"] = "index"
@@ -101,7 +109,7 @@ func TestMetrics_SeriesPartition(t *testing.T) {
 		for _, name := range counters {
 			exp := float64(i + len(name))
 
-			if name == base+"compactions_total" {
+			if name == base+"compactions_total" || name == base+"segment_compactions_total" {
 				// Make a copy since we need to add a label
 				l := make(prometheus.Labels, len(labels))
 				for k, v := range labels {
@@ -1,6 +1,7 @@
 package tsdb
 
 import (
+	"sort"
 	"sync"
 	"sync/atomic"
 	"unsafe"
@@ -20,10 +21,15 @@ type SeriesCollection struct {
 	Types      []models.FieldType
 	SeriesIDs  []SeriesID
 
+	// Indexes holds the index of each entry in the points the collection was
+	// built from, so that dropped entries can be reported against the write.
+	Indexes []int
+
 	// Keeps track of invalid entries.
-	Dropped     uint64
-	DroppedKeys [][]byte
-	Reason      string
+	Dropped       uint64
+	DroppedKeys   [][]byte
+	DroppedPoints []DroppedPoint
+	Reason        string
 
 	// Used by the concurrent iterators to stage drops. Inefficient, but should be
 	// very infrequently used.
@@ -32,9 +38,8 @@ type SeriesCollection struct {
 
 // seriesCollectionState keeps track of concurrent iterator state.
 type seriesCollectionState struct {
-	mu     sync.Mutex
-	reason string
-	index  map[int]struct{}
+	mu    sync.Mutex
+	index map[int]string
 }
 
 // NewSeriesCollection builds a SeriesCollection from a slice of points. It does some filtering
@@ -46,9 +51,12 @@ func NewSeriesCollection(points []models.Point) *SeriesCollection {
 		Names:  make([][]byte, 0, len(points)),
 		Tags:   make([]models.Tags, 0, len(points)),
 		Types:  make([]models.FieldType, 0, len(points)),
+
+		Indexes: make([]int, 0, len(points)),
 	}
 
-	for _, pt := range points {
+	for i, pt := range points {
+		out.Indexes = append(out.Indexes, i)
 		out.Keys = append(out.Keys, pt.Key())
 		out.Names = append(out.Names, pt.Name())
 		out.Tags = append(out.Tags, pt.Tags())
@@ -115,6 +123,9 @@ func (s *SeriesCollection) Copy(dst, src int) {
 	if n := uint(len(s.SeriesIDs)); udst < n && usrc < n {
 		s.SeriesIDs[udst] = s.SeriesIDs[usrc]
 	}
+	if n := uint(len(s.Indexes)); udst < n && usrc < n {
+		s.Indexes[udst] = s.Indexes[usrc]
+	}
 }
 
 // Swap will swap the elements at i and j in all slices that can: x[i], x[j] = x[j], x[i].
@@ -144,6 +155,9 @@ func (s *SeriesCollection) Swap(i, j int) {
 	if n := uint(len(s.SeriesIDs)); ui < n && uj < n {
 		s.SeriesIDs[ui], s.SeriesIDs[uj] = s.SeriesIDs[uj], s.SeriesIDs[ui]
 	}
+	if n := uint(len(s.Indexes)); ui < n && uj < n {
+		s.Indexes[ui], s.Indexes[uj] = s.Indexes[uj], s.Indexes[ui]
+	}
 }
 
 // Truncate will truncate all of the slices that can down to length: x = x[:length].
@@ -170,6 +184,9 @@ func (s *SeriesCollection) Truncate(length int) {
 	if ulength < uint(len(s.SeriesIDs)) {
 		s.SeriesIDs = s.SeriesIDs[:ulength]
 	}
+	if ulength < uint(len(s.Indexes)) {
+		s.Indexes = s.Indexes[:ulength]
+	}
 }
 
 // Advance will advance all of the slices that can length elements: x = x[length:].
@@ -196,15 +213,35 @@ func (s *SeriesCollection) Advance(length int) {
 	if ulength < uint(len(s.SeriesIDs)) {
 		s.SeriesIDs = s.SeriesIDs[ulength:]
 	}
+	if ulength < uint(len(s.Indexes)) {
+		s.Indexes = s.Indexes[ulength:]
+	}
 }
 
-// InvalidateAll causes all of the entries to become invalid.
-func (s *SeriesCollection) InvalidateAll(reason string) {
+// Invalidate records the entry at index as dropped with the reason. Only the first reason is
+// kept in Reason. It does not remove the entry, so it must be called before the entry is
+// overwritten by Copy or removed by Truncate.
+func (s *SeriesCollection) Invalidate(index int, reason string) {
 	if s.Reason == "" {
 		s.Reason = reason
 	}
-	s.Dropped += uint64(len(s.Keys))
-	s.DroppedKeys = append(s.DroppedKeys, s.Keys...)
+	s.Dropped++
+	if index < len(s.Keys) {
+		s.DroppedKeys = append(s.DroppedKeys, s.Keys[index])
+	}
+
+	point := DroppedPoint{Index: -1, Reason: reason}
+	if index < len(s.Indexes) {
+		point.Index = s.Indexes[index]
+	}
+	s.DroppedPoints = append(s.DroppedPoints, point)
+}
+
+// InvalidateAll causes all of the entries to become invalid.
+func (s *SeriesCollection) InvalidateAll(reason string) {
+	for i := range s.Keys {
+		s.Invalidate(i, reason)
+	}
 	s.Truncate(0)
 }
 
@@ -218,13 +255,8 @@ func (s *SeriesCollection) ApplyConcurrentDrops() {
 
 	length, j := s.Length(), 0
 	for i := 0; i < length; i++ {
-		if _, ok := state.index[i]; ok {
-			s.Dropped++
-
-			if i < len(s.Keys) {
-				s.DroppedKeys = append(s.DroppedKeys, s.Keys[i])
-			}
-
+		if reason, ok := state.index[i]; ok {
+			s.Invalidate(i, reason)
 			continue
 		}
 
@@ -233,10 +265,6 @@ func (s *SeriesCollection) ApplyConcurrentDrops() {
 	}
 	s.Truncate(j)
 
-	if s.Reason == "" {
-		s.Reason = state.reason
-	}
-
 	// clear concurrent state
 	atomic.StorePointer((*unsafe.Pointer)(unsafe.Pointer(&s.state)), nil)
 }
@@ -266,11 +294,10 @@ func (s *SeriesCollection) invalidIndex(index int, reason string) {
 
 	state.mu.Lock()
 	if state.index == nil {
-		state.index = make(map[int]struct{})
+		state.index = make(map[int]string)
 	}
-	state.index[index] = struct{}{}
-	if state.reason == "" {
-		state.reason = reason
+	if _, ok := state.index[index]; !ok {
+		state.index[index] = reason
 	}
 	state.mu.Unlock()
 }
@@ -282,10 +309,13 @@ func (s *SeriesCollection) PartialWriteError() error {
 		return nil
 	}
 	droppedKeys := bytesutil.SortDedup(s.DroppedKeys)
+	points := append([]DroppedPoint(nil), s.DroppedPoints...)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].Index < points[j].Index })
 	return PartialWriteError{
 		Reason:      s.Reason,
 		Dropped:     len(droppedKeys),
 		DroppedKeys: droppedKeys,
+		Points:      points,
 	}
 }
 
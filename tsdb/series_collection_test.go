@@ -121,6 +121,7 @@ func TestSeriesCollection(t *testing.T) {
 			Reason:      "test reason",
 			Dropped:     3,
 			DroppedKeys: bs("ka", "kb", "kc"),
+			Points:      []DroppedPoint{{-1, "test reason"}, {-1, "test reason"}, {-1, "test reason"}},
 		})
 	})
 
@@ -144,6 +145,26 @@ func TestSeriesCollection(t *testing.T) {
 			Reason:      "test reason",
 			Dropped:     2,
 			DroppedKeys: bs("ka", "kc"),
+			Points:      []DroppedPoint{{-1, "test reason"}, {-1, "test reason"}},
+		})
+	})
+
+	t.Run("Indexes", func(t *testing.T) {
+		collection := NewSeriesCollection([]models.Point{
+			models.MustNewPoint("a", nil, models.Fields{"f": 1.0}, time.Unix(0, 0)),
+			models.MustNewPoint("b", nil, models.Fields{"f": 1.0}, time.Unix(0, 0)),
+			models.MustNewPoint("c", nil, models.Fields{"f": 1.0}, time.Unix(0, 0)),
+		})
+
+		// dropped entries are reported by their index in the points
+		collection.Invalidate(1, "reason b")
+		collection.Copy(1, 2)
+		collection.Truncate(2)
+		assertEqual(t, "indexes", collection.Indexes, []int{0, 2})
+
+		collection.InvalidateAll("reason all")
+		assertEqual(t, "points", collection.PartialWriteError().(PartialWriteError).Points, []DroppedPoint{
+			{0, "reason all"}, {1, "reason b"}, {2, "reason all"},
 		})
 	})
 }
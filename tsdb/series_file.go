@@ -51,6 +51,11 @@ type SeriesFile struct {
 
 	LargeWriteThreshold int
 
+	// ReadOnly opens the series file without preparing it for writes, so it
+	// can be opened from a snapshot or a replicated volume. It must be set
+	// before Open.
+	ReadOnly bool
+
 	Logger *zap.Logger
 }
 
@@ -127,6 +132,7 @@ func (f *SeriesFile) Open(ctx context.Context) error {
 		// TODO(edd): These partition initialisation should be moved up to NewSeriesFile.
 		p := NewSeriesPartition(i, f.SeriesPartitionPath(i))
 		p.LargeWriteThreshold = f.LargeWriteThreshold
+		p.ReadOnly = f.ReadOnly
 		p.Logger = f.Logger.With(zap.Int("partition", p.ID()))
 
 		// For each series file index, rhh trackers are used to track the RHH Hashmap.
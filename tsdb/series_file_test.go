@@ -271,6 +271,101 @@ func TestSeriesFile_DeleteSeriesID(t *testing.T) {
 	}
 }
 
+// Ensure deleted series are removed from the inactive segments of a partition.
+func TestSeriesFile_CompactSegments(t *testing.T) {
+	sfile := MustOpenSeriesFile()
+	defer sfile.Close()
+	p := sfile.Partitions()[0]
+
+	// Write enough series to fill the first segment.
+	const n = 6000
+	var collection tsdb.SeriesCollection
+	for i := 0; i < n; i++ {
+		collection.Names = append(collection.Names, []byte("cpu"))
+		collection.Tags = append(collection.Tags, models.Tags{
+			{Key: []byte("host"), Value: []byte(fmt.Sprintf("%01000d", i))},
+		})
+		collection.Types = append(collection.Types, models.Integer)
+	}
+	collection.SeriesKeys = tsdb.GenerateSeriesKeys(collection.Names, collection.Tags)
+	collection.SeriesIDs = make([]tsdb.SeriesID, n)
+	if err := p.CreateSeriesListIfNotExists(&collection, make([]int, n)); err != nil {
+		t.Fatal(err)
+	}
+	ids := append([]tsdb.SeriesID(nil), collection.SeriesIDs...)
+
+	// Nothing is removed until series are deleted.
+	if removed, err := p.CompactSegments(context.Background(), 0.25); err != nil {
+		t.Fatal(err)
+	} else if removed != 0 {
+		t.Fatalf("removed %d series, expected none", removed)
+	}
+
+	const deleted = 3000
+	for _, id := range ids[:deleted] {
+		if err := p.DeleteSeriesID(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The threshold is not reached by the deleted series.
+	if removed, err := p.CompactSegments(context.Background(), 0.9); err != nil {
+		t.Fatal(err)
+	} else if removed != 0 {
+		t.Fatalf("removed %d series, expected none", removed)
+	}
+
+	if removed, err := sfile.CompactSegments(context.Background(), 0.25); err != nil {
+		t.Fatal(err)
+	} else if removed != deleted {
+		t.Fatalf("removed %d series, expected %d", removed, deleted)
+	}
+
+	verify := func(p *tsdb.SeriesPartition) {
+		t.Helper()
+		for i, id := range ids {
+			if i < deleted {
+				if !p.IsDeleted(id) {
+					t.Fatalf("series %d not deleted", id)
+				} else if key := p.SeriesKey(id); key != nil {
+					t.Fatalf("unexpected key for deleted series %d", id)
+				}
+				continue
+			}
+			if got := p.FindIDBySeriesKey(collection.SeriesKeys[i]); got != id {
+				t.Fatalf("got id %d for series %d", got, id)
+			} else if key := p.SeriesKey(id); !bytes.Equal(key, collection.SeriesKeys[i]) {
+				t.Fatalf("unexpected key for series %d", id)
+			}
+		}
+		if got, exp := p.SeriesCount(), uint64(n-deleted); got != exp {
+			t.Fatalf("SeriesCount()=%d, expected %d", got, exp)
+		}
+	}
+	verify(p)
+
+	// The compacted segments are used once the series file is reopened, and
+	// ids are not assigned again.
+	if err := sfile.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	p = sfile.Partitions()[0]
+	verify(p)
+
+	collection = tsdb.SeriesCollection{
+		Names: [][]byte{[]byte("mem")},
+		Tags:  []models.Tags{nil},
+		Types: []models.FieldType{models.Integer},
+	}
+	collection.SeriesKeys = tsdb.GenerateSeriesKeys(collection.Names, collection.Tags)
+	collection.SeriesIDs = make([]tsdb.SeriesID, 1)
+	if err := p.CreateSeriesListIfNotExists(&collection, []int{0}); err != nil {
+		t.Fatal(err)
+	} else if id := collection.SeriesIDs[0]; !id.Greater(ids[n-1]) {
+		t.Fatalf("got id %d for new series, expected greater than %d", id, ids[n-1])
+	}
+}
+
 // Series represents name/tagset pairs that are used in testing.
 type Series struct {
 	Name    []byte
@@ -23,6 +23,7 @@ import (
 var (
 	ErrSeriesPartitionClosed              = errors.New("tsdb: series partition closed")
 	ErrSeriesPartitionCompactionCancelled = errors.New("tsdb: series partition compaction cancelled")
+	ErrSeriesPartitionReadOnly            = errors.New("tsdb: series partition is read-only")
 )
 
 // DefaultSeriesPartitionCompactThreshold is the number of series IDs to hold in the in-memory
@@ -44,12 +45,21 @@ type SeriesPartition struct {
 	index    *SeriesIndex
 	seq      uint64 // series id sequence
 
+	// retired holds the segments replaced by segment compactions. They stay
+	// mapped until the partition is closed since keys read from them may
+	// still be in use.
+	retired []*SeriesSegment
+
 	compacting          bool
 	compactionsDisabled int
 
 	CompactThreshold    int
 	LargeWriteThreshold int
 
+	// ReadOnly opens the partition without preparing its active segment for
+	// writes. Series can not be created or deleted in a read-only partition.
+	ReadOnly bool
+
 	tracker *seriesPartitionTracker
 	Logger  *zap.Logger
 }
@@ -87,8 +97,10 @@ func (p *SeriesPartition) Open() error {
 			return err
 		}
 		// Init last segment for writes.
-		if err := p.activeSegment().InitForWrite(); err != nil {
-			return err
+		if !p.ReadOnly {
+			if err := p.activeSegment().InitForWrite(); err != nil {
+				return err
+			}
 		}
 
 		if err := p.index.Open(); err != nil {
@@ -108,6 +120,11 @@ func (p *SeriesPartition) Open() error {
 }
 
 func (p *SeriesPartition) openSegments() error {
+	// Finish or discard a segment compaction interrupted by a crash.
+	if err := p.recoverSegmentRewrite(); err != nil {
+		return err
+	}
+
 	fis, err := ioutil.ReadDir(p.path)
 	if err != nil {
 		return err
@@ -136,7 +153,7 @@ func (p *SeriesPartition) openSegments() error {
 	}
 
 	// Create initial segment if none exist.
-	if len(p.segments) == 0 {
+	if len(p.segments) == 0 && !p.ReadOnly {
 		segment, err := CreateSeriesSegment(0, filepath.Join(p.path, "0000"))
 		if err != nil {
 			return err
@@ -165,6 +182,13 @@ func (p *SeriesPartition) Close() (err error) {
 	}
 	p.segments = nil
 
+	for _, s := range p.retired {
+		if e := s.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	p.retired = nil
+
 	if p.index != nil {
 		if e := p.index.Close(); e != nil && err == nil {
 			err = e
@@ -221,6 +245,8 @@ func (p *SeriesPartition) CreateSeriesListIfNotExists(collection *SeriesCollecti
 	// Exit if all series for this partition already exist.
 	if writeRequired == 0 {
 		return nil
+	} else if p.ReadOnly {
+		return ErrSeriesPartitionReadOnly
 	}
 
 	type keyRange struct {
@@ -312,7 +338,7 @@ func (p *SeriesPartition) CreateSeriesListIfNotExists(collection *SeriesCollecti
 		log, logEnd := logger.NewOperation(ctx, p.Logger, "Series partition compaction", "series_partition_compaction", zap.String("path", p.path))
 
 		p.wg.Add(1)
-		p.tracker.IncCompactionsActive()
+		p.tracker.IncCompactionsActive("index")
 		go func() {
 			defer p.wg.Done()
 
@@ -332,7 +358,7 @@ func (p *SeriesPartition) CreateSeriesListIfNotExists(collection *SeriesCollecti
 			p.mu.Lock()
 			p.compacting = false
 			p.mu.Unlock()
-			p.tracker.DecCompactionsActive()
+			p.tracker.DecCompactionsActive("index")
 
 			// Disk size may have changed due to compaction.
 			p.tracker.SetDiskSize(p.DiskSize())
@@ -357,6 +383,8 @@ func (p *SeriesPartition) DeleteSeriesID(id SeriesID) error {
 
 	if p.closed {
 		return ErrSeriesPartitionClosed
+	} else if p.ReadOnly {
+		return ErrSeriesPartitionReadOnly
 	}
 
 	// Already tombstoned, ignore.
@@ -485,6 +513,8 @@ func (p *SeriesPartition) compactionsEnabled() bool {
 
 // AppendSeriesIDs returns a list of all series ids.
 func (p *SeriesPartition) AppendSeriesIDs(a []SeriesID) []SeriesID {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	for _, segment := range p.segments {
 		a = segment.AppendSeriesIDs(a)
 	}
@@ -655,27 +685,27 @@ func (t *seriesPartitionTracker) SetSegments(n uint64) {
 	t.metrics.Segments.With(labels).Set(float64(n))
 }
 
-// IncCompactionsActive increments the number of active compactions for the
-// components of a partition (index and segments).
-func (t *seriesPartitionTracker) IncCompactionsActive() {
+// IncCompactionsActive increments the number of active compactions for a
+// component of a partition ("index" or "segments").
+func (t *seriesPartitionTracker) IncCompactionsActive(component string) {
 	if !t.enabled {
 		return
 	}
 
 	labels := t.Labels()
-	labels["component"] = "index" // TODO(edd): when we add segment compactions we will add a new label value.
+	labels["component"] = component
 	t.metrics.CompactionsActive.With(labels).Inc()
 }
 
-// DecCompactionsActive decrements the number of active compactions for the
-// components of a partition (index and segments).
-func (t *seriesPartitionTracker) DecCompactionsActive() {
+// DecCompactionsActive decrements the number of active compactions for a
+// component of a partition ("index" or "segments").
+func (t *seriesPartitionTracker) DecCompactionsActive(component string) {
 	if !t.enabled {
 		return
 	}
 
 	labels := t.Labels()
-	labels["component"] = "index" // TODO(edd): when we add segment compactions we will add a new label value.
+	labels["component"] = component
 	t.metrics.CompactionsActive.With(labels).Dec()
 }
 
@@ -705,6 +735,57 @@ func (t *seriesPartitionTracker) IncCompactionOK(duration time.Duration) {
 // IncCompactionErr increments the number of failed compactions for the partition.
 func (t *seriesPartitionTracker) IncCompactionErr() { t.incCompactions("error", 0) }
 
+// incSegmentCompactions increments the number of segment compactions for the
+// partition. Callers should use IncSegmentCompactionOK and
+// IncSegmentCompactionErr.
+func (t *seriesPartitionTracker) incSegmentCompactions(status string, duration time.Duration) {
+	if !t.enabled {
+		return
+	}
+
+	if duration > 0 {
+		labels := t.Labels()
+		labels["component"] = "segments"
+		t.metrics.CompactionDuration.With(labels).Observe(duration.Seconds())
+	}
+
+	labels := t.Labels()
+	labels["status"] = status
+	t.metrics.SegmentCompactions.With(labels).Inc()
+}
+
+// IncSegmentCompactionOK increments the number of successful segment compactions
+// for the partition.
+func (t *seriesPartitionTracker) IncSegmentCompactionOK(duration time.Duration) {
+	t.incSegmentCompactions("ok", duration)
+}
+
+// IncSegmentCompactionErr increments the number of failed segment compactions
+// for the partition.
+func (t *seriesPartitionTracker) IncSegmentCompactionErr() { t.incSegmentCompactions("error", 0) }
+
+// SetSegmentCompactionProgress sets the fraction of the running segment
+// compaction completed.
+func (t *seriesPartitionTracker) SetSegmentCompactionProgress(v float64) {
+	if !t.enabled {
+		return
+	}
+
+	labels := t.Labels()
+	t.metrics.SegmentCompactionProgress.With(labels).Set(v)
+}
+
+// AddSeriesRemoved increases the number of series removed from the segments
+// of the partition by n.
+func (t *seriesPartitionTracker) AddSeriesRemoved(n uint64) {
+	if !t.enabled {
+		return
+	}
+
+	labels := t.Labels()
+	t.metrics.SeriesRemoved.With(labels).Add(float64(n))
+}
+
 // SeriesPartitionCompactor represents an object reindexes a series partition and optionally compacts segments.
 type SeriesPartitionCompactor struct {
 	cancel <-chan struct{}
@@ -0,0 +1,469 @@
+package tsdb
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/pkg/fs"
+	"go.uber.org/zap"
+)
+
+const (
+	// seriesSegmentRewriteExt is the extension of the segments and index
+	// written by a segment compaction before they replace the existing ones.
+	seriesSegmentRewriteExt = ".rewrite"
+
+	// seriesSegmentRewriteMarker is the name of the file committing a segment
+	// compaction. It lists the segment files the compaction removes.
+	seriesSegmentRewriteMarker = "REWRITE"
+)
+
+// CompactSegments rewrites the segments of every partition in which at least
+// threshold of the data, between 0 and 1, is taken by deleted series. It
+// returns the number of deleted series removed.
+func (f *SeriesFile) CompactSegments(ctx context.Context, threshold float64) (int, error) {
+	var n int
+	for _, p := range f.partitions {
+		removed, err := p.CompactSegments(ctx, threshold)
+		n += removed
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// CompactSegments rewrites the segments of the partition without the entries
+// of deleted series, if at least threshold of their data, between 0 and 1, is
+// taken by them. It returns the number of deleted series removed.
+//
+// The active segment is not rewritten. The remaining entries are packed into
+// as few segments as possible and the series index is rebuilt for them, while
+// series IDs are left unchanged. Tombstones are dropped along with the series
+// they delete, and IDs without an insert entry are considered deleted.
+func (p *SeriesPartition) CompactSegments(ctx context.Context, threshold float64) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return 0, ErrSeriesPartitionClosed
+	} else if p.ReadOnly {
+		p.mu.Unlock()
+		return 0, ErrSeriesPartitionReadOnly
+	} else if p.compacting || !p.compactionsEnabled() || len(p.segments) < 2 {
+		p.mu.Unlock()
+		return 0, nil
+	}
+	p.compacting = true
+	p.wg.Add(1)
+
+	// Snapshot the segments and index. Only the active segment is written to
+	// while the compaction runs.
+	segments := CloneSeriesSegments(p.segments)
+	index := p.index.Clone()
+	seriesN := p.index.Count()
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.compacting = false
+		p.mu.Unlock()
+		p.wg.Done()
+	}()
+
+	c := &seriesSegmentCompactor{
+		p:        p,
+		segments: segments[:len(segments)-1],
+		active:   segments[len(segments)-1],
+		index:    index,
+		seriesN:  seriesN,
+		cancel:   p.closing,
+	}
+
+	if !c.plan(threshold) {
+		return 0, nil
+	}
+
+	log, logEnd := logger.NewOperation(ctx, p.Logger, "Series segment compaction", "series_segment_compaction", zap.String("path", p.path))
+	defer logEnd()
+
+	p.tracker.IncCompactionsActive("segments")
+	defer p.tracker.DecCompactionsActive("segments")
+	defer p.tracker.SetSegmentCompactionProgress(0)
+
+	now := time.Now()
+	if err := c.compact(ctx); err != nil {
+		c.cleanup()
+		p.tracker.IncSegmentCompactionErr()
+		log.Error("Series segment compaction failed", zap.Error(err))
+		return 0, err
+	}
+	p.tracker.IncSegmentCompactionOK(time.Since(now))
+	p.tracker.AddSeriesRemoved(uint64(c.removedN))
+	log.Info("Compacted series segments", zap.Int("series_removed", c.removedN),
+		zap.Int("segments_before", len(c.segments)), zap.Int("segments_after", c.segmentN))
+	return c.removedN, nil
+}
+
+// seriesSegmentCompactor rewrites the inactive segments of a partition.
+type seriesSegmentCompactor struct {
+	p        *SeriesPartition
+	segments []*SeriesSegment // inactive segments being rewritten
+	active   *SeriesSegment
+	index    *SeriesIndex
+	seriesN  uint64
+	cancel   <-chan struct{}
+
+	maxID    SeriesID // greatest series id inserted in segments
+	entryN   int      // number of entries in segments
+	removedN int      // number of deleted series removed
+	segmentN int      // number of segments written
+
+	rewritten []*SeriesSegment // segments written by the compaction
+}
+
+// plan determines whether enough of the data of the inactive segments is taken
+// by deleted series to rewrite them.
+func (c *seriesSegmentCompactor) plan(threshold float64) bool {
+	var total, deleted int64
+	for _, segment := range c.segments {
+		segment.ForEachEntry(func(flag uint8, id SeriesIDTyped, offset int64, key []byte) error {
+			sz := int64(SeriesEntryHeaderSize + len(key))
+			total += sz
+			c.entryN++
+
+			switch flag {
+			case SeriesEntryInsertFlag:
+				untypedID := id.SeriesID()
+				if untypedID.Greater(c.maxID) {
+					c.maxID = untypedID
+				}
+				if c.index.IsDeleted(untypedID) {
+					deleted += sz
+				}
+			case SeriesEntryTombstoneFlag:
+				deleted += sz
+			}
+			return nil
+		})
+	}
+	return deleted > 0 && float64(deleted) >= threshold*float64(total)
+}
+
+// compact writes the rewritten segments and index, and swaps them in.
+func (c *seriesSegmentCompactor) compact(ctx context.Context) error {
+	if err := c.writeSegments(ctx); err != nil {
+		return err
+	}
+
+	// Index the rewritten segments and the active segment as of the snapshot.
+	// Later entries are replayed once the index is swapped in.
+	segments := append(append([]*SeriesSegment(nil), c.rewritten...), c.active)
+	compactor := NewSeriesPartitionCompactor()
+	compactor.cancel = c.cancel
+	if err := compactor.compactIndexTo(c.index, c.seriesN, segments, c.p.IndexPath()+seriesSegmentRewriteExt); err != nil {
+		return err
+	}
+
+	return c.swap()
+}
+
+// writeSegments writes the entries of the series that are not deleted to new
+// segments, starting with the id of the first inactive segment.
+//
+// The insert entry of the greatest series id is always kept, followed by a
+// tombstone if it was deleted, so that ids are not assigned again when the
+// partition is reopened.
+func (c *seriesSegmentCompactor) writeSegments(ctx context.Context) error {
+	w := &seriesSegmentRewriter{dir: c.p.path, id: c.segments[0].ID()}
+	defer func() {
+		w.close()
+		c.rewritten = w.segments
+	}()
+
+	var n int
+	for _, segment := range c.segments {
+		if err := segment.ForEachEntry(func(flag uint8, id SeriesIDTyped, offset int64, key []byte) error {
+			// Check for cancellation periodically.
+			if n++; n%1000 == 0 {
+				select {
+				case <-c.cancel:
+					return ErrSeriesPartitionCompactionCancelled
+				default:
+				}
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				c.p.tracker.SetSegmentCompactionProgress(float64(n) / float64(c.entryN))
+			}
+
+			if flag != SeriesEntryInsertFlag {
+				return nil
+			}
+
+			untypedID := id.SeriesID()
+			deleted := c.index.IsDeleted(untypedID)
+			if deleted && untypedID != c.maxID {
+				c.removedN++
+				return nil
+			}
+
+			if err := w.write(AppendSeriesEntry(nil, SeriesEntryInsertFlag, id, key)); err != nil {
+				return err
+			}
+			if deleted {
+				return w.write(AppendSeriesEntry(nil, SeriesEntryTombstoneFlag, id, nil))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := w.close(); err != nil {
+		return err
+	}
+	c.segmentN = len(w.segments)
+
+	// The remaining entries always fit in as many segments as before, but a
+	// tombstone added for the greatest series id could overflow them.
+	if n := len(w.segments); n > 0 && w.segments[n-1].ID() >= c.active.ID() {
+		return fmt.Errorf("series segment compaction overflows active segment %04x", c.active.ID())
+	}
+	return nil
+}
+
+// swap replaces the inactive segments and the index with the rewritten ones.
+func (c *seriesSegmentCompactor) swap() error {
+	p := c.p
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return ErrSeriesPartitionClosed
+	}
+
+	// Commit the compaction by listing the segment files it removes. From
+	// here on an interrupted compaction is finished when the partition opens.
+	var removed []string
+	for _, segment := range c.segments[len(c.rewritten):] {
+		removed = append(removed, filepath.Base(segment.path))
+	}
+	if err := writeSeriesSegmentRewriteMarker(p.path, removed); err != nil {
+		return err
+	}
+
+	if err := p.index.Close(); err != nil {
+		return err
+	} else if err := finishSeriesSegmentRewrite(p.path, removed); err != nil {
+		return err
+	}
+
+	// The rewritten segments were opened under their temporary names.
+	for _, segment := range c.rewritten {
+		segment.path = strings.TrimSuffix(segment.path, seriesSegmentRewriteExt)
+	}
+
+	// Keep the replaced segments mapped and swap in the rewritten ones. Any
+	// segments created since the snapshot follow the active segment.
+	p.retired = append(p.retired, p.segments[:len(c.segments)]...)
+	p.segments = append(c.rewritten, p.segments[len(c.segments):]...)
+	c.rewritten = nil
+
+	if err := p.index.Open(); err != nil {
+		return err
+	} else if err := p.index.Recover(p.segments); err != nil {
+		return err
+	}
+
+	p.tracker.SetSegments(uint64(len(p.segments)))
+	p.tracker.SetSeries(p.index.Count())
+	p.tracker.SetDiskSize(p.diskSize())
+	return nil
+}
+
+// cleanup removes the files written by a failed compaction.
+func (c *seriesSegmentCompactor) cleanup() {
+	for _, segment := range c.rewritten {
+		segment.Close()
+	}
+	c.rewritten = nil
+
+	// Nothing is removed once the compaction has been committed.
+	if _, err := os.Stat(filepath.Join(c.p.path, seriesSegmentRewriteMarker)); err == nil {
+		return
+	}
+	removeSeriesSegmentRewrites(c.p.path)
+}
+
+// seriesSegmentRewriter writes entries to new segments, starting a new segment
+// when an entry does not fit in the current one.
+type seriesSegmentRewriter struct {
+	dir string
+	id  uint16
+
+	f    *os.File
+	w    *bufio.Writer
+	size uint32
+
+	segments []*SeriesSegment
+}
+
+// write appends an entry to the current segment.
+func (w *seriesSegmentRewriter) write(data []byte) error {
+	if w.f != nil && w.size+uint32(len(data)) > SeriesSegmentSize(w.id) {
+		if err := w.close(); err != nil {
+			return err
+		}
+		w.id++
+	}
+
+	if w.f == nil {
+		f, err := fs.CreateFile(filepath.Join(w.dir, fmt.Sprintf("%04x", w.id)+seriesSegmentRewriteExt))
+		if err != nil {
+			return err
+		}
+		w.f, w.w = f, bufio.NewWriterSize(f, 32*1024)
+
+		hdr := NewSeriesSegmentHeader()
+		n, err := hdr.WriteTo(w.w)
+		if err != nil {
+			return err
+		}
+		w.size = uint32(n)
+	}
+
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	w.size += uint32(len(data))
+	return nil
+}
+
+// close syncs the current segment, if any, and opens it for reads.
+func (w *seriesSegmentRewriter) close() error {
+	if w.f == nil {
+		return nil
+	}
+	f := w.f
+	w.f = nil
+	defer f.Close()
+
+	if err := w.w.Flush(); err != nil {
+		return err
+	} else if err := f.Truncate(int64(SeriesSegmentSize(w.id))); err != nil {
+		return err
+	} else if err := f.Sync(); err != nil {
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+
+	segment := NewSeriesSegment(w.id, f.Name())
+	if err := segment.Open(); err != nil {
+		return err
+	}
+	w.segments = append(w.segments, segment)
+	return nil
+}
+
+// writeSeriesSegmentRewriteMarker writes the marker committing a segment
+// compaction in dir.
+func writeSeriesSegmentRewriteMarker(dir string, removed []string) error {
+	f, err := fs.CreateFile(filepath.Join(dir, seriesSegmentRewriteMarker+seriesSegmentRewriteExt))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, name := range removed {
+		if _, err := fmt.Fprintln(f, name); err != nil {
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	} else if err := fs.RenameFile(f.Name(), filepath.Join(dir, seriesSegmentRewriteMarker)); err != nil {
+		return err
+	}
+	return fs.SyncDir(dir)
+}
+
+// finishSeriesSegmentRewrite moves the rewritten segments and index of a
+// committed segment compaction in dir into place, removes the segments listed
+// in removed and then the marker. It can be run again if it is interrupted.
+func finishSeriesSegmentRewrite(dir string, removed []string) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		name := fi.Name()
+		if !strings.HasSuffix(name, seriesSegmentRewriteExt) || name == seriesSegmentRewriteMarker+seriesSegmentRewriteExt {
+			continue
+		}
+		if err := fs.RenameFileWithReplacement(filepath.Join(dir, name), filepath.Join(dir, strings.TrimSuffix(name, seriesSegmentRewriteExt))); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range removed {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, seriesSegmentRewriteMarker)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return fs.SyncDir(dir)
+}
+
+// removeSeriesSegmentRewrites removes the files written by an uncommitted
+// segment compaction in dir.
+func removeSeriesSegmentRewrites(dir string) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if !strings.HasSuffix(fi.Name(), seriesSegmentRewriteExt) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, fi.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverSegmentRewrite finishes a segment compaction that was committed but
+// interrupted, or removes the files of one that was not committed.
+func (p *SeriesPartition) recoverSegmentRewrite() error {
+	data, err := ioutil.ReadFile(filepath.Join(p.path, seriesSegmentRewriteMarker))
+	if os.IsNotExist(err) {
+		if p.ReadOnly {
+			return nil
+		}
+		return removeSeriesSegmentRewrites(p.path)
+	} else if err != nil {
+		return err
+	} else if p.ReadOnly {
+		return fmt.Errorf("tsdb: series partition %s has an unfinished segment compaction", p.path)
+	}
+
+	p.Logger.Info("Finishing series segment compaction", zap.String("path", p.path))
+	return finishSeriesSegmentRewrite(p.path, strings.Fields(string(data)))
+}
@@ -33,6 +33,9 @@ import (
 // an index is closed while a compaction is occurring.
 var ErrCompactionInterrupted = errors.New("tsi1: compaction interrupted")
 
+// ErrIndexReadOnly is returned when modifying an index opened read-only.
+var ErrIndexReadOnly = errors.New("tsi1: index is read-only")
+
 func init() {
 	if os.Getenv("INFLUXDB_EXP_TSI_PARTITIONS") != "" {
 		i, err := strconv.Atoi(os.Getenv("INFLUXDB_EXP_TSI_PARTITIONS"))
@@ -77,6 +80,15 @@ var DisableFsync = func() IndexOption {
 	}
 }
 
+// WithReadOnly opens the index without modifying any of its files, so that it
+// can be opened from a snapshot or a replicated volume. Series can not be added
+// to or dropped from a read-only index.
+var WithReadOnly = func() IndexOption {
+	return func(i *Index) {
+		i.readOnly = true
+	}
+}
+
 // WithLogFileBufferSize sets the size of the buffer used within LogFiles.
 // Typically appending an entry to a LogFile involves writing 11 or 12 bytes, so
 // depending on how many new series are being created within a batch, it may
@@ -118,6 +130,7 @@ type Index struct {
 	maxLogFileSize     int64       // Maximum size of a LogFile before it's compacted.
 	logfileBufferSize  int         // The size of the buffer used by the LogFile.
 	disableFsync       bool        // Disables flushing buffers and fsyning files. Used when working with indexes offline.
+	readOnly           bool        // Opens the index without modifying its files.
 	logger             *zap.Logger // Index's logger.
 	config             Config      // The index configuration
 
@@ -127,6 +140,13 @@ type Index struct {
 	// Index's version.
 	version int
 
+	// rebuildMu is held for reading while series are created or dropped, and
+	// for writing to start and complete a rebuild, which records the changes
+	// made while it is in progress.
+	rebuildMu  sync.RWMutex
+	rebuild    *indexRebuild
+	rebuilding int32 // set while a rebuild is in progress; accessed atomically
+
 	// Cardinality stats caching time-to-live.
 	StatsTTL time.Duration
 
@@ -225,6 +245,13 @@ func (i *Index) Open(ctx context.Context) error {
 	span, _ := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	// Recover from a rebuild that was interrupted.
+	if !i.readOnly {
+		if err := i.recoverRebuild(); err != nil {
+			return err
+		}
+	}
+
 	// Ensure root exists.
 	if err := os.MkdirAll(i.path, 0777); err != nil {
 		return err
@@ -246,23 +273,7 @@ func (i *Index) Open(ctx context.Context) error {
 	// Initialize index partitions.
 	i.partitions = make([]*Partition, i.PartitionN)
 	for j := 0; j < len(i.partitions); j++ {
-		p := NewPartition(i.sfile, filepath.Join(i.path, fmt.Sprint(j)))
-		p.MaxLogFileSize = i.maxLogFileSize
-		p.StatsTTL = i.StatsTTL
-		p.nosync = i.disableFsync
-		p.logbufferSize = i.logfileBufferSize
-		p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
-
-		// Each of the trackers needs to be given slightly different default
-		// labels to ensure the correct partition ids are set as labels.
-		labels := make(prometheus.Labels, len(i.defaultLabels))
-		for k, v := range i.defaultLabels {
-			labels[k] = v
-		}
-		labels["index_partition"] = fmt.Sprint(j)
-		p.tracker = newPartitionTracker(pms, labels)
-		p.tracker.enabled = i.metricsEnabled
-		i.partitions[j] = p
+		i.partitions[j] = i.newPartition(j)
 	}
 
 	// Open all the Partitions in parallel.
@@ -309,6 +320,28 @@ func (i *Index) Open(ctx context.Context) error {
 	return nil
 }
 
+// newPartition returns a new partition of the index with index j.
+func (i *Index) newPartition(j int) *Partition {
+	p := NewPartition(i.sfile, filepath.Join(i.path, fmt.Sprint(j)))
+	p.MaxLogFileSize = i.maxLogFileSize
+	p.StatsTTL = i.StatsTTL
+	p.nosync = i.disableFsync
+	p.readOnly = i.readOnly
+	p.logbufferSize = i.logfileBufferSize
+	p.logger = i.logger.With(zap.String("tsi1_partition", fmt.Sprint(j+1)))
+
+	// Each of the trackers needs to be given slightly different default
+	// labels to ensure the correct partition ids are set as labels.
+	labels := make(prometheus.Labels, len(i.defaultLabels))
+	for k, v := range i.defaultLabels {
+		labels[k] = v
+	}
+	labels["index_partition"] = fmt.Sprint(j)
+	p.tracker = newPartitionTracker(pms, labels)
+	p.tracker.enabled = i.metricsEnabled
+	return p
+}
+
 // Acquire returns a reference to the index that causes it to be unable to be
 // closed until the reference is released.
 func (i *Index) Acquire() (*lifecycle.Reference, error) {
@@ -598,6 +631,24 @@ func (i *Index) MeasurementNamesByRegex(re *regexp.Regexp) ([][]byte, error) {
 // DropMeasurement deletes a measurement from the index. It returns the first
 // error encountered, if any.
 func (i *Index) DropMeasurement(name []byte) error {
+	if i.readOnly {
+		return ErrIndexReadOnly
+	}
+
+	i.rebuildMu.RLock()
+	defer i.rebuildMu.RUnlock()
+	if err := i.dropMeasurement(name); err != nil {
+		return err
+	}
+
+	if i.rebuild != nil {
+		name = append([]byte(nil), name...)
+		i.rebuild.record(func(idx *Index) error { return idx.dropMeasurement(name) })
+	}
+	return nil
+}
+
+func (i *Index) dropMeasurement(name []byte) error {
 	n := i.availableThreads()
 
 	// Store results.
@@ -630,6 +681,23 @@ func (i *Index) DropMeasurement(name []byte) error {
 
 // CreateSeriesListIfNotExists creates a list of series if they doesn't exist in bulk.
 func (i *Index) CreateSeriesListIfNotExists(collection *tsdb.SeriesCollection) error {
+	if i.readOnly {
+		return ErrIndexReadOnly
+	}
+
+	i.rebuildMu.RLock()
+	defer i.rebuildMu.RUnlock()
+	if err := i.createSeriesListIfNotExists(collection); err != nil {
+		return err
+	}
+
+	if i.rebuild != nil {
+		i.rebuild.recordSeries(collection)
+	}
+	return nil
+}
+
+func (i *Index) createSeriesListIfNotExists(collection *tsdb.SeriesCollection) error {
 	// Create the series list on the series file first. This validates all of the types for
 	// the collection.
 	err := i.sfile.CreateSeriesListIfNotExists(collection)
@@ -732,6 +800,24 @@ func (i *Index) InitializeSeries(*tsdb.SeriesCollection) error {
 // DropSeries drops the provided series from the index.  If cascade is true
 // and this is the last series to the measurement, the measurment will also be dropped.
 func (i *Index) DropSeries(seriesID tsdb.SeriesID, key []byte, cascade bool) error {
+	if i.readOnly {
+		return ErrIndexReadOnly
+	}
+
+	i.rebuildMu.RLock()
+	defer i.rebuildMu.RUnlock()
+	if err := i.dropSeries(seriesID, key, cascade); err != nil {
+		return err
+	}
+
+	if i.rebuild != nil {
+		key = append([]byte(nil), key...)
+		i.rebuild.record(func(idx *Index) error { return idx.dropSeries(seriesID, key, cascade) })
+	}
+	return nil
+}
+
+func (i *Index) dropSeries(seriesID tsdb.SeriesID, key []byte, cascade bool) error {
 	// Remove from partition.
 	if err := i.partition(key).DropSeries(seriesID); err != nil {
 		return err
@@ -762,7 +848,7 @@ func (i *Index) DropSeries(seriesID tsdb.SeriesID, key []byte, cascade bool) err
 	}
 
 	// If no more series exist in the measurement then delete the measurement.
-	if err := i.DropMeasurement(name); err != nil {
+	if err := i.dropMeasurement(name); err != nil {
 		return err
 	}
 	return nil
@@ -1196,9 +1282,6 @@ func (i *Index) FileSet() (*FileSet, error) {
 // SetFieldName is a no-op on this index.
 func (i *Index) SetFieldName(measurement []byte, name string) {}
 
-// Rebuild rebuilds an index. It's a no-op for this index.
-func (i *Index) Rebuild() {}
-
 // MeasurementCardinalityStats returns cardinality stats for all measurements.
 func (i *Index) MeasurementCardinalityStats() (MeasurementCardinalityStats, error) {
 	i.mu.RLock()
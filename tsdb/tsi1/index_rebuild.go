@@ -0,0 +1,266 @@
+package tsi1
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// ErrRebuildInProgress is returned when starting a rebuild of an index that is
+// already being rebuilt.
+var ErrRebuildInProgress = errors.New("tsi1: index rebuild in progress")
+
+// Suffixes of the directories used while an index is rebuilt. The new index is
+// built next to the existing one, which is moved aside when they are swapped.
+const (
+	rebuildDirSuffix  = ".rebuild"
+	replacedDirSuffix = ".replaced"
+)
+
+// indexRebuild records the changes made to an index while it is rebuilt, so
+// that they can be applied to the new index before it replaces the old one.
+type indexRebuild struct {
+	mu  sync.Mutex
+	ops []func(idx *Index) error
+}
+
+// record records a change to replay against the new index.
+func (r *indexRebuild) record(op func(idx *Index) error) {
+	r.mu.Lock()
+	r.ops = append(r.ops, op)
+	r.mu.Unlock()
+}
+
+// recordSeries records the creation of the series of collection.
+func (r *indexRebuild) recordSeries(collection *tsdb.SeriesCollection) {
+	if collection.Length() == 0 {
+		return
+	}
+
+	// The collection may be reused by the caller, but its series keys are
+	// allocated for each call.
+	keys := append([][]byte(nil), collection.SeriesKeys...)
+	types := append([]models.FieldType(nil), collection.Types...)
+	r.record(func(idx *Index) error {
+		c := &tsdb.SeriesCollection{
+			Keys:  make([][]byte, len(keys)),
+			Names: make([][]byte, len(keys)),
+			Tags:  make([]models.Tags, len(keys)),
+			Types: types,
+		}
+		for j, key := range keys {
+			c.Names[j], c.Tags[j] = tsdb.ParseSeriesKey(key)
+			c.Keys[j] = models.MakeKey(c.Names[j], c.Tags[j])
+		}
+		return idx.createSeriesListIfNotExists(c)
+	})
+}
+
+// replay applies the recorded changes to idx, returning how many were
+// applied.
+func (r *indexRebuild) replay(idx *Index) (int, error) {
+	r.mu.Lock()
+	ops := r.ops
+	r.ops = nil
+	r.mu.Unlock()
+
+	for _, op := range ops {
+		if err := op(idx); err != nil {
+			return 0, err
+		}
+	}
+	return len(ops), nil
+}
+
+// Rebuild replaces the contents of the index with the series returned by next,
+// which is called until it returns an empty collection.
+//
+// The new index is built in the background while the existing one continues
+// to serve reads and writes. Series created or dropped in the meantime are
+// applied to the new index, and then its partitions replace the existing
+// ones. Writes block briefly while the partitions are swapped. Reads that
+// began before the swap complete against the existing partitions, which are
+// closed once they are released.
+//
+// If the process stops during a rebuild, the existing index is kept.
+func (i *Index) Rebuild(ctx context.Context, next func() (*tsdb.SeriesCollection, error)) error {
+	if i.readOnly {
+		return ErrIndexReadOnly
+	} else if !atomic.CompareAndSwapInt32(&i.rebuilding, 0, 1) {
+		return ErrRebuildInProgress
+	}
+	defer atomic.StoreInt32(&i.rebuilding, 0)
+
+	start := time.Now()
+	log := i.logger.With(zap.String("op_name", "tsi1_rebuild"))
+	log.Info("Rebuilding index")
+
+	path := i.path + rebuildDirSuffix
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	idx := NewIndex(i.sfile, i.config, WithPath(path), DisableMetrics())
+	idx.maxLogFileSize = i.maxLogFileSize
+	idx.logfileBufferSize = i.logfileBufferSize
+	idx.disableFsync = i.disableFsync
+	idx.PartitionN = i.PartitionN
+	idx.StatsTTL = i.StatsTTL
+	idx.logger = log
+	if err := idx.Open(ctx); err != nil {
+		return err
+	}
+
+	// Record the changes made from now on.
+	r := &indexRebuild{}
+	i.rebuildMu.Lock()
+	i.rebuild = r
+	i.rebuildMu.Unlock()
+
+	swapped := false
+	defer func() {
+		if swapped {
+			return
+		}
+		i.rebuildMu.Lock()
+		i.rebuild = nil
+		i.rebuildMu.Unlock()
+		idx.Close()
+		os.RemoveAll(path)
+	}()
+
+	var n int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		collection, err := next()
+		if err != nil {
+			return err
+		} else if collection == nil || collection.Length() == 0 {
+			break
+		}
+		n += collection.Length()
+		if err := idx.createSeriesListIfNotExists(collection); err != nil {
+			return err
+		}
+
+		// Keep up with the changes to the existing index.
+		if _, err := r.replay(idx); err != nil {
+			return err
+		}
+	}
+
+	idx.Compact()
+	idx.Wait()
+
+	// Apply the changes made while compacting, so that few remain to be
+	// applied while writes are blocked.
+	if _, err := r.replay(idx); err != nil {
+		return err
+	}
+
+	i.rebuildMu.Lock()
+	old, err := i.swap(idx, r)
+	if err == nil {
+		swapped = true
+		i.rebuild = nil
+	}
+	i.rebuildMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Wait for reads of the old partitions to complete.
+	for _, p := range old {
+		if err := p.Close(); err != nil {
+			log.Warn("Unable to close replaced index partition", zap.String("path", p.Path()), zap.Error(err))
+		}
+	}
+	if err := os.RemoveAll(i.path + replacedDirSuffix); err != nil {
+		log.Warn("Unable to remove replaced index", zap.Error(err))
+	}
+
+	log.Info("Rebuilt index", zap.Int("series", n), zap.Duration("elapsed", time.Since(start)))
+	return nil
+}
+
+// swap replaces the partitions of the index with those of the rebuilt index
+// idx, after applying the last changes recorded by r. It returns the replaced
+// partitions, which must be closed by the caller. The rebuild lock must be
+// held, so that no changes are made during the swap.
+func (i *Index) swap(idx *Index, r *indexRebuild) ([]*Partition, error) {
+	if _, err := r.replay(idx); err != nil {
+		return nil, err
+	}
+	if err := idx.Close(); err != nil {
+		return nil, err
+	}
+
+	// Move the rebuilt index into place. The open files of the existing
+	// partitions remain readable once moved, and they are not compacted again.
+	i.DisableCompactions()
+	replaced := i.path + replacedDirSuffix
+	if err := os.RemoveAll(replaced); err != nil {
+		i.EnableCompactions()
+		return nil, err
+	} else if err := os.Rename(i.path, replaced); err != nil {
+		i.EnableCompactions()
+		return nil, err
+	} else if err := os.Rename(idx.path, i.path); err != nil {
+		os.Rename(replaced, i.path)
+		i.EnableCompactions()
+		return nil, err
+	}
+
+	partitions := make([]*Partition, i.PartitionN)
+	for j := range partitions {
+		p := i.newPartition(j)
+		if err := p.Open(); err != nil {
+			for _, p := range partitions[:j] {
+				p.Close()
+			}
+			os.Rename(i.path, idx.path)
+			os.Rename(replaced, i.path)
+			i.EnableCompactions()
+			return nil, err
+		}
+		partitions[j] = p
+	}
+
+	// The partitions are replaced in place, so that callers that do not hold
+	// the index lock see either partition.
+	i.mu.Lock()
+	old := make([]*Partition, len(i.partitions))
+	copy(old, i.partitions)
+	copy(i.partitions, partitions)
+	i.mu.Unlock()
+
+	// Cached series sets refer to the series of the old partitions.
+	i.tagValueCache.Clear()
+	return old, nil
+}
+
+// recoverRebuild restores the index if the process stopped while a rebuild
+// was swapping it, and removes what was left of the rebuild.
+func (i *Index) recoverRebuild() error {
+	replaced := i.path + replacedDirSuffix
+	if _, err := os.Stat(i.path); os.IsNotExist(err) {
+		if _, err := os.Stat(replaced); err == nil {
+			if err := os.Rename(replaced, i.path); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.RemoveAll(replaced); err != nil {
+		return err
+	}
+	return os.RemoveAll(i.path + rebuildDirSuffix)
+}
@@ -213,6 +213,90 @@ func TestIndex_DropMeasurement(t *testing.T) {
 	})
 }
 
+func TestIndex_Rebuild(t *testing.T) {
+	idx := MustOpenIndex(2, tsi1.NewConfig())
+	defer idx.Close()
+
+	if err := idx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "west"})},
+		{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"region": "west"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	batches := [][]Series{
+		{{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})}},
+		{{Name: []byte("disk"), Tags: models.NewTags(map[string]string{"region": "north"})}},
+		{{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"region": "west"})}},
+	}
+	var n int
+	next := func() (*tsdb.SeriesCollection, error) {
+		if n == len(batches) {
+			return nil, nil
+		}
+		if n == 2 {
+			// Changes made during the rebuild are kept.
+			if err := idx.CreateSeriesSliceIfNotExists([]Series{
+				{Name: []byte("gpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+			}); err != nil {
+				return nil, err
+			}
+			if err := idx.DropMeasurement([]byte("disk")); err != nil {
+				return nil, err
+			}
+		}
+		collection := &tsdb.SeriesCollection{}
+		for _, s := range batches[n] {
+			collection.Keys = append(collection.Keys, models.MakeKey(s.Name, s.Tags))
+			collection.Names = append(collection.Names, s.Name)
+			collection.Tags = append(collection.Tags, s.Tags)
+			collection.Types = append(collection.Types, s.Type)
+		}
+		n++
+		return collection, nil
+	}
+	if err := idx.Index.Rebuild(context.Background(), next); err != nil {
+		t.Fatal(err)
+	}
+
+	idx.Run(t, func(t *testing.T) {
+		for name, exp := range map[string]bool{"cpu": true, "disk": false, "gpu": true, "mem": true} {
+			if v, err := idx.MeasurementExists([]byte(name)); err != nil {
+				t.Fatal(err)
+			} else if v != exp {
+				t.Fatalf("unexpected existence of %s: got %v, exp %v", name, v, exp)
+			}
+		}
+
+		// The series that were not rebuilt are gone.
+		itr, err := idx.MeasurementSeriesIDIterator([]byte("cpu"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer itr.Close()
+		var ids []tsdb.SeriesID
+		for {
+			e, err := itr.Next()
+			if err != nil {
+				t.Fatal(err)
+			} else if e.SeriesID.IsZero() {
+				break
+			}
+			ids = append(ids, e.SeriesID)
+		}
+		if got, exp := len(ids), 1; got != exp {
+			t.Fatalf("unexpected number of cpu series: got %d, exp %d", got, exp)
+		}
+	})
+
+	for _, suffix := range []string{".rebuild", ".replaced"} {
+		if _, err := os.Stat(idx.Path() + suffix); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed: %v", idx.Path()+suffix, err)
+		}
+	}
+}
+
 func TestIndex_Open(t *testing.T) {
 	// Opening a fresh index should set the MANIFEST version to current version.
 	idx := NewIndex(tsi1.DefaultPartitionN, tsi1.NewConfig())
@@ -55,6 +55,7 @@ type LogFile struct {
 	w          *bufio.Writer // buffered writer
 	bufferSize int           // The size of the buffer used by the buffered writer
 	nosync     bool          // Disables buffer flushing and file syncing. Useful for offline tooling.
+	readOnly   bool          // Opens the file for reading only. Entries can not be appended.
 	buf        []byte        // marshaling buffer
 	keyBuf     []byte
 
@@ -136,7 +137,11 @@ func (f *LogFile) open() (err error) {
 	f.id, _ = ParseFilename(f.path)
 
 	// Open file for appending.
-	file, err := os.OpenFile(f.Path(), os.O_WRONLY|os.O_CREATE, 0666)
+	flag := os.O_WRONLY | os.O_CREATE
+	if f.readOnly {
+		flag = os.O_RDONLY
+	}
+	file, err := os.OpenFile(f.Path(), flag, 0666)
 	if err != nil {
 		return err
 	}
@@ -83,6 +83,7 @@ type Partition struct {
 	// Log file compaction thresholds.
 	MaxLogFileSize int64
 	nosync         bool // when true, flushing and syncing of LogFile will be disabled.
+	readOnly       bool // when true, no files are created, modified or removed.
 	logbufferSize  int  // the LogFile's buffer is set to this value.
 
 	logger *zap.Logger
@@ -121,7 +122,10 @@ func (p *Partition) bytes() int {
 	// Do not count SeriesFile contents because it belongs to the code that constructed this Partition.
 	b += int(unsafe.Sizeof(p.sfile))
 	b += int(unsafe.Sizeof(p.sfileref))
-	b += int(unsafe.Sizeof(p.activeLogFile)) + p.activeLogFile.bytes()
+	b += int(unsafe.Sizeof(p.activeLogFile))
+	if p.activeLogFile != nil {
+		b += p.activeLogFile.bytes()
+	}
 	b += int(unsafe.Sizeof(p.fileSet)) + p.fileSet.bytes()
 	b += int(unsafe.Sizeof(p.seq))
 	b += int(unsafe.Sizeof(p.tracker))
@@ -150,6 +154,9 @@ func (p *Partition) bytes() int {
 // incompatible tsi1 manifest file.
 var ErrIncompatibleVersion = errors.New("incompatible tsi1 index MANIFEST")
 
+// ErrPartitionClosed is returned when using a partition that has been closed.
+var ErrPartitionClosed = errors.New("tsi1: partition closed")
+
 // Open opens the partition.
 func (p *Partition) Open() (err error) {
 	p.resmu.Lock()
@@ -258,16 +265,20 @@ func (p *Partition) Open() (err error) {
 	// Set initial sequence number.
 	p.seq = p.fileSet.MaxID()
 
-	// Delete any files not in the manifest.
-	if err := p.deleteNonManifestFiles(m); err != nil {
-		return err
-	}
-
-	// Ensure a log file exists.
-	if p.activeLogFile == nil {
-		if err := p.prependActiveLogFile(); err != nil {
+	// A read-only partition is left exactly as it was found. Files not in the
+	// manifest may belong to a newer manifest that has not yet been synced.
+	if !p.readOnly {
+		// Delete any files not in the manifest.
+		if err := p.deleteNonManifestFiles(m); err != nil {
 			return err
 		}
+
+		// Ensure a log file exists.
+		if p.activeLogFile == nil {
+			if err := p.prependActiveLogFile(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Build series existence set.
@@ -283,7 +294,11 @@ func (p *Partition) Open() (err error) {
 	p.res.Open()
 
 	// Send a compaction request on start up.
-	p.compact()
+	if p.readOnly {
+		p.compactionsDisabled++
+	} else {
+		p.compact()
+	}
 
 	return nil
 }
@@ -292,6 +307,7 @@ func (p *Partition) Open() (err error) {
 func (p *Partition) openLogFile(path string) (*LogFile, error) {
 	f := NewLogFile(p.sfile, path)
 	f.nosync = p.nosync
+	f.readOnly = p.readOnly
 	f.bufferSize = p.logbufferSize
 
 	if err := f.Open(); err != nil {
@@ -461,9 +477,14 @@ func (p *Partition) WithLogger(logger *zap.Logger) {
 // you are finished.
 func (p *Partition) FileSet() (*FileSet, error) {
 	p.mu.RLock()
-	fs, err := p.fileSet.Duplicate()
-	p.mu.RUnlock()
-	return fs, err
+	defer p.mu.RUnlock()
+
+	// The partition may have been closed by a rebuild of the index since it
+	// was looked up.
+	if p.fileSet == nil {
+		return nil, ErrPartitionClosed
+	}
+	return p.fileSet.Duplicate()
 }
 
 // replaceFileSet is a helper to replace the file set of the partition. It releases
@@ -17,8 +17,9 @@ import (
 
 type floatArrayAscendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []float64
+		pos        int
 	}
 
 	tsm struct {
@@ -41,11 +42,14 @@ func newFloatArrayAscendingCursor() *floatArrayAscendingCursor {
 	return c
 }
 
-func (c *floatArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *floatArrayAscendingCursor) reset(seek, end int64, cacheValues *tsdb.FloatArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-		return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+		return c.cache.timestamps[i] >= seek
 	})
 
 	c.tsm.keyCursor = tsmKeyCursor
@@ -63,7 +67,7 @@ func (c *floatArrayAscendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -72,23 +76,23 @@ func (c *floatArrayAscendingCursor) Stats() cursors.CursorStats { return c.stats
 // Next returns the next key/value for the cursor.
 func (c *floatArrayAscendingCursor) Next() *tsdb.FloatArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
-	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(cvals) {
-		ckey := cvals[c.cache.pos].UnixNano()
+	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(ctimes) {
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 			c.tsm.pos++
 		} else if ckey < tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -124,14 +128,12 @@ func (c *floatArrayAscendingCursor) Next() *tsdb.FloatArray {
 			}
 		}
 
-		if c.cache.pos < len(cvals) {
+		if c.cache.pos < len(ctimes) {
 			// TSM was exhausted
-			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).RawValue()
-				pos++
-				c.cache.pos++
-			}
+			n := copy(c.res.Timestamps[pos:], ctimes[c.cache.pos:])
+			copy(c.res.Values[pos:], cvals[c.cache.pos:])
+			pos += n
+			c.cache.pos += n
 		}
 	}
 
@@ -161,14 +163,17 @@ func (c *floatArrayAscendingCursor) nextTSM() *tsdb.FloatArray {
 }
 
 func (c *floatArrayAscendingCursor) readArrayBlock() *tsdb.FloatArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadFloatArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 	return values
 }
 
 type floatArrayDescendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []float64
+		pos        int
 	}
 
 	tsm struct {
@@ -191,16 +196,19 @@ func newFloatArrayDescendingCursor() *floatArrayDescendingCursor {
 	return c
 }
 
-func (c *floatArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *floatArrayDescendingCursor) reset(seek, end int64, cacheValues *tsdb.FloatArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	if len(c.cache.values) > 0 {
-		c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-			return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	if len(c.cache.timestamps) > 0 {
+		c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+			return c.cache.timestamps[i] >= seek
 		})
-		if c.cache.pos == len(c.cache.values) {
+		if c.cache.pos == len(c.cache.timestamps) {
 			c.cache.pos--
-		} else if c.cache.values[c.cache.pos].UnixNano() != seek {
+		} else if c.cache.timestamps[c.cache.pos] != seek {
 			c.cache.pos--
 		}
 	} else {
@@ -230,7 +238,7 @@ func (c *floatArrayDescendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -238,23 +246,23 @@ func (c *floatArrayDescendingCursor) Stats() cursors.CursorStats { return c.stat
 
 func (c *floatArrayDescendingCursor) Next() *tsdb.FloatArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
 	for pos < len(c.res.Timestamps) && c.tsm.pos >= 0 && c.cache.pos >= 0 {
-		ckey := cvals[c.cache.pos].UnixNano()
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 			c.tsm.pos--
 		} else if ckey > tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -286,8 +294,8 @@ func (c *floatArrayDescendingCursor) Next() *tsdb.FloatArray {
 		if c.cache.pos >= 0 {
 			// TSM was exhausted
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(FloatValue).RawValue()
+				c.res.Timestamps[pos] = ctimes[c.cache.pos]
+				c.res.Values[pos] = cvals[c.cache.pos]
 				pos++
 				c.cache.pos--
 			}
@@ -316,7 +324,9 @@ func (c *floatArrayDescendingCursor) nextTSM() *tsdb.FloatArray {
 }
 
 func (c *floatArrayDescendingCursor) readArrayBlock() *tsdb.FloatArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadFloatArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 
 	c.stats.ScannedValues += len(values.Values)
 
@@ -327,8 +337,9 @@ func (c *floatArrayDescendingCursor) readArrayBlock() *tsdb.FloatArray {
 
 type integerArrayAscendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []int64
+		pos        int
 	}
 
 	tsm struct {
@@ -351,11 +362,14 @@ func newIntegerArrayAscendingCursor() *integerArrayAscendingCursor {
 	return c
 }
 
-func (c *integerArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *integerArrayAscendingCursor) reset(seek, end int64, cacheValues *tsdb.IntegerArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-		return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+		return c.cache.timestamps[i] >= seek
 	})
 
 	c.tsm.keyCursor = tsmKeyCursor
@@ -373,7 +387,7 @@ func (c *integerArrayAscendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -382,23 +396,23 @@ func (c *integerArrayAscendingCursor) Stats() cursors.CursorStats { return c.sta
 // Next returns the next key/value for the cursor.
 func (c *integerArrayAscendingCursor) Next() *tsdb.IntegerArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
-	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(cvals) {
-		ckey := cvals[c.cache.pos].UnixNano()
+	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(ctimes) {
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 			c.tsm.pos++
 		} else if ckey < tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -434,14 +448,12 @@ func (c *integerArrayAscendingCursor) Next() *tsdb.IntegerArray {
 			}
 		}
 
-		if c.cache.pos < len(cvals) {
+		if c.cache.pos < len(ctimes) {
 			// TSM was exhausted
-			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).RawValue()
-				pos++
-				c.cache.pos++
-			}
+			n := copy(c.res.Timestamps[pos:], ctimes[c.cache.pos:])
+			copy(c.res.Values[pos:], cvals[c.cache.pos:])
+			pos += n
+			c.cache.pos += n
 		}
 	}
 
@@ -471,14 +483,17 @@ func (c *integerArrayAscendingCursor) nextTSM() *tsdb.IntegerArray {
 }
 
 func (c *integerArrayAscendingCursor) readArrayBlock() *tsdb.IntegerArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadIntegerArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 	return values
 }
 
 type integerArrayDescendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []int64
+		pos        int
 	}
 
 	tsm struct {
@@ -501,16 +516,19 @@ func newIntegerArrayDescendingCursor() *integerArrayDescendingCursor {
 	return c
 }
 
-func (c *integerArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *integerArrayDescendingCursor) reset(seek, end int64, cacheValues *tsdb.IntegerArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	if len(c.cache.values) > 0 {
-		c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-			return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	if len(c.cache.timestamps) > 0 {
+		c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+			return c.cache.timestamps[i] >= seek
 		})
-		if c.cache.pos == len(c.cache.values) {
+		if c.cache.pos == len(c.cache.timestamps) {
 			c.cache.pos--
-		} else if c.cache.values[c.cache.pos].UnixNano() != seek {
+		} else if c.cache.timestamps[c.cache.pos] != seek {
 			c.cache.pos--
 		}
 	} else {
@@ -540,7 +558,7 @@ func (c *integerArrayDescendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -548,23 +566,23 @@ func (c *integerArrayDescendingCursor) Stats() cursors.CursorStats { return c.st
 
 func (c *integerArrayDescendingCursor) Next() *tsdb.IntegerArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
 	for pos < len(c.res.Timestamps) && c.tsm.pos >= 0 && c.cache.pos >= 0 {
-		ckey := cvals[c.cache.pos].UnixNano()
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 			c.tsm.pos--
 		} else if ckey > tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -596,8 +614,8 @@ func (c *integerArrayDescendingCursor) Next() *tsdb.IntegerArray {
 		if c.cache.pos >= 0 {
 			// TSM was exhausted
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(IntegerValue).RawValue()
+				c.res.Timestamps[pos] = ctimes[c.cache.pos]
+				c.res.Values[pos] = cvals[c.cache.pos]
 				pos++
 				c.cache.pos--
 			}
@@ -626,7 +644,9 @@ func (c *integerArrayDescendingCursor) nextTSM() *tsdb.IntegerArray {
 }
 
 func (c *integerArrayDescendingCursor) readArrayBlock() *tsdb.IntegerArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadIntegerArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 
 	c.stats.ScannedValues += len(values.Values)
 
@@ -637,8 +657,9 @@ func (c *integerArrayDescendingCursor) readArrayBlock() *tsdb.IntegerArray {
 
 type unsignedArrayAscendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []uint64
+		pos        int
 	}
 
 	tsm struct {
@@ -661,11 +682,14 @@ func newUnsignedArrayAscendingCursor() *unsignedArrayAscendingCursor {
 	return c
 }
 
-func (c *unsignedArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *unsignedArrayAscendingCursor) reset(seek, end int64, cacheValues *tsdb.UnsignedArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-		return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+		return c.cache.timestamps[i] >= seek
 	})
 
 	c.tsm.keyCursor = tsmKeyCursor
@@ -683,7 +707,7 @@ func (c *unsignedArrayAscendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -692,23 +716,23 @@ func (c *unsignedArrayAscendingCursor) Stats() cursors.CursorStats { return c.st
 // Next returns the next key/value for the cursor.
 func (c *unsignedArrayAscendingCursor) Next() *tsdb.UnsignedArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
-	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(cvals) {
-		ckey := cvals[c.cache.pos].UnixNano()
+	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(ctimes) {
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 			c.tsm.pos++
 		} else if ckey < tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -744,14 +768,12 @@ func (c *unsignedArrayAscendingCursor) Next() *tsdb.UnsignedArray {
 			}
 		}
 
-		if c.cache.pos < len(cvals) {
+		if c.cache.pos < len(ctimes) {
 			// TSM was exhausted
-			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).RawValue()
-				pos++
-				c.cache.pos++
-			}
+			n := copy(c.res.Timestamps[pos:], ctimes[c.cache.pos:])
+			copy(c.res.Values[pos:], cvals[c.cache.pos:])
+			pos += n
+			c.cache.pos += n
 		}
 	}
 
@@ -781,14 +803,17 @@ func (c *unsignedArrayAscendingCursor) nextTSM() *tsdb.UnsignedArray {
 }
 
 func (c *unsignedArrayAscendingCursor) readArrayBlock() *tsdb.UnsignedArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadUnsignedArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 	return values
 }
 
 type unsignedArrayDescendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []uint64
+		pos        int
 	}
 
 	tsm struct {
@@ -811,16 +836,19 @@ func newUnsignedArrayDescendingCursor() *unsignedArrayDescendingCursor {
 	return c
 }
 
-func (c *unsignedArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *unsignedArrayDescendingCursor) reset(seek, end int64, cacheValues *tsdb.UnsignedArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	if len(c.cache.values) > 0 {
-		c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-			return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	if len(c.cache.timestamps) > 0 {
+		c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+			return c.cache.timestamps[i] >= seek
 		})
-		if c.cache.pos == len(c.cache.values) {
+		if c.cache.pos == len(c.cache.timestamps) {
 			c.cache.pos--
-		} else if c.cache.values[c.cache.pos].UnixNano() != seek {
+		} else if c.cache.timestamps[c.cache.pos] != seek {
 			c.cache.pos--
 		}
 	} else {
@@ -850,7 +878,7 @@ func (c *unsignedArrayDescendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -858,23 +886,23 @@ func (c *unsignedArrayDescendingCursor) Stats() cursors.CursorStats { return c.s
 
 func (c *unsignedArrayDescendingCursor) Next() *tsdb.UnsignedArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
 	for pos < len(c.res.Timestamps) && c.tsm.pos >= 0 && c.cache.pos >= 0 {
-		ckey := cvals[c.cache.pos].UnixNano()
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 			c.tsm.pos--
 		} else if ckey > tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -906,8 +934,8 @@ func (c *unsignedArrayDescendingCursor) Next() *tsdb.UnsignedArray {
 		if c.cache.pos >= 0 {
 			// TSM was exhausted
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(UnsignedValue).RawValue()
+				c.res.Timestamps[pos] = ctimes[c.cache.pos]
+				c.res.Values[pos] = cvals[c.cache.pos]
 				pos++
 				c.cache.pos--
 			}
@@ -936,7 +964,9 @@ func (c *unsignedArrayDescendingCursor) nextTSM() *tsdb.UnsignedArray {
 }
 
 func (c *unsignedArrayDescendingCursor) readArrayBlock() *tsdb.UnsignedArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadUnsignedArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 
 	c.stats.ScannedValues += len(values.Values)
 
@@ -947,8 +977,9 @@ func (c *unsignedArrayDescendingCursor) readArrayBlock() *tsdb.UnsignedArray {
 
 type stringArrayAscendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []string
+		pos        int
 	}
 
 	tsm struct {
@@ -971,11 +1002,14 @@ func newStringArrayAscendingCursor() *stringArrayAscendingCursor {
 	return c
 }
 
-func (c *stringArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *stringArrayAscendingCursor) reset(seek, end int64, cacheValues *tsdb.StringArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-		return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+		return c.cache.timestamps[i] >= seek
 	})
 
 	c.tsm.keyCursor = tsmKeyCursor
@@ -993,7 +1027,7 @@ func (c *stringArrayAscendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -1002,23 +1036,23 @@ func (c *stringArrayAscendingCursor) Stats() cursors.CursorStats { return c.stat
 // Next returns the next key/value for the cursor.
 func (c *stringArrayAscendingCursor) Next() *tsdb.StringArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
-	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(cvals) {
-		ckey := cvals[c.cache.pos].UnixNano()
+	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(ctimes) {
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(StringValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 			c.tsm.pos++
 		} else if ckey < tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(StringValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -1054,14 +1088,12 @@ func (c *stringArrayAscendingCursor) Next() *tsdb.StringArray {
 			}
 		}
 
-		if c.cache.pos < len(cvals) {
+		if c.cache.pos < len(ctimes) {
 			// TSM was exhausted
-			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(StringValue).RawValue()
-				pos++
-				c.cache.pos++
-			}
+			n := copy(c.res.Timestamps[pos:], ctimes[c.cache.pos:])
+			copy(c.res.Values[pos:], cvals[c.cache.pos:])
+			pos += n
+			c.cache.pos += n
 		}
 	}
 
@@ -1093,14 +1125,17 @@ func (c *stringArrayAscendingCursor) nextTSM() *tsdb.StringArray {
 }
 
 func (c *stringArrayAscendingCursor) readArrayBlock() *tsdb.StringArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadStringArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 	return values
 }
 
 type stringArrayDescendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []string
+		pos        int
 	}
 
 	tsm struct {
@@ -1123,16 +1158,19 @@ func newStringArrayDescendingCursor() *stringArrayDescendingCursor {
 	return c
 }
 
-func (c *stringArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *stringArrayDescendingCursor) reset(seek, end int64, cacheValues *tsdb.StringArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	if len(c.cache.values) > 0 {
-		c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-			return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	if len(c.cache.timestamps) > 0 {
+		c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+			return c.cache.timestamps[i] >= seek
 		})
-		if c.cache.pos == len(c.cache.values) {
+		if c.cache.pos == len(c.cache.timestamps) {
 			c.cache.pos--
-		} else if c.cache.values[c.cache.pos].UnixNano() != seek {
+		} else if c.cache.timestamps[c.cache.pos] != seek {
 			c.cache.pos--
 		}
 	} else {
@@ -1162,7 +1200,7 @@ func (c *stringArrayDescendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -1170,23 +1208,23 @@ func (c *stringArrayDescendingCursor) Stats() cursors.CursorStats { return c.sta
 
 func (c *stringArrayDescendingCursor) Next() *tsdb.StringArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
 	for pos < len(c.res.Timestamps) && c.tsm.pos >= 0 && c.cache.pos >= 0 {
-		ckey := cvals[c.cache.pos].UnixNano()
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(StringValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 			c.tsm.pos--
 		} else if ckey > tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(StringValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -1218,8 +1256,8 @@ func (c *stringArrayDescendingCursor) Next() *tsdb.StringArray {
 		if c.cache.pos >= 0 {
 			// TSM was exhausted
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(StringValue).RawValue()
+				c.res.Timestamps[pos] = ctimes[c.cache.pos]
+				c.res.Values[pos] = cvals[c.cache.pos]
 				pos++
 				c.cache.pos--
 			}
@@ -1248,7 +1286,9 @@ func (c *stringArrayDescendingCursor) nextTSM() *tsdb.StringArray {
 }
 
 func (c *stringArrayDescendingCursor) readArrayBlock() *tsdb.StringArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadStringArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 
 	c.stats.ScannedValues += len(values.Values)
 
@@ -1261,8 +1301,9 @@ func (c *stringArrayDescendingCursor) readArrayBlock() *tsdb.StringArray {
 
 type booleanArrayAscendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []bool
+		pos        int
 	}
 
 	tsm struct {
@@ -1285,11 +1326,14 @@ func newBooleanArrayAscendingCursor() *booleanArrayAscendingCursor {
 	return c
 }
 
-func (c *booleanArrayAscendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *booleanArrayAscendingCursor) reset(seek, end int64, cacheValues *tsdb.BooleanArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-		return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+		return c.cache.timestamps[i] >= seek
 	})
 
 	c.tsm.keyCursor = tsmKeyCursor
@@ -1307,7 +1351,7 @@ func (c *booleanArrayAscendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -1316,23 +1360,23 @@ func (c *booleanArrayAscendingCursor) Stats() cursors.CursorStats { return c.sta
 // Next returns the next key/value for the cursor.
 func (c *booleanArrayAscendingCursor) Next() *tsdb.BooleanArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
-	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(cvals) {
-		ckey := cvals[c.cache.pos].UnixNano()
+	for pos < len(c.res.Timestamps) && c.tsm.pos < len(tvals.Timestamps) && c.cache.pos < len(ctimes) {
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 			c.tsm.pos++
 		} else if ckey < tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos++
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -1368,14 +1412,12 @@ func (c *booleanArrayAscendingCursor) Next() *tsdb.BooleanArray {
 			}
 		}
 
-		if c.cache.pos < len(cvals) {
+		if c.cache.pos < len(ctimes) {
 			// TSM was exhausted
-			for pos < len(c.res.Timestamps) && c.cache.pos < len(cvals) {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).RawValue()
-				pos++
-				c.cache.pos++
-			}
+			n := copy(c.res.Timestamps[pos:], ctimes[c.cache.pos:])
+			copy(c.res.Values[pos:], cvals[c.cache.pos:])
+			pos += n
+			c.cache.pos += n
 		}
 	}
 
@@ -1405,14 +1447,17 @@ func (c *booleanArrayAscendingCursor) nextTSM() *tsdb.BooleanArray {
 }
 
 func (c *booleanArrayAscendingCursor) readArrayBlock() *tsdb.BooleanArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadBooleanArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 	return values
 }
 
 type booleanArrayDescendingCursor struct {
 	cache struct {
-		values Values
-		pos    int
+		timestamps []int64
+		values     []bool
+		pos        int
 	}
 
 	tsm struct {
@@ -1435,16 +1480,19 @@ func newBooleanArrayDescendingCursor() *booleanArrayDescendingCursor {
 	return c
 }
 
-func (c *booleanArrayDescendingCursor) reset(seek, end int64, cacheValues Values, tsmKeyCursor *KeyCursor) {
+func (c *booleanArrayDescendingCursor) reset(seek, end int64, cacheValues *tsdb.BooleanArray, tsmKeyCursor *KeyCursor) {
 	c.end = end
-	c.cache.values = cacheValues
-	if len(c.cache.values) > 0 {
-		c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
-			return c.cache.values[i].UnixNano() >= seek
+	c.cache.timestamps, c.cache.values = nil, nil
+	if cacheValues != nil {
+		c.cache.timestamps, c.cache.values = cacheValues.Timestamps, cacheValues.Values
+	}
+	if len(c.cache.timestamps) > 0 {
+		c.cache.pos = sort.Search(len(c.cache.timestamps), func(i int) bool {
+			return c.cache.timestamps[i] >= seek
 		})
-		if c.cache.pos == len(c.cache.values) {
+		if c.cache.pos == len(c.cache.timestamps) {
 			c.cache.pos--
-		} else if c.cache.values[c.cache.pos].UnixNano() != seek {
+		} else if c.cache.timestamps[c.cache.pos] != seek {
 			c.cache.pos--
 		}
 	} else {
@@ -1474,7 +1522,7 @@ func (c *booleanArrayDescendingCursor) Close() {
 		c.tsm.keyCursor.Close()
 		c.tsm.keyCursor = nil
 	}
-	c.cache.values = nil
+	c.cache.timestamps, c.cache.values = nil, nil
 	c.tsm.values = nil
 }
 
@@ -1482,23 +1530,23 @@ func (c *booleanArrayDescendingCursor) Stats() cursors.CursorStats { return c.st
 
 func (c *booleanArrayDescendingCursor) Next() *tsdb.BooleanArray {
 	pos := 0
-	cvals := c.cache.values
+	ctimes, cvals := c.cache.timestamps, c.cache.values
 	tvals := c.tsm.values
 
 	c.res.Timestamps = c.res.Timestamps[:cap(c.res.Timestamps)]
 	c.res.Values = c.res.Values[:cap(c.res.Values)]
 
 	for pos < len(c.res.Timestamps) && c.tsm.pos >= 0 && c.cache.pos >= 0 {
-		ckey := cvals[c.cache.pos].UnixNano()
+		ckey := ctimes[c.cache.pos]
 		tkey := tvals.Timestamps[c.tsm.pos]
 		if ckey == tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 			c.tsm.pos--
 		} else if ckey > tkey {
 			c.res.Timestamps[pos] = ckey
-			c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).RawValue()
+			c.res.Values[pos] = cvals[c.cache.pos]
 			c.cache.pos--
 		} else {
 			c.res.Timestamps[pos] = tkey
@@ -1530,8 +1578,8 @@ func (c *booleanArrayDescendingCursor) Next() *tsdb.BooleanArray {
 		if c.cache.pos >= 0 {
 			// TSM was exhausted
 			for pos < len(c.res.Timestamps) && c.cache.pos >= 0 {
-				c.res.Timestamps[pos] = cvals[c.cache.pos].UnixNano()
-				c.res.Values[pos] = cvals[c.cache.pos].(BooleanValue).RawValue()
+				c.res.Timestamps[pos] = ctimes[c.cache.pos]
+				c.res.Values[pos] = cvals[c.cache.pos]
 				pos++
 				c.cache.pos--
 			}
@@ -1560,7 +1608,9 @@ func (c *booleanArrayDescendingCursor) nextTSM() *tsdb.BooleanArray {
 }
 
 func (c *booleanArrayDescendingCursor) readArrayBlock() *tsdb.BooleanArray {
+	n := c.tsm.keyCursor.duplicates
 	values, _ := c.tsm.keyCursor.ReadBooleanArrayBlock(c.tsm.buf)
+	c.stats.DuplicateValues += c.tsm.keyCursor.duplicates - n
 
 	c.stats.ScannedValues += len(values.Values)
 
@@ -141,6 +141,9 @@ func IntegerArrayDecodeAll(b []byte, dst []int64) ([]int64, error) {
 	if len(b) == 0 {
 		return []int64{}, nil
 	}
+	if b[0]>>4 == intCompressedDeltaOfDelta {
+		return integerBatchDecodeAllDeltaOfDelta(b, dst)
+	}
 
 	encoding := b[0] >> 4
 	if encoding > intCompressedRLE {
@@ -154,6 +157,10 @@ func UnsignedArrayDecodeAll(b []byte, dst []uint64) ([]uint64, error) {
 	if len(b) == 0 {
 		return []uint64{}, nil
 	}
+	if b[0]>>4 == intCompressedDeltaOfDelta {
+		res, err := integerBatchDecodeAllDeltaOfDelta(b, reintepretUint64ToInt64Slice(dst))
+		return reintepretInt64ToUint64Slice(res), err
+	}
 
 	encoding := b[0] >> 4
 	if encoding > intCompressedRLE {
@@ -0,0 +1,212 @@
+package tsm1
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/klauspost/compress/zstd"
+)
+
+// BlockCompression identifies a compression scheme applied to an entire encoded
+// block, in addition to the per-type timestamp and value encodings.
+type BlockCompression int
+
+const (
+	// BlockCompressionNone writes blocks using only the per-type encodings.
+	BlockCompressionNone BlockCompression = iota
+
+	// BlockCompressionZstd compresses the timestamps and values of a block
+	// with zstd. It trades CPU on read for a smaller footprint on disk, which
+	// suits buckets that are rarely read.
+	BlockCompressionZstd
+)
+
+const (
+	// blockFlagZstd is set in the type byte of a block whose remaining bytes
+	// are zstd compressed.
+	blockFlagZstd = byte(0x80)
+)
+
+// ParseBlockCompression returns the BlockCompression named by s. An empty
+// string is equivalent to "none".
+func ParseBlockCompression(s string) (BlockCompression, error) {
+	switch s {
+	case "", "none":
+		return BlockCompressionNone, nil
+	case "zstd":
+		return BlockCompressionZstd, nil
+	default:
+		return BlockCompressionNone, fmt.Errorf("unknown block compression: %q", s)
+	}
+}
+
+// String returns the name of the compression.
+func (c BlockCompression) String() string {
+	switch c {
+	case BlockCompressionNone:
+		return "none"
+	case BlockCompressionZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(c))
+	}
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+// initZstd lazily creates the shared zstd encoder and decoder, both of which
+// are safe for concurrent use. Blocks are already protected by a checksum in
+// the TSM file so the zstd frame checksum is omitted.
+func initZstd() error {
+	zstdOnce.Do(func() {
+		zstdEncoder, zstdErr = zstd.NewWriter(nil, zstd.WithEncoderCRC(false), zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+		if zstdErr != nil {
+			return
+		}
+		zstdDecoder, zstdErr = zstd.NewReader(nil)
+	})
+	return zstdErr
+}
+
+// BlockCompressionOf returns the compression applied to block.
+func BlockCompressionOf(block []byte) BlockCompression {
+	if len(block) > 0 && block[0]&blockFlagZstd != 0 {
+		return BlockCompressionZstd
+	}
+	return BlockCompressionNone
+}
+
+// CompressBlock compresses an encoded block using c. The original block is
+// returned if it is already compressed or encrypted, or if compressing it would
+// not make it any smaller.
+func CompressBlock(block []byte, c BlockCompression) ([]byte, error) {
+	if len(block) <= encodedBlockHeaderSize || BlockCompressionOf(block) != BlockCompressionNone || BlockEncrypted(block) {
+		return block, nil
+	}
+
+	switch c {
+	case BlockCompressionNone:
+		return block, nil
+	case BlockCompressionZstd:
+		if err := initZstd(); err != nil {
+			return nil, err
+		}
+		b := make([]byte, 1, len(block))
+		b[0] = block[0] | blockFlagZstd
+		b = zstdEncoder.EncodeAll(block[1:], b)
+		if len(b) >= len(block) {
+			return block, nil
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown block compression: %d", int(c))
+	}
+}
+
+// DecompressBlock returns block with any block level compression removed. If
+// block is not compressed it is returned as is.
+func DecompressBlock(block []byte) ([]byte, error) {
+	if BlockCompressionOf(block) == BlockCompressionNone {
+		return block, nil
+	}
+
+	if err := initZstd(); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 1, 4*len(block))
+	b[0] = block[0] &^ blockFlagZstd
+	b, err := zstdDecoder.DecodeAll(block[1:], b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress block: %v", err)
+	}
+	return b, nil
+}
+
+// TranscodeBlock returns block compressed using c, decompressing it first if it
+// was written with a different compression. An encrypted block that must be
+// transcoded is returned decrypted.
+func TranscodeBlock(block []byte, c BlockCompression) ([]byte, error) {
+	if BlockCompressionOf(block) == c {
+		return block, nil
+	}
+
+	block, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	return CompressBlock(block, c)
+}
+
+// BlockCompressionPolicy determines the compression used for blocks written to
+// new TSM files, optionally overriding the default for individual buckets.
+type BlockCompressionPolicy struct {
+	Default BlockCompression
+	Buckets map[influxdb.ID]BlockCompression
+}
+
+// NewBlockCompressionPolicy returns a policy from the block compression
+// settings in config.
+func NewBlockCompressionPolicy(config Config) (*BlockCompressionPolicy, error) {
+	def, err := ParseBlockCompression(config.BlockCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &BlockCompressionPolicy{Default: def}
+	for k, v := range config.BucketBlockCompression {
+		id, err := influxdb.IDFromString(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket id %q for block compression: %v", k, err)
+		}
+		c, err := ParseBlockCompression(v)
+		if err != nil {
+			return nil, err
+		}
+		if p.Buckets == nil {
+			p.Buckets = make(map[influxdb.ID]BlockCompression)
+		}
+		p.Buckets[*id] = c
+	}
+	return p, nil
+}
+
+// Compression returns the compression to use for blocks of the series key.
+func (p *BlockCompressionPolicy) Compression(key []byte) BlockCompression {
+	if p == nil {
+		return BlockCompressionNone
+	}
+	if len(p.Buckets) > 0 && len(key) >= 16 {
+		_, bucketID := tsdb.DecodeNameSlice(key[:16])
+		if c, ok := p.Buckets[bucketID]; ok {
+			return c
+		}
+	}
+	return p.Default
+}
+
+// transcodingKeyIterator transcodes the blocks read from a KeyIterator to the
+// compression required by a policy.
+type transcodingKeyIterator struct {
+	KeyIterator
+	policy *BlockCompressionPolicy
+}
+
+func (k *transcodingKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	key, minTime, maxTime, block, err := k.KeyIterator.Read()
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+
+	block, err = TranscodeBlock(block, k.policy.Compression(key))
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	return key, minTime, maxTime, block, nil
+}
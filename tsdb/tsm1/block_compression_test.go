@@ -0,0 +1,199 @@
+package tsm1_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestCompressBlock_RoundTrip(t *testing.T) {
+	var floats, integers, unsigneds, booleans, strings tsm1.Values
+	for i := 0; i < tsm1.MaxPointsPerBlock; i++ {
+		ts := int64(i) * 1e9
+		floats = append(floats, tsm1.NewValue(ts, float64(i%10)))
+		integers = append(integers, tsm1.NewValue(ts, int64(i%10)))
+		unsigneds = append(unsigneds, tsm1.NewValue(ts, uint64(i%10)))
+		booleans = append(booleans, tsm1.NewValue(ts, i%2 == 0))
+		strings = append(strings, tsm1.NewValue(ts, fmt.Sprintf("status=%d", i%3)))
+	}
+
+	for _, values := range []tsm1.Values{floats, integers, unsigneds, booleans, strings} {
+		block, err := values.Encode(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		typ, _ := tsm1.BlockType(block)
+
+		t.Run(tsm1.BlockTypeName(typ), func(t *testing.T) {
+			compressed, err := tsm1.CompressBlock(block, tsm1.BlockCompressionZstd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tsm1.BlockCompressionOf(compressed); got != tsm1.BlockCompressionZstd && len(compressed) != len(block) {
+				t.Fatalf("unexpected compression: %v", got)
+			}
+			if got, exp := len(compressed), len(block); got > exp {
+				t.Fatalf("compressed block is larger than original: got %d, exp <= %d", got, exp)
+			}
+
+			if got, err := tsm1.BlockType(compressed); err != nil {
+				t.Fatal(err)
+			} else if got != typ {
+				t.Fatalf("unexpected block type: got %d, exp %d", got, typ)
+			}
+			if got, err := tsm1.BlockCount(compressed); err != nil {
+				t.Fatal(err)
+			} else if exp := len(values); got != exp {
+				t.Fatalf("unexpected block count: got %d, exp %d", got, exp)
+			}
+
+			decoded, err := tsm1.DecodeBlock(compressed, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, exp := len(decoded), len(values); got != exp {
+				t.Fatalf("unexpected number of values: got %d, exp %d", got, exp)
+			}
+			for i := range values {
+				assertValueEqual(t, decoded[i], values[i])
+			}
+
+			plain, err := tsm1.TranscodeBlock(compressed, tsm1.BlockCompressionNone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(plain, block); diff != "" {
+				t.Fatalf("unexpected decompressed block:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDecodeArrayBlock_Compressed(t *testing.T) {
+	var values tsm1.Values
+	for i := 0; i < 100; i++ {
+		values = append(values, tsm1.NewValue(int64(i), "value"))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := tsm1.CompressBlock(block, tsm1.BlockCompressionZstd)
+	if err != nil {
+		t.Fatal(err)
+	} else if tsm1.BlockCompressionOf(compressed) != tsm1.BlockCompressionZstd {
+		t.Fatal("expected block to be compressed")
+	}
+
+	var a tsdb.StringArray
+	if err := tsm1.DecodeStringArrayBlock(compressed, &a); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := a.Len(), len(values); got != exp {
+		t.Fatalf("unexpected number of values: got %d, exp %d", got, exp)
+	}
+	for i := range values {
+		if a.Timestamps[i] != values[i].UnixNano() || a.Values[i] != values[i].Value() {
+			t.Fatalf("unexpected value at %d: got (%d, %v), exp %v", i, a.Timestamps[i], a.Values[i], values[i])
+		}
+	}
+
+	var ts tsdb.TimestampArray
+	if err := tsm1.DecodeTimestampArrayBlock(compressed, &ts); err != nil {
+		t.Fatal(err)
+	} else if got, exp := ts.Len(), len(values); got != exp {
+		t.Fatalf("unexpected number of timestamps: got %d, exp %d", got, exp)
+	}
+}
+
+func TestNewBlockCompressionPolicy(t *testing.T) {
+	config := tsm1.NewConfig()
+	config.BlockCompression = "none"
+	config.BucketBlockCompression = map[string]string{"0000000000000002": "zstd"}
+
+	p, err := tsm1.NewBlockCompressionPolicy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hot := tsdb.EncodeName(influxdb.ID(1), influxdb.ID(1))
+	cold := tsdb.EncodeName(influxdb.ID(1), influxdb.ID(2))
+	if got := p.Compression(append(hot[:], ",m=cpu"...)); got != tsm1.BlockCompressionNone {
+		t.Errorf("unexpected compression for hot bucket: %v", got)
+	}
+	if got := p.Compression(append(cold[:], ",m=cpu"...)); got != tsm1.BlockCompressionZstd {
+		t.Errorf("unexpected compression for cold bucket: %v", got)
+	}
+
+	config.BlockCompression = "lz4"
+	if _, err := tsm1.NewBlockCompressionPolicy(config); err == nil {
+		t.Error("expected error for unknown compression")
+	}
+
+	config.BlockCompression = ""
+	config.BucketBlockCompression = map[string]string{"bad": "zstd"}
+	if _, err := tsm1.NewBlockCompressionPolicy(config); err == nil {
+		t.Error("expected error for invalid bucket id")
+	}
+}
+
+// Ensures compactions transcode blocks to the configured compression.
+func TestCompactor_CompactFull_BlockCompression(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	var a, b tsm1.Values
+	for i := 0; i < 500; i++ {
+		a = append(a, tsm1.NewValue(int64(i), "idle"))
+		b = append(b, tsm1.NewValue(int64(i+500), "busy"))
+	}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{"cpu,host=A#!~#state": a})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{"cpu,host=A#!~#state": b})
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.BlockCompression = &tsm1.BlockCompressionPolicy{Default: tsm1.BlockCompressionZstd}
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		_, _, _, _, _, buf, err := iter.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tsm1.BlockCompressionOf(buf); got != tsm1.BlockCompressionZstd {
+			t.Fatalf("unexpected block compression: got %v, exp %v", got, tsm1.BlockCompressionZstd)
+		}
+	}
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := append(a, b...)
+	if got, exp := len(values), len(exp); got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+	for i := range exp {
+		assertValueEqual(t, values[i], exp[i])
+	}
+}
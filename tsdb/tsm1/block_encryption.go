@@ -0,0 +1,118 @@
+package tsm1
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/fault"
+)
+
+const (
+	// blockFlagEncrypted is set in the type byte of a block whose remaining
+	// bytes are sealed by an encryption.Cipher. Encryption is applied after
+	// any block compression.
+	blockFlagEncrypted = byte(0x40)
+
+	// blockFlags are the bits of the type byte that describe how a block is
+	// wrapped, rather than the type of its values.
+	blockFlags = blockFlagZstd | blockFlagEncrypted
+)
+
+// BlockEncrypted returns true if block is encrypted.
+func BlockEncrypted(block []byte) bool {
+	return len(block) > 0 && block[0]&blockFlagEncrypted != 0
+}
+
+// BlockKeyID returns the ID of the key that encrypted block, and false if
+// block is not encrypted.
+func BlockKeyID(block []byte) (uint32, bool) {
+	if !BlockEncrypted(block) {
+		return 0, false
+	}
+	return encryption.KeyID(block[1:])
+}
+
+// EncryptBlock seals the contents of an encoded block with c, leaving the
+// type byte in the clear. A block that is already encrypted is decrypted and
+// sealed again if it was encrypted with a key other than the current key.
+func EncryptBlock(block []byte, c *encryption.Cipher) ([]byte, error) {
+	if len(block) <= encodedBlockHeaderSize {
+		return block, nil
+	}
+
+	if id, ok := BlockKeyID(block); ok {
+		current, err := c.CurrentKeyID()
+		if err != nil {
+			return nil, err
+		} else if id == current {
+			return block, nil
+		}
+		if block, err = DecryptBlock(block); err != nil {
+			return nil, err
+		}
+	}
+
+	b := make([]byte, 1, len(block)+c.Overhead())
+	b[0] = block[0] | blockFlagEncrypted
+	return c.Seal(b, block[1:])
+}
+
+// DecryptBlock returns block with any encryption removed, using the default
+// cipher. If block is not encrypted it is returned as is.
+func DecryptBlock(block []byte) ([]byte, error) {
+	if !BlockEncrypted(block) {
+		return block, nil
+	}
+
+	c := encryption.Default()
+	if c == nil {
+		return nil, fmt.Errorf("unable to decrypt block: no encryption keys configured")
+	}
+	b := make([]byte, 1, len(block))
+	b[0] = block[0] &^ blockFlagEncrypted
+	b, err := c.Open(b, block[1:])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt block: %v", err)
+	}
+	return b, nil
+}
+
+// unwrapBlock returns block with any encryption and block level compression
+// removed, ready to be decoded. It is where blocks read to be decoded pass
+// through, so faults injected into TSM reads are returned from it.
+func unwrapBlock(block []byte) ([]byte, error) {
+	if err := fault.Err(fault.TSMRead); err != nil {
+		return nil, err
+	}
+	return removeBlockWrapping(fault.Corrupt(fault.TSMRead, block))
+}
+
+// removeBlockWrapping returns block with any encryption and block level
+// compression removed.
+func removeBlockWrapping(block []byte) ([]byte, error) {
+	block, err := DecryptBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	return DecompressBlock(block)
+}
+
+// encryptingKeyIterator encrypts the blocks read from a KeyIterator with the
+// current key of a cipher.
+type encryptingKeyIterator struct {
+	KeyIterator
+	cipher *encryption.Cipher
+}
+
+func (k *encryptingKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	key, minTime, maxTime, block, err := k.KeyIterator.Read()
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+
+	block, err = EncryptBlock(block, k.cipher)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	return key, minTime, maxTime, block, nil
+}
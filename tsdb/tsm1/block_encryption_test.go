@@ -0,0 +1,236 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/fault"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func mustCipher(t *testing.T, ids ...uint32) *encryption.Cipher {
+	t.Helper()
+	keys := make(map[uint32][]byte)
+	for _, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(id)}, encryption.KeySize)
+	}
+	r, err := encryption.NewKeyRing(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encryption.NewCipher(r)
+}
+
+func TestEncryptBlock_RoundTrip(t *testing.T) {
+	var values tsm1.Values
+	for i := 0; i < 100; i++ {
+		values = append(values, tsm1.NewValue(int64(i), "secret"))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compressed, err := tsm1.CompressBlock(block, tsm1.BlockCompressionZstd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := mustCipher(t, 1)
+	encryption.SetDefault(c)
+	defer encryption.SetDefault(nil)
+
+	for _, b := range [][]byte{block, compressed} {
+		encrypted, err := tsm1.EncryptBlock(b, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, ok := tsm1.BlockKeyID(encrypted); !ok || id != 1 {
+			t.Fatalf("unexpected key id: %d, %v", id, ok)
+		}
+		if bytes.Contains(encrypted, []byte("secret")) {
+			t.Fatal("encrypted block contains plaintext")
+		}
+
+		if got, err := tsm1.BlockType(encrypted); err != nil || got != tsm1.BlockString {
+			t.Fatalf("unexpected block type: %d, %v", got, err)
+		}
+		if got, err := tsm1.BlockCount(encrypted); err != nil {
+			t.Fatal(err)
+		} else if exp := len(values); got != exp {
+			t.Fatalf("unexpected block count: got %d, exp %d", got, exp)
+		}
+		decoded, err := tsm1.DecodeBlock(encrypted, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range values {
+			assertValueEqual(t, decoded[i], values[i])
+		}
+
+		// Transcoding a block leaves it decrypted, to be encrypted again.
+		plain, err := tsm1.TranscodeBlock(encrypted, tsm1.BlockCompressionNone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tsm1.BlockCompressionOf(b) == tsm1.BlockCompressionNone {
+			plain, err = tsm1.DecryptBlock(plain)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if diff := cmp.Diff(plain, block); diff != "" {
+			t.Fatalf("unexpected transcoded block:\n%s", diff)
+		}
+	}
+
+	// After rotation, blocks are encrypted again with the new key.
+	encrypted, err := tsm1.EncryptBlock(block, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = mustCipher(t, 1, 2)
+	encryption.SetDefault(c)
+	rotated, err := tsm1.EncryptBlock(encrypted, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := tsm1.BlockKeyID(rotated); id != 2 {
+		t.Fatalf("unexpected key id: got %d, exp 2", id)
+	}
+	if got, err := tsm1.DecryptBlock(rotated); err != nil || !bytes.Equal(got, block) {
+		t.Fatalf("unexpected decrypted block: %v", err)
+	}
+
+	encryption.SetDefault(nil)
+	if _, err := tsm1.DecodeBlock(rotated, nil); err == nil {
+		t.Fatal("expected error decoding encrypted block without keys")
+	}
+	if _, err := tsm1.BlockCount(rotated); err == nil {
+		t.Fatal("expected error counting encrypted block without keys")
+	}
+}
+
+// Ensures faults injected into TSM reads fail decoding, without affecting
+// counting the values of blocks.
+func TestDecodeBlock_InjectedReadFault(t *testing.T) {
+	block, err := tsm1.Values{tsm1.NewValue(0, 1.0)}.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := fault.NewInjector(fault.Fault{Point: fault.TSMRead, Kind: fault.KindError})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fault.Enable(i)
+	defer fault.Enable(nil)
+
+	if _, err := tsm1.DecodeBlock(block, nil); err == nil {
+		t.Fatal("expected injected error decoding block")
+	}
+	if got, err := tsm1.BlockCount(block); err != nil || got != 1 {
+		t.Fatalf("unexpected block count: %d, %v", got, err)
+	}
+}
+
+// Ensures compactions encrypt blocks with the current key.
+func TestCompactor_CompactFull_Encryption(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	var a, b tsm1.Values
+	for i := 0; i < 500; i++ {
+		a = append(a, tsm1.NewValue(int64(i), "idle"))
+		b = append(b, tsm1.NewValue(int64(i+500), "busy"))
+	}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{"cpu,host=A#!~#state": a})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{"cpu,host=A#!~#state": b})
+
+	encryption.SetDefault(mustCipher(t, 1, 2))
+	defer encryption.SetDefault(nil)
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		_, _, _, _, _, buf, err := iter.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id, ok := tsm1.BlockKeyID(buf); !ok || id != 2 {
+			t.Fatalf("unexpected block key: %d, %v", id, ok)
+		}
+	}
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := append(a, b...)
+	if got, exp := len(values), len(exp); got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+	for i := range exp {
+		assertValueEqual(t, values[i], exp[i])
+	}
+}
+
+// Ensures compacting encrypted blocks without their key fails rather than panics.
+func TestCompactor_CompactFull_MissingKey(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	var a, b, c tsm1.Values
+	for i := 0; i < 500; i++ {
+		a = append(a, tsm1.NewValue(int64(i), "idle"))
+		b = append(b, tsm1.NewValue(int64(i+500), "busy"))
+		c = append(c, tsm1.NewValue(int64(i+1000), "idle"))
+	}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{"cpu,host=A#!~#state": a})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{"cpu,host=A#!~#state": b})
+
+	encryption.SetDefault(mustCipher(t, 1))
+	defer encryption.SetDefault(nil)
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+	encrypted := strings.TrimSuffix(files[0], ".tmp")
+	if err := os.Rename(files[0], encrypted); err != nil {
+		t.Fatal(err)
+	}
+	f3 := MustWriteTSM(dir, 4, map[string][]tsm1.Value{"cpu,host=A#!~#state": c})
+
+	encryption.SetDefault(nil)
+	if _, err := compactor.CompactFull([]string{encrypted, f3}); err == nil {
+		t.Fatal("expected error compacting encrypted blocks without keys")
+	}
+}
@@ -89,7 +89,9 @@ func (e *SQLBlockExporter) ExportFile(filename string) error {
 		record.MinTime = minTime
 		record.MaxTime = maxTime
 		record.Checksum = checksum
-		record.Count = BlockCount(buf)
+		if record.Count, err = BlockCount(buf); err != nil {
+			return err
+		}
 
 		if err := e.write(&record); err != nil {
 			return err
@@ -0,0 +1,193 @@
+package tsm1
+
+import (
+	"bytes"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// bucketPrefixSize is the size of the encoded org and bucket ID that prefixes
+// every series key.
+const bucketPrefixSize = 16
+
+// BucketStorageStats describes the storage used by the data of a bucket.
+type BucketStorageStats struct {
+	OrgID influxdb.ID
+
+	// CacheBytes is the size of the bucket's values in the cache.
+	CacheBytes uint64
+
+	// Files is the number of TSM files holding the bucket's data, and
+	// UncompactedFiles is the number of those that have not yet reached the
+	// highest compaction level.
+	Files            int
+	UncompactedFiles int
+
+	// TombstonedBytes is the size of the bucket's blocks in TSM files whose
+	// values have all been deleted, which is reclaimed once the files are
+	// compacted.
+	TombstonedBytes uint64
+}
+
+// BucketStorageStats returns the storage used by each bucket with data in the
+// engine, keyed by bucket ID.
+func (e *Engine) BucketStorageStats() map[influxdb.ID]*BucketStorageStats {
+	stats := make(map[influxdb.ID]*BucketStorageStats)
+	get := func(prefix []byte) *BucketStorageStats {
+		orgID, bucketID := tsdb.DecodeNameSlice(prefix)
+		s := stats[bucketID]
+		if s == nil {
+			s = &BucketStorageStats{OrgID: orgID}
+			stats[bucketID] = s
+		}
+		return s
+	}
+
+	_ = e.Cache.ApplyEntryFn(func(key string, entry *entry) error {
+		if len(key) >= bucketPrefixSize {
+			get([]byte(key[:bucketPrefixSize])).CacheBytes += uint64(entry.size())
+		}
+		return nil
+	})
+
+	files := e.FileStore.Stats()
+	tombstoned := e.filesTombstones(files)
+	for _, f := range files {
+		_, seq, err := e.FileStore.ParseFileName(f.Path)
+		if err != nil {
+			continue
+		}
+		for _, prefix := range e.fileBucketPrefixes(f) {
+			s := get(prefix)
+			s.Files++
+			// Matches the levels assigned to generations by the planner.
+			if seq < 4 {
+				s.UncompactedFiles++
+			}
+		}
+		if tf := tombstoned[f.Path]; tf != nil {
+			for prefix, n := range tf.buckets {
+				get([]byte(prefix)).TombstonedBytes += n
+			}
+		}
+	}
+	return stats
+}
+
+// BucketDataStats describes the data of a single bucket.
+type BucketDataStats struct {
+	// DiskBytes is the size of the bucket's blocks in TSM files, and
+	// CacheBytes the size of its values in the cache.
+	DiskBytes  uint64
+	CacheBytes uint64
+
+	// Points is the number of values of the bucket in TSM files and the
+	// cache. Values overwritten or deleted are counted until the files
+	// holding them are compacted.
+	Points uint64
+}
+
+// BucketDataStats returns the stats of the data of the bucket whose keys are
+// prefixed with the encoded org and bucket ID in name. The values of every
+// block of the bucket are counted, so it reads the headers of all of them.
+func (e *Engine) BucketDataStats(name []byte) (*BucketDataStats, error) {
+	s := &BucketDataStats{}
+	_ = e.Cache.ApplyEntryFn(func(key string, entry *entry) error {
+		if bytes.HasPrefix([]byte(key), name) {
+			s.CacheBytes += uint64(entry.size())
+			s.Points += uint64(entry.count())
+		}
+		return nil
+	})
+
+	for _, f := range e.FileStore.Stats() {
+		r := e.FileStore.TSMReader(f.Path)
+		if r == nil {
+			continue
+		}
+		var err error
+		if r.OverlapsKeyPrefixRange(name, name) {
+			err = bucketFileStats(r, name, s)
+		}
+		r.Unref()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// bucketFileStats adds the blocks of r whose keys are prefixed with name to s.
+func bucketFileStats(r *TSMReader, name []byte, s *BucketDataStats) error {
+	var buf []byte
+	iter := r.Iterator(name)
+	for iter.Next() {
+		if !bytes.HasPrefix(iter.Key(), name) {
+			break
+		}
+		entries := iter.Entries()
+		for i := range entries {
+			_, b, err := r.ReadBytes(&entries[i], buf)
+			if err != nil {
+				return err
+			}
+			n, err := BlockCount(b)
+			if err != nil {
+				return err
+			}
+			s.DiskBytes += uint64(entries[i].Size)
+			s.Points += uint64(n)
+			buf = b
+		}
+	}
+	return iter.Err()
+}
+
+// fileBucketPrefixes returns the distinct bucket prefixes of the keys in the
+// TSM file described by f.
+func (e *Engine) fileBucketPrefixes(f FileStat) [][]byte {
+	if len(f.MinKey) < bucketPrefixSize || len(f.MaxKey) < bucketPrefixSize {
+		return nil
+	}
+	min, max := f.MinKey[:bucketPrefixSize], f.MaxKey[:bucketPrefixSize]
+	if bytes.Equal(min, max) {
+		return [][]byte{min}
+	}
+
+	r := e.FileStore.TSMReader(f.Path)
+	if r == nil {
+		return [][]byte{min, max}
+	}
+	defer r.Unref()
+
+	// Seek past each bucket in turn rather than visiting every key.
+	var prefixes [][]byte
+	for seek := min; ; {
+		iter := r.Iterator(seek)
+		if !iter.Next() || len(iter.Key()) < bucketPrefixSize {
+			break
+		}
+		prefix := append([]byte(nil), iter.Key()[:bucketPrefixSize]...)
+		prefixes = append(prefixes, prefix)
+
+		var ok bool
+		if seek, ok = nextPrefix(prefix); !ok {
+			break
+		}
+	}
+	return prefixes
+}
+
+// nextPrefix returns the smallest prefix of the same length greater than
+// prefix, and false if there is none.
+func nextPrefix(prefix []byte) ([]byte, bool) {
+	next := append([]byte(nil), prefix...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next, true
+		}
+	}
+	return nil, false
+}
@@ -52,6 +52,11 @@ type Cache struct {
 	snapshot     *Cache
 	snapshotting bool
 
+	// snapshotPartial is true if the snapshot only holds the data of the
+	// buckets over their budget.
+	snapshotPartial bool
+	buckets         *cacheBuckets
+
 	tracker       *cacheTracker
 	lastSnapshot  time.Time
 	lastWriteTime time.Time
@@ -63,6 +68,7 @@ func NewCache(maxSize uint64) *Cache {
 	return &Cache{
 		maxSize:      maxSize,
 		store:        newRing(),
+		buckets:      newCacheBuckets(),
 		lastSnapshot: time.Now(),
 		tracker:      newCacheTracker(newCacheMetrics(nil), nil),
 	}
@@ -93,6 +99,8 @@ func (c *Cache) Write(key []byte, values []Value) error {
 	if newKey {
 		addedSize += uint64(len(key))
 	}
+	c.buckets.addKey(key, addedSize)
+
 	// Update the cache size and the memory size stat.
 	c.tracker.IncCacheSize(addedSize)
 	c.tracker.AddMemBytes(addedSize)
@@ -128,6 +136,7 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 	c.mu.RUnlock()
 
 	var bytesWrittenErr uint64
+	sizes := make(bucketSizes)
 
 	// We'll optimistically set size here, and then decrement it for write errors.
 	for k, v := range values {
@@ -137,12 +146,16 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 			werr = err
 			addedSize -= uint64(Values(v).Size())
 			bytesWrittenErr += uint64(Values(v).Size())
+		} else {
+			sizes.add([]byte(k), uint64(Values(v).Size()))
 		}
 
 		if newKey {
 			addedSize += uint64(len(k))
+			sizes.add([]byte(k), uint64(len(k)))
 		}
 	}
+	c.buckets.add(sizes)
 
 	// Some points in the batch were dropped.  An error is returned so
 	// error stat is incremented as well.
@@ -187,13 +200,20 @@ func (c *Cache) Snapshot() (*Cache, error) {
 	}
 
 	// Did a prior snapshot exist that failed?  If so, return the existing
-	// snapshot to retry.
+	// snapshot to retry, including the rest of the cache if it only held the
+	// data of some buckets.
 	if c.snapshot.Size() > 0 {
+		if c.snapshotPartial {
+			c.mergeSnapshot()
+		}
 		return c.snapshot, nil
 	}
 
 	c.snapshot.store, c.store = c.store, c.snapshot.store
 	snapshotSize := c.Size()
+	for prefix, n := range c.buckets.take(nil) {
+		c.tracker.AddBucketEvictedBytes(prefix, n)
+	}
 
 	c.snapshot.tracker.SetSnapshotSize(snapshotSize) // Save the size of the snapshot on the snapshot cache
 	c.tracker.SetSnapshotSize(snapshotSize)          // Save the size of the snapshot on the live cache
@@ -245,6 +265,7 @@ func (c *Cache) ClearSnapshot(success bool) {
 		c.tracker.SubMemBytes(snapshotSize) // decrement the number of bytes in cache
 
 		// Reset the snapshot to a fresh Cache.
+		c.snapshotPartial = false
 		c.snapshot = &Cache{
 			store:   c.snapshot.store,
 			tracker: newCacheTracker(c.tracker.metrics, c.tracker.labels),
@@ -330,6 +351,33 @@ func (c *Cache) Type(key []byte) (models.FieldType, error) {
 
 // Values returns a copy of all values, deduped and sorted, for the given key.
 func (c *Cache) Values(key []byte) Values {
+	entries, sz := c.entries(key)
+
+	// Any entries? If not, return.
+	if sz == 0 {
+		return nil
+	}
+
+	// Create the buffer, and copy all hot values and snapshots. Individual
+	// entries are sorted at this point, so now the code has to check if the
+	// resultant buffer will be sorted from start to finish.
+	values := make(Values, 0, sz)
+	for _, e := range entries {
+		e.mu.RLock()
+		if e.column != nil {
+			values = e.column.appendTo(values)
+		}
+		e.mu.RUnlock()
+	}
+	values = values.Deduplicate()
+
+	return values
+}
+
+// entries returns the deduplicated snapshot and hot entries for the given
+// key, in the order their values must be merged, and their total number of
+// values.
+func (c *Cache) entries(key []byte) ([]*entry, int) {
 	var snapshotEntries *entry
 
 	c.mu.RLock()
@@ -339,10 +387,14 @@ func (c *Cache) Values(key []byte) Values {
 	}
 	c.mu.RUnlock()
 
+	if c.buckets != nil {
+		c.tracker.IncBucketReads(key, e != nil || snapshotEntries != nil)
+	}
+
 	if e == nil {
 		if snapshotEntries == nil {
 			// No values in hot cache or snapshots.
-			return nil
+			return nil, 0
 		}
 	} else {
 		e.deduplicate()
@@ -363,26 +415,7 @@ func (c *Cache) Values(key []byte) Values {
 		entries = append(entries, e)
 		sz += e.count()
 	}
-
-	// Any entries? If not, return.
-	if sz == 0 {
-		return nil
-	}
-
-	// Create the buffer, and copy all hot values and snapshots. Individual
-	// entries are sorted at this point, so now the code has to check if the
-	// resultant buffer will be sorted from start to finish.
-	values := make(Values, sz)
-	n := 0
-	for _, e := range entries {
-		e.mu.RLock()
-		n += copy(values[n:], e.values)
-		e.mu.RUnlock()
-	}
-	values = values[:n]
-	values = values.Deduplicate()
-
-	return values
+	return entries, sz
 }
 
 // DeleteBucketRange removes values for all keys containing points
@@ -398,6 +431,7 @@ func (c *Cache) DeleteBucketRange(ctx context.Context, name string, min, max int
 
 	var toDelete []string
 	var total uint64
+	sizes := make(bucketSizes)
 
 	// applySerial only errors if the closure returns an error.
 	_ = c.store.applySerial(func(k string, e *entry) error {
@@ -410,17 +444,21 @@ func (c *Cache) DeleteBucketRange(ctx context.Context, name string, min, max int
 			return nil
 		}
 
-		total += uint64(e.size())
+		sz := uint64(e.size())
 
 		// if everything is being deleted, just stage it to be deleted and move on.
 		if min == math.MinInt64 && max == math.MaxInt64 {
+			total += sz
+			sizes.add([]byte(k), sz)
 			toDelete = append(toDelete, k)
 			return nil
 		}
 
 		// filter the values and subtract out the remaining bytes from the reduction.
 		e.filter(min, max)
-		total -= uint64(e.size())
+		sz -= uint64(e.size())
+		total += sz
+		sizes.add([]byte(k), sz)
 
 		// if it has no entries left, flag it to be deleted.
 		if e.count() == 0 {
@@ -432,10 +470,12 @@ func (c *Cache) DeleteBucketRange(ctx context.Context, name string, min, max int
 
 	for _, k := range toDelete {
 		total += uint64(len(k))
+		sizes.add([]byte(k), uint64(len(k)))
 		// TODO(edd): either use unsafe conversion to []byte or add a removeString method.
 		c.store.remove([]byte(k))
 	}
 
+	c.buckets.sub(sizes)
 	c.tracker.DecCacheSize(total)
 	c.tracker.SetMemBytes(uint64(c.Size()))
 }
@@ -455,10 +495,7 @@ func (c *Cache) values(key []byte) Values {
 	if e == nil {
 		return nil
 	}
-	e.mu.RLock()
-	v := e.values
-	e.mu.RUnlock()
-	return v
+	return e.values()
 }
 
 // ApplyEntryFn applies the function f to each entry in the Cache.
@@ -550,6 +587,8 @@ func (c *Cache) UpdateAge() {
 // *NOTE* - cacheTracker fields should not be directory modified. Doing so
 // could result in the Engine exposing inaccurate metrics.
 type cacheTracker struct {
+	mu              sync.Mutex
+	buckets         map[string]*cacheBucketMetrics // keyed by bucket prefix
 	metrics         *cacheMetrics
 	labels          prometheus.Labels
 	snapshotsActive uint64
@@ -711,6 +750,8 @@ func valueType(v Value) byte {
 		return 3
 	case BooleanValue:
 		return 4
+	case UnsignedValue:
+		return 5
 	default:
 		return 0
 	}
@@ -0,0 +1,382 @@
+package tsm1
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// cacheBuckets tracks the size and last write time of the data of each bucket
+// in the hot cache, and the memory budget and write cold duration of the
+// buckets given one. The data of a bucket over its budget, or not written to
+// for its cold duration, is snapshotted on its own, so that a single busy
+// bucket does not force the data of every other bucket to be snapshotted with
+// it.
+type cacheBuckets struct {
+	mu      sync.Mutex
+	sizes   bucketSizes
+	written map[string]time.Time
+	budgets map[influxdb.ID]uint64
+	cold    map[influxdb.ID]time.Duration
+}
+
+func newCacheBuckets() *cacheBuckets {
+	return &cacheBuckets{
+		sizes:   make(bucketSizes),
+		written: make(map[string]time.Time),
+		budgets: make(map[influxdb.ID]uint64),
+		cold:    make(map[influxdb.ID]time.Duration),
+	}
+}
+
+// setBudget sets the budget of the bucket with id. A budget of zero removes
+// it.
+func (b *cacheBuckets) setBudget(id influxdb.ID, n uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n == 0 {
+		delete(b.budgets, id)
+		return
+	}
+	b.budgets[id] = n
+}
+
+// setColdDuration sets the write cold duration of the bucket with id. A
+// duration of zero removes it.
+func (b *cacheBuckets) setColdDuration(id influxdb.ID, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if d <= 0 {
+		delete(b.cold, id)
+		return
+	}
+	b.cold[id] = d
+}
+
+// addKey records n bytes written for key.
+func (b *cacheBuckets) addKey(key []byte, n uint64) {
+	if b == nil || len(key) < bucketPrefixSize {
+		return
+	}
+	now := time.Now()
+	b.mu.Lock()
+	b.sizes[string(key[:bucketPrefixSize])] += n
+	b.written[string(key[:bucketPrefixSize])] = now
+	b.mu.Unlock()
+}
+
+// add records the bytes written for each bucket prefix in sizes.
+func (b *cacheBuckets) add(sizes bucketSizes) {
+	if b == nil {
+		return
+	}
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for prefix, n := range sizes {
+		b.sizes[prefix] += n
+		b.written[prefix] = now
+	}
+}
+
+// sub records the bytes removed for each bucket prefix in sizes.
+func (b *cacheBuckets) sub(sizes bucketSizes) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for prefix, n := range sizes {
+		if n >= b.sizes[prefix] {
+			delete(b.sizes, prefix)
+			delete(b.written, prefix)
+		} else {
+			b.sizes[prefix] -= n
+		}
+	}
+}
+
+// take forgets the sizes of the buckets with prefixes, or of every bucket if
+// prefixes is nil, returning them.
+func (b *cacheBuckets) take(prefixes [][]byte) bucketSizes {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if prefixes == nil {
+		sizes := b.sizes
+		b.sizes = make(bucketSizes)
+		b.written = make(map[string]time.Time)
+		return sizes
+	}
+
+	sizes := make(bucketSizes, len(prefixes))
+	for _, prefix := range prefixes {
+		if n, ok := b.sizes[string(prefix)]; ok {
+			sizes[string(prefix)] = n
+			delete(b.sizes, string(prefix))
+			delete(b.written, string(prefix))
+		}
+	}
+	return sizes
+}
+
+// overBudget returns the prefixes of the buckets whose data is larger than
+// their budget.
+func (b *cacheBuckets) overBudget() [][]byte {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.budgets) == 0 {
+		return nil
+	}
+
+	var prefixes [][]byte
+	for prefix, n := range b.sizes {
+		_, bucketID := tsdb.DecodeNameSlice([]byte(prefix))
+		if budget, ok := b.budgets[bucketID]; ok && n > budget {
+			prefixes = append(prefixes, []byte(prefix))
+		}
+	}
+	return prefixes
+}
+
+// coldAt returns the prefixes of the buckets with a write cold duration whose
+// data has not been written to for that long at t.
+func (b *cacheBuckets) coldAt(t time.Time) [][]byte {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.cold) == 0 {
+		return nil
+	}
+
+	var prefixes [][]byte
+	for prefix, written := range b.written {
+		_, bucketID := tsdb.DecodeNameSlice([]byte(prefix))
+		if d, ok := b.cold[bucketID]; ok && t.Sub(written) > d {
+			prefixes = append(prefixes, []byte(prefix))
+		}
+	}
+	return prefixes
+}
+
+// budgeted returns the total size of the buckets with a budget.
+func (b *cacheBuckets) budgeted() uint64 {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.budgets) == 0 {
+		return 0
+	}
+
+	var total uint64
+	for prefix, n := range b.sizes {
+		_, bucketID := tsdb.DecodeNameSlice([]byte(prefix))
+		if _, ok := b.budgets[bucketID]; ok {
+			total += n
+		}
+	}
+	return total
+}
+
+// bucketSizes holds the sizes of cache entries by bucket prefix.
+type bucketSizes map[string]uint64
+
+func (s bucketSizes) add(key []byte, n uint64) {
+	if len(key) >= bucketPrefixSize {
+		s[string(key[:bucketPrefixSize])] += n
+	}
+}
+
+// hasBucketPrefix returns true if key starts with one of prefixes.
+func hasBucketPrefix(key []byte, prefixes [][]byte) bool {
+	for _, prefix := range prefixes {
+		if bytes.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBucketBudget sets the maximum size of the data of the bucket with id in
+// the hot cache. Once over its budget, the bucket's data is snapshotted on its
+// own, and is not counted towards the size at which the whole cache is
+// snapshotted. A budget of zero removes it.
+func (c *Cache) SetBucketBudget(id influxdb.ID, n uint64) {
+	c.buckets.setBudget(id, n)
+}
+
+// BucketsOverBudget returns the prefixes of the buckets whose data in the hot
+// cache is larger than their budget.
+func (c *Cache) BucketsOverBudget() [][]byte {
+	return c.buckets.overBudget()
+}
+
+// SetBucketWriteColdDuration sets how long the data of the bucket with id stays
+// in the hot cache without being written to before it is snapshotted on its
+// own. A duration of zero removes it.
+func (c *Cache) SetBucketWriteColdDuration(id influxdb.ID, d time.Duration) {
+	c.buckets.setColdDuration(id, d)
+}
+
+// ColdBuckets returns the prefixes of the buckets whose data in the hot cache
+// has not been written to for their write cold duration at t.
+func (c *Cache) ColdBuckets(t time.Time) [][]byte {
+	return c.buckets.coldAt(t)
+}
+
+// unbudgetedSize returns the size of the cache, less that of the data of the
+// buckets with a budget.
+func (c *Cache) unbudgetedSize() uint64 {
+	sz, budgeted := c.Size(), c.buckets.budgeted()
+	if budgeted > sz {
+		return 0
+	}
+	return sz - budgeted
+}
+
+// SnapshotBuckets takes a snapshot of the data of the buckets with prefixes in
+// the current cache, leaving the data of other buckets in place. Like
+// Snapshot, it returns the existing snapshot to retry if a prior snapshot
+// failed.
+func (c *Cache) SnapshotBuckets(prefixes [][]byte) (*Cache, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.snapshotting {
+		return nil, ErrSnapshotInProgress
+	}
+
+	c.snapshotting = true
+	c.tracker.IncSnapshotsActive()
+
+	if c.snapshot == nil {
+		c.snapshot = &Cache{
+			store:   newRing(),
+			tracker: newCacheTracker(c.tracker.metrics, c.tracker.labels),
+		}
+	}
+
+	if c.snapshot.Size() > 0 {
+		return c.snapshot, nil
+	}
+
+	// Move the entries of the buckets into the snapshot store.
+	_ = c.store.applySerial(func(k string, e *entry) error {
+		if hasBucketPrefix([]byte(k), prefixes) {
+			c.snapshot.store.add([]byte(k), e)
+		}
+		return nil
+	})
+	_ = c.snapshot.store.applySerial(func(k string, _ *entry) error {
+		c.store.remove([]byte(k))
+		return nil
+	})
+	c.snapshotPartial = true
+
+	sizes := c.buckets.take(prefixes)
+	var snapshotSize uint64
+	for prefix, n := range sizes {
+		snapshotSize += n
+		c.tracker.AddBucketEvictedBytes(prefix, n)
+	}
+	if snapshotSize > c.tracker.CacheSize() {
+		snapshotSize = c.tracker.CacheSize()
+	}
+
+	c.snapshot.tracker.SetSnapshotSize(snapshotSize)
+	c.tracker.SetSnapshotSize(snapshotSize)
+	c.tracker.DecCacheSize(snapshotSize)
+
+	c.tracker.AddSnapshottedBytes(snapshotSize)
+	c.tracker.SetDiskBytes(0)
+	c.tracker.SetSnapshotsActive(0)
+
+	return c.snapshot, nil
+}
+
+// mergeSnapshot moves the entries of the hot cache into the existing snapshot
+// of the data of some buckets, so that a snapshot of the whole cache can be
+// retried. It must be called with c.mu held.
+func (c *Cache) mergeSnapshot() {
+	_ = c.store.applySerial(func(k string, e *entry) error {
+		if se := c.snapshot.store.entry([]byte(k)); se != nil {
+			_ = se.add(e.values())
+		} else {
+			c.snapshot.store.add([]byte(k), e)
+		}
+		return nil
+	})
+	c.store.reset()
+
+	for prefix, n := range c.buckets.take(nil) {
+		c.tracker.AddBucketEvictedBytes(prefix, n)
+	}
+	snapshotSize := c.Size()
+	c.snapshot.tracker.SetSnapshotSize(snapshotSize)
+	c.tracker.SetSnapshotSize(snapshotSize)
+	c.tracker.SetCacheSize(0)
+	c.snapshotPartial = false
+}
+
+// cacheBucketMetrics are the metrics of the cache for a single bucket.
+type cacheBucketMetrics struct {
+	hits         prometheus.Counter
+	misses       prometheus.Counter
+	evictedBytes prometheus.Counter
+}
+
+// bucket returns the metrics of the bucket with prefix.
+func (t *cacheTracker) bucket(prefix string) *cacheBucketMetrics {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if m := t.buckets[prefix]; m != nil {
+		return m
+	}
+
+	_, bucketID := tsdb.DecodeNameSlice([]byte(prefix))
+	labels := t.Labels()
+	labels["bucket"] = bucketID.String()
+	m := &cacheBucketMetrics{
+		hits:         t.metrics.BucketHits.With(labels),
+		misses:       t.metrics.BucketMisses.With(labels),
+		evictedBytes: t.metrics.BucketEvictedBytes.With(labels),
+	}
+	if t.buckets == nil {
+		t.buckets = make(map[string]*cacheBucketMetrics)
+	}
+	t.buckets[prefix] = m
+	return m
+}
+
+// IncBucketReads increments the number of reads of key from the cache, as a
+// hit if the cache held values for it or a miss otherwise.
+func (t *cacheTracker) IncBucketReads(key []byte, hit bool) {
+	if len(key) < bucketPrefixSize {
+		return
+	}
+	m := t.bucket(string(key[:bucketPrefixSize]))
+	if hit {
+		m.hits.Inc()
+	} else {
+		m.misses.Inc()
+	}
+}
+
+// AddBucketEvictedBytes increases the number of bytes of the bucket with
+// prefix moved out of the hot cache by snapshots.
+func (t *cacheTracker) AddBucketEvictedBytes(prefix string, bytes uint64) {
+	t.bucket(prefix).evictedBytes.Add(float64(bytes))
+}
@@ -0,0 +1,402 @@
+// Generated by tmpl
+// https://github.com/benbjohnson/tmpl
+//
+// DO NOT EDIT!
+// Source: cache_entry.gen.go.tmpl
+
+package tsm1
+
+import (
+	"sort"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// floatCacheColumn stores the float values of an entry as parallel arrays of
+// timestamps and values.
+type floatCacheColumn struct {
+	tsdb.FloatArray
+}
+
+func (c *floatCacheColumn) Less(i, j int) bool { return c.Timestamps[i] < c.Timestamps[j] }
+
+func (c *floatCacheColumn) Swap(i, j int) {
+	c.Timestamps[i], c.Timestamps[j] = c.Timestamps[j], c.Timestamps[i]
+	c.Values[i], c.Values[j] = c.Values[j], c.Values[i]
+}
+
+func (c *floatCacheColumn) timestamps() []int64 { return c.Timestamps }
+
+func (c *floatCacheColumn) appendValues(values []Value) {
+	for _, v := range values {
+		c.Timestamps = append(c.Timestamps, v.UnixNano())
+		c.Values = append(c.Values, v.(FloatValue).RawValue())
+	}
+}
+
+func (c *floatCacheColumn) appendTo(dst Values) Values {
+	for i, t := range c.Timestamps {
+		dst = append(dst, NewRawFloatValue(t, c.Values[i]))
+	}
+	return dst
+}
+
+func (c *floatCacheColumn) deduplicate() {
+	if !needsDeduplicate(c.Timestamps) {
+		return
+	}
+
+	sort.Stable(c)
+	var i int
+	for j := 1; j < len(c.Timestamps); j++ {
+		if c.Timestamps[j] != c.Timestamps[i] {
+			i++
+		}
+		c.Timestamps[i] = c.Timestamps[j]
+		c.Values[i] = c.Values[j]
+	}
+	c.Timestamps = c.Timestamps[:i+1]
+	c.Values = c.Values[:i+1]
+}
+
+func (c *floatCacheColumn) size() int {
+	return (8 + 8) * len(c.Timestamps)
+}
+
+// FloatArray returns a copy of all float values, deduped and sorted,
+// for the given key. It returns nil if the key has no float values.
+func (c *Cache) FloatArray(key []byte) *tsdb.FloatArray {
+	entries, sz := c.entries(key)
+	if sz == 0 {
+		return nil
+	}
+
+	a := &floatCacheColumn{}
+	a.Timestamps = make([]int64, 0, sz)
+	a.Values = make([]float64, 0, sz)
+	for _, e := range entries {
+		e.mu.RLock()
+		if col, ok := e.column.(*floatCacheColumn); ok {
+			a.Timestamps = append(a.Timestamps, col.Timestamps...)
+			a.Values = append(a.Values, col.Values...)
+		}
+		e.mu.RUnlock()
+	}
+	if a.Len() == 0 {
+		return nil
+	}
+	a.deduplicate()
+	return &a.FloatArray
+}
+
+// integerCacheColumn stores the integer values of an entry as parallel arrays of
+// timestamps and values.
+type integerCacheColumn struct {
+	tsdb.IntegerArray
+}
+
+func (c *integerCacheColumn) Less(i, j int) bool { return c.Timestamps[i] < c.Timestamps[j] }
+
+func (c *integerCacheColumn) Swap(i, j int) {
+	c.Timestamps[i], c.Timestamps[j] = c.Timestamps[j], c.Timestamps[i]
+	c.Values[i], c.Values[j] = c.Values[j], c.Values[i]
+}
+
+func (c *integerCacheColumn) timestamps() []int64 { return c.Timestamps }
+
+func (c *integerCacheColumn) appendValues(values []Value) {
+	for _, v := range values {
+		c.Timestamps = append(c.Timestamps, v.UnixNano())
+		c.Values = append(c.Values, v.(IntegerValue).RawValue())
+	}
+}
+
+func (c *integerCacheColumn) appendTo(dst Values) Values {
+	for i, t := range c.Timestamps {
+		dst = append(dst, NewRawIntegerValue(t, c.Values[i]))
+	}
+	return dst
+}
+
+func (c *integerCacheColumn) deduplicate() {
+	if !needsDeduplicate(c.Timestamps) {
+		return
+	}
+
+	sort.Stable(c)
+	var i int
+	for j := 1; j < len(c.Timestamps); j++ {
+		if c.Timestamps[j] != c.Timestamps[i] {
+			i++
+		}
+		c.Timestamps[i] = c.Timestamps[j]
+		c.Values[i] = c.Values[j]
+	}
+	c.Timestamps = c.Timestamps[:i+1]
+	c.Values = c.Values[:i+1]
+}
+
+func (c *integerCacheColumn) size() int {
+	return (8 + 8) * len(c.Timestamps)
+}
+
+// IntegerArray returns a copy of all integer values, deduped and sorted,
+// for the given key. It returns nil if the key has no integer values.
+func (c *Cache) IntegerArray(key []byte) *tsdb.IntegerArray {
+	entries, sz := c.entries(key)
+	if sz == 0 {
+		return nil
+	}
+
+	a := &integerCacheColumn{}
+	a.Timestamps = make([]int64, 0, sz)
+	a.Values = make([]int64, 0, sz)
+	for _, e := range entries {
+		e.mu.RLock()
+		if col, ok := e.column.(*integerCacheColumn); ok {
+			a.Timestamps = append(a.Timestamps, col.Timestamps...)
+			a.Values = append(a.Values, col.Values...)
+		}
+		e.mu.RUnlock()
+	}
+	if a.Len() == 0 {
+		return nil
+	}
+	a.deduplicate()
+	return &a.IntegerArray
+}
+
+// unsignedCacheColumn stores the unsigned values of an entry as parallel arrays of
+// timestamps and values.
+type unsignedCacheColumn struct {
+	tsdb.UnsignedArray
+}
+
+func (c *unsignedCacheColumn) Less(i, j int) bool { return c.Timestamps[i] < c.Timestamps[j] }
+
+func (c *unsignedCacheColumn) Swap(i, j int) {
+	c.Timestamps[i], c.Timestamps[j] = c.Timestamps[j], c.Timestamps[i]
+	c.Values[i], c.Values[j] = c.Values[j], c.Values[i]
+}
+
+func (c *unsignedCacheColumn) timestamps() []int64 { return c.Timestamps }
+
+func (c *unsignedCacheColumn) appendValues(values []Value) {
+	for _, v := range values {
+		c.Timestamps = append(c.Timestamps, v.UnixNano())
+		c.Values = append(c.Values, v.(UnsignedValue).RawValue())
+	}
+}
+
+func (c *unsignedCacheColumn) appendTo(dst Values) Values {
+	for i, t := range c.Timestamps {
+		dst = append(dst, NewRawUnsignedValue(t, c.Values[i]))
+	}
+	return dst
+}
+
+func (c *unsignedCacheColumn) deduplicate() {
+	if !needsDeduplicate(c.Timestamps) {
+		return
+	}
+
+	sort.Stable(c)
+	var i int
+	for j := 1; j < len(c.Timestamps); j++ {
+		if c.Timestamps[j] != c.Timestamps[i] {
+			i++
+		}
+		c.Timestamps[i] = c.Timestamps[j]
+		c.Values[i] = c.Values[j]
+	}
+	c.Timestamps = c.Timestamps[:i+1]
+	c.Values = c.Values[:i+1]
+}
+
+func (c *unsignedCacheColumn) size() int {
+	return (8 + 8) * len(c.Timestamps)
+}
+
+// UnsignedArray returns a copy of all unsigned values, deduped and sorted,
+// for the given key. It returns nil if the key has no unsigned values.
+func (c *Cache) UnsignedArray(key []byte) *tsdb.UnsignedArray {
+	entries, sz := c.entries(key)
+	if sz == 0 {
+		return nil
+	}
+
+	a := &unsignedCacheColumn{}
+	a.Timestamps = make([]int64, 0, sz)
+	a.Values = make([]uint64, 0, sz)
+	for _, e := range entries {
+		e.mu.RLock()
+		if col, ok := e.column.(*unsignedCacheColumn); ok {
+			a.Timestamps = append(a.Timestamps, col.Timestamps...)
+			a.Values = append(a.Values, col.Values...)
+		}
+		e.mu.RUnlock()
+	}
+	if a.Len() == 0 {
+		return nil
+	}
+	a.deduplicate()
+	return &a.UnsignedArray
+}
+
+// stringCacheColumn stores the string values of an entry as parallel arrays of
+// timestamps and values.
+type stringCacheColumn struct {
+	tsdb.StringArray
+}
+
+func (c *stringCacheColumn) Less(i, j int) bool { return c.Timestamps[i] < c.Timestamps[j] }
+
+func (c *stringCacheColumn) Swap(i, j int) {
+	c.Timestamps[i], c.Timestamps[j] = c.Timestamps[j], c.Timestamps[i]
+	c.Values[i], c.Values[j] = c.Values[j], c.Values[i]
+}
+
+func (c *stringCacheColumn) timestamps() []int64 { return c.Timestamps }
+
+func (c *stringCacheColumn) appendValues(values []Value) {
+	for _, v := range values {
+		c.Timestamps = append(c.Timestamps, v.UnixNano())
+		c.Values = append(c.Values, v.(StringValue).RawValue())
+	}
+}
+
+func (c *stringCacheColumn) appendTo(dst Values) Values {
+	for i, t := range c.Timestamps {
+		dst = append(dst, NewRawStringValue(t, c.Values[i]))
+	}
+	return dst
+}
+
+func (c *stringCacheColumn) deduplicate() {
+	if !needsDeduplicate(c.Timestamps) {
+		return
+	}
+
+	sort.Stable(c)
+	var i int
+	for j := 1; j < len(c.Timestamps); j++ {
+		if c.Timestamps[j] != c.Timestamps[i] {
+			i++
+		}
+		c.Timestamps[i] = c.Timestamps[j]
+		c.Values[i] = c.Values[j]
+	}
+	c.Timestamps = c.Timestamps[:i+1]
+	c.Values = c.Values[:i+1]
+}
+
+func (c *stringCacheColumn) size() int {
+	sz := 8 * len(c.Timestamps)
+	for _, v := range c.Values {
+		sz += len(v)
+	}
+	return sz
+}
+
+// StringArray returns a copy of all string values, deduped and sorted,
+// for the given key. It returns nil if the key has no string values.
+func (c *Cache) StringArray(key []byte) *tsdb.StringArray {
+	entries, sz := c.entries(key)
+	if sz == 0 {
+		return nil
+	}
+
+	a := &stringCacheColumn{}
+	a.Timestamps = make([]int64, 0, sz)
+	a.Values = make([]string, 0, sz)
+	for _, e := range entries {
+		e.mu.RLock()
+		if col, ok := e.column.(*stringCacheColumn); ok {
+			a.Timestamps = append(a.Timestamps, col.Timestamps...)
+			a.Values = append(a.Values, col.Values...)
+		}
+		e.mu.RUnlock()
+	}
+	if a.Len() == 0 {
+		return nil
+	}
+	a.deduplicate()
+	return &a.StringArray
+}
+
+// booleanCacheColumn stores the boolean values of an entry as parallel arrays of
+// timestamps and values.
+type booleanCacheColumn struct {
+	tsdb.BooleanArray
+}
+
+func (c *booleanCacheColumn) Less(i, j int) bool { return c.Timestamps[i] < c.Timestamps[j] }
+
+func (c *booleanCacheColumn) Swap(i, j int) {
+	c.Timestamps[i], c.Timestamps[j] = c.Timestamps[j], c.Timestamps[i]
+	c.Values[i], c.Values[j] = c.Values[j], c.Values[i]
+}
+
+func (c *booleanCacheColumn) timestamps() []int64 { return c.Timestamps }
+
+func (c *booleanCacheColumn) appendValues(values []Value) {
+	for _, v := range values {
+		c.Timestamps = append(c.Timestamps, v.UnixNano())
+		c.Values = append(c.Values, v.(BooleanValue).RawValue())
+	}
+}
+
+func (c *booleanCacheColumn) appendTo(dst Values) Values {
+	for i, t := range c.Timestamps {
+		dst = append(dst, NewRawBooleanValue(t, c.Values[i]))
+	}
+	return dst
+}
+
+func (c *booleanCacheColumn) deduplicate() {
+	if !needsDeduplicate(c.Timestamps) {
+		return
+	}
+
+	sort.Stable(c)
+	var i int
+	for j := 1; j < len(c.Timestamps); j++ {
+		if c.Timestamps[j] != c.Timestamps[i] {
+			i++
+		}
+		c.Timestamps[i] = c.Timestamps[j]
+		c.Values[i] = c.Values[j]
+	}
+	c.Timestamps = c.Timestamps[:i+1]
+	c.Values = c.Values[:i+1]
+}
+
+func (c *booleanCacheColumn) size() int {
+	return (8 + 1) * len(c.Timestamps)
+}
+
+// BooleanArray returns a copy of all boolean values, deduped and sorted,
+// for the given key. It returns nil if the key has no boolean values.
+func (c *Cache) BooleanArray(key []byte) *tsdb.BooleanArray {
+	entries, sz := c.entries(key)
+	if sz == 0 {
+		return nil
+	}
+
+	a := &booleanCacheColumn{}
+	a.Timestamps = make([]int64, 0, sz)
+	a.Values = make([]bool, 0, sz)
+	for _, e := range entries {
+		e.mu.RLock()
+		if col, ok := e.column.(*booleanCacheColumn); ok {
+			a.Timestamps = append(a.Timestamps, col.Timestamps...)
+			a.Values = append(a.Values, col.Values...)
+		}
+		e.mu.RUnlock()
+	}
+	if a.Len() == 0 {
+		return nil
+	}
+	a.deduplicate()
+	return &a.BooleanArray
+}
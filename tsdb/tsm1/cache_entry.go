@@ -1,6 +1,7 @@
 package tsm1
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
 
@@ -15,20 +16,71 @@ type entry struct {
 	n int64
 
 	mu     sync.RWMutex
-	values Values // All stored values.
+	column cacheColumn // All stored values, or nil if there are none.
 
-	// The type of values stored. Read only so doesn't need to be protected by mu.
+	// The type of values stored. Set by the first values added to the entry.
 	vtype byte
 }
 
+// cacheColumn stores the values of an entry as parallel arrays of timestamps
+// and values of a single type, rather than as a slice of Value interfaces.
+// This avoids an allocation per value and lets cursors copy values out of the
+// cache without type assertions.
+type cacheColumn interface {
+	Len() int
+	FindRange(min, max int64) (int, int)
+	Exclude(min, max int64)
+
+	// timestamps returns the timestamps of the values in the column.
+	timestamps() []int64
+
+	// appendValues appends values, which must all be of the column's type.
+	appendValues(values []Value)
+
+	// appendTo appends the values in the column to dst.
+	appendTo(dst Values) Values
+
+	// deduplicate sorts the values by time, keeping the last value written
+	// for any duplicate timestamps.
+	deduplicate()
+
+	// size returns the size of the values in bytes, as calculated by Values.Size.
+	size() int
+}
+
+// newCacheColumn returns an empty column for values of type vtype.
+func newCacheColumn(vtype byte) cacheColumn {
+	switch vtype {
+	case 1:
+		return &floatCacheColumn{}
+	case 2:
+		return &integerCacheColumn{}
+	case 3:
+		return &stringCacheColumn{}
+	case 4:
+		return &booleanCacheColumn{}
+	case 5:
+		return &unsignedCacheColumn{}
+	default:
+		return nil
+	}
+}
+
+// needsDeduplicate returns true if the timestamps are not sorted or contain
+// duplicates.
+func needsDeduplicate(a []int64) bool {
+	for i := 1; i < len(a); i++ {
+		if a[i-1] >= a[i] {
+			return true
+		}
+	}
+	return false
+}
+
 // newEntryValues returns a new instance of entry with the given values.  If the
 // values are not valid, an error is returned.
 func newEntryValues(values []Value) (*entry, error) {
-	e := &entry{
-		values: make(Values, 0, len(values)),
-		n:      int64(len(values)),
-	}
-	e.values = append(e.values, values...)
+	e := &entry{}
 
 	// No values, don't check types and ordering
 	if len(values) == 0 {
@@ -43,6 +95,12 @@ func newEntryValues(values []Value) (*entry, error) {
 		}
 	}
 
+	if e.column = newCacheColumn(et); e.column == nil {
+		return nil, tsdb.ErrUnknownFieldType
+	}
+	e.column.appendValues(values)
+	e.n = int64(e.column.Len())
+
 	// Set the type of values stored.
 	e.vtype = et
 
@@ -64,20 +122,25 @@ func (e *entry) add(values []Value) error {
 		}
 	}
 
-	// entry currently has no values, so add the new ones and we're done.
+	// entry currently has no values, so create a column for the new ones.
 	e.mu.Lock()
-	if len(e.values) == 0 {
-		e.values = values
-		atomic.StoreInt64(&e.n, int64(len(e.values)))
-		e.vtype = valueType(values[0])
-		e.mu.Unlock()
-		return nil
+	defer e.mu.Unlock()
+	if e.column == nil {
+		et := valueType(values[0])
+		for _, v := range values {
+			if et != valueType(v) {
+				return tsdb.ErrFieldTypeConflict
+			}
+		}
+		if e.column = newCacheColumn(et); e.column == nil {
+			return tsdb.ErrUnknownFieldType
+		}
+		e.vtype = et
 	}
 
 	// Append the new values to the existing ones...
-	e.values = append(e.values, values...)
-	atomic.StoreInt64(&e.n, int64(len(e.values)))
-	e.mu.Unlock()
+	e.column.appendValues(values)
+	atomic.StoreInt64(&e.n, int64(e.column.Len()))
 	return nil
 }
 
@@ -87,11 +150,11 @@ func (e *entry) deduplicate() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	if len(e.values) <= 1 {
+	if e.column == nil || e.column.Len() <= 1 {
 		return
 	}
-	e.values = e.values.Deduplicate()
-	atomic.StoreInt64(&e.n, int64(len(e.values)))
+	e.column.deduplicate()
+	atomic.StoreInt64(&e.n, int64(e.column.Len()))
 }
 
 // count returns the number of values in this entry.
@@ -102,25 +165,77 @@ func (e *entry) count() int {
 // filter removes all values with timestamps between min and max inclusive.
 func (e *entry) filter(min, max int64) {
 	e.mu.Lock()
-	if len(e.values) > 1 {
-		e.values = e.values.Deduplicate()
+	if e.column != nil {
+		e.column.deduplicate()
+		e.column.Exclude(min, max)
+		atomic.StoreInt64(&e.n, int64(e.column.Len()))
 	}
-	e.values = e.values.Exclude(min, max)
-	atomic.StoreInt64(&e.n, int64(len(e.values)))
 	e.mu.Unlock()
 }
 
 // size returns the size of this entry in bytes.
 func (e *entry) size() int {
 	e.mu.RLock()
-	sz := e.values.Size()
-	e.mu.RUnlock()
-	return sz
+	defer e.mu.RUnlock()
+	if e.column == nil {
+		return 0
+	}
+	return e.column.size()
+}
+
+// values returns a copy of the entry's values.
+func (e *entry) values() Values {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.column == nil {
+		return nil
+	}
+	return e.column.appendTo(make(Values, 0, e.column.Len()))
+}
+
+// contains returns true if the entry has values for the time interval
+// [min, max] inclusive. The entry must be deduplicated before calling
+// contains or the results are undefined.
+func (e *entry) contains(min, max int64) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.column == nil {
+		return false
+	}
+
+	rmin, rmax := e.column.FindRange(min, max)
+	if rmin == -1 && rmax == -1 {
+		return false
+	}
+
+	ts := e.column.timestamps()
+	if ts[rmin] == min {
+		return true
+	}
+
+	if rmax < len(ts) && ts[rmax] == max {
+		return true
+	}
+
+	return rmax-rmin > 0
 }
 
 // InfluxQLType returns for the entry the data type of its values.
 func (e *entry) InfluxQLType() (influxql.DataType, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
-	return e.values.InfluxQLType()
+	switch e.vtype {
+	case 1:
+		return influxql.Float, nil
+	case 2:
+		return influxql.Integer, nil
+	case 3:
+		return influxql.String, nil
+	case 4:
+		return influxql.Boolean, nil
+	case 5:
+		return influxql.Unsigned, nil
+	default:
+		return influxql.Unknown, fmt.Errorf("no values to infer type")
+	}
 }
@@ -14,9 +14,11 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/tsdb"
 
 	"github.com/golang/snappy"
 )
@@ -382,6 +384,51 @@ func TestCache_CacheValues(t *testing.T) {
 	}
 }
 
+func TestCache_FloatArray(t *testing.T) {
+	c := NewCache(512)
+	if a := c.FloatArray([]byte("foo")); a != nil {
+		t.Fatalf("FloatArray returned for no such key")
+	}
+
+	if err := c.Write([]byte("foo"), Values{NewValue(2, 2.0), NewValue(3, 3.0)}); err != nil {
+		t.Fatalf("failed to write values to cache: %v", err)
+	}
+	if _, err := c.Snapshot(); err != nil {
+		t.Fatalf("failed to snapshot cache: %v", err)
+	}
+	if err := c.Write([]byte("foo"), Values{NewValue(4, 4.0), NewValue(1, 1.0), NewValue(3, 5.0)}); err != nil {
+		t.Fatalf("failed to write values to cache: %v", err)
+	}
+
+	exp := &tsdb.FloatArray{
+		Timestamps: []int64{1, 2, 3, 4},
+		Values:     []float64{1.0, 2.0, 5.0, 4.0},
+	}
+	if got := c.FloatArray([]byte("foo")); !reflect.DeepEqual(exp, got) {
+		t.Fatalf("unexpected float array, exp: %v, got %v", exp, got)
+	}
+
+	// Values of another type are not returned.
+	if a := c.IntegerArray([]byte("foo")); a != nil {
+		t.Fatalf("IntegerArray returned for float key: %v", a)
+	}
+}
+
+func TestCache_CacheWrite_UnsignedTypeConflict(t *testing.T) {
+	c := NewCache(0)
+	if err := c.Write([]byte("foo"), Values{NewValue(1, uint64(1))}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write([]byte("foo"), Values{NewValue(2, int64(2))}); err == nil {
+		t.Fatalf("expected field type conflict")
+	}
+
+	exp := Values{NewValue(1, uint64(1))}
+	if got := c.Values([]byte("foo")); !reflect.DeepEqual(exp, got) {
+		t.Fatalf("unexpected values, exp: %v, got %v", exp, got)
+	}
+}
+
 func TestCache_CacheSnapshot(t *testing.T) {
 	v0 := NewValue(2, 0.0)
 	v1 := NewValue(3, 2.0)
@@ -500,6 +547,107 @@ func TestCache_Snapshot_Stats(t *testing.T) {
 	}
 }
 
+func TestCache_SnapshotBuckets(t *testing.T) {
+	noisy, quiet := tsdb.EncodeName(1, 2), tsdb.EncodeName(1, 3)
+	noisyKey := append(noisy[:], "cpu"...)
+	quietKey := append(quiet[:], "mem"...)
+
+	c := NewCache(0)
+	c.SetBucketBudget(2, uint64(len(noisyKey)+16))
+	if err := c.Write(quietKey, Values{NewValue(1, 1.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(noisyKey, Values{NewValue(1, 1.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.BucketsOverBudget(); len(got) != 0 {
+		t.Fatalf("got buckets over budget %q, expected none", got)
+	}
+	if got, exp := c.unbudgetedSize(), uint64(len(quietKey)+16); got != exp {
+		t.Fatalf("got unbudgeted size %d, expected %d", got, exp)
+	}
+
+	if err := c.Write(noisyKey, Values{NewValue(2, 2.0)}); err != nil {
+		t.Fatal(err)
+	}
+	prefixes := c.BucketsOverBudget()
+	if len(prefixes) != 1 || string(prefixes[0]) != string(noisy[:]) {
+		t.Fatalf("got buckets over budget %q, expected %q", prefixes, noisy[:])
+	}
+
+	// Only the data of the bucket over budget is moved into the snapshot.
+	snapshot, err := c.SnapshotBuckets(prefixes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(snapshot.values(noisyKey)), 2; got != exp {
+		t.Fatalf("got %d snapshotted values, expected %d", got, exp)
+	}
+	if got := snapshot.values(quietKey); got != nil {
+		t.Fatalf("got snapshotted values %v, expected none", got)
+	}
+	if got, exp := len(c.Values(quietKey)), 1; got != exp {
+		t.Fatalf("got %d cached values, expected %d", got, exp)
+	}
+	if got, exp := atomic.LoadUint64(&c.tracker.snapshottedBytes), uint64(len(noisyKey)+32); got != exp {
+		t.Fatalf("got %d snapshotted bytes, expected %d", got, exp)
+	}
+
+	c.ClearSnapshot(true)
+	if got := c.Values(noisyKey); got != nil {
+		t.Fatalf("got cached values %v, expected none", got)
+	}
+	if got, exp := c.Size(), uint64(len(quietKey)+16); got != exp {
+		t.Fatalf("got cache size %d, expected %d", got, exp)
+	}
+	if got := c.BucketsOverBudget(); len(got) != 0 {
+		t.Fatalf("got buckets over budget %q, expected none", got)
+	}
+}
+
+func TestCache_ColdBuckets(t *testing.T) {
+	cold, hot := tsdb.EncodeName(1, 2), tsdb.EncodeName(1, 3)
+	coldKey := append(cold[:], "cpu"...)
+	hotKey := append(hot[:], "mem"...)
+
+	c := NewCache(0)
+	if err := c.Write(coldKey, Values{NewValue(1, 1.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(hotKey, Values{NewValue(1, 1.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ColdBuckets(time.Now().Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("got cold buckets %q, expected none", got)
+	}
+
+	// Only the bucket with a cold duration is reported, once it has passed.
+	c.SetBucketWriteColdDuration(2, time.Minute)
+	if got := c.ColdBuckets(time.Now()); len(got) != 0 {
+		t.Fatalf("got cold buckets %q, expected none", got)
+	}
+	prefixes := c.ColdBuckets(time.Now().Add(time.Hour))
+	if len(prefixes) != 1 || string(prefixes[0]) != string(cold[:]) {
+		t.Fatalf("got cold buckets %q, expected %q", prefixes, cold[:])
+	}
+
+	if _, err := c.SnapshotBuckets(prefixes); err != nil {
+		t.Fatal(err)
+	}
+	c.ClearSnapshot(true)
+	if got := c.ColdBuckets(time.Now().Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("got cold buckets %q, expected none", got)
+	}
+
+	c.SetBucketWriteColdDuration(2, 0)
+	if err := c.Write(coldKey, Values{NewValue(2, 2.0)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.ColdBuckets(time.Now().Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("got cold buckets %q, expected none", got)
+	}
+}
+
 func TestCache_CacheEmptySnapshot(t *testing.T) {
 	c := NewCache(512)
 
@@ -815,6 +963,37 @@ func BenchmarkCacheFloatEntries(b *testing.B) {
 	}
 }
 
+func BenchmarkCache_Scan(b *testing.B) {
+	cache := NewCache(0)
+	vals := make([]Value, 10000)
+	for i := range vals {
+		vals[i] = NewValue(int64(i), float64(i))
+	}
+	if err := cache.Write([]byte("test"), vals); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("values", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var sum float64
+			for _, v := range cache.Values([]byte("test")) {
+				sum += v.(FloatValue).RawValue()
+			}
+		}
+	})
+
+	b.Run("array", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var sum float64
+			for _, v := range cache.FloatArray([]byte("test")).Values {
+				sum += v
+			}
+		}
+	})
+}
+
 type points struct {
 	key  []byte
 	vals []Value
@@ -14,11 +14,17 @@ func _() {
 	_ = x[CacheStatusColdNoWrites-3]
 	_ = x[CacheStatusRetention-4]
 	_ = x[CacheStatusFullCompaction-5]
+	_ = x[CacheStatusBackup-6]
+	_ = x[CacheStatusDrain-7]
+	_ = x[CacheStatusCopy-8]
+	_ = x[CacheStatusBucketBudgetExceeded-9]
+	_ = x[CacheStatusIndexRebuild-10]
+	_ = x[CacheStatusBucketColdNoWrites-11]
 }
 
-const _CacheStatus_name = "CacheStatusOkayCacheStatusSizeExceededCacheStatusAgeExceededCacheStatusColdNoWritesCacheStatusRetentionCacheStatusFullCompaction"
+const _CacheStatus_name = "CacheStatusOkayCacheStatusSizeExceededCacheStatusAgeExceededCacheStatusColdNoWritesCacheStatusRetentionCacheStatusFullCompactionCacheStatusBackupCacheStatusDrainCacheStatusCopyCacheStatusBucketBudgetExceededCacheStatusIndexRebuildCacheStatusBucketColdNoWrites"
 
-var _CacheStatus_index = [...]uint8{0, 15, 38, 60, 83, 103, 128}
+var _CacheStatus_index = [...]uint16{0, 15, 38, 60, 83, 103, 128, 145, 161, 176, 207, 230, 259}
 
 func (i CacheStatus) String() string {
 	if i < 0 || i >= CacheStatus(len(_CacheStatus_index)-1) {
@@ -113,7 +113,12 @@ func (k *tsmKeyIterator) combineFloat(dedup bool) blocks {
 				continue
 			}
 			// If we this block is already full, just add it as is
-			if BlockCount(k.blocks[i].b) >= k.size {
+			n, err := BlockCount(k.blocks[i].b)
+			if err != nil {
+				k.err = err
+				return nil
+			}
+			if n >= k.size {
 				k.merged = append(k.merged, k.blocks[i])
 			} else {
 				break
@@ -312,7 +317,12 @@ func (k *tsmKeyIterator) combineInteger(dedup bool) blocks {
 				continue
 			}
 			// If we this block is already full, just add it as is
-			if BlockCount(k.blocks[i].b) >= k.size {
+			n, err := BlockCount(k.blocks[i].b)
+			if err != nil {
+				k.err = err
+				return nil
+			}
+			if n >= k.size {
 				k.merged = append(k.merged, k.blocks[i])
 			} else {
 				break
@@ -511,7 +521,12 @@ func (k *tsmKeyIterator) combineUnsigned(dedup bool) blocks {
 				continue
 			}
 			// If we this block is already full, just add it as is
-			if BlockCount(k.blocks[i].b) >= k.size {
+			n, err := BlockCount(k.blocks[i].b)
+			if err != nil {
+				k.err = err
+				return nil
+			}
+			if n >= k.size {
 				k.merged = append(k.merged, k.blocks[i])
 			} else {
 				break
@@ -710,7 +725,12 @@ func (k *tsmKeyIterator) combineString(dedup bool) blocks {
 				continue
 			}
 			// If we this block is already full, just add it as is
-			if BlockCount(k.blocks[i].b) >= k.size {
+			n, err := BlockCount(k.blocks[i].b)
+			if err != nil {
+				k.err = err
+				return nil
+			}
+			if n >= k.size {
 				k.merged = append(k.merged, k.blocks[i])
 			} else {
 				break
@@ -909,7 +929,12 @@ func (k *tsmKeyIterator) combineBoolean(dedup bool) blocks {
 				continue
 			}
 			// If we this block is already full, just add it as is
-			if BlockCount(k.blocks[i].b) >= k.size {
+			n, err := BlockCount(k.blocks[i].b)
+			if err != nil {
+				k.err = err
+				return nil
+			}
+			if n >= k.size {
 				k.merged = append(k.merged, k.blocks[i])
 			} else {
 				break
@@ -1117,7 +1142,12 @@ func (k *tsmBatchKeyIterator) combineFloat(dedup bool) blocks {
 			continue
 		}
 		// If we this block is already full, just add it as is
-		if BlockCount(k.blocks[i].b) >= k.size {
+		n, err := BlockCount(k.blocks[i].b)
+		if err != nil {
+			k.err = err
+			return nil
+		}
+		if n >= k.size {
 			k.merged = append(k.merged, k.blocks[i])
 		} else {
 			break
@@ -1336,7 +1366,12 @@ func (k *tsmBatchKeyIterator) combineInteger(dedup bool) blocks {
 			continue
 		}
 		// If we this block is already full, just add it as is
-		if BlockCount(k.blocks[i].b) >= k.size {
+		n, err := BlockCount(k.blocks[i].b)
+		if err != nil {
+			k.err = err
+			return nil
+		}
+		if n >= k.size {
 			k.merged = append(k.merged, k.blocks[i])
 		} else {
 			break
@@ -1555,7 +1590,12 @@ func (k *tsmBatchKeyIterator) combineUnsigned(dedup bool) blocks {
 			continue
 		}
 		// If we this block is already full, just add it as is
-		if BlockCount(k.blocks[i].b) >= k.size {
+		n, err := BlockCount(k.blocks[i].b)
+		if err != nil {
+			k.err = err
+			return nil
+		}
+		if n >= k.size {
 			k.merged = append(k.merged, k.blocks[i])
 		} else {
 			break
@@ -1774,7 +1814,12 @@ func (k *tsmBatchKeyIterator) combineString(dedup bool) blocks {
 			continue
 		}
 		// If we this block is already full, just add it as is
-		if BlockCount(k.blocks[i].b) >= k.size {
+		n, err := BlockCount(k.blocks[i].b)
+		if err != nil {
+			k.err = err
+			return nil
+		}
+		if n >= k.size {
 			k.merged = append(k.merged, k.blocks[i])
 		} else {
 			break
@@ -1993,7 +2038,12 @@ func (k *tsmBatchKeyIterator) combineBoolean(dedup bool) blocks {
 			continue
 		}
 		// If we this block is already full, just add it as is
-		if BlockCount(k.blocks[i].b) >= k.size {
+		n, err := BlockCount(k.blocks[i].b)
+		if err != nil {
+			k.err = err
+			return nil
+		}
+		if n >= k.size {
 			k.merged = append(k.merged, k.blocks[i])
 		} else {
 			break
@@ -27,6 +27,8 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/encryption"
+	"github.com/influxdata/influxdb/pkg/fault"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/tsdb"
 )
@@ -85,6 +87,11 @@ type CompactionPlanner interface {
 	Release(group []CompactionGroup)
 	FullyCompacted() bool
 
+	// Acquire marks the files in the groups as in use, so they are not
+	// returned by other plans until released. It returns false, and marks
+	// nothing, if any of the files are already in use.
+	Acquire(groups []CompactionGroup) bool
+
 	// ForceFull causes the planner to return a full compaction plan the next
 	// time Plan() is called if there are files that could be compacted.
 	ForceFull()
@@ -123,6 +130,10 @@ type DefaultPlanner struct {
 	// filesInUse is the set of files that have been returned as part of a plan and might
 	// be being compacted.  Two plans should not return the same file at any given time.
 	filesInUse map[string]struct{}
+
+	// fanOut is the number of generations compacted together by level 2 and
+	// higher plans. Level 1 plans compact twice as many.
+	fanOut int
 }
 
 type fileStore interface {
@@ -137,6 +148,7 @@ func NewDefaultPlanner(fs fileStore, writeColdDuration time.Duration) *DefaultPl
 		FileStore:                    fs,
 		compactFullWriteColdDuration: writeColdDuration,
 		filesInUse:                   make(map[string]struct{}),
+		fanOut:                       defaultPlannerFanOut,
 	}
 }
 
@@ -277,9 +289,9 @@ func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 		}
 	}
 
-	minGenerations := 4
+	minGenerations := c.fanOut
 	if level == 1 {
-		minGenerations = 8
+		minGenerations = 2 * c.fanOut
 	}
 
 	var cGroups []CompactionGroup
@@ -304,7 +316,7 @@ func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 		}
 	}
 
-	if !c.acquire(cGroups) {
+	if !c.Acquire(cGroups) {
 		return nil
 	}
 
@@ -382,7 +394,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	var cGroups []CompactionGroup
 	for _, group := range levelGroups {
 		// Skip the group if it's not worthwhile to optimize it
-		if len(group) < 4 && !group.hasTombstones() {
+		if len(group) < c.fanOut && !group.hasTombstones() {
 			continue
 		}
 
@@ -396,7 +408,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 		cGroups = append(cGroups, cGroup)
 	}
 
-	if !c.acquire(cGroups) {
+	if !c.Acquire(cGroups) {
 		return nil
 	}
 
@@ -459,7 +471,7 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 		}
 
 		group := []CompactionGroup{tsmFiles}
-		if !c.acquire(group) {
+		if !c.Acquire(group) {
 			return nil
 		}
 		return group
@@ -519,9 +531,9 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 		}
 	}
 
-	// step is how may files to compact in a group.  We want to clamp it at 4 but also stil
-	// return groups smaller than 4.
-	step := 4
+	// step is how may files to compact in a group.  We want to clamp it at the fan-out
+	// but also still return smaller groups.
+	step := c.fanOut
 	if step > end {
 		step = end
 	}
@@ -575,7 +587,7 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 	compactable := []tsmGenerations{}
 	for _, group := range groups {
 		//if we don't have enough generations to compact, skip it
-		if len(group) < 4 && !group.hasTombstones() {
+		if len(group) < c.fanOut && !group.hasTombstones() {
 			continue
 		}
 		compactable = append(compactable, group)
@@ -595,7 +607,7 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 		tsmFiles = append(tsmFiles, cGroup)
 	}
 
-	if !c.acquire(tsmFiles) {
+	if !c.Acquire(tsmFiles) {
 		return nil
 	}
 	return tsmFiles
@@ -627,6 +639,12 @@ func (c *DefaultPlanner) findGenerations(skipInUse bool) tsmGenerations {
 			continue
 		}
 
+		// Skip offloaded files, as compacting them would fetch all of their
+		// blocks back from the object store.
+		if f.Offloaded {
+			continue
+		}
+
 		group := generations[gen]
 		if group == nil {
 			group = newTsmGeneration(gen, c.ParseFileName)
@@ -649,7 +667,9 @@ func (c *DefaultPlanner) findGenerations(skipInUse bool) tsmGenerations {
 	return orderedGenerations
 }
 
-func (c *DefaultPlanner) acquire(groups []CompactionGroup) bool {
+// Acquire marks the files in the groups as in use. It returns false if any
+// of the files are already part of another plan.
+func (c *DefaultPlanner) Acquire(groups []CompactionGroup) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -698,6 +718,27 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// BlockCompression, if set, determines the compression of blocks written
+	// by compactions. Blocks are transcoded if they were previously written
+	// with a different compression. Snapshots of the cache are always written
+	// without block compression, as that data is likely to be compacted again.
+	BlockCompression *BlockCompressionPolicy
+
+	// ValueEncoding, if set, determines the encoding of the values of blocks
+	// written by compactions. Like block compression, it is not applied to
+	// snapshots of the cache.
+	ValueEncoding *ValueEncodingPolicy
+
+	// ShardGroups, if set, holds the shard group duration of each bucket.
+	// Blocks written for those buckets are split so that no block spans
+	// more than one shard group.
+	ShardGroups *ShardGroupDurations
+
+	// Strategies, if set, holds the compaction strategy of each bucket. Files
+	// are ended at the boundaries of buckets that do not use the default
+	// strategy, so that their files can be planned by their own strategy.
+	Strategies *CompactionStrategies
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -853,6 +894,12 @@ func (c *Compactor) WriteSnapshot(ctx context.Context, cache *Cache) ([]string,
 	for i := 0; i < concurrency; i++ {
 		go func(sp *Cache) {
 			iter := NewCacheKeyIterator(sp, MaxPointsPerBlock, intC)
+			if c.ShardGroups != nil {
+				iter = &partitioningKeyIterator{KeyIterator: iter, durations: c.ShardGroups}
+			}
+			if cipher := encryption.Default(); cipher != nil {
+				iter = &encryptingKeyIterator{KeyIterator: iter, cipher: cipher}
+			}
 			files, err := c.writeNewFiles(c.FileStore.NextGeneration(), 0, nil, iter, throttle)
 			resC <- res{files: files, err: err}
 
@@ -946,9 +993,56 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 		return nil, err
 	}
 
+	if c.ShardGroups != nil {
+		tsm = &partitioningKeyIterator{KeyIterator: tsm, durations: c.ShardGroups}
+	}
+
+	// Values are re-encoded before blocks are compressed.
+	if c.ValueEncoding != nil {
+		tsm = &reencodingKeyIterator{KeyIterator: tsm, policy: c.ValueEncoding}
+	}
+
+	if c.BlockCompression != nil {
+		tsm = &transcodingKeyIterator{KeyIterator: tsm, policy: c.BlockCompression}
+	}
+
+	// Blocks are encrypted after they are compressed, re-encrypting blocks
+	// written with an older key so that compaction migrates existing files.
+	if cipher := encryption.Default(); cipher != nil {
+		tsm = &encryptingKeyIterator{KeyIterator: tsm, cipher: cipher}
+	}
+
 	return c.writeNewFiles(maxGeneration, maxSequence, tsmFiles, tsm, true)
 }
 
+// WriteKeys writes the keys and blocks read from iter to new TSM files of the
+// next generation. The blocks are partitioned, compressed and encrypted as
+// they are by compactions. The files are returned with a temporary extension,
+// to be added to the file store by the caller.
+func (c *Compactor) WriteKeys(iter KeyIterator) ([]string, error) {
+	c.mu.RLock()
+	enabled := c.snapshotsEnabled || c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	if c.ShardGroups != nil {
+		iter = &partitioningKeyIterator{KeyIterator: iter, durations: c.ShardGroups}
+	}
+	if c.ValueEncoding != nil {
+		iter = &reencodingKeyIterator{KeyIterator: iter, policy: c.ValueEncoding}
+	}
+	if c.BlockCompression != nil {
+		iter = &transcodingKeyIterator{KeyIterator: iter, policy: c.BlockCompression}
+	}
+	if cipher := encryption.Default(); cipher != nil {
+		iter = &encryptingKeyIterator{KeyIterator: iter, cipher: cipher}
+	}
+	return c.writeNewFiles(c.FileStore.NextGeneration(), 0, nil, iter, true)
+}
+
 // CompactFull writes multiple smaller TSM files into 1 or more larger files.
 func (c *Compactor) CompactFull(tsmFiles []string) ([]string, error) {
 	c.mu.RLock()
@@ -1031,6 +1125,10 @@ func (c *Compactor) writeNewFiles(generation, sequence int, src []string, iter K
 	// These are the new TSM files written
 	var files []string
 
+	if c.Strategies != nil {
+		iter = &unreadKeyIterator{KeyIterator: iter}
+	}
+
 	for {
 		sequence++
 
@@ -1085,6 +1183,10 @@ func (c *Compactor) writeNewFiles(generation, sequence int, src []string, iter K
 }
 
 func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err error) {
+	if err := fault.Err(fault.TSMWrite); err != nil {
+		return err
+	}
+
 	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
 	if err != nil {
 		return errCompactionInProgress{err: err}
@@ -1141,6 +1243,7 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 		}
 	}()
 
+	var bucket []byte
 	for iter.Next() {
 		c.mu.RLock()
 		enabled := c.snapshotsEnabled || c.compactionsEnabled
@@ -1161,6 +1264,21 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 			return fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
 		}
 
+		// End the file at the boundary of a bucket with its own strategy. The
+		// block is read again as the first of the next file.
+		if u, ok := iter.(*unreadKeyIterator); ok {
+			if bucket != nil && c.Strategies.boundary(bucket, key) {
+				u.Unread()
+				if err := w.WriteIndex(); err != nil {
+					return err
+				}
+				return errMaxFileExceeded
+			}
+			if len(key) >= bucketPrefixSize {
+				bucket = append(bucket[:0], key[:bucketPrefixSize]...)
+			}
+		}
+
 		// Write the key and value
 		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
@@ -1513,6 +1631,12 @@ RETRY:
 
 	k.merge()
 
+	// A block that could not be merged is left in place, so return its error
+	// from Read instead of merging it again.
+	if k.err != nil {
+		return true
+	}
+
 	// After merging all the values for this key, we might not have any.  (e.g. they were all deleted
 	// through many tombstones).  In this case, move on to the next key instead of ending iteration.
 	if len(k.merged) == 0 {
@@ -1801,6 +1925,12 @@ RETRY:
 
 	k.merge()
 
+	// A block that could not be merged is left in place, so return its error
+	// from Read instead of merging it again.
+	if k.err != nil {
+		return true
+	}
+
 	// After merging all the values for this key, we might not have any.  (e.g. they were all deleted
 	// through many tombstones).  In this case, move on to the next key instead of ending iteration.
 	if len(k.merged) == 0 {
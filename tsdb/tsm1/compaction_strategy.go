@@ -0,0 +1,461 @@
+package tsm1
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// DefaultCompactionStrategy is the name of the compaction strategy used for
+// buckets that do not select one.
+const DefaultCompactionStrategy = "default"
+
+// defaultPlannerFanOut is the number of generations the DefaultPlanner
+// compacts together at level 2 and higher.
+const defaultPlannerFanOut = 4
+
+// PlannerFileStore is the view of the TSM files that a CompactionPlanner
+// plans compactions of.
+type PlannerFileStore interface {
+	Stats() []FileStat
+	LastModified() time.Time
+	BlockCount(path string, idx int) int
+	ParseFileName(path string) (int, int, error)
+}
+
+// PlannerFactory returns a CompactionPlanner for the files of fs.
+type PlannerFactory func(fs PlannerFileStore, config CompactionConfig) CompactionPlanner
+
+var (
+	plannersMu sync.RWMutex
+	planners   = make(map[string]PlannerFactory)
+)
+
+func init() {
+	RegisterCompactionStrategy(DefaultCompactionStrategy, leveledPlannerFactory(defaultPlannerFanOut))
+
+	// Data that is rarely rewritten gains little from being compacted often,
+	// so files are rolled up in larger, less frequent steps.
+	RegisterCompactionStrategy("append-only", leveledPlannerFactory(2*defaultPlannerFanOut))
+
+	// Data that is frequently overwritten or deleted is compacted in smaller,
+	// more frequent steps so that duplicates and tombstones are dropped sooner.
+	RegisterCompactionStrategy("high-churn", leveledPlannerFactory(defaultPlannerFanOut/2))
+}
+
+// leveledPlannerFactory returns a factory of DefaultPlanners compacting
+// fanOut generations together.
+func leveledPlannerFactory(fanOut int) PlannerFactory {
+	return func(fs PlannerFileStore, config CompactionConfig) CompactionPlanner {
+		p := NewDefaultPlanner(fs, time.Duration(config.FullWriteColdDuration))
+		p.fanOut = fanOut
+		return p
+	}
+}
+
+// RegisterCompactionStrategy makes a compaction strategy available under
+// name, so that buckets can select it. It panics if name is already
+// registered or factory is nil.
+func RegisterCompactionStrategy(name string, factory PlannerFactory) {
+	plannersMu.Lock()
+	defer plannersMu.Unlock()
+	if factory == nil {
+		panic("tsm1: RegisterCompactionStrategy factory is nil")
+	}
+	if _, ok := planners[name]; ok {
+		panic("tsm1: RegisterCompactionStrategy called twice for " + name)
+	}
+	planners[name] = factory
+}
+
+// CompactionStrategyNames returns the sorted names of the registered
+// compaction strategies.
+func CompactionStrategyNames() []string {
+	plannersMu.RLock()
+	defer plannersMu.RUnlock()
+	names := make([]string, 0, len(planners))
+	for name := range planners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateCompactionStrategy returns an error if name is not empty or the name
+// of a registered compaction strategy.
+func ValidateCompactionStrategy(name string) error {
+	if name == "" {
+		return nil
+	}
+	plannersMu.RLock()
+	defer plannersMu.RUnlock()
+	if _, ok := planners[name]; !ok {
+		return fmt.Errorf("unknown compaction strategy %q", name)
+	}
+	return nil
+}
+
+func lookupPlannerFactory(name string) PlannerFactory {
+	plannersMu.RLock()
+	defer plannersMu.RUnlock()
+	return planners[name]
+}
+
+// CompactionStrategies holds the compaction strategy of each bucket.
+type CompactionStrategies struct {
+	mu         sync.RWMutex
+	strategies map[influxdb.ID]string
+	modified   time.Time
+}
+
+// NewCompactionStrategies returns a set of strategies in which every bucket
+// uses the default strategy.
+func NewCompactionStrategies() *CompactionStrategies {
+	return &CompactionStrategies{strategies: make(map[influxdb.ID]string)}
+}
+
+// Set sets the compaction strategy of a bucket. An empty name selects the
+// default strategy.
+func (s *CompactionStrategies) Set(bucketID influxdb.ID, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if name == DefaultCompactionStrategy {
+		name = ""
+	}
+	if s.strategies[bucketID] == name {
+		return
+	}
+	if name == "" {
+		delete(s.strategies, bucketID)
+	} else {
+		s.strategies[bucketID] = name
+	}
+	s.modified = time.Now()
+}
+
+// Strategy returns the compaction strategy for the bucket of a series key.
+func (s *CompactionStrategies) Strategy(key []byte) string {
+	if s == nil || len(key) < bucketPrefixSize {
+		return DefaultCompactionStrategy
+	}
+	_, bucketID := tsdb.DecodeNameSlice(key[:bucketPrefixSize])
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if name, ok := s.strategies[bucketID]; ok {
+		return name
+	}
+	return DefaultCompactionStrategy
+}
+
+// names returns the sorted names of the strategies in use, including the
+// default strategy.
+func (s *CompactionStrategies) names() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	set := map[string]struct{}{DefaultCompactionStrategy: {}}
+	for _, name := range s.strategies {
+		set[name] = struct{}{}
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lastModified returns when a strategy was last changed.
+func (s *CompactionStrategies) lastModified() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.modified
+}
+
+// boundary returns true if key belongs to a different bucket than the bucket
+// prefix prev, and either bucket has a strategy other than the default.
+func (s *CompactionStrategies) boundary(prev, key []byte) bool {
+	if len(key) < bucketPrefixSize || bytes.Equal(prev, key[:bucketPrefixSize]) {
+		return false
+	}
+	return s.Strategy(prev) != DefaultCompactionStrategy || s.Strategy(key) != DefaultCompactionStrategy
+}
+
+// fileStrategy returns the strategy that plans the compactions of a file.
+// Files holding the data of more than one bucket are planned by the default
+// strategy.
+func (s *CompactionStrategies) fileStrategy(st FileStat) string {
+	if len(st.MinKey) < bucketPrefixSize || len(st.MaxKey) < bucketPrefixSize ||
+		!bytes.Equal(st.MinKey[:bucketPrefixSize], st.MaxKey[:bucketPrefixSize]) {
+		return DefaultCompactionStrategy
+	}
+	return s.Strategy(st.MinKey)
+}
+
+// BucketPlanner implements CompactionPlanner by planning the files of each
+// bucket with the strategy the bucket selects. Each strategy plans only the
+// files that hold the data of buckets using it.
+type BucketPlanner struct {
+	strategies *CompactionStrategies
+	config     CompactionConfig
+
+	mu       sync.RWMutex
+	fs       PlannerFileStore
+	planners map[string]CompactionPlanner
+}
+
+// NewBucketPlanner returns a BucketPlanner for the files of fs.
+func NewBucketPlanner(fs PlannerFileStore, strategies *CompactionStrategies, config CompactionConfig) *BucketPlanner {
+	return &BucketPlanner{
+		strategies: strategies,
+		config:     config,
+		fs:         fs,
+		planners:   make(map[string]CompactionPlanner),
+	}
+}
+
+// activePlanners returns the planners of the strategies in use. Buckets with
+// an unregistered strategy are planned by the default strategy.
+func (p *BucketPlanner) activePlanners() []CompactionPlanner {
+	names := p.strategies.names()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	active := make([]CompactionPlanner, 0, len(names))
+	for _, name := range names {
+		planner, ok := p.planners[name]
+		if !ok {
+			factory := lookupPlannerFactory(name)
+			if factory == nil {
+				continue
+			}
+			planner = factory(&strategyFileStore{planner: p, strategy: name}, p.config)
+			p.planners[name] = planner
+		}
+		active = append(active, planner)
+	}
+	return active
+}
+
+// planner returns the planner of the file at path.
+func (p *BucketPlanner) planner(stats map[string]FileStat, path string) CompactionPlanner {
+	name := DefaultCompactionStrategy
+	if st, ok := stats[path]; ok {
+		name = p.owner(st)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if planner, ok := p.planners[name]; ok {
+		return planner
+	}
+	return p.planners[DefaultCompactionStrategy]
+}
+
+// owner returns the name of the strategy planning a file.
+func (p *BucketPlanner) owner(st FileStat) string {
+	name := p.strategies.fileStrategy(st)
+	if lookupPlannerFactory(name) == nil {
+		return DefaultCompactionStrategy
+	}
+	return name
+}
+
+func (p *BucketPlanner) fileStore() PlannerFileStore {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.fs
+}
+
+func (p *BucketPlanner) Plan(lastWrite time.Time) []CompactionGroup {
+	var groups []CompactionGroup
+	for _, planner := range p.activePlanners() {
+		groups = append(groups, planner.Plan(lastWrite)...)
+	}
+	return groups
+}
+
+func (p *BucketPlanner) PlanLevel(level int) []CompactionGroup {
+	var groups []CompactionGroup
+	for _, planner := range p.activePlanners() {
+		groups = append(groups, planner.PlanLevel(level)...)
+	}
+	return groups
+}
+
+func (p *BucketPlanner) PlanOptimize() []CompactionGroup {
+	var groups []CompactionGroup
+	for _, planner := range p.activePlanners() {
+		groups = append(groups, planner.PlanOptimize()...)
+	}
+	return groups
+}
+
+// Release releases the files of the groups from every strategy, as the
+// strategy of a file may have changed since it was acquired.
+func (p *BucketPlanner) Release(groups []CompactionGroup) {
+	for _, planner := range p.activePlanners() {
+		planner.Release(groups)
+	}
+}
+
+func (p *BucketPlanner) FullyCompacted() bool {
+	for _, planner := range p.activePlanners() {
+		if !planner.FullyCompacted() {
+			return false
+		}
+	}
+	return true
+}
+
+// Acquire marks the files in the groups as in use by the strategies that plan
+// them. It returns false, and marks nothing, if any of the files are already
+// in use.
+func (p *BucketPlanner) Acquire(groups []CompactionGroup) bool {
+	active := p.activePlanners()
+
+	stats := make(map[string]FileStat)
+	for _, st := range p.fileStore().Stats() {
+		stats[st.Path] = st
+	}
+
+	owned := make(map[CompactionPlanner][]CompactionGroup, len(active))
+	for _, g := range groups {
+		for _, f := range g {
+			planner := p.planner(stats, f)
+			owned[planner] = append(owned[planner], CompactionGroup{f})
+		}
+	}
+
+	var acquired []CompactionPlanner
+	for _, planner := range active {
+		if len(owned[planner]) == 0 {
+			continue
+		}
+		if !planner.Acquire(owned[planner]) {
+			for _, a := range acquired {
+				a.Release(owned[a])
+			}
+			return false
+		}
+		acquired = append(acquired, planner)
+	}
+	return true
+}
+
+func (p *BucketPlanner) ForceFull() {
+	for _, planner := range p.activePlanners() {
+		planner.ForceFull()
+	}
+}
+
+func (p *BucketPlanner) SetFileStore(fs *FileStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fs = fs
+}
+
+// strategyFileStore is the view of the files of a BucketPlanner that are
+// planned by one strategy.
+type strategyFileStore struct {
+	planner  *BucketPlanner
+	strategy string
+
+	mu sync.RWMutex
+	// levels holds the lowest sequence of each generation across all files.
+	levels map[int]int
+}
+
+// Stats returns the stats of the files planned by the strategy.
+func (s *strategyFileStore) Stats() []FileStat {
+	fs := s.planner.fileStore()
+	all := fs.Stats()
+
+	levels := make(map[int]int)
+	stats := make([]FileStat, 0, len(all))
+	for _, st := range all {
+		if gen, seq, err := fs.ParseFileName(st.Path); err == nil {
+			if l, ok := levels[gen]; !ok || seq < l {
+				levels[gen] = seq
+			}
+		}
+		if s.planner.owner(st) == s.strategy {
+			stats = append(stats, st)
+		}
+	}
+
+	s.mu.Lock()
+	s.levels = levels
+	s.mu.Unlock()
+	return stats
+}
+
+// LastModified returns when the files or the strategies of their buckets
+// were last changed.
+func (s *strategyFileStore) LastModified() time.Time {
+	t := s.planner.fileStore().LastModified()
+	if m := s.planner.strategies.lastModified(); m.After(t) {
+		return m
+	}
+	return t
+}
+
+func (s *strategyFileStore) BlockCount(path string, idx int) int {
+	return s.planner.fileStore().BlockCount(path, idx)
+}
+
+// ParseFileName returns the generation of a file, and the lowest sequence of
+// that generation. The files of a generation can be split between
+// strategies, and the level of a generation is given by its first file.
+func (s *strategyFileStore) ParseFileName(path string) (int, int, error) {
+	gen, seq, err := s.planner.fileStore().ParseFileName(path)
+	if err != nil {
+		return gen, seq, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if l, ok := s.levels[gen]; ok {
+		seq = l
+	}
+	return gen, seq, nil
+}
+
+// unreadKeyIterator is a KeyIterator whose last block can be unread, so that
+// it is read again after the next call to Next.
+type unreadKeyIterator struct {
+	KeyIterator
+
+	replay, read     bool
+	key              []byte
+	minTime, maxTime int64
+	block            []byte
+	err              error
+}
+
+func (k *unreadKeyIterator) Next() bool {
+	if k.replay {
+		k.replay = false
+		return true
+	}
+	k.read = false
+	return k.KeyIterator.Next()
+}
+
+func (k *unreadKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	if !k.read {
+		k.key, k.minTime, k.maxTime, k.block, k.err = k.KeyIterator.Read()
+		k.read = true
+	}
+	return k.key, k.minTime, k.maxTime, k.block, k.err
+}
+
+// Unread causes the next call to Next to return the last block read again.
+func (k *unreadKeyIterator) Unread() {
+	k.replay = true
+}
@@ -0,0 +1,149 @@
+package tsm1_test
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func bucketSeriesKey(bucketID influxdb.ID, series string) string {
+	name := tsdb.EncodeName(1, bucketID)
+	return string(name[:]) + series
+}
+
+func TestCompactionStrategies_Registry(t *testing.T) {
+	if err := tsm1.ValidateCompactionStrategy(""); err != nil {
+		t.Fatalf("unexpected error for empty strategy: %v", err)
+	}
+	for _, name := range tsm1.CompactionStrategyNames() {
+		if err := tsm1.ValidateCompactionStrategy(name); err != nil {
+			t.Fatalf("unexpected error for strategy %q: %v", name, err)
+		}
+	}
+	if err := tsm1.ValidateCompactionStrategy("unknown"); err == nil {
+		t.Fatal("expected error for unknown strategy")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic registering a strategy twice")
+		}
+	}()
+	tsm1.RegisterCompactionStrategy(tsm1.DefaultCompactionStrategy, func(fs tsm1.PlannerFileStore, config tsm1.CompactionConfig) tsm1.CompactionPlanner {
+		return tsm1.NewDefaultPlanner(fs, 0)
+	})
+}
+
+// Ensures snapshots end files at the boundaries of buckets that do not use
+// the default strategy.
+func TestCompactor_Snapshot_CompactionStrategies(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := tsm1.NewCache(0)
+	for _, bucketID := range []influxdb.ID{2, 3, 4} {
+		for _, host := range []string{"A", "B"} {
+			key := bucketSeriesKey(bucketID, ",host="+host+"#!~#value")
+			if err := c.Write([]byte(key), []tsm1.Value{tsm1.NewValue(1, float64(1))}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = &fakeFileStore{}
+	compactor.Strategies = tsm1.NewCompactionStrategies()
+	compactor.Strategies.Set(3, "high-churn")
+	compactor.Open()
+
+	files, err := compactor.WriteSnapshot(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	} else if got, exp := len(files), 3; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, f := range files {
+		r := MustOpenTSMReader(f)
+		if got, exp := r.KeyCount(), 2; got != exp {
+			t.Fatalf("file %d keys length mismatch: got %v, exp %v", i, got, exp)
+		}
+		min, max := r.KeyRange()
+		if string(min[:16]) != string(max[:16]) {
+			t.Fatalf("file %d holds the data of more than one bucket", i)
+		}
+		r.Close()
+	}
+}
+
+// Ensures files holding the data of a single bucket are planned by the
+// strategy of that bucket, and all others by the default strategy.
+func TestBucketPlanner_PlanLevel(t *testing.T) {
+	churn := []byte(bucketSeriesKey(2, ",host=A#!~#value"))
+	min := []byte(bucketSeriesKey(3, ",host=A#!~#value"))
+	max := []byte(bucketSeriesKey(4, ",host=A#!~#value"))
+
+	// The generation 08 is split between the strategies. Its second file is
+	// still a level 1 file.
+	data := []tsm1.FileStat{
+		{Path: "01-01.tsm1", MinKey: churn, MaxKey: churn},
+		{Path: "02-01.tsm1", MinKey: churn, MaxKey: churn},
+		{Path: "03-01.tsm1", MinKey: churn, MaxKey: churn},
+		{Path: "04-01.tsm1", MinKey: min, MaxKey: max},
+		{Path: "05-01.tsm1", MinKey: min, MaxKey: max},
+		{Path: "06-01.tsm1", MinKey: min, MaxKey: max},
+		{Path: "07-01.tsm1", MinKey: min, MaxKey: max},
+		{Path: "08-01.tsm1", MinKey: min, MaxKey: max},
+		{Path: "08-02.tsm1", MinKey: churn, MaxKey: churn},
+	}
+
+	fs := &fakeFileStore{
+		PathsFn: func() []tsm1.FileStat {
+			return data
+		},
+	}
+
+	strategies := tsm1.NewCompactionStrategies()
+	strategies.Set(2, "high-churn")
+	cp := tsm1.NewBucketPlanner(fs, strategies, tsm1.CompactionConfig{})
+
+	// Only the high-churn strategy has enough level 1 generations to plan.
+	tsm := cp.PlanLevel(1)
+	if got, exp := len(tsm), 1; got != exp {
+		t.Fatalf("tsm file plan length mismatch: got %v, exp %v", got, exp)
+	}
+	files := append([]string(nil), tsm[0]...)
+	sort.Strings(files)
+	if got, exp := files, []string{"01-01.tsm1", "02-01.tsm1", "03-01.tsm1", "08-02.tsm1"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+	}
+
+	// Planned files are in use until released.
+	if cp.Acquire([]tsm1.CompactionGroup{{"01-01.tsm1", "04-01.tsm1"}}) {
+		t.Fatal("expected planned file to be in use")
+	}
+	if !cp.Acquire([]tsm1.CompactionGroup{{"04-01.tsm1"}}) {
+		t.Fatal("expected unplanned file to be acquired")
+	}
+	cp.Release(tsm)
+	if !cp.Acquire([]tsm1.CompactionGroup{{"01-01.tsm1"}}) {
+		t.Fatal("expected released file to be acquired")
+	}
+	cp.Release([]tsm1.CompactionGroup{{"01-01.tsm1", "04-01.tsm1"}})
+
+	// Once the bucket uses the default strategy, all files are planned by it.
+	strategies.Set(2, "")
+	tsm = cp.PlanLevel(1)
+	if got, exp := len(tsm), 1; got != exp {
+		t.Fatalf("tsm file plan length mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := len(tsm[0]), len(data); got != exp {
+		t.Fatalf("tsm files length mismatch: got %v, exp %v", got, exp)
+	}
+}
@@ -33,8 +33,32 @@ type Config struct {
 	// preallocation to improve throughput. Currently used in the series file.
 	LargeSeriesWriteThreshold int `toml:"large-series-write-threshold"`
 
+	// BlockCompression is the compression applied to blocks as they are
+	// rewritten by compactions, in addition to the per-type encodings. Valid
+	// values are "none" and "zstd". Blocks written with a different compression
+	// are transcoded when they are next compacted.
+	BlockCompression string `toml:"block-compression"`
+
+	// BucketBlockCompression overrides BlockCompression for individual
+	// buckets, keyed by bucket ID.
+	BucketBlockCompression map[string]string `toml:"bucket-block-compression"`
+
+	// ValueEncoding is the encoding of the values of float and integer blocks
+	// as they are rewritten by compactions, as a comma separated list. Valid
+	// encodings are "xor" and "xor-runs" for floats, and "delta" and
+	// "delta-of-delta" for integers. An alternative encoding is only kept for
+	// blocks it makes smaller. Blocks written with an alternative encoding
+	// cannot be read by versions that do not support it.
+	ValueEncoding string `toml:"value-encoding"`
+
+	// BucketValueEncoding overrides ValueEncoding for individual buckets,
+	// keyed by bucket ID, or for individual measurements, keyed by bucket ID
+	// and measurement separated by a slash.
+	BucketValueEncoding map[string]string `toml:"bucket-value-encoding"`
+
 	Compaction CompactionConfig `toml:"compaction"`
 	Cache      CacheConfig      `toml:"cache"`
+	Tiering    TieringConfig    `toml:"tiering"`
 }
 
 // NewConfig constructs a Config with the default values.
@@ -44,7 +68,8 @@ func NewConfig() Config {
 		MADVWillNeed:              DefaultMADVWillNeed,
 		LargeSeriesWriteThreshold: DefaultLargeSeriesWriteThreshold,
 
-		Cache: NewCacheConfig(),
+		Cache:   NewCacheConfig(),
+		Tiering: NewTieringConfig(),
 		Compaction: CompactionConfig{
 			FullWriteColdDuration: toml.Duration(DefaultCompactFullWriteColdDuration),
 			Throughput:            toml.Size(DefaultCompactThroughput),
@@ -82,6 +107,15 @@ type CompactionConfig struct {
 	// MaxConcurrent is the maximum number of concurrent full and level compactions that can
 	// run at one time.  A value of 0 results in 50% of runtime.GOMAXPROCS(0) used at runtime.
 	MaxConcurrent int `toml:"max-concurrent"`
+
+	// MaxConcurrentFull is the maximum number of concurrent full and optimize compactions
+	// that can run at one time. A value of 0 limits them only by MaxConcurrent.
+	MaxConcurrentFull int `toml:"max-concurrent-full"`
+
+	// QuietHours are windows of the day, such as "09:00-17:00" in local time, during which
+	// level 3, full and optimize compactions are not started, so that compactions compete
+	// less with peak ingest. Level 1 and 2 compactions still run to keep up with writes.
+	QuietHours []string `toml:"quiet-hours"`
 }
 
 // Default Cache configuration values.
@@ -125,10 +159,53 @@ func NewCacheConfig() CacheConfig {
 	}
 }
 
+// Default tiering configuration values.
+const (
+	DefaultTieringColdDuration       = toml.Duration(30 * 24 * time.Hour) // 30 days
+	DefaultTieringCheckInterval      = toml.Duration(10 * time.Minute)
+	DefaultTieringCacheMaxMemorySize = toml.Size(256 << 20) // 256MB
+	DefaultTieringFetchTimeout       = toml.Duration(time.Minute)
+)
+
+// TieringConfig holds the configuration for offloading cold TSM files to an
+// object store.
+type TieringConfig struct {
+	// URL is the object store that cold TSM files are offloaded to, such as
+	// s3://bucket/prefix, gs://bucket/prefix, azblob://account/container or
+	// file:///path. Tiering is disabled if it is empty.
+	URL string `toml:"url"`
+
+	// ColdDuration is how long after its newest point a fully compacted TSM
+	// file is offloaded and replaced by a local stub.
+	ColdDuration toml.Duration `toml:"cold-duration"`
+
+	// CheckInterval is how often the engine looks for files to offload.
+	CheckInterval toml.Duration `toml:"check-interval"`
+
+	// CacheMaxMemorySize is the maximum size of the blocks fetched from the
+	// object store that are kept in memory.
+	CacheMaxMemorySize toml.Size `toml:"cache-max-memory-size"`
+
+	// FetchTimeout is the maximum duration of fetching a block from the
+	// object store. Zero disables the timeout.
+	FetchTimeout toml.Duration `toml:"fetch-timeout"`
+}
+
+// NewTieringConfig initialises a new TieringConfig with default values.
+func NewTieringConfig() TieringConfig {
+	return TieringConfig{
+		ColdDuration:       DefaultTieringColdDuration,
+		CheckInterval:      DefaultTieringCheckInterval,
+		CacheMaxMemorySize: DefaultTieringCacheMaxMemorySize,
+		FetchTimeout:       DefaultTieringFetchTimeout,
+	}
+}
+
 // Default WAL configuration values.
 const (
-	DefaultWALEnabled    = true
-	DefaultWALFsyncDelay = time.Duration(0)
+	DefaultWALEnabled            = true
+	DefaultWALFsyncDelay         = time.Duration(0)
+	DefaultWALFsyncMaxBatchBytes = 0
 )
 
 // WALConfig holds all of the configuration about the WAL.
@@ -141,11 +218,17 @@ type WALConfig struct {
 	// useful for slower disks or when WAL write contention is seen.  A value of 0 fsyncs
 	// every write to the WAL.
 	FsyncDelay toml.Duration `toml:"fsync-delay"`
+
+	// FsyncMaxBatchBytes is the number of bytes written to the WAL since the
+	// last fsync that causes an fsync without waiting for FsyncDelay. A value
+	// of 0 only fsyncs after FsyncDelay.
+	FsyncMaxBatchBytes toml.Size `toml:"fsync-max-batch-bytes"`
 }
 
 func NewWALConfig() WALConfig {
 	return WALConfig{
-		Enabled:    DefaultWALEnabled,
-		FsyncDelay: toml.Duration(DefaultWALFsyncDelay),
+		Enabled:            DefaultWALEnabled,
+		FsyncDelay:         toml.Duration(DefaultWALFsyncDelay),
+		FsyncMaxBatchBytes: toml.Size(DefaultWALFsyncMaxBatchBytes),
 	}
 }
@@ -162,7 +162,7 @@ func (a Values) InfluxQLType() (influxql.DataType, error) {
 // BlockType returns the type of value encoded in a block or an error
 // if the block type is unknown.
 func BlockType(block []byte) (byte, error) {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlags
 	switch blockType {
 	case BlockFloat64, BlockInteger, BlockUnsigned, BlockBoolean, BlockString:
 		return blockType, nil
@@ -171,17 +171,24 @@ func BlockType(block []byte) (byte, error) {
 	}
 }
 
-// BlockCount returns the number of timestamps encoded in block.
-func BlockCount(block []byte) int {
+// BlockCount returns the number of timestamps encoded in block. It returns an
+// error if the block cannot be decrypted or decompressed, such as when the key
+// it was encrypted with is not configured.
+func BlockCount(block []byte) (int, error) {
 	if len(block) <= encodedBlockHeaderSize {
-		panic(fmt.Sprintf("count of short block: got %v, exp %v", len(block), encodedBlockHeaderSize))
+		return 0, fmt.Errorf("BlockCount: short block: got %v, exp %v", len(block), encodedBlockHeaderSize)
+	}
+	block, err := removeBlockWrapping(block)
+	if err != nil {
+		return 0, fmt.Errorf("BlockCount: error unwrapping block: %v", err)
 	}
+
 	// first byte is the block type
 	tb, _, err := unpackBlock(block[1:])
 	if err != nil {
-		panic(fmt.Sprintf("BlockCount: error unpacking block: %s", err.Error()))
+		return 0, fmt.Errorf("BlockCount: error unpacking block: %v", err)
 	}
-	return CountTimestamps(tb)
+	return CountTimestamps(tb), nil
 }
 
 // DecodeBlock takes a byte slice and decodes it into values of the appropriate type
@@ -309,6 +316,11 @@ func encodeFloatBlockUsing(buf []byte, values []Value, tsenc TimeEncoder, venc *
 // DecodeFloatBlock decodes the float block from the byte slice
 // and appends the float values to a.
 func DecodeFloatBlock(block []byte, a *[]FloatValue) ([]FloatValue, error) {
+	block, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
 	// Block type is the next block, make sure we actually have a float block
 	blockType := block[0]
 	if blockType != BlockFloat64 {
@@ -414,6 +426,11 @@ func encodeBooleanBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc
 // DecodeBooleanBlock decodes the boolean block from the byte slice
 // and appends the boolean values to a.
 func DecodeBooleanBlock(block []byte, a *[]BooleanValue) ([]BooleanValue, error) {
+	block, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
 	// Block type is the next block, make sure we actually have a float block
 	blockType := block[0]
 	if blockType != BlockBoolean {
@@ -506,6 +523,11 @@ func encodeIntegerBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc
 // DecodeIntegerBlock decodes the integer block from the byte slice
 // and appends the integer values to a.
 func DecodeIntegerBlock(block []byte, a *[]IntegerValue) ([]IntegerValue, error) {
+	block, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
 	blockType := block[0]
 	if blockType != BlockInteger {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockInteger, blockType)
@@ -599,6 +621,11 @@ func encodeUnsignedBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc
 // DecodeUnsignedBlock decodes the unsigned integer block from the byte slice
 // and appends the unsigned integer values to a.
 func DecodeUnsignedBlock(block []byte, a *[]UnsignedValue) ([]UnsignedValue, error) {
+	block, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
 	blockType := block[0]
 	if blockType != BlockUnsigned {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockUnsigned, blockType)
@@ -692,6 +719,11 @@ func encodeStringBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc S
 // DecodeStringBlock decodes the string block from the byte slice
 // and appends the string values to a.
 func DecodeStringBlock(block []byte, a *[]StringValue) ([]StringValue, error) {
+	block, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
 	blockType := block[0]
 	if blockType != BlockString {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockString, blockType)
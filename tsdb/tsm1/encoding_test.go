@@ -314,7 +314,9 @@ func TestEncoding_Count(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
-		if got, exp := tsm1.BlockCount(b), 1; got != exp {
+		if got, err := tsm1.BlockCount(b); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if exp := 1; got != exp {
 			t.Fatalf("block count mismatch: got %v, exp %v", got, exp)
 		}
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/encryption"
 	"github.com/influxdata/influxdb/pkg/lifecycle"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
@@ -30,7 +31,7 @@ import (
 	"go.uber.org/zap"
 )
 
-//go:generate env GO111MODULE=on go run github.com/benbjohnson/tmpl -data=@array_cursor.gen.go.tmpldata array_cursor.gen.go.tmpl array_cursor_iterator.gen.go.tmpl
+//go:generate env GO111MODULE=on go run github.com/benbjohnson/tmpl -data=@array_cursor.gen.go.tmpldata array_cursor.gen.go.tmpl array_cursor_iterator.gen.go.tmpl cache_entry.gen.go.tmpl
 //go:generate env GO111MODULE=on go run github.com/influxdata/influxdb/tools/tmpl -i -data=file_store.gen.go.tmpldata file_store.gen.go.tmpl=file_store.gen.go
 //go:generate env GO111MODULE=on go run github.com/influxdata/influxdb/tools/tmpl -i -d isArray=y -data=file_store.gen.go.tmpldata file_store.gen.go.tmpl=file_store_array.gen.go
 //go:generate env GO111MODULE=on go run github.com/benbjohnson/tmpl -data=@encoding.gen.go.tmpldata encoding.gen.go.tmpl
@@ -43,6 +44,10 @@ var (
 	KeyFieldSeparatorBytes = []byte(keyFieldSeparator)
 )
 
+// ErrEngineReadOnly is returned when writing to or deleting from an engine
+// opened with WithReadOnly.
+var ErrEngineReadOnly = fmt.Errorf("engine is read-only")
+
 var (
 	tsmGroup                  = metrics.MustRegisterGroup("platform-tsm1")
 	numberOfRefCursorsCounter = metrics.MustRegisterCounter("cursors_ref", metrics.WithGroup(tsmGroup))
@@ -82,6 +87,17 @@ func WithCompactionPlanner(planner CompactionPlanner) EngineOption {
 	}
 }
 
+// WithReadOnly opens the engine without modifying any of its files, so that it
+// can be opened from a snapshot or a replicated volume. Writes, deletes and
+// compactions are rejected by a read-only engine.
+func WithReadOnly() EngineOption {
+	return func(e *Engine) {
+		e.readOnly = true
+		e.enableCompactionsOnOpen = false
+		e.FileStore.SetReadOnly(true)
+	}
+}
+
 // Snapshotter allows upward signaling of the tsm1 engine to the storage engine. Hopefully
 // it can be removed one day. The weird interface is due to the weird inversion of locking
 // that has to happen.
@@ -155,12 +171,24 @@ type Engine struct {
 	// Controls whether to enabled compactions when the engine is open
 	enableCompactionsOnOpen bool
 
+	// readOnly prevents the engine from modifying its files.
+	readOnly bool
+
 	compactionTracker   *compactionTracker // Used to track state of compactions.
+	tombstoned          tombstonedFiles    // Used to track the deleted data in each file.
 	readTracker         *readTracker       // Used to track number of reads.
 	defaultMetricLabels prometheus.Labels  // N.B this must not be mutated after Open is called.
 
 	// Limiter for concurrent compactions.
 	compactionLimiter limiter.Fixed
+	// Limiter for compaction disk throughput, shared with the Compactor.
+	compactionRate *limiter.AdjustableRate
+
+	// Settings restricting when lower priority compactions can start.
+	compactionMu      sync.RWMutex
+	maxConcurrentFull int
+	quietHours        QuietHours
+
 	// A semaphore for limiting full compactions across multiple engines.
 	fullCompactionSemaphore influxdb.Semaphore
 	// Tracks how long the last full compaction took. Should be accessed atomically.
@@ -168,6 +196,9 @@ type Engine struct {
 
 	scheduler   *scheduler
 	snapshotter Snapshotter
+
+	// configErr is any error found in the configuration passed to NewEngine.
+	configErr error
 }
 
 // NewEngine returns a new instance of Engine.
@@ -181,9 +212,35 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 	c := NewCompactor()
 	c.Dir = path
 	c.FileStore = fs
-	c.RateLimit = limiter.NewRate(
+	rate := limiter.NewAdjustableRate(
 		int(config.Compaction.Throughput),
 		int(config.Compaction.ThroughputBurst))
+	c.RateLimit = rate
+
+	// Invalid block compression and value encoding settings are reported when
+	// the engine is opened.
+	policy, configErr := NewBlockCompressionPolicy(config)
+	c.BlockCompression = policy
+	encodings, err := NewValueEncodingPolicy(config)
+	if err != nil && configErr == nil {
+		configErr = err
+	}
+	c.ValueEncoding = encodings
+	c.ShardGroups = NewShardGroupDurations()
+	c.Strategies = NewCompactionStrategies()
+
+	if config.Tiering.URL != "" {
+		store, err := NewObjectStore(config.Tiering.URL)
+		if err != nil && configErr == nil {
+			configErr = err
+		}
+		fs.WithObjectTier(NewObjectTier(store, config.Tiering))
+	}
+
+	quietHours, err := ParseQuietHours(config.Compaction.QuietHours)
+	if err != nil && configErr == nil {
+		configErr = err
+	}
 
 	// determine max concurrent compactions informed by the system
 	maxCompactions := config.Compaction.MaxConcurrent
@@ -214,10 +271,9 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 
 		Cache: cache,
 
-		FileStore: fs,
-		Compactor: c,
-		CompactionPlan: NewDefaultPlanner(fs,
-			time.Duration(config.Compaction.FullWriteColdDuration)),
+		FileStore:      fs,
+		Compactor:      c,
+		CompactionPlan: NewBucketPlanner(fs, c.Strategies, config.Compaction),
 
 		CacheFlushMemorySizeThreshold:  uint64(config.Cache.SnapshotMemorySize),
 		CacheFlushWriteColdDuration:    time.Duration(config.Cache.SnapshotWriteColdDuration),
@@ -225,9 +281,13 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		enableCompactionsOnOpen:        true,
 		formatFileName:                 DefaultFormatFileName,
 		compactionLimiter:              limiter.NewFixed(maxCompactions),
+		compactionRate:                 rate,
+		maxConcurrentFull:              config.Compaction.MaxConcurrentFull,
+		quietHours:                     quietHours,
 		fullCompactionSemaphore:        influxdb.NopSemaphore,
 		scheduler:                      newScheduler(maxCompactions),
 		snapshotter:                    new(noSnapshotter),
+		configErr:                      configErr,
 	}
 
 	for _, option := range options {
@@ -243,6 +303,62 @@ func (e *Engine) SetSemaphore(s influxdb.Semaphore) {
 	e.fullCompactionSemaphore = s
 }
 
+// CompactionSettings returns the current compaction settings.
+func (e *Engine) CompactionSettings() influxdb.CompactionSettings {
+	e.compactionMu.RLock()
+	defer e.compactionMu.RUnlock()
+
+	throughput, burst := e.compactionRate.Limits()
+	return influxdb.CompactionSettings{
+		MaxConcurrentFull: e.maxConcurrentFull,
+		Throughput:        int64(throughput),
+		ThroughputBurst:   int64(burst),
+		QuietHours:        e.quietHours.Strings(),
+	}
+}
+
+// SetCompactionSettings changes the compaction settings of the running engine.
+// The new throughput applies immediately, including to compactions in progress.
+func (e *Engine) SetCompactionSettings(s influxdb.CompactionSettings) error {
+	if s.MaxConcurrentFull < 0 {
+		return fmt.Errorf("max concurrent full compactions must not be negative")
+	} else if s.Throughput < 0 || s.ThroughputBurst < 0 {
+		return fmt.Errorf("compaction throughput must not be negative")
+	}
+
+	quietHours, err := ParseQuietHours(s.QuietHours)
+	if err != nil {
+		return err
+	}
+
+	e.compactionMu.Lock()
+	defer e.compactionMu.Unlock()
+	e.maxConcurrentFull = s.MaxConcurrentFull
+	e.quietHours = quietHours
+	e.compactionRate.SetRate(int(s.Throughput), int(s.ThroughputBurst))
+	return nil
+}
+
+// canCompactLoPriority returns true if level 3, full and optimize compactions
+// can be started at t.
+func (e *Engine) canCompactLoPriority(t time.Time) bool {
+	e.compactionMu.RLock()
+	defer e.compactionMu.RUnlock()
+	return !e.quietHours.Contains(t)
+}
+
+// canCompactFull returns true if another full or optimize compaction can be
+// started.
+func (e *Engine) canCompactFull() bool {
+	e.compactionMu.RLock()
+	max := e.maxConcurrentFull
+	e.compactionMu.RUnlock()
+	if max <= 0 {
+		return true
+	}
+	return int(e.compactionTracker.ActiveFull()+e.compactionTracker.ActiveOptimise()) < max
+}
+
 // WithCompactionLimiter sets the compaction limiter, which is used to limit the
 // number of concurrent compactions.
 func (e *Engine) WithCompactionLimiter(limiter limiter.Fixed) {
@@ -272,6 +388,36 @@ func (e *Engine) WithCompactionPlanner(planner CompactionPlanner) {
 	e.CompactionPlan = planner
 }
 
+// SetShardGroupDuration sets the shard group duration of a bucket. Data for
+// the bucket written by later snapshots and compactions is partitioned into
+// shard groups of that duration. A duration of zero disables partitioning.
+func (e *Engine) SetShardGroupDuration(bucketID influxdb.ID, d time.Duration) {
+	e.Compactor.ShardGroups.Set(bucketID, d)
+}
+
+// SetCompactionStrategy sets the compaction strategy of a bucket. Files
+// holding only the bucket's data are planned by that strategy once later
+// snapshots and compactions have separated them from the data of other
+// buckets. An empty or unregistered name selects the default strategy.
+func (e *Engine) SetCompactionStrategy(bucketID influxdb.ID, name string) {
+	e.Compactor.Strategies.Set(bucketID, name)
+}
+
+// SetCacheBudget sets the maximum size of the data of a bucket in the cache.
+// Once over its budget, the bucket's data is snapshotted to a TSM file on its
+// own. A budget of zero removes it.
+func (e *Engine) SetCacheBudget(bucketID influxdb.ID, n uint64) {
+	e.Cache.SetBucketBudget(bucketID, n)
+}
+
+// SetCacheWriteColdDuration sets how long the data of a bucket stays in the
+// cache without being written to. After that, the bucket's data is
+// snapshotted to a TSM file on its own, even while other buckets are being
+// written to. A duration of zero removes it.
+func (e *Engine) SetCacheWriteColdDuration(bucketID influxdb.ID, d time.Duration) {
+	e.Cache.SetBucketWriteColdDuration(bucketID, d)
+}
+
 // SetDefaultMetricLabels sets the default labels for metrics on the engine.
 // It must be called before the Engine is opened.
 func (e *Engine) SetDefaultMetricLabels(labels prometheus.Labels) {
@@ -287,6 +433,10 @@ func (e *Engine) SetEnabled(enabled bool) {
 // SetCompactionsEnabled enables compactions on the engine.  When disabled
 // all running compactions are aborted and new compactions stop running.
 func (e *Engine) SetCompactionsEnabled(enabled bool) {
+	if enabled && e.readOnly {
+		return
+	}
+
 	if enabled {
 		e.enableSnapshotCompactions()
 		e.enableLevelCompactions(false)
@@ -327,9 +477,19 @@ func (e *Engine) enableLevelCompactions(wait bool) {
 	wg := new(sync.WaitGroup)
 	wg.Add(1)
 	e.wg = wg
+
+	// Cold files are offloaded while level compactions are enabled, so they
+	// are also stopped for backups and deletes.
+	quit, tiered := e.done, e.FileStore.tier != nil
+	if tiered {
+		wg.Add(1)
+	}
 	e.mu.Unlock()
 
 	go func() { defer wg.Done(); e.compact(wg) }()
+	if tiered {
+		go func() { defer wg.Done(); e.offloadColdFiles(quit) }()
+	}
 }
 
 // disableLevelCompactions will stop level compactions before returning.
@@ -463,6 +623,168 @@ func (e *Engine) ScheduleFullCompaction(ctx context.Context) error {
 	return nil
 }
 
+// CompactPrefix writes a snapshot of any data in the cache to TSM files and then
+// fully compacts the TSM files containing keys with the given prefix. Files
+// newer than the oldest of those are compacted with them so that newer values
+// still replace older ones. A nil prefix compacts all files.
+//
+// CompactPrefix blocks until the compaction has finished, waiting for running
+// compactions of the files and for a free compaction slot if necessary.
+func (e *Engine) CompactPrefix(ctx context.Context, prefix []byte) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := e.WriteSnapshot(ctx, CacheStatusFullCompaction); err != nil {
+		return err
+	}
+
+	_, err := e.compactGroupWhenReady(ctx, func() CompactionGroup {
+		return e.prefixCompactionGroup(prefix)
+	})
+	return err
+}
+
+// compactGroupWhenReady fully compacts the group returned by findGroup, waiting
+// for running compactions of its files and for a free compaction slot if
+// necessary. The set of files can change while background compactions finish,
+// so the group is found again each time it could not be acquired. It returns
+// false if findGroup returned no files.
+func (e *Engine) compactGroupWhenReady(ctx context.Context, findGroup func() CompactionGroup) (bool, error) {
+	t := time.NewTicker(time.Second)
+	defer t.Stop()
+
+	var group CompactionGroup
+	for {
+		if group = findGroup(); len(group) == 0 {
+			return false, nil
+		} else if e.CompactionPlan.Acquire([]CompactionGroup{group}) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-t.C:
+		}
+	}
+	defer e.CompactionPlan.Release([]CompactionGroup{group})
+
+	for !e.compactionLimiter.TryTake() {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-t.C:
+		}
+	}
+	defer e.compactionLimiter.Release()
+
+	e.compactionTracker.IncFullActive()
+	defer e.compactionTracker.DecFullActive()
+
+	return true, e.fullCompactionStrategy(group, false).compactGroup(ctx)
+}
+
+// prefixCompactionGroup returns the TSM files to compact for CompactPrefix,
+// ordered by generation. It returns nil if the files are already compacted.
+func (e *Engine) prefixCompactionGroup(prefix []byte) CompactionGroup {
+	stats := e.FileStore.Stats()
+	for i, f := range stats {
+		if prefix != nil && (bytes.Compare(f.MaxKey, prefix) < 0 ||
+			bytes.Compare(f.MinKey, prefix) > 0 && !bytes.HasPrefix(f.MinKey, prefix)) {
+			continue
+		}
+
+		stats = stats[i:]
+		if len(stats) == 1 && !stats[0].HasTombstone {
+			return nil
+		}
+
+		group := make(CompactionGroup, 0, len(stats))
+		for _, f := range stats {
+			group = append(group, f.Path)
+		}
+		return group
+	}
+	return nil
+}
+
+// EncryptFiles rewrites the TSM files whose blocks are not encrypted with the
+// current key of the default cipher, so that existing data is encrypted once
+// encryption is enabled and re-encrypted once a key is rotated. Files are
+// rewritten by compacting them one generation at a time.
+//
+// EncryptFiles blocks until every file has been rewritten. It does nothing if
+// encryption is not enabled.
+func (e *Engine) EncryptFiles(ctx context.Context) error {
+	for {
+		cipher := encryption.Default()
+		if cipher == nil {
+			return nil
+		}
+		current, err := cipher.CurrentKeyID()
+		if err != nil {
+			return err
+		}
+
+		if ok, err := e.compactGroupWhenReady(ctx, func() CompactionGroup {
+			return e.encryptionCompactionGroup(current)
+		}); err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// encryptionCompactionGroup returns the files of the oldest generation that
+// contains a file not encrypted with the key identified by keyID. It returns
+// nil if every file is encrypted with that key.
+func (e *Engine) encryptionCompactionGroup(keyID uint32) CompactionGroup {
+	var (
+		group      CompactionGroup
+		generation = -1
+		stale      bool
+	)
+	for _, f := range e.FileStore.Stats() {
+		gen, _, err := e.FileStore.ParseFileName(f.Path)
+		if err != nil {
+			continue
+		}
+		if gen != generation {
+			if stale {
+				return group
+			}
+			group, generation = group[:0], gen
+		}
+		group = append(group, f.Path)
+		stale = stale || !e.fileEncryptedWith(f.Path, keyID)
+	}
+	if stale {
+		return group
+	}
+	return nil
+}
+
+// fileEncryptedWith returns true if the blocks of the TSM file at path are
+// encrypted with the key identified by keyID. Every block written by a
+// compaction is encrypted with the same key, so only the first is checked.
+func (e *Engine) fileEncryptedWith(path string, keyID uint32) bool {
+	r := e.FileStore.TSMReader(path)
+	if r == nil {
+		return true
+	}
+	defer r.Unref()
+
+	iter := r.BlockIterator()
+	if !iter.Next() {
+		return true
+	}
+	_, _, _, _, _, block, err := iter.Read()
+	if err != nil {
+		return true
+	}
+	id, ok := BlockKeyID(block)
+	return ok && id == keyID
+}
+
 // Path returns the path the engine was opened with.
 func (e *Engine) Path() string { return e.path }
 
@@ -523,6 +845,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if e.configErr != nil {
+		return e.configErr
+	}
+
 	defer func() {
 		if err != nil {
 			e.Close()
@@ -545,8 +871,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
-	if err := e.cleanup(); err != nil {
-		return err
+	if !e.readOnly {
+		if err := e.cleanup(); err != nil {
+			return err
+		}
 	}
 
 	if err := e.FileStore.Open(ctx); err != nil {
@@ -623,6 +951,10 @@ func (e *Engine) WritePoints(points []models.Point) error {
 
 // WriteValues saves the set of values in the engine.
 func (e *Engine) WriteValues(values map[string][]Value) error {
+	if e.readOnly {
+		return ErrEngineReadOnly
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -794,8 +1126,20 @@ func (t *compactionTracker) SetOptimiseQueue(length uint64) { t.SetQueue(4, leng
 func (t *compactionTracker) SetFullQueue(length uint64) { t.SetQueue(5, length) }
 
 func (e *Engine) WriteSnapshot(ctx context.Context, status CacheStatus) error {
+	return e.snapshotCache(ctx, status, nil)
+}
+
+// WriteBucketsSnapshot writes the data of the buckets with prefixes in the
+// cache to a new TSM file, leaving the data of other buckets in the cache.
+func (e *Engine) WriteBucketsSnapshot(ctx context.Context, prefixes [][]byte) error {
+	return e.snapshotCache(ctx, CacheStatusBucketBudgetExceeded, prefixes)
+}
+
+// snapshotCache snapshots the data of the buckets with prefixes in the cache,
+// or the whole cache if prefixes is nil, recording the attempt.
+func (e *Engine) snapshotCache(ctx context.Context, status CacheStatus, prefixes [][]byte) error {
 	start := time.Now()
-	err := e.writeSnapshot(ctx)
+	err := e.writeSnapshot(ctx, prefixes)
 	if err != nil && err != errCompactionsDisabled {
 		e.logger.Info("Error writing snapshot", zap.Error(err))
 	}
@@ -810,7 +1154,8 @@ func (e *Engine) WriteSnapshot(ctx context.Context, status CacheStatus) error {
 }
 
 // WriteSnapshot will snapshot the cache and write a new TSM file with its contents, releasing the snapshot when done.
-func (e *Engine) writeSnapshot(ctx context.Context) error {
+// If prefixes is not nil, only the data of the buckets with those prefixes is snapshotted.
+func (e *Engine) writeSnapshot(ctx context.Context, prefixes [][]byte) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -832,7 +1177,17 @@ func (e *Engine) writeSnapshot(ctx context.Context) error {
 		snapshot *Cache
 		segments []string
 	)
-	if err := e.snapshotter.AcquireSegments(ctx, func(segs []string) (err error) {
+	if prefixes != nil {
+		// The WAL segments also hold the data of the buckets left in the
+		// cache, so they are kept until the whole cache is snapshotted.
+		var err error
+		e.mu.Lock()
+		snapshot, err = e.Cache.SnapshotBuckets(prefixes)
+		e.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	} else if err := e.snapshotter.AcquireSegments(ctx, func(segs []string) (err error) {
 		segments = segs
 
 		e.mu.Lock()
@@ -905,6 +1260,21 @@ func (e *Engine) compactCache() {
 			e.Cache.UpdateAge()
 			status := e.ShouldCompactCache(time.Now())
 			if status == CacheStatusOkay {
+				status, prefixes := CacheStatusBucketBudgetExceeded, e.Cache.BucketsOverBudget()
+				if len(prefixes) == 0 {
+					status, prefixes = CacheStatusBucketColdNoWrites, e.Cache.ColdBuckets(time.Now())
+				}
+				if len(prefixes) > 0 {
+					span, ctx := tracing.StartSpanFromContextWithOperationName(context.Background(), "compact cache buckets")
+					span.LogKV("path", e.path, "buckets", len(prefixes))
+
+					err := e.snapshotCache(ctx, status, prefixes)
+					if err != nil && err != errCompactionsDisabled && err != ErrSnapshotInProgress {
+						e.logger.Info("Error writing bucket snapshot", zap.Error(err))
+					}
+
+					span.Finish()
+				}
 				continue
 			}
 
@@ -934,12 +1304,18 @@ const (
 	CacheStatusRetention                         // The cache was snapshotted before running retention.
 	CacheStatusFullCompaction                    // The cache was snapshotted as part of a full compaction.
 	CacheStatusBackup                            // The cache was snapshotted before running backup.
+	CacheStatusDrain                             // The cache was snapshotted while draining the server.
+	CacheStatusCopy                              // The cache was snapshotted before copying data to another prefix.
+	CacheStatusBucketBudgetExceeded              // The data of buckets over their budget was snapshotted.
+	CacheStatusIndexRebuild                      // The cache was snapshotted before rebuilding the index from TSM data.
+	CacheStatusBucketColdNoWrites                // The data of buckets not written to for their cold duration was snapshotted.
 )
 
 // ShouldCompactCache returns a status indicating if the Cache should be
 // snapshotted. There are three situations when the cache should be snapshotted:
 //
-// - the Cache size is over its flush size threshold;
+// - the Cache size is over its flush size threshold, not counting the data of
+//   buckets with a budget, which are snapshotted on their own;
 // - the Cache has not been snapshotted for longer than its flush time threshold; or
 // - the Cache has not been written since the write cold threshold.
 //
@@ -950,7 +1326,7 @@ func (e *Engine) ShouldCompactCache(t time.Time) CacheStatus {
 	}
 
 	// Cache is now big enough to snapshot.
-	if sz > e.CacheFlushMemorySizeThreshold {
+	if e.Cache.unbudgetedSize() > e.CacheFlushMemorySizeThreshold {
 		return CacheStatusSizeExceeded
 	}
 
@@ -1012,6 +1388,17 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 			e.compactionTracker.SetQueue(2, uint64(len(level2Groups)))
 			e.compactionTracker.SetQueue(3, uint64(len(level3Groups)))
 
+			// Hold back the plans that can't be started now, so the scheduler
+			// picks from the levels that can.
+			if !e.canCompactLoPriority(time.Now()) {
+				e.CompactionPlan.Release(level3Groups)
+				e.CompactionPlan.Release(level4Groups)
+				level3Groups, level4Groups = nil, nil
+			} else if !e.canCompactFull() {
+				e.CompactionPlan.Release(level4Groups)
+				level4Groups = nil
+			}
+
 			// Set the queue depths on the scheduler
 			e.scheduler.setDepth(1, len(level1Groups))
 			e.scheduler.setDepth(2, len(level2Groups))
@@ -1212,7 +1599,8 @@ func (s *compactionStrategy) Apply(ctx context.Context) {
 }
 
 // compactGroup executes the compaction strategy against a single CompactionGroup.
-func (s *compactionStrategy) compactGroup(ctx context.Context) {
+// Errors are logged as well as returned.
+func (s *compactionStrategy) compactGroup(ctx context.Context) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -1248,13 +1636,13 @@ func (s *compactionStrategy) compactGroup(ctx context.Context) {
 			if _, ok := err.(errCompactionInProgress); ok {
 				time.Sleep(time.Second)
 			}
-			return
+			return err
 		}
 
 		log.Info("Error compacting TSM files", zap.Error(err))
 		s.tracker.Attempted(s.level, false, "", 0)
 		time.Sleep(time.Second)
-		return
+		return err
 	}
 
 	if err := s.fileStore.ReplaceWithCallback(group, files, nil); err != nil {
@@ -1270,7 +1658,7 @@ func (s *compactionStrategy) compactGroup(ctx context.Context) {
 			}
 		}
 
-		return
+		return err
 	}
 
 	for i, f := range files {
@@ -1279,6 +1667,7 @@ func (s *compactionStrategy) compactGroup(ctx context.Context) {
 	}
 	log.Info("Finished compacting files", zap.Int("tsm1_files_n", len(files)))
 	s.tracker.Attempted(s.level, true, "", time.Since(now))
+	return nil
 }
 
 // levelCompactionStrategy returns a compactionStrategy for the given level.
@@ -1355,7 +1744,9 @@ func (e *Engine) cleanupTempTSMFiles() error {
 
 // KeyCursor returns a KeyCursor for the given key starting at time t.
 func (e *Engine) KeyCursor(ctx context.Context, key []byte, t int64, ascending bool) *KeyCursor {
-	return e.FileStore.KeyCursor(ctx, key, t, ascending)
+	c := e.FileStore.KeyCursor(ctx, key, t, ascending)
+	c.tracker = e.readTracker
+	return c
 }
 
 // IteratorCost produces the cost of an iterator.
@@ -1479,16 +1870,19 @@ func SeriesAndFieldFromCompositeKey(key []byte) ([]byte, []byte) {
 
 // readTracker tracks reads from the engine.
 type readTracker struct {
-	metrics *readMetrics
-	labels  prometheus.Labels
-	cursors uint64
-	seeks   uint64
+	metrics          *readMetrics
+	labels           prometheus.Labels
+	cursors          uint64
+	seeks            uint64
+	tombstonedValues uint64
+	tombstonedBlocks uint64
 }
 
 func newReadTracker(metrics *readMetrics, defaultLabels prometheus.Labels) *readTracker {
 	t := &readTracker{metrics: metrics, labels: defaultLabels}
 	t.AddCursors(0)
 	t.AddSeeks(0)
+	t.AddTombstoned(0, 0)
 	return t
 }
 
@@ -1513,3 +1907,19 @@ func (t *readTracker) AddSeeks(n uint64) {
 	atomic.AddUint64(&t.seeks, n)
 	t.metrics.Seeks.With(t.labels).Add(float64(n))
 }
+
+// AddTombstoned increases the number of values decoded and then dropped
+// because they had been deleted, and the number of blocks holding only such
+// values.
+func (t *readTracker) AddTombstoned(values, blocks uint64) {
+	atomic.AddUint64(&t.tombstonedValues, values)
+	atomic.AddUint64(&t.tombstonedBlocks, blocks)
+	t.metrics.TombstonedValues.With(t.labels).Add(float64(values))
+	t.metrics.TombstonedBlocks.With(t.labels).Add(float64(blocks))
+}
+
+// Tombstoned returns the number of values and blocks read and then dropped
+// because they had been deleted.
+func (t *readTracker) Tombstoned() (values, blocks uint64) {
+	return atomic.LoadUint64(&t.tombstonedValues), atomic.LoadUint64(&t.tombstonedBlocks)
+}
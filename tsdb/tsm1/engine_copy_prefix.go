@@ -0,0 +1,188 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// CopyPrefix copies the TSM data whose keys begin with src, and match pred if
+// it is not nil, to keys beginning with dst instead, and adds the series of
+// the copied keys to the index. The cache is written to disk first, so that
+// all data written before CopyPrefix is called is copied. Copied data replaces
+// any data of the destination keys at the same timestamps.
+//
+// It returns the series that were copied.
+func (e *Engine) CopyPrefix(ctx context.Context, src, dst []byte, pred Predicate) (*tsdb.SeriesCollection, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	span.LogKV("src_prefix", fmt.Sprintf("%x", src), "dst_prefix", fmt.Sprintf("%x", dst),
+		"has_pred", pred != nil,
+	)
+	defer span.Finish()
+
+	if e.readOnly {
+		return nil, ErrEngineReadOnly
+	}
+
+	// Compactions would replace the files being read with files that are not,
+	// so they are stopped until the copied data is in the file store.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	if err := e.WriteSnapshot(ctx, CacheStatusCopy); err != nil {
+		return nil, err
+	}
+
+	var trs []*TSMReader
+	defer func() {
+		for _, tr := range trs {
+			tr.Unref()
+		}
+	}()
+	for _, f := range e.FileStore.Stats() {
+		if bytes.Compare(f.MaxKey, src) < 0 ||
+			bytes.Compare(f.MinKey, src) > 0 && !bytes.HasPrefix(f.MinKey, src) {
+			continue
+		}
+		if tr := e.FileStore.TSMReader(f.Path); tr != nil {
+			trs = append(trs, tr)
+		}
+	}
+
+	collection := &tsdb.SeriesCollection{}
+	if len(trs) == 0 {
+		return collection, nil
+	}
+
+	iter, err := NewTSMBatchKeyIterator(MaxPointsPerBlock, false, nil, trs...)
+	if err != nil {
+		return nil, err
+	}
+	copier := &prefixCopyingKeyIterator{KeyIterator: iter, ctx: ctx, src: src, dst: dst, pred: pred}
+	files, err := e.Compactor.WriteKeys(copier)
+	if err != nil {
+		return nil, err
+	} else if len(files) == 0 {
+		return collection, nil
+	}
+
+	// The series are added to the index before the files are added to the file
+	// store, so that no data is visible for series that do not exist.
+	for i, key := range copier.keys {
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+		name, tags := models.ParseKeyBytes(seriesKey)
+		collection.Keys = append(collection.Keys, seriesKey)
+		collection.Names = append(collection.Names, name)
+		collection.Tags = append(collection.Tags, tags)
+		collection.Types = append(collection.Types, blockFieldType(copier.types[i]))
+	}
+	if err = e.index.CreateSeriesListIfNotExists(collection); err == nil {
+		err = collection.PartialWriteError()
+	}
+	if err == nil {
+		err = e.FileStore.Replace(nil, files)
+	}
+
+	if err != nil {
+		for _, f := range files {
+			if rerr := os.RemoveAll(f); rerr != nil {
+				e.logger.Info("Unable to remove copied file", zap.String("path", f), zap.Error(rerr))
+			}
+			_ = os.RemoveAll(StatsFilename(f))
+		}
+		return nil, err
+	}
+	return collection, nil
+}
+
+// blockFieldType returns the field type of the values of a block type.
+func blockFieldType(typ byte) models.FieldType {
+	switch typ {
+	case BlockFloat64:
+		return models.Float
+	case BlockInteger:
+		return models.Integer
+	case BlockUnsigned:
+		return models.Unsigned
+	case BlockBoolean:
+		return models.Boolean
+	case BlockString:
+		return models.String
+	default:
+		return models.Empty
+	}
+}
+
+// prefixCopyingKeyIterator returns the blocks of the keys read from a
+// KeyIterator that begin with src, and match pred if it is not nil, under keys
+// beginning with dst instead. It keeps the keys returned, and the type of
+// their blocks.
+type prefixCopyingKeyIterator struct {
+	KeyIterator
+	ctx      context.Context
+	src, dst []byte
+	pred     Predicate
+
+	key              []byte
+	minTime, maxTime int64
+	block            []byte
+	err              error
+
+	keys  [][]byte
+	types []byte
+}
+
+func (k *prefixCopyingKeyIterator) Next() bool {
+	for k.KeyIterator.Next() {
+		key, minTime, maxTime, block, err := k.KeyIterator.Read()
+		if err != nil {
+			k.err = err
+			return true
+		}
+		if !bytes.HasPrefix(key, k.src) || k.pred != nil && !k.pred.Matches(key) {
+			continue
+		}
+		if err := k.ctx.Err(); err != nil {
+			k.err = err
+			return true
+		}
+
+		suffix := key[len(k.src):]
+		if n := len(k.keys); n > 0 && bytes.Equal(k.keys[n-1][len(k.dst):], suffix) {
+			k.key = k.keys[n-1]
+		} else {
+			typ, err := BlockType(block)
+			if err != nil {
+				k.err = err
+				return true
+			}
+			k.key = make([]byte, 0, len(k.dst)+len(suffix))
+			k.key = append(append(k.key, k.dst...), suffix...)
+			k.keys = append(k.keys, k.key)
+			k.types = append(k.types, typ)
+		}
+		k.minTime, k.maxTime, k.block = minTime, maxTime, block
+		return true
+	}
+	return false
+}
+
+func (k *prefixCopyingKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	if k.err != nil {
+		return nil, 0, 0, nil, k.err
+	}
+	return k.key, k.minTime, k.maxTime, k.block, nil
+}
+
+func (k *prefixCopyingKeyIterator) Err() error {
+	if k.err != nil {
+		return k.err
+	}
+	return k.KeyIterator.Err()
+}
@@ -25,6 +25,11 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 		"has_pred", pred != nil,
 	)
 	defer span.Finish()
+
+	if e.readOnly {
+		return ErrEngineReadOnly
+	}
+
 	// TODO(jeff): we need to block writes to this prefix while deletes are in progress
 	// otherwise we can end up in a situation where we have staged data in the cache or
 	// WAL that was deleted from the index, or worse. This needs to happen at a higher
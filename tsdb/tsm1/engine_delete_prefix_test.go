@@ -119,3 +119,127 @@ func TestEngine_DeletePrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestEngine_CopyPrefix(t *testing.T) {
+	p1 := MustParsePointString("cpu,host=A value=1.1 1", "mm0")
+	p2 := MustParsePointString("cpu,host=B value=2i 2", "mm0")
+	p3 := MustParsePointString("mem,host=C value=1.3 1", "mm1")
+
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// The points are left in the cache, so the copy has to snapshot them.
+	if err := e.writePoints(p1, p2, p3); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	collection, err := e.CopyPrefix(context.Background(), []byte("mm0"), []byte("mm2"), nil)
+	if err != nil {
+		t.Fatalf("failed to copy series: %v", err)
+	}
+	if got, exp := collection.Length(), 2; got != exp {
+		t.Fatalf("copied series mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := collection.Types, []models.FieldType{models.Float, models.Integer}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("copied types mismatch: got %v, exp %v", got, exp)
+	}
+
+	exp := map[string]byte{
+		"mm0,\x00=cpu,host=A,\xff=value#!~#value": 0,
+		"mm0,\x00=cpu,host=B,\xff=value#!~#value": 1,
+		"mm1,\x00=mem,host=C,\xff=value#!~#value": 0,
+		"mm2,\x00=cpu,host=A,\xff=value#!~#value": 0,
+		"mm2,\x00=cpu,host=B,\xff=value#!~#value": 1,
+	}
+	if keys := e.FileStore.Keys(); !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected series in file store: %v != %v", keys, exp)
+	}
+
+	values, err := e.FileStore.Read([]byte("mm2,\x00=cpu,host=B,\xff=value#!~#value"), 2)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(values) != 1 || values[0].Value() != int64(2) {
+		t.Fatalf("unexpected copied values: %v", values)
+	}
+
+	// The copied series are added to the index.
+	iter, err := e.index.MeasurementSeriesIDIterator([]byte("mm2"))
+	if err != nil {
+		t.Fatalf("iterator error: %v", err)
+	}
+	defer iter.Close()
+
+	var n int
+	for {
+		elem, err := iter.Next()
+		if err != nil {
+			t.Fatal(err)
+		} else if elem.SeriesID.IsZero() {
+			break
+		}
+		n++
+	}
+	if got, exp := n, 2; got != exp {
+		t.Fatalf("series index mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+func TestEngine_PrefixSeries(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.1 1", "mm0"),
+		MustParsePointString("cpu,host=B value=2i 2", "mm0"),
+		MustParsePointString("disk,host=C value=true 1", "mm0"),
+		MustParsePointString("mem,host=C value=1.3 1", "mm1"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background(), tsm1.CacheStatusIndexRebuild); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	var types []models.FieldType
+	var after []byte
+	for {
+		collection, last, err := e.PrefixSeries(context.Background(), []byte("mm0"), after, 2)
+		if err != nil {
+			t.Fatal(err)
+		} else if collection.Length() == 0 {
+			break
+		} else if collection.Length() > 2 {
+			t.Fatalf("too many series: %d", collection.Length())
+		}
+		for i := range collection.Keys {
+			keys = append(keys, string(collection.Keys[i]))
+			types = append(types, collection.Types[i])
+		}
+		after = last
+	}
+
+	expKeys := []string{
+		"mm0,\x00=cpu,host=A,\xff=value",
+		"mm0,\x00=cpu,host=B,\xff=value",
+		"mm0,\x00=disk,host=C,\xff=value",
+	}
+	if !reflect.DeepEqual(keys, expKeys) {
+		t.Fatalf("unexpected series: %q != %q", keys, expKeys)
+	}
+	if exp := []models.FieldType{models.Float, models.Integer, models.Boolean}; !reflect.DeepEqual(types, exp) {
+		t.Fatalf("unexpected types: %v != %v", types, exp)
+	}
+}
@@ -0,0 +1,55 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// errStopWalk stops walking the keys of the file store early.
+var errStopWalk = errors.New("stop walk")
+
+// PrefixSeries returns at most n of the series whose keys in the TSM files
+// begin with prefix and sort after the key after, along with their field
+// types. Each series includes its field. The returned collection is empty once
+// all series have been returned.
+//
+// Data in the cache is not included, so it should be written to disk first.
+// The key to pass as after in the next call is returned.
+func (e *Engine) PrefixSeries(ctx context.Context, prefix, after []byte, n int) (*tsdb.SeriesCollection, []byte, error) {
+	seek := prefix
+	if after != nil {
+		seek = append(append(make([]byte, 0, len(after)+1), after...), 0)
+	}
+
+	collection := &tsdb.SeriesCollection{}
+	var last []byte
+	err := e.FileStore.WalkKeys(seek, func(key []byte, typ byte) error {
+		if !bytes.HasPrefix(key, prefix) {
+			return errStopWalk
+		} else if bytes.Equal(key, last) {
+			return nil // The key is in several files.
+		} else if collection.Length() == n {
+			return errStopWalk
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		last = append(last[:0], key...)
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+		seriesKey = append([]byte(nil), seriesKey...)
+		name, tags := models.ParseKeyBytes(seriesKey)
+		collection.Keys = append(collection.Keys, seriesKey)
+		collection.Names = append(collection.Names, name)
+		collection.Tags = append(collection.Tags, tags)
+		collection.Types = append(collection.Types, blockFieldType(typ))
+		return nil
+	})
+	if err != nil && err != errStopWalk {
+		return nil, nil, err
+	}
+	return collection, last, nil
+}
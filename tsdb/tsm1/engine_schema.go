@@ -114,10 +114,10 @@ func (e *Engine) tagValuesNoPredicate(ctx context.Context, orgBucket, tagKeyByte
 			return nil
 		}
 
-		stats.ScannedValues += entry.values.Len()
-		stats.ScannedBytes += entry.values.Len() * 8 // sizeof timestamp
+		stats.ScannedValues += entry.count()
+		stats.ScannedBytes += entry.count() * 8 // sizeof timestamp
 
-		if entry.values.Contains(start, end) {
+		if entry.contains(start, end) {
 			tsmValues[string(curVal)] = struct{}{}
 		}
 		return nil
@@ -368,10 +368,10 @@ func (e *Engine) tagKeysNoPredicate(ctx context.Context, orgBucket []byte, start
 			return nil
 		}
 
-		stats.ScannedValues += entry.values.Len()
-		stats.ScannedBytes += entry.values.Len() * 8 // sizeof timestamp
+		stats.ScannedValues += entry.count()
+		stats.ScannedBytes += entry.count() * 8 // sizeof timestamp
 
-		if entry.values.Contains(start, end) {
+		if entry.contains(start, end) {
 			keyset.UnionKeys(tags)
 		}
 		return nil
@@ -7,6 +7,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"sync"
@@ -359,6 +360,225 @@ func BenchmarkEngine_DeletePrefixRange_Cache(b *testing.B) {
 	e.Close()
 }
 
+func TestEngine_SetCompactionSettings(t *testing.T) {
+	config := tsm1.NewConfig()
+	config.Compaction.MaxConcurrentFull = 1
+	config.Compaction.QuietHours = []string{"09:00-17:00"}
+	e, err := NewEngine(config, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	exp := influxdb.CompactionSettings{
+		MaxConcurrentFull: 1,
+		Throughput:        tsm1.DefaultCompactThroughput,
+		ThroughputBurst:   tsm1.DefaultCompactThroughputBurst,
+		QuietHours:        []string{"09:00-17:00"},
+	}
+	if got := e.CompactionSettings(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got settings %+v, expected %+v", got, exp)
+	}
+
+	exp = influxdb.CompactionSettings{
+		MaxConcurrentFull: 2,
+		Throughput:        1 << 20,
+		ThroughputBurst:   1 << 20,
+		QuietHours:        []string{"22:00-06:00"},
+	}
+	if err := e.SetCompactionSettings(influxdb.CompactionSettings{
+		MaxConcurrentFull: 2,
+		Throughput:        1 << 20,
+		QuietHours:        []string{"22:00-06:00"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := e.CompactionSettings(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got settings %+v, expected %+v", got, exp)
+	}
+
+	// Invalid settings are rejected and leave the settings unchanged.
+	if err := e.SetCompactionSettings(influxdb.CompactionSettings{QuietHours: []string{"nightly"}}); err == nil {
+		t.Fatal("expected error for invalid quiet hours")
+	}
+	if err := e.SetCompactionSettings(influxdb.CompactionSettings{Throughput: -1}); err == nil {
+		t.Fatal("expected error for negative throughput")
+	}
+	if got := e.CompactionSettings(); !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got settings %+v, expected %+v", got, exp)
+	}
+}
+
+func TestEngine_CompactPrefix(t *testing.T) {
+	e := MustOpenEngine(t)
+	defer e.Close()
+
+	org, bucket1, bucket2 := influxdb.ID(0x10), influxdb.ID(0x20), influxdb.ID(0x30)
+	e.MustWritePointsString(org, bucket1, "cpu,host=A value=1.1 1000000000")
+	e.MustWriteSnapshot()
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.1 1000000000")
+	e.MustWriteSnapshot()
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.2 2000000000")
+	e.MustWriteSnapshot()
+	if got, exp := e.FileStore.Count(), 3; got != exp {
+		t.Fatalf("got %d TSM files, expected %d", got, exp)
+	}
+
+	encoded := tsdb.EncodeName(org, bucket2)
+	prefix := models.EscapeMeasurement(encoded[:])
+
+	// The cache is snapshot before compacting, and only the files of
+	// bucket2 are compacted.
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.3 3000000000")
+	if err := e.CompactPrefix(context.Background(), prefix); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := e.FileStore.Count(), 2; got != exp {
+		t.Fatalf("got %d TSM files, expected %d", got, exp)
+	}
+	if got := e.Cache.Size(); got != 0 {
+		t.Fatalf("got cache size %d, expected 0", got)
+	}
+
+	// A nil prefix compacts the remaining files together.
+	if err := e.CompactPrefix(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := e.FileStore.Count(), 1; got != exp {
+		t.Fatalf("got %d TSM files, expected %d", got, exp)
+	}
+
+	// Compacting a single file without tombstones does nothing.
+	files := e.FileStore.Files()
+	if err := e.CompactPrefix(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := e.FileStore.Files()[0].Path(), files[0].Path(); got != exp {
+		t.Fatalf("got TSM file %s, expected %s", got, exp)
+	}
+}
+
+func TestEngine_BucketStorageStats(t *testing.T) {
+	e := MustOpenEngine(t)
+	defer e.Close()
+
+	org, bucket1, bucket2 := influxdb.ID(0x10), influxdb.ID(0x21), influxdb.ID(0x30)
+	e.MustWritePointsString(org, bucket1, "cpu,host=A value=1.1 1000000000")
+	e.MustWriteSnapshot()
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.1 1000000000")
+	e.MustWriteSnapshot()
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.2 2000000000")
+	e.MustWriteSnapshot()
+
+	stats := e.BucketStorageStats()
+	if got := stats[bucket2]; got == nil || got.OrgID != org || got.Files != 2 || got.UncompactedFiles != 2 {
+		t.Fatalf("unexpected stats for bucket2: %+v", got)
+	}
+
+	// Files holding several buckets are counted for each of them.
+	if err := e.CompactPrefix(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.3 3000000000")
+
+	stats = e.BucketStorageStats()
+	if got := stats[bucket1]; got == nil || got.Files != 1 || got.CacheBytes != 0 {
+		t.Fatalf("unexpected stats for bucket1: %+v", got)
+	}
+	if got := stats[bucket2]; got == nil || got.Files != 1 || got.CacheBytes == 0 {
+		t.Fatalf("unexpected stats for bucket2: %+v", got)
+	}
+}
+
+func TestEngine_CompactTombstones(t *testing.T) {
+	e := MustOpenEngine(t)
+	defer e.Close()
+
+	org, bucket1, bucket2 := influxdb.ID(0x10), influxdb.ID(0x21), influxdb.ID(0x30)
+	e.MustWritePointsString(org, bucket1, "cpu,host=A value=1.1 1000000000\ncpu,host=B value=1.2 1000000000")
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.1 1000000000\ncpu,host=A value=2.2 2000000000")
+	e.MustWriteSnapshot()
+
+	// Deleting only some of the values of a block leaves it in use.
+	e.MustDeleteBucketRange(org, bucket2, 0, 1000000000)
+	if got := e.BucketStorageStats()[bucket2]; got == nil || got.TombstonedBytes != 0 {
+		t.Fatalf("unexpected stats for bucket2: %+v", got)
+	}
+
+	e.MustDeleteBucketRange(org, bucket1, 0, 1000000000)
+	if got := e.BucketStorageStats()[bucket1]; got == nil || got.TombstonedBytes == 0 {
+		t.Fatalf("unexpected stats for bucket1: %+v", got)
+	}
+
+	n, err := e.CompactTombstones(context.Background(), 0.01)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("got %d generations compacted, expected 1", n)
+	}
+
+	stats := e.BucketStorageStats()
+	if got := stats[bucket1]; got != nil {
+		t.Fatalf("unexpected stats for bucket1: %+v", got)
+	}
+	if got := stats[bucket2]; got == nil || got.Files != 1 || got.TombstonedBytes != 0 {
+		t.Fatalf("unexpected stats for bucket2: %+v", got)
+	}
+
+	// Nothing is left to compact.
+	if n, err := e.CompactTombstones(context.Background(), 0.01); err != nil || n != 0 {
+		t.Fatalf("got %d generations compacted, err %v", n, err)
+	}
+}
+
+func TestEngine_TombstoneStats(t *testing.T) {
+	e := MustOpenEngine(t)
+	defer e.Close()
+
+	org, bucket1, bucket2 := influxdb.ID(0x10), influxdb.ID(0x21), influxdb.ID(0x30)
+	e.MustWritePointsString(org, bucket1, "cpu,host=A value=1.1 1000000000\ncpu,host=B value=1.2 1000000000")
+	e.MustWritePointsString(org, bucket2, "cpu,host=A value=2.1 1000000000\ncpu,host=A value=2.2 2000000000")
+	e.MustWriteSnapshot()
+
+	if stats := e.TombstoneStats(); len(stats.Files) != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	e.MustDeleteBucketRange(org, bucket1, 0, 1000000000)
+	e.MustDeleteBucketRange(org, bucket2, 0, 1000000000)
+
+	stats := e.TombstoneStats()
+	if got, exp := len(stats.Files), 1; got != exp {
+		t.Fatalf("got %d files, expected %d", got, exp)
+	}
+	if f := stats.Files[0]; f.TombstonedKeys != 2 || f.TombstonedBlocks != 2 || f.TombstonedBytes == 0 || f.TombstoneSize == 0 {
+		t.Fatalf("unexpected file stats: %+v", f)
+	}
+
+	// Reading the partially deleted block decodes the deleted value, and then
+	// drops it.
+	name := tsdb.EncodeName(org, bucket2)
+	tags := models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", "host": "A", models.FieldKeyTagKey: "value"})
+	key := tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), "value")
+
+	c := e.KeyCursor(context.Background(), key, 0, true)
+	values, err := c.ReadFloatBlock(&[]tsm1.FloatValue{})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(values) != 1 {
+		t.Fatalf("got %d values, expected 1", len(values))
+	}
+	c.Close()
+
+	stats = e.TombstoneStats()
+	if stats.TombstonedValuesRead != 1 || stats.TombstonedBlocksRead != 0 {
+		t.Fatalf("unexpected read stats: %+v", stats)
+	}
+}
+
 // Engine is a test wrapper for tsm1.Engine.
 type Engine struct {
 	*tsm1.Engine
@@ -615,5 +835,6 @@ func (m *mockPlanner) PlanLevel(level int) []tsm1.CompactionGroup      { return
 func (m *mockPlanner) PlanOptimize() []tsm1.CompactionGroup            { return nil }
 func (m *mockPlanner) Release(groups []tsm1.CompactionGroup)           {}
 func (m *mockPlanner) FullyCompacted() bool                            { return false }
+func (m *mockPlanner) Acquire(groups []tsm1.CompactionGroup) bool      { return true }
 func (m *mockPlanner) ForceFull()                                      {}
 func (m *mockPlanner) SetFileStore(fs *tsm1.FileStore)                 {}
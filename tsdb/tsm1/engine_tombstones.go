@@ -0,0 +1,215 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"sync"
+)
+
+// tombstonedFile is the size of the blocks of a TSM file whose values have
+// all been deleted, as of the last modification of the file or its tombstones.
+type tombstonedFile struct {
+	lastModified  int64
+	tombstoneSize uint32
+	total         uint64
+	keys          int
+	blocks        int
+	buckets       map[string]uint64 // keyed by bucket prefix
+}
+
+// tombstonedFiles caches the tombstoned bytes of each TSM file, as finding
+// them requires walking the index of the file.
+type tombstonedFiles struct {
+	mu    sync.Mutex
+	files map[string]*tombstonedFile
+}
+
+// fileTombstones returns the tombstoned bytes of the TSM file described by f,
+// or nil if it has no tombstones. It must be called with e.tombstoned.mu held.
+func (e *Engine) fileTombstones(f FileStat) *tombstonedFile {
+	if !f.HasTombstone {
+		return nil
+	}
+
+	r := e.FileStore.TSMReader(f.Path)
+	if r == nil {
+		return nil
+	}
+	defer r.Unref()
+
+	// Tombstones written in quick succession may share a modification time,
+	// but each one grows the tombstone file.
+	var tombstoneSize uint32
+	for _, ts := range r.TombstoneFiles() {
+		tombstoneSize += ts.Size
+	}
+	if tf := e.tombstoned.files[f.Path]; tf != nil && tf.lastModified == f.LastModified && tf.tombstoneSize == tombstoneSize {
+		return tf
+	}
+
+	tf := &tombstonedFile{
+		lastModified:  f.LastModified,
+		tombstoneSize: tombstoneSize,
+		buckets:       make(map[string]uint64),
+	}
+	var last []byte
+	r.TombstonedBlocks(func(key []byte, size uint32) {
+		if !bytes.Equal(key, last) {
+			last = append(last[:0], key...)
+			tf.keys++
+		}
+		tf.blocks++
+		tf.total += uint64(size)
+		if len(key) >= bucketPrefixSize {
+			tf.buckets[string(key[:bucketPrefixSize])] += uint64(size)
+		}
+	})
+
+	if e.tombstoned.files == nil {
+		e.tombstoned.files = make(map[string]*tombstonedFile)
+	}
+	e.tombstoned.files[f.Path] = tf
+	return tf
+}
+
+// filesTombstones returns the tombstoned bytes of each file in stats, keyed by
+// path. Files without tombstones are omitted, and forgotten by the cache. The
+// tombstone metrics of the file store are updated with the totals by level.
+func (e *Engine) filesTombstones(stats []FileStat) map[string]*tombstonedFile {
+	e.tombstoned.mu.Lock()
+	defer e.tombstoned.mu.Unlock()
+
+	files := make(map[string]*tombstonedFile)
+	sizes, blocks := make(map[int]uint64), make(map[int]uint64)
+	for _, f := range stats {
+		tf := e.fileTombstones(f)
+		if tf == nil {
+			continue
+		}
+		files[f.Path] = tf
+		if _, seq, err := e.FileStore.ParseFileName(f.Path); err == nil {
+			sizes[seq] += tf.total
+			blocks[seq] += uint64(tf.blocks)
+		}
+	}
+	e.tombstoned.files = files
+	e.FileStore.tracker.SetTombstones(sizes, blocks)
+	return files
+}
+
+// FileTombstoneStats describes the deleted data held in a TSM file.
+type FileTombstoneStats struct {
+	Path string
+	Size uint32
+
+	// TombstoneSize is the size of the file's tombstone files, which record
+	// the deletes until the file is compacted.
+	TombstoneSize uint32
+
+	// TombstonedBlocks is the number of blocks whose values have all been
+	// deleted, TombstonedKeys is the number of keys they belong to, and
+	// TombstonedBytes is their size, which is reclaimed once the file is
+	// compacted.
+	TombstonedKeys   int
+	TombstonedBlocks int
+	TombstonedBytes  uint64
+}
+
+// TombstoneStats describes the deleted data held in the TSM files of the
+// engine, and its cost to reads.
+type TombstoneStats struct {
+	// Files holds the stats of the files with tombstones.
+	Files []FileTombstoneStats
+
+	// TombstonedValuesRead is the number of values decoded by reads since the
+	// engine was opened that were then dropped because they had been deleted,
+	// and TombstonedBlocksRead is the number of blocks decoded that held only
+	// such values.
+	TombstonedValuesRead uint64
+	TombstonedBlocksRead uint64
+}
+
+// TombstoneStats returns the deleted data waiting to be removed from the TSM
+// files of the engine by compaction.
+func (e *Engine) TombstoneStats() TombstoneStats {
+	stats := e.FileStore.Stats()
+	tombstoned := e.filesTombstones(stats)
+
+	var s TombstoneStats
+	for _, f := range stats {
+		tf := tombstoned[f.Path]
+		if tf == nil {
+			continue
+		}
+		s.Files = append(s.Files, FileTombstoneStats{
+			Path:             f.Path,
+			Size:             f.Size,
+			TombstoneSize:    tf.tombstoneSize,
+			TombstonedKeys:   tf.keys,
+			TombstonedBlocks: tf.blocks,
+			TombstonedBytes:  tf.total,
+		})
+	}
+	if e.readTracker != nil {
+		s.TombstonedValuesRead, s.TombstonedBlocksRead = e.readTracker.Tombstoned()
+	}
+	return s
+}
+
+// CompactTombstones rewrites the TSM files in which the blocks whose values
+// have all been deleted make up at least threshold of the file size, so that
+// the space used by deleted data is reclaimed. Files are rewritten by
+// compacting them one generation at a time.
+//
+// CompactTombstones blocks until every such file has been rewritten, and
+// returns the number of generations compacted.
+func (e *Engine) CompactTombstones(ctx context.Context, threshold float64) (int, error) {
+	if e.readOnly {
+		return 0, ErrEngineReadOnly
+	}
+
+	var n int
+	for {
+		ok, err := e.compactGroupWhenReady(ctx, func() CompactionGroup {
+			return e.tombstoneCompactionGroup(threshold)
+		})
+		if err != nil || !ok {
+			return n, err
+		}
+		n++
+	}
+}
+
+// tombstoneCompactionGroup returns the files of the oldest generation that
+// contains a file whose tombstoned bytes make up at least threshold of its
+// size. It returns nil if there is no such file.
+func (e *Engine) tombstoneCompactionGroup(threshold float64) CompactionGroup {
+	stats := e.FileStore.Stats()
+	tombstoned := e.filesTombstones(stats)
+
+	var (
+		group      CompactionGroup
+		generation = -1
+		stale      bool
+	)
+	for _, f := range stats {
+		gen, _, err := e.FileStore.ParseFileName(f.Path)
+		if err != nil {
+			continue
+		}
+		if gen != generation {
+			if stale {
+				return group
+			}
+			group, generation = group[:0], gen
+		}
+		group = append(group, f.Path)
+		if tf := tombstoned[f.Path]; tf != nil && tf.total > 0 && float64(tf.total) >= threshold*float64(f.Size) {
+			stale = true
+		}
+	}
+	if stale {
+		return group
+	}
+	return nil
+}
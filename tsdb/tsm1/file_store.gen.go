@@ -12,6 +12,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	*buf = (*buf)[:0]
 	var values FloatValues
 	values, err := first.r.ReadFloatBlockAt(&first.entry, buf)
@@ -28,7 +31,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	values = excludeTombstonesFloatValues(c.trbuf, values)
+	values = c.excludeTombstonesFloatValues(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -85,6 +88,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []FloatValue
 			var v FloatValues
 			v, err := cur.r.ReadFloatBlockAt(&cur.entry, &a)
@@ -98,7 +104,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesFloatValues(c.trbuf, v)
+			v = c.excludeTombstonesFloatValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -148,6 +154,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []FloatValue
 			var v FloatValues
 			v, err := cur.r.ReadFloatBlockAt(&cur.entry, &a)
@@ -160,7 +169,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesFloatValues(c.trbuf, v)
+			v = c.excludeTombstonesFloatValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -181,10 +190,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesFloatValues(t []TimeRange, values FloatValues) FloatValues {
+// excludeTombstonesFloatValues returns values without those deleted by t,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesFloatValues(t []TimeRange, values FloatValues) FloatValues {
+	n := values.Len()
 	for i := range t {
 		values = values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 	return values
 }
 
@@ -198,6 +211,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	*buf = (*buf)[:0]
 	var values IntegerValues
 	values, err := first.r.ReadIntegerBlockAt(&first.entry, buf)
@@ -214,7 +230,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	values = excludeTombstonesIntegerValues(c.trbuf, values)
+	values = c.excludeTombstonesIntegerValues(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -271,6 +287,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []IntegerValue
 			var v IntegerValues
 			v, err := cur.r.ReadIntegerBlockAt(&cur.entry, &a)
@@ -284,7 +303,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesIntegerValues(c.trbuf, v)
+			v = c.excludeTombstonesIntegerValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -334,6 +353,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []IntegerValue
 			var v IntegerValues
 			v, err := cur.r.ReadIntegerBlockAt(&cur.entry, &a)
@@ -346,7 +368,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesIntegerValues(c.trbuf, v)
+			v = c.excludeTombstonesIntegerValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -367,10 +389,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesIntegerValues(t []TimeRange, values IntegerValues) IntegerValues {
+// excludeTombstonesIntegerValues returns values without those deleted by t,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesIntegerValues(t []TimeRange, values IntegerValues) IntegerValues {
+	n := values.Len()
 	for i := range t {
 		values = values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 	return values
 }
 
@@ -384,6 +410,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	*buf = (*buf)[:0]
 	var values UnsignedValues
 	values, err := first.r.ReadUnsignedBlockAt(&first.entry, buf)
@@ -400,7 +429,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	values = excludeTombstonesUnsignedValues(c.trbuf, values)
+	values = c.excludeTombstonesUnsignedValues(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -457,6 +486,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []UnsignedValue
 			var v UnsignedValues
 			v, err := cur.r.ReadUnsignedBlockAt(&cur.entry, &a)
@@ -470,7 +502,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesUnsignedValues(c.trbuf, v)
+			v = c.excludeTombstonesUnsignedValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -520,6 +552,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []UnsignedValue
 			var v UnsignedValues
 			v, err := cur.r.ReadUnsignedBlockAt(&cur.entry, &a)
@@ -532,7 +567,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesUnsignedValues(c.trbuf, v)
+			v = c.excludeTombstonesUnsignedValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -553,10 +588,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesUnsignedValues(t []TimeRange, values UnsignedValues) UnsignedValues {
+// excludeTombstonesUnsignedValues returns values without those deleted by t,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesUnsignedValues(t []TimeRange, values UnsignedValues) UnsignedValues {
+	n := values.Len()
 	for i := range t {
 		values = values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 	return values
 }
 
@@ -570,6 +609,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	*buf = (*buf)[:0]
 	var values StringValues
 	values, err := first.r.ReadStringBlockAt(&first.entry, buf)
@@ -586,7 +628,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	values = excludeTombstonesStringValues(c.trbuf, values)
+	values = c.excludeTombstonesStringValues(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -643,6 +685,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []StringValue
 			var v StringValues
 			v, err := cur.r.ReadStringBlockAt(&cur.entry, &a)
@@ -656,7 +701,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesStringValues(c.trbuf, v)
+			v = c.excludeTombstonesStringValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -706,6 +751,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []StringValue
 			var v StringValues
 			v, err := cur.r.ReadStringBlockAt(&cur.entry, &a)
@@ -718,7 +766,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesStringValues(c.trbuf, v)
+			v = c.excludeTombstonesStringValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -739,10 +787,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesStringValues(t []TimeRange, values StringValues) StringValues {
+// excludeTombstonesStringValues returns values without those deleted by t,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesStringValues(t []TimeRange, values StringValues) StringValues {
+	n := values.Len()
 	for i := range t {
 		values = values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 	return values
 }
 
@@ -756,6 +808,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	*buf = (*buf)[:0]
 	var values BooleanValues
 	values, err := first.r.ReadBooleanBlockAt(&first.entry, buf)
@@ -772,7 +827,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	values = excludeTombstonesBooleanValues(c.trbuf, values)
+	values = c.excludeTombstonesBooleanValues(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -829,6 +884,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []BooleanValue
 			var v BooleanValues
 			v, err := cur.r.ReadBooleanBlockAt(&cur.entry, &a)
@@ -842,7 +900,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesBooleanValues(c.trbuf, v)
+			v = c.excludeTombstonesBooleanValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -892,6 +950,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			var a []BooleanValue
 			var v BooleanValues
 			v, err := cur.r.ReadBooleanBlockAt(&cur.entry, &a)
@@ -904,7 +965,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			v = excludeTombstonesBooleanValues(c.trbuf, v)
+			v = c.excludeTombstonesBooleanValues(c.trbuf, v)
 
 			// Remove values we already read
 			v = v.Exclude(cur.readMin, cur.readMax)
@@ -925,9 +986,13 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesBooleanValues(t []TimeRange, values BooleanValues) BooleanValues {
+// excludeTombstonesBooleanValues returns values without those deleted by t,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesBooleanValues(t []TimeRange, values BooleanValues) BooleanValues {
+	n := values.Len()
 	for i := range t {
 		values = values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 	return values
 }
@@ -23,6 +23,7 @@ import (
 	"github.com/influxdata/influxdb/pkg/metrics"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -217,6 +218,12 @@ type FileStore struct {
 	parseFileName ParseFileNameFunc
 
 	obs FileStoreObserver
+
+	// tier is the object tier that cold files are offloaded to, if any.
+	tier *ObjectTier
+
+	// readOnly prevents corrupt files from being renamed when opened.
+	readOnly bool
 }
 
 // FileStat holds information about a TSM file on disk.
@@ -227,6 +234,7 @@ type FileStat struct {
 	LastModified     int64
 	MinTime, MaxTime int64
 	MinKey, MaxKey   []byte
+	Offloaded        bool
 }
 
 // OverlapsTimeRange returns true if the time range of the file intersect min and max.
@@ -285,6 +293,19 @@ func (f *FileStore) SetCurrentGenerationFunc(fn func() int) {
 	f.currentGenerationFunc = fn
 }
 
+// WithObjectTier sets the object tier that cold files are offloaded to. It
+// must be set before the file store is opened.
+func (f *FileStore) WithObjectTier(tier *ObjectTier) {
+	f.tier = tier
+}
+
+// SetReadOnly sets whether the file store may modify its directory. A
+// read-only file store skips corrupt files rather than renaming them. It must
+// be set before the file store is opened.
+func (f *FileStore) SetReadOnly(readOnly bool) {
+	f.readOnly = readOnly
+}
+
 // WithLogger sets the logger on the file store.
 func (f *FileStore) WithLogger(log *zap.Logger) {
 	f.logger = log.With(zap.String("service", "filestore"))
@@ -368,6 +389,32 @@ func (t *fileTracker) SetFileCount(files map[int]uint64) {
 	}
 }
 
+// SetTombstones sets the number of bytes and blocks of deleted data in TSM
+// files, by level, that are waiting to be removed by compaction. Levels
+// missing from bytes and blocks are reset to zero.
+func (t *fileTracker) SetTombstones(bytes, blocks map[int]uint64) {
+	sizes, counts := make(map[string]uint64), make(map[string]uint64)
+	for i := uint64(1); i <= 4; i++ {
+		sizes[formatLevel(i)], counts[formatLevel(i)] = 0, 0
+	}
+	for k, v := range bytes {
+		sizes[formatLevel(uint64(k))] += v
+	}
+	for k, v := range blocks {
+		counts[formatLevel(uint64(k))] += v
+	}
+
+	labels := t.Labels()
+	for k, v := range sizes {
+		labels["level"] = k
+		t.metrics.TombstonedBytes.With(labels).Set(float64(v))
+	}
+	for k, v := range counts {
+		labels["level"] = k
+		t.metrics.TombstonedBlocks.With(labels).Set(float64(v))
+	}
+}
+
 func (t *fileTracker) ClearFileCounts() {
 	labels := t.Labels()
 	for i := uint64(1); i <= 4; i++ {
@@ -663,12 +710,29 @@ func (f *FileStore) Open(ctx context.Context) error {
 			start := time.Now()
 			df, err := NewTSMReader(file,
 				WithMadviseWillNeed(f.tsmMMAPWillNeed),
-				WithTSMReaderLogger(f.logger))
+				WithTSMReaderLogger(f.logger),
+				WithObjectTier(f.tier))
 			f.logger.Info("Opened file",
 				zap.String("path", file.Name()),
 				zap.Int("id", idx),
 				zap.Duration("duration", time.Since(start)))
 
+			// Stubs of offloaded files are not corrupt, so refuse to open
+			// rather than renaming them.
+			if err == ErrObjectTierRequired {
+				file.Close()
+				readerC <- &res{err: fmt.Errorf("cannot open %s: %v", file.Name(), err)}
+				return
+			}
+
+			// A read-only file store can not rename the file, so skip it.
+			if err != nil && f.readOnly {
+				f.logger.Error("Cannot read corrupt tsm file, skipping", zap.String("path", file.Name()), zap.Int("id", idx), zap.Error(err))
+				file.Close()
+				readerC <- &res{}
+				return
+			}
+
 			// If we are unable to read a TSM file then log the error, rename
 			// the file, and continue loading the shard without it.
 			if err != nil {
@@ -904,7 +968,8 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 
 		tsm, err := NewTSMReader(fd,
 			WithMadviseWillNeed(f.tsmMMAPWillNeed),
-			WithTSMReaderLogger(f.logger))
+			WithTSMReaderLogger(f.logger),
+			WithObjectTier(f.tier))
 		if err != nil {
 			return err
 		}
@@ -1024,6 +1089,12 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	f.lastFileStats = nil
 	f.files = active
 	sort.Sort(tsmReaders(f.files))
+	return f.resetTracker()
+}
+
+// resetTracker recalculates the file count and disk size stats from the
+// current set of files. f.mu must be held.
+func (f *FileStore) resetTracker() error {
 	f.tracker.ClearFileCounts()
 	f.tracker.ClearDiskSizes()
 
@@ -1060,7 +1131,7 @@ func (f *FileStore) LastModified() time.Time {
 // BlockCount returns number of values stored in the block at location idx
 // in the file at path.  If path does not match any file in the store, 0 is
 // returned.  If idx is out of range for the number of blocks in the file,
-// 0 is returned.
+// or the block cannot be read, 0 is returned.
 func (f *FileStore) BlockCount(path string, idx int) int {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -1077,8 +1148,15 @@ func (f *FileStore) BlockCount(path string, idx int) int {
 					return 0
 				}
 			}
-			_, _, _, _, _, block, _ := iter.Read()
-			return BlockCount(block)
+			_, _, _, _, _, block, err := iter.Read()
+			if err != nil {
+				return 0
+			}
+			n, err := BlockCount(block)
+			if err != nil {
+				return 0
+			}
+			return n
 		}
 	}
 	return 0
@@ -1340,6 +1418,17 @@ type KeyCursor struct {
 	// decrement through the size of seeks slice.
 	pos       int
 	ascending bool
+
+	// tracker, if set, is given the number of values and blocks read and then
+	// dropped because they had been deleted when the cursor is closed.
+	tracker          *readTracker
+	tombstonedValues uint64
+	tombstonedBlocks uint64
+
+	// dedup resolves the values of overlapping blocks with the same timestamp
+	// read as arrays, and duplicates is the number of values resolved.
+	dedup      cursors.DuplicateResolution
+	duplicates int
 }
 
 type location struct {
@@ -1396,6 +1485,7 @@ func newKeyCursor(ctx context.Context, fs *FileStore, key []byte, t int64, ascen
 		ctx:       ctx,
 		col:       metrics.GroupFromContext(ctx),
 		ascending: ascending,
+		dedup:     cursors.DuplicateResolutionFromContext(ctx),
 	}
 
 	if ascending {
@@ -1420,11 +1510,27 @@ func (c *KeyCursor) Close() {
 		f.r.Unref()
 	}
 
+	if c.tracker != nil && c.tombstonedValues > 0 {
+		c.tracker.AddTombstoned(c.tombstonedValues, c.tombstonedBlocks)
+	}
+	c.tombstonedValues, c.tombstonedBlocks = 0, 0
+
 	c.buf = nil
 	c.seeks = nil
 	c.current = nil
 }
 
+// fetch fetches the block of loc if its file is offloaded to an object store,
+// so that a stalled fetch is abandoned when the context of the cursor is
+// cancelled.
+func (c *KeyCursor) fetch(loc *location) error {
+	r, ok := loc.r.(*TSMReader)
+	if !ok || c.ctx == nil {
+		return nil
+	}
+	return r.fetchBlock(c.ctx, &loc.entry)
+}
+
 // seek positions the cursor at the given time.
 func (c *KeyCursor) seek(t int64) {
 	if len(c.seeks) == 0 {
@@ -1465,6 +1571,18 @@ func (c *KeyCursor) seekDescending(t int64) {
 	}
 }
 
+// addTombstoned records that n values of a block were dropped because they
+// had been deleted, leaving remaining values of the block to be read.
+func (c *KeyCursor) addTombstoned(n, remaining int) {
+	if n == 0 {
+		return
+	}
+	c.tombstonedValues += uint64(n)
+	if remaining == 0 {
+		c.tombstonedBlocks++
+	}
+}
+
 // seekN returns the number of seek locations.
 func (c *KeyCursor) seekN() int {
 	return len(c.seeks)
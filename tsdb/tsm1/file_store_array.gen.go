@@ -18,6 +18,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	err := first.r.ReadFloatArrayBlockAt(&first.entry, values)
 	if err != nil {
 		return nil, err
@@ -32,7 +35,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	excludeTombstonesFloatArray(c.trbuf, values)
+	c.excludeTombstonesFloatArray(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -89,6 +92,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.FloatArray{}
 			err := cur.r.ReadFloatArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -101,7 +107,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesFloatArray(c.trbuf, v)
+			c.excludeTombstonesFloatArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -110,7 +116,7 @@ LOOP:
 				// Only use values in the overlapping window
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				values.Merge(v)
+				c.duplicates += values.MergeResolve(v, c.dedup)
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -151,6 +157,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.FloatArray{}
 			err := cur.r.ReadFloatArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -162,7 +171,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesFloatArray(c.trbuf, v)
+			c.excludeTombstonesFloatArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -172,7 +181,7 @@ LOOP:
 			if v.Len() > 0 {
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				v.Merge(values)
+				c.duplicates += v.MergeResolve(values, c.dedup)
 				*values = *v
 			}
 			cur.markRead(minT, maxT)
@@ -184,10 +193,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesFloatArray(t []TimeRange, values *tsdb.FloatArray) {
+// excludeTombstonesFloatArray removes the values deleted by t from values,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesFloatArray(t []TimeRange, values *tsdb.FloatArray) {
+	n := values.Len()
 	for i := range t {
 		values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 }
 
 // ReadIntegerArrayBlock reads the next block as a set of integer values.
@@ -202,6 +215,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	err := first.r.ReadIntegerArrayBlockAt(&first.entry, values)
 	if err != nil {
 		return nil, err
@@ -216,7 +232,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	excludeTombstonesIntegerArray(c.trbuf, values)
+	c.excludeTombstonesIntegerArray(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -273,6 +289,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.IntegerArray{}
 			err := cur.r.ReadIntegerArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -285,7 +304,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesIntegerArray(c.trbuf, v)
+			c.excludeTombstonesIntegerArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -294,7 +313,7 @@ LOOP:
 				// Only use values in the overlapping window
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				values.Merge(v)
+				c.duplicates += values.MergeResolve(v, c.dedup)
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -335,6 +354,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.IntegerArray{}
 			err := cur.r.ReadIntegerArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -346,7 +368,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesIntegerArray(c.trbuf, v)
+			c.excludeTombstonesIntegerArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -356,7 +378,7 @@ LOOP:
 			if v.Len() > 0 {
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				v.Merge(values)
+				c.duplicates += v.MergeResolve(values, c.dedup)
 				*values = *v
 			}
 			cur.markRead(minT, maxT)
@@ -368,10 +390,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesIntegerArray(t []TimeRange, values *tsdb.IntegerArray) {
+// excludeTombstonesIntegerArray removes the values deleted by t from values,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesIntegerArray(t []TimeRange, values *tsdb.IntegerArray) {
+	n := values.Len()
 	for i := range t {
 		values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 }
 
 // ReadUnsignedArrayBlock reads the next block as a set of unsigned values.
@@ -386,6 +412,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	err := first.r.ReadUnsignedArrayBlockAt(&first.entry, values)
 	if err != nil {
 		return nil, err
@@ -400,7 +429,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	excludeTombstonesUnsignedArray(c.trbuf, values)
+	c.excludeTombstonesUnsignedArray(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -457,6 +486,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.UnsignedArray{}
 			err := cur.r.ReadUnsignedArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -469,7 +501,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesUnsignedArray(c.trbuf, v)
+			c.excludeTombstonesUnsignedArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -478,7 +510,7 @@ LOOP:
 				// Only use values in the overlapping window
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				values.Merge(v)
+				c.duplicates += values.MergeResolve(v, c.dedup)
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -519,6 +551,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.UnsignedArray{}
 			err := cur.r.ReadUnsignedArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -530,7 +565,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesUnsignedArray(c.trbuf, v)
+			c.excludeTombstonesUnsignedArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -540,7 +575,7 @@ LOOP:
 			if v.Len() > 0 {
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				v.Merge(values)
+				c.duplicates += v.MergeResolve(values, c.dedup)
 				*values = *v
 			}
 			cur.markRead(minT, maxT)
@@ -552,10 +587,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesUnsignedArray(t []TimeRange, values *tsdb.UnsignedArray) {
+// excludeTombstonesUnsignedArray removes the values deleted by t from values,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesUnsignedArray(t []TimeRange, values *tsdb.UnsignedArray) {
+	n := values.Len()
 	for i := range t {
 		values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 }
 
 // ReadStringArrayBlock reads the next block as a set of string values.
@@ -570,6 +609,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	err := first.r.ReadStringArrayBlockAt(&first.entry, values)
 	if err != nil {
 		return nil, err
@@ -584,7 +626,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	excludeTombstonesStringArray(c.trbuf, values)
+	c.excludeTombstonesStringArray(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -641,6 +683,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.StringArray{}
 			err := cur.r.ReadStringArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -653,7 +698,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesStringArray(c.trbuf, v)
+			c.excludeTombstonesStringArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -662,7 +707,7 @@ LOOP:
 				// Only use values in the overlapping window
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				values.Merge(v)
+				c.duplicates += values.MergeResolve(v, c.dedup)
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -703,6 +748,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.StringArray{}
 			err := cur.r.ReadStringArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -714,7 +762,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesStringArray(c.trbuf, v)
+			c.excludeTombstonesStringArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -724,7 +772,7 @@ LOOP:
 			if v.Len() > 0 {
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				v.Merge(values)
+				c.duplicates += v.MergeResolve(values, c.dedup)
 				*values = *v
 			}
 			cur.markRead(minT, maxT)
@@ -736,10 +784,14 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesStringArray(t []TimeRange, values *tsdb.StringArray) {
+// excludeTombstonesStringArray removes the values deleted by t from values,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesStringArray(t []TimeRange, values *tsdb.StringArray) {
+	n := values.Len()
 	for i := range t {
 		values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 }
 
 // ReadBooleanArrayBlock reads the next block as a set of boolean values.
@@ -754,6 +806,9 @@ LOOP:
 
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
+	if err := c.fetch(first); err != nil {
+		return nil, err
+	}
 	err := first.r.ReadBooleanArrayBlockAt(&first.entry, values)
 	if err != nil {
 		return nil, err
@@ -768,7 +823,7 @@ LOOP:
 
 	// Remove any tombstones
 	c.trbuf = first.r.TombstoneRange(c.key, c.trbuf[:0])
-	excludeTombstonesBooleanArray(c.trbuf, values)
+	c.excludeTombstonesBooleanArray(c.trbuf, values)
 	// If there are no values in this first block (all tombstoned or previously read) and
 	// we have more potential blocks too search.  Try again.
 	if values.Len() == 0 && len(c.current) > 0 {
@@ -825,6 +880,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.BooleanArray{}
 			err := cur.r.ReadBooleanArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -837,7 +895,7 @@ LOOP:
 
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesBooleanArray(c.trbuf, v)
+			c.excludeTombstonesBooleanArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -846,7 +904,7 @@ LOOP:
 				// Only use values in the overlapping window
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				values.Merge(v)
+				c.duplicates += values.MergeResolve(v, c.dedup)
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -887,6 +945,9 @@ LOOP:
 				continue
 			}
 
+			if err := c.fetch(cur); err != nil {
+				return nil, err
+			}
 			v := &tsdb.BooleanArray{}
 			err := cur.r.ReadBooleanArrayBlockAt(&cur.entry, v)
 			if err != nil {
@@ -898,7 +959,7 @@ LOOP:
 			}
 			c.trbuf = cur.r.TombstoneRange(c.key, c.trbuf[:0])
 			// Remove any tombstoned values
-			excludeTombstonesBooleanArray(c.trbuf, v)
+			c.excludeTombstonesBooleanArray(c.trbuf, v)
 
 			// Remove values we already read
 			v.Exclude(cur.readMin, cur.readMax)
@@ -908,7 +969,7 @@ LOOP:
 			if v.Len() > 0 {
 				v.Include(minT, maxT)
 				// Merge the remaining values with the existing
-				v.Merge(values)
+				c.duplicates += v.MergeResolve(values, c.dedup)
 				*values = *v
 			}
 			cur.markRead(minT, maxT)
@@ -920,8 +981,12 @@ LOOP:
 	return values, err
 }
 
-func excludeTombstonesBooleanArray(t []TimeRange, values *tsdb.BooleanArray) {
+// excludeTombstonesBooleanArray removes the values deleted by t from values,
+// recording the number removed.
+func (c *KeyCursor) excludeTombstonesBooleanArray(t []TimeRange, values *tsdb.BooleanArray) {
+	n := values.Len()
 	for i := range t {
 		values.Exclude(t[i].Min, t[i].Max)
 	}
+	c.addTombstoned(n-values.Len(), values.Len())
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
 
@@ -367,3 +368,73 @@ func TestFileStore_Array(t *testing.T) {
 		})
 	}
 }
+
+func TestFileStore_Array_DuplicateResolution(t *testing.T) {
+	data := []keyValues{
+		{key: "cpu", values: []tsm1.Value{tsm1.NewFloatValue(0, 1.0), tsm1.NewFloatValue(1, 5.0), tsm1.NewFloatValue(2, 4.0)}},
+		{key: "cpu", values: []tsm1.Value{tsm1.NewFloatValue(0, 3.0), tsm1.NewFloatValue(1, 2.0)}},
+		{key: "cpu", values: []tsm1.Value{tsm1.NewFloatValue(0, 2.0), tsm1.NewFloatValue(2, 6.0)}},
+	}
+
+	cases := []struct {
+		name string
+		r    cursors.DuplicateResolution
+		asc  bool
+		exp  *tsdb.FloatArray
+	}{
+		{
+			name: "NewestAsc",
+			r:    cursors.DuplicateResolutionNewest,
+			asc:  true,
+			exp:  &tsdb.FloatArray{Timestamps: []int64{0, 1, 2}, Values: []float64{2.0, 2.0, 6.0}},
+		},
+		{
+			name: "MaxAsc",
+			r:    cursors.DuplicateResolutionMax,
+			asc:  true,
+			exp:  &tsdb.FloatArray{Timestamps: []int64{0, 1, 2}, Values: []float64{3.0, 5.0, 6.0}},
+		},
+		{
+			name: "MinAsc",
+			r:    cursors.DuplicateResolutionMin,
+			asc:  true,
+			exp:  &tsdb.FloatArray{Timestamps: []int64{0, 1, 2}, Values: []float64{1.0, 2.0, 4.0}},
+		},
+		{
+			name: "MaxDesc",
+			r:    cursors.DuplicateResolutionMax,
+			asc:  false,
+			exp:  &tsdb.FloatArray{Timestamps: []int64{0, 1, 2}, Values: []float64{3.0, 5.0, 6.0}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := MustTempDir()
+			defer os.RemoveAll(dir)
+			fs := tsm1.NewFileStore(dir)
+
+			files, err := newFiles(dir, data...)
+			if err != nil {
+				t.Fatalf("unexpected error creating files: %v", err)
+			}
+			fs.Replace(nil, files)
+
+			seek := int64(0)
+			if !tc.asc {
+				seek = 2
+			}
+			ctx := cursors.NewContextWithDuplicateResolution(context.Background(), tc.r)
+			c := fs.KeyCursor(ctx, []byte("cpu"), seek, tc.asc)
+			defer c.Close()
+
+			values, err := c.ReadFloatArrayBlock(tsdb.NewFloatArrayLen(1000))
+			if err != nil {
+				t.Fatalf("unexpected error reading values: %v", err)
+			}
+			if !cmp.Equal(values, tc.exp) {
+				t.Fatalf("unexpected values -got/+exp\n%s", cmp.Diff(values, tc.exp))
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ this version.
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
 	"math"
 	"math/bits"
@@ -22,6 +23,10 @@ import (
 // floatCompressedGorilla is a compressed format using the gorilla paper encoding
 const floatCompressedGorilla = 1
 
+// floatCompressedRuns is the gorilla encoding of runs of repeated values,
+// preceded by the length of each run.
+const floatCompressedRuns = 2
+
 // uvnan is the constant returned from math.NaN().
 const uvnan = 0x7FF8000000000001
 
@@ -148,6 +153,11 @@ type FloatDecoder struct {
 	br BitReader
 	b  []byte
 
+	// runs holds the lengths of the runs not yet started if the values are
+	// run length encoded, and runN the repeats left in the current run.
+	runs []byte
+	runN uint64
+
 	first    bool
 	finished bool
 
@@ -156,6 +166,15 @@ type FloatDecoder struct {
 
 // SetBytes initializes the decoder with b. Must call before calling Next().
 func (it *FloatDecoder) SetBytes(b []byte) error {
+	it.runs, it.runN = nil, 0
+	if len(b) > 0 && b[0]>>4 == floatCompressedRuns {
+		runs, payload, err := unpackFloatRuns(b)
+		if err != nil {
+			return err
+		}
+		it.runs, b = runs, payload
+	}
+
 	var v uint64
 	if len(b) == 0 {
 		v = uvnan
@@ -185,6 +204,26 @@ func (it *FloatDecoder) SetBytes(b []byte) error {
 
 // Next returns true if there are remaining values to read.
 func (it *FloatDecoder) Next() bool {
+	if it.runN > 0 {
+		it.runN--
+		return true
+	}
+	if !it.next() {
+		return false
+	}
+	if it.runs != nil {
+		n, i := binary.Uvarint(it.runs)
+		if i <= 0 || n == 0 {
+			it.err = fmt.Errorf("floatDecoder: invalid run length")
+			return false
+		}
+		it.runs, it.runN = it.runs[i:], n-1
+	}
+	return true
+}
+
+// next reads the next value of the gorilla encoding.
+func (it *FloatDecoder) next() bool {
 	if it.err != nil || it.finished {
 		return false
 	}
@@ -34,6 +34,10 @@ const (
 	intCompressedSimple = 1
 	// intCompressedRLE is a run-length encoding format
 	intCompressedRLE = 2
+	// intCompressedDeltaOfDelta is one of the formats above holding the
+	// differences between consecutive values, so that values are stored as
+	// deltas of deltas
+	intCompressedDeltaOfDelta = 3
 )
 
 // IntegerEncoder encodes int64s into byte slices.
@@ -170,10 +174,21 @@ type IntegerDecoder struct {
 	rleDelta uint64
 	encoding byte
 	err      error
+
+	// dod is true if the decoded values are the differences between the
+	// values, which are summed in sum.
+	dod bool
+	sum int64
 }
 
 // SetBytes sets the underlying byte slice of the decoder.
 func (d *IntegerDecoder) SetBytes(b []byte) {
+	d.dod, d.sum = false, 0
+	if len(b) > 0 && b[0]>>4 == intCompressedDeltaOfDelta {
+		d.dod = true
+		b = b[1:]
+	}
+
 	if len(b) > 0 {
 		d.encoding = b[0] >> 4
 		d.bytes = b[1:]
@@ -222,6 +237,14 @@ func (d *IntegerDecoder) Error() error {
 
 // Read returns the next value from the decoder.
 func (d *IntegerDecoder) Read() int64 {
+	if d.dod {
+		d.sum += d.read()
+		return d.sum
+	}
+	return d.read()
+}
+
+func (d *IntegerDecoder) read() int64 {
 	switch d.encoding {
 	case intCompressedRLE:
 		return ZigZagDecode(d.rleFirst) + int64(d.i)*ZigZagDecode(d.rleDelta)
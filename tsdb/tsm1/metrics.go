@@ -131,8 +131,10 @@ func (m *compactionMetrics) PrometheusCollectors() []prometheus.Collector {
 
 // fileMetrics are a set of metrics concerned with tracking data about compactions.
 type fileMetrics struct {
-	DiskSize *prometheus.GaugeVec
-	Files    *prometheus.GaugeVec
+	DiskSize         *prometheus.GaugeVec
+	Files            *prometheus.GaugeVec
+	TombstonedBytes  *prometheus.GaugeVec
+	TombstonedBlocks *prometheus.GaugeVec
 }
 
 // newFileMetrics initialises the prometheus metrics for tracking files on disk.
@@ -157,6 +159,18 @@ func newFileMetrics(labels prometheus.Labels) *fileMetrics {
 			Name:      "total",
 			Help:      "Number of files.",
 		}, names),
+		TombstonedBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: fileStoreSubsystem,
+			Name:      "tombstoned_bytes",
+			Help:      "Number of bytes of deleted blocks waiting to be removed by compaction.",
+		}, names),
+		TombstonedBlocks: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: fileStoreSubsystem,
+			Name:      "tombstoned_blocks",
+			Help:      "Number of deleted blocks waiting to be removed by compaction.",
+		}, names),
 	}
 }
 
@@ -165,6 +179,8 @@ func (m *fileMetrics) PrometheusCollectors() []prometheus.Collector {
 	return []prometheus.Collector{
 		m.DiskSize,
 		m.Files,
+		m.TombstonedBytes,
+		m.TombstonedBlocks,
 	}
 }
 
@@ -179,6 +195,11 @@ type cacheMetrics struct {
 	// The following metrics include a ``"status" = {ok, error, dropped}` label
 	WrittenBytes *prometheus.CounterVec
 	Writes       *prometheus.CounterVec
+
+	// The following metrics include a "bucket" label.
+	BucketHits         *prometheus.CounterVec
+	BucketMisses       *prometheus.CounterVec
+	BucketEvictedBytes *prometheus.CounterVec
 }
 
 // newCacheMetrics initialises the prometheus metrics for compactions.
@@ -192,6 +213,9 @@ func newCacheMetrics(labels prometheus.Labels) *cacheMetrics {
 	writeNames := append(append([]string(nil), names...), "status")
 	sort.Strings(writeNames)
 
+	bucketNames := append(append([]string(nil), names...), "bucket")
+	sort.Strings(bucketNames)
+
 	return &cacheMetrics{
 		MemSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace: namespace,
@@ -235,6 +259,24 @@ func newCacheMetrics(labels prometheus.Labels) *cacheMetrics {
 			Name:      "writes_total",
 			Help:      "Number of writes to the Cache.",
 		}, writeNames),
+		BucketHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: cacheSubsystem,
+			Name:      "bucket_hits_total",
+			Help:      "Number of reads of a bucket's series that found values in the Cache.",
+		}, bucketNames),
+		BucketMisses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: cacheSubsystem,
+			Name:      "bucket_misses_total",
+			Help:      "Number of reads of a bucket's series that found no values in the Cache.",
+		}, bucketNames),
+		BucketEvictedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: cacheSubsystem,
+			Name:      "bucket_evicted_bytes",
+			Help:      "Number of bytes of a bucket's data moved out of the Cache by snapshots.",
+		}, bucketNames),
 	}
 }
 
@@ -248,13 +290,18 @@ func (m *cacheMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.SnapshottedBytes,
 		m.WrittenBytes,
 		m.Writes,
+		m.BucketHits,
+		m.BucketMisses,
+		m.BucketEvictedBytes,
 	}
 }
 
 // readMetrics are a set of metrics concerned with tracking data engine reads.
 type readMetrics struct {
-	Cursors *prometheus.CounterVec
-	Seeks   *prometheus.CounterVec
+	Cursors          *prometheus.CounterVec
+	Seeks            *prometheus.CounterVec
+	TombstonedValues *prometheus.CounterVec
+	TombstonedBlocks *prometheus.CounterVec
 }
 
 // newReadMetrics initialises the prometheus metrics for tracking reads.
@@ -278,6 +325,18 @@ func newReadMetrics(labels prometheus.Labels) *readMetrics {
 			Name:      "seeks",
 			Help:      "Number of tsm locations seeked.",
 		}, names),
+		TombstonedValues: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: readSubsystem,
+			Name:      "tombstoned_values",
+			Help:      "Number of values decoded and then dropped because they were deleted.",
+		}, names),
+		TombstonedBlocks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: readSubsystem,
+			Name:      "tombstoned_blocks",
+			Help:      "Number of blocks decoded whose remaining values had all been deleted.",
+		}, names),
 	}
 }
 
@@ -286,5 +345,7 @@ func (m *readMetrics) PrometheusCollectors() []prometheus.Collector {
 	return []prometheus.Collector{
 		m.Cursors,
 		m.Seeks,
+		m.TombstonedValues,
+		m.TombstonedBlocks,
 	}
 }
@@ -53,6 +53,27 @@ func TestMetrics_Filestore(t *testing.T) {
 	if m, got, exp := m3Bytes2, m3Bytes2.GetGauge().GetValue(), 200.0; got != exp {
 		t.Errorf("[%s] got %v, expected %v", m, got, exp)
 	}
+
+	// Levels without tombstones are reset.
+	t2.SetTombstones(map[int]uint64{1: 100, 4: 50}, map[int]uint64{1: 2, 4: 1})
+	t2.SetTombstones(map[int]uint64{4: 50}, map[int]uint64{4: 1})
+	if mfs, err = reg.Gather(); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name  string
+		level string
+		exp   float64
+	}{
+		{name: "tombstoned_bytes", level: "1", exp: 0},
+		{name: "tombstoned_bytes", level: "4+", exp: 50},
+		{name: "tombstoned_blocks", level: "4+", exp: 1},
+	} {
+		m := promtest.MustFindMetric(t, mfs, base+tt.name, prometheus.Labels{"engine_id": "1", "node_id": "0", "level": tt.level})
+		if got := m.GetGauge().GetValue(); got != tt.exp {
+			t.Errorf("[%s] got %v, expected %v", m, got, tt.exp)
+		}
+	}
 }
 
 func TestMetrics_Cache(t *testing.T) {
@@ -0,0 +1,144 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/influxdb/pkg/fs"
+)
+
+// ObjectStore is a store of immutable objects, such as S3, GCS or Azure Blob
+// Storage, that cold TSM files are offloaded to.
+type ObjectStore interface {
+	// Put uploads size bytes read from r as the object named key.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// GetRange returns n bytes of the object named key, starting at off.
+	GetRange(ctx context.Context, key string, off, n int64) ([]byte, error)
+
+	// Delete removes the object named key. Deleting an object that does
+	// not exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewObjectStore returns the ObjectStore described by rawurl. The supported
+// schemes are:
+//
+//	s3://bucket/prefix?region=us-east-1&endpoint=http://host:9000
+//	gs://bucket/prefix
+//	azblob://account/container/prefix
+//	file:///path/to/dir
+//
+// S3 credentials are taken from the standard AWS credential chain. GCS is
+// accessed through its S3-compatible interoperability API, so it uses HMAC
+// keys from the same chain. Azure requires a SAS token for the container in
+// the AZURE_STORAGE_SAS_TOKEN environment variable.
+func NewObjectStore(rawurl string) (ObjectStore, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object store url: %v", err)
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		q := u.Query()
+		return newS3ObjectStore(u.Host, prefix, q.Get("region"), q.Get("endpoint"), q.Get("force-path-style") == "true")
+	case "gs":
+		return newS3ObjectStore(u.Host, prefix, "auto", "https://storage.googleapis.com", false)
+	case "azblob":
+		parts := strings.SplitN(prefix, "/", 2)
+		container := parts[0]
+		prefix = ""
+		if len(parts) == 2 {
+			prefix = parts[1]
+		}
+		return newAzureObjectStore(u.Host, container, prefix, os.Getenv("AZURE_STORAGE_SAS_TOKEN"))
+	case "file":
+		return NewFileObjectStore(u.Path)
+	default:
+		return nil, fmt.Errorf("unsupported object store scheme %q", u.Scheme)
+	}
+}
+
+// objectPath returns key inside prefix.
+func objectPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return path.Join(prefix, key)
+}
+
+// FileObjectStore is an ObjectStore backed by a local directory, such as a
+// network file system mount.
+type FileObjectStore struct {
+	dir string
+}
+
+// NewFileObjectStore returns an ObjectStore that stores objects in dir.
+func NewFileObjectStore(dir string) (*FileObjectStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("object store directory required")
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	return &FileObjectStore{dir: dir}, nil
+}
+
+// Put writes the object to a temporary file before renaming it into place.
+func (s *FileObjectStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	name := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".*."+CompactionTempExtension)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if n, err := io.Copy(f, r); err != nil {
+		return err
+	} else if n != size {
+		return fmt.Errorf("object %s: wrote %d bytes, expected %d", key, n, size)
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	} else if err := f.Close(); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(f.Name(), name)
+}
+
+// GetRange reads the range from the object's file.
+func (s *FileObjectStore) GetRange(ctx context.Context, key string, off, n int64) ([]byte, error) {
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := make([]byte, n)
+	if _, err := f.ReadAt(b, off); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Delete removes the object's file.
+func (s *FileObjectStore) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
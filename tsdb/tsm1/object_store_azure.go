@@ -0,0 +1,138 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureBlobAPIVersion is the version of the Blob service REST API used. It
+// is the first to allow single requests to upload blobs up to 5000 MiB,
+// larger than the maximum TSM file size.
+const azureBlobAPIVersion = "2019-12-12"
+
+// azureRequestTimeout bounds every request to Azure Blob Storage, including
+// uploads of whole TSM files, so that a stalled connection is abandoned.
+const azureRequestTimeout = time.Hour
+
+// newAzureHTTPClient returns the client used for requests to Azure Blob
+// Storage. Besides the overall timeout, connecting and waiting for responses
+// are bounded separately, so that requests fail well before the timeout when
+// the service does not respond at all.
+func newAzureHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: azureRequestTimeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: time.Minute,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConnsPerHost:   16,
+		},
+	}
+}
+
+// azureObjectStore is an ObjectStore backed by an Azure Blob Storage
+// container, authorized with a shared access signature.
+type azureObjectStore struct {
+	container *url.URL
+	prefix    string
+	sas       url.Values
+
+	client *http.Client
+}
+
+func newAzureObjectStore(account, container, prefix, sas string) (*azureObjectStore, error) {
+	if account == "" || container == "" {
+		return nil, fmt.Errorf("object store account and container required")
+	}
+	q, err := url.ParseQuery(strings.TrimPrefix(sas, "?"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure sas token: %v", err)
+	} else if len(q) == 0 {
+		return nil, fmt.Errorf("azure sas token required in AZURE_STORAGE_SAS_TOKEN")
+	}
+
+	return &azureObjectStore{
+		container: &url.URL{Scheme: "https", Host: account + ".blob.core.windows.net", Path: "/" + container},
+		prefix:    prefix,
+		sas:       q,
+		client:    newAzureHTTPClient(),
+	}, nil
+}
+
+func (s *azureObjectStore) do(ctx context.Context, method, key string, body io.Reader, fn func(r *http.Request)) (*http.Response, error) {
+	u := *s.container
+	u.Path += "/" + objectPath(s.prefix, key)
+	u.RawQuery = s.sas.Encode()
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	if fn != nil {
+		fn(req)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp, fmt.Errorf("azure %s %s: %s: %s", method, key, resp.Status, msg)
+	}
+	return resp, nil
+}
+
+func (s *azureObjectStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	resp, err := s.do(ctx, http.MethodPut, key, r, func(req *http.Request) {
+		req.ContentLength = size
+		req.Header.Set("x-ms-blob-type", "BlockBlob")
+	})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *azureObjectStore) GetRange(ctx context.Context, key string, off, n int64) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, func(req *http.Request) {
+		req.Header.Set("x-ms-range", fmt.Sprintf("bytes=%d-%d", off, off+n-1))
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	} else if int64(len(b)) != n {
+		return nil, fmt.Errorf("object %s: read %d bytes at offset %d, expected %d", key, len(b), off, n)
+	}
+	return b, nil
+}
+
+func (s *azureObjectStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
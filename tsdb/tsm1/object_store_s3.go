@@ -0,0 +1,88 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// s3ObjectStore is an ObjectStore backed by an S3 compatible bucket.
+type s3ObjectStore struct {
+	bucket, prefix string
+
+	client   *s3.S3
+	uploader *s3manager.Uploader
+}
+
+func newS3ObjectStore(bucket, prefix, region, endpoint string, forcePathStyle bool) (*s3ObjectStore, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("object store bucket required")
+	}
+
+	config := aws.NewConfig().WithS3ForcePathStyle(forcePathStyle)
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+	if endpoint != "" {
+		config = config.WithEndpoint(endpoint)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3ObjectStore{
+		bucket:   bucket,
+		prefix:   prefix,
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+	}, nil
+}
+
+// Put uploads the object, using a multipart upload for large files.
+func (s *s3ObjectStore) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	_, err := s.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectPath(s.prefix, key)),
+		Body:   r,
+	})
+	return err
+}
+
+func (s *s3ObjectStore) GetRange(ctx context.Context, key string, off, n int64) ([]byte, error) {
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectPath(s.prefix, key)),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", off, off+n-1)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	b, err := ioutil.ReadAll(out.Body)
+	if err != nil {
+		return nil, err
+	} else if int64(len(b)) != n {
+		return nil, fmt.Errorf("object %s: read %d bytes at offset %d, expected %d", key, len(b), off, n)
+	}
+	return b, nil
+}
+
+func (s *s3ObjectStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(objectPath(s.prefix, key)),
+	})
+	return err
+}
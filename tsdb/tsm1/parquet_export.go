@@ -0,0 +1,512 @@
+package tsm1
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/parquet"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// ParquetExport exports the data held in the TSM files of a data directory to
+// Apache Parquet files, so that it can be loaded by analytics tools.
+//
+// A file is written for each bucket, measurement and day (UTC) with data, to
+// <OutputDir>/bucket=<id>/measurement=<name>/day=<yyyy-mm-dd>/data.parquet.
+// Each row holds the values of the fields of a series at a single time, with
+// a column for the time, each tag and each field of the measurement. Tags and
+// fields missing from a row are null.
+type ParquetExport struct {
+	Stdout io.Writer
+
+	Dir       string
+	OutputDir string
+
+	OrgID, BucketID *influxdb.ID // Export only the data of the org or bucket.
+	Start, End      int64        // Export only the values in [Start, End].
+
+	// RowGroupSize is the number of rows of each file buffered in memory
+	// before they are written. Zero uses parquet.DefaultRowGroupSize.
+	RowGroupSize int
+}
+
+// NewParquetExport returns a new instance of ParquetExport exporting all of
+// the data in dir to outputDir.
+func NewParquetExport(dir, outputDir string) *ParquetExport {
+	return &ParquetExport{
+		Stdout:    ioutil.Discard,
+		Dir:       dir,
+		OutputDir: outputDir,
+		Start:     math.MinInt64,
+		End:       math.MaxInt64,
+	}
+}
+
+// ParquetExportFile describes a Parquet file written by an export.
+type ParquetExportFile struct {
+	Path string
+	Rows int64
+}
+
+// Run exports the data, returning the files written.
+func (e *ParquetExport) Run() ([]ParquetExportFile, error) {
+	if e.BucketID != nil && e.OrgID == nil {
+		return nil, errors.New("org id must be set to export a bucket")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(e.Dir, "*."+TSMFileExtension))
+	if err != nil {
+		return nil, err
+	}
+
+	// Files are read in generation order, so that newer values overwrite
+	// older ones.
+	sort.Strings(paths)
+	var readers []*TSMReader
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r, err := NewTSMReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("cannot read %s: %v", path, err)
+		}
+		readers = append(readers, r)
+	}
+
+	var prefix []byte
+	if e.OrgID != nil {
+		name := tsdb.EncodeName(*e.OrgID, 0)
+		prefix = name[:8]
+		if e.BucketID != nil {
+			name = tsdb.EncodeName(*e.OrgID, *e.BucketID)
+			prefix = name[:]
+		}
+	}
+
+	// The schema of each measurement is found from the indexes first, so that
+	// every file of the measurement has the same columns.
+	schemas := make(map[string]*parquetSchema)
+	if err := mergeKeys(readers, prefix, func(key []byte, typ byte) error {
+		k, ok := parseExportKey(key)
+		if !ok {
+			return nil
+		}
+		s := schemas[k.schemaKey()]
+		if s == nil {
+			s = newParquetSchema()
+			schemas[k.schemaKey()] = s
+		}
+		return s.add(k, typ)
+	}); err != nil {
+		return nil, err
+	}
+
+	w := &parquetExportWriter{
+		export:  e,
+		schemas: schemas,
+		writers: make(map[string]*parquetFileWriter),
+	}
+	if err := mergeKeys(readers, prefix, func(key []byte, typ byte) error {
+		k, ok := parseExportKey(key)
+		if !ok {
+			return nil
+		}
+
+		var values Values
+		for _, r := range readers {
+			if !r.Contains(key) || !r.OverlapsTimeRange(e.Start, e.End) {
+				continue
+			}
+			vs, err := r.ReadAll(key)
+			if err != nil {
+				return err
+			}
+			values = append(values, vs...)
+		}
+		values = values.Deduplicate().Include(e.Start, e.End)
+		return w.add(k, values)
+	}); err != nil {
+		w.closeWriters()
+		return nil, err
+	}
+	if err := w.flush(); err != nil {
+		w.closeWriters()
+		return nil, err
+	}
+	if err := w.closeWriters(); err != nil {
+		return nil, err
+	}
+	return w.files, nil
+}
+
+// mergeKeys calls fn with each distinct key beginning with prefix in the
+// readers, in order, along with its block type.
+func mergeKeys(readers []*TSMReader, prefix []byte, fn func(key []byte, typ byte) error) error {
+	var iters []TSMIterator
+	next := func(itr TSMIterator) bool {
+		return itr.Next() && bytes.HasPrefix(itr.Key(), prefix)
+	}
+	for _, r := range readers {
+		if itr := r.Iterator(prefix); next(itr) {
+			iters = append(iters, itr)
+		} else if err := itr.Err(); err != nil {
+			return err
+		}
+	}
+
+	for len(iters) > 0 {
+		min := iters[0]
+		for _, itr := range iters[1:] {
+			if bytes.Compare(itr.Key(), min.Key()) < 0 {
+				min = itr
+			}
+		}
+		key := append([]byte(nil), min.Key()...)
+		if err := fn(key, min.Type()); err != nil {
+			return err
+		}
+
+		remaining := iters[:0]
+		for _, itr := range iters {
+			if !bytes.Equal(itr.Key(), key) || next(itr) {
+				remaining = append(remaining, itr)
+			} else if err := itr.Err(); err != nil {
+				return err
+			}
+		}
+		iters = remaining
+	}
+	return nil
+}
+
+// exportKey is a TSM key parsed for export.
+type exportKey struct {
+	bucketID    influxdb.ID
+	measurement string
+	tags        models.Tags // excludes the measurement and field tags
+	field       string
+
+	// series identifies the tags of the key, so that the keys of every field
+	// of the same tags can be found. Those keys are adjacent, as the field tag
+	// sorts after all others.
+	series []byte
+}
+
+// parseExportKey parses key, returning false if it is not the key of a
+// field of a measurement in a bucket.
+func parseExportKey(key []byte) (exportKey, bool) {
+	if len(key) < bucketPrefixSize {
+		return exportKey{}, false
+	}
+	seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+	_, tags := models.ParseKeyBytes(seriesKey)
+
+	k := exportKey{field: string(field)}
+	_, k.bucketID = tsdb.DecodeNameSlice(key[:bucketPrefixSize])
+	for _, t := range tags {
+		switch string(t.Key) {
+		case models.MeasurementTagKey:
+			k.measurement = string(t.Value)
+		case models.FieldKeyTagKey:
+		default:
+			k.tags = append(k.tags, t)
+		}
+	}
+	if k.measurement == "" || k.field == "" {
+		return exportKey{}, false
+	}
+
+	k.series = seriesKey
+	if i := bytes.LastIndex(seriesKey, []byte(","+models.FieldKeyTagKey+"=")); i >= 0 {
+		k.series = seriesKey[:i]
+	}
+	return k, true
+}
+
+// schemaKey returns the key of the schema of the key's measurement.
+func (k exportKey) schemaKey() string {
+	return k.bucketID.String() + "/" + k.measurement
+}
+
+// parquetSchema holds the columns of the files of a measurement.
+type parquetSchema struct {
+	tags   map[string]struct{}
+	fields map[string]byte // block type, keyed by field name
+
+	columns    []parquet.Column
+	tagIndex   map[string]int // column index, keyed by tag key
+	fieldIndex map[string]int // column index, keyed by field name
+}
+
+func newParquetSchema() *parquetSchema {
+	return &parquetSchema{
+		tags:   make(map[string]struct{}),
+		fields: make(map[string]byte),
+	}
+}
+
+// add adds the tags and field of k, whose values are of type typ.
+func (s *parquetSchema) add(k exportKey, typ byte) error {
+	for _, t := range k.tags {
+		s.tags[string(t.Key)] = struct{}{}
+	}
+	if prev, ok := s.fields[k.field]; ok && prev != typ {
+		return fmt.Errorf("field type conflict: field %q of measurement %q is both %s and %s",
+			k.field, k.measurement, BlockTypeName(prev), BlockTypeName(typ))
+	}
+	s.fields[k.field] = typ
+	return nil
+}
+
+// build sets the columns of the schema: the time, followed by the tags and
+// then the fields, each in order of name. A tag or field whose name is
+// already taken by another column is given a numeric suffix.
+func (s *parquetSchema) build() {
+	if s.columns != nil {
+		return
+	}
+
+	used := map[string]struct{}{"time": {}}
+	name := func(n string) string {
+		if _, ok := used[n]; !ok {
+			used[n] = struct{}{}
+			return n
+		}
+		for i := 1; ; i++ {
+			if _, ok := used[n+"_"+strconv.Itoa(i)]; !ok {
+				n = n + "_" + strconv.Itoa(i)
+				used[n] = struct{}{}
+				return n
+			}
+		}
+	}
+
+	s.columns = []parquet.Column{{Name: "time", Kind: parquet.Timestamp}}
+	s.tagIndex = make(map[string]int, len(s.tags))
+	for _, t := range sortedKeys(s.tags) {
+		s.tagIndex[t] = len(s.columns)
+		s.columns = append(s.columns, parquet.Column{Name: name(t), Kind: parquet.String, Optional: true})
+	}
+
+	s.fieldIndex = make(map[string]int, len(s.fields))
+	fields := make([]string, 0, len(s.fields))
+	for f := range s.fields {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		kind := parquet.Double
+		switch s.fields[f] {
+		case BlockInteger:
+			kind = parquet.Int64
+		case BlockUnsigned:
+			kind = parquet.Uint64
+		case BlockBoolean:
+			kind = parquet.Boolean
+		case BlockString:
+			kind = parquet.String
+		}
+		s.fieldIndex[f] = len(s.columns)
+		s.columns = append(s.columns, parquet.Column{Name: name(f), Kind: kind, Optional: true})
+	}
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parquetFileWriter writes the rows of a single file.
+type parquetFileWriter struct {
+	path string
+	f    *os.File
+	bw   *bufio.Writer
+	w    *parquet.Writer
+	rows int64
+}
+
+// parquetExportWriter writes the values of each field to the files of their
+// measurement and day.
+type parquetExportWriter struct {
+	export  *ParquetExport
+	schemas map[string]*parquetSchema
+	files   []ParquetExportFile
+
+	// The open files of the current measurement, keyed by day.
+	schemaKey string
+	writers   map[string]*parquetFileWriter
+
+	// The key and values of each field of the current series.
+	key    exportKey
+	fields []exportField
+}
+
+type exportField struct {
+	name   string
+	values Values
+}
+
+// add adds the values of the field of k. The fields of a series are
+// buffered until the next series is added, so that they can be combined into
+// rows.
+func (w *parquetExportWriter) add(k exportKey, values Values) error {
+	if len(w.fields) > 0 && (k.bucketID != w.key.bucketID || !bytes.Equal(k.series, w.key.series)) {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	w.key = k
+	if len(values) > 0 {
+		w.fields = append(w.fields, exportField{name: k.field, values: values})
+	}
+	return nil
+}
+
+// flush writes the rows of the current series.
+func (w *parquetExportWriter) flush() error {
+	if len(w.fields) == 0 {
+		return nil
+	}
+	defer func() { w.fields = w.fields[:0] }()
+
+	// The files of the previous measurement are complete once the keys of
+	// another are reached, as the measurement tag sorts first.
+	if sk := w.key.schemaKey(); sk != w.schemaKey {
+		if err := w.closeWriters(); err != nil {
+			return err
+		}
+		w.schemaKey = sk
+	}
+	s := w.schemas[w.schemaKey]
+	s.build()
+
+	row := make([]interface{}, len(s.columns))
+	for _, t := range w.key.tags {
+		row[s.tagIndex[string(t.Key)]] = string(t.Value)
+	}
+
+	// Merge the values of the fields by time.
+	pos := make([]int, len(w.fields))
+	for {
+		ts := int64(math.MaxInt64)
+		done := true
+		for i, f := range w.fields {
+			if pos[i] < len(f.values) {
+				done = false
+				if t := f.values[pos[i]].UnixNano(); t < ts {
+					ts = t
+				}
+			}
+		}
+		if done {
+			return nil
+		}
+
+		row[0] = ts
+		for i, f := range w.fields {
+			col := s.fieldIndex[f.name]
+			row[col] = nil
+			if pos[i] < len(f.values) && f.values[pos[i]].UnixNano() == ts {
+				row[col] = f.values[pos[i]].Value()
+				pos[i]++
+			}
+		}
+
+		fw, err := w.writer(s, time.Unix(0, ts).UTC().Format("2006-01-02"))
+		if err != nil {
+			return err
+		}
+		if err := fw.w.Write(row); err != nil {
+			return fmt.Errorf("cannot write to %s: %v", fw.path, err)
+		}
+		fw.rows++
+	}
+}
+
+// writer returns the writer of the file of the current measurement for day,
+// creating it if needed.
+func (w *parquetExportWriter) writer(s *parquetSchema, day string) (*parquetFileWriter, error) {
+	if fw := w.writers[day]; fw != nil {
+		return fw, nil
+	}
+
+	dir := filepath.Join(w.export.OutputDir,
+		"bucket="+w.key.bucketID.String(),
+		"measurement="+url.PathEscape(w.key.measurement),
+		"day="+day)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "data.parquet")
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fw := &parquetFileWriter{path: path, f: f, bw: bufio.NewWriter(f)}
+	if fw.w, err = parquet.NewWriter(fw.bw, s.columns); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if w.export.RowGroupSize > 0 {
+		fw.w.RowGroupSize = w.export.RowGroupSize
+	}
+	w.writers[day] = fw
+	return fw, nil
+}
+
+// closeWriters completes the files of the current measurement.
+func (w *parquetExportWriter) closeWriters() error {
+	days := make([]string, 0, len(w.writers))
+	for day := range w.writers {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	for _, day := range days {
+		fw := w.writers[day]
+		delete(w.writers, day)
+
+		err := fw.w.Close()
+		if err == nil {
+			err = fw.bw.Flush()
+		}
+		if e := fw.f.Close(); err == nil {
+			err = e
+		}
+		if err != nil {
+			return fmt.Errorf("cannot write %s: %v", fw.path, err)
+		}
+
+		w.files = append(w.files, ParquetExportFile{Path: fw.path, Rows: fw.rows})
+		if w.export.Stdout != nil {
+			fmt.Fprintf(w.export.Stdout, "%s\t%d rows\n", fw.path, fw.rows)
+		}
+	}
+	return nil
+}
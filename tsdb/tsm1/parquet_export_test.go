@@ -0,0 +1,127 @@
+package tsm1_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestParquetExport(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	out := MustTempDir()
+	defer os.RemoveAll(out)
+
+	key := func(bucket influxdb.ID, measurement, host, field string) string {
+		name := tsdb.EncodeName(1, bucket)
+		tags := models.NewTags(map[string]string{
+			models.MeasurementTagKey: measurement,
+			models.FieldKeyTagKey:    field,
+			"host":                   host,
+		})
+		return string(tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), field))
+	}
+
+	day := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC).UnixNano()
+	next := day + int64(24*time.Hour)
+	MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		key(2, "cpu", "a", "usage"): {tsm1.NewValue(day, 1.5), tsm1.NewValue(next, 2.5)},
+		key(2, "cpu", "a", "count"): {tsm1.NewValue(day, int64(1))},
+		key(2, "cpu", "b", "usage"): {tsm1.NewValue(day+1, 3.5)},
+		key(2, "mem", "a", "free"):  {tsm1.NewValue(day, uint64(10))},
+		key(3, "cpu", "a", "usage"): {tsm1.NewValue(day, 4.5)},
+	})
+	MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		// Overwrites the value of the first file.
+		key(2, "cpu", "a", "count"): {tsm1.NewValue(day, int64(2)), tsm1.NewValue(day+2, int64(3))},
+	})
+
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	export := tsm1.NewParquetExport(dir, out)
+	export.OrgID = &orgID
+	export.BucketID = &bucketID
+	files, err := export.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := func(measurement, day string) string {
+		return filepath.Join(out, "bucket="+bucketID.String(), "measurement="+measurement, "day="+day, "data.parquet")
+	}
+	exp := []tsm1.ParquetExportFile{
+		{Path: path("cpu", "2019-06-01"), Rows: 3}, // a at day and day+2, b at day+1
+		{Path: path("cpu", "2019-06-02"), Rows: 1},
+		{Path: path("mem", "2019-06-01"), Rows: 1},
+	}
+	if !cmp.Equal(files, exp) {
+		t.Fatalf("unexpected files: %v", cmp.Diff(files, exp))
+	}
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.HasPrefix(b, []byte("PAR1")) || !bytes.HasSuffix(b, []byte("PAR1")) {
+			t.Fatalf("%s is not a Parquet file", f.Path)
+		}
+		for _, column := range []string{"time", "host"} {
+			if !bytes.Contains(b, []byte(column)) {
+				t.Fatalf("%s has no %q column", f.Path, column)
+			}
+		}
+	}
+
+	// Restricting the time range excludes the second day.
+	out2 := MustTempDir()
+	defer os.RemoveAll(out2)
+	export = tsm1.NewParquetExport(dir, out2)
+	export.End = next - 1
+	files, err = export.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(files), 3; got != exp {
+		t.Fatalf("unexpected number of files: got %d, exp %d", got, exp)
+	}
+
+	// A bucket cannot be exported without its org.
+	export = tsm1.NewParquetExport(dir, out2)
+	export.BucketID = &bucketID
+	if _, err := export.Run(); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestParquetExport_FieldTypeConflict(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	out := MustTempDir()
+	defer os.RemoveAll(out)
+
+	name := tsdb.EncodeName(1, 2)
+	key := func(host string) string {
+		tags := models.NewTags(map[string]string{
+			models.MeasurementTagKey: "cpu",
+			models.FieldKeyTagKey:    "value",
+			"host":                   host,
+		})
+		return string(tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), "value"))
+	}
+	MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		key("a"): {tsm1.NewValue(0, 1.5)},
+		key("b"): {tsm1.NewValue(0, "x")},
+	})
+
+	if _, err := tsm1.NewParquetExport(dir, out).Run(); err == nil {
+		t.Fatal("expected error")
+	}
+}
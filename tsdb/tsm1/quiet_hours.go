@@ -0,0 +1,76 @@
+package tsm1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// quietWindow is a window of the day, as minutes since midnight. A window
+// whose end is before its start runs past midnight.
+type quietWindow struct {
+	start, end int
+}
+
+// QuietHours are windows of the day during which lower priority compactions
+// are not started.
+type QuietHours []quietWindow
+
+// ParseQuietHours parses windows of the form "HH:MM-HH:MM". A window such as
+// "22:00-06:00" runs past midnight.
+func ParseQuietHours(windows []string) (QuietHours, error) {
+	var q QuietHours
+	for _, s := range windows {
+		parts := strings.Split(strings.TrimSpace(s), "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid quiet hours %q: expected HH:MM-HH:MM", s)
+		}
+
+		start, err := parseClock(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours %q: %v", s, err)
+		}
+		end, err := parseClock(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid quiet hours %q: %v", s, err)
+		} else if start == end {
+			return nil, fmt.Errorf("invalid quiet hours %q: window is empty", s)
+		}
+		q = append(q, quietWindow{start: start, end: end})
+	}
+	return q, nil
+}
+
+// parseClock parses a time of day as HH:MM, returning minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains returns true if the time of day of t, in its location, is within
+// one of the windows.
+func (q QuietHours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	for _, w := range q {
+		if w.start < w.end {
+			if m >= w.start && m < w.end {
+				return true
+			}
+		} else if m >= w.start || m < w.end {
+			return true
+		}
+	}
+	return false
+}
+
+// Strings returns the windows in the form accepted by ParseQuietHours.
+func (q QuietHours) Strings() []string {
+	a := make([]string, len(q))
+	for i, w := range q {
+		a[i] = fmt.Sprintf("%02d:%02d-%02d:%02d", w.start/60, w.start%60, w.end/60, w.end%60)
+	}
+	return a
+}
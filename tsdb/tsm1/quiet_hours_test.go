@@ -0,0 +1,50 @@
+package tsm1_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	q, err := tsm1.ParseQuietHours([]string{"09:00-17:30", " 22:00 - 06:00 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, exp := q.Strings(), []string{"09:00-17:30", "22:00-06:00"}; !reflect.DeepEqual(got, exp) {
+		t.Fatalf("got %v, expected %v", got, exp)
+	}
+
+	for _, tt := range []struct {
+		clock string
+		exp   bool
+	}{
+		{clock: "08:59", exp: false},
+		{clock: "09:00", exp: true},
+		{clock: "17:29", exp: true},
+		{clock: "17:30", exp: false},
+		{clock: "23:15", exp: true},
+		{clock: "00:00", exp: true},
+		{clock: "05:59", exp: true},
+		{clock: "06:00", exp: false},
+	} {
+		ts, err := time.Parse("15:04", tt.clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := q.Contains(ts); got != tt.exp {
+			t.Errorf("Contains(%s) = %v, expected %v", tt.clock, got, tt.exp)
+		}
+	}
+}
+
+func TestParseQuietHours_Invalid(t *testing.T) {
+	for _, s := range []string{"09:00", "9am-5pm", "09:00-25:00", "10:00-10:00"} {
+		if _, err := tsm1.ParseQuietHours([]string{s}); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
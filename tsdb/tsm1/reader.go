@@ -40,6 +40,9 @@ type TSMReader struct {
 
 	// deleteMu limits concurrent deletes
 	deleteMu sync.Mutex
+
+	// tier is used to fetch blocks if the file has been offloaded.
+	tier *ObjectTier
 }
 
 type tsmReaderOption func(*TSMReader)
@@ -72,10 +75,24 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	}
 	t.size = stat.Size()
 	t.lastModified = stat.ModTime().UnixNano()
-	t.accessor = &mmapAccessor{
-		logger:       t.logger,
-		f:            f,
-		mmapWillNeed: t.madviseWillNeed,
+
+	if stub, err := isStubFile(f); err != nil {
+		return nil, err
+	} else if stub {
+		if t.tier == nil {
+			return nil, ErrObjectTierRequired
+		}
+		t.accessor = &remoteAccessor{
+			logger: t.logger,
+			tier:   t.tier,
+			f:      f,
+		}
+	} else {
+		t.accessor = &mmapAccessor{
+			logger:       t.logger,
+			f:            f,
+			mmapWillNeed: t.madviseWillNeed,
+		}
 	}
 
 	index, err := t.accessor.init()
@@ -286,6 +303,10 @@ func (t *TSMReader) remove() error {
 	if err := t.tombstoner.Delete(); err != nil {
 		return err
 	}
+
+	if r, ok := t.accessor.(*remoteAccessor); ok {
+		return r.removeObject()
+	}
 	return nil
 }
 
@@ -450,6 +471,14 @@ func (t *TSMReader) TombstoneRange(key []byte, buf []TimeRange) []TimeRange {
 	return tr
 }
 
+// TombstonedBlocks calls fn with the key and size of each block in the file
+// whose values have all been deleted.
+func (t *TSMReader) TombstonedBlocks(fn func(key []byte, size uint32)) {
+	t.mu.RLock()
+	t.index.TombstonedBlocks(fn)
+	t.mu.RUnlock()
+}
+
 // Stats returns the FileStat for the TSMReader's underlying file.
 func (t *TSMReader) Stats() FileStat {
 	minTime, maxTime := t.index.TimeRange()
@@ -463,6 +492,7 @@ func (t *TSMReader) Stats() FileStat {
 		MinKey:       minKey,
 		MaxKey:       maxKey,
 		HasTombstone: t.tombstoner.HasTombstones(),
+		Offloaded:    t.Offloaded(),
 	}
 }
 
@@ -73,6 +73,10 @@ type TSMIndex interface {
 	// TombstoneRange returns ranges of time that are deleted for the given key.
 	TombstoneRange(key []byte, buf []TimeRange) []TimeRange
 
+	// TombstonedBlocks calls fn with the key and size of each block in the
+	// file whose values have all been deleted.
+	TombstonedBlocks(fn func(key []byte, size uint32))
+
 	// KeyRange returns the min and max keys in the file.
 	KeyRange() ([]byte, []byte)
 
@@ -642,6 +646,51 @@ func (d *indirectIndex) TombstoneRange(key []byte, buf []TimeRange) []TimeRange
 	return rs
 }
 
+// TombstonedBlocks calls fn with the key and size of each block in the file
+// whose values have all been deleted. Deleted keys are no longer in the
+// offsets, so the raw index is walked to find them.
+func (d *indirectIndex) TombstonedBlocks(fn func(key []byte, size uint32)) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var (
+		entries []IndexEntry
+		buf     []TimeRange
+		err     error
+	)
+	iter := d.ro.Iterator()
+	for i, n := uint32(0), d.b.len(); i < n; {
+		b := d.b.access(i, 0)
+		key := readKey(b)
+		if entries, err = readEntries(b[2+len(key):], entries); err != nil {
+			return
+		}
+		i += 2 + uint32(len(key)) + indexTypeSize + indexCountSize + uint32(len(entries))*indexEntrySize
+
+		if exact, _ := iter.Seek(key, &d.b); !exact {
+			for _, e := range entries {
+				fn(key, e.Size)
+			}
+			continue
+		}
+
+		fromMap := d.tombstones[iter.Offset()]
+		buf = d.prefixTombstones.Search(key, buf[:0])
+		if len(fromMap) == 0 && len(buf) == 0 {
+			continue
+		}
+		if len(buf) > 1 {
+			sort.Slice(buf, func(i, j int) bool { return buf[i].Less(buf[j]) })
+		}
+		for j := range entries {
+			merger := timeRangeMerger{fromMap: fromMap, fromPrefix: buf, used: true}
+			if timeRangesCoverEntries(merger, entries[j:j+1]) {
+				fn(key, entries[j].Size)
+			}
+		}
+	}
+}
+
 // Contains return true if the given key exists in the index.
 func (d *indirectIndex) Contains(key []byte) bool {
 	d.mu.RLock()
@@ -0,0 +1,215 @@
+package tsm1
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"sync"
+
+	"github.com/influxdata/influxdb/pkg/fs"
+	"go.uber.org/zap"
+)
+
+// remoteAccessor is a block accessor for a TSM file that has been offloaded
+// to an object store. The index is read from a local stub file and blocks
+// are fetched from the object store as they are needed.
+type remoteAccessor struct {
+	logger *zap.Logger
+	tier   *ObjectTier
+
+	mu    sync.RWMutex
+	b     []byte // mmap of the stub file
+	f     *os.File
+	_path string
+
+	// object is the key of the TSM file in the object store.
+	object string
+
+	index *indirectIndex
+}
+
+func (m *remoteAccessor) init() (*indirectIndex, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m._path = m.f.Name()
+
+	stat, err := m.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	m.b, err = mmap(m.f, 0, int(stat.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	object, indexStart, err := readStubHeader(m.b)
+	if err != nil {
+		return nil, err
+	}
+	m.object = object
+
+	m.index = NewIndirectIndex()
+	if err := m.index.UnmarshalBinary(m.b[indexStart : len(m.b)-8]); err != nil {
+		return nil, err
+	}
+	m.index.logger = m.logger
+
+	return m.index, nil
+}
+
+// block returns the checksum and block for entry, fetching it from the
+// object store if it is not cached.
+func (m *remoteAccessor) block(entry *IndexEntry) ([]byte, error) {
+	return m.fetchBlock(context.Background(), entry)
+}
+
+// fetchBlock returns the checksum and block for entry, fetching it from the
+// object store within the fetch timeout of the tier if it is not cached, or
+// until ctx is cancelled.
+func (m *remoteAccessor) fetchBlock(ctx context.Context, entry *IndexEntry) ([]byte, error) {
+	m.mu.RLock()
+	closed := m.b == nil
+	m.mu.RUnlock()
+	if closed {
+		return nil, ErrTSMClosed
+	}
+
+	key := blockCacheKey{object: m.object, offset: entry.Offset}
+	if b, ok := m.tier.cache.get(key); ok {
+		return b, nil
+	}
+
+	if m.tier.FetchTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.tier.FetchTimeout)
+		defer cancel()
+	}
+
+	b, err := m.tier.Store.GetRange(ctx, m.object, entry.Offset, int64(entry.Size))
+	if err != nil {
+		return nil, fmt.Errorf("fetch block from %s: %v", m.object, err)
+	} else if len(b) < 4 || len(b) != int(entry.Size) {
+		return nil, fmt.Errorf("fetch block from %s: short read", m.object)
+	} else if binary.BigEndian.Uint32(b[:4]) != crc32.ChecksumIEEE(b[4:]) {
+		return nil, fmt.Errorf("fetch block from %s: checksum mismatch at offset %d", m.object, entry.Offset)
+	}
+
+	m.tier.cache.put(key, b)
+	return b, nil
+}
+
+func (m *remoteAccessor) read(key []byte, timestamp int64) ([]Value, error) {
+	entry := m.index.Entry(key, timestamp)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return m.readBlock(entry, nil)
+}
+
+func (m *remoteAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, error) {
+	b, err := m.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(b[4:], values)
+}
+
+func (m *remoteAccessor) readBytes(entry *IndexEntry, _ []byte) (uint32, []byte, error) {
+	b, err := m.block(entry)
+	if err != nil {
+		return 0, nil, err
+	}
+	return binary.BigEndian.Uint32(b[:4]), b[4:], nil
+}
+
+// readAll returns all values for a key in all blocks.
+func (m *remoteAccessor) readAll(key []byte) ([]Value, error) {
+	blocks, err := m.index.ReadEntries(key, nil)
+	if len(blocks) == 0 || err != nil {
+		return nil, err
+	}
+
+	tombstones := m.index.TombstoneRange(key, nil)
+
+	var temp []Value
+	var values []Value
+	for i := range blocks {
+		block := &blocks[i]
+
+		var skip bool
+		for _, t := range tombstones {
+			// Should we skip this block because it contains points that have been deleted
+			if t.Min <= block.MinTime && t.Max >= block.MaxTime {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		temp, err = m.readBlock(block, temp[:0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out any values that were deleted
+		for _, t := range tombstones {
+			temp = Values(temp).Exclude(t.Min, t.Max)
+		}
+
+		values = append(values, temp...)
+	}
+
+	return values, nil
+}
+
+func (m *remoteAccessor) rename(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := fs.RenameFileWithReplacement(m._path, path); err != nil {
+		return err
+	}
+	m._path = path
+	return nil
+}
+
+func (m *remoteAccessor) path() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m._path
+}
+
+// free is a no-op as cached blocks are shared by all offloaded files and
+// evicted by the cache itself.
+func (m *remoteAccessor) free() error { return nil }
+
+func (m *remoteAccessor) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.b == nil {
+		return nil
+	}
+
+	err := munmap(m.b)
+	if err != nil {
+		return err
+	}
+
+	m.b = nil
+	return m.f.Close()
+}
+
+// removeObject deletes the offloaded TSM file from the object store.
+func (m *remoteAccessor) removeObject() error {
+	m.tier.cache.evict(m.object)
+	return m.tier.Store.Delete(context.Background(), m.object)
+}
@@ -1225,7 +1225,9 @@ func TestCompacted_NotFull(t *testing.T) {
 		t.Fatalf("unexpected error reading block: %v", err)
 	}
 
-	if got, exp := BlockCount(block), 1; got != exp {
+	if got, err := BlockCount(block); err != nil {
+		t.Fatalf("unexpected error counting block: %v", err)
+	} else if exp := 1; got != exp {
 		t.Fatalf("block count mismatch: got %v, exp %v", got, exp)
 	}
 }
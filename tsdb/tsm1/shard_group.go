@@ -0,0 +1,147 @@
+package tsm1
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// ShardGroupStart returns the start of the shard group of duration d that
+// contains the timestamp ts. Shard groups are aligned to the Unix epoch.
+func ShardGroupStart(ts int64, d time.Duration) int64 {
+	n := int64(d)
+	if n <= 0 {
+		return ts
+	}
+	start := ts - ts%n
+	if start > ts {
+		start -= n
+	}
+	return start
+}
+
+// ShardGroupDurations holds the shard group duration of each bucket. Blocks
+// written for a bucket with a shard group duration never span more than one
+// shard group, so data can be expired a shard group at a time by dropping
+// whole blocks.
+type ShardGroupDurations struct {
+	mu        sync.RWMutex
+	durations map[influxdb.ID]time.Duration
+}
+
+// NewShardGroupDurations returns an empty set of shard group durations.
+func NewShardGroupDurations() *ShardGroupDurations {
+	return &ShardGroupDurations{durations: make(map[influxdb.ID]time.Duration)}
+}
+
+// Set sets the shard group duration of a bucket. A duration of zero stops
+// the bucket's data from being partitioned.
+func (s *ShardGroupDurations) Set(bucketID influxdb.ID, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d <= 0 {
+		delete(s.durations, bucketID)
+		return
+	}
+	s.durations[bucketID] = d
+}
+
+// Duration returns the shard group duration for the bucket of a series key,
+// or zero if it is not partitioned.
+func (s *ShardGroupDurations) Duration(key []byte) time.Duration {
+	if s == nil || len(key) < 16 {
+		return 0
+	}
+	_, bucketID := tsdb.DecodeNameSlice(key[:16])
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.durations[bucketID]
+}
+
+// partitionedBlock is a block produced by splitting a block at shard group
+// boundaries.
+type partitionedBlock struct {
+	minTime, maxTime int64
+	b                []byte
+}
+
+// partitioningKeyIterator splits the blocks read from a KeyIterator that
+// span more than one shard group of their bucket.
+type partitioningKeyIterator struct {
+	KeyIterator
+	durations *ShardGroupDurations
+
+	key     []byte
+	pending []partitionedBlock
+	err     error
+}
+
+func (k *partitioningKeyIterator) Next() bool {
+	if len(k.pending) > 1 {
+		k.pending = k.pending[1:]
+		return true
+	}
+	k.pending = k.pending[:0]
+
+	if !k.KeyIterator.Next() {
+		return false
+	}
+
+	var minTime, maxTime int64
+	var block []byte
+	k.key, minTime, maxTime, block, k.err = k.KeyIterator.Read()
+	if k.err != nil {
+		return true
+	}
+
+	d := k.durations.Duration(k.key)
+	if d <= 0 || ShardGroupStart(minTime, d) == ShardGroupStart(maxTime, d) {
+		k.pending = append(k.pending, partitionedBlock{minTime: minTime, maxTime: maxTime, b: block})
+		return true
+	}
+
+	values, err := DecodeBlock(block, nil)
+	if err != nil {
+		k.err = err
+		return true
+	}
+
+	for len(values) > 0 {
+		end := ShardGroupStart(values[0].UnixNano(), d) + int64(d)
+		i := 1
+		for i < len(values) && values[i].UnixNano() < end {
+			i++
+		}
+
+		b, err := Values(values[:i]).Encode(nil)
+		if err != nil {
+			k.err = err
+			return true
+		}
+		k.pending = append(k.pending, partitionedBlock{
+			minTime: values[0].UnixNano(),
+			maxTime: values[i-1].UnixNano(),
+			b:       b,
+		})
+		values = values[i:]
+	}
+	return true
+}
+
+func (k *partitioningKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	if k.err != nil {
+		return nil, 0, 0, nil, k.err
+	}
+	b := k.pending[0]
+	return k.key, b.minTime, b.maxTime, b.b, nil
+}
+
+func (k *partitioningKeyIterator) Err() error {
+	if k.err != nil {
+		return k.err
+	}
+	return k.KeyIterator.Err()
+}
@@ -0,0 +1,97 @@
+package tsm1_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestShardGroupStart(t *testing.T) {
+	for _, tt := range []struct {
+		ts, exp int64
+	}{
+		{ts: 0, exp: 0},
+		{ts: 99, exp: 0},
+		{ts: 100, exp: 100},
+		{ts: -1, exp: -100},
+		{ts: -100, exp: -100},
+		{ts: -101, exp: -200},
+	} {
+		if got := tsm1.ShardGroupStart(tt.ts, 100); got != tt.exp {
+			t.Errorf("got %d for %d, expected %d", got, tt.ts, tt.exp)
+		}
+	}
+}
+
+func TestCompactor_CompactFull_ShardGroups(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	name := tsdb.EncodeName(1, 2)
+	partitioned := string(name[:]) + ",host=A#!~#value"
+	other := tsdb.EncodeName(1, 3)
+	unpartitioned := string(other[:]) + ",host=A#!~#value"
+
+	var values []tsm1.Value
+	for i := 0; i < 250; i++ {
+		values = append(values, tsm1.NewValue(int64(i), float64(i)))
+	}
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		partitioned:   values[:150],
+		unpartitioned: values[:150],
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		partitioned:   values[150:],
+		unpartitioned: values[150:],
+	})
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.ShardGroups = tsm1.NewShardGroupDurations()
+	compactor.ShardGroups.Set(2, time.Duration(100))
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	} else if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	entries, err := r.ReadEntries([]byte(partitioned), nil)
+	if err != nil {
+		t.Fatal(err)
+	} else if got, exp := len(entries), 3; got != exp {
+		t.Fatalf("block length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, e := range entries {
+		if got, exp := tsm1.ShardGroupStart(e.MinTime, 100), int64(i*100); got != exp || tsm1.ShardGroupStart(e.MaxTime, 100) != exp {
+			t.Fatalf("block %d spans more than one shard group: min %d, max %d", i, e.MinTime, e.MaxTime)
+		}
+	}
+
+	if entries, err := r.ReadEntries([]byte(unpartitioned), nil); err != nil {
+		t.Fatal(err)
+	} else if got, exp := len(entries), 1; got != exp {
+		t.Fatalf("block length mismatch: got %v, exp %v", got, exp)
+	}
+
+	got, err := r.ReadAll([]byte(partitioned))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(got) != len(values) {
+		t.Fatalf("value length mismatch: got %v, exp %v", len(got), len(values))
+	}
+	for i, v := range got {
+		assertValueEqual(t, v, values[i])
+	}
+}
@@ -0,0 +1,435 @@
+package tsm1
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/fs"
+	"go.uber.org/zap"
+)
+
+const (
+	// StubMagicNumber is written as the first 4 bytes of a stub file, which
+	// replaces a TSM file that has been offloaded to an object store.
+	StubMagicNumber uint32 = 0x16D116D2
+
+	// StubVersion indicates the version of the stub file format.
+	StubVersion byte = 1
+
+	// offloadLevel is the lowest level of TSM file that is offloaded. Files
+	// at lower levels will be compacted again soon.
+	offloadLevel = 4
+)
+
+// ErrObjectTierRequired is returned when opening a stub file without an
+// object tier configured to fetch its blocks from.
+var ErrObjectTierRequired = fmt.Errorf("tsm file is offloaded to an object store, but no object tier is configured")
+
+// ObjectTier offloads cold TSM files to an ObjectStore, caching the blocks
+// that are read back from it.
+type ObjectTier struct {
+	Store ObjectStore
+
+	// ColdDuration is how long after its newest point a TSM file is offloaded.
+	ColdDuration time.Duration
+
+	// CheckInterval is how often the engine looks for files to offload.
+	CheckInterval time.Duration
+
+	// FetchTimeout is the maximum duration of fetching a block from Store.
+	// Zero disables the timeout.
+	FetchTimeout time.Duration
+
+	cache *blockCache
+}
+
+// NewObjectTier returns an ObjectTier for store configured by config.
+func NewObjectTier(store ObjectStore, config TieringConfig) *ObjectTier {
+	return &ObjectTier{
+		Store:         store,
+		ColdDuration:  time.Duration(config.ColdDuration),
+		CheckInterval: time.Duration(config.CheckInterval),
+		FetchTimeout:  time.Duration(config.FetchTimeout),
+		cache:         newBlockCache(uint64(config.CacheMaxMemorySize)),
+	}
+}
+
+// WithObjectTier sets the object tier used to read offloaded TSM files.
+var WithObjectTier = func(tier *ObjectTier) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.tier = tier
+	}
+}
+
+// Offloaded returns true if the file's blocks are stored in an object store.
+func (t *TSMReader) Offloaded() bool {
+	t.mu.RLock()
+	_, ok := t.accessor.(*remoteAccessor)
+	t.mu.RUnlock()
+	return ok
+}
+
+// fetchBlock fetches the block of entry into the block cache of the tier if
+// the file is offloaded, giving up when ctx is cancelled.
+func (t *TSMReader) fetchBlock(ctx context.Context, entry *IndexEntry) error {
+	t.mu.RLock()
+	a, ok := t.accessor.(*remoteAccessor)
+	t.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	_, err := a.fetchBlock(ctx, entry)
+	return err
+}
+
+// isStubFile returns true if f is a stub for an offloaded TSM file.
+func isStubFile(f *os.File) (bool, error) {
+	var b [4]byte
+	if _, err := f.ReadAt(b[:], 0); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("init: error reading magic number of file: %v", err)
+	}
+	return binary.BigEndian.Uint32(b[:]) == StubMagicNumber, nil
+}
+
+// writeStub writes a stub for the TSM file src, offloaded as object, to path.
+// A stub has the same index as the file it replaces, as the block offsets
+// within the index remain valid in the object:
+//
+// ┌────────┬─────────┬────────────┬────────┬─────────────┬─────────┬────────┐
+// │ Magic  │ Version │ Key Length │  Key   │ Object Size │  Index  │ Footer │
+// │4 bytes │ 1 byte  │  2 bytes   │N bytes │   8 bytes   │ N bytes │8 bytes │
+// └────────┴─────────┴────────────┴────────┴─────────────┴─────────┴────────┘
+//
+// The footer is the offset of the index within the stub.
+func writeStub(path string, src *os.File, size int64, object string) (err error) {
+	var buf [8]byte
+	if _, err := src.ReadAt(buf[:], size-8); err != nil {
+		return err
+	}
+	indexStart := int64(binary.BigEndian.Uint64(buf[:]))
+	if indexStart <= 0 || indexStart >= size-8 {
+		return fmt.Errorf("writeStub: invalid indexStart")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if e := f.Close(); err == nil {
+			err = e
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	header := make([]byte, 0, 15+len(object))
+	header = append(header, make([]byte, 5)...)
+	binary.BigEndian.PutUint32(header[0:4], StubMagicNumber)
+	header[4] = StubVersion
+	header = append(header, byte(len(object)>>8), byte(len(object)))
+	header = append(header, object...)
+	binary.BigEndian.PutUint64(buf[:], uint64(size))
+	header = append(header, buf[:]...)
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, io.NewSectionReader(src, indexStart, size-8-indexStart)); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint64(buf[:], uint64(len(header)))
+	if _, err := w.Write(buf[:]); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// readStubHeader returns the object key and index offset of the stub b.
+func readStubHeader(b []byte) (object string, indexStart int, err error) {
+	if len(b) < 23 {
+		return "", 0, fmt.Errorf("remoteAccessor: byte slice too small for stub")
+	}
+	if binary.BigEndian.Uint32(b[0:4]) != StubMagicNumber {
+		return "", 0, fmt.Errorf("can only read from stub file")
+	}
+	if b[4] != StubVersion {
+		return "", 0, fmt.Errorf("init: stub is version %b. expected %b", b[4], StubVersion)
+	}
+
+	n := int(binary.BigEndian.Uint16(b[5:7]))
+	if 7+n+8 > len(b)-8 {
+		return "", 0, fmt.Errorf("remoteAccessor: invalid object key length")
+	}
+	object = string(b[7 : 7+n])
+
+	indexStart = int(binary.BigEndian.Uint64(b[len(b)-8:]))
+	if indexStart != 7+n+8 {
+		return "", 0, fmt.Errorf("remoteAccessor: invalid indexStart")
+	}
+	return object, indexStart, nil
+}
+
+// OffloadColdFiles uploads fully compacted TSM files whose newest point is
+// before the given time to the object tier, replacing each with a stub. It
+// returns the number of files offloaded.
+func (f *FileStore) OffloadColdFiles(ctx context.Context, before int64) (int, error) {
+	if f.tier == nil {
+		return 0, nil
+	}
+
+	var paths []string
+	for _, stat := range f.Stats() {
+		if stat.Offloaded || stat.MaxTime >= before {
+			continue
+		}
+		if _, seq, err := f.parseFileName(stat.Path); err != nil || seq < offloadLevel {
+			continue
+		}
+		paths = append(paths, stat.Path)
+	}
+
+	var n int
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		start := time.Now()
+		if ok, err := f.offload(ctx, path); err != nil {
+			return n, err
+		} else if ok {
+			f.logger.Info("Offloaded TSM file", zap.String("path", path), zap.Duration("duration", time.Since(start)))
+			n++
+		}
+	}
+	return n, nil
+}
+
+// offload uploads the TSM file at path and replaces it with a stub. It
+// returns false if the file was removed, such as by a compaction, before it
+// could be replaced.
+func (f *FileStore) offload(ctx context.Context, path string) (bool, error) {
+	r := f.TSMReader(path)
+	if r == nil {
+		return false, nil
+	}
+	defer r.Unref()
+
+	src, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	defer src.Close()
+
+	stat, err := src.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	// Include the time in the object key as generations may be reused if the
+	// newest file is removed.
+	object := fmt.Sprintf("%s.%d", filepath.Base(path), time.Now().UnixNano())
+	if err := f.tier.Store.Put(ctx, object, io.NewSectionReader(src, 0, stat.Size()), stat.Size()); err != nil {
+		return false, fmt.Errorf("upload %s: %v", path, err)
+	}
+
+	stubPath := fmt.Sprintf("%s.%s", path, CompactionTempExtension)
+	replaced, err := false, writeStub(stubPath, src, stat.Size(), object)
+	if err == nil {
+		replaced, err = f.replaceWithStub(path, stubPath)
+	}
+
+	if !replaced {
+		os.Remove(stubPath)
+		if e := f.tier.Store.Delete(context.Background(), object); e != nil {
+			f.logger.Warn("Failed to delete offloaded TSM file", zap.String("object", object), zap.Error(e))
+		}
+	}
+	return replaced, err
+}
+
+// replaceWithStub renames the stub at stubPath over the TSM file at path and
+// swaps its reader for one that fetches blocks from the object tier. The old
+// reader is closed once it is no longer in use. It returns true if the stub
+// was moved into place.
+func (f *FileStore) replaceWithStub(path, stubPath string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	i := -1
+	for j, file := range f.files {
+		if file.Path() == path {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return false, nil
+	}
+
+	// give the observer a chance to process the file first.
+	if err := f.obs.FileFinishing(stubPath); err != nil {
+		return false, err
+	}
+	if err := fs.RenameFileWithReplacement(stubPath, path); err != nil {
+		return false, err
+	}
+	if err := fs.SyncDir(f.dir); err != nil {
+		return true, err
+	}
+
+	fd, err := os.Open(path)
+	if err != nil {
+		return true, err
+	}
+	r, err := NewTSMReader(fd,
+		WithMadviseWillNeed(f.tsmMMAPWillNeed),
+		WithTSMReaderLogger(f.logger),
+		WithObjectTier(f.tier))
+	if err != nil {
+		fd.Close()
+		return true, err
+	}
+	r.WithObserver(f.obs)
+
+	old := f.files[i]
+	f.files[i] = r
+	go func() {
+		// Close waits for any readers of the old file to finish.
+		if err := old.Close(); err != nil {
+			f.logger.Warn("Failed to close offloaded TSM file", zap.String("path", path), zap.Error(err))
+		}
+	}()
+
+	// Invalidate cached stats without treating the change as a new write.
+	f.lastFileStats = nil
+	f.lastModified = f.lastModified.Add(1)
+	return true, f.resetTracker()
+}
+
+// offloadColdFiles periodically offloads cold TSM files to the object tier
+// until quit is closed.
+func (e *Engine) offloadColdFiles(quit <-chan struct{}) {
+	tier := e.FileStore.tier
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	t := time.NewTicker(tier.CheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-quit:
+			return
+
+		case <-t.C:
+			before := time.Now().Add(-tier.ColdDuration).UnixNano()
+			if n, err := e.FileStore.OffloadColdFiles(ctx, before); err != nil && ctx.Err() == nil {
+				e.logger.Error("Failed to offload cold TSM files", zap.Int("offloaded", n), zap.Error(err))
+			} else if n > 0 {
+				e.logger.Info("Offloaded cold TSM files", zap.Int("offloaded", n))
+			}
+		}
+	}
+}
+
+type blockCacheKey struct {
+	object string
+	offset int64
+}
+
+type blockCacheEntry struct {
+	key blockCacheKey
+	b   []byte
+}
+
+// blockCache is an LRU cache of blocks fetched from an object store, bounded
+// by the total size of the blocks.
+type blockCache struct {
+	mu      sync.Mutex
+	maxSize uint64
+	size    uint64
+	lru     *list.List
+	blocks  map[blockCacheKey]*list.Element
+}
+
+func newBlockCache(maxSize uint64) *blockCache {
+	return &blockCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		blocks:  make(map[blockCacheKey]*list.Element),
+	}
+}
+
+func (c *blockCache) get(key blockCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.blocks[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*blockCacheEntry).b, true
+}
+
+func (c *blockCache) put(key blockCacheKey, b []byte) {
+	if uint64(len(b)) > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.blocks[key]; ok {
+		return
+	}
+	c.blocks[key] = c.lru.PushFront(&blockCacheEntry{key: key, b: b})
+	c.size += uint64(len(b))
+
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+}
+
+// evict removes all blocks of object from the cache.
+func (c *blockCache) evict(object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, e := range c.blocks {
+		if key.object == object {
+			c.remove(e)
+		}
+	}
+}
+
+func (c *blockCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*blockCacheEntry)
+	delete(c.blocks, entry.key)
+	c.size -= uint64(len(entry.b))
+}
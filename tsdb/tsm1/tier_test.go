@@ -0,0 +1,231 @@
+package tsm1_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/fs"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// MustWriteFullyCompactedTSM writes values to a level 4 TSM file, as written
+// by a full compaction.
+func MustWriteFullyCompactedTSM(dir string, gen int, values map[string][]tsm1.Value) string {
+	name := MustWriteTSM(dir, gen, values)
+	newName := filepath.Join(dir, tsm1.DefaultFormatFileName(gen, 4)+".tsm")
+	if err := fs.RenameFile(name, newName); err != nil {
+		panic(err)
+	}
+	return newName
+}
+
+func TestFileStore_OffloadColdFiles(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	objects := filepath.Join(dir, "objects")
+
+	store, err := tsm1.NewFileObjectStore(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tier := tsm1.NewObjectTier(store, tsm1.NewTieringConfig())
+
+	var cold, hot []tsm1.Value
+	for i := 0; i < 2500; i++ {
+		cold = append(cold, tsm1.NewValue(int64(i), float64(i)))
+		hot = append(hot, tsm1.NewValue(int64(10000+i), float64(i)))
+	}
+	coldFile := MustWriteFullyCompactedTSM(dir, 1, map[string][]tsm1.Value{"cpu": cold})
+	hotFile := MustWriteFullyCompactedTSM(dir, 2, map[string][]tsm1.Value{"mem": hot})
+	levelFile := MustWriteTSM(dir, 3, map[string][]tsm1.Value{"disk": cold})
+
+	fstore := tsm1.NewFileStore(dir)
+	fstore.WithObjectTier(tier)
+	if err := fstore.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { fstore.Close() }()
+
+	// Delete some of the cold data to ensure tombstones still apply once it
+	// has been offloaded.
+	if err := fstore.DeleteRange([][]byte{[]byte("cpu")}, 0, 99); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := fstore.OffloadColdFiles(context.Background(), 5000)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected number of files offloaded: got %d, exp 1", n)
+	}
+
+	for _, stat := range fstore.Stats() {
+		if exp := stat.Path == coldFile; stat.Offloaded != exp {
+			t.Fatalf("unexpected offloaded state for %s: got %v, exp %v", stat.Path, stat.Offloaded, exp)
+		}
+	}
+	if stat := fstore.Stats()[1]; stat.Path != hotFile || stat.Offloaded {
+		t.Fatalf("unexpected stats for hot file: %+v", stat)
+	}
+	if _, err := os.Stat(levelFile); err != nil {
+		t.Fatal(err)
+	}
+
+	objectFiles, err := ioutil.ReadDir(objects)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(objectFiles) != 1 {
+		t.Fatalf("unexpected number of objects: got %d, exp 1", len(objectFiles))
+	}
+	if local, err := os.Stat(coldFile); err != nil {
+		t.Fatal(err)
+	} else if local.Size() >= objectFiles[0].Size() {
+		t.Fatalf("expected stub to be smaller than the file: got %d, exp < %d", local.Size(), objectFiles[0].Size())
+	}
+
+	readCold := func(fstore *tsm1.FileStore) {
+		t.Helper()
+		r := fstore.TSMReader(coldFile)
+		if r == nil {
+			t.Fatal("expected reader for offloaded file")
+		}
+		defer r.Unref()
+
+		values, err := r.ReadAll([]byte("cpu"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := len(values), len(cold)-100; got != exp {
+			t.Fatalf("unexpected number of values: got %d, exp %d", got, exp)
+		}
+		for i, v := range values {
+			assertValueEqual(t, v, cold[i+100])
+		}
+	}
+	readCold(fstore)
+
+	// Reopening the file store reads the stub.
+	if err := fstore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fstore = tsm1.NewFileStore(dir)
+	fstore.WithObjectTier(tier)
+	if err := fstore.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	readCold(fstore)
+
+	// Offloaded files are not offloaded again.
+	if n, err := fstore.OffloadColdFiles(context.Background(), 5000); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("unexpected number of files offloaded: got %d, exp 0", n)
+	}
+
+	// Removing the stub removes the object.
+	if err := fstore.Replace([]string{coldFile}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if objectFiles, err := ioutil.ReadDir(objects); err != nil {
+		t.Fatal(err)
+	} else if len(objectFiles) != 0 {
+		t.Fatalf("unexpected number of objects: got %d, exp 0", len(objectFiles))
+	}
+}
+
+// stallingObjectStore is an ObjectStore whose GetRange does not return until
+// its context is done.
+type stallingObjectStore struct {
+	tsm1.ObjectStore
+}
+
+func (s *stallingObjectStore) GetRange(ctx context.Context, key string, off, n int64) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFileStore_OffloadedFetchTimeout(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	store, err := tsm1.NewFileObjectStore(filepath.Join(dir, "objects"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tier := tsm1.NewObjectTier(&stallingObjectStore{ObjectStore: store}, tsm1.NewTieringConfig())
+
+	var cold []tsm1.Value
+	for i := 0; i < 100; i++ {
+		cold = append(cold, tsm1.NewValue(int64(i), float64(i)))
+	}
+	coldFile := MustWriteFullyCompactedTSM(dir, 1, map[string][]tsm1.Value{"cpu": cold})
+
+	fstore := tsm1.NewFileStore(dir)
+	fstore.WithObjectTier(tier)
+	if err := fstore.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer fstore.Close()
+
+	if n, err := fstore.OffloadColdFiles(context.Background(), 5000); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected number of files offloaded: got %d, exp 1", n)
+	}
+
+	// Reads without a context are bounded by the fetch timeout.
+	tier.FetchTimeout = 10 * time.Millisecond
+	r := fstore.TSMReader(coldFile)
+	if _, err := r.ReadAll([]byte("cpu")); err == nil {
+		t.Fatal("expected error fetching block from stalled object store")
+	}
+	r.Unref()
+
+	// Cursors give up when their context is cancelled.
+	tier.FetchTimeout = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := fstore.KeyCursor(ctx, []byte("cpu"), 0, true)
+	defer c.Close()
+	if _, err := c.ReadFloatBlock(&[]tsm1.FloatValue{}); err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatalf("unexpected error: got %v, exp %v", err, context.Canceled)
+	}
+}
+
+func TestFileStore_Open_OffloadedWithoutTier(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	store, err := tsm1.NewFileObjectStore(filepath.Join(dir, "objects"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := MustWriteFullyCompactedTSM(dir, 1, map[string][]tsm1.Value{"cpu": {tsm1.NewValue(0, 1.0)}})
+
+	fstore := tsm1.NewFileStore(dir)
+	fstore.WithObjectTier(tsm1.NewObjectTier(store, tsm1.NewTieringConfig()))
+	if err := fstore.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fstore.OffloadColdFiles(context.Background(), time.Now().UnixNano()); err != nil {
+		t.Fatal(err)
+	}
+	if err := fstore.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fstore = tsm1.NewFileStore(dir)
+	if err := fstore.Open(context.Background()); err == nil {
+		fstore.Close()
+		t.Fatal("expected error opening offloaded file without a tier")
+	}
+	if _, err := os.Stat(f); err != nil {
+		t.Fatalf("expected stub to be kept: %v", err)
+	}
+}
@@ -0,0 +1,387 @@
+package tsm1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// FloatEncoding identifies an encoding of the values of float blocks.
+type FloatEncoding int
+
+const (
+	// FloatEncodingXOR encodes each value as the XOR of it and the previous
+	// value, as described by the Gorilla paper.
+	FloatEncodingXOR FloatEncoding = iota
+
+	// FloatEncodingXORRuns encodes runs of repeated values as a single XOR
+	// encoded value and the length of the run. It suits sensor data with
+	// long constant runs.
+	FloatEncodingXORRuns
+)
+
+// IntegerEncoding identifies an encoding of the values of integer blocks.
+type IntegerEncoding int
+
+const (
+	// IntegerEncodingDelta encodes the differences between consecutive
+	// values.
+	IntegerEncodingDelta IntegerEncoding = iota
+
+	// IntegerEncodingDeltaOfDelta encodes the differences between consecutive
+	// deltas. It suits counters that increase at a near constant rate.
+	IntegerEncodingDeltaOfDelta
+)
+
+// ValueEncoding is the encoding of the values of float and integer blocks.
+// The zero value selects the default encodings.
+type ValueEncoding struct {
+	Float   FloatEncoding
+	Integer IntegerEncoding
+}
+
+// ParseValueEncoding returns the ValueEncoding named by s, a comma separated
+// list of encodings. Valid encodings are "xor" and "xor-runs" for floats, and
+// "delta" and "delta-of-delta" for integers. An empty string or "default"
+// selects the default encodings.
+func ParseValueEncoding(s string) (ValueEncoding, error) {
+	var enc ValueEncoding
+	if s == "" || s == "default" {
+		return enc, nil
+	}
+
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "xor":
+			enc.Float = FloatEncodingXOR
+		case "xor-runs":
+			enc.Float = FloatEncodingXORRuns
+		case "delta":
+			enc.Integer = IntegerEncodingDelta
+		case "delta-of-delta":
+			enc.Integer = IntegerEncodingDeltaOfDelta
+		default:
+			return ValueEncoding{}, fmt.Errorf("unknown value encoding: %q", name)
+		}
+	}
+	return enc, nil
+}
+
+// String returns the names of the encodings.
+func (e ValueEncoding) String() string {
+	var names []string
+	if e.Float == FloatEncodingXORRuns {
+		names = append(names, "xor-runs")
+	}
+	if e.Integer == IntegerEncodingDeltaOfDelta {
+		names = append(names, "delta-of-delta")
+	}
+	if len(names) == 0 {
+		return "default"
+	}
+	return strings.Join(names, ",")
+}
+
+// FloatArrayEncodeRuns encodes src into b using the XOR encoding of runs of
+// repeated values, returning b and any error encountered.
+func FloatArrayEncodeRuns(src []float64, b []byte) ([]byte, error) {
+	var (
+		values []float64
+		runs   []byte
+		buf    [binary.MaxVarintLen64]byte
+	)
+	for i := 0; i < len(src); {
+		j := i + 1
+		for j < len(src) && src[j] == src[i] {
+			j++
+		}
+		values = append(values, src[i])
+		runs = append(runs, buf[:binary.PutUvarint(buf[:], uint64(j-i))]...)
+		i = j
+	}
+
+	payload, err := FloatArrayEncodeAll(values, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b = append(b[:0], floatCompressedRuns<<4)
+	b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(values)))]...)
+	b = append(b, runs...)
+	return append(b, payload...), nil
+}
+
+// unpackFloatRuns returns the run lengths and the XOR encoded values of b,
+// encoded by FloatArrayEncodeRuns.
+func unpackFloatRuns(b []byte) (runs, payload []byte, err error) {
+	n, i := binary.Uvarint(b[1:])
+	if i <= 0 {
+		return nil, nil, fmt.Errorf("floatDecoder: invalid run count")
+	}
+
+	start := 1 + i
+	end := start
+	for ; n > 0; n-- {
+		_, i := binary.Uvarint(b[end:])
+		if i <= 0 {
+			return nil, nil, fmt.Errorf("floatDecoder: invalid run length")
+		}
+		end += i
+	}
+	return b[start:end], b[end:], nil
+}
+
+// floatArrayDecodeRuns decodes the values encoded by FloatArrayEncodeRuns.
+func floatArrayDecodeRuns(b []byte, buf []float64) ([]float64, error) {
+	runs, payload, err := unpackFloatRuns(b)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := FloatArrayDecodeAll(payload, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	buf = buf[:0]
+	for _, v := range values {
+		n, i := binary.Uvarint(runs)
+		if i <= 0 || n == 0 {
+			return nil, fmt.Errorf("floatArrayDecodeAll: invalid run length")
+		}
+		runs = runs[i:]
+		for ; n > 0; n-- {
+			buf = append(buf, v)
+		}
+	}
+	return buf, nil
+}
+
+// IntegerArrayEncodeDeltaOfDelta encodes src into b using the delta of delta
+// encoding, returning b and any error encountered. Unlike
+// IntegerArrayEncodeAll, src is left unchanged.
+func IntegerArrayEncodeDeltaOfDelta(src []int64, b []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, nil
+	}
+
+	// The differences are encoded as deltas, which makes them deltas of deltas.
+	deltas := make([]int64, len(src))
+	deltas[0] = src[0]
+	for i := 1; i < len(src); i++ {
+		deltas[i] = src[i] - src[i-1]
+	}
+
+	payload, err := IntegerArrayEncodeAll(deltas, nil)
+	if err != nil {
+		return nil, err
+	}
+	b = append(b[:0], intCompressedDeltaOfDelta<<4)
+	return append(b, payload...), nil
+}
+
+// integerBatchDecodeAllDeltaOfDelta decodes the values encoded by
+// IntegerArrayEncodeDeltaOfDelta.
+func integerBatchDecodeAllDeltaOfDelta(b []byte, dst []int64) ([]int64, error) {
+	if len(b) < 2 || b[1]>>4 == intCompressedDeltaOfDelta {
+		return []int64{}, fmt.Errorf("integerArrayDecodeAll: invalid delta of delta encoding")
+	}
+
+	dst, err := IntegerArrayDecodeAll(b[1:], dst)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i < len(dst); i++ {
+		dst[i] += dst[i-1]
+	}
+	return dst, nil
+}
+
+// ReencodeBlock returns block with its values encoded using enc. The original
+// block is returned if it is not a float or integer block, if its values are
+// already encoded using enc, or if encoding them using enc would not make the
+// block any smaller. A block that is re-encoded is returned decrypted and
+// without block compression.
+func ReencodeBlock(block []byte, enc ValueEncoding) ([]byte, error) {
+	if len(block) <= encodedBlockHeaderSize {
+		return block, nil
+	}
+	if typ, err := BlockType(block); err != nil {
+		return nil, err
+	} else if typ != BlockFloat64 && typ != BlockInteger {
+		return block, nil
+	}
+
+	b, err := unwrapBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	ts, vb, err := unpackBlock(b[1:])
+	if err != nil {
+		return nil, err
+	} else if len(vb) == 0 {
+		return block, nil
+	}
+
+	var (
+		encoded []byte
+		revert  bool // true if the values are re-encoded with a default encoding
+	)
+	switch b[0] {
+	case BlockFloat64:
+		revert = vb[0]>>4 == floatCompressedRuns
+		if revert == (enc.Float == FloatEncodingXORRuns) {
+			return block, nil
+		}
+		values, err := FloatArrayDecodeAll(vb, nil)
+		if err != nil {
+			return nil, err
+		}
+		if revert {
+			encoded, err = FloatArrayEncodeAll(values, nil)
+		} else {
+			encoded, err = FloatArrayEncodeRuns(values, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	case BlockInteger:
+		revert = vb[0]>>4 == intCompressedDeltaOfDelta
+		if revert == (enc.Integer == IntegerEncodingDeltaOfDelta) {
+			return block, nil
+		}
+		values, err := IntegerArrayDecodeAll(vb, nil)
+		if err != nil {
+			return nil, err
+		}
+		if revert {
+			encoded, err = IntegerArrayEncodeAll(values, nil)
+		} else {
+			encoded, err = IntegerArrayEncodeDeltaOfDelta(values, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Fall back to the default encoding unless the alternative one is
+	// smaller. The default encodings are always used when asked for, so that
+	// blocks can be read by versions that do not support the others.
+	if !revert && len(encoded) >= len(vb) {
+		return block, nil
+	}
+	return packBlock(nil, b[0], ts, encoded), nil
+}
+
+// ValueEncodingPolicy determines the encoding of the values of blocks written
+// by compactions, optionally overriding the default for individual buckets or
+// measurements.
+type ValueEncodingPolicy struct {
+	Default      ValueEncoding
+	Buckets      map[influxdb.ID]ValueEncoding
+	Measurements map[influxdb.ID]map[string]ValueEncoding
+}
+
+// NewValueEncodingPolicy returns a policy from the value encoding settings in
+// config.
+func NewValueEncodingPolicy(config Config) (*ValueEncodingPolicy, error) {
+	def, err := ParseValueEncoding(config.ValueEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ValueEncodingPolicy{Default: def}
+	for k, v := range config.BucketValueEncoding {
+		bucket, measurement := k, ""
+		if i := strings.IndexByte(k, '/'); i >= 0 {
+			bucket, measurement = k[:i], k[i+1:]
+		}
+		id, err := influxdb.IDFromString(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket id %q for value encoding: %v", bucket, err)
+		}
+		enc, err := ParseValueEncoding(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if measurement == "" {
+			if p.Buckets == nil {
+				p.Buckets = make(map[influxdb.ID]ValueEncoding)
+			}
+			p.Buckets[*id] = enc
+			continue
+		}
+		if p.Measurements == nil {
+			p.Measurements = make(map[influxdb.ID]map[string]ValueEncoding)
+		}
+		if p.Measurements[*id] == nil {
+			p.Measurements[*id] = make(map[string]ValueEncoding)
+		}
+		p.Measurements[*id][measurement] = enc
+	}
+	return p, nil
+}
+
+// Encoding returns the encoding to use for the values of blocks of the series
+// key.
+func (p *ValueEncodingPolicy) Encoding(key []byte) ValueEncoding {
+	if p == nil {
+		return ValueEncoding{}
+	}
+	if (len(p.Buckets) > 0 || len(p.Measurements) > 0) && len(key) >= 16 {
+		_, bucketID := tsdb.DecodeNameSlice(key[:16])
+		if measurements := p.Measurements[bucketID]; len(measurements) > 0 {
+			seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+			_, tags := models.ParseKeyBytes(seriesKey)
+			if enc, ok := measurements[string(tags.Get(models.MeasurementTagKeyBytes))]; ok {
+				return enc
+			}
+		}
+		if enc, ok := p.Buckets[bucketID]; ok {
+			return enc
+		}
+	}
+	return p.Default
+}
+
+// reencodingKeyIterator re-encodes the values of the blocks read from a
+// KeyIterator with the encoding required by a policy.
+type reencodingKeyIterator struct {
+	KeyIterator
+	policy *ValueEncodingPolicy
+
+	// The encoding of the last key read, as a key usually has many blocks.
+	key []byte
+	enc ValueEncoding
+}
+
+func (k *reencodingKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	key, minTime, maxTime, block, err := k.KeyIterator.Read()
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+
+	if !bytes.Equal(key, k.key) {
+		k.key = append(k.key[:0], key...)
+		k.enc = k.policy.Encoding(key)
+	}
+
+	// Blocks are left with the encoding they were written with unless the
+	// key is given an alternative encoding.
+	if k.enc == (ValueEncoding{}) {
+		return key, minTime, maxTime, block, nil
+	}
+
+	block, err = ReencodeBlock(block, k.enc)
+	if err != nil {
+		return nil, 0, 0, nil, err
+	}
+	return key, minTime, maxTime, block, nil
+}
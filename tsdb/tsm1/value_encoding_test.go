@@ -0,0 +1,267 @@
+package tsm1_test
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestReencodeBlock_Float(t *testing.T) {
+	// A sensor reading that holds its value for long runs.
+	var values tsm1.Values
+	for i := 0; i < tsm1.MaxPointsPerBlock; i++ {
+		values = append(values, tsm1.NewValue(int64(i)*1e9, 20.5+float64(i/100)))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := tsm1.ReencodeBlock(block, tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(runs), len(block); got >= exp {
+		t.Fatalf("re-encoded block is not smaller: got %d, exp < %d", got, exp)
+	}
+
+	// Both the iterator and the array decoders read the re-encoded values.
+	decoded, err := tsm1.DecodeBlock(runs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(decoded), len(values); got != exp {
+		t.Fatalf("unexpected number of values: got %d, exp %d", got, exp)
+	}
+	for i := range values {
+		assertValueEqual(t, decoded[i], values[i])
+	}
+	var a tsdb.FloatArray
+	if err := tsm1.DecodeFloatArrayBlock(runs, &a); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		if a.Timestamps[i] != v.UnixNano() || a.Values[i] != v.Value().(float64) {
+			t.Fatalf("unexpected value at %d: got %d=%v, exp %v", i, a.Timestamps[i], a.Values[i], v)
+		}
+	}
+
+	// Asking for the default encoding reverts the block.
+	reverted, err := tsm1.ReencodeBlock(runs, tsm1.ValueEncoding{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(reverted, block) {
+		t.Fatal("expected block to be reverted to the default encoding")
+	}
+}
+
+func TestReencodeBlock_Integer(t *testing.T) {
+	// A counter that increases at a near constant rate.
+	var values tsm1.Values
+	for i := 0; i < tsm1.MaxPointsPerBlock; i++ {
+		values = append(values, tsm1.NewValue(int64(i)*1e9, int64(i)*1000+int64(i%3)))
+	}
+
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dod, err := tsm1.ReencodeBlock(block, tsm1.ValueEncoding{Integer: tsm1.IntegerEncodingDeltaOfDelta})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(dod), len(block); got >= exp {
+		t.Fatalf("re-encoded block is not smaller: got %d, exp < %d", got, exp)
+	}
+
+	decoded, err := tsm1.DecodeBlock(dod, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := len(decoded), len(values); got != exp {
+		t.Fatalf("unexpected number of values: got %d, exp %d", got, exp)
+	}
+	for i := range values {
+		assertValueEqual(t, decoded[i], values[i])
+	}
+	var a tsdb.IntegerArray
+	if err := tsm1.DecodeIntegerArrayBlock(dod, &a); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range values {
+		if a.Timestamps[i] != v.UnixNano() || a.Values[i] != v.Value().(int64) {
+			t.Fatalf("unexpected value at %d: got %d=%v, exp %v", i, a.Timestamps[i], a.Values[i], v)
+		}
+	}
+}
+
+func TestIntegerArrayEncodeDeltaOfDelta_Overflow(t *testing.T) {
+	src := []int64{0, math.MaxInt64, math.MinInt64, -1, math.MaxInt64, 1}
+	b, err := tsm1.IntegerArrayEncodeDeltaOfDelta(src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tsm1.IntegerArrayDecodeAll(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, src) {
+		t.Fatalf("unexpected values: %v", cmp.Diff(got, src))
+	}
+}
+
+func TestReencodeBlock_Fallback(t *testing.T) {
+	// Random values have no runs, so the default encoding is kept.
+	var values tsm1.Values
+	for i := 0; i < 100; i++ {
+		values = append(values, tsm1.NewValue(int64(i), rand.Float64()))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := tsm1.ReencodeBlock(block, tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(got, block) {
+		t.Fatal("expected original block")
+	}
+
+	// Blocks of other types are left as they are.
+	block, err = tsm1.Values{tsm1.NewValue(0, "a"), tsm1.NewValue(1, "a")}.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := tsm1.ReencodeBlock(block, tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns}); err != nil {
+		t.Fatal(err)
+	} else if !cmp.Equal(got, block) {
+		t.Fatal("expected original block")
+	}
+}
+
+func TestParseValueEncoding(t *testing.T) {
+	for _, tt := range []struct {
+		s   string
+		exp tsm1.ValueEncoding
+	}{
+		{s: "", exp: tsm1.ValueEncoding{}},
+		{s: "default", exp: tsm1.ValueEncoding{}},
+		{s: "xor-runs", exp: tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns}},
+		{s: "xor-runs, delta-of-delta", exp: tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns, Integer: tsm1.IntegerEncodingDeltaOfDelta}},
+	} {
+		got, err := tsm1.ParseValueEncoding(tt.s)
+		if err != nil {
+			t.Fatal(err)
+		} else if got != tt.exp {
+			t.Fatalf("got %s for %q, exp %s", got, tt.s, tt.exp)
+		}
+	}
+
+	if _, err := tsm1.ParseValueEncoding("xor,gzip"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestValueEncodingPolicy_Encoding(t *testing.T) {
+	config := tsm1.NewConfig()
+	config.BucketValueEncoding = map[string]string{
+		"0000000000000002":        "delta-of-delta",
+		"0000000000000002/sensor": "xor-runs",
+	}
+	p, err := tsm1.NewValueEncodingPolicy(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := func(bucket uint64, measurement string) []byte {
+		name := tsdb.EncodeName(1, influxdb.ID(bucket))
+		tags := models.NewTags(map[string]string{models.MeasurementTagKey: measurement, models.FieldKeyTagKey: "value"})
+		return tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name[:], tags)), "value")
+	}
+
+	for _, tt := range []struct {
+		key []byte
+		exp tsm1.ValueEncoding
+	}{
+		{key: key(2, "sensor"), exp: tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns}},
+		{key: key(2, "cpu"), exp: tsm1.ValueEncoding{Integer: tsm1.IntegerEncodingDeltaOfDelta}},
+		{key: key(3, "sensor"), exp: tsm1.ValueEncoding{}},
+	} {
+		if got := p.Encoding(tt.key); got != tt.exp {
+			t.Fatalf("got %s for %q, exp %s", got, tt.key, tt.exp)
+		}
+	}
+
+	config.BucketValueEncoding = map[string]string{"invalid": "xor-runs"}
+	if _, err := tsm1.NewValueEncodingPolicy(config); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// Ensures compactions re-encode values with the configured encoding.
+func TestCompactor_CompactFull_ValueEncoding(t *testing.T) {
+	var a, b tsm1.Values
+	for i := 0; i < 500; i++ {
+		a = append(a, tsm1.NewValue(int64(i), 1.5))
+		b = append(b, tsm1.NewValue(int64(i+500), 2.5))
+	}
+
+	compact := func(policy *tsm1.ValueEncodingPolicy) (*tsm1.TSMReader, func()) {
+		dir := MustTempDir()
+		f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{"cpu,host=A#!~#value": a})
+		f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{"cpu,host=A#!~#value": b})
+
+		fs := &fakeFileStore{}
+		compactor := tsm1.NewCompactor()
+		compactor.Dir = dir
+		compactor.FileStore = fs
+		compactor.ValueEncoding = policy
+		compactor.Open()
+
+		files, err := compactor.CompactFull([]string{f1, f2})
+		if err != nil {
+			t.Fatalf("unexpected error compacting: %v", err)
+		}
+		if got, exp := len(files), 1; got != exp {
+			t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+		}
+		r := MustOpenTSMReader(files[0])
+		return r, func() {
+			r.Close()
+			fs.Close()
+			os.RemoveAll(dir)
+		}
+	}
+
+	plain, cleanup := compact(nil)
+	defer cleanup()
+	runs, cleanup := compact(&tsm1.ValueEncodingPolicy{Default: tsm1.ValueEncoding{Float: tsm1.FloatEncodingXORRuns}})
+	defer cleanup()
+
+	if got, exp := runs.Size(), plain.Size(); got >= exp {
+		t.Fatalf("re-encoded file is not smaller: got %d, exp < %d", got, exp)
+	}
+
+	values, err := runs.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := append(a, b...)
+	if got, exp := len(values), len(exp); got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+	for i := range exp {
+		assertValueEqual(t, values[i], exp[i])
+	}
+}
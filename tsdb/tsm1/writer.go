@@ -75,6 +75,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/fault"
 	"github.com/influxdata/influxdb/pkg/fs"
 )
 
@@ -806,6 +807,10 @@ func (t *tsmWriter) Flush() error {
 }
 
 func (t *tsmWriter) sync() error {
+	if err := fault.Err(fault.TSMSync); err != nil {
+		return err
+	}
+
 	// sync is a minimal interface to make sure we can sync the wrapped
 	// value. we use a minimal interface to be as robust as possible for
 	// syncing these files.
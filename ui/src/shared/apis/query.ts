@@ -35,7 +35,8 @@ export interface RunQueryErrorResult {
 export const runQuery = (
   orgID: string,
   query: string,
-  extern?: File
+  extern?: File,
+  priority?: Query['priority']
 ): CancelBox<RunQueryResult> => {
   const url = `${API_BASE_PATH}api/v2/query?${new URLSearchParams({orgID})}`
 
@@ -48,6 +49,7 @@ export const runQuery = (
     query,
     extern,
     dialect: {annotations: ['group', 'datatype', 'default']},
+    priority,
   }
 
   const controller = new AbortController()
@@ -180,7 +180,7 @@ class TimeSeries extends Component<Props & WithRouterProps, State> {
         const windowVars = getWindowVars(text, variables)
         const extern = buildVarsOption([...variables, ...windowVars])
 
-        return runQuery(orgID, text, extern)
+        return runQuery(orgID, text, extern, 'dashboard')
       })
 
       // Wait for new queries to complete
@@ -0,0 +1,95 @@
+package vault
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	influxlogger "github.com/influxdata/influxdb/logger"
+	"go.uber.org/zap"
+)
+
+// TokenRenewer keeps the token of a vault client alive by renewing its lease
+// before it runs out, if the token is renewable.
+type TokenRenewer struct {
+	Client *api.Client
+
+	// RetryInterval is how long the renewer waits to retry failed renewals.
+	RetryInterval time.Duration
+
+	log *zap.Logger
+}
+
+// NewTokenRenewer returns a TokenRenewer renewing the token of c, retrying
+// failed renewals every 30 seconds.
+func NewTokenRenewer(log *zap.Logger, c *api.Client) *TokenRenewer {
+	return &TokenRenewer{
+		Client:        c,
+		RetryInterval: 30 * time.Second,
+		log:           log,
+	}
+}
+
+// Run renews the token once two thirds of its TTL have passed until ctx is
+// done. It returns right away if the token does not expire or cannot be
+// renewed.
+func (r *TokenRenewer) Run(ctx context.Context) {
+	logger := r.log.With(
+		zap.String("service", "vault_token_renewer"),
+		influxlogger.DurationLiteral("retry_interval", r.RetryInterval),
+	)
+
+	logger.Info("Starting")
+	for {
+		d, err := r.Renew()
+		if err != nil {
+			logger.Warn("Unable to renew vault token", zap.Error(err))
+			d = r.RetryInterval
+		} else if d == 0 {
+			logger.Info("Vault token does not need to be renewed")
+			return
+		}
+
+		timer := time.NewTimer(d)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Info("Stopping")
+			return
+		}
+	}
+}
+
+// Renew renews the token if it is renewable, and returns how long to wait
+// before renewing it again, or zero if it does not expire or cannot be
+// renewed.
+func (r *TokenRenewer) Renew() (time.Duration, error) {
+	sec, err := r.Client.Auth().Token().LookupSelf()
+	if err != nil {
+		return 0, err
+	}
+	renewable, err := sec.TokenIsRenewable()
+	if err != nil {
+		return 0, err
+	}
+	ttl, err := sec.TokenTTL()
+	if err != nil {
+		return 0, err
+	}
+	if !renewable || ttl == 0 {
+		return 0, nil
+	}
+
+	sec, err = r.Client.Auth().Token().RenewSelf(0)
+	if err != nil {
+		return 0, err
+	}
+	if ttl, err = sec.TokenTTL(); err != nil {
+		return 0, err
+	}
+	if d := ttl * 2 / 3; d > time.Second {
+		return d, nil
+	}
+	return time.Second, nil
+}
@@ -0,0 +1,70 @@
+package vault_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/vault"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTokenRenewer_Renew(t *testing.T) {
+	tests := []struct {
+		name       string
+		lookup     string
+		wantRenew  bool
+		wantResult time.Duration
+	}{
+		{
+			name:       "renewable token",
+			lookup:     `{"data": {"renewable": true, "ttl": 60}}`,
+			wantRenew:  true,
+			wantResult: 40 * time.Second,
+		},
+		{
+			name:   "token that cannot be renewed",
+			lookup: `{"data": {"renewable": false, "ttl": 60}}`,
+		},
+		{
+			name:   "token that does not expire",
+			lookup: `{"data": {"renewable": false, "ttl": 0}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var renewed bool
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/auth/token/lookup-self":
+					fmt.Fprint(w, tt.lookup)
+				case "/v1/auth/token/renew-self":
+					renewed = true
+					fmt.Fprint(w, `{"auth": {"renewable": true, "lease_duration": 60}}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer ts.Close()
+
+			s, err := vault.NewSecretService(vault.WithConfig(vault.Config{Address: ts.URL, Token: "test"}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			d, err := vault.NewTokenRenewer(zaptest.NewLogger(t), s.Client).Renew()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if renewed != tt.wantRenew {
+				t.Errorf("got renewed %v, want %v", renewed, tt.wantRenew)
+			}
+			if d != tt.wantResult {
+				t.Errorf("got next renewal in %v, want %v", d, tt.wantResult)
+			}
+		})
+	}
+}
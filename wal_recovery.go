@@ -0,0 +1,53 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// WALRecoveryReport describes the replay of the write ahead log when the
+// storage engine was opened. Entries after a corrupt entry in a segment are
+// truncated, so the report lists the data that was lost after an unclean
+// shutdown.
+type WALRecoveryReport struct {
+	StartedAt   time.Time            `json:"startedAt"`
+	CompletedAt time.Time            `json:"completedAt"`
+	Segments    []WALSegmentRecovery `json:"segments"`
+	Buckets     []WALBucketRecovery  `json:"buckets"`
+	// Error is the error that stopped the replay, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// WALSegmentRecovery describes the replay of a WAL segment file.
+type WALSegmentRecovery struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	// EntriesReplayed is the number of entries replayed from the segment.
+	EntriesReplayed int `json:"entriesReplayed"`
+	// EntriesDropped is the number of valid entries following a corrupt
+	// entry, which were truncated with it.
+	EntriesDropped int `json:"entriesDropped"`
+	// CorruptEntries is the number of entries that could not be read.
+	CorruptEntries int `json:"corruptEntries"`
+	// TruncatedBytes is the size of the data truncated from the segment.
+	TruncatedBytes int64 `json:"truncatedBytes"`
+	// Corruption describes the first corrupt entry of the segment, if any.
+	Corruption string `json:"corruption,omitempty"`
+}
+
+// WALBucketRecovery counts the points of a bucket replayed from the WAL.
+type WALBucketRecovery struct {
+	OrgID           ID  `json:"orgID"`
+	BucketID        ID  `json:"bucketID"`
+	PointsRecovered int `json:"pointsRecovered"`
+	// PointsDropped is the number of points that were truncated from the WAL
+	// or rejected by the engine when they were replayed.
+	PointsDropped int `json:"pointsDropped"`
+}
+
+// WALRecoveryService reports on the replay of the write ahead log.
+type WALRecoveryService interface {
+	// WALRecoveryReport returns the report of the replay of the WAL when the
+	// storage engine was last opened.
+	WALRecoveryReport(ctx context.Context) (*WALRecoveryReport, error)
+}
@@ -9,3 +9,48 @@ import (
 type WriteService interface {
 	Write(ctx context.Context, org, bucket ID, r io.Reader) error
 }
+
+// MeasurementWriteStats summarises recent write activity for a single
+// measurement within a bucket.
+type MeasurementWriteStats struct {
+	OrgID       ID     `json:"orgID"`
+	BucketID    ID     `json:"bucketID"`
+	Measurement string `json:"measurement"`
+
+	// Points, Bytes and NewSeries cover the most recently completed
+	// tracking interval.
+	Points    uint64 `json:"points"`
+	Bytes     uint64 `json:"bytes"`
+	NewSeries uint64 `json:"newSeries"`
+
+	// The Total fields cover all writes since tracking began. TotalSeries
+	// is an estimate of the number of distinct series written.
+	TotalPoints uint64 `json:"totalPoints"`
+	TotalBytes  uint64 `json:"totalBytes"`
+	TotalSeries uint64 `json:"totalSeries"`
+}
+
+// Measurement write stats sort keys.
+const (
+	WriteStatsSortPoints = "points"
+	WriteStatsSortBytes  = "bytes"
+	WriteStatsSortSeries = "series"
+)
+
+// WriteStatsFilter restricts and orders the results of a write stats lookup.
+type WriteStatsFilter struct {
+	OrgID    *ID
+	BucketID *ID
+
+	// SortBy is one of the WriteStatsSort keys. Results are ranked in
+	// descending order.
+	SortBy string
+
+	// Limit is the maximum number of results returned. Zero means no limit.
+	Limit int
+}
+
+// WriteStatsService provides ranked per-measurement write statistics.
+type WriteStatsService interface {
+	FindMeasurementWriteStats(ctx context.Context, filter WriteStatsFilter) ([]*MeasurementWriteStats, error)
+}